@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixturesDir holds the golden DID Manager responses, checked against the service by
+// its own contract test
+const fixturesDir = "../api/did-manager/fixtures"
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// serveFixture answers every request with the named golden response and returns it
+func serveFixture(t *testing.T, name string, status int) (*httptest.Server, []byte) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join(fixturesDir, name+".json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, body
+}
+
+// checkFieldsInFixture checks that every JSON field of the type of v is present in
+// the fixture, so a field renamed or dropped by the DID Manager is caught here rather
+// than silently decoded as its zero value
+func checkFieldsInFixture(t *testing.T, v any, body []byte) {
+	t.Helper()
+	var fixture any
+	if err := json.Unmarshal(body, &fixture); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	checkFields(t, reflect.TypeOf(v), fixture, "")
+}
+
+func checkFields(t *testing.T, typ reflect.Type, value any, path string) {
+	t.Helper()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(unmarshalerType) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			t.Errorf("expected an object at %q", path)
+			return
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" || name == "" {
+				continue
+			}
+			fieldValue, ok := object[name]
+			if !ok {
+				t.Errorf("field %q is not in the DID Manager response", path+"."+name)
+				continue
+			}
+			checkFields(t, field.Type, fieldValue, path+"."+name)
+		}
+	case reflect.Slice:
+		if items, ok := value.([]any); ok && len(items) > 0 {
+			checkFields(t, typ.Elem(), items[0], path+"[0]")
+		}
+	}
+}
+
+func TestCreateDIDContract(t *testing.T) {
+	server, body := serveFixture(t, "create_did", http.StatusCreated)
+	checkFieldsInFixture(t, DIDResponse{}, body)
+
+	resp, err := NewDIDClient(server.URL).CreateDID(&DIDCreateRequest{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("CreateDID failed: %v", err)
+	}
+	if !resp.Success || resp.Data.DID.Did == "" || resp.Data.DID.CreatedAt.IsZero() || resp.Data.UserHash == "" {
+		t.Errorf("expected the created DID, got %+v", resp.Data)
+	}
+}
+
+func TestVerifyDIDContract(t *testing.T) {
+	server, body := serveFixture(t, "verify_did", http.StatusOK)
+	checkFieldsInFixture(t, DIDVerificationResponse{}, body)
+
+	resp, err := NewDIDClient(server.URL).VerifyDID(&DIDVerificationRequest{DID: "did:example:1"})
+	if err != nil {
+		t.Fatalf("VerifyDID failed: %v", err)
+	}
+	if !resp.Data.IsValid || resp.Data.BlockchainTx == "" {
+		t.Errorf("expected a valid, anchored DID, got %+v", resp.Data)
+	}
+}
+
+func TestCheckAnchoringContract(t *testing.T) {
+	server, body := serveFixture(t, "did_status", http.StatusOK)
+	var resp struct {
+		Data DIDAnchoring `json:"data"`
+	}
+	checkFieldsInFixture(t, resp, body)
+
+	anchoring, err := NewDIDClient(server.URL).CheckAnchoring("did:example:1")
+	if err != nil {
+		t.Fatalf("CheckAnchoring failed: %v", err)
+	}
+	if anchoring.BlockchainTx == "" || anchoring.Assurance == "" || anchoring.Confirmations == 0 {
+		t.Errorf("expected the anchoring of the DID, got %+v", anchoring)
+	}
+}
+
+func TestErrorContract(t *testing.T) {
+	server, body := serveFixture(t, "error", http.StatusConflict)
+	checkFieldsInFixture(t, apiError{}, body)
+
+	err := NewDIDClient(server.URL).do(http.MethodGet, "/api/v1/did/status/did:example:1", nil, nil, nil, http.StatusOK, nil)
+	if err == nil || !strings.Contains(err.Error(), "Identity already registered") {
+		t.Errorf("expected the DID Manager's error message, got %v", err)
+	}
+}
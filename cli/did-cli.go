@@ -2,11 +2,18 @@ package main
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,10 +37,12 @@ func NewDIDClient(baseURL string) *DIDClient {
 
 // DIDCreateRequest represents a request to create a DID
 type DIDCreateRequest struct {
-	UserID   string `json:"user_id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	UserID       string `json:"user_id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	KeyAlgorithm string `json:"key_algorithm,omitempty"`
+	DryRun       bool   `json:"dry_run,omitempty"`
 }
 
 // DIDResponse represents the response after DID creation
@@ -47,10 +56,15 @@ type DIDResponse struct {
 			UserHash  string    `json:"user_hash"`
 			Status    string    `json:"status"`
 			CreatedAt time.Time `json:"created_at"`
+			// PublicKey holds the private key of custodial DIDs
+			PublicKey    string `json:"public_key"`
+			KeyAlgorithm string `json:"key_algorithm"`
 		} `json:"did"`
 		UserHash string `json:"user_hash"`
 		Status   string `json:"status"`
 		Message  string `json:"message"`
+		// ConsistencyToken makes reads right after the creation see the new DID
+		ConsistencyToken string `json:"consistency_token"`
 	} `json:"data"`
 }
 
@@ -58,6 +72,10 @@ type DIDResponse struct {
 type DIDVerificationRequest struct {
 	DID      string `json:"did"`
 	UserHash string `json:"user_hash"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// ConsistencyToken is sent in X-Consistency-Token to verify a DID just created
+	ConsistencyToken string `json:"-"`
 }
 
 // DIDVerificationResponse represents the response after DID verification
@@ -114,11 +132,16 @@ func (c *DIDClient) VerifyDID(req *DIDVerificationRequest) (*DIDVerificationResp
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		c.baseURL+"/api/v1/did/verify",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v1/did/verify", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if req.ConsistencyToken != "" {
+		httpReq.Header.Set("X-Consistency-Token", req.ConsistencyToken)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -183,7 +206,901 @@ func (c *DIDClient) HealthCheck() error {
 	return nil
 }
 
+// apiError is the error body returned by the DID Manager
+type apiError struct {
+	Error   string `json:"error"`
+	Details string `json:"details"`
+}
+
+// do sends a JSON request and decodes the response into out, failing unless the
+// response has the expected status. A non-nil signer authenticates the request as
+// its DID.
+func (c *DIDClient) do(method, path string, body any, signer *didSigner, headers map[string]string, wantStatus int, out any) error {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if signer != nil {
+		signer.sign(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != wantStatus {
+		var apiErr apiError
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			if apiErr.Details != "" {
+				return fmt.Errorf("%s %s: %d %s: %s", method, path, resp.StatusCode, apiErr.Error, apiErr.Details)
+			}
+			return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("%s %s: unexpected status code: %d, body: %s", method, path, resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
+
+// didSigner authenticates requests as a DID by signing the method, path and
+// timestamp with the DID's Ed25519 key
+type didSigner struct {
+	did        string
+	privateKey ed25519.PrivateKey
+}
+
+func (s *didSigner) sign(req *http.Request) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	payload := req.Method + " " + req.URL.Path + "\n" + timestamp
+
+	req.Header.Set("X-Caller-DID", s.did)
+	req.Header.Set("X-Caller-Timestamp", timestamp)
+	req.Header.Set("X-Caller-Signature", hex.EncodeToString(ed25519.Sign(s.privateKey, []byte(payload))))
+}
+
+// selfTestCredential is the part of a stored credential checked by the self-test
+type selfTestCredential struct {
+	ID       string          `json:"id"`
+	Status   string          `json:"status"`
+	Document json.RawMessage `json:"document"`
+}
+
+// selfTestVerification is a credential verification result
+type selfTestVerification struct {
+	Valid   bool   `json:"valid"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// SelfTest runs the create → queue → anchor (simulated) → verify → revoke path against
+// a deployment, stopping at the first failing step. The DID is created as a dry run,
+// so nothing is submitted on-chain; the simulated anchoring step needs the admin key
+// and is skipped without it. The test DID and credential are left in place.
+func (c *DIDClient) SelfTest(adminKey string) error {
+	// Step 1: Readiness
+	fmt.Println("\n1. Checking service readiness...")
+	if err := c.do(http.MethodGet, "/api/v1/ready", nil, nil, nil, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("service is not ready: %w", err)
+	}
+	fmt.Println("✓ Service is ready")
+
+	// Step 2: Create a dry-run DID with an Ed25519 key the CLI can sign with
+	fmt.Println("\n2. Creating a dry-run DID...")
+	runID := uuid.New().String()
+	createReq := &DIDCreateRequest{
+		UserID:       uuid.New().String(),
+		Name:         "Selftest " + runID,
+		Email:        "selftest+" + runID + "@example.com",
+		Password:     uuid.New().String(),
+		KeyAlgorithm: "ed25519-2020",
+		DryRun:       true,
+	}
+	var created DIDResponse
+	if err := c.do(http.MethodPost, "/api/v1/did", createReq, nil, nil, http.StatusCreated, &created); err != nil {
+		return fmt.Errorf("failed to create DID: %w", err)
+	}
+	record := created.Data.DID
+	if record.Status == "pending_review" {
+		return fmt.Errorf("DID %s was held for review; exempt selftest identities from screening and duplicate detection", record.Did)
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil || len(keyMaterial) != ed25519.PrivateKeySize {
+		return errors.New("created DID is not custodial with an Ed25519 key; cannot sign as it")
+	}
+	signer := &didSigner{did: record.Did, privateKey: ed25519.PrivateKey(keyMaterial)}
+	fmt.Printf("✓ DID created: %s (%s)\n", record.Did, record.Status)
+
+	// Step 3: Process the queue, simulating the dry-run registration job
+	fmt.Println("\n3. Processing the blockchain queue...")
+	if err := c.do(http.MethodPost, "/api/v1/queue/process", nil, nil, nil, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("failed to process queue: %w", err)
+	}
+	fmt.Println("✓ Queue processed")
+
+	// Step 4: Simulate anchoring the DID on-chain
+	fmt.Println("\n4. Simulating on-chain anchoring...")
+	if adminKey == "" {
+		fmt.Println("- Skipped: set DID_MANAGER_ADMIN_KEY to simulate anchoring")
+	} else {
+		var simulated struct {
+			Data struct {
+				ID         string `json:"id"`
+				Status     string `json:"status"`
+				Simulation *struct {
+					GasEstimate  uint64 `json:"gas_estimate"`
+					Reverted     bool   `json:"reverted"`
+					RevertReason string `json:"revert_reason"`
+				} `json:"simulation"`
+			} `json:"data"`
+		}
+		simulateReq := map[string]string{"did_id": record.ID, "job_type": "register_did"}
+		headers := map[string]string{"X-Admin-Key": adminKey}
+		if err := c.do(http.MethodPost, "/api/v1/admin/jobs/simulate", simulateReq, nil, headers, http.StatusOK, &simulated); err != nil {
+			return fmt.Errorf("failed to simulate anchoring: %w", err)
+		}
+		simulation := simulated.Data.Simulation
+		if simulation == nil {
+			return fmt.Errorf("job %s has no simulation result", simulated.Data.ID)
+		}
+		if simulation.Reverted {
+			return fmt.Errorf("anchoring would revert: %s", simulation.RevertReason)
+		}
+		fmt.Printf("✓ Anchoring simulated: job %s, gas estimate %d\n", simulated.Data.ID, simulation.GasEstimate)
+	}
+
+	// Step 5: Verify the DID and its claims. A dry-run DID is never anchored, so it
+	// stays pending rather than valid on-chain.
+	fmt.Println("\n5. Verifying the DID...")
+	verifyReq := &DIDVerificationRequest{
+		DID:      record.Did,
+		UserHash: created.Data.UserHash,
+		Name:     createReq.Name,
+		Email:    createReq.Email,
+	}
+	var verified DIDVerificationResponse
+	if err := c.do(http.MethodPost, "/api/v1/did/verify", verifyReq, nil, nil, http.StatusOK, &verified); err != nil {
+		return fmt.Errorf("failed to verify DID: %w", err)
+	}
+	if !verified.Data.IsValid && verified.Data.Status != "pending" {
+		return fmt.Errorf("DID verification failed: %s (%s)", verified.Data.Status, verified.Data.Message)
+	}
+	fmt.Printf("✓ DID verified: %s\n", verified.Data.Status)
+
+	// Step 6: Issue a credential signed by the DID to itself
+	fmt.Println("\n6. Issuing a credential...")
+	issueReq := map[string]any{
+		"subject_did": record.Did,
+		"type":        "SelfTestCredential",
+		"claims":      map[string]any{"selftest": runID},
+	}
+	var issued struct {
+		Data selfTestCredential `json:"data"`
+	}
+	if err := c.do(http.MethodPost, "/api/v1/credentials", issueReq, signer, nil, http.StatusCreated, &issued); err != nil {
+		return fmt.Errorf("failed to issue credential: %w", err)
+	}
+	if issued.Data.Status != "active" {
+		return fmt.Errorf("credential %s is %s, expected active", issued.Data.ID, issued.Data.Status)
+	}
+	if err := c.expectCredential(issued.Data.Document, true, "active"); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Credential issued and verified: %s\n", issued.Data.ID)
+
+	// Step 7: Revoke the credential and check verification now fails
+	fmt.Println("\n7. Revoking the credential...")
+	if err := c.do(http.MethodPost, "/api/v1/credentials/"+issued.Data.ID+"/revoke", nil, signer, nil, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("failed to revoke credential: %w", err)
+	}
+	if err := c.expectCredential(issued.Data.Document, false, "revoked"); err != nil {
+		return err
+	}
+	fmt.Println("✓ Credential revoked and no longer verifies")
+
+	return nil
+}
+
+// jobSummary is the part of a blockchain job printed by the CLI
+type jobSummary struct {
+	ID      string `json:"id"`
+	JobType string `json:"job_type"`
+	DID     string `json:"did"`
+	Status  string `json:"status"`
+}
+
+// JobCancellation is the outcome of canceling a blockchain job
+type JobCancellation struct {
+	Job             jobSummary  `json:"job"`
+	Canceled        bool        `json:"canceled"`
+	CompensatingJob *jobSummary `json:"compensating_job"`
+}
+
+// CancelJob cancels a blockchain job before its transaction is broadcast; once it was,
+// the service revokes the DID with a compensating job instead
+func (c *DIDClient) CancelJob(adminKey, jobID, reason string) (*JobCancellation, error) {
+	var resp struct {
+		Data JobCancellation `json:"data"`
+	}
+	headers := map[string]string{"X-Admin-Key": adminKey}
+	body := map[string]string{"reason": reason}
+	if err := c.do(http.MethodPost, "/api/v1/admin/jobs/"+jobID+"/cancel", body, nil, headers, http.StatusOK, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// expectCredential verifies a credential document and checks the outcome
+func (c *DIDClient) expectCredential(document json.RawMessage, wantValid bool, wantStatus string) error {
+	var result struct {
+		Data selfTestVerification `json:"data"`
+	}
+	req := map[string]json.RawMessage{"credential": document}
+	if err := c.do(http.MethodPost, "/api/v1/credentials/verify", req, nil, nil, http.StatusOK, &result); err != nil {
+		return fmt.Errorf("failed to verify credential: %w", err)
+	}
+	if result.Data.Valid != wantValid || result.Data.Status != wantStatus {
+		return fmt.Errorf("credential verification returned valid=%t status=%q (%s), expected valid=%t status=%q",
+			result.Data.Valid, result.Data.Status, result.Data.Message, wantValid, wantStatus)
+	}
+	return nil
+}
+
+// DIDDocument is a resolved W3C DID Document
+type DIDDocument struct {
+	Context            any                     `json:"@context"`
+	ID                 string                  `json:"id"`
+	Controller         any                     `json:"controller,omitempty"`
+	VerificationMethod []DIDVerificationMethod `json:"verificationMethod"`
+	Authentication     []any                   `json:"authentication"`
+	AssertionMethod    []any                   `json:"assertionMethod"`
+	Service            []DIDService            `json:"service,omitempty"`
+}
+
+// DIDVerificationMethod is a public key of a DID Document, in any of the encodings
+// DID methods use
+type DIDVerificationMethod struct {
+	ID                 string         `json:"id"`
+	Type               string         `json:"type"`
+	Controller         string         `json:"controller"`
+	PublicKeyHex       string         `json:"publicKeyHex,omitempty"`
+	PublicKeyMultibase string         `json:"publicKeyMultibase,omitempty"`
+	PublicKeyJwk       map[string]any `json:"publicKeyJwk,omitempty"`
+}
+
+// DIDService is a service endpoint of a DID Document
+type DIDService struct {
+	ID              string `json:"id"`
+	Type            any    `json:"type"`
+	ServiceEndpoint any    `json:"serviceEndpoint"`
+}
+
+// DIDResolution is a resolved DID Document with its metadata
+type DIDResolution struct {
+	Document *DIDDocument `json:"didDocument"`
+	Metadata struct {
+		Status       string `json:"status"`
+		Deactivated  bool   `json:"deactivated"`
+		BlockchainTx string `json:"blockchainTx"`
+	} `json:"didDocumentMetadata"`
+	// Source tells where the document came from
+	Source string `json:"-"`
+}
+
+// DIDAnchoring is the on-chain state of a DID reported by the status endpoint
+type DIDAnchoring struct {
+	IsValid       bool   `json:"is_valid"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	BlockchainTx  string `json:"blockchain_tx"`
+	Assurance     string `json:"assurance"`
+	Confirmations uint64 `json:"confirmations"`
+	Warning       string `json:"warning"`
+}
+
+const (
+	contextDIDCore = "https://www.w3.org/ns/did/v1"
+	// ed25519Multicodec prefixes Ed25519 public keys in did:key identifiers
+	ed25519Multicodec = "\xed\x01"
+	// x25519Multicodec prefixes X25519 keyAgreement keys in publicKeyMultibase
+	x25519Multicodec = "\xec\x01"
+)
+
+// ResolveDID resolves a DID: did:key DIDs are expanded locally, did:web documents are
+// fetched from their domain, and every other method is resolved by the service
+func (c *DIDClient) ResolveDID(did string) (*DIDResolution, error) {
+	switch {
+	case strings.HasPrefix(did, "did:key:"):
+		document, err := resolveDIDKey(did)
+		if err != nil {
+			return nil, err
+		}
+		return &DIDResolution{Document: document, Source: "local (did:key)"}, nil
+	case strings.HasPrefix(did, "did:web:"):
+		document, source, err := c.resolveDIDWeb(did)
+		if err != nil {
+			return nil, err
+		}
+		return &DIDResolution{Document: document, Source: source}, nil
+	}
+
+	var resp struct {
+		Data DIDResolution `json:"data"`
+	}
+	if err := c.do(http.MethodGet, "/api/v1/did/resolve/"+did, nil, nil, nil, http.StatusOK, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Document == nil {
+		return nil, errors.New("resolution returned no DID Document")
+	}
+	resp.Data.Source = c.baseURL
+	return &resp.Data, nil
+}
+
+// CheckAnchoring asks the service for the on-chain state of a DID
+func (c *DIDClient) CheckAnchoring(did string) (*DIDAnchoring, error) {
+	var resp struct {
+		Data DIDAnchoring `json:"data"`
+	}
+	if err := c.do(http.MethodGet, "/api/v1/did/status/"+did, nil, nil, nil, http.StatusOK, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// resolveDIDKey expands an Ed25519 did:key into its DID Document. The identifier is
+// the key itself, so the document needs no other proof.
+func resolveDIDKey(did string) (*DIDDocument, error) {
+	fingerprint := strings.TrimPrefix(did, "did:key:")
+	if !strings.HasPrefix(fingerprint, "z") {
+		return nil, errors.New("did:key identifier is not base58btc multibase")
+	}
+	decoded, err := decodeBase58(fingerprint[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid did:key identifier: %w", err)
+	}
+	if !strings.HasPrefix(string(decoded), ed25519Multicodec) || len(decoded) != len(ed25519Multicodec)+ed25519.PublicKeySize {
+		return nil, errors.New("unsupported did:key key type, only Ed25519 keys are resolved")
+	}
+
+	keyID := did + "#" + fingerprint
+	return &DIDDocument{
+		Context: []any{contextDIDCore, "https://w3id.org/security/suites/ed25519-2020/v1"},
+		ID:      did,
+		VerificationMethod: []DIDVerificationMethod{{
+			ID:                 keyID,
+			Type:               "Ed25519VerificationKey2020",
+			Controller:         did,
+			PublicKeyMultibase: fingerprint,
+		}},
+		Authentication:  []any{keyID},
+		AssertionMethod: []any{keyID},
+	}, nil
+}
+
+// resolveDIDWeb fetches a did:web document over HTTPS: from /.well-known/did.json for
+// a bare domain, or from did.json under the path the DID names
+func (c *DIDClient) resolveDIDWeb(did string) (*DIDDocument, string, error) {
+	segments := strings.Split(strings.TrimPrefix(did, "did:web:"), ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil || host == "" {
+		return nil, "", errors.New("invalid did:web domain")
+	}
+	target := "https://" + host + "/.well-known/did.json"
+	if len(segments) > 1 {
+		target = "https://" + host + "/" + strings.Join(segments[1:], "/") + "/did.json"
+	}
+
+	resp, err := c.httpClient.Get(target)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s: unexpected status code: %d", target, resp.StatusCode)
+	}
+
+	var document DIDDocument
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, "", fmt.Errorf("%s is not a DID Document: %w", target, err)
+	}
+	return &document, target, nil
+}
+
+// ValidateDocument checks a DID Document's structure and key material, returning the
+// problems found. For did:key DIDs the keys must match the identifier.
+func ValidateDocument(did string, document *DIDDocument) []string {
+	var problems []string
+	if !hasContext(document.Context, contextDIDCore) {
+		problems = append(problems, "@context does not include "+contextDIDCore)
+	}
+	if document.ID != did {
+		problems = append(problems, fmt.Sprintf("document id %q does not match the DID", document.ID))
+	}
+	if len(document.VerificationMethod) == 0 {
+		problems = append(problems, "document has no verification methods")
+	}
+
+	methods := make(map[string]bool, len(document.VerificationMethod))
+	for _, method := range document.VerificationMethod {
+		methods[method.ID] = true
+		if method.ID == "" || method.Type == "" || method.Controller == "" {
+			problems = append(problems, fmt.Sprintf("verification method %q lacks an id, type or controller", method.ID))
+		}
+		if _, _, err := methodKey(method); err != nil {
+			problems = append(problems, fmt.Sprintf("verification method %q: %v", method.ID, err))
+		}
+		if strings.HasPrefix(did, "did:key:") && method.PublicKeyMultibase != strings.TrimPrefix(did, "did:key:") {
+			problems = append(problems, fmt.Sprintf("verification method %q does not carry the did:key key", method.ID))
+		}
+	}
+
+	for relationship, references := range map[string][]any{
+		"authentication":  document.Authentication,
+		"assertionMethod": document.AssertionMethod,
+	} {
+		for _, reference := range references {
+			// Embedded verification methods are valid; references must resolve
+			if id, ok := reference.(string); ok && !methods[id] {
+				problems = append(problems, fmt.Sprintf("%s references unknown verification method %q", relationship, id))
+			}
+		}
+	}
+
+	for _, service := range document.Service {
+		if service.ID == "" || service.Type == nil || service.ServiceEndpoint == nil {
+			problems = append(problems, fmt.Sprintf("service %q lacks an id, type or serviceEndpoint", service.ID))
+		}
+	}
+	return problems
+}
+
+// hasContext reports whether a JSON-LD @context, a string or an array, includes want
+func hasContext(context any, want string) bool {
+	switch value := context.(type) {
+	case string:
+		return value == want
+	case []any:
+		for _, entry := range value {
+			if entry == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// methodKey decodes the public key of a verification method, returning its encoding
+// and key bytes. Ed25519 keys must be 32 bytes.
+func methodKey(method DIDVerificationMethod) (string, []byte, error) {
+	var encoding string
+	var key []byte
+	switch {
+	case method.PublicKeyHex != "":
+		decoded, err := hex.DecodeString(method.PublicKeyHex)
+		if err != nil {
+			return "", nil, errors.New("publicKeyHex is not hex")
+		}
+		encoding, key = "publicKeyHex", decoded
+	case method.PublicKeyMultibase != "":
+		if !strings.HasPrefix(method.PublicKeyMultibase, "z") {
+			return "", nil, errors.New("publicKeyMultibase is not base58btc")
+		}
+		decoded, err := decodeBase58(method.PublicKeyMultibase[1:])
+		if err != nil {
+			return "", nil, fmt.Errorf("publicKeyMultibase: %w", err)
+		}
+		key = []byte(strings.TrimPrefix(string(decoded), ed25519Multicodec))
+		if method.Type == "X25519KeyAgreementKey2020" {
+			key = []byte(strings.TrimPrefix(string(decoded), x25519Multicodec))
+		}
+		encoding = "publicKeyMultibase"
+	case method.PublicKeyJwk != nil:
+		kty, _ := method.PublicKeyJwk["kty"].(string)
+		x, _ := method.PublicKeyJwk["x"].(string)
+		if kty == "" || x == "" {
+			return "", nil, errors.New("publicKeyJwk lacks kty or x")
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(x, "="))
+		if err != nil {
+			return "", nil, errors.New("publicKeyJwk x is not base64url")
+		}
+		encoding, key = "publicKeyJwk ("+kty+")", decoded
+	default:
+		return "", nil, errors.New("no public key")
+	}
+
+	if strings.HasPrefix(method.Type, "Ed25519") && len(key) != ed25519.PublicKeySize {
+		return "", nil, fmt.Errorf("Ed25519 key is %d bytes, expected %d", len(key), ed25519.PublicKeySize)
+	}
+	return encoding, key, nil
+}
+
+// base58Alphabet is the Bitcoin base58 alphabet used by base58btc multibase
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes Bitcoin base58
+func decodeBase58(encoded string) ([]byte, error) {
+	decoded := []byte{}
+	for _, r := range encoded {
+		carry := strings.IndexRune(base58Alphabet, r)
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		for i := len(decoded) - 1; i >= 0; i-- {
+			carry += int(decoded[i]) * 58
+			decoded[i] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			decoded = append([]byte{byte(carry)}, decoded...)
+		}
+	}
+	// Leading 1s encode leading zero bytes
+	zeros := len(encoded) - len(strings.TrimLeft(encoded, "1"))
+	return append(make([]byte, zeros), decoded...), nil
+}
+
+// printResolution prints a resolved document's keys and services with the problems found
+func printResolution(resolution *DIDResolution, problems []string) {
+	document := resolution.Document
+	fmt.Printf("DID: %s\n", document.ID)
+	fmt.Printf("Resolved from: %s\n", resolution.Source)
+	if resolution.Metadata.Status != "" {
+		fmt.Printf("Status: %s\n", resolution.Metadata.Status)
+	}
+	if resolution.Metadata.Deactivated {
+		fmt.Println("Deactivated: true")
+	}
+	if controller, ok := document.Controller.(string); ok && controller != "" {
+		fmt.Printf("Controller: %s\n", controller)
+	}
+
+	fmt.Println("\nVerification methods:")
+	for _, method := range document.VerificationMethod {
+		fmt.Printf("  %s\n", method.ID)
+		fmt.Printf("    Type: %s\n", method.Type)
+		if encoding, key, err := methodKey(method); err == nil {
+			fmt.Printf("    Key: %s (%d bytes, %s)\n", hex.EncodeToString(key), len(key), encoding)
+		}
+	}
+	fmt.Printf("  Authentication: %s\n", formatReferences(document.Authentication))
+	fmt.Printf("  Assertion: %s\n", formatReferences(document.AssertionMethod))
+
+	if len(document.Service) > 0 {
+		fmt.Println("\nServices:")
+		for _, service := range document.Service {
+			fmt.Printf("  %s (%v): %v\n", service.ID, service.Type, service.ServiceEndpoint)
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("\n✓ Document structure and key material are valid")
+		return
+	}
+	fmt.Println("\n✗ Document is invalid:")
+	for _, problem := range problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+}
+
+// formatReferences lists the verification method references of a relationship
+func formatReferences(references []any) string {
+	if len(references) == 0 {
+		return "none"
+	}
+	ids := make([]string, 0, len(references))
+	for _, reference := range references {
+		if id, ok := reference.(string); ok {
+			ids = append(ids, id)
+		} else {
+			ids = append(ids, "(embedded)")
+		}
+	}
+	return strings.Join(ids, ", ")
+}
+
+// adminUsage lists the admin subcommands
+const adminUsage = `Usage: go run did-cli.go admin <command> [args]
+Tenants are API keys; the tenant of a key is its ID. Results are printed as JSON.
+  tenant create <name>                         - Create a tenant, printing its API key once
+  sandbox create <name>                        - Create a sandbox tenant, printing its API key once
+  sandbox reset <tenant>                       - Delete the DIDs and credentials of a sandbox tenant
+  keys list                                    - List API keys
+  keys rotate <id>                             - Replace a key's secret, printing the new key once
+  keys revoke <id>                             - Revoke a key
+  quota list                                   - List monthly quotas
+  quota set <tenant|default> <event> <limit>   - Limit a tenant's did_created, did_verification or credential_issued events per month
+  quota delete <tenant|default> <event>        - Remove a quota
+  user suspend <userID> <reason>               - Suspend a user's active DIDs
+  user unsuspend <userID>                      - Reinstate a user's suspended DIDs
+  webhook list                                 - List the webhooks of DID_MANAGER_API_KEY's tenant
+  webhook create <url> [event types...]        - Add a webhook for DID_MANAGER_API_KEY's tenant, printing its secret once
+  webhook delete <id>                          - Remove a webhook
+  webhook rotate-secret <id>                   - Replace a webhook's signing secret
+  events replay <tenant> <since> [types...]    - Re-deliver a tenant's events since an RFC 3339 time to its webhooks
+  anchoring status                             - Show whether anchoring is paused and how many jobs are not yet anchored
+  anchoring pause <reason>                     - Stop submitting blockchain jobs on every instance; DIDs are still queued
+  anchoring resume                             - Submit the queued blockchain jobs again`
+
+// RunAdmin runs an admin subcommand and returns the data of its response. Tenant,
+// sandbox, key, quota, user, event replay and anchoring commands need the admin key;
+// webhooks belong to a tenant and are managed with its API key.
+func (c *DIDClient) RunAdmin(adminKey, apiKey string, args []string) (json.RawMessage, error) {
+	if len(args) < 2 {
+		return nil, errors.New(adminUsage)
+	}
+	admin := map[string]string{"X-Admin-Key": adminKey}
+	tenant := map[string]string{"X-API-Key": apiKey}
+	if args[0] == "webhook" {
+		if apiKey == "" {
+			return nil, errors.New("DID_MANAGER_API_KEY is required to manage a tenant's webhooks")
+		}
+	} else if adminKey == "" {
+		return nil, errors.New("DID_MANAGER_ADMIN_KEY is required for admin commands")
+	}
+
+	var resp struct {
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+	}
+	var err error
+	switch command := args[0] + " " + args[1]; {
+	case command == "tenant create" && len(args) == 3:
+		err = c.do(http.MethodPost, "/api/v1/admin/api-keys", map[string]string{"name": args[2]}, nil, admin, http.StatusCreated, &resp)
+	case command == "sandbox create" && len(args) == 3:
+		body := map[string]any{"name": args[2], "sandbox": true}
+		err = c.do(http.MethodPost, "/api/v1/admin/api-keys", body, nil, admin, http.StatusCreated, &resp)
+	case command == "sandbox reset" && len(args) == 3:
+		err = c.do(http.MethodPost, "/api/v1/admin/sandboxes/"+url.PathEscape(args[2])+"/reset", nil, nil, admin, http.StatusOK, &resp)
+	case command == "keys list":
+		err = c.do(http.MethodGet, "/api/v1/admin/api-keys", nil, nil, admin, http.StatusOK, &resp)
+	case command == "keys rotate" && len(args) == 3:
+		err = c.do(http.MethodPost, "/api/v1/admin/api-keys/"+args[2]+"/rotate", nil, nil, admin, http.StatusOK, &resp)
+	case command == "keys revoke" && len(args) == 3:
+		err = c.do(http.MethodDelete, "/api/v1/admin/api-keys/"+args[2], nil, nil, admin, http.StatusOK, &resp)
+	case command == "quota list":
+		err = c.do(http.MethodGet, "/api/v1/admin/quotas", nil, nil, admin, http.StatusOK, &resp)
+	case command == "quota set" && len(args) == 5:
+		limit, convErr := strconv.Atoi(args[4])
+		if convErr != nil {
+			return nil, fmt.Errorf("invalid monthly limit %q", args[4])
+		}
+		body := map[string]any{"tenant": quotaTenant(args[2]), "event": args[3], "monthly_limit": limit}
+		err = c.do(http.MethodPut, "/api/v1/admin/quotas", body, nil, admin, http.StatusOK, &resp)
+	case command == "quota delete" && len(args) == 4:
+		query := url.Values{"tenant": {quotaTenant(args[2])}, "event": {args[3]}}
+		err = c.do(http.MethodDelete, "/api/v1/admin/quotas?"+query.Encode(), nil, nil, admin, http.StatusOK, &resp)
+	case command == "user suspend" && len(args) >= 4:
+		body := map[string]string{"reason": strings.Join(args[3:], " ")}
+		err = c.do(http.MethodPost, "/api/v1/admin/users/"+args[2]+"/suspension", body, nil, admin, http.StatusOK, &resp)
+	case command == "user unsuspend" && len(args) == 3:
+		err = c.do(http.MethodDelete, "/api/v1/admin/users/"+args[2]+"/suspension", nil, nil, admin, http.StatusOK, &resp)
+	case command == "webhook list":
+		err = c.do(http.MethodGet, "/api/v1/webhooks", nil, nil, tenant, http.StatusOK, &resp)
+	case command == "webhook create" && len(args) >= 3:
+		body := map[string]any{"url": args[2], "event_types": args[3:]}
+		err = c.do(http.MethodPost, "/api/v1/webhooks", body, nil, tenant, http.StatusCreated, &resp)
+	case command == "webhook delete" && len(args) == 3:
+		err = c.do(http.MethodDelete, "/api/v1/webhooks/"+args[2], nil, nil, tenant, http.StatusOK, &resp)
+	case command == "webhook rotate-secret" && len(args) == 3:
+		err = c.do(http.MethodPost, "/api/v1/webhooks/"+args[2]+"/secret", nil, nil, tenant, http.StatusOK, &resp)
+	case command == "events replay" && len(args) >= 4:
+		if _, parseErr := time.Parse(time.RFC3339, args[3]); parseErr != nil {
+			return nil, fmt.Errorf("invalid since time %q, expected RFC 3339", args[3])
+		}
+		body := map[string]any{"tenant": args[2], "since": args[3], "event_types": args[4:]}
+		err = c.do(http.MethodPost, "/api/v1/admin/events/replay", body, nil, admin, http.StatusAccepted, &resp)
+	case command == "anchoring status":
+		err = c.do(http.MethodGet, "/api/v1/admin/anchoring", nil, nil, admin, http.StatusOK, &resp)
+	case command == "anchoring pause" && len(args) >= 3:
+		body := map[string]string{"reason": strings.Join(args[2:], " ")}
+		err = c.do(http.MethodPut, "/api/v1/admin/anchoring/pause", body, nil, admin, http.StatusOK, &resp)
+	case command == "anchoring resume":
+		err = c.do(http.MethodDelete, "/api/v1/admin/anchoring/pause", nil, nil, admin, http.StatusOK, &resp)
+	default:
+		return nil, errors.New(adminUsage)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Data == nil {
+		// Deletions only answer with a message
+		return json.Marshal(map[string]string{"message": resp.Message})
+	}
+	return resp.Data, nil
+}
+
+// quotaTenant maps the default keyword to the empty tenant of the default quota
+func quotaTenant(tenant string) string {
+	if tenant == "default" {
+		return ""
+	}
+	return tenant
+}
+
+// Profile is a named DID Manager environment of the config file, such as dev, stage or
+// prod. The config file is shared with the Go client of did-manager/pkg/client.
+type Profile struct {
+	Name     string `json:"-"`
+	URL      string `json:"url"`
+	APIKey   string `json:"api_key,omitempty"`
+	AdminKey string `json:"admin_key,omitempty"`
+	Tenant   string `json:"tenant,omitempty"`
+	Chain    string `json:"chain,omitempty"`
+	// Protected makes writing commands ask for the profile name before running
+	Protected bool `json:"protected,omitempty"`
+}
+
+// configPath returns the path of the profiles config file: DID_MANAGER_CONFIG, or
+// did-manager/config.json in the user's config directory
+func configPath() (string, error) {
+	if path := os.Getenv("DID_MANAGER_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return dir + "/did-manager/config.json", nil
+}
+
+// loadProfile reads the named profile of the config file at path, or its default
+// profile when name is empty. It returns nil without an error when there is neither.
+func loadProfile(path, name string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && name == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config struct {
+		DefaultProfile string              `json:"default_profile"`
+		Profiles       map[string]*Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	if name == "" {
+		name = config.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("unknown profile %q in %s", name, path)
+	}
+	if profile.URL == "" {
+		return nil, fmt.Errorf("profile %q in %s has no url", name, path)
+	}
+	profile.Name = name
+	return profile, nil
+}
+
+// globalFlags removes the --profile <name> (or --profile=<name>) and --yes flags from
+// args, which may appear anywhere on the command line
+func globalFlags(args []string) (profile string, yes bool, rest []string, err error) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--profile":
+			if i+1 == len(args) {
+				return "", false, nil, errors.New("--profile needs a profile name")
+			}
+			profile = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--profile="):
+			profile = strings.TrimPrefix(arg, "--profile=")
+		case arg == "--yes":
+			yes = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return profile, yes, rest, nil
+}
+
+// settings are the service URL and keys the CLI runs with
+type settings struct {
+	baseURL  string
+	adminKey string
+	apiKey   string
+}
+
+// resolveSettings combines a profile with the environment. A profile chosen with
+// --profile wins over DID_MANAGER_URL and the key variables, so a stray production
+// variable cannot redirect it; the default profile only fills in unset variables.
+func resolveSettings(profile *Profile, explicit bool, getenv func(string) string) settings {
+	s := settings{
+		baseURL:  getenv("DID_MANAGER_URL"),
+		adminKey: getenv("DID_MANAGER_ADMIN_KEY"),
+		apiKey:   getenv("DID_MANAGER_API_KEY"),
+	}
+	if profile != nil {
+		pick := func(env, value string) string {
+			if value != "" && (explicit || env == "") {
+				return value
+			}
+			return env
+		}
+		s.baseURL = pick(s.baseURL, profile.URL)
+		s.adminKey = pick(s.adminKey, profile.AdminKey)
+		s.apiKey = pick(s.apiKey, profile.APIKey)
+	}
+	if s.baseURL == "" {
+		s.baseURL = "http://localhost:8082"
+	}
+	return s
+}
+
+// writingCommands change the state of the service
+var writingCommands = map[string]bool{
+	"create":   true,
+	"demo":     true,
+	"selftest": true,
+	"cancel":   true,
+	"admin":    true,
+}
+
+// confirmProtected asks for the name of a protected profile before a writing command
+// runs against it, unless --yes was given
+func confirmProtected(profile *Profile, command string, yes bool, in io.Reader) error {
+	if profile == nil || !profile.Protected || !writingCommands[command] || yes {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Profile %s (%s) is protected. Type the profile name to run %s: ", profile.Name, profile.URL, command)
+	var answer string
+	fmt.Fscanln(in, &answer)
+	if answer != profile.Name {
+		return fmt.Errorf("not confirmed, %s against profile %s canceled", command, profile.Name)
+	}
+	return nil
+}
+
 func main() {
+	profileName, yes, args, err := globalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: go run did-cli.go <command> [options]")
 		fmt.Println("Commands:")
@@ -191,15 +1108,59 @@ func main() {
 		fmt.Println("  create <name> <email>     - Create a new DID")
 		fmt.Println("  verify <did> <userHash>   - Verify a DID")
 		fmt.Println("  status <did>              - Get DID status")
+		fmt.Println("  resolve <did> [--chain] [--json] - Resolve and validate a DID Document; did:key and did:web resolve locally")
 		fmt.Println("  demo                      - Run a complete demo workflow")
+		fmt.Println("  selftest                  - Smoke test a deployment: create, queue, anchor (simulated), verify, revoke")
+		fmt.Println("  cancel <jobID> [reason]   - Cancel a blockchain job, or revoke its DID if already broadcast")
+		fmt.Println("  admin <command> [args]    - Manage tenants, API keys, quotas, webhooks, DID suspensions and anchoring (run admin for the list)")
+		fmt.Println("Options:")
+		fmt.Println("  --profile <name>          - Use a profile of the config file instead of the environment")
+		fmt.Println("  --yes                     - Skip the confirmation of writing commands on protected profiles")
+		fmt.Println("Environment:")
+		fmt.Println("  DID_MANAGER_URL           - Service base URL (default http://localhost:8082)")
+		fmt.Println("  DID_MANAGER_ADMIN_KEY     - Admin key for cancel, admin and the simulated anchoring step of selftest")
+		fmt.Println("  DID_MANAGER_API_KEY       - Tenant API key for admin webhook commands")
+		fmt.Println("  DID_MANAGER_PROFILE       - Profile used without --profile")
+		fmt.Println("  DID_MANAGER_CONFIG        - Profiles config file (default did-manager/config.json in the user config directory)")
 		return
 	}
 
-	// Initialize client
-	client := NewDIDClient("http://localhost:8082")
-
 	command := os.Args[1]
 
+	// Initialize client
+	explicit := profileName != ""
+	if !explicit {
+		profileName = os.Getenv("DID_MANAGER_PROFILE")
+		explicit = profileName != ""
+	}
+	path, err := configPath()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	profile, err := loadProfile(path, profileName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	config := resolveSettings(profile, explicit, os.Getenv)
+	if profile != nil {
+		fmt.Fprintf(os.Stderr, "Profile: %s (%s", profile.Name, config.baseURL)
+		if profile.Chain != "" {
+			fmt.Fprintf(os.Stderr, ", chain %s", profile.Chain)
+		}
+		if profile.Tenant != "" {
+			fmt.Fprintf(os.Stderr, ", tenant %s", profile.Tenant)
+		}
+		fmt.Fprintln(os.Stderr, ")")
+	}
+	if err := confirmProtected(profile, command, yes, os.Stdin); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	baseURL := config.baseURL
+	client := NewDIDClient(baseURL)
+
 	switch command {
 	case "health":
 		if err := client.HealthCheck(); err != nil {
@@ -273,6 +1234,64 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "resolve":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: go run did-cli.go resolve <did> [--chain] [--json]")
+			os.Exit(1)
+		}
+		did := os.Args[2]
+		var checkChain, printJSON bool
+		for _, flag := range os.Args[3:] {
+			switch flag {
+			case "--chain":
+				checkChain = true
+			case "--json":
+				printJSON = true
+			default:
+				fmt.Printf("Unknown resolve option: %s\n", flag)
+				os.Exit(1)
+			}
+		}
+
+		resolution, err := client.ResolveDID(did)
+		if err != nil {
+			fmt.Printf("Failed to resolve DID: %v\n", err)
+			os.Exit(1)
+		}
+
+		problems := ValidateDocument(did, resolution.Document)
+		if printJSON {
+			out, _ := json.MarshalIndent(resolution.Document, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			printResolution(resolution, problems)
+		}
+
+		if checkChain {
+			anchoring, err := client.CheckAnchoring(did)
+			if err != nil {
+				fmt.Printf("\n✗ Failed to check anchoring: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\nAnchoring: %s (assurance %s)\n", anchoring.Status, anchoring.Assurance)
+			if anchoring.BlockchainTx != "" {
+				fmt.Printf("  Blockchain TX: %s\n", anchoring.BlockchainTx)
+			}
+			if anchoring.Confirmations > 0 {
+				fmt.Printf("  Confirmations: %d\n", anchoring.Confirmations)
+			}
+			if anchoring.Warning != "" {
+				fmt.Printf("  Warning: %s\n", anchoring.Warning)
+			}
+			if !anchoring.IsValid {
+				fmt.Printf("✗ DID is not anchored: %s\n", anchoring.Message)
+				os.Exit(1)
+			}
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+
 	case "demo":
 		fmt.Println("Running complete DID workflow demo...")
 		fmt.Println("=====================================")
@@ -307,8 +1326,9 @@ func main() {
 		// Step 3: Verify DID
 		fmt.Println("\n3. Verifying the created DID...")
 		verifyReq := &DIDVerificationRequest{
-			DID:      resp.Data.DID.Did,
-			UserHash: resp.Data.UserHash,
+			DID:              resp.Data.DID.Did,
+			UserHash:         resp.Data.UserHash,
+			ConsistencyToken: resp.Data.ConsistencyToken,
 		}
 
 		verifyResp, err := client.VerifyDID(verifyReq)
@@ -332,6 +1352,57 @@ func main() {
 		fmt.Println("\n=====================================")
 		fmt.Println("Demo completed successfully!")
 
+	case "selftest":
+		fmt.Printf("Running self-test against %s...\n", baseURL)
+		fmt.Println("=====================================")
+
+		if err := client.SelfTest(config.adminKey); err != nil {
+			fmt.Printf("\n✗ Self-test failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("\n=====================================")
+		fmt.Println("Self-test passed!")
+
+	case "cancel":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: go run did-cli.go cancel <jobID> [reason]")
+			os.Exit(1)
+		}
+		adminKey := config.adminKey
+		if adminKey == "" {
+			fmt.Println("DID_MANAGER_ADMIN_KEY is required to cancel jobs")
+			os.Exit(1)
+		}
+		reason := strings.Join(os.Args[3:], " ")
+
+		cancellation, err := client.CancelJob(adminKey, os.Args[2], reason)
+		if err != nil {
+			fmt.Printf("Failed to cancel job: %v\n", err)
+			os.Exit(1)
+		}
+
+		job := cancellation.Job
+		if cancellation.Canceled {
+			fmt.Printf("Job %s (%s of %s) canceled before broadcast\n", job.ID, job.JobType, job.DID)
+		} else if compensating := cancellation.CompensatingJob; compensating != nil {
+			fmt.Printf("Job %s (%s of %s) was already broadcast\n", job.ID, job.JobType, job.DID)
+			fmt.Printf("Compensating job %s queued to revoke the DID (%s)\n", compensating.ID, compensating.Status)
+		}
+
+	case "admin":
+		data, err := client.RunAdmin(config.adminKey, config.apiKey, os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			fmt.Println(string(data))
+			break
+		}
+		fmt.Println(out.String())
+
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
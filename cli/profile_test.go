@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlobalFlags(t *testing.T) {
+	profile, yes, rest, err := globalFlags([]string{"--profile", "prod", "admin", "tenant", "list", "--yes"})
+	if err != nil {
+		t.Fatalf("globalFlags failed: %v", err)
+	}
+	if profile != "prod" || !yes || !reflect.DeepEqual(rest, []string{"admin", "tenant", "list"}) {
+		t.Errorf("got profile %q, yes %t, args %v", profile, yes, rest)
+	}
+
+	profile, _, rest, _ = globalFlags([]string{"resolve", "did:example:1", "--chain", "--profile=stage"})
+	if profile != "stage" || !reflect.DeepEqual(rest, []string{"resolve", "did:example:1", "--chain"}) {
+		t.Errorf("got profile %q, args %v", profile, rest)
+	}
+
+	if _, _, _, err := globalFlags([]string{"health", "--profile"}); err == nil {
+		t.Error("expected --profile without a name to fail")
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+		"default_profile": "dev",
+		"profiles": {
+			"dev": {"url": "http://localhost:8082"},
+			"prod": {"url": "https://did.example.com", "admin_key": "prod-admin", "chain": "polygon", "protected": true}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if profile, err := loadProfile(path, ""); err != nil || profile.Name != "dev" {
+		t.Errorf("expected the default dev profile, got %+v, %v", profile, err)
+	}
+	if profile, err := loadProfile(path, "prod"); err != nil || profile.AdminKey != "prod-admin" || !profile.Protected {
+		t.Errorf("expected the prod profile, got %+v, %v", profile, err)
+	}
+	if _, err := loadProfile(path, "stage"); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if profile, err := loadProfile(missing, ""); profile != nil || err != nil {
+		t.Errorf("expected no profile without a config file, got %+v, %v", profile, err)
+	}
+	if _, err := loadProfile(missing, "prod"); err == nil {
+		t.Error("expected a named profile without a config file to fail")
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	env := map[string]string{"DID_MANAGER_URL": "https://did.example.com", "DID_MANAGER_API_KEY": "dm_env"}
+	getenv := func(name string) string { return env[name] }
+	dev := &Profile{Name: "dev", URL: "http://localhost:8082", AdminKey: "dev-admin"}
+
+	explicit := resolveSettings(dev, true, getenv)
+	if explicit.baseURL != dev.URL || explicit.adminKey != "dev-admin" || explicit.apiKey != "dm_env" {
+		t.Errorf("expected --profile to win over the environment, got %+v", explicit)
+	}
+
+	fallback := resolveSettings(dev, false, getenv)
+	if fallback.baseURL != "https://did.example.com" || fallback.adminKey != "dev-admin" {
+		t.Errorf("expected the default profile to fill in unset variables only, got %+v", fallback)
+	}
+
+	if none := resolveSettings(nil, false, func(string) string { return "" }); none.baseURL != "http://localhost:8082" {
+		t.Errorf("expected the default URL, got %+v", none)
+	}
+}
+
+func TestConfirmProtected(t *testing.T) {
+	prod := &Profile{Name: "prod", URL: "https://did.example.com", Protected: true}
+
+	if err := confirmProtected(prod, "admin", false, strings.NewReader("prod\n")); err != nil {
+		t.Errorf("expected the typed profile name to confirm, got %v", err)
+	}
+	if err := confirmProtected(prod, "admin", false, strings.NewReader("dev\n")); err == nil {
+		t.Error("expected a wrong profile name to cancel")
+	}
+	if err := confirmProtected(prod, "admin", true, strings.NewReader("")); err != nil {
+		t.Errorf("expected --yes to skip the confirmation, got %v", err)
+	}
+	if err := confirmProtected(prod, "status", false, strings.NewReader("")); err != nil {
+		t.Errorf("expected reads to need no confirmation, got %v", err)
+	}
+}
@@ -18,6 +18,7 @@ contract DIDRegistry is Ownable, ReentrancyGuard {
     event DIDUpdated(bytes32 indexed userHash, string did, uint256 timestamp);
     event DIDRevoked(bytes32 indexed userHash, string did, uint256 timestamp);
     event AdminChanged(address indexed previousAdmin, address indexed newAdmin);
+    event DocumentNotarized(bytes32 indexed documentHash, string did, uint256 timestamp);
 
     // Structs
     struct DIDRecord {
@@ -33,6 +34,8 @@ contract DIDRegistry is Ownable, ReentrancyGuard {
     mapping(bytes32 => DIDRecord) public didRecords;
     mapping(string => bytes32) public didToUserHash;
     mapping(address => bool) public authorizedOperators;
+    // Document hash => DIDs that notarized it => notarization time
+    mapping(bytes32 => mapping(string => uint256)) public notarizations;
     
     uint256 public totalDIDs;
     uint256 public activeDIDs;
@@ -280,6 +283,33 @@ contract DIDRegistry is Ownable, ReentrancyGuard {
         emit AdminChanged(operator, address(0));
     }
 
+    /**
+     * @dev Notarize a document hash on behalf of a DID
+     * @param documentHash SHA-256 of the document
+     * @param did The DID that signed the document hash
+     */
+    function notarize(bytes32 documentHash, string calldata did) external onlyAuthorized {
+        require(documentHash != bytes32(0), "DIDRegistry: document hash cannot be empty");
+        require(
+            notarizations[documentHash][did] == 0,
+            "DIDRegistry: document already notarized by this DID"
+        );
+
+        notarizations[documentHash][did] = block.timestamp;
+
+        emit DocumentNotarized(documentHash, did, block.timestamp);
+    }
+
+    /**
+     * @dev Get when a DID notarized a document hash
+     * @param documentHash SHA-256 of the document
+     * @param did The notarizing DID
+     * @return timestamp Notarization block timestamp, zero if never notarized
+     */
+    function getNotarization(bytes32 documentHash, string calldata did) external view returns (uint256 timestamp) {
+        return notarizations[documentHash][did];
+    }
+
     /**
      * @dev Get contract statistics
      * @return total Total number of DIDs
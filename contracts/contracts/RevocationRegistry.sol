@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.19;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+ * @title RevocationRegistry
+ * @dev Anchors the roots of the credential revocation accumulator, a 256-level sparse
+ * Merkle tree over keccak256 in which revoked credentials hold a leaf of 1 and empty
+ * subtrees hash to zero. Each published root covers every revocation up to its batch,
+ * so verifiers can check (non-)revocation proofs against the chain.
+ */
+contract RevocationRegistry is Ownable {
+    // Events
+    event RootPublished(uint256 indexed batch, bytes32 root, uint256 revokedCount, uint256 timestamp);
+
+    // Structs
+    struct RootRecord {
+        bytes32 root;
+        uint256 revokedCount;
+        uint256 publishedAt;
+    }
+
+    // State variables
+    mapping(uint256 => RootRecord) public roots;
+    mapping(address => bool) public authorizedPublishers;
+    uint256 public latestBatch;
+
+    bytes32 private constant REVOKED_LEAF = bytes32(uint256(1));
+
+    // Modifiers
+    modifier onlyPublisher() {
+        require(
+            msg.sender == owner() || authorizedPublishers[msg.sender],
+            "RevocationRegistry: caller is not authorized"
+        );
+        _;
+    }
+
+    /**
+     * @dev Publish the accumulator root for a batch of revocations
+     * @param root Sparse Merkle root covering all revocations up to this batch
+     * @param batch Batch sequence number, strictly increasing
+     * @param revokedCount Number of revoked credentials in the tree
+     */
+    function publishRoot(bytes32 root, uint256 batch, uint256 revokedCount) external onlyPublisher {
+        require(batch > latestBatch, "RevocationRegistry: batch already published");
+
+        roots[batch] = RootRecord({
+            root: root,
+            revokedCount: revokedCount,
+            publishedAt: block.timestamp
+        });
+        latestBatch = batch;
+
+        emit RootPublished(batch, root, revokedCount, block.timestamp);
+    }
+
+    /**
+     * @dev Get the most recently published root
+     * @return root The latest root
+     * @return batch The batch it was published for
+     */
+    function latestRoot() external view returns (bytes32 root, uint256 batch) {
+        return (roots[latestBatch].root, latestBatch);
+    }
+
+    /**
+     * @dev Compute the root implied by a compressed sparse Merkle proof
+     * @param key keccak256 of the credential ID
+     * @param leaf REVOKED_LEAF for membership, zero for non-membership
+     * @param bitmap Bit i is set when the sibling at level i (from the leaves) is non-empty
+     * @param siblings The non-empty siblings, from the leaves upwards
+     */
+    function computeRoot(
+        bytes32 key,
+        bytes32 leaf,
+        bytes32 bitmap,
+        bytes32[] calldata siblings
+    ) public pure returns (bytes32) {
+        bytes32 node = leaf;
+        uint256 next = 0;
+
+        for (uint256 level = 0; level < 256; level++) {
+            bytes32 sibling;
+            if ((uint256(bitmap) >> level) & 1 == 1) {
+                require(next < siblings.length, "RevocationRegistry: malformed proof");
+                sibling = siblings[next++];
+            }
+
+            if ((uint256(key) >> level) & 1 == 0) {
+                node = _hashNode(node, sibling);
+            } else {
+                node = _hashNode(sibling, node);
+            }
+        }
+
+        require(next == siblings.length, "RevocationRegistry: malformed proof");
+        return node;
+    }
+
+    /**
+     * @dev Check that a credential was not revoked as of a published batch
+     * @param batch The batch whose root the proof was generated against
+     * @param key keccak256 of the credential ID
+     */
+    function verifyNonRevocation(
+        uint256 batch,
+        bytes32 key,
+        bytes32 bitmap,
+        bytes32[] calldata siblings
+    ) external view returns (bool) {
+        RootRecord storage record = roots[batch];
+        require(record.publishedAt > 0, "RevocationRegistry: unknown batch");
+        return computeRoot(key, bytes32(0), bitmap, siblings) == record.root;
+    }
+
+    /**
+     * @dev Check that a credential was revoked as of a published batch
+     * @param batch The batch whose root the proof was generated against
+     * @param key keccak256 of the credential ID
+     */
+    function verifyRevocation(
+        uint256 batch,
+        bytes32 key,
+        bytes32 bitmap,
+        bytes32[] calldata siblings
+    ) external view returns (bool) {
+        RootRecord storage record = roots[batch];
+        require(record.publishedAt > 0, "RevocationRegistry: unknown batch");
+        return computeRoot(key, REVOKED_LEAF, bitmap, siblings) == record.root;
+    }
+
+    /**
+     * @dev Authorize an address to publish roots
+     * @param publisher The address to authorize
+     */
+    function addAuthorizedPublisher(address publisher) external onlyOwner {
+        require(publisher != address(0), "RevocationRegistry: invalid publisher address");
+        authorizedPublishers[publisher] = true;
+    }
+
+    /**
+     * @dev Remove an authorized publisher
+     * @param publisher The address to remove
+     */
+    function removeAuthorizedPublisher(address publisher) external onlyOwner {
+        authorizedPublishers[publisher] = false;
+    }
+
+    /**
+     * @dev Hash two children; a node with two empty children is itself empty
+     */
+    function _hashNode(bytes32 left, bytes32 right) private pure returns (bytes32) {
+        if (left == bytes32(0) && right == bytes32(0)) {
+            return bytes32(0);
+        }
+        return keccak256(abi.encodePacked(left, right));
+    }
+}
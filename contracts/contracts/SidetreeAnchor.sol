@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.19;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+ * @title SidetreeAnchor
+ * @dev Anchors Sidetree-style batches of DID operations. Each batch is described by a
+ * core index file stored in IPFS; only the SHA-256 digest of that file and the number
+ * of operations it covers are written on-chain, so one transaction anchors the whole
+ * batch. Observers replay Anchor events in transaction number order.
+ */
+contract SidetreeAnchor is Ownable {
+    // Events
+    event Anchor(
+        bytes32 anchorFileHash,
+        uint256 indexed transactionNumber,
+        uint256 numberOfOperations,
+        address indexed writer
+    );
+
+    // State variables
+    mapping(address => bool) public authorizedWriters;
+    uint256 public transactionNumber;
+
+    // Modifiers
+    modifier onlyWriter() {
+        require(
+            msg.sender == owner() || authorizedWriters[msg.sender],
+            "SidetreeAnchor: caller is not authorized"
+        );
+        _;
+    }
+
+    /**
+     * @dev Anchor a batch
+     * @param anchorFileHash SHA-256 digest of the batch's core index file
+     * @param numberOfOperations Number of DID operations in the batch
+     */
+    function anchorHash(bytes32 anchorFileHash, uint256 numberOfOperations) external onlyWriter {
+        require(anchorFileHash != bytes32(0), "SidetreeAnchor: empty anchor file hash");
+        require(numberOfOperations > 0, "SidetreeAnchor: empty batch");
+
+        emit Anchor(anchorFileHash, transactionNumber, numberOfOperations, msg.sender);
+        transactionNumber++;
+    }
+
+    /**
+     * @dev Authorize an address to anchor batches
+     * @param writer The address to authorize
+     */
+    function addAuthorizedWriter(address writer) external onlyOwner {
+        require(writer != address(0), "SidetreeAnchor: invalid writer address");
+        authorizedWriters[writer] = true;
+    }
+
+    /**
+     * @dev Remove an authorized writer
+     * @param writer The address to remove
+     */
+    function removeAuthorizedWriter(address writer) external onlyOwner {
+        authorizedWriters[writer] = false;
+    }
+}
@@ -30,11 +30,29 @@ async function main() {
         revoked: stats.revoked.toString(),
     });
 
+    // Deploy the Revocation Registry contract anchoring credential revocation roots
+    console.log("Deploying Revocation Registry contract...");
+    const RevocationRegistry = await ethers.getContractFactory("RevocationRegistry");
+    const revocationRegistry = await RevocationRegistry.deploy();
+    await revocationRegistry.deployed();
+
+    console.log("Revocation Registry deployed to:", revocationRegistry.address);
+
+    // Deploy the Sidetree Anchor contract anchoring batches of DID operations
+    console.log("Deploying Sidetree Anchor contract...");
+    const SidetreeAnchor = await ethers.getContractFactory("SidetreeAnchor");
+    const sidetreeAnchor = await SidetreeAnchor.deploy();
+    await sidetreeAnchor.deployed();
+
+    console.log("Sidetree Anchor deployed to:", sidetreeAnchor.address);
+
     // Save deployment info
     const deploymentInfo = {
         network: hre.network.name,
         contract: "DIDRegistry",
         address: didRegistry.address,
+        revocationRegistry: revocationRegistry.address,
+        sidetreeAnchor: sidetreeAnchor.address,
         deployer: deployer.address,
         timestamp: new Date().toISOString(),
         blockNumber: await deployer.provider.getBlockNumber(),
@@ -42,6 +60,8 @@ async function main() {
 
     console.log("Deployment successful!");
     console.log("Contract address:", didRegistry.address);
+    console.log("Revocation Registry address:", revocationRegistry.address);
+    console.log("Sidetree Anchor address:", sidetreeAnchor.address);
     console.log("Network:", hre.network.name);
     console.log("Block number:", deploymentInfo.blockNumber);
 
@@ -0,0 +1,122 @@
+// Package canonical canonicalizes the emails and names users enter, so the same
+// identity typed differently yields the same commitments, fingerprints and stored values.
+// auth-service stores accounts and did-manager commits DIDs to the same canonical forms,
+// so both services must canonicalize with this package.
+package canonical
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// ErrInvalidName is returned for names containing control or invisible characters
+	ErrInvalidName = errors.New("name contains control or invisible characters")
+	// ErrHomograph is returned for names mixing scripts within a word, as spoofed
+	// names such as a Latin word with Cyrillic letters do
+	ErrHomograph = errors.New("name mixes scripts within a word")
+)
+
+const (
+	zeroWidthNonJoiner = '\u200c'
+	zeroWidthJoiner    = '\u200d'
+)
+
+// Email canonicalizes an email address: it is NFC-normalized, trimmed and case-folded.
+// Folding is locale independent, unlike lower-casing.
+func Email(email string) string {
+	email = strings.TrimSpace(norm.NFC.String(email))
+	// Casers are stateful, so one is made per call
+	return norm.NFC.String(cases.Fold().String(email))
+}
+
+// Name canonicalizes a display name: it is NFC-normalized and trimmed, and its
+// whitespace collapsed to single spaces. Case is kept.
+func Name(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// ValidateName rejects names with control or invisible format characters, and names
+// with a word mixing scripts. Joiners, which some scripts need, and the Common and
+// Inherited scripts of digits, punctuation and combining marks are allowed, as are
+// the script combinations of Japanese, Korean and Chinese writing.
+func ValidateName(name string) error {
+	for _, r := range name {
+		if r == zeroWidthNonJoiner || r == zeroWidthJoiner {
+			continue
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return fmt.Errorf("%w: %U", ErrInvalidName, r)
+		}
+	}
+
+	for _, word := range strings.Fields(norm.NFC.String(name)) {
+		scripts := wordScripts(word)
+		if len(scripts) > 1 && !allowedMix(scripts) {
+			return fmt.Errorf("%w: %q", ErrHomograph, word)
+		}
+	}
+	return nil
+}
+
+// scriptTables are the scripts names are checked against, by name
+var scriptTables = func() map[string]*unicode.RangeTable {
+	tables := make(map[string]*unicode.RangeTable, len(unicode.Scripts))
+	for name, table := range unicode.Scripts {
+		if name == "Common" || name == "Inherited" {
+			continue
+		}
+		tables[name] = table
+	}
+	return tables
+}()
+
+// wordScripts returns the set of scripts used by a word, ignoring Common and Inherited
+func wordScripts(word string) map[string]bool {
+	scripts := make(map[string]bool)
+	for _, r := range word {
+		if r < unicode.MaxASCII {
+			if unicode.IsLetter(r) {
+				scripts["Latin"] = true
+			}
+			continue
+		}
+		for name, table := range scriptTables {
+			if unicode.Is(table, r) {
+				scripts[name] = true
+				break
+			}
+		}
+	}
+	return scripts
+}
+
+// allowedMixes are the script combinations used together within words, each
+// optionally with Latin
+var allowedMixes = []map[string]bool{
+	{"Han": true, "Hiragana": true, "Katakana": true},
+	{"Han": true, "Hangul": true},
+	{"Han": true, "Bopomofo": true},
+}
+
+// allowedMix reports whether a word's scripts form an allowed combination
+func allowedMix(scripts map[string]bool) bool {
+	for _, allowed := range allowedMixes {
+		ok := true
+		for script := range scripts {
+			if script != "Latin" && !allowed[script] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
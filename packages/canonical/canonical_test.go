@@ -0,0 +1,78 @@
+package canonical
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmail(t *testing.T) {
+	tests := map[string]string{
+		"  Alice@Example.COM ":   "alice@example.com",
+		"JOSE\u0301@example.com": "josé@example.com",
+		"josé@example.com":       "josé@example.com",
+		"STRASSE@example.com":    "strasse@example.com",
+		"Straße@example.com":     "strasse@example.com",
+		"alice+news@example.com": "alice+news@example.com",
+		"ΣΊΣΥΦΟΣ@example.com":    "σίσυφοσ@example.com",
+	}
+	for input, want := range tests {
+		if got := Email(input); got != want {
+			t.Errorf("Email(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		"  Alice   Smith\t": "Alice Smith",
+		"Jose\u0301":        "José",
+		"Zoë\nBrown":        "Zoë Brown",
+	}
+	for input, want := range tests {
+		if got := Name(input); got != want {
+			t.Errorf("Name(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	valid := []string{
+		"Alice Smith",
+		"José O'Brien-Núñez",
+		"Дмитрий Иванов",
+		"Alice Иванова",
+		"山田 太郎",
+		"やまだ太郎",
+		"김민준",
+		"Ἀλέξανδρος",
+		"می\u200cخواهم",
+		"R2-D2",
+	}
+	for _, name := range valid {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	homographs := []string{
+		"Аlice",     // Cyrillic А
+		"Pаypal",    // Cyrillic а
+		"Αlice Doe", // Greek Α
+	}
+	for _, name := range homographs {
+		if err := ValidateName(name); !errors.Is(err, ErrHomograph) {
+			t.Errorf("ValidateName(%q) = %v, want ErrHomograph", name, err)
+		}
+	}
+
+	invalid := []string{
+		"Alice\x00",
+		"Ali\u200bce",
+		"Alice\u202eecilA",
+	}
+	for _, name := range invalid {
+		if err := ValidateName(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ValidateName(%q) = %v, want ErrInvalidName", name, err)
+		}
+	}
+}
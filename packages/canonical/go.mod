@@ -0,0 +1,5 @@
+module packages/canonical
+
+go 1.24.6
+
+require golang.org/x/text v0.14.0
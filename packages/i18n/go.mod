@@ -0,0 +1,5 @@
+module packages/i18n
+
+go 1.24.6
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,115 @@
+// Package i18n holds catalogs of translated messages shown to end users and negotiates
+// the locale they are shown in. Each service keeps its own message tables.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// ErrUnsupportedLocale is returned for locales a catalog has no messages in
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+// Catalog holds messages by locale and key. Messages missing from a locale fall back to
+// the catalog's fallback locale.
+type Catalog struct {
+	messages map[string]map[string]string
+	fallback string
+	locales  []string
+	matcher  language.Matcher
+}
+
+// NewCatalog creates a catalog of messages by locale, then key. locales lists the
+// catalog's locales in order of preference, starting with the fallback locale, which
+// must hold every message.
+func NewCatalog(messages map[string]map[string]string, locales ...string) (*Catalog, error) {
+	if len(locales) == 0 {
+		return nil, errors.New("catalog needs a fallback locale")
+	}
+
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid catalog locale %q: %w", locale, err)
+		}
+		if _, ok := messages[locale]; !ok {
+			return nil, fmt.Errorf("catalog has no %s messages", locale)
+		}
+		tags[i] = tag
+	}
+
+	return &Catalog{
+		messages: messages,
+		fallback: locales[0],
+		locales:  locales,
+		matcher:  language.NewMatcher(tags),
+	}, nil
+}
+
+// Locales lists the catalog's locales, the fallback locale first
+func (c *Catalog) Locales() []string {
+	return c.locales
+}
+
+// Fallback returns the locale of messages missing from other locales
+func (c *Catalog) Fallback() string {
+	return c.fallback
+}
+
+// Supported returns the catalog locale serving a locale, such as "pt" for "pt-BR", or
+// ErrUnsupportedLocale
+func (c *Catalog) Supported(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedLocale, locale)
+	}
+	return c.match(tag)
+}
+
+// Match returns the catalog locale best serving an Accept-Language header, and false
+// when the header is empty, malformed or accepts none of the catalog's locales
+func (c *Catalog) Match(acceptLanguage string) (string, bool) {
+	if acceptLanguage == "" {
+		return "", false
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	locale, err := c.match(tags...)
+	return locale, err == nil
+}
+
+// match returns the catalog locale best serving the given tags
+func (c *Catalog) match(tags ...language.Tag) (string, error) {
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedLocale, tags[0])
+	}
+	return c.locales[index], nil
+}
+
+// Has reports whether the catalog has a message for key
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.messages[c.fallback][key]
+	return ok
+}
+
+// Message returns the message for key in a locale, formatted with args. Messages missing
+// from the locale are taken from the fallback locale, and unknown keys return the key.
+func (c *Catalog) Message(locale, key string, args ...any) string {
+	message, ok := c.messages[locale][key]
+	if !ok {
+		message, ok = c.messages[c.fallback][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	catalog, err := NewCatalog(map[string]map[string]string{
+		"en": {"greeting": "Hello", "farewell": "Goodbye", "count": "%d credentials"},
+		"es": {"greeting": "Hola", "count": "%d credenciales"},
+		"pt": {"greeting": "Olá"},
+	}, "en", "es", "pt")
+	if err != nil {
+		t.Fatalf("failed to create catalog: %v", err)
+	}
+	return catalog
+}
+
+func TestCatalogMessage(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		locale, key string
+		args        []any
+		want        string
+	}{
+		{"es", "greeting", nil, "Hola"},
+		{"es", "farewell", nil, "Goodbye"},
+		{"es", "count", []any{3}, "3 credenciales"},
+		{"de", "greeting", nil, "Hello"},
+		{"", "greeting", nil, "Hello"},
+		{"en", "missing", nil, "missing"},
+	}
+	for _, tt := range tests {
+		if got := catalog.Message(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Errorf("Message(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogHas(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	if !catalog.Has("farewell") {
+		t.Error("expected the catalog to have a fallback message")
+	}
+	if catalog.Has("missing") {
+		t.Error("expected the catalog not to have an unknown key")
+	}
+}
+
+func TestCatalogMatch(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"es-MX,es;q=0.9,en;q=0.8", "es", true},
+		{"pt-BR", "pt", true},
+		{"de-DE,pt;q=0.5", "pt", true},
+		{"en-GB", "en", true},
+		{"ja", "", false},
+		{"", "", false},
+		{";;;", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := catalog.Match(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCatalogSupported(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	if locale, err := catalog.Supported("es-AR"); err != nil || locale != "es" {
+		t.Errorf("Supported(es-AR) = %q, %v, want es", locale, err)
+	}
+	for _, locale := range []string{"ja", "not a locale"} {
+		if _, err := catalog.Supported(locale); !errors.Is(err, ErrUnsupportedLocale) {
+			t.Errorf("Supported(%q) = %v, want ErrUnsupportedLocale", locale, err)
+		}
+	}
+}
+
+func TestNewCatalogRequiresLocaleMessages(t *testing.T) {
+	if _, err := NewCatalog(map[string]map[string]string{"en": {}}, "en", "fr"); err == nil {
+		t.Error("expected an error for a locale without messages")
+	}
+	if _, err := NewCatalog(map[string]map[string]string{"en": {}}); err == nil {
+		t.Error("expected an error without a fallback locale")
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 	"auth-service/models"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // TokenConfig holds JWT configuration
@@ -40,7 +40,7 @@ fi
 if [ -d "$PROJECT_ROOT/tests/integration" ]; then
     echo -e "${YELLOW}Running integration tests...${NC}"
     cd "$PROJECT_ROOT/tests/integration"
-    go test -v ./...
+    go test -tags=integration -v -count=1 ./...
     echo -e "${GREEN}✓ Integration tests passed${NC}"
 fi
 
@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/tls"
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -63,6 +64,62 @@ type Config struct {
 	JWTExpirationTime    int // in minutes
 	JWTRefreshExpiration int // in days
 
+	// Wallet API (OAuth) Configuration; wallet tokens are verified by the DID manager
+	// with the same secret, and the OAuth endpoints are disabled when it is empty
+	WalletTokenSecret string
+	WalletTokenTTL    int // in minutes
+
+	// DID Manager service tokens; service clients obtain tokens limited to DID Manager
+	// operation scopes with client credentials, verified by the DID manager with the
+	// same secret. The client credentials grant is disabled when it is empty.
+	ServiceTokenSecret string
+	ServiceTokenTTL    int // in minutes
+
+	// Password Reset Configuration; reset tokens are posted to the webhook, e.g. a mail
+	// relay, and password resets are disabled when it is empty
+	PasswordResetWebhookURL string
+	PasswordResetTokenTTL   int // in minutes
+
+	// Login Anomaly Detection; when enabled, sign-in attempts are recorded and successful
+	// sign-ins showing signs of account takeover suspend the user's DIDs and are
+	// reported to the user through the security alert webhook
+	LoginAnomalyDetection       bool
+	LoginAnomalySuspendDIDs     bool
+	GeoIPLookupURL              string // {ip} is replaced by the address; enables impossible travel
+	ImpossibleTravelMaxSpeed    int    // in km/h
+	CredentialStuffingWindow    int    // in minutes
+	CredentialStuffingThreshold int    // failed accounts per IP address
+	SecurityAlertWebhookURL     string
+
+	// Client Addresses; sign-ins are attributed to the address the REST gateway received
+	// them from, or, when that is one of these proxies (IPs or CIDRs), to the address the
+	// proxies forwarded in X-Forwarded-For
+	TrustedProxies []string
+
+	// Signup Saga; after a user is created, their DID is created, a base credential is
+	// issued to it by the issuer DID, a verification email is sent and, once the DID is
+	// active, a one-time link importing it into a mobile wallet is sent, each step
+	// retried until it succeeds or runs out of attempts. The credential, email and wallet
+	// steps are skipped when their issuer or webhook is not configured.
+	SignupDIDPolicy             string // required, background or skip
+	SignupSagaMaxAttempts       int
+	SignupSagaInterval          int // in seconds
+	SignupCredentialIssuerDID   string
+	SignupCredentialIssuerKeyID string // device key verification method; empty for the DID's key
+	SignupCredentialIssuerKey   string // hex Ed25519 seed
+	SignupCredentialType        string
+	EmailVerificationWebhookURL string
+	EmailVerificationTokenTTL   int // in minutes
+	WalletOnboardingWebhookURL  string
+
+	// Localization; notices are rendered in the user's locale, or DefaultLocale when it
+	// is not supported
+	DefaultLocale string
+
+	// Admin API; administrators authenticate with X-Admin-Key, and the admin endpoints
+	// are disabled when it is empty
+	AdminAPIKey string
+
 	// Database Security
 	DBSSLMode            string
 	DBMaxConnections     int
@@ -131,6 +188,48 @@ func LoadConfig() (*Config, error) {
 		JWTExpirationTime:    getEnvInt("JWT_EXPIRATION_TIME", 15),   // 15 minutes
 		JWTRefreshExpiration: getEnvInt("JWT_REFRESH_EXPIRATION", 7), // 7 days
 
+		// Wallet API (OAuth) Configuration
+		WalletTokenSecret: getEnv("WALLET_TOKEN_SECRET", ""),
+		WalletTokenTTL:    getEnvInt("WALLET_TOKEN_TTL", 15), // 15 minutes
+
+		// DID Manager service tokens
+		ServiceTokenSecret: getEnv("SERVICE_TOKEN_SECRET", ""),
+		ServiceTokenTTL:    getEnvInt("SERVICE_TOKEN_TTL", 15), // 15 minutes
+
+		// Password Reset Configuration
+		PasswordResetWebhookURL: getEnv("PASSWORD_RESET_WEBHOOK_URL", ""),
+		PasswordResetTokenTTL:   getEnvInt("PASSWORD_RESET_TOKEN_TTL", 30), // 30 minutes
+
+		// Login Anomaly Detection
+		LoginAnomalyDetection:       getEnv("LOGIN_ANOMALY_DETECTION", "false") == "true",
+		LoginAnomalySuspendDIDs:     getEnv("LOGIN_ANOMALY_SUSPEND_DIDS", "true") == "true",
+		GeoIPLookupURL:              getEnv("GEOIP_LOOKUP_URL", ""),
+		ImpossibleTravelMaxSpeed:    getEnvInt("IMPOSSIBLE_TRAVEL_MAX_SPEED", 1000), // km/h, faster than airliners
+		CredentialStuffingWindow:    getEnvInt("CREDENTIAL_STUFFING_WINDOW", 15),    // 15 minutes
+		CredentialStuffingThreshold: getEnvInt("CREDENTIAL_STUFFING_THRESHOLD", 10), // 10 accounts
+		SecurityAlertWebhookURL:     getEnv("SECURITY_ALERT_WEBHOOK_URL", ""),
+
+		// Client Addresses
+		TrustedProxies: splitList(getEnv("TRUSTED_PROXIES", "")),
+
+		// Signup Saga
+		SignupDIDPolicy:             getEnv("SIGNUP_DID_POLICY", "background"),
+		SignupSagaMaxAttempts:       getEnvInt("SIGNUP_SAGA_MAX_ATTEMPTS", 10),
+		SignupSagaInterval:          getEnvInt("SIGNUP_SAGA_INTERVAL", 30), // 30 seconds
+		SignupCredentialIssuerDID:   getEnv("SIGNUP_CREDENTIAL_ISSUER_DID", ""),
+		SignupCredentialIssuerKeyID: getEnv("SIGNUP_CREDENTIAL_ISSUER_KEY_ID", ""),
+		SignupCredentialIssuerKey:   getEnv("SIGNUP_CREDENTIAL_ISSUER_KEY", ""),
+		SignupCredentialType:        getEnv("SIGNUP_CREDENTIAL_TYPE", "AccountCredential"),
+		EmailVerificationWebhookURL: getEnv("EMAIL_VERIFICATION_WEBHOOK_URL", ""),
+		EmailVerificationTokenTTL:   getEnvInt("EMAIL_VERIFICATION_TOKEN_TTL", 1440), // 24 hours
+		WalletOnboardingWebhookURL:  getEnv("WALLET_ONBOARDING_WEBHOOK_URL", ""),
+
+		// Localization
+		DefaultLocale: getEnv("DEFAULT_LOCALE", "en"),
+
+		// Admin API
+		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
+
 		// Database Security
 		DBSSLMode:            getEnv("DB_SSL_MODE", "require"),
 		DBMaxConnections:     getEnvInt("DB_MAX_CONNECTIONS", 25),
@@ -176,6 +275,44 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(raw string) []string {
+	var list []string
+	for _, entry := range strings.Split(raw, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// TrustedProxyPrefixes returns TrustedProxies as address prefixes, addresses as
+// single-address prefixes. Entries are checked by ValidateConfig; invalid ones are
+// skipped.
+func (c *Config) TrustedProxyPrefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range c.TrustedProxies {
+		if prefix, err := parseProxy(entry); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// parseProxy parses a trusted proxy, an IP address or a CIDR range
+func parseProxy(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 // getEnvInt retrieves an environment variable as an integer or returns a fallback
 func getEnvInt(key string, fallback int) int {
 	if value, exists := os.LookupEnv(key); exists {
@@ -377,6 +377,19 @@ func TestConfigValidation(t *testing.T) {
 	assert.NotNil(t, config)
 }
 
+func TestTrustedProxyPrefixes(t *testing.T) {
+	cfg := &Config{TrustedProxies: splitList(" 10.0.0.0/8, 192.0.2.10,,::ffff:198.51.100.1 ")}
+	prefixes := cfg.TrustedProxyPrefixes()
+	if assert.Len(t, prefixes, 3) {
+		assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+		assert.Equal(t, "192.0.2.10/32", prefixes[1].String())
+		assert.Equal(t, "198.51.100.1/32", prefixes[2].String())
+	}
+
+	_, err := parseProxy("proxy.internal")
+	assert.Error(t, err)
+}
+
 // Benchmark tests for performance
 func BenchmarkLoadConfig(b *testing.B) {
 	// Save original environment variables
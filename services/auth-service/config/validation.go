@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net"
 	"strconv"
@@ -96,6 +97,28 @@ func ValidateConfig(cfg *Config) *ValidationResult {
 		result.AddError("JWT_timing", err.Error())
 	}
 
+	// Validate login anomaly detection configuration
+	if err := validateLoginAnomalyConfig(cfg); err != nil {
+		result.AddError("login_anomaly", err.Error())
+	}
+
+	// Validate trusted proxies
+	for _, proxy := range cfg.TrustedProxies {
+		if _, err := parseProxy(proxy); err != nil {
+			result.AddError("TRUSTED_PROXIES", fmt.Sprintf("invalid IP address or CIDR %q", proxy))
+		}
+	}
+
+	// Validate signup saga configuration
+	if err := validateSignupConfig(cfg); err != nil {
+		result.AddError("signup", err.Error())
+	}
+
+	// Validate admin API configuration
+	if cfg.AdminAPIKey != "" && len(cfg.AdminAPIKey) < 32 {
+		result.AddError("ADMIN_API_KEY", "must be at least 32 characters long")
+	}
+
 	return result
 }
 
@@ -177,6 +200,24 @@ func validateJWTConfig(cfg *Config) error {
 		return fmt.Errorf("JWT_REFRESH_TOKEN_SECRET must be at least 32 characters long")
 	}
 
+	if cfg.WalletTokenSecret != "" {
+		if len(cfg.WalletTokenSecret) < 32 {
+			return fmt.Errorf("WALLET_TOKEN_SECRET must be at least 32 characters long")
+		}
+		if cfg.WalletTokenSecret == cfg.JWTAccessTokenSecret {
+			return fmt.Errorf("WALLET_TOKEN_SECRET must differ from JWT_ACCESS_TOKEN_SECRET")
+		}
+	}
+
+	if cfg.ServiceTokenSecret != "" {
+		if len(cfg.ServiceTokenSecret) < 32 {
+			return fmt.Errorf("SERVICE_TOKEN_SECRET must be at least 32 characters long")
+		}
+		if cfg.ServiceTokenSecret == cfg.JWTAccessTokenSecret || cfg.ServiceTokenSecret == cfg.WalletTokenSecret {
+			return fmt.Errorf("SERVICE_TOKEN_SECRET must differ from JWT_ACCESS_TOKEN_SECRET and WALLET_TOKEN_SECRET")
+		}
+	}
+
 	// Check for weak secrets in development
 	if cfg.Environment == DEVELOPMENT_ENV {
 		if cfg.JWTAccessTokenSecret == "default-access" {
@@ -404,6 +445,63 @@ func validateJWTTimingConfig(cfg *Config) error {
 	return nil
 }
 
+// validateLoginAnomalyConfig validates login anomaly detection configuration
+func validateLoginAnomalyConfig(cfg *Config) error {
+	if !cfg.LoginAnomalyDetection {
+		return nil
+	}
+
+	if cfg.GeoIPLookupURL != "" && !strings.Contains(cfg.GeoIPLookupURL, "{ip}") {
+		return fmt.Errorf("GEOIP_LOOKUP_URL must contain {ip}")
+	}
+
+	if cfg.ImpossibleTravelMaxSpeed <= 0 {
+		return fmt.Errorf("IMPOSSIBLE_TRAVEL_MAX_SPEED must be positive")
+	}
+
+	if cfg.CredentialStuffingWindow <= 0 {
+		return fmt.Errorf("CREDENTIAL_STUFFING_WINDOW must be positive")
+	}
+
+	if cfg.CredentialStuffingThreshold < 2 {
+		return fmt.Errorf("CREDENTIAL_STUFFING_THRESHOLD must be at least 2")
+	}
+
+	return nil
+}
+
+// validateSignupConfig validates the signup saga configuration
+func validateSignupConfig(cfg *Config) error {
+	switch cfg.SignupDIDPolicy {
+	case "required", "background", "skip":
+	default:
+		return fmt.Errorf("SIGNUP_DID_POLICY must be required, background or skip")
+	}
+
+	if cfg.SignupSagaMaxAttempts <= 0 {
+		return fmt.Errorf("SIGNUP_SAGA_MAX_ATTEMPTS must be positive")
+	}
+
+	if cfg.SignupSagaInterval <= 0 {
+		return fmt.Errorf("SIGNUP_SAGA_INTERVAL must be positive")
+	}
+
+	if cfg.SignupCredentialIssuerDID != "" {
+		if seed, err := hex.DecodeString(cfg.SignupCredentialIssuerKey); err != nil || len(seed) != 32 {
+			return fmt.Errorf("SIGNUP_CREDENTIAL_ISSUER_KEY must be a hex-encoded 32-byte Ed25519 seed")
+		}
+		if cfg.SignupCredentialType == "" {
+			return fmt.Errorf("SIGNUP_CREDENTIAL_TYPE cannot be empty")
+		}
+	}
+
+	if cfg.EmailVerificationWebhookURL != "" && cfg.EmailVerificationTokenTTL <= 0 {
+		return fmt.Errorf("EMAIL_VERIFICATION_TOKEN_TTL must be positive")
+	}
+
+	return nil
+}
+
 // GetValidationErrors returns a formatted string of all validation errors
 func (r *ValidationResult) GetValidationErrors() string {
 	if r.IsValid {
@@ -5,7 +5,7 @@ go 1.24.6
 require (
 	api/auth/v1/proto v0.0.0
 	github.com/go-ozzo/ozzo-validation v3.6.0+incompatible
-	github.com/golang-jwt/jwt v3.2.2+incompatible
+	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/google/uuid v1.6.0
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.2
 	github.com/jmoiron/sqlx v1.4.0
@@ -17,6 +17,8 @@ require (
 	google.golang.org/grpc v1.75.0
 	google.golang.org/protobuf v1.36.7
 	packages/auth v0.0.0
+	packages/canonical v0.0.0
+	packages/i18n v0.0.0
 	packages/logger v0.0.0
 )
 
@@ -27,7 +29,6 @@ require (
 	github.com/cloudwego/base64x v0.1.4 // indirect
 	github.com/cloudwego/iasm v0.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/gin-gonic/gin v1.10.1 // indirect
@@ -62,4 +63,8 @@ replace api/auth/v1/proto => ../../api/auth/v1/proto
 
 replace packages/auth => ../../packages/auth
 
+replace packages/canonical => ../../packages/canonical
+
+replace packages/i18n => ../../packages/i18n
+
 replace packages/logger => ../../packages/logger
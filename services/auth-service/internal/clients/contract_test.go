@@ -0,0 +1,123 @@
+package clients
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixturesDir holds the golden DID Manager responses, checked against the service by
+// its own contract test
+const fixturesDir = "../../../../api/did-manager/fixtures"
+
+// loadFixture reads a golden DID Manager response
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join(fixturesDir, name+".json"))
+	require.NoError(t, err)
+	return body
+}
+
+// serveFixture answers every request with a golden response
+func serveFixture(t *testing.T, status int, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// assertFieldsInFixture checks that every JSON field of the type of v is present in
+// the fixture, so a field renamed or dropped by the DID Manager is caught here rather
+// than silently decoded as its zero value
+func assertFieldsInFixture(t *testing.T, v any, body []byte) {
+	t.Helper()
+	var fixture any
+	require.NoError(t, json.Unmarshal(body, &fixture))
+	assertFields(t, reflect.TypeOf(v), fixture, "")
+}
+
+func assertFields(t *testing.T, typ reflect.Type, value any, path string) {
+	t.Helper()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !assert.True(t, ok, "expected an object at %q", path) {
+			return
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" || name == "" {
+				continue
+			}
+			fieldValue, ok := object[name]
+			if assert.True(t, ok, "field %q is not in the DID Manager response", path+"."+name) {
+				assertFields(t, field.Type, fieldValue, path+"."+name)
+			}
+		}
+	case reflect.Slice:
+		if items, ok := value.([]any); ok && len(items) > 0 {
+			assertFields(t, typ.Elem(), items[0], path+"[0]")
+		}
+	}
+}
+
+func TestDIDClient_CreateDID_Contract(t *testing.T) {
+	body := loadFixture(t, "create_did")
+	server := serveFixture(t, http.StatusCreated, body)
+
+	resp, err := NewDIDClient(server.URL, "").CreateDID(&DIDCreateRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.NotEmpty(t, resp.Data.DIDRecord.ID)
+	assert.NotEmpty(t, resp.Data.DIDRecord.DID)
+	assert.NotEmpty(t, resp.Data.DIDRecord.PublicKey)
+	assert.NotEmpty(t, resp.Data.DIDRecord.CreatedAt)
+	assert.Equal(t, resp.Data.DIDRecord.UserHash, resp.Data.UserHash)
+	assert.Equal(t, "pending", resp.Data.Status)
+	assert.False(t, resp.Data.Existing)
+}
+
+func TestDIDClient_GetOrCreateDID_Contract(t *testing.T) {
+	body := loadFixture(t, "get_or_create_did")
+	assertFieldsInFixture(t, DIDCreateResponse{}, body)
+
+	server := serveFixture(t, http.StatusOK, body)
+	resp, err := NewDIDClient(server.URL, "secret").GetOrCreateDID(&DIDCreateRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.True(t, resp.Data.Existing)
+	assert.NotEmpty(t, resp.Data.DIDRecord.DID)
+	assert.NotEmpty(t, resp.Data.DIDRecord.BlockchainTx)
+}
+
+func TestDIDClient_Error_Contract(t *testing.T) {
+	body := loadFixture(t, "error")
+	assertFieldsInFixture(t, errorEnvelope{}, body)
+
+	server := serveFixture(t, http.StatusConflict, body)
+	_, err := NewDIDClient(server.URL, "").CreateDID(&DIDCreateRequest{UserID: "user-1"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.ErrorIs(t, err, ErrDuplicateDID)
+	assert.NotEmpty(t, apiErr.Message)
+	assert.NotEmpty(t, apiErr.Details)
+}
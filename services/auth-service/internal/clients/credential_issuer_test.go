@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialIssuer_SignsRequests(t *testing.T) {
+	seed := strings.Repeat("07", ed25519.SeedSize)
+	seedBytes, _ := hex.DecodeString(seed)
+	publicKey := ed25519.NewKeyFromSeed(seedBytes).Public().(ed25519.PublicKey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/credentials", r.URL.Path)
+		assert.Equal(t, "did:example:issuer", r.Header.Get("X-Caller-DID"))
+		assert.Equal(t, "did:example:issuer#device-1", r.Header.Get("X-Caller-Key"))
+
+		signature, err := hex.DecodeString(r.Header.Get("X-Caller-Signature"))
+		require.NoError(t, err)
+		payload := "POST /api/v1/credentials\n" + r.Header.Get("X-Caller-Timestamp")
+		assert.True(t, ed25519.Verify(publicKey, []byte(payload), signature))
+
+		var req CredentialIssueRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "did:example:subject", req.SubjectDID)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success":true,"data":{"id":"credential-1","subject_did":"did:example:subject","type":"AccountCredential","status":"active"}}`))
+	}))
+	defer server.Close()
+
+	issuer, err := NewCredentialIssuer(NewDIDClient(server.URL, ""), "did:example:issuer", "did:example:issuer#device-1", seed)
+	require.NoError(t, err)
+
+	credential, err := issuer.IssueCredential(&CredentialIssueRequest{
+		SubjectDID: "did:example:subject",
+		Type:       "AccountCredential",
+		Claims:     map[string]any{"account_id": "user-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "credential-1", credential.ID)
+}
+
+func TestCredentialIssuer_Errors(t *testing.T) {
+	_, err := NewCredentialIssuer(NewDIDClient("http://did-manager.invalid", ""), "did:example:issuer", "", "not-hex")
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"Deployment is a read-only standby"}`))
+	}))
+	defer server.Close()
+
+	issuer, err := NewCredentialIssuer(NewDIDClient(server.URL, ""), "did:example:issuer", "", strings.Repeat("07", ed25519.SeedSize))
+	require.NoError(t, err)
+	_, err = issuer.IssueCredential(&CredentialIssueRequest{SubjectDID: "did:example:subject", Type: "AccountCredential"})
+	assert.ErrorIs(t, err, ErrDegraded)
+}
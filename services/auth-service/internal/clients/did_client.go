@@ -3,34 +3,114 @@ package clients
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"time"
 )
 
+// ErrAdminKeyMissing is returned by calls to the DID Manager's admin API when no admin
+// key is configured
+var ErrAdminKeyMissing = errors.New("DID Manager admin key not configured")
+
+// Errors reported by the DID Manager, matched with errors.Is against the *APIError
+// returned for a failed call
+var (
+	// ErrDuplicateDID is returned when the identity already has a DID
+	ErrDuplicateDID = errors.New("identity already has a DID")
+	// ErrQuotaExceeded is returned when the caller's usage quota or rate limit is exhausted
+	ErrQuotaExceeded = errors.New("DID Manager quota exceeded")
+	// ErrDegraded is returned when the DID Manager cannot serve the call for now, such as
+	// a read-only standby or a disabled dependency; the call may succeed later
+	ErrDegraded = errors.New("DID Manager degraded")
+	// ErrDIDNotActive is returned when a DID cannot be offered to a wallet until it is
+	// anchored and active
+	ErrDIDNotActive = errors.New("DID is not active yet")
+)
+
+// APIError is a failed call to the DID Manager, decoded from its error envelope
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    string
+	// Err is the error the status code maps to, if any
+	Err error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("DID Manager returned status %d", e.StatusCode)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if e.Details != "" {
+		message += " (" + e.Details + ")"
+	}
+	return message
+}
+
+// Unwrap returns the error the status code maps to
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// errorEnvelope is the body of the DID Manager's error responses
+type errorEnvelope struct {
+	Error   string `json:"error"`
+	Details string `json:"details"`
+}
+
+// newAPIError decodes a failed response into an *APIError
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != "" {
+		apiErr.Message = envelope.Error
+		apiErr.Details = envelope.Details
+	} else {
+		apiErr.Message = string(body)
+	}
+
+	switch statusCode {
+	case http.StatusConflict:
+		apiErr.Err = ErrDuplicateDID
+	case http.StatusTooManyRequests:
+		apiErr.Err = ErrQuotaExceeded
+	case http.StatusServiceUnavailable:
+		apiErr.Err = ErrDegraded
+	}
+	return apiErr
+}
+
 // DIDClient handles communication with the DID Manager service
 type DIDClient struct {
 	baseURL    string
+	adminKey   string
 	httpClient *http.Client
 }
 
-// NewDIDClient creates a new DID client
-func NewDIDClient(baseURL string) *DIDClient {
+// NewDIDClient creates a new DID client; adminKey authenticates calls to the DID
+// Manager's admin API and may be empty when they are not needed
+func NewDIDClient(baseURL, adminKey string) *DIDClient {
 	return &DIDClient{
-		baseURL: baseURL,
+		baseURL:  baseURL,
+		adminKey: adminKey,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
-// DIDCreateRequest represents a request to create a DID
+// DIDCreateRequest represents a request to create a DID. The user's password is not
+// sent: the DID's claims commitment covers the name and email only.
 type DIDCreateRequest struct {
-	UserID   string `json:"user_id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
 }
 
 // DIDRecord represents the DID record structure
@@ -52,6 +132,8 @@ type DIDCreateResponseData struct {
 	UserHash  string    `json:"user_hash"`
 	Status    string    `json:"status"`
 	Message   string    `json:"message"`
+	// Existing is set when GetOrCreateDID returned the DID the user already had
+	Existing bool `json:"existing"`
 }
 
 // DIDCreateResponse represents the full response from DID creation
@@ -60,7 +142,8 @@ type DIDCreateResponse struct {
 	Data    DIDCreateResponseData `json:"data"`
 }
 
-// CreateDID creates a new DID for a user
+// CreateDID creates a new DID for a user. A failed call returns an *APIError, which
+// matches ErrDuplicateDID, ErrQuotaExceeded or ErrDegraded where they apply.
 func (c *DIDClient) CreateDID(req *DIDCreateRequest) (*DIDCreateResponse, error) {
 	jsonData, err := json.Marshal(req)
 	if err != nil {
@@ -77,13 +160,49 @@ func (c *DIDClient) CreateDID(req *DIDCreateRequest) (*DIDCreateResponse, error)
 	}
 	defer resp.Body.Close()
 
+	return decodeDIDCreateResponse(resp, http.StatusCreated)
+}
+
+// GetOrCreateDID returns the DID the user already has, found by user ID or by a DID
+// whose commitment matches the user's name and email, or creates one. The DID Manager
+// links a DID found by email to the user. It uses the admin API, so it fails with
+// ErrAdminKeyMissing when no admin key is configured.
+func (c *DIDClient) GetOrCreateDID(req *DIDCreateRequest) (*DIDCreateResponse, error) {
+	if c.adminKey == "" {
+		return nil, ErrAdminKeyMissing
+	}
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v1/admin/did/get-or-create", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Admin-Key", c.adminKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeDIDCreateResponse(resp, http.StatusOK, http.StatusCreated)
+}
+
+// decodeDIDCreateResponse decodes the response of a DID creation answered with one of
+// the expected status codes
+func decodeDIDCreateResponse(resp *http.Response, expected ...int) (*DIDCreateResponse, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	if !slices.Contains(expected, resp.StatusCode) {
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var response DIDCreateResponse
@@ -98,3 +217,300 @@ func (c *DIDClient) CreateDID(req *DIDCreateRequest) (*DIDCreateResponse, error)
 
 	return &response, nil
 }
+
+// userExportResponse represents the response of a user data export
+type userExportResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ExportUserData retrieves the DIDs of a user with their documents, credentials,
+// access grants and audit trail, as exported by the DID Manager
+func (c *DIDClient) ExportUserData(userID string) (json.RawMessage, error) {
+	if c.adminKey == "" {
+		return nil, ErrAdminKeyMissing
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/api/v1/admin/users/"+url.PathEscape(userID)+"/export", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Admin-Key", c.adminKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	var response userExportResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("user data export failed: %s", string(body))
+	}
+
+	return response.Data, nil
+}
+
+// passwordChangeRequest reports a password change to the DID Manager
+type passwordChangeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RecordPasswordChange records a change ("change") or reset ("reset") of a user's
+// password as an event on their DIDs. DID commitments do not cover the password, so
+// the DIDs themselves stay valid.
+func (c *DIDClient) RecordPasswordChange(userID, reason string) error {
+	_, err := c.postAdmin("/api/v1/admin/users/"+url.PathEscape(userID)+"/password-changes", passwordChangeRequest{Reason: reason})
+	return err
+}
+
+// suspensionRequest asks the DID Manager to suspend a user's DIDs
+type suspensionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SuspendUserDIDs suspends a user's active DIDs, so they verify as invalid until an
+// administrator reinstates them, and returns the suspended DIDs
+func (c *DIDClient) SuspendUserDIDs(userID, reason string) ([]string, error) {
+	body, err := c.postAdmin("/api/v1/admin/users/"+url.PathEscape(userID)+"/suspension", suspensionRequest{Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			DIDs []string `json:"dids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return response.Data.DIDs, nil
+}
+
+// DeviceKeyRegisterRequest registers a device's key on one of its user's DIDs
+type DeviceKeyRegisterRequest struct {
+	DID          string `json:"did"`
+	Name         string `json:"name"`
+	PublicKey    string `json:"public_key"`
+	KeyAlgorithm string `json:"key_algorithm,omitempty"`
+	// Signature is the device's hex signature over "device-key:<did>:<public_key>"
+	Signature string `json:"signature"`
+	// Attestation is the app instance's platform attestation, if it submitted one
+	Attestation *DeviceKeyAttestationEvidence `json:"attestation,omitempty"`
+}
+
+// DeviceKeyAttestationEvidence is a Play Integrity or App Attest attestation of the app
+// instance registering a device key
+type DeviceKeyAttestationEvidence struct {
+	Platform string `json:"platform"`
+	Token    string `json:"token"`
+	KeyID    string `json:"key_id,omitempty"`
+}
+
+// DeviceKeyAttestation describes the attested app instance holding a device key
+type DeviceKeyAttestation struct {
+	Platform string `json:"platform"`
+	AppID    string `json:"app_id"`
+	KeyID    string `json:"key_id,omitempty"`
+}
+
+// DeviceKey is a device key registered on a user's DID
+type DeviceKey struct {
+	ID                 string                `json:"id"`
+	DID                string                `json:"did"`
+	Name               string                `json:"name"`
+	VerificationMethod string                `json:"verification_method"`
+	PublicKey          string                `json:"public_key"`
+	KeyAlgorithm       string                `json:"key_algorithm"`
+	Status             string                `json:"status"`
+	Attestation        *DeviceKeyAttestation `json:"attestation,omitempty"`
+}
+
+// RegisterDeviceKey lists a device's key as an authentication method on one of the
+// user's DIDs. The DID Manager rejects it without the device's proof of possession.
+func (c *DIDClient) RegisterDeviceKey(userID string, req *DeviceKeyRegisterRequest) (*DeviceKey, error) {
+	body, err := c.adminRequest(http.MethodPost, deviceKeysPath(userID), req, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data DeviceKey `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &response.Data, nil
+}
+
+// RevokeDeviceKey removes one of the user's device keys from its DID
+func (c *DIDClient) RevokeDeviceKey(userID, keyID string) error {
+	_, err := c.adminRequest(http.MethodDelete, deviceKeysPath(userID)+"/"+url.PathEscape(keyID), nil, http.StatusOK)
+	return err
+}
+
+// RevokeDeviceKeys removes every device key of the user from their DIDs
+func (c *DIDClient) RevokeDeviceKeys(userID string) error {
+	_, err := c.adminRequest(http.MethodDelete, deviceKeysPath(userID), nil, http.StatusOK)
+	return err
+}
+
+// deviceSignatureRequest asks the DID Manager whether a device key signed a message
+type deviceSignatureRequest struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// VerifyDeviceSignature reports whether one of the user's active device keys made the
+// hex signature over message
+func (c *DIDClient) VerifyDeviceSignature(userID, keyID, message, signature string) (bool, error) {
+	body, err := c.postAdmin(deviceKeysPath(userID)+"/"+url.PathEscape(keyID)+"/verify", deviceSignatureRequest{
+		Message:   message,
+		Signature: signature,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var response struct {
+		Data struct {
+			Valid bool `json:"valid"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return response.Data.Valid, nil
+}
+
+// WalletOfferRequest offers a DID and the given credentials it holds, or all of them,
+// to its owner's mobile wallet
+type WalletOfferRequest struct {
+	DID           string   `json:"did"`
+	CredentialIDs []string `json:"credential_ids,omitempty"`
+}
+
+// WalletOffer is a one-time offer of a DID to its owner's mobile wallet
+type WalletOffer struct {
+	ID            string     `json:"id"`
+	DID           string     `json:"did"`
+	CredentialIDs []string   `json:"credential_ids"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	ClaimedAt     *time.Time `json:"claimed_at,omitempty"`
+}
+
+// WalletOfferResponse is a new wallet offer with the claim link the user's wallet opens,
+// which the DID Manager returns only once
+type WalletOfferResponse struct {
+	Offer    WalletOffer `json:"offer"`
+	ClaimURI string      `json:"claim_uri"`
+	// QRImage is the claim link as a PNG data URI
+	QRImage string `json:"qr_image"`
+}
+
+// CreateWalletOffer offers a DID to its owner's mobile wallet. It fails with
+// ErrDIDNotActive while the DID is being anchored.
+func (c *DIDClient) CreateWalletOffer(req *WalletOfferRequest) (*WalletOfferResponse, error) {
+	body, err := c.adminRequest(http.MethodPost, "/api/v1/admin/wallet-offers", req, http.StatusCreated)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			apiErr.Err = ErrDIDNotActive
+		}
+		return nil, err
+	}
+
+	var response struct {
+		Data WalletOfferResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &response.Data, nil
+}
+
+// GetWalletOffer returns a wallet offer, telling whether the user claimed it
+func (c *DIDClient) GetWalletOffer(id string) (*WalletOffer, error) {
+	body, err := c.adminRequest(http.MethodGet, "/api/v1/admin/wallet-offers/"+url.PathEscape(id), nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data WalletOffer `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &response.Data, nil
+}
+
+// deviceKeysPath is the admin route of a user's device keys
+func deviceKeysPath(userID string) string {
+	return "/api/v1/admin/users/" + url.PathEscape(userID) + "/device-keys"
+}
+
+// postAdmin posts payload to an admin route of the DID Manager, which must answer 200,
+// and returns the response body
+func (c *DIDClient) postAdmin(path string, payload any) ([]byte, error) {
+	return c.adminRequest(http.MethodPost, path, payload, http.StatusOK)
+}
+
+// adminRequest calls an admin route of the DID Manager with an optional JSON payload,
+// requiring one of the expected status codes, and returns the response body
+func (c *DIDClient) adminRequest(method, path string, payload any, expected ...int) ([]byte, error) {
+	if c.adminKey == "" {
+		return nil, ErrAdminKeyMissing
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Admin-Key", c.adminKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if !slices.Contains(expected, resp.StatusCode) {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	return body, nil
+}
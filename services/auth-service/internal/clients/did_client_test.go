@@ -0,0 +1,200 @@
+package clients
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDIDClient_CreateDID_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{
+			name:   "duplicate identity",
+			status: http.StatusConflict,
+			body:   `{"error":"Identity already registered","details":"identity is already registered: email matches an existing DID"}`,
+			want:   ErrDuplicateDID,
+		},
+		{
+			name:   "quota exceeded",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":"Usage quota exceeded","details":"usage quota exceeded"}`,
+			want:   ErrQuotaExceeded,
+		},
+		{
+			name:   "read-only standby",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":"Deployment is a read-only standby"}`,
+			want:   ErrDegraded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewDIDClient(server.URL, "").CreateDID(&DIDCreateRequest{UserID: "user-1"})
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.want), "expected %v, got %v", tt.want, err)
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.NotEmpty(t, apiErr.Message)
+		})
+	}
+}
+
+func TestDIDClient_CreateDID_UntypedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	_, err := NewDIDClient(server.URL, "").CreateDID(&DIDCreateRequest{UserID: "user-1"})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrDuplicateDID))
+	assert.False(t, errors.Is(err, ErrQuotaExceeded))
+	assert.False(t, errors.Is(err, ErrDegraded))
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestDIDClient_GetOrCreateDID(t *testing.T) {
+	_, err := NewDIDClient("http://did-manager.invalid", "").GetOrCreateDID(&DIDCreateRequest{UserID: "user-1"})
+	assert.ErrorIs(t, err, ErrAdminKeyMissing)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/admin/did/get-or-create", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-Admin-Key"))
+		_, _ = w.Write([]byte(`{"success":true,"data":{"did":{"did":"did:example:123"},"user_hash":"hash","status":"active","existing":true}}`))
+	}))
+	defer server.Close()
+
+	response, err := NewDIDClient(server.URL, "admin-key").GetOrCreateDID(&DIDCreateRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.True(t, response.Data.Existing)
+	assert.Equal(t, "did:example:123", response.Data.DIDRecord.DID)
+}
+
+func TestDIDClient_RecordPasswordChange(t *testing.T) {
+	err := NewDIDClient("http://did-manager.invalid", "").RecordPasswordChange("user-1", "reset")
+	assert.ErrorIs(t, err, ErrAdminKeyMissing)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/admin/users/user-1/password-changes", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-Admin-Key"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"reason":"reset"}`, string(body))
+		_, _ = w.Write([]byte(`{"success":true,"data":{"dids":["did:example:123"]}}`))
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewDIDClient(server.URL, "admin-key").RecordPasswordChange("user-1", "reset"))
+}
+
+func TestDIDClient_SuspendUserDIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/admin/users/user-1/suspension", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-Admin-Key"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"reason":"suspicious sign-in"}`, string(body))
+		_, _ = w.Write([]byte(`{"success":true,"data":{"dids":["did:example:123"]}}`))
+	}))
+	defer server.Close()
+
+	dids, err := NewDIDClient(server.URL, "admin-key").SuspendUserDIDs("user-1", "suspicious sign-in")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"did:example:123"}, dids)
+}
+
+func TestDIDClient_DeviceKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "admin-key", r.Header.Get("X-Admin-Key"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/admin/users/user-1/device-keys":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":"key-1","did":"did:example:123","verification_method":"did:example:123#device-key-1","status":"active"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/admin/users/user-1/device-keys/key-1/verify":
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"message":"hello","signature":"abcd"}`, string(body))
+			_, _ = w.Write([]byte(`{"success":true,"data":{"valid":true}}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/admin/users/user-1/device-keys/key-1":
+			_, _ = w.Write([]byte(`{"success":true,"message":"Device key revoked"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Device key not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewDIDClient(server.URL, "admin-key")
+
+	key, err := client.RegisterDeviceKey("user-1", &DeviceKeyRegisterRequest{DID: "did:example:123", Name: "phone"})
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key.ID)
+	assert.Equal(t, "did:example:123#device-key-1", key.VerificationMethod)
+
+	valid, err := client.VerifyDeviceSignature("user-1", "key-1", "hello", "abcd")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	require.NoError(t, client.RevokeDeviceKey("user-1", "key-1"))
+
+	var apiErr *APIError
+	require.ErrorAs(t, client.RevokeDeviceKey("user-1", "key-2"), &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestDIDClient_WalletOffers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "admin-key", r.Header.Get("X-Admin-Key"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/admin/wallet-offers":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) == `{"did":"did:example:pending"}` {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error":"DID cannot be offered yet","details":"DID is not active yet"}`))
+				return
+			}
+			assert.JSONEq(t, `{"did":"did:example:123","credential_ids":["credential-1"]}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success":true,"data":{"offer":{"id":"offer-1","did":"did:example:123","status":"pending"},"claim_uri":"didwallet://claim?code=abc","qr_image":"data:image/png;base64,AA=="}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/admin/wallet-offers/offer-1":
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":"offer-1","did":"did:example:123","status":"claimed"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Wallet offer not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewDIDClient(server.URL, "admin-key")
+
+	response, err := client.CreateWalletOffer(&WalletOfferRequest{DID: "did:example:123", CredentialIDs: []string{"credential-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "offer-1", response.Offer.ID)
+	assert.Equal(t, "didwallet://claim?code=abc", response.ClaimURI)
+
+	_, err = client.CreateWalletOffer(&WalletOfferRequest{DID: "did:example:pending"})
+	assert.ErrorIs(t, err, ErrDIDNotActive)
+	assert.NotErrorIs(t, err, ErrDuplicateDID)
+
+	offer, err := client.GetWalletOffer("offer-1")
+	require.NoError(t, err)
+	assert.Equal(t, "claimed", offer.Status)
+}
@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GeoIPClient locates IP addresses with an HTTP geolocation service answering
+// {"latitude": ..., "longitude": ...}, such as ipapi.co
+type GeoIPClient struct {
+	urlTemplate string
+	httpClient  *http.Client
+}
+
+// NewGeoIPClient creates a geolocation client; {ip} in urlTemplate is replaced by the
+// address to locate, e.g. https://ipapi.co/{ip}/json/
+func NewGeoIPClient(urlTemplate string) *GeoIPClient {
+	return &GeoIPClient{
+		urlTemplate: urlTemplate,
+		httpClient: &http.Client{
+			Timeout: 3 * time.Second,
+		},
+	}
+}
+
+// Locate returns the latitude and longitude of an IP address
+func (c *GeoIPClient) Locate(ctx context.Context, ipAddress string) (float64, float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(c.urlTemplate, "{ip}", url.PathEscape(ipAddress)), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var location struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := json.Unmarshal(body, &location); err != nil {
+		return 0, 0, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if location.Latitude == nil || location.Longitude == nil {
+		return 0, 0, fmt.Errorf("no location for %s", ipAddress)
+	}
+
+	return *location.Latitude, *location.Longitude, nil
+}
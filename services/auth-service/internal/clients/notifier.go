@@ -0,0 +1,182 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"auth-service/internal/i18n"
+)
+
+// noticeTimeLayout formats the times in notice texts
+const noticeTimeLayout = "2006-01-02 15:04 MST"
+
+// Notice types, telling a webhook shared by several notices apart
+const (
+	NoticePasswordReset     = "password_reset"
+	NoticeLoginAnomaly      = "login_anomaly"
+	NoticeEmailVerification = "email_verification"
+	NoticeWalletOnboarding  = "wallet_onboarding"
+)
+
+// WebhookNotifier hands notices for users, such as password reset tokens, to the
+// service that delivers them, such as a mail relay. Notices are posted with their
+// subject and text rendered in the user's locale.
+type WebhookNotifier struct {
+	webhookURL    string
+	defaultLocale string
+	httpClient    *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting notices to webhookURL
+func NewWebhookNotifier(webhookURL string) *WebhookNotifier {
+	return &WebhookNotifier{
+		webhookURL:    webhookURL,
+		defaultLocale: i18n.DefaultLocale,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// SetDefaultLocale sets the locale of notices to users without a supported locale
+func (n *WebhookNotifier) SetDefaultLocale(locale string) error {
+	supported, err := i18n.Notices.Supported(locale)
+	if err != nil {
+		return err
+	}
+	n.defaultLocale = supported
+	return nil
+}
+
+// NoticeText is a notice rendered for the user: its subject and text in Locale, which
+// the sender sets to the user's locale and the notifier to the locale rendered in
+type NoticeText struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// text returns the rendered text of a notice
+func (t *NoticeText) text() *NoticeText {
+	return t
+}
+
+// localizedNotice is a notice the notifier renders before posting it
+type localizedNotice interface {
+	text() *NoticeText
+	// template returns the notice type and the arguments of its text
+	template() (string, []any)
+}
+
+// PasswordResetNotice is the body posted for every password reset request
+type PasswordResetNotice struct {
+	NoticeText
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EmailVerificationNotice is the body posted to confirm a new user's email address
+type EmailVerificationNotice struct {
+	NoticeText
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// DID is the user's DID, if signup created one
+	DID string `json:"did,omitempty"`
+}
+
+// WalletOnboardingNotice is the body posted once a new user's DID is active, with the
+// one-time link importing it and their base credential into a mobile wallet
+type WalletOnboardingNotice struct {
+	NoticeText
+	Type  string `json:"type"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	DID   string `json:"did"`
+	// ClaimURI is the wallet deep link and QRImage the same link as a PNG data URI
+	ClaimURI  string    `json:"claim_uri"`
+	QRImage   string    `json:"qr_image"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginAnomalyNotice is the body posted when a sign-in to a user's account looked
+// suspicious
+type LoginAnomalyNotice struct {
+	NoticeText
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Signals   []string  `json:"signals"`
+	Details   []string  `json:"details"`
+	IPAddress string    `json:"ip_address"`
+	SignedIn  time.Time `json:"signed_in_at"`
+	// SuspendedDIDs are the user's DIDs suspended in response
+	SuspendedDIDs []string `json:"suspended_dids"`
+}
+
+// template returns the notice type and the arguments of its text
+func (n *PasswordResetNotice) template() (string, []any) {
+	return n.Type, []any{n.Name, n.Token, n.ExpiresAt.UTC().Format(noticeTimeLayout)}
+}
+
+// template returns the notice type and the arguments of its text
+func (n *EmailVerificationNotice) template() (string, []any) {
+	return n.Type, []any{n.Name, n.Token, n.ExpiresAt.UTC().Format(noticeTimeLayout)}
+}
+
+// template returns the notice type and the arguments of its text
+func (n *WalletOnboardingNotice) template() (string, []any) {
+	return n.Type, []any{n.Name, n.ClaimURI, n.ExpiresAt.UTC().Format(noticeTimeLayout)}
+}
+
+// template returns the notice type and the arguments of its text
+func (n *LoginAnomalyNotice) template() (string, []any) {
+	return n.Type, []any{n.Name, n.IPAddress, n.SignedIn.UTC().Format(noticeTimeLayout)}
+}
+
+// render sets the subject and text of a notice in the user's locale, or the default
+// locale when it is not supported
+func (n *WebhookNotifier) render(notice localizedNotice) {
+	text := notice.text()
+	locale, err := i18n.Notices.Supported(text.Locale)
+	if text.Locale == "" || err != nil {
+		locale = n.defaultLocale
+	}
+
+	noticeType, args := notice.template()
+	text.Locale = locale
+	text.Subject = i18n.Notices.Message(locale, noticeType+".subject")
+	text.Text = i18n.Notices.Message(locale, noticeType+".text", args...)
+}
+
+// Send posts a notice to the webhook
+func (n *WebhookNotifier) Send(notice any) error {
+	if notice, ok := notice.(localizedNotice); ok {
+		n.render(notice)
+	}
+
+	jsonData, err := json.Marshal(notice)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notice: %w", err)
+	}
+
+	resp, err := n.httpClient.Post(n.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to post notice: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package clients
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notice PasswordResetNotice
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notice))
+		assert.Equal(t, NoticePasswordReset, notice.Type)
+		assert.Equal(t, "ada@example.com", notice.Email)
+		assert.Equal(t, "token", notice.Token)
+		assert.True(t, expiresAt.Equal(notice.ExpiresAt))
+		assert.Equal(t, "en", notice.Locale)
+		assert.Equal(t, "Reset your password", notice.Subject)
+		assert.Contains(t, notice.Text, "Hi Ada")
+		assert.Contains(t, notice.Text, "token")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL).Send(&PasswordResetNotice{
+		Type:      NoticePasswordReset,
+		Email:     "ada@example.com",
+		Name:      "Ada",
+		Token:     "token",
+		ExpiresAt: expiresAt,
+	})
+	assert.NoError(t, err)
+}
+
+func TestWebhookNotifier_SendLocalized(t *testing.T) {
+	var notice LoginAnomalyNotice
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notice))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL)
+	err := notifier.Send(&LoginAnomalyNotice{
+		NoticeText: NoticeText{Locale: "es"},
+		Type:       NoticeLoginAnomaly,
+		Name:       "Ada",
+		IPAddress:  "203.0.113.7",
+		SignedIn:   time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "es", notice.Locale)
+	assert.Equal(t, "Inicio de sesión inusual en tu cuenta", notice.Subject)
+	assert.Contains(t, notice.Text, "203.0.113.7 el 2026-10-16 12:30 UTC")
+
+	// Unsupported locales fall back to the notifier's default
+	require.NoError(t, notifier.SetDefaultLocale("fr-CA"))
+	err = notifier.Send(&LoginAnomalyNotice{
+		NoticeText: NoticeText{Locale: "ja"},
+		Type:       NoticeLoginAnomaly,
+		Name:       "Ada",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fr", notice.Locale)
+	assert.Equal(t, "Connexion inhabituelle à votre compte", notice.Subject)
+
+	assert.Error(t, notifier.SetDefaultLocale("ja"))
+}
+
+func TestWebhookNotifier_SendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL).Send(&PasswordResetNotice{Email: "ada@example.com"})
+	assert.Error(t, err)
+}
@@ -2,14 +2,21 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
 	"time"
 
 	"api/auth/v1/proto"
 	"auth-service/internal/services"
+	"auth-service/internal/services/signup"
 	"auth-service/models"
 	zlog "packages/logger"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -40,12 +47,16 @@ func (h *AuthHandler) SignUp(ctx context.Context, req *proto.UserCreateRequest)
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
+		Locale:   acceptLanguage(ctx),
 	}
 
 	// Call service
 	user, err := h.service.Auth.SignUp(ctx, userReq)
 	if err != nil {
 		h.logger.Error(ctx, err, "SignUp failed", 400)
+		if errors.Is(err, signup.ErrDIDRequired) {
+			return nil, status.Errorf(codes.FailedPrecondition, "signup failed: %v", err)
+		}
 		return nil, status.Errorf(codes.InvalidArgument, "signup failed: %v", err)
 	}
 
@@ -88,11 +99,15 @@ func (h *AuthHandler) SignIn(ctx context.Context, req *proto.Credentials) (*prot
 		Email:    req.Email,
 		Password: req.Password,
 	}
+	creds.IPAddress, creds.UserAgent = signInClient(ctx, h.service.Config.TrustedProxyPrefixes())
 
 	// Call service with JWT secrets
 	user, accessToken, refreshToken, err := h.service.Auth.SignIn(ctx, creds, h.service.Config.JWTAccessTokenSecret, h.service.Config.JWTRefreshTokenSecret)
 	if err != nil {
 		h.logger.Error(ctx, err, "SignIn failed", 401)
+		if errors.Is(err, signup.ErrDIDRequired) {
+			return nil, status.Errorf(codes.FailedPrecondition, "signin failed: %v", err)
+		}
 		return nil, status.Errorf(codes.Unauthenticated, "signin failed: %v", err)
 	}
 
@@ -138,7 +153,7 @@ func (h *AuthHandler) RefreshToken(ctx context.Context, req *proto.RefreshTokenR
 	h.logger.Info(ctx, "Processing RefreshToken request")
 
 	// Call service with JWT secrets
-	tokens, err := h.service.Auth.RefreshToken(ctx, req.RefreshToken, h.service.Config.JWTAccessTokenSecret, h.service.Config.JWTRefreshTokenSecret)
+	tokens, err := h.service.Auth.RefreshToken(ctx, req.RefreshToken, deviceProof(ctx), h.service.Config.JWTAccessTokenSecret, h.service.Config.JWTRefreshTokenSecret)
 	if err != nil {
 		h.logger.Error(ctx, err, "RefreshToken failed", 400)
 		return nil, status.Errorf(codes.InvalidArgument, "token refresh failed: %v", err)
@@ -235,6 +250,88 @@ func (h *AuthHandler) ValidateToken(ctx context.Context, req *proto.ValidateToke
 	}, nil
 }
 
+// signInClient returns the address and user agent of the client signing in: the peer
+// address of direct gRPC calls, and for calls through the REST gateway, which connects
+// over loopback, the address the gateway received the request from. The gateway appends
+// it to X-Forwarded-For, so addresses further left are only believed as far as the
+// addresses after them are trustedProxies; the rest of the header is set by the client.
+func signInClient(ctx context.Context, trustedProxies []netip.Prefix) (ipAddress, userAgent string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ipAddress = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ipAddress); err == nil {
+			ipAddress = host
+		}
+	}
+	if addr, err := netip.ParseAddr(ipAddress); err == nil && (addr.Unmap().IsLoopback() || trusted(addr, trustedProxies)) {
+		ipAddress = forwardedClient(md.Get("x-forwarded-for"), trustedProxies, ipAddress)
+	}
+
+	for _, key := range []string{"grpcgateway-user-agent", "user-agent"} {
+		if values := md.Get(key); len(values) > 0 {
+			userAgent = values[0]
+			break
+		}
+	}
+	return ipAddress, userAgent
+}
+
+// forwardedClient walks X-Forwarded-For from the right, past trusted proxies, and
+// returns the first address not trusted, or fallback without a valid last address
+func forwardedClient(forwarded []string, trustedProxies []netip.Prefix, fallback string) string {
+	entries := strings.Split(strings.Join(forwarded, ","), ",")
+	client := fallback
+	for i := len(entries) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(entries[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap().String()
+		if !trusted(addr, trustedProxies) {
+			break
+		}
+	}
+	return client
+}
+
+// trusted reports whether an address is one of the trusted proxies
+func trusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptLanguage returns the Accept-Language header of a request through the REST
+// gateway, or the accept-language metadata of a direct gRPC call
+func acceptLanguage(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range []string{"grpcgateway-accept-language", "accept-language"} {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// deviceProof returns the device signature a refresh request carries in its
+// x-device-timestamp and x-device-signature metadata (Grpc-Metadata-X-Device-* headers
+// through the REST gateway), or nil without one
+func deviceProof(ctx context.Context) *models.DeviceProof {
+	md, _ := metadata.FromIncomingContext(ctx)
+	timestamps, signatures := md.Get("x-device-timestamp"), md.Get("x-device-signature")
+	if len(timestamps) == 0 || len(signatures) == 0 {
+		return nil
+	}
+	return &models.DeviceProof{
+		Timestamp: timestamps[0],
+		Signature: signatures[0],
+	}
+}
+
 // Helper functions to convert between internal models and protobuf messages
 
 func convertUserToProto(user *models.User) *proto.User {
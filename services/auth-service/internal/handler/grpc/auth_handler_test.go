@@ -1,6 +1,9 @@
 package grpc
 
 import (
+	"context"
+	"net"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -12,6 +15,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 func TestNewAuthHandler(t *testing.T) {
@@ -194,3 +199,44 @@ func BenchmarkConvertUserTokenToProto(b *testing.B) {
 		_ = convertUserTokenToProto(userToken)
 	}
 }
+
+func TestSignInClient(t *testing.T) {
+	gateway := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 41234}}
+	ctx := peer.NewContext(context.Background(), gateway)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		"x-forwarded-for", "198.51.100.9",
+		"grpcgateway-user-agent", "Mozilla/5.0",
+	))
+	ip, userAgent := signInClient(ctx, nil)
+	assert.Equal(t, "198.51.100.9", ip)
+	assert.Equal(t, "Mozilla/5.0", userAgent)
+
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.4"), Port: 50051}})
+	ip, userAgent = signInClient(ctx, nil)
+	assert.Equal(t, "198.51.100.4", ip)
+	assert.Empty(t, userAgent)
+}
+
+func TestSignInClient_SpoofedForwardedFor(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	gateway := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 41234}})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		forwarded string
+		want      string
+	}{
+		// The gateway appends the address it received the request from
+		{"client spoofing through the gateway", gateway, "203.0.113.7, 198.51.100.9", "198.51.100.9"},
+		{"client spoofing through a trusted proxy", gateway, "203.0.113.7, 198.51.100.9, 10.0.0.2", "198.51.100.9"},
+		{"proxy not trusted", gateway, "203.0.113.7, 192.0.2.10", "192.0.2.10"},
+		{"malformed entry", gateway, "203.0.113.7, bogus, 10.0.0.2", "10.0.0.2"},
+		{"direct call", peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.44"), Port: 50051}}), "203.0.113.7", "192.0.2.44"},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(tt.ctx, metadata.Pairs("x-forwarded-for", tt.forwarded))
+		ip, _ := signInClient(ctx, proxies)
+		assert.Equal(t, tt.want, ip, tt.name)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/services"
+	authentication "auth-service/internal/services/auth"
+	"auth-service/models"
+
+	zlog "packages/logger"
+)
+
+// DeviceHandler binds signed-in sessions to device keys registered on the user's DID
+type DeviceHandler struct {
+	service *services.Service
+	logger  *zlog.Logger
+}
+
+// NewDeviceHandler creates a new device handler
+func NewDeviceHandler(service *services.Service, logger *zlog.Logger) *DeviceHandler {
+	return &DeviceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the device binding endpoint on mux
+func (h *DeviceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/sessions/current/device", h.handleBind)
+}
+
+// handleBind registers the device's key on the user's DID and binds the session making
+// the request to it
+func (h *DeviceHandler) handleBind(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	user, err := sessionUser(h.service, r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+	accessToken, _ := bearerToken(r)
+
+	var req models.DeviceBindRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.PublicKey == "" || req.Signature == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name, public_key and signature are required"})
+		return
+	}
+
+	key, err := h.service.Auth.BindDevice(r.Context(), user, accessToken, &req)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, key)
+}
+
+// writeServiceError maps device binding errors to responses
+func (h *DeviceHandler) writeServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+	var apiErr *clients.APIError
+	switch {
+	case errors.Is(err, authentication.ErrNoDID), errors.Is(err, authentication.ErrSessionAlreadyBound):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, authentication.ErrDeviceBindingUnavailable):
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest:
+		// The DID Manager rejected the key or its proof of possession
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": apiErr.Message, "details": apiErr.Details})
+	default:
+		h.logger.Error(ctx, err, "device binding failed", http.StatusInternalServerError)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
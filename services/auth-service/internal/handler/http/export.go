@@ -0,0 +1,56 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"auth-service/internal/services"
+
+	zlog "packages/logger"
+)
+
+// ExportHandler serves users' self-service data exports, which answer data-subject
+// access requests with a downloadable JSON archive
+type ExportHandler struct {
+	service *services.Service
+	logger  *zlog.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(service *services.Service, logger *zlog.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the export endpoint on mux
+func (h *ExportHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/users/me/export", h.handleExport)
+}
+
+// handleExport returns the signed-in user's profile, consent receipts and identity
+// data as a JSON attachment
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	user, err := sessionUser(h.service, r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	export, err := h.service.Export.ExportUser(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), err, "user data export failed", http.StatusInternalServerError)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to export user data"})
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-data-%s.json"`, export.ExportedAt.Format("20060102")))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, export)
+}
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"auth-service/internal/repository"
+	"auth-service/internal/services"
+	"auth-service/internal/services/oauth"
+	"auth-service/models"
+
+	zlog "packages/logger"
+)
+
+// OAuthHandler serves the OAuth endpoints third-party wallet applications use to obtain
+// scoped tokens. These are plain HTTP handlers because the authorization code flow is
+// form/redirect based and does not map onto the gRPC API.
+type OAuthHandler struct {
+	service *services.Service
+	logger  *zlog.Logger
+}
+
+// NewOAuthHandler creates a new OAuth handler
+func NewOAuthHandler(service *services.Service, logger *zlog.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Session authentication errors, worded for the client
+var (
+	errBearerRequired = errors.New("bearer token required")
+	errInvalidBearer  = errors.New("invalid or expired token")
+)
+
+// registerClientRequest is the body of a client registration request
+type registerClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scope        string   `json:"scope"`
+}
+
+// registerServiceClientRequest is the body of a service client registration request
+type registerServiceClientRequest struct {
+	Name   string `json:"name"`
+	Tenant string `json:"tenant"`
+	Scope  string `json:"scope"`
+}
+
+// consentRequest is the body of a consent decision
+type consentRequest struct {
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	Scope       string `json:"scope"`
+	State       string `json:"state"`
+	Approve     bool   `json:"approve"`
+}
+
+// RegisterRoutes registers the OAuth endpoints on mux
+func (h *OAuthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/oauth/clients", h.handleClients)
+	mux.HandleFunc("/v1/oauth/consent", h.handleConsent)
+	mux.HandleFunc("/v1/oauth/token", h.handleToken)
+	mux.HandleFunc("/v1/admin/oauth/service-clients", h.handleServiceClients)
+}
+
+// handleClients registers a new client owned by the signed-in user
+func (h *OAuthHandler) handleClients(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+		return
+	}
+	if !h.enabled(w) {
+		return
+	}
+
+	user, ok := h.authenticateUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req registerClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	client, secret, err := h.service.OAuth.RegisterClient(r.Context(), user.ID, req.Name, req.RedirectURIs, req.Scope)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"client":        client,
+		"client_secret": secret,
+	})
+}
+
+// handleServiceClients registers a service client for a DID Manager tenant
+func (h *OAuthHandler) handleServiceClients(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+		return
+	}
+	if !authorizeAdmin(h.service, w, r) {
+		return
+	}
+
+	var req registerServiceClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	client, secret, err := h.service.OAuth.RegisterServiceClient(r.Context(), req.Name, req.Tenant, req.Scope)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	h.logger.Info(r.Context(), "service client registered by admin", map[string]any{
+		"client_id": client.ClientID,
+		"tenant":    client.Tenant,
+		"scopes":    client.AllowedScopes,
+	})
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"client":        client,
+		"client_secret": secret,
+	})
+}
+
+// handleConsent returns consent screen data (GET), records a decision (POST) or
+// withdraws earlier consent (DELETE)
+func (h *OAuthHandler) handleConsent(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled(w) {
+		return
+	}
+
+	user, ok := h.authenticateUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		screen, err := h.service.OAuth.ConsentScreen(ctx, user.ID, query.Get("client_id"), query.Get("redirect_uri"), query.Get("scope"))
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, screen)
+
+	case http.MethodPost:
+		var req consentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+			return
+		}
+
+		redirect, err := url.Parse(req.RedirectURI)
+		if err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid redirect_uri")
+			return
+		}
+
+		params := redirect.Query()
+		if req.State != "" {
+			params.Set("state", req.State)
+		}
+
+		if !req.Approve {
+			params.Set("error", "access_denied")
+		} else {
+			code, err := h.service.OAuth.Approve(ctx, user.ID, req.ClientID, req.RedirectURI, req.Scope)
+			if err != nil {
+				h.writeServiceError(ctx, w, err)
+				return
+			}
+			params.Set("code", code)
+		}
+
+		redirect.RawQuery = params.Encode()
+		writeJSON(w, http.StatusOK, map[string]string{"redirect_uri": redirect.String()})
+
+	case http.MethodDelete:
+		if err := h.service.OAuth.RevokeConsent(ctx, user.ID, r.URL.Query().Get("client_id")); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+	}
+}
+
+// handleToken exchanges an authorization code for a wallet access token, or issues a
+// service client a DID Manager service token with the client credentials grant
+func (h *OAuthHandler) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid form body")
+		return
+	}
+
+	// Client credentials may come from HTTP Basic auth or the form body
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	var token *oauth.TokenResponse
+	var err error
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		if !h.enabled(w) {
+			return
+		}
+		token, err = h.service.OAuth.ExchangeCode(r.Context(), clientID, clientSecret, r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"))
+	case "client_credentials":
+		if !h.service.OAuth.ServiceTokensEnabled() {
+			writeOAuthError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "service tokens are not configured")
+			return
+		}
+		token, err = h.service.OAuth.IssueServiceToken(r.Context(), clientID, clientSecret, r.PostForm.Get("scope"))
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code and client_credentials are supported")
+		return
+	}
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, token)
+}
+
+// enabled reports whether wallet tokens can be issued, writing an error if not
+func (h *OAuthHandler) enabled(w http.ResponseWriter) bool {
+	if h.service.Config.WalletTokenSecret == "" {
+		writeOAuthError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "wallet API access is not configured")
+		return false
+	}
+	return true
+}
+
+// authenticateUser validates the user's session bearer token
+func (h *OAuthHandler) authenticateUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, err := sessionUser(h.service, r)
+	if err != nil {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+		return nil, false
+	}
+	return user, true
+}
+
+// sessionUser returns the user whose session bearer token authenticates the request
+func sessionUser(service *services.Service, r *http.Request) (*models.User, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, errBearerRequired
+	}
+
+	user, err := service.Auth.ValidateToken(r.Context(), token, service.Config.JWTAccessTokenSecret)
+	if err != nil {
+		return nil, errInvalidBearer
+	}
+	return user, nil
+}
+
+// bearerToken returns the access token of the request's Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		return "", false
+	}
+	return token, true
+}
+
+// writeServiceError maps OAuth service errors to RFC 6749 error responses
+func (h *OAuthHandler) writeServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, oauth.ErrInvalidClient):
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+	case errors.Is(err, oauth.ErrInvalidGrant):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "authorization code is invalid, expired or already used")
+	case errors.Is(err, oauth.ErrInvalidScope):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+	case errors.Is(err, oauth.ErrUnauthorizedClient):
+		writeOAuthError(w, http.StatusBadRequest, "unauthorized_client", "client is not registered for this grant")
+	case errors.Is(err, oauth.ErrInvalidRedirectURI):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "redirect_uri is not registered for this client")
+	case errors.Is(err, oauth.ErrInvalidRequest):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, repository.ErrOAuthNotFound):
+		writeOAuthError(w, http.StatusNotFound, "invalid_request", "no consent found for this client")
+	default:
+		h.logger.Error(ctx, err, "oauth request failed", http.StatusInternalServerError)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "internal server error")
+	}
+}
+
+// writeOAuthError writes an RFC 6749 style error body
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
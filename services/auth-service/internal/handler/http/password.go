@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"auth-service/internal/services"
+	authentication "auth-service/internal/services/auth"
+	"auth-service/models"
+	"auth-service/utils"
+
+	zlog "packages/logger"
+)
+
+// PasswordHandler serves password changes for signed-in users and the password reset
+// flow for users who forgot theirs
+type PasswordHandler struct {
+	service *services.Service
+	logger  *zlog.Logger
+}
+
+// NewPasswordHandler creates a new password handler
+func NewPasswordHandler(service *services.Service, logger *zlog.Logger) *PasswordHandler {
+	return &PasswordHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the password endpoints on mux
+func (h *PasswordHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/users/me/password", h.handleChange)
+	mux.HandleFunc("/v1/password/reset-request", h.handleResetRequest)
+	mux.HandleFunc("/v1/password/reset", h.handleReset)
+}
+
+// handleChange replaces the signed-in user's password; all of their sessions, including
+// the one making the request, are signed out
+func (h *PasswordHandler) handleChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	user, err := sessionUser(h.service, r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req models.PasswordChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CurrentPassword == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "current_password and new_password are required"})
+		return
+	}
+	if !h.validNewPassword(w, req.NewPassword) {
+		return
+	}
+
+	if err := h.service.Auth.ChangePassword(r.Context(), user.ID, &req); err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleResetRequest sends a reset token to the account's email. It is accepted whether
+// or not an account uses the email.
+func (h *PasswordHandler) handleResetRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req models.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email is required"})
+		return
+	}
+
+	if err := h.service.Auth.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "if an account uses this email, a password reset token has been sent"})
+}
+
+// handleReset sets a new password with a reset token
+func (h *PasswordHandler) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req models.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "token and new_password are required"})
+		return
+	}
+	if !h.validNewPassword(w, req.NewPassword) {
+		return
+	}
+
+	if err := h.service.Auth.ResetPassword(r.Context(), &req); err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validNewPassword checks a new password against the password policy, writing an error
+// if it falls short
+func (h *PasswordHandler) validNewPassword(w http.ResponseWriter, password string) bool {
+	cfg := h.service.Config
+	err := utils.ValidatePasswordStrength(password, cfg.MinPasswordLength,
+		cfg.RequireUppercase, cfg.RequireLowercase, cfg.RequireNumbers, cfg.RequireSpecialChars)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeServiceError maps password service errors to responses
+func (h *PasswordHandler) writeServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authentication.ErrIncorrectPassword):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, authentication.ErrInvalidResetToken):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, authentication.ErrPasswordResetDisabled):
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	default:
+		h.logger.Error(ctx, err, "password request failed", http.StatusInternalServerError)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
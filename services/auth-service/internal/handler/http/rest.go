@@ -35,6 +35,8 @@ type RESTGateway struct {
 	grpcAddr    string
 	tlsEnabled  bool
 	tlsConfig   any
+	// routes registers plain HTTP handlers that are served alongside the gateway
+	routes []func(mux *http.ServeMux)
 }
 
 // NewRESTGateway creates a new REST gateway instance
@@ -46,6 +48,12 @@ func NewRESTGateway(cfg *config.GatewayConfig, logger *zlog.Logger) *RESTGateway
 	}
 }
 
+// AddRoutes registers plain HTTP handlers on the gateway mux; it must be called
+// before CreateGateway
+func (g *RESTGateway) AddRoutes(register func(mux *http.ServeMux)) {
+	g.routes = append(g.routes, register)
+}
+
 // CreateGateway creates the REST gateway server and listener
 func (g *RESTGateway) CreateGateway(ctx context.Context, grpcAddr string, tlsEnabled bool, tlsConfig any) error {
 	// Create REST listener
@@ -87,6 +95,11 @@ func (g *RESTGateway) CreateGateway(ctx context.Context, grpcAddr string, tlsEna
 	// Register custom health endpoints
 	g.registerCustomHealthEndpoints(customMux)
 
+	// Register plain HTTP handlers
+	for _, register := range g.routes {
+		register(customMux)
+	}
+
 	// Register gRPC gateway handlers
 	if err := g.registerHandlers(ctx, gwMux); err != nil {
 		return fmt.Errorf("failed to register REST handlers: %w", err)
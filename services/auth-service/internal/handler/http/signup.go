@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"auth-service/internal/repository"
+	"auth-service/internal/services"
+	authentication "auth-service/internal/services/auth"
+	"auth-service/internal/services/signup"
+	"auth-service/models"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+)
+
+// Limits of the stuck signup saga listing
+const (
+	defaultStuckSagaLimit = 50
+	maxStuckSagaLimit     = 200
+)
+
+// SignupHandler serves email verification and wallet onboarding for new users and the
+// administrators' view of signup sagas that are stuck
+type SignupHandler struct {
+	service *services.Service
+	logger  *zlog.Logger
+}
+
+// NewSignupHandler creates a new signup handler
+func NewSignupHandler(service *services.Service, logger *zlog.Logger) *SignupHandler {
+	return &SignupHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the signup endpoints on mux
+func (h *SignupHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/email/verify", h.handleVerifyEmail)
+	mux.HandleFunc("/v1/users/me/wallet-offer", h.handleWalletOffer)
+	mux.HandleFunc("/v1/admin/signup-sagas", h.handleListStuck)
+	mux.HandleFunc("/v1/admin/signup-sagas/{id}/retry", h.handleRetry)
+}
+
+// handleVerifyEmail confirms a user's email address with the token sent on signup
+func (h *SignupHandler) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req models.EmailVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "token is required"})
+		return
+	}
+
+	if err := h.service.Auth.VerifyEmail(r.Context(), req.Token); err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWalletOffer reports whether the user claimed the wallet link sent on signup
+func (h *SignupHandler) handleWalletOffer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	user, err := sessionUser(h.service, r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.service.Signup.WalletOffer(r.Context(), user.ID)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, offer)
+}
+
+// handleListStuck lists failed signup sagas and running ones retrying a failed step,
+// oldest first
+func (h *SignupHandler) handleListStuck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if !authorizeAdmin(h.service, w, r) {
+		return
+	}
+
+	limit := defaultStuckSagaLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxStuckSagaLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = parsed
+	}
+
+	sagas, err := h.service.Signup.Stuck(r.Context(), limit)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sagas": sagas})
+}
+
+// handleRetry resumes a failed signup saga at the step it failed on
+func (h *SignupHandler) handleRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if !authorizeAdmin(h.service, w, r) {
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid saga ID"})
+		return
+	}
+
+	saga, err := h.service.Signup.Retry(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saga)
+}
+
+// authorizeAdmin checks the admin key in X-Admin-Key, writing an error if it is
+// missing or wrong. Admin endpoints are disabled without ADMIN_API_KEY.
+func authorizeAdmin(service *services.Service, w http.ResponseWriter, r *http.Request) bool {
+	adminKey := service.Config.AdminAPIKey
+	if adminKey == "" {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin API is disabled"})
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Key")), []byte(adminKey)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid admin key"})
+		return false
+	}
+	return true
+}
+
+// writeServiceError maps signup service errors to responses
+func (h *SignupHandler) writeServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authentication.ErrInvalidVerificationToken):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repository.ErrSignupSagaNotFound), errors.Is(err, signup.ErrNoWalletOffer):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, signup.ErrNotRetryable):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		h.logger.Error(ctx, err, "signup request failed", http.StatusInternalServerError)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,81 @@
+// Package i18n holds the translated notices sent to users and negotiates the locale
+// they are sent in, with a catalog of the shared packages/i18n.
+package i18n
+
+import catalogs "packages/i18n"
+
+// DefaultLocale is the locale of notices to users without a supported locale, unless
+// configured otherwise
+const DefaultLocale = "en"
+
+// noticeLocales are the locales notices are translated to, English first as the
+// fallback for untranslated messages
+var noticeLocales = []string{"en", "es", "fr", "de", "pt"}
+
+// noticeMessages holds the subject and text of each notice type by locale. Texts are
+// formatted with the user's name, the notice's token or link, and a time: the token or
+// link's expiry, or the time of a suspicious sign-in.
+var noticeMessages = map[string]map[string]string{
+	"en": {
+		"password_reset.subject":     "Reset your password",
+		"password_reset.text":        "Hi %[1]s,\n\nUse this code to reset your password: %[2]s\n\nThe code expires at %[3]s. If you did not ask to reset your password, you can ignore this email.",
+		"email_verification.subject": "Confirm your email address",
+		"email_verification.text":    "Hi %[1]s,\n\nUse this code to confirm your email address: %[2]s\n\nThe code expires at %[3]s.",
+		"wallet_onboarding.subject":  "Add your digital identity to your wallet",
+		"wallet_onboarding.text":     "Hi %[1]s,\n\nYour digital identity is ready. Open this link on your phone to add it to your wallet: %[2]s\n\nThe link can be used once and expires at %[3]s.",
+		"login_anomaly.subject":      "Unusual sign-in to your account",
+		"login_anomaly.text":         "Hi %[1]s,\n\nWe noticed an unusual sign-in to your account from %[2]s at %[3]s. If this was not you, reset your password now.",
+	},
+	"es": {
+		"password_reset.subject":     "Restablece tu contraseña",
+		"password_reset.text":        "Hola, %[1]s:\n\nUsa este código para restablecer tu contraseña: %[2]s\n\nEl código caduca el %[3]s. Si no solicitaste restablecer tu contraseña, puedes ignorar este correo.",
+		"email_verification.subject": "Confirma tu dirección de correo",
+		"email_verification.text":    "Hola, %[1]s:\n\nUsa este código para confirmar tu dirección de correo: %[2]s\n\nEl código caduca el %[3]s.",
+		"wallet_onboarding.subject":  "Añade tu identidad digital a tu billetera",
+		"wallet_onboarding.text":     "Hola, %[1]s:\n\nTu identidad digital está lista. Abre este enlace en tu teléfono para añadirla a tu billetera: %[2]s\n\nEl enlace se puede usar una vez y caduca el %[3]s.",
+		"login_anomaly.subject":      "Inicio de sesión inusual en tu cuenta",
+		"login_anomaly.text":         "Hola, %[1]s:\n\nDetectamos un inicio de sesión inusual en tu cuenta desde %[2]s el %[3]s. Si no fuiste tú, restablece tu contraseña ahora.",
+	},
+	"fr": {
+		"password_reset.subject":     "Réinitialisez votre mot de passe",
+		"password_reset.text":        "Bonjour %[1]s,\n\nUtilisez ce code pour réinitialiser votre mot de passe : %[2]s\n\nLe code expire le %[3]s. Si vous n'avez pas demandé de réinitialisation, ignorez cet e-mail.",
+		"email_verification.subject": "Confirmez votre adresse e-mail",
+		"email_verification.text":    "Bonjour %[1]s,\n\nUtilisez ce code pour confirmer votre adresse e-mail : %[2]s\n\nLe code expire le %[3]s.",
+		"wallet_onboarding.subject":  "Ajoutez votre identité numérique à votre portefeuille",
+		"wallet_onboarding.text":     "Bonjour %[1]s,\n\nVotre identité numérique est prête. Ouvrez ce lien sur votre téléphone pour l'ajouter à votre portefeuille : %[2]s\n\nLe lien est à usage unique et expire le %[3]s.",
+		"login_anomaly.subject":      "Connexion inhabituelle à votre compte",
+		"login_anomaly.text":         "Bonjour %[1]s,\n\nNous avons détecté une connexion inhabituelle à votre compte depuis %[2]s le %[3]s. Si ce n'était pas vous, réinitialisez votre mot de passe dès maintenant.",
+	},
+	"de": {
+		"password_reset.subject":     "Passwort zurücksetzen",
+		"password_reset.text":        "Hallo %[1]s,\n\nmit diesem Code setzt du dein Passwort zurück: %[2]s\n\nDer Code läuft am %[3]s ab. Wenn du das Zurücksetzen nicht angefordert hast, kannst du diese E-Mail ignorieren.",
+		"email_verification.subject": "Bestätige deine E-Mail-Adresse",
+		"email_verification.text":    "Hallo %[1]s,\n\nmit diesem Code bestätigst du deine E-Mail-Adresse: %[2]s\n\nDer Code läuft am %[3]s ab.",
+		"wallet_onboarding.subject":  "Füge deine digitale Identität deiner Wallet hinzu",
+		"wallet_onboarding.text":     "Hallo %[1]s,\n\ndeine digitale Identität ist bereit. Öffne diesen Link auf deinem Smartphone, um sie deiner Wallet hinzuzufügen: %[2]s\n\nDer Link ist einmal verwendbar und läuft am %[3]s ab.",
+		"login_anomaly.subject":      "Ungewöhnliche Anmeldung bei deinem Konto",
+		"login_anomaly.text":         "Hallo %[1]s,\n\nwir haben eine ungewöhnliche Anmeldung bei deinem Konto von %[2]s am %[3]s bemerkt. Wenn das nicht du warst, setze jetzt dein Passwort zurück.",
+	},
+	"pt": {
+		"password_reset.subject":     "Redefina a sua senha",
+		"password_reset.text":        "Olá, %[1]s,\n\nUse este código para redefinir a sua senha: %[2]s\n\nO código expira em %[3]s. Se você não pediu para redefinir a senha, ignore este e-mail.",
+		"email_verification.subject": "Confirme o seu endereço de e-mail",
+		"email_verification.text":    "Olá, %[1]s,\n\nUse este código para confirmar o seu endereço de e-mail: %[2]s\n\nO código expira em %[3]s.",
+		"wallet_onboarding.subject":  "Adicione a sua identidade digital à sua carteira",
+		"wallet_onboarding.text":     "Olá, %[1]s,\n\nA sua identidade digital está pronta. Abra este link no seu celular para adicioná-la à sua carteira: %[2]s\n\nO link pode ser usado uma vez e expira em %[3]s.",
+		"login_anomaly.subject":      "Acesso incomum à sua conta",
+		"login_anomaly.text":         "Olá, %[1]s,\n\nNotamos um acesso incomum à sua conta a partir de %[2]s em %[3]s. Se não foi você, redefina a sua senha agora.",
+	},
+}
+
+// Notices is the catalog of notices sent to users
+var Notices = mustCatalog(noticeMessages, noticeLocales...)
+
+// mustCatalog creates a built-in catalog, which is known to be valid
+func mustCatalog(messages map[string]map[string]string, locales ...string) *catalogs.Catalog {
+	catalog, err := catalogs.NewCatalog(messages, locales...)
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
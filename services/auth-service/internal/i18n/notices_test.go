@@ -0,0 +1,16 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotices_Complete(t *testing.T) {
+	for _, locale := range Notices.Locales() {
+		for key := range noticeMessages[DefaultLocale] {
+			_, ok := noticeMessages[locale][key]
+			assert.True(t, ok, "%s has no %s notice message", locale, key)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	insertLoginAttemptQuery = `
+		INSERT INTO login_attempts (
+			user_id,
+			email,
+			ip_address,
+			user_agent,
+			latitude,
+			longitude,
+			success
+		) VALUES (
+			:user_id,
+			:email,
+			:ip_address,
+			:user_agent,
+			:latitude,
+			:longitude,
+			:success
+		)
+		RETURNING id, occurred_at
+	`
+
+	previousLocatedLoginQuery = `
+		SELECT id, user_id, email, ip_address, user_agent, latitude, longitude, success, occurred_at
+		FROM login_attempts
+		WHERE user_id = :user_id AND id < :before_id AND success = true
+			AND latitude IS NOT NULL AND longitude IS NOT NULL
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	countFailedLoginEmailsQuery = `
+		SELECT COUNT(DISTINCT email)
+		FROM login_attempts
+		WHERE ip_address = :ip_address AND success = false AND occurred_at >= :since
+	`
+)
+
+// ErrLoginAttemptNotFound is returned when no login attempt matches
+var ErrLoginAttemptNotFound = errors.New("login attempt not found")
+
+// RecordLoginAttempt stores a sign-in attempt, setting its ID and time
+func (db *DB) RecordLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	stmt, err := db.PrepareNamedContext(ctx, insertLoginAttemptQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert login attempt failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if err := stmt.QueryRowxContext(ctx, attempt).Scan(&attempt.ID, &attempt.OccurredAt); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert login attempt failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// PreviousLocatedLogin returns a user's last successful, located sign-in before the
+// attempt with ID beforeID
+func (db *DB) PreviousLocatedLogin(ctx context.Context, userID uuid.UUID, beforeID int64) (*models.LoginAttempt, error) {
+	params := map[string]any{
+		"user_id":   userID,
+		"before_id": beforeID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, previousLocatedLoginQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare previous login query failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var attempt models.LoginAttempt
+	if err := stmt.GetContext(ctx, &attempt, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLoginAttemptNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "previous login query failed", status)
+		return nil, mappedErr
+	}
+
+	return &attempt, nil
+}
+
+// CountFailedLoginEmails counts the distinct emails that failed to sign in from an IP
+// address since a time
+func (db *DB) CountFailedLoginEmails(ctx context.Context, ipAddress string, since time.Time) (int, error) {
+	params := map[string]any{
+		"ip_address": ipAddress,
+		"since":      since,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, countFailedLoginEmailsQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare failed login count failed", http.StatusInternalServerError)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var count int
+	if err := stmt.GetContext(ctx, &count, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "failed login count failed", status)
+		return 0, mappedErr
+	}
+
+	return count, nil
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Sessions bound to a device key registered on the user's DID
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS device_key_id UUID;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE user_tokens DROP COLUMN IF EXISTS device_key_id;
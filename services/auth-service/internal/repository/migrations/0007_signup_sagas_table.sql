@@ -0,0 +1,41 @@
+-- +goose Up
+-- Signup sagas: the steps of a user's signup run after the user is created, retried
+-- until they succeed. Sagas have no foreign key on users, so a compensated saga is kept
+-- after the user was deleted.
+CREATE TABLE IF NOT EXISTS signup_sagas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    step VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    did VARCHAR(255) NOT NULL DEFAULT '',
+    user_hash VARCHAR(64) NOT NULL DEFAULT '',
+    credential_id VARCHAR(64) NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signup_sagas_due ON signup_sagas(next_attempt_at) WHERE status = 'running';
+
+-- Single-use tokens sent by the saga's last step, confirming the user's email address
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used BOOLEAN DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS signup_sagas;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- The DID created for each user, checked on sign-in when the signup policy requires one
+ALTER TABLE users ADD COLUMN IF NOT EXISTS did VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS user_hash VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE users SET did = signup_sagas.did, user_hash = signup_sagas.user_hash
+FROM signup_sagas
+WHERE signup_sagas.user_id = users.id AND signup_sagas.did <> '';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE users DROP COLUMN IF EXISTS user_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS did;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Service clients are registered by administrators for a DID Manager tenant rather than
+-- owned by a user, and obtain DID Manager operation scopes with client credentials
+ALTER TABLE oauth_clients ALTER COLUMN owner_id DROP NOT NULL;
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DELETE FROM oauth_clients WHERE owner_id IS NULL;
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS tenant;
+ALTER TABLE oauth_clients ALTER COLUMN owner_id SET NOT NULL;
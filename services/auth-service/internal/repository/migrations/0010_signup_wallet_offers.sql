@@ -0,0 +1,10 @@
+-- +goose Up
+-- The signup saga's last step offers the user's DID and base credential to their mobile
+-- wallet; the saga tracks the offer until the user claims it
+ALTER TABLE signup_sagas ADD COLUMN IF NOT EXISTS wallet_offer_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE signup_sagas ADD COLUMN IF NOT EXISTS wallet_offer_status VARCHAR(16) NOT NULL DEFAULT '';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE signup_sagas DROP COLUMN IF EXISTS wallet_offer_status;
+ALTER TABLE signup_sagas DROP COLUMN IF EXISTS wallet_offer_id;
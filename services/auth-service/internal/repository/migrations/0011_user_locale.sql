@@ -0,0 +1,9 @@
+-- +goose Up
+-- Notices are rendered in the locale a user signed up with; empty for the default locale
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
+ALTER TABLE signup_sagas ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE signup_sagas DROP COLUMN IF EXISTS locale;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
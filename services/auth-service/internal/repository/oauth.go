@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	insertOAuthClientQuery = `
+		INSERT INTO oauth_clients (
+			client_id,
+			client_secret_hash,
+			name,
+			redirect_uris,
+			allowed_scopes,
+			owner_id,
+			tenant
+		) VALUES (
+			:client_id,
+			:client_secret_hash,
+			:name,
+			:redirect_uris,
+			:allowed_scopes,
+			:owner_id,
+			:tenant
+		)
+		RETURNING id, client_id, client_secret_hash, name, redirect_uris, allowed_scopes, owner_id, tenant, created_at
+	`
+
+	getOAuthClientQuery = `
+		SELECT
+			id,
+			client_id,
+			client_secret_hash,
+			name,
+			redirect_uris,
+			allowed_scopes,
+			owner_id,
+			tenant,
+			created_at
+		FROM oauth_clients
+		WHERE client_id = :client_id
+	`
+
+	upsertOAuthConsentQuery = `
+		INSERT INTO oauth_consents (
+			user_id,
+			client_id,
+			scopes
+		) VALUES (
+			:user_id,
+			:client_id,
+			:scopes
+		)
+		ON CONFLICT (user_id, client_id)
+		DO UPDATE SET scopes = EXCLUDED.scopes, granted_at = CURRENT_TIMESTAMP
+	`
+
+	getOAuthConsentQuery = `
+		SELECT
+			id,
+			user_id,
+			client_id,
+			scopes,
+			granted_at
+		FROM oauth_consents
+		WHERE user_id = :user_id AND client_id = :client_id
+	`
+
+	listOAuthConsentsByUserQuery = `
+		SELECT
+			id,
+			user_id,
+			client_id,
+			scopes,
+			granted_at
+		FROM oauth_consents
+		WHERE user_id = :user_id
+		ORDER BY granted_at ASC
+	`
+
+	deleteOAuthConsentQuery = `
+		DELETE FROM oauth_consents
+		WHERE user_id = :user_id AND client_id = :client_id
+	`
+
+	insertAuthorizationCodeQuery = `
+		INSERT INTO oauth_authorization_codes (
+			code_hash,
+			client_id,
+			user_id,
+			redirect_uri,
+			scopes,
+			expires_at
+		) VALUES (
+			:code_hash,
+			:client_id,
+			:user_id,
+			:redirect_uri,
+			:scopes,
+			:expires_at
+		)
+	`
+
+	consumeAuthorizationCodeQuery = `
+		UPDATE oauth_authorization_codes
+		SET used = true
+		WHERE code_hash = :code_hash AND used = false
+		RETURNING code_hash, client_id, user_id, redirect_uri, scopes, expires_at, used, created_at
+	`
+)
+
+// ErrOAuthNotFound is returned when an OAuth client, consent or code does not exist
+var ErrOAuthNotFound = errors.New("oauth record not found")
+
+// CreateOAuthClient registers a new OAuth client
+func (db *DB) CreateOAuthClient(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error) {
+	stmt, err := db.PrepareNamedContext(ctx, insertOAuthClientQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert oauth client failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var created models.OAuthClient
+	if err := stmt.GetContext(ctx, &created, client); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert oauth client failed", status)
+		return nil, mappedErr
+	}
+
+	db.logger.Info(ctx, "oauth client created successfully", map[string]any{
+		"client_id": created.ClientID,
+		"owner_id":  created.OwnerID,
+		"tenant":    created.Tenant,
+	})
+
+	return &created, nil
+}
+
+// GetOAuthClient retrieves an OAuth client by its public client ID
+func (db *DB) GetOAuthClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	params := map[string]any{
+		"client_id": clientID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, getOAuthClientQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare select oauth client failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var client models.OAuthClient
+	if err := stmt.GetContext(ctx, &client, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOAuthNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "select oauth client failed", status)
+		return nil, mappedErr
+	}
+
+	return &client, nil
+}
+
+// UpsertOAuthConsent records the scopes a user granted to a client, replacing earlier consent
+func (db *DB) UpsertOAuthConsent(ctx context.Context, userID uuid.UUID, clientID, scopes string) error {
+	params := map[string]any{
+		"user_id":   userID,
+		"client_id": clientID,
+		"scopes":    scopes,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, upsertOAuthConsentQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare upsert consent failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "upsert consent failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// GetOAuthConsent retrieves the consent a user granted to a client
+func (db *DB) GetOAuthConsent(ctx context.Context, userID uuid.UUID, clientID string) (*models.OAuthConsent, error) {
+	params := map[string]any{
+		"user_id":   userID,
+		"client_id": clientID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, getOAuthConsentQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare select consent failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var consent models.OAuthConsent
+	if err := stmt.GetContext(ctx, &consent, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOAuthNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "select consent failed", status)
+		return nil, mappedErr
+	}
+
+	return &consent, nil
+}
+
+// ListOAuthConsentsByUser retrieves every consent a user has granted, oldest first
+func (db *DB) ListOAuthConsentsByUser(ctx context.Context, userID uuid.UUID) ([]models.OAuthConsent, error) {
+	params := map[string]any{
+		"user_id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, listOAuthConsentsByUserQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare select consents failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	consents := []models.OAuthConsent{}
+	if err := stmt.SelectContext(ctx, &consents, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "select consents failed", status)
+		return nil, mappedErr
+	}
+
+	return consents, nil
+}
+
+// DeleteOAuthConsent withdraws a user's consent for a client
+func (db *DB) DeleteOAuthConsent(ctx context.Context, userID uuid.UUID, clientID string) error {
+	params := map[string]any{
+		"user_id":   userID,
+		"client_id": clientID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, deleteOAuthConsentQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare delete consent failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, params)
+	if err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "delete consent failed", status)
+		return mappedErr
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrOAuthNotFound
+	}
+
+	return nil
+}
+
+// StoreAuthorizationCode stores a hashed authorization code
+func (db *DB) StoreAuthorizationCode(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	stmt, err := db.PrepareNamedContext(ctx, insertAuthorizationCodeQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert authorization code failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, code); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert authorization code failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// ConsumeAuthorizationCode marks an unused authorization code as used and returns it
+func (db *DB) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error) {
+	params := map[string]any{
+		"code_hash": codeHash,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, consumeAuthorizationCodeQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare consume authorization code failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var code models.OAuthAuthorizationCode
+	if err := stmt.GetContext(ctx, &code, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOAuthNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "consume authorization code failed", status)
+		return nil, mappedErr
+	}
+
+	return &code, nil
+}
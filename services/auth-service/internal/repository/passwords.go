@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	updatePasswordQuery = `
+		UPDATE users
+		SET password = :password, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	insertPasswordResetTokenQuery = `
+		INSERT INTO password_reset_tokens (
+			token_hash,
+			user_id,
+			expires_at
+		) VALUES (
+			:token_hash,
+			:user_id,
+			:expires_at
+		)
+	`
+
+	consumePasswordResetTokenQuery = `
+		UPDATE password_reset_tokens
+		SET used = true
+		WHERE token_hash = :token_hash AND used = false
+		RETURNING token_hash, user_id, expires_at, used, created_at
+	`
+
+	deletePasswordResetTokensQuery = `
+		DELETE FROM password_reset_tokens
+		WHERE user_id = :user_id
+	`
+)
+
+// ErrPasswordResetNotFound is returned when a password reset token does not exist or
+// was already used
+var ErrPasswordResetNotFound = errors.New("password reset token not found")
+
+// UpdatePassword replaces a user's password hash
+func (db *DB) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	params := map[string]any{
+		"id":       userID,
+		"password": passwordHash,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, updatePasswordQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare update password failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, params)
+	if err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "update password failed", status)
+		return mappedErr
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// StorePasswordResetToken stores a hashed password reset token
+func (db *DB) StorePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	stmt, err := db.PrepareNamedContext(ctx, insertPasswordResetTokenQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert password reset token failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, token); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert password reset token failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// ConsumePasswordResetToken marks an unused password reset token as used and returns it
+func (db *DB) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	params := map[string]any{
+		"token_hash": tokenHash,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, consumePasswordResetTokenQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare consume password reset token failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var token models.PasswordResetToken
+	if err := stmt.GetContext(ctx, &token, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPasswordResetNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "consume password reset token failed", status)
+		return nil, mappedErr
+	}
+
+	return &token, nil
+}
+
+// DeletePasswordResetTokens deletes a user's outstanding password reset tokens
+func (db *DB) DeletePasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	params := map[string]any{
+		"user_id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, deletePasswordResetTokensQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare delete password reset tokens failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "delete password reset tokens failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
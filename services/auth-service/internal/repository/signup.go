@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+// signupSagaColumns are the columns of signup_sagas, in the order of models.SignupSaga
+const signupSagaColumns = `
+	id, user_id, email, name, locale, step, status, attempts, last_error, did, user_hash,
+	credential_id, wallet_offer_id, wallet_offer_status, next_attempt_at, created_at, updated_at
+`
+
+const (
+	insertSignupSagaQuery = `
+		INSERT INTO signup_sagas (
+			user_id,
+			email,
+			name,
+			locale,
+			step,
+			status,
+			next_attempt_at
+		) VALUES (
+			:user_id,
+			:email,
+			:name,
+			:locale,
+			:step,
+			:status,
+			:next_attempt_at
+		)
+		RETURNING id, created_at, updated_at
+	`
+
+	updateSignupSagaQuery = `
+		UPDATE signup_sagas
+		SET step = :step, status = :status, attempts = :attempts, last_error = :last_error,
+			did = :did, user_hash = :user_hash, credential_id = :credential_id,
+			wallet_offer_id = :wallet_offer_id, wallet_offer_status = :wallet_offer_status,
+			next_attempt_at = :next_attempt_at, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	getSignupSagaQuery = `
+		SELECT ` + signupSagaColumns + `
+		FROM signup_sagas
+		WHERE id = :id
+	`
+
+	getSignupSagaByUserQuery = `
+		SELECT ` + signupSagaColumns + `
+		FROM signup_sagas
+		WHERE user_id = :user_id
+	`
+
+	// claimDueSignupSagasQuery leases due sagas to the caller by moving their next
+	// attempt past the lease, so concurrent workers and instances skip them
+	claimDueSignupSagasQuery = `
+		UPDATE signup_sagas
+		SET next_attempt_at = NOW() + :lease_seconds * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM signup_sagas
+			WHERE status = 'running' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + signupSagaColumns
+
+	// listStuckSignupSagasQuery lists failed sagas and running sagas whose current step
+	// failed at least once, oldest first
+	listStuckSignupSagasQuery = `
+		SELECT ` + signupSagaColumns + `
+		FROM signup_sagas
+		WHERE status = 'failed' OR (status = 'running' AND attempts > 0)
+		ORDER BY created_at
+		LIMIT :limit
+	`
+
+	deleteUserQuery = `
+		DELETE FROM users
+		WHERE id = :id
+	`
+
+	insertEmailVerificationTokenQuery = `
+		INSERT INTO email_verification_tokens (
+			token_hash,
+			user_id,
+			expires_at
+		) VALUES (
+			:token_hash,
+			:user_id,
+			:expires_at
+		)
+	`
+
+	consumeEmailVerificationTokenQuery = `
+		UPDATE email_verification_tokens
+		SET used = true
+		WHERE token_hash = :token_hash AND used = false
+		RETURNING token_hash, user_id, expires_at, used, created_at
+	`
+
+	markEmailVerifiedQuery = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = :id
+	`
+)
+
+// Signup errors
+var (
+	// ErrSignupSagaNotFound is returned when no signup saga has the ID
+	ErrSignupSagaNotFound = errors.New("signup saga not found")
+	// ErrEmailVerificationNotFound is returned when an email verification token does not
+	// exist or was already used
+	ErrEmailVerificationNotFound = errors.New("email verification token not found")
+)
+
+// CreateSignupSaga stores a new signup saga, setting its ID and times
+func (db *DB) CreateSignupSaga(ctx context.Context, saga *models.SignupSaga) error {
+	stmt, err := db.PrepareNamedContext(ctx, insertSignupSagaQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert signup saga failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if err := stmt.QueryRowxContext(ctx, saga).Scan(&saga.ID, &saga.CreatedAt, &saga.UpdatedAt); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert signup saga failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// UpdateSignupSaga stores the progress of a signup saga
+func (db *DB) UpdateSignupSaga(ctx context.Context, saga *models.SignupSaga) error {
+	stmt, err := db.PrepareNamedContext(ctx, updateSignupSagaQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare update signup saga failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, saga)
+	if err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "update signup saga failed", status)
+		return mappedErr
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrSignupSagaNotFound
+	}
+
+	return nil
+}
+
+// GetSignupSaga returns a signup saga
+func (db *DB) GetSignupSaga(ctx context.Context, id uuid.UUID) (*models.SignupSaga, error) {
+	params := map[string]any{
+		"id": id,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, getSignupSagaQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare select signup saga failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var saga models.SignupSaga
+	if err := stmt.GetContext(ctx, &saga, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSignupSagaNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "select signup saga failed", status)
+		return nil, mappedErr
+	}
+
+	return &saga, nil
+}
+
+// GetSignupSagaByUser returns the signup saga of a user
+func (db *DB) GetSignupSagaByUser(ctx context.Context, userID uuid.UUID) (*models.SignupSaga, error) {
+	params := map[string]any{
+		"user_id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, getSignupSagaByUserQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare select signup saga by user failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var saga models.SignupSaga
+	if err := stmt.GetContext(ctx, &saga, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSignupSagaNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "select signup saga by user failed", status)
+		return nil, mappedErr
+	}
+
+	return &saga, nil
+}
+
+// ClaimDueSignupSagas returns up to limit running sagas due for their next attempt,
+// leasing them to the caller for lease
+func (db *DB) ClaimDueSignupSagas(ctx context.Context, lease time.Duration, limit int) ([]models.SignupSaga, error) {
+	params := map[string]any{
+		"lease_seconds": int(lease.Seconds()),
+		"limit":         limit,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, claimDueSignupSagasQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare claim signup sagas failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var sagas []models.SignupSaga
+	if err := stmt.SelectContext(ctx, &sagas, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "claim signup sagas failed", status)
+		return nil, mappedErr
+	}
+
+	return sagas, nil
+}
+
+// ListStuckSignupSagas returns up to limit failed sagas and running sagas retrying a
+// failed step, oldest first
+func (db *DB) ListStuckSignupSagas(ctx context.Context, limit int) ([]models.SignupSaga, error) {
+	params := map[string]any{
+		"limit": limit,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, listStuckSignupSagasQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare list stuck signup sagas failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	sagas := []models.SignupSaga{}
+	if err := stmt.SelectContext(ctx, &sagas, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "list stuck signup sagas failed", status)
+		return nil, mappedErr
+	}
+
+	return sagas, nil
+}
+
+// DeleteUser deletes a user together with their tokens, sessions and consents
+func (db *DB) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	params := map[string]any{
+		"id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, deleteUserQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare delete user failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "delete user failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// StoreEmailVerificationToken stores a hashed email verification token
+func (db *DB) StoreEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error {
+	stmt, err := db.PrepareNamedContext(ctx, insertEmailVerificationTokenQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare insert email verification token failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, token); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "insert email verification token failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
+
+// ConsumeEmailVerificationToken marks an unused email verification token as used and
+// returns it
+func (db *DB) ConsumeEmailVerificationToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	params := map[string]any{
+		"token_hash": tokenHash,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, consumeEmailVerificationTokenQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare consume email verification token failed", http.StatusInternalServerError)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var token models.EmailVerificationToken
+	if err := stmt.GetContext(ctx, &token, params); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEmailVerificationNotFound
+		}
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "consume email verification token failed", status)
+		return nil, mappedErr
+	}
+
+	return &token, nil
+}
+
+// MarkEmailVerified records that a user's email address was verified; the time of the
+// first verification is kept
+func (db *DB) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	params := map[string]any{
+		"id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, markEmailVerifiedQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare mark email verified failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "mark email verified failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
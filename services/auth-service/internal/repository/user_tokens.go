@@ -37,6 +37,12 @@ const (
 		WHERE access_token = :access_token
 	`
 
+	revokeUserTokensQuery = `
+		UPDATE user_tokens
+		SET is_revoked = true
+		WHERE user_id = :user_id AND is_revoked = false
+	`
+
 	getTokenByAccessTokenQuery = `
 		SELECT 
 			id, 
@@ -46,7 +52,8 @@ const (
 			access_expires_at, 
 			refresh_expires_at, 
 			is_revoked, 
-			created_at
+			created_at,
+			device_key_id
 		FROM user_tokens
 		WHERE access_token = :access_token
 	`
@@ -60,11 +67,18 @@ const (
 			access_expires_at, 
 			refresh_expires_at, 
 			is_revoked, 
-			created_at
+			created_at,
+			device_key_id
 		FROM user_tokens
 		WHERE refresh_token = :refresh_token
 	`
 
+	bindTokenDeviceKeyQuery = `
+		UPDATE user_tokens
+		SET device_key_id = :device_key_id
+		WHERE id = :id AND is_revoked = false AND device_key_id IS NULL
+	`
+
 	updateAccessTokenQuery = `
 		UPDATE user_tokens
 		SET access_token = :access_token, access_expires_at = :access_expires_at
@@ -143,6 +157,40 @@ func (db *DB) RevokeToken(ctx context.Context, accessToken string) error {
 	return nil
 }
 
+// RevokeUserTokens revokes every session of a user, returning how many were active
+func (db *DB) RevokeUserTokens(ctx context.Context, userID uuid.UUID) (int64, error) {
+	params := map[string]any{
+		"user_id": userID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, revokeUserTokensQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare revoke user tokens failed", http.StatusInternalServerError)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, params)
+	if err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "revoke user tokens failed", status)
+		return 0, mappedErr
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		db.logger.Error(ctx, err, "failed to get rows affected", http.StatusInternalServerError)
+		return 0, err
+	}
+
+	db.logger.Info(ctx, "user tokens revoked", map[string]any{
+		"user_id": userID,
+		"revoked": revoked,
+	})
+
+	return revoked, nil
+}
+
 // GetTokenByAccessToken retrieves a token by access token
 func (db *DB) GetTokenByAccessToken(ctx context.Context, accessToken string) (*models.UserToken, error) {
 	params := map[string]any{
@@ -242,3 +290,45 @@ func (db *DB) UpdateAccessToken(ctx context.Context, tokenID uuid.UUID, newAcces
 
 	return nil
 }
+
+// BindTokenDeviceKey binds an active, unbound session to a device key
+func (db *DB) BindTokenDeviceKey(ctx context.Context, tokenID, deviceKeyID uuid.UUID) error {
+	params := map[string]any{
+		"id":            tokenID,
+		"device_key_id": deviceKeyID,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, bindTokenDeviceKeyQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare bind token device key failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, params)
+	if err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "bind token device key failed", status)
+		return mappedErr
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		db.logger.Error(ctx, err, "failed to get rows affected", http.StatusInternalServerError)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		db.logger.Info(ctx, "token not found to bind", map[string]any{
+			"token_id": tokenID,
+		})
+		return errors.New("token not found")
+	}
+
+	db.logger.Info(ctx, "token bound to device key", map[string]any{
+		"token_id":      tokenID,
+		"device_key_id": deviceKeyID,
+	})
+
+	return nil
+}
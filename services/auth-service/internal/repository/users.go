@@ -19,16 +19,18 @@ const (
 			name,
 			email,
 			password,
+			locale,
 			created_at,
 			updated_at
 		) VALUES (
 			:name,
 			:email,
 			:password,
+			:locale,
 			:created_at,
 			:updated_at
 		)
-		RETURNING id, name, email, created_at, updated_at
+		RETURNING id, name, email, locale, created_at, updated_at
 	`
 
 	getUserByEmailQuery = `
@@ -37,6 +39,9 @@ const (
 			name,
 			email,
 			password,
+			did,
+			user_hash,
+			locale,
 			created_at,
 			updated_at
 		FROM users
@@ -49,6 +54,9 @@ const (
 			name,
 			email,
 			password,
+			did,
+			user_hash,
+			locale,
 			created_at,
 			updated_at
 		FROM users
@@ -70,6 +78,12 @@ const (
 	countUsersQuery = `
 		SELECT COUNT(*) FROM users
 	`
+
+	setUserDIDQuery = `
+		UPDATE users
+		SET did = :did, user_hash = :user_hash, updated_at = NOW()
+		WHERE id = :id
+	`
 )
 
 // CreateUser inserts a new user into the database
@@ -206,3 +220,27 @@ func (db *DB) CountUsers(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+// SetUserDID records the DID created for a user and its user hash
+func (db *DB) SetUserDID(ctx context.Context, userID uuid.UUID, did, userHash string) error {
+	params := map[string]any{
+		"id":        userID,
+		"did":       did,
+		"user_hash": userHash,
+	}
+
+	stmt, err := db.PrepareNamedContext(ctx, setUserDIDQuery)
+	if err != nil {
+		db.logger.Error(ctx, err, "prepare set user DID failed", http.StatusInternalServerError)
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, params); err != nil {
+		status, mappedErr := HandlePgError(err)
+		db.logger.Error(ctx, mappedErr, "set user DID failed", status)
+		return mappedErr
+	}
+
+	return nil
+}
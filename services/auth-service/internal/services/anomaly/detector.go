@@ -0,0 +1,186 @@
+// Package anomaly detects signs of account takeover in sign-ins and responds to them
+// by suspending the user's DIDs and notifying the user
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+
+	zlog "packages/logger"
+)
+
+// Store records sign-in attempts and serves their history
+type Store interface {
+	History
+	RecordLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error
+}
+
+// Locator locates IP addresses
+type Locator interface {
+	Locate(ctx context.Context, ipAddress string) (latitude, longitude float64, err error)
+}
+
+// Suspender suspends a user's DIDs and returns the suspended DIDs
+type Suspender interface {
+	SuspendUserDIDs(userID, reason string) ([]string, error)
+}
+
+// Notifier delivers notices to users
+type Notifier interface {
+	Send(notice any) error
+}
+
+// Detector records sign-in attempts and evaluates its signals on successful ones
+type Detector struct {
+	store   Store
+	logger  *zlog.Logger
+	signals []Signal
+	// locator, suspender and notifier are optional
+	locator   Locator
+	suspender Suspender
+	notifier  Notifier
+}
+
+// NewDetector creates a detector evaluating signals
+func NewDetector(store Store, logger *zlog.Logger, signals ...Signal) *Detector {
+	return &Detector{
+		store:   store,
+		logger:  logger,
+		signals: signals,
+	}
+}
+
+// SetLocator locates successful sign-ins with locator, for location-based signals
+func (d *Detector) SetLocator(locator Locator) {
+	d.locator = locator
+}
+
+// SetSuspender suspends the user's DIDs through suspender when an anomaly is detected
+func (d *Detector) SetSuspender(suspender Suspender) {
+	d.suspender = suspender
+}
+
+// SetNotifier notifies the user through notifier when an anomaly is detected
+func (d *Detector) SetNotifier(notifier Notifier) {
+	d.notifier = notifier
+}
+
+// Observe records a sign-in attempt by the client of credentials; user is nil when no
+// account uses the email. A successful sign-in is evaluated by every signal, and
+// the anomalies found are responded to and returned. Sign-ins are never refused.
+func (d *Detector) Observe(ctx context.Context, user *models.User, credentials *models.Credentials, success bool) []models.LoginAnomaly {
+	attempt := &models.LoginAttempt{
+		Email:     credentials.Email,
+		IPAddress: credentials.IPAddress,
+		UserAgent: credentials.UserAgent,
+		Success:   success,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if success && d.locator != nil && locatable(attempt.IPAddress) {
+		latitude, longitude, err := d.locator.Locate(ctx, attempt.IPAddress)
+		if err != nil {
+			d.logger.Warn(ctx, "failed to locate sign-in", map[string]any{
+				"ip_address": attempt.IPAddress,
+				"error":      err.Error(),
+			})
+		} else {
+			attempt.Latitude, attempt.Longitude = &latitude, &longitude
+		}
+	}
+
+	if err := d.store.RecordLoginAttempt(ctx, attempt); err != nil {
+		return nil
+	}
+	if !success || user == nil {
+		return nil
+	}
+
+	var anomalies []models.LoginAnomaly
+	for _, signal := range d.signals {
+		anomaly, err := signal.Evaluate(ctx, attempt)
+		if err != nil {
+			d.logger.Warn(ctx, "failed to evaluate sign-in anomaly signal", map[string]any{
+				"signal": signal.Name(),
+				"error":  err.Error(),
+			})
+			continue
+		}
+		if anomaly != nil {
+			anomalies = append(anomalies, *anomaly)
+		}
+	}
+
+	if len(anomalies) > 0 {
+		d.respond(ctx, user, attempt, anomalies)
+	}
+	return anomalies
+}
+
+// respond suspends the user's DIDs and notifies the user of anomalies in a sign-in
+func (d *Detector) respond(ctx context.Context, user *models.User, attempt *models.LoginAttempt, anomalies []models.LoginAnomaly) {
+	signals := make([]string, len(anomalies))
+	details := make([]string, len(anomalies))
+	for i, anomaly := range anomalies {
+		signals[i] = anomaly.Signal
+		details[i] = anomaly.Signal + ": " + anomaly.Detail
+	}
+	d.logger.Warn(ctx, "sign-in anomaly detected", map[string]any{
+		"user_id":    user.ID.String(),
+		"ip_address": attempt.IPAddress,
+		"anomalies":  details,
+	})
+
+	suspended := []string{}
+	if d.suspender != nil {
+		dids, err := d.suspender.SuspendUserDIDs(user.ID.String(), "suspicious sign-in: "+strings.Join(details, "; "))
+		switch {
+		case errors.Is(err, clients.ErrAdminKeyMissing):
+			d.logger.Warn(ctx, "DIDs not suspended after sign-in anomaly: DID_MANAGER_ADMIN_KEY is not set", nil)
+		case err != nil:
+			d.logger.Error(ctx, err, "failed to suspend DIDs after sign-in anomaly", http.StatusBadGateway, map[string]any{
+				"user_id": user.ID.String(),
+			})
+		default:
+			suspended = dids
+			d.logger.Info(ctx, "DIDs suspended after sign-in anomaly", map[string]any{
+				"user_id": user.ID.String(),
+				"dids":    dids,
+			})
+		}
+	}
+
+	if d.notifier == nil {
+		return
+	}
+	notice := &clients.LoginAnomalyNotice{
+		NoticeText:    clients.NoticeText{Locale: user.Locale},
+		Type:          clients.NoticeLoginAnomaly,
+		Email:         user.Email,
+		Name:          user.Name,
+		Signals:       signals,
+		Details:       details,
+		IPAddress:     attempt.IPAddress,
+		SignedIn:      attempt.OccurredAt,
+		SuspendedDIDs: suspended,
+	}
+	if err := d.notifier.Send(notice); err != nil {
+		d.logger.Error(ctx, err, "failed to notify user of sign-in anomaly", http.StatusBadGateway, map[string]any{
+			"user_id": user.ID.String(),
+		})
+	}
+}
+
+// locatable reports whether an IP address is public, so a geolocation service can
+// locate it
+func locatable(ipAddress string) bool {
+	ip := net.ParseIP(ipAddress)
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsLinkLocalUnicast()
+}
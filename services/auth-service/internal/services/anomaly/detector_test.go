@@ -0,0 +1,129 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore records attempts in memory
+type fakeStore struct {
+	fakeHistory
+	attempts []*models.LoginAttempt
+}
+
+func (s *fakeStore) RecordLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	attempt.ID = int64(len(s.attempts) + 1)
+	attempt.OccurredAt = time.Now()
+	s.attempts = append(s.attempts, attempt)
+	return nil
+}
+
+// fixedSignal raises an anomaly on every sign-in
+type fixedSignal struct{}
+
+func (fixedSignal) Name() string { return "fixed" }
+
+func (fixedSignal) Evaluate(ctx context.Context, attempt *models.LoginAttempt) (*models.LoginAnomaly, error) {
+	return &models.LoginAnomaly{Signal: "fixed", Detail: "always raised"}, nil
+}
+
+type fakeLocator struct{ calls int }
+
+func (l *fakeLocator) Locate(ctx context.Context, ipAddress string) (float64, float64, error) {
+	l.calls++
+	return 52.52, 13.405, nil
+}
+
+type fakeSuspender struct {
+	userID, reason string
+	err            error
+}
+
+func (s *fakeSuspender) SuspendUserDIDs(userID, reason string) ([]string, error) {
+	s.userID, s.reason = userID, reason
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []string{"did:example:123"}, nil
+}
+
+type fakeNotifier struct{ notices []any }
+
+func (n *fakeNotifier) Send(notice any) error {
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+func TestDetector_Observe(t *testing.T) {
+	logger := zlog.NewLogger(zlog.Config{Level: "debug"})
+	user := &models.User{ID: uuid.New(), Name: "Ada", Email: "ada@example.com"}
+	credentials := &models.Credentials{Email: user.Email, IPAddress: "203.0.113.7", UserAgent: "test"}
+
+	store := &fakeStore{}
+	locator := &fakeLocator{}
+	suspender := &fakeSuspender{}
+	notifier := &fakeNotifier{}
+	detector := NewDetector(store, logger, fixedSignal{})
+	detector.SetLocator(locator)
+	detector.SetSuspender(suspender)
+	detector.SetNotifier(notifier)
+
+	// Failed sign-ins are only recorded
+	assert.Empty(t, detector.Observe(context.Background(), nil, credentials, false))
+	assert.Empty(t, detector.Observe(context.Background(), user, credentials, false))
+	require.Len(t, store.attempts, 2)
+	assert.Nil(t, store.attempts[0].UserID)
+	assert.Zero(t, locator.calls)
+	assert.Empty(t, notifier.notices)
+
+	anomalies := detector.Observe(context.Background(), user, credentials, true)
+	require.Len(t, anomalies, 1)
+	attempt := store.attempts[2]
+	assert.True(t, attempt.Located())
+	assert.Equal(t, "test", attempt.UserAgent)
+
+	assert.Equal(t, user.ID.String(), suspender.userID)
+	assert.Contains(t, suspender.reason, "fixed: always raised")
+
+	require.Len(t, notifier.notices, 1)
+	notice := notifier.notices[0].(*clients.LoginAnomalyNotice)
+	assert.Equal(t, clients.NoticeLoginAnomaly, notice.Type)
+	assert.Equal(t, []string{"fixed"}, notice.Signals)
+	assert.Equal(t, []string{"did:example:123"}, notice.SuspendedDIDs)
+}
+
+func TestDetector_ObserveWithoutSuspension(t *testing.T) {
+	logger := zlog.NewLogger(zlog.Config{Level: "debug"})
+	user := &models.User{ID: uuid.New(), Email: "ada@example.com"}
+	notifier := &fakeNotifier{}
+	detector := NewDetector(&fakeStore{}, logger, fixedSignal{})
+	detector.SetSuspender(&fakeSuspender{err: errors.New("DID Manager unreachable")})
+	detector.SetNotifier(notifier)
+
+	anomalies := detector.Observe(context.Background(), user, &models.Credentials{Email: user.Email, IPAddress: "10.0.0.1"}, true)
+	require.Len(t, anomalies, 1)
+
+	// The user is still notified, with nothing suspended
+	require.Len(t, notifier.notices, 1)
+	assert.Empty(t, notifier.notices[0].(*clients.LoginAnomalyNotice).SuspendedDIDs)
+}
+
+func TestLocatable(t *testing.T) {
+	assert.True(t, locatable("203.0.113.7"))
+	assert.True(t, locatable("2001:db8::1"))
+	assert.False(t, locatable("10.0.0.1"))
+	assert.False(t, locatable("127.0.0.1"))
+	assert.False(t, locatable("::1"))
+	assert.False(t, locatable(""))
+}
@@ -0,0 +1,139 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"auth-service/internal/repository"
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+// Signal inspects a successful sign-in for signs of account takeover. Signals see the
+// attempt after it was recorded.
+type Signal interface {
+	// Name identifies the signal in logs and notices
+	Name() string
+	// Evaluate returns an anomaly, or nil when the sign-in looks normal
+	Evaluate(ctx context.Context, attempt *models.LoginAttempt) (*models.LoginAnomaly, error)
+}
+
+// History is the sign-in history the built-in signals consult
+type History interface {
+	PreviousLocatedLogin(ctx context.Context, userID uuid.UUID, beforeID int64) (*models.LoginAttempt, error)
+	CountFailedLoginEmails(ctx context.Context, ipAddress string, since time.Time) (int, error)
+}
+
+// minTravelDistance is the distance in kilometers below which sign-ins are never
+// impossible travel, as IP geolocation is only accurate to a region
+const minTravelDistance = 500
+
+// earthRadius is the mean radius of the Earth in kilometers
+const earthRadius = 6371
+
+// ImpossibleTravel flags sign-ins located too far from the user's previous sign-in to
+// have been reached in the time between them
+type ImpossibleTravel struct {
+	history  History
+	maxSpeed float64
+}
+
+// NewImpossibleTravel creates the impossible travel signal; maxSpeed is the fastest
+// plausible travel in km/h
+func NewImpossibleTravel(history History, maxSpeed float64) *ImpossibleTravel {
+	return &ImpossibleTravel{
+		history:  history,
+		maxSpeed: maxSpeed,
+	}
+}
+
+// Name identifies the signal
+func (s *ImpossibleTravel) Name() string {
+	return "impossible_travel"
+}
+
+// Evaluate compares a located sign-in with the user's previous located one
+func (s *ImpossibleTravel) Evaluate(ctx context.Context, attempt *models.LoginAttempt) (*models.LoginAnomaly, error) {
+	if attempt.UserID == nil || !attempt.Located() {
+		return nil, nil
+	}
+
+	previous, err := s.history.PreviousLocatedLogin(ctx, *attempt.UserID, attempt.ID)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	distance := distanceKm(*previous.Latitude, *previous.Longitude, *attempt.Latitude, *attempt.Longitude)
+	if distance < minTravelDistance {
+		return nil, nil
+	}
+	elapsed := attempt.OccurredAt.Sub(previous.OccurredAt)
+	if elapsed > 0 && distance/elapsed.Hours() <= s.maxSpeed {
+		return nil, nil
+	}
+
+	return &models.LoginAnomaly{
+		Signal: s.Name(),
+		Detail: fmt.Sprintf("signed in %.0f km from the previous sign-in %s after it", distance, elapsed.Round(time.Minute)),
+	}, nil
+}
+
+// distanceKm is the great-circle distance between two coordinates
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// CredentialStuffing flags sign-ins from an IP address that recently failed to sign in
+// to many accounts, as when leaked credentials are tried in bulk
+type CredentialStuffing struct {
+	history   History
+	window    time.Duration
+	threshold int
+}
+
+// NewCredentialStuffing creates the credential stuffing signal, raised when an IP
+// address failed to sign in to threshold or more accounts within window
+func NewCredentialStuffing(history History, window time.Duration, threshold int) *CredentialStuffing {
+	return &CredentialStuffing{
+		history:   history,
+		window:    window,
+		threshold: threshold,
+	}
+}
+
+// Name identifies the signal
+func (s *CredentialStuffing) Name() string {
+	return "credential_stuffing"
+}
+
+// Evaluate counts the accounts the sign-in's IP address recently failed to sign in to
+func (s *CredentialStuffing) Evaluate(ctx context.Context, attempt *models.LoginAttempt) (*models.LoginAnomaly, error) {
+	if attempt.IPAddress == "" {
+		return nil, nil
+	}
+
+	accounts, err := s.history.CountFailedLoginEmails(ctx, attempt.IPAddress, attempt.OccurredAt.Add(-s.window))
+	if err != nil {
+		return nil, err
+	}
+	if accounts < s.threshold {
+		return nil, nil
+	}
+
+	return &models.LoginAnomaly{
+		Signal: s.Name(),
+		Detail: fmt.Sprintf("signed in from %s, which failed to sign in to %d accounts within %s", attempt.IPAddress, accounts, s.window),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auth-service/internal/repository"
+	"auth-service/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHistory serves a fixed sign-in history
+type fakeHistory struct {
+	previous     *models.LoginAttempt
+	failedEmails int
+	since        time.Time
+}
+
+func (h *fakeHistory) PreviousLocatedLogin(ctx context.Context, userID uuid.UUID, beforeID int64) (*models.LoginAttempt, error) {
+	if h.previous == nil {
+		return nil, repository.ErrLoginAttemptNotFound
+	}
+	return h.previous, nil
+}
+
+func (h *fakeHistory) CountFailedLoginEmails(ctx context.Context, ipAddress string, since time.Time) (int, error) {
+	h.since = since
+	return h.failedEmails, nil
+}
+
+// locatedAttempt is a successful sign-in at a location
+func locatedAttempt(userID uuid.UUID, latitude, longitude float64, at time.Time) *models.LoginAttempt {
+	return &models.LoginAttempt{
+		ID:         2,
+		UserID:     &userID,
+		IPAddress:  "203.0.113.7",
+		Latitude:   &latitude,
+		Longitude:  &longitude,
+		Success:    true,
+		OccurredAt: at,
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// Berlin to New York
+	assert.InDelta(t, 6385, distanceKm(52.52, 13.405, 40.7128, -74.006), 10)
+	assert.Zero(t, distanceKm(52.52, 13.405, 52.52, 13.405))
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	berlin := locatedAttempt(userID, 52.52, 13.405, now.Add(-time.Hour))
+
+	tests := []struct {
+		name     string
+		previous *models.LoginAttempt
+		attempt  *models.LoginAttempt
+		want     bool
+	}{
+		{
+			name:     "New York an hour after Berlin",
+			previous: berlin,
+			attempt:  locatedAttempt(userID, 40.7128, -74.006, now),
+			want:     true,
+		},
+		{
+			name:     "New York a day after Berlin",
+			previous: berlin,
+			attempt:  locatedAttempt(userID, 40.7128, -74.006, now.Add(23*time.Hour)),
+		},
+		{
+			name:     "Potsdam right after Berlin",
+			previous: berlin,
+			attempt:  locatedAttempt(userID, 52.39, 13.065, now.Add(-59*time.Minute)),
+		},
+		{
+			name:    "first located sign-in",
+			attempt: locatedAttempt(userID, 40.7128, -74.006, now),
+		},
+		{
+			name:     "sign-in that was not located",
+			previous: berlin,
+			attempt:  &models.LoginAttempt{UserID: &userID, Success: true, OccurredAt: now},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal := NewImpossibleTravel(&fakeHistory{previous: tt.previous}, 1000)
+			anomaly, err := signal.Evaluate(context.Background(), tt.attempt)
+			require.NoError(t, err)
+			if !tt.want {
+				assert.Nil(t, anomaly)
+				return
+			}
+			require.NotNil(t, anomaly)
+			assert.Equal(t, "impossible_travel", anomaly.Signal)
+			assert.Contains(t, anomaly.Detail, "6385 km")
+		})
+	}
+}
+
+func TestCredentialStuffing(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	attempt := &models.LoginAttempt{IPAddress: "203.0.113.7", Success: true, OccurredAt: now}
+
+	history := &fakeHistory{failedEmails: 9}
+	signal := NewCredentialStuffing(history, 15*time.Minute, 10)
+	anomaly, err := signal.Evaluate(context.Background(), attempt)
+	require.NoError(t, err)
+	assert.Nil(t, anomaly)
+	assert.Equal(t, now.Add(-15*time.Minute), history.since)
+
+	history.failedEmails = 10
+	anomaly, err = signal.Evaluate(context.Background(), attempt)
+	require.NoError(t, err)
+	require.NotNil(t, anomaly)
+	assert.Equal(t, "credential_stuffing", anomaly.Signal)
+	assert.Contains(t, anomaly.Detail, "10 accounts")
+}
@@ -0,0 +1,137 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+
+	"github.com/google/uuid"
+)
+
+// Device binding errors
+var (
+	ErrDeviceBindingUnavailable = errors.New("device binding requires the DID Manager admin API")
+	ErrNoDID                    = errors.New("user has no DID to register a device key on")
+	ErrSessionAlreadyBound      = errors.New("session is already bound to a device")
+	ErrDeviceProofRequired      = errors.New("session is bound to a device; a valid device signature is required")
+)
+
+// deviceProofMaxSkew bounds how far a device proof's timestamp may be from now
+const deviceProofMaxSkew = 5 * time.Minute
+
+// DeviceRefreshMessage is the message a device signs with its key to refresh a session
+// bound to it
+func DeviceRefreshMessage(refreshToken, timestamp string) string {
+	return "device-refresh:" + refreshToken + ":" + timestamp
+}
+
+// BindDevice registers a device key on the user's DID and binds the session of
+// accessToken to it. From then on the session is refreshed only with the device's
+// signature, and signing it out removes the key from the DID.
+func (s *AuthService) BindDevice(ctx context.Context, user *models.User, accessToken string, req *models.DeviceBindRequest) (*clients.DeviceKey, error) {
+	if s.didClient == nil {
+		return nil, ErrDeviceBindingUnavailable
+	}
+	if user.DID == "" {
+		return nil, ErrNoDID
+	}
+
+	token, err := s.DB.GetTokenByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.DeviceKeyID != nil {
+		return nil, ErrSessionAlreadyBound
+	}
+
+	registration := &clients.DeviceKeyRegisterRequest{
+		DID:          user.DID,
+		Name:         req.Name,
+		PublicKey:    req.PublicKey,
+		KeyAlgorithm: req.KeyAlgorithm,
+		Signature:    req.Signature,
+	}
+	if req.Attestation != nil {
+		registration.Attestation = &clients.DeviceKeyAttestationEvidence{
+			Platform: req.Attestation.Platform,
+			Token:    req.Attestation.Token,
+			KeyID:    req.Attestation.KeyID,
+		}
+	}
+	key, err := s.didClient.RegisterDeviceKey(user.ID.String(), registration)
+	if err != nil {
+		if errors.Is(err, clients.ErrAdminKeyMissing) {
+			return nil, ErrDeviceBindingUnavailable
+		}
+		return nil, err
+	}
+
+	keyID, err := uuid.Parse(key.ID)
+	if err == nil {
+		err = s.DB.BindTokenDeviceKey(ctx, token.ID, keyID)
+	}
+	if err != nil {
+		// Don't leave a key on the DID that no session uses
+		s.revokeDeviceKey(ctx, user.ID.String(), key.ID)
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "session bound to device key", map[string]any{
+		"user_id":       user.ID.String(),
+		"token_id":      token.ID.String(),
+		"device_key_id": key.ID,
+	})
+	return key, nil
+}
+
+// verifyDeviceProof checks that the device a session is bound to signed its refresh
+func (s *AuthService) verifyDeviceProof(ctx context.Context, token *models.UserToken, proof *models.DeviceProof) error {
+	if token.DeviceKeyID == nil {
+		return nil
+	}
+	if proof == nil || proof.Signature == "" || s.didClient == nil {
+		return ErrDeviceProofRequired
+	}
+
+	signedAt, err := strconv.ParseInt(proof.Timestamp, 10, 64)
+	if err != nil {
+		return ErrDeviceProofRequired
+	}
+	skew := time.Since(time.Unix(signedAt, 0))
+	if skew > deviceProofMaxSkew || skew < -deviceProofMaxSkew {
+		return ErrDeviceProofRequired
+	}
+
+	valid, err := s.didClient.VerifyDeviceSignature(token.UserID.String(), token.DeviceKeyID.String(),
+		DeviceRefreshMessage(token.RefreshToken, proof.Timestamp), proof.Signature)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to verify device signature", http.StatusBadGateway, map[string]any{
+			"token_id": token.ID.String(),
+		})
+		return ErrDeviceProofRequired
+	}
+	if !valid {
+		return ErrDeviceProofRequired
+	}
+	return nil
+}
+
+// revokeDeviceKey removes a signed-out session's device key from the user's DID. The
+// sign-out stands if this fails; the key then remains listed until revoked again.
+func (s *AuthService) revokeDeviceKey(ctx context.Context, userID, keyID string) {
+	if s.didClient == nil {
+		return
+	}
+	if err := s.didClient.RevokeDeviceKey(userID, keyID); err != nil && !errors.Is(err, clients.ErrAdminKeyMissing) {
+		s.logger.Warn(ctx, "failed to revoke device key", map[string]any{
+			"user_id":       userID,
+			"device_key_id": keyID,
+			"error":         err.Error(),
+		})
+	}
+}
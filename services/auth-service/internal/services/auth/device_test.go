@@ -0,0 +1,61 @@
+package authentication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_VerifyDeviceProof(t *testing.T) {
+	logger := zlog.NewLogger(zlog.Config{Level: "debug"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		valid := r.Header.Get("X-Admin-Key") == "admin-key"
+		_, _ = w.Write([]byte(`{"success":true,"data":{"valid":` + strconv.FormatBool(valid) + `}}`))
+	}))
+	defer server.Close()
+
+	service := NewAuthService(nil, logger, clients.NewDIDClient(server.URL, "admin-key"))
+	keyID := uuid.New()
+	bound := &models.UserToken{ID: uuid.New(), UserID: uuid.New(), RefreshToken: "refresh", DeviceKeyID: &keyID}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		token   *models.UserToken
+		proof   *models.DeviceProof
+		wantErr bool
+	}{
+		{name: "unbound session", token: &models.UserToken{ID: uuid.New()}},
+		{name: "bound session with proof", token: bound, proof: &models.DeviceProof{Timestamp: now, Signature: "abcd"}},
+		{name: "bound session without proof", token: bound, wantErr: true},
+		{name: "stale timestamp", token: bound, proof: &models.DeviceProof{Timestamp: stale, Signature: "abcd"}, wantErr: true},
+		{name: "malformed timestamp", token: bound, proof: &models.DeviceProof{Timestamp: "yesterday", Signature: "abcd"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.verifyDeviceProof(context.Background(), tt.token, tt.proof)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrDeviceProofRequired)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeviceRefreshMessage(t *testing.T) {
+	assert.Equal(t, "device-refresh:token:1700000000", DeviceRefreshMessage("token", "1700000000"))
+}
@@ -0,0 +1,170 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/repository"
+	"auth-service/models"
+	"auth-service/utils"
+
+	"github.com/google/uuid"
+)
+
+// Password change and reset errors
+var (
+	ErrIncorrectPassword     = errors.New("current password is incorrect")
+	ErrInvalidResetToken     = errors.New("password reset token is invalid, expired or already used")
+	ErrPasswordResetDisabled = errors.New("password reset is not configured")
+)
+
+// Password change reasons recorded on the user's DIDs
+const (
+	passwordChangeReasonChange = "change"
+	passwordChangeReasonReset  = "reset"
+)
+
+// passwordResetTokenBytes is the entropy of a password reset token
+const passwordResetTokenBytes = 32
+
+// ChangePassword replaces a signed-in user's password after checking the current one
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *models.PasswordChangeRequest) error {
+	user, err := s.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		s.logger.Warn(ctx, "password change with incorrect current password", map[string]any{
+			"user_id": user.ID.String(),
+		})
+		return ErrIncorrectPassword
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword, passwordChangeReasonChange)
+}
+
+// RequestPasswordReset sends a single-use reset token for the account with the given
+// email. Unknown addresses are not reported, so the request cannot probe for accounts.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	if s.resetNotifier == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	user, err := s.userByEmail(ctx, email)
+	if err != nil {
+		s.logger.Info(ctx, "password reset requested for unknown email", nil)
+		return nil
+	}
+
+	token, err := utils.GenerateSecureToken(passwordResetTokenBytes)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to generate password reset token", http.StatusInternalServerError, nil)
+		return err
+	}
+
+	resetToken := &models.PasswordResetToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.resetTokenTTL),
+	}
+	if err := s.DB.StorePasswordResetToken(ctx, resetToken); err != nil {
+		return err
+	}
+
+	notice := &clients.PasswordResetNotice{
+		NoticeText: clients.NoticeText{Locale: user.Locale},
+		Type:       clients.NoticePasswordReset,
+		Email:      user.Email,
+		Name:       user.Name,
+		Token:      token,
+		ExpiresAt:  resetToken.ExpiresAt,
+	}
+	if err := s.resetNotifier.Send(notice); err != nil {
+		s.logger.Error(ctx, err, "failed to send password reset token", http.StatusBadGateway, map[string]any{
+			"user_id": user.ID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "password reset token sent", map[string]any{
+		"user_id": user.ID.String(),
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token, which can only be used once
+func (s *AuthService) ResetPassword(ctx context.Context, req *models.PasswordResetConfirmRequest) error {
+	if s.resetNotifier == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	resetToken, err := s.DB.ConsumePasswordResetToken(ctx, utils.HashToken(req.Token))
+	if errors.Is(err, repository.ErrPasswordResetNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if time.Now().After(resetToken.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.DB.GetUserByID(ctx, resetToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword, passwordChangeReasonReset)
+}
+
+// setPassword stores a new password, ends every session of the user and discards their
+// outstanding reset tokens, so whoever knew the old password or held a token is signed
+// out. The change is recorded on the user's DIDs; their commitments cover the name and
+// email only, so nothing identity-side is re-derived.
+func (s *AuthService) setPassword(ctx context.Context, user *models.User, newPassword, reason string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to hash password", http.StatusInternalServerError, nil)
+		return err
+	}
+
+	if err := s.DB.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	if _, err := s.DB.RevokeUserTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.DB.DeletePasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if s.didClient != nil {
+		// Don't fail the password change if it cannot be recorded on the DIDs or their
+		// device keys revoked; without an admin key the DID Manager is not told at all
+		err := s.didClient.RecordPasswordChange(user.ID.String(), reason)
+		if err != nil && !errors.Is(err, clients.ErrAdminKeyMissing) {
+			s.logger.Warn(ctx, "failed to record password change on DIDs", map[string]any{
+				"user_id": user.ID.String(),
+				"error":   err.Error(),
+			})
+		}
+		// The signed-out sessions' devices no longer sign in as the user's DIDs
+		err = s.didClient.RevokeDeviceKeys(user.ID.String())
+		if err != nil && !errors.Is(err, clients.ErrAdminKeyMissing) {
+			s.logger.Warn(ctx, "failed to revoke device keys", map[string]any{
+				"user_id": user.ID.String(),
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	s.logger.Info(ctx, "password updated", map[string]any{
+		"user_id": user.ID.String(),
+		"reason":  reason,
+	})
+	return nil
+}
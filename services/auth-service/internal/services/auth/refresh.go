@@ -9,8 +9,9 @@ import (
 	"time"
 )
 
-// RefreshToken refreshes an access token using a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, accessSecret, refreshSecret string) (*models.UserToken, error) {
+// RefreshToken refreshes an access token using a refresh token. A session bound to a
+// device also needs the device's proof; proof may be nil for unbound sessions.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, proof *models.DeviceProof, accessSecret, refreshSecret string) (*models.UserToken, error) {
 	if refreshToken == "" {
 		err := errors.New("refresh token cannot be empty")
 		s.logger.Error(ctx, err, "validation error", http.StatusBadRequest)
@@ -55,6 +56,14 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, acc
 		return nil, err
 	}
 
+	// Check that a device-bound session is refreshed by its device
+	if err := s.verifyDeviceProof(ctx, token, proof); err != nil {
+		s.logger.Error(ctx, err, "device proof rejected", http.StatusUnauthorized, map[string]any{
+			"token_id": token.ID.String(),
+		})
+		return nil, err
+	}
+
 	// Get user
 	user, err := s.DB.GetUserByID(ctx, token.UserID)
 	if err != nil {
@@ -94,6 +103,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, acc
 		RefreshExpiresAt: token.RefreshExpiresAt,
 		IsRevoked:        false,
 		CreatedAt:        token.CreatedAt,
+		DeviceKeyID:      token.DeviceKeyID,
 	}
 
 	s.logger.Info(ctx, "token refreshed successfully", map[string]any{
@@ -8,7 +8,7 @@ import (
 	authpkg "packages/auth"
 )
 
-// RevokeToken revokes an access token
+// RevokeToken revokes an access token, and the device key of a device-bound session
 func (s *AuthService) RevokeToken(ctx context.Context, accessToken string) error {
 	if accessToken == "" {
 		err := errors.New("access token can not be empty")
@@ -16,12 +16,24 @@ func (s *AuthService) RevokeToken(ctx context.Context, accessToken string) error
 		return err
 	}
 
+	// Look up the session's device before the token is revoked
+	token, err := s.DB.GetTokenByAccessToken(ctx, accessToken)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to revoke token", http.StatusInternalServerError, nil)
+		return err
+	}
+
 	// First revoke in database
 	if err := s.DB.RevokeToken(ctx, accessToken); err != nil {
 		s.logger.Error(ctx, err, "failed to revoke token", http.StatusInternalServerError, nil)
 		return err
 	}
 
+	// The session's device can no longer sign in as the user's DID
+	if token.DeviceKeyID != nil && !token.IsRevoked {
+		s.revokeDeviceKey(ctx, token.UserID.String(), token.DeviceKeyID.String())
+	}
+
 	// Also revoke in memory for immediate effect
 	authpkg.RevokeToken(accessToken)
 
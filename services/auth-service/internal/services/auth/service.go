@@ -1,8 +1,12 @@
 package authentication
 
 import (
+	"time"
+
 	"auth-service/internal/clients"
 	"auth-service/internal/repository"
+	"auth-service/internal/services/anomaly"
+	"auth-service/internal/services/signup"
 
 	zlog "packages/logger"
 )
@@ -12,6 +16,14 @@ type AuthService struct {
 	DB        *repository.DB
 	logger    *zlog.Logger
 	didClient *clients.DIDClient
+	// resetNotifier delivers password reset tokens; nil disables password resets
+	resetNotifier *clients.WebhookNotifier
+	resetTokenTTL time.Duration
+	// anomalies inspects sign-ins for signs of account takeover; nil disables it
+	anomalies *anomaly.Detector
+	// signup runs the steps of each signup after the user is created; nil creates
+	// users only
+	signup *signup.Orchestrator
 }
 
 // NewAuthService creates a new authentication service
@@ -22,3 +34,22 @@ func NewAuthService(db *repository.DB, logger *zlog.Logger, didClient *clients.D
 		didClient: didClient,
 	}
 }
+
+// SetPasswordReset enables password resets, delivering reset tokens valid for ttl
+// through notifier
+func (s *AuthService) SetPasswordReset(notifier *clients.WebhookNotifier, ttl time.Duration) {
+	s.resetNotifier = notifier
+	s.resetTokenTTL = ttl
+}
+
+// SetAnomalyDetector records sign-in attempts with detector, which responds to signs of
+// account takeover
+func (s *AuthService) SetAnomalyDetector(detector *anomaly.Detector) {
+	s.anomalies = detector
+}
+
+// SetSignupSaga runs each signup's identity and verification steps as a saga through
+// orchestrator
+func (s *AuthService) SetSignupSaga(orchestrator *signup.Orchestrator) {
+	s.signup = orchestrator
+}
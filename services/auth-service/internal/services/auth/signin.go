@@ -9,6 +9,8 @@ import (
 	"auth-service/models"
 	"auth-service/utils"
 
+	"packages/canonical"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
 )
@@ -24,9 +26,10 @@ func (s *AuthService) SignIn(ctx context.Context, credentials *models.Credential
 	}
 
 	// Get user by email
-	user, err := s.DB.GetUserByEmail(ctx, credentials.Email)
+	user, err := s.userByEmail(ctx, credentials.Email)
 	if err != nil {
 		s.logger.Error(ctx, err, "failed to fetch user", http.StatusInternalServerError, nil)
+		s.observeSignIn(ctx, nil, credentials, false)
 		return nil, "", "", errors.New("invalid credentials")
 	}
 
@@ -34,9 +37,17 @@ func (s *AuthService) SignIn(ctx context.Context, credentials *models.Credential
 	if !utils.CheckPasswordHash(credentials.Password, user.Password) {
 		err := fmt.Errorf("invalid email or password")
 		s.logger.Error(ctx, err, "password mismatch", http.StatusUnauthorized, nil)
+		s.observeSignIn(ctx, user, credentials, false)
 		return nil, "", "", errors.New("invalid credentials")
 	}
 
+	// Users who signed up under another DID policy get the DID the policy requires
+	if s.signup != nil {
+		if err := s.signup.EnsureDID(ctx, user); err != nil {
+			return nil, "", "", err
+		}
+	}
+
 	// Generate tokens
 	accessToken, refreshToken, err := s.GenerateTokens(ctx, user, accessSecret, refreshSecret)
 	if err != nil {
@@ -44,9 +55,29 @@ func (s *AuthService) SignIn(ctx context.Context, credentials *models.Credential
 		return nil, "", "", err
 	}
 
+	s.observeSignIn(ctx, user, credentials, true)
+
 	s.logger.Info(ctx, "sign in successful", map[string]any{
 		"user_id": user.ID.String(),
 		"email":   user.Email,
 	})
 	return user, accessToken, refreshToken, nil
 }
+
+// observeSignIn hands a sign-in attempt to the anomaly detector, if enabled
+func (s *AuthService) observeSignIn(ctx context.Context, user *models.User, credentials *models.Credentials, success bool) {
+	if s.anomalies != nil {
+		s.anomalies.Observe(ctx, user, credentials, success)
+	}
+}
+
+// userByEmail looks up a user by the canonical form of an email, falling back to the
+// email as given for accounts created before emails were canonicalized
+func (s *AuthService) userByEmail(ctx context.Context, email string) (*models.User, error) {
+	canonicalEmail := canonical.Email(email)
+	user, err := s.DB.GetUserByEmail(ctx, canonicalEmail)
+	if err != nil && canonicalEmail != email {
+		return s.DB.GetUserByEmail(ctx, email)
+	}
+	return user, err
+}
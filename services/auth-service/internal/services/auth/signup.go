@@ -6,15 +6,30 @@ import (
 	"net/http"
 	"time"
 
-	"auth-service/internal/clients"
+	"auth-service/internal/i18n"
+	"auth-service/internal/repository"
 	"auth-service/models"
 	"auth-service/utils"
+
+	"packages/canonical"
 )
 
+// ErrInvalidVerificationToken is returned for email verification tokens that are
+// invalid, expired or already used
+var ErrInvalidVerificationToken = errors.New("email verification token is invalid, expired or already used")
+
 // SignUp registers a new user
 func (s *AuthService) SignUp(ctx context.Context, req *models.UserCreateRequest) (*models.User, error) {
+	// Names and emails are stored in canonical form, which the user's DID commits to
+	if err := canonical.ValidateName(req.Name); err != nil {
+		s.logger.Error(ctx, err, "invalid name", http.StatusBadRequest, nil)
+		return nil, err
+	}
+	req.Name = canonical.Name(req.Name)
+	req.Email = canonical.Email(req.Email)
+
 	// Check if user already exists
-	existingUser, _ := s.DB.GetUserByEmail(ctx, req.Email)
+	existingUser, _ := s.userByEmail(ctx, req.Email)
 	if existingUser != nil {
 		err := errors.New("user already exists")
 		s.logger.Error(ctx, err, "user already exists", http.StatusConflict, map[string]any{
@@ -30,11 +45,16 @@ func (s *AuthService) SignUp(ctx context.Context, req *models.UserCreateRequest)
 		return nil, err
 	}
 
+	// Notices are sent in the supported locale best serving the user's, or the default
+	// locale when none does
+	locale, _ := i18n.Notices.Match(req.Locale)
+
 	// Create user
 	user := &models.User{
 		Name:      req.Name,
 		Email:     req.Email,
 		Password:  hashedPassword,
+		Locale:    locale,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -45,33 +65,20 @@ func (s *AuthService) SignUp(ctx context.Context, req *models.UserCreateRequest)
 		return nil, err
 	}
 
-	// Create DID for the user if DID client is available
-	if s.didClient != nil {
-		didRequest := &clients.DIDCreateRequest{
-			UserID:   user.ID.String(),
-			Name:     user.Name,
-			Email:    user.Email,
-			Password: req.Password, // Use original password for DID hash
-		}
-
-		didResponse, err := s.didClient.CreateDID(didRequest)
+	// Create the user's DID, issue their base credential and send the verification
+	// email; steps that fail are retried by the saga without failing the signup, unless
+	// the DID policy requires the DID
+	if s.signup != nil {
+		saga, err := s.signup.Start(ctx, user)
 		if err != nil {
-			s.logger.Warn(ctx, "failed to create DID for user", map[string]any{
+			s.logger.Warn(ctx, "signup undone", map[string]any{
 				"user_id": user.ID.String(),
 				"error":   err.Error(),
 			})
-			// Don't fail user creation if DID creation fails
-		} else {
-			// Update user with DID information
-			user.DID = didResponse.Data.DIDRecord.DID
-			user.UserHash = didResponse.Data.UserHash
-
-			s.logger.Info(ctx, "DID created successfully for user", map[string]any{
-				"user_id": user.ID.String(),
-				"did":     didResponse.Data.DIDRecord.DID,
-				"status":  didResponse.Data.Status,
-			})
+			return nil, err
 		}
+		user.DID = saga.DID
+		user.UserHash = saga.UserHash
 	}
 
 	s.logger.Info(ctx, "user registered successfully", map[string]any{
@@ -81,3 +88,27 @@ func (s *AuthService) SignUp(ctx context.Context, req *models.UserCreateRequest)
 	})
 	return user, nil
 }
+
+// VerifyEmail confirms a user's email address with the single-use token sent by the
+// signup saga
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	verificationToken, err := s.DB.ConsumeEmailVerificationToken(ctx, utils.HashToken(token))
+	if errors.Is(err, repository.ErrEmailVerificationNotFound) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+	if time.Now().After(verificationToken.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	if err := s.DB.MarkEmailVerified(ctx, verificationToken.UserID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "email verified", map[string]any{
+		"user_id": verificationToken.UserID.String(),
+	})
+	return nil
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/repository"
+	"auth-service/models"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+)
+
+// UserDataExport is the archive returned to a user making a data-subject access
+// request
+type UserDataExport struct {
+	ExportedAt time.Time    `json:"exported_at"`
+	Profile    *models.User `json:"profile"`
+	// ConsentReceipts are the scopes the user granted to third-party applications
+	ConsentReceipts []models.OAuthConsent `json:"consent_receipts"`
+	// Identity is the DID Manager's export of the user's DIDs, DID documents,
+	// credentials and audit trail; it is absent when DID integration is disabled
+	Identity json.RawMessage `json:"identity,omitempty"`
+}
+
+// ExportService assembles users' data exports
+type ExportService struct {
+	DB        *repository.DB
+	logger    *zlog.Logger
+	didClient *clients.DIDClient
+}
+
+// NewExportService creates a new export service; didClient may be nil when DID
+// integration is disabled
+func NewExportService(db *repository.DB, logger *zlog.Logger, didClient *clients.DIDClient) *ExportService {
+	return &ExportService{
+		DB:        db,
+		logger:    logger,
+		didClient: didClient,
+	}
+}
+
+// ExportUser gathers everything held about a user: their profile and consents from
+// this service, and their identity data from the DID Manager
+func (s *ExportService) ExportUser(ctx context.Context, userID uuid.UUID) (*UserDataExport, error) {
+	user, err := s.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	consents, err := s.DB.ListOAuthConsentsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &UserDataExport{
+		ExportedAt:      time.Now(),
+		Profile:         user,
+		ConsentReceipts: consents,
+	}
+
+	if s.didClient != nil {
+		identity, err := s.didClient.ExportUserData(userID.String())
+		if err != nil {
+			s.logger.Error(ctx, err, "failed to export user data from DID Manager", http.StatusBadGateway, map[string]any{
+				"user_id": userID.String(),
+			})
+			return nil, err
+		}
+		export.Identity = identity
+	}
+
+	s.logger.Info(ctx, "user data exported", map[string]any{
+		"user_id":  userID.String(),
+		"consents": len(consents),
+	})
+	return export, nil
+}
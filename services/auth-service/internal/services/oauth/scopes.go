@@ -0,0 +1,113 @@
+package oauth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Wallet API scopes that third-party applications can request
+const (
+	ScopeDIDsRead            = "wallet:dids:read"
+	ScopeCredentialsRead     = "wallet:credentials:read"
+	ScopePresentationsCreate = "wallet:presentations:create"
+	ScopeNotificationsManage = "wallet:notifications:manage"
+	ScopeDIDsTransfer        = "wallet:dids:transfer"
+	ScopeDeviceKeysManage    = "wallet:device_keys:manage"
+)
+
+// DID Manager operation scopes, granted to service clients with the client credentials
+// grant. Each maps to a group of DID Manager operations, enforced by its middleware.
+const (
+	ScopeDIDRead   = "did:read"
+	ScopeDIDWrite  = "did:write"
+	ScopeVCIssue   = "vc:issue"
+	ScopeVCVerify  = "vc:verify"
+	ScopeAdminJobs = "admin:jobs"
+)
+
+// ScopeInfo describes a scope for consent screens
+type ScopeInfo struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// scopeDescriptions holds the user-facing description of every supported scope
+var scopeDescriptions = map[string]string{
+	ScopeDIDsRead:            "View your decentralized identifiers",
+	ScopeCredentialsRead:     "View the credentials held in your wallet",
+	ScopePresentationsCreate: "Share credentials from your wallet by creating presentations",
+	ScopeNotificationsManage: "Register devices to receive notifications about your wallet",
+	ScopeDIDsTransfer:        "Take control of your decentralized identifiers by moving them to your own keys",
+	ScopeDeviceKeysManage:    "Add and remove the device keys that sign in with your decentralized identifiers",
+}
+
+// serviceScopeDescriptions describes every DID Manager operation scope. They are never
+// shown on consent screens: users cannot grant them.
+var serviceScopeDescriptions = map[string]string{
+	ScopeDIDRead:   "Resolve DIDs and read their status, receipts and anchoring proofs",
+	ScopeDIDWrite:  "Create and deactivate DIDs of the tenant",
+	ScopeVCIssue:   "Issue and revoke credentials and delegations",
+	ScopeVCVerify:  "Verify DIDs, credentials, age proofs and delegations",
+	ScopeAdminJobs: "List, inspect, simulate, cancel and archive blockchain jobs",
+}
+
+// ParseScopes splits a space-separated wallet scope string, removing duplicates and
+// rejecting unknown scopes
+func ParseScopes(scope string) ([]string, error) {
+	return parseScopes(scope, scopeDescriptions)
+}
+
+// ParseServiceScopes splits a space-separated DID Manager operation scope string,
+// removing duplicates and rejecting unknown scopes
+func ParseServiceScopes(scope string) ([]string, error) {
+	return parseScopes(scope, serviceScopeDescriptions)
+}
+
+// parseScopes splits a space-separated scope string, accepting only known scopes
+func parseScopes(scope string, known map[string]string) ([]string, error) {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if _, ok := known[s]; !ok {
+			return nil, fmt.Errorf("unknown scope: %s", s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+// JoinScopes formats scopes as a space-separated string
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// DescribeScopes returns consent screen descriptions for scopes
+func DescribeScopes(scopes []string) []ScopeInfo {
+	infos := make([]ScopeInfo, 0, len(scopes))
+	for _, s := range scopes {
+		infos = append(infos, ScopeInfo{Scope: s, Description: scopeDescriptions[s]})
+	}
+	return infos
+}
+
+// containsAll reports whether granted includes every scope in requested
+func containsAll(granted, requested []string) bool {
+	set := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		set[s] = true
+	}
+	for _, s := range requested {
+		if !set[s] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single scope",
+			scope: ScopeDIDsRead,
+			want:  []string{ScopeDIDsRead},
+		},
+		{
+			name:  "duplicates removed and sorted",
+			scope: ScopePresentationsCreate + " " + ScopeCredentialsRead + " " + ScopePresentationsCreate,
+			want:  []string{ScopeCredentialsRead, ScopePresentationsCreate},
+		},
+		{
+			name:    "unknown scope",
+			scope:   ScopeDIDsRead + " wallet:keys:export",
+			wantErr: true,
+		},
+		{
+			name:    "empty scope",
+			scope:   "  ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScopes(tt.scope)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseServiceScopes(t *testing.T) {
+	scopes, err := ParseServiceScopes(ScopeVCVerify + " " + ScopeDIDRead + " " + ScopeDIDRead)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ScopeDIDRead, ScopeVCVerify}, scopes)
+
+	// Wallet and operation scopes are granted through different flows
+	_, err = ParseServiceScopes(ScopeDIDsRead)
+	assert.Error(t, err)
+	_, err = ParseScopes(ScopeAdminJobs)
+	assert.Error(t, err)
+}
+
+func TestDescribeScopes(t *testing.T) {
+	infos := DescribeScopes([]string{ScopeDIDsRead, ScopeCredentialsRead})
+
+	assert.Len(t, infos, 2)
+	for _, info := range infos {
+		assert.NotEmpty(t, info.Description)
+	}
+}
+
+func TestContainsAll(t *testing.T) {
+	granted := []string{ScopeDIDsRead, ScopeCredentialsRead}
+
+	assert.True(t, containsAll(granted, []string{ScopeDIDsRead}))
+	assert.True(t, containsAll(granted, granted))
+	assert.False(t, containsAll(granted, []string{ScopePresentationsCreate}))
+}
+
+func TestHashSecret(t *testing.T) {
+	assert.Equal(t, hashSecret("code"), hashSecret("code"))
+	assert.NotEqual(t, hashSecret("code"), hashSecret("other"))
+	assert.Len(t, hashSecret("code"), 64)
+}
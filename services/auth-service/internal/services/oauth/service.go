@@ -0,0 +1,378 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"auth-service/internal/repository"
+	"auth-service/models"
+	"auth-service/utils"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+)
+
+// authorizationCodeTTL bounds how long a consent redirect can wait before the code is exchanged
+const authorizationCodeTTL = 5 * time.Minute
+
+// OAuth errors, named after the RFC 6749 error codes they map to
+var (
+	ErrInvalidClient      = errors.New("invalid_client")
+	ErrInvalidGrant       = errors.New("invalid_grant")
+	ErrInvalidScope       = errors.New("invalid_scope")
+	ErrInvalidRedirectURI = errors.New("invalid_redirect_uri")
+	ErrInvalidRequest     = errors.New("invalid_request")
+	// ErrUnauthorizedClient is returned when a client uses a grant it is not registered for
+	ErrUnauthorizedClient = errors.New("unauthorized_client")
+)
+
+// ConsentScreen is the data a wallet UI needs to ask a user for consent
+type ConsentScreen struct {
+	ClientID        string      `json:"client_id"`
+	ClientName      string      `json:"client_name"`
+	RedirectURI     string      `json:"redirect_uri"`
+	RequestedScopes []ScopeInfo `json:"requested_scopes"`
+	GrantedScopes   []string    `json:"granted_scopes"`
+	// AlreadyGranted is true when earlier consent covers every requested scope
+	AlreadyGranted bool `json:"already_granted"`
+}
+
+// TokenResponse is the RFC 6749 access token response
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// OAuthService issues scoped wallet tokens to third-party applications, and DID
+// Manager operation tokens to service clients
+type OAuthService struct {
+	DB          *repository.DB
+	logger      *zlog.Logger
+	tokenSecret string
+	tokenTTL    time.Duration
+
+	// serviceTokenSecret signs service tokens, shared with the DID Manager; service
+	// tokens are not issued without it
+	serviceTokenSecret string
+	serviceTokenTTL    time.Duration
+}
+
+// NewOAuthService creates a new OAuth service
+func NewOAuthService(db *repository.DB, logger *zlog.Logger, tokenSecret string, tokenTTL time.Duration) *OAuthService {
+	return &OAuthService{
+		DB:          db,
+		logger:      logger,
+		tokenSecret: tokenSecret,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+// SetServiceTokens enables the client credentials grant, issuing service tokens signed
+// with secret and valid for ttl
+func (s *OAuthService) SetServiceTokens(secret string, ttl time.Duration) {
+	s.serviceTokenSecret = secret
+	s.serviceTokenTTL = ttl
+}
+
+// ServiceTokensEnabled reports whether service tokens can be issued
+func (s *OAuthService) ServiceTokensEnabled() bool {
+	return s.serviceTokenSecret != ""
+}
+
+// RegisterClient registers a third-party application and returns its one-time visible secret
+func (s *OAuthService) RegisterClient(ctx context.Context, ownerID uuid.UUID, name string, redirectURIs []string, scope string) (*models.OAuthClient, string, error) {
+	if strings.TrimSpace(name) == "" || len(redirectURIs) == 0 {
+		return nil, "", fmt.Errorf("%w: name and at least one redirect URI are required", ErrInvalidRequest)
+	}
+	for _, uri := range redirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidRedirectURI, uri)
+		}
+	}
+
+	scopes, err := ParseScopes(scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidScope, err)
+	}
+
+	clientID, err := randomHex(16)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := s.DB.CreateOAuthClient(ctx, &models.OAuthClient{
+		ClientID:         "wc_" + clientID,
+		ClientSecretHash: hashSecret(secret),
+		Name:             name,
+		RedirectURIs:     strings.Join(redirectURIs, " "),
+		AllowedScopes:    JoinScopes(scopes),
+		OwnerID:          &ownerID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// RegisterServiceClient registers a service client acting as a DID Manager tenant, the
+// ID of one of its API keys, and returns its one-time visible secret. The client
+// obtains tokens for its allowed DID Manager operation scopes with client credentials.
+func (s *OAuthService) RegisterServiceClient(ctx context.Context, name, tenant, scope string) (*models.OAuthClient, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if _, err := uuid.Parse(tenant); err != nil {
+		return nil, "", fmt.Errorf("%w: tenant must be the ID of a DID Manager API key", ErrInvalidRequest)
+	}
+
+	scopes, err := ParseServiceScopes(scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidScope, err)
+	}
+
+	clientID, err := randomHex(16)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := s.DB.CreateOAuthClient(ctx, &models.OAuthClient{
+		ClientID:         "sc_" + clientID,
+		ClientSecretHash: hashSecret(secret),
+		Name:             name,
+		AllowedScopes:    JoinScopes(scopes),
+		Tenant:           tenant,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// IssueServiceToken authenticates a service client with its secret and issues a token
+// for the requested DID Manager operation scopes, or all the client's allowed scopes
+// when scope is empty
+func (s *OAuthService) IssueServiceToken(ctx context.Context, clientID, clientSecret, scope string) (*TokenResponse, error) {
+	client, err := s.DB.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, ErrInvalidClient
+	}
+	if !client.IsService() {
+		return nil, ErrUnauthorizedClient
+	}
+
+	if scope == "" {
+		scope = client.AllowedScopes
+	}
+	scopes, err := ParseServiceScopes(scope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScope, err)
+	}
+	if !containsAll(strings.Fields(client.AllowedScopes), scopes) {
+		return nil, fmt.Errorf("%w: client is not allowed to request these scopes", ErrInvalidScope)
+	}
+
+	token, err := utils.GenerateServiceAccessToken(clientID, client.Tenant, JoinScopes(scopes), s.serviceTokenTTL, s.serviceTokenSecret)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to generate service access token", http.StatusInternalServerError, nil)
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.serviceTokenTTL.Seconds()),
+		Scope:       JoinScopes(scopes),
+	}, nil
+}
+
+// ConsentScreen validates an authorization request and describes it for the consent UI
+func (s *OAuthService) ConsentScreen(ctx context.Context, userID uuid.UUID, clientID, redirectURI, scope string) (*ConsentScreen, error) {
+	client, scopes, err := s.validateAuthorizationRequest(ctx, clientID, redirectURI, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	screen := &ConsentScreen{
+		ClientID:        client.ClientID,
+		ClientName:      client.Name,
+		RedirectURI:     redirectURI,
+		RequestedScopes: DescribeScopes(scopes),
+		GrantedScopes:   []string{},
+	}
+
+	consent, err := s.DB.GetOAuthConsent(ctx, userID, clientID)
+	if err != nil && !errors.Is(err, repository.ErrOAuthNotFound) {
+		return nil, err
+	}
+	if consent != nil {
+		screen.GrantedScopes = strings.Fields(consent.Scopes)
+		screen.AlreadyGranted = containsAll(screen.GrantedScopes, scopes)
+	}
+
+	return screen, nil
+}
+
+// Approve records the user's consent and returns an authorization code for the client
+func (s *OAuthService) Approve(ctx context.Context, userID uuid.UUID, clientID, redirectURI, scope string) (string, error) {
+	_, scopes, err := s.validateAuthorizationRequest(ctx, clientID, redirectURI, scope)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.DB.UpsertOAuthConsent(ctx, userID, clientID, JoinScopes(scopes)); err != nil {
+		return "", err
+	}
+
+	code, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.DB.StoreAuthorizationCode(ctx, &models.OAuthAuthorizationCode{
+		CodeHash:    hashSecret(code),
+		ClientID:    clientID,
+		UserID:      userID,
+		RedirectURI: redirectURI,
+		Scopes:      JoinScopes(scopes),
+		ExpiresAt:   time.Now().Add(authorizationCodeTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	s.logger.Info(ctx, "oauth consent granted", map[string]any{
+		"user_id":   userID.String(),
+		"client_id": clientID,
+		"scopes":    JoinScopes(scopes),
+	})
+	return code, nil
+}
+
+// ExchangeCode exchanges an authorization code for a scoped wallet access token
+func (s *OAuthService) ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI string) (*TokenResponse, error) {
+	client, err := s.DB.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, ErrInvalidClient
+	}
+
+	authCode, err := s.DB.ConsumeAuthorizationCode(ctx, hashSecret(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthNotFound) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+	if authCode.ClientID != clientID || authCode.RedirectURI != redirectURI || time.Now().After(authCode.ExpiresAt) {
+		return nil, ErrInvalidGrant
+	}
+
+	// Consent may have been withdrawn between approval and exchange
+	consent, err := s.DB.GetOAuthConsent(ctx, authCode.UserID, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthNotFound) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+	scopes := strings.Fields(authCode.Scopes)
+	if !containsAll(strings.Fields(consent.Scopes), scopes) {
+		return nil, ErrInvalidGrant
+	}
+
+	token, err := utils.GenerateWalletAccessToken(authCode.UserID.String(), clientID, authCode.Scopes, s.tokenTTL, s.tokenSecret)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to generate wallet access token", http.StatusInternalServerError, nil)
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.tokenTTL.Seconds()),
+		Scope:       authCode.Scopes,
+	}, nil
+}
+
+// RevokeConsent withdraws a user's consent for a client. Tokens already issued stay
+// valid until they expire, which is why wallet tokens are short-lived.
+func (s *OAuthService) RevokeConsent(ctx context.Context, userID uuid.UUID, clientID string) error {
+	return s.DB.DeleteOAuthConsent(ctx, userID, clientID)
+}
+
+// validateAuthorizationRequest checks the client, redirect URI and requested scopes
+func (s *OAuthService) validateAuthorizationRequest(ctx context.Context, clientID, redirectURI, scope string) (*models.OAuthClient, []string, error) {
+	client, err := s.DB.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthNotFound) {
+			return nil, nil, ErrInvalidClient
+		}
+		return nil, nil, err
+	}
+
+	// Service clients act for a tenant, not a user, and cannot ask for consent
+	if client.IsService() {
+		return nil, nil, ErrUnauthorizedClient
+	}
+	if !containsAll(strings.Fields(client.RedirectURIs), []string{redirectURI}) {
+		return nil, nil, ErrInvalidRedirectURI
+	}
+
+	scopes, err := ParseScopes(scope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidScope, err)
+	}
+	if !containsAll(strings.Fields(client.AllowedScopes), scopes) {
+		return nil, nil, fmt.Errorf("%w: client is not allowed to request these scopes", ErrInvalidScope)
+	}
+
+	return client, scopes, nil
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecret hashes client secrets and authorization codes for storage
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,9 +4,14 @@ import (
 	"auth-service/config"
 	"auth-service/internal/clients"
 	"auth-service/internal/repository"
+	"auth-service/internal/services/anomaly"
 	auth "auth-service/internal/services/auth"
+	"auth-service/internal/services/export"
+	"auth-service/internal/services/oauth"
+	"auth-service/internal/services/signup"
 	"auth-service/internal/services/users"
 	"os"
+	"time"
 
 	zlog "packages/logger"
 )
@@ -17,6 +22,10 @@ type Service struct {
 	DB     *repository.DB
 	User   *users.UserService
 	Auth   *auth.AuthService
+	OAuth  *oauth.OAuthService
+	Export *export.ExportService
+	// Signup runs the signup saga of each new user and retries its failed steps
+	Signup *signup.Orchestrator
 }
 
 // NewService creates a new service instance
@@ -25,7 +34,7 @@ func NewService(db *repository.DB, logger *zlog.Logger, cfg *config.Config) *Ser
 	var didClient *clients.DIDClient
 	didManagerURL := os.Getenv("DID_MANAGER_URL")
 	if didManagerURL != "" {
-		didClient = clients.NewDIDClient(didManagerURL)
+		didClient = clients.NewDIDClient(didManagerURL, os.Getenv("DID_MANAGER_ADMIN_KEY"))
 		logger.Info(nil, "DID Manager client initialized", map[string]any{
 			"url": didManagerURL,
 		})
@@ -33,10 +42,104 @@ func NewService(db *repository.DB, logger *zlog.Logger, cfg *config.Config) *Ser
 		logger.Warn(nil, "DID_MANAGER_URL not set, DID integration disabled")
 	}
 
+	authService := auth.NewAuthService(db, logger, didClient)
+	if cfg.PasswordResetWebhookURL != "" {
+		authService.SetPasswordReset(
+			newNotifier(logger, cfg, cfg.PasswordResetWebhookURL),
+			time.Duration(cfg.PasswordResetTokenTTL)*time.Minute,
+		)
+	}
+
+	if cfg.LoginAnomalyDetection {
+		authService.SetAnomalyDetector(newAnomalyDetector(db, logger, cfg, didClient))
+	}
+
+	orchestrator := newSignupOrchestrator(db, logger, cfg, didClient)
+	authService.SetSignupSaga(orchestrator)
+
+	oauthService := oauth.NewOAuthService(db, logger, cfg.WalletTokenSecret, time.Duration(cfg.WalletTokenTTL)*time.Minute)
+	if cfg.ServiceTokenSecret != "" {
+		oauthService.SetServiceTokens(cfg.ServiceTokenSecret, time.Duration(cfg.ServiceTokenTTL)*time.Minute)
+	}
+
 	return &Service{
 		Config: cfg,
 		DB:     db,
 		User:   users.NewUserService(db, logger),
-		Auth:   auth.NewAuthService(db, logger, didClient),
+		Auth:   authService,
+		OAuth:  oauthService,
+		Export: export.NewExportService(db, logger, didClient),
+		Signup: orchestrator,
+	}
+}
+
+// newNotifier creates a notifier posting notices to webhookURL in cfg's default locale
+func newNotifier(logger *zlog.Logger, cfg *config.Config, webhookURL string) *clients.WebhookNotifier {
+	notifier := clients.NewWebhookNotifier(webhookURL)
+	if err := notifier.SetDefaultLocale(cfg.DefaultLocale); err != nil {
+		logger.Warn(nil, "invalid default locale, sending notices in English", map[string]any{
+			"error": err.Error(),
+		})
+	}
+	return notifier
+}
+
+// newAnomalyDetector creates the sign-in anomaly detector configured by cfg
+func newAnomalyDetector(db *repository.DB, logger *zlog.Logger, cfg *config.Config, didClient *clients.DIDClient) *anomaly.Detector {
+	signals := []anomaly.Signal{
+		anomaly.NewCredentialStuffing(db, time.Duration(cfg.CredentialStuffingWindow)*time.Minute, cfg.CredentialStuffingThreshold),
+	}
+	if cfg.GeoIPLookupURL != "" {
+		signals = append(signals, anomaly.NewImpossibleTravel(db, float64(cfg.ImpossibleTravelMaxSpeed)))
+	}
+
+	detector := anomaly.NewDetector(db, logger, signals...)
+	if cfg.GeoIPLookupURL != "" {
+		detector.SetLocator(clients.NewGeoIPClient(cfg.GeoIPLookupURL))
+	}
+	if cfg.LoginAnomalySuspendDIDs && didClient != nil {
+		detector.SetSuspender(didClient)
+	}
+	if cfg.SecurityAlertWebhookURL != "" {
+		detector.SetNotifier(newNotifier(logger, cfg, cfg.SecurityAlertWebhookURL))
+	}
+	return detector
+}
+
+// newSignupOrchestrator creates the signup saga orchestrator configured by cfg
+func newSignupOrchestrator(db *repository.DB, logger *zlog.Logger, cfg *config.Config, didClient *clients.DIDClient) *signup.Orchestrator {
+	orchestrator := signup.NewOrchestrator(db, logger, cfg.SignupSagaMaxAttempts)
+	policy, err := signup.ParsePolicy(cfg.SignupDIDPolicy)
+	if err != nil {
+		logger.Warn(nil, "invalid signup DID policy, creating DIDs in the background", map[string]any{
+			"error": err.Error(),
+		})
+		policy = signup.PolicyBackground
+	}
+	if didClient != nil {
+		orchestrator.SetIdentity(didClient, policy)
+		if cfg.SignupCredentialIssuerDID != "" {
+			issuer, err := clients.NewCredentialIssuer(didClient, cfg.SignupCredentialIssuerDID,
+				cfg.SignupCredentialIssuerKeyID, cfg.SignupCredentialIssuerKey)
+			if err != nil {
+				logger.Warn(nil, "invalid signup credential issuer, base credentials disabled", map[string]any{
+					"error": err.Error(),
+				})
+			} else {
+				orchestrator.SetCredentialIssuer(issuer, cfg.SignupCredentialType)
+			}
+		}
+		if cfg.WalletOnboardingWebhookURL != "" {
+			orchestrator.SetWalletOffers(didClient, newNotifier(logger, cfg, cfg.WalletOnboardingWebhookURL))
+		}
+	} else if policy == signup.PolicyRequired {
+		logger.Warn(nil, "SIGNUP_DID_POLICY=required needs DID_MANAGER_URL, users are created without DIDs")
+	}
+	if cfg.EmailVerificationWebhookURL != "" {
+		orchestrator.SetVerification(
+			newNotifier(logger, cfg, cfg.EmailVerificationWebhookURL),
+			time.Duration(cfg.EmailVerificationTokenTTL)*time.Minute,
+		)
 	}
+	return orchestrator
 }
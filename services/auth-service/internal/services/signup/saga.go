@@ -0,0 +1,605 @@
+// Package signup runs the signup saga: once a user is created, their DID is created, a
+// base credential is issued to it, a verification email is sent and, once the DID is
+// active, the user is sent a link importing it into a mobile wallet. The saga's state is
+// persisted and failed steps are retried with backoff. The DID policy decides whether
+// signup blocks on the DID, creates it in the background or skips it.
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+	"auth-service/utils"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+)
+
+// Store persists signup sagas and the records their steps create
+type Store interface {
+	CreateSignupSaga(ctx context.Context, saga *models.SignupSaga) error
+	UpdateSignupSaga(ctx context.Context, saga *models.SignupSaga) error
+	GetSignupSaga(ctx context.Context, id uuid.UUID) (*models.SignupSaga, error)
+	GetSignupSagaByUser(ctx context.Context, userID uuid.UUID) (*models.SignupSaga, error)
+	ClaimDueSignupSagas(ctx context.Context, lease time.Duration, limit int) ([]models.SignupSaga, error)
+	ListStuckSignupSagas(ctx context.Context, limit int) ([]models.SignupSaga, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	SetUserDID(ctx context.Context, userID uuid.UUID, did, userHash string) error
+	StoreEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error
+}
+
+// Identity creates users' DIDs
+type Identity interface {
+	GetOrCreateDID(req *clients.DIDCreateRequest) (*clients.DIDCreateResponse, error)
+	CreateDID(req *clients.DIDCreateRequest) (*clients.DIDCreateResponse, error)
+}
+
+// Issuer issues credentials to DIDs
+type Issuer interface {
+	IssueCredential(req *clients.CredentialIssueRequest) (*clients.Credential, error)
+}
+
+// WalletOffers offers DIDs to their owners' mobile wallets
+type WalletOffers interface {
+	CreateWalletOffer(req *clients.WalletOfferRequest) (*clients.WalletOfferResponse, error)
+	GetWalletOffer(id string) (*clients.WalletOffer, error)
+}
+
+// Notifier delivers notices to users
+type Notifier interface {
+	Send(notice any) error
+}
+
+// Policy decides how signup and sign-in treat users' DIDs
+type Policy string
+
+const (
+	// PolicyRequired blocks signup on the DID: a user whose DID cannot be created right
+	// away is deleted again, and users without a DID get one before they sign in
+	PolicyRequired Policy = "required"
+	// PolicyBackground creates the DID as a saga step retried in the background; users
+	// are usable without one
+	PolicyBackground Policy = "background"
+	// PolicySkip creates no DIDs, and so issues no base credentials
+	PolicySkip Policy = "skip"
+)
+
+// ParsePolicy parses a DID policy; empty selects PolicyBackground
+func ParsePolicy(value string) (Policy, error) {
+	switch policy := Policy(value); policy {
+	case "":
+		return PolicyBackground, nil
+	case PolicyRequired, PolicyBackground, PolicySkip:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown DID policy %q, expected required, background or skip", value)
+	}
+}
+
+// Signup saga errors
+var (
+	// ErrDIDRequired is returned when a user has no DID, none can be created right now
+	// and the policy requires one. A signup failing with it was undone.
+	ErrDIDRequired = errors.New("the user has no DID and the signup policy requires one")
+	// ErrNotRetryable is returned when retrying a saga that has not failed
+	ErrNotRetryable = errors.New("only failed signup sagas can be retried")
+	// ErrNoWalletOffer is returned when the user's signup has not offered their DID to a
+	// wallet
+	ErrNoWalletOffer = errors.New("no wallet offer was sent to the user")
+)
+
+const (
+	// claimLease is how long a saga being run is hidden from other workers
+	claimLease = 5 * time.Minute
+	// claimBatch is the number of due sagas run per pass
+	claimBatch = 50
+	// Retries of a failed step back off exponentially from retryBaseDelay
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+	// verificationTokenBytes is the entropy of an email verification token
+	verificationTokenBytes = 32
+)
+
+// Orchestrator runs signup sagas. Steps without the client they need are skipped.
+type Orchestrator struct {
+	store       Store
+	logger      *zlog.Logger
+	maxAttempts int
+	// identity creates the DID as policy decides
+	identity Identity
+	policy   Policy
+	// issuer issues the base credential of type credentialType
+	issuer         Issuer
+	credentialType string
+	// notifier sends verification tokens valid for verificationTTL
+	notifier        Notifier
+	verificationTTL time.Duration
+	// walletOffers offers the DID to the user's wallet with a link sent through
+	// walletNotifier
+	walletOffers   WalletOffers
+	walletNotifier Notifier
+}
+
+// NewOrchestrator creates an orchestrator giving each step maxAttempts attempts before
+// it is given up
+func NewOrchestrator(store Store, logger *zlog.Logger, maxAttempts int) *Orchestrator {
+	return &Orchestrator{
+		store:       store,
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		policy:      PolicySkip,
+	}
+}
+
+// SetIdentity creates users' DIDs through identity as policy decides
+func (o *Orchestrator) SetIdentity(identity Identity, policy Policy) {
+	o.identity = identity
+	o.policy = policy
+}
+
+// RequiresDID reports whether users need a DID to sign in
+func (o *Orchestrator) RequiresDID() bool {
+	return o.policy == PolicyRequired
+}
+
+// SetCredentialIssuer issues a base credential of credentialType to each new DID
+// through issuer
+func (o *Orchestrator) SetCredentialIssuer(issuer Issuer, credentialType string) {
+	o.issuer = issuer
+	o.credentialType = credentialType
+}
+
+// SetVerification sends new users an email verification token valid for ttl through
+// notifier
+func (o *Orchestrator) SetVerification(notifier Notifier, ttl time.Duration) {
+	o.notifier = notifier
+	o.verificationTTL = ttl
+}
+
+// SetWalletOffers offers each new DID, once active, and its base credential to the
+// user's mobile wallet through offers, sending the claim link through notifier
+func (o *Orchestrator) SetWalletOffers(offers WalletOffers, notifier Notifier) {
+	o.walletOffers = offers
+	o.walletNotifier = notifier
+}
+
+// Start begins the signup saga of a newly created user and runs it as far as it goes.
+// Steps that fail are left to Run. When the saga cannot be stored, or the user was
+// deleted because the policy requires a DID and none could be created, the signup fails.
+func (o *Orchestrator) Start(ctx context.Context, user *models.User) (*models.SignupSaga, error) {
+	saga := &models.SignupSaga{
+		UserID: user.ID,
+		Email:  user.Email,
+		Name:   user.Name,
+		Locale: user.Locale,
+		Step:   models.SignupStepCreateDID,
+		Status: models.SignupRunning,
+		// Leased to this run, so workers leave it alone until it is done
+		NextAttemptAt: time.Now().Add(claimLease),
+	}
+	if err := o.store.CreateSignupSaga(ctx, saga); err != nil {
+		// Without a saga the steps would never run, so the signup is undone
+		if deleteErr := o.store.DeleteUser(ctx, user.ID); deleteErr != nil {
+			o.logger.Error(ctx, deleteErr, "failed to delete user without signup saga", http.StatusInternalServerError, map[string]any{
+				"user_id": user.ID.String(),
+			})
+		}
+		return nil, err
+	}
+
+	o.run(ctx, saga)
+	if saga.Status == models.SignupCompensated {
+		return saga, fmt.Errorf("%w: %s", ErrDIDRequired, saga.LastError)
+	}
+	return saga, nil
+}
+
+// EnsureDID gives a user without a DID one before they sign in, when the policy
+// requires it; users who signed up under another policy may have none. It fails with
+// ErrDIDRequired when the DID cannot be created.
+func (o *Orchestrator) EnsureDID(ctx context.Context, user *models.User) error {
+	if !o.RequiresDID() || user.DID != "" {
+		return nil
+	}
+
+	did, userHash, err := o.getOrCreateDID(ctx, user.ID, user.Name, user.Email)
+	if err != nil {
+		o.logger.Warn(ctx, "no DID can be created for user signing in", map[string]any{
+			"user_id": user.ID.String(),
+			"error":   err.Error(),
+		})
+		return fmt.Errorf("%w: %v", ErrDIDRequired, err)
+	}
+	user.DID, user.UserHash = did, userHash
+	return nil
+}
+
+// Run retries the due steps of running sagas every interval until ctx is done
+func (o *Orchestrator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue runs the running sagas due for another attempt and returns how many it ran
+func (o *Orchestrator) RunDue(ctx context.Context) int {
+	sagas, err := o.store.ClaimDueSignupSagas(ctx, claimLease, claimBatch)
+	if err != nil {
+		return 0
+	}
+	for i := range sagas {
+		o.run(ctx, &sagas[i])
+	}
+	return len(sagas)
+}
+
+// Retry resumes a failed saga at the step it failed on and runs it
+func (o *Orchestrator) Retry(ctx context.Context, id uuid.UUID) (*models.SignupSaga, error) {
+	saga, err := o.store.GetSignupSaga(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status != models.SignupFailed {
+		return nil, ErrNotRetryable
+	}
+
+	saga.Status = models.SignupRunning
+	saga.Attempts = 0
+	saga.NextAttemptAt = time.Now().Add(claimLease)
+	if err := o.store.UpdateSignupSaga(ctx, saga); err != nil {
+		return nil, err
+	}
+
+	o.run(ctx, saga)
+	return saga, nil
+}
+
+// WalletOffer returns the offer of the user's DID to their wallet, storing its current
+// status on the saga so claims are tracked
+func (o *Orchestrator) WalletOffer(ctx context.Context, userID uuid.UUID) (*clients.WalletOffer, error) {
+	saga, err := o.store.GetSignupSagaByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.WalletOfferID == "" || o.walletOffers == nil {
+		return nil, ErrNoWalletOffer
+	}
+
+	offer, err := o.walletOffers.GetWalletOffer(saga.WalletOfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != saga.WalletOfferStatus {
+		saga.WalletOfferStatus = offer.Status
+		if err := o.store.UpdateSignupSaga(ctx, saga); err != nil {
+			return nil, err
+		}
+		o.logger.Info(ctx, "wallet offer status changed", map[string]any{
+			"user_id":  userID.String(),
+			"offer_id": offer.ID,
+			"status":   offer.Status,
+		})
+	}
+	return offer, nil
+}
+
+// Stuck returns up to limit failed sagas and running sagas retrying a failed step,
+// oldest first
+func (o *Orchestrator) Stuck(ctx context.Context, limit int) ([]models.SignupSaga, error) {
+	return o.store.ListStuckSignupSagas(ctx, limit)
+}
+
+// run runs a saga's steps until it completes or a step fails, storing its progress
+// after each step
+func (o *Orchestrator) run(ctx context.Context, saga *models.SignupSaga) {
+	for saga.Status == models.SignupRunning {
+		if saga.Step == models.SignupStepDone {
+			saga.Status = models.SignupCompleted
+			o.save(ctx, saga)
+			o.logger.Info(ctx, "signup saga completed", map[string]any{
+				"saga_id": saga.ID.String(),
+				"user_id": saga.UserID.String(),
+				"did":     saga.DID,
+			})
+			return
+		}
+
+		if err := o.runStep(ctx, saga); err != nil {
+			if !o.fail(ctx, saga, err) {
+				return
+			}
+			continue
+		}
+
+		saga.Step = nextStep(saga.Step)
+		saga.Attempts = 0
+		o.save(ctx, saga)
+	}
+}
+
+// runStep runs the saga's current step, recording its result on the saga
+func (o *Orchestrator) runStep(ctx context.Context, saga *models.SignupSaga) error {
+	switch saga.Step {
+	case models.SignupStepCreateDID:
+		return o.createDID(ctx, saga)
+	case models.SignupStepIssueCredential:
+		return o.issueCredential(ctx, saga)
+	case models.SignupStepSendVerificationEmail:
+		return o.sendVerificationEmail(ctx, saga)
+	case models.SignupStepCreateWalletOffer:
+		return o.createWalletOffer(ctx, saga)
+	default:
+		return nil
+	}
+}
+
+// createDID gives the user a DID, reusing the one they already have
+func (o *Orchestrator) createDID(ctx context.Context, saga *models.SignupSaga) error {
+	if o.identity == nil || o.policy == PolicySkip {
+		return nil
+	}
+
+	did, userHash, err := o.getOrCreateDID(ctx, saga.UserID, saga.Name, saga.Email)
+	if err != nil {
+		return err
+	}
+	saga.DID, saga.UserHash = did, userHash
+	return nil
+}
+
+// getOrCreateDID returns the DID the user already has or creates one, and records it
+// on the user
+func (o *Orchestrator) getOrCreateDID(ctx context.Context, userID uuid.UUID, name, email string) (string, string, error) {
+	if o.identity == nil {
+		return "", "", errors.New("DID Manager not configured")
+	}
+
+	didRequest := &clients.DIDCreateRequest{
+		UserID: userID.String(),
+		Name:   name,
+		Email:  email,
+	}
+
+	// A user who already has a DID keeps it; without the admin API, a DID is created
+	didResponse, err := o.identity.GetOrCreateDID(didRequest)
+	if errors.Is(err, clients.ErrAdminKeyMissing) {
+		didResponse, err = o.identity.CreateDID(didRequest)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	did, userHash := didResponse.Data.DIDRecord.DID, didResponse.Data.UserHash
+	if err := o.store.SetUserDID(ctx, userID, did, userHash); err != nil {
+		return "", "", err
+	}
+
+	message := "DID created successfully for user"
+	if didResponse.Data.Existing {
+		message = "existing DID linked to user"
+	}
+	o.logger.Info(ctx, message, map[string]any{
+		"user_id": userID.String(),
+		"did":     did,
+		"status":  didResponse.Data.Status,
+	})
+	return did, userHash, nil
+}
+
+// issueCredential issues the base credential to the user's DID
+func (o *Orchestrator) issueCredential(ctx context.Context, saga *models.SignupSaga) error {
+	if o.issuer == nil || saga.DID == "" {
+		return nil
+	}
+
+	credential, err := o.issuer.IssueCredential(&clients.CredentialIssueRequest{
+		SubjectDID: saga.DID,
+		Type:       o.credentialType,
+		Claims: map[string]any{
+			"account_id": saga.UserID.String(),
+			"email":      saga.Email,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	saga.CredentialID = credential.ID
+	o.logger.Info(ctx, "base credential issued to user", map[string]any{
+		"user_id":       saga.UserID.String(),
+		"did":           saga.DID,
+		"credential_id": credential.ID,
+	})
+	return nil
+}
+
+// sendVerificationEmail sends the user a single-use token confirming their email
+func (o *Orchestrator) sendVerificationEmail(ctx context.Context, saga *models.SignupSaga) error {
+	if o.notifier == nil {
+		return nil
+	}
+
+	token, err := utils.GenerateSecureToken(verificationTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	verificationToken := &models.EmailVerificationToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    saga.UserID,
+		ExpiresAt: time.Now().Add(o.verificationTTL),
+	}
+	if err := o.store.StoreEmailVerificationToken(ctx, verificationToken); err != nil {
+		return err
+	}
+
+	return o.notifier.Send(&clients.EmailVerificationNotice{
+		NoticeText: clients.NoticeText{Locale: saga.Locale},
+		Type:       clients.NoticeEmailVerification,
+		Email:      saga.Email,
+		Name:       saga.Name,
+		Token:      token,
+		ExpiresAt:  verificationToken.ExpiresAt,
+		DID:        saga.DID,
+	})
+}
+
+// createWalletOffer offers the user's DID and base credential to their mobile wallet
+// and sends them the claim link. The DID Manager refuses to offer a DID before it is
+// active, so the step is retried until the DID is anchored.
+func (o *Orchestrator) createWalletOffer(ctx context.Context, saga *models.SignupSaga) error {
+	if o.walletOffers == nil || o.walletNotifier == nil || saga.DID == "" {
+		return nil
+	}
+
+	req := &clients.WalletOfferRequest{DID: saga.DID}
+	if saga.CredentialID != "" {
+		req.CredentialIDs = []string{saga.CredentialID}
+	}
+	response, err := o.walletOffers.CreateWalletOffer(req)
+	if err != nil {
+		return err
+	}
+
+	// A link that fails to send is replaced by a new offer on the next attempt; the old
+	// one expires unclaimed
+	saga.WalletOfferID = response.Offer.ID
+	saga.WalletOfferStatus = response.Offer.Status
+	if err := o.walletNotifier.Send(&clients.WalletOnboardingNotice{
+		NoticeText: clients.NoticeText{Locale: saga.Locale},
+		Type:       clients.NoticeWalletOnboarding,
+		Email:      saga.Email,
+		Name:       saga.Name,
+		DID:        saga.DID,
+		ClaimURI:   response.ClaimURI,
+		QRImage:    response.QRImage,
+		ExpiresAt:  response.Offer.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	o.logger.Info(ctx, "wallet offer sent to user", map[string]any{
+		"user_id":  saga.UserID.String(),
+		"did":      saga.DID,
+		"offer_id": response.Offer.ID,
+	})
+	return nil
+}
+
+// fail records a failed attempt of the saga's current step and reports whether the
+// saga carries on right away. When the policy requires a DID, a DID that cannot be
+// created deletes the user at once. Other steps are retried with backoff until they
+// fail permanently or run out of attempts; a DID is then skipped together with the
+// credential, and any other step fails the saga.
+func (o *Orchestrator) fail(ctx context.Context, saga *models.SignupSaga, err error) bool {
+	saga.Attempts++
+	saga.LastError = err.Error()
+	fields := map[string]any{
+		"saga_id":  saga.ID.String(),
+		"user_id":  saga.UserID.String(),
+		"step":     string(saga.Step),
+		"attempts": saga.Attempts,
+		"error":    err.Error(),
+	}
+
+	required := saga.Step == models.SignupStepCreateDID && o.policy == PolicyRequired
+	if !required && !permanent(err) && saga.Attempts < o.maxAttempts {
+		saga.NextAttemptAt = time.Now().Add(backoff(saga.Attempts))
+		o.save(ctx, saga)
+		o.logger.Warn(ctx, "signup saga step failed, will retry", fields)
+		return false
+	}
+
+	if saga.Step != models.SignupStepCreateDID {
+		saga.Status = models.SignupFailed
+		o.save(ctx, saga)
+		o.logger.Error(ctx, err, "signup saga step failed", http.StatusBadGateway, fields)
+		return false
+	}
+
+	if !required {
+		saga.Step = models.SignupStepSendVerificationEmail
+		saga.Attempts = 0
+		o.save(ctx, saga)
+		o.logger.Warn(ctx, "no DID can be created for user, continuing signup without one", fields)
+		return true
+	}
+
+	// Compensate: the user cannot have the DID the policy requires, so they are removed
+	if deleteErr := o.store.DeleteUser(ctx, saga.UserID); deleteErr != nil {
+		saga.Status = models.SignupFailed
+		saga.LastError = "compensation failed: " + deleteErr.Error()
+		o.save(ctx, saga)
+		o.logger.Error(ctx, deleteErr, "failed to delete user without a DID", http.StatusInternalServerError, fields)
+		return false
+	}
+	saga.Status = models.SignupCompensated
+	o.save(ctx, saga)
+	o.logger.Warn(ctx, "no DID can be created for user, signup undone", fields)
+	return false
+}
+
+// save stores the saga's progress; a saga that cannot be stored is picked up again
+// when its lease runs out
+func (o *Orchestrator) save(ctx context.Context, saga *models.SignupSaga) {
+	if saga.Status == models.SignupRunning && saga.Attempts == 0 {
+		saga.NextAttemptAt = time.Now().Add(claimLease)
+	}
+	if err := o.store.UpdateSignupSaga(ctx, saga); err != nil {
+		o.logger.Warn(ctx, "failed to store signup saga", map[string]any{
+			"saga_id": saga.ID.String(),
+			"error":   err.Error(),
+		})
+	}
+}
+
+// nextStep returns the step following step
+func nextStep(step models.SignupStep) models.SignupStep {
+	switch step {
+	case models.SignupStepCreateDID:
+		return models.SignupStepIssueCredential
+	case models.SignupStepIssueCredential:
+		return models.SignupStepSendVerificationEmail
+	case models.SignupStepSendVerificationEmail:
+		return models.SignupStepCreateWalletOffer
+	default:
+		return models.SignupStepDone
+	}
+}
+
+// permanent reports whether a step failed in a way retrying cannot fix: the DID
+// Manager rejected the request itself rather than being unavailable, over quota or
+// waiting for the DID to be anchored
+func permanent(err error) bool {
+	var apiErr *clients.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if errors.Is(err, clients.ErrQuotaExceeded) || errors.Is(err, clients.ErrDegraded) || errors.Is(err, clients.ErrDIDNotActive) {
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// backoff is the delay before the next attempt of a step that failed attempts times
+func backoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
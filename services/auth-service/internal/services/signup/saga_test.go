@@ -0,0 +1,400 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/models"
+
+	zlog "packages/logger"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps sagas, users' DIDs, deleted users and verification tokens in memory
+type fakeStore struct {
+	sagas   map[uuid.UUID]models.SignupSaga
+	dids    map[uuid.UUID]string
+	deleted []uuid.UUID
+	tokens  []*models.EmailVerificationToken
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{sagas: map[uuid.UUID]models.SignupSaga{}, dids: map[uuid.UUID]string{}}
+}
+
+func (s *fakeStore) CreateSignupSaga(ctx context.Context, saga *models.SignupSaga) error {
+	saga.ID = uuid.New()
+	saga.CreatedAt = time.Now()
+	s.sagas[saga.ID] = *saga
+	return nil
+}
+
+func (s *fakeStore) UpdateSignupSaga(ctx context.Context, saga *models.SignupSaga) error {
+	s.sagas[saga.ID] = *saga
+	return nil
+}
+
+func (s *fakeStore) GetSignupSaga(ctx context.Context, id uuid.UUID) (*models.SignupSaga, error) {
+	saga, ok := s.sagas[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &saga, nil
+}
+
+func (s *fakeStore) GetSignupSagaByUser(ctx context.Context, userID uuid.UUID) (*models.SignupSaga, error) {
+	for _, saga := range s.sagas {
+		if saga.UserID == userID {
+			return &saga, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// ClaimDueSignupSagas claims every running saga regardless of its next attempt
+func (s *fakeStore) ClaimDueSignupSagas(ctx context.Context, lease time.Duration, limit int) ([]models.SignupSaga, error) {
+	var due []models.SignupSaga
+	for _, saga := range s.sagas {
+		if saga.Status == models.SignupRunning {
+			due = append(due, saga)
+		}
+	}
+	return due, nil
+}
+
+func (s *fakeStore) ListStuckSignupSagas(ctx context.Context, limit int) ([]models.SignupSaga, error) {
+	var stuck []models.SignupSaga
+	for _, saga := range s.sagas {
+		if saga.Status == models.SignupFailed || (saga.Status == models.SignupRunning && saga.Attempts > 0) {
+			stuck = append(stuck, saga)
+		}
+	}
+	return stuck, nil
+}
+
+func (s *fakeStore) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	s.deleted = append(s.deleted, userID)
+	return nil
+}
+
+func (s *fakeStore) SetUserDID(ctx context.Context, userID uuid.UUID, did, userHash string) error {
+	s.dids[userID] = did
+	return nil
+}
+
+func (s *fakeStore) StoreEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error {
+	s.tokens = append(s.tokens, token)
+	return nil
+}
+
+// fakeIdentity fails DID creation with errs in turn, then creates a DID
+type fakeIdentity struct {
+	errs  []error
+	calls int
+}
+
+func (i *fakeIdentity) GetOrCreateDID(req *clients.DIDCreateRequest) (*clients.DIDCreateResponse, error) {
+	i.calls++
+	if len(i.errs) > 0 {
+		err := i.errs[0]
+		i.errs = i.errs[1:]
+		return nil, err
+	}
+	return &clients.DIDCreateResponse{
+		Success: true,
+		Data: clients.DIDCreateResponseData{
+			DIDRecord: clients.DIDRecord{DID: "did:example:user:" + req.UserID},
+			UserHash:  "hash-" + req.UserID,
+		},
+	}, nil
+}
+
+func (i *fakeIdentity) CreateDID(req *clients.DIDCreateRequest) (*clients.DIDCreateResponse, error) {
+	return i.GetOrCreateDID(req)
+}
+
+type fakeIssuer struct {
+	err      error
+	subjects []string
+}
+
+func (i *fakeIssuer) IssueCredential(req *clients.CredentialIssueRequest) (*clients.Credential, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	i.subjects = append(i.subjects, req.SubjectDID)
+	return &clients.Credential{ID: "credential-1", SubjectDID: req.SubjectDID, Type: req.Type}, nil
+}
+
+type fakeNotifier struct {
+	notices []any
+}
+
+func (n *fakeNotifier) Send(notice any) error {
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+// fakeWalletOffers refuses to offer DIDs with errs in turn, then offers them
+type fakeWalletOffers struct {
+	errs   []error
+	offers map[string]*clients.WalletOffer
+}
+
+func (w *fakeWalletOffers) CreateWalletOffer(req *clients.WalletOfferRequest) (*clients.WalletOfferResponse, error) {
+	if len(w.errs) > 0 {
+		err := w.errs[0]
+		w.errs = w.errs[1:]
+		return nil, err
+	}
+	offer := &clients.WalletOffer{
+		ID:            uuid.NewString(),
+		DID:           req.DID,
+		CredentialIDs: req.CredentialIDs,
+		Status:        "pending",
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+	w.offers[offer.ID] = offer
+	return &clients.WalletOfferResponse{Offer: *offer, ClaimURI: "didwallet://claim?code=" + offer.ID}, nil
+}
+
+func (w *fakeWalletOffers) GetWalletOffer(id string) (*clients.WalletOffer, error) {
+	offer, ok := w.offers[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return offer, nil
+}
+
+func newTestOrchestrator(store *fakeStore, identity *fakeIdentity, policy Policy) (*Orchestrator, *fakeIssuer, *fakeNotifier) {
+	issuer, notifier := &fakeIssuer{}, &fakeNotifier{}
+	orchestrator := NewOrchestrator(store, zlog.NewLogger(zlog.Config{Level: "error"}), 3)
+	orchestrator.SetIdentity(identity, policy)
+	orchestrator.SetCredentialIssuer(issuer, "AccountCredential")
+	orchestrator.SetVerification(notifier, time.Hour)
+	return orchestrator, issuer, notifier
+}
+
+func newUser() *models.User {
+	return &models.User{ID: uuid.New(), Name: "Ada Lovelace", Email: "ada@example.com"}
+}
+
+func TestOrchestrator_CompletesSaga(t *testing.T) {
+	store := newFakeStore()
+	orchestrator, issuer, notifier := newTestOrchestrator(store, &fakeIdentity{}, PolicyRequired)
+	user := newUser()
+
+	saga, err := orchestrator.Start(context.Background(), user)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.SignupCompleted, saga.Status)
+	assert.Equal(t, models.SignupStepDone, saga.Step)
+	assert.Equal(t, "did:example:user:"+user.ID.String(), saga.DID)
+	assert.Equal(t, saga.DID, store.dids[user.ID], "the DID is recorded on the user")
+	assert.Equal(t, "credential-1", saga.CredentialID)
+	assert.Equal(t, []string{saga.DID}, issuer.subjects)
+
+	require.Len(t, notifier.notices, 1)
+	notice := notifier.notices[0].(*clients.EmailVerificationNotice)
+	assert.Equal(t, clients.NoticeEmailVerification, notice.Type)
+	assert.Equal(t, saga.DID, notice.DID)
+	require.Len(t, store.tokens, 1)
+	assert.NotEqual(t, notice.Token, store.tokens[0].TokenHash, "only the token hash is stored")
+
+	assert.Equal(t, models.SignupCompleted, store.sagas[saga.ID].Status)
+}
+
+func TestOrchestrator_RetriesTransientFailures(t *testing.T) {
+	store := newFakeStore()
+	identity := &fakeIdentity{errs: []error{&clients.APIError{StatusCode: http.StatusServiceUnavailable, Err: clients.ErrDegraded}}}
+	orchestrator, _, _ := newTestOrchestrator(store, identity, PolicyBackground)
+
+	saga, err := orchestrator.Start(context.Background(), newUser())
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupRunning, saga.Status)
+	assert.Equal(t, models.SignupStepCreateDID, saga.Step)
+	assert.Equal(t, 1, saga.Attempts)
+	assert.True(t, saga.NextAttemptAt.After(time.Now()), "expected the retry to back off")
+
+	stuck, err := orchestrator.Stuck(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Len(t, stuck, 1)
+
+	assert.Equal(t, 1, orchestrator.RunDue(context.Background()))
+	stored := store.sagas[saga.ID]
+	assert.Equal(t, models.SignupCompleted, stored.Status)
+	assert.NotEmpty(t, stored.DID)
+}
+
+func TestOrchestrator_CompensatesWhenDIDRequired(t *testing.T) {
+	store := newFakeStore()
+	identity := &fakeIdentity{errs: []error{&clients.APIError{StatusCode: http.StatusServiceUnavailable, Err: clients.ErrDegraded}}}
+	orchestrator, _, notifier := newTestOrchestrator(store, identity, PolicyRequired)
+	user := newUser()
+
+	saga, err := orchestrator.Start(context.Background(), user)
+	assert.ErrorIs(t, err, ErrDIDRequired)
+	assert.Equal(t, models.SignupCompensated, saga.Status)
+	assert.Equal(t, []uuid.UUID{user.ID}, store.deleted)
+	assert.Empty(t, notifier.notices)
+	assert.Equal(t, 1, identity.calls, "signup does not wait for a retry")
+}
+
+func TestOrchestrator_ContinuesWithoutDIDInBackground(t *testing.T) {
+	store := newFakeStore()
+	identity := &fakeIdentity{errs: []error{&clients.APIError{StatusCode: http.StatusBadRequest}}}
+	orchestrator, issuer, notifier := newTestOrchestrator(store, identity, PolicyBackground)
+
+	saga, err := orchestrator.Start(context.Background(), newUser())
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupCompleted, saga.Status)
+	assert.Empty(t, saga.DID)
+	assert.NotEmpty(t, saga.LastError, "the DID failure is kept on the saga")
+	assert.Empty(t, issuer.subjects, "no credential without a DID")
+	assert.Len(t, notifier.notices, 1)
+	assert.Empty(t, store.deleted)
+}
+
+func TestOrchestrator_FailsAndRetriesSteps(t *testing.T) {
+	store := newFakeStore()
+	orchestrator, issuer, _ := newTestOrchestrator(store, &fakeIdentity{}, PolicyBackground)
+	issuer.err = &clients.APIError{StatusCode: http.StatusUnauthorized}
+
+	saga, err := orchestrator.Start(context.Background(), newUser())
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupFailed, saga.Status)
+	assert.Equal(t, models.SignupStepIssueCredential, saga.Step)
+	assert.Empty(t, store.deleted, "only DID failures are compensated")
+
+	_, err = orchestrator.Retry(context.Background(), uuid.New())
+	assert.Error(t, err)
+
+	issuer.err = nil
+	retried, err := orchestrator.Retry(context.Background(), saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupCompleted, retried.Status)
+	assert.Equal(t, "credential-1", retried.CredentialID)
+
+	_, err = orchestrator.Retry(context.Background(), saga.ID)
+	assert.ErrorIs(t, err, ErrNotRetryable)
+}
+
+func TestOrchestrator_GivesUpAfterMaxAttempts(t *testing.T) {
+	store := newFakeStore()
+	degraded := &clients.APIError{StatusCode: http.StatusServiceUnavailable, Err: clients.ErrDegraded}
+	identity := &fakeIdentity{errs: []error{degraded, degraded, degraded}}
+	orchestrator, _, _ := newTestOrchestrator(store, identity, PolicyBackground)
+
+	saga, err := orchestrator.Start(context.Background(), newUser())
+	require.NoError(t, err)
+	orchestrator.RunDue(context.Background())
+	orchestrator.RunDue(context.Background())
+
+	stored := store.sagas[saga.ID]
+	assert.Equal(t, models.SignupCompleted, stored.Status)
+	assert.Empty(t, stored.DID)
+	assert.Equal(t, 3, identity.calls)
+	assert.Empty(t, store.deleted)
+}
+
+func TestOrchestrator_SkipsDID(t *testing.T) {
+	store := newFakeStore()
+	identity := &fakeIdentity{}
+	orchestrator, issuer, notifier := newTestOrchestrator(store, identity, PolicySkip)
+
+	saga, err := orchestrator.Start(context.Background(), newUser())
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupCompleted, saga.Status)
+	assert.Empty(t, saga.DID)
+	assert.Zero(t, identity.calls)
+	assert.Empty(t, issuer.subjects)
+	assert.Len(t, notifier.notices, 1)
+	assert.False(t, orchestrator.RequiresDID())
+}
+
+func TestOrchestrator_EnsureDID(t *testing.T) {
+	store := newFakeStore()
+	identity := &fakeIdentity{errs: []error{&clients.APIError{StatusCode: http.StatusServiceUnavailable, Err: clients.ErrDegraded}}}
+	orchestrator, _, _ := newTestOrchestrator(store, identity, PolicyRequired)
+	user := newUser()
+
+	assert.ErrorIs(t, orchestrator.EnsureDID(context.Background(), user), ErrDIDRequired)
+	assert.Empty(t, user.DID)
+
+	require.NoError(t, orchestrator.EnsureDID(context.Background(), user))
+	assert.Equal(t, "did:example:user:"+user.ID.String(), user.DID)
+	assert.Equal(t, user.DID, store.dids[user.ID])
+
+	require.NoError(t, orchestrator.EnsureDID(context.Background(), user))
+	assert.Equal(t, 2, identity.calls, "users with a DID are not looked up again")
+
+	background, _, _ := newTestOrchestrator(store, &fakeIdentity{}, PolicyBackground)
+	require.NoError(t, background.EnsureDID(context.Background(), newUser()))
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyBackground, policy)
+
+	policy, err = ParsePolicy("required")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyRequired, policy)
+
+	_, err = ParsePolicy("sometimes")
+	assert.Error(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, backoff(1))
+	assert.Equal(t, 4*retryBaseDelay, backoff(3))
+	assert.Equal(t, retryMaxDelay, backoff(20))
+}
+
+func TestOrchestrator_OffersDIDToWallet(t *testing.T) {
+	store := newFakeStore()
+	orchestrator, _, _ := newTestOrchestrator(store, &fakeIdentity{}, PolicyBackground)
+	notActive := &clients.APIError{StatusCode: http.StatusConflict, Err: clients.ErrDIDNotActive}
+	walletOffers := &fakeWalletOffers{errs: []error{notActive}, offers: map[string]*clients.WalletOffer{}}
+	walletNotifier := &fakeNotifier{}
+	orchestrator.SetWalletOffers(walletOffers, walletNotifier)
+	user := newUser()
+
+	_, err := orchestrator.WalletOffer(context.Background(), user.ID)
+	assert.Error(t, err, "no saga yet")
+
+	saga, err := orchestrator.Start(context.Background(), user)
+	require.NoError(t, err)
+	assert.Equal(t, models.SignupRunning, saga.Status, "the offer waits for the DID to be active")
+	assert.Equal(t, models.SignupStepCreateWalletOffer, saga.Step)
+	assert.Empty(t, walletNotifier.notices)
+
+	_, err = orchestrator.WalletOffer(context.Background(), user.ID)
+	assert.ErrorIs(t, err, ErrNoWalletOffer)
+
+	orchestrator.RunDue(context.Background())
+	stored := store.sagas[saga.ID]
+	assert.Equal(t, models.SignupCompleted, stored.Status)
+	require.NotEmpty(t, stored.WalletOfferID)
+	assert.Equal(t, "pending", stored.WalletOfferStatus)
+	assert.Equal(t, []string{"credential-1"}, walletOffers.offers[stored.WalletOfferID].CredentialIDs)
+
+	require.Len(t, walletNotifier.notices, 1)
+	notice := walletNotifier.notices[0].(*clients.WalletOnboardingNotice)
+	assert.Equal(t, clients.NoticeWalletOnboarding, notice.Type)
+	assert.Equal(t, stored.DID, notice.DID)
+	assert.NotEmpty(t, notice.ClaimURI)
+
+	walletOffers.offers[stored.WalletOfferID].Status = "claimed"
+	offer, err := orchestrator.WalletOffer(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "claimed", offer.Status)
+	assert.Equal(t, "claimed", store.sagas[saga.ID].WalletOfferStatus, "the claim is tracked on the saga")
+}
@@ -28,6 +28,8 @@ type Server struct {
 	lifecycle    *lifecycle.Manager
 	grpcListener net.Listener
 	restListener net.Listener
+	// cancelBackground stops the background tasks, such as signup saga retries
+	cancelBackground context.CancelFunc
 }
 
 // NewServer initializes both gRPC and REST servers with their dependencies
@@ -90,6 +92,11 @@ func NewServer(ctx context.Context) (*Server, error) {
 
 	// Create REST gateway
 	restGateway := http.NewRESTGateway(&deps.TransportConfig.Gateway, logger)
+	restGateway.AddRoutes(http.NewOAuthHandler(svc, logger).RegisterRoutes)
+	restGateway.AddRoutes(http.NewExportHandler(svc, logger).RegisterRoutes)
+	restGateway.AddRoutes(http.NewPasswordHandler(svc, logger).RegisterRoutes)
+	restGateway.AddRoutes(http.NewDeviceHandler(svc, logger).RegisterRoutes)
+	restGateway.AddRoutes(http.NewSignupHandler(svc, logger).RegisterRoutes)
 	// In Docker, both gRPC and REST services run in the same container
 	// gRPC service runs on AuthServicePort, REST gateway connects to localhost:AuthServicePort
 	grpcAddr := "localhost:" + cfg.AuthServicePort
@@ -172,11 +179,14 @@ func registerServices(grpcServer *grpc.Server, deps *Dependencies) {
 
 // Start runs both gRPC and REST servers
 func (s *Server) Start(ctx context.Context) error {
+	s.startBackground()
 	return s.lifecycle.Start(ctx)
 }
 
 // Shutdown gracefully shuts down both servers
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopBackground()
+
 	// Shutdown lifecycle manager
 	if err := s.lifecycle.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown lifecycle manager: %w", err)
@@ -192,5 +202,27 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // Run starts both servers and handles graceful shutdown
 func (s *Server) Run(ctx context.Context) error {
+	s.startBackground()
+	defer s.stopBackground()
 	return s.lifecycle.Run(ctx)
 }
+
+// startBackground starts the background tasks, which run until the server shuts down
+// rather than for the initialization context
+func (s *Server) startBackground() {
+	if s.cancelBackground != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelBackground = cancel
+
+	interval := time.Duration(s.deps.Config.SignupSagaInterval) * time.Second
+	go s.deps.Services.Signup.Run(ctx, interval)
+}
+
+// stopBackground stops the background tasks started by startBackground
+func (s *Server) stopBackground() {
+	if s.cancelBackground != nil {
+		s.cancelBackground()
+	}
+}
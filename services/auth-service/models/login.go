@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt is a recorded sign-in attempt
+type LoginAttempt struct {
+	ID int64 `json:"id" db:"id"`
+	// UserID is nil for attempts on unknown emails
+	UserID    *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Email     string     `json:"email" db:"email"`
+	IPAddress string     `json:"ip_address" db:"ip_address"`
+	UserAgent string     `json:"user_agent" db:"user_agent"`
+	// Latitude and Longitude locate the IP address when a geolocation service is
+	// configured
+	Latitude   *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude  *float64  `json:"longitude,omitempty" db:"longitude"`
+	Success    bool      `json:"success" db:"success"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// Located reports whether the attempt's IP address was located
+func (a *LoginAttempt) Located() bool {
+	return a.Latitude != nil && a.Longitude != nil
+}
+
+// LoginAnomaly is a sign of account takeover raised by an anomaly signal
+type LoginAnomaly struct {
+	Signal string `json:"signal"`
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient represents a third-party application registered for wallet access, or a
+// service client calling the DID Manager on behalf of one of its tenants
+type OAuthClient struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	ClientID         string    `json:"client_id" db:"client_id"`
+	ClientSecretHash string    `json:"-" db:"client_secret_hash"`
+	Name             string    `json:"name" db:"name"`
+	// RedirectURIs and AllowedScopes are stored space-separated
+	RedirectURIs  string `json:"redirect_uris" db:"redirect_uris"`
+	AllowedScopes string `json:"allowed_scopes" db:"allowed_scopes"`
+	// OwnerID is the user who registered a wallet client; nil for service clients
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+	// Tenant is the DID Manager tenant (API key ID) a service client acts as; empty for
+	// wallet clients
+	Tenant    string    `json:"tenant,omitempty" db:"tenant"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsService reports whether the client is a service client, which obtains DID Manager
+// operation scopes with the client credentials grant
+func (c *OAuthClient) IsService() bool {
+	return c.Tenant != ""
+}
+
+// OAuthConsent records the scopes a user has granted to a client
+type OAuthConsent struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	ClientID  string    `json:"client_id" db:"client_id"`
+	Scopes    string    `json:"scopes" db:"scopes"`
+	GrantedAt time.Time `json:"granted_at" db:"granted_at"`
+}
+
+// OAuthAuthorizationCode is a single-use code issued after consent
+type OAuthAuthorizationCode struct {
+	CodeHash    string    `json:"-" db:"code_hash"`
+	ClientID    string    `json:"client_id" db:"client_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	RedirectURI string    `json:"redirect_uri" db:"redirect_uri"`
+	Scopes      string    `json:"scopes" db:"scopes"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	Used        bool      `json:"used" db:"used"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
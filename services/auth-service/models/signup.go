@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignupStep is a step of the signup saga. The user is created before the saga starts,
+// so a saga begins at SignupStepCreateDID.
+type SignupStep string
+
+const (
+	SignupStepCreateDID             SignupStep = "create_did"
+	SignupStepIssueCredential       SignupStep = "issue_credential"
+	SignupStepSendVerificationEmail SignupStep = "send_verification_email"
+	SignupStepCreateWalletOffer     SignupStep = "create_wallet_offer"
+	SignupStepDone                  SignupStep = "done"
+)
+
+// SignupStatus is the state of a signup saga
+type SignupStatus string
+
+const (
+	// SignupRunning sagas have steps left, retried until they succeed or run out of attempts
+	SignupRunning SignupStatus = "running"
+	// SignupCompleted sagas ran every step
+	SignupCompleted SignupStatus = "completed"
+	// SignupCompensated sagas were undone: the user was deleted because no DID could be
+	// created for them and the signup policy requires one
+	SignupCompensated SignupStatus = "compensated"
+	// SignupFailed sagas ran out of attempts on a step and wait for an administrator
+	SignupFailed SignupStatus = "failed"
+)
+
+// SignupSaga is the persisted state of a user's signup across the auth service, the
+// DID Manager and the mail relay
+type SignupSaga struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	// UserID is kept after a compensated saga deleted the user
+	UserID uuid.UUID    `json:"user_id" db:"user_id"`
+	Email  string       `json:"email" db:"email"`
+	Name   string       `json:"name" db:"name"`
+	Locale string       `json:"locale,omitempty" db:"locale"`
+	Step   SignupStep   `json:"step" db:"step"`
+	Status SignupStatus `json:"status" db:"status"`
+	// Attempts counts the failed attempts of the current step
+	Attempts  int    `json:"attempts" db:"attempts"`
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+	// DID, UserHash and CredentialID are the results of the steps run so far
+	DID          string `json:"did,omitempty" db:"did"`
+	UserHash     string `json:"user_hash,omitempty" db:"user_hash"`
+	CredentialID string `json:"credential_id,omitempty" db:"credential_id"`
+	// WalletOfferID is the DID Manager offer of the DID to the user's wallet, and
+	// WalletOfferStatus its last known status: pending, claimed or expired
+	WalletOfferID     string    `json:"wallet_offer_id,omitempty" db:"wallet_offer_id"`
+	WalletOfferStatus string    `json:"wallet_offer_status,omitempty" db:"wallet_offer_status"`
+	NextAttemptAt     time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EmailVerificationToken is a single-use token proving a user received mail at their
+// email address
+type EmailVerificationToken struct {
+	TokenHash string    `json:"-" db:"token_hash"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	Used      bool      `json:"used" db:"used"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// EmailVerificationRequest confirms a user's email address with a verification token
+type EmailVerificationRequest struct {
+	Token string `json:"token"`
+}
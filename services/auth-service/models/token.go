@@ -15,4 +15,39 @@ type UserToken struct {
 	RefreshExpiresAt time.Time `db:"refresh_expires_at" json:"refresh_expires_at"`
 	IsRevoked        bool      `db:"is_revoked" json:"is_revoked"`
 	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	// DeviceKeyID is the device key on the user's DID the session is bound to; a bound
+	// session is refreshed only with a signature by that key
+	DeviceKeyID *uuid.UUID `db:"device_key_id" json:"device_key_id,omitempty"`
+}
+
+// DeviceBindRequest binds the current session to a device key, registered on the
+// user's DID with the device's proof of possession
+type DeviceBindRequest struct {
+	Name         string `json:"name"`
+	PublicKey    string `json:"public_key"`
+	KeyAlgorithm string `json:"key_algorithm"`
+	// Signature is the device's hex signature over "device-key:<did>:<public_key>"
+	Signature string `json:"signature"`
+	// Attestation optionally vouches that a genuine app instance holds the key
+	Attestation *DeviceAttestation `json:"attestation,omitempty"`
+}
+
+// DeviceAttestation is a platform attestation of the app instance binding a device,
+// bound to the SHA-256 hash of "device-key:<did>:<public_key>"
+type DeviceAttestation struct {
+	// Platform is play_integrity or app_attest
+	Platform string `json:"platform"`
+	// Token is the Play Integrity token, or the base64 App Attest attestation object
+	Token string `json:"token"`
+	// KeyID is the base64 App Attest key ID
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// DeviceProof is a device's signature over a refresh of its session, made with the
+// device key the session is bound to
+type DeviceProof struct {
+	// Timestamp is the Unix time at which the device signed
+	Timestamp string
+	// Signature is the hex signature over "device-refresh:<refresh_token>:<timestamp>"
+	Signature string
 }
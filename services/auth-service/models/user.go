@@ -14,6 +14,7 @@ type User struct {
 	Password  string    `json:"-" db:"password"`
 	DID       string    `json:"did,omitempty" db:"did"`
 	UserHash  string    `json:"user_hash,omitempty" db:"user_hash"`
+	Locale    string    `json:"locale,omitempty" db:"locale"` // of notices; empty for the default
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -22,6 +23,9 @@ type User struct {
 type Credentials struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// IPAddress and UserAgent describe the client signing in, for anomaly detection
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // UserCreateRequest represents user registration request
@@ -29,4 +33,7 @@ type UserCreateRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	// Locale is the user's preferred locale, or the Accept-Language header of the
+	// signup request
+	Locale string `json:"locale"`
 }
@@ -12,7 +12,7 @@ import (
 
 	"auth-service/models"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // TokenConfig holds JWT configuration
@@ -77,6 +77,38 @@ func GenerateRefreshToken(user *models.User, secret string) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
+// GenerateWalletAccessToken creates a scoped access token for a third-party client acting on
+// behalf of a user. Wallet tokens are signed with their own secret, shared with the DID
+// manager, so user session tokens are never accepted by the wallet API and vice versa.
+func GenerateWalletAccessToken(userID, clientID, scope string, ttl time.Duration, secret string) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":   userID,
+		"client_id": clientID,
+		"scope":     scope,
+		"exp":       time.Now().Add(ttl).Unix(),
+		"iat":       time.Now().Unix(),
+		"type":      "wallet_access",
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// GenerateServiceAccessToken creates a token for a service client calling the DID Manager
+// as tenant, limited to the DID Manager operation scopes in scope. Service tokens are
+// signed with their own secret, shared with the DID Manager.
+func GenerateServiceAccessToken(clientID, tenant, scope string, ttl time.Duration, secret string) (string, error) {
+	claims := jwt.MapClaims{
+		"client_id": clientID,
+		"tenant":    tenant,
+		"scope":     scope,
+		"exp":       time.Now().Add(ttl).Unix(),
+		"iat":       time.Now().Unix(),
+		"type":      "service_access",
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString, secret string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
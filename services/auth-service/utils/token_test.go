@@ -347,6 +347,41 @@ func TestTokenClaims(t *testing.T) {
 	assert.NotNil(t, claims["exp"]) // expiration
 }
 
+func TestWalletAccessTokenClaims(t *testing.T) {
+	secret := "wallet-secret"
+	scope := "wallet:credentials:read wallet:dids:read"
+
+	token, err := GenerateWalletAccessToken("user123", "wc_client", scope, 5*time.Minute, secret)
+	assert.NoError(t, err)
+
+	claims, err := ValidateToken(token, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", claims["user_id"])
+	assert.Equal(t, "wc_client", claims["client_id"])
+	assert.Equal(t, scope, claims["scope"])
+	assert.Equal(t, "wallet_access", claims["type"])
+
+	// Wallet tokens must not validate against another secret
+	_, err = ValidateToken(token, "access-secret")
+	assert.Error(t, err)
+}
+
+func TestServiceAccessTokenClaims(t *testing.T) {
+	secret := "service-secret"
+	scope := "did:read vc:verify"
+
+	token, err := GenerateServiceAccessToken("sc_client", "3f1c0d5e-8a4b-4c2d-9e6f-7a8b9c0d1e2f", scope, 5*time.Minute, secret)
+	assert.NoError(t, err)
+
+	claims, err := ValidateToken(token, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "sc_client", claims["client_id"])
+	assert.Equal(t, "3f1c0d5e-8a4b-4c2d-9e6f-7a8b9c0d1e2f", claims["tenant"])
+	assert.Equal(t, scope, claims["scope"])
+	assert.Equal(t, "service_access", claims["type"])
+	assert.NotContains(t, claims, "user_id")
+}
+
 // Benchmark tests for performance
 func BenchmarkGenerateAccessTokenSimple(b *testing.B) {
 	userID := "user123"
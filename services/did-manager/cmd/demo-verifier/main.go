@@ -0,0 +1,275 @@
+// Command demo-verifier is a mock relying party for demos and integration tests of the
+// verifier flows. It asks a wallet for a presentation through a verification session of
+// the DID Manager, shows the QR code and deep link the wallet joins with, and displays
+// the verified result once the wallet responds.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"did-manager/pkg/client"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/skip2/go-qrcode"
+)
+
+// maxSessions bounds the QR payloads kept for started sessions; they are dropped when full
+const maxSessions = 1000
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	apiKey := os.Getenv("DEMO_VERIFIER_API_KEY")
+	if apiKey == "" {
+		logger.Fatal().Msg("DEMO_VERIFIER_API_KEY is required to create verification sessions")
+	}
+	serviceURL := os.Getenv("DID_MANAGER_URL")
+	if serviceURL == "" {
+		serviceURL = "http://localhost:8081"
+	}
+	domain := os.Getenv("DEMO_VERIFIER_DOMAIN")
+	if domain == "" {
+		domain = "demo-verifier.example.com"
+	}
+
+	demo := &demoVerifier{
+		didManager: client.New(serviceURL, client.WithAPIKey(apiKey)),
+		domain:     domain,
+		payloads:   make(map[string]string),
+		logger:     logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", demo.index)
+	mux.HandleFunc("POST /sessions", demo.startSession)
+	mux.HandleFunc("GET /sessions/{id}", demo.showSession)
+	mux.HandleFunc("GET /sessions/{id}/qr.png", demo.qrCode)
+	mux.HandleFunc("GET /sessions/{id}/status", demo.sessionStatus)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8090"
+	}
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.Info().Msgf("Demo verifier listening on port %s, verifying with %s", port, serviceURL)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal().Err(err).Msg("HTTP server failed")
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msg("Demo verifier failed to stop cleanly")
+	}
+}
+
+// demoVerifier serves the relying party pages
+type demoVerifier struct {
+	didManager *client.Client
+	domain     string
+	logger     zerolog.Logger
+
+	// payloads holds the wallet deep link of each started session, which the service
+	// only returns when the session starts
+	mu       sync.Mutex
+	payloads map[string]string
+}
+
+// index offers to start a verification
+func (d *demoVerifier) index(w http.ResponseWriter, r *http.Request) {
+	render(w, indexPage, map[string]any{"Domain": d.domain})
+}
+
+// startSession creates a verification session for the requested credential types and
+// shows it; without types the wallet only proves control of its DID
+func (d *demoVerifier) startSession(w http.ResponseWriter, r *http.Request) {
+	var credentialTypes []string
+	for _, credentialType := range strings.Split(r.FormValue("credential_types"), ",") {
+		if credentialType = strings.TrimSpace(credentialType); credentialType != "" {
+			credentialTypes = append(credentialTypes, credentialType)
+		}
+	}
+
+	start, err := d.didManager.CreateVerificationSession(r.Context(), d.domain, credentialTypes)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("Failed to create verification session")
+		http.Error(w, "Failed to create verification session: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	d.mu.Lock()
+	if len(d.payloads) >= maxSessions {
+		d.payloads = make(map[string]string)
+	}
+	d.payloads[start.Session.ID] = start.QRPayload
+	d.mu.Unlock()
+
+	d.logger.Info().Str("session", start.Session.ID).Strs("credential_types", credentialTypes).Msg("Verification session started")
+	http.Redirect(w, r, "/sessions/"+start.Session.ID, http.StatusSeeOther)
+}
+
+// showSession shows the QR code and deep link of a session and follows its status
+func (d *demoVerifier) showSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	session, err := d.didManager.GetVerificationSession(r.Context(), id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	d.mu.Lock()
+	payload := d.payloads[id]
+	d.mu.Unlock()
+
+	render(w, sessionPage, map[string]any{
+		"Session":  session,
+		"DeepLink": template.URL(payload),
+	})
+}
+
+// qrCode renders the deep link of a session started here as a QR code
+func (d *demoVerifier) qrCode(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	payload, ok := d.payloads[r.PathValue("id")]
+	d.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, 320)
+	if err != nil {
+		http.Error(w, "Failed to render QR code", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// sessionStatus returns a session as JSON, polled by the session page and usable by
+// integration tests waiting for the outcome
+func (d *demoVerifier) sessionStatus(w http.ResponseWriter, r *http.Request) {
+	session, err := d.didManager.GetVerificationSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(session)
+}
+
+// respondServiceError passes on the status of a failed DID Manager request
+func respondServiceError(w http.ResponseWriter, err error) {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		http.Error(w, "Verification session not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+// render writes an HTML page
+func render(w http.ResponseWriter, page *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		log.Printf("Failed to render page: %v", err)
+	}
+}
+
+const layout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Demo Verifier</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; color: #1f2933; }
+input[type=text] { width: 100%; padding: .5rem; box-sizing: border-box; }
+button { margin-top: 1rem; padding: .6rem 1.2rem; }
+.status { font-size: 1.4rem; font-weight: 600; }
+.verified { color: #137333; } .rejected, .expired { color: #b3261e; }
+pre { background: #f3f4f6; padding: 1rem; overflow-x: auto; font-size: .8rem; }
+</style>
+</head>
+<body>
+{{template "content" .}}
+</body>
+</html>`
+
+var indexPage = template.Must(template.Must(template.New("index").Parse(layout)).Parse(`{{define "content"}}
+<h1>Demo Verifier</h1>
+<p>Ask a wallet to sign in with its DID or present credentials to <strong>{{.Domain}}</strong>.</p>
+<form method="post" action="/sessions">
+<label for="credential_types">Credential types, comma separated; leave empty for DID authentication only</label>
+<input type="text" id="credential_types" name="credential_types" placeholder="KYCCredential, AgeOver">
+<button type="submit">Request presentation</button>
+</form>
+{{end}}`))
+
+var sessionPage = template.Must(template.Must(template.New("session").Parse(layout)).Parse(`{{define "content"}}
+<h1>Scan with your wallet</h1>
+{{with .Session.CredentialTypes}}<p>Requested credentials: {{range $i, $t := .}}{{if $i}}, {{end}}{{$t}}{{end}}</p>{{else}}<p>Requested: proof of DID control</p>{{end}}
+{{if .DeepLink}}
+<img src="/sessions/{{.Session.ID}}/qr.png" alt="Verification QR code" width="320" height="320">
+<p>On this device? <a href="{{.DeepLink}}">Open the wallet</a></p>
+{{end}}
+<p class="status" id="status">{{.Session.Status}}</p>
+<p id="holder"></p>
+<p id="message"></p>
+<pre id="presentation" hidden></pre>
+<p><a href="/">Start over</a></p>
+<script>
+const statusURL = "/sessions/{{.Session.ID}}/status";
+async function poll() {
+	const response = await fetch(statusURL);
+	if (!response.ok) { setTimeout(poll, 2000); return; }
+	const session = await response.json();
+	const status = document.getElementById("status");
+	status.textContent = session.status;
+	status.className = "status " + session.status;
+	if (session.holder_did) document.getElementById("holder").textContent = "Holder: " + session.holder_did;
+	if (session.message) document.getElementById("message").textContent = session.message;
+	if (session.presentation) {
+		const presentation = document.getElementById("presentation");
+		presentation.textContent = JSON.stringify(session.presentation, null, 2);
+		presentation.hidden = false;
+	}
+	if (!["verified", "rejected", "expired"].includes(session.status)) setTimeout(poll, 1000);
+}
+poll();
+</script>
+{{end}}`))
@@ -0,0 +1,156 @@
+// Command repair cross-checks dids, blockchain_jobs and the DID registry contract,
+// printing a JSON report of inconsistencies and, with -fix, repairing those that can
+// be repaired automatically. It exits with status 2 when inconsistencies remain.
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"did-manager/internal/repository"
+	"did-manager/internal/services"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/did"
+	"did-manager/pkg/queue"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	fix := flag.Bool("fix", false, "repair inconsistencies instead of only reporting them")
+	offline := flag.Bool("offline", false, "skip on-chain checks and only compare database records")
+	fromBlock := flag.Uint64("from-block", envUint64("REPAIR_FROM_BLOCK", 0), "first block scanned for on-chain registrations")
+	flag.Parse()
+
+	db, err := connectDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	var registry services.RegistryReader
+	if !*offline {
+		client, err := blockchain.NewEthereumClient(
+			os.Getenv("ETHEREUM_RPC_URL"),
+			os.Getenv("ETHEREUM_PRIVATE_KEY"),
+			os.Getenv("ETHEREUM_CONTRACT_ADDRESS"),
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize blockchain client (use -offline to skip on-chain checks): %v", err)
+		}
+		defer client.Close()
+		registry = client
+	}
+
+	// Repair jobs are picked up by the server's database-backed worker; publishing to
+	// NATS only speeds that up
+	jobQueue := services.OfflineQueue(nil)
+	if *fix {
+		queueClient, err := queue.NewNATSQueue(os.Getenv("NATS_URL"))
+		if err != nil {
+			log.Printf("Warning: failed to initialize NATS queue, repair jobs will only be stored: %v", err)
+			jobQueue = services.OfflineQueue(err)
+		} else if keyID := os.Getenv("QUEUE_ENCRYPTION_KEY_ID"); keyID != "" {
+			keyring, err := queue.ParseKeyring(keyID, os.Getenv("QUEUE_ENCRYPTION_KEYS"))
+			if err != nil {
+				log.Fatalf("Invalid queue encryption keys: %v", err)
+			}
+			queueClient.SetEncryption(keyring)
+		}
+		if queueClient != nil {
+			defer queueClient.Close()
+			jobQueue = queueClient
+		}
+	}
+
+	// Local DIDs are found by user hash through its peppered index
+	peppers, err := did.ParsePeppers(os.Getenv("DID_ID_PEPPERS"))
+	if err != nil {
+		log.Fatalf("Invalid DID_ID_PEPPERS: %v", err)
+	}
+	didGen, err := did.NewGeneratorWithConfig(did.GeneratorConfig{
+		Pepper:                []byte(os.Getenv("DID_ID_PEPPER")),
+		Peppers:               peppers,
+		PepperVersion:         os.Getenv("DID_ID_PEPPER_VERSION"),
+		PreviousPepperVersion: os.Getenv("DID_ID_PEPPER_PREVIOUS_VERSION"),
+	})
+	if err != nil {
+		log.Fatalf("Invalid pepper configuration: %v", err)
+	}
+
+	repairService := services.NewRepairService(
+		repository.NewDIDRepository(db),
+		repository.NewBlockchainJobRepository(db),
+		services.NewUserHashIndexService(repository.NewUserHashIndexRepository(db), didGen),
+		registry,
+		jobQueue,
+		*fromBlock,
+	)
+
+	report, err := repairService.Check(*fix)
+	if err != nil {
+		log.Fatalf("Consistency check failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if len(report.Inconsistencies) > report.Repaired {
+		os.Exit(2)
+	}
+}
+
+// connectDB establishes a connection to the PostgreSQL database
+func connectDB() (*sql.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_PORT"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_SSLMODE"),
+	)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// envUint64 reads an unsigned integer environment variable, falling back to defaultValue
+func envUint64(key string, defaultValue uint64) uint64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+
+	return parsed
+}
@@ -0,0 +1,11 @@
+package main
+
+// hookPlugins are registered with the service hooks at startup; each plugin implements
+// one or more of services.DIDCreationHook, services.DIDVerificationHook and
+// services.CredentialIssuanceHook. Deployments add custom validation without changing
+// the service by adding a file to this package that appends its plugins from init:
+//
+//	func init() {
+//		hookPlugins = append(hookPlugins, kyc.NewPlugin())
+//	}
+var hookPlugins []any
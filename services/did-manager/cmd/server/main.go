@@ -2,28 +2,62 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"text/template"
 	"time"
 
+	"did-manager/internal/domain"
 	"did-manager/internal/handler"
 	"did-manager/internal/repository"
+	"did-manager/internal/security"
 	"did-manager/internal/services"
+	"did-manager/pkg/attestation"
 	"did-manager/pkg/blockchain"
+	"did-manager/pkg/challenge"
+	"did-manager/pkg/clock"
 	"did-manager/pkg/did"
+	"did-manager/pkg/erc4337"
+	"did-manager/pkg/errreport"
+	"did-manager/pkg/lifecycle"
+	"did-manager/pkg/push"
 	"did-manager/pkg/queue"
+	"did-manager/pkg/screening"
+	"did-manager/pkg/sidetree"
+	"did-manager/pkg/siem"
+	"did-manager/pkg/socialproof"
+	"did-manager/pkg/vault"
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/apiversion"
+	"did-manager/pkg/web/compress"
+	"did-manager/pkg/web/ginweb"
+	"did-manager/pkg/web/stdweb"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/nats-io/nats.go"
 	"github.com/rs/zerolog"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -42,10 +76,44 @@ func main() {
 	defer db.Close()
 
 	// Initialize repositories
-	didRepo := repository.NewDIDRepository(db)
+	var didRepo domain.DIDRepository = repository.NewDIDRepository(db)
 	queueRepo := repository.NewBlockchainJobRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	aclRepo := repository.NewACLRepository(db)
+	credentialRepo := repository.NewCredentialRepository(db)
+	pushDeviceRepo := repository.NewPushDeviceRepository(db)
+	sessionRepo := repository.NewVerificationSessionRepository(db)
+	revocationBatchRepo := repository.NewRevocationBatchRepository(db)
+	notarizationRepo := repository.NewNotarizationRepository(db)
+	custodyTransferRepo := repository.NewCustodyTransferRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	endorsementRepo := repository.NewEndorsementRepository(db)
+	renewalPolicyRepo := repository.NewRenewalPolicyRepository(db)
+	complianceRepo := repository.NewComplianceRepository(db)
+	featureFlagRepo := repository.NewFeatureFlagRepository(db)
+	duplicateRepo := repository.NewDuplicateRepository(db)
+	userHashIndexRepo := repository.NewUserHashIndexRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
+	costRepo := repository.NewCostRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	verificationLogRepo := repository.NewVerificationLogRepository(db)
+	documentRepo := repository.NewDocumentRepository(db)
+	backupRepo := repository.NewBackupRepository(db)
+	analyticsExportRepo := repository.NewAnalyticsExportRepository(db)
+	jobArchiveRepo := repository.NewJobArchiveRepository(db)
+	identifierMigrationRepo := repository.NewIdentifierMigrationRepository(db)
+	methodPolicyRepo := repository.NewDIDMethodPolicyRepository(db)
+	verificationPolicyRepo := repository.NewVerificationPolicyRepository(db)
+	verificationNonceRepo := repository.NewVerificationNonceRepository(db)
+	stepUpChallengeRepo := repository.NewStepUpChallengeRepository(db)
+	deviceKeyRepo := repository.NewDeviceKeyRepository(db)
+	purposeKeyRepo := repository.NewPurposeKeyRepository(db)
+	sidetreeRepo := repository.NewSidetreeRepository(db)
 
-	// Initialize blockchain client
+	// Initialize blockchain client. Offline, services get a ledger that fails with
+	// ErrBlockchainUnavailable instead of a nil client.
+	var ledger services.Ledger
 	blockchainClient, err := blockchain.NewEthereumClient(
 		os.Getenv("ETHEREUM_RPC_URL"),
 		os.Getenv("ETHEREUM_PRIVATE_KEY"),
@@ -54,6 +122,28 @@ func main() {
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to initialize blockchain client, running in offline mode")
 		blockchainClient = nil
+		ledger = services.OfflineLedger(err)
+	} else {
+		ledger = blockchainClient
+		if address := os.Getenv("REVOCATION_REGISTRY_ADDRESS"); address != "" {
+			blockchainClient.SetRevocationRegistry(address)
+		}
+		if address := os.Getenv("SIDETREE_ANCHOR_ADDRESS"); address != "" {
+			blockchainClient.SetSidetreeAnchor(address)
+		}
+		if err := configureGasPrice(blockchainClient.Fees()); err != nil {
+			logger.Fatal().Err(err).Msg("Invalid gas price configuration")
+		}
+		// A chain or contract that does not match the pinned configuration gets no
+		// transactions; the service runs offline until an operator steps in
+		if path := os.Getenv("BLOCKCHAIN_TRUST_CONFIG"); path != "" {
+			if err := verifyBlockchainTrust(blockchainClient, path); err != nil {
+				logger.Error().Str("alert", "critical").Err(err).Msg("Blockchain does not match the trust configuration, refusing to submit transactions")
+				blockchainClient.Close()
+				blockchainClient = nil
+				ledger = services.OfflineLedger(err)
+			}
+		}
 	}
 	defer func() {
 		if blockchainClient != nil {
@@ -61,11 +151,89 @@ func main() {
 		}
 	}()
 
-	// Initialize NATS queue
-	queueClient, err := queue.NewNATSQueue(os.Getenv("NATS_URL"))
+	// With the hedera anchor backend, DID operations and notarizations are anchored as
+	// Hedera Consensus Service messages instead; the Ethereum client still serves the
+	// other contracts. anchoring tells whether the job worker has a chain to submit to.
+	anchoring := blockchainClient != nil
+	anchorAddress := os.Getenv("ETHEREUM_CONTRACT_ADDRESS")
+	if os.Getenv("ANCHOR_BACKEND") == "hedera" {
+		hederaClient, err := blockchain.NewHederaClient(hederaConfig())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to initialize Hedera client, running in offline mode")
+			ledger = services.OfflineLedger(err)
+		} else {
+			ledger = hederaClient
+			anchorAddress = hederaClient.TopicID()
+		}
+		anchoring = err == nil
+	}
+
+	// With a shadow anchor backend, every DID operation and notarization is replayed on
+	// it in parallel, without affecting results, and its outcomes compared with the
+	// primary's, to evaluate a migration to it before cutover
+	var shadowChain *blockchain.ShadowChain
+	if backend := os.Getenv("ANCHOR_SHADOW_BACKEND"); backend != "" {
+		if err := validateShadowBackend(backend); err != nil {
+			logger.Fatal().Err(err).Msg("Invalid ANCHOR_SHADOW_BACKEND")
+		}
+		shadow, err := loadShadowLedger(backend, blockchainClient)
+		switch {
+		case err != nil:
+			logger.Warn().Err(err).Msg("Failed to initialize the shadow anchor backend, not shadowing anchoring")
+		case !anchoring:
+			logger.Warn().Msg("Anchoring is offline, not shadowing it")
+		default:
+			shadowChain = blockchain.NewShadowChain(ledger, shadow, blockchain.ShadowConfig{
+				Timeout:     getEnvDuration("ANCHOR_SHADOW_TIMEOUT", 5*time.Minute),
+				MaxInFlight: getEnvInt("ANCHOR_SHADOW_MAX_IN_FLIGHT", 16),
+			})
+			ledger = shadowChain
+			logger.Info().Str("backend", backend).Msg("Shadow anchoring enabled")
+		}
+	}
+
+	// Active-passive replication: a standby follows the primary's database through
+	// logical replication and mirrors its queue, serving reads until promoted
+	replicationRole := domain.ReplicationRole(os.Getenv("REPLICATION_ROLE"))
+	if replicationRole == "" {
+		replicationRole = domain.ReplicationRolePrimary
+	}
+	if replicationRole != domain.ReplicationRolePrimary && replicationRole != domain.ReplicationRoleStandby {
+		logger.Fatal().Str("role", string(replicationRole)).Msg("REPLICATION_ROLE must be primary or standby")
+	}
+	var queueMirrorDomain string
+	if replicationRole == domain.ReplicationRoleStandby {
+		queueMirrorDomain = os.Getenv("NATS_MIRROR_DOMAIN")
+	}
+
+	// Initialize NATS queue. Without it, jobs are only picked up from the database.
+	var queueClient *queue.NATSQueue
+	var jobQueue services.JobPublisher
+	if queueMirrorDomain != "" {
+		queueClient, err = queue.NewNATSStandby(os.Getenv("NATS_URL"), queueMirrorDomain)
+	} else {
+		queueClient, err = queue.NewNATSQueue(os.Getenv("NATS_URL"))
+	}
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to initialize NATS queue, running in local mode")
 		queueClient = nil
+		jobQueue = services.OfflineQueue(err)
+	} else {
+		jobQueue = queueClient
+		encoding, err := queue.ParseEncoding(os.Getenv("QUEUE_ENCODING"))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid QUEUE_ENCODING")
+		}
+		queueClient.SetEncoding(encoding)
+
+		if keyID := os.Getenv("QUEUE_ENCRYPTION_KEY_ID"); keyID != "" {
+			keyring, err := queue.ParseKeyring(keyID, os.Getenv("QUEUE_ENCRYPTION_KEYS"))
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Invalid queue encryption keys")
+			}
+			queueClient.SetEncryption(keyring)
+			logger.Info().Str("key_id", keyID).Msg("Queue payload encryption enabled")
+		}
 	}
 	defer func() {
 		if queueClient != nil {
@@ -73,48 +241,964 @@ func main() {
 		}
 	}()
 
-	// Initialize DID generator
-	didGen := did.NewGenerator()
+	// Avoid wrapping a nil client in the interface
+	var queuePromoter services.QueuePromoter
+	if queueClient != nil {
+		queuePromoter = queueClient
+	}
+	replicationService := services.NewReplicationService(
+		repository.NewReplicationRepository(db),
+		queuePromoter,
+		replicationRole,
+		os.Getenv("REPLICATION_REGION"),
+		os.Getenv("REPLICATION_SUBSCRIPTION"),
+		queueMirrorDomain,
+	)
+	if replicationService.ReadOnly() {
+		logger.Warn().Msg("Running as a read-only standby; writes and background workers resume once promoted")
+	}
+
+	// Configured feature flag defaults; FEATURE_FLAGS takes precedence over the
+	// older dedicated variables
+	featureDefaults := map[domain.FeatureFlag]bool{
+		domain.FeaturePQSignatures:        os.Getenv("ENABLE_EXPERIMENTAL_PQ_SIGNATURES") == "true",
+		domain.FeatureRevocationAnchoring: true,
+		domain.FeatureAnonymousDIDs:       false,
+	}
+	configuredFlags, err := services.ParseFeatureFlags(os.Getenv("FEATURE_FLAGS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid FEATURE_FLAGS")
+	}
+	for flag, enabled := range configuredFlags {
+		featureDefaults[flag] = enabled
+	}
+
+	// Panics recovered in handlers and workers are logged with their stack, and sent to
+	// Sentry, or a tracker compatible with it, when SENTRY_DSN is set
+	errorReporter := loadErrorReporter(logger)
+
+	// Audit records, security-relevant requests and domain events are streamed to the
+	// SIEM when a sink is configured
+	siemShipper := loadSIEMShipper(logger)
+	if siemShipper != nil {
+		log.SetOutput(siem.AuditWriter(siemShipper, os.Stderr))
+	}
+
+	// Expirations, such as those of credentials and status tokens, retries and periodic
+	// jobs read this clock; CLOCK_OFFSET shifts it to simulate a later (or, when
+	// negative, earlier) time
+	clockOffset := getEnvDuration("CLOCK_OFFSET", 0)
+	if clockOffset != 0 {
+		logger.Warn().Str("offset", clockOffset.String()).Msg("Clock shifted by CLOCK_OFFSET; do not run this in production")
+	}
+	appClock := clock.Offset(clock.System, clockOffset)
+	queueRepo.SetClock(appClock)
+	webhookRepo.SetClock(appClock)
+
+	// Initialize DID generator, peppering user hashes when a pepper is configured.
+	// Versioned peppers let the pepper be rotated without breaking existing records.
+	peppers, err := did.ParsePeppers(os.Getenv("DID_ID_PEPPERS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID_ID_PEPPERS")
+	}
+	didGen, err := did.NewGeneratorWithConfig(did.GeneratorConfig{
+		Pepper:                []byte(os.Getenv("DID_ID_PEPPER")),
+		Peppers:               peppers,
+		PepperVersion:         os.Getenv("DID_ID_PEPPER_VERSION"),
+		PreviousPepperVersion: os.Getenv("DID_ID_PEPPER_PREVIOUS_VERSION"),
+		HashScheme:            os.Getenv("USER_HASH_SCHEME"),
+		SignatureSuite:        os.Getenv("DID_SIGNATURE_SUITE"),
+		// Experimental post-quantum suites stay verifiable but only issue new keys behind this flag
+		ExperimentalSuites: featureDefaults[domain.FeaturePQSignatures],
+		Clock:              appClock,
+		Argon2Params: did.Argon2Params{
+			Time:      uint32(getEnvInt("ARGON2_TIME", int(did.DefaultArgon2Params.Time))),
+			MemoryKiB: uint32(getEnvInt("ARGON2_MEMORY_KIB", int(did.DefaultArgon2Params.MemoryKiB))),
+			Threads:   uint8(getEnvInt("ARGON2_THREADS", int(did.DefaultArgon2Params.Threads))),
+			KeyLength: did.DefaultArgon2Params.KeyLength,
+		},
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID generator configuration")
+	}
+
+	// Feature flags follow database overrides at runtime, without a redeploy
+	featureService := services.NewFeatureService(
+		featureFlagRepo,
+		featureDefaults,
+		didGen.Registry(),
+		getEnvDuration("FEATURE_FLAG_REFRESH_INTERVAL", 30*time.Second),
+	)
+	if err := featureService.Refresh(); err != nil {
+		logger.Warn().Err(err).Msg("Failed to load feature overrides, using configured defaults")
+	}
+
+	// Assign the queue only when connected so a nil client is not wrapped in a non-nil interface
+	var events services.EventPublisher
+	var changeReader services.ChangeReader
+	if queueClient != nil {
+		events = queueClient
+		changeReader = queueClient
+	}
+	// Publish every change to a DID record to the event stream, feeding the change feed
+	didRepo = services.PublishDIDChanges(didRepo, events)
 
 	// Initialize services
-	didService := services.NewDIDService(didRepo, queueRepo, didGen, blockchainClient, queueClient)
+	didService := services.NewDIDService(didRepo, queueRepo, didGen, ledger, jobQueue, notarizationRepo, featureService)
+	didService.SetClock(appClock)
+	// Simulate every blockchain job instead of submitting it, e.g. in staging without a funded account
+	didService.SetDryRun(os.Getenv("BLOCKCHAIN_DRY_RUN") == "true")
+	didService.SetErrorReporter(errorReporter)
+	// Claims checks hash with Argon2id, so each DID's are limited against guessing
+	didService.SetClaimsAttemptLimit(getEnvInt("CLAIMS_ATTEMPT_RATE_LIMIT", 5), getEnvInt("CLAIMS_ATTEMPT_RATE_BURST", 5))
+	// Operators pause job submission on every instance, e.g. during a gas price spike,
+	// while DIDs keep being accepted into the queue
+	anchoringPause := services.NewAnchoringPauseService(
+		repository.NewAnchoringPauseRepository(db),
+		queueRepo,
+		getEnvDuration("ANCHORING_PAUSE_REFRESH_INTERVAL", 10*time.Second),
+	)
+	didService.SetAnchoringPause(anchoringPause)
+	// New DIDs come with a consistency token, so verifying them right away through a
+	// read replica, the verifier's cache or a standby does not miss them
+	consistencyService := services.NewConsistencyService(
+		repository.NewConsistencyRepository(db, os.Getenv("REPLICATION_SUBSCRIPTION")),
+		didRepo,
+		getEnvDuration("CONSISTENCY_MAX_WAIT", 2*time.Second),
+	)
+	didService.SetConsistencyTokens(consistencyService)
+	// How verification answers when the chain is unreachable, unless a tenant or
+	// endpoint policy says otherwise
+	verificationFallback, err := services.ParseVerificationFallback(os.Getenv("VERIFICATION_FALLBACK"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid VERIFICATION_FALLBACK")
+	}
+	verificationPolicyService := services.NewVerificationPolicyService(verificationPolicyRepo, verificationFallback)
+	didService.SetVerificationPolicies(verificationPolicyService)
+	// Index holder email addresses so relying parties can look DIDs up by email
+	emailIndex := loadEmailIndex(logger)
+	if emailIndex != nil {
+		didService.SetEmailIndex(emailIndex)
+	}
+	// Lookups by user hash go through its peppered index, re-hashed after a pepper rotation
+	userHashIndexService := services.NewUserHashIndexService(userHashIndexRepo, didGen)
+	didService.SetUserHashIndex(userHashIndexService)
+	identifierService := services.NewIdentifierService(identifierMigrationRepo, didRepo, queueRepo, didGen.Registry(), jobQueue)
+	accessService := services.NewAccessService(apiKeyRepo, aclRepo, didRepo, didGen.Registry())
+	accessService.SetClock(appClock)
+	// Messages shown to end users follow Accept-Language, then the tenant's default locale
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+	localeService, err := services.NewLocaleService(apiKeyRepo, defaultLocale)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DEFAULT_LOCALE")
+	}
+	emailLookupService := services.NewEmailLookupService(didRepo, accessService, emailIndex)
+	resolverService := services.NewResolverService(accessService, didGen.Registry())
+	// Documents serve keys as publicKeyMultibase, publicKeyJwk or legacy publicKeyHex
+	keyFormat, err := did.ParseKeyFormat(os.Getenv("DID_DOCUMENT_KEY_FORMAT"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID_DOCUMENT_KEY_FORMAT")
+	}
+	resolverService.SetKeyFormat(keyFormat)
+	changeService := services.NewChangeService(changeReader, resolverService)
+	credentialService := services.NewCredentialService(credentialRepo, didRepo, didGen.Registry(), events)
+	credentialService.SetClock(appClock)
+	// Device subkeys sign in as their DID and are listed in its document
+	deviceKeyService := services.NewDeviceKeyService(deviceKeyRepo, didRepo, didGen.Registry(), events)
+	accessService.SetDeviceKeys(deviceKeyService)
+	resolverService.SetDeviceKeys(deviceKeyService)
+	// Service clients of auth-service call as their tenant with tokens signed with the
+	// shared SERVICE_TOKEN_SECRET, limited to the operations of their scopes
+	if secret := os.Getenv("SERVICE_TOKEN_SECRET"); secret != "" {
+		accessService.SetServiceTokens(security.NewServiceTokenVerifier(secret))
+	}
+	// Services invoke UCANs at this deployment's DID, rooted at a tenant's root DID and
+	// verified with keys resolved here
+	if audience := os.Getenv("UCAN_AUDIENCE"); audience != "" {
+		accessService.SetUCANs(resolverService, audience)
+	}
+	// Custodial DIDs get separate authentication, assertionMethod and keyAgreement keys
+	purposeKeyService := services.NewPurposeKeyService(purposeKeyRepo, didGen)
+	didService.SetPurposeKeys(purposeKeyService)
+	resolverService.SetPurposeKeys(purposeKeyService)
+	credentialService.SetPurposeKeys(purposeKeyService)
+	// Tenants with issuance rules only issue the credentials their rules allow
+	issuanceRuleService := services.NewIssuanceRuleService(repository.NewIssuanceRuleRepository(db), didRepo, usageRepo)
+	credentialService.SetIssuanceRules(issuanceRuleService)
+	// Payloads are encrypted to the keyAgreement keys of DIDs for internal services and
+	// webhook endpoints
+	encryptionService := services.NewEncryptionService(accessService, purposeKeyService)
+	statusTokenIssuer := os.Getenv("STATUS_TOKEN_ISSUER")
+	if statusTokenIssuer == "" {
+		statusTokenIssuer = "did-manager"
+	}
+	statusTokenService := services.NewStatusTokenService(
+		accessService,
+		didService,
+		loadStatusTokenSigningKey(logger),
+		statusTokenIssuer,
+		getEnvDuration("STATUS_TOKEN_TTL", 12*time.Hour),
+		getEnvDuration("STATUS_TOKEN_MAX_TTL", 72*time.Hour),
+	)
+	statusTokenService.SetClock(appClock)
+	// Devices may prove they run a genuine app build, which high-risk wallet
+	// operations can require
+	attestedOperations, err := services.ParseAttestedOperations(os.Getenv("WALLET_ATTESTED_OPERATIONS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid WALLET_ATTESTED_OPERATIONS")
+	}
+	deviceKeyService.SetAttestation(loadAttestationVerifiers(logger), attestedOperations)
+
+	// Plugin hooks run deployment-specific logic around DID creation, verification and
+	// credential issuance
+	hooks := services.NewHooks()
+	usageService := services.NewUsageService(usageRepo)
+	if err := hooks.Register(usageService); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to register usage metering")
+	}
+	// Requests made with API keys draw cost units from monthly budgets, and chain
+	// writes are throttled per tenant
+	costService := services.NewCostService(costRepo)
+	costService.SetChainWriteLimit(getEnvInt("CHAIN_WRITE_RATE_LIMIT", 120), getEnvInt("CHAIN_WRITE_RATE_BURST", 30))
+	methodService := services.NewMethodService(methodPolicyRepo, resolverService)
+	if err := hooks.Register(methodService); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to register DID method policies")
+	}
+	if path := os.Getenv("SCREENING_DENYLIST_FILE"); path != "" {
+		denylist, err := screening.LoadDenylist(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load screening denylist")
+		}
+		if err := hooks.Register(services.NewScreeningService(denylist)); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to register screening")
+		}
+		logger.Info().Int("entries", denylist.Len()).Msg("Screening identities against static denylist")
+	}
+	// Relying parties that verified a DID or credential are notified at their webhooks
+	// when it is revoked
+	webhookService := services.NewWebhookService(webhookRepo, accessService)
+	webhookService.SetClock(appClock)
+	if queueClient != nil {
+		// Administrators replay retained events to endpoints whose consumer was down
+		webhookService.SetEventHistory(queueClient)
+	}
+	webhookService.SetEncryption(encryptionService)
+	revocationPropagationService := services.NewRevocationPropagationService(
+		verificationLogRepo,
+		webhookService,
+		getEnvDuration("REVOCATION_PROPAGATION_WINDOW", 90*24*time.Hour),
+	)
+	if err := hooks.Register(revocationPropagationService); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to register revocation propagation")
+	}
+	for _, plugin := range hookPlugins {
+		if err := hooks.Register(plugin); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to register plugin hook")
+		}
+	}
+	didService.SetHooks(hooks)
+	credentialService.SetHooks(hooks)
+
+	// Documents attached to credentials are kept encrypted in an object store
+	documentVault := loadDocumentVault(logger)
+	if documentVault != nil {
+		credentialService.SetDocuments(documentRepo)
+	}
+	documentService := services.NewDocumentService(
+		documentRepo,
+		documentVault,
+		[]byte(os.Getenv("DOCUMENT_URL_SECRET")),
+		os.Getenv("PUBLIC_BASE_URL"),
+		getEnvDuration("DOCUMENT_URL_TTL", 15*time.Minute),
+		int64(getEnvInt("DOCUMENT_MAX_SIZE", 10<<20)),
+	)
+
+	// Identity tables are backed up encrypted to an S3-compatible bucket
+	backupService := services.NewBackupService(
+		backupRepo,
+		loadBackupVault(logger),
+		getEnvDuration("BACKUP_RETENTION", 30*24*time.Hour),
+	)
+
+	// Usage events are exported as Parquet files to the data warehouse's bucket
+	analyticsPrefix := os.Getenv("ANALYTICS_S3_PREFIX")
+	if analyticsPrefix == "" {
+		analyticsPrefix = "analytics"
+	}
+	analyticsExportService := services.NewAnalyticsExportService(
+		analyticsExportRepo,
+		loadAnalyticsStore(logger),
+		analyticsPrefix,
+	)
+
+	// Finished blockchain jobs are moved to the compressed job archive rather than deleted
+	jobArchiveService := services.NewJobArchiveService(jobArchiveRepo, getEnvDuration("JOB_ARCHIVE_AFTER", 30*24*time.Hour))
+
+	// Duplicate identity detection; matching DIDs are held for review unless configured to reject
+	duplicateRules := map[domain.DuplicateRule]domain.DuplicateAction{
+		domain.DuplicateRuleEmail:  domain.DuplicateActionReview,
+		domain.DuplicateRuleClaims: domain.DuplicateActionReview,
+	}
+	configuredRules, err := services.ParseDuplicateRules(os.Getenv("DUPLICATE_IDENTITY_RULES"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DUPLICATE_IDENTITY_RULES")
+	}
+	for rule, action := range configuredRules {
+		duplicateRules[rule] = action
+	}
+	didService.SetDuplicateDetection(services.NewDuplicateService(duplicateRepo, didGen, duplicateRules))
+
+	// Operations flagged by screening, duplicate detection or other hooks are held for review
+	didService.SetReviewQueue(reviewRepo)
+	credentialService.SetReviewQueue(reviewRepo)
+
+	// Resolve did:ion DIDs through an ION node or universal resolver so their
+	// credentials and presentations can be verified
+	if url := os.Getenv("ION_RESOLVER_URL"); url != "" {
+		ionResolver := sidetree.NewResolver(url)
+		resolverService.SetIONResolver(ionResolver)
+		credentialService.SetIONResolver(ionResolver)
+	}
+	reviewService := services.NewReviewService(reviewRepo, didService, credentialService)
+
+	// With the sidetree anchor backend, DID operations are batched into files on IPFS
+	// and anchored with one transaction per batch instead of one registry transaction each
+	var sidetreeService *services.SidetreeService
+	switch backend := os.Getenv("ANCHOR_BACKEND"); backend {
+	case "", "registry", "hedera":
+	case "sidetree":
+		if blockchainClient == nil || blockchainClient.SidetreeAnchor() == "" {
+			logger.Fatal().Msg("ANCHOR_BACKEND=sidetree requires a blockchain client and SIDETREE_ANCHOR_ADDRESS")
+		}
+		ipfsURL := os.Getenv("IPFS_API_URL")
+		if ipfsURL == "" {
+			logger.Fatal().Msg("ANCHOR_BACKEND=sidetree requires IPFS_API_URL")
+		}
+		sidetreeService = services.NewSidetreeService(
+			sidetreeRepo,
+			queueRepo,
+			didService,
+			sidetree.NewIPFSStore(ipfsURL),
+			blockchainClient,
+			getEnvInt("SIDETREE_MAX_OPERATIONS", 1000),
+		)
+		didService.SetSidetreeBatching(sidetreeRepo)
+	default:
+		logger.Fatal().Str("backend", backend).Msg("Unknown ANCHOR_BACKEND")
+	}
+	walletService := services.NewWalletService(didRepo, credentialService)
+	// Anchor revocation roots only when the registry contract is reachable; as with events,
+	// avoid wrapping a nil client in the interface
+	var revocationAnchor services.RevocationAnchor
+	if blockchainClient != nil && blockchainClient.RevocationRegistry() != "" {
+		revocationAnchor = blockchainClient
+	}
+	revocationService := services.NewRevocationService(credentialRepo, revocationBatchRepo, revocationAnchor)
+	notarizationService := services.NewNotarizationService(
+		notarizationRepo,
+		queueRepo,
+		didRepo,
+		didGen.Registry(),
+		jobQueue,
+		anchorAddress,
+	)
+	sessionService := services.NewVerificationSessionService(
+		sessionRepo,
+		credentialService,
+		os.Getenv("PUBLIC_BASE_URL"),
+		getEnvDuration("VERIFICATION_SESSION_TTL", 5*time.Minute),
+	)
+	sessionService.SetClock(appClock)
+	sessionService.SetRevocationPropagation(revocationPropagationService)
+	walletOfferService := services.NewWalletOfferService(
+		repository.NewWalletOfferRepository(db),
+		didRepo,
+		credentialRepo,
+		os.Getenv("PUBLIC_BASE_URL"),
+		getEnvDuration("WALLET_OFFER_TTL", 7*24*time.Hour),
+	)
+	walletOfferService.SetClock(appClock)
+	notificationService := services.NewNotificationService(pushDeviceRepo, didRepo, localeService, loadPushProviders(logger)...)
+	custodyService := services.NewCustodyService(custodyTransferRepo, didRepo, queueRepo, didGen.Registry(), jobQueue)
+	custodyService.SetClock(appClock)
+	endorsementService := services.NewEndorsementService(endorsementRepo, didRepo, didGen.Registry())
+	signedVerificationService := services.NewSignedVerificationService(
+		verificationNonceRepo,
+		didRepo,
+		didGen.Registry(),
+		didService,
+		getEnvDuration("VERIFICATION_NONCE_TTL", 5*time.Minute),
+	)
+	signedVerificationService.SetClock(appClock)
+	// Sensitive operations require a fresh DID-signed challenge or credential
+	// presentation when a step-up policy is configured for them
+	stepUpPolicies, err := services.ParseStepUpPolicies(os.Getenv("STEP_UP_POLICIES"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid STEP_UP_POLICIES")
+	}
+	stepUpService := services.NewStepUpService(
+		stepUpChallengeRepo,
+		didRepo,
+		didGen.Registry(),
+		credentialService,
+		stepUpPolicies,
+		getEnvDuration("STEP_UP_CHALLENGE_TTL", 5*time.Minute),
+	)
+	stepUpService.SetClock(appClock)
+	// DIDs link social accounts by publishing a signed challenge from them
+	socialProofService := services.NewSocialProofService(
+		repository.NewSocialProofChallengeRepository(db),
+		didRepo,
+		didGen.Registry(),
+		credentialService,
+		os.Getenv("SOCIAL_PROOF_ISSUER_DID"),
+		getEnvDuration("SOCIAL_PROOF_CHALLENGE_TTL", time.Hour),
+		getEnvDuration("SOCIAL_PROOF_CREDENTIAL_VALIDITY", 365*24*time.Hour),
+	)
+	socialProofService.AddPlatform(socialproof.NewGitHubGists(socialproof.GitHubAPIURL, os.Getenv("SOCIAL_PROOF_GITHUB_TOKEN")))
+	if bearerToken := os.Getenv("SOCIAL_PROOF_TWITTER_BEARER_TOKEN"); bearerToken != "" {
+		twitter, err := socialproof.NewTwitter(socialproof.TwitterAPIURL, bearerToken)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid Twitter social proof configuration")
+		}
+		socialProofService.AddPlatform(twitter)
+	}
+	renewalService := services.NewRenewalService(
+		credentialRepo,
+		renewalPolicyRepo,
+		credentialService,
+		getEnvDuration("CREDENTIAL_EXPIRY_REMINDER_WINDOW", 7*24*time.Hour),
+	)
+	renewalService.SetClock(appClock)
+	// Bulk revocations enqueue a limited number of DID deactivations per run, so
+	// terminating an organization does not flood the chain
+	bulkRevocationService := services.NewBulkRevocationService(
+		repository.NewBulkRevocationRepository(db),
+		credentialRepo,
+		didService,
+		credentialService,
+		getEnvInt("BULK_REVOCATION_CHAIN_WRITES", 20),
+	)
+	organizationService := services.NewOrganizationService(
+		organizationRepo,
+		didRepo,
+		queueRepo,
+		credentialService,
+		didGen.Registry(),
+		jobQueue,
+	)
+	organizationService.SetClock(appClock)
+	// Apps render identity cards from the profile fields DIDs publish
+	profileService := services.NewProfileService(repository.NewProfileRepository(db), didRepo, credentialRepo, accessService)
+	organizationService.SetProfiles(profileService)
+	// Tenants add the claims they verify, such as phone numbers, to existing DIDs
+	claimRepo := repository.NewClaimRepository(db)
+	resolverService.SetClaims(claimRepo)
+	claimService := services.NewClaimService(claimRepo, accessService, didGen, credentialService, os.Getenv("CLAIMS_ISSUER_DID"))
+
+	complianceService := services.NewComplianceService(complianceRepo, loadReportSigningKey(logger))
+
+	// Verifiers follow the trust registry through webhooks and signed snapshots
+	trustRegistryService := services.NewTrustRegistryService(
+		repository.NewTrustRegistryRepository(db),
+		events,
+		loadTrustRegistryTemplates(logger),
+		loadTrustRegistrySigningKey(logger),
+	)
+
+	// Compare against the registry contract only when the chain is reachable
+	var registryReader services.RegistryReader
+	if blockchainClient != nil {
+		registryReader = blockchainClient
+	}
+	repairService := services.NewRepairService(
+		didRepo,
+		queueRepo,
+		userHashIndexService,
+		registryReader,
+		jobQueue,
+		uint64(getEnvInt("REPAIR_FROM_BLOCK", 0)),
+	)
+
+	// Initialize resolution protections
+	resolutionLimiter := security.NewRateLimiter(
+		getEnvInt("RESOLUTION_RATE_LIMIT", 60),
+		getEnvInt("RESOLUTION_RATE_BURST", 20),
+	)
+	// Sandbox keys exercise flows in bulk while integrating
+	sandboxLimiter := security.NewRateLimiter(
+		getEnvInt("SANDBOX_RESOLUTION_RATE_LIMIT", 600),
+		getEnvInt("SANDBOX_RESOLUTION_RATE_BURST", 200),
+	)
+	enumerationMonitor := security.NewEnumerationMonitor(
+		getEnvInt("ENUMERATION_ALERT_THRESHOLD", 20),
+		getEnvDuration("ENUMERATION_ALERT_WINDOW", time.Minute),
+	)
+	resolutionGuard := handler.ResolutionGuard(resolutionLimiter, sandboxLimiter, enumerationMonitor)
+
+	// Anonymous DID creation costs gas and claims checks hash with Argon2id, so both can
+	// require an anti-automation challenge
+	challengeVerifier := loadChallengeVerifier(logger)
+	challengeGuard := handler.RequireChallenge(challengeVerifier)
 
 	// Initialize handlers
-	didHandler := handler.NewDIDHandler(didService)
+	didHandler := handler.NewDIDHandler(didService, accessService, localeService, resolutionGuard, challengeVerifier)
+	resolverHandler := handler.NewResolverHandler(resolverService, resolutionGuard)
+	profileHandler := handler.NewProfileHandler(profileService, resolutionGuard)
+	claimHandler := handler.NewClaimHandler(claimService, resolutionGuard)
+	changeHandler := handler.NewChangeHandler(changeService, resolutionGuard)
+	signedVerificationHandler := handler.NewSignedVerificationHandler(signedVerificationService, resolutionGuard)
+	emailLookupHandler := handler.NewEmailLookupHandler(emailLookupService, resolutionGuard)
+	encryptionHandler := handler.NewEncryptionHandler(encryptionService)
+	statusTokenHandler := handler.NewStatusTokenHandler(statusTokenService)
+	adminHandler := handler.NewAdminHandler(accessService, didService, organizationService, repairService, enumerationMonitor, os.Getenv("ADMIN_API_KEY"))
+	credentialHandler := handler.NewCredentialHandler(credentialService, revocationPropagationService)
+	sessionHandler := handler.NewVerificationSessionHandler(sessionService)
+	revocationHandler := handler.NewRevocationHandler(revocationService)
+	notarizationHandler := handler.NewNotarizationHandler(notarizationService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService, stepUpService)
+	endorsementHandler := handler.NewEndorsementHandler(endorsementService)
+	stepUpHandler := handler.NewStepUpHandler(stepUpService)
+	socialProofHandler := handler.NewSocialProofHandler(socialProofService)
+	renewalHandler := handler.NewRenewalHandler(renewalService)
+	webhookHandler := handler.NewWebhookHandler(webhookService, os.Getenv("ADMIN_API_KEY"))
+	documentHandler := handler.NewDocumentHandler(documentService)
+	backupHandler := handler.NewBackupHandler(backupService, os.Getenv("ADMIN_API_KEY"))
+	sandboxHandler := handler.NewSandboxHandler(services.NewSandboxService(didRepo), os.Getenv("ADMIN_API_KEY"))
+	funnelService := services.NewFunnelService(usageRepo, getEnvInt("FUNNEL_METRICS_DAYS", 7))
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsExportService, funnelService, os.Getenv("ADMIN_API_KEY"))
+	complianceHandler := handler.NewComplianceHandler(complianceService, os.Getenv("ADMIN_API_KEY"))
+	trustRegistryHandler := handler.NewTrustRegistryHandler(trustRegistryService, os.Getenv("ADMIN_API_KEY"))
+	bulkRevocationHandler := handler.NewBulkRevocationHandler(bulkRevocationService, os.Getenv("ADMIN_API_KEY"))
+	exportService := services.NewExportService(didRepo, credentialRepo, aclRepo, queueRepo, resolverService)
+	exportService.SetJobArchive(jobArchiveRepo)
+	exportHandler := handler.NewExportHandler(exportService, os.Getenv("ADMIN_API_KEY"))
+	jobArchiveHandler := handler.NewJobArchiveHandler(jobArchiveService, os.Getenv("ADMIN_API_KEY"))
+	identifierMigrationHandler := handler.NewIdentifierMigrationHandler(identifierService, os.Getenv("ADMIN_API_KEY"))
+	accountHandler := handler.NewAccountHandler(services.NewAccountEventService(didRepo, events), os.Getenv("ADMIN_API_KEY"))
+	capabilityService := services.NewCapabilityService(ledger, jobQueue)
+	featureHandler := handler.NewFeatureHandler(featureService, methodService, capabilityService, didGen.Registry(), version, os.Getenv("ADMIN_API_KEY"))
+	reviewHandler := handler.NewReviewHandler(reviewService, os.Getenv("ADMIN_API_KEY"))
+	usageHandler := handler.NewUsageHandler(usageService, os.Getenv("ADMIN_API_KEY"))
+	costHandler := handler.NewCostHandler(costService, accessService, os.Getenv("ADMIN_API_KEY"))
+	methodHandler := handler.NewMethodHandler(methodService, os.Getenv("ADMIN_API_KEY"))
+	verificationPolicyHandler := handler.NewVerificationPolicyHandler(verificationPolicyService, os.Getenv("ADMIN_API_KEY"))
+	issuanceRuleHandler := handler.NewIssuanceRuleHandler(issuanceRuleService, os.Getenv("ADMIN_API_KEY"))
+	replicationHandler := handler.NewReplicationHandler(replicationService, os.Getenv("ADMIN_API_KEY"))
+	// Wallet tokens are issued by auth-service and signed with the shared WALLET_TOKEN_SECRET
+	walletTokenVerifier := security.NewWalletTokenVerifier(os.Getenv("WALLET_TOKEN_SECRET"))
+	walletHandler := handler.NewWalletHandler(walletService, notificationService, custodyService, walletTokenVerifier, stepUpService, deviceKeyService)
+	deviceKeyHandler := handler.NewDeviceKeyHandler(deviceKeyService, walletTokenVerifier, os.Getenv("ADMIN_API_KEY"))
+	walletOfferHandler := handler.NewWalletOfferHandler(walletOfferService, os.Getenv("ADMIN_API_KEY"))
 
-	// Setup Gin router
-	router := gin.Default()
+	// Components start in dependency order and stop in reverse; the service reports
+	// ready only once all of them are running
+	lifecycleManager := lifecycle.NewManager(
+		getEnvDuration("COMPONENT_START_TIMEOUT", 10*time.Second),
+		getEnvDuration("COMPONENT_STOP_TIMEOUT", 30*time.Second),
+	)
+	lifecycleManager.SetPanicHandler(func(component string, value any) {
+		errorReporter.Report(errreport.Panic(value, map[string]string{errreport.TagComponent: component}))
+	})
+	// Added first so it stops last, shipping the records of every other component
+	if siemShipper != nil {
+		lifecycleManager.Add(lifecycle.Component{Name: "siem", Start: siemShipper.Start, Stop: siemShipper.Stop})
+	}
+	readinessHandler := handler.NewReadinessHandler(lifecycleManager)
+	if shadowChain != nil {
+		// Stopped after the job workers, so the last shadow calls are compared
+		lifecycleManager.Add(lifecycle.Component{
+			Name:  "anchoring-shadow",
+			Start: func(context.Context) error { return nil },
+			Stop:  shadowChain.Drain,
+		})
+	}
+	statusPageService := services.NewStatusPageService(repository.NewStatusIncidentRepository(db), lifecycleManager, queueRepo)
+	statusPageService.SetAnchoringPause(anchoringPause)
+	statusPageHandler := handler.NewStatusPageHandler(statusPageService, localeService, os.Getenv("ADMIN_API_KEY"))
+	localeHandler := handler.NewLocaleHandler(localeService, os.Getenv("ADMIN_API_KEY"))
+	anchoringPauseHandler := handler.NewAnchoringPauseHandler(anchoringPause, os.Getenv("ADMIN_API_KEY"))
+	metricsHandler := handler.NewMetricsHandler(anchoringPause, funnelService, siemShipper, shadowChain, os.Getenv("ADMIN_API_KEY"))
+
+	// Setup the router, Gin unless configured otherwise
+	baseRouter, routerHandler := newRouter(os.Getenv("HTTP_ROUTER"), splitList(os.Getenv("TRUSTED_PROXIES")), logger)
 
-	// Add middleware
-	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
+	// API versions are served side by side: v2 serves the v1 routes it does not replace
+	// in its own envelope, while v1 keeps working for existing integrators until its
+	// sunset
+	apiV1, apiV2 := handler.NewAPIVersions(getEnvTime("API_V1_DEPRECATION"), getEnvTime("API_V1_SUNSET"))
+	router := apiversion.NewAPI(baseRouter, apiV1, apiV2)
+
+	// Add middleware; request IDs and panic recovery come first so every other
+	// middleware is covered
+	router.Use(handler.RequestID())
+	router.Use(handler.Recover(errorReporter))
+	if siemShipper != nil {
+		router.Use(handler.ShipSecurityActivity(siemShipper))
+	}
+	router.Use(handler.Authenticate(accessService))
+	router.Use(handler.EnforceScopes())
+	router.Use(handler.ReadYourWrites(consistencyService))
+	router.Use(handler.RejectWritesOnStandby(replicationService))
+	router.Use(handler.MeterCost(costService))
 
 	// Register routes
-	didHandler.RegisterRoutes(router)
+	web.Mount(router,
+		didHandler,
+		resolverHandler,
+		profileHandler,
+		claimHandler,
+		changeHandler,
+		signedVerificationHandler,
+		emailLookupHandler,
+		encryptionHandler,
+		statusTokenHandler,
+		adminHandler,
+		credentialHandler,
+		sessionHandler,
+		revocationHandler,
+		notarizationHandler,
+		organizationHandler,
+		endorsementHandler,
+		stepUpHandler,
+		socialProofHandler,
+		renewalHandler,
+		webhookHandler,
+		documentHandler,
+		backupHandler,
+		jobArchiveHandler,
+		identifierMigrationHandler,
+		sandboxHandler,
+		analyticsHandler,
+		complianceHandler,
+		trustRegistryHandler,
+		bulkRevocationHandler,
+		exportHandler,
+		accountHandler,
+		featureHandler,
+		reviewHandler,
+		usageHandler,
+		costHandler,
+		methodHandler,
+		verificationPolicyHandler,
+		issuanceRuleHandler,
+		replicationHandler,
+		walletHandler,
+		deviceKeyHandler,
+		walletOfferHandler,
+		readinessHandler,
+		statusPageHandler,
+		localeHandler,
+		anchoringPauseHandler,
+		metricsHandler,
+	)
+
+	// Operators follow the shadow anchor backend's divergences before cutover
+	if shadowChain != nil {
+		web.Mount(router, handler.NewAnchoringShadowHandler(shadowChain, os.Getenv("ADMIN_API_KEY")))
+	}
+
+	// Clients fetch the challenge to solve before creating a DID anonymously
+	if challengeVerifier != nil {
+		web.Mount(router, handler.NewChallengeHandler(challengeVerifier))
+	}
 
-	// Start background worker for blockchain queue processing
-	if blockchainClient != nil && queueClient != nil {
-		go startBackgroundWorker(didService, logger)
+	// Conformance mode serves the Universal Resolver and Registrar driver interfaces and
+	// the fixtures the W3C DID test suites are run with
+	if os.Getenv("CONFORMANCE_MODE") == "true" {
+		implementer := os.Getenv("CONFORMANCE_IMPLEMENTER")
+		if implementer == "" {
+			implementer = "DID Manager"
+		}
+		conformanceService := services.NewConformanceService(
+			resolverService,
+			didService,
+			splitList(os.Getenv("CONFORMANCE_DIDS")),
+			splitList(os.Getenv("CONFORMANCE_DEACTIVATED_DIDS")),
+			implementer,
+		)
+		web.Mount(router, handler.NewConformanceHandler(conformanceService, resolutionGuard, challengeGuard))
+		capabilityService.SetConformanceMode(true)
+		logger.Info().Msg("Conformance mode enabled")
+	}
+
+	// ERC-4337 smart accounts control DIDs on-chain; their user operations are signed by
+	// the account owners and relayed to a bundler
+	if bundlerURL := os.Getenv("ERC4337_BUNDLER_URL"); bundlerURL != "" {
+		factoryAddress := os.Getenv("ERC4337_ACCOUNT_FACTORY")
+		entryPoint := os.Getenv("ERC4337_ENTRY_POINT")
+		if entryPoint == "" {
+			entryPoint = erc4337.EntryPointV06
+		}
+		if blockchainClient == nil || !common.IsHexAddress(factoryAddress) || !common.IsHexAddress(entryPoint) {
+			logger.Fatal().Msg("ERC4337_BUNDLER_URL requires a blockchain client, ERC4337_ACCOUNT_FACTORY and a valid ERC4337_ENTRY_POINT")
+		}
+		smartAccountRepo := repository.NewSmartAccountRepository(db)
+		smartAccountService := services.NewSmartAccountService(
+			smartAccountRepo,
+			didRepo,
+			erc4337.NewFactory(blockchainClient.Node(), common.HexToAddress(factoryAddress), common.HexToAddress(entryPoint)),
+			erc4337.NewBundler(bundlerURL, common.HexToAddress(entryPoint)),
+			blockchainClient.Node(),
+			blockchainClient.ChainID(),
+		)
+		resolverService.SetSmartAccounts(smartAccountRepo)
+		web.Mount(router, handler.NewSmartAccountHandler(smartAccountService))
+		capabilityService.SetSmartAccounts(true)
+		logger.Info().Str("entry_point", entryPoint).Msg("ERC-4337 smart accounts enabled")
+	}
+
+	// Deliver push notifications for wallet events from the domain event stream
+	if queueClient != nil {
+		lifecycleManager.Add(eventConsumer("push-notifications", "did-manager-push", queueClient, replicationService, errorReporter, notificationService.HandleEvent))
+		// Fan domain events out to the tenants' webhook endpoints
+		lifecycleManager.Add(eventConsumer("webhooks", "did-manager-webhooks", queueClient, replicationService, errorReporter, webhookService.HandleEvent))
+		// Notify relying parties of revocations of DIDs and credentials they verified
+		lifecycleManager.Add(eventConsumer("revocation-propagation", "did-manager-revocation-propagation", queueClient, replicationService, errorReporter, revocationPropagationService.HandleEvent))
+		// Stream domain events to the SIEM
+		if siemShipper != nil {
+			lifecycleManager.Add(eventConsumer("siem-events", "did-manager-siem", queueClient, replicationService, errorReporter, shipEvents(siemShipper)))
+		}
+	}
+
+	// Process blockchain jobs from the database, with or without the queue. Like the
+	// other anchoring workers, it idles on a standby and while anchoring is paused.
+	if anchoring {
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("blockchain-worker", appClock, getEnvDuration("JOB_PROCESSING_INTERVAL", 30*time.Second), func(context.Context) {
+			if replicationService.ReadOnly() || anchoringPause.Paused() {
+				return
+			}
+			if err := didService.ProcessBlockchainQueue(); err != nil {
+				logger.Error().Err(err).Msg("Failed to process blockchain queue")
+			}
+		}))
+	}
+
+	// Keep the gas price of blockchain jobs in step with the network
+	if blockchainClient != nil {
+		fees := blockchainClient.Fees()
+		web.Mount(router, handler.NewGasPriceHandler(fees, os.Getenv("ADMIN_API_KEY")))
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("gas-price", appClock, getEnvDuration("GAS_PRICE_REFRESH_INTERVAL", 15*time.Second), func(ctx context.Context) {
+			if err := fees.Refresh(ctx); err != nil {
+				logger.Warn().Err(err).Msg("Failed to refresh gas price, keeping the previous one")
+			}
+		}))
 	}
 
-	// Start HTTP server
+	// Follow the registry's DIDUpdated and DIDRevoked events, publishing them for
+	// webhooks and streaming them to relying parties. It idles on a standby too.
+	if blockchainClient != nil {
+		chainEventService := services.NewChainEventService(blockchainClient, didRepo, events, accessService)
+		web.Mount(router, handler.NewChainEventHandler(chainEventService))
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("chain-events", appClock, getEnvDuration("CHAIN_EVENT_POLL_INTERVAL", 5*time.Second), func(context.Context) {
+			if replicationService.ReadOnly() {
+				return
+			}
+			if err := chainEventService.Poll(); err != nil {
+				logger.Error().Err(err).Msg("Failed to read chain events")
+			}
+		}))
+	}
+
+	// Anchor batches of DID operations with the sidetree backend
+	if sidetreeService != nil {
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("sidetree-batcher", appClock, getEnvDuration("SIDETREE_BATCH_INTERVAL", time.Minute), func(context.Context) {
+			if replicationService.ReadOnly() || anchoringPause.Paused() {
+				return
+			}
+			if err := sidetreeService.ProcessBatch(); err != nil {
+				logger.Error().Err(err).Msg("Failed to anchor Sidetree batch")
+			}
+		}))
+	}
+
+	// Fold revocations into batches and anchor their accumulator roots on-chain while
+	// the revocation_anchoring feature is enabled
+	if revocationAnchor != nil {
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("revocation-anchoring", appClock, getEnvDuration("REVOCATION_BATCH_INTERVAL", 10*time.Minute), func(context.Context) {
+			if replicationService.ReadOnly() || anchoringPause.Paused() || !featureService.Enabled(domain.FeatureRevocationAnchoring, "") {
+				return
+			}
+			if err := revocationService.ProcessBatch(); err != nil {
+				logger.Error().Err(err).Msg("Failed to anchor revocation batch")
+			}
+		}))
+	}
+
+	// Advance executing bulk revocations; their DID deactivations wait while anchoring
+	// is paused
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("bulk-revocation", appClock, getEnvDuration("BULK_REVOCATION_INTERVAL", time.Minute), func(context.Context) {
+		if replicationService.ReadOnly() || anchoringPause.Paused() {
+			return
+		}
+		if err := bulkRevocationService.ProcessExecuting(); err != nil {
+			logger.Error().Err(err).Msg("Failed to process bulk revocations")
+		}
+	}))
+
+	// Remind holders and issuers of expiring credentials and apply auto-renewal
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("credential-renewal", appClock, getEnvDuration("CREDENTIAL_EXPIRY_CHECK_INTERVAL", time.Hour), func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := renewalService.ProcessExpiring(); err != nil {
+			logger.Error().Err(err).Msg("Failed to process expiring credentials")
+		}
+	}))
+
+	// Post due webhook deliveries, retrying failed ones with backoff
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("webhook-deliveries", appClock, getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", 10*time.Second), func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := webhookService.DeliverDue(); err != nil {
+			logger.Error().Err(err).Msg("Failed to deliver webhooks")
+		}
+	}))
+
+	// Forget verifications older than the revocation propagation window
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("verification-log-cleanup", appClock, time.Hour, func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := revocationPropagationService.Prune(); err != nil {
+			logger.Error().Err(err).Msg("Failed to prune verification log")
+		}
+	}))
+
+	// Back up the identity tables on schedule when a backup bucket is configured
+	if backupService.Enabled() {
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("backups", appClock, getEnvDuration("BACKUP_INTERVAL", 24*time.Hour), func(context.Context) {
+			if replicationService.ReadOnly() {
+				return
+			}
+			if _, err := backupService.RunBackup(); err != nil {
+				logger.Error().Err(err).Msg("Failed to back up identity data")
+			}
+		}))
+	}
+
+	// Export usage events for analytics on schedule when an analytics bucket is configured
+	if analyticsExportService.Enabled() {
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("analytics-export", appClock, getEnvDuration("ANALYTICS_EXPORT_INTERVAL", time.Hour), func(context.Context) {
+			if replicationService.ReadOnly() {
+				return
+			}
+			if _, err := analyticsExportService.RunExport(); err != nil {
+				logger.Error().Err(err).Msg("Failed to export analytics events")
+			}
+		}))
+	}
+
+	// Move finished blockchain jobs into the job archive
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("job-archival", appClock, getEnvDuration("JOB_ARCHIVE_INTERVAL", time.Hour), func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if _, err := jobArchiveService.RunArchival(); err != nil {
+			logger.Error().Err(err).Msg("Failed to archive blockchain jobs")
+		}
+	}))
+
+	// Index user hashes under the current pepper version, completing pepper rotations
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("user-hash-rehash", appClock, getEnvDuration("USER_HASH_REHASH_INTERVAL", time.Hour), func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		run, err := userHashIndexService.Rehash()
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to re-hash user hash indexes")
+			return
+		}
+		if run.Remaining > 0 {
+			logger.Warn().Int("remaining", run.Remaining).Str("pepper_version", run.Version).Msg("User hash indexes left to re-hash")
+		}
+	}))
+
+	// Alias legacy DIDs with identifiers in the current format and anchor the aliases
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("did-identifier-migration", appClock, getEnvDuration("DID_IDENTIFIER_MIGRATION_INTERVAL", time.Hour), func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if _, err := identifierService.Migrate(); err != nil {
+			logger.Error().Err(err).Msg("Failed to migrate legacy DID identifiers")
+		}
+	}))
+
+	// Purge used and unused verification nonces once expired
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("verification-nonce-cleanup", appClock, time.Hour, func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := signedVerificationService.DeleteExpiredNonces(); err != nil {
+			logger.Error().Err(err).Msg("Failed to delete expired verification nonces")
+		}
+	}))
+
+	// Purge step-up challenges once expired
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("step-up-challenge-cleanup", appClock, time.Hour, func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := stepUpService.DeleteExpiredChallenges(); err != nil {
+			logger.Error().Err(err).Msg("Failed to delete expired step-up challenges")
+		}
+	}))
+
+	// Purge social proof challenges once expired
+	lifecycleManager.Add(lifecycle.PeriodicWithClock("social-proof-challenge-cleanup", appClock, time.Hour, func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := socialProofService.DeleteExpiredChallenges(); err != nil {
+			logger.Error().Err(err).Msg("Failed to delete expired social proof challenges")
+		}
+	}))
+
+	// Write the cost usage accumulated in memory, a last time once the HTTP server has
+	// drained
+	flushCosts := func(context.Context) {
+		if replicationService.ReadOnly() {
+			return
+		}
+		if err := costService.Flush(); err != nil {
+			logger.Error().Err(err).Msg("Failed to record cost usage")
+		}
+	}
+	costAccounting := lifecycle.PeriodicWithClock("cost-accounting", appClock, getEnvDuration("COST_FLUSH_INTERVAL", 10*time.Second), flushCosts)
+	stopCostAccounting := costAccounting.Stop
+	costAccounting.Stop = func(ctx context.Context) error {
+		err := stopCostAccounting(ctx)
+		flushCosts(ctx)
+		return err
+	}
+	lifecycleManager.Add(costAccounting)
+
+	// Every route is registered; serve the v1 routes v2 has not replaced under /api/v2
+	router.Mirror()
+
+	// The HTTP server starts last and stops first, so requests are drained before the
+	// components serving them go down
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8082"
 	}
+	lifecycleManager.Add(httpServer(&http.Server{
+		Addr:      ":" + port,
+		Handler:   compressResponses(routerHandler, logger),
+		Protocols: httpProtocols(),
+	}, getEnvDuration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second), logger))
 
-	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: router,
+	if err := lifecycleManager.Start(context.Background()); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to start DID Manager")
 	}
-
-	// Start server in a goroutine
-	go func() {
-		logger.Info().Msgf("Starting DID Manager server on port %s", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal().Err(err).Msg("Failed to start server")
-		}
-	}()
+	for _, status := range lifecycleManager.Statuses() {
+		logger.Info().Str("component", status.Name).Msg("Component running")
+	}
+	logger.Info().Msgf("DID Manager ready on port %s", port)
 
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
@@ -123,12 +1207,8 @@ func main() {
 
 	logger.Info().Msg("Shutting down server...")
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal().Err(err).Msg("Server forced to shutdown")
+	if err := lifecycleManager.Stop(context.Background()); err != nil {
+		logger.Error().Err(err).Msg("Components failed to stop cleanly")
 	}
 
 	logger.Info().Msg("Server exited")
@@ -164,19 +1244,684 @@ func connectDB() (*sql.DB, error) {
 	return db, nil
 }
 
-// startBackgroundWorker starts a background worker to process blockchain jobs
-func startBackgroundWorker(didService *services.DIDService, logger zerolog.Logger) {
-	ticker := time.NewTicker(30 * time.Second) // Process every 30 seconds
-	defer ticker.Stop()
+// verifyBlockchainTrust checks the chain and contracts of a client against the trust
+// configuration file at path, which must be signed with BLOCKCHAIN_TRUST_PUBLIC_KEY when
+// that key is set
+func verifyBlockchainTrust(client *blockchain.EthereumClient, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read trust configuration: %w", err)
+	}
 
-	logger.Info().Msg("Starting background blockchain job processor")
+	var publicKey ed25519.PublicKey
+	var signature []byte
+	if key := os.Getenv("BLOCKCHAIN_TRUST_PUBLIC_KEY"); key != "" {
+		if publicKey, err = hex.DecodeString(key); err != nil {
+			return fmt.Errorf("invalid BLOCKCHAIN_TRUST_PUBLIC_KEY: %w", err)
+		}
+		if signature, err = hex.DecodeString(os.Getenv("BLOCKCHAIN_TRUST_SIGNATURE")); err != nil {
+			return fmt.Errorf("invalid BLOCKCHAIN_TRUST_SIGNATURE: %w", err)
+		}
+	}
 
-	for {
-		select {
-		case <-ticker.C:
-			if err := didService.ProcessBlockchainQueue(); err != nil {
-				logger.Error().Err(err).Msg("Failed to process blockchain queue")
+	config, err := blockchain.ParseTrustConfig(data, signature, publicKey)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return client.VerifyTrust(ctx, config)
+}
+
+// hederaConfig reads the Hedera Consensus Service configuration of the hedera anchor
+// backend
+func hederaConfig() blockchain.HederaConfig {
+	config := blockchain.HederaConfig{
+		Network:       os.Getenv("HEDERA_NETWORK"),
+		NodeURL:       os.Getenv("HEDERA_NODE_URL"),
+		NodeAccountID: os.Getenv("HEDERA_NODE_ACCOUNT_ID"),
+		MirrorURL:     os.Getenv("HEDERA_MIRROR_URL"),
+		TopicID:       os.Getenv("HEDERA_TOPIC_ID"),
+		OperatorID:    os.Getenv("HEDERA_OPERATOR_ID"),
+		OperatorKey:   os.Getenv("HEDERA_OPERATOR_KEY"),
+	}
+	if fee := getEnvInt("HEDERA_MAX_TRANSACTION_FEE", 0); fee > 0 {
+		config.MaxTransactionFee = uint64(fee)
+	}
+	return config
+}
+
+// configureGasPrice applies GAS_PRICE_OVERRIDE and MAX_GAS_PRICE, both in wei, to the
+// fee oracle of the blockchain client
+func configureGasPrice(fees *blockchain.FeeOracle) error {
+	for key, set := range map[string]func(*big.Int) error{
+		"GAS_PRICE_OVERRIDE": fees.SetOverride,
+		"MAX_GAS_PRICE":      fees.SetMaxGasPrice,
+	} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		price, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return fmt.Errorf("%s must be a decimal number of wei", key)
+		}
+		if err := set(price); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// getEnvInt reads an integer environment variable, falling back to defaultValue
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+
+	return parsed
+}
+
+// getEnvTime reads an RFC 3339 time environment variable, zero when unset or
+// invalid
+func getEnvTime(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, expected an RFC 3339 time", key)
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+// getEnvDuration reads a duration environment variable, falling back to defaultValue
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using default %s", key, defaultValue)
+		return defaultValue
+	}
+
+	return parsed
+}
+
+// splitList reads a comma-separated list, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadPushProviders configures the push providers whose credentials are set in the
+// environment; devices on unconfigured platforms are not notified
+func loadPushProviders(logger zerolog.Logger) []push.Provider {
+	var providers []push.Provider
+
+	if path := os.Getenv("FCM_CREDENTIALS_FILE"); path != "" {
+		credentials, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read FCM credentials")
+		}
+		provider, err := push.NewFCMProvider(credentials)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid FCM configuration")
+		}
+		providers = append(providers, provider)
+	}
+
+	if path := os.Getenv("APNS_KEY_FILE"); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read APNs signing key")
+		}
+		provider, err := push.NewAPNsProvider(push.APNsConfig{
+			KeyID:         os.Getenv("APNS_KEY_ID"),
+			TeamID:        os.Getenv("APNS_TEAM_ID"),
+			BundleID:      os.Getenv("APNS_TOPIC"),
+			PrivateKeyPEM: key,
+			Production:    os.Getenv("APNS_PRODUCTION") == "true",
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid APNs configuration")
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// loadAttestationVerifiers configures the app attestation platforms whose settings
+// are in the environment; attestations from other platforms are rejected
+func loadAttestationVerifiers(logger zerolog.Logger) []attestation.Verifier {
+	var verifiers []attestation.Verifier
+
+	if path := os.Getenv("PLAY_INTEGRITY_CREDENTIALS_FILE"); path != "" {
+		credentials, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read Play Integrity credentials")
+		}
+		verifier, err := attestation.NewPlayIntegrityVerifier(os.Getenv("PLAY_INTEGRITY_PACKAGE_NAME"), credentials)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid Play Integrity configuration")
+		}
+		verifiers = append(verifiers, verifier)
+	}
+
+	if path := os.Getenv("APP_ATTEST_ROOT_CA_FILE"); path != "" {
+		rootCA, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read App Attest root CA")
+		}
+		verifier, err := attestation.NewAppAttestVerifier(attestation.AppAttestConfig{
+			AppID:       os.Getenv("APP_ATTEST_APP_ID"),
+			RootCAPEM:   rootCA,
+			Development: os.Getenv("APP_ATTEST_DEVELOPMENT") == "true",
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid App Attest configuration")
+		}
+		verifiers = append(verifiers, verifier)
+	}
+
+	return verifiers
+}
+
+// loadDocumentVault configures the object store and encryption keys of the document
+// vault from the environment; the vault is disabled unless DOCUMENT_STORE is set
+func loadDocumentVault(logger zerolog.Logger) *vault.Vault {
+	var store vault.Store
+	switch provider := os.Getenv("DOCUMENT_STORE"); provider {
+	case "":
+		return nil
+	case "s3":
+		s3, err := vault.NewS3Store(vault.S3Config{
+			Bucket:          os.Getenv("DOCUMENT_S3_BUCKET"),
+			Region:          os.Getenv("DOCUMENT_S3_REGION"),
+			Endpoint:        os.Getenv("DOCUMENT_S3_ENDPOINT"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid S3 document store configuration")
+		}
+		store = s3
+	case "gcs":
+		credentials, err := os.ReadFile(os.Getenv("DOCUMENT_GCS_CREDENTIALS_FILE"))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read GCS credentials")
+		}
+		gcs, err := vault.NewGCSStore(os.Getenv("DOCUMENT_GCS_BUCKET"), credentials)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid GCS document store configuration")
+		}
+		store = gcs
+	default:
+		logger.Fatal().Str("store", provider).Msg("Unknown DOCUMENT_STORE, expected s3 or gcs")
+	}
+
+	keyring, err := queue.ParseKeyring(os.Getenv("DOCUMENT_ENCRYPTION_KEY_ID"), os.Getenv("DOCUMENT_ENCRYPTION_KEYS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid document encryption keys")
+	}
+	if len(os.Getenv("DOCUMENT_URL_SECRET")) < 32 {
+		logger.Fatal().Msg("DOCUMENT_URL_SECRET must be at least 32 characters when the document vault is enabled")
+	}
+
+	logger.Info().Str("store", os.Getenv("DOCUMENT_STORE")).Msg("Document vault enabled")
+	return vault.New(store, keyring)
+}
+
+// loadBackupVault configures the S3-compatible bucket and encryption keys of backups
+// from the environment; backups are disabled unless BACKUP_S3_BUCKET is set
+func loadBackupVault(logger zerolog.Logger) *vault.Vault {
+	if os.Getenv("BACKUP_S3_BUCKET") == "" {
+		return nil
+	}
+
+	store, err := vault.NewS3Store(vault.S3Config{
+		Bucket:          os.Getenv("BACKUP_S3_BUCKET"),
+		Region:          os.Getenv("BACKUP_S3_REGION"),
+		Endpoint:        os.Getenv("BACKUP_S3_ENDPOINT"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid S3 backup configuration")
+	}
+
+	keyring, err := queue.ParseKeyring(os.Getenv("BACKUP_ENCRYPTION_KEY_ID"), os.Getenv("BACKUP_ENCRYPTION_KEYS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid backup encryption keys")
+	}
+
+	logger.Info().Str("bucket", os.Getenv("BACKUP_S3_BUCKET")).Msg("Backups enabled")
+	return vault.New(store, keyring)
+}
+
+// loadAnalyticsStore configures the S3-compatible bucket receiving analytics exports
+// from the environment; exports are disabled unless ANALYTICS_S3_BUCKET is set. Files
+// are stored unencrypted by the service so the warehouse can read them; rely on the
+// bucket's server-side encryption instead.
+func loadAnalyticsStore(logger zerolog.Logger) vault.Store {
+	if os.Getenv("ANALYTICS_S3_BUCKET") == "" {
+		return nil
+	}
+
+	store, err := vault.NewS3Store(vault.S3Config{
+		Bucket:          os.Getenv("ANALYTICS_S3_BUCKET"),
+		Region:          os.Getenv("ANALYTICS_S3_REGION"),
+		Endpoint:        os.Getenv("ANALYTICS_S3_ENDPOINT"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid S3 analytics configuration")
+	}
+
+	logger.Info().Str("bucket", os.Getenv("ANALYTICS_S3_BUCKET")).Msg("Analytics export enabled")
+	return store
+}
+
+// newRouter creates the router serving the API: Gin by default, or the standard
+// library's http.ServeMux with "stdlib". It returns the handler to serve it with.
+// Client IPs, which key rate limits and challenges of anonymous callers, are taken from
+// X-Forwarded-For only on requests from the trusted proxies, IPs or CIDRs; the
+// standard library router never trusts it.
+func newRouter(name string, trustedProxies []string, logger zerolog.Logger) (web.Router, http.Handler) {
+	switch name {
+	case "", "gin":
+		engine := gin.Default()
+		if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+			logger.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+		}
+		engine.Use(gin.Recovery())
+		engine.Use(gin.Logger())
+		return ginweb.New(engine), engine
+	case "stdlib":
+		router := stdweb.New()
+		router.Use(stdweb.Logger())
+		return router, router
+	default:
+		logger.Fatal().Msgf("Unknown HTTP_ROUTER %q, expected gin or stdlib", name)
+		return nil, nil
+	}
+}
+
+// loadChallengeVerifier creates the anti-automation challenge verifier selected by
+// CHALLENGE_PROVIDER; no challenge is required when none is configured
+func loadChallengeVerifier(logger zerolog.Logger) challenge.Verifier {
+	var (
+		verifier challenge.Verifier
+		err      error
+	)
+	secret := os.Getenv("CHALLENGE_SECRET")
+	switch provider := os.Getenv("CHALLENGE_PROVIDER"); provider {
+	case "":
+		return nil
+	case "turnstile":
+		verifier, err = challenge.NewTurnstile(secret)
+	case "hcaptcha":
+		verifier, err = challenge.NewHCaptcha(secret)
+	case "recaptcha":
+		verifier, err = challenge.NewReCAPTCHA(secret)
+	case "pow":
+		key, decodeErr := hex.DecodeString(secret)
+		if decodeErr != nil {
+			logger.Fatal().Msg("CHALLENGE_SECRET must be a hex-encoded key for proof of work")
+		}
+		verifier, err = challenge.NewProofOfWork(key, getEnvInt("CHALLENGE_POW_DIFFICULTY", 20), getEnvDuration("CHALLENGE_POW_TTL", 5*time.Minute))
+	default:
+		logger.Fatal().Msgf("Unknown CHALLENGE_PROVIDER %q, expected turnstile, hcaptcha, recaptcha or pow", provider)
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid challenge configuration")
+	}
+
+	logger.Info().Str("provider", verifier.Name()).Msg("Anti-automation challenge required for anonymous DID creation")
+	return verifier
+}
+
+// loadEmailIndex loads the blind index of holder email addresses from its hex key;
+// email lookups are disabled when none is configured
+func loadEmailIndex(logger zerolog.Logger) *did.BlindIndex {
+	keyHex := os.Getenv("EMAIL_INDEX_KEY")
+	if keyHex == "" {
+		return nil
+	}
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		logger.Fatal().Msg("EMAIL_INDEX_KEY must be hex-encoded")
+	}
+	index, err := did.NewBlindIndex(key)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid EMAIL_INDEX_KEY")
+	}
+
+	return index
+}
+
+// loadErrorReporter creates the reporter of errors and recovered panics: the log, plus
+// the Sentry project of SENTRY_DSN when set
+func loadErrorReporter(logger zerolog.Logger) errreport.Reporter {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return errreport.Log
+	}
+
+	environment := os.Getenv("SENTRY_ENVIRONMENT")
+	if environment == "" {
+		environment = os.Getenv("ENV")
+	}
+	sentry, err := errreport.NewSentry(dsn, environment)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid SENTRY_DSN")
+	}
+
+	logger.Info().Str("environment", environment).Msg("Error reporting to Sentry enabled")
+	return errreport.Multi(errreport.Log, sentry)
+}
+
+// validateShadowBackend checks that a shadow anchor backend is hedera or registry and
+// differs from ANCHOR_BACKEND
+func validateShadowBackend(backend string) error {
+	primary := os.Getenv("ANCHOR_BACKEND")
+	if primary == "" {
+		primary = "registry"
+	}
+	switch {
+	case primary == "sidetree":
+		return errors.New("shadowing the sidetree backend is not supported")
+	case backend != "hedera" && backend != "registry":
+		return fmt.Errorf("unknown shadow backend %q, expected hedera or registry", backend)
+	case backend == primary:
+		return fmt.Errorf("shadow backend %s is the primary backend", backend)
+	}
+	return nil
+}
+
+// loadShadowLedger creates the chain of a shadow anchor backend: "hedera" from the
+// HEDERA_* settings, "registry" the Ethereum client's DID registry
+func loadShadowLedger(backend string, ethereum *blockchain.EthereumClient) (blockchain.Chain, error) {
+	if backend == "hedera" {
+		return blockchain.NewHederaClient(hederaConfig())
+	}
+	if ethereum == nil {
+		return nil, errors.New("the Ethereum client is offline")
+	}
+	return ethereum, nil
+}
+
+// loadSIEMShipper creates the SIEM shipper with a sink for each of SIEM_SYSLOG_ADDRESS,
+// SIEM_HTTP_URL and SIEM_KAFKA_REST_URL set; shipping is disabled when none is
+func loadSIEMShipper(logger zerolog.Logger) *siem.Shipper {
+	var sinks []siem.Sink
+	if address := os.Getenv("SIEM_SYSLOG_ADDRESS"); address != "" {
+		network := os.Getenv("SIEM_SYSLOG_NETWORK")
+		if network == "" {
+			network = "tcp"
+		}
+		sink, err := siem.NewSyslogSink(network, address, "did-manager")
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid SIEM syslog sink")
+		}
+		sinks = append(sinks, sink)
+	}
+	if url := os.Getenv("SIEM_HTTP_URL"); url != "" {
+		sinks = append(sinks, siem.NewHTTPSink(url, os.Getenv("SIEM_HTTP_AUTHORIZATION")))
+	}
+	if url := os.Getenv("SIEM_KAFKA_REST_URL"); url != "" {
+		topic := os.Getenv("SIEM_KAFKA_TOPIC")
+		if topic == "" {
+			topic = "did-manager-siem"
+		}
+		sinks = append(sinks, siem.NewKafkaSink(url, topic, os.Getenv("SIEM_KAFKA_AUTHORIZATION")))
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	source := "did-manager"
+	if hostname, err := os.Hostname(); err == nil {
+		source += "@" + hostname
+	}
+	shipper, err := siem.NewShipper(siem.Config{
+		Source:        source,
+		BufferSize:    getEnvInt("SIEM_BUFFER_SIZE", 10000),
+		BatchSize:     getEnvInt("SIEM_BATCH_SIZE", 100),
+		FlushInterval: getEnvDuration("SIEM_FLUSH_INTERVAL", 5*time.Second),
+		MaxRetries:    getEnvInt("SIEM_MAX_RETRIES", 3),
+		BlockTimeout:  getEnvDuration("SIEM_BLOCK_TIMEOUT", 0),
+	}, sinks...)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create SIEM shipper")
+	}
+
+	logger.Info().Int("sinks", len(sinks)).Msg("SIEM export enabled")
+	return shipper
+}
+
+// loadReportSigningKey loads the Ed25519 key signing compliance reports from its hex
+// seed; reports are disabled when none is configured
+func loadReportSigningKey(logger zerolog.Logger) ed25519.PrivateKey {
+	seedHex := os.Getenv("COMPLIANCE_REPORT_SIGNING_KEY")
+	if seedHex == "" {
+		return nil
+	}
+
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		logger.Fatal().Msg("COMPLIANCE_REPORT_SIGNING_KEY must be a hex-encoded 32-byte Ed25519 seed")
+	}
+
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// loadStatusTokenSigningKey loads the Ed25519 key signing status tokens from its hex
+// seed; status tokens are disabled when none is configured
+func loadStatusTokenSigningKey(logger zerolog.Logger) ed25519.PrivateKey {
+	seedHex := os.Getenv("STATUS_TOKEN_SIGNING_KEY")
+	if seedHex == "" {
+		return nil
+	}
+
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		logger.Fatal().Msg("STATUS_TOKEN_SIGNING_KEY must be a hex-encoded 32-byte Ed25519 seed")
+	}
+
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// loadTrustRegistrySigningKey loads the Ed25519 key signing trust registry snapshots
+// from its hex seed; snapshots are disabled when none is configured
+func loadTrustRegistrySigningKey(logger zerolog.Logger) ed25519.PrivateKey {
+	seedHex := os.Getenv("TRUST_REGISTRY_SIGNING_KEY")
+	if seedHex == "" {
+		return nil
+	}
+
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		logger.Fatal().Msg("TRUST_REGISTRY_SIGNING_KEY must be a hex-encoded 32-byte Ed25519 seed")
+	}
+
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// loadTrustRegistryTemplates parses the message templates of trust registry events,
+// overriding the defaults with the JSON object of templates by event type in
+// TRUST_REGISTRY_TEMPLATES_FILE
+func loadTrustRegistryTemplates(logger zerolog.Logger) map[string]*template.Template {
+	overrides := map[string]string{}
+	if path := os.Getenv("TRUST_REGISTRY_TEMPLATES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read TRUST_REGISTRY_TEMPLATES_FILE")
+		}
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			logger.Fatal().Err(err).Msg("TRUST_REGISTRY_TEMPLATES_FILE must be a JSON object of templates by event type")
+		}
+	}
+
+	templates, err := services.ParseTrustRegistryTemplates(overrides)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid trust registry templates")
+	}
+	return templates
+}
+
+// eventConsumer creates a component consuming the domain event stream with a durable
+// consumer; stopping it drains the subscription so in-flight events are acknowledged.
+// A standby has no event stream of its own, so it subscribes once promoted. An event
+// whose handler panics is reported and redelivered.
+func eventConsumer(name, durable string, queueClient *queue.NATSQueue, replication *services.ReplicationService, reporter errreport.Reporter, handle func(*queue.Event) error) lifecycle.Component {
+	handle = recoverEvents(name, reporter, handle)
+	var (
+		mu           sync.Mutex
+		subscription *nats.Subscription
+	)
+	subscribe := func() error {
+		sub, err := queueClient.SubscribeToEvents(durable, handle)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		subscription = sub
+		mu.Unlock()
+		return nil
+	}
+
+	return lifecycle.Component{
+		Name: name,
+		Start: func(context.Context) error {
+			if replication.ReadOnly() {
+				replication.OnPromote(subscribe)
+				return nil
 			}
+			return subscribe()
+		},
+		Stop: func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if subscription == nil {
+				return nil
+			}
+			return subscription.Drain()
+		},
+	}
+}
+
+// shipEvents returns an event handler shipping domain events to the SIEM
+func shipEvents(shipper *siem.Shipper) func(*queue.Event) error {
+	return func(event *queue.Event) error {
+		data := make(map[string]string, len(event.Data)+1)
+		for key, value := range event.Data {
+			data[key] = value
 		}
+		data["event_id"] = event.ID
+		shipper.Ship(&siem.Record{
+			Timestamp: event.OccurredAt.UTC(),
+			Category:  siem.CategoryEvent,
+			Action:    event.Type,
+			Outcome:   siem.OutcomeSuccess,
+			Severity:  siem.SeverityInfo,
+			Subject:   event.Subject,
+			Data:      data,
+		})
+		return nil
+	}
+}
+
+// recoverEvents wraps an event handler so a panic fails the event, to be redelivered,
+// instead of killing the process, and is reported with the event's ID
+func recoverEvents(name string, reporter errreport.Reporter, handle func(*queue.Event) error) func(*queue.Event) error {
+	return func(event *queue.Event) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				reporter.Report(errreport.Panic(value, map[string]string{
+					errreport.TagComponent: name,
+					errreport.TagEventID:   event.ID,
+				}))
+				err = fmt.Errorf("event handler panicked: %v", value)
+			}
+		}()
+		return handle(event)
+	}
+}
+
+// compressResponses compresses large text and JSON responses, such as DID Documents
+// and revocation proofs, with the codings in HTTP_COMPRESSION in order of preference:
+// gzip by default, none to serve responses uncompressed
+func compressResponses(next http.Handler, logger zerolog.Logger) http.Handler {
+	encodings := os.Getenv("HTTP_COMPRESSION")
+	if encodings == "" {
+		encodings = "gzip"
+	}
+	if encodings == "none" {
+		return next
+	}
+
+	compressor, err := compress.New(strings.Split(encodings, ","), getEnvInt("HTTP_COMPRESSION_MIN_SIZE", 1024))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid HTTP_COMPRESSION")
+	}
+	return compressor.Handler(next)
+}
+
+// httpProtocols returns the protocols served: HTTP/1.1 and, with HTTP2=true, HTTP/2
+// over cleartext (h2c) for load balancers and ingresses that terminate TLS and speak
+// HTTP/2 to the service
+func httpProtocols() *http.Protocols {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(os.Getenv("HTTP2") == "true")
+	return &protocols
+}
+
+// httpServer creates a component serving HTTP. The listener is bound during Start so
+// that a port already in use fails startup instead of the running server.
+func httpServer(srv *http.Server, shutdownTimeout time.Duration, logger zerolog.Logger) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "http",
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+					logger.Fatal().Err(err).Msg("HTTP server failed")
+				}
+			}()
+			return nil
+		},
+		Stop:        srv.Shutdown,
+		StopTimeout: shutdownTimeout,
 	}
 }
@@ -0,0 +1,443 @@
+// Command verifier serves the read path of the DID Manager: DID resolution, status and
+// verification, credential verification and the revocation accumulator. It runs against
+// a read replica of the database with read-only transactions, without a signing key,
+// queue or background workers, so the public verification surface can be scaled and
+// secured apart from the service that writes.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/handler"
+	"did-manager/internal/repository"
+	"did-manager/internal/security"
+	"did-manager/internal/services"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+	"did-manager/pkg/errreport"
+	"did-manager/pkg/lifecycle"
+	"did-manager/pkg/sidetree"
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/compress"
+	"did-manager/pkg/web/ginweb"
+	"did-manager/pkg/web/stdweb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	db, err := connectDB()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer db.Close()
+
+	// Initialize repositories. DID lookups are cached briefly, so a revocation is seen
+	// at most VERIFIER_CACHE_TTL after it reaches the replica.
+	didRepo := services.CacheDIDReads(repository.NewDIDRepository(db), getEnvDuration("VERIFIER_CACHE_TTL", 5*time.Second))
+	queueRepo := repository.NewBlockchainJobRepository(db)
+	credentialRepo := repository.NewCredentialRepository(db)
+	// API key use is recorded by the primary only
+	apiKeyRepo := readOnlyAPIKeys{repository.NewAPIKeyRepository(db)}
+	aclRepo := repository.NewACLRepository(db)
+
+	// Contract reads need no signing key; without a node, verification falls back to
+	// the replicated records as the verification policies allow
+	var ledger services.Ledger
+	blockchainClient, err := blockchain.NewReadOnlyClient(
+		os.Getenv("ETHEREUM_RPC_URL"),
+		os.Getenv("ETHEREUM_CONTRACT_ADDRESS"),
+	)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to initialize blockchain client, verifying against the database only")
+		ledger = services.OfflineLedger(err)
+	} else {
+		defer blockchainClient.Close()
+		ledger = blockchainClient
+	}
+	// DIDs anchored on Hedera are verified through the mirror node of the topic
+	if os.Getenv("ANCHOR_BACKEND") == "hedera" {
+		hederaClient, err := blockchain.NewHederaClient(blockchain.HederaConfig{
+			Network:   os.Getenv("HEDERA_NETWORK"),
+			MirrorURL: os.Getenv("HEDERA_MIRROR_URL"),
+			TopicID:   os.Getenv("HEDERA_TOPIC_ID"),
+		})
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to initialize Hedera client, verifying against the database only")
+			ledger = services.OfflineLedger(err)
+		} else {
+			ledger = hederaClient
+		}
+	}
+
+	// Panics recovered in handlers and workers are logged with their stack, and sent to
+	// Sentry, or a tracker compatible with it, when SENTRY_DSN is set
+	errorReporter := loadErrorReporter(logger)
+
+	// Expirations, such as those of credentials and status tokens, retries and periodic
+	// jobs read this clock; CLOCK_OFFSET shifts it to simulate a later (or, when
+	// negative, earlier) time
+	clockOffset := getEnvDuration("CLOCK_OFFSET", 0)
+	if clockOffset != 0 {
+		logger.Warn().Str("offset", clockOffset.String()).Msg("Clock shifted by CLOCK_OFFSET; do not run this in production")
+	}
+	appClock := clock.Offset(clock.System, clockOffset)
+	queueRepo.SetClock(appClock)
+
+	peppers, err := did.ParsePeppers(os.Getenv("DID_ID_PEPPERS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID_ID_PEPPERS")
+	}
+	didGen, err := did.NewGeneratorWithConfig(did.GeneratorConfig{
+		Pepper:                []byte(os.Getenv("DID_ID_PEPPER")),
+		Peppers:               peppers,
+		PepperVersion:         os.Getenv("DID_ID_PEPPER_VERSION"),
+		PreviousPepperVersion: os.Getenv("DID_ID_PEPPER_PREVIOUS_VERSION"),
+		HashScheme:            os.Getenv("USER_HASH_SCHEME"),
+		SignatureSuite:        os.Getenv("DID_SIGNATURE_SUITE"),
+		Clock:                 appClock,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID generator configuration")
+	}
+
+	// Initialize services. No queue is configured: the verifier never enqueues jobs.
+	didService := services.NewDIDService(didRepo, queueRepo, didGen, ledger, services.OfflineQueue(blockchain.ErrReadOnly), nil, nil)
+	didService.SetClock(appClock)
+	didService.SetClaimsAttemptLimit(getEnvInt("CLAIMS_ATTEMPT_RATE_LIMIT", 5), getEnvInt("CLAIMS_ATTEMPT_RATE_BURST", 5))
+	verificationFallback, err := services.ParseVerificationFallback(os.Getenv("VERIFICATION_FALLBACK"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid VERIFICATION_FALLBACK")
+	}
+	didService.SetVerificationPolicies(services.NewVerificationPolicyService(repository.NewVerificationPolicyRepository(db), verificationFallback))
+	if os.Getenv("ANCHOR_BACKEND") == "sidetree" {
+		didService.SetSidetreeBatching(repository.NewSidetreeRepository(db))
+	}
+	accessService := services.NewAccessService(apiKeyRepo, aclRepo, didRepo, didGen.Registry())
+	accessService.SetClock(appClock)
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+	localeService, err := services.NewLocaleService(apiKeyRepo, defaultLocale)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DEFAULT_LOCALE")
+	}
+	resolverService := services.NewResolverService(accessService, didGen.Registry())
+	keyFormat, err := did.ParseKeyFormat(os.Getenv("DID_DOCUMENT_KEY_FORMAT"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid DID_DOCUMENT_KEY_FORMAT")
+	}
+	resolverService.SetKeyFormat(keyFormat)
+	deviceKeyService := services.NewDeviceKeyService(repository.NewDeviceKeyRepository(db), didRepo, didGen.Registry(), nil)
+	accessService.SetDeviceKeys(deviceKeyService)
+	resolverService.SetDeviceKeys(deviceKeyService)
+	// Service clients of auth-service call as their tenant with tokens signed with the
+	// shared SERVICE_TOKEN_SECRET, limited to the operations of their scopes
+	if secret := os.Getenv("SERVICE_TOKEN_SECRET"); secret != "" {
+		accessService.SetServiceTokens(security.NewServiceTokenVerifier(secret))
+	}
+	// Services invoke UCANs at this deployment's DID, rooted at a tenant's root DID and
+	// verified with keys resolved here
+	if audience := os.Getenv("UCAN_AUDIENCE"); audience != "" {
+		accessService.SetUCANs(resolverService, audience)
+	}
+	credentialService := services.NewCredentialService(credentialRepo, didRepo, didGen.Registry(), nil)
+	credentialService.SetClock(appClock)
+	purposeKeyService := services.NewPurposeKeyService(repository.NewPurposeKeyRepository(db), didGen)
+	resolverService.SetPurposeKeys(purposeKeyService)
+	credentialService.SetPurposeKeys(purposeKeyService)
+	resolverService.SetClaims(repository.NewClaimRepository(db))
+	if url := os.Getenv("ION_RESOLVER_URL"); url != "" {
+		ionResolver := sidetree.NewResolver(url)
+		resolverService.SetIONResolver(ionResolver)
+		credentialService.SetIONResolver(ionResolver)
+	}
+	revocationService := services.NewRevocationService(credentialRepo, repository.NewRevocationBatchRepository(db), nil)
+
+	// Initialize resolution protections
+	resolutionGuard := handler.ResolutionGuard(
+		security.NewRateLimiter(
+			getEnvInt("RESOLUTION_RATE_LIMIT", 60),
+			getEnvInt("RESOLUTION_RATE_BURST", 20),
+		),
+		nil,
+		security.NewEnumerationMonitor(
+			getEnvInt("ENUMERATION_ALERT_THRESHOLD", 20),
+			getEnvDuration("ENUMERATION_ALERT_WINDOW", time.Minute),
+		),
+	)
+
+	// Initialize handlers. Verifications are not recorded for revocation propagation or
+	// usage metering, which both write. Without challenges, only authenticated callers
+	// check claims.
+	didHandler := handler.NewDIDHandler(didService, accessService, localeService, resolutionGuard, nil)
+	credentialHandler := handler.NewCredentialHandler(credentialService, nil)
+
+	lifecycleManager := lifecycle.NewManager(
+		getEnvDuration("COMPONENT_START_TIMEOUT", 10*time.Second),
+		getEnvDuration("COMPONENT_STOP_TIMEOUT", 30*time.Second),
+	)
+	lifecycleManager.SetPanicHandler(func(component string, value any) {
+		errorReporter.Report(errreport.Panic(value, map[string]string{errreport.TagComponent: component}))
+	})
+
+	router, routerHandler := newRouter(os.Getenv("HTTP_ROUTER"), logger)
+	router.Use(handler.RequestID())
+	router.Use(handler.Recover(errorReporter))
+	router.Use(handler.Authenticate(accessService))
+	router.Use(handler.EnforceScopes())
+	// Reads presenting the consistency token of a DID just created wait for the
+	// replica to replay it
+	router.Use(handler.ReadYourWrites(services.NewConsistencyService(
+		repository.NewConsistencyRepository(db, ""),
+		didRepo,
+		getEnvDuration("CONSISTENCY_MAX_WAIT", 2*time.Second),
+	)))
+
+	// Register only the routes that do not write
+	web.Mount(router,
+		web.RoutesFunc(didHandler.RegisterReadRoutes),
+		handler.NewResolverHandler(resolverService, resolutionGuard),
+		web.RoutesFunc(credentialHandler.RegisterReadRoutes),
+		handler.NewRevocationHandler(revocationService),
+		handler.NewReadinessHandler(lifecycleManager),
+	)
+
+	// Drop cached DIDs as soon as the registry emits DIDUpdated or DIDRevoked for them,
+	// instead of waiting for VERIFIER_CACHE_TTL, and stream the events to relying parties
+	if blockchainClient != nil {
+		chainEventService := services.NewChainEventService(blockchainClient, didRepo, nil, accessService)
+		web.Mount(router, handler.NewChainEventHandler(chainEventService))
+		lifecycleManager.Add(lifecycle.PeriodicWithClock("chain-events", appClock, getEnvDuration("CHAIN_EVENT_POLL_INTERVAL", 5*time.Second), func(context.Context) {
+			if err := chainEventService.Poll(); err != nil {
+				logger.Error().Err(err).Msg("Failed to read chain events")
+			}
+		}))
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8083"
+	}
+	lifecycleManager.Add(httpServer(&http.Server{
+		Addr:      ":" + port,
+		Handler:   compressResponses(routerHandler, logger),
+		Protocols: httpProtocols(),
+	}, getEnvDuration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second), logger))
+
+	if err := lifecycleManager.Start(context.Background()); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to start verifier")
+	}
+	logger.Info().Msgf("Verifier ready on port %s", port)
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info().Msg("Shutting down verifier...")
+
+	if err := lifecycleManager.Stop(context.Background()); err != nil {
+		logger.Error().Err(err).Msg("Components failed to stop cleanly")
+	}
+
+	logger.Info().Msg("Verifier exited")
+}
+
+// readOnlyAPIKeys authenticates API keys without recording their use, which would write
+type readOnlyAPIKeys struct {
+	domain.APIKeyRepository
+}
+
+// TouchLastUsed records nothing
+func (readOnlyAPIKeys) TouchLastUsed(uuid.UUID) error {
+	return nil
+}
+
+// connectDB connects to the read replica given by VERIFIER_DB_HOST and VERIFIER_DB_PORT,
+// or to DB_HOST and DB_PORT. Every transaction is read-only, so even a primary cannot
+// be written through the verifier.
+func connectDB() (*sql.DB, error) {
+	host := os.Getenv("VERIFIER_DB_HOST")
+	if host == "" {
+		host = os.Getenv("DB_HOST")
+	}
+	port := os.Getenv("VERIFIER_DB_PORT")
+	if port == "" {
+		port = os.Getenv("DB_PORT")
+	}
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s default_transaction_read_only=on",
+		host,
+		port,
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_SSLMODE"),
+	)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	db.SetMaxOpenConns(getEnvInt("VERIFIER_DB_MAX_CONNS", 50))
+	db.SetMaxIdleConns(getEnvInt("VERIFIER_DB_MAX_CONNS", 50))
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	return db, nil
+}
+
+// getEnvInt reads an integer environment variable, falling back to defaultValue
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+
+	return parsed
+}
+
+// getEnvDuration reads a duration environment variable, falling back to defaultValue
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using default %s", key, defaultValue)
+		return defaultValue
+	}
+
+	return parsed
+}
+
+// loadErrorReporter creates the reporter of errors and recovered panics: the log, plus
+// the Sentry project of SENTRY_DSN when set
+func loadErrorReporter(logger zerolog.Logger) errreport.Reporter {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return errreport.Log
+	}
+
+	environment := os.Getenv("SENTRY_ENVIRONMENT")
+	if environment == "" {
+		environment = os.Getenv("ENV")
+	}
+	sentry, err := errreport.NewSentry(dsn, environment)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid SENTRY_DSN")
+	}
+
+	logger.Info().Str("environment", environment).Msg("Error reporting to Sentry enabled")
+	return errreport.Multi(errreport.Log, sentry)
+}
+
+// newRouter creates the router serving the API: Gin by default, or the standard
+// library's http.ServeMux with "stdlib". It returns the handler to serve it with.
+func newRouter(name string, logger zerolog.Logger) (web.Router, http.Handler) {
+	switch name {
+	case "", "gin":
+		engine := gin.New()
+		engine.Use(gin.Recovery())
+		return ginweb.New(engine), engine
+	case "stdlib":
+		router := stdweb.New()
+		return router, router
+	default:
+		logger.Fatal().Msgf("Unknown HTTP_ROUTER %q, expected gin or stdlib", name)
+		return nil, nil
+	}
+}
+
+// compressResponses compresses large text and JSON responses, such as DID Documents
+// and revocation proofs, with the codings in HTTP_COMPRESSION in order of preference:
+// gzip by default, none to serve responses uncompressed
+func compressResponses(next http.Handler, logger zerolog.Logger) http.Handler {
+	encodings := os.Getenv("HTTP_COMPRESSION")
+	if encodings == "" {
+		encodings = "gzip"
+	}
+	if encodings == "none" {
+		return next
+	}
+
+	compressor, err := compress.New(strings.Split(encodings, ","), getEnvInt("HTTP_COMPRESSION_MIN_SIZE", 1024))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid HTTP_COMPRESSION")
+	}
+	return compressor.Handler(next)
+}
+
+// httpProtocols returns the protocols served: HTTP/1.1 and, with HTTP2=true, HTTP/2
+// over cleartext (h2c) for load balancers and ingresses that terminate TLS and speak
+// HTTP/2 to the service
+func httpProtocols() *http.Protocols {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(os.Getenv("HTTP2") == "true")
+	return &protocols
+}
+
+// httpServer creates a component serving HTTP. The listener is bound during Start so
+// that a port already in use fails startup instead of the running server.
+func httpServer(srv *http.Server, shutdownTimeout time.Duration, logger zerolog.Logger) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "http",
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Fatal().Err(err).Msg("HTTP server failed")
+				}
+			}()
+			return nil
+		},
+		Stop:        srv.Shutdown,
+		StopTimeout: shutdownTimeout,
+	}
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DIDVisibility controls who may resolve a DID document
+type DIDVisibility string
+
+const (
+	DIDVisibilityPublic  DIDVisibility = "public"
+	DIDVisibilityPrivate DIDVisibility = "private"
+)
+
+// CallerType identifies how the caller of a request authenticated
+type CallerType string
+
+const (
+	CallerTypeAnonymous CallerType = "anonymous"
+	CallerTypeAPIKey    CallerType = "api_key"
+	CallerTypeDID       CallerType = "did"
+)
+
+// Caller represents the party making a request
+type Caller struct {
+	Type CallerType `json:"type"`
+	ID   string     `json:"id"` // API key ID or DID string
+	// SandboxTenant is the sandbox partition the caller works in: the ID of a sandbox
+	// API key, or the partition of a sandbox DID. Empty for production callers.
+	SandboxTenant string `json:"sandbox_tenant,omitempty"`
+	// KeyID is the sub-key an API key caller authenticated with; ID is then the
+	// sub-key's parent, the tenant. Empty otherwise.
+	KeyID string `json:"key_id,omitempty"`
+	// Client is the auth-service client of a caller authenticated with a service
+	// token, acting as the API key caller's tenant. Empty otherwise.
+	Client string `json:"client,omitempty"`
+	// Scopes restrict a service token caller to the operations they grant; nil for
+	// callers with every operation of their type
+	Scopes []string `json:"scopes,omitempty"`
+	// Resources grants a scoped caller further scopes on single DIDs only, by DID
+	Resources map[string][]string `json:"resources,omitempty"`
+}
+
+// AnonymousCaller is used for requests without credentials
+var AnonymousCaller = &Caller{Type: CallerTypeAnonymous}
+
+// IsAnonymous reports whether the caller presented no credentials
+func (c *Caller) IsAnonymous() bool {
+	return c == nil || c.Type == CallerTypeAnonymous
+}
+
+// Scoped reports whether the caller is restricted to the operations of its scopes
+func (c *Caller) Scoped() bool {
+	return c != nil && c.Scopes != nil
+}
+
+// Allows reports whether the caller may perform an operation needing scope
+func (c *Caller) Allows(scope string) bool {
+	if !c.Scoped() {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOn reports whether the caller may perform an operation needing scope on a
+// resource, the DID the operation acts on or empty for none
+func (c *Caller) AllowsOn(scope, resource string) bool {
+	if c.Allows(scope) {
+		return true
+	}
+	if resource == "" {
+		return false
+	}
+	for _, s := range c.Resources[resource] {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey represents a relying party API key
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Status     string     `json:"status" db:"status"` // active, revoked
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	// Sandbox keys work in their own sandbox partition: their DIDs are isolated from
+	// production, anchored by simulation and can be reset
+	Sandbox bool `json:"sandbox" db:"sandbox"`
+	// ParentID is the tenant key that issued a sub-key. Sub-keys act as their parent,
+	// with their own cost budget, and stop working when the parent is revoked.
+	ParentID *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	// RootDID is the DID holding the tenant's root capability: UCANs rooted at it call
+	// as the tenant. Empty when the tenant does not use UCANs.
+	RootDID string `json:"root_did,omitempty" db:"root_did"`
+	// DefaultLocale is the locale of messages shown to the tenant's end users when their
+	// requests accept none; empty for the server's default
+	DefaultLocale string `json:"default_locale,omitempty" db:"default_locale"`
+}
+
+// APIKeyStatus represents the current status of an API key
+type APIKeyStatus string
+
+const (
+	APIKeyStatusActive  APIKeyStatus = "active"
+	APIKeyStatusRevoked APIKeyStatus = "revoked"
+)
+
+// APIKeyCreateRequest represents a request to issue a new API key
+type APIKeyCreateRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Sandbox bool   `json:"sandbox"`
+}
+
+// APIKeyCreateResponse carries the plaintext key, which is only returned once
+type APIKeyCreateResponse struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+// APIKeyRootDIDRequest represents a request to bind a tenant's root capability to a DID
+type APIKeyRootDIDRequest struct {
+	DID string `json:"did" binding:"required"`
+}
+
+// APIKeyLocaleRequest represents a request to set a tenant's default locale
+type APIKeyLocaleRequest struct {
+	Locale string `json:"locale" binding:"required,max=35"`
+}
+
+// ACLEntry grants a caller permission to resolve a private DID
+type ACLEntry struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	DIDID       uuid.UUID `json:"did_id" db:"did_id"`
+	GranteeType string    `json:"grantee_type" db:"grantee_type"` // api_key, did
+	Grantee     string    `json:"grantee" db:"grantee"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// ACLGrantRequest represents a request to grant resolution access to a DID
+type ACLGrantRequest struct {
+	DID         string `json:"did" binding:"required"`
+	GranteeType string `json:"grantee_type" binding:"required,oneof=api_key did"`
+	Grantee     string `json:"grantee" binding:"required"`
+}
+
+// DIDVisibilityRequest represents a request to change the visibility of a DID
+type DIDVisibilityRequest struct {
+	DID        string `json:"did" binding:"required"`
+	Visibility string `json:"visibility" binding:"required,oneof=public private"`
+}
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	GetByHash(keyHash string) (*APIKey, error)
+	GetByID(id uuid.UUID) (*APIKey, error)
+	List() ([]*APIKey, error)
+	// ListByParent lists the sub-keys a tenant key issued
+	ListByParent(parentID uuid.UUID) ([]*APIKey, error)
+	// Revoke revokes a key along with its sub-keys
+	Revoke(id uuid.UUID) error
+	// Rotate replaces the prefix and hash of an active key, returning ErrAPIKeyNotFound
+	// for unknown or revoked keys
+	Rotate(id uuid.UUID, prefix, keyHash string) (*APIKey, error)
+	// GetByRootDID retrieves the tenant key whose root capability a DID holds
+	GetByRootDID(rootDID string) (*APIKey, error)
+	// SetRootDID binds the root capability of an active tenant key to a DID, or unbinds
+	// it when rootDID is empty, returning ErrAPIKeyNotFound for unknown or revoked keys
+	SetRootDID(id uuid.UUID, rootDID string) (*APIKey, error)
+	// SetDefaultLocale sets the default locale of an active tenant key, or clears it when
+	// locale is empty, returning ErrAPIKeyNotFound for unknown or revoked keys
+	SetDefaultLocale(id uuid.UUID, locale string) (*APIKey, error)
+	TouchLastUsed(id uuid.UUID) error
+}
+
+// ACLRepository defines the interface for DID access control list operations
+type ACLRepository interface {
+	Create(entry *ACLEntry) error
+	Delete(id uuid.UUID) error
+	ListByDIDID(didID uuid.UUID) ([]*ACLEntry, error)
+	Exists(didID uuid.UUID, granteeType, grantee string) (bool, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+// PasswordChangeReason tells how a user's account password changed
+type PasswordChangeReason string
+
+const (
+	// PasswordChanged is a change by the signed-in user
+	PasswordChanged PasswordChangeReason = "change"
+	// PasswordReset is a reset through a single-use reset token
+	PasswordReset PasswordChangeReason = "reset"
+)
+
+// PasswordChangeRequest reports a change of a user's account password, sent by the
+// auth service. DID commitments cover the holder's name and email but not the password,
+// so the DIDs are unchanged; the change is recorded on them as an event.
+type PasswordChangeRequest struct {
+	Reason PasswordChangeReason `json:"reason" binding:"required,oneof=change reset"`
+}
+
+// PasswordChangeResponse lists the DIDs a password change was recorded on
+type PasswordChangeResponse struct {
+	DIDs []string `json:"dids"`
+}
+
+// SuspensionRequest suspends a user's DIDs, sent by the auth service when a sign-in
+// looks compromised or by an administrator
+type SuspensionRequest struct {
+	// Reason is recorded in the audit log, e.g. the anomaly that was detected
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// SuspensionResponse lists the DIDs a suspension or reinstatement changed
+type SuspensionResponse struct {
+	DIDs []string `json:"dids"`
+}
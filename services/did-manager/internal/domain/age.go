@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"did-manager/pkg/did"
+)
+
+// Age credential formats. BBS credentials yield unlinkable proofs that hide the
+// holder; SD-JWT credentials are widely supported and bind proofs to the holder's key.
+const (
+	AgeCredentialFormatBBS   = "bbs"
+	AgeCredentialFormatSDJWT = "sd-jwt"
+)
+
+// DefaultAgeThreshold is attested when an age credential request names no thresholds
+const DefaultAgeThreshold = 18
+
+// AgeCredentialIssueRequest represents a request by an issuer DID to attest a
+// subject's age. The birthdate is signed as its own claim next to an age_over_N claim
+// per threshold, evaluated at issuance.
+type AgeCredentialIssueRequest struct {
+	SubjectDID string     `json:"subject_did" binding:"required"`
+	Birthdate  string     `json:"birthdate" binding:"required,datetime=2006-01-02"`
+	Format     string     `json:"format" binding:"required,oneof=bbs sd-jwt"`
+	Thresholds []int      `json:"thresholds" binding:"max=10,dive,min=1,max=150"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Tenant     string     `json:"tenant" binding:"max=255"`
+}
+
+// AgeCredentialDocument is the stored document of an AgeOver credential: a BBS-signed
+// credential or an SD-JWT carrying every disclosure
+type AgeCredentialDocument struct {
+	Format string                   `json:"format"`
+	BBS    *did.SelectiveCredential `json:"bbs,omitempty"`
+	SDJWT  string                   `json:"sd_jwt,omitempty"`
+}
+
+// AgeProofRequest represents a holder's request to prove from an age credential that
+// it is over an age. Nonce and Audience are supplied by the verifier to prevent replay.
+type AgeProofRequest struct {
+	Threshold int    `json:"threshold" binding:"required,min=1,max=150"`
+	Nonce     string `json:"nonce" binding:"max=256"`
+	Audience  string `json:"audience" binding:"max=2048"`
+}
+
+// AgeProof proves that a credential's subject is over Threshold years old without
+// revealing its birthdate: a BBS selective disclosure or an SD-JWT presentation
+type AgeProof struct {
+	Format    string                   `json:"format" binding:"required,oneof=bbs sd-jwt"`
+	Threshold int                      `json:"threshold" binding:"required,min=1,max=150"`
+	BBS       *did.SelectiveDisclosure `json:"bbs,omitempty"`
+	SDJWT     string                   `json:"sd_jwt,omitempty"`
+}
+
+// AgeProofVerifyRequest represents a verifier's request to check an age proof against
+// the nonce and audience it handed out
+type AgeProofVerifyRequest struct {
+	AgeProof
+	Nonce    string `json:"nonce"`
+	Audience string `json:"audience"`
+}
+
+// AgeProofVerifyResponse reports the outcome of age proof verification. Disclosed lists
+// every claim the proof revealed.
+type AgeProofVerifyResponse struct {
+	Valid     bool     `json:"valid"`
+	Issuer    string   `json:"issuer,omitempty"`
+	Threshold int      `json:"threshold"`
+	Disclosed []string `json:"disclosed,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	Message   string   `json:"message"`
+	// CredentialID is the ID of the proven credential, recorded for revocation
+	// propagation
+	CredentialID string `json:"-"`
+}
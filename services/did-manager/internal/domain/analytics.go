@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalyticsEvent is a DID creation, DID verification or credential issuance exported
+// to the analytics bucket, read from the usage events
+type AnalyticsEvent struct {
+	ID         int64          `json:"id"`
+	Tenant     string         `json:"tenant"`
+	Event      UsageEventType `json:"event"`
+	Subject    string         `json:"subject"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// AnalyticsExport records a run of the analytics exporter: the usage events from
+// FirstEventID to LastEventID, written as Files Parquet files partitioned by day and
+// tenant
+type AnalyticsExport struct {
+	ID           uuid.UUID `json:"id"`
+	FirstEventID int64     `json:"first_event_id"`
+	LastEventID  int64     `json:"last_event_id"`
+	Events       int       `json:"events"`
+	Files        int       `json:"files"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AnalyticsExportRepository defines the interface for analytics export data operations
+type AnalyticsExportRepository interface {
+	// LastEventID returns the ID of the last exported usage event, or 0
+	LastEventID() (int64, error)
+	// ListEventsAfter lists up to limit usage events with IDs above afterID, in ID order
+	ListEventsAfter(afterID int64, limit int) ([]*AnalyticsEvent, error)
+	Create(export *AnalyticsExport) error
+	// List retrieves the most recent exports, newest first
+	List(limit int) ([]*AnalyticsExport, error)
+}
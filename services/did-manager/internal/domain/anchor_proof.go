@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Chain of the anchor proofs of sandbox DIDs, whose anchoring is simulated
+const AnchorChainSandbox = "sandbox"
+
+// AnchorProof proves that a DID is anchored, in the same shape whichever backend
+// anchored it, so verifiers and auditors can check it against the chain themselves
+type AnchorProof struct {
+	DID string `json:"did"`
+	// Chain is the chain family, e.g. ethereum or hedera, and Network the chain within
+	// it: the chain ID of an EVM chain or the name of a Hedera network
+	Chain   string `json:"chain"`
+	Network string `json:"network,omitempty"`
+	// Anchor is the contract or topic the transaction wrote to
+	Anchor string `json:"anchor,omitempty"`
+	// TransactionID is the transaction hash, or the Hedera transaction ID of the topic
+	// message
+	TransactionID string `json:"transaction_id"`
+	// BlockNumber is left out on chains without blocks
+	BlockNumber   uint64     `json:"block_number,omitempty"`
+	Timestamp     *time.Time `json:"timestamp,omitempty"`
+	Confirmations uint64     `json:"confirmations"`
+	// Inclusion proves the DID's latest operation is part of a batch the transaction
+	// anchored; nil when the transaction carried the operation itself
+	Inclusion *AnchorInclusion `json:"inclusion,omitempty"`
+	// Simulated is set for sandbox DIDs, which are never on-chain
+	Simulated bool `json:"simulated,omitempty"`
+}
+
+// AnchorInclusion proves an operation is part of an anchored batch: it is listed at
+// OperationIndex of the core index file at CoreIndexFileURI, whose SHA-256
+// AnchorFileHash is what the transaction wrote on-chain
+type AnchorInclusion struct {
+	Operation        string `json:"operation"`
+	OperationIndex   int    `json:"operation_index"`
+	OperationCount   int    `json:"operation_count"`
+	AnchorString     string `json:"anchor_string"`
+	CoreIndexFileURI string `json:"core_index_file_uri"`
+	ChunkFileURI     string `json:"chunk_file_uri"`
+	AnchorFileHash   string `json:"anchor_file_hash"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// AnchoringPause is the state of the operator switch pausing anchoring, e.g. during a
+// gas price spike or a contract incident. While paused, DIDs and credentials are still
+// accepted and their blockchain jobs queued, but none is submitted on-chain.
+type AnchoringPause struct {
+	Paused   bool       `json:"paused"`
+	Reason   string     `json:"reason,omitempty"`
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	// PendingJobs counts the blockchain jobs not yet anchored
+	PendingJobs int `json:"pending_jobs"`
+}
+
+// AnchoringPauseRequest represents a request to pause anchoring, or to update the
+// reason of a pause
+type AnchoringPauseRequest struct {
+	Reason string `json:"reason" binding:"required,max=280"`
+}
+
+// AnchoringPauseRepository defines the interface for anchoring pause data operations
+type AnchoringPauseRepository interface {
+	// Get retrieves the pause, or nil while anchoring runs
+	Get() (*AnchoringPause, error)
+	// Save pauses anchoring, or updates the reason of a pause keeping its start
+	Save(pause *AnchoringPause) error
+	// Delete resumes anchoring, returning ErrAnchoringNotPaused when it is not paused
+	Delete() error
+}
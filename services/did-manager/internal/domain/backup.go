@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupFormat identifies the layout of backup snapshots
+const BackupFormat = "did-manager-backup/v1"
+
+// Backup records an encrypted snapshot of the identity tables stored in the backup
+// bucket
+type Backup struct {
+	ID uuid.UUID `json:"id"`
+	// ObjectName is the name of the snapshot in the backup bucket
+	ObjectName string `json:"object_name"`
+	// KeyID names the key the snapshot is encrypted with
+	KeyID       string    `json:"-"`
+	Size        int64     `json:"size"`
+	DIDs        int       `json:"dids"`
+	Credentials int       `json:"credentials"`
+	Jobs        int       `json:"jobs"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BackupSnapshot is the content of a backup: the rows of the dids, credentials and
+// blockchain_jobs tables as JSON objects keyed by column, and the tenant that created
+// each DID, keyed by DID string
+type BackupSnapshot struct {
+	Format      string            `json:"format"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DIDs        []json.RawMessage `json:"dids"`
+	Credentials []json.RawMessage `json:"credentials"`
+	Jobs        []json.RawMessage `json:"blockchain_jobs"`
+	DIDTenants  map[string]string `json:"did_tenants"`
+}
+
+// BackupRestoreRequest selects the tenant whose DIDs are restored from a backup,
+// with their credentials and blockchain jobs. A dry run only counts the rows.
+type BackupRestoreRequest struct {
+	Tenant string `json:"tenant" binding:"required,max=255"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// BackupRestoreResult counts the rows of a tenant found in a backup and those
+// restored. Rows still present are kept as they are, so only missing rows are restored.
+type BackupRestoreResult struct {
+	BackupID            uuid.UUID `json:"backup_id"`
+	Tenant              string    `json:"tenant"`
+	DryRun              bool      `json:"dry_run"`
+	DIDs                int       `json:"dids"`
+	Credentials         int       `json:"credentials"`
+	Jobs                int       `json:"jobs"`
+	RestoredDIDs        int       `json:"restored_dids"`
+	RestoredCredentials int       `json:"restored_credentials"`
+	RestoredJobs        int       `json:"restored_jobs"`
+}
+
+// BackupRepository defines the interface for backup data operations
+type BackupRepository interface {
+	// Snapshot reads the identity tables in one consistent transaction
+	Snapshot() (*BackupSnapshot, error)
+	// Restore inserts the rows of a snapshot that are missing, in one transaction,
+	// returning the number of DIDs, credentials and jobs inserted
+	Restore(snapshot *BackupSnapshot) (int, int, int, error)
+	Create(backup *Backup) error
+	GetByID(id uuid.UUID) (*Backup, error)
+	// List lists backups, newest first
+	List(limit int) ([]*Backup, error)
+	// ListBefore lists the backups created before the given time
+	ListBefore(before time.Time) ([]*Backup, error)
+	Delete(id uuid.UUID) error
+}
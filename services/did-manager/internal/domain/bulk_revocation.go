@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkRevocationStatus represents the stage of a bulk revocation
+type BulkRevocationStatus string
+
+const (
+	// BulkRevocationStaged revocations have their targets resolved and can still be
+	// canceled; nothing is revoked until they are executed
+	BulkRevocationStaged BulkRevocationStatus = "staged"
+	// BulkRevocationExecuting revocations are revoking their targets and can no longer
+	// be canceled
+	BulkRevocationExecuting BulkRevocationStatus = "executing"
+	BulkRevocationCompleted BulkRevocationStatus = "completed"
+	BulkRevocationCanceled  BulkRevocationStatus = "canceled"
+)
+
+// BulkRevocationTarget is the kind of a bulk revocation's target
+type BulkRevocationTarget string
+
+const (
+	// BulkRevocationTargetDID targets are deactivated with a revoke_did job each; these
+	// are the revocation's chain writes
+	BulkRevocationTargetDID BulkRevocationTarget = "did"
+	// BulkRevocationTargetCredential targets are revoked off-chain and reach the
+	// revocation accumulator with its next batch
+	BulkRevocationTargetCredential BulkRevocationTarget = "credential"
+)
+
+// BulkRevocationItemStatus represents the outcome of revoking a single target
+type BulkRevocationItemStatus string
+
+const (
+	BulkRevocationItemPending BulkRevocationItemStatus = "pending"
+	BulkRevocationItemRevoked BulkRevocationItemStatus = "revoked"
+	// BulkRevocationItemSkipped targets were already revoked, or no longer exist
+	BulkRevocationItemSkipped BulkRevocationItemStatus = "skipped"
+	BulkRevocationItemFailed  BulkRevocationItemStatus = "failed"
+)
+
+// BulkRevocation revokes many DIDs or credentials at once, e.g. when terminating a whole
+// organization, as a staged job group: it is created staged with its targets resolved,
+// then executed by the bulk revocation worker in rate-limited chunks. Counts report its
+// progress.
+type BulkRevocation struct {
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+	Status string    `json:"status"`
+	// Total counts the revocation's targets; Pending those not yet processed
+	Total       int        `json:"total"`
+	Pending     int        `json:"pending"`
+	Revoked     int        `json:"revoked"`
+	Skipped     int        `json:"skipped"`
+	Failed      int        `json:"failed"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExecutedAt  *time.Time `json:"executed_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CanceledAt  *time.Time `json:"canceled_at,omitempty"`
+}
+
+// BulkRevocationItem is a single target of a bulk revocation
+type BulkRevocationItem struct {
+	RevocationID uuid.UUID `json:"-"`
+	TargetType   string    `json:"target_type"`
+	// Target is the DID or the credential ID
+	Target string `json:"target"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// JobID is the revoke_did job anchoring a DID target's deactivation
+	JobID       *uuid.UUID `json:"job_id,omitempty"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// BulkRevocationDetail is a bulk revocation with its failed targets
+type BulkRevocationDetail struct {
+	*BulkRevocation
+	Failures []*BulkRevocationItem `json:"failures"`
+}
+
+// DIDBulkRevocationRequest requests the deactivation of many DIDs, optionally with
+// every active credential they issued
+type DIDBulkRevocationRequest struct {
+	DIDs   []string `json:"dids" binding:"required,min=1,max=10000,dive,required"`
+	Reason string   `json:"reason" binding:"required,max=500"`
+	// RevokeIssuedCredentials also revokes the active credentials the DIDs issued
+	RevokeIssuedCredentials bool `json:"revoke_issued_credentials"`
+}
+
+// CredentialBulkRevocationRequest requests the revocation of many credentials, listed
+// by ID or selected as every active credential of an issuer
+type CredentialBulkRevocationRequest struct {
+	CredentialIDs []uuid.UUID `json:"credential_ids" binding:"required_without=IssuerDID,max=10000"`
+	IssuerDID     string      `json:"issuer_did" binding:"required_without=CredentialIDs"`
+	Reason        string      `json:"reason" binding:"required,max=500"`
+}
+
+// BulkRevocationRepository defines the interface for bulk revocation data operations
+type BulkRevocationRepository interface {
+	// Create stores a staged revocation with its targets
+	Create(revocation *BulkRevocation, items []*BulkRevocationItem) error
+	// Get returns a revocation with its progress counts, or ErrBulkRevocationNotFound
+	Get(id uuid.UUID) (*BulkRevocation, error)
+	// List returns the most recent revocations with their progress counts
+	List(limit int) ([]*BulkRevocation, error)
+	// ListByStatus returns the revocations in a stage, oldest first
+	ListByStatus(status BulkRevocationStatus) ([]*BulkRevocation, error)
+	// Transition moves a revocation from one stage to another, returning
+	// ErrBulkRevocationState when it is no longer in the expected stage
+	Transition(id uuid.UUID, from, to BulkRevocationStatus, at time.Time) error
+	// ListPendingItems returns up to limit unprocessed targets of a kind
+	ListPendingItems(id uuid.UUID, targetType BulkRevocationTarget, limit int) ([]*BulkRevocationItem, error)
+	// ListFailedItems returns the targets that failed to be revoked
+	ListFailedItems(id uuid.UUID) ([]*BulkRevocationItem, error)
+	// RecordItem records the outcome of revoking a target
+	RecordItem(item *BulkRevocationItem) error
+}
@@ -0,0 +1,29 @@
+package domain
+
+// Capability is an external dependency the service can run without
+type Capability string
+
+const (
+	// CapabilityBlockchain submits and verifies DID transactions on-chain
+	CapabilityBlockchain Capability = "blockchain"
+	// CapabilityQueue publishes blockchain jobs to NATS; without it, jobs are only
+	// picked up from the database
+	CapabilityQueue Capability = "queue"
+	// CapabilityConformance serves the resolver and registrar in the shapes the W3C DID
+	// test suites and the DIF interop suites expect; see CONFORMANCE_MODE
+	CapabilityConformance Capability = "conformance"
+	// CapabilitySmartAccounts binds DIDs to ERC-4337 smart accounts and relays their
+	// user operations to a bundler; see ERC4337_BUNDLER_URL
+	CapabilitySmartAccounts Capability = "smart_accounts"
+)
+
+// CapabilityStatus reports whether a capability is available, and why not when it is
+// offline
+type CapabilityStatus struct {
+	Capability Capability `json:"capability"`
+	Available  bool       `json:"available"`
+	Reason     string     `json:"reason,omitempty"`
+	// Deviations lists where the capability intentionally departs from the specs it
+	// is tested against
+	Deviations []ConformanceDeviation `json:"deviations,omitempty"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// DIDChange is a change to a DID in the change feed, with the DID's current state
+type DIDChange struct {
+	// Sequence is the change's position in the event stream
+	Sequence   uint64    `json:"sequence"`
+	Type       string    `json:"type"`
+	DID        string    `json:"did"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Resolution is the DID's current record and document, which may already reflect
+	// later changes
+	Resolution *DIDResolutionResult `json:"resolution"`
+}
+
+// DIDChangesRequest represents a request for the changes after a cursor
+type DIDChangesRequest struct {
+	// Since is the cursor returned by the previous page; empty starts from the oldest
+	// retained change
+	Since string `form:"since"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// DIDChangePage is a page of the change feed. Only the latest change of each DID in the
+// page is listed.
+type DIDChangePage struct {
+	Changes []*DIDChange `json:"changes"`
+	// Cursor continues the feed after this page
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"has_more"`
+}
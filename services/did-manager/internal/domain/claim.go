@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerifiedClaimCredentialType is the type of the credentials issued for claims added to
+// a DID after its creation
+const VerifiedClaimCredentialType = "VerifiedClaimCredential"
+
+// Claim types committed to at DID creation, by the DID's user hash
+const (
+	ClaimTypeName  = "name"
+	ClaimTypeEmail = "email"
+)
+
+// ClaimCommitment commits to a claim verified for a DID, either at creation or added
+// later without recreating the DID. Added claims are appended: a new commitment to a
+// type supersedes the previous one, and each bumps the DID's claims version.
+type ClaimCommitment struct {
+	ID    uuid.UUID `json:"-"`
+	DIDID uuid.UUID `json:"-"`
+	Type  string    `json:"type"`
+	// Scheme, Params and Commitment recompute and hold the commitment, like a DID's
+	// user hash
+	Scheme        string `json:"scheme"`
+	Params        string `json:"-"`
+	Commitment    string `json:"commitment"`
+	PepperVersion string `json:"-"`
+	// Version is the DID's claims version the claim was added in; zero for the claims
+	// committed at creation
+	Version int `json:"version"`
+	// CredentialID is the credential issued for the claim, if any
+	CredentialID *uuid.UUID `json:"credential_id,omitempty"`
+	// VerifiedBy is the tenant that verified the claim; empty for claims committed at
+	// creation
+	VerifiedBy string    `json:"verified_by,omitempty"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// DIDClaims lists the claim types a DID has verified, with the latest commitment of
+// each. Version counts the claims added since the DID was created.
+type DIDClaims struct {
+	DID     string             `json:"did"`
+	Version int                `json:"version"`
+	Claims  []*ClaimCommitment `json:"claims"`
+}
+
+// ClaimAddRequest represents a verifier's request to add a verified claim to a DID
+type ClaimAddRequest struct {
+	DID   string `json:"did" binding:"required"`
+	Type  string `json:"type" binding:"required,max=64"`
+	Value string `json:"value" binding:"required,max=1024"`
+	// ExpiresAt optionally limits the validity of the issued credential
+	ExpiresAt *time.Time `json:"expires_at"`
+	// VerifiedBy is the authenticated caller adding the claim
+	VerifiedBy string `json:"-"`
+}
+
+// ClaimAddResponse is an added claim with the credential issued for it, if any
+type ClaimAddResponse struct {
+	Claim      *ClaimCommitment `json:"claim"`
+	Credential *Credential      `json:"credential,omitempty"`
+}
+
+// ClaimVerifyRequest represents a request to check a claim value against a DID's latest
+// commitment of its type
+type ClaimVerifyRequest struct {
+	DID   string `json:"did" binding:"required"`
+	Type  string `json:"type" binding:"required,max=64"`
+	Value string `json:"value" binding:"required,max=1024"`
+}
+
+// ClaimVerifyResponse is the result of checking a claim
+type ClaimVerifyResponse struct {
+	DID     string `json:"did"`
+	Type    string `json:"type"`
+	Matches bool   `json:"matches"`
+	Version int    `json:"version"`
+}
+
+// ClaimRepository defines the interface for added claim data operations
+type ClaimRepository interface {
+	// Append stores a claim commitment as the DID's next claims version, which it sets
+	Append(claim *ClaimCommitment) error
+	// SetCredential records the credential issued for a claim
+	SetCredential(id, credentialID uuid.UUID) error
+	// ListLatest lists the latest commitment of each type added to a DID, by type
+	ListLatest(didID uuid.UUID) ([]*ClaimCommitment, error)
+	// Latest returns the latest commitment of a type added to a DID, or
+	// ErrClaimNotFound when none was added
+	Latest(didID uuid.UUID, claimType string) (*ClaimCommitment, error)
+	// Version returns the DID's claims version, zero when no claim was added
+	Version(didID uuid.UUID) (int, error)
+}
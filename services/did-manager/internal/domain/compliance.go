@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnchorKindRevocationRoot marks anchoring transactions publishing revocation roots;
+// other anchoring transactions are identified by their job type
+const AnchorKindRevocationRoot = "revocation_root"
+
+// ComplianceReport summarizes the service's activity over a period for auditors
+type ComplianceReport struct {
+	ID            uuid.UUID               `json:"id"`
+	From          time.Time               `json:"from"`
+	To            time.Time               `json:"to"`
+	GeneratedAt   time.Time               `json:"generated_at"`
+	DIDs          DIDActivity             `json:"dids"`
+	Credentials   CredentialActivity      `json:"credentials"`
+	Verifications VerificationActivity    `json:"verifications"`
+	Anchors       []*AnchoringTransaction `json:"anchoring_transactions"`
+}
+
+// DIDActivity counts DID lifecycle events in a report period
+type DIDActivity struct {
+	Created int `json:"created"`
+	// Revoked counts revocations confirmed on-chain
+	Revoked int `json:"revoked"`
+}
+
+// CredentialActivity counts credential lifecycle events in a report period
+type CredentialActivity struct {
+	Issued  int `json:"issued"`
+	Revoked int `json:"revoked"`
+}
+
+// VerificationActivity counts the verification sessions opened in a report period by
+// their outcome
+type VerificationActivity struct {
+	Sessions int `json:"sessions"`
+	Verified int `json:"verified"`
+	Rejected int `json:"rejected"`
+	Expired  int `json:"expired"`
+}
+
+// AnchoringTransaction is a transaction the service submitted on-chain
+type AnchoringTransaction struct {
+	// Kind is the job type, or AnchorKindRevocationRoot
+	Kind string `json:"kind"`
+	// Subject is the DID, the document hash of notarizations, or the revocation root
+	Subject    string    `json:"subject"`
+	TxHash     string    `json:"tx_hash"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// ReportSignature is an Ed25519 signature over the compact JSON encoding of a report
+type ReportSignature struct {
+	Algorithm string `json:"algorithm"`
+	// KeyID is the hex SHA-256 fingerprint of the public key
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+	Value     string `json:"value"`
+}
+
+// SignedComplianceReport is the exported JSON form of a report
+type SignedComplianceReport struct {
+	Report    json.RawMessage  `json:"report" binding:"required"`
+	Signature *ReportSignature `json:"signature" binding:"required"`
+}
+
+// ComplianceReportRequest selects the period and format of a compliance report
+type ComplianceReportRequest struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" binding:"required,gtfield=From" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string    `form:"format" binding:"omitempty,oneof=json pdf"`
+}
+
+// ComplianceRepository aggregates the activity covered by compliance reports
+type ComplianceRepository interface {
+	// Summarize fills the activity counts and anchoring transactions of [from, to)
+	Summarize(from, to time.Time) (*ComplianceReport, error)
+}
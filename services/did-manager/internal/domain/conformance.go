@@ -0,0 +1,64 @@
+package domain
+
+import "did-manager/pkg/did"
+
+// ConformanceDeviation is a known, intentional departure from a DID specification.
+// Deviations are reported with the conformance capability so that test suite failures
+// they explain can be told apart from regressions.
+type ConformanceDeviation struct {
+	Spec        string `json:"spec"`
+	Section     string `json:"section"`
+	Description string `json:"description"`
+}
+
+// DIDResolutionContext is the JSON-LD context of DID resolution results
+const DIDResolutionContext = "https://w3id.org/did-resolution/v1"
+
+// Content types of DID Document representations and of DID resolution results
+const (
+	ContentTypeDIDJSON       = "application/did+json"
+	ContentTypeDIDLDJSON     = "application/did+ld+json"
+	ContentTypeDIDResolution = `application/ld+json;profile="https://w3id.org/did-resolution"`
+)
+
+// Error codes of the DID Resolution specification, set in the resolution metadata
+const (
+	ResolutionErrorInvalidDID                 = "invalidDid"
+	ResolutionErrorNotFound                   = "notFound"
+	ResolutionErrorRepresentationNotSupported = "representationNotSupported"
+	ResolutionErrorInternal                   = "internalError"
+)
+
+// RegistrarCreateRequest is a create request of the DIF Universal Registrar interface
+type RegistrarCreateRequest struct {
+	Options RegistrarCreateOptions `json:"options"`
+}
+
+// RegistrarCreateOptions are the creation options understood by the registrar. Without
+// a public key the DID gets a custodial key.
+type RegistrarCreateOptions struct {
+	KeyAlgorithm string `json:"keyAlgorithm"`
+	PublicKeyHex string `json:"publicKeyHex" binding:"omitempty,hexadecimal,max=8192"`
+}
+
+// RegistrarResult is the response of the DIF Universal Registrar interface
+type RegistrarResult struct {
+	JobID                   *string             `json:"jobId"`
+	DIDState                RegistrarDIDState   `json:"didState"`
+	DIDRegistrationMetadata map[string]any      `json:"didRegistrationMetadata"`
+	DIDDocumentMetadata     DIDDocumentMetadata `json:"didDocumentMetadata"`
+}
+
+// Registrar DID states
+const (
+	RegistrarStateFinished = "finished"
+	RegistrarStateFailed   = "failed"
+)
+
+// RegistrarDIDState is the outcome of a registrar operation
+type RegistrarDIDState struct {
+	State       string        `json:"state"`
+	DID         string        `json:"did,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+	DIDDocument *did.Document `json:"didDocument,omitempty"`
+}
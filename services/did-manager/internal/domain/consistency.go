@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// ConsistencyTokenHeader carries a consistency token on reads that must see a write
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// walPosition matches a PostgreSQL WAL position (pg_lsn), e.g. 0/16B3748
+var walPosition = regexp.MustCompile(`^[0-9A-F]{1,8}/[0-9A-F]{1,8}$`)
+
+// ConsistencyToken is returned by writes so that reads right after them, such as
+// verifying a DID just created, see the write even when served by a lagging read
+// replica or through a read cache. It names the written DID and the position of the
+// primary's write-ahead log once the write was committed.
+type ConsistencyToken struct {
+	DID string `json:"did"`
+	LSN string `json:"lsn"`
+}
+
+// Encode returns the opaque form of the token handed to clients
+func (t *ConsistencyToken) Encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseConsistencyToken decodes a token returned by Encode, failing with
+// ErrInvalidConsistencyToken when it is malformed
+func ParseConsistencyToken(encoded string) (*ConsistencyToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConsistencyToken, err)
+	}
+
+	var token ConsistencyToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConsistencyToken, err)
+	}
+	if token.DID == "" || !walPosition.MatchString(token.LSN) {
+		return nil, fmt.Errorf("%w: missing DID or WAL position", ErrInvalidConsistencyToken)
+	}
+
+	return &token, nil
+}
+
+// ConsistencyRepository defines the interface for following the write-ahead log
+// position of the primary on the database a deployment reads from
+type ConsistencyRepository interface {
+	// CurrentLSN returns the current write-ahead log position of the primary
+	CurrentLSN() (string, error)
+	// Replayed reports whether the database has applied the primary's writes up to lsn
+	Replayed(lsn string) (bool, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConsistencyTokenRoundTrip(t *testing.T) {
+	token := &ConsistencyToken{DID: "did:example:user:hash:key", LSN: "0/16B3748"}
+
+	parsed, err := ParseConsistencyToken(token.Encode())
+	if err != nil {
+		t.Fatalf("ParseConsistencyToken() error = %v", err)
+	}
+	if *parsed != *token {
+		t.Errorf("ParseConsistencyToken() = %+v, want %+v", parsed, token)
+	}
+}
+
+func TestParseConsistencyTokenRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not base64", "not a token!"},
+		{"not JSON", "bm90IGpzb24"},
+		{"missing DID", (&ConsistencyToken{LSN: "0/16B3748"}).Encode()},
+		{"bad position", (&ConsistencyToken{DID: "did:example:a", LSN: "0'; DROP TABLE dids"}).Encode()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseConsistencyToken(tt.encoded); !errors.Is(err, ErrInvalidConsistencyToken) {
+				t.Errorf("ParseConsistencyToken() error = %v, want ErrInvalidConsistencyToken", err)
+			}
+		})
+	}
+}
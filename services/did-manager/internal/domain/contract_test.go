@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// update rewrites the contract fixtures from the responses below instead of checking them
+var update = flag.Bool("update", false, "rewrite the API contract fixtures")
+
+// fixturesDir holds the golden responses the auth-service and CLI clients are tested
+// against
+const fixturesDir = "../../../../api/did-manager/fixtures"
+
+// contractTime is the timestamp of every fixture, so they are stable across runs
+var contractTime = time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// contractDID is the DID record of the fixtures. Optional fields are set too, so the
+// fixtures describe the full shape of the responses.
+func contractDID() *DID {
+	return &DID{
+		ID:                uuid.MustParse("7d7b6b0e-3f0c-4c47-9d0e-1b8f7b2a4c11"),
+		UserID:            uuid.MustParse("0b6a1a52-5f3e-4d8e-a6a1-9c2f4e7d3b20"),
+		Did:               "did:example:9f2c4e7d3b200b6a1a525f3e4d8ea6a1",
+		UserHash:          "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		HashScheme:        "sha256-v1",
+		PublicKey:         "04a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+		KeyAlgorithm:      "ed25519-2020",
+		Custodial:         true,
+		Status:            string(DIDStatusActive),
+		Visibility:        "public",
+		CreatedAt:         contractTime,
+		UpdatedAt:         contractTime,
+		BlockchainTx:      "0x3c9f1e0d8b7a6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d",
+		ApprovalThreshold: 2,
+		SandboxTenant:     "c3f7a9e2-6b1d-4f8a-9e0c-2d4b6f8a0c1e",
+		AliasDID:          "did:example:alias9f2c4e7d3b200b6a1a525f3e4d8e",
+	}
+}
+
+// success wraps data in the envelope of successful responses
+func success(data any) any {
+	return map[string]any{
+		"success": true,
+		"data":    data,
+	}
+}
+
+// contracts are the responses of the DID Manager endpoints consumed by the other
+// services, by fixture name
+func contracts() map[string]any {
+	record := contractDID()
+	blockTime := contractTime.Add(12 * time.Second)
+	verification := &DIDVerificationResponse{
+		IsValid:        true,
+		DID:            record.Did,
+		UserHash:       record.UserHash,
+		Status:         record.Status,
+		Message:        "DID is valid",
+		BlockchainTx:   record.BlockchainTx,
+		Assurance:      AssuranceChainConfirmed(12),
+		Confirmations:  12,
+		BlockNumber:    1024,
+		BlockTimestamp: &blockTime,
+		Warning:        "blockchain check skipped",
+	}
+
+	return map[string]any{
+		"create_did": success(&DIDResponse{
+			DID:              record,
+			UserHash:         record.UserHash,
+			Status:           string(DIDStatusPending),
+			Message:          "DID created successfully",
+			ConsistencyToken: (&ConsistencyToken{DID: record.Did, LSN: "0/16B3748"}).Encode(),
+		}),
+		"get_or_create_did": success(&DIDResponse{
+			DID:      record,
+			UserHash: record.UserHash,
+			Status:   record.Status,
+			Message:  "Existing DID returned",
+			Existing: true,
+		}),
+		"verify_did": success(verification),
+		"did_status": success(NewDIDStatusResponse(verification)),
+		"error": map[string]any{
+			"error":   "Identity already registered",
+			"details": "identity is already registered: email matches an existing DID",
+		},
+	}
+}
+
+// TestAPIContract checks the responses against the fixtures the clients are tested
+// against. A failure means a response changed shape: update the clients, then the
+// fixtures with go test ./internal/domain -run TestAPIContract -update.
+func TestAPIContract(t *testing.T) {
+	for name, response := range contracts() {
+		t.Run(name, func(t *testing.T) {
+			got, err := json.MarshalIndent(response, "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal response: %v", err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join(fixturesDir, name+".json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("failed to write fixture: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("response no longer matches %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+			}
+		})
+	}
+}
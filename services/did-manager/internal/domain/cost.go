@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CostClass classifies API operations by what they cost to serve
+type CostClass string
+
+const (
+	// CostChainWrite operations submit transactions, spending gas
+	CostChainWrite CostClass = "chain_write"
+	// CostRead operations only read
+	CostRead CostClass = "read"
+	// CostDBOnly operations write to the database without touching the chain
+	CostDBOnly CostClass = "db_only"
+)
+
+// CostUnits is what one request of each class draws from a budget
+var CostUnits = map[CostClass]int{
+	CostChainWrite: 100,
+	CostDBOnly:     5,
+	CostRead:       1,
+}
+
+// CostUsage counts the successful requests of one class an API key made on a day (UTC)
+type CostUsage struct {
+	APIKeyID uuid.UUID `json:"api_key_id"`
+	// Tenant is the API key's tenant: its parent for a sub-key, the key itself otherwise
+	Tenant   string    `json:"tenant"`
+	Class    CostClass `json:"cost_class"`
+	Day      time.Time `json:"day"`
+	Requests int       `json:"requests"`
+	Units    int       `json:"units"`
+}
+
+// CostBudget limits the cost units an API key draws per calendar month (UTC). A
+// tenant's budget covers its sub-keys; a sub-key's covers the sub-key alone.
+type CostBudget struct {
+	APIKeyID     uuid.UUID `json:"api_key_id"`
+	MonthlyUnits int       `json:"monthly_units"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CostBudgetRequest represents a request to set an API key's budget
+type CostBudgetRequest struct {
+	MonthlyUnits *int `json:"monthly_units" binding:"required,min=0"`
+}
+
+// CostSummary is an API key's consumption over a period, by cost class
+type CostSummary struct {
+	APIKeyID string            `json:"api_key_id"`
+	Requests map[CostClass]int `json:"requests"`
+	Units    int               `json:"units"`
+	// MonthlyBudget is the key's budget, if it has one
+	MonthlyBudget *int `json:"monthly_budget,omitempty"`
+}
+
+// CostReport summarizes the consumption of API keys over a period
+type CostReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Summaries []*CostSummary `json:"summaries"`
+}
+
+// CostReportRequest selects the period and API key of a cost report
+type CostReportRequest struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" binding:"required,gtfield=From" time_format:"2006-01-02T15:04:05Z07:00"`
+	// APIKey optionally restricts the report to one tenant and its sub-keys
+	APIKey string `form:"api_key"`
+}
+
+// Quota scopes
+const (
+	QuotaScopeTenant = "tenant"
+	QuotaScopeSubKey = "sub_key"
+)
+
+// QuotaInfo describes the quota a throttled request ran into, in 402 and 429
+// responses
+type QuotaInfo struct {
+	// Scope is the budget or limit exceeded: the tenant's or the sub-key's
+	Scope     string    `json:"scope"`
+	APIKeyID  string    `json:"api_key_id"`
+	CostClass CostClass `json:"cost_class"`
+	// Limit is in cost units per month for budgets, and in requests per minute for
+	// chain write throttling
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used,omitempty"`
+	Remaining int       `json:"remaining"`
+	Cost      int       `json:"cost"`
+	ResetsAt  time.Time `json:"resets_at"`
+	// RetryAfter is the number of seconds to wait before retrying a throttled request
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// SubKeyCreateRequest represents a tenant's request to issue a sub-key, optionally
+// with a monthly budget
+type SubKeyCreateRequest struct {
+	Name         string `json:"name" binding:"required,max=255"`
+	MonthlyUnits *int   `json:"monthly_units" binding:"omitempty,min=0"`
+}
+
+// CostRepository defines the interface for cost accounting data operations
+type CostRepository interface {
+	// AddUsage adds requests and units to the daily totals of API keys
+	AddUsage(usage []*CostUsage) error
+	// Units sums the units an API key drew since the given time
+	Units(apiKeyID uuid.UUID, since time.Time) (int, error)
+	// TenantUnits sums the units a tenant and its sub-keys drew since the given time
+	TenantUnits(tenant string, since time.Time) (int, error)
+	// Summarize totals usage per API key and class over [from, to), optionally for
+	// one tenant and its sub-keys
+	Summarize(from, to time.Time, tenant string) ([]*CostUsage, error)
+	// GetBudget returns an API key's budget, or nil when it has none
+	GetBudget(apiKeyID uuid.UUID) (*CostBudget, error)
+	ListBudgets() ([]*CostBudget, error)
+	UpsertBudget(budget *CostBudget) error
+	// DeleteBudget removes a budget, returning ErrCostBudgetNotFound when none exists
+	DeleteBudget(apiKeyID uuid.UUID) error
+}
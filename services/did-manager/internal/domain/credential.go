@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CredentialStatus represents the lifecycle state of an issued credential
+type CredentialStatus string
+
+const (
+	CredentialStatusActive  CredentialStatus = "active"
+	CredentialStatusRevoked CredentialStatus = "revoked"
+	// CredentialStatusPendingReview holds a flagged credential in the review queue; it
+	// does not verify until approved
+	CredentialStatusPendingReview CredentialStatus = "pending_review"
+	CredentialStatusRejected      CredentialStatus = "rejected"
+)
+
+// Credential is a stored verifiable credential together with indexed metadata
+type Credential struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	IssuerDID  string     `json:"issuer_did" db:"issuer_did"`
+	SubjectDID string     `json:"subject_did" db:"subject_did"`
+	Type       string     `json:"type" db:"type"`
+	Status     string     `json:"status" db:"status"` // active, revoked, pending_review, rejected
+	IssuedAt   time.Time  `json:"issued_at" db:"issued_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	// Tenant is an optional issuer-assigned label grouping subjects, e.g. a customer
+	Tenant string `json:"tenant,omitempty" db:"tenant"`
+	// Document is the signed W3C credential JSON, or the AgeCredentialDocument of
+	// AgeOver credentials
+	Document json.RawMessage `json:"document" db:"document"`
+}
+
+// CredentialIssueRequest represents a request by an issuer DID to issue a credential
+type CredentialIssueRequest struct {
+	SubjectDID string         `json:"subject_did" binding:"required"`
+	Type       string         `json:"type" binding:"required"`
+	Claims     map[string]any `json:"claims" binding:"required"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	// Tenant optionally labels the credential for the issuer's own searches; it is not
+	// part of the signed document
+	Tenant string `json:"tenant" binding:"max=255"`
+	// OnBehalfOf optionally names a DID the issuer acts for; DelegationIDs is then the
+	// chain of stored delegation credentials from that DID to the issuer, root first
+	OnBehalfOf    string      `json:"on_behalf_of"`
+	DelegationIDs []uuid.UUID `json:"delegation_ids"`
+	// Attachments are documents from the vault, uploaded by the issuer, that the
+	// credential references by digest; its subject may then download them
+	Attachments []uuid.UUID `json:"attachments"`
+}
+
+// DelegationIssueRequest represents a request by a DID to delegate capabilities to another DID
+type DelegationIssueRequest struct {
+	DelegateDID  string   `json:"delegate_did" binding:"required"`
+	Capabilities []string `json:"capabilities" binding:"required,min=1"`
+	// ParentID re-delegates a delegation held by the caller instead of delegating its own authority
+	ParentID  *uuid.UUID `json:"parent_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// DelegationVerifyRequest represents a request to verify a delegation chain
+type DelegationVerifyRequest struct {
+	// Chain lists the delegation credentials from the delegator to the delegate
+	Chain      []json.RawMessage `json:"chain" binding:"required,min=1"`
+	Delegator  string            `json:"delegator" binding:"required"`
+	Delegate   string            `json:"delegate" binding:"required"`
+	Capability string            `json:"capability" binding:"required"`
+}
+
+// CredentialVerifyRequest represents a request to verify a credential's proof
+type CredentialVerifyRequest struct {
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+// CredentialVerifyResponse reports the outcome of credential verification
+type CredentialVerifyResponse struct {
+	Valid   bool   `json:"valid"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message"`
+	// CredentialID is the document ID of a valid credential, recorded for revocation
+	// propagation
+	CredentialID string `json:"-"`
+}
+
+// PresentationCreateRequest represents a holder's request to present credentials. A
+// presentation without credentials only proves control of the holder DID (DID auth).
+type PresentationCreateRequest struct {
+	HolderDID     string      `json:"holder_did" binding:"required"`
+	CredentialIDs []uuid.UUID `json:"credential_ids"`
+	// Challenge and Domain are supplied by the verifier to prevent replay
+	Challenge string `json:"challenge"`
+	Domain    string `json:"domain"`
+}
+
+// CredentialSearchRequest filters an issuer's credentials by their indexed metadata;
+// empty fields match everything. Sort is issued_at for oldest first or -issued_at, the
+// default, for newest first.
+type CredentialSearchRequest struct {
+	Type          string     `form:"type"`
+	SubjectDID    string     `form:"subject_did"`
+	Tenant        string     `form:"tenant"`
+	Status        string     `form:"status" binding:"omitempty,oneof=active revoked"`
+	IssuedAfter   *time.Time `form:"issued_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	IssuedBefore  *time.Time `form:"issued_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	ExpiresAfter  *time.Time `form:"expires_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	ExpiresBefore *time.Time `form:"expires_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=issued_at -issued_at"`
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// CredentialSearchResponse is a page of credential search results
+type CredentialSearchResponse struct {
+	Credentials []*Credential `json:"credentials"`
+	Total       int           `json:"total"`
+	Page        int           `json:"page"`
+	Limit       int           `json:"limit"`
+}
+
+// CredentialRepository defines the interface for credential data operations
+type CredentialRepository interface {
+	Create(credential *Credential) error
+	GetByID(id uuid.UUID) (*Credential, error)
+	ListBySubjects(subjectDIDs []string) ([]*Credential, error)
+	// Search returns a page of credentials issued by issuerDID matching req, in the
+	// requested order, together with the total number of matches
+	Search(issuerDID string, req *CredentialSearchRequest, limit, offset int) ([]*Credential, int, error)
+	// Revoke marks an active credential as revoked
+	Revoke(id uuid.UUID, revokedAt time.Time) error
+	// ListExpiring lists active credentials expiring before the given time whose
+	// holders have not yet been notified, soonest first
+	ListExpiring(before time.Time, limit int) ([]*Credential, error)
+	// MarkExpiryNotified records that expiry was handled, and the replacement if renewed
+	MarkExpiryNotified(id uuid.UUID, notifiedAt time.Time, renewedByID *uuid.UUID) error
+	// ListUnbatchedRevoked lists revoked credentials not yet in a revocation batch
+	ListUnbatchedRevoked() ([]uuid.UUID, error)
+	// ListRevokedThroughBatch lists credentials revoked in batches up to sequence;
+	// a negative sequence includes every batch
+	ListRevokedThroughBatch(sequence int64) ([]uuid.UUID, error)
+	// ResolveReview moves a credential out of pending review, returning
+	// ErrReviewClosed if it is no longer pending
+	ResolveReview(id uuid.UUID, status CredentialStatus) error
+}
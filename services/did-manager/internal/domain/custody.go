@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustodyTransferStatus represents the state of a custody transfer
+type CustodyTransferStatus string
+
+const (
+	CustodyTransferStatusPending   CustodyTransferStatus = "pending"
+	CustodyTransferStatusCompleted CustodyTransferStatus = "completed"
+	CustodyTransferStatusExpired   CustodyTransferStatus = "expired"
+)
+
+// CustodyTransfer moves control of a custodial DID to its owner. The owner proves
+// possession of a new key by signing the transfer challenge; on completion the DID is
+// rotated to that key and the stored private key is wiped. Records are kept as the
+// audit trail of the transfer.
+type CustodyTransfer struct {
+	ID    uuid.UUID `json:"id"`
+	DIDID uuid.UUID `json:"did_id"`
+	Did   string    `json:"did"`
+	// UserID and ClientID identify the owner and wallet application that requested it
+	UserID    uuid.UUID `json:"user_id"`
+	ClientID  string    `json:"client_id"`
+	Challenge string    `json:"challenge"`
+	Status    string    `json:"status"`
+	// PreviousKeyFingerprint is the hex SHA-256 of the custodial public key
+	PreviousKeyFingerprint string     `json:"previous_key_fingerprint"`
+	NewPublicKey           string     `json:"new_public_key,omitempty"`
+	KeyAlgorithm           string     `json:"key_algorithm,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	ExpiresAt              time.Time  `json:"expires_at"`
+	CompletedAt            *time.Time `json:"completed_at,omitempty"`
+}
+
+// CustodyTransferCompleteRequest presents the owner's new key and a signature over
+// the transfer message made with it
+type CustodyTransferCompleteRequest struct {
+	PublicKey string `json:"public_key" binding:"required,hexadecimal"`
+	// KeyAlgorithm defaults to the DID's current signature suite
+	KeyAlgorithm string `json:"key_algorithm"`
+	Signature    string `json:"signature" binding:"required,hexadecimal"`
+}
+
+// CustodyTransferRepository defines the interface for custody transfer data operations
+type CustodyTransferRepository interface {
+	Create(transfer *CustodyTransfer) error
+	GetByID(id uuid.UUID) (*CustodyTransfer, error)
+	// Complete rotates the DID to the transfer's new key, clears its custodial flag
+	// and marks the transfer completed in one transaction; it returns
+	// ErrCustodyTransferClosed if the transfer or DID changed since it was loaded
+	Complete(transfer *CustodyTransfer) error
+	MarkExpired(id uuid.UUID) error
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceKeyStatus represents the status of a device key
+type DeviceKeyStatus string
+
+const (
+	DeviceKeyStatusActive  DeviceKeyStatus = "active"
+	DeviceKeyStatusRevoked DeviceKeyStatus = "revoked"
+)
+
+// DeviceKey is a subkey held by one of a user's devices, listed as an authentication
+// verification method on the user's DID. Sessions bound to the device prove
+// possession of it, and revoking it cuts off that device alone.
+type DeviceKey struct {
+	ID     uuid.UUID `json:"id"`
+	DIDID  uuid.UUID `json:"did_id"`
+	DID    string    `json:"did"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	// VerificationMethod is the key's ID in the DID document, <did>#device-<id>
+	VerificationMethod string     `json:"verification_method"`
+	PublicKey          string     `json:"public_key"` // hex
+	KeyAlgorithm       string     `json:"key_algorithm"`
+	Status             string     `json:"status"`
+	CreatedAt          time.Time  `json:"created_at"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	// Attestation is set when a platform attestation vouched for the app instance
+	// that registered the key
+	Attestation *DeviceKeyAttestation `json:"attestation,omitempty"`
+}
+
+// DeviceKeyAttestation records the platform attestation bound to a device key
+type DeviceKeyAttestation struct {
+	// Platform is play_integrity or app_attest
+	Platform string `json:"platform"`
+	// AppID is the attested Android package name or iOS app ID
+	AppID string `json:"app_id"`
+	// KeyID is the app instance's App Attest key ID; empty for Play Integrity
+	KeyID      string    `json:"key_id,omitempty"`
+	AttestedAt time.Time `json:"attested_at"`
+}
+
+// DeviceKeyAttestationEvidence is a platform attestation submitted with a device key.
+// Its challenge is DeviceKeyAttestationChallenge of the DID and public key, binding
+// the attestation to the key being registered.
+type DeviceKeyAttestationEvidence struct {
+	Platform string `json:"platform" binding:"required,oneof=play_integrity app_attest"`
+	// Token is the Play Integrity token, or the base64 App Attest attestation object
+	Token string `json:"token" binding:"required"`
+	// KeyID is the base64 App Attest key ID
+	KeyID string `json:"key_id"`
+}
+
+// WalletOperation names a group of high-risk wallet API routes that can be restricted
+// to attested app instances
+type WalletOperation string
+
+const (
+	// WalletOperationCustodyTransfer covers starting and completing custody transfers
+	WalletOperationCustodyTransfer WalletOperation = "custody_transfer"
+	// WalletOperationPresentation covers creating verifiable presentations
+	WalletOperationPresentation WalletOperation = "presentation"
+	// WalletOperationDeviceKeys covers managing device keys
+	WalletOperationDeviceKeys WalletOperation = "device_keys"
+)
+
+// DeviceKeyRegisterRequest registers a device's key on one of its user's DIDs
+type DeviceKeyRegisterRequest struct {
+	DID  string `json:"did" binding:"required"`
+	Name string `json:"name" binding:"required,max=100"`
+	// PublicKey is the hex-encoded public key of the device
+	PublicKey string `json:"public_key" binding:"required"`
+	// KeyAlgorithm is the key's signature suite; empty for the default suite
+	KeyAlgorithm string `json:"key_algorithm"`
+	// Signature is the device's hex-encoded signature over
+	// "device-key:<did>:<public_key>", proving it holds the private key
+	Signature string `json:"signature" binding:"required"`
+	// Attestation optionally vouches that a genuine app instance holds the key
+	Attestation *DeviceKeyAttestationEvidence `json:"attestation"`
+}
+
+// DeviceKeyVerifyRequest asks whether a device key signed a message
+type DeviceKeyVerifyRequest struct {
+	Message   string `json:"message" binding:"required"`
+	Signature string `json:"signature" binding:"required"` // hex
+}
+
+// DeviceKeyRepository defines the interface for device key data operations
+type DeviceKeyRepository interface {
+	Create(key *DeviceKey) error
+	GetByID(id uuid.UUID) (*DeviceKey, error)
+	// GetByVerificationMethod returns the key with a verification method ID
+	GetByVerificationMethod(verificationMethod string) (*DeviceKey, error)
+	ListActiveByDIDID(didID uuid.UUID) ([]*DeviceKey, error)
+	ListByUserID(userID uuid.UUID) ([]*DeviceKey, error)
+	CountActiveByDIDID(didID uuid.UUID) (int, error)
+	// Revoke revokes an active key, returning ErrDeviceKeyNotFound when there is none
+	Revoke(id uuid.UUID, revokedAt time.Time) error
+	// RevokeByUserID revokes every active key of a user, returning how many were active
+	RevokeByUserID(userID uuid.UUID, revokedAt time.Time) (int64, error)
+}
@@ -12,19 +12,110 @@ type DID struct {
 	UserID       uuid.UUID `json:"user_id" db:"user_id"`
 	Did          string    `json:"did" db:"did"`
 	UserHash     string    `json:"user_hash" db:"user_hash"`
+	HashScheme   string    `json:"hash_scheme" db:"hash_scheme"` // sha256-v1, argon2id-v1
+	HashParams   string    `json:"-" db:"hash_params"`           // PHC parameter string, empty for sha256-v1
 	PublicKey    string    `json:"public_key" db:"public_key"`
-	Status       string    `json:"status" db:"status"` // active, revoked, expired
+	KeyAlgorithm string    `json:"key_algorithm" db:"key_algorithm"` // signature suite ID, e.g. ed25519-2020
+	// Custodial DIDs have their private key in PublicKey and are signed for by the
+	// service; once custody is transferred PublicKey holds only the owner's public key
+	Custodial    bool      `json:"custodial" db:"custodial"`
+	Status       string    `json:"status" db:"status"`         // active, revoked, expired
+	Visibility   string    `json:"visibility" db:"visibility"` // public, private
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 	BlockchainTx string    `json:"blockchain_tx" db:"blockchain_tx"`
+	// ApprovalThreshold is the number of controllers that must approve mutating
+	// operations on an organization DID; zero for DIDs without controllers
+	ApprovalThreshold int `json:"approval_threshold,omitempty" db:"approval_threshold"`
+	// EmailIndex is the blind index of the holder's email address; empty for DIDs
+	// created without an email index key until their claims verify
+	EmailIndex string `json:"-" db:"email_index"`
+	// SandboxTenant is the sandbox API key whose partition holds the DID; empty for
+	// production DIDs
+	SandboxTenant string `json:"sandbox_tenant,omitempty" db:"sandbox_tenant"`
+	// PepperVersion names the pepper keying UserHash, so the commitment stays verifiable
+	// after the pepper is rotated
+	PepperVersion string `json:"-" db:"pepper_version"`
+	// AliasDID is the current identifier of a DID created with a legacy identifier,
+	// resolving to the same DID; empty for current identifiers and for legacy DIDs not
+	// yet migrated or whose inception key is no longer stored (see IdentifierMigration)
+	AliasDID string `json:"alias_did,omitempty" db:"alias_did"`
 }
 
-// DIDCreateRequest represents a request to create a new DID
+// DIDSummary is the view of a DID record returned by lookups, without its key material
+// or claims commitment parameters
+type DIDSummary struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Did          string    `json:"did"`
+	UserHash     string    `json:"user_hash"`
+	KeyAlgorithm string    `json:"key_algorithm"`
+	Custodial    bool      `json:"custodial"`
+	Status       string    `json:"status"`
+	Visibility   string    `json:"visibility"`
+	CreatedAt    time.Time `json:"created_at"`
+	BlockchainTx string    `json:"blockchain_tx"`
+	AliasDID     string    `json:"alias_did,omitempty"`
+}
+
+// NewDIDSummary builds the lookup view of a DID record
+func NewDIDSummary(record *DID) *DIDSummary {
+	return &DIDSummary{
+		ID:           record.ID,
+		UserID:       record.UserID,
+		Did:          record.Did,
+		UserHash:     record.UserHash,
+		KeyAlgorithm: record.KeyAlgorithm,
+		Custodial:    record.Custodial,
+		Status:       record.Status,
+		Visibility:   record.Visibility,
+		CreatedAt:    record.CreatedAt,
+		BlockchainTx: record.BlockchainTx,
+		AliasDID:     record.AliasDID,
+	}
+}
+
+// AnchoredDID is the identifier anchored on-chain for the DID: its alias once it has
+// been migrated from a legacy identifier, and the DID itself otherwise
+func (d *DID) AnchoredDID() string {
+	if d.AliasDID != "" {
+		return d.AliasDID
+	}
+	return d.Did
+}
+
+// DIDCreateRequest represents a request to create a new DID. The holder's claims are
+// required unless the DID is anonymous.
 type DIDCreateRequest struct {
-	UserID   uuid.UUID `json:"user_id" binding:"required"`
-	Name     string    `json:"name" binding:"required"`
-	Email    string    `json:"email" binding:"required,email"`
-	Password string    `json:"password" binding:"required"`
+	UserID uuid.UUID `json:"user_id" binding:"required_unless=Anonymous true"`
+	Name   string    `json:"name" binding:"required_unless=Anonymous true"`
+	Email  string    `json:"email" binding:"required_unless=Anonymous true,omitempty,email"`
+	// Password is accepted from older clients and ignored, as the claims commitment
+	// covers the name and email only
+	Password string `json:"password"`
+	// Anonymous creates a DID without any PII or claims commitment, for tenants with the
+	// anonymous_dids feature; name, email and password must then be empty and user_id
+	// is an optional pseudonymous account ID
+	Anonymous bool `json:"anonymous"`
+	// PublicKey is an optional hex-encoded public key for an anonymous DID, whose private
+	// key stays with the holder; a custodial key is generated otherwise
+	PublicKey string `json:"public_key" binding:"omitempty,hexadecimal,max=8192"`
+	// Visibility is optional and defaults to public
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public private"`
+	// KeyAlgorithm optionally selects the signature suite for the DID key;
+	// experimental suites are only accepted when enabled on the server
+	KeyAlgorithm string `json:"key_algorithm"`
+	// Method optionally selects the DID method, defaulting to example; it must be
+	// creatable by the deployment and allowed for the tenant
+	Method DIDMethod `json:"method" binding:"max=32"`
+	// DryRun simulates the on-chain registration instead of submitting it; the DID
+	// stays pending
+	DryRun bool `json:"dry_run"`
+	// Tenant is the authenticated caller creating the DID, used to resolve feature flags
+	// and meter usage
+	Tenant string `json:"-"`
+	// SandboxTenant is the caller's sandbox partition, which then holds the DID
+	SandboxTenant string `json:"-"`
 }
 
 // DIDResponse represents the response after DID creation
@@ -33,12 +124,29 @@ type DIDResponse struct {
 	UserHash string `json:"user_hash"`
 	Status   string `json:"status"`
 	Message  string `json:"message"`
+	// Existing is set when a get-or-create request returned the DID the user already
+	// had instead of creating one
+	Existing bool `json:"existing,omitempty"`
+	// ConsistencyToken is presented in X-Consistency-Token by reads that must see the
+	// new DID, such as verifying it right away; empty when tokens are not issued
+	ConsistencyToken string `json:"consistency_token,omitempty"`
 }
 
 // DIDVerificationRequest represents a request to verify a DID
 type DIDVerificationRequest struct {
 	DID      string `json:"did" binding:"required"`
 	UserHash string `json:"user_hash"`
+	// Name and Email optionally check the claims against the DID's commitment
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Tenant is the authenticated caller verifying the DID, used for usage metering
+	// and to select the verification policy
+	Tenant     string     `json:"-"`
+	TenantType CallerType `json:"-"`
+	// SandboxTenant is the caller's sandbox partition; sandbox usage is not metered
+	SandboxTenant string `json:"-"`
+	// Endpoint is the API endpoint verifying the DID, selecting the verification policy
+	Endpoint VerificationEndpoint `json:"-"`
 }
 
 // DIDVerificationResponse represents the response after DID verification
@@ -49,6 +157,46 @@ type DIDVerificationResponse struct {
 	Status       string `json:"status"`
 	Message      string `json:"message"`
 	BlockchainTx string `json:"blockchain_tx"`
+	// Assurance tells what the result is based on
+	Assurance AssuranceLevel `json:"assurance"`
+	// Confirmations counts the blocks confirming the DID's transaction at the chain check
+	Confirmations uint64 `json:"confirmations,omitempty"`
+	// BlockNumber and BlockTimestamp identify the block the chain check read
+	BlockNumber    uint64     `json:"block_number,omitempty"`
+	BlockTimestamp *time.Time `json:"block_timestamp,omitempty"`
+	// Warning is set when the result falls back to the local record because the
+	// blockchain could not be reached
+	Warning string `json:"warning,omitempty"`
+}
+
+// DIDStatusResponse is the on-chain state of a DID reported by the status endpoint:
+// its verification without the claims check
+type DIDStatusResponse struct {
+	DID           string         `json:"did"`
+	Status        string         `json:"status"`
+	IsValid       bool           `json:"is_valid"`
+	Message       string         `json:"message"`
+	BlockchainTx  string         `json:"blockchain_tx"`
+	Assurance     AssuranceLevel `json:"assurance"`
+	Confirmations uint64         `json:"confirmations,omitempty"`
+	Warning       string         `json:"warning,omitempty"`
+	// StatusDescription describes the status to the DID's holder, in the locale the
+	// request accepts or the tenant's default locale
+	StatusDescription string `json:"status_description,omitempty"`
+}
+
+// NewDIDStatusResponse reports the status of a DID from its verification
+func NewDIDStatusResponse(v *DIDVerificationResponse) *DIDStatusResponse {
+	return &DIDStatusResponse{
+		DID:           v.DID,
+		Status:        v.Status,
+		IsValid:       v.IsValid,
+		Message:       v.Message,
+		BlockchainTx:  v.BlockchainTx,
+		Assurance:     v.Assurance,
+		Confirmations: v.Confirmations,
+		Warning:       v.Warning,
+	}
 }
 
 // DIDStatus represents the current status of a DID
@@ -57,21 +205,100 @@ type DIDStatus string
 const (
 	DIDStatusPending DIDStatus = "pending"
 	DIDStatusActive  DIDStatus = "active"
+	// DIDStatusRevoked is a deactivated DID whose deactivation marker is anchored
+	// on-chain; it resolves with deactivated metadata and accepts no updates
 	DIDStatusRevoked DIDStatus = "revoked"
 	DIDStatusExpired DIDStatus = "expired"
 	DIDStatusFailed  DIDStatus = "failed"
+	// DIDStatusDeactivating is a DID whose deactivation marker is being anchored; it
+	// already accepts no updates and becomes revoked once the marker is on-chain
+	DIDStatusDeactivating DIDStatus = "deactivating"
+	// DIDStatusPendingReview holds a flagged DID in the review queue; it is registered
+	// on-chain once approved
+	DIDStatusPendingReview DIDStatus = "pending_review"
+	DIDStatusRejected      DIDStatus = "rejected"
+	// DIDStatusSuspended is an active DID put on hold, e.g. after a suspicious sign-in to its
+	// user's account; it verifies as invalid and accepts no updates until an administrator
+	// reinstates it. The chain is not touched.
+	DIDStatusSuspended DIDStatus = "suspended"
 )
 
-// DIDRepository defines the interface for DID data operations
+// Valid reports whether s is one of the DID statuses above
+func (s DIDStatus) Valid() bool {
+	switch s {
+	case DIDStatusPending, DIDStatusActive, DIDStatusRevoked, DIDStatusExpired, DIDStatusFailed,
+		DIDStatusDeactivating, DIDStatusPendingReview, DIDStatusRejected, DIDStatusSuspended:
+		return true
+	}
+	return false
+}
+
+// DIDDeactivateRequest is a request to deactivate a DID
+type DIDDeactivateRequest struct {
+	DID string `json:"did" binding:"required"`
+}
+
+// DIDDeactivationResponse reports a deactivation whose marker is anchored by the job JobID
+type DIDDeactivationResponse struct {
+	DID    string    `json:"did"`
+	Status string    `json:"status"`
+	JobID  uuid.UUID `json:"job_id"`
+}
+
+// DIDListRequest filters DIDs for administrators; empty fields match everything. Sort
+// is created_at for oldest first or -created_at, the default, for newest first.
+type DIDListRequest struct {
+	Status DIDStatus `form:"status"`
+	UserID string    `form:"user_id" binding:"omitempty,uuid"`
+	Method string    `form:"method" binding:"max=32"`
+	// SandboxTenant lists the DIDs of one sandbox instead of production DIDs
+	SandboxTenant string     `form:"sandbox_tenant" binding:"max=255"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=created_at -created_at"`
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// DIDListResponse is a page of DIDs
+type DIDListResponse struct {
+	DIDs  []*DID `json:"dids"`
+	Total int    `json:"total"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+}
+
+// DIDRepository defines the interface for DID data operations. Writes fail with
+// ErrInvalidDIDStatus for a status that is not a DIDStatus.
 type DIDRepository interface {
 	Create(did *DID) error
 	GetByID(id uuid.UUID) (*DID, error)
 	GetByDID(did string) (*DID, error)
+	// GetByAlias retrieves a DID by the current identifier aliasing its legacy identifier
+	GetByAlias(alias string) (*DID, error)
 	GetByUserID(userID uuid.UUID) (*DID, error)
-	GetByUserHash(userHash string) (*DID, error)
+	ListByUserID(userID uuid.UUID) ([]*DID, error)
 	Update(did *DID) error
-	UpdateStatus(id uuid.UUID, status string, txHash string) error
-	ListByStatus(status string) ([]*DID, error)
+	UpdateStatus(id uuid.UUID, status DIDStatus, txHash string) error
+	UpdateVisibility(id uuid.UUID, visibility string) error
+	// RotateKey replaces the stored key of a custodial DID
+	RotateKey(id uuid.UUID, keyMaterial string, keyAlgorithm string) error
+	ListByStatus(status DIDStatus) ([]*DID, error)
+	// List returns a page of DIDs matching req, together with the total number of matches
+	List(req *DIDListRequest, limit, offset int) ([]*DID, int, error)
+	// GetByEmailIndex retrieves the DID whose holder has the indexed email address,
+	// preferring active DIDs over older ones
+	GetByEmailIndex(emailIndex string) (*DID, error)
+	// SetEmailIndex records the blind index of a DID holder's email address
+	SetEmailIndex(id uuid.UUID, emailIndex string) error
+	// ListLegacyUnmigrated returns up to limit DIDs with a legacy identifier and no
+	// identifier migration, oldest first
+	ListLegacyUnmigrated(limit int) ([]*DID, error)
+	// SetUserID links a DID to another user account
+	SetUserID(id uuid.UUID, userID uuid.UUID) error
+	// DeleteSandbox deletes the DIDs of a sandbox partition and the credentials they
+	// issued or hold, returning how many DIDs were deleted
+	DeleteSandbox(sandboxTenant string) (int64, error)
 }
 
 // DIDService defines the interface for DID business logic
@@ -79,6 +306,6 @@ type DIDService interface {
 	CreateDID(req *DIDCreateRequest) (*DIDResponse, error)
 	VerifyDID(req *DIDVerificationRequest) (*DIDVerificationResponse, error)
 	GetDIDByUserID(userID uuid.UUID) (*DID, error)
-	UpdateDIDStatus(didID uuid.UUID, status string, txHash string) error
+	UpdateDIDStatus(didID uuid.UUID, status DIDStatus, txHash string) error
 	ProcessBlockchainQueue() error
 }
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// DIDMethod names a DID method, the second segment of a DID
+type DIDMethod string
+
+const (
+	DIDMethodExample DIDMethod = "example"
+	DIDMethodKey     DIDMethod = "key"
+	DIDMethodWeb     DIDMethod = "web"
+	DIDMethodEthr    DIDMethod = "ethr"
+	DIDMethodPeer    DIDMethod = "peer"
+	DIDMethodION     DIDMethod = "ion"
+)
+
+// KnownDIDMethods lists the methods reported in the capability matrix
+var KnownDIDMethods = []DIDMethod{
+	DIDMethodExample,
+	DIDMethodKey,
+	DIDMethodWeb,
+	DIDMethodEthr,
+	DIDMethodPeer,
+	DIDMethodION,
+}
+
+// DIDMethodCapability reports what the deployment can do with a DID method
+type DIDMethodCapability struct {
+	Method  DIDMethod `json:"method"`
+	Create  bool      `json:"create"`
+	Resolve bool      `json:"resolve"`
+	// Allowed reports whether the caller's method policy allows creating the method
+	Allowed bool `json:"allowed"`
+}
+
+// DIDMethodPolicy restricts the DID methods a tenant may create. The policy of the
+// empty tenant is the default for tenants without their own; without any policy every
+// creatable method is allowed.
+type DIDMethodPolicy struct {
+	Tenant    string      `json:"tenant"`
+	Methods   []DIDMethod `json:"methods"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// DIDMethodPolicyRequest represents a request to set a method policy
+type DIDMethodPolicyRequest struct {
+	Tenant  string      `json:"tenant" binding:"max=255"`
+	Methods []DIDMethod `json:"methods" binding:"required"`
+}
+
+// DIDMethodPolicyRepository defines the interface for DID method policy data operations
+type DIDMethodPolicyRepository interface {
+	List() ([]*DIDMethodPolicy, error)
+	// Effective returns the tenant's policy, falling back to the default policy, or
+	// nil when neither exists
+	Effective(tenant string) (*DIDMethodPolicy, error)
+	Upsert(policy *DIDMethodPolicy) error
+	// Delete removes a policy, returning ErrMethodPolicyNotFound when none exists
+	Delete(tenant string) error
+}
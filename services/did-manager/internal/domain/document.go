@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document is a file kept encrypted in the document vault, such as a diploma PDF.
+// Its owner, the DID that uploaded it, attaches it to the credentials it issues,
+// which reference it by ID and digest; the subjects of those credentials may then
+// download it as well.
+type Document struct {
+	ID          uuid.UUID `json:"id"`
+	OwnerDID    string    `json:"owner_did"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	// Digest is the hex-encoded SHA-256 of the content
+	Digest string `json:"digest"`
+	// KeyID names the key the stored object is encrypted with
+	KeyID     string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StorageName is the name of the document's object in the vault
+func (d *Document) StorageName() string {
+	return "documents/" + d.ID.String()
+}
+
+// DocumentURLRequest represents a request for an expiring download URL; without a TTL
+// the configured default applies
+type DocumentURLRequest struct {
+	TTLSeconds int `json:"ttl_seconds" binding:"omitempty,min=1"`
+}
+
+// DocumentURL is an expiring download URL. Anyone holding it can download the
+// document until it expires, so holders share it with the relying parties that
+// verify their credentials.
+type DocumentURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DocumentRepository defines the interface for document vault data operations
+type DocumentRepository interface {
+	Create(document *Document) error
+	// GetByID returns a document, or ErrDocumentNotFound
+	GetByID(id uuid.UUID) (*Document, error)
+	ListByOwner(ownerDID string) ([]*Document, error)
+	// Attach links documents to the credential that references them
+	Attach(credentialID uuid.UUID, documentIDs []uuid.UUID) error
+	// IsAttached reports whether a document is referenced by any credential
+	IsAttached(id uuid.UUID) (bool, error)
+	// IsAttachedForSubject reports whether a document is referenced by an active
+	// credential issued to the subject
+	IsAttachedForSubject(id uuid.UUID, subjectDID string) (bool, error)
+	Delete(id uuid.UUID) error
+}
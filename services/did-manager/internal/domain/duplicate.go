@@ -0,0 +1,41 @@
+package domain
+
+import "github.com/google/uuid"
+
+// DuplicateRule names a heuristic matching a new DID against existing identities
+type DuplicateRule string
+
+const (
+	// DuplicateRuleEmail matches the normalized email address
+	DuplicateRuleEmail DuplicateRule = "email"
+	// DuplicateRuleClaims matches the normalized name and email together
+	DuplicateRuleClaims DuplicateRule = "claims"
+)
+
+// DuplicateAction is what happens when a rule matches
+type DuplicateAction string
+
+const (
+	DuplicateActionOff DuplicateAction = "off"
+	// DuplicateActionReject refuses the new DID
+	DuplicateActionReject DuplicateAction = "reject"
+	// DuplicateActionReview creates the DID on hold in the manual review queue
+	DuplicateActionReview DuplicateAction = "review"
+)
+
+// DuplicateMatch is an existing DID matched by a rule
+type DuplicateMatch struct {
+	Rule  DuplicateRule `json:"rule"`
+	DIDID uuid.UUID     `json:"did_id"`
+	Did   string        `json:"did"`
+}
+
+// DuplicateRepository defines the interface for identity fingerprint data operations
+type DuplicateRepository interface {
+	// RecordFingerprints stores the fingerprints of a DID by rule, keyed with the given
+	// pepper version
+	RecordFingerprints(didID uuid.UUID, pepperVersion string, fingerprints map[DuplicateRule]string) error
+	// FindMatches finds non-revoked DIDs of other users with any of the given
+	// fingerprints under a rule
+	FindMatches(rule DuplicateRule, fingerprints []string, userID uuid.UUID) ([]*DuplicateMatch, error)
+}
@@ -0,0 +1,15 @@
+package domain
+
+// EmailLookupRequest asks whether a DID exists for an email address, e.g. before
+// inviting its holder
+type EmailLookupRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// EmailLookupResponse answers an email lookup. The DID is only included when the
+// caller may resolve it; private DIDs the caller has no access to are reported as not
+// found.
+type EmailLookupResponse struct {
+	Exists bool   `json:"exists"`
+	DID    string `json:"did,omitempty"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "encoding/json"
+
+// EncryptToDIDRequest asks to encrypt a payload to the keyAgreement key of a DID, so
+// that only its holder can read it
+type EncryptToDIDRequest struct {
+	DID string `json:"did" binding:"required,max=255"`
+	// Payload is the JSON value to encrypt
+	Payload json.RawMessage `json:"payload" binding:"required"`
+}
+
+// EncryptedPayload is a payload encrypted to a DID as a compact JWE
+type EncryptedPayload struct {
+	DID string `json:"did"`
+	// KeyID is the keyAgreement verification method the payload is encrypted to
+	KeyID string `json:"kid"`
+	JWE   string `json:"jwe"`
+}
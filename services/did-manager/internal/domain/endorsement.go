@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Endorsement is a signed statement by one DID vouching for another, e.g. an employer
+// endorsing an employee. Endorsements form a directed graph from endorser to subject.
+type Endorsement struct {
+	ID          uuid.UUID `json:"id"`
+	EndorserDID string    `json:"endorser_did"`
+	SubjectDID  string    `json:"subject_did"`
+	Type        string    `json:"type"`
+	Statement   string    `json:"statement,omitempty"`
+	// Signature is the endorser's signature over the endorsement message
+	Signature          string     `json:"signature"`
+	KeyAlgorithm       string     `json:"key_algorithm"`
+	VerificationMethod string     `json:"verification_method"`
+	CreatedAt          time.Time  `json:"created_at"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+}
+
+// EndorsementCreateRequest represents a request by a DID to endorse another DID. The
+// signature covers the message built from the endorser, subject, type and statement.
+type EndorsementCreateRequest struct {
+	SubjectDID string `json:"subject_did" binding:"required"`
+	Type       string `json:"type" binding:"required,max=100"`
+	Statement  string `json:"statement" binding:"max=1000"`
+	Signature  string `json:"signature" binding:"required,hexadecimal"`
+}
+
+// EndorsementFilter selects endorsements; empty fields match everything
+type EndorsementFilter struct {
+	EndorserDID    string `form:"endorser"`
+	SubjectDID     string `form:"subject"`
+	Type           string `form:"type"`
+	IncludeRevoked bool   `form:"include_revoked"`
+}
+
+// EndorsementGraph is the neighbourhood of a DID in the endorsement graph
+type EndorsementGraph struct {
+	Root  string         `json:"root"`
+	Nodes []string       `json:"nodes"`
+	Edges []*Endorsement `json:"edges"`
+}
+
+// EndorsementRepository defines the interface for endorsement data operations
+type EndorsementRepository interface {
+	Create(endorsement *Endorsement) error
+	GetByID(id uuid.UUID) (*Endorsement, error)
+	// GetActive retrieves the active endorsement of subject by endorser under type
+	GetActive(endorserDID, subjectDID, endorsementType string) (*Endorsement, error)
+	List(filter *EndorsementFilter) ([]*Endorsement, error)
+	// ListTouching lists active endorsements made by or about any of dids
+	ListTouching(dids []string) ([]*Endorsement, error)
+	Revoke(id uuid.UUID, revokedAt time.Time) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestDIDStatusValid(t *testing.T) {
+	for _, status := range []DIDStatus{
+		DIDStatusPending, DIDStatusActive, DIDStatusRevoked, DIDStatusExpired, DIDStatusFailed,
+		DIDStatusDeactivating, DIDStatusPendingReview, DIDStatusRejected, DIDStatusSuspended,
+	} {
+		if !status.Valid() {
+			t.Errorf("expected %q to be valid", status)
+		}
+	}
+	for _, status := range []DIDStatus{"", "Active", "deleted", "not_found"} {
+		if status.Valid() {
+			t.Errorf("expected %q to be rejected", status)
+		}
+	}
+}
+
+func TestJobStatusValid(t *testing.T) {
+	for _, status := range []JobStatus{
+		JobStatusPending, JobStatusProcessing, JobStatusCompleted, JobStatusFailed,
+		JobStatusRetrying, JobStatusSimulated, JobStatusCanceled,
+	} {
+		if !status.Valid() {
+			t.Errorf("expected %q to be valid", status)
+		}
+	}
+	for _, status := range []JobStatus{"", "done", "cancelled"} {
+		if status.Valid() {
+			t.Errorf("expected %q to be rejected", status)
+		}
+	}
+}
+
+func TestJobTypeValid(t *testing.T) {
+	for _, jobType := range []JobType{JobTypeRegisterDID, JobTypeUpdateDID, JobTypeRevokeDID, JobTypeNotarize} {
+		if !jobType.Valid() {
+			t.Errorf("expected %q to be valid", jobType)
+		}
+	}
+	for _, jobType := range []JobType{"", "register", "REVOKE_DID"} {
+		if jobType.Valid() {
+			t.Errorf("expected %q to be rejected", jobType)
+		}
+	}
+}
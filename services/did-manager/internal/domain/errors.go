@@ -0,0 +1,132 @@
+package domain
+
+import "errors"
+
+// Sentinel errors shared between repositories, services and handlers
+var (
+	ErrDIDNotFound          = errors.New("DID not found")
+	ErrAPIKeyNotFound       = errors.New("API key not found")
+	ErrACLEntryNotFound     = errors.New("ACL entry not found")
+	ErrUnauthenticated      = errors.New("invalid caller credentials")
+	ErrCredentialNotFound   = errors.New("credential not found")
+	ErrForbidden            = errors.New("caller is not allowed to perform this operation")
+	ErrDeviceNotFound       = errors.New("push device not found")
+	ErrDeviceKeyNotFound    = errors.New("device key not found")
+	ErrNotarizationNotFound = errors.New("notarization not found")
+	ErrInvalidSignature     = errors.New("signature does not verify")
+	ErrNotCustodial         = errors.New("DID keys are held by its owner")
+
+	ErrVerificationSessionNotFound = errors.New("verification session not found")
+	ErrVerificationSessionClosed   = errors.New("verification session is no longer open")
+	ErrNoAnchoredRevocationRoot    = errors.New("no revocation root has been anchored yet")
+	ErrCustodyTransferNotFound     = errors.New("custody transfer not found")
+	ErrCustodyTransferClosed       = errors.New("custody transfer is no longer open")
+	ErrOperationNotFound           = errors.New("operation not found")
+	ErrOperationClosed             = errors.New("operation is no longer pending")
+	ErrNotOrganization             = errors.New("DID has no controllers")
+	ErrEndorsementNotFound         = errors.New("endorsement not found")
+	ErrEndorsementExists           = errors.New("an active endorsement of this type already exists")
+	ErrRenewalPolicyNotFound       = errors.New("renewal policy not found")
+	ErrJobNotFound                 = errors.New("blockchain job not found")
+	ErrArchivedJobNotFound         = errors.New("archived job not found")
+	ErrIdentifierMigrationNotFound = errors.New("identifier migration not found")
+	ErrJobNotClaimable             = errors.New("blockchain job is no longer pending")
+	ErrJobNotCancelable            = errors.New("blockchain job can no longer be canceled")
+	ErrBlockchainUnavailable       = errors.New("blockchain client is not configured")
+	ErrQueueUnavailable            = errors.New("job queue is not connected")
+	ErrUnknownCapability           = errors.New("unknown capability")
+	ErrReportSigningDisabled       = errors.New("compliance report signing key is not configured")
+	ErrStatusTokensDisabled        = errors.New("status token signing key is not configured")
+	ErrUnknownFeature              = errors.New("unknown feature flag")
+	ErrFeatureOverrideNotFound     = errors.New("feature override not found")
+	ErrRejectedByHook              = errors.New("rejected by plugin hook")
+	ErrIdentityRejected            = errors.New("identity rejected by screening")
+	ErrDuplicateIdentity           = errors.New("identity is already registered")
+	ErrReviewRequired              = errors.New("operation requires manual review")
+	ErrReviewNotFound              = errors.New("review not found")
+	ErrReviewClosed                = errors.New("review is already resolved")
+	ErrQuotaExceeded               = errors.New("tenant usage quota exceeded")
+	ErrQuotaNotFound               = errors.New("quota not found")
+	ErrReadOnly                    = errors.New("deployment is a read-only standby")
+	ErrNotStandby                  = errors.New("deployment is not a standby")
+	ErrSubscriptionNotFound        = errors.New("replication subscription not found")
+	ErrSidetreeDisabled            = errors.New("sidetree batching is not enabled")
+	ErrUnsupportedDIDMethod        = errors.New("DID method cannot be created by this deployment")
+	ErrDIDMethodNotAllowed         = errors.New("DID method is not allowed for this tenant")
+	ErrMethodPolicyNotFound        = errors.New("DID method policy not found")
+	ErrInvalidCursor               = errors.New("invalid change cursor")
+	ErrCursorExpired               = errors.New("change cursor has expired")
+	ErrChangeFeedUnavailable       = errors.New("change feed requires the event stream")
+	ErrChainUnreachable            = errors.New("blockchain is unreachable and the verification policy fails closed")
+	ErrVerificationPolicyNotFound  = errors.New("verification policy not found")
+	ErrClaimsAttemptsExceeded      = errors.New("too many claims verification attempts for this DID")
+	ErrVerificationNonceInvalid    = errors.New("verification nonce is unknown, expired or already used")
+	ErrStepUpRequired              = errors.New("step-up authentication required")
+	ErrStepUpChallengeInvalid      = errors.New("step-up challenge is unknown, expired or already used")
+	ErrDeviceKeyLimit              = errors.New("DID has too many device keys")
+	ErrInvalidDeviceKey            = errors.New("invalid device key")
+	ErrInvalidAttestation          = errors.New("app attestation does not verify")
+	ErrAttestationRequired         = errors.New("request must come from an attested app instance")
+	ErrNotSandbox                  = errors.New("caller is not a sandbox API key")
+	ErrEmailLookupDisabled         = errors.New("email lookup requires an email index key")
+	ErrWebhookNotFound             = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidWebhook              = errors.New("invalid webhook configuration")
+	ErrInvalidWebhookReplay        = errors.New("invalid webhook replay")
+	ErrWebhookReplayUnavailable    = errors.New("webhook replay requires the event stream")
+	ErrAnonymousDIDsDisabled       = errors.New("anonymous DIDs are not enabled for this tenant")
+	ErrInvalidDIDRequest           = errors.New("invalid DID creation request")
+	ErrInvalidAgeCredential        = errors.New("invalid age credential request")
+	ErrDocumentNotFound            = errors.New("document not found")
+	ErrDocumentVaultDisabled       = errors.New("document vault is not configured")
+	ErrInvalidDocument             = errors.New("invalid document")
+	ErrDocumentAttached            = errors.New("document is attached to a credential")
+	ErrDocumentURLInvalid          = errors.New("document URL is invalid or has expired")
+	ErrBackupNotFound              = errors.New("backup not found")
+	ErrBackupsDisabled             = errors.New("backups are not configured")
+	ErrInvalidBackup               = errors.New("backup snapshot is invalid")
+	ErrConformanceModeDisabled     = errors.New("conformance mode is not enabled")
+	ErrDIDDeactivated              = errors.New("DID is deactivated")
+	ErrSmartAccountsDisabled       = errors.New("smart accounts require a bundler")
+	ErrSmartAccountNotFound        = errors.New("smart account not found")
+	ErrSmartAccountExists          = errors.New("DID already has a smart account")
+	ErrInvalidUserOperation        = errors.New("invalid user operation")
+	ErrAnalyticsExportDisabled     = errors.New("analytics export is not configured")
+	ErrNoKeyAgreementKey           = errors.New("DID has no key agreement key")
+	ErrUnknownIncidentFlag         = errors.New("unknown incident flag")
+	ErrStatusIncidentNotFound      = errors.New("incident flag is not raised")
+	ErrCostBudgetNotFound          = errors.New("cost budget not found")
+	ErrBudgetExceeded              = errors.New("cost budget exceeded")
+	ErrChainWriteThrottled         = errors.New("chain write rate limit exceeded")
+	ErrSubKeyNotAllowed            = errors.New("sub-keys cannot manage API keys")
+	ErrInvalidDIDStatus            = errors.New("unknown DID status")
+	ErrInvalidJobStatus            = errors.New("unknown job status")
+	ErrInvalidJobType              = errors.New("unknown job type")
+	ErrDIDNotAnchored              = errors.New("DID is not anchored yet")
+	ErrAnchoringPaused             = errors.New("anchoring is paused")
+	ErrAnchoringNotPaused          = errors.New("anchoring is not paused")
+	ErrTrustedIssuerExists         = errors.New("issuer is already in the trust registry")
+	ErrTrustedIssuerNotFound       = errors.New("issuer is not in the trust registry")
+	ErrTrustRegistryUnsigned       = errors.New("trust registry snapshots are not configured")
+	ErrIssuanceRuleNotFound        = errors.New("issuance rule not found")
+	ErrIssuanceNotAllowed          = errors.New("no issuance rule allows this credential")
+	ErrProfileNotFound             = errors.New("profile not found")
+	ErrSocialProofsDisabled        = errors.New("social account proofs are not configured")
+	ErrSocialPlatformUnsupported   = errors.New("social platform is not supported")
+	ErrSocialChallengeNotFound     = errors.New("social proof challenge is unknown, expired or already used")
+	ErrSocialProofInvalid          = errors.New("social account proof is invalid")
+	ErrClaimNotFound               = errors.New("claim not found")
+	ErrInvalidClaimType            = errors.New("invalid claim type")
+	ErrBulkRevocationNotFound      = errors.New("bulk revocation not found")
+	ErrBulkRevocationState         = errors.New("bulk revocation is not in the required stage")
+	ErrBulkRevocationTooLarge      = errors.New("bulk revocation has too many targets")
+	ErrInvalidConsistencyToken     = errors.New("invalid consistency token")
+	ErrReplicaBehind               = errors.New("database has not applied the write yet")
+	ErrRootDIDInUse                = errors.New("DID already holds the root capability of another tenant")
+	ErrInvalidRootDID              = errors.New("root DID is not a valid DID")
+	ErrWalletOfferNotFound         = errors.New("wallet offer not found")
+	ErrWalletOfferClosed           = errors.New("wallet offer was already claimed or expired")
+	ErrDIDNotActive                = errors.New("DID is not active yet")
+	ErrFunnelRangeTooLong          = errors.New("funnel range spans too many days")
+	ErrUnsupportedLocale           = errors.New("unsupported locale")
+)
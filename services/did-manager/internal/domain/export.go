@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDataExport is the data held about a user, for data-subject access requests
+type UserDataExport struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	DIDs        []*DIDExport `json:"dids"`
+}
+
+// DIDExport is one of a user's DIDs with the data attached to it. The key material of
+// custodial DIDs is left out; the document carries their public key.
+type DIDExport struct {
+	Record *DID `json:"record"`
+	// Document is the DID's current resolution
+	Document *DIDResolutionResult `json:"document,omitempty"`
+	// Credentials are the credentials issued to the DID
+	Credentials []*Credential `json:"credentials"`
+	// AccessGrants are the callers allowed to resolve the DID while it is private
+	AccessGrants []*ACLEntry `json:"access_grants"`
+	// AuditTrail lists the DID's blockchain jobs with their timelines, oldest first
+	AuditTrail []*JobAuditEntry `json:"audit_trail"`
+}
+
+// JobAuditEntry is a blockchain job run for a DID with its timeline
+type JobAuditEntry struct {
+	Job    *BlockchainJob   `json:"job"`
+	Phases []*JobPhaseEvent `json:"phases"`
+}
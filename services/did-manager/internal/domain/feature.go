@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// FeatureFlag names a feature that can be toggled at runtime
+type FeatureFlag string
+
+const (
+	// FeaturePQSignatures allows experimental post-quantum signature suites for new keys
+	FeaturePQSignatures FeatureFlag = "pq_signatures"
+	// FeatureRevocationAnchoring anchors revocation accumulator roots on-chain in batches
+	FeatureRevocationAnchoring FeatureFlag = "revocation_anchoring"
+	// FeatureAnonymousDIDs allows creating DIDs without name, email or claims commitment
+	FeatureAnonymousDIDs FeatureFlag = "anonymous_dids"
+)
+
+// FeatureDescriptions documents every known feature flag
+var FeatureDescriptions = map[FeatureFlag]string{
+	FeaturePQSignatures:        "Experimental post-quantum signature suites (ML-DSA-65, Ed25519+ML-DSA-65 hybrid) for new keys",
+	FeatureRevocationAnchoring: "Batched anchoring of credential revocation roots on-chain",
+	FeatureAnonymousDIDs:       "Anonymous DIDs created without PII, keyed by a client-supplied or generated key",
+}
+
+// Feature flag sources, from lowest to highest precedence
+const (
+	FeatureSourceConfig         = "config"
+	FeatureSourceGlobalOverride = "global_override"
+	FeatureSourceTenantOverride = "tenant_override"
+)
+
+// FeatureOverride overrides the configured state of a flag, globally when Tenant is
+// empty or for a single tenant. Tenants are callers: an API key ID or a DID.
+type FeatureOverride struct {
+	Flag      FeatureFlag `json:"flag"`
+	Tenant    string      `json:"tenant"`
+	Enabled   bool        `json:"enabled"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// FeatureOverrideRequest represents a request to override a flag
+type FeatureOverrideRequest struct {
+	Tenant  string `json:"tenant" binding:"max=255"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// FeatureState is the effective state of a flag for a tenant
+type FeatureState struct {
+	Flag        FeatureFlag `json:"flag"`
+	Description string      `json:"description"`
+	Enabled     bool        `json:"enabled"`
+	// Source is where the state comes from: config or a global or tenant override
+	Source string `json:"source"`
+}
+
+// FeatureFlagRepository defines the interface for feature override data operations
+type FeatureFlagRepository interface {
+	List() ([]*FeatureOverride, error)
+	Upsert(override *FeatureOverride) error
+	// Delete removes an override, returning ErrFeatureOverrideNotFound when none exists
+	Delete(flag FeatureFlag, tenant string) error
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// FunnelStage is a stage of the DID funnel, from creation to first use
+type FunnelStage string
+
+const (
+	FunnelCreated  FunnelStage = "created"
+	FunnelAnchored FunnelStage = "anchored"
+	// FunnelVerified DIDs were verified at least once, by any caller
+	FunnelVerified FunnelStage = "verified"
+	// FunnelCredentialIssued DIDs hold at least one credential
+	FunnelCredentialIssued FunnelStage = "credential_issued"
+)
+
+// FunnelStages are the funnel stages in order
+var FunnelStages = []FunnelStage{FunnelCreated, FunnelAnchored, FunnelVerified, FunnelCredentialIssued}
+
+// FunnelDay counts the DIDs a tenant created on a day (UTC) that reached each stage of
+// the funnel so far. Stages are counted independently: a DID verified before its
+// anchoring completed counts as verified but not anchored.
+type FunnelDay struct {
+	Day              string `json:"day"`
+	Tenant           string `json:"tenant"`
+	Created          int    `json:"created"`
+	Anchored         int    `json:"anchored"`
+	Verified         int    `json:"verified"`
+	CredentialIssued int    `json:"credential_issued"`
+}
+
+// Count returns the number of DIDs that reached a stage
+func (d *FunnelDay) Count(stage FunnelStage) int {
+	switch stage {
+	case FunnelCreated:
+		return d.Created
+	case FunnelAnchored:
+		return d.Anchored
+	case FunnelVerified:
+		return d.Verified
+	case FunnelCredentialIssued:
+		return d.CredentialIssued
+	default:
+		return 0
+	}
+}
+
+// Conversion returns the share of the day's created DIDs that reached a stage
+func (d *FunnelDay) Conversion(stage FunnelStage) float64 {
+	if d.Created == 0 {
+		return 0
+	}
+	return float64(d.Count(stage)) / float64(d.Created)
+}
+
+// FunnelReport is the DID funnel of each tenant and day over a range of days
+type FunnelReport struct {
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Days        []*FunnelDay `json:"days"`
+}
+
+// FunnelRequest selects the days, inclusive, and optionally the tenant of a funnel report
+type FunnelRequest struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02"`
+	To     time.Time `form:"to" binding:"required,gtefield=From" time_format:"2006-01-02"`
+	Tenant string    `form:"tenant"`
+}
+
+// FunnelRepository defines the interface for DID funnel data operations
+type FunnelRepository interface {
+	// Funnel counts the DIDs whose creation was metered over [from, to) per tenant and
+	// day, by the stages they reached, ordered by day and tenant
+	Funnel(from, to time.Time, tenant string) ([]*FunnelDay, error)
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "testing"
+
+func TestFunnelDayStages(t *testing.T) {
+	day := &FunnelDay{Day: "2026-01-02", Tenant: "tenant-1", Created: 4, Anchored: 3, Verified: 2, CredentialIssued: 1}
+
+	want := map[FunnelStage]int{FunnelCreated: 4, FunnelAnchored: 3, FunnelVerified: 2, FunnelCredentialIssued: 1}
+	if len(FunnelStages) != len(want) {
+		t.Fatalf("expected %d stages, got %d", len(want), len(FunnelStages))
+	}
+	for _, stage := range FunnelStages {
+		if got := day.Count(stage); got != want[stage] {
+			t.Errorf("%s: expected %d DIDs, got %d", stage, want[stage], got)
+		}
+	}
+	if got := day.Count("activated"); got != 0 {
+		t.Errorf("expected unknown stages to count nothing, got %d", got)
+	}
+
+	if got := day.Conversion(FunnelVerified); got != 0.5 {
+		t.Errorf("expected half of the DIDs verified, got %v", got)
+	}
+	if got := (&FunnelDay{}).Conversion(FunnelAnchored); got != 0 {
+		t.Errorf("expected no conversion without DIDs, got %v", got)
+	}
+}
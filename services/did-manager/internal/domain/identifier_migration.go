@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identifier migration statuses, derived from the migration and its update job
+const (
+	// IdentifierMigrationUnmigratable DIDs no longer store the inception key their
+	// current identifier would commit to, and keep only their legacy identifier
+	IdentifierMigrationUnmigratable = "unmigratable"
+	// IdentifierMigrationUnanchored migrations have not been anchored yet, waiting for
+	// their DID to be active
+	IdentifierMigrationUnanchored = "unanchored"
+	IdentifierMigrationAnchoring  = "anchoring"
+	IdentifierMigrationAnchored   = "anchored"
+	// IdentifierMigrationFailed migrations have an update job that failed or was
+	// canceled; retrying the job anchors them
+	IdentifierMigrationFailed = "failed"
+)
+
+// IdentifierMigration maps the legacy identifier of a DID to the identifier of the
+// current format. The legacy identifier stays the DID's identifier, which credentials
+// and access lists refer to; the new one is its alias, resolving to the same DID, and
+// is anchored on-chain for the DID's user hash by an update_did job.
+type IdentifierMigration struct {
+	DIDID      uuid.UUID `json:"did_id"`
+	LegacyDID  string    `json:"legacy_did"`
+	FromFormat string    `json:"from_format"`
+	// NewDID is empty for unmigratable DIDs
+	NewDID   string `json:"new_did,omitempty"`
+	ToFormat string `json:"to_format,omitempty"`
+	// Error explains why a DID is unmigratable
+	Error  string     `json:"error,omitempty"`
+	JobID  *uuid.UUID `json:"job_id,omitempty"`
+	Status string     `json:"status"`
+	// TxHash is the transaction anchoring the new identifier
+	TxHash    string    `json:"tx_hash,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdentifierMigrationQuery filters identifier migrations for administrators; empty
+// fields match everything. Migrations are listed oldest first.
+type IdentifierMigrationQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=unmigratable unanchored anchoring anchored failed"`
+	// DID matches the legacy or the new identifier
+	DID   string `form:"did" binding:"max=255"`
+	Page  int    `form:"page" binding:"omitempty,min=1"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// IdentifierMigrationPage is a page of identifier migrations
+type IdentifierMigrationPage struct {
+	Migrations []*IdentifierMigration `json:"migrations"`
+	Total      int                    `json:"total"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+}
+
+// IdentifierMigrationRun reports a run of the identifier migration: legacy DIDs given
+// their current identifier as an alias, those whose inception key is no longer stored
+// so that no alias can be derived, and the update jobs enqueued to anchor aliases
+type IdentifierMigrationRun struct {
+	Migrated     int `json:"migrated"`
+	Unmigratable int `json:"unmigratable"`
+	Anchoring    int `json:"anchoring"`
+}
+
+// IdentifierMigrationRepository defines the interface for identifier migration data
+// operations
+type IdentifierMigrationRepository interface {
+	// Record stores a migration and sets the alias of its DID to the new identifier in
+	// one transaction
+	Record(migration *IdentifierMigration) error
+	// Get retrieves the migration of a DID, returning ErrIdentifierMigrationNotFound
+	// when none exists
+	Get(didID uuid.UUID) (*IdentifierMigration, error)
+	// ListUnanchored returns up to limit migrations of active DIDs with a new
+	// identifier and no update job, oldest first
+	ListUnanchored(limit int) ([]*IdentifierMigration, error)
+	// SetJob records the update job anchoring a migration
+	SetJob(didID, jobID uuid.UUID) error
+	// List returns a page of migrations matching query, together with the total number
+	// of matches
+	List(query *IdentifierMigrationQuery, limit, offset int) ([]*IdentifierMigration, int, error)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IssuanceSubjects is the population of subjects an issuance rule lets issuers issue to
+type IssuanceSubjects string
+
+const (
+	// IssuanceSubjectsAny allows any subject
+	IssuanceSubjectsAny IssuanceSubjects = "any"
+	// IssuanceSubjectsOwnTenant allows subjects created by the issuer's own tenant
+	IssuanceSubjectsOwnTenant IssuanceSubjects = "own_tenant"
+	// IssuanceSubjectsPrefixes allows subjects whose DID starts with one of the rule's
+	// subject prefixes
+	IssuanceSubjectsPrefixes IssuanceSubjects = "prefixes"
+)
+
+// IssuanceRule allows issuers of a tenant to issue some credential types to a subject
+// population. The tenant of an issuer is the API key that created its DID. Once any
+// rule applies to a tenant, its issuers only issue what a rule allows; tenants without
+// rules issue freely.
+type IssuanceRule struct {
+	ID uuid.UUID `json:"id"`
+	// Tenant is the API key ID the rule applies to; empty applies to every tenant
+	Tenant string `json:"tenant"`
+	// IssuerPrefix limits the rule to issuer DIDs starting with it; empty allows any of
+	// the tenant's issuers
+	IssuerPrefix string `json:"issuer_prefix"`
+	// CredentialTypes limits the rule to some types; empty allows every type
+	CredentialTypes []string         `json:"credential_types"`
+	Subjects        IssuanceSubjects `json:"subjects"`
+	SubjectPrefixes []string         `json:"subject_prefixes,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// Allows reports whether the rule lets issuerDID issue credentialType to subjectDID.
+// sameTenant tells whether the subject was created by the issuer's tenant.
+func (r *IssuanceRule) Allows(issuerDID, credentialType, subjectDID string, sameTenant bool) bool {
+	if !strings.HasPrefix(issuerDID, r.IssuerPrefix) {
+		return false
+	}
+	if len(r.CredentialTypes) > 0 && !slices.Contains(r.CredentialTypes, credentialType) {
+		return false
+	}
+
+	switch r.Subjects {
+	case IssuanceSubjectsAny:
+		return true
+	case IssuanceSubjectsOwnTenant:
+		return sameTenant
+	case IssuanceSubjectsPrefixes:
+		for _, prefix := range r.SubjectPrefixes {
+			if strings.HasPrefix(subjectDID, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IssuanceRuleRequest represents a request to add an issuance rule
+type IssuanceRuleRequest struct {
+	Tenant          string           `json:"tenant" binding:"max=255"`
+	IssuerPrefix    string           `json:"issuer_prefix" binding:"omitempty,startswith=did:,max=255"`
+	CredentialTypes []string         `json:"credential_types" binding:"max=50,dive,required,max=100"`
+	Subjects        IssuanceSubjects `json:"subjects" binding:"required,oneof=any own_tenant prefixes"`
+	SubjectPrefixes []string         `json:"subject_prefixes" binding:"required_if=Subjects prefixes,max=50,dive,startswith=did:,max=255"`
+}
+
+// IssuanceRuleRepository defines the interface for issuance rule data operations
+type IssuanceRuleRepository interface {
+	List() ([]*IssuanceRule, error)
+	// ListForTenant lists the rules of a tenant along with those of every tenant
+	ListForTenant(tenant string) ([]*IssuanceRule, error)
+	Create(rule *IssuanceRule) error
+	// Delete removes a rule, returning ErrIssuanceRuleNotFound when none exists
+	Delete(id uuid.UUID) (*IssuanceRule, error)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import "testing"
+
+func TestIssuanceRuleAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       IssuanceRule
+		issuer     string
+		credType   string
+		subject    string
+		sameTenant bool
+		want       bool
+	}{
+		{
+			name:    "any subject",
+			rule:    IssuanceRule{Subjects: IssuanceSubjectsAny},
+			issuer:  "did:example:issuer",
+			subject: "did:web:elsewhere.example",
+			want:    true,
+		},
+		{
+			name:     "listed type",
+			rule:     IssuanceRule{CredentialTypes: []string{"EmployeeCredential"}, Subjects: IssuanceSubjectsAny},
+			credType: "EmployeeCredential",
+			want:     true,
+		},
+		{
+			name:     "unlisted type",
+			rule:     IssuanceRule{CredentialTypes: []string{"EmployeeCredential"}, Subjects: IssuanceSubjectsAny},
+			credType: "DegreeCredential",
+			want:     false,
+		},
+		{
+			name:   "issuer outside prefix",
+			rule:   IssuanceRule{IssuerPrefix: "did:example:hr", Subjects: IssuanceSubjectsAny},
+			issuer: "did:example:sales",
+			want:   false,
+		},
+		{
+			name:       "own tenant subject",
+			rule:       IssuanceRule{Subjects: IssuanceSubjectsOwnTenant},
+			sameTenant: true,
+			want:       true,
+		},
+		{
+			name:       "other tenant subject",
+			rule:       IssuanceRule{Subjects: IssuanceSubjectsOwnTenant},
+			sameTenant: false,
+			want:       false,
+		},
+		{
+			name:    "subject within prefix",
+			rule:    IssuanceRule{Subjects: IssuanceSubjectsPrefixes, SubjectPrefixes: []string{"did:web:a.example", "did:web:b.example"}},
+			subject: "did:web:b.example:users:42",
+			want:    true,
+		},
+		{
+			name:       "subject outside prefix",
+			rule:       IssuanceRule{Subjects: IssuanceSubjectsPrefixes, SubjectPrefixes: []string{"did:web:a.example"}},
+			subject:    "did:web:c.example",
+			sameTenant: true,
+			want:       false,
+		},
+		{
+			name: "unknown subjects",
+			rule: IssuanceRule{Subjects: "everyone"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Allows(tt.issuer, tt.credType, tt.subject, tt.sameTenant); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedJob is a finished blockchain job moved from the job queue into the job
+// archive, kept as anchoring evidence for compliance. The job and its timeline are
+// stored compressed; the columns identifying the DID, transaction and times are kept
+// alongside so the archive can be queried.
+type ArchivedJob struct {
+	ID          uuid.UUID  `json:"id"`
+	JobType     string     `json:"job_type"`
+	DIDID       uuid.UUID  `json:"did_id"`
+	DID         string     `json:"did"`
+	Status      string     `json:"status"`
+	TxHash      string     `json:"tx_hash,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	ArchivedAt  time.Time  `json:"archived_at"`
+	// Record is the job with its timeline as they were when archived
+	Record *JobAuditEntry `json:"record"`
+}
+
+// JobArchiveQuery filters the job archive for administrators; empty fields match
+// everything. Jobs are listed oldest first.
+type JobArchiveQuery struct {
+	DID             string     `form:"did" binding:"max=255"`
+	TxHash          string     `form:"tx_hash" binding:"max=66"`
+	JobType         JobType    `form:"job_type" binding:"max=50"`
+	ProcessedAfter  *time.Time `form:"processed_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	ProcessedBefore *time.Time `form:"processed_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page            int        `form:"page" binding:"omitempty,min=1"`
+	Limit           int        `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// JobArchivePage is a page of archived jobs
+type JobArchivePage struct {
+	Jobs  []*ArchivedJob `json:"jobs"`
+	Total int            `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+// JobArchiveRun reports a run of the job archiver: the jobs finished before Cutoff
+// that it moved into the archive
+type JobArchiveRun struct {
+	Archived int       `json:"archived"`
+	Cutoff   time.Time `json:"cutoff"`
+}
+
+// JobArchiveRepository defines the interface for job archive data operations
+type JobArchiveRepository interface {
+	// ArchiveFinished moves up to limit completed, canceled and simulated jobs that
+	// finished before cutoff, with their timelines, from the job queue into the
+	// archive in one transaction, returning how many were moved. Jobs referenced by a
+	// notarization and completed jobs whose DID is still pending stay in the queue.
+	ArchiveFinished(cutoff time.Time, limit int) (int, error)
+	// Get retrieves an archived job, returning ErrArchivedJobNotFound when none exists
+	Get(id uuid.UUID) (*ArchivedJob, error)
+	// List returns a page of archived jobs matching query, together with the total
+	// number of matches
+	List(query *JobArchiveQuery, limit, offset int) ([]*ArchivedJob, int, error)
+	// ListByDIDID retrieves the archived jobs of a DID, oldest first
+	ListByDIDID(didID uuid.UUID) ([]*ArchivedJob, error)
+}
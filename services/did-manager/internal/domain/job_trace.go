@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobPhase is a step in the life of a blockchain job
+type JobPhase string
+
+const (
+	JobPhaseEnqueued  JobPhase = "enqueued"
+	JobPhasePublished JobPhase = "published"
+	JobPhaseClaimed   JobPhase = "claimed"
+	JobPhaseSubmitted JobPhase = "submitted" // transaction sent, Detail carries its hash
+	JobPhaseConfirmed JobPhase = "confirmed" // transaction mined
+	JobPhaseSimulated JobPhase = "simulated"
+	JobPhaseFailed    JobPhase = "failed" // Detail carries the error
+	JobPhaseRetrying  JobPhase = "retrying"
+	JobPhaseCanceled  JobPhase = "canceled" // Detail carries the reason or compensating job
+)
+
+// JobPhaseEvent records when a job entered a phase. Each phase is a span of the job's
+// trace.
+type JobPhaseEvent struct {
+	Phase      JobPhase  `json:"phase" db:"phase"`
+	SpanID     string    `json:"span_id" db:"span_id"`
+	Detail     string    `json:"detail,omitempty" db:"detail"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// JobTimeline is the trace of a blockchain job with the latency of each stage
+type JobTimeline struct {
+	JobID   uuid.UUID        `json:"job_id"`
+	TraceID string           `json:"trace_id,omitempty"`
+	Status  string           `json:"status"`
+	Phases  []*JobPhaseEvent `json:"phases"`
+	Latency JobLatency       `json:"latency"`
+}
+
+// JobLatency attributes a job's latency to its stages, in milliseconds. A stage is
+// omitted until the job completes it; for retried jobs the stages end at the last
+// attempt, so Queued includes the earlier attempts.
+type JobLatency struct {
+	// Queued is the time from enqueueing until a worker claimed the job
+	Queued *int64 `json:"queued_ms,omitempty"`
+	// Submission is the time from the claim until the transaction was sent
+	Submission *int64 `json:"submission_ms,omitempty"`
+	// Confirmation is the time from sending the transaction until it was mined
+	Confirmation *int64 `json:"confirmation_ms,omitempty"`
+	// Total is the time from enqueueing until confirmation
+	Total *int64 `json:"total_ms,omitempty"`
+}
+
+// NewTraceID generates a W3C Trace Context trace ID
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+// NewSpanID generates a W3C Trace Context span ID
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotarizationStatus represents the anchoring state of a notarization
+type NotarizationStatus string
+
+const (
+	NotarizationStatusPending  NotarizationStatus = "pending"
+	NotarizationStatusAnchored NotarizationStatus = "anchored"
+	NotarizationStatusFailed   NotarizationStatus = "failed"
+)
+
+// Notarization records a document hash signed by a DID and anchored on-chain through
+// the blockchain job pipeline. Together with the anchoring transaction it proves the
+// DID held the document no later than the block time.
+type Notarization struct {
+	ID           uuid.UUID `json:"id"`
+	DIDID        uuid.UUID `json:"-"`
+	Did          string    `json:"did"`
+	DocumentHash string    `json:"document_hash"` // hex SHA-256
+	// Signature is the DID's signature over the raw 32-byte document hash
+	Signature          string     `json:"signature"`
+	KeyAlgorithm       string     `json:"key_algorithm"`
+	VerificationMethod string     `json:"verification_method"`
+	JobID              uuid.UUID  `json:"job_id"`
+	Status             string     `json:"status"`
+	TxHash             string     `json:"tx_hash,omitempty"`
+	Contract           string     `json:"contract,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	AnchoredAt         *time.Time `json:"anchored_at,omitempty"`
+}
+
+// NotarizeRequest represents a request by a DID to notarize a document hash
+type NotarizeRequest struct {
+	DocumentHash string `json:"document_hash" binding:"required,len=64,hexadecimal"`
+	Signature    string `json:"signature" binding:"required,hexadecimal"`
+}
+
+// NotarizationRepository defines the interface for notarization data operations
+type NotarizationRepository interface {
+	Create(notarization *Notarization) error
+	GetByID(id uuid.UUID) (*Notarization, error)
+	ListByDocumentHash(documentHash string) ([]*Notarization, error)
+	// MarkAnchored records the transaction of the notarization created with jobID
+	MarkAnchored(jobID uuid.UUID, txHash string, anchoredAt time.Time) error
+}
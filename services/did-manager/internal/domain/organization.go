@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OperationType represents a mutating operation on an organization DID
+type OperationType string
+
+const (
+	OperationTypeRotateKey       OperationType = "rotate_key"
+	OperationTypeRevokeDID       OperationType = "revoke_did"
+	OperationTypeIssueCredential OperationType = "issue_credential"
+	OperationTypeSetProfile      OperationType = "set_profile"
+)
+
+// OperationStatus represents the lifecycle state of a controlled operation
+type OperationStatus string
+
+const (
+	OperationStatusPending   OperationStatus = "pending"
+	OperationStatusExecuting OperationStatus = "executing"
+	OperationStatusExecuted  OperationStatus = "executed"
+	OperationStatusFailed    OperationStatus = "failed"
+)
+
+// DIDController is a DID allowed to approve operations on an organization DID
+type DIDController struct {
+	ControllerDID string    `json:"controller_did"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OrganizationControllersRequest makes a DID an organization DID controlled by
+// Threshold of Controllers
+type OrganizationControllersRequest struct {
+	DID         string   `json:"did" binding:"required"`
+	Controllers []string `json:"controllers" binding:"required,min=1,dive,required"`
+	Threshold   int      `json:"threshold" binding:"required,min=1"`
+}
+
+// Organization describes an organization DID and its controllers
+type Organization struct {
+	Did         string           `json:"did"`
+	Threshold   int              `json:"threshold"`
+	Controllers []*DIDController `json:"controllers"`
+}
+
+// ControlledOperation is a mutating operation on an organization DID awaiting, or
+// carried out after, approval by Threshold of the DID's controllers
+type ControlledOperation struct {
+	ID    uuid.UUID `json:"id"`
+	DIDID uuid.UUID `json:"-"`
+	Did   string    `json:"did"`
+	Type  string    `json:"type"`
+	// Payload holds the operation's parameters, e.g. the credential issue request
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Status     string          `json:"status"`
+	ProposedBy string          `json:"proposed_by"`
+	Approvals  []string        `json:"approvals"`
+	Threshold  int             `json:"threshold"`
+	// Result holds the output of an executed operation, e.g. the issued credential
+	Result     json.RawMessage `json:"result,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ExecutedAt *time.Time      `json:"executed_at,omitempty"`
+}
+
+// OperationProposeRequest represents a controller's proposal of an operation on an
+// organization DID; the proposal counts as the proposer's approval
+type OperationProposeRequest struct {
+	Type string `json:"type" binding:"required,oneof=rotate_key revoke_did issue_credential set_profile"`
+	// KeyAlgorithm optionally changes the signature suite on rotate_key
+	KeyAlgorithm string `json:"key_algorithm"`
+	// Credential is required for issue_credential
+	Credential *CredentialIssueRequest `json:"credential" binding:"required_if=Type issue_credential"`
+	// Profile is required for set_profile
+	Profile *DIDProfileRequest `json:"profile" binding:"required_if=Type set_profile"`
+}
+
+// OrganizationRepository defines the interface for organization DID data operations
+type OrganizationRepository interface {
+	// SetControllers replaces the controllers and approval threshold of a DID
+	SetControllers(didID uuid.UUID, controllers []string, threshold int) error
+	ListControllers(didID uuid.UUID) ([]*DIDController, error)
+	CreateOperation(operation *ControlledOperation) error
+	GetOperation(id uuid.UUID) (*ControlledOperation, error)
+	ListOperations(didID uuid.UUID, status string) ([]*ControlledOperation, error)
+	// AddApproval records a controller's approval; approving twice is a no-op
+	AddApproval(operationID uuid.UUID, controllerDID string) error
+	// MarkExecuting claims a pending operation for execution, returning
+	// ErrOperationClosed if it is no longer pending
+	MarkExecuting(id uuid.UUID) error
+	Finish(id uuid.UUID, status string, result json.RawMessage, message string, executedAt time.Time) error
+}
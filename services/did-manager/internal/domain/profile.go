@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileField names a field of a DID profile. Fields are only published once their
+// DID's controller opts in to each of them.
+type ProfileField string
+
+const (
+	ProfileFieldDisplayName       ProfileField = "display_name"
+	ProfileFieldAvatar            ProfileField = "avatar"
+	ProfileFieldLinkedDomains     ProfileField = "linked_domains"
+	ProfileFieldPublicCredentials ProfileField = "public_credentials"
+)
+
+// DIDProfile is the profile a DID's controller maintains, including the fields it has
+// not published
+type DIDProfile struct {
+	DID           string   `json:"did"`
+	DisplayName   string   `json:"display_name,omitempty"`
+	AvatarURL     string   `json:"avatar_url,omitempty"`
+	LinkedDomains []string `json:"linked_domains"`
+	// PublicCredentials are credentials held by the DID, which it shows on its profile
+	PublicCredentials []uuid.UUID `json:"public_credentials"`
+	// Published lists the fields the public profile shows
+	Published []ProfileField `json:"published"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// DIDProfileRequest represents a request to replace a DID's profile. Only the fields
+// listed in Published are shown on the public profile.
+type DIDProfileRequest struct {
+	DisplayName       string         `json:"display_name" binding:"max=100"`
+	AvatarURL         string         `json:"avatar_url" binding:"omitempty,url,startswith=https://,max=2048"`
+	LinkedDomains     []string       `json:"linked_domains" binding:"max=10,dive,required,fqdn,max=253"`
+	PublicCredentials []uuid.UUID    `json:"public_credentials" binding:"max=20"`
+	Published         []ProfileField `json:"published" binding:"max=4,dive,oneof=display_name avatar linked_domains public_credentials"`
+}
+
+// PublicProfile is what anyone sees of a DID's profile: the published fields only
+type PublicProfile struct {
+	DID           string   `json:"did"`
+	DisplayName   string   `json:"display_name,omitempty"`
+	AvatarURL     string   `json:"avatar_url,omitempty"`
+	LinkedDomains []string `json:"linked_domains,omitempty"`
+	// Credentials are the published credentials still active, with their signed
+	// documents so apps can verify them
+	Credentials []*ProfileCredential `json:"credentials,omitempty"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// ProfileCredential is a credential shown on a public profile
+type ProfileCredential struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	IssuerDID string          `json:"issuer_did"`
+	IssuedAt  time.Time       `json:"issued_at"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	Document  json.RawMessage `json:"document"`
+}
+
+// DIDProfileRepository defines the interface for DID profile data operations
+type DIDProfileRepository interface {
+	// Get returns the profile of a DID, or ErrProfileNotFound when it has none
+	Get(didID uuid.UUID) (*DIDProfile, error)
+	Upsert(didID uuid.UUID, profile *DIDProfile) error
+	// Delete removes the profile of a DID, returning ErrProfileNotFound when it has none
+	Delete(didID uuid.UUID) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurposeKey is a key of a DID generated for a single verification relationship, kept
+// in the keystore with its purpose: authentication, assertionMethod or keyAgreement.
+// The DID's own key is its capabilityInvocation key. Only custodial DIDs created with
+// purpose keys have them; other DIDs use their own key for every purpose.
+type PurposeKey struct {
+	ID    uuid.UUID `json:"id"`
+	DIDID uuid.UUID `json:"did_id"`
+	// Purpose is the verification relationship the key is listed under
+	Purpose string `json:"purpose"`
+	// VerificationMethod is the ID of the key in the DID document, e.g. <did>#assert-1
+	VerificationMethod string `json:"verification_method"`
+	KeyAlgorithm       string `json:"key_algorithm"`
+	PublicKey          string `json:"public_key"`
+	// PrivateKey is the hex-encoded private key held for the custodial DID
+	PrivateKey string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PurposeKeyRepository defines the interface for keystore data operations on purpose keys
+type PurposeKeyRepository interface {
+	// Create stores the purpose keys of a DID in one transaction
+	Create(keys []*PurposeKey) error
+	// ListByDIDID retrieves the purpose keys of a DID
+	ListByDIDID(didID uuid.UUID) ([]*PurposeKey, error)
+}
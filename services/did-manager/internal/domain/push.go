@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushDevice is a mobile device registered to receive wallet push notifications
+type PushDevice struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Platform   string    `json:"platform"` // fcm, apns
+	Token      string    `json:"-"`
+	DeviceName string    `json:"device_name"`
+	// Locale is the locale notifications are shown in; empty for the server default
+	Locale     string    `json:"locale"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// DeviceRegisterRequest represents a request to register a device for push notifications
+type DeviceRegisterRequest struct {
+	Platform   string `json:"platform" binding:"required,oneof=fcm apns"`
+	Token      string `json:"token" binding:"required,max=4096"`
+	DeviceName string `json:"device_name" binding:"max=255"`
+	// Locale optionally sets the locale of notifications, such as "es"; the request's
+	// Accept-Language is used otherwise
+	Locale string `json:"locale" binding:"max=35"`
+}
+
+// PushDeviceRepository defines the interface for push device data operations
+type PushDeviceRepository interface {
+	// Upsert registers a device token, moving it to device.UserID if it was registered
+	// by another user
+	Upsert(device *PushDevice) error
+	ListByUserID(userID uuid.UUID) ([]*PushDevice, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+	DeleteByToken(token string) error
+}
@@ -13,13 +13,71 @@ type BlockchainJob struct {
 	DIDID       uuid.UUID  `json:"did_id" db:"did_id"`
 	UserHash    string     `json:"user_hash" db:"user_hash"`
 	DID         string     `json:"did" db:"did"`
-	Status      string     `json:"status" db:"status"` // pending, processing, completed, failed
+	Status      string     `json:"status" db:"status"` // pending, processing, completed, failed, simulated, canceled
 	RetryCount  int        `json:"retry_count" db:"retry_count"`
 	MaxRetries  int        `json:"max_retries" db:"max_retries"`
 	Error       string     `json:"error" db:"error"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	ProcessedAt *time.Time `json:"processed_at" db:"processed_at"`
+	// TxHash is the transaction submitted by a completed job
+	TxHash string `json:"tx_hash,omitempty" db:"tx_hash"`
+	// DryRun jobs are simulated instead of submitted, leaving the DID untouched
+	DryRun     bool           `json:"dry_run" db:"dry_run"`
+	Simulation *JobSimulation `json:"simulation,omitempty" db:"simulation"`
+	// TraceID is the trace the job's phases are recorded in, assigned on creation
+	TraceID string `json:"trace_id,omitempty" db:"trace_id"`
+}
+
+// JobSimulation records the outcome of simulating a dry-run job on-chain
+type JobSimulation struct {
+	GasEstimate uint64 `json:"gas_estimate"`
+	GasLimit    uint64 `json:"gas_limit"`
+	// GasPrice is in wei
+	GasPrice     string    `json:"gas_price"`
+	Reverted     bool      `json:"reverted"`
+	RevertReason string    `json:"revert_reason,omitempty"`
+	SimulatedAt  time.Time `json:"simulated_at"`
+}
+
+// JobSimulateRequest represents a request to simulate a blockchain operation on a DID
+type JobSimulateRequest struct {
+	DIDID   uuid.UUID `json:"did_id" binding:"required"`
+	JobType JobType   `json:"job_type" binding:"required,oneof=register_did update_did revoke_did"`
+}
+
+// GasPriceOverrideRequest pins the gas price blockchain jobs are sent with
+type GasPriceOverrideRequest struct {
+	// GasPrice is in wei, as a decimal string
+	GasPrice string `json:"gas_price" binding:"required"`
+}
+
+// GasPriceStatus describes how blockchain jobs are priced. Prices are in wei, as
+// decimal strings, and empty when unset.
+type GasPriceStatus struct {
+	// GasPrice is the price the next transaction is sent with: the override when set,
+	// the node's suggestion otherwise, at most MaxGasPrice
+	GasPrice    string    `json:"gas_price"`
+	Suggested   string    `json:"suggested"`
+	Override    string    `json:"override,omitempty"`
+	MaxGasPrice string    `json:"max_gas_price,omitempty"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// JobCancelRequest represents a request to cancel a blockchain job
+type JobCancelRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// JobCancellation is the outcome of canceling a blockchain job. A job whose
+// transaction was already broadcast cannot be withdrawn, so its DID is revoked by a
+// compensating job instead.
+type JobCancellation struct {
+	Job *BlockchainJob `json:"job"`
+	// Canceled reports whether the job was withdrawn before reaching the chain
+	Canceled bool `json:"canceled"`
+	// CompensatingJob revokes the DID when the job's transaction was already broadcast
+	CompensatingJob *BlockchainJob `json:"compensating_job,omitempty"`
 }
 
 // JobStatus represents the current status of a blockchain job
@@ -31,8 +89,21 @@ const (
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
 	JobStatusRetrying   JobStatus = "retrying"
+	JobStatusSimulated  JobStatus = "simulated"
+	// JobStatusCanceled jobs were withdrawn before their transaction was broadcast
+	JobStatusCanceled JobStatus = "canceled"
 )
 
+// Valid reports whether s is one of the job statuses above
+func (s JobStatus) Valid() bool {
+	switch s {
+	case JobStatusPending, JobStatusProcessing, JobStatusCompleted, JobStatusFailed,
+		JobStatusRetrying, JobStatusSimulated, JobStatusCanceled:
+		return true
+	}
+	return false
+}
+
 // JobType represents the type of blockchain operation
 type JobType string
 
@@ -40,17 +111,77 @@ const (
 	JobTypeRegisterDID JobType = "register_did"
 	JobTypeUpdateDID   JobType = "update_did"
 	JobTypeRevokeDID   JobType = "revoke_did"
+	JobTypeNotarize    JobType = "notarize" // UserHash carries the document hash
 )
 
-// BlockchainJobRepository defines the interface for blockchain job data operations
+// Valid reports whether t is one of the job types above
+func (t JobType) Valid() bool {
+	switch t {
+	case JobTypeRegisterDID, JobTypeUpdateDID, JobTypeRevokeDID, JobTypeNotarize:
+		return true
+	}
+	return false
+}
+
+// JobListRequest filters blockchain jobs for administrators; empty fields match
+// everything. Sort is created_at for oldest first or -created_at, the default, for
+// newest first.
+type JobListRequest struct {
+	Status        JobStatus  `form:"status"`
+	JobType       JobType    `form:"job_type"`
+	DID           string     `form:"did"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=created_at -created_at"`
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// JobListResponse is a page of blockchain jobs
+type JobListResponse struct {
+	Jobs  []*BlockchainJob `json:"jobs"`
+	Total int              `json:"total"`
+	Page  int              `json:"page"`
+	Limit int              `json:"limit"`
+}
+
+// BlockchainJobRepository defines the interface for blockchain job data operations.
+// Writes fail with ErrInvalidJobType or ErrInvalidJobStatus for a type or status that
+// is not a JobType or JobStatus.
 type BlockchainJobRepository interface {
 	Create(job *BlockchainJob) error
 	GetByID(id uuid.UUID) (*BlockchainJob, error)
 	GetPendingJobs(limit int) ([]*BlockchainJob, error)
-	UpdateStatus(id uuid.UUID, status string, error string) error
-	MarkCompleted(id uuid.UUID) error
+	// GetPendingBatchJobs retrieves pending DID operation jobs that are not dry runs,
+	// which are anchored in Sidetree batches when batching is enabled
+	GetPendingBatchJobs(limit int) ([]*BlockchainJob, error)
+	// GetPendingUnbatchedJobs retrieves the pending jobs GetPendingBatchJobs leaves out
+	GetPendingUnbatchedJobs(limit int) ([]*BlockchainJob, error)
+	// CountPending counts the jobs waiting to be processed or being processed
+	CountPending() (int, error)
+	// ListCompletedWithPendingDID retrieves completed DID jobs whose DID is still pending
+	ListCompletedWithPendingDID() ([]*BlockchainJob, error)
+	// ListByDIDID retrieves the jobs run for a DID, oldest first
+	ListByDIDID(didID uuid.UUID) ([]*BlockchainJob, error)
+	// List returns a page of jobs matching req, together with the total number of matches
+	List(req *JobListRequest, limit, offset int) ([]*BlockchainJob, int, error)
+	UpdateStatus(id uuid.UUID, status JobStatus, error string) error
+	// Claim marks a pending or retrying job processing, failing with
+	// ErrJobNotClaimable when it was claimed by another worker or canceled
+	Claim(id uuid.UUID) error
+	// Cancel marks a pending, retrying or failed job canceled unless a transaction was
+	// broadcast for it, failing with ErrJobNotCancelable otherwise
+	Cancel(id uuid.UUID, reason string) error
+	MarkCompleted(id uuid.UUID, txHash string) error
+	// RecordSimulation stores the outcome of a dry-run job and marks it simulated
+	RecordSimulation(id uuid.UUID, simulation *JobSimulation) error
 	IncrementRetryCount(id uuid.UUID) error
-	CleanupCompletedJobs(daysOld int) error
+	// RecordPhase adds a phase to a job's timeline. Creating a job and changing its
+	// status record the enqueued, claimed, confirmed, simulated, failed and retrying
+	// phases themselves.
+	RecordPhase(id uuid.UUID, phase JobPhase, spanID, detail string) error
+	// ListPhases retrieves a job's timeline, oldest phase first
+	ListPhases(id uuid.UUID) ([]*JobPhaseEvent, error)
 }
 
 // QueueService defines the interface for blockchain queue management
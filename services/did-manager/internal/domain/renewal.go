@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RenewalPolicy configures expiry handling for an issuer's credentials under a tenant;
+// the policy with an empty tenant applies to credentials without a more specific one
+type RenewalPolicy struct {
+	IssuerDID string `json:"issuer_did"`
+	Tenant    string `json:"tenant"`
+	// AutoRenew reissues expiring credentials from the original claims
+	AutoRenew bool `json:"auto_renew"`
+	// ValidityDays is the validity of renewed credentials; zero keeps the original period
+	ValidityDays int `json:"validity_days"`
+	// WebhookURL receives expiry notifications signed with WebhookSecret
+	WebhookURL    string    `json:"webhook_url,omitempty"`
+	WebhookSecret string    `json:"webhook_secret,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RenewalPolicyRequest represents an issuer's request to configure expiry handling
+type RenewalPolicyRequest struct {
+	Tenant       string `json:"tenant" binding:"max=255"`
+	AutoRenew    bool   `json:"auto_renew"`
+	ValidityDays int    `json:"validity_days" binding:"min=0,max=3650"`
+	WebhookURL   string `json:"webhook_url" binding:"omitempty,url"`
+}
+
+// ExpiryNotice is the webhook payload sent when a credential nears expiry
+type ExpiryNotice struct {
+	Type       string      `json:"type"`
+	Credential *Credential `json:"credential"`
+	// RenewedCredentialID identifies the replacement issued under auto-renewal
+	RenewedCredentialID *uuid.UUID `json:"renewed_credential_id,omitempty"`
+	SentAt              time.Time  `json:"sent_at"`
+}
+
+// RenewalPolicyRepository defines the interface for renewal policy data operations
+type RenewalPolicyRepository interface {
+	Upsert(policy *RenewalPolicy) error
+	// Get retrieves the policy for an issuer and tenant, returning
+	// ErrRenewalPolicyNotFound when none is configured
+	Get(issuerDID, tenant string) (*RenewalPolicy, error)
+	ListByIssuer(issuerDID string) ([]*RenewalPolicy, error)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InconsistencyKind classifies a disagreement between dids, blockchain_jobs and the chain
+type InconsistencyKind string
+
+const (
+	// InconsistencyActiveWithoutTx is an active DID with no registration transaction
+	InconsistencyActiveWithoutTx InconsistencyKind = "active_without_tx"
+	// InconsistencyCompletedJobPendingDID is a completed job whose DID is still pending
+	InconsistencyCompletedJobPendingDID InconsistencyKind = "completed_job_pending_did"
+	// InconsistencyNotOnChain is an active DID the registry has no record of
+	InconsistencyNotOnChain InconsistencyKind = "not_on_chain"
+	// InconsistencyRevocationNotOnChain is a revoked DID still active on-chain
+	InconsistencyRevocationNotOnChain InconsistencyKind = "revocation_not_on_chain"
+	// InconsistencyMissingLocally is an on-chain registration with no local DID
+	InconsistencyMissingLocally InconsistencyKind = "missing_locally"
+)
+
+// Inconsistency is a single finding of a consistency check
+type Inconsistency struct {
+	Kind     InconsistencyKind `json:"kind"`
+	DIDID    *uuid.UUID        `json:"did_id,omitempty"`
+	DID      string            `json:"did,omitempty"`
+	UserHash string            `json:"user_hash,omitempty"`
+	JobID    *uuid.UUID        `json:"job_id,omitempty"`
+	Detail   string            `json:"detail"`
+	// Repair describes the fix applied, or that would be applied, when the finding
+	// can be repaired automatically; empty when it needs manual attention
+	Repair   string `json:"repair,omitempty"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ConsistencyReport is the outcome of cross-checking dids, blockchain_jobs and the chain
+type ConsistencyReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Fix reports whether repairs were applied or only reported
+	Fix bool `json:"fix"`
+	// OnChain reports whether records were compared against the registry contract
+	OnChain         bool             `json:"on_chain"`
+	DIDsChecked     int              `json:"dids_checked"`
+	JobsChecked     int              `json:"jobs_checked"`
+	Inconsistencies []*Inconsistency `json:"inconsistencies"`
+	Repaired        int              `json:"repaired"`
+}
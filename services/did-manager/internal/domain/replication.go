@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// ReplicationRole is the role of a deployment in an active-passive pair
+type ReplicationRole string
+
+const (
+	// ReplicationRolePrimary serves reads and writes and runs the background workers
+	ReplicationRolePrimary ReplicationRole = "primary"
+	// ReplicationRoleStandby follows the primary through logical replication, serving
+	// reads only until promoted
+	ReplicationRoleStandby ReplicationRole = "standby"
+)
+
+// ReplicationSubscription is the state of the logical replication subscription
+// applying the primary's changes to a standby's database
+type ReplicationSubscription struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// LastReceivedAt is when the subscription last heard from the primary; nil when
+	// its apply worker is not running
+	LastReceivedAt *time.Time `json:"last_received_at,omitempty"`
+}
+
+// ReplicationStatus describes the deployment's replication role
+type ReplicationStatus struct {
+	Role   ReplicationRole `json:"role"`
+	Region string          `json:"region,omitempty"`
+	// ReadOnly is set while the deployment is a standby
+	ReadOnly     bool                     `json:"read_only"`
+	Subscription *ReplicationSubscription `json:"subscription,omitempty"`
+	// QueueMirrorDomain is the JetStream domain of the primary whose streams are mirrored
+	QueueMirrorDomain string     `json:"queue_mirror_domain,omitempty"`
+	PromotedAt        *time.Time `json:"promoted_at,omitempty"`
+}
+
+// ReplicationRepository defines the interface for inspecting and detaching the
+// logical replication subscription of a standby's database
+type ReplicationRepository interface {
+	GetSubscription(name string) (*ReplicationSubscription, error)
+	DisableSubscription(name string) error
+}
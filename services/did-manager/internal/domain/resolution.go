@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"did-manager/pkg/did"
+)
+
+// DIDResolutionResult represents the output of resolving a DID
+type DIDResolutionResult struct {
+	Document           *did.Document         `json:"didDocument"`
+	DocumentMetadata   DIDDocumentMetadata   `json:"didDocumentMetadata"`
+	ResolutionMetadata DIDResolutionMetadata `json:"didResolutionMetadata"`
+}
+
+// DIDDocumentMetadata describes the state of a resolved DID Document. Created and
+// Updated are unknown for DIDs resolved through an external resolver.
+type DIDDocumentMetadata struct {
+	Created     *time.Time `json:"created,omitempty"`
+	Updated     *time.Time `json:"updated,omitempty"`
+	Status      string     `json:"status"`
+	Deactivated bool       `json:"deactivated,omitempty"`
+	// BlockchainTx is the transaction of the DID's latest anchored operation; for a
+	// deactivated DID it is the one anchoring the deactivation marker
+	BlockchainTx string `json:"blockchainTx,omitempty"`
+	// CanonicalID is the short-form DID of a published did:ion long-form DID, or the
+	// legacy DID a DID managed here was resolved by the alias of
+	CanonicalID string `json:"canonicalId,omitempty"`
+	// EquivalentID lists the alias of a legacy DID managed here
+	EquivalentID []string `json:"equivalentId,omitempty"`
+	// VersionID is the claims version of a DID managed here, bumped by every claim
+	// added after its creation; empty until one is
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// DIDResolutionMetadata describes the resolution process itself
+type DIDResolutionMetadata struct {
+	ContentType string `json:"contentType,omitempty"`
+	// Error is a DID Resolution error code such as notFound when resolution failed
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewOperation is the kind of operation held for manual review
+type ReviewOperation string
+
+const (
+	ReviewOperationCreateDID       ReviewOperation = "create_did"
+	ReviewOperationIssueCredential ReviewOperation = "issue_credential"
+)
+
+// ReviewStatus represents the state of a review
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+// Review holds an operation flagged by screening, duplicate detection or another
+// plugin hook until an administrator decides on it. The DID or credential it targets
+// stays in pending_review; approved DIDs continue to on-chain registration and
+// approved credentials become valid.
+type Review struct {
+	ID        uuid.UUID       `json:"id"`
+	Operation ReviewOperation `json:"operation"`
+	// TargetID is the held DID or credential, and Target its DID or the credential's
+	// subject DID
+	TargetID   uuid.UUID  `json:"target_id"`
+	Target     string     `json:"target"`
+	Reasons    []string   `json:"reasons"`
+	Status     string     `json:"status"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReviewListRequest filters the review queue
+type ReviewListRequest struct {
+	// Status defaults to pending
+	Status    string `form:"status" binding:"omitempty,oneof=pending approved rejected all"`
+	Operation string `form:"operation" binding:"omitempty,oneof=create_did issue_credential"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// ReviewResolveRequest records an administrator's decision on a review
+type ReviewResolveRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Note     string `json:"note" binding:"max=1000"`
+}
+
+// ReviewRepository defines the interface for review queue data operations
+type ReviewRepository interface {
+	Create(review *Review) error
+	GetByID(id uuid.UUID) (*Review, error)
+	// List lists reviews, newest first; empty filters match everything
+	List(status, operation string, limit int) ([]*Review, error)
+	// Resolve records a decision on a pending review, returning ErrReviewClosed if it
+	// was already resolved
+	Resolve(id uuid.UUID, status ReviewStatus, note string, resolvedAt time.Time) error
+}
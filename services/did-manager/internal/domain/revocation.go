@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"did-manager/pkg/smt"
+
+	"github.com/google/uuid"
+)
+
+// RevocationBatchStatus represents whether a batch's root has been anchored on-chain
+type RevocationBatchStatus string
+
+const (
+	RevocationBatchPending  RevocationBatchStatus = "pending"
+	RevocationBatchAnchored RevocationBatchStatus = "anchored"
+)
+
+// RevocationBatch is a set of revocations folded into the revocation accumulator. Its
+// root covers every revocation in this and all earlier batches.
+type RevocationBatch struct {
+	ID           uuid.UUID  `json:"id"`
+	Sequence     int64      `json:"sequence"`
+	Root         smt.Hash   `json:"root"`
+	RevokedCount int        `json:"revoked_count"`
+	Status       string     `json:"status"`
+	TxHash       string     `json:"tx_hash,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	AnchoredAt   *time.Time `json:"anchored_at,omitempty"`
+}
+
+// RevocationProof proves a credential's revocation state against an anchored root.
+// Verifiers recompute the root from Key, Revoked and Proof and compare it with the
+// root published for Batch in the RevocationRegistry contract.
+type RevocationProof struct {
+	CredentialID string     `json:"credential_id"`
+	Key          smt.Hash   `json:"key"`
+	Revoked      bool       `json:"revoked"`
+	Root         smt.Hash   `json:"root"`
+	Batch        int64      `json:"batch"`
+	TxHash       string     `json:"tx_hash"`
+	AnchoredAt   *time.Time `json:"anchored_at"`
+	Contract     string     `json:"contract,omitempty"`
+	Proof        *smt.Proof `json:"proof"`
+}
+
+// RevocationBatchRepository defines the interface for revocation batch data operations
+type RevocationBatchRepository interface {
+	// Create stores a batch and assigns the given revoked credentials to it
+	Create(batch *RevocationBatch, credentialIDs []uuid.UUID) error
+	GetLatestAnchored() (*RevocationBatch, error)
+	ListPending() ([]*RevocationBatch, error)
+	MarkAnchored(id uuid.UUID, txHash string, anchoredAt time.Time) error
+	MarkFailed(id uuid.UUID, message string) error
+}
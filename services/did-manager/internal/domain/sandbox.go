@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// SandboxReset reports the reset of a sandbox partition
+type SandboxReset struct {
+	SandboxTenant string    `json:"sandbox_tenant"`
+	DeletedDIDs   int64     `json:"deleted_dids"`
+	ResetAt       time.Time `json:"reset_at"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SidetreeBatch is a batch of DID operations anchored with a single transaction
+type SidetreeBatch struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	// AnchorString is "<operation count>.<core index file CID>"
+	AnchorString     string `json:"anchor_string" db:"anchor_string"`
+	CoreIndexFileURI string `json:"core_index_file_uri" db:"core_index_file_uri"`
+	ChunkFileURI     string `json:"chunk_file_uri" db:"chunk_file_uri"`
+	// AnchorFileHash is the SHA-256 of the core index file written on-chain, 0x-prefixed
+	AnchorFileHash string    `json:"anchor_file_hash" db:"anchor_file_hash"`
+	OperationCount int       `json:"operation_count" db:"operation_count"`
+	Contract       string    `json:"contract" db:"contract"`
+	TxHash         string    `json:"transaction_hash" db:"tx_hash"`
+	AnchoredAt     time.Time `json:"anchored_at" db:"anchored_at"`
+}
+
+// SidetreeReceipt proves a DID operation was included in an anchored batch: the
+// operation is listed at OperationIndex of the batch's core index file, whose digest
+// the transaction wrote to the anchor contract
+type SidetreeReceipt struct {
+	JobID          uuid.UUID      `json:"job_id" db:"job_id"`
+	DIDID          uuid.UUID      `json:"-" db:"did_id"`
+	DID            string         `json:"did" db:"did"`
+	Operation      string         `json:"operation" db:"operation"` // create, update, deactivate
+	OperationIndex int            `json:"operation_index" db:"operation_index"`
+	Batch          *SidetreeBatch `json:"batch"`
+}
+
+// SidetreeRepository defines the interface for Sidetree batch data operations
+type SidetreeRepository interface {
+	// CreateBatch stores a batch with the receipts of its operations
+	CreateBatch(batch *SidetreeBatch, receipts []*SidetreeReceipt) error
+	// ListReceipts retrieves the receipts of a DID's operations, oldest first
+	ListReceipts(did string) ([]*SidetreeReceipt, error)
+}
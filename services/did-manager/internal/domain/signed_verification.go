@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// VerificationNonce is a single-use nonce issued to a relying party, the audience. The
+// holder of a DID signs it to authorize that relying party to verify the DID once.
+type VerificationNonce struct {
+	Nonce     string    `json:"nonce"`
+	Audience  string    `json:"audience"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SignedVerificationRequest is a verification request authorized by the DID's holder
+type SignedVerificationRequest struct {
+	DID      string `json:"did" binding:"required"`
+	Nonce    string `json:"nonce" binding:"required"`
+	Audience string `json:"audience" binding:"required"`
+	// Signature is the holder's hex-encoded signature over
+	// "did-verify:<did>:<nonce>:<audience>" with the DID's key
+	Signature string `json:"signature" binding:"required"`
+}
+
+// VerificationNonceRepository defines the interface for verification nonce data
+// operations
+type VerificationNonceRepository interface {
+	Create(nonce *VerificationNonce) error
+	// Consume marks an issued nonce of the audience used, returning
+	// ErrVerificationNonceInvalid when it is unknown, expired or already used
+	Consume(nonce, audience string) error
+	// DeleteExpired removes nonces that expired before the given time
+	DeleteExpired(before time.Time) error
+}
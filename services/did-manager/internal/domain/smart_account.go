@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"did-manager/pkg/erc4337"
+
+	"github.com/google/uuid"
+)
+
+// SmartAccount is an ERC-4337 smart contract account controlling a DID. The account is
+// derived counterfactually from the factory, owner and salt and deployed by its first
+// user operation; recovery guardians and session keys are managed on-chain through
+// operations sent from it.
+type SmartAccount struct {
+	ID    uuid.UUID `json:"id" db:"id"`
+	DIDID uuid.UUID `json:"-" db:"did_id"`
+	Did   string    `json:"did" db:"did"`
+	// Address is the account contract address, 0x-prefixed
+	Address    string    `json:"address" db:"address"`
+	Owner      string    `json:"owner" db:"owner"`
+	Factory    string    `json:"factory" db:"factory"`
+	EntryPoint string    `json:"entry_point" db:"entry_point"`
+	ChainID    int64     `json:"chain_id" db:"chain_id"`
+	Salt       string    `json:"salt" db:"salt"`
+	Deployed   bool      `json:"deployed" db:"deployed"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SmartAccountCreateRequest binds a DID to the smart account of an owner key
+type SmartAccountCreateRequest struct {
+	Owner string `json:"owner" binding:"required,eth_addr"`
+	// Salt selects one of the owner's accounts; 0 when empty
+	Salt string `json:"salt" binding:"omitempty,number"`
+}
+
+// UserOperationPrepareRequest describes a call the smart account should make
+type UserOperationPrepareRequest struct {
+	Target string `json:"target" binding:"required,eth_addr"`
+	// Value is the wei sent with the call, in decimal
+	Value string `json:"value" binding:"omitempty,number"`
+	Data  string `json:"data" binding:"omitempty,hexadecimal"`
+}
+
+// PreparedUserOperation is an unsigned user operation and the hash its owner signs
+type PreparedUserOperation struct {
+	Operation *erc4337.UserOperation `json:"operation"`
+	Hash      string                 `json:"hash"`
+}
+
+// UserOperationSubmitRequest submits an operation signed by the account owner
+type UserOperationSubmitRequest struct {
+	Operation *erc4337.UserOperation `json:"operation" binding:"required"`
+}
+
+// UserOperationStatus is the state of a submitted user operation
+type UserOperationStatus struct {
+	Hash     string `json:"hash"`
+	Included bool   `json:"included"`
+	Success  bool   `json:"success"`
+	Reason   string `json:"reason,omitempty"`
+	TxHash   string `json:"transaction_hash,omitempty"`
+}
+
+// SmartAccountRepository defines the interface for smart account data operations
+type SmartAccountRepository interface {
+	// Create stores an account; it returns ErrSmartAccountExists if the DID has one
+	Create(account *SmartAccount) error
+	GetByDIDID(didID uuid.UUID) (*SmartAccount, error)
+	MarkDeployed(id uuid.UUID) error
+}
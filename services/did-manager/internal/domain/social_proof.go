@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SocialAccountLinkageType is the type of the credentials attesting that a DID controls
+// a social account
+const SocialAccountLinkageType = "SocialAccountLinkage"
+
+// SocialProofChallenge is a challenge a DID signs and publishes from a social account
+// to prove it controls the account
+type SocialProofChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	DID       string    `json:"did"`
+	Platform  string    `json:"platform"`
+	Handle    string    `json:"handle"`
+	Challenge string    `json:"challenge"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SocialProofChallengeRequest requests a challenge for linking a social account
+type SocialProofChallengeRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=github twitter"`
+	Handle   string `json:"handle" binding:"required,max=39"`
+}
+
+// SocialProofChallengeResponse is an issued challenge with the message the DID signs;
+// the hex signature is then published in a public post from the account
+type SocialProofChallengeResponse struct {
+	*SocialProofChallenge
+	Message string `json:"message"`
+}
+
+// SocialProofRequest submits the public post carrying the signed challenge
+type SocialProofRequest struct {
+	ChallengeID uuid.UUID `json:"challenge_id" binding:"required"`
+	URL         string    `json:"url" binding:"required,url,startswith=https://,max=2048"`
+}
+
+// SocialProofChallengeRepository defines the interface for social proof challenge data
+// operations
+type SocialProofChallengeRepository interface {
+	Create(challenge *SocialProofChallenge) error
+	// Get returns an unexpired, unused challenge, or ErrSocialChallengeNotFound
+	Get(id uuid.UUID) (*SocialProofChallenge, error)
+	// Consume marks an unexpired, unused challenge used, returning
+	// ErrSocialChallengeNotFound when it is unknown, expired or already used
+	Consume(id uuid.UUID) error
+	// DeleteExpired removes challenges that expired before the given time
+	DeleteExpired(before time.Time) error
+}
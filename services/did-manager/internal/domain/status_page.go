@@ -0,0 +1,110 @@
+package domain
+
+import "time"
+
+// ServiceStatus is the public status of the service or one of its components
+type ServiceStatus string
+
+const (
+	ServiceOperational   ServiceStatus = "operational"
+	ServiceMaintenance   ServiceStatus = "maintenance"
+	ServiceDegraded      ServiceStatus = "degraded"
+	ServicePartialOutage ServiceStatus = "partial_outage"
+	ServiceMajorOutage   ServiceStatus = "major_outage"
+)
+
+// serviceStatusSeverity orders statuses from best to worst
+var serviceStatusSeverity = map[ServiceStatus]int{
+	ServiceOperational:   0,
+	ServiceMaintenance:   1,
+	ServiceDegraded:      2,
+	ServicePartialOutage: 3,
+	ServiceMajorOutage:   4,
+}
+
+// Worse returns the worse of two statuses
+func (s ServiceStatus) Worse(other ServiceStatus) ServiceStatus {
+	if serviceStatusSeverity[other] > serviceStatusSeverity[s] {
+		return other
+	}
+	return s
+}
+
+// BacklogBucket is the coarse size of the anchoring backlog shown on the status page
+type BacklogBucket string
+
+const (
+	BacklogEmpty    BacklogBucket = "empty"
+	BacklogLow      BacklogBucket = "low"
+	BacklogElevated BacklogBucket = "elevated"
+	BacklogHigh     BacklogBucket = "high"
+)
+
+// IncidentFlag names an incident administrators raise on the status page
+type IncidentFlag string
+
+const (
+	// IncidentMaintenance announces planned maintenance
+	IncidentMaintenance IncidentFlag = "maintenance"
+	// IncidentDegradedPerformance reports slow but working operations
+	IncidentDegradedPerformance IncidentFlag = "degraded_performance"
+	// IncidentAnchoringDelayed reports on-chain anchoring running behind
+	IncidentAnchoringDelayed IncidentFlag = "anchoring_delayed"
+	// IncidentPartialOutage reports some operations failing
+	IncidentPartialOutage IncidentFlag = "partial_outage"
+	// IncidentMajorOutage reports the service being unavailable
+	IncidentMajorOutage IncidentFlag = "major_outage"
+)
+
+// IncidentStatuses is the overall status each incident flag puts the service in
+var IncidentStatuses = map[IncidentFlag]ServiceStatus{
+	IncidentMaintenance:         ServiceMaintenance,
+	IncidentDegradedPerformance: ServiceDegraded,
+	IncidentAnchoringDelayed:    ServiceDegraded,
+	IncidentPartialOutage:       ServicePartialOutage,
+	IncidentMajorOutage:         ServiceMajorOutage,
+}
+
+// StatusIncident is an incident flag raised by an administrator. Its message is shown
+// on the public status page as is.
+type StatusIncident struct {
+	Flag      IncidentFlag `json:"flag"`
+	Message   string       `json:"message"`
+	StartedAt time.Time    `json:"started_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// StatusIncidentRequest represents a request to raise an incident flag or update its
+// message
+type StatusIncidentRequest struct {
+	Message string `json:"message" binding:"max=280"`
+}
+
+// ComponentHealth is the public status of a group of the service's components
+type ComponentHealth struct {
+	Name   string        `json:"name"`
+	Status ServiceStatus `json:"status"`
+}
+
+// StatusPage is the public status of the service, free of internal details
+type StatusPage struct {
+	Status ServiceStatus `json:"status"`
+	// Description describes the overall status in the locale the page was requested in
+	Description      string            `json:"description"`
+	Components       []ComponentHealth `json:"components"`
+	AnchoringBacklog BacklogBucket     `json:"anchoring_backlog"`
+	// AnchoringPaused is set while an operator has paused anchoring; new DIDs are
+	// accepted but not anchored until it resumes
+	AnchoringPaused bool              `json:"anchoring_paused"`
+	Incidents       []*StatusIncident `json:"incidents"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// StatusIncidentRepository defines the interface for status incident data operations
+type StatusIncidentRepository interface {
+	List() ([]*StatusIncident, error)
+	// Upsert raises a flag, or updates the message of a raised flag keeping its start
+	Upsert(incident *StatusIncident) error
+	// Delete clears a flag, returning ErrStatusIncidentNotFound when it is not raised
+	Delete(flag IncidentFlag) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"did-manager/pkg/did"
+)
+
+// StatusTokenRequest requests status tokens for a batch of DIDs, e.g. the attendees of
+// an event fetched ahead of offline checks at the door
+type StatusTokenRequest struct {
+	DIDs []string `json:"dids" binding:"required,min=1,max=500,dive,required,max=255"`
+	// TTL is how long the tokens are valid in seconds, capped at the configured
+	// maximum; the configured default when zero
+	TTL int `json:"ttl" binding:"omitempty,min=60"`
+}
+
+// StatusToken is the status of one DID of a batch with the token asserting it. Only
+// active DIDs get a token; the others are listed with their status.
+type StatusToken struct {
+	DID       string         `json:"did"`
+	Status    string         `json:"status"`
+	Assurance AssuranceLevel `json:"assurance,omitempty"`
+	Token     string         `json:"token,omitempty"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+	// Error explains why the status of the DID could not be checked
+	Error string `json:"error,omitempty"`
+}
+
+// StatusTokenBatch is the outcome of a status token request
+type StatusTokenBatch struct {
+	Tokens   []*StatusToken `json:"tokens"`
+	IssuedAt time.Time      `json:"issued_at"`
+}
+
+// StatusTokenKey is a status token signing key published to verifiers as a JWK
+type StatusTokenKey struct {
+	*did.JWK
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+// StatusTokenKeySet is the JWK set verifiers load status token keys from
+type StatusTokenKeySet struct {
+	Issuer string            `json:"issuer"`
+	Keys   []*StatusTokenKey `json:"keys"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StepUpOperation names a group of sensitive routes sharing a step-up policy
+type StepUpOperation string
+
+const (
+	// StepUpCustodyTransfer covers starting and completing custody transfers, which
+	// rotate a custodial DID to its owner's key
+	StepUpCustodyTransfer StepUpOperation = "custody_transfer"
+	// StepUpOrganizationOperation covers proposing and approving organization
+	// operations such as key rotation and revocation
+	StepUpOrganizationOperation StepUpOperation = "organization_operation"
+)
+
+// StepUpMethod is a way of proving the caller again before a sensitive operation
+type StepUpMethod string
+
+const (
+	// StepUpMethodDIDChallenge is a signature over a fresh challenge with the DID's key
+	StepUpMethodDIDChallenge StepUpMethod = "did_challenge"
+	// StepUpMethodCredential is a presentation of a credential of a given type, signed
+	// over a fresh challenge by the DID
+	StepUpMethodCredential StepUpMethod = "credential"
+)
+
+// StepUpRequirement is one proof satisfying a step-up policy
+type StepUpRequirement struct {
+	Method StepUpMethod `json:"method"`
+	// CredentialType is the credential type to present with StepUpMethodCredential
+	CredentialType string `json:"credential_type,omitempty"`
+}
+
+// StepUpPolicy lists the proofs, any one of which lets a caller perform an operation
+type StepUpPolicy struct {
+	Operation StepUpOperation     `json:"operation"`
+	AnyOf     []StepUpRequirement `json:"any_of"`
+}
+
+// StepUpChallenge is a single-use challenge a DID signs, or presents a credential
+// over, to step up before a sensitive operation
+type StepUpChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	DID       string    `json:"did"`
+	Challenge string    `json:"challenge"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// StepUpChallengeResponse is an issued challenge with what the DID's holder signs:
+// Message for StepUpMethodDIDChallenge, or a presentation whose proof carries the
+// challenge and Domain for StepUpMethodCredential
+type StepUpChallengeResponse struct {
+	*StepUpChallenge
+	Message string `json:"message"`
+	Domain  string `json:"domain"`
+}
+
+// StepUpChallengeRequest requests a step-up challenge for a DID
+type StepUpChallengeRequest struct {
+	DID string `json:"did" binding:"required"`
+}
+
+// StepUpProof is the step-up proof accompanying a request: the challenge ID with
+// either a hex signature over the challenge or a verifiable presentation
+type StepUpProof struct {
+	ChallengeID  uuid.UUID
+	Signature    string
+	Presentation []byte
+}
+
+// StepUpSubject is the authenticated party a step-up proof must belong to: a
+// DID-authenticated caller or the user of a wallet token
+type StepUpSubject struct {
+	DID    string
+	UserID uuid.UUID
+}
+
+// StepUpChallengeRepository defines the interface for step-up challenge data operations
+type StepUpChallengeRepository interface {
+	Create(challenge *StepUpChallenge) error
+	// Consume marks an unexpired, unused challenge used and returns it, returning
+	// ErrStepUpChallengeInvalid when it is unknown, expired or already used
+	Consume(id uuid.UUID) (*StepUpChallenge, error)
+	// DeleteExpired removes challenges that expired before the given time
+	DeleteExpired(before time.Time) error
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TrustedIssuer is an issuer administrators accredit in the trust registry, optionally
+// for some credential types only
+type TrustedIssuer struct {
+	DID  string `json:"did"`
+	Name string `json:"name"`
+	// CredentialTypes limits the accreditation; empty accredits every type
+	CredentialTypes []string `json:"credential_types"`
+	// Version is the registry version that added the issuer
+	Version int64     `json:"version"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// TrustedIssuerRequest represents a request to add an issuer to the trust registry
+type TrustedIssuerRequest struct {
+	DID             string   `json:"did" binding:"required,startswith=did:,max=255"`
+	Name            string   `json:"name" binding:"required,max=200"`
+	CredentialTypes []string `json:"credential_types" binding:"max=50,dive,required,max=100"`
+}
+
+// TrustRegistrySnapshot is the trust registry at a version. Every addition and removal
+// bumps the version, so policy engines holding a snapshot know whether theirs is stale.
+type TrustRegistrySnapshot struct {
+	Version     int64            `json:"version"`
+	Issuers     []*TrustedIssuer `json:"issuers"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// SignedTrustRegistrySnapshot is the exported form of a snapshot, signed like
+// compliance reports over the compact JSON encoding of the snapshot
+type SignedTrustRegistrySnapshot struct {
+	Snapshot  json.RawMessage  `json:"snapshot"`
+	Signature *ReportSignature `json:"signature"`
+}
+
+// TrustRegistryRepository defines the interface for trust registry data operations.
+// Add and Remove bump the registry version in the same transaction as the change.
+type TrustRegistryRepository interface {
+	// List retrieves the registry's issuers ordered by DID, with the registry version
+	List() ([]*TrustedIssuer, int64, error)
+	// Add adds an issuer, setting its version; ErrTrustedIssuerExists when listed
+	Add(issuer *TrustedIssuer) error
+	// Remove removes an issuer, returning the new registry version;
+	// ErrTrustedIssuerNotFound when not listed
+	Remove(did string) (*TrustedIssuer, int64, error)
+}
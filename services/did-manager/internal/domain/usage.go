@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// UsageEventType is a billable event
+type UsageEventType string
+
+const (
+	UsageDIDCreated       UsageEventType = "did_created"
+	UsageDIDVerification  UsageEventType = "did_verification"
+	UsageCredentialIssued UsageEventType = "credential_issued"
+	// UsageAnchoredTransaction is a submitted blockchain job for a DID, billed to the
+	// tenant that created the DID; it is derived from completed jobs rather than recorded
+	UsageAnchoredTransaction UsageEventType = "anchored_transaction"
+)
+
+// UsageEvent records a billable event of a tenant. Tenants are authenticated callers:
+// an API key ID or a DID; anonymous requests are not metered.
+type UsageEvent struct {
+	Tenant string         `json:"tenant"`
+	Event  UsageEventType `json:"event"`
+	// Subject is the DID created or verified, or the credential issued
+	Subject    string    `json:"subject"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// TenantQuota limits a tenant's events of one type per calendar month (UTC). A quota
+// with an empty tenant applies to every tenant without its own.
+type TenantQuota struct {
+	Tenant       string         `json:"tenant"`
+	Event        UsageEventType `json:"event"`
+	MonthlyLimit int            `json:"monthly_limit"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// TenantQuotaRequest represents a request to set a quota
+type TenantQuotaRequest struct {
+	Tenant       string         `json:"tenant" binding:"max=255"`
+	Event        UsageEventType `json:"event" binding:"required,oneof=did_created did_verification credential_issued"`
+	MonthlyLimit *int           `json:"monthly_limit" binding:"required,min=0"`
+}
+
+// BillingLine counts a tenant's events of one type over a billing period
+type BillingLine struct {
+	Tenant string         `json:"tenant"`
+	Event  UsageEventType `json:"event"`
+	Count  int            `json:"count"`
+}
+
+// BillingExport summarizes billable usage over a period for invoicing
+type BillingExport struct {
+	From        time.Time      `json:"from"`
+	To          time.Time      `json:"to"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Lines       []*BillingLine `json:"lines"`
+}
+
+// BillingExportRequest selects the period, tenant and format of a billing export
+type BillingExportRequest struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" binding:"required,gtfield=From" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Tenant optionally restricts the export to one tenant
+	Tenant string `form:"tenant"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// UsageRepository defines the interface for usage metering and quota data operations
+type UsageRepository interface {
+	Record(event *UsageEvent) error
+	// Count counts a tenant's events of one type since the given time
+	Count(tenant string, event UsageEventType, since time.Time) (int, error)
+	// Summarize counts events, including anchored transactions, per tenant and type
+	// over [from, to), optionally for a single tenant
+	Summarize(from, to time.Time, tenant string) ([]*BillingLine, error)
+	ListQuotas() ([]*TenantQuota, error)
+	// EffectiveQuota returns the tenant's quota for an event, falling back to the
+	// default quota; it returns nil when neither exists
+	EffectiveQuota(tenant string, event UsageEventType) (*TenantQuota, error)
+	UpsertQuota(quota *TenantQuota) error
+	// DeleteQuota removes a quota, returning ErrQuotaNotFound when none exists
+	DeleteQuota(tenant string, event UsageEventType) error
+}
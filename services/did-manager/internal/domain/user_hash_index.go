@@ -0,0 +1,31 @@
+package domain
+
+import "github.com/google/uuid"
+
+// UserHashRehashRun reports a run of the user hash re-hash migration: lookup indexes
+// derived under the current pepper version for DIDs lacking one, and indexes under
+// versions no longer looked up deleted once none are left
+type UserHashRehashRun struct {
+	Version  string `json:"version"`
+	Rehashed int    `json:"rehashed"`
+	// Remaining counts DIDs still without an index under the current version
+	Remaining int   `json:"remaining"`
+	Retired   int64 `json:"retired"`
+}
+
+// UserHashIndexRepository defines the interface for user hash lookup index data
+// operations. A DID has one index per pepper version it was indexed under.
+type UserHashIndexRepository interface {
+	// Record stores the lookup indexes of a DID by pepper version
+	Record(didID uuid.UUID, indexes map[string]string) error
+	// GetDID retrieves the DID with any of the given indexes, returning ErrDIDNotFound
+	// when none has
+	GetDID(indexes []string) (*DID, error)
+	// ListUnindexed retrieves up to limit DIDs without an index under version
+	ListUnindexed(version string, limit int) ([]*DID, error)
+	// CountUnindexed counts the DIDs without an index under version
+	CountUnindexed(version string) (int, error)
+	// DeleteExcept deletes the indexes under every version but the given ones,
+	// returning how many were deleted
+	DeleteExcept(versions []string) (int64, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// VerifiedSubjectKind is the kind of subject a relying party verified
+type VerifiedSubjectKind string
+
+const (
+	VerifiedSubjectDID        VerifiedSubjectKind = "did"
+	VerifiedSubjectCredential VerifiedSubjectKind = "credential"
+)
+
+// VerificationRecord records that a relying party, an authenticated caller, verified a
+// DID or credential. Credentials are identified by their stored ID. Relying parties
+// that verified a subject within the propagation window are notified when it is
+// revoked.
+type VerificationRecord struct {
+	VerifierType    CallerType          `json:"verifier_type"`
+	VerifierID      string              `json:"verifier_id"`
+	SubjectKind     VerifiedSubjectKind `json:"subject_kind"`
+	Subject         string              `json:"subject"`
+	FirstVerifiedAt time.Time           `json:"first_verified_at"`
+	LastVerifiedAt  time.Time           `json:"last_verified_at"`
+}
+
+// VerificationLogRepository defines the interface for verification log data operations
+type VerificationLogRepository interface {
+	// Record stores a verification, updating the last verification time of a relying
+	// party that verified the subject before
+	Record(record *VerificationRecord) error
+	// ListVerifiers lists the relying parties that verified a subject since the given time
+	ListVerifiers(kind VerifiedSubjectKind, subject string, since time.Time) ([]*VerificationRecord, error)
+	// DeleteBefore removes records last verified before the given time
+	DeleteBefore(before time.Time) error
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// VerificationFallback decides how DID verification answers when the blockchain cannot
+// be reached
+type VerificationFallback string
+
+const (
+	// VerificationFailClosed rejects the verification with ErrChainUnreachable
+	VerificationFailClosed VerificationFallback = "fail_closed"
+	// VerificationFailOpen answers from the local record with a warning
+	VerificationFailOpen VerificationFallback = "fail_open"
+	// VerificationLocalOnly answers from the local record without querying the chain
+	VerificationLocalOnly VerificationFallback = "local_only"
+)
+
+// VerificationEndpoint names an API endpoint verifying DIDs, which policies can target
+type VerificationEndpoint string
+
+const (
+	VerificationEndpointVerify VerificationEndpoint = "verify" // POST /api/v1/did/verify
+	VerificationEndpointStatus VerificationEndpoint = "status" // GET /api/v1/did/status/:did
+	VerificationEndpointSigned VerificationEndpoint = "signed" // POST /api/v1/did/verify/signed
+)
+
+// AssuranceLevel tells relying parties what a verification result is based on:
+// local_db, chain_unconfirmed, or chain_confirmed_<N>
+type AssuranceLevel string
+
+const (
+	// AssuranceLocalDB results come from the service's database only
+	AssuranceLocalDB AssuranceLevel = "local_db"
+	// AssuranceChainUnconfirmed results were checked against the registry contract, but
+	// the DID's transaction is not known to be mined, e.g. for DIDs registered before
+	// transactions were recorded
+	AssuranceChainUnconfirmed AssuranceLevel = "chain_unconfirmed"
+)
+
+// AssuranceChainConfirmed is the level of results checked against the registry
+// contract whose DID transaction has the given number of confirmations
+func AssuranceChainConfirmed(confirmations uint64) AssuranceLevel {
+	return AssuranceLevel(fmt.Sprintf("chain_confirmed_%d", confirmations))
+}
+
+// VerificationPolicy sets the fallback of a tenant and endpoint. An empty tenant or
+// endpoint matches any; the most specific policy applies, preferring a tenant match
+// over an endpoint match.
+type VerificationPolicy struct {
+	Tenant    string               `json:"tenant"`
+	Endpoint  VerificationEndpoint `json:"endpoint"`
+	Fallback  VerificationFallback `json:"fallback"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// VerificationPolicyRequest represents a request to set a verification policy
+type VerificationPolicyRequest struct {
+	Tenant   string               `json:"tenant" binding:"max=255"`
+	Endpoint VerificationEndpoint `json:"endpoint" binding:"omitempty,oneof=verify status signed"`
+	Fallback VerificationFallback `json:"fallback" binding:"required,oneof=fail_closed fail_open local_only"`
+}
+
+// VerificationPolicyRepository defines the interface for verification policy data
+// operations
+type VerificationPolicyRepository interface {
+	List() ([]*VerificationPolicy, error)
+	// Effective returns the most specific policy for a tenant and endpoint, or nil when
+	// none matches
+	Effective(tenant string, endpoint VerificationEndpoint) (*VerificationPolicy, error)
+	Upsert(policy *VerificationPolicy) error
+	// Delete removes a policy, returning ErrVerificationPolicyNotFound when none exists
+	Delete(tenant string, endpoint VerificationEndpoint) error
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationSessionStatus represents the state of a cross-device verification session
+type VerificationSessionStatus string
+
+const (
+	VerificationSessionPending  VerificationSessionStatus = "pending"  // waiting for a wallet to scan the QR code
+	VerificationSessionScanned  VerificationSessionStatus = "scanned"  // a wallet fetched the request
+	VerificationSessionVerified VerificationSessionStatus = "verified" // the wallet's response verified
+	VerificationSessionRejected VerificationSessionStatus = "rejected" // the wallet's response failed verification
+	VerificationSessionExpired  VerificationSessionStatus = "expired"
+)
+
+// IsFinal reports whether a session can no longer change
+func (s VerificationSessionStatus) IsFinal() bool {
+	return s == VerificationSessionVerified || s == VerificationSessionRejected || s == VerificationSessionExpired
+}
+
+// VerificationSession is a verifier's request for a wallet on another device to
+// authenticate with its DID or present credentials
+type VerificationSession struct {
+	ID uuid.UUID `json:"id"`
+	// VerifierType and VerifierID identify the caller that created the session
+	VerifierType    CallerType `json:"-"`
+	VerifierID      string     `json:"-"`
+	Challenge       string     `json:"challenge"`
+	Domain          string     `json:"domain"`
+	CredentialTypes []string   `json:"credential_types"`
+	Status          string     `json:"status"`
+	HolderDID       string     `json:"holder_did,omitempty"`
+	Message         string     `json:"message,omitempty"`
+	// Presentation is the verified presentation submitted by the wallet
+	Presentation json.RawMessage `json:"presentation,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+}
+
+// VerificationSessionCreateRequest represents a verifier's request to start a session.
+// Without credential types the wallet only needs to prove control of its DID.
+type VerificationSessionCreateRequest struct {
+	CredentialTypes []string `json:"credential_types"`
+	Domain          string   `json:"domain" binding:"required"`
+}
+
+// VerificationSessionCreateResponse is returned to the verifier when a session starts
+type VerificationSessionCreateResponse struct {
+	Session *VerificationSession `json:"session"`
+	// QRPayload is the content of the QR code the verifier renders for the wallet
+	QRPayload string `json:"qr_payload"`
+	// QRImageURL serves QRPayload as a PNG image
+	QRImageURL string `json:"qr_image_url"`
+	EventsURL  string `json:"events_url"`
+}
+
+// VerificationRequest is what a wallet receives after scanning a session's QR code
+type VerificationRequest struct {
+	SessionID       uuid.UUID `json:"session_id"`
+	Challenge       string    `json:"challenge"`
+	Domain          string    `json:"domain"`
+	CredentialTypes []string  `json:"credential_types"`
+	// ResponseURI is where the wallet posts its signed presentation
+	ResponseURI string    `json:"response_uri"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// VerificationResponseRequest carries the wallet's signed presentation; a presentation
+// without credentials serves as DID authentication
+type VerificationResponseRequest struct {
+	Presentation json.RawMessage `json:"presentation" binding:"required"`
+}
+
+// PresentationVerifyResponse reports the outcome of presentation verification
+type PresentationVerifyResponse struct {
+	Valid   bool   `json:"valid"`
+	Holder  string `json:"holder,omitempty"`
+	Message string `json:"message"`
+	// CredentialIDs are the document IDs of the credentials in a valid presentation,
+	// recorded for revocation propagation
+	CredentialIDs []string `json:"-"`
+}
+
+// VerificationSessionRepository defines the interface for verification session data operations
+type VerificationSessionRepository interface {
+	Create(session *VerificationSession) error
+	GetByID(id uuid.UUID) (*VerificationSession, error)
+	// MarkScanned moves a pending session to scanned
+	MarkScanned(id uuid.UUID) error
+	// Complete records the outcome of an open session, returning ErrVerificationSessionClosed
+	// if it already completed
+	Complete(session *VerificationSession) error
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletDID is the view of a DID exposed to third-party wallet applications;
+// it deliberately omits key material and the user hash
+type WalletDID struct {
+	ID           uuid.UUID `json:"id"`
+	Did          string    `json:"did"`
+	KeyAlgorithm string    `json:"key_algorithm"`
+	Custodial    bool      `json:"custodial"`
+	Status       string    `json:"status"`
+	Visibility   string    `json:"visibility"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewWalletDID builds the wallet view of a DID record
+func NewWalletDID(record *DID) *WalletDID {
+	return &WalletDID{
+		ID:           record.ID,
+		Did:          record.Did,
+		KeyAlgorithm: record.KeyAlgorithm,
+		Custodial:    record.Custodial,
+		Status:       record.Status,
+		Visibility:   record.Visibility,
+		CreatedAt:    record.CreatedAt,
+	}
+}
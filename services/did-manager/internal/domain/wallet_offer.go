@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletOfferStatus represents the state of a wallet offer
+type WalletOfferStatus string
+
+const (
+	WalletOfferPending WalletOfferStatus = "pending" // waiting for a wallet to claim it
+	WalletOfferClaimed WalletOfferStatus = "claimed"
+	WalletOfferExpired WalletOfferStatus = "expired"
+)
+
+// IsFinal reports whether an offer can no longer change
+func (s WalletOfferStatus) IsFinal() bool {
+	return s == WalletOfferClaimed || s == WalletOfferExpired
+}
+
+// WalletOffer lets a user import a DID and its credentials into a mobile wallet with a
+// one-time claim link, typically sent at the end of signup
+type WalletOffer struct {
+	ID    uuid.UUID `json:"id"`
+	DIDID uuid.UUID `json:"-"`
+	DID   string    `json:"did"`
+	// CredentialIDs limits the offer to these credentials; when empty, every active
+	// credential the DID holds at claim time is offered
+	CredentialIDs []uuid.UUID `json:"credential_ids"`
+	// CodeHash is the SHA-256 of the claim code carried by the claim link
+	CodeHash  string     `json:"-"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+}
+
+// WalletOfferCreateRequest represents a request to offer an active DID to its owner's wallet
+type WalletOfferCreateRequest struct {
+	DID           string      `json:"did" binding:"required"`
+	CredentialIDs []uuid.UUID `json:"credential_ids"`
+}
+
+// WalletOfferCreateResponse carries the claim link, which is only returned once
+type WalletOfferCreateResponse struct {
+	Offer *WalletOffer `json:"offer"`
+	// ClaimURI is the wallet deep link claiming the offer
+	ClaimURI string `json:"claim_uri"`
+	// QRImage is ClaimURI as a PNG data URI, for pages and emails showing a QR code
+	QRImage string `json:"qr_image"`
+}
+
+// WalletOfferClaimRequest presents the claim code of a claim link
+type WalletOfferClaimRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// WalletOfferClaim is what a wallet receives for a claimed offer: the DID to import and
+// its credentials. Wallets take over custodial DIDs with a custody transfer.
+type WalletOfferClaim struct {
+	DIDID       uuid.UUID     `json:"did_id"`
+	DID         string        `json:"did"`
+	Custodial   bool          `json:"custodial"`
+	Credentials []*Credential `json:"credentials"`
+}
+
+// WalletOfferRepository defines the interface for wallet offer data operations
+type WalletOfferRepository interface {
+	Create(offer *WalletOffer) error
+	GetByID(id uuid.UUID) (*WalletOffer, error)
+	// Close moves a pending offer to a final status, returning ErrWalletOfferClosed if
+	// it is no longer pending, so an offer is only ever claimed once
+	Close(id uuid.UUID, status WalletOfferStatus, closedAt time.Time) error
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook retry defaults for endpoints that do not configure their own
+const (
+	DefaultWebhookMaxAttempts    = 5
+	DefaultWebhookBackoffSeconds = 30
+)
+
+// WebhookEndpoint delivers a tenant's domain events to a URL. Tenants are callers: an
+// API key ID or a DID. An endpoint receives the events about DIDs its tenant may
+// resolve, limited to EventTypes unless that is empty.
+type WebhookEndpoint struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TenantType CallerType `json:"tenant_type" db:"tenant_type"`
+	Tenant     string     `json:"tenant" db:"tenant"`
+	URL        string     `json:"url" db:"url"`
+	EventTypes []string   `json:"event_types" db:"event_types"`
+	// Headers are sent with every delivery, e.g. to authenticate with the receiver
+	Headers map[string]string `json:"headers" db:"headers"`
+	// Secret keys the HMAC-SHA256 signature of every delivery; it is only returned
+	// when the endpoint is created or the secret rotated
+	Secret string `json:"secret,omitempty" db:"secret"`
+	// MaxAttempts bounds the attempts of a delivery, the first included
+	MaxAttempts int `json:"max_attempts" db:"max_attempts"`
+	// BackoffSeconds is the delay before the first retry, doubling with every retry
+	BackoffSeconds int       `json:"backoff_seconds" db:"backoff_seconds"`
+	Active         bool      `json:"active" db:"active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	// EncryptToDID is the DID whose keyAgreement key payloads are encrypted to, posted
+	// as compact JWEs; payloads are posted as plain JSON when empty
+	EncryptToDID string `json:"encrypt_to_did,omitempty" db:"encrypt_to_did"`
+}
+
+// Subscribes reports whether the endpoint receives events of a type
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range e.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpointRequest represents a tenant's request to configure a webhook endpoint.
+// Zero retry settings use the defaults.
+type WebhookEndpointRequest struct {
+	URL            string            `json:"url" binding:"required,url,max=2048"`
+	EventTypes     []string          `json:"event_types" binding:"max=20,dive,required,max=64"`
+	Headers        map[string]string `json:"headers" binding:"max=20"`
+	MaxAttempts    int               `json:"max_attempts" binding:"min=0,max=20"`
+	BackoffSeconds int               `json:"backoff_seconds" binding:"min=0,max=86400"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+	// EncryptToDID encrypts payloads to a DID the tenant may resolve, which must have a
+	// keyAgreement key
+	EncryptToDID string `json:"encrypt_to_did" binding:"max=255"`
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event to deliver to an endpoint, retried until it succeeds or
+// runs out of attempts
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	EndpointID uuid.UUID `json:"endpoint_id" db:"endpoint_id"`
+	EventID    string    `json:"event_id" db:"event_id"`
+	EventType  string    `json:"event_type" db:"event_type"`
+	// Payload is the body posted to the endpoint
+	Payload       json.RawMessage       `json:"payload" db:"payload"`
+	Status        WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts      int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" db:"updated_at"`
+	// ReplayID is the replay that re-delivered the event; nil for live deliveries
+	ReplayID *uuid.UUID `json:"replay_id,omitempty" db:"replay_id"`
+	// AttemptLog lists the delivery's attempts, oldest first, when requested
+	AttemptLog []*WebhookAttempt `json:"attempt_log,omitempty" db:"-"`
+}
+
+// WebhookAttempt records one attempt to deliver an event
+type WebhookAttempt struct {
+	Attempt int `json:"attempt" db:"attempt"`
+	// StatusCode is the receiver's response status, zero when no response arrived
+	StatusCode  int       `json:"status_code,omitempty" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	DurationMs  int64     `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// WebhookDeliveryQuery filters an endpoint's delivery history
+type WebhookDeliveryQuery struct {
+	Status WebhookDeliveryStatus `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Limit  int                   `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// WebhookReplayRequest selects the retained events an administrator re-delivers to a
+// tenant's webhook endpoints, e.g. after the tenant's consumer was down. Empty filters
+// match every event the endpoints subscribe to.
+type WebhookReplayRequest struct {
+	// Tenant is an API key ID or a DID
+	Tenant string `json:"tenant" binding:"required,max=255"`
+	// EndpointID limits the replay to one of the tenant's endpoints; every active
+	// endpoint of the tenant is replayed to otherwise
+	EndpointID *uuid.UUID `json:"endpoint_id"`
+	EventTypes []string   `json:"event_types" binding:"max=20,dive,required,max=64"`
+	Since      time.Time  `json:"since" binding:"required"`
+	// Until defaults to now
+	Until *time.Time `json:"until"`
+	// Resource is a DID or credential ID the events must concern
+	Resource string `json:"resource" binding:"max=255"`
+	// Limit bounds the events replayed, defaulting to 1000
+	Limit int `json:"limit" binding:"min=0,max=10000"`
+}
+
+// WebhookReplay reports the events a replay queued for delivery
+type WebhookReplay struct {
+	ID          uuid.UUID   `json:"id"`
+	Tenant      string      `json:"tenant"`
+	EndpointIDs []uuid.UUID `json:"endpoint_ids"`
+	// Events counts the matching events and Deliveries the deliveries queued for them
+	Events     int `json:"events"`
+	Deliveries int `json:"deliveries"`
+	// ResumeAt is set when the limit stopped the replay early: the time the last
+	// replayed event occurred at, to replay the rest from
+	ResumeAt *time.Time `json:"resume_at,omitempty"`
+}
+
+// WebhookRepository defines the interface for webhook endpoint and delivery data
+// operations
+type WebhookRepository interface {
+	CreateEndpoint(endpoint *WebhookEndpoint) error
+	// GetEndpoint retrieves an endpoint, returning ErrWebhookNotFound when none exists
+	GetEndpoint(id uuid.UUID) (*WebhookEndpoint, error)
+	UpdateEndpoint(endpoint *WebhookEndpoint) error
+	// DeleteEndpoint removes an endpoint with its delivery history
+	DeleteEndpoint(id uuid.UUID) error
+	ListEndpointsByTenant(tenantType CallerType, tenant string) ([]*WebhookEndpoint, error)
+	// ListActiveEndpoints retrieves the active endpoints subscribed to an event type
+	ListActiveEndpoints(eventType string) ([]*WebhookEndpoint, error)
+	CreateDelivery(delivery *WebhookDelivery) error
+	// GetDelivery retrieves a delivery, returning ErrWebhookDeliveryNotFound when none
+	// exists
+	GetDelivery(id uuid.UUID) (*WebhookDelivery, error)
+	ListDeliveries(endpointID uuid.UUID, query *WebhookDeliveryQuery) ([]*WebhookDelivery, error)
+	// ClaimDueDeliveries retrieves pending deliveries whose next attempt is due,
+	// postponing them by lease so concurrent workers skip them meanwhile
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	// RecordAttempt adds an attempt to a delivery's history and updates its status,
+	// scheduling the next attempt at nextAttemptAt while it stays pending
+	RecordAttempt(id uuid.UUID, attempt *WebhookAttempt, status WebhookDeliveryStatus, nextAttemptAt *time.Time) error
+	ListAttempts(deliveryID uuid.UUID) ([]*WebhookAttempt, error)
+}
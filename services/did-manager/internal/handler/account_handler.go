@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// AccountHandler handles administrative notifications of account changes from the auth
+// service and the suspension of users' DIDs
+type AccountHandler struct {
+	accounts *services.AccountEventService
+	adminKey string
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accounts *services.AccountEventService, adminKey string) *AccountHandler {
+	return &AccountHandler{
+		accounts: accounts,
+		adminKey: adminKey,
+	}
+}
+
+// RecordPasswordChange records a change of a user's account password on their DIDs
+func (h *AccountHandler) RecordPasswordChange(c web.Context) {
+	userID, ok := accountUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.accounts.RecordPasswordChange(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to record password change",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// SuspendUserDIDs suspends a user's active DIDs
+func (h *AccountHandler) SuspendUserDIDs(c web.Context) {
+	userID, ok := accountUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SuspensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.accounts.SuspendUserDIDs(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to suspend DIDs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ReinstateUserDIDs lifts the suspension of a user's DIDs
+func (h *AccountHandler) ReinstateUserDIDs(c web.Context) {
+	userID, ok := accountUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.accounts.ReinstateUserDIDs(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to reinstate DIDs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// accountUserID parses the user ID of an account route, writing an error if it is invalid
+func accountUserID(c web.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	// Anonymous DIDs created without a user ID share the nil UUID
+	if err != nil || userID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid user ID format",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// RegisterRoutes registers the admin account routes
+func (h *AccountHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.POST("/users/:userID/password-changes", h.RecordPasswordChange)
+		admin.POST("/users/:userID/suspension", h.SuspendUserDIDs)
+		admin.DELETE("/users/:userID/suspension", h.ReinstateUserDIDs)
+	}
+}
@@ -0,0 +1,734 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	access        *services.AccessService
+	dids          *services.DIDService
+	organizations *services.OrganizationService
+	repair        *services.RepairService
+	monitor       *security.EnumerationMonitor
+	adminKey      string
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(access *services.AccessService, dids *services.DIDService, organizations *services.OrganizationService, repair *services.RepairService, monitor *security.EnumerationMonitor, adminKey string) *AdminHandler {
+	return &AdminHandler{
+		access:        access,
+		dids:          dids,
+		organizations: organizations,
+		repair:        repair,
+		monitor:       monitor,
+		adminKey:      adminKey,
+	}
+}
+
+// CreateAPIKey issues a new relying party API key
+func (h *AdminHandler) CreateAPIKey(c web.Context) {
+	var req domain.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.access.CreateAPIKey(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListAPIKeys lists issued API keys
+func (h *AdminHandler) ListAPIKeys(c web.Context) {
+	keys, err := h.access.ListAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// RevokeAPIKey revokes an API key
+func (h *AdminHandler) RevokeAPIKey(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	if err := h.access.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "API key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "API key revoked",
+	})
+}
+
+// RotateAPIKey replaces the secret of an API key, keeping its ID
+func (h *AdminHandler) RotateAPIKey(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	response, err := h.access.RotateAPIKey(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Active API key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to rotate API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// SetRootDID binds the root capability of a tenant key to a DID, letting the DID
+// delegate the tenant's operations to services with UCANs
+func (h *AdminHandler) SetRootDID(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	var req domain.APIKeyRootDIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.respondRootDID(c, id, req.DID)
+}
+
+// ClearRootDID unbinds the root capability of a tenant key, so UCANs rooted at its
+// former root DID stop working
+func (h *AdminHandler) ClearRootDID(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	h.respondRootDID(c, id, "")
+}
+
+// respondRootDID sets the root DID of a tenant key and writes the updated key
+func (h *AdminHandler) respondRootDID(c web.Context, id uuid.UUID, rootDID string) {
+	key, err := h.access.SetRootDID(id, rootDID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidRootDID):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, domain.ErrRootDIDInUse):
+			c.JSON(http.StatusConflict, web.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, domain.ErrAPIKeyNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Active tenant API key not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to set root DID",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// SetVisibility marks a DID as public or private
+func (h *AdminHandler) SetVisibility(c web.Context) {
+	var req domain.DIDVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.access.SetVisibility(&req); err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to update DID visibility",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "DID visibility updated",
+	})
+}
+
+// DeactivateDID deactivates a DID on behalf of its holder, such as a custodial DID whose
+// holder cannot sign requests
+func (h *AdminHandler) DeactivateDID(c web.Context) {
+	var req domain.DIDDeactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.dids.DeactivateDID(req.DID, "")
+	if err != nil {
+		respondDeactivationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetOrCreateDID returns the DID a user already has, found by user ID or email
+// commitment, or creates one. Account services call it on signup so a returning user
+// keeps their DID rather than getting a duplicate.
+func (h *AdminHandler) GetOrCreateDID(c web.Context) {
+	var req domain.DIDCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.UserID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "User ID is required",
+		})
+		return
+	}
+	caller := callerFromContext(c)
+	req.Tenant = caller.ID
+	req.SandboxTenant = caller.SandboxTenant
+
+	response, err := h.dids.GetOrCreateDID(&req)
+	if err != nil {
+		respondCreateDIDError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if response.Existing {
+		status = http.StatusOK
+	}
+	c.JSON(status, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// SetControllers makes a DID an organization DID whose operations require m-of-n
+// controller approvals
+func (h *AdminHandler) SetControllers(c web.Context) {
+	var req domain.OrganizationControllersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	organization, err := h.organizations.SetControllers(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "DID not found",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrNotCustodial), errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid controllers",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to update DID controllers",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    organization,
+	})
+}
+
+// GrantAccess grants a caller access to resolve a private DID
+func (h *AdminHandler) GrantAccess(c web.Context) {
+	var req domain.ACLGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.access.GrantAccess(&req)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Failed to grant access",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    entry,
+	})
+}
+
+// ListAccess lists the ACL entries of a DID
+func (h *AdminHandler) ListAccess(c web.Context) {
+	did := c.Query("did")
+	if did == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "did query parameter is required",
+		})
+		return
+	}
+
+	entries, err := h.access.ListAccess(did)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list access entries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    entries,
+	})
+}
+
+// RevokeAccess removes an ACL entry
+func (h *AdminHandler) RevokeAccess(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid ACL entry ID format",
+		})
+		return
+	}
+
+	if err := h.access.RevokeAccess(id); err != nil {
+		if errors.Is(err, domain.ErrACLEntryNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "ACL entry not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke access",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Access revoked",
+	})
+}
+
+// EnumerationSuspects lists callers currently flagged for DID enumeration
+func (h *AdminHandler) EnumerationSuspects(c web.Context) {
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    h.monitor.Suspects(),
+	})
+}
+
+// CheckConsistency reports inconsistencies between dids, blockchain jobs and the chain
+func (h *AdminHandler) CheckConsistency(c web.Context) {
+	h.consistencyReport(c, false)
+}
+
+// RepairConsistency repairs the inconsistencies that can be fixed automatically and
+// reports the rest
+func (h *AdminHandler) RepairConsistency(c web.Context) {
+	h.consistencyReport(c, true)
+}
+
+// consistencyReport runs a consistency check and responds with its report
+func (h *AdminHandler) consistencyReport(c web.Context, fix bool) {
+	report, err := h.repair.Check(fix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to check consistency",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// SimulateJob dry-runs a blockchain operation on a DID, returning the job with its
+// gas estimate or revert reason
+func (h *AdminHandler) SimulateJob(c web.Context) {
+	var req domain.JobSimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	job, err := h.dids.SimulateJob(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrBlockchainUnavailable):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain is unavailable",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to simulate job",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// ListDIDs lists DIDs page by page, filtered by status, user, method and creation time
+func (h *AdminHandler) ListDIDs(c web.Context) {
+	var req domain.DIDListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.dids.ListDIDs(&req)
+	if err != nil {
+		if respondInvalidFilter(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list DIDs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ListJobs lists blockchain jobs page by page, filtered by status, type, DID and
+// creation time
+func (h *AdminHandler) ListJobs(c web.Context) {
+	var req domain.JobListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.dids.ListJobs(&req)
+	if err != nil {
+		if respondInvalidFilter(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// respondInvalidFilter responds 422 to a listing filtered by an unknown status or job
+// type, reporting whether it did
+func respondInvalidFilter(c web.Context, err error) bool {
+	if !errors.Is(err, domain.ErrInvalidDIDStatus) && !errors.Is(err, domain.ErrInvalidJobStatus) &&
+		!errors.Is(err, domain.ErrInvalidJobType) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, web.H{
+		"error":   "Invalid filter",
+		"details": err.Error(),
+	})
+	return true
+}
+
+// GetJob returns a blockchain job, including the simulated result of dry runs
+func (h *AdminHandler) GetJob(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid job ID format",
+		})
+		return
+	}
+
+	job, err := h.dids.GetJob(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Job not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// CancelJob cancels a blockchain job before its transaction is broadcast, or revokes
+// its DID with a compensating job once it was
+func (h *AdminHandler) CancelJob(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid job ID format",
+		})
+		return
+	}
+
+	// The reason is optional
+	var req domain.JobCancelRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	cancellation, err := h.dids.CancelJob(id, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Job not found",
+			})
+		case errors.Is(err, domain.ErrJobNotCancelable):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Job cannot be canceled",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to cancel job",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    cancellation,
+	})
+}
+
+// GetJobTimeline returns the phases of a blockchain job with its latency per stage
+func (h *AdminHandler) GetJobTimeline(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid job ID format",
+		})
+		return
+	}
+
+	timeline, err := h.dids.GetJobTimeline(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Job not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get job timeline",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    timeline,
+	})
+}
+
+// RegisterRoutes registers all admin routes
+func (h *AdminHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		// API keys
+		admin.POST("/api-keys", h.CreateAPIKey)
+		admin.GET("/api-keys", h.ListAPIKeys)
+		admin.DELETE("/api-keys/:id", h.RevokeAPIKey)
+		admin.POST("/api-keys/:id/rotate", h.RotateAPIKey)
+		admin.PUT("/api-keys/:id/root-did", h.SetRootDID)
+		admin.DELETE("/api-keys/:id/root-did", h.ClearRootDID)
+
+		// DIDs
+		admin.GET("/dids", h.ListDIDs)
+		admin.POST("/did/deactivate", h.DeactivateDID)
+		admin.POST("/did/get-or-create", h.GetOrCreateDID)
+
+		// DID access control
+		admin.PUT("/did/visibility", h.SetVisibility)
+		admin.PUT("/did/controllers", h.SetControllers)
+		admin.POST("/acl", h.GrantAccess)
+		admin.GET("/acl", h.ListAccess)
+		admin.DELETE("/acl/:id", h.RevokeAccess)
+
+		// Security monitoring
+		admin.GET("/security/enumeration", h.EnumerationSuspects)
+
+		// DID state consistency
+		admin.GET("/consistency", h.CheckConsistency)
+		admin.POST("/consistency/repair", h.RepairConsistency)
+
+		// Blockchain jobs
+		admin.GET("/jobs", h.ListJobs)
+		admin.POST("/jobs/simulate", h.SimulateJob)
+		admin.GET("/jobs/:id", h.GetJob)
+		admin.GET("/jobs/:id/timeline", h.GetJobTimeline)
+		admin.POST("/jobs/:id/cancel", h.CancelJob)
+	}
+}
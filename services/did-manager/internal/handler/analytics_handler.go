@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// AnalyticsHandler handles administrative requests for the analytics export and the
+// DID funnel
+type AnalyticsHandler struct {
+	analytics *services.AnalyticsExportService
+	funnel    *services.FunnelService
+	adminKey  string
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(analytics *services.AnalyticsExportService, funnel *services.FunnelService, adminKey string) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analytics: analytics,
+		funnel:    funnel,
+		adminKey:  adminKey,
+	}
+}
+
+// ListExports lists the most recent analytics exports
+func (h *AnalyticsHandler) ListExports(c web.Context) {
+	exports, err := h.analytics.ListExports()
+	if err != nil {
+		respondAnalyticsError(c, err, "Failed to list analytics exports")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    exports,
+	})
+}
+
+// RunExport exports the events recorded since the last export outside the schedule
+func (h *AnalyticsHandler) RunExport(c web.Context) {
+	exports, err := h.analytics.RunExport()
+	if err != nil {
+		respondAnalyticsError(c, err, "Failed to export analytics events")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    exports,
+	})
+}
+
+// GetFunnel reports how many of the DIDs each tenant created per day were anchored,
+// verified and issued a credential
+func (h *AnalyticsHandler) GetFunnel(c web.Context) {
+	var req domain.FunnelRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	report, err := h.funnel.Report(req.From, req.To, req.Tenant)
+	if err != nil {
+		if errors.Is(err, domain.ErrFunnelRangeTooLong) {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid query parameters",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get DID funnel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// respondAnalyticsError maps analytics export errors to responses
+func respondAnalyticsError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrAnalyticsExportDisabled):
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error":   "Analytics export is not enabled",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the admin analytics routes
+func (h *AnalyticsHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/analytics/exports", h.ListExports)
+		admin.POST("/analytics/exports", h.RunExport)
+		admin.GET("/analytics/funnel", h.GetFunnel)
+	}
+}
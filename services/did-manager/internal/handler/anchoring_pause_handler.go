@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// AnchoringPauseHandler handles HTTP requests for the operator switch pausing
+// anchoring
+type AnchoringPauseHandler struct {
+	pause    *services.AnchoringPauseService
+	adminKey string
+}
+
+// NewAnchoringPauseHandler creates a new anchoring pause handler
+func NewAnchoringPauseHandler(pause *services.AnchoringPauseService, adminKey string) *AnchoringPauseHandler {
+	return &AnchoringPauseHandler{
+		pause:    pause,
+		adminKey: adminKey,
+	}
+}
+
+// GetStatus reports whether anchoring is paused and how many jobs are not yet anchored
+func (h *AnchoringPauseHandler) GetStatus(c web.Context) {
+	status, err := h.pause.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get anchoring status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Pause stops job submission on every instance, or updates the reason of a pause
+func (h *AnchoringPauseHandler) Pause(c web.Context) {
+	var req domain.AnchoringPauseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.pause.Pause(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to pause anchoring",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Resume lets the workers submit the queued jobs again
+func (h *AnchoringPauseHandler) Resume(c web.Context) {
+	status, err := h.pause.Resume()
+	if err != nil {
+		if errors.Is(err, domain.ErrAnchoringNotPaused) {
+			c.JSON(http.StatusConflict, web.H{
+				"error": "Anchoring is not paused",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to resume anchoring",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// RegisterRoutes registers the admin anchoring routes
+func (h *AnchoringPauseHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/anchoring", h.GetStatus)
+		admin.PUT("/anchoring/pause", h.Pause)
+		admin.DELETE("/anchoring/pause", h.Resume)
+	}
+}
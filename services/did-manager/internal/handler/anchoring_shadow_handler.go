@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/web"
+)
+
+// AnchoringShadowHandler reports how the shadow anchor backend compares with the
+// primary while a migration is evaluated
+type AnchoringShadowHandler struct {
+	shadow   *blockchain.ShadowChain
+	adminKey string
+}
+
+// NewAnchoringShadowHandler creates a new anchoring shadow handler
+func NewAnchoringShadowHandler(shadow *blockchain.ShadowChain, adminKey string) *AnchoringShadowHandler {
+	return &AnchoringShadowHandler{
+		shadow:   shadow,
+		adminKey: adminKey,
+	}
+}
+
+// GetStats reports the comparisons by method and result, and the recent divergences
+func (h *AnchoringShadowHandler) GetStats(c web.Context) {
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    h.shadow.Stats(),
+	})
+}
+
+// RegisterRoutes registers the admin shadow anchoring route
+func (h *AnchoringShadowHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/anchoring/shadow", h.GetStats)
+	}
+}
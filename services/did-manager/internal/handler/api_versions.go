@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/apiversion"
+)
+
+// NewAPIVersions returns the API versions served side by side. v1 is the API existing
+// integrators use, deprecated from deprecation and retired at sunset when they are set.
+// v2 serves every v1 route it does not replace, in the v2 envelope, so breaking changes
+// ship as v2 routes while v1 keeps working.
+func NewAPIVersions(deprecation, sunset time.Time) (v1, v2 *apiversion.Version) {
+	v1 = &apiversion.Version{
+		Name:        "v1",
+		Prefix:      "/api/v1",
+		Deprecation: deprecation,
+		Sunset:      sunset,
+	}
+	v2 = &apiversion.Version{
+		Name:     "v2",
+		Prefix:   "/api/v2",
+		Base:     v1,
+		Response: envelopeV2,
+	}
+	v1.Successor = v2
+	return v1, v2
+}
+
+// envelopeV2 maps a v1 response to the v2 envelope. Successful responses carry their
+// result in "data" and anything else, such as pagination or quotas, in "meta"; v1's
+// "success" flag is dropped. Errors carry an "error" object with a machine-readable
+// "code" derived from the status, the v1 message and details, and the rest of the v1
+// body, such as the request ID.
+func envelopeV2(status int, body any) any {
+	fields, ok := body.(web.H)
+	if status >= http.StatusBadRequest {
+		problem := web.H{"code": errorCode(status), "message": http.StatusText(status)}
+		if !ok {
+			problem["details"] = body
+			return web.H{"error": problem}
+		}
+		for key, value := range fields {
+			switch key {
+			case "success":
+			case "error":
+				if message, isString := value.(string); isString {
+					problem["message"] = message
+				} else if _, hasDetails := fields["details"]; !hasDetails {
+					problem["details"] = value
+				}
+			default:
+				problem[key] = value
+			}
+		}
+		return web.H{"error": problem}
+	}
+
+	if !ok {
+		return web.H{"data": body}
+	}
+	data, hasData := fields["data"]
+	meta := web.H{}
+	for key, value := range fields {
+		if key != "success" && key != "data" {
+			meta[key] = value
+		}
+	}
+	if !hasData {
+		return web.H{"data": meta}
+	}
+	envelope := web.H{"data": data}
+	if len(meta) > 0 {
+		envelope["meta"] = meta
+	}
+	return envelope
+}
+
+// errorCode derives the v2 error code of a status from its text, e.g. not_found or
+// too_many_requests
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("status_%d", status)
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, strings.ReplaceAll(text, "'", ""))
+}
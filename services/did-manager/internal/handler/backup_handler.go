@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// BackupHandler handles administrative requests for backups of identity data
+type BackupHandler struct {
+	backups  *services.BackupService
+	adminKey string
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(backups *services.BackupService, adminKey string) *BackupHandler {
+	return &BackupHandler{
+		backups:  backups,
+		adminKey: adminKey,
+	}
+}
+
+// ListBackups lists the most recent backups
+func (h *BackupHandler) ListBackups(c web.Context) {
+	backups, err := h.backups.ListBackups()
+	if err != nil {
+		respondBackupError(c, err, "Failed to list backups")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    backups,
+	})
+}
+
+// CreateBackup takes a backup outside the schedule
+func (h *BackupHandler) CreateBackup(c web.Context) {
+	backup, err := h.backups.RunBackup()
+	if err != nil {
+		respondBackupError(c, err, "Failed to create backup")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    backup,
+	})
+}
+
+// RestoreBackup restores a tenant's missing DIDs, credentials and jobs from a backup
+func (h *BackupHandler) RestoreBackup(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid ID format",
+		})
+		return
+	}
+
+	var req domain.BackupRestoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.backups.Restore(id, &req)
+	if err != nil {
+		respondBackupError(c, err, "Failed to restore backup")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// respondBackupError maps backup service errors to responses
+func respondBackupError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrBackupNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Backup not found",
+		})
+	case errors.Is(err, domain.ErrInvalidBackup):
+		c.JSON(http.StatusUnprocessableEntity, web.H{
+			"error":   "Backup cannot be restored",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrBackupsDisabled):
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error":   "Backups are not enabled",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the admin backup routes
+func (h *BackupHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/backups", h.ListBackups)
+		admin.POST("/backups", h.CreateBackup)
+		admin.POST("/backups/:id/restore", h.RestoreBackup)
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// BulkRevocationHandler handles the admin HTTP requests revoking many DIDs or
+// credentials at once
+type BulkRevocationHandler struct {
+	revocations *services.BulkRevocationService
+	adminKey    string
+}
+
+// NewBulkRevocationHandler creates a new bulk revocation handler
+func NewBulkRevocationHandler(revocations *services.BulkRevocationService, adminKey string) *BulkRevocationHandler {
+	return &BulkRevocationHandler{
+		revocations: revocations,
+		adminKey:    adminKey,
+	}
+}
+
+// StageDIDs stages the deactivation of many DIDs
+func (h *BulkRevocationHandler) StageDIDs(c web.Context) {
+	var req domain.DIDBulkRevocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	revocation, err := h.revocations.StageDIDs(&req)
+	if err != nil {
+		h.fail(c, err, "Failed to stage bulk revocation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    revocation,
+	})
+}
+
+// StageCredentials stages the revocation of many credentials
+func (h *BulkRevocationHandler) StageCredentials(c web.Context) {
+	var req domain.CredentialBulkRevocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	revocation, err := h.revocations.StageCredentials(&req)
+	if err != nil {
+		h.fail(c, err, "Failed to stage bulk revocation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    revocation,
+	})
+}
+
+// ListRevocations lists the most recent bulk revocations with their progress
+func (h *BulkRevocationHandler) ListRevocations(c web.Context) {
+	revocations, err := h.revocations.List()
+	if err != nil {
+		h.fail(c, err, "Failed to list bulk revocations")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    revocations,
+	})
+}
+
+// GetRevocation reports a bulk revocation's progress with its failed targets
+func (h *BulkRevocationHandler) GetRevocation(c web.Context) {
+	id, ok := h.revocationID(c)
+	if !ok {
+		return
+	}
+
+	revocation, err := h.revocations.Get(id)
+	if err != nil {
+		h.fail(c, err, "Failed to get bulk revocation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    revocation,
+	})
+}
+
+// ExecuteRevocation starts revoking a staged bulk revocation's targets
+func (h *BulkRevocationHandler) ExecuteRevocation(c web.Context) {
+	id, ok := h.revocationID(c)
+	if !ok {
+		return
+	}
+
+	revocation, err := h.revocations.Execute(id)
+	if err != nil {
+		h.fail(c, err, "Failed to execute bulk revocation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    revocation,
+	})
+}
+
+// CancelRevocation cancels a staged bulk revocation
+func (h *BulkRevocationHandler) CancelRevocation(c web.Context) {
+	id, ok := h.revocationID(c)
+	if !ok {
+		return
+	}
+
+	revocation, err := h.revocations.Cancel(id)
+	if err != nil {
+		h.fail(c, err, "Failed to cancel bulk revocation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    revocation,
+	})
+}
+
+// revocationID parses the bulk revocation ID of the path, answering 400 when invalid
+func (h *BulkRevocationHandler) revocationID(c web.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid bulk revocation ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// fail answers a failed bulk revocation request
+func (h *BulkRevocationHandler) fail(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrBulkRevocationNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Bulk revocation not found",
+		})
+	case errors.Is(err, domain.ErrBulkRevocationState):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "Bulk revocation is not staged",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrBulkRevocationTooLarge):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Too many targets",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the admin bulk revocation routes
+func (h *BulkRevocationHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1", RequireAdmin(h.adminKey))
+	{
+		api.POST("/did/revoke/batch", h.StageDIDs)
+		api.POST("/credentials/revoke/batch", h.StageCredentials)
+	}
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/bulk-revocations", h.ListRevocations)
+		admin.GET("/bulk-revocations/:id", h.GetRevocation)
+		admin.POST("/bulk-revocations/:id/execute", h.ExecuteRevocation)
+		admin.POST("/bulk-revocations/:id/cancel", h.CancelRevocation)
+	}
+}
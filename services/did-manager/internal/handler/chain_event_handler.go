@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"io"
+	"time"
+
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// chainEventsKeepAlive is how often an idle chain event stream sends a keep-alive
+// event, so proxies do not close it
+const chainEventsKeepAlive = 30 * time.Second
+
+// ChainEventHandler streams on-chain DID updates and revocations, so relying parties
+// can drop their own caches as soon as the chain changes
+type ChainEventHandler struct {
+	chainEvents *services.ChainEventService
+}
+
+// NewChainEventHandler creates a new chain event handler
+func NewChainEventHandler(chainEvents *services.ChainEventService) *ChainEventHandler {
+	return &ChainEventHandler{
+		chainEvents: chainEvents,
+	}
+}
+
+// StreamEvents streams the chain events of the DIDs the caller may resolve as
+// server-sent events until the client disconnects
+func (h *ChainEventHandler) StreamEvents(c web.Context) {
+	events, unsubscribe := h.chainEvents.Subscribe(callerFromContext(c))
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(chainEventsKeepAlive)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request().Context().Done():
+			return false
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+		case <-ticker.C:
+			c.SSEvent("keep-alive", web.H{"time": time.Now().UTC()})
+		}
+		return true
+	})
+}
+
+// RegisterRoutes registers the chain event stream
+func (h *ChainEventHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/did/chain-events", h.StreamEvents)
+	}
+}
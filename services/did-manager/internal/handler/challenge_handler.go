@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"did-manager/pkg/challenge"
+	"did-manager/pkg/web"
+)
+
+// ChallengeHandler tells clients which anti-automation challenge public creation
+// endpoints require, handing out challenges the service issues itself
+type ChallengeHandler struct {
+	verifier challenge.Verifier
+}
+
+// NewChallengeHandler creates a new challenge handler
+func NewChallengeHandler(verifier challenge.Verifier) *ChallengeHandler {
+	return &ChallengeHandler{verifier: verifier}
+}
+
+// GetChallenge names the challenge provider and, for proof of work, returns a new
+// challenge to solve. CAPTCHA tokens come from the provider's widget instead.
+func (h *ChallengeHandler) GetChallenge(c web.Context) {
+	data := web.H{"provider": h.verifier.Name()}
+
+	if issuer, ok := h.verifier.(challenge.Issuer); ok {
+		issued, err := issuer.Issue()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to issue challenge",
+				"details": err.Error(),
+			})
+			return
+		}
+		data["challenge"] = issued
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// RegisterRoutes registers the challenge route
+func (h *ChallengeHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/challenge", h.GetChallenge)
+}
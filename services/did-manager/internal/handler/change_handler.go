@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// ChangeHandler handles HTTP requests for the DID change feed
+type ChangeHandler struct {
+	changes *services.ChangeService
+	guard   web.HandlerFunc
+}
+
+// NewChangeHandler creates a new change handler; guard is applied to the feed since it
+// resolves DIDs
+func NewChangeHandler(changes *services.ChangeService, guard web.HandlerFunc) *ChangeHandler {
+	return &ChangeHandler{
+		changes: changes,
+		guard:   guard,
+	}
+}
+
+// ListChanges returns the DID changes after the since cursor
+func (h *ChangeHandler) ListChanges(c web.Context) {
+	var req domain.DIDChangesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	page, err := h.changes.Changes(&req, callerFromContext(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error": "Invalid cursor",
+			})
+		case errors.Is(err, domain.ErrCursorExpired):
+			c.JSON(http.StatusGone, web.H{
+				"error":   "Cursor expired",
+				"details": "Changes after the cursor are no longer retained; resync from a full export",
+			})
+		case errors.Is(err, domain.ErrChangeFeedUnavailable):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Change feed unavailable",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to list DID changes",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// RegisterRoutes registers the change feed route
+func (h *ChangeHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/did/changes", h.guard, h.ListChanges)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// ClaimHandler handles HTTP requests for the claims verified for DIDs
+type ClaimHandler struct {
+	claims *services.ClaimService
+	guard  web.HandlerFunc
+}
+
+// NewClaimHandler creates a new claim handler; guard is applied to claim lookups and
+// checks
+func NewClaimHandler(claims *services.ClaimService, guard web.HandlerFunc) *ClaimHandler {
+	return &ClaimHandler{
+		claims: claims,
+		guard:  guard,
+	}
+}
+
+// AddClaim adds a claim the calling tenant verified to a DID
+func (h *ClaimHandler) AddClaim(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeAPIKey {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Claims are added by an API key authenticated verifier",
+		})
+		return
+	}
+
+	var req domain.ClaimAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	req.VerifiedBy = caller.ID
+
+	response, err := h.claims.AddClaim(&req, caller)
+	if err != nil {
+		h.fail(c, err, "Failed to add claim")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListClaims lists the claim types a DID has verified
+func (h *ClaimHandler) ListClaims(c web.Context) {
+	claims, err := h.claims.ListClaims(c.Param("did"), callerFromContext(c))
+	if err != nil {
+		h.fail(c, err, "Failed to list claims")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    claims,
+	})
+}
+
+// VerifyClaim checks a claim value against a DID's latest commitment of its type
+func (h *ClaimHandler) VerifyClaim(c web.Context) {
+	var req domain.ClaimVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.claims.VerifyClaim(&req, callerFromContext(c))
+	if err != nil {
+		h.fail(c, err, "Failed to verify claim")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// fail answers a failed claim request
+func (h *ClaimHandler) fail(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidClaimType):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid claim type",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrDIDNotFound):
+		markResolutionMiss(c)
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrClaimNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Claim not found",
+		})
+	case errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "DID is deactivated",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Claim credential cannot be issued",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the claim routes
+func (h *ClaimHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/did/claims", h.AddClaim)
+		api.POST("/did/claims/verify", h.guard, h.VerifyClaim)
+		api.GET("/did/claims/:did", h.guard, h.ListClaims)
+	}
+}
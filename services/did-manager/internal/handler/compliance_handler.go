@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// ComplianceHandler handles administrative requests for signed compliance reports
+type ComplianceHandler struct {
+	compliance *services.ComplianceService
+	adminKey   string
+}
+
+// NewComplianceHandler creates a new compliance handler
+func NewComplianceHandler(compliance *services.ComplianceService, adminKey string) *ComplianceHandler {
+	return &ComplianceHandler{
+		compliance: compliance,
+		adminKey:   adminKey,
+	}
+}
+
+// GenerateReport exports the signed compliance report of a period. The response is the
+// report document itself rather than the usual envelope: the signed JSON, or a PDF
+// whose signature is returned in the X-Report-Signature header.
+func (h *ComplianceHandler) GenerateReport(c web.Context) {
+	var req domain.ComplianceReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	report, signed, err := h.compliance.Generate(req.From, req.To)
+	if err != nil {
+		if errors.Is(err, domain.ErrReportSigningDisabled) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Compliance reports are disabled",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to generate compliance report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("compliance-report-%s-%s", report.From.Format("20060102"), report.To.Format("20060102"))
+	if req.Format == "pdf" {
+		document, signature := h.compliance.RenderPDF(report, signed)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+		c.Header("X-Report-Signature", signature.Value)
+		c.Header("X-Report-Key-ID", signature.KeyID)
+		c.Data(http.StatusOK, "application/pdf", document)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+	c.JSON(http.StatusOK, signed)
+}
+
+// VerifyReport checks the signature of an exported JSON report
+func (h *ComplianceHandler) VerifyReport(c web.Context) {
+	var signed domain.SignedComplianceReport
+	if err := c.ShouldBindJSON(&signed); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.compliance.Verify(&signed); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSignature):
+			c.JSON(http.StatusOK, web.H{
+				"success": true,
+				"data": web.H{
+					"valid":   false,
+					"message": err.Error(),
+				},
+			})
+		case errors.Is(err, domain.ErrReportSigningDisabled):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Compliance reports are disabled",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to verify compliance report",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data": web.H{
+			"valid": true,
+		},
+	})
+}
+
+// RegisterRoutes registers all compliance report routes
+func (h *ComplianceHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/reports/compliance", h.GenerateReport)
+		admin.POST("/reports/compliance/verify", h.VerifyReport)
+	}
+}
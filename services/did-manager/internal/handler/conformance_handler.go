@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/did"
+	"did-manager/pkg/web"
+)
+
+// ConformanceHandler serves the DIF Universal Resolver and Registrar interfaces and the
+// W3C DID test suite fixtures. It is only mounted in conformance mode.
+type ConformanceHandler struct {
+	conformance *services.ConformanceService
+	guard       web.HandlerFunc
+	challenge   web.HandlerFunc
+}
+
+// NewConformanceHandler creates a new conformance handler; guard is applied to the
+// resolution route and challenge to DID creation
+func NewConformanceHandler(conformance *services.ConformanceService, guard, challenge web.HandlerFunc) *ConformanceHandler {
+	return &ConformanceHandler{
+		conformance: conformance,
+		guard:       guard,
+		challenge:   challenge,
+	}
+}
+
+// resolutionResponse is a DID resolution result as served by the resolver driver
+type resolutionResponse struct {
+	Context string `json:"@context"`
+	*domain.DIDResolutionResult
+}
+
+// ResolveIdentifier resolves a DID as a Universal Resolver driver. The Accept header
+// selects the full resolution result, the default, or a DID Document representation.
+func (h *ConformanceHandler) ResolveIdentifier(c web.Context) {
+	contentType, ok := negotiateRepresentation(c.GetHeader("Accept"))
+	if !ok {
+		c.JSON(http.StatusNotAcceptable, resolutionResponse{
+			Context:             domain.DIDResolutionContext,
+			DIDResolutionResult: &domain.DIDResolutionResult{ResolutionMetadata: domain.DIDResolutionMetadata{Error: domain.ResolutionErrorRepresentationNotSupported}},
+		})
+		return
+	}
+
+	result := h.conformance.Resolve(c.Param("did"), callerFromContext(c))
+	status := http.StatusOK
+	switch {
+	case result.ResolutionMetadata.Error == domain.ResolutionErrorInvalidDID:
+		status = http.StatusBadRequest
+	case result.ResolutionMetadata.Error == domain.ResolutionErrorNotFound:
+		markResolutionMiss(c)
+		status = http.StatusNotFound
+	case result.ResolutionMetadata.Error != "":
+		status = http.StatusInternalServerError
+	case result.DocumentMetadata.Deactivated:
+		status = http.StatusGone
+	}
+
+	if contentType == domain.ContentTypeDIDResolution || result.Document == nil {
+		body, err := json.Marshal(resolutionResponse{Context: domain.DIDResolutionContext, DIDResolutionResult: result})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to encode resolution result",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.Data(status, domain.ContentTypeDIDResolution, body)
+		return
+	}
+
+	representation, err := services.Represent(result.Document, contentType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to represent DID Document",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.Data(status, contentType, representation)
+}
+
+// negotiateRepresentation picks the first media type of an Accept header that can be
+// served. Anything JSON, or no preference, gets the full resolution result.
+func negotiateRepresentation(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return domain.ContentTypeDIDResolution, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case domain.ContentTypeDIDLDJSON, domain.ContentTypeDIDJSON:
+			return mediaType, true
+		case "application/ld+json", "application/json", "application/*", "*/*":
+			return domain.ContentTypeDIDResolution, true
+		}
+	}
+	return "", false
+}
+
+// CreateDID creates an anonymous DID of the method query parameter as a Universal
+// Registrar driver
+func (h *ConformanceHandler) CreateDID(c web.Context) {
+	var req domain.RegistrarCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, registrarFailure("Invalid request data: "+err.Error()))
+		return
+	}
+
+	method := c.Query("method")
+	if method == "" {
+		method = string(domain.DIDMethodExample)
+	}
+
+	result, err := h.conformance.Register(method, &req, callerFromContext(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, did.ErrSuiteDisabled),
+			errors.Is(err, domain.ErrUnsupportedDIDMethod),
+			errors.Is(err, domain.ErrInvalidDIDRequest):
+			status = http.StatusBadRequest
+		case errors.Is(err, domain.ErrDIDMethodNotAllowed),
+			errors.Is(err, domain.ErrRejectedByHook),
+			errors.Is(err, domain.ErrAnonymousDIDsDisabled):
+			status = http.StatusForbidden
+		case errors.Is(err, domain.ErrQuotaExceeded):
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, registrarFailure(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// DeactivateDID deactivates the calling DID as a Universal Registrar driver
+func (h *ConformanceHandler) DeactivateDID(c web.Context) {
+	var req domain.DIDDeactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, registrarFailure("Invalid request data: "+err.Error()))
+		return
+	}
+
+	result, err := h.conformance.Deactivate(req.DID, callerFromContext(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrDIDDeactivated):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrForbidden):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, registrarFailure(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// registrarFailure is a registrar result for a failed operation
+func registrarFailure(reason string) *domain.RegistrarResult {
+	return &domain.RegistrarResult{
+		DIDState: domain.RegistrarDIDState{
+			State:  domain.RegistrarStateFailed,
+			Reason: reason,
+		},
+		DIDRegistrationMetadata: map[string]any{},
+	}
+}
+
+// DIDCoreFixture returns the W3C DID Core test suite implementation file for the method
+// query parameter
+func (h *ConformanceHandler) DIDCoreFixture(c web.Context) {
+	h.respondFixture(c, h.conformance.DIDCoreFixture)
+}
+
+// ResolutionFixture returns the W3C DID Resolution test suite implementation file for
+// the method query parameter
+func (h *ConformanceHandler) ResolutionFixture(c web.Context) {
+	h.respondFixture(c, h.conformance.ResolutionFixture)
+}
+
+// respondFixture writes a fixture unwrapped, so it can be saved into a test suite as is
+func (h *ConformanceHandler) respondFixture(c web.Context, build func(method string) (map[string]any, error)) {
+	method := c.Query("method")
+	if method == "" {
+		method = string(domain.DIDMethodExample)
+	}
+
+	fixture, err := build(method)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to build fixture",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, fixture)
+}
+
+// RegisterRoutes registers the driver and fixture routes
+func (h *ConformanceHandler) RegisterRoutes(router web.Router) {
+	driver := router.Group("/1.0")
+	{
+		driver.GET("/identifiers/:did", h.guard, h.ResolveIdentifier)
+		driver.POST("/create", h.challenge, h.CreateDID)
+		driver.POST("/deactivate", h.DeactivateDID)
+	}
+
+	api := router.Group("/api/v1/conformance")
+	{
+		api.GET("/did-core", h.DIDCoreFixture)
+		api.GET("/did-resolution", h.ResolutionFixture)
+	}
+}
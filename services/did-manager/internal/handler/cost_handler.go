@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// CostHandler handles cost reports and budgets, and the sub-keys tenants issue with
+// budgets of their own
+type CostHandler struct {
+	costs    *services.CostService
+	access   *services.AccessService
+	adminKey string
+}
+
+// NewCostHandler creates a new cost handler
+func NewCostHandler(costs *services.CostService, access *services.AccessService, adminKey string) *CostHandler {
+	return &CostHandler{
+		costs:    costs,
+		access:   access,
+		adminKey: adminKey,
+	}
+}
+
+// tenantKey returns the ID of the calling tenant API key, responding with an error
+// for other callers; sub-keys cannot manage keys or budgets
+func tenantKey(c web.Context) (uuid.UUID, bool) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeAPIKey {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "An API key is required",
+		})
+		return uuid.Nil, false
+	}
+	if caller.KeyID != "" {
+		c.JSON(http.StatusForbidden, web.H{
+			"error": "Sub-keys cannot manage API keys or budgets",
+		})
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(caller.ID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "An API key is required",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// CreateSubKey issues a sub-key of the calling tenant, with an optional budget
+func (h *CostHandler) CreateSubKey(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SubKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.access.CreateSubKey(tenant, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to create sub-key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.MonthlyUnits != nil {
+		if _, err := h.costs.SetBudget(response.APIKey.ID, *req.MonthlyUnits); err != nil {
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Sub-key created, but failed to set its budget",
+				"details": err.Error(),
+				"data":    response,
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListSubKeys lists the sub-keys of the calling tenant
+func (h *CostHandler) ListSubKeys(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.access.ListSubKeys(tenant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list sub-keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// RevokeSubKey revokes a sub-key of the calling tenant
+func (h *CostHandler) RevokeSubKey(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+	id, ok := parseKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.access.RevokeSubKey(tenant, id); err != nil {
+		respondCostError(c, err, "Failed to revoke sub-key")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Sub-key revoked",
+	})
+}
+
+// SetSubKeyBudget sets the monthly budget of a sub-key of the calling tenant
+func (h *CostHandler) SetSubKeyBudget(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+	id, ok := parseKeyID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.access.SubKey(tenant, id); err != nil {
+		respondCostError(c, err, "Failed to set budget")
+		return
+	}
+	h.setBudget(c, id)
+}
+
+// DeleteSubKeyBudget removes the budget of a sub-key of the calling tenant
+func (h *CostHandler) DeleteSubKeyBudget(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+	id, ok := parseKeyID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.access.SubKey(tenant, id); err != nil {
+		respondCostError(c, err, "Failed to remove budget")
+		return
+	}
+	h.deleteBudget(c, id)
+}
+
+// MonthToDate summarizes the calling tenant's consumption this month, per key
+func (h *CostHandler) MonthToDate(c web.Context) {
+	tenant, ok := tenantKey(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.costs.MonthToDate(tenant.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to summarize costs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// SetBudget sets the monthly budget of any API key
+func (h *CostHandler) SetBudget(c web.Context) {
+	id, ok := parseKeyID(c)
+	if !ok {
+		return
+	}
+	h.setBudget(c, id)
+}
+
+// DeleteBudget removes the budget of any API key
+func (h *CostHandler) DeleteBudget(c web.Context) {
+	id, ok := parseKeyID(c)
+	if !ok {
+		return
+	}
+	h.deleteBudget(c, id)
+}
+
+// Report summarizes the consumption of API keys over a period
+func (h *CostHandler) Report(c web.Context) {
+	var req domain.CostReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	report, err := h.costs.Report(req.From, req.To, req.APIKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to summarize costs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+func (h *CostHandler) setBudget(c web.Context, id uuid.UUID) {
+	var req domain.CostBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	budget, err := h.costs.SetBudget(id, *req.MonthlyUnits)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to set budget",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    budget,
+	})
+}
+
+func (h *CostHandler) deleteBudget(c web.Context, id uuid.UUID) {
+	if err := h.costs.DeleteBudget(id); err != nil {
+		respondCostError(c, err, "Failed to remove budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Budget removed",
+	})
+}
+
+// parseKeyID parses the :id path parameter as an API key ID
+func parseKeyID(c web.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondCostError maps API key and budget errors to responses
+func respondCostError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Sub-key not found",
+		})
+	case errors.Is(err, domain.ErrCostBudgetNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Budget not found",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the tenant sub-key and cost routes and the admin budget routes
+func (h *CostHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/api-keys", h.CreateSubKey)
+		api.GET("/api-keys", h.ListSubKeys)
+		api.DELETE("/api-keys/:id", h.RevokeSubKey)
+		api.PUT("/api-keys/:id/budget", h.SetSubKeyBudget)
+		api.DELETE("/api-keys/:id/budget", h.DeleteSubKeyBudget)
+		api.GET("/costs", h.MonthToDate)
+	}
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.PUT("/api-keys/:id/budget", h.SetBudget)
+		admin.DELETE("/api-keys/:id/budget", h.DeleteBudget)
+		admin.GET("/costs", h.Report)
+	}
+}
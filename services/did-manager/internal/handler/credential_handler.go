@@ -0,0 +1,443 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// CredentialHandler handles HTTP requests for credential issuance and verification
+type CredentialHandler struct {
+	credentials *services.CredentialService
+	propagation *services.RevocationPropagationService
+}
+
+// NewCredentialHandler creates a new credential handler
+func NewCredentialHandler(credentials *services.CredentialService, propagation *services.RevocationPropagationService) *CredentialHandler {
+	return &CredentialHandler{
+		credentials: credentials,
+		propagation: propagation,
+	}
+}
+
+// IssueCredential issues a credential signed by the calling DID
+func (h *CredentialHandler) IssueCredential(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Credentials must be issued by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.CredentialIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credential, err := h.credentials.IssueCredential(caller.ID, &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrCredentialNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Delegation credential not found",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Attached document not found",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidDocument) {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid attachments",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrDocumentVaultDisabled) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Document vault is not enabled",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "Issuer cannot issue credentials",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Usage quota exceeded",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrRejectedByHook) {
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "Credential issuance rejected",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to issue credential",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    credential,
+	})
+}
+
+// VerifyCredential verifies a credential's proof and status
+func (h *CredentialHandler) VerifyCredential(c web.Context) {
+	var req domain.CredentialVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.credentials.VerifyCredential(req.Credential)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to verify credential",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.Valid {
+		h.propagation.RecordVerification(callerFromContext(c), domain.VerifiedSubjectCredential, result.CredentialID)
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SearchCredentials searches the credentials issued by the calling DID, e.g.
+// ?type=EmployeeCredential&tenant=acme&status=active&expires_before=2026-11-01T00:00:00Z
+func (h *CredentialHandler) SearchCredentials(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Credentials can only be searched by their DID-authenticated issuer",
+		})
+		return
+	}
+
+	var req domain.CredentialSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.credentials.SearchCredentials(caller.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to search credentials",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// RevokeCredential revokes a credential issued by the calling DID
+func (h *CredentialHandler) RevokeCredential(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Credentials must be revoked by their DID-authenticated issuer",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid credential ID format",
+		})
+		return
+	}
+
+	credential, err := h.credentials.RevokeCredential(caller.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrCredentialNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Credential not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke credential",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    credential,
+	})
+}
+
+// IssueDelegation delegates capabilities from the calling DID to another DID
+func (h *CredentialHandler) IssueDelegation(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Delegations must be issued by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.DelegationIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	delegation, err := h.credentials.IssueDelegation(caller.ID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCredentialNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Parent delegation not found",
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "Delegation not allowed",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to issue delegation",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    delegation,
+	})
+}
+
+// VerifyDelegation verifies that a delegation chain authorizes a capability
+func (h *CredentialHandler) VerifyDelegation(c web.Context) {
+	var req domain.DelegationVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.credentials.VerifyDelegation(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to verify delegation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// IssueAgeCredential issues an AgeOver credential signed by the calling DID
+func (h *CredentialHandler) IssueAgeCredential(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Credentials must be issued by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.AgeCredentialIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credential, err := h.credentials.IssueAgeCredential(caller.ID, &req)
+	if err != nil {
+		respondAgeCredentialError(c, err, "Failed to issue age credential")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    credential,
+	})
+}
+
+// CreateAgeProof derives a proof that the calling DID is over an age from one of its
+// age credentials
+func (h *CredentialHandler) CreateAgeProof(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Age proofs must be created by the DID-authenticated holder",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid credential ID format",
+		})
+		return
+	}
+
+	var req domain.AgeProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	proof, err := h.credentials.CreateAgeProof(caller.ID, id, &req)
+	if err != nil {
+		respondAgeCredentialError(c, err, "Failed to create age proof")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    proof,
+	})
+}
+
+// VerifyAgeProof verifies a BBS or SD-JWT proof that a credential subject is over an age
+func (h *CredentialHandler) VerifyAgeProof(c web.Context) {
+	var req domain.AgeProofVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.credentials.VerifyAgeProof(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to verify age proof",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.Valid {
+		h.propagation.RecordVerification(callerFromContext(c), domain.VerifiedSubjectCredential, result.CredentialID)
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// respondAgeCredentialError maps age credential errors to responses
+func respondAgeCredentialError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrCredentialNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Credential not found",
+		})
+	case errors.Is(err, domain.ErrDIDNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrInvalidAgeCredential):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid age credential request",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrRejectedByHook):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Operation not allowed",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		c.JSON(http.StatusTooManyRequests, web.H{
+			"error":   "Usage quota exceeded",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all credential routes
+func (h *CredentialHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/credentials", h.IssueCredential)
+		api.POST("/credentials/verify", h.VerifyCredential)
+		api.GET("/credentials/search", h.SearchCredentials)
+		api.POST("/credentials/:id/revoke", h.RevokeCredential)
+		api.POST("/credentials/age", h.IssueAgeCredential)
+		api.POST("/credentials/age/verify", h.VerifyAgeProof)
+		api.POST("/credentials/:id/age-proof", h.CreateAgeProof)
+		api.POST("/delegations", h.IssueDelegation)
+		api.POST("/delegations/verify", h.VerifyDelegation)
+	}
+}
+
+// RegisterReadRoutes registers only the credential verification routes, for the
+// read-only verifier
+func (h *CredentialHandler) RegisterReadRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/credentials/verify", h.VerifyCredential)
+		api.POST("/credentials/age/verify", h.VerifyAgeProof)
+		api.POST("/delegations/verify", h.VerifyDelegation)
+	}
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// DeviceKeyHandler handles the device subkeys of users' DIDs. auth-service manages
+// them through the admin routes as devices sign in and out; wallet applications list
+// and revoke the token owner's keys through the wallet API.
+type DeviceKeyHandler struct {
+	deviceKeys *services.DeviceKeyService
+	verifier   *security.WalletTokenVerifier
+	adminKey   string
+}
+
+// NewDeviceKeyHandler creates a new device key handler
+func NewDeviceKeyHandler(deviceKeys *services.DeviceKeyService, verifier *security.WalletTokenVerifier, adminKey string) *DeviceKeyHandler {
+	return &DeviceKeyHandler{
+		deviceKeys: deviceKeys,
+		verifier:   verifier,
+		adminKey:   adminKey,
+	}
+}
+
+// Register lists a device's key on one of the user's DIDs
+func (h *DeviceKeyHandler) Register(c web.Context) {
+	userID, ok := deviceKeyUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.DeviceKeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := h.deviceKeys.Register(c.Request().Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrInvalidDeviceKey):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid device key",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Proof of possession failed",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrInvalidAttestation):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "App attestation failed",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrDeviceKeyLimit):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Device key cannot be added",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to register device key",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// List lists the device keys of the user's DIDs
+func (h *DeviceKeyHandler) List(c web.Context) {
+	userID, ok := deviceKeyUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.deviceKeys.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list device keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// Revoke removes one of the user's device keys from its DID
+func (h *DeviceKeyHandler) Revoke(c web.Context) {
+	userID, ok := deviceKeyUserID(c)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid device key ID format",
+		})
+		return
+	}
+
+	if err := h.deviceKeys.Revoke(userID, keyID); err != nil {
+		if errors.Is(err, domain.ErrDeviceKeyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Device key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke device key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Device key revoked",
+	})
+}
+
+// RevokeAll removes every device key of the user
+func (h *DeviceKeyHandler) RevokeAll(c web.Context) {
+	userID, ok := accountUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.deviceKeys.RevokeAll(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke device keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    web.H{"revoked": revoked},
+	})
+}
+
+// Verify reports whether one of the user's active device keys signed a message, so
+// auth-service can check the proof of possession of device-bound sessions
+func (h *DeviceKeyHandler) Verify(c web.Context) {
+	userID, ok := accountUserID(c)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid device key ID format",
+		})
+		return
+	}
+
+	var req domain.DeviceKeyVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	valid, err := h.deviceKeys.Verify(userID, keyID, &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceKeyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Device key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to verify device signature",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    web.H{"valid": valid},
+	})
+}
+
+// deviceKeyUserID returns the user whose device keys a request manages: the wallet
+// token owner, or the user of an admin route
+func deviceKeyUserID(c web.Context) (uuid.UUID, bool) {
+	if claims := walletClaimsFromContext(c); claims != nil {
+		return claims.UserID, true
+	}
+	return accountUserID(c)
+}
+
+// RegisterRoutes registers the admin and wallet device key routes
+func (h *DeviceKeyHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.POST("/users/:userID/device-keys", h.Register)
+		admin.GET("/users/:userID/device-keys", h.List)
+		admin.DELETE("/users/:userID/device-keys", h.RevokeAll)
+		admin.DELETE("/users/:userID/device-keys/:id", h.Revoke)
+		admin.POST("/users/:userID/device-keys/:id/verify", h.Verify)
+	}
+
+	wallet := router.Group("/api/v1/wallet", WalletAuth(h.verifier))
+	{
+		attested := RequireAttestedApp(h.deviceKeys, domain.WalletOperationDeviceKeys)
+		wallet.POST("/device-keys", RequireScope(security.ScopeDeviceKeysManage), attested, h.Register)
+		wallet.GET("/device-keys", RequireScope(security.ScopeDeviceKeysManage), attested, h.List)
+		wallet.DELETE("/device-keys/:id", RequireScope(security.ScopeDeviceKeysManage), attested, h.Revoke)
+	}
+}
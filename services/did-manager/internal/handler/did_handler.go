@@ -1,71 +1,205 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"did-manager/internal/domain"
 	"did-manager/internal/services"
+	"did-manager/pkg/challenge"
+	"did-manager/pkg/did"
+	"did-manager/pkg/web"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // DIDHandler handles HTTP requests for DID operations
 type DIDHandler struct {
 	didService *services.DIDService
+	access     *services.AccessService
+	locales    *services.LocaleService
+	guard      web.HandlerFunc
+	challenge  web.HandlerFunc
+	verifier   challenge.Verifier
 }
 
-// NewDIDHandler creates a new DID handler
-func NewDIDHandler(didService *services.DIDService) *DIDHandler {
+// NewDIDHandler creates a new DID handler; guard is applied to the lookup routes. The
+// challenges of verifier are required of anonymous callers creating DIDs and checking
+// claims; without a verifier, anonymous callers cannot check claims.
+func NewDIDHandler(didService *services.DIDService, access *services.AccessService, locales *services.LocaleService, guard web.HandlerFunc, verifier challenge.Verifier) *DIDHandler {
 	return &DIDHandler{
 		didService: didService,
+		access:     access,
+		locales:    locales,
+		guard:      guard,
+		challenge:  RequireChallenge(verifier),
+		verifier:   verifier,
 	}
 }
 
+// authorizeClaims requires anonymous callers checking claims to solve a challenge, so
+// the Argon2id hashing of claims cannot be run at will. It aborts the request and
+// reports false when the caller may not check claims.
+func (h *DIDHandler) authorizeClaims(c web.Context) bool {
+	if !callerFromContext(c).IsAnonymous() {
+		return true
+	}
+	if h.verifier == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+			"error": "Authentication required to verify claims",
+		})
+		return false
+	}
+	return checkChallenge(c, h.verifier)
+}
+
+// authorizeDID hides private DIDs from callers outside their access control list.
+// It writes the same not_found verification result the service returns for unknown DIDs
+// and reports false when the request must not proceed.
+func (h *DIDHandler) authorizeDID(c web.Context, did, userHash string) bool {
+	_, err := h.access.CheckDIDAccess(did, callerFromContext(c))
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		markResolutionMiss(c)
+		c.JSON(http.StatusOK, web.H{
+			"success": true,
+			"data": &domain.DIDVerificationResponse{
+				IsValid:   false,
+				DID:       did,
+				UserHash:  userHash,
+				Status:    "not_found",
+				Message:   "DID not found in local database: " + err.Error(),
+				Assurance: domain.AssuranceLocalDB,
+			},
+		})
+		return false
+	}
+
+	c.JSON(http.StatusInternalServerError, web.H{
+		"error":   "Failed to check DID access",
+		"details": err.Error(),
+	})
+	return false
+}
+
 // CreateDID handles DID creation requests
-func (h *DIDHandler) CreateDID(c *gin.Context) {
+func (h *DIDHandler) CreateDID(c web.Context) {
 	var req domain.DIDCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusBadRequest, web.H{
 			"error":   "Invalid request data",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	// Validate user ID
-	if req.UserID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	// Validate user ID; anonymous DIDs may omit it
+	if req.UserID == uuid.Nil && !req.Anonymous {
+		c.JSON(http.StatusBadRequest, web.H{
 			"error": "User ID is required",
 		})
 		return
 	}
 
+	// Feature flags and usage are resolved for the authenticated caller, whose sandbox
+	// holds the DID
+	caller := callerFromContext(c)
+	req.Tenant = caller.ID
+	req.SandboxTenant = caller.SandboxTenant
+
 	// Create DID
 	response, err := h.didService.CreateDID(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create DID",
-			"details": err.Error(),
-		})
+		respondCreateDIDError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	if response.ConsistencyToken != "" {
+		c.Header(domain.ConsistencyTokenHeader, response.ConsistencyToken)
+	}
+	c.JSON(http.StatusCreated, web.H{
 		"success": true,
 		"data":    response,
 	})
 }
 
+// respondCreateDIDError maps DID creation errors to responses
+func respondCreateDIDError(c web.Context, err error) {
+	if errors.Is(err, did.ErrSuiteDisabled) {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Key algorithm not enabled",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		c.JSON(http.StatusTooManyRequests, web.H{
+			"error":   "Usage quota exceeded",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrUnsupportedDIDMethod) {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "DID method not supported",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrDIDMethodNotAllowed) {
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "DID method not allowed",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrRejectedByHook) {
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "DID creation rejected",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrAnonymousDIDsDisabled) {
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Anonymous DIDs not enabled",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidDIDRequest) {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrDuplicateIdentity) {
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "Identity already registered",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, web.H{
+		"error":   "Failed to create DID",
+		"details": err.Error(),
+	})
+}
+
 // VerifyDID handles DID verification requests
-func (h *DIDHandler) VerifyDID(c *gin.Context) {
+func (h *DIDHandler) VerifyDID(c web.Context) {
 	log.Printf("DEBUG HANDLER: VerifyDID called")
 
 	var req domain.DIDVerificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("DEBUG HANDLER: JSON binding failed: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusBadRequest, web.H{
 			"error":   "Invalid request data",
 			"details": err.Error(),
 		})
@@ -74,11 +208,52 @@ func (h *DIDHandler) VerifyDID(c *gin.Context) {
 
 	log.Printf("DEBUG HANDLER: Request parsed: %+v", req)
 
+	if (req.Name != "" || req.Email != "") && !h.authorizeClaims(c) {
+		return
+	}
+	if !h.authorizeDID(c, req.DID, req.UserHash) {
+		return
+	}
+	caller := callerFromContext(c)
+	req.Tenant = caller.ID
+	req.TenantType = caller.Type
+	req.SandboxTenant = caller.SandboxTenant
+	req.Endpoint = domain.VerificationEndpointVerify
+
 	// Verify DID
 	response, err := h.didService.VerifyDID(&req)
 	if err != nil {
 		log.Printf("DEBUG HANDLER: Service call failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Usage quota exceeded",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrClaimsAttemptsExceeded) {
+			c.Header("Retry-After", strconv.Itoa(int(h.didService.ClaimsRetryAfter().Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Too many claims verification attempts",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrRejectedByHook) {
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID verification rejected",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrChainUnreachable) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain unreachable",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
 			"error":   "Failed to verify DID",
 			"details": err.Error(),
 		})
@@ -87,142 +262,351 @@ func (h *DIDHandler) VerifyDID(c *gin.Context) {
 
 	log.Printf("DEBUG HANDLER: Service response: %+v", response)
 
-	c.JSON(http.StatusOK, gin.H{
+	if response.Status == "not_found" {
+		markResolutionMiss(c)
+	}
+
+	c.JSON(http.StatusOK, web.H{
 		"success": true,
 		"data":    response,
 	})
 }
 
 // GetDIDByUserID retrieves a DID by user ID
-func (h *DIDHandler) GetDIDByUserID(c *gin.Context) {
+func (h *DIDHandler) GetDIDByUserID(c web.Context) {
 	userIDStr := c.Param("userID")
 	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	// Anonymous DIDs created without a user ID share the nil UUID
+	if err != nil || userID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, web.H{
 			"error": "Invalid user ID format",
 		})
 		return
 	}
 
-	did, err := h.didService.GetDIDByUserID(userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+	// Private DIDs the caller may not see are not found either
+	record, err := h.didService.GetDIDByUserID(userID)
+	if err == nil {
+		record, err = h.access.CheckDIDAccess(record.Did, callerFromContext(c))
+	}
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		markResolutionMiss(c)
+		c.JSON(http.StatusNotFound, web.H{
 			"error": "DID not found",
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get DID",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, web.H{
 		"success": true,
-		"data":    did,
+		"data":    domain.NewDIDSummary(record),
 	})
 }
 
+// DeactivateDID deactivates the calling DID. The request is signed with the DID's key,
+// which proves control; the deactivation marker is then anchored on-chain.
+func (h *DIDHandler) DeactivateDID(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "DIDs must be deactivated by the DID-authenticated DID itself",
+		})
+		return
+	}
+
+	var req domain.DIDDeactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.didService.DeactivateDID(req.DID, caller.ID)
+	if err != nil {
+		respondDeactivationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// respondDeactivationError maps DID deactivation errors to responses
+func respondDeactivationError(c web.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDIDNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "DID is already deactivated",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "DID cannot be deactivated",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to deactivate DID",
+			"details": err.Error(),
+		})
+	}
+}
+
 // GetDIDStatus retrieves the status of a DID
-func (h *DIDHandler) GetDIDStatus(c *gin.Context) {
+func (h *DIDHandler) GetDIDStatus(c web.Context) {
 	did := c.Param("did")
 	if did == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusBadRequest, web.H{
 			"error": "DID parameter is required",
 		})
 		return
 	}
 
+	if !h.authorizeDID(c, did, "") {
+		return
+	}
+
 	// For status check, we'll create a minimal verification request
+	caller := callerFromContext(c)
 	req := &domain.DIDVerificationRequest{
-		DID:      did,
-		UserHash: "", // Empty hash for status check only
+		DID:           did,
+		UserHash:      "", // Empty hash for status check only
+		Tenant:        caller.ID,
+		TenantType:    caller.Type,
+		SandboxTenant: caller.SandboxTenant,
+		Endpoint:      domain.VerificationEndpointStatus,
 	}
 
 	response, err := h.didService.VerifyDID(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Usage quota exceeded",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrRejectedByHook) {
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID verification rejected",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrChainUnreachable) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain unreachable",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
 			"error":   "Failed to get DID status",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	if response.Status == "not_found" {
+		markResolutionMiss(c)
+	}
+
+	status := domain.NewDIDStatusResponse(response)
+	locale := h.locales.Resolve(c.GetHeader("Accept-Language"), caller)
+	status.StatusDescription = h.locales.DIDStatusDescription(locale, status.Status)
+	c.Header("Content-Language", locale)
+
+	c.JSON(http.StatusOK, web.H{
 		"success": true,
-		"data": gin.H{
-			"did":      response.DID,
-			"status":   response.Status,
-			"is_valid": response.IsValid,
-			"message":  response.Message,
-		},
+		"data":    status,
 	})
 }
 
-// ProcessQueue manually triggers blockchain queue processing
-func (h *DIDHandler) ProcessQueue(c *gin.Context) {
-	// This endpoint is for manual queue processing (useful for testing)
-	if err := h.didService.ProcessBlockchainQueue(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to process queue",
+// GetSidetreeReceipts lists the receipts of a DID's operations anchored in Sidetree
+// batches
+func (h *DIDHandler) GetSidetreeReceipts(c web.Context) {
+	did := c.Param("did")
+
+	if _, err := h.access.CheckDIDAccess(did, callerFromContext(c)); err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "DID not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to check DID access",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	receipts, err := h.didService.ListSidetreeReceipts(did)
+	if err != nil {
+		if errors.Is(err, domain.ErrSidetreeDisabled) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "Sidetree batching is not enabled",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list Sidetree receipts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
 		"success": true,
-		"message": "Queue processing completed",
+		"data":    receipts,
 	})
 }
 
-// HealthCheck provides a health check endpoint
-func (h *DIDHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "did-manager",
-		"version": "1.0.0",
-	})
-}
+// GetAnchorProof returns the proof of a DID's anchoring, whichever backend anchored it
+func (h *DIDHandler) GetAnchorProof(c web.Context) {
+	did := c.Param("did")
 
-// TestDBDirect directly tests the database to prove DIDs exist
-func (h *DIDHandler) TestDBDirect(c *gin.Context) {
-	// This is a temporary debug endpoint
-	didParam := c.Query("did")
-	if didParam == "" {
-		didParam = "did:example:user:94b97f078270a88c:a8ef117c9787f5c32b9afffb223de27c"
+	if _, err := h.access.CheckDIDAccess(did, callerFromContext(c)); err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "DID not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to check DID access",
+			"details": err.Error(),
+		})
+		return
 	}
 
-	// Try direct repository call
-	result, err := h.didService.GetDIDRepo().GetByDID(didParam)
+	proof, err := h.didService.GetAnchorProof(did)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "error",
-			"did":     didParam,
-			"error":   err.Error(),
-			"message": "Direct repository call failed",
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "DID not found",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrDIDNotAnchored):
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "DID is not anchored yet",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrBlockchainUnavailable):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain is unavailable",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to get anchoring proof",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    proof,
+	})
+}
+
+// ProcessQueue manually triggers blockchain queue processing
+func (h *DIDHandler) ProcessQueue(c web.Context) {
+	// This endpoint is for manual queue processing (useful for testing)
+	if err := h.didService.ProcessBlockchainQueue(); err != nil {
+		if errors.Is(err, domain.ErrBlockchainUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain is unavailable",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrAnchoringPaused) {
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Anchoring is paused",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to process queue",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "found",
-		"did":     didParam,
-		"result":  result,
-		"message": "Direct repository call succeeded",
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Queue processing completed",
+	})
+}
+
+// HealthCheck provides a health check endpoint
+func (h *DIDHandler) HealthCheck(c web.Context) {
+	anchoring := "active"
+	if h.didService.AnchoringPaused() {
+		anchoring = "paused"
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"status":    "healthy",
+		"service":   "did-manager",
+		"version":   "1.0.0",
+		"anchoring": anchoring,
 	})
 }
 
 // RegisterRoutes registers all DID routes
-func (h *DIDHandler) RegisterRoutes(router *gin.Engine) {
+func (h *DIDHandler) RegisterRoutes(router web.Router) {
 	api := router.Group("/api/v1")
 	{
 		// DID operations
-		api.POST("/did", h.CreateDID)
-		api.POST("/did/verify", h.VerifyDID)
-		api.GET("/did/user/:userID", h.GetDIDByUserID)
-		api.GET("/did/status/:did", h.GetDIDStatus)
+		api.POST("/did", h.challenge, h.CreateDID)
+		api.POST("/did/deactivate", h.DeactivateDID)
+		api.POST("/did/verify", h.guard, h.VerifyDID)
+		api.GET("/did/user/:userID", h.guard, h.GetDIDByUserID)
+		api.GET("/did/status/:did", h.guard, h.GetDIDStatus)
+		api.GET("/did/receipts/:did", h.guard, h.GetSidetreeReceipts)
+		api.GET("/did/anchor/:did", h.guard, h.GetAnchorProof)
 
 		// Queue management
 		api.POST("/queue/process", h.ProcessQueue)
 
 		// Health check
 		api.GET("/health", h.HealthCheck)
-		api.GET("/test/db", h.TestDBDirect)
+	}
+}
+
+// RegisterReadRoutes registers only the DID routes that do not write, for the
+// read-only verifier
+func (h *DIDHandler) RegisterReadRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/did/verify", h.guard, h.VerifyDID)
+		api.GET("/did/status/:did", h.guard, h.GetDIDStatus)
+		api.GET("/did/receipts/:did", h.guard, h.GetSidetreeReceipts)
+		api.GET("/did/anchor/:did", h.guard, h.GetAnchorProof)
+		api.GET("/health", h.HealthCheck)
 	}
 }
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/challenge"
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/stdweb"
+)
+
+// fakeVerifier accepts the challenge response "solved"
+type fakeVerifier struct{}
+
+func (fakeVerifier) Name() string {
+	return "fake"
+}
+
+func (fakeVerifier) Verify(ctx context.Context, response, remoteIP string) error {
+	switch response {
+	case "":
+		return challenge.ErrMissing
+	case "solved":
+		return nil
+	}
+	return fmt.Errorf("%w: %s", challenge.ErrFailed, response)
+}
+
+func TestVerifyDIDClaimsRequireChallenge(t *testing.T) {
+	pass := func(c web.Context) { c.Next() }
+	tests := []struct {
+		name     string
+		verifier challenge.Verifier
+		response string
+		want     int
+	}{
+		{"without verifier", nil, "", http.StatusUnauthorized},
+		{"without response", fakeVerifier{}, "", http.StatusForbidden},
+		{"with wrong response", fakeVerifier{}, "guessed", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		router := stdweb.New()
+		web.Mount(router, NewDIDHandler(nil, nil, nil, pass, tt.verifier))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/did/verify", strings.NewReader(`{"did":"did:example:1","name":"John Doe","email":"john@example.com"}`))
+		req.Header.Set("X-Challenge-Response", tt.response)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+// fakeDIDs serves fixed DID records
+type fakeDIDs struct {
+	domain.DIDRepository
+	records []*domain.DID
+}
+
+func (f *fakeDIDs) GetByDID(did string) (*domain.DID, error) {
+	for _, record := range f.records {
+		if record.Did == did {
+			return record, nil
+		}
+	}
+	return nil, domain.ErrDIDNotFound
+}
+
+func (f *fakeDIDs) GetByUserID(userID uuid.UUID) (*domain.DID, error) {
+	for _, record := range f.records {
+		if record.UserID == userID {
+			return record, nil
+		}
+	}
+	return nil, domain.ErrDIDNotFound
+}
+
+func TestGetDIDByUserIDChecksAccess(t *testing.T) {
+	public := &domain.DID{ID: uuid.New(), UserID: uuid.New(), Did: "did:example:public", PublicKey: "secret-key", Custodial: true}
+	private := &domain.DID{ID: uuid.New(), UserID: uuid.New(), Did: "did:example:private", Visibility: string(domain.DIDVisibilityPrivate)}
+	dids := &fakeDIDs{records: []*domain.DID{public, private}}
+	didService := services.NewDIDService(dids, nil, nil, nil, nil, nil, nil)
+	access := services.NewAccessService(nil, nil, dids, nil)
+
+	router := stdweb.New()
+	web.Mount(router, NewDIDHandler(didService, access, nil, func(c web.Context) { c.Next() }, nil))
+	get := func(userID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/did/user/"+userID.String(), nil))
+		return w
+	}
+
+	w := get(public.UserID)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), public.Did) {
+		t.Fatalf("expected the public DID, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), public.PublicKey) || strings.Contains(w.Body.String(), "public_key") {
+		t.Errorf("expected no key material, got %s", w.Body.String())
+	}
+
+	if w := get(private.UserID); w.Code != http.StatusNotFound {
+		t.Errorf("expected the private DID not found by anonymous callers, got %d: %s", w.Code, w.Body.String())
+	}
+}
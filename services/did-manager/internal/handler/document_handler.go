@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// DocumentHandler handles HTTP requests for the document vault
+type DocumentHandler struct {
+	documents *services.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documents *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		documents: documents,
+	}
+}
+
+// UploadDocument stores the request body as a document of the calling DID, named by
+// the name query parameter and typed by the Content-Type header
+func (h *DocumentHandler) UploadDocument(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Documents must be uploaded by a DID-authenticated caller",
+		})
+		return
+	}
+
+	content, err := io.ReadAll(io.LimitReader(c.Request().Body, h.documents.MaxSize()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Failed to read document",
+			"details": err.Error(),
+		})
+		return
+	}
+	if int64(len(content)) > h.documents.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, web.H{
+			"error": fmt.Sprintf("Documents are limited to %d bytes", h.documents.MaxSize()),
+		})
+		return
+	}
+
+	document, err := h.documents.Upload(caller, c.Query("name"), c.GetHeader("Content-Type"), content)
+	if err != nil {
+		respondDocumentError(c, err, "Failed to upload document")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    document,
+	})
+}
+
+// ListDocuments lists the documents uploaded by the calling DID
+func (h *DocumentHandler) ListDocuments(c web.Context) {
+	documents, err := h.documents.ListDocuments(callerFromContext(c))
+	if err != nil {
+		respondDocumentError(c, err, "Failed to list documents")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    documents,
+	})
+}
+
+// GetDocument returns the metadata of a document the calling DID may access
+func (h *DocumentHandler) GetDocument(c web.Context) {
+	id, ok := documentID(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.documents.GetDocument(callerFromContext(c), id)
+	if err != nil {
+		respondDocumentError(c, err, "Failed to get document")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    document,
+	})
+}
+
+// CreateURL issues an expiring download URL for a document the calling DID may access
+func (h *DocumentHandler) CreateURL(c web.Context) {
+	id, ok := documentID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.DocumentURLRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	documentURL, err := h.documents.CreateURL(callerFromContext(c), id, &req)
+	if err != nil {
+		respondDocumentError(c, err, "Failed to create download URL")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    documentURL,
+	})
+}
+
+// DownloadDocument serves a document for a signed download URL. The URL itself
+// grants access, so no caller credentials are needed.
+func (h *DocumentHandler) DownloadDocument(c web.Context) {
+	id, ok := documentID(c)
+	if !ok {
+		return
+	}
+
+	document, content, err := h.documents.Download(id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		respondDocumentError(c, err, "Failed to download document")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, document.Name))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, document.ContentType, content)
+}
+
+// DeleteDocument removes one of the calling DID's documents
+func (h *DocumentHandler) DeleteDocument(c web.Context) {
+	id, ok := documentID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.documents.DeleteDocument(callerFromContext(c), id); err != nil {
+		respondDocumentError(c, err, "Failed to delete document")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Document deleted",
+	})
+}
+
+// documentID parses the document ID path parameter, answering 400 when malformed
+func documentID(c web.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondDocumentError maps document service errors to responses
+func respondDocumentError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Documents are accessed by a DID-authenticated caller",
+		})
+	case errors.Is(err, domain.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Document not found",
+		})
+	case errors.Is(err, domain.ErrDocumentURLInvalid):
+		c.JSON(http.StatusForbidden, web.H{
+			"error": "Download URL is invalid or has expired",
+		})
+	case errors.Is(err, domain.ErrInvalidDocument):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid document",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrDocumentAttached):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "Document is attached to a credential",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrDocumentVaultDisabled):
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error":   "Document vault is not enabled",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all document routes
+func (h *DocumentHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/documents", h.UploadDocument)
+		api.GET("/documents", h.ListDocuments)
+		api.GET("/documents/:id", h.GetDocument)
+		api.DELETE("/documents/:id", h.DeleteDocument)
+		api.POST("/documents/:id/url", h.CreateURL)
+		api.GET("/documents/:id/content", h.DownloadDocument)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// EmailLookupHandler handles lookups of DIDs by their holder's email address
+type EmailLookupHandler struct {
+	lookups *services.EmailLookupService
+	guard   web.HandlerFunc
+}
+
+// NewEmailLookupHandler creates a new email lookup handler; guard is applied to the
+// lookup since it probes the directory
+func NewEmailLookupHandler(lookups *services.EmailLookupService, guard web.HandlerFunc) *EmailLookupHandler {
+	return &EmailLookupHandler{
+		lookups: lookups,
+		guard:   guard,
+	}
+}
+
+// Lookup reports whether a DID exists for an email address. The address is taken from
+// the body rather than the URL so it stays out of access logs.
+func (h *EmailLookupHandler) Lookup(c web.Context) {
+	var req domain.EmailLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.lookups.Lookup(callerFromContext(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "Email lookups require an API key",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrEmailLookupDisabled):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Email lookup is not enabled",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to look up email",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	// Misses count towards enumeration like unknown DIDs
+	if !response.Exists {
+		markResolutionMiss(c)
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// RegisterRoutes registers the email lookup routes
+func (h *EmailLookupHandler) RegisterRoutes(router web.Router) {
+	router.POST("/api/v1/did/lookup/email", h.guard, h.Lookup)
+}
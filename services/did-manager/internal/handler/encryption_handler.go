@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// EncryptionHandler handles requests of internal services to encrypt payloads to DIDs
+type EncryptionHandler struct {
+	encryption *services.EncryptionService
+}
+
+// NewEncryptionHandler creates a new encryption handler
+func NewEncryptionHandler(encryption *services.EncryptionService) *EncryptionHandler {
+	return &EncryptionHandler{encryption: encryption}
+}
+
+// EncryptToDID encrypts a JSON payload to the keyAgreement key of a DID
+func (h *EncryptionHandler) EncryptToDID(c web.Context) {
+	var req domain.EncryptToDIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	encrypted, err := h.encryption.EncryptToDID(callerFromContext(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "Encrypting to DIDs requires an API key",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrDIDDeactivated):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "DID is deactivated",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrNoKeyAgreementKey):
+			c.JSON(http.StatusUnprocessableEntity, web.H{
+				"error":   "DID has no key agreement key",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to encrypt payload",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    encrypted,
+	})
+}
+
+// RegisterRoutes registers the encryption routes
+func (h *EncryptionHandler) RegisterRoutes(router web.Router) {
+	router.POST("/api/v1/encrypt-to-did", h.EncryptToDID)
+}
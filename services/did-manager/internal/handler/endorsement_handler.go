@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// EndorsementHandler handles HTTP requests for endorsements between DIDs
+type EndorsementHandler struct {
+	endorsements *services.EndorsementService
+}
+
+// NewEndorsementHandler creates a new endorsement handler
+func NewEndorsementHandler(endorsements *services.EndorsementService) *EndorsementHandler {
+	return &EndorsementHandler{
+		endorsements: endorsements,
+	}
+}
+
+// Endorse publishes an endorsement signed by the calling DID
+func (h *EndorsementHandler) Endorse(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Endorsements must be published by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.EndorsementCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	endorsement, err := h.endorsements.Endorse(caller.ID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid endorsement signature",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrEndorsementExists):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Endorsement already exists",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID cannot publish this endorsement",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to publish endorsement",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    endorsement,
+	})
+}
+
+// ListEndorsements lists endorsements made by or about a DID
+func (h *EndorsementHandler) ListEndorsements(c web.Context) {
+	var filter domain.EndorsementFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	if filter.EndorserDID == "" && filter.SubjectDID == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "endorser or subject query parameter is required",
+		})
+		return
+	}
+
+	endorsements, err := h.endorsements.ListEndorsements(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list endorsements",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endorsements,
+	})
+}
+
+// GetEndorsement returns an endorsement
+func (h *EndorsementHandler) GetEndorsement(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid endorsement ID format",
+		})
+		return
+	}
+
+	endorsement, err := h.endorsements.GetEndorsement(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrEndorsementNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Endorsement not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get endorsement",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endorsement,
+	})
+}
+
+// RevokeEndorsement withdraws an endorsement made by the calling DID
+func (h *EndorsementHandler) RevokeEndorsement(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Endorsements must be revoked by their DID-authenticated endorser",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid endorsement ID format",
+		})
+		return
+	}
+
+	endorsement, err := h.endorsements.RevokeEndorsement(caller.ID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrEndorsementNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Endorsement not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to revoke endorsement",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endorsement,
+	})
+}
+
+// GetGraph returns the endorsement graph around a DID; depth defaults to 1 and is
+// capped at services.MaxEndorsementGraphDepth
+func (h *EndorsementHandler) GetGraph(c web.Context) {
+	root := c.Query("did")
+	if root == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "did query parameter is required",
+		})
+		return
+	}
+
+	depth := 1
+	if raw := c.Query("depth"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error": "Invalid depth",
+			})
+			return
+		}
+		depth = parsed
+	}
+
+	graph, err := h.endorsements.Graph(root, depth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to load endorsement graph",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    graph,
+	})
+}
+
+// RegisterRoutes registers all endorsement routes
+func (h *EndorsementHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/endorsements", h.Endorse)
+		api.GET("/endorsements", h.ListEndorsements)
+		api.GET("/endorsements/graph", h.GetGraph)
+		api.GET("/endorsements/:id", h.GetEndorsement)
+		api.POST("/endorsements/:id/revoke", h.RevokeEndorsement)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// ExportHandler handles administrative exports of users' data, which the auth service
+// serves to users making data-subject access requests
+type ExportHandler struct {
+	exports  *services.ExportService
+	adminKey string
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exports *services.ExportService, adminKey string) *ExportHandler {
+	return &ExportHandler{
+		exports:  exports,
+		adminKey: adminKey,
+	}
+}
+
+// ExportUser exports a user's DIDs with their documents, credentials, access grants
+// and audit trail
+func (h *ExportHandler) ExportUser(c web.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	// Anonymous DIDs created without a user ID share the nil UUID
+	if err != nil || userID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid user ID format",
+		})
+		return
+	}
+
+	export, err := h.exports.ExportUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to export user data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s-export.json"`, userID))
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+// RegisterRoutes registers all export routes
+func (h *ExportHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/users/:userID/export", h.ExportUser)
+	}
+}
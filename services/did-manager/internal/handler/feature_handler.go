@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/did"
+	"did-manager/pkg/web"
+)
+
+// FeatureHandler handles HTTP requests for feature flags and service capabilities
+type FeatureHandler struct {
+	features     *services.FeatureService
+	methods      *services.MethodService
+	capabilities *services.CapabilityService
+	registry     *did.Registry
+	version      string
+	adminKey     string
+}
+
+// NewFeatureHandler creates a new feature handler
+func NewFeatureHandler(features *services.FeatureService, methods *services.MethodService, capabilities *services.CapabilityService, registry *did.Registry, version, adminKey string) *FeatureHandler {
+	return &FeatureHandler{
+		features:     features,
+		methods:      methods,
+		capabilities: capabilities,
+		registry:     registry,
+		version:      version,
+		adminKey:     adminKey,
+	}
+}
+
+// Capabilities describes the service version, its algorithms, the DID methods it
+// creates and resolves, the feature flags in effect for the caller, and whether the
+// blockchain, the job queue, conformance mode and smart accounts are available, with
+// the reason for each one that is offline
+func (h *FeatureHandler) Capabilities(c web.Context) {
+	tenant := callerFromContext(c).ID
+	hashSchemes, signatureSuites := h.registry.Algorithms()
+
+	methods, err := h.methods.Capabilities(tenant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get DID method capabilities",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var experimental []string
+	for _, suite := range signatureSuites {
+		if h.registry.IsExperimental(suite) {
+			experimental = append(experimental, suite)
+		}
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data": web.H{
+			"version": h.version,
+			"hash_schemes": web.H{
+				"default":   h.registry.DefaultHashScheme().ID(),
+				"supported": hashSchemes,
+			},
+			"signature_suites": web.H{
+				"default":      h.registry.DefaultSignatureSuite().ID(),
+				"supported":    signatureSuites,
+				"experimental": experimental,
+			},
+			"did_methods":  methods,
+			"features":     h.features.States(tenant),
+			"capabilities": h.capabilities.List(),
+		},
+	})
+}
+
+// ListFeatures lists the global state of every flag with the stored overrides
+func (h *FeatureHandler) ListFeatures(c web.Context) {
+	overrides, err := h.features.Overrides()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list feature overrides",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data": web.H{
+			"features":  h.features.States(c.Query("tenant")),
+			"overrides": overrides,
+		},
+	})
+}
+
+// SetOverride overrides a flag globally or for a tenant
+func (h *FeatureHandler) SetOverride(c web.Context) {
+	var req domain.FeatureOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	override, err := h.features.SetOverride(domain.FeatureFlag(c.Param("flag")), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownFeature) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Unknown feature flag",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to save feature override",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    override,
+	})
+}
+
+// DeleteOverride removes a global override, or a tenant's with the tenant query parameter
+func (h *FeatureHandler) DeleteOverride(c web.Context) {
+	err := h.features.DeleteOverride(domain.FeatureFlag(c.Param("flag")), c.Query("tenant"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownFeature):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Unknown feature flag",
+			})
+		case errors.Is(err, domain.ErrFeatureOverrideNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Feature override not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to delete feature override",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Feature override removed",
+	})
+}
+
+// RegisterRoutes registers the capabilities route and the admin feature flag routes
+func (h *FeatureHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/capabilities", h.Capabilities)
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/features", h.ListFeatures)
+		admin.PUT("/features/:flag", h.SetOverride)
+		admin.DELETE("/features/:flag", h.DeleteOverride)
+	}
+}
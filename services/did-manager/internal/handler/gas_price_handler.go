@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/web"
+)
+
+// GasPriceHandler handles HTTP requests for the gas price blockchain jobs are sent with
+type GasPriceHandler struct {
+	fees     *blockchain.FeeOracle
+	adminKey string
+}
+
+// NewGasPriceHandler creates a new gas price handler
+func NewGasPriceHandler(fees *blockchain.FeeOracle, adminKey string) *GasPriceHandler {
+	return &GasPriceHandler{
+		fees:     fees,
+		adminKey: adminKey,
+	}
+}
+
+// GetGasPrice reports the current gas price and where it comes from
+func (h *GasPriceHandler) GetGasPrice(c web.Context) {
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    h.status(),
+	})
+}
+
+// SetOverride pins the gas price until the override is cleared
+func (h *GasPriceHandler) SetOverride(c web.Context) {
+	var req domain.GasPriceOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	price, ok := new(big.Int).SetString(req.GasPrice, 10)
+	if !ok {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": "gas_price must be a decimal number of wei",
+		})
+		return
+	}
+	if err := h.fees.SetOverride(price); err != nil {
+		if errors.Is(err, blockchain.ErrInvalidGasPrice) {
+			c.JSON(http.StatusUnprocessableEntity, web.H{
+				"error":   "Invalid gas price",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to set gas price",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    h.status(),
+	})
+}
+
+// ClearOverride goes back to the node's suggested gas price
+func (h *GasPriceHandler) ClearOverride(c web.Context) {
+	h.fees.SetOverride(nil)
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    h.status(),
+	})
+}
+
+func (h *GasPriceHandler) status() *domain.GasPriceStatus {
+	status := h.fees.Status()
+	return &domain.GasPriceStatus{
+		GasPrice:    formatWei(status.GasPrice),
+		Suggested:   formatWei(status.Suggested),
+		Override:    formatWei(status.Override),
+		MaxGasPrice: formatWei(status.Max),
+		RefreshedAt: status.UpdatedAt,
+	}
+}
+
+// formatWei formats an amount of wei, empty when unset
+func formatWei(amount *big.Int) string {
+	if amount == nil {
+		return ""
+	}
+	return amount.String()
+}
+
+// RegisterRoutes registers the admin gas price routes
+func (h *GasPriceHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/gas-price", h.GetGasPrice)
+		admin.PUT("/gas-price/override", h.SetOverride)
+		admin.DELETE("/gas-price/override", h.ClearOverride)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// IdentifierMigrationHandler handles administrative queries and runs of the migration
+// of legacy DID identifiers
+type IdentifierMigrationHandler struct {
+	identifiers *services.IdentifierService
+	adminKey    string
+}
+
+// NewIdentifierMigrationHandler creates a new identifier migration handler
+func NewIdentifierMigrationHandler(identifiers *services.IdentifierService, adminKey string) *IdentifierMigrationHandler {
+	return &IdentifierMigrationHandler{
+		identifiers: identifiers,
+		adminKey:    adminKey,
+	}
+}
+
+// ListMigrations lists identifier migrations page by page, filtered by status and DID
+func (h *IdentifierMigrationHandler) ListMigrations(c web.Context) {
+	var query domain.IdentifierMigrationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.identifiers.ListMigrations(&query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list identifier migrations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetMigration returns the identifier migration of a DID, named by its legacy or new
+// identifier
+func (h *IdentifierMigrationHandler) GetMigration(c web.Context) {
+	migration, err := h.identifiers.GetMigration(c.Param("did"))
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) || errors.Is(err, domain.ErrIdentifierMigrationNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Identifier migration not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get identifier migration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    migration,
+	})
+}
+
+// RunMigration migrates and anchors legacy identifiers outside the schedule
+func (h *IdentifierMigrationHandler) RunMigration(c web.Context) {
+	run, err := h.identifiers.Migrate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to migrate identifiers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// RegisterRoutes registers the admin identifier migration routes
+func (h *IdentifierMigrationHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/identifier-migrations", h.ListMigrations)
+		admin.POST("/identifier-migrations", h.RunMigration)
+		admin.GET("/identifier-migrations/:did", h.GetMigration)
+	}
+}
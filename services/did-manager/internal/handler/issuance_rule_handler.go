@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// IssuanceRuleHandler handles administrative requests for the rules deciding which
+// credentials the issuers of each tenant may issue
+type IssuanceRuleHandler struct {
+	rules    *services.IssuanceRuleService
+	adminKey string
+}
+
+// NewIssuanceRuleHandler creates a new issuance rule handler
+func NewIssuanceRuleHandler(rules *services.IssuanceRuleService, adminKey string) *IssuanceRuleHandler {
+	return &IssuanceRuleHandler{
+		rules:    rules,
+		adminKey: adminKey,
+	}
+}
+
+// ListRules lists every issuance rule
+func (h *IssuanceRuleHandler) ListRules(c web.Context) {
+	rules, err := h.rules.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list issuance rules",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// AddRule adds an issuance rule
+func (h *IssuanceRuleHandler) AddRule(c web.Context) {
+	var req domain.IssuanceRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.rules.AddRule(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to add issuance rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DeleteRule removes an issuance rule
+func (h *IssuanceRuleHandler) DeleteRule(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid issuance rule ID format",
+		})
+		return
+	}
+
+	if err := h.rules.DeleteRule(id); err != nil {
+		if errors.Is(err, domain.ErrIssuanceRuleNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Issuance rule not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to delete issuance rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Issuance rule removed",
+	})
+}
+
+// RegisterRoutes registers the admin issuance rule routes
+func (h *IssuanceRuleHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/issuance-rules", h.ListRules)
+		admin.POST("/issuance-rules", h.AddRule)
+		admin.DELETE("/issuance-rules/:id", h.DeleteRule)
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// JobArchiveHandler handles administrative queries of the blockchain job archive
+type JobArchiveHandler struct {
+	archive  *services.JobArchiveService
+	adminKey string
+}
+
+// NewJobArchiveHandler creates a new job archive handler
+func NewJobArchiveHandler(archive *services.JobArchiveService, adminKey string) *JobArchiveHandler {
+	return &JobArchiveHandler{
+		archive:  archive,
+		adminKey: adminKey,
+	}
+}
+
+// ListArchivedJobs lists archived jobs page by page, filtered by DID, transaction
+// hash, type and processing time
+func (h *JobArchiveHandler) ListArchivedJobs(c web.Context) {
+	var query domain.JobArchiveQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.archive.ListArchivedJobs(&query)
+	if err != nil {
+		if respondInvalidFilter(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list archived jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetArchivedJob returns an archived job with its timeline
+func (h *JobArchiveHandler) GetArchivedJob(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid job ID format",
+		})
+		return
+	}
+
+	job, err := h.archive.GetArchivedJob(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrArchivedJobNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Archived job not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get archived job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// RunArchival archives the finished jobs past the retention outside the schedule
+func (h *JobArchiveHandler) RunArchival(c web.Context) {
+	run, err := h.archive.RunArchival()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to archive jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// RegisterRoutes registers the admin job archive routes
+func (h *JobArchiveHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/archive/jobs", h.ListArchivedJobs)
+		admin.POST("/archive/jobs", h.RunArchival)
+		admin.GET("/archive/jobs/:id", h.GetArchivedJob)
+	}
+}
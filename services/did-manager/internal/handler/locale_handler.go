@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// LocaleHandler lists the locales of end-user messages and sets tenants' default locales
+type LocaleHandler struct {
+	locales  *services.LocaleService
+	adminKey string
+}
+
+// NewLocaleHandler creates a new locale handler
+func NewLocaleHandler(locales *services.LocaleService, adminKey string) *LocaleHandler {
+	return &LocaleHandler{
+		locales:  locales,
+		adminKey: adminKey,
+	}
+}
+
+// ListLocales lists the supported locales and the server's default locale
+func (h *LocaleHandler) ListLocales(c web.Context) {
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data": web.H{
+			"locales":        h.locales.Locales(),
+			"default_locale": h.locales.DefaultLocale(),
+		},
+	})
+}
+
+// SetTenantLocale sets the default locale of a tenant's end users
+func (h *LocaleHandler) SetTenantLocale(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	var req domain.APIKeyLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.respondTenantLocale(c, id, req.Locale)
+}
+
+// ClearTenantLocale resets the default locale of a tenant's end users to the server's
+func (h *LocaleHandler) ClearTenantLocale(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid API key ID format",
+		})
+		return
+	}
+
+	h.respondTenantLocale(c, id, "")
+}
+
+// respondTenantLocale sets the default locale of a tenant key and writes the updated key
+func (h *LocaleHandler) respondTenantLocale(c web.Context, id uuid.UUID, locale string) {
+	key, err := h.locales.SetTenantLocale(id, locale)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnsupportedLocale):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Unsupported locale",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrAPIKeyNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Active tenant API key not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to set default locale",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// RegisterRoutes registers the locale list and the admin routes setting tenant locales
+func (h *LocaleHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/locales", h.ListLocales)
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.PUT("/api-keys/:id/locale", h.SetTenantLocale)
+		admin.DELETE("/api-keys/:id/locale", h.ClearTenantLocale)
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// MethodHandler handles administrative requests for per-tenant DID method policies
+type MethodHandler struct {
+	methods  *services.MethodService
+	adminKey string
+}
+
+// NewMethodHandler creates a new method handler
+func NewMethodHandler(methods *services.MethodService, adminKey string) *MethodHandler {
+	return &MethodHandler{
+		methods:  methods,
+		adminKey: adminKey,
+	}
+}
+
+// ListPolicies lists every tenant and default method policy
+func (h *MethodHandler) ListPolicies(c web.Context) {
+	policies, err := h.methods.ListPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list DID method policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// SetPolicy sets the methods a tenant may create, or the default policy without a tenant
+func (h *MethodHandler) SetPolicy(c web.Context) {
+	var req domain.DIDMethodPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.methods.SetPolicy(&req)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnsupportedDIDMethod) {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "DID method not supported",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to save DID method policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// DeletePolicy removes the policy of the tenant query parameter, or the default policy
+func (h *MethodHandler) DeletePolicy(c web.Context) {
+	if err := h.methods.DeletePolicy(c.Query("tenant")); err != nil {
+		if errors.Is(err, domain.ErrMethodPolicyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID method policy not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to delete DID method policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "DID method policy removed",
+	})
+}
+
+// RegisterRoutes registers the admin method policy routes
+func (h *MethodHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/did-methods", h.ListPolicies)
+		admin.PUT("/did-methods", h.SetPolicy)
+		admin.DELETE("/did-methods", h.DeletePolicy)
+	}
+}
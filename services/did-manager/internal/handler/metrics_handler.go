@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/siem"
+	"did-manager/pkg/web"
+)
+
+// MetricsHandler exposes the anchoring state, the DID funnel, SIEM delivery and shadow
+// anchoring in the Prometheus text format
+type MetricsHandler struct {
+	pause    *services.AnchoringPauseService
+	funnel   *services.FunnelService
+	shipper  *siem.Shipper           // nil when no SIEM is configured
+	shadow   *blockchain.ShadowChain // nil without a shadow anchor backend
+	adminKey string
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(pause *services.AnchoringPauseService, funnel *services.FunnelService, shipper *siem.Shipper, shadow *blockchain.ShadowChain, adminKey string) *MetricsHandler {
+	return &MetricsHandler{
+		pause:    pause,
+		funnel:   funnel,
+		shipper:  shipper,
+		shadow:   shadow,
+		adminKey: adminKey,
+	}
+}
+
+// Metrics writes every metric in the Prometheus text format
+func (h *MetricsHandler) Metrics(c web.Context) {
+	status, err := h.pause.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get anchoring status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	days, err := h.funnel.Recent()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get DID funnel",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	paused := 0
+	if status.Paused {
+		paused = 1
+	}
+	var metrics strings.Builder
+	fmt.Fprintln(&metrics, "# HELP did_manager_anchoring_paused Whether an operator paused anchoring.")
+	fmt.Fprintln(&metrics, "# TYPE did_manager_anchoring_paused gauge")
+	fmt.Fprintf(&metrics, "did_manager_anchoring_paused %d\n", paused)
+	fmt.Fprintln(&metrics, "# HELP did_manager_blockchain_jobs_pending Blockchain jobs not yet anchored.")
+	fmt.Fprintln(&metrics, "# TYPE did_manager_blockchain_jobs_pending gauge")
+	fmt.Fprintf(&metrics, "did_manager_blockchain_jobs_pending %d\n", status.PendingJobs)
+	fmt.Fprintln(&metrics, "# HELP did_manager_did_funnel DIDs a tenant created on a day (UTC) that reached a funnel stage.")
+	fmt.Fprintln(&metrics, "# TYPE did_manager_did_funnel gauge")
+	for _, day := range days {
+		for _, stage := range domain.FunnelStages {
+			fmt.Fprintf(&metrics, "did_manager_did_funnel{tenant=%q,day=%q,stage=%q} %d\n", day.Tenant, day.Day, stage, day.Count(stage))
+		}
+	}
+	if h.shipper != nil {
+		writeSIEMMetrics(&metrics, h.shipper.Stats())
+	}
+	if h.shadow != nil {
+		stats := h.shadow.Stats()
+		fmt.Fprintln(&metrics, "# HELP did_manager_anchoring_shadow_comparisons_total Calls replayed on the shadow anchor backend by method and result (match, diverged or skipped).")
+		fmt.Fprintln(&metrics, "# TYPE did_manager_anchoring_shadow_comparisons_total counter")
+		for _, comparison := range stats.Comparisons {
+			fmt.Fprintf(&metrics, "did_manager_anchoring_shadow_comparisons_total{method=%q,result=%q} %d\n", comparison.Method, comparison.Result, comparison.Count)
+		}
+		fmt.Fprintln(&metrics, "# HELP did_manager_anchoring_shadow_in_flight Calls running on the shadow anchor backend.")
+		fmt.Fprintln(&metrics, "# TYPE did_manager_anchoring_shadow_in_flight gauge")
+		fmt.Fprintf(&metrics, "did_manager_anchoring_shadow_in_flight %d\n", stats.InFlight)
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(metrics.String()))
+}
+
+// writeSIEMMetrics writes the delivery metrics of the SIEM shipper
+func writeSIEMMetrics(metrics *strings.Builder, stats *siem.Stats) {
+	fmt.Fprintln(metrics, "# HELP did_manager_siem_records_total Records a SIEM sink shipped or failed to ship.")
+	fmt.Fprintln(metrics, "# TYPE did_manager_siem_records_total counter")
+	for _, sink := range stats.Sinks {
+		fmt.Fprintf(metrics, "did_manager_siem_records_total{sink=%q,outcome=\"shipped\"} %d\n", sink.Sink, sink.Shipped)
+		fmt.Fprintf(metrics, "did_manager_siem_records_total{sink=%q,outcome=\"failed\"} %d\n", sink.Sink, sink.Failed)
+	}
+	fmt.Fprintln(metrics, "# HELP did_manager_siem_retries_total Batches a SIEM sink was retried with.")
+	fmt.Fprintln(metrics, "# TYPE did_manager_siem_retries_total counter")
+	for _, sink := range stats.Sinks {
+		fmt.Fprintf(metrics, "did_manager_siem_retries_total{sink=%q} %d\n", sink.Sink, sink.Retries)
+	}
+	fmt.Fprintln(metrics, "# HELP did_manager_siem_records_dropped_total Records dropped because the SIEM buffer was full.")
+	fmt.Fprintln(metrics, "# TYPE did_manager_siem_records_dropped_total counter")
+	fmt.Fprintf(metrics, "did_manager_siem_records_dropped_total %d\n", stats.Dropped)
+	fmt.Fprintln(metrics, "# HELP did_manager_siem_queue_depth Records waiting to be shipped to the SIEM.")
+	fmt.Fprintln(metrics, "# TYPE did_manager_siem_queue_depth gauge")
+	fmt.Fprintf(metrics, "did_manager_siem_queue_depth %d\n", stats.Queued)
+	fmt.Fprintln(metrics, "# HELP did_manager_siem_queue_capacity Records the SIEM buffer holds.")
+	fmt.Fprintln(metrics, "# TYPE did_manager_siem_queue_capacity gauge")
+	fmt.Fprintf(metrics, "did_manager_siem_queue_capacity %d\n", stats.Capacity)
+}
+
+// RegisterRoutes registers the metrics route, which needs the admin key since it
+// carries job counts and tenants
+func (h *MetricsHandler) RegisterRoutes(router web.Router) {
+	router.GET("/metrics", RequireAdmin(h.adminKey), h.Metrics)
+}
@@ -0,0 +1,656 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+	"did-manager/internal/services"
+	"did-manager/pkg/challenge"
+	"did-manager/pkg/did"
+	"did-manager/pkg/errreport"
+	"did-manager/pkg/siem"
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/apiversion"
+
+	"github.com/google/uuid"
+)
+
+// Request context keys shared between middleware and handlers
+const (
+	callerContextKey         = "caller"          // authenticated *domain.Caller
+	resolutionMissContextKey = "resolution_miss" // set when a lookup hit an unknown DID
+	walletClaimsContextKey   = "wallet_claims"   // verified *security.WalletClaims
+	requestIDContextKey      = "request_id"      // ID of the request set by RequestID
+)
+
+// RequestIDHeader carries the ID of a request, set by a proxy or generated by RequestID
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from the X-Request-ID header
+const maxRequestIDLength = 128
+
+// RequestID gives each request an ID, taken from the X-Request-ID header a proxy set or
+// generated, and echoes it in the X-Request-ID response header, so errors reported for
+// a request can be looked up by the ID its client saw
+func RequestID() web.HandlerFunc {
+	return func(c web.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID is printable ASCII of
+// reasonable length, so it is safe to log and report
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// routeOf returns the route of a request as written for API v1, so the route tables of
+// the middleware, such as scopes and costs, cover the routes later versions serve too
+func routeOf(c web.Context) string {
+	return apiversion.FromContext(c).Canonical(c.FullPath())
+}
+
+// requestIDFromContext returns the ID set by RequestID, or an empty string
+func requestIDFromContext(c web.Context) string {
+	if value, ok := c.Get(requestIDContextKey); ok {
+		if id, ok := value.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// Recover answers a panic in a handler or middleware after it with 500 Internal Server
+// Error, and reports it with its stack and the request's ID, route and tenant
+func Recover(reporter errreport.Reporter) web.HandlerFunc {
+	return func(c web.Context) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			// Aborted responses are the server's business, as with net/http
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			requestID := requestIDFromContext(c)
+			tags := map[string]string{
+				errreport.TagRoute:     c.Request().Method + " " + c.FullPath(),
+				errreport.TagRequestID: requestID,
+			}
+			if caller := callerFromContext(c); !caller.IsAnonymous() {
+				tags[errreport.TagTenantID] = caller.ID
+			}
+			reporter.Report(errreport.Panic(value, tags))
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+				"error":      "Internal server error",
+				"request_id": requestID,
+			})
+		}()
+		c.Next()
+	}
+}
+
+// ShipSecurityActivity ships the security-relevant requests to the SIEM once they are
+// answered: admin API calls and metrics scrapes, and requests refused as
+// unauthenticated, forbidden or rate limited
+func ShipSecurityActivity(shipper *siem.Shipper) web.HandlerFunc {
+	return func(c web.Context) {
+		c.Next()
+
+		status := c.Status()
+		route := c.FullPath()
+		admin := strings.HasPrefix(routeOf(c), "/api/v1/admin") || route == "/metrics"
+		denied := status == http.StatusUnauthorized || status == http.StatusForbidden
+		limited := status == http.StatusTooManyRequests
+		if !admin && !denied && !limited {
+			return
+		}
+
+		record := &siem.Record{
+			Category:   siem.CategorySecurity,
+			Action:     "http.request",
+			Outcome:    siem.OutcomeSuccess,
+			Severity:   siem.SeverityNotice,
+			SourceIP:   c.ClientIP(),
+			RequestID:  requestIDFromContext(c),
+			HTTPMethod: c.Request().Method,
+			HTTPRoute:  route,
+			HTTPStatus: status,
+			Subject:    c.Param("did"),
+		}
+		switch {
+		case denied:
+			record.Outcome, record.Severity = siem.OutcomeDenied, siem.SeverityWarning
+		case status >= http.StatusBadRequest:
+			record.Outcome, record.Severity = siem.OutcomeFailure, siem.SeverityWarning
+		}
+		caller := callerFromContext(c)
+		record.ActorType = string(caller.Type)
+		if !caller.IsAnonymous() {
+			record.ActorID, record.Tenant = caller.ID, caller.ID
+			if caller.KeyID != "" {
+				record.ActorID = caller.KeyID
+			}
+		}
+		shipper.Ship(record)
+	}
+}
+
+// Authenticate resolves the caller of each request from either an API key
+// (X-API-Key), a DID signature (X-Caller-DID, X-Caller-Timestamp, X-Caller-Signature)
+// or a service token of auth-service or UCAN (Authorization: Bearer). Devices signing with
+// their device key name its verification method in X-Caller-Key. Requests without
+// credentials continue as anonymous callers; other bearer tokens are left to WalletAuth.
+func Authenticate(access *services.AccessService) web.HandlerFunc {
+	return func(c web.Context) {
+		caller := domain.AnonymousCaller
+		var err error
+
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			caller, err = access.AuthenticateAPIKey(apiKey)
+		} else if callerDID := c.GetHeader("X-Caller-DID"); callerDID != "" {
+			caller, err = access.AuthenticateDID(
+				callerDID,
+				c.GetHeader("X-Caller-Key"),
+				c.GetHeader("X-Caller-Timestamp"),
+				c.GetHeader("X-Caller-Signature"),
+				c.Request().Method,
+				c.Request().URL.Path,
+			)
+		} else if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			switch {
+			case security.IsServiceToken(token):
+				caller, err = access.AuthenticateServiceToken(token)
+			case did.IsUCAN(token):
+				caller, err = access.AuthenticateUCAN(token)
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, domain.ErrUnauthenticated) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+					"error": "Invalid caller credentials",
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to authenticate caller",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.Set(callerContextKey, caller)
+		c.Next()
+	}
+}
+
+// callerFromContext returns the caller set by Authenticate, or an anonymous caller
+func callerFromContext(c web.Context) *domain.Caller {
+	if value, ok := c.Get(callerContextKey); ok {
+		if caller, ok := value.(*domain.Caller); ok {
+			return caller
+		}
+	}
+	return domain.AnonymousCaller
+}
+
+// EnforceScopes restricts callers authenticated with a service token or UCAN to the
+// operations their scopes grant, on the DID named by the route's :did parameter for
+// scopes granted on single DIDs. Routes outside the scope taxonomy are refused to them.
+func EnforceScopes() web.HandlerFunc {
+	return func(c web.Context) {
+		caller := callerFromContext(c)
+		if !caller.Scoped() {
+			c.Next()
+			return
+		}
+
+		scope := security.OperationScope(c.Request().Method, routeOf(c))
+		if scope == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+				"error": "Operation is not available to service tokens",
+			})
+			return
+		}
+		if !caller.AllowsOn(scope, c.Param("did")) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+				"error": "Insufficient scope",
+				"scope": scope,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin restricts a route group to requests carrying the admin key in X-Admin-Key.
+// Admin routes are disabled entirely when no admin key is configured. Service tokens
+// granted admin:jobs reach the job routes without the admin key.
+func RequireAdmin(adminKey string) web.HandlerFunc {
+	return func(c web.Context) {
+		if caller := callerFromContext(c); caller.Scoped() &&
+			security.OperationScope(c.Request().Method, routeOf(c)) == security.ScopeAdminJobs &&
+			caller.Allows(security.ScopeAdminJobs) {
+			c.Next()
+			return
+		}
+
+		if adminKey == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+				"error": "Admin API is disabled",
+			})
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+				"error": "Invalid admin key",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// callerKey identifies a caller for rate limiting: authenticated callers by identity,
+// anonymous callers by client IP, which only the router's trusted proxies may forward
+func callerKey(c web.Context) string {
+	caller := callerFromContext(c)
+	if caller.IsAnonymous() {
+		return "ip:" + c.ClientIP()
+	}
+	return string(caller.Type) + ":" + caller.ID
+}
+
+// markResolutionMiss records that the current request looked up an unknown DID
+func markResolutionMiss(c web.Context) {
+	c.Set(resolutionMissContextKey, true)
+}
+
+// ResolutionGuard rate limits DID lookups per caller and reports lookups of unknown
+// DIDs to the enumeration monitor. Sandbox callers are limited by sandboxLimiter
+// instead when it is set, typically with relaxed limits.
+func ResolutionGuard(limiter, sandboxLimiter *security.RateLimiter, monitor *security.EnumerationMonitor) web.HandlerFunc {
+	return func(c web.Context) {
+		key := callerKey(c)
+
+		limiter := limiter
+		if sandboxLimiter != nil && callerFromContext(c).SandboxTenant != "" {
+			limiter = sandboxLimiter
+		}
+		if !limiter.Allow(key) {
+			c.Header("Retry-After", strconv.Itoa(int(limiter.RetryAfter().Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, web.H{
+				"error": "Resolution rate limit exceeded",
+			})
+			return
+		}
+
+		c.Next()
+
+		if c.GetBool(resolutionMissContextKey) {
+			monitor.RecordMiss(key)
+		}
+	}
+}
+
+// MeterCost accounts for the cost of each request made with an API key. Requests that
+// would overrun a monthly budget are refused with 402, and chain writes beyond the
+// tenant's rate with 429, both carrying the quota in "quota". Only requests that
+// succeed are accounted for.
+func MeterCost(costs *services.CostService) web.HandlerFunc {
+	return func(c web.Context) {
+		caller := callerFromContext(c)
+		class := costs.Classify(c.Request().Method, routeOf(c))
+
+		quota, err := costs.Admit(caller, class)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrChainWriteThrottled):
+				c.Header("Retry-After", strconv.Itoa(quota.RetryAfter))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, web.H{
+					"error": "Chain write rate limit exceeded",
+					"quota": quota,
+				})
+			case errors.Is(err, domain.ErrBudgetExceeded):
+				c.AbortWithStatusJSON(http.StatusPaymentRequired, web.H{
+					"error":   "Cost budget exceeded",
+					"details": err.Error(),
+					"quota":   quota,
+				})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+					"error":   "Failed to check cost budget",
+					"details": err.Error(),
+				})
+			}
+			return
+		}
+
+		c.Next()
+
+		if c.Status() < http.StatusBadRequest {
+			costs.Record(caller, class)
+		}
+	}
+}
+
+// RequireChallenge requires anonymous callers to send the solution to an
+// anti-automation challenge in X-Challenge-Response, checked by verifier.
+// Authenticated callers are trusted, and nothing is required without a verifier.
+func RequireChallenge(verifier challenge.Verifier) web.HandlerFunc {
+	return func(c web.Context) {
+		if verifier == nil || !callerFromContext(c).IsAnonymous() {
+			c.Next()
+			return
+		}
+		if !checkChallenge(c, verifier) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// checkChallenge checks the challenge response of a request with verifier. It aborts
+// the request and reports false when the response is missing or does not verify.
+func checkChallenge(c web.Context, verifier challenge.Verifier) bool {
+	err := verifier.Verify(c.Request().Context(), c.GetHeader("X-Challenge-Response"), c.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	c.Header("X-Challenge-Provider", verifier.Name())
+	switch {
+	case errors.Is(err, challenge.ErrMissing):
+		c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+			"error":    "Challenge response required",
+			"provider": verifier.Name(),
+		})
+	case errors.Is(err, challenge.ErrFailed):
+		c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+			"error":    "Challenge verification failed",
+			"provider": verifier.Name(),
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, web.H{
+			"error": "Challenge verification is unavailable",
+		})
+	}
+	return false
+}
+
+// WalletAuth authenticates third-party wallet API requests with a bearer wallet access
+// token issued by auth-service. The wallet API is disabled when no secret is configured.
+func WalletAuth(verifier *security.WalletTokenVerifier) web.HandlerFunc {
+	return func(c web.Context) {
+		if !verifier.Enabled() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, web.H{
+				"error": "Wallet API is disabled",
+			})
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == "" || token == header {
+			c.Header("WWW-Authenticate", `Bearer realm="wallet"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+				"error": "Bearer token required",
+			})
+			return
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="wallet", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+				"error": "Invalid or expired wallet token",
+			})
+			return
+		}
+
+		c.Set(walletClaimsContextKey, claims)
+		c.Next()
+	}
+}
+
+// RequireScope rejects wallet API requests whose token does not grant scope
+func RequireScope(scope string) web.HandlerFunc {
+	return func(c web.Context) {
+		claims := walletClaimsFromContext(c)
+		if claims == nil || !claims.HasScope(scope) {
+			c.Header("WWW-Authenticate", `Bearer realm="wallet", error="insufficient_scope", scope="`+scope+`"`)
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+				"error": "Insufficient scope",
+				"scope": scope,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// walletClaimsFromContext returns the claims set by WalletAuth
+func walletClaimsFromContext(c web.Context) *security.WalletClaims {
+	if value, ok := c.Get(walletClaimsContextKey); ok {
+		if claims, ok := value.(*security.WalletClaims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// RequireStepUp rejects requests to a sensitive route unless they carry a step-up
+// proof satisfying the operation's policy: the challenge ID in X-Step-Up-Challenge
+// with either a hex signature over it in X-Step-Up-Signature or a base64url verifiable
+// presentation in X-Step-Up-Presentation. Routes whose operation has no policy pass.
+func RequireStepUp(stepUp *services.StepUpService, operation domain.StepUpOperation) web.HandlerFunc {
+	return func(c web.Context) {
+		policy := stepUp.Policy(operation)
+		if policy == nil {
+			c.Next()
+			return
+		}
+
+		proof, err := stepUpProof(c)
+		if err == nil {
+			err = stepUp.Verify(operation, stepUpSubject(c), proof)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrStepUpRequired):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+					"error":   "Step-up authentication required",
+					"details": err.Error(),
+					"step_up": policy,
+				})
+			case errors.Is(err, domain.ErrUnauthenticated):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{
+					"error": "Authentication required",
+				})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+					"error":   "Failed to verify step-up authentication",
+					"details": err.Error(),
+				})
+			}
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// stepUpProof reads the step-up proof headers, returning nil when there are none
+func stepUpProof(c web.Context) (*domain.StepUpProof, error) {
+	header := c.GetHeader("X-Step-Up-Challenge")
+	if header == "" {
+		return nil, nil
+	}
+
+	challengeID, err := uuid.Parse(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed challenge ID", domain.ErrStepUpRequired)
+	}
+	proof := &domain.StepUpProof{
+		ChallengeID: challengeID,
+		Signature:   c.GetHeader("X-Step-Up-Signature"),
+	}
+	if presentation := c.GetHeader("X-Step-Up-Presentation"); presentation != "" {
+		proof.Presentation, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(presentation, "="))
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed presentation", domain.ErrStepUpRequired)
+		}
+	}
+	return proof, nil
+}
+
+// stepUpSubject returns who a step-up proof must belong to: the user of a wallet
+// token, or else a DID-authenticated caller
+func stepUpSubject(c web.Context) domain.StepUpSubject {
+	if claims := walletClaimsFromContext(c); claims != nil {
+		return domain.StepUpSubject{UserID: claims.UserID}
+	}
+	if caller := callerFromContext(c); caller.Type == domain.CallerTypeDID {
+		return domain.StepUpSubject{DID: caller.ID}
+	}
+	return domain.StepUpSubject{}
+}
+
+// RequireAttestedApp restricts a high-risk wallet operation to attested app instances
+// when it is configured to be: besides the wallet token, the request must be signed as
+// one of the user's DIDs (X-Caller-DID) with a device key (X-Caller-Key) that was
+// registered with a platform attestation. Operations without the restriction pass.
+func RequireAttestedApp(deviceKeys *services.DeviceKeyService, operation domain.WalletOperation) web.HandlerFunc {
+	return func(c web.Context) {
+		if !deviceKeys.AttestationRequired(operation) {
+			c.Next()
+			return
+		}
+
+		claims := walletClaimsFromContext(c)
+		caller := callerFromContext(c)
+		if claims == nil || caller.Type != domain.CallerTypeDID {
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+				"error": "Attested app instance required",
+			})
+			return
+		}
+
+		if _, err := deviceKeys.RequireAttested(claims.UserID, caller.ID, c.GetHeader("X-Caller-Key")); err != nil {
+			if errors.Is(err, domain.ErrAttestationRequired) {
+				c.AbortWithStatusJSON(http.StatusForbidden, web.H{
+					"error":   "Attested app instance required",
+					"details": err.Error(),
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to check app attestation",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// standbyReadRoutes are POST routes that only read, served by a standby
+var standbyReadRoutes = map[string]bool{
+	"/api/v1/did/verify":                      true,
+	"/api/v1/credentials/verify":              true,
+	"/api/v1/delegations/verify":              true,
+	"/api/v1/encrypt-to-did":                  true,
+	"/api/v1/status-tokens":                   true,
+	"/api/v1/admin/reports/compliance/verify": true,
+	"/api/v1/admin/replication/promote":       true,
+}
+
+// RejectWritesOnStandby rejects mutating requests while the deployment is a read-only
+// standby, so nothing diverges from the primary being replicated
+func RejectWritesOnStandby(replication *services.ReplicationService) web.HandlerFunc {
+	return func(c web.Context) {
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if replication.ReadOnly() && !standbyReadRoutes[routeOf(c)] {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, web.H{
+				"error": "Deployment is a read-only standby",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ReadYourWrites makes requests carrying a consistency token in X-Consistency-Token see
+// the write it was issued for, bypassing the read cache for its DID and waiting for the
+// database to catch up. Requests the database cannot catch up with in time answer 503,
+// to be retried or routed to the primary; requests without a token are not delayed.
+func ReadYourWrites(consistency *services.ConsistencyService) web.HandlerFunc {
+	return func(c web.Context) {
+		token := c.GetHeader(domain.ConsistencyTokenHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		err := consistency.Await(c.Request().Context(), token)
+		switch {
+		case errors.Is(err, domain.ErrInvalidConsistencyToken):
+			c.AbortWithStatusJSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid consistency token",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrReplicaBehind):
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Database has not caught up with the write yet",
+				"details": err.Error(),
+			})
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to check read consistency",
+				"details": err.Error(),
+			})
+		default:
+			c.Next()
+		}
+	}
+}
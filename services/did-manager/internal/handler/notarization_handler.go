@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// NotarizationHandler handles HTTP requests for document hash notarization
+type NotarizationHandler struct {
+	notarizations *services.NotarizationService
+}
+
+// NewNotarizationHandler creates a new notarization handler
+func NewNotarizationHandler(notarizations *services.NotarizationService) *NotarizationHandler {
+	return &NotarizationHandler{
+		notarizations: notarizations,
+	}
+}
+
+// Notarize anchors a document hash signed by the calling DID
+func (h *NotarizationHandler) Notarize(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Documents must be notarized by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.NotarizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	notarization, err := h.notarizations.Notarize(caller.ID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid document signature",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID cannot notarize documents",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to notarize document",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, web.H{
+		"success": true,
+		"data":    notarization,
+	})
+}
+
+// GetNotarization returns a notarization proof
+func (h *NotarizationHandler) GetNotarization(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid notarization ID format",
+		})
+		return
+	}
+
+	notarization, err := h.notarizations.GetNotarization(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotarizationNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Notarization not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get notarization",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    notarization,
+	})
+}
+
+// ListNotarizations lists the notarizations of a document hash
+func (h *NotarizationHandler) ListNotarizations(c web.Context) {
+	documentHash := c.Query("document_hash")
+	if documentHash == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "document_hash is required",
+		})
+		return
+	}
+
+	notarizations, err := h.notarizations.ListByDocumentHash(documentHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list notarizations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    notarizations,
+	})
+}
+
+// RegisterRoutes registers all notarization routes
+func (h *NotarizationHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/notarize", h.Notarize)
+		api.GET("/notarizations", h.ListNotarizations)
+		api.GET("/notarizations/:id", h.GetNotarization)
+	}
+}
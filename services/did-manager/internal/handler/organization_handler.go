@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationHandler handles the pending-approvals API through which the controllers
+// of an organization DID propose and approve operations on it
+type OrganizationHandler struct {
+	organizations *services.OrganizationService
+	stepUp        *services.StepUpService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizations *services.OrganizationService, stepUp *services.StepUpService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizations: organizations,
+		stepUp:        stepUp,
+	}
+}
+
+// GetOrganization returns an organization DID's controllers and approval threshold
+func (h *OrganizationHandler) GetOrganization(c web.Context) {
+	organization, err := h.organizations.GetOrganization(c.Param("did"))
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to get organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    organization,
+	})
+}
+
+// ProposeOperation proposes an operation on an organization DID controlled by the caller
+func (h *OrganizationHandler) ProposeOperation(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Operations must be proposed by a DID-authenticated controller",
+		})
+		return
+	}
+
+	var req domain.OperationProposeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	operation, err := h.organizations.Propose(caller.ID, c.Param("did"), &req)
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to propose operation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    operation,
+	})
+}
+
+// ListOperations lists the operations on an organization DID; ?status=pending lists
+// those awaiting approval
+func (h *OrganizationHandler) ListOperations(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Operations are only listed to DID-authenticated controllers",
+		})
+		return
+	}
+
+	operations, err := h.organizations.ListOperations(caller.ID, c.Param("did"), c.Query("status"))
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to list operations")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    operations,
+	})
+}
+
+// GetOperation returns an operation and its approvals
+func (h *OrganizationHandler) GetOperation(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Operations are only shown to DID-authenticated controllers",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid operation ID format",
+		})
+		return
+	}
+
+	operation, err := h.organizations.GetOperation(caller.ID, id)
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to get operation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    operation,
+	})
+}
+
+// ApproveOperation records the calling controller's approval of an operation, running
+// it once the threshold is reached
+func (h *OrganizationHandler) ApproveOperation(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Operations must be approved by a DID-authenticated controller",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid operation ID format",
+		})
+		return
+	}
+
+	operation, err := h.organizations.Approve(caller.ID, id)
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to approve operation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    operation,
+	})
+}
+
+// respondOrganizationError maps organization service errors to responses
+func respondOrganizationError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrDIDNotFound), errors.Is(err, domain.ErrNotOrganization):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Organization DID not found",
+		})
+	case errors.Is(err, domain.ErrOperationNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Operation not found",
+		})
+	case errors.Is(err, domain.ErrOperationClosed):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "Operation is no longer pending",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrRejectedByHook):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Operation not allowed",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all organization routes
+func (h *OrganizationHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/organizations/:did", h.GetOrganization)
+		api.POST("/organizations/:did/operations", RequireStepUp(h.stepUp, domain.StepUpOrganizationOperation), h.ProposeOperation)
+		api.GET("/organizations/:did/operations", h.ListOperations)
+		api.GET("/operations/:id", h.GetOperation)
+		api.POST("/operations/:id/approve", RequireStepUp(h.stepUp, domain.StepUpOrganizationOperation), h.ApproveOperation)
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// ProfileHandler handles HTTP requests for the human-readable profiles of DIDs
+type ProfileHandler struct {
+	profiles *services.ProfileService
+	guard    web.HandlerFunc
+}
+
+// NewProfileHandler creates a new profile handler; guard is applied to public profile
+// lookups
+func NewProfileHandler(profiles *services.ProfileService, guard web.HandlerFunc) *ProfileHandler {
+	return &ProfileHandler{
+		profiles: profiles,
+		guard:    guard,
+	}
+}
+
+// GetPublicProfile returns the published fields of a DID's profile
+func (h *ProfileHandler) GetPublicProfile(c web.Context) {
+	profile, err := h.profiles.PublicProfile(c.Param("did"), callerFromContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			markResolutionMiss(c)
+		}
+		if errors.Is(err, domain.ErrDIDNotFound) || errors.Is(err, domain.ErrProfileNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Profile not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get profile",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// GetProfile returns the calling DID's full profile, including unpublished fields
+func (h *ProfileHandler) GetProfile(c web.Context) {
+	caller, ok := h.requireDID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.profiles.GetProfile(caller.ID)
+	if err != nil {
+		h.fail(c, err, "Failed to get profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// SetProfile replaces the calling DID's profile
+func (h *ProfileHandler) SetProfile(c web.Context) {
+	caller, ok := h.requireDID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.DIDProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	profile, err := h.profiles.SetProfile(caller.ID, &req)
+	if err != nil {
+		h.fail(c, err, "Failed to save profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// DeleteProfile removes the calling DID's profile
+func (h *ProfileHandler) DeleteProfile(c web.Context) {
+	caller, ok := h.requireDID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.profiles.DeleteProfile(caller.ID); err != nil {
+		h.fail(c, err, "Failed to delete profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Profile removed",
+	})
+}
+
+// requireDID returns the DID-authenticated caller, answering 401 for other callers
+func (h *ProfileHandler) requireDID(c web.Context) (*domain.Caller, bool) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Profiles are managed by a DID-authenticated caller",
+		})
+		return nil, false
+	}
+	return caller, true
+}
+
+// fail answers a failed profile operation of the calling DID
+func (h *ProfileHandler) fail(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrProfileNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Profile not found",
+		})
+	case errors.Is(err, domain.ErrDIDNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrCredentialNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Public credential not found",
+		})
+	case errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "DID is deactivated",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Profile cannot be changed",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the profile routes
+func (h *ProfileHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/did/profile/:did", h.guard, h.GetPublicProfile)
+		api.GET("/did/profile", h.GetProfile)
+		api.PUT("/did/profile", h.SetProfile)
+		api.DELETE("/did/profile", h.DeleteProfile)
+	}
+}
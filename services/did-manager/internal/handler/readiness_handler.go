@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"did-manager/pkg/lifecycle"
+	"did-manager/pkg/web"
+)
+
+// ReadinessHandler reports whether the service is ready to take traffic
+type ReadinessHandler struct {
+	lifecycle *lifecycle.Manager
+}
+
+// NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(manager *lifecycle.Manager) *ReadinessHandler {
+	return &ReadinessHandler{lifecycle: manager}
+}
+
+// Ready responds 200 once every component is running and 503 while the service is
+// starting or shutting down, listing the state of each component
+func (h *ReadinessHandler) Ready(c web.Context) {
+	status, code := "ready", http.StatusOK
+	if !h.lifecycle.Ready() {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, web.H{
+		"status":     status,
+		"service":    "did-manager",
+		"components": h.lifecycle.Statuses(),
+	})
+}
+
+// RegisterRoutes registers the readiness route next to the health check
+func (h *ReadinessHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/ready", h.Ready)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// RenewalHandler handles HTTP requests for issuers' credential renewal policies
+type RenewalHandler struct {
+	renewals *services.RenewalService
+}
+
+// NewRenewalHandler creates a new renewal handler
+func NewRenewalHandler(renewals *services.RenewalService) *RenewalHandler {
+	return &RenewalHandler{
+		renewals: renewals,
+	}
+}
+
+// ListPolicies lists the calling issuer's renewal policies
+func (h *RenewalHandler) ListPolicies(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Renewal policies are managed by a DID-authenticated issuer",
+		})
+		return
+	}
+
+	policies, err := h.renewals.ListPolicies(caller.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list renewal policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// SetPolicy configures expiry reminders and auto-renewal for the calling issuer's
+// credentials under a tenant
+func (h *RenewalHandler) SetPolicy(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Renewal policies are managed by a DID-authenticated issuer",
+		})
+		return
+	}
+
+	var req domain.RenewalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.renewals.SetPolicy(caller.ID, &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid webhook URL",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to save renewal policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// RegisterRoutes registers all renewal policy routes
+func (h *RenewalHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/renewal-policies", h.ListPolicies)
+		api.PUT("/renewal-policies", h.SetPolicy)
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// ReplicationHandler handles HTTP requests for the deployment's replication role
+type ReplicationHandler struct {
+	replication *services.ReplicationService
+	adminKey    string
+}
+
+// NewReplicationHandler creates a new replication handler
+func NewReplicationHandler(replication *services.ReplicationService, adminKey string) *ReplicationHandler {
+	return &ReplicationHandler{
+		replication: replication,
+		adminKey:    adminKey,
+	}
+}
+
+// GetStatus reports the deployment's role and replication subscription
+func (h *ReplicationHandler) GetStatus(c web.Context) {
+	status, err := h.replication.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get replication status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Promote fails over to this standby, making it the primary
+func (h *ReplicationHandler) Promote(c web.Context) {
+	status, err := h.replication.Promote()
+	if err != nil {
+		if errors.Is(err, domain.ErrNotStandby) {
+			c.JSON(http.StatusConflict, web.H{
+				"error": "Deployment is already the primary",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to promote deployment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// RegisterRoutes registers the admin replication routes
+func (h *ReplicationHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/replication", h.GetStatus)
+		admin.POST("/replication/promote", h.Promote)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/did"
+	"did-manager/pkg/web"
+)
+
+// ResolverHandler handles HTTP requests for DID resolution
+type ResolverHandler struct {
+	resolver *services.ResolverService
+	guard    web.HandlerFunc
+}
+
+// NewResolverHandler creates a new resolver handler; guard is applied to every lookup route
+func NewResolverHandler(resolver *services.ResolverService, guard web.HandlerFunc) *ResolverHandler {
+	return &ResolverHandler{
+		resolver: resolver,
+		guard:    guard,
+	}
+}
+
+// ResolveDID resolves a DID into its DID Document
+func (h *ResolverHandler) ResolveDID(c web.Context) {
+	didString := c.Param("did")
+	if didString == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "DID parameter is required",
+		})
+		return
+	}
+
+	// Keys are served in the configured representation unless key_format asks for
+	// another
+	var format did.KeyFormat
+	if value := c.Query("key_format"); value != "" {
+		var err error
+		if format, err = did.ParseKeyFormat(value); err != nil {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Invalid key format",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	result, err := h.resolver.ResolveWithKeyFormat(didString, callerFromContext(c), format)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			markResolutionMiss(c)
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to resolve DID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// RegisterRoutes registers all resolver routes
+func (h *ResolverHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/did/resolve/:did", h.guard, h.ResolveDID)
+	}
+}
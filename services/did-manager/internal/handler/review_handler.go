@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// ReviewHandler handles administrative requests for the manual review queue
+type ReviewHandler struct {
+	reviews  *services.ReviewService
+	adminKey string
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(reviews *services.ReviewService, adminKey string) *ReviewHandler {
+	return &ReviewHandler{
+		reviews:  reviews,
+		adminKey: adminKey,
+	}
+}
+
+// ListReviews lists operations held for review, pending ones by default
+func (h *ReviewHandler) ListReviews(c web.Context) {
+	var req domain.ReviewListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reviews, err := h.reviews.List(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list reviews",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    reviews,
+	})
+}
+
+// GetReview retrieves a review
+func (h *ReviewHandler) GetReview(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid review ID format",
+		})
+		return
+	}
+
+	review, err := h.reviews.Get(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrReviewNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Review not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to get review",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    review,
+	})
+}
+
+// ResolveReview approves or rejects a held operation
+func (h *ReviewHandler) ResolveReview(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid review ID format",
+		})
+		return
+	}
+
+	var req domain.ReviewResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	review, err := h.reviews.Resolve(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrReviewNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Review not found",
+			})
+		case errors.Is(err, domain.ErrReviewClosed):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Review is already resolved",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to resolve review",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    review,
+	})
+}
+
+// RegisterRoutes registers the admin review queue routes
+func (h *ReviewHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/reviews", h.ListReviews)
+		admin.GET("/reviews/:id", h.GetReview)
+		admin.POST("/reviews/:id/resolve", h.ResolveReview)
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// RevocationHandler serves the on-chain revocation accumulator: anchored roots and
+// (non-)revocation proofs verifiers can check against the RevocationRegistry contract
+type RevocationHandler struct {
+	revocation *services.RevocationService
+}
+
+// NewRevocationHandler creates a new revocation handler
+func NewRevocationHandler(revocation *services.RevocationService) *RevocationHandler {
+	return &RevocationHandler{
+		revocation: revocation,
+	}
+}
+
+// GetRoot returns the latest anchored accumulator root
+func (h *RevocationHandler) GetRoot(c web.Context) {
+	batch, err := h.revocation.LatestRoot()
+	if err != nil {
+		respondRevocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    batch,
+	})
+}
+
+// GetProof returns a credential's revocation proof against the latest anchored root
+func (h *RevocationHandler) GetProof(c web.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid credential ID format",
+		})
+		return
+	}
+
+	proof, err := h.revocation.Proof(id)
+	if err != nil {
+		respondRevocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    proof,
+	})
+}
+
+// respondRevocationError maps revocation errors to HTTP responses
+func respondRevocationError(c web.Context, err error) {
+	if errors.Is(err, domain.ErrNoAnchoredRevocationRoot) {
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error": "No revocation root has been anchored yet",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, web.H{
+		"error":   "Failed to load revocation state",
+		"details": err.Error(),
+	})
+}
+
+// RegisterRoutes registers all revocation routes
+func (h *RevocationHandler) RegisterRoutes(router web.Router) {
+	revocation := router.Group("/api/v1/revocation")
+	{
+		revocation.GET("/root", h.GetRoot)
+		revocation.GET("/proofs/:id", h.GetProof)
+	}
+}
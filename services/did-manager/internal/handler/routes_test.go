@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/apiversion"
+	"did-manager/pkg/web/ginweb"
+	"did-manager/pkg/web/stdweb"
+)
+
+// allHandlers returns every handler of the package, keyed by its constructor. Only the
+// routes are registered, so the handlers go without their services.
+func allHandlers() map[string]web.Routes {
+	return map[string]web.Routes{
+		"NewAccountHandler":             NewAccountHandler(nil, ""),
+		"NewAdminHandler":               NewAdminHandler(nil, nil, nil, nil, nil, ""),
+		"NewAnalyticsHandler":           NewAnalyticsHandler(nil, nil, ""),
+		"NewAnchoringPauseHandler":      NewAnchoringPauseHandler(nil, ""),
+		"NewAnchoringShadowHandler":     NewAnchoringShadowHandler(nil, ""),
+		"NewBackupHandler":              NewBackupHandler(nil, ""),
+		"NewBulkRevocationHandler":      NewBulkRevocationHandler(nil, ""),
+		"NewChainEventHandler":          NewChainEventHandler(nil),
+		"NewChallengeHandler":           NewChallengeHandler(nil),
+		"NewChangeHandler":              NewChangeHandler(nil, nil),
+		"NewClaimHandler":               NewClaimHandler(nil, nil),
+		"NewComplianceHandler":          NewComplianceHandler(nil, ""),
+		"NewConformanceHandler":         NewConformanceHandler(nil, nil, nil),
+		"NewCostHandler":                NewCostHandler(nil, nil, ""),
+		"NewCredentialHandler":          NewCredentialHandler(nil, nil),
+		"NewDeviceKeyHandler":           NewDeviceKeyHandler(nil, nil, ""),
+		"NewDIDHandler":                 NewDIDHandler(nil, nil, nil, nil, nil),
+		"NewDocumentHandler":            NewDocumentHandler(nil),
+		"NewEmailLookupHandler":         NewEmailLookupHandler(nil, nil),
+		"NewEncryptionHandler":          NewEncryptionHandler(nil),
+		"NewEndorsementHandler":         NewEndorsementHandler(nil),
+		"NewExportHandler":              NewExportHandler(nil, ""),
+		"NewFeatureHandler":             NewFeatureHandler(nil, nil, nil, nil, "", ""),
+		"NewGasPriceHandler":            NewGasPriceHandler(nil, ""),
+		"NewIdentifierMigrationHandler": NewIdentifierMigrationHandler(nil, ""),
+		"NewIssuanceRuleHandler":        NewIssuanceRuleHandler(nil, ""),
+		"NewJobArchiveHandler":          NewJobArchiveHandler(nil, ""),
+		"NewLocaleHandler":              NewLocaleHandler(nil, ""),
+		"NewMethodHandler":              NewMethodHandler(nil, ""),
+		"NewMetricsHandler":             NewMetricsHandler(nil, nil, nil, nil, ""),
+		"NewNotarizationHandler":        NewNotarizationHandler(nil),
+		"NewOrganizationHandler":        NewOrganizationHandler(nil, nil),
+		"NewProfileHandler":             NewProfileHandler(nil, nil),
+		"NewReadinessHandler":           NewReadinessHandler(nil),
+		"NewRenewalHandler":             NewRenewalHandler(nil),
+		"NewReplicationHandler":         NewReplicationHandler(nil, ""),
+		"NewResolverHandler":            NewResolverHandler(nil, nil),
+		"NewReviewHandler":              NewReviewHandler(nil, ""),
+		"NewRevocationHandler":          NewRevocationHandler(nil),
+		"NewSandboxHandler":             NewSandboxHandler(nil, ""),
+		"NewSignedVerificationHandler":  NewSignedVerificationHandler(nil, nil),
+		"NewSmartAccountHandler":        NewSmartAccountHandler(nil),
+		"NewSocialProofHandler":         NewSocialProofHandler(nil),
+		"NewStatusPageHandler":          NewStatusPageHandler(nil, nil, ""),
+		"NewStatusTokenHandler":         NewStatusTokenHandler(nil),
+		"NewStepUpHandler":              NewStepUpHandler(nil),
+		"NewTrustRegistryHandler":       NewTrustRegistryHandler(nil, ""),
+		"NewUsageHandler":               NewUsageHandler(nil, ""),
+		"NewVerificationPolicyHandler":  NewVerificationPolicyHandler(nil, ""),
+		"NewVerificationSessionHandler": NewVerificationSessionHandler(nil),
+		"NewWalletHandler":              NewWalletHandler(nil, nil, nil, nil, nil, nil),
+		"NewWalletOfferHandler":         NewWalletOfferHandler(nil, ""),
+		"NewWebhookHandler":             NewWebhookHandler(nil, ""),
+	}
+}
+
+// TestAllHandlersListed keeps allHandlers in step with the handler constructors, so a
+// new handler is covered by the route tests
+func TestAllHandlersListed(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var constructors []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "New") && strings.HasSuffix(fn.Name.Name, "Handler") {
+				constructors = append(constructors, fn.Name.Name)
+			}
+		}
+	}
+	sort.Strings(constructors)
+
+	handlers := allHandlers()
+	for _, constructor := range constructors {
+		if _, ok := handlers[constructor]; !ok {
+			t.Errorf("%s is missing from allHandlers", constructor)
+		}
+	}
+	if len(handlers) != len(constructors) {
+		t.Errorf("allHandlers lists %d handlers, the package has %d constructors", len(handlers), len(constructors))
+	}
+}
+
+// mountAll registers every handler with the versioned API on router, as the server
+// does; routing conflicts panic
+func mountAll(t *testing.T, router web.Router) {
+	t.Helper()
+	defer func() {
+		if value := recover(); value != nil {
+			t.Fatalf("registering the routes panicked: %v", value)
+		}
+	}()
+
+	v1, v2 := NewAPIVersions(time.Time{}, time.Time{})
+	api := apiversion.NewAPI(router, v1, v2)
+	handlers := allHandlers()
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		web.Mount(api, handlers[name])
+	}
+	api.Mirror()
+}
+
+func TestRoutesMountOnGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mountAll(t, ginweb.New(gin.New()))
+}
+
+func TestRoutesMountOnStdlib(t *testing.T) {
+	mountAll(t, stdweb.New())
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// SandboxHandler handles resets of tenant sandboxes, by the sandbox API key itself or
+// by an administrator
+type SandboxHandler struct {
+	sandboxes *services.SandboxService
+	adminKey  string
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler(sandboxes *services.SandboxService, adminKey string) *SandboxHandler {
+	return &SandboxHandler{
+		sandboxes: sandboxes,
+		adminKey:  adminKey,
+	}
+}
+
+// Reset deletes the data of the calling sandbox API key's sandbox
+func (h *SandboxHandler) Reset(c web.Context) {
+	reset, err := h.sandboxes.Reset(callerFromContext(c))
+	if err != nil {
+		respondSandboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    reset,
+	})
+}
+
+// ResetTenant deletes the data of a tenant's sandbox
+func (h *SandboxHandler) ResetTenant(c web.Context) {
+	reset, err := h.sandboxes.ResetTenant(c.Param("tenant"))
+	if err != nil {
+		respondSandboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    reset,
+	})
+}
+
+// respondSandboxError maps sandbox errors to responses
+func respondSandboxError(c web.Context, err error) {
+	if errors.Is(err, domain.ErrNotSandbox) {
+		c.JSON(http.StatusForbidden, web.H{
+			"error": "Only sandbox API keys have a sandbox to reset",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, web.H{
+		"error":   "Failed to reset sandbox",
+		"details": err.Error(),
+	})
+}
+
+// RegisterRoutes registers the sandbox reset routes
+func (h *SandboxHandler) RegisterRoutes(router web.Router) {
+	router.POST("/api/v1/sandbox/reset", h.Reset)
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.POST("/sandboxes/:tenant/reset", h.ResetTenant)
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// SignedVerificationHandler handles DID verifications authorized by the DID's holder
+type SignedVerificationHandler struct {
+	verifications *services.SignedVerificationService
+	guard         web.HandlerFunc
+}
+
+// NewSignedVerificationHandler creates a new signed verification handler; guard is
+// applied to verification since it looks up DIDs
+func NewSignedVerificationHandler(verifications *services.SignedVerificationService, guard web.HandlerFunc) *SignedVerificationHandler {
+	return &SignedVerificationHandler{
+		verifications: verifications,
+		guard:         guard,
+	}
+}
+
+// IssueNonce issues a single-use nonce for the caller to have a holder sign
+func (h *SignedVerificationHandler) IssueNonce(c web.Context) {
+	nonce, err := h.verifications.IssueNonce(callerFromContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to issue verification nonce",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    nonce,
+	})
+}
+
+// Verify verifies a DID with the holder's signature over the caller's nonce
+func (h *SignedVerificationHandler) Verify(c web.Context) {
+	var req domain.SignedVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.verifications.Verify(callerFromContext(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+		case errors.Is(err, domain.ErrVerificationNonceInvalid), errors.Is(err, domain.ErrInvalidSignature):
+			// Unknown DIDs end up here too, so they count towards enumeration
+			markResolutionMiss(c)
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error":   "Verification not authorized by the holder",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrQuotaExceeded):
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Usage quota exceeded",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrRejectedByHook):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID verification rejected",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrChainUnreachable):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Blockchain unreachable",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to verify DID",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// RegisterRoutes registers the signed verification routes
+func (h *SignedVerificationHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1/did/verify")
+	{
+		api.POST("/nonce", h.IssueNonce)
+		api.POST("/signed", h.guard, h.Verify)
+	}
+}
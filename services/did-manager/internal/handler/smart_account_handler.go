@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// SmartAccountHandler handles the ERC-4337 smart accounts controlling DIDs. Every route
+// acts on the DID-authenticated DID itself.
+type SmartAccountHandler struct {
+	accounts *services.SmartAccountService
+}
+
+// NewSmartAccountHandler creates a new smart account handler
+func NewSmartAccountHandler(accounts *services.SmartAccountService) *SmartAccountHandler {
+	return &SmartAccountHandler{
+		accounts: accounts,
+	}
+}
+
+// CreateAccount binds the calling DID to the smart account of an owner key
+func (h *SmartAccountHandler) CreateAccount(c web.Context) {
+	caller, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SmartAccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account, err := h.accounts.CreateAccount(c.Param("did"), caller.ID, &req)
+	if err != nil {
+		respondSmartAccountError(c, err, "Failed to create smart account")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// GetAccount returns the smart account of the calling DID
+func (h *SmartAccountHandler) GetAccount(c web.Context) {
+	caller, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accounts.GetAccount(c.Param("did"), caller.ID)
+	if err != nil {
+		respondSmartAccountError(c, err, "Failed to get smart account")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// PrepareOperation builds an unsigned user operation for the owner to sign
+func (h *SmartAccountHandler) PrepareOperation(c web.Context) {
+	caller, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UserOperationPrepareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	prepared, err := h.accounts.PrepareOperation(c.Param("did"), caller.ID, &req)
+	if err != nil {
+		respondSmartAccountError(c, err, "Failed to prepare user operation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    prepared,
+	})
+}
+
+// SubmitOperation relays a signed user operation to the bundler
+func (h *SmartAccountHandler) SubmitOperation(c web.Context) {
+	caller, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UserOperationSubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hash, err := h.accounts.SubmitOperation(c.Param("did"), caller.ID, &req)
+	if err != nil {
+		respondSmartAccountError(c, err, "Failed to submit user operation")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, web.H{
+		"success": true,
+		"data":    web.H{"hash": hash},
+	})
+}
+
+// GetOperation returns the status of a submitted user operation
+func (h *SmartAccountHandler) GetOperation(c web.Context) {
+	caller, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.accounts.OperationStatus(c.Param("did"), caller.ID, c.Param("hash"))
+	if err != nil {
+		respondSmartAccountError(c, err, "Failed to get user operation")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// requireSelf returns the DID-authenticated caller, responding 401 for other callers
+func requireSelf(c web.Context) (*domain.Caller, bool) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Smart accounts are only managed by the DID-authenticated DID itself",
+		})
+		return nil, false
+	}
+	return caller, true
+}
+
+// respondSmartAccountError maps smart account service errors to responses
+func respondSmartAccountError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrDIDNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrSmartAccountNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Smart account not found",
+		})
+	case errors.Is(err, domain.ErrSmartAccountExists), errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidUserOperation):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid user operation",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Operation not allowed",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all smart account routes
+func (h *SmartAccountHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1/smart-accounts")
+	{
+		api.POST("/:did", h.CreateAccount)
+		api.GET("/:did", h.GetAccount)
+		api.POST("/:did/operations/prepare", h.PrepareOperation)
+		api.POST("/:did/operations", h.SubmitOperation)
+		api.GET("/:did/operations/:hash", h.GetOperation)
+	}
+}
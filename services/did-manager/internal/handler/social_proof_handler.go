@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// SocialProofHandler handles HTTP requests linking social accounts to DIDs
+type SocialProofHandler struct {
+	proofs *services.SocialProofService
+}
+
+// NewSocialProofHandler creates a new social proof handler
+func NewSocialProofHandler(proofs *services.SocialProofService) *SocialProofHandler {
+	return &SocialProofHandler{proofs: proofs}
+}
+
+// IssueChallenge issues the calling DID a challenge for linking a social account
+func (h *SocialProofHandler) IssueChallenge(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Social accounts are linked by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.SocialProofChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, err := h.proofs.IssueChallenge(caller.ID, &req)
+	if err != nil {
+		h.fail(c, err, "Failed to issue social proof challenge")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    challenge,
+	})
+}
+
+// SubmitProof checks the post carrying the calling DID's signed challenge and returns
+// the issued linkage credential
+func (h *SocialProofHandler) SubmitProof(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.Type != domain.CallerTypeDID {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Social accounts are linked by a DID-authenticated caller",
+		})
+		return
+	}
+
+	var req domain.SocialProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credential, err := h.proofs.Verify(c.Request().Context(), caller.ID, &req)
+	if err != nil {
+		h.fail(c, err, "Failed to verify social account proof")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    credential,
+	})
+}
+
+// fail answers a failed social proof request
+func (h *SocialProofHandler) fail(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrSocialProofsDisabled):
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error": "Social account proofs are not enabled",
+		})
+	case errors.Is(err, domain.ErrSocialPlatformUnsupported), errors.Is(err, domain.ErrSocialProofInvalid):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid social account proof",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrSocialChallengeNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Challenge not found",
+		})
+	case errors.Is(err, domain.ErrDIDNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "DID not found",
+		})
+	case errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusConflict, web.H{
+			"error":   "DID is deactivated",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, web.H{
+			"error":   "Linkage credential cannot be issued",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the social proof routes
+func (h *SocialProofHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/social/challenges", h.IssueChallenge)
+		api.POST("/social/proofs", h.SubmitProof)
+	}
+}
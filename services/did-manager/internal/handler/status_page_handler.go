@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// StatusPageHandler serves the public status page data and the admin routes raising
+// and clearing incident flags
+type StatusPageHandler struct {
+	status   *services.StatusPageService
+	locales  *services.LocaleService
+	adminKey string
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(status *services.StatusPageService, locales *services.LocaleService, adminKey string) *StatusPageHandler {
+	return &StatusPageHandler{
+		status:   status,
+		locales:  locales,
+		adminKey: adminKey,
+	}
+}
+
+// Status reports the overall status, the health of each public component, the
+// anchoring backlog bucket and the raised incident flags, with the overall status
+// described in the locale the request accepts. It needs no credentials.
+func (h *StatusPageHandler) Status(c web.Context) {
+	locale := h.locales.Resolve(c.GetHeader("Accept-Language"), nil)
+
+	// The page is shared between requests; describe a copy
+	page := *h.status.Page()
+	page.Description = h.locales.ServiceStatusDescription(locale, page.Status)
+
+	c.Header("Cache-Control", "public, max-age=15")
+	c.Header("Vary", "Accept-Language")
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, &page)
+}
+
+// ListIncidents lists the raised incident flags
+func (h *StatusPageHandler) ListIncidents(c web.Context) {
+	incidents, err := h.status.Incidents()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list incident flags",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    incidents,
+	})
+}
+
+// RaiseIncident raises an incident flag with a public message, or updates the
+// message of a raised flag
+func (h *StatusPageHandler) RaiseIncident(c web.Context) {
+	var req domain.StatusIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	incident, err := h.status.RaiseIncident(domain.IncidentFlag(c.Param("flag")), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownIncidentFlag) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Unknown incident flag",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to raise incident flag",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    incident,
+	})
+}
+
+// ClearIncident clears a raised incident flag
+func (h *StatusPageHandler) ClearIncident(c web.Context) {
+	err := h.status.ClearIncident(domain.IncidentFlag(c.Param("flag")))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownIncidentFlag):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Unknown incident flag",
+			})
+		case errors.Is(err, domain.ErrStatusIncidentNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Incident flag is not raised",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to clear incident flag",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Incident flag cleared",
+	})
+}
+
+// RegisterRoutes registers the public status route and the admin incident routes
+func (h *StatusPageHandler) RegisterRoutes(router web.Router) {
+	router.GET("/status", h.Status)
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/status/incidents", h.ListIncidents)
+		admin.PUT("/status/incidents/:flag", h.RaiseIncident)
+		admin.DELETE("/status/incidents/:flag", h.ClearIncident)
+	}
+}
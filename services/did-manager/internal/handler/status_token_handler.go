@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// StatusTokenHandler handles requests for status tokens and their verification keys
+type StatusTokenHandler struct {
+	statusTokens *services.StatusTokenService
+}
+
+// NewStatusTokenHandler creates a new status token handler
+func NewStatusTokenHandler(statusTokens *services.StatusTokenService) *StatusTokenHandler {
+	return &StatusTokenHandler{statusTokens: statusTokens}
+}
+
+// IssueStatusTokens signs status tokens for a batch of DIDs
+func (h *StatusTokenHandler) IssueStatusTokens(c web.Context) {
+	var req domain.StatusTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	batch, err := h.statusTokens.Issue(callerFromContext(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrStatusTokensDisabled):
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Status tokens are disabled",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrUnauthenticated):
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+		case errors.Is(err, domain.ErrQuotaExceeded):
+			c.JSON(http.StatusTooManyRequests, web.H{
+				"error":   "Usage quota exceeded",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to issue status tokens",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    batch,
+	})
+}
+
+// GetKeySet returns the JWK set status tokens verify against. Verifiers cache it to
+// check tokens offline.
+func (h *StatusTokenHandler) GetKeySet(c web.Context) {
+	keySet, err := h.statusTokens.KeySet()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error":   "Status tokens are disabled",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, keySet)
+}
+
+// RegisterRoutes registers the status token routes
+func (h *StatusTokenHandler) RegisterRoutes(router web.Router) {
+	router.POST("/api/v1/status-tokens", h.IssueStatusTokens)
+	router.GET("/api/v1/status-tokens/keys", h.GetKeySet)
+}
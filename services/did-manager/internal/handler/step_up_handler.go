@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// StepUpHandler issues step-up challenges to DID-authenticated callers; wallet API
+// clients obtain theirs from the wallet routes
+type StepUpHandler struct {
+	stepUp *services.StepUpService
+}
+
+// NewStepUpHandler creates a new step-up handler
+func NewStepUpHandler(stepUp *services.StepUpService) *StepUpHandler {
+	return &StepUpHandler{
+		stepUp: stepUp,
+	}
+}
+
+// IssueChallenge issues a step-up challenge for the calling DID
+func (h *StepUpHandler) IssueChallenge(c web.Context) {
+	issueStepUpChallenge(c, h.stepUp)
+}
+
+// issueStepUpChallenge issues a step-up challenge for one of the request's subject's
+// DIDs
+func issueStepUpChallenge(c web.Context, stepUp *services.StepUpService) {
+	var req domain.StepUpChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, err := stepUp.IssueChallenge(stepUpSubject(c), req.DID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			c.JSON(http.StatusUnauthorized, web.H{
+				"error": "Authentication required",
+			})
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, web.H{
+				"error":   "DID cannot step up",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to issue step-up challenge",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    challenge,
+	})
+}
+
+// RegisterRoutes registers the step-up routes
+func (h *StepUpHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1/step-up")
+	{
+		api.POST("/challenges", h.IssueChallenge)
+	}
+}
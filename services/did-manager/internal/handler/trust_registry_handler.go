@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// TrustRegistryHandler handles HTTP requests for the trust registry: the public signed
+// snapshot and the admin routes adding and removing issuers
+type TrustRegistryHandler struct {
+	registry *services.TrustRegistryService
+	adminKey string
+}
+
+// NewTrustRegistryHandler creates a new trust registry handler
+func NewTrustRegistryHandler(registry *services.TrustRegistryService, adminKey string) *TrustRegistryHandler {
+	return &TrustRegistryHandler{
+		registry: registry,
+		adminKey: adminKey,
+	}
+}
+
+// GetSnapshot exports the signed registry. Its version is the ETag, so policy engines
+// polling with If-None-Match get 304 Not Modified until the registry changes.
+func (h *TrustRegistryHandler) GetSnapshot(c web.Context) {
+	snapshot, signed, err := h.registry.Snapshot()
+	if err != nil {
+		if errors.Is(err, domain.ErrTrustRegistryUnsigned) {
+			c.JSON(http.StatusServiceUnavailable, web.H{
+				"error":   "Trust registry snapshots are disabled",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to export trust registry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	etag := `"` + strconv.FormatInt(snapshot.Version, 10) + `"`
+	c.Header("ETag", etag)
+	c.Header("X-Trust-Registry-Version", strconv.FormatInt(snapshot.Version, 10))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Data(http.StatusNotModified, "application/json", nil)
+		return
+	}
+	c.JSON(http.StatusOK, signed)
+}
+
+// ListIssuers lists the registry's issuers
+func (h *TrustRegistryHandler) ListIssuers(c web.Context) {
+	issuers, err := h.registry.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list trusted issuers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    issuers,
+	})
+}
+
+// AddIssuer accredits an issuer
+func (h *TrustRegistryHandler) AddIssuer(c web.Context) {
+	var req domain.TrustedIssuerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	issuer, err := h.registry.AddIssuer(&req)
+	if err != nil {
+		if errors.Is(err, domain.ErrTrustedIssuerExists) {
+			c.JSON(http.StatusConflict, web.H{
+				"error": "Issuer is already in the trust registry",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to add trusted issuer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    issuer,
+	})
+}
+
+// RemoveIssuer withdraws an issuer's accreditation
+func (h *TrustRegistryHandler) RemoveIssuer(c web.Context) {
+	if err := h.registry.RemoveIssuer(c.Param("did")); err != nil {
+		if errors.Is(err, domain.ErrTrustedIssuerNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Issuer is not in the trust registry",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to remove trusted issuer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Issuer removed from the trust registry",
+	})
+}
+
+// RegisterRoutes registers the public snapshot route and the admin issuer routes
+func (h *TrustRegistryHandler) RegisterRoutes(router web.Router) {
+	router.GET("/api/v1/trust-registry", h.GetSnapshot)
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/trust-registry/issuers", h.ListIssuers)
+		admin.POST("/trust-registry/issuers", h.AddIssuer)
+		admin.DELETE("/trust-registry/issuers/:did", h.RemoveIssuer)
+	}
+}
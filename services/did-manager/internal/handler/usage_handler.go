@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// UsageHandler handles administrative requests for tenant quotas and billing exports
+type UsageHandler struct {
+	usage    *services.UsageService
+	adminKey string
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usage *services.UsageService, adminKey string) *UsageHandler {
+	return &UsageHandler{
+		usage:    usage,
+		adminKey: adminKey,
+	}
+}
+
+// ExportBilling exports billable usage per tenant over a period as JSON or CSV
+func (h *UsageHandler) ExportBilling(c web.Context) {
+	var req domain.BillingExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	export, err := h.usage.Export(req.From, req.To, req.Tenant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to export billing usage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("billing-%s-%s", export.From.Format("20060102"), export.To.Format("20060102"))
+	if req.Format == "csv" {
+		document, err := h.usage.RenderCSV(export)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to export billing usage",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Data(http.StatusOK, "text/csv", document)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+// ListQuotas lists every tenant and default quota
+func (h *UsageHandler) ListQuotas(c web.Context) {
+	quotas, err := h.usage.ListQuotas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list quotas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    quotas,
+	})
+}
+
+// SetQuota sets a tenant's monthly quota, or the default quota without a tenant
+func (h *UsageHandler) SetQuota(c web.Context) {
+	var req domain.TenantQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	quota, err := h.usage.SetQuota(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to save quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    quota,
+	})
+}
+
+// DeleteQuota removes the quota selected by the tenant and event query parameters
+func (h *UsageHandler) DeleteQuota(c web.Context) {
+	event := c.Query("event")
+	if event == "" {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Event parameter is required",
+		})
+		return
+	}
+
+	if err := h.usage.DeleteQuota(c.Query("tenant"), domain.UsageEventType(event)); err != nil {
+		if errors.Is(err, domain.ErrQuotaNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Quota not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to delete quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Quota removed",
+	})
+}
+
+// RegisterRoutes registers the admin quota and billing routes
+func (h *UsageHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/billing/export", h.ExportBilling)
+		admin.GET("/quotas", h.ListQuotas)
+		admin.PUT("/quotas", h.SetQuota)
+		admin.DELETE("/quotas", h.DeleteQuota)
+	}
+}
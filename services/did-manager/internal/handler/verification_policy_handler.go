@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+)
+
+// VerificationPolicyHandler handles administrative requests for the policies deciding
+// how DID verification answers when the blockchain is unreachable
+type VerificationPolicyHandler struct {
+	policies *services.VerificationPolicyService
+	adminKey string
+}
+
+// NewVerificationPolicyHandler creates a new verification policy handler
+func NewVerificationPolicyHandler(policies *services.VerificationPolicyService, adminKey string) *VerificationPolicyHandler {
+	return &VerificationPolicyHandler{
+		policies: policies,
+		adminKey: adminKey,
+	}
+}
+
+// ListPolicies lists every verification policy
+func (h *VerificationPolicyHandler) ListPolicies(c web.Context) {
+	policies, err := h.policies.ListPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list verification policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// SetPolicy sets the fallback of a tenant and endpoint
+func (h *VerificationPolicyHandler) SetPolicy(c web.Context) {
+	var req domain.VerificationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.policies.SetPolicy(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to save verification policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// DeletePolicy removes the policy of the tenant and endpoint query parameters
+func (h *VerificationPolicyHandler) DeletePolicy(c web.Context) {
+	endpoint := domain.VerificationEndpoint(c.Query("endpoint"))
+	if err := h.policies.DeletePolicy(c.Query("tenant"), endpoint); err != nil {
+		if errors.Is(err, domain.ErrVerificationPolicyNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Verification policy not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to delete verification policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Verification policy removed",
+	})
+}
+
+// RegisterRoutes registers the admin verification policy routes
+func (h *VerificationPolicyHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.GET("/verification-policies", h.ListPolicies)
+		admin.PUT("/verification-policies", h.SetPolicy)
+		admin.DELETE("/verification-policies", h.DeletePolicy)
+	}
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+// sessionEventsPollInterval is how often an event stream checks its session for changes
+const sessionEventsPollInterval = time.Second
+
+// VerificationSessionHandler handles HTTP requests for cross-device verification sessions
+type VerificationSessionHandler struct {
+	sessions *services.VerificationSessionService
+}
+
+// NewVerificationSessionHandler creates a new verification session handler
+func NewVerificationSessionHandler(sessions *services.VerificationSessionService) *VerificationSessionHandler {
+	return &VerificationSessionHandler{
+		sessions: sessions,
+	}
+}
+
+// CreateSession starts a verification session for the calling verifier
+func (h *VerificationSessionHandler) CreateSession(c web.Context) {
+	caller := callerFromContext(c)
+	if caller.IsAnonymous() {
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Verification sessions must be created by an authenticated caller",
+		})
+		return
+	}
+
+	var req domain.VerificationSessionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.sessions.CreateSession(caller, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to create verification session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetSession returns a session's status to the verifier that created it
+func (h *VerificationSessionHandler) GetSession(c web.Context) {
+	id, ok := parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetSession(callerFromContext(c), id)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// StreamEvents streams a session's status to the verifier as server-sent events until
+// the session completes or expires
+func (h *VerificationSessionHandler) StreamEvents(c web.Context) {
+	id, ok := parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	caller := callerFromContext(c)
+	session, err := h.sessions.GetSession(caller, id)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(sessionEventsPollInterval)
+	defer ticker.Stop()
+
+	lastStatus := ""
+	c.Stream(func(w io.Writer) bool {
+		if session.Status != lastStatus {
+			lastStatus = session.Status
+			c.SSEvent("status", session)
+			if domain.VerificationSessionStatus(session.Status).IsFinal() {
+				return false
+			}
+		}
+
+		select {
+		case <-c.Request().Context().Done():
+			return false
+		case <-ticker.C:
+		}
+
+		next, err := h.sessions.GetSession(caller, id)
+		if err != nil {
+			c.SSEvent("error", web.H{"error": err.Error()})
+			return false
+		}
+		session = next
+		return true
+	})
+}
+
+// QRCode renders the QR code the wallet scans to join a session
+func (h *VerificationSessionHandler) QRCode(c web.Context) {
+	id, ok := parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.sessions.GetSession(callerFromContext(c), id); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	png, err := qrcode.Encode(h.sessions.QRPayload(id), qrcode.Medium, 256)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to render QR code",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// GetRequest returns the verification request to a wallet that scanned the QR code
+func (h *VerificationSessionHandler) GetRequest(c web.Context) {
+	id, ok := parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.sessions.GetRequest(id)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// Respond accepts the wallet's signed presentation for a session
+func (h *VerificationSessionHandler) Respond(c web.Context) {
+	id, ok := parseSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.VerificationResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.sessions.Respond(id, &req)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// parseSessionID parses the :id path parameter, responding with 400 when it is invalid
+func parseSessionID(c web.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid session ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondSessionError maps verification session errors to HTTP responses
+func respondSessionError(c web.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrVerificationSessionNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Verification session not found",
+		})
+	case errors.Is(err, domain.ErrVerificationSessionClosed):
+		c.JSON(http.StatusGone, web.H{
+			"error": "Verification session is no longer open",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to process verification session",
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all verification session routes. The request and response
+// routes are called by the wallet, which is identified only by the session ID it scanned.
+func (h *VerificationSessionHandler) RegisterRoutes(router web.Router) {
+	sessions := router.Group("/api/v1/verification-sessions")
+	{
+		sessions.POST("", h.CreateSession)
+		sessions.GET("/:id", h.GetSession)
+		sessions.GET("/:id/events", h.StreamEvents)
+		sessions.GET("/:id/qr", h.QRCode)
+		sessions.GET("/:id/request", h.GetRequest)
+		sessions.POST("/:id/response", h.Respond)
+	}
+}
@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// WalletHandler handles wallet API requests made by third-party applications with
+// scoped tokens issued by auth-service
+type WalletHandler struct {
+	wallet        *services.WalletService
+	notifications *services.NotificationService
+	custody       *services.CustodyService
+	verifier      *security.WalletTokenVerifier
+	stepUp        *services.StepUpService
+	deviceKeys    *services.DeviceKeyService
+}
+
+// NewWalletHandler creates a new wallet handler
+func NewWalletHandler(wallet *services.WalletService, notifications *services.NotificationService, custody *services.CustodyService, verifier *security.WalletTokenVerifier, stepUp *services.StepUpService, deviceKeys *services.DeviceKeyService) *WalletHandler {
+	return &WalletHandler{
+		wallet:        wallet,
+		notifications: notifications,
+		custody:       custody,
+		verifier:      verifier,
+		stepUp:        stepUp,
+		deviceKeys:    deviceKeys,
+	}
+}
+
+// ListDIDs lists the token owner's DIDs
+func (h *WalletHandler) ListDIDs(c web.Context) {
+	claims := walletClaimsFromContext(c)
+
+	dids, err := h.wallet.ListDIDs(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list DIDs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    dids,
+	})
+}
+
+// ListCredentials lists the credentials held by the token owner's DIDs
+func (h *WalletHandler) ListCredentials(c web.Context) {
+	claims := walletClaimsFromContext(c)
+
+	credentials, err := h.wallet.ListCredentials(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list credentials",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    credentials,
+	})
+}
+
+// CreatePresentation creates a signed presentation of the token owner's credentials
+func (h *WalletHandler) CreatePresentation(c web.Context) {
+	var req domain.PresentationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	claims := walletClaimsFromContext(c)
+
+	presentation, err := h.wallet.CreatePresentation(claims.UserID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Holder DID not found",
+			})
+		case errors.Is(err, domain.ErrCredentialNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Credential not found",
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Credential cannot be presented",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to create presentation",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    presentation,
+	})
+}
+
+// RegisterDevice registers the caller's device for wallet push notifications
+func (h *WalletHandler) RegisterDevice(c web.Context) {
+	var req domain.DeviceRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	claims := walletClaimsFromContext(c)
+
+	device, err := h.notifications.RegisterDevice(claims.UserID, &req, c.GetHeader("Accept-Language"))
+	if err != nil {
+		if errors.Is(err, domain.ErrUnsupportedLocale) {
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Unsupported locale",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to register device",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    device,
+	})
+}
+
+// ListDevices lists the token owner's registered devices
+func (h *WalletHandler) ListDevices(c web.Context) {
+	claims := walletClaimsFromContext(c)
+
+	devices, err := h.notifications.ListDevices(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to list devices",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    devices,
+	})
+}
+
+// UnregisterDevice stops push notifications to one of the token owner's devices
+func (h *WalletHandler) UnregisterDevice(c web.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid device ID format",
+		})
+		return
+	}
+
+	claims := walletClaimsFromContext(c)
+
+	if err := h.notifications.UnregisterDevice(claims.UserID, deviceID); err != nil {
+		if errors.Is(err, domain.ErrDeviceNotFound) {
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Device not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to unregister device",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Device unregistered",
+	})
+}
+
+// StartCustodyTransfer opens a transfer of one of the token owner's custodial DIDs to
+// a key the owner holds. The returned challenge is signed with that key as
+// "custody-transfer:<did>:<challenge>" to complete the transfer.
+func (h *WalletHandler) StartCustodyTransfer(c web.Context) {
+	didID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid DID ID format",
+		})
+		return
+	}
+
+	claims := walletClaimsFromContext(c)
+
+	transfer, err := h.custody.StartTransfer(claims.UserID, claims.ClientID, didID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrNotCustodial), errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "DID custody cannot be transferred",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to start custody transfer",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    transfer,
+	})
+}
+
+// CompleteCustodyTransfer rotates a DID to the token owner's key after checking the
+// owner's signature over the transfer challenge
+func (h *WalletHandler) CompleteCustodyTransfer(c web.Context) {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid custody transfer ID format",
+		})
+		return
+	}
+
+	var req domain.CustodyTransferCompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	claims := walletClaimsFromContext(c)
+
+	transfer, err := h.custody.CompleteTransfer(claims.UserID, transferID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCustodyTransferNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "Custody transfer not found",
+			})
+		case errors.Is(err, domain.ErrCustodyTransferClosed):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "Custody transfer is no longer open",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrDIDDeactivated):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "DID is deactivated",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, web.H{
+				"error":   "Proof of possession failed",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to complete custody transfer",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    transfer,
+	})
+}
+
+// IssueStepUpChallenge issues a step-up challenge for one of the token owner's DIDs,
+// proving the owner again before custody transfers
+func (h *WalletHandler) IssueStepUpChallenge(c web.Context) {
+	issueStepUpChallenge(c, h.stepUp)
+}
+
+// RegisterRoutes registers all wallet routes
+func (h *WalletHandler) RegisterRoutes(router web.Router) {
+	wallet := router.Group("/api/v1/wallet", WalletAuth(h.verifier))
+	{
+		wallet.GET("/dids", RequireScope(security.ScopeDIDsRead), h.ListDIDs)
+		wallet.GET("/credentials", RequireScope(security.ScopeCredentialsRead), h.ListCredentials)
+		wallet.POST("/presentations", RequireScope(security.ScopePresentationsCreate), RequireAttestedApp(h.deviceKeys, domain.WalletOperationPresentation), h.CreatePresentation)
+		wallet.POST("/devices", RequireScope(security.ScopeNotificationsManage), h.RegisterDevice)
+		wallet.GET("/devices", RequireScope(security.ScopeNotificationsManage), h.ListDevices)
+		wallet.DELETE("/devices/:id", RequireScope(security.ScopeNotificationsManage), h.UnregisterDevice)
+		wallet.POST("/step-up/challenges", h.IssueStepUpChallenge)
+		wallet.POST("/dids/:id/custody-transfers", RequireScope(security.ScopeDIDsTransfer), RequireAttestedApp(h.deviceKeys, domain.WalletOperationCustodyTransfer), RequireStepUp(h.stepUp, domain.StepUpCustodyTransfer), h.StartCustodyTransfer)
+		wallet.POST("/custody-transfers/:id/complete", RequireScope(security.ScopeDIDsTransfer), RequireAttestedApp(h.deviceKeys, domain.WalletOperationCustodyTransfer), RequireStepUp(h.stepUp, domain.StepUpCustodyTransfer), h.CompleteCustodyTransfer)
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+// WalletOfferHandler handles wallet offers. auth-service creates and tracks them through
+// the admin routes; wallets claim them with the code from the claim link.
+type WalletOfferHandler struct {
+	offers   *services.WalletOfferService
+	adminKey string
+}
+
+// NewWalletOfferHandler creates a new wallet offer handler
+func NewWalletOfferHandler(offers *services.WalletOfferService, adminKey string) *WalletOfferHandler {
+	return &WalletOfferHandler{
+		offers:   offers,
+		adminKey: adminKey,
+	}
+}
+
+// CreateOffer offers an active DID and its credentials to the owner's wallet
+func (h *WalletOfferHandler) CreateOffer(c web.Context) {
+	var req domain.WalletOfferCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.offers.CreateOffer(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error": "DID not found",
+			})
+		case errors.Is(err, domain.ErrCredentialNotFound):
+			c.JSON(http.StatusNotFound, web.H{
+				"error":   "Credential not found",
+				"details": err.Error(),
+			})
+		case errors.Is(err, domain.ErrDIDNotActive):
+			c.JSON(http.StatusConflict, web.H{
+				"error":   "DID cannot be offered yet",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, web.H{
+				"error":   "Failed to create wallet offer",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	png, err := qrcode.Encode(response.ClaimURI, qrcode.Medium, 256)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to render QR code",
+			"details": err.Error(),
+		})
+		return
+	}
+	response.QRImage = "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetOffer returns an offer, so its creator can track whether it was claimed
+func (h *WalletOfferHandler) GetOffer(c web.Context) {
+	id, ok := parseWalletOfferID(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.offers.GetOffer(id)
+	if err != nil {
+		respondWalletOfferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    offer,
+	})
+}
+
+// Claim hands an offer's DID and credentials to the wallet presenting its claim code
+func (h *WalletOfferHandler) Claim(c web.Context) {
+	id, ok := parseWalletOfferID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.WalletOfferClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	claim, err := h.offers.Claim(id, req.Code)
+	if err != nil {
+		respondWalletOfferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    claim,
+	})
+}
+
+// parseWalletOfferID parses the :id path parameter, responding with 400 when it is invalid
+func parseWalletOfferID(c web.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid wallet offer ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondWalletOfferError maps wallet offer errors to HTTP responses
+func respondWalletOfferError(c web.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWalletOfferNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Wallet offer not found",
+		})
+	case errors.Is(err, domain.ErrWalletOfferClosed):
+		c.JSON(http.StatusGone, web.H{
+			"error": "Wallet offer was already claimed or has expired",
+		})
+	case errors.Is(err, domain.ErrDIDDeactivated):
+		c.JSON(http.StatusGone, web.H{
+			"error": "DID has been deactivated",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   "Failed to process wallet offer",
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers the admin and wallet offer routes. The claim route is called
+// by the wallet, which is identified only by the claim code it was sent.
+func (h *WalletOfferHandler) RegisterRoutes(router web.Router) {
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.POST("/wallet-offers", h.CreateOffer)
+		admin.GET("/wallet-offers/:id", h.GetOffer)
+	}
+
+	router.POST("/api/v1/wallet-offers/:id/claim", h.Claim)
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/services"
+	"did-manager/pkg/web"
+
+	"github.com/google/uuid"
+)
+
+// WebhookHandler handles HTTP requests for tenants' webhook endpoints and their
+// delivery history, and administrators' event replays
+type WebhookHandler struct {
+	webhooks *services.WebhookService
+	adminKey string
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks *services.WebhookService, adminKey string) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+		adminKey: adminKey,
+	}
+}
+
+// CreateEndpoint configures a webhook endpoint for the caller
+func (h *WebhookHandler) CreateEndpoint(c web.Context) {
+	var req domain.WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	endpoint, err := h.webhooks.CreateEndpoint(callerFromContext(c), &req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook endpoint")
+		return
+	}
+
+	c.JSON(http.StatusCreated, web.H{
+		"success": true,
+		"data":    endpoint,
+	})
+}
+
+// ListEndpoints lists the caller's webhook endpoints
+func (h *WebhookHandler) ListEndpoints(c web.Context) {
+	endpoints, err := h.webhooks.ListEndpoints(callerFromContext(c))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to list webhook endpoints")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endpoints,
+	})
+}
+
+// GetEndpoint returns one of the caller's webhook endpoints
+func (h *WebhookHandler) GetEndpoint(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhooks.GetEndpoint(callerFromContext(c), id)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to get webhook endpoint")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endpoint,
+	})
+}
+
+// UpdateEndpoint replaces the configuration of one of the caller's webhook endpoints
+func (h *WebhookHandler) UpdateEndpoint(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	endpoint, err := h.webhooks.UpdateEndpoint(callerFromContext(c), id, &req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to update webhook endpoint")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endpoint,
+	})
+}
+
+// DeleteEndpoint removes one of the caller's webhook endpoints
+func (h *WebhookHandler) DeleteEndpoint(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.DeleteEndpoint(callerFromContext(c), id); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook endpoint")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"message": "Webhook endpoint deleted",
+	})
+}
+
+// RotateSecret replaces the signing secret of one of the caller's webhook endpoints
+func (h *WebhookHandler) RotateSecret(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhooks.RotateSecret(callerFromContext(c), id)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to rotate webhook secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    endpoint,
+	})
+}
+
+// ListDeliveries lists the deliveries of one of the caller's webhook endpoints
+func (h *WebhookHandler) ListDeliveries(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var query domain.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(callerFromContext(c), id, &query)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    deliveries,
+	})
+}
+
+// GetDelivery returns a delivery of one of the caller's webhook endpoints with its
+// attempts
+func (h *WebhookHandler) GetDelivery(c web.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := webhookID(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.GetDelivery(callerFromContext(c), id, deliveryID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to get webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, web.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// Replay re-delivers selected retained events to a tenant's webhook endpoints
+func (h *WebhookHandler) Replay(c web.Context) {
+	var req domain.WebhookReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	replay, err := h.webhooks.Replay(&req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to replay events")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, web.H{
+		"success": true,
+		"data":    replay,
+	})
+}
+
+// webhookID parses a UUID path parameter, responding with 400 when it is malformed
+func webhookID(c web.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, web.H{
+			"error": "Invalid ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondWebhookError maps webhook service errors to responses
+func respondWebhookError(c web.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, web.H{
+			"error": "Webhooks are managed by an authenticated caller",
+		})
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Webhook endpoint not found",
+		})
+	case errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, web.H{
+			"error": "Webhook delivery not found",
+		})
+	case errors.Is(err, domain.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid webhook endpoint",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidWebhookReplay):
+		c.JSON(http.StatusBadRequest, web.H{
+			"error":   "Invalid replay",
+			"details": err.Error(),
+		})
+	case errors.Is(err, domain.ErrWebhookReplayUnavailable):
+		c.JSON(http.StatusServiceUnavailable, web.H{
+			"error":   "Event replay unavailable",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, web.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// RegisterRoutes registers all webhook routes
+func (h *WebhookHandler) RegisterRoutes(router web.Router) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/webhooks", h.CreateEndpoint)
+		api.GET("/webhooks", h.ListEndpoints)
+		api.GET("/webhooks/:id", h.GetEndpoint)
+		api.PUT("/webhooks/:id", h.UpdateEndpoint)
+		api.DELETE("/webhooks/:id", h.DeleteEndpoint)
+		api.POST("/webhooks/:id/secret", h.RotateSecret)
+		api.GET("/webhooks/:id/deliveries", h.ListDeliveries)
+		api.GET("/webhooks/:id/deliveries/:deliveryId", h.GetDelivery)
+	}
+
+	admin := router.Group("/api/v1/admin", RequireAdmin(h.adminKey))
+	{
+		admin.POST("/events/replay", h.Replay)
+	}
+}
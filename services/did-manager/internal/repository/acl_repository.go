@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ACLRepository implements the DID access control list repository interface
+type ACLRepository struct {
+	db *sql.DB
+}
+
+// NewACLRepository creates a new ACL repository
+func NewACLRepository(db *sql.DB) *ACLRepository {
+	return &ACLRepository{db: db}
+}
+
+// Create creates a new ACL entry, ignoring duplicates of an existing grant
+func (r *ACLRepository) Create(entry *domain.ACLEntry) error {
+	query := `
+		INSERT INTO did_acl_entries (id, did_id, grantee_type, grantee, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (did_id, grantee_type, grantee) DO NOTHING
+	`
+
+	_, err := r.db.Exec(query,
+		entry.ID,
+		entry.DIDID,
+		entry.GranteeType,
+		entry.Grantee,
+		entry.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create ACL entry: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an ACL entry
+func (r *ACLRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM did_acl_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete ACL entry: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrACLEntryNotFound
+	}
+
+	return nil
+}
+
+// ListByDIDID retrieves all ACL entries for a DID
+func (r *ACLRepository) ListByDIDID(didID uuid.UUID) ([]*domain.ACLEntry, error) {
+	query := `
+		SELECT id, did_id, grantee_type, grantee, created_at
+		FROM did_acl_entries WHERE did_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ACL entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.ACLEntry
+	for rows.Next() {
+		var entry domain.ACLEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.DIDID,
+			&entry.GranteeType,
+			&entry.Grantee,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ACL entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Exists checks whether a grantee has been granted access to a DID
+func (r *ACLRepository) Exists(didID uuid.UUID, granteeType, grantee string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM did_acl_entries
+			WHERE did_id = $1 AND grantee_type = $2 AND grantee = $3
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(query, didID, granteeType, grantee).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check ACL entry: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// analyticsExportColumns lists the columns selected for every analytics export query, in scan order
+const analyticsExportColumns = `id, first_event_id, last_event_id, event_count, file_count, created_at`
+
+// AnalyticsExportRepository implements the analytics export repository interface
+type AnalyticsExportRepository struct {
+	db *sql.DB
+}
+
+// NewAnalyticsExportRepository creates a new analytics export repository
+func NewAnalyticsExportRepository(db *sql.DB) *AnalyticsExportRepository {
+	return &AnalyticsExportRepository{db: db}
+}
+
+// LastEventID returns the ID of the last exported usage event, or 0 before the first export
+func (r *AnalyticsExportRepository) LastEventID() (int64, error) {
+	var last int64
+	if err := r.db.QueryRow(`SELECT COALESCE(MAX(last_event_id), 0) FROM analytics_exports`).Scan(&last); err != nil {
+		return 0, fmt.Errorf("failed to get last exported event: %w", err)
+	}
+	return last, nil
+}
+
+// ListEventsAfter lists usage events with IDs above afterID, in ID order
+func (r *AnalyticsExportRepository) ListEventsAfter(afterID int64, limit int) ([]*domain.AnalyticsEvent, error) {
+	query := `
+		SELECT id, tenant, event_type, subject, occurred_at
+		FROM usage_events
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.AnalyticsEvent
+	for rows.Next() {
+		var event domain.AnalyticsEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.Tenant,
+			&event.Event,
+			&event.Subject,
+			&event.OccurredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan usage event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// Create records an export
+func (r *AnalyticsExportRepository) Create(export *domain.AnalyticsExport) error {
+	query := `
+		INSERT INTO analytics_exports (id, first_event_id, last_event_id, event_count, file_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(query,
+		export.ID,
+		export.FirstEventID,
+		export.LastEventID,
+		export.Events,
+		export.Files,
+		export.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create analytics export: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the most recent exports, newest first
+func (r *AnalyticsExportRepository) List(limit int) ([]*domain.AnalyticsExport, error) {
+	query := `SELECT ` + analyticsExportColumns + ` FROM analytics_exports ORDER BY created_at DESC LIMIT $1`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []*domain.AnalyticsExport
+	for rows.Next() {
+		var export domain.AnalyticsExport
+		if err := rows.Scan(
+			&export.ID,
+			&export.FirstEventID,
+			&export.LastEventID,
+			&export.Events,
+			&export.Files,
+			&export.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics export: %w", err)
+		}
+		exports = append(exports, &export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return exports, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// AnchoringPauseRepository implements the anchoring pause repository interface
+type AnchoringPauseRepository struct {
+	db *sql.DB
+}
+
+// NewAnchoringPauseRepository creates a new anchoring pause repository
+func NewAnchoringPauseRepository(db *sql.DB) *AnchoringPauseRepository {
+	return &AnchoringPauseRepository{db: db}
+}
+
+// Get retrieves the pause, or nil while anchoring runs
+func (r *AnchoringPauseRepository) Get() (*domain.AnchoringPause, error) {
+	query := `SELECT reason, paused_at FROM anchoring_pause`
+
+	pause := &domain.AnchoringPause{Paused: true}
+	var pausedAt time.Time
+	err := r.db.QueryRow(query).Scan(&pause.Reason, &pausedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anchoring pause: %w", err)
+	}
+	pause.PausedAt = &pausedAt
+
+	return pause, nil
+}
+
+// Save pauses anchoring, or updates the reason of a pause keeping its start
+func (r *AnchoringPauseRepository) Save(pause *domain.AnchoringPause) error {
+	query := `
+		INSERT INTO anchoring_pause (id, reason, paused_at, updated_at)
+		VALUES (TRUE, $1, $2, $2)
+		ON CONFLICT (id) DO UPDATE
+		SET reason = EXCLUDED.reason, updated_at = EXCLUDED.updated_at
+		RETURNING paused_at
+	`
+
+	var pausedAt time.Time
+	if err := r.db.QueryRow(query, pause.Reason, pause.PausedAt).Scan(&pausedAt); err != nil {
+		return fmt.Errorf("failed to save anchoring pause: %w", err)
+	}
+	pause.PausedAt = &pausedAt
+
+	return nil
+}
+
+// Delete resumes anchoring
+func (r *AnchoringPauseRepository) Delete() error {
+	query := `DELETE FROM anchoring_pause`
+
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to delete anchoring pause: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrAnchoringNotPaused
+	}
+
+	return nil
+}
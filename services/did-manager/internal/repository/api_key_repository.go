@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyColumns lists the columns selected for every API key query, in scan order
+const apiKeyColumns = `id, name, prefix, key_hash, status, created_at, last_used_at, sandbox, parent_id, root_did, default_locale`
+
+// scanAPIKey scans a single API key row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Status,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.Sandbox,
+		&key.ParentID,
+		&key.RootDID,
+		&key.DefaultLocale,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// APIKeyRepository implements the API key repository interface
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create creates a new API key record
+func (r *APIKeyRepository) Create(key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, status, created_at, sandbox, parent_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Status,
+		key.CreatedAt,
+		key.Sandbox,
+		key.ParentID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves an API key by the hash of its plaintext value
+func (r *APIKeyRepository) GetByHash(keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// List retrieves all API keys
+func (r *APIKeyRepository) List() ([]*domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows)
+}
+
+// ListByParent retrieves the sub-keys issued by a tenant key
+func (r *APIKeyRepository) ListByParent(parentID uuid.UUID) ([]*domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE parent_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sub-keys: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows)
+}
+
+// scanAPIKeys scans API key rows selected with apiKeyColumns
+func scanAPIKeys(rows *sql.Rows) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke marks an API key and its sub-keys as revoked
+func (r *APIKeyRepository) Revoke(id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET status = $2
+		WHERE id = $1 OR parent_id = $1
+	`
+
+	result, err := r.db.Exec(query, id, domain.APIKeyStatusRevoked)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Rotate replaces the prefix and hash of an active API key
+func (r *APIKeyRepository) Rotate(id uuid.UUID, prefix, keyHash string) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET prefix = $2, key_hash = $3
+		WHERE id = $1 AND status = $4
+		RETURNING ` + apiKeyColumns + `
+	`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id, prefix, keyHash, domain.APIKeyStatusActive))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByRootDID retrieves the tenant key whose root capability a DID holds
+func (r *APIKeyRepository) GetByRootDID(rootDID string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE root_did = $1 AND root_did <> ''`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, rootDID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// SetRootDID binds the root capability of an active tenant key to a DID, or unbinds it
+// when rootDID is empty
+func (r *APIKeyRepository) SetRootDID(id uuid.UUID, rootDID string) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET root_did = $2
+		WHERE id = $1 AND status = $3 AND parent_id IS NULL
+		RETURNING ` + apiKeyColumns + `
+	`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id, rootDID, domain.APIKeyStatusActive))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to set root DID: %w", err)
+	}
+
+	return key, nil
+}
+
+// SetDefaultLocale sets the default locale of an active tenant key, or clears it when
+// locale is empty
+func (r *APIKeyRepository) SetDefaultLocale(id uuid.UUID, locale string) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET default_locale = $2
+		WHERE id = $1 AND status = $3 AND parent_id IS NULL
+		RETURNING ` + apiKeyColumns + `
+	`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id, locale, domain.APIKeyStatusActive))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to set default locale: %w", err)
+	}
+
+	return key, nil
+}
+
+// TouchLastUsed records that an API key was just used
+func (r *APIKeyRepository) TouchLastUsed(id uuid.UUID) error {
+	query := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+
+	return nil
+}
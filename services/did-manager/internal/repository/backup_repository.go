@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// backupColumns lists the columns selected for every backup query, in scan order
+const backupColumns = `id, object_name, key_id, size, did_count, credential_count, job_count, created_at`
+
+// backupTables lists the tables in a snapshot, in restore order: blockchain jobs
+// reference their DID
+var backupTables = []string{"dids", "credentials", "blockchain_jobs"}
+
+// scanBackup scans a single backup row selected with backupColumns
+func scanBackup(row rowScanner) (*domain.Backup, error) {
+	var backup domain.Backup
+	err := row.Scan(
+		&backup.ID,
+		&backup.ObjectName,
+		&backup.KeyID,
+		&backup.Size,
+		&backup.DIDs,
+		&backup.Credentials,
+		&backup.Jobs,
+		&backup.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// BackupRepository implements the backup repository interface
+type BackupRepository struct {
+	db *sql.DB
+}
+
+// NewBackupRepository creates a new backup repository
+func NewBackupRepository(db *sql.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// Snapshot reads the identity tables in one repeatable-read transaction, so the
+// snapshot is consistent across tables. Rows are read as JSON keyed by column, which
+// keeps columns added to the tables in the snapshot without changes here.
+func (r *BackupRepository) Snapshot() (*domain.BackupSnapshot, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := &domain.BackupSnapshot{
+		Format:     domain.BackupFormat,
+		CreatedAt:  time.Now(),
+		DIDTenants: make(map[string]string),
+	}
+	tables := map[string]*[]json.RawMessage{
+		"dids":            &snapshot.DIDs,
+		"credentials":     &snapshot.Credentials,
+		"blockchain_jobs": &snapshot.Jobs,
+	}
+	for _, table := range backupTables {
+		if *tables[table], err = dumpTable(tx, table); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(`
+		SELECT DISTINCT ON (subject) subject, tenant
+		FROM usage_events WHERE event_type = $1
+		ORDER BY subject, occurred_at
+	`, domain.UsageDIDCreated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DID tenants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var did, tenant string
+		if err := rows.Scan(&did, &tenant); err != nil {
+			return nil, fmt.Errorf("failed to scan DID tenant: %w", err)
+		}
+		snapshot.DIDTenants[did] = tenant
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// dumpTable reads every row of a table as a JSON object
+func dumpTable(tx *sql.Tx, table string) ([]json.RawMessage, error) {
+	rows, err := tx.Query(`SELECT row_to_json(t) FROM ` + table + ` t`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	dump := []json.RawMessage{}
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		dump = append(dump, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return dump, nil
+}
+
+// Restore inserts the rows of a snapshot missing from the tables in one transaction.
+// Rows whose key already exists are left untouched.
+func (r *BackupRepository) Restore(snapshot *domain.BackupSnapshot) (int, int, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tables := map[string][]json.RawMessage{
+		"dids":            snapshot.DIDs,
+		"credentials":     snapshot.Credentials,
+		"blockchain_jobs": snapshot.Jobs,
+	}
+	restored := make(map[string]int, len(backupTables))
+	for _, table := range backupTables {
+		if restored[table], err = restoreTable(tx, table, tables[table]); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored["dids"], restored["credentials"], restored["blockchain_jobs"], nil
+}
+
+// restoreTable inserts JSON rows into a table, skipping those that conflict with
+// existing rows, and returns the number inserted
+func restoreTable(tx *sql.Tx, table string, rows []json.RawMessage) (int, error) {
+	query := `
+		INSERT INTO ` + table + `
+		SELECT * FROM json_populate_record(NULL::` + table + `, $1::json)
+		ON CONFLICT DO NOTHING
+	`
+
+	inserted := 0
+	for _, row := range rows {
+		result, err := tx.Exec(query, string(row))
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore %s row: %w", table, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+
+	return inserted, nil
+}
+
+// Create stores a new backup record
+func (r *BackupRepository) Create(backup *domain.Backup) error {
+	query := `
+		INSERT INTO backups (id, object_name, key_id, size, did_count, credential_count, job_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(query,
+		backup.ID,
+		backup.ObjectName,
+		backup.KeyID,
+		backup.Size,
+		backup.DIDs,
+		backup.Credentials,
+		backup.Jobs,
+		backup.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a backup by ID
+func (r *BackupRepository) GetByID(id uuid.UUID) (*domain.Backup, error) {
+	query := `SELECT ` + backupColumns + ` FROM backups WHERE id = $1`
+
+	backup, err := scanBackup(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBackupNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+
+	return backup, nil
+}
+
+// List lists backups, newest first
+func (r *BackupRepository) List(limit int) ([]*domain.Backup, error) {
+	query := `SELECT ` + backupColumns + ` FROM backups ORDER BY created_at DESC LIMIT $1`
+	return r.list(query, limit)
+}
+
+// ListBefore lists the backups created before the given time
+func (r *BackupRepository) ListBefore(before time.Time) ([]*domain.Backup, error) {
+	query := `SELECT ` + backupColumns + ` FROM backups WHERE created_at < $1 ORDER BY created_at`
+	return r.list(query, before)
+}
+
+// list runs a backup query
+func (r *BackupRepository) list(query string, args ...any) ([]*domain.Backup, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backups: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []*domain.Backup
+	for rows.Next() {
+		backup, err := scanBackup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backup: %w", err)
+		}
+		backups = append(backups, backup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return backups, nil
+}
+
+// Delete removes a backup record
+func (r *BackupRepository) Delete(id uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM backups WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	return nil
+}
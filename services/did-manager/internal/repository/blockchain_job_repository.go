@@ -2,32 +2,112 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"time"
+	"strings"
 
 	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
 
 	"github.com/google/uuid"
 )
 
+// jobColumns lists the columns selected for every blockchain job query, in scan order
+const jobColumns = `id, job_type, did_id, user_hash, did, status, retry_count, max_retries, error,
+	created_at, updated_at, processed_at, COALESCE(tx_hash, ''), dry_run, simulation, COALESCE(trace_id, '')`
+
+// scanJob scans a single job row selected with jobColumns
+func scanJob(row rowScanner) (*domain.BlockchainJob, error) {
+	var job domain.BlockchainJob
+	var simulation []byte
+	err := row.Scan(
+		&job.ID,
+		&job.JobType,
+		&job.DIDID,
+		&job.UserHash,
+		&job.DID,
+		&job.Status,
+		&job.RetryCount,
+		&job.MaxRetries,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.ProcessedAt,
+		&job.TxHash,
+		&job.DryRun,
+		&simulation,
+		&job.TraceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if simulation != nil {
+		job.Simulation = &domain.JobSimulation{}
+		if err := json.Unmarshal(simulation, job.Simulation); err != nil {
+			return nil, fmt.Errorf("failed to decode job simulation: %w", err)
+		}
+	}
+
+	return &job, nil
+}
+
 // BlockchainJobRepository implements the blockchain job repository interface
 type BlockchainJobRepository struct {
 	db *sql.DB
+	// clock dates status changes and timeline phases
+	clock clock.Clock
 }
 
 // NewBlockchainJobRepository creates a new blockchain job repository
 func NewBlockchainJobRepository(db *sql.DB) *BlockchainJobRepository {
-	return &BlockchainJobRepository{db: db}
+	return &BlockchainJobRepository{db: db, clock: clock.System}
+}
+
+// SetClock sets the time source dating status changes and phases, clock.System by
+// default, so jobs are dated on the same clock as the services creating them
+func (r *BlockchainJobRepository) SetClock(clk clock.Clock) {
+	r.clock = clk
 }
 
-// Create creates a new blockchain job record
+// jobStatusPhases are the timeline phases recorded by status changes
+var jobStatusPhases = map[domain.JobStatus]domain.JobPhase{
+	domain.JobStatusProcessing: domain.JobPhaseClaimed,
+	domain.JobStatusFailed:     domain.JobPhaseFailed,
+}
+
+// execWithPhase runs a statement modifying a job, returning its ID, and adds a phase to
+// the timeline of the job it matched in the same statement. The result counts the jobs
+// matched.
+func (r *BlockchainJobRepository) execWithPhase(statement string, phase domain.JobPhase, detail string, args ...any) (sql.Result, error) {
+	n := len(args)
+	query := fmt.Sprintf(`
+		WITH job AS (%s RETURNING id)
+		INSERT INTO blockchain_job_phases (job_id, phase, span_id, detail, occurred_at)
+		SELECT id, $%d, $%d, $%d, $%d FROM job
+	`, statement, n+1, n+2, n+3, n+4)
+	return r.db.Exec(query, append(args, phase, domain.NewSpanID(), detail, r.clock.Now())...)
+}
+
+// Create creates a new blockchain job record, starting its trace unless the job
+// already carries one, and records it enqueued
 func (r *BlockchainJobRepository) Create(job *domain.BlockchainJob) error {
-	query := `
-		INSERT INTO blockchain_jobs (id, job_type, did_id, user_hash, did, status, retry_count, max_retries, error, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	if !domain.JobType(job.JobType).Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidJobType, job.JobType)
+	}
+	if !domain.JobStatus(job.Status).Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidJobStatus, job.Status)
+	}
+	if job.TraceID == "" {
+		job.TraceID = domain.NewTraceID()
+	}
+
+	statement := `
+		INSERT INTO blockchain_jobs (id, job_type, did_id, user_hash, did, status, retry_count, max_retries, error, created_at, updated_at, dry_run, trace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := r.execWithPhase(statement, domain.JobPhaseEnqueued, "",
 		job.ID,
 		job.JobType,
 		job.DIDID,
@@ -39,6 +119,8 @@ func (r *BlockchainJobRepository) Create(job *domain.BlockchainJob) error {
 		job.Error,
 		job.CreatedAt,
 		job.UpdatedAt,
+		job.DryRun,
+		job.TraceID,
 	)
 
 	if err != nil {
@@ -50,43 +132,56 @@ func (r *BlockchainJobRepository) Create(job *domain.BlockchainJob) error {
 
 // GetByID retrieves a blockchain job by ID
 func (r *BlockchainJobRepository) GetByID(id uuid.UUID) (*domain.BlockchainJob, error) {
-	query := `
-		SELECT id, job_type, did_id, user_hash, did, status, retry_count, max_retries, error, created_at, updated_at, processed_at
-		FROM blockchain_jobs WHERE id = $1
-	`
-
-	var job domain.BlockchainJob
-	err := r.db.QueryRow(query, id).Scan(
-		&job.ID,
-		&job.JobType,
-		&job.DIDID,
-		&job.UserHash,
-		&job.DID,
-		&job.Status,
-		&job.RetryCount,
-		&job.MaxRetries,
-		&job.Error,
-		&job.CreatedAt,
-		&job.UpdatedAt,
-		&job.ProcessedAt,
-	)
+	query := `SELECT ` + jobColumns + ` FROM blockchain_jobs WHERE id = $1`
 
+	job, err := scanJob(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("blockchain job not found")
+			return nil, domain.ErrJobNotFound
 		}
 		return nil, fmt.Errorf("failed to get blockchain job: %w", err)
 	}
 
-	return &job, nil
+	return job, nil
 }
 
+// batchableJobs matches the DID operation jobs anchored in Sidetree batches
+const batchableJobs = `job_type IN ('register_did', 'update_did', 'revoke_did') AND NOT dry_run`
+
 // GetPendingJobs retrieves pending blockchain jobs
 func (r *BlockchainJobRepository) GetPendingJobs(limit int) ([]*domain.BlockchainJob, error) {
+	return r.getPending("TRUE", limit)
+}
+
+// GetPendingBatchJobs retrieves pending DID operation jobs that are not dry runs
+func (r *BlockchainJobRepository) GetPendingBatchJobs(limit int) ([]*domain.BlockchainJob, error) {
+	return r.getPending(batchableJobs, limit)
+}
+
+// GetPendingUnbatchedJobs retrieves the pending jobs GetPendingBatchJobs leaves out
+func (r *BlockchainJobRepository) GetPendingUnbatchedJobs(limit int) ([]*domain.BlockchainJob, error) {
+	return r.getPending("NOT ("+batchableJobs+")", limit)
+}
+
+// CountPending counts the jobs waiting to be processed or being processed
+func (r *BlockchainJobRepository) CountPending() (int, error) {
+	query := `SELECT COUNT(*) FROM blockchain_jobs WHERE status IN ($1, $2, $3)`
+
+	var count int
+	err := r.db.QueryRow(query, domain.JobStatusPending, domain.JobStatusRetrying, domain.JobStatusProcessing).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+// getPending retrieves pending jobs matching condition, oldest first
+func (r *BlockchainJobRepository) getPending(condition string, limit int) ([]*domain.BlockchainJob, error) {
 	query := `
-		SELECT id, job_type, did_id, user_hash, did, status, retry_count, max_retries, error, created_at, updated_at, processed_at
-		FROM blockchain_jobs 
-		WHERE status IN ($1, $2) AND retry_count < max_retries
+		SELECT ` + jobColumns + `
+		FROM blockchain_jobs
+		WHERE status IN ($1, $2) AND retry_count < max_retries AND ` + condition + `
 		ORDER BY created_at ASC
 		LIMIT $3
 	`
@@ -99,25 +194,11 @@ func (r *BlockchainJobRepository) GetPendingJobs(limit int) ([]*domain.Blockchai
 
 	var jobs []*domain.BlockchainJob
 	for rows.Next() {
-		var job domain.BlockchainJob
-		err := rows.Scan(
-			&job.ID,
-			&job.JobType,
-			&job.DIDID,
-			&job.UserHash,
-			&job.DID,
-			&job.Status,
-			&job.RetryCount,
-			&job.MaxRetries,
-			&job.Error,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-			&job.ProcessedAt,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan blockchain job: %w", err)
 		}
-		jobs = append(jobs, &job)
+		jobs = append(jobs, job)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -127,15 +208,154 @@ func (r *BlockchainJobRepository) GetPendingJobs(limit int) ([]*domain.Blockchai
 	return jobs, nil
 }
 
-// UpdateStatus updates the status of a blockchain job
-func (r *BlockchainJobRepository) UpdateStatus(id uuid.UUID, status string, errorMsg string) error {
+// ListCompletedWithPendingDID retrieves completed DID jobs whose DID is still pending
+func (r *BlockchainJobRepository) ListCompletedWithPendingDID() ([]*domain.BlockchainJob, error) {
 	query := `
+		SELECT ` + jobColumns + `
+		FROM blockchain_jobs
+		WHERE status = $1 AND job_type IN ($2, $3, $4)
+			AND did_id IN (SELECT id FROM dids WHERE status = $5)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query,
+		domain.JobStatusCompleted,
+		domain.JobTypeRegisterDID,
+		domain.JobTypeUpdateDID,
+		domain.JobTypeRevokeDID,
+		domain.DIDStatusPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.BlockchainJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blockchain job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ListByDIDID retrieves the jobs run for a DID, oldest first
+func (r *BlockchainJobRepository) ListByDIDID(didID uuid.UUID) ([]*domain.BlockchainJob, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM blockchain_jobs
+		WHERE did_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DID jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.BlockchainJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blockchain job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// List returns a page of jobs matching req, together with the total number of matches
+func (r *BlockchainJobRepository) List(req *domain.JobListRequest, limit, offset int) ([]*domain.BlockchainJob, int, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.Status != "" {
+		add("status = $%d", string(req.Status))
+	}
+	if req.JobType != "" {
+		add("job_type = $%d", string(req.JobType))
+	}
+	if req.DID != "" {
+		add("did = $%d", req.DID)
+	}
+	if req.CreatedAfter != nil {
+		add("created_at >= $%d", *req.CreatedAfter)
+	}
+	if req.CreatedBefore != nil {
+		add("created_at < $%d", *req.CreatedBefore)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM blockchain_jobs WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count blockchain jobs: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM blockchain_jobs WHERE %s
+		ORDER BY %s, id
+		LIMIT $%d OFFSET $%d
+	`, jobColumns, where, orderBy(req.Sort, "created_at"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query blockchain jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.BlockchainJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan blockchain job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return jobs, total, nil
+}
+
+// UpdateStatus updates the status of a blockchain job; claiming a job and failing it
+// are recorded on its timeline
+func (r *BlockchainJobRepository) UpdateStatus(id uuid.UUID, status domain.JobStatus, errorMsg string) error {
+	if !status.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidJobStatus, status)
+	}
+
+	statement := `
 		UPDATE blockchain_jobs 
-		SET status = $2, error = $3, updated_at = NOW()
+		SET status = $2, error = $3, updated_at = $4
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(query, id, status, errorMsg)
+	var err error
+	if phase, ok := jobStatusPhases[status]; ok {
+		_, err = r.execWithPhase(statement, phase, errorMsg, id, string(status), errorMsg, r.clock.Now())
+	} else {
+		_, err = r.db.Exec(statement, id, string(status), errorMsg, r.clock.Now())
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update blockchain job status: %w", err)
 	}
@@ -143,15 +363,75 @@ func (r *BlockchainJobRepository) UpdateStatus(id uuid.UUID, status string, erro
 	return nil
 }
 
-// MarkCompleted marks a blockchain job as completed
-func (r *BlockchainJobRepository) MarkCompleted(id uuid.UUID) error {
-	query := `
+// Claim marks a pending or retrying job processing and records it claimed. The status
+// is checked in the same statement, so a job canceled after it was listed is not
+// processed.
+func (r *BlockchainJobRepository) Claim(id uuid.UUID) error {
+	statement := `
+		UPDATE blockchain_jobs
+		SET status = $2, error = '', updated_at = $5
+		WHERE id = $1 AND status IN ($3, $4)
+	`
+
+	result, err := r.execWithPhase(statement, domain.JobPhaseClaimed, "",
+		id, domain.JobStatusProcessing, domain.JobStatusPending, domain.JobStatusRetrying, r.clock.Now())
+	if err != nil {
+		return fmt.Errorf("failed to claim blockchain job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrJobNotClaimable
+	}
+
+	return nil
+}
+
+// Cancel marks a pending, retrying or failed job canceled and records the reason on
+// its timeline. Jobs being processed or with a submitted transaction are left alone.
+func (r *BlockchainJobRepository) Cancel(id uuid.UUID, reason string) error {
+	statement := `
+		UPDATE blockchain_jobs
+		SET status = $2, error = $3, updated_at = $8
+		WHERE id = $1 AND status IN ($4, $5, $6)
+			AND NOT EXISTS (
+				SELECT 1 FROM blockchain_job_phases WHERE job_id = $1 AND phase = $7
+			)
+	`
+
+	result, err := r.execWithPhase(statement, domain.JobPhaseCanceled, reason,
+		id, domain.JobStatusCanceled, reason,
+		domain.JobStatusPending, domain.JobStatusRetrying, domain.JobStatusFailed,
+		domain.JobPhaseSubmitted, r.clock.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel blockchain job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrJobNotCancelable
+	}
+
+	return nil
+}
+
+// MarkCompleted marks a blockchain job as completed with the transaction it submitted,
+// which is confirmed by then
+func (r *BlockchainJobRepository) MarkCompleted(id uuid.UUID, txHash string) error {
+	statement := `
 		UPDATE blockchain_jobs 
-		SET status = $2, processed_at = NOW(), updated_at = NOW()
+		SET status = $2, tx_hash = NULLIF($3, ''), processed_at = $4, updated_at = $4
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(query, id, domain.JobStatusCompleted)
+	_, err := r.execWithPhase(statement, domain.JobPhaseConfirmed, txHash, id, domain.JobStatusCompleted, txHash, r.clock.Now())
 	if err != nil {
 		return fmt.Errorf("failed to mark blockchain job completed: %w", err)
 	}
@@ -159,15 +439,44 @@ func (r *BlockchainJobRepository) MarkCompleted(id uuid.UUID) error {
 	return nil
 }
 
+// RecordSimulation stores the outcome of a dry-run job and marks it simulated
+func (r *BlockchainJobRepository) RecordSimulation(id uuid.UUID, simulation *domain.JobSimulation) error {
+	encoded, err := json.Marshal(simulation)
+	if err != nil {
+		return fmt.Errorf("failed to encode job simulation: %w", err)
+	}
+
+	statement := `
+		UPDATE blockchain_jobs
+		SET status = $2, simulation = $3, processed_at = $4, updated_at = $4
+		WHERE id = $1
+	`
+
+	result, err := r.execWithPhase(statement, domain.JobPhaseSimulated, "", id, domain.JobStatusSimulated, encoded, r.clock.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record job simulation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
 // IncrementRetryCount increments the retry count for a blockchain job
 func (r *BlockchainJobRepository) IncrementRetryCount(id uuid.UUID) error {
-	query := `
+	statement := `
 		UPDATE blockchain_jobs 
-		SET retry_count = retry_count + 1, status = $2, updated_at = NOW()
+		SET retry_count = retry_count + 1, status = $2, updated_at = $3
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(query, id, domain.JobStatusRetrying)
+	_, err := r.execWithPhase(statement, domain.JobPhaseRetrying, "", id, domain.JobStatusRetrying, r.clock.Now())
 	if err != nil {
 		return fmt.Errorf("failed to increment retry count: %w", err)
 	}
@@ -175,19 +484,58 @@ func (r *BlockchainJobRepository) IncrementRetryCount(id uuid.UUID) error {
 	return nil
 }
 
-// CleanupCompletedJobs removes old completed jobs
-func (r *BlockchainJobRepository) CleanupCompletedJobs(daysOld int) error {
-	cutoffDate := time.Now().AddDate(0, 0, -daysOld)
-
+// RecordPhase adds a phase to a job's timeline
+func (r *BlockchainJobRepository) RecordPhase(id uuid.UUID, phase domain.JobPhase, spanID, detail string) error {
 	query := `
-		DELETE FROM blockchain_jobs 
-		WHERE status = $1 AND processed_at < $2
+		INSERT INTO blockchain_job_phases (job_id, phase, span_id, detail, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
 	`
 
-	_, err := r.db.Exec(query, domain.JobStatusCompleted, cutoffDate)
+	_, err := r.db.Exec(query, id, phase, spanID, detail, r.clock.Now())
 	if err != nil {
-		return fmt.Errorf("failed to cleanup completed jobs: %w", err)
+		return fmt.Errorf("failed to record job phase: %w", err)
 	}
 
 	return nil
 }
+
+// ListPhases retrieves a job's timeline, oldest phase first
+func (r *BlockchainJobRepository) ListPhases(id uuid.UUID) ([]*domain.JobPhaseEvent, error) {
+	return listPhases(r.db, id)
+}
+
+// queryer runs queries on the database or within a transaction
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// listPhases retrieves a job's timeline, oldest phase first
+func listPhases(q queryer, id uuid.UUID) ([]*domain.JobPhaseEvent, error) {
+	query := `
+		SELECT phase, span_id, detail, occurred_at
+		FROM blockchain_job_phases
+		WHERE job_id = $1
+		ORDER BY occurred_at ASC, id ASC
+	`
+
+	rows, err := q.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job phases: %w", err)
+	}
+	defer rows.Close()
+
+	var phases []*domain.JobPhaseEvent
+	for rows.Next() {
+		var phase domain.JobPhaseEvent
+		if err := rows.Scan(&phase.Phase, &phase.SpanID, &phase.Detail, &phase.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job phase: %w", err)
+		}
+		phases = append(phases, &phase)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return phases, nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"did-manager/internal/domain"
+)
+
+// bulkRevocationQuery selects revocations with their progress counts; callers append
+// their WHERE clause before bulkRevocationGroup
+const bulkRevocationQuery = `
+	SELECT r.id, r.reason, r.status, r.created_at, r.executed_at, r.completed_at, r.canceled_at,
+		COUNT(i.target),
+		COUNT(i.target) FILTER (WHERE i.status = 'pending'),
+		COUNT(i.target) FILTER (WHERE i.status = 'revoked'),
+		COUNT(i.target) FILTER (WHERE i.status = 'skipped'),
+		COUNT(i.target) FILTER (WHERE i.status = 'failed')
+	FROM bulk_revocations r
+	LEFT JOIN bulk_revocation_items i ON i.revocation_id = r.id
+`
+
+// bulkRevocationGroup groups the rows of bulkRevocationQuery by revocation
+const bulkRevocationGroup = ` GROUP BY r.id`
+
+// scanBulkRevocation scans a single revocation row selected with bulkRevocationQuery
+func scanBulkRevocation(row rowScanner) (*domain.BulkRevocation, error) {
+	var revocation domain.BulkRevocation
+	var executedAt, completedAt, canceledAt sql.NullTime
+	err := row.Scan(
+		&revocation.ID,
+		&revocation.Reason,
+		&revocation.Status,
+		&revocation.CreatedAt,
+		&executedAt,
+		&completedAt,
+		&canceledAt,
+		&revocation.Total,
+		&revocation.Pending,
+		&revocation.Revoked,
+		&revocation.Skipped,
+		&revocation.Failed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if executedAt.Valid {
+		revocation.ExecutedAt = &executedAt.Time
+	}
+	if completedAt.Valid {
+		revocation.CompletedAt = &completedAt.Time
+	}
+	if canceledAt.Valid {
+		revocation.CanceledAt = &canceledAt.Time
+	}
+	return &revocation, nil
+}
+
+// BulkRevocationRepository implements the bulk revocation repository interface
+type BulkRevocationRepository struct {
+	db *sql.DB
+}
+
+// NewBulkRevocationRepository creates a new bulk revocation repository
+func NewBulkRevocationRepository(db *sql.DB) *BulkRevocationRepository {
+	return &BulkRevocationRepository{db: db}
+}
+
+// Create stores a staged revocation with its targets in one transaction. Targets listed
+// twice are stored once.
+func (r *BulkRevocationRepository) Create(revocation *domain.BulkRevocation, items []*domain.BulkRevocationItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO bulk_revocations (id, reason, status, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, revocation.ID, revocation.Reason, revocation.Status, revocation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk revocation: %w", err)
+	}
+
+	targetTypes := make([]string, len(items))
+	targets := make([]string, len(items))
+	for i, item := range items {
+		targetTypes[i] = item.TargetType
+		targets[i] = item.Target
+	}
+	_, err = tx.Exec(`
+		INSERT INTO bulk_revocation_items (revocation_id, target_type, target, status)
+		SELECT $1, t.target_type, t.target, 'pending'
+		FROM unnest($2::text[], $3::text[]) AS t(target_type, target)
+		ON CONFLICT DO NOTHING
+	`, revocation.ID, pq.Array(targetTypes), pq.Array(targets))
+	if err != nil {
+		return fmt.Errorf("failed to store bulk revocation targets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bulk revocation: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a revocation with its progress counts
+func (r *BulkRevocationRepository) Get(id uuid.UUID) (*domain.BulkRevocation, error) {
+	revocation, err := scanBulkRevocation(r.db.QueryRow(bulkRevocationQuery+`WHERE r.id = $1`+bulkRevocationGroup, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBulkRevocationNotFound
+		}
+		return nil, fmt.Errorf("failed to get bulk revocation: %w", err)
+	}
+
+	return revocation, nil
+}
+
+// List retrieves the most recent revocations with their progress counts
+func (r *BulkRevocationRepository) List(limit int) ([]*domain.BulkRevocation, error) {
+	return r.query(bulkRevocationQuery+bulkRevocationGroup+` ORDER BY r.created_at DESC LIMIT $1`, limit)
+}
+
+// ListByStatus retrieves the revocations in a stage, oldest first
+func (r *BulkRevocationRepository) ListByStatus(status domain.BulkRevocationStatus) ([]*domain.BulkRevocation, error) {
+	return r.query(bulkRevocationQuery+`WHERE r.status = $1`+bulkRevocationGroup+` ORDER BY r.created_at`, status)
+}
+
+// query retrieves the revocations selected by a bulkRevocationQuery
+func (r *BulkRevocationRepository) query(query string, args ...any) ([]*domain.BulkRevocation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bulk revocations: %w", err)
+	}
+	defer rows.Close()
+
+	revocations := []*domain.BulkRevocation{}
+	for rows.Next() {
+		revocation, err := scanBulkRevocation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk revocation: %w", err)
+		}
+		revocations = append(revocations, revocation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return revocations, nil
+}
+
+// Transition moves a revocation from one stage to another, stamping the time it
+// reached the new one. The check and the update are one statement, so a revocation
+// cannot be both canceled and executed.
+func (r *BulkRevocationRepository) Transition(id uuid.UUID, from, to domain.BulkRevocationStatus, at time.Time) error {
+	var column string
+	switch to {
+	case domain.BulkRevocationExecuting:
+		column = "executed_at"
+	case domain.BulkRevocationCompleted:
+		column = "completed_at"
+	case domain.BulkRevocationCanceled:
+		column = "canceled_at"
+	default:
+		return fmt.Errorf("%w: cannot move to %s", domain.ErrBulkRevocationState, to)
+	}
+
+	query := `UPDATE bulk_revocations SET status = $3, ` + column + ` = $4 WHERE id = $1 AND status = $2`
+	result, err := r.db.Exec(query, id, from, to, at)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk revocation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		if _, err := r.Get(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: it is not %s", domain.ErrBulkRevocationState, from)
+	}
+
+	return nil
+}
+
+// ListPendingItems retrieves up to limit unprocessed targets of a kind
+func (r *BulkRevocationRepository) ListPendingItems(id uuid.UUID, targetType domain.BulkRevocationTarget, limit int) ([]*domain.BulkRevocationItem, error) {
+	query := `
+		SELECT revocation_id, target_type, target, status, error, job_id, processed_at
+		FROM bulk_revocation_items
+		WHERE revocation_id = $1 AND target_type = $2 AND status = 'pending'
+		ORDER BY target
+		LIMIT $3
+	`
+	return r.queryItems(query, id, targetType, limit)
+}
+
+// ListFailedItems retrieves the targets that failed to be revoked
+func (r *BulkRevocationRepository) ListFailedItems(id uuid.UUID) ([]*domain.BulkRevocationItem, error) {
+	query := `
+		SELECT revocation_id, target_type, target, status, error, job_id, processed_at
+		FROM bulk_revocation_items
+		WHERE revocation_id = $1 AND status = 'failed'
+		ORDER BY processed_at
+	`
+	return r.queryItems(query, id)
+}
+
+// queryItems retrieves the targets selected by query
+func (r *BulkRevocationRepository) queryItems(query string, args ...any) ([]*domain.BulkRevocationItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bulk revocation targets: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*domain.BulkRevocationItem{}
+	for rows.Next() {
+		var item domain.BulkRevocationItem
+		var jobID uuid.NullUUID
+		var processedAt sql.NullTime
+		if err := rows.Scan(&item.RevocationID, &item.TargetType, &item.Target, &item.Status, &item.Error, &jobID, &processedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bulk revocation target: %w", err)
+		}
+		if jobID.Valid {
+			item.JobID = &jobID.UUID
+		}
+		if processedAt.Valid {
+			item.ProcessedAt = &processedAt.Time
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// RecordItem records the outcome of revoking a target
+func (r *BulkRevocationRepository) RecordItem(item *domain.BulkRevocationItem) error {
+	query := `
+		UPDATE bulk_revocation_items
+		SET status = $4, error = $5, job_id = $6, processed_at = $7
+		WHERE revocation_id = $1 AND target_type = $2 AND target = $3
+	`
+
+	_, err := r.db.Exec(query, item.RevocationID, item.TargetType, item.Target, item.Status, item.Error, item.JobID, item.ProcessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record bulk revocation target: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+)
+
+// claimColumns lists the columns selected for every claim query, in scan order
+const claimColumns = `id, did_id, claim_type, scheme, params, commitment, pepper_version, version, credential_id, verified_by, verified_at`
+
+// scanClaim scans a single claim row selected with claimColumns
+func scanClaim(row rowScanner) (*domain.ClaimCommitment, error) {
+	var claim domain.ClaimCommitment
+	var credentialID uuid.NullUUID
+	err := row.Scan(
+		&claim.ID,
+		&claim.DIDID,
+		&claim.Type,
+		&claim.Scheme,
+		&claim.Params,
+		&claim.Commitment,
+		&claim.PepperVersion,
+		&claim.Version,
+		&credentialID,
+		&claim.VerifiedBy,
+		&claim.VerifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if credentialID.Valid {
+		claim.CredentialID = &credentialID.UUID
+	}
+	return &claim, nil
+}
+
+// ClaimRepository implements the claim repository interface
+type ClaimRepository struct {
+	db *sql.DB
+}
+
+// NewClaimRepository creates a new claim repository
+func NewClaimRepository(db *sql.DB) *ClaimRepository {
+	return &ClaimRepository{db: db}
+}
+
+// Append stores a claim commitment as the DID's next claims version and touches the
+// DID's updated_at. The DID's row is locked, so concurrent claims get distinct versions.
+func (r *ClaimRepository) Append(claim *domain.ClaimCommitment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked uuid.UUID
+	if err := tx.QueryRow(`SELECT id FROM dids WHERE id = $1 FOR UPDATE`, claim.DIDID).Scan(&locked); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrDIDNotFound
+		}
+		return fmt.Errorf("failed to lock DID: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO did_claims (id, did_id, claim_type, scheme, params, commitment, pepper_version, version, verified_by, verified_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, COALESCE(MAX(version), 0) + 1, $8, $9
+		FROM did_claims WHERE did_id = $2
+		RETURNING version
+	`,
+		claim.ID,
+		claim.DIDID,
+		claim.Type,
+		claim.Scheme,
+		claim.Params,
+		claim.Commitment,
+		claim.PepperVersion,
+		claim.VerifiedBy,
+		claim.VerifiedAt,
+	).Scan(&claim.Version)
+	if err != nil {
+		return fmt.Errorf("failed to append claim: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE dids SET updated_at = $2 WHERE id = $1`, claim.DIDID, claim.VerifiedAt); err != nil {
+		return fmt.Errorf("failed to touch DID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit claim: %w", err)
+	}
+
+	return nil
+}
+
+// SetCredential records the credential issued for a claim
+func (r *ClaimRepository) SetCredential(id, credentialID uuid.UUID) error {
+	result, err := r.db.Exec(`UPDATE did_claims SET credential_id = $2 WHERE id = $1`, id, credentialID)
+	if err != nil {
+		return fmt.Errorf("failed to set claim credential: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrClaimNotFound
+	}
+
+	return nil
+}
+
+// ListLatest lists the latest commitment of each type added to a DID, by type
+func (r *ClaimRepository) ListLatest(didID uuid.UUID) ([]*domain.ClaimCommitment, error) {
+	query := `
+		SELECT DISTINCT ON (claim_type) ` + claimColumns + `
+		FROM did_claims
+		WHERE did_id = $1
+		ORDER BY claim_type, version DESC
+	`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query claims: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []*domain.ClaimCommitment
+	for rows.Next() {
+		claim, err := scanClaim(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan claim: %w", err)
+		}
+		claims = append(claims, claim)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return claims, nil
+}
+
+// Latest returns the latest commitment of a type added to a DID
+func (r *ClaimRepository) Latest(didID uuid.UUID, claimType string) (*domain.ClaimCommitment, error) {
+	query := `
+		SELECT ` + claimColumns + `
+		FROM did_claims
+		WHERE did_id = $1 AND claim_type = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	claim, err := scanClaim(r.db.QueryRow(query, didID, claimType))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrClaimNotFound
+		}
+		return nil, fmt.Errorf("failed to get claim: %w", err)
+	}
+
+	return claim, nil
+}
+
+// Version returns the DID's claims version, zero when no claim was added
+func (r *ClaimRepository) Version(didID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM did_claims WHERE did_id = $1`, didID).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get claims version: %w", err)
+	}
+
+	return version, nil
+}
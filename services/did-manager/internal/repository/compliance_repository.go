@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// ComplianceRepository implements the compliance repository interface
+type ComplianceRepository struct {
+	db *sql.DB
+}
+
+// NewComplianceRepository creates a new compliance repository
+func NewComplianceRepository(db *sql.DB) *ComplianceRepository {
+	return &ComplianceRepository{db: db}
+}
+
+// Summarize fills the activity counts and anchoring transactions of [from, to)
+func (r *ComplianceRepository) Summarize(from, to time.Time) (*domain.ComplianceReport, error) {
+	report := &domain.ComplianceReport{
+		From:    from,
+		To:      to,
+		Anchors: []*domain.AnchoringTransaction{},
+	}
+
+	counts := `
+		SELECT
+			(SELECT COUNT(*) FROM dids WHERE created_at >= $1 AND created_at < $2),
+			(SELECT COUNT(*) FROM blockchain_jobs
+				WHERE job_type = $3 AND status = $4 AND processed_at >= $1 AND processed_at < $2),
+			(SELECT COUNT(*) FROM credentials WHERE issued_at >= $1 AND issued_at < $2),
+			(SELECT COUNT(*) FROM credentials WHERE revoked_at >= $1 AND revoked_at < $2)
+	`
+	err := r.db.QueryRow(counts, from, to, domain.JobTypeRevokeDID, domain.JobStatusCompleted).Scan(
+		&report.DIDs.Created,
+		&report.DIDs.Revoked,
+		&report.Credentials.Issued,
+		&report.Credentials.Revoked,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count activity: %w", err)
+	}
+
+	sessions := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'verified'),
+			COUNT(*) FILTER (WHERE status = 'rejected'),
+			COUNT(*) FILTER (WHERE status = 'expired' OR (status IN ('pending', 'scanned') AND expires_at < NOW()))
+		FROM verification_sessions
+		WHERE created_at >= $1 AND created_at < $2
+	`
+	err = r.db.QueryRow(sessions, from, to).Scan(
+		&report.Verifications.Sessions,
+		&report.Verifications.Verified,
+		&report.Verifications.Rejected,
+		&report.Verifications.Expired,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count verification sessions: %w", err)
+	}
+
+	anchors := `
+		SELECT job_type, CASE WHEN job_type = $3 THEN user_hash ELSE did END, tx_hash, processed_at
+		FROM blockchain_jobs
+		WHERE status = $4 AND tx_hash IS NOT NULL AND processed_at >= $1 AND processed_at < $2
+		UNION ALL
+		SELECT $5::VARCHAR, root, tx_hash, anchored_at
+		FROM revocation_batches
+		WHERE status = $6 AND tx_hash IS NOT NULL AND anchored_at >= $1 AND anchored_at < $2
+		ORDER BY 4
+	`
+	rows, err := r.db.Query(anchors, from, to, domain.JobTypeNotarize, domain.JobStatusCompleted, domain.AnchorKindRevocationRoot, domain.RevocationBatchAnchored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchoring transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var anchor domain.AnchoringTransaction
+		if err := rows.Scan(&anchor.Kind, &anchor.Subject, &anchor.TxHash, &anchor.AnchoredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan anchoring transaction: %w", err)
+		}
+		report.Anchors = append(report.Anchors, &anchor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return report, nil
+}
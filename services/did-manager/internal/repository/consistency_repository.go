@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ConsistencyRepository implements the consistency repository interface against the
+// PostgreSQL write-ahead log functions
+type ConsistencyRepository struct {
+	db *sql.DB
+	// subscription is the logical replication subscription a standby's database
+	// applies the primary's changes through; empty without one
+	subscription string
+}
+
+// NewConsistencyRepository creates a new consistency repository. subscription names the
+// logical replication subscription of a standby's database, if any.
+func NewConsistencyRepository(db *sql.DB, subscription string) *ConsistencyRepository {
+	return &ConsistencyRepository{db: db, subscription: subscription}
+}
+
+// CurrentLSN returns the current write-ahead log position of the primary
+func (r *ConsistencyRepository) CurrentLSN() (string, error) {
+	var lsn string
+	if err := r.db.QueryRow(`SELECT pg_current_wal_lsn()::text`).Scan(&lsn); err != nil {
+		return "", fmt.Errorf("failed to get WAL position: %w", err)
+	}
+
+	return lsn, nil
+}
+
+// Replayed reports whether the database has applied the primary's writes up to lsn: a
+// streaming replica once it replayed the position, a standby subscribed through
+// logical replication once its subscription confirmed it, and the primary itself
+// always
+func (r *ConsistencyRepository) Replayed(lsn string) (bool, error) {
+	query := `
+		SELECT CASE
+			WHEN pg_is_in_recovery() THEN COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, false)
+			WHEN EXISTS (SELECT 1 FROM pg_subscription WHERE subname = $2 AND subenabled) THEN COALESCE((
+				SELECT MAX(st.latest_end_lsn) >= $1::pg_lsn
+				FROM pg_subscription s
+				JOIN pg_stat_subscription st ON st.subid = s.oid AND st.relid IS NULL
+				WHERE s.subname = $2
+			), false)
+			ELSE pg_current_wal_lsn() >= $1::pg_lsn
+		END
+	`
+
+	var replayed bool
+	if err := r.db.QueryRow(query, lsn, r.subscription).Scan(&replayed); err != nil {
+		return false, fmt.Errorf("failed to check WAL replay: %w", err)
+	}
+
+	return replayed, nil
+}
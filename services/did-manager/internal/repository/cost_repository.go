@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CostRepository implements the cost accounting repository interface
+type CostRepository struct {
+	db *sql.DB
+}
+
+// NewCostRepository creates a new cost repository
+func NewCostRepository(db *sql.DB) *CostRepository {
+	return &CostRepository{db: db}
+}
+
+// AddUsage adds requests and units to the daily totals of API keys in one transaction
+func (r *CostRepository) AddUsage(usage []*domain.CostUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO cost_usage (api_key_id, tenant, cost_class, day, requests, units)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (api_key_id, cost_class, day) DO UPDATE
+		SET requests = cost_usage.requests + EXCLUDED.requests,
+			units = cost_usage.units + EXCLUDED.units
+	`
+
+	for _, u := range usage {
+		if _, err := tx.Exec(query, u.APIKeyID, u.Tenant, u.Class, u.Day, u.Requests, u.Units); err != nil {
+			return fmt.Errorf("failed to record cost usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cost usage: %w", err)
+	}
+
+	return nil
+}
+
+// Units sums the units an API key drew since the given day
+func (r *CostRepository) Units(apiKeyID uuid.UUID, since time.Time) (int, error) {
+	query := `SELECT COALESCE(SUM(units), 0) FROM cost_usage WHERE api_key_id = $1 AND day >= $2`
+
+	var units int
+	if err := r.db.QueryRow(query, apiKeyID, since).Scan(&units); err != nil {
+		return 0, fmt.Errorf("failed to sum cost units: %w", err)
+	}
+
+	return units, nil
+}
+
+// TenantUnits sums the units a tenant and its sub-keys drew since the given day
+func (r *CostRepository) TenantUnits(tenant string, since time.Time) (int, error) {
+	query := `SELECT COALESCE(SUM(units), 0) FROM cost_usage WHERE tenant = $1 AND day >= $2`
+
+	var units int
+	if err := r.db.QueryRow(query, tenant, since).Scan(&units); err != nil {
+		return 0, fmt.Errorf("failed to sum tenant cost units: %w", err)
+	}
+
+	return units, nil
+}
+
+// Summarize totals usage per API key and class over [from, to), optionally for one
+// tenant and its sub-keys
+func (r *CostRepository) Summarize(from, to time.Time, tenant string) ([]*domain.CostUsage, error) {
+	query := `
+		SELECT api_key_id, tenant, cost_class, SUM(requests), SUM(units)
+		FROM cost_usage
+		WHERE day >= $1 AND day < $2 AND ($3 = '' OR tenant = $3)
+		GROUP BY api_key_id, tenant, cost_class
+		ORDER BY tenant, api_key_id, cost_class
+	`
+
+	rows, err := r.db.Query(query, from, to, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize cost usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*domain.CostUsage
+	for rows.Next() {
+		var u domain.CostUsage
+		if err := rows.Scan(&u.APIKeyID, &u.Tenant, &u.Class, &u.Requests, &u.Units); err != nil {
+			return nil, fmt.Errorf("failed to scan cost usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return usage, nil
+}
+
+// GetBudget retrieves an API key's budget, or nil when it has none
+func (r *CostRepository) GetBudget(apiKeyID uuid.UUID) (*domain.CostBudget, error) {
+	query := `SELECT api_key_id, monthly_units, updated_at FROM cost_budgets WHERE api_key_id = $1`
+
+	var budget domain.CostBudget
+	err := r.db.QueryRow(query, apiKeyID).Scan(&budget.APIKeyID, &budget.MonthlyUnits, &budget.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cost budget: %w", err)
+	}
+
+	return &budget, nil
+}
+
+// ListBudgets retrieves every budget
+func (r *CostRepository) ListBudgets() ([]*domain.CostBudget, error) {
+	query := `SELECT api_key_id, monthly_units, updated_at FROM cost_budgets`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []*domain.CostBudget
+	for rows.Next() {
+		var budget domain.CostBudget
+		if err := rows.Scan(&budget.APIKeyID, &budget.MonthlyUnits, &budget.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cost budget: %w", err)
+		}
+		budgets = append(budgets, &budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// UpsertBudget sets an API key's budget
+func (r *CostRepository) UpsertBudget(budget *domain.CostBudget) error {
+	query := `
+		INSERT INTO cost_budgets (api_key_id, monthly_units, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (api_key_id) DO UPDATE
+		SET monthly_units = EXCLUDED.monthly_units, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Exec(query, budget.APIKeyID, budget.MonthlyUnits, budget.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save cost budget: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBudget removes an API key's budget
+func (r *CostRepository) DeleteBudget(apiKeyID uuid.UUID) error {
+	query := `DELETE FROM cost_budgets WHERE api_key_id = $1`
+
+	result, err := r.db.Exec(query, apiKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cost budget: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrCostBudgetNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// credentialColumns lists the columns selected for every credential query, in scan order
+const credentialColumns = `id, issuer_did, subject_did, type, status, issued_at, expires_at, revoked_at, COALESCE(tenant, ''), document`
+
+// scanCredential scans a single credential row selected with credentialColumns
+func scanCredential(row rowScanner) (*domain.Credential, error) {
+	var credential domain.Credential
+	var document []byte
+	err := row.Scan(
+		&credential.ID,
+		&credential.IssuerDID,
+		&credential.SubjectDID,
+		&credential.Type,
+		&credential.Status,
+		&credential.IssuedAt,
+		&credential.ExpiresAt,
+		&credential.RevokedAt,
+		&credential.Tenant,
+		&document,
+	)
+	if err != nil {
+		return nil, err
+	}
+	credential.Document = document
+	return &credential, nil
+}
+
+// CredentialRepository implements the credential repository interface
+type CredentialRepository struct {
+	db *sql.DB
+}
+
+// NewCredentialRepository creates a new credential repository
+func NewCredentialRepository(db *sql.DB) *CredentialRepository {
+	return &CredentialRepository{db: db}
+}
+
+// Create stores an issued credential
+func (r *CredentialRepository) Create(credential *domain.Credential) error {
+	query := `
+		INSERT INTO credentials (id, issuer_did, subject_did, type, status, issued_at, expires_at, tenant, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+	`
+
+	_, err := r.db.Exec(query,
+		credential.ID,
+		credential.IssuerDID,
+		credential.SubjectDID,
+		credential.Type,
+		credential.Status,
+		credential.IssuedAt,
+		credential.ExpiresAt,
+		credential.Tenant,
+		[]byte(credential.Document),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create credential: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a credential by ID
+func (r *CredentialRepository) GetByID(id uuid.UUID) (*domain.Credential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE id = $1`
+
+	credential, err := scanCredential(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+
+	return credential, nil
+}
+
+// ListBySubjects retrieves all credentials issued to any of the given subject DIDs
+func (r *CredentialRepository) ListBySubjects(subjectDIDs []string) ([]*domain.Credential, error) {
+	query := `
+		SELECT ` + credentialColumns + `
+		FROM credentials WHERE subject_did = ANY($1)
+		ORDER BY issued_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(subjectDIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []*domain.Credential
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// Search returns a page of credentials issued by issuerDID matching req
+func (r *CredentialRepository) Search(issuerDID string, req *domain.CredentialSearchRequest, limit, offset int) ([]*domain.Credential, int, error) {
+	conditions := []string{"issuer_did = $1"}
+	args := []any{issuerDID}
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.Type != "" {
+		add("type = $%d", req.Type)
+	}
+	if req.SubjectDID != "" {
+		add("subject_did = $%d", req.SubjectDID)
+	}
+	if req.Tenant != "" {
+		add("tenant = $%d", req.Tenant)
+	}
+	if req.Status != "" {
+		add("status = $%d", req.Status)
+	}
+	if req.IssuedAfter != nil {
+		add("issued_at >= $%d", *req.IssuedAfter)
+	}
+	if req.IssuedBefore != nil {
+		add("issued_at < $%d", *req.IssuedBefore)
+	}
+	if req.ExpiresAfter != nil {
+		add("expires_at >= $%d", *req.ExpiresAfter)
+	}
+	if req.ExpiresBefore != nil {
+		add("expires_at < $%d", *req.ExpiresBefore)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM credentials WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count credentials: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM credentials WHERE %s
+		ORDER BY %s, id
+		LIMIT $%d OFFSET $%d
+	`, credentialColumns, where, orderBy(req.Sort, "issued_at"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []*domain.Credential
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return credentials, total, nil
+}
+
+// orderBy translates a sort parameter on column, either column for ascending order or
+// -column, into an ORDER BY term, descending unless ascending was asked for
+func orderBy(sort, column string) string {
+	if sort == column {
+		return column + " ASC"
+	}
+	return column + " DESC"
+}
+
+// Revoke marks an active credential as revoked
+func (r *CredentialRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE credentials SET status = $2, revoked_at = $3 WHERE id = $1 AND status = $4`
+
+	result, err := r.db.Exec(query, id, domain.CredentialStatusRevoked, revokedAt, domain.CredentialStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to revoke credential: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrCredentialNotFound
+	}
+
+	return nil
+}
+
+// ResolveReview moves a credential out of pending review
+func (r *CredentialRepository) ResolveReview(id uuid.UUID, status domain.CredentialStatus) error {
+	query := `UPDATE credentials SET status = $2 WHERE id = $1 AND status = $3`
+
+	result, err := r.db.Exec(query, id, status, domain.CredentialStatusPendingReview)
+	if err != nil {
+		return fmt.Errorf("failed to resolve credential review: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrReviewClosed
+	}
+
+	return nil
+}
+
+// ListExpiring lists active credentials expiring before the given time whose holders
+// have not yet been notified
+func (r *CredentialRepository) ListExpiring(before time.Time, limit int) ([]*domain.Credential, error) {
+	query := `
+		SELECT ` + credentialColumns + `
+		FROM credentials
+		WHERE status = $1 AND expires_at IS NOT NULL AND expires_at <= $2 AND expiry_notified_at IS NULL
+		ORDER BY expires_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(query, domain.CredentialStatusActive, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []*domain.Credential
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// MarkExpiryNotified records that a credential's expiry was handled
+func (r *CredentialRepository) MarkExpiryNotified(id uuid.UUID, notifiedAt time.Time, renewedByID *uuid.UUID) error {
+	query := `UPDATE credentials SET expiry_notified_at = $2, renewed_by_id = $3 WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, notifiedAt, renewedByID); err != nil {
+		return fmt.Errorf("failed to mark credential expiry notified: %w", err)
+	}
+
+	return nil
+}
+
+// ListUnbatchedRevoked lists revoked credentials not yet assigned to a revocation batch
+func (r *CredentialRepository) ListUnbatchedRevoked() ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM credentials
+		WHERE status = $1 AND revocation_batch_id IS NULL
+		ORDER BY revoked_at ASC
+	`
+
+	return r.queryIDs(query, domain.CredentialStatusRevoked)
+}
+
+// ListRevokedThroughBatch lists credentials revoked in batches with a sequence up to
+// and including sequence; a negative sequence includes every batch
+func (r *CredentialRepository) ListRevokedThroughBatch(sequence int64) ([]uuid.UUID, error) {
+	query := `
+		SELECT c.id FROM credentials c
+		JOIN revocation_batches b ON b.id = c.revocation_batch_id
+		WHERE $1::bigint < 0 OR b.sequence <= $1::bigint
+	`
+
+	return r.queryIDs(query, sequence)
+}
+
+// queryIDs runs a query selecting a single UUID column
+func (r *CredentialRepository) queryIDs(query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan credential ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return ids, nil
+}
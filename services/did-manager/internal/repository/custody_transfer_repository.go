@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// custodyTransferColumns lists the columns selected for every custody transfer query, in scan order
+const custodyTransferColumns = `id, did_id, did, user_id, client_id, challenge, status, previous_key_fingerprint,
+	COALESCE(new_public_key, ''), COALESCE(key_algorithm, ''), created_at, expires_at, completed_at`
+
+// scanCustodyTransfer scans a single transfer row selected with custodyTransferColumns
+func scanCustodyTransfer(row rowScanner) (*domain.CustodyTransfer, error) {
+	var transfer domain.CustodyTransfer
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.DIDID,
+		&transfer.Did,
+		&transfer.UserID,
+		&transfer.ClientID,
+		&transfer.Challenge,
+		&transfer.Status,
+		&transfer.PreviousKeyFingerprint,
+		&transfer.NewPublicKey,
+		&transfer.KeyAlgorithm,
+		&transfer.CreatedAt,
+		&transfer.ExpiresAt,
+		&transfer.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// CustodyTransferRepository implements the custody transfer repository interface
+type CustodyTransferRepository struct {
+	db *sql.DB
+}
+
+// NewCustodyTransferRepository creates a new custody transfer repository
+func NewCustodyTransferRepository(db *sql.DB) *CustodyTransferRepository {
+	return &CustodyTransferRepository{db: db}
+}
+
+// Create stores a new custody transfer
+func (r *CustodyTransferRepository) Create(transfer *domain.CustodyTransfer) error {
+	query := `
+		INSERT INTO custody_transfers (id, did_id, did, user_id, client_id, challenge, status, previous_key_fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.Exec(query,
+		transfer.ID,
+		transfer.DIDID,
+		transfer.Did,
+		transfer.UserID,
+		transfer.ClientID,
+		transfer.Challenge,
+		transfer.Status,
+		transfer.PreviousKeyFingerprint,
+		transfer.CreatedAt,
+		transfer.ExpiresAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create custody transfer: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a custody transfer by ID
+func (r *CustodyTransferRepository) GetByID(id uuid.UUID) (*domain.CustodyTransfer, error) {
+	query := `SELECT ` + custodyTransferColumns + ` FROM custody_transfers WHERE id = $1`
+
+	transfer, err := scanCustodyTransfer(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCustodyTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get custody transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// Complete rotates the DID to the transfer's new key, wiping the stored private key,
+// and marks the transfer completed in one transaction
+func (r *CustodyTransferRepository) Complete(transfer *domain.CustodyTransfer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE custody_transfers
+		SET status = $2, new_public_key = $3, key_algorithm = $4, completed_at = $5
+		WHERE id = $1 AND status = 'pending'
+	`,
+		transfer.ID,
+		string(domain.CustodyTransferStatusCompleted),
+		transfer.NewPublicKey,
+		transfer.KeyAlgorithm,
+		transfer.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete custody transfer: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrCustodyTransferClosed
+	}
+
+	result, err = tx.Exec(`
+		UPDATE dids
+		SET public_key = $2, key_algorithm = $3, custodial = FALSE, updated_at = NOW()
+		WHERE id = $1 AND custodial
+	`,
+		transfer.DIDID,
+		transfer.NewPublicKey,
+		transfer.KeyAlgorithm,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rotate DID key: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrCustodyTransferClosed
+	}
+
+	// The owner's key now serves every purpose; the purpose keys held for the DID go
+	// with its private key
+	if _, err := tx.Exec(`DELETE FROM did_purpose_keys WHERE did_id = $1`, transfer.DIDID); err != nil {
+		return fmt.Errorf("failed to delete purpose keys: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit custody transfer: %w", err)
+	}
+
+	transfer.Status = string(domain.CustodyTransferStatusCompleted)
+	return nil
+}
+
+// MarkExpired marks a pending custody transfer as expired
+func (r *CustodyTransferRepository) MarkExpired(id uuid.UUID) error {
+	query := `UPDATE custody_transfers SET status = $2 WHERE id = $1 AND status = 'pending'`
+
+	_, err := r.db.Exec(query, id, string(domain.CustodyTransferStatusExpired))
+	if err != nil {
+		return fmt.Errorf("failed to expire custody transfer: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// deviceKeyColumns lists the columns selected for every device key query, in scan order
+const deviceKeyColumns = `id, did_id, did, user_id, name, verification_method, public_key,
+	key_algorithm, status, created_at, revoked_at, COALESCE(attestation_platform, ''),
+	COALESCE(attested_app_id, ''), COALESCE(attestation_key_id, ''), attested_at`
+
+// scanDeviceKey scans a single device key row selected with deviceKeyColumns
+func scanDeviceKey(row rowScanner) (*domain.DeviceKey, error) {
+	var key domain.DeviceKey
+	var attestation domain.DeviceKeyAttestation
+	var attestedAt *time.Time
+	err := row.Scan(
+		&key.ID,
+		&key.DIDID,
+		&key.DID,
+		&key.UserID,
+		&key.Name,
+		&key.VerificationMethod,
+		&key.PublicKey,
+		&key.KeyAlgorithm,
+		&key.Status,
+		&key.CreatedAt,
+		&key.RevokedAt,
+		&attestation.Platform,
+		&attestation.AppID,
+		&attestation.KeyID,
+		&attestedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if attestedAt != nil {
+		attestation.AttestedAt = *attestedAt
+		key.Attestation = &attestation
+	}
+	return &key, nil
+}
+
+// DeviceKeyRepository implements the device key repository interface
+type DeviceKeyRepository struct {
+	db *sql.DB
+}
+
+// NewDeviceKeyRepository creates a new device key repository
+func NewDeviceKeyRepository(db *sql.DB) *DeviceKeyRepository {
+	return &DeviceKeyRepository{db: db}
+}
+
+// Create stores a device key
+func (r *DeviceKeyRepository) Create(key *domain.DeviceKey) error {
+	query := `
+		INSERT INTO device_keys (id, did_id, did, user_id, name, verification_method, public_key, key_algorithm, status, created_at,
+			attestation_platform, attested_app_id, attestation_key_id, attested_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	var platform, appID, keyID *string
+	var attestedAt *time.Time
+	if attestation := key.Attestation; attestation != nil {
+		platform, appID, attestedAt = &attestation.Platform, &attestation.AppID, &attestation.AttestedAt
+		if attestation.KeyID != "" {
+			keyID = &attestation.KeyID
+		}
+	}
+
+	_, err := r.db.Exec(query,
+		key.ID,
+		key.DIDID,
+		key.DID,
+		key.UserID,
+		key.Name,
+		key.VerificationMethod,
+		key.PublicKey,
+		key.KeyAlgorithm,
+		key.Status,
+		key.CreatedAt,
+		platform,
+		appID,
+		keyID,
+		attestedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create device key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a device key by ID
+func (r *DeviceKeyRepository) GetByID(id uuid.UUID) (*domain.DeviceKey, error) {
+	query := `SELECT ` + deviceKeyColumns + ` FROM device_keys WHERE id = $1`
+
+	key, err := scanDeviceKey(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDeviceKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get device key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByVerificationMethod retrieves a device key by its verification method ID
+func (r *DeviceKeyRepository) GetByVerificationMethod(verificationMethod string) (*domain.DeviceKey, error) {
+	query := `SELECT ` + deviceKeyColumns + ` FROM device_keys WHERE verification_method = $1`
+
+	key, err := scanDeviceKey(r.db.QueryRow(query, verificationMethod))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDeviceKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get device key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListActiveByDIDID lists the active device keys of a DID, oldest first
+func (r *DeviceKeyRepository) ListActiveByDIDID(didID uuid.UUID) ([]*domain.DeviceKey, error) {
+	query := `
+		SELECT ` + deviceKeyColumns + `
+		FROM device_keys
+		WHERE did_id = $1 AND status = 'active'
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device keys: %w", err)
+	}
+
+	return scanDeviceKeys(rows)
+}
+
+// ListByUserID lists all device keys of a user's DIDs, newest first
+func (r *DeviceKeyRepository) ListByUserID(userID uuid.UUID) ([]*domain.DeviceKey, error) {
+	query := `
+		SELECT ` + deviceKeyColumns + `
+		FROM device_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device keys: %w", err)
+	}
+
+	return scanDeviceKeys(rows)
+}
+
+// CountActiveByDIDID counts the active device keys of a DID
+func (r *DeviceKeyRepository) CountActiveByDIDID(didID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM device_keys WHERE did_id = $1 AND status = 'active'`
+
+	var count int
+	if err := r.db.QueryRow(query, didID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count device keys: %w", err)
+	}
+
+	return count, nil
+}
+
+// Revoke marks an active device key as revoked
+func (r *DeviceKeyRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE device_keys SET status = 'revoked', revoked_at = $2 WHERE id = $1 AND status = 'active'`
+
+	result, err := r.db.Exec(query, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke device key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrDeviceKeyNotFound
+	}
+
+	return nil
+}
+
+// RevokeByUserID marks every active device key of a user as revoked
+func (r *DeviceKeyRepository) RevokeByUserID(userID uuid.UUID, revokedAt time.Time) (int64, error) {
+	query := `UPDATE device_keys SET status = 'revoked', revoked_at = $2 WHERE user_id = $1 AND status = 'active'`
+
+	result, err := r.db.Exec(query, userID, revokedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke device keys: %w", err)
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// scanDeviceKeys scans and closes a result set of device key rows
+func scanDeviceKeys(rows *sql.Rows) ([]*domain.DeviceKey, error) {
+	defer rows.Close()
+
+	var keys []*domain.DeviceKey
+	for rows.Next() {
+		key, err := scanDeviceKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// DIDMethodPolicyRepository implements the DID method policy repository interface
+type DIDMethodPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewDIDMethodPolicyRepository creates a new DID method policy repository
+func NewDIDMethodPolicyRepository(db *sql.DB) *DIDMethodPolicyRepository {
+	return &DIDMethodPolicyRepository{db: db}
+}
+
+// List retrieves every method policy
+func (r *DIDMethodPolicyRepository) List() ([]*domain.DIDMethodPolicy, error) {
+	query := `
+		SELECT tenant, methods, updated_at
+		FROM tenant_did_methods
+		ORDER BY tenant
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query method policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*domain.DIDMethodPolicy
+	for rows.Next() {
+		policy, err := scanMethodPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan method policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Effective returns the tenant's method policy, falling back to the default
+func (r *DIDMethodPolicyRepository) Effective(tenant string) (*domain.DIDMethodPolicy, error) {
+	query := `
+		SELECT tenant, methods, updated_at
+		FROM tenant_did_methods
+		WHERE tenant IN ($1, '')
+		ORDER BY tenant DESC
+		LIMIT 1
+	`
+
+	policy, err := scanMethodPolicy(r.db.QueryRow(query, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get method policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Upsert creates or replaces a method policy
+func (r *DIDMethodPolicyRepository) Upsert(policy *domain.DIDMethodPolicy) error {
+	query := `
+		INSERT INTO tenant_did_methods (tenant, methods, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant) DO UPDATE
+		SET methods = EXCLUDED.methods, updated_at = EXCLUDED.updated_at
+	`
+
+	methods := make([]string, len(policy.Methods))
+	for i, method := range policy.Methods {
+		methods[i] = string(method)
+	}
+
+	_, err := r.db.Exec(query, policy.Tenant, pq.Array(methods), policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save method policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a method policy
+func (r *DIDMethodPolicyRepository) Delete(tenant string) error {
+	query := `DELETE FROM tenant_did_methods WHERE tenant = $1`
+
+	result, err := r.db.Exec(query, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to delete method policy: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrMethodPolicyNotFound
+	}
+
+	return nil
+}
+
+// scanMethodPolicy scans a single method policy row
+func scanMethodPolicy(row rowScanner) (*domain.DIDMethodPolicy, error) {
+	var policy domain.DIDMethodPolicy
+	var methods []string
+	if err := row.Scan(&policy.Tenant, pq.Array(&methods), &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	policy.Methods = make([]domain.DIDMethod, len(methods))
+	for i, method := range methods {
+		policy.Methods[i] = domain.DIDMethod(method)
+	}
+	return &policy, nil
+}
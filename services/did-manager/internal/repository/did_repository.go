@@ -4,12 +4,51 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 
 	"did-manager/internal/domain"
 
 	"github.com/google/uuid"
 )
 
+// didColumns lists the columns selected for every DID query, in scan order
+const didColumns = `id, user_id, did, user_hash, hash_scheme, COALESCE(hash_params, '') as hash_params, public_key, key_algorithm, custodial, status, visibility, created_at, updated_at, COALESCE(blockchain_tx, '') as blockchain_tx, approval_threshold, COALESCE(email_index, '') as email_index, COALESCE(sandbox_tenant, '') as sandbox_tenant, pepper_version, COALESCE(alias_did, '') as alias_did`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDID scans a single DID row selected with didColumns
+func scanDID(row rowScanner) (*domain.DID, error) {
+	var did domain.DID
+	err := row.Scan(
+		&did.ID,
+		&did.UserID,
+		&did.Did,
+		&did.UserHash,
+		&did.HashScheme,
+		&did.HashParams,
+		&did.PublicKey,
+		&did.KeyAlgorithm,
+		&did.Custodial,
+		&did.Status,
+		&did.Visibility,
+		&did.CreatedAt,
+		&did.UpdatedAt,
+		&did.BlockchainTx,
+		&did.ApprovalThreshold,
+		&did.EmailIndex,
+		&did.SandboxTenant,
+		&did.PepperVersion,
+		&did.AliasDID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &did, nil
+}
+
 // DIDRepository implements the DID repository interface
 type DIDRepository struct {
 	db *sql.DB
@@ -22,9 +61,13 @@ func NewDIDRepository(db *sql.DB) *DIDRepository {
 
 // Create creates a new DID record
 func (r *DIDRepository) Create(did *domain.DID) error {
+	if !domain.DIDStatus(did.Status).Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidDIDStatus, did.Status)
+	}
+
 	query := `
-		INSERT INTO dids (id, user_id, did, user_hash, public_key, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO dids (id, user_id, did, user_hash, hash_scheme, hash_params, public_key, key_algorithm, custodial, status, visibility, created_at, updated_at, email_index, sandbox_tenant, blockchain_tx, pepper_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), NULLIF($15, ''), NULLIF($16, ''), $17)
 	`
 
 	_, err := r.db.Exec(query,
@@ -32,10 +75,18 @@ func (r *DIDRepository) Create(did *domain.DID) error {
 		did.UserID,
 		did.Did,
 		did.UserHash,
+		did.HashScheme,
+		did.HashParams,
 		did.PublicKey,
+		did.KeyAlgorithm,
+		did.Custodial,
 		did.Status,
+		did.Visibility,
 		did.CreatedAt,
 		did.UpdatedAt,
+		did.EmailIndex,
+		did.SandboxTenant,
+		did.BlockchainTx,
 	)
 
 	if err != nil {
@@ -47,133 +98,140 @@ func (r *DIDRepository) Create(did *domain.DID) error {
 
 // GetByID retrieves a DID by ID
 func (r *DIDRepository) GetByID(id uuid.UUID) (*domain.DID, error) {
-	query := `
-		SELECT id, user_id, did, user_hash, public_key, status, created_at, updated_at, blockchain_tx
-		FROM dids WHERE id = $1
-	`
-
-	var did domain.DID
-	err := r.db.QueryRow(query, id).Scan(
-		&did.ID,
-		&did.UserID,
-		&did.Did,
-		&did.UserHash,
-		&did.PublicKey,
-		&did.Status,
-		&did.CreatedAt,
-		&did.UpdatedAt,
-		&did.BlockchainTx,
-	)
+	query := `SELECT ` + didColumns + ` FROM dids WHERE id = $1`
 
+	did, err := scanDID(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("DID not found")
+			return nil, domain.ErrDIDNotFound
 		}
 		return nil, fmt.Errorf("failed to get DID: %w", err)
 	}
 
-	return &did, nil
+	return did, nil
 }
 
 // GetByDID retrieves a DID by DID string
 func (r *DIDRepository) GetByDID(didString string) (*domain.DID, error) {
-	query := `
-		SELECT id, user_id, did, user_hash, public_key, status, created_at, updated_at, COALESCE(blockchain_tx, '') as blockchain_tx
-		FROM dids WHERE did = $1
-	`
+	query := `SELECT ` + didColumns + ` FROM dids WHERE did = $1`
 
 	log.Printf("DEBUG: Searching for DID: %s", didString)
 
-	var did domain.DID
-	err := r.db.QueryRow(query, didString).Scan(
-		&did.ID,
-		&did.UserID,
-		&did.Did,
-		&did.UserHash,
-		&did.PublicKey,
-		&did.Status,
-		&did.CreatedAt,
-		&did.UpdatedAt,
-		&did.BlockchainTx,
-	)
-
+	did, err := scanDID(r.db.QueryRow(query, didString))
 	if err != nil {
 		log.Printf("DEBUG: Query error: %v", err)
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("DID not found")
+			return nil, domain.ErrDIDNotFound
 		}
 		return nil, fmt.Errorf("failed to get DID: %w", err)
 	}
 
 	log.Printf("DEBUG: Found DID: %+v", did)
-	return &did, nil
+	return did, nil
 }
 
-// GetByUserID retrieves a DID by user ID
-func (r *DIDRepository) GetByUserID(userID uuid.UUID) (*domain.DID, error) {
+// GetByAlias retrieves a DID by the current identifier aliasing its legacy identifier
+func (r *DIDRepository) GetByAlias(alias string) (*domain.DID, error) {
+	query := `SELECT ` + didColumns + ` FROM dids WHERE alias_did = $1`
+
+	did, err := scanDID(r.db.QueryRow(query, alias))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDIDNotFound
+		}
+		return nil, fmt.Errorf("failed to get DID: %w", err)
+	}
+
+	return did, nil
+}
+
+// ListLegacyUnmigrated retrieves up to limit DIDs with a legacy identifier that have no
+// identifier migration, oldest first
+func (r *DIDRepository) ListLegacyUnmigrated(limit int) ([]*domain.DID, error) {
 	query := `
-		SELECT id, user_id, did, user_hash, public_key, status, created_at, updated_at, blockchain_tx
-		FROM dids WHERE user_id = $1
+		SELECT ` + didColumns + `
+		FROM dids
+		WHERE did ~ '^did:example:user:[0-9a-f]{16}:[0-9a-f]{32}$'
+			AND NOT EXISTS (SELECT 1 FROM did_identifier_migrations m WHERE m.did_id = dids.id)
+		ORDER BY created_at
+		LIMIT $1
 	`
 
-	var did domain.DID
-	err := r.db.QueryRow(query, userID).Scan(
-		&did.ID,
-		&did.UserID,
-		&did.Did,
-		&did.UserHash,
-		&did.PublicKey,
-		&did.Status,
-		&did.CreatedAt,
-		&did.UpdatedAt,
-		&did.BlockchainTx,
-	)
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DIDs: %w", err)
+	}
 
+	return scanDIDs(rows)
+}
+
+// GetByUserID retrieves a DID by user ID
+func (r *DIDRepository) GetByUserID(userID uuid.UUID) (*domain.DID, error) {
+	query := `SELECT ` + didColumns + ` FROM dids WHERE user_id = $1`
+
+	did, err := scanDID(r.db.QueryRow(query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("DID not found")
+			return nil, domain.ErrDIDNotFound
 		}
 		return nil, fmt.Errorf("failed to get DID: %w", err)
 	}
 
-	return &did, nil
+	return did, nil
 }
 
-// GetByUserHash retrieves a DID by user hash
-func (r *DIDRepository) GetByUserHash(userHash string) (*domain.DID, error) {
+// GetByEmailIndex retrieves the DID whose holder has the indexed email address. A
+// holder may have several DIDs; active ones come first, then the most recent.
+func (r *DIDRepository) GetByEmailIndex(emailIndex string) (*domain.DID, error) {
 	query := `
-		SELECT id, user_id, did, user_hash, public_key, status, created_at, updated_at, blockchain_tx
-		FROM dids WHERE user_hash = $1
+		SELECT ` + didColumns + `
+		FROM dids WHERE email_index = $1
+		ORDER BY status = 'active' DESC, created_at DESC
+		LIMIT 1
 	`
 
-	var did domain.DID
-	err := r.db.QueryRow(query, userHash).Scan(
-		&did.ID,
-		&did.UserID,
-		&did.Did,
-		&did.UserHash,
-		&did.PublicKey,
-		&did.Status,
-		&did.CreatedAt,
-		&did.UpdatedAt,
-		&did.BlockchainTx,
-	)
-
+	did, err := scanDID(r.db.QueryRow(query, emailIndex))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("DID not found")
+			return nil, domain.ErrDIDNotFound
 		}
 		return nil, fmt.Errorf("failed to get DID: %w", err)
 	}
 
-	return &did, nil
+	return did, nil
+}
+
+// SetEmailIndex records the blind index of a DID holder's email address
+func (r *DIDRepository) SetEmailIndex(id uuid.UUID, emailIndex string) error {
+	query := `UPDATE dids SET email_index = $2 WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, emailIndex); err != nil {
+		return fmt.Errorf("failed to set DID email index: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserID links a DID to another user account
+func (r *DIDRepository) SetUserID(id uuid.UUID, userID uuid.UUID) error {
+	query := `UPDATE dids SET user_id = $2, updated_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, userID); err != nil {
+		return fmt.Errorf("failed to set DID user: %w", err)
+	}
+
+	return nil
 }
 
 // Update updates a DID record
 func (r *DIDRepository) Update(did *domain.DID) error {
+	if !domain.DIDStatus(did.Status).Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidDIDStatus, did.Status)
+	}
+
 	query := `
-		UPDATE dids 
-		SET user_id = $2, did = $3, user_hash = $4, public_key = $5, status = $6, updated_at = $7, blockchain_tx = $8
+		UPDATE dids
+		SET user_id = $2, did = $3, user_hash = $4, hash_scheme = $5, hash_params = $6, public_key = $7, key_algorithm = $8, custodial = $9, status = $10, visibility = $11, updated_at = $12, blockchain_tx = $13
 		WHERE id = $1
 	`
 
@@ -182,8 +240,13 @@ func (r *DIDRepository) Update(did *domain.DID) error {
 		did.UserID,
 		did.Did,
 		did.UserHash,
+		did.HashScheme,
+		did.HashParams,
 		did.PublicKey,
+		did.KeyAlgorithm,
+		did.Custodial,
 		did.Status,
+		did.Visibility,
 		did.UpdatedAt,
 		did.BlockchainTx,
 	)
@@ -196,14 +259,18 @@ func (r *DIDRepository) Update(did *domain.DID) error {
 }
 
 // UpdateStatus updates the status of a DID
-func (r *DIDRepository) UpdateStatus(id uuid.UUID, status string, txHash string) error {
+func (r *DIDRepository) UpdateStatus(id uuid.UUID, status domain.DIDStatus, txHash string) error {
+	if !status.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidDIDStatus, status)
+	}
+
 	query := `
-		UPDATE dids 
+		UPDATE dids
 		SET status = $2, blockchain_tx = $3, updated_at = NOW()
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(query, id, status, txHash)
+	_, err := r.db.Exec(query, id, string(status), txHash)
 	if err != nil {
 		return fmt.Errorf("failed to update DID status: %w", err)
 	}
@@ -211,41 +278,180 @@ func (r *DIDRepository) UpdateStatus(id uuid.UUID, status string, txHash string)
 	return nil
 }
 
+// UpdateVisibility updates the resolution visibility of a DID
+func (r *DIDRepository) UpdateVisibility(id uuid.UUID, visibility string) error {
+	query := `
+		UPDATE dids
+		SET visibility = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, visibility)
+	if err != nil {
+		return fmt.Errorf("failed to update DID visibility: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrDIDNotFound
+	}
+
+	return nil
+}
+
+// RotateKey replaces the stored key of a custodial DID
+func (r *DIDRepository) RotateKey(id uuid.UUID, keyMaterial string, keyAlgorithm string) error {
+	query := `
+		UPDATE dids
+		SET public_key = $2, key_algorithm = $3, updated_at = NOW()
+		WHERE id = $1 AND custodial
+	`
+
+	result, err := r.db.Exec(query, id, keyMaterial, keyAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed to rotate DID key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrNotCustodial
+	}
+
+	return nil
+}
+
+// ListByUserID retrieves all DIDs owned by a user
+func (r *DIDRepository) ListByUserID(userID uuid.UUID) ([]*domain.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM dids WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DIDs: %w", err)
+	}
+
+	return scanDIDs(rows)
+}
+
 // ListByStatus retrieves DIDs by status
-func (r *DIDRepository) ListByStatus(status string) ([]*domain.DID, error) {
+func (r *DIDRepository) ListByStatus(status domain.DIDStatus) ([]*domain.DID, error) {
 	query := `
-		SELECT id, user_id, did, user_hash, public_key, status, created_at, updated_at, blockchain_tx
+		SELECT ` + didColumns + `
 		FROM dids WHERE status = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, status)
+	rows, err := r.db.Query(query, string(status))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query DIDs: %w", err)
 	}
+
+	return scanDIDs(rows)
+}
+
+// List returns a page of DIDs matching req, together with the total number of matches
+func (r *DIDRepository) List(req *domain.DIDListRequest, limit, offset int) ([]*domain.DID, int, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.Status != "" {
+		add("status = $%d", string(req.Status))
+	}
+	if req.UserID != "" {
+		add("user_id = $%d", req.UserID)
+	}
+	if req.Method != "" {
+		add("did LIKE 'did:' || $%d || ':%%'", req.Method)
+	}
+	if req.SandboxTenant != "" {
+		add("sandbox_tenant = $%d", req.SandboxTenant)
+	} else {
+		conditions = append(conditions, "sandbox_tenant IS NULL")
+	}
+	if req.CreatedAfter != nil {
+		add("created_at >= $%d", *req.CreatedAfter)
+	}
+	if req.CreatedBefore != nil {
+		add("created_at < $%d", *req.CreatedBefore)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM dids WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count DIDs: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM dids WHERE %s
+		ORDER BY %s, id
+		LIMIT $%d OFFSET $%d
+	`, didColumns, where, orderBy(req.Sort, "created_at"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query DIDs: %w", err)
+	}
+
+	dids, err := scanDIDs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return dids, total, nil
+}
+
+// DeleteSandbox deletes the DIDs of a sandbox partition with the credentials they
+// issued or hold; the DIDs' jobs, keys and other dependent rows cascade
+func (r *DIDRepository) DeleteSandbox(sandboxTenant string) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM credentials
+		WHERE issuer_did IN (SELECT did FROM dids WHERE sandbox_tenant = $1)
+		   OR subject_did IN (SELECT did FROM dids WHERE sandbox_tenant = $1)
+	`, sandboxTenant)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sandbox credentials: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM dids WHERE sandbox_tenant = $1`, sandboxTenant)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sandbox DIDs: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sandbox reset: %w", err)
+	}
+	return deleted, nil
+}
+
+// scanDIDs scans and closes a result set of DID rows
+func scanDIDs(rows *sql.Rows) ([]*domain.DID, error) {
 	defer rows.Close()
 
 	var dids []*domain.DID
 	for rows.Next() {
-		var did domain.DID
-		err := rows.Scan(
-			&did.ID,
-			&did.UserID,
-			&did.Did,
-			&did.UserHash,
-			&did.PublicKey,
-			&did.Status,
-			&did.CreatedAt,
-			&did.UpdatedAt,
-			&did.BlockchainTx,
-		)
+		did, err := scanDID(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan DID: %w", err)
 		}
-		dids = append(dids, &did)
+		dids = append(dids, did)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating over rows: %w", err)
 	}
 
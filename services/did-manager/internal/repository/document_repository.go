@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// documentColumns lists the columns selected for every document query, in scan order
+const documentColumns = `id, owner_did, name, content_type, size, digest, key_id, created_at`
+
+// scanDocument scans a single document row selected with documentColumns
+func scanDocument(row rowScanner) (*domain.Document, error) {
+	var document domain.Document
+	err := row.Scan(
+		&document.ID,
+		&document.OwnerDID,
+		&document.Name,
+		&document.ContentType,
+		&document.Size,
+		&document.Digest,
+		&document.KeyID,
+		&document.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// DocumentRepository implements the document repository interface
+type DocumentRepository struct {
+	db *sql.DB
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db *sql.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+// Create stores a new document
+func (r *DocumentRepository) Create(document *domain.Document) error {
+	query := `
+		INSERT INTO documents (id, owner_did, name, content_type, size, digest, key_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(query,
+		document.ID,
+		document.OwnerDID,
+		document.Name,
+		document.ContentType,
+		document.Size,
+		document.Digest,
+		document.KeyID,
+		document.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create document: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a document by ID
+func (r *DocumentRepository) GetByID(id uuid.UUID) (*domain.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE id = $1`
+
+	document, err := scanDocument(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return document, nil
+}
+
+// ListByOwner retrieves the documents uploaded by a DID, newest first
+func (r *DocumentRepository) ListByOwner(ownerDID string) ([]*domain.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE owner_did = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, ownerDID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*domain.Document
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return documents, nil
+}
+
+// Attach links documents to the credential that references them
+func (r *DocumentRepository) Attach(credentialID uuid.UUID, documentIDs []uuid.UUID) error {
+	query := `
+		INSERT INTO credential_documents (credential_id, document_id)
+		SELECT $1, UNNEST($2::UUID[])
+		ON CONFLICT DO NOTHING
+	`
+
+	ids := make([]string, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = id.String()
+	}
+	if _, err := r.db.Exec(query, credentialID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to attach documents: %w", err)
+	}
+
+	return nil
+}
+
+// IsAttached reports whether a document is referenced by any credential
+func (r *DocumentRepository) IsAttached(id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM credential_documents WHERE document_id = $1)`
+
+	var attached bool
+	if err := r.db.QueryRow(query, id).Scan(&attached); err != nil {
+		return false, fmt.Errorf("failed to check document attachments: %w", err)
+	}
+
+	return attached, nil
+}
+
+// IsAttachedForSubject reports whether a document is referenced by an active credential
+// issued to the subject
+func (r *DocumentRepository) IsAttachedForSubject(id uuid.UUID, subjectDID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM credential_documents cd
+			JOIN credentials c ON c.id = cd.credential_id
+			WHERE cd.document_id = $1 AND c.subject_did = $2 AND c.status = $3
+		)
+	`
+
+	var attached bool
+	if err := r.db.QueryRow(query, id, subjectDID, domain.CredentialStatusActive).Scan(&attached); err != nil {
+		return false, fmt.Errorf("failed to check document attachments: %w", err)
+	}
+
+	return attached, nil
+}
+
+// Delete removes a document
+func (r *DocumentRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM documents WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrDocumentNotFound
+	}
+
+	return nil
+}
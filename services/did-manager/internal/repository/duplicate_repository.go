@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// DuplicateRepository implements the duplicate repository interface
+type DuplicateRepository struct {
+	db *sql.DB
+}
+
+// NewDuplicateRepository creates a new duplicate repository
+func NewDuplicateRepository(db *sql.DB) *DuplicateRepository {
+	return &DuplicateRepository{db: db}
+}
+
+// RecordFingerprints stores the fingerprints of a DID by rule under a pepper version
+func (r *DuplicateRepository) RecordFingerprints(didID uuid.UUID, pepperVersion string, fingerprints map[domain.DuplicateRule]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for rule, fingerprint := range fingerprints {
+		_, err := tx.Exec(`
+			INSERT INTO identity_fingerprints (did_id, rule, pepper_version, fingerprint)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (did_id, rule, pepper_version) DO UPDATE SET fingerprint = EXCLUDED.fingerprint
+		`, didID, rule, pepperVersion, fingerprint)
+		if err != nil {
+			return fmt.Errorf("failed to record identity fingerprint: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit identity fingerprints: %w", err)
+	}
+
+	return nil
+}
+
+// FindMatches finds non-revoked DIDs of other users with any of the given fingerprints
+func (r *DuplicateRepository) FindMatches(rule domain.DuplicateRule, fingerprints []string, userID uuid.UUID) ([]*domain.DuplicateMatch, error) {
+	query := `
+		SELECT d.id, d.did
+		FROM dids d
+		WHERE d.id IN (
+			SELECT did_id FROM identity_fingerprints WHERE rule = $1 AND fingerprint = ANY($2)
+		) AND d.user_id <> $3 AND d.status <> $4
+		ORDER BY d.created_at
+	`
+
+	rows, err := r.db.Query(query, rule, pq.Array(fingerprints), userID, string(domain.DIDStatusRevoked))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identity fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*domain.DuplicateMatch
+	for rows.Next() {
+		match := &domain.DuplicateMatch{Rule: rule}
+		if err := rows.Scan(&match.DIDID, &match.Did); err != nil {
+			return nil, fmt.Errorf("failed to scan identity match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// endorsementColumns lists the columns selected for every endorsement query, in scan order
+const endorsementColumns = `id, endorser_did, subject_did, endorsement_type, COALESCE(statement, ''),
+	signature, key_algorithm, verification_method, created_at, revoked_at`
+
+// scanEndorsement scans a single endorsement row selected with endorsementColumns
+func scanEndorsement(row rowScanner) (*domain.Endorsement, error) {
+	var endorsement domain.Endorsement
+	err := row.Scan(
+		&endorsement.ID,
+		&endorsement.EndorserDID,
+		&endorsement.SubjectDID,
+		&endorsement.Type,
+		&endorsement.Statement,
+		&endorsement.Signature,
+		&endorsement.KeyAlgorithm,
+		&endorsement.VerificationMethod,
+		&endorsement.CreatedAt,
+		&endorsement.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &endorsement, nil
+}
+
+// EndorsementRepository implements the endorsement repository interface
+type EndorsementRepository struct {
+	db *sql.DB
+}
+
+// NewEndorsementRepository creates a new endorsement repository
+func NewEndorsementRepository(db *sql.DB) *EndorsementRepository {
+	return &EndorsementRepository{db: db}
+}
+
+// Create stores an endorsement
+func (r *EndorsementRepository) Create(endorsement *domain.Endorsement) error {
+	query := `
+		INSERT INTO endorsements (id, endorser_did, subject_did, endorsement_type, statement, signature, key_algorithm, verification_method, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.Exec(query,
+		endorsement.ID,
+		endorsement.EndorserDID,
+		endorsement.SubjectDID,
+		endorsement.Type,
+		endorsement.Statement,
+		endorsement.Signature,
+		endorsement.KeyAlgorithm,
+		endorsement.VerificationMethod,
+		endorsement.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create endorsement: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an endorsement by ID
+func (r *EndorsementRepository) GetByID(id uuid.UUID) (*domain.Endorsement, error) {
+	query := `SELECT ` + endorsementColumns + ` FROM endorsements WHERE id = $1`
+
+	endorsement, err := scanEndorsement(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrEndorsementNotFound
+		}
+		return nil, fmt.Errorf("failed to get endorsement: %w", err)
+	}
+
+	return endorsement, nil
+}
+
+// GetActive retrieves the active endorsement of subject by endorser under type
+func (r *EndorsementRepository) GetActive(endorserDID, subjectDID, endorsementType string) (*domain.Endorsement, error) {
+	query := `
+		SELECT ` + endorsementColumns + `
+		FROM endorsements
+		WHERE endorser_did = $1 AND subject_did = $2 AND endorsement_type = $3 AND revoked_at IS NULL
+	`
+
+	endorsement, err := scanEndorsement(r.db.QueryRow(query, endorserDID, subjectDID, endorsementType))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrEndorsementNotFound
+		}
+		return nil, fmt.Errorf("failed to get endorsement: %w", err)
+	}
+
+	return endorsement, nil
+}
+
+// List retrieves endorsements matching a filter, newest first
+func (r *EndorsementRepository) List(filter *domain.EndorsementFilter) ([]*domain.Endorsement, error) {
+	query := `
+		SELECT ` + endorsementColumns + `
+		FROM endorsements
+		WHERE ($1 = '' OR endorser_did = $1)
+			AND ($2 = '' OR subject_did = $2)
+			AND ($3 = '' OR endorsement_type = $3)
+			AND ($4 OR revoked_at IS NULL)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, filter.EndorserDID, filter.SubjectDID, filter.Type, filter.IncludeRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query endorsements: %w", err)
+	}
+
+	return scanEndorsements(rows)
+}
+
+// ListTouching lists active endorsements made by or about any of dids
+func (r *EndorsementRepository) ListTouching(dids []string) ([]*domain.Endorsement, error) {
+	query := `
+		SELECT ` + endorsementColumns + `
+		FROM endorsements
+		WHERE (endorser_did = ANY($1) OR subject_did = ANY($1)) AND revoked_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, pq.Array(dids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query endorsements: %w", err)
+	}
+
+	return scanEndorsements(rows)
+}
+
+// Revoke marks an active endorsement as revoked
+func (r *EndorsementRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE endorsements SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke endorsement: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrEndorsementNotFound
+	}
+
+	return nil
+}
+
+// scanEndorsements scans and closes a result set of endorsement rows
+func scanEndorsements(rows *sql.Rows) ([]*domain.Endorsement, error) {
+	defer rows.Close()
+
+	var endorsements []*domain.Endorsement
+	for rows.Next() {
+		endorsement, err := scanEndorsement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan endorsement: %w", err)
+		}
+		endorsements = append(endorsements, endorsement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return endorsements, nil
+}
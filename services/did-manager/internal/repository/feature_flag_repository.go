@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// FeatureFlagRepository implements the feature flag repository interface
+type FeatureFlagRepository struct {
+	db *sql.DB
+}
+
+// NewFeatureFlagRepository creates a new feature flag repository
+func NewFeatureFlagRepository(db *sql.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// List retrieves every feature override
+func (r *FeatureFlagRepository) List() ([]*domain.FeatureOverride, error) {
+	query := `
+		SELECT flag, tenant, enabled, updated_at
+		FROM feature_overrides
+		ORDER BY flag, tenant
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feature overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*domain.FeatureOverride
+	for rows.Next() {
+		var override domain.FeatureOverride
+		if err := rows.Scan(&override.Flag, &override.Tenant, &override.Enabled, &override.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature override: %w", err)
+		}
+		overrides = append(overrides, &override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// Upsert creates or replaces an override
+func (r *FeatureFlagRepository) Upsert(override *domain.FeatureOverride) error {
+	query := `
+		INSERT INTO feature_overrides (flag, tenant, enabled, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (flag, tenant) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(query, override.Flag, override.Tenant, override.Enabled, override.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save feature override: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an override
+func (r *FeatureFlagRepository) Delete(flag domain.FeatureFlag, tenant string) error {
+	query := `DELETE FROM feature_overrides WHERE flag = $1 AND tenant = $2`
+
+	result, err := r.db.Exec(query, flag, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature override: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrFeatureOverrideNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// identifierMigrationSelect selects identifier migrations in scan order, deriving their
+// status and transaction from the update job anchoring them, in the job queue or the
+// job archive
+const identifierMigrationSelect = `
+	SELECT * FROM (
+		SELECT m.did_id, m.legacy_did, m.from_format, m.new_did, m.to_format, m.error, m.job_id,
+			CASE
+				WHEN m.new_did = '' THEN 'unmigratable'
+				WHEN m.job_id IS NULL THEN 'unanchored'
+				WHEN COALESCE(j.status, a.status) = 'completed' THEN 'anchored'
+				WHEN COALESCE(j.status, a.status) IN ('failed', 'canceled') THEN 'failed'
+				ELSE 'anchoring'
+			END AS status,
+			COALESCE(j.tx_hash, a.tx_hash, '') AS tx_hash,
+			m.created_at
+		FROM did_identifier_migrations m
+		LEFT JOIN blockchain_jobs j ON j.id = m.job_id
+		LEFT JOIN blockchain_job_archive a ON a.id = m.job_id
+	) migrations`
+
+// scanIdentifierMigration scans a single row selected with identifierMigrationSelect
+func scanIdentifierMigration(row rowScanner) (*domain.IdentifierMigration, error) {
+	var migration domain.IdentifierMigration
+	var jobID uuid.NullUUID
+	err := row.Scan(
+		&migration.DIDID,
+		&migration.LegacyDID,
+		&migration.FromFormat,
+		&migration.NewDID,
+		&migration.ToFormat,
+		&migration.Error,
+		&jobID,
+		&migration.Status,
+		&migration.TxHash,
+		&migration.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if jobID.Valid {
+		migration.JobID = &jobID.UUID
+	}
+	return &migration, nil
+}
+
+// IdentifierMigrationRepository implements the identifier migration repository
+// interface
+type IdentifierMigrationRepository struct {
+	db *sql.DB
+}
+
+// NewIdentifierMigrationRepository creates a new identifier migration repository
+func NewIdentifierMigrationRepository(db *sql.DB) *IdentifierMigrationRepository {
+	return &IdentifierMigrationRepository{db: db}
+}
+
+// Record stores a migration and sets the alias of its DID to the new identifier in one
+// transaction
+func (r *IdentifierMigrationRepository) Record(migration *domain.IdentifierMigration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO did_identifier_migrations (did_id, legacy_did, from_format, new_did, to_format, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, migration.DIDID, migration.LegacyDID, migration.FromFormat, migration.NewDID, migration.ToFormat, migration.Error, migration.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record identifier migration: %w", err)
+	}
+
+	if migration.NewDID != "" {
+		if _, err := tx.Exec(`UPDATE dids SET alias_did = $2 WHERE id = $1`, migration.DIDID, migration.NewDID); err != nil {
+			return fmt.Errorf("failed to set DID alias: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit identifier migration: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves the migration of a DID
+func (r *IdentifierMigrationRepository) Get(didID uuid.UUID) (*domain.IdentifierMigration, error) {
+	migration, err := scanIdentifierMigration(r.db.QueryRow(identifierMigrationSelect+` WHERE did_id = $1`, didID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrIdentifierMigrationNotFound
+		}
+		return nil, fmt.Errorf("failed to get identifier migration: %w", err)
+	}
+
+	return migration, nil
+}
+
+// ListUnanchored returns up to limit migrations of active DIDs with a new identifier and
+// no update job, oldest first
+func (r *IdentifierMigrationRepository) ListUnanchored(limit int) ([]*domain.IdentifierMigration, error) {
+	query := identifierMigrationSelect + `
+		WHERE status = 'unanchored'
+			AND EXISTS (SELECT 1 FROM dids d WHERE d.id = migrations.did_id AND d.status = 'active')
+		ORDER BY created_at
+		LIMIT $1
+	`
+
+	return r.query(query, limit)
+}
+
+// SetJob records the update job anchoring a migration
+func (r *IdentifierMigrationRepository) SetJob(didID, jobID uuid.UUID) error {
+	query := `UPDATE did_identifier_migrations SET job_id = $2 WHERE did_id = $1`
+
+	if _, err := r.db.Exec(query, didID, jobID); err != nil {
+		return fmt.Errorf("failed to set identifier migration job: %w", err)
+	}
+
+	return nil
+}
+
+// List returns a page of migrations matching query, together with the total number of
+// matches
+func (r *IdentifierMigrationRepository) List(query *domain.IdentifierMigrationQuery, limit, offset int) ([]*domain.IdentifierMigration, int, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.Status != "" {
+		add("status = $%d", query.Status)
+	}
+	if query.DID != "" {
+		add("(legacy_did = $%[1]d OR new_did = $%[1]d)", query.DID)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM (`+identifierMigrationSelect+` WHERE `+where+`) matches`, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count identifier migrations: %w", err)
+	}
+
+	statement := fmt.Sprintf(`%s
+		WHERE %s
+		ORDER BY created_at ASC, did_id
+		LIMIT $%d OFFSET $%d
+	`, identifierMigrationSelect, where, len(args)+1, len(args)+2)
+
+	migrations, err := r.query(statement, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrations, total, nil
+}
+
+func (r *IdentifierMigrationRepository) query(query string, args ...any) ([]*domain.IdentifierMigration, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identifier migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var migrations []*domain.IdentifierMigration
+	for rows.Next() {
+		migration, err := scanIdentifierMigration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identifier migration: %w", err)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, rows.Err()
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IssuanceRuleRepository implements the issuance rule repository interface
+type IssuanceRuleRepository struct {
+	db *sql.DB
+}
+
+// NewIssuanceRuleRepository creates a new issuance rule repository
+func NewIssuanceRuleRepository(db *sql.DB) *IssuanceRuleRepository {
+	return &IssuanceRuleRepository{db: db}
+}
+
+// List retrieves every issuance rule
+func (r *IssuanceRuleRepository) List() ([]*domain.IssuanceRule, error) {
+	query := `
+		SELECT id, tenant, issuer_prefix, credential_types, subjects, subject_prefixes, created_at
+		FROM issuance_rules
+		ORDER BY tenant, created_at
+	`
+
+	return r.query(query)
+}
+
+// ListForTenant retrieves the rules of a tenant along with those of every tenant
+func (r *IssuanceRuleRepository) ListForTenant(tenant string) ([]*domain.IssuanceRule, error) {
+	query := `
+		SELECT id, tenant, issuer_prefix, credential_types, subjects, subject_prefixes, created_at
+		FROM issuance_rules
+		WHERE tenant IN ($1, '')
+		ORDER BY tenant DESC, created_at
+	`
+
+	return r.query(query, tenant)
+}
+
+// Create stores a new issuance rule
+func (r *IssuanceRuleRepository) Create(rule *domain.IssuanceRule) error {
+	query := `
+		INSERT INTO issuance_rules (id, tenant, issuer_prefix, credential_types, subjects, subject_prefixes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Exec(query,
+		rule.ID,
+		rule.Tenant,
+		rule.IssuerPrefix,
+		pq.Array(rule.CredentialTypes),
+		rule.Subjects,
+		pq.Array(rule.SubjectPrefixes),
+		rule.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create issuance rule: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an issuance rule, returning the removed rule
+func (r *IssuanceRuleRepository) Delete(id uuid.UUID) (*domain.IssuanceRule, error) {
+	query := `
+		DELETE FROM issuance_rules
+		WHERE id = $1
+		RETURNING id, tenant, issuer_prefix, credential_types, subjects, subject_prefixes, created_at
+	`
+
+	rule, err := scanIssuanceRule(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrIssuanceRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to delete issuance rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// query runs a query returning issuance rules
+func (r *IssuanceRuleRepository) query(query string, args ...any) ([]*domain.IssuanceRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query issuance rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.IssuanceRule
+	for rows.Next() {
+		rule, err := scanIssuanceRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issuance rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// scanIssuanceRule scans a single issuance rule row
+func scanIssuanceRule(row rowScanner) (*domain.IssuanceRule, error) {
+	var rule domain.IssuanceRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.Tenant,
+		&rule.IssuerPrefix,
+		pq.Array(&rule.CredentialTypes),
+		&rule.Subjects,
+		pq.Array(&rule.SubjectPrefixes),
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
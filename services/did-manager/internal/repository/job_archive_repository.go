@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// archivedJobColumns lists the columns selected for every archived job query, in scan
+// order
+const archivedJobColumns = `id, job_type, did_id, did, status, tx_hash, created_at, processed_at,
+	archived_at, record`
+
+// scanArchivedJob scans a single archived job row selected with archivedJobColumns,
+// decompressing its record
+func scanArchivedJob(row rowScanner) (*domain.ArchivedJob, error) {
+	var job domain.ArchivedJob
+	var record []byte
+	err := row.Scan(
+		&job.ID,
+		&job.JobType,
+		&job.DIDID,
+		&job.DID,
+		&job.Status,
+		&job.TxHash,
+		&job.CreatedAt,
+		&job.ProcessedAt,
+		&job.ArchivedAt,
+		&record,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(record))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archived job: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archived job: %w", err)
+	}
+	if err := json.Unmarshal(data, &job.Record); err != nil {
+		return nil, fmt.Errorf("failed to decode archived job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// JobArchiveRepository implements the job archive repository interface
+type JobArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewJobArchiveRepository creates a new job archive repository
+func NewJobArchiveRepository(db *sql.DB) *JobArchiveRepository {
+	return &JobArchiveRepository{db: db}
+}
+
+// ArchiveFinished moves finished jobs into the archive: the jobs are locked, their
+// records compressed into blockchain_job_archive and the jobs deleted, their timelines
+// cascading, in one transaction
+func (r *JobArchiveRepository) ArchiveFinished(cutoff time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + jobColumns + `
+		FROM blockchain_jobs
+		WHERE status IN ($1, $2, $3)
+		  AND COALESCE(processed_at, updated_at) < $4
+		  AND NOT EXISTS (SELECT 1 FROM notarizations WHERE notarizations.job_id = blockchain_jobs.id)
+		  AND NOT (status = $1 AND EXISTS (
+			SELECT 1 FROM dids WHERE dids.id = blockchain_jobs.did_id AND dids.status = $5
+		  ))
+		ORDER BY created_at ASC
+		LIMIT $6
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(query,
+		domain.JobStatusCompleted,
+		domain.JobStatusCanceled,
+		domain.JobStatusSimulated,
+		cutoff,
+		domain.DIDStatusPending,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query finished jobs: %w", err)
+	}
+	var jobs []*domain.BlockchainJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan blockchain job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		phases, err := listPhases(tx, job.ID)
+		if err != nil {
+			return 0, err
+		}
+		if phases == nil {
+			phases = []*domain.JobPhaseEvent{}
+		}
+
+		record, err := compressJobRecord(&domain.JobAuditEntry{Job: job, Phases: phases})
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO blockchain_job_archive (id, job_type, did_id, did, status, tx_hash, created_at,
+				processed_at, record)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (id) DO NOTHING
+		`, job.ID, job.JobType, job.DIDID, job.DID, job.Status, job.TxHash, job.CreatedAt, job.ProcessedAt, record)
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive blockchain job: %w", err)
+		}
+		ids = append(ids, job.ID.String())
+	}
+
+	if _, err := tx.Exec(`DELETE FROM blockchain_jobs WHERE id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to delete archived jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit job archival: %w", err)
+	}
+
+	return len(jobs), nil
+}
+
+// Get retrieves an archived job by ID
+func (r *JobArchiveRepository) Get(id uuid.UUID) (*domain.ArchivedJob, error) {
+	query := `SELECT ` + archivedJobColumns + ` FROM blockchain_job_archive WHERE id = $1`
+
+	job, err := scanArchivedJob(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrArchivedJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get archived job: %w", err)
+	}
+
+	return job, nil
+}
+
+// List retrieves a page of archived jobs matching query, oldest first
+func (r *JobArchiveRepository) List(query *domain.JobArchiveQuery, limit, offset int) ([]*domain.ArchivedJob, int, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.DID != "" {
+		add("did = $%d", query.DID)
+	}
+	if query.TxHash != "" {
+		add("tx_hash = $%d", query.TxHash)
+	}
+	if query.JobType != "" {
+		add("job_type = $%d", string(query.JobType))
+	}
+	if query.ProcessedAfter != nil {
+		add("processed_at >= $%d", *query.ProcessedAfter)
+	}
+	if query.ProcessedBefore != nil {
+		add("processed_at < $%d", *query.ProcessedBefore)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM blockchain_job_archive WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count archived jobs: %w", err)
+	}
+
+	statement := fmt.Sprintf(`
+		SELECT %s
+		FROM blockchain_job_archive WHERE %s
+		ORDER BY created_at ASC, id
+		LIMIT $%d OFFSET $%d
+	`, archivedJobColumns, where, len(args)+1, len(args)+2)
+
+	jobs, err := r.queryArchivedJobs(statement, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// ListByDIDID retrieves the archived jobs of a DID, oldest first
+func (r *JobArchiveRepository) ListByDIDID(didID uuid.UUID) ([]*domain.ArchivedJob, error) {
+	query := `
+		SELECT ` + archivedJobColumns + `
+		FROM blockchain_job_archive
+		WHERE did_id = $1
+		ORDER BY created_at ASC, id
+	`
+
+	return r.queryArchivedJobs(query, didID)
+}
+
+func (r *JobArchiveRepository) queryArchivedJobs(query string, args ...any) ([]*domain.ArchivedJob, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.ArchivedJob
+	for rows.Next() {
+		job, err := scanArchivedJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archived job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// compressJobRecord encodes an archived job's record as gzipped JSON
+func compressJobRecord(record *domain.JobAuditEntry) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode archived job: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress archived job: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archived job: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// notarizationColumns lists the columns selected for every notarization query, in scan
+// order. The status follows the anchoring job until the transaction is recorded.
+const notarizationColumns = `n.id, n.did_id, n.did, n.document_hash, n.signature, n.key_algorithm,
+	n.verification_method, n.job_id,
+	CASE
+		WHEN n.tx_hash IS NOT NULL THEN 'anchored'
+		WHEN j.status = 'failed' THEN 'failed'
+		ELSE 'pending'
+	END,
+	COALESCE(n.tx_hash, ''), n.created_at, n.anchored_at`
+
+// notarizationFrom joins notarizations with their anchoring jobs
+const notarizationFrom = `notarizations n LEFT JOIN blockchain_jobs j ON j.id = n.job_id`
+
+// scanNotarization scans a single notarization row selected with notarizationColumns
+func scanNotarization(row rowScanner) (*domain.Notarization, error) {
+	var notarization domain.Notarization
+	err := row.Scan(
+		&notarization.ID,
+		&notarization.DIDID,
+		&notarization.Did,
+		&notarization.DocumentHash,
+		&notarization.Signature,
+		&notarization.KeyAlgorithm,
+		&notarization.VerificationMethod,
+		&notarization.JobID,
+		&notarization.Status,
+		&notarization.TxHash,
+		&notarization.CreatedAt,
+		&notarization.AnchoredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &notarization, nil
+}
+
+// NotarizationRepository implements the notarization repository interface
+type NotarizationRepository struct {
+	db *sql.DB
+}
+
+// NewNotarizationRepository creates a new notarization repository
+func NewNotarizationRepository(db *sql.DB) *NotarizationRepository {
+	return &NotarizationRepository{db: db}
+}
+
+// Create stores a notarization
+func (r *NotarizationRepository) Create(notarization *domain.Notarization) error {
+	query := `
+		INSERT INTO notarizations (id, did_id, did, document_hash, signature, key_algorithm, verification_method, job_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.Exec(query,
+		notarization.ID,
+		notarization.DIDID,
+		notarization.Did,
+		notarization.DocumentHash,
+		notarization.Signature,
+		notarization.KeyAlgorithm,
+		notarization.VerificationMethod,
+		notarization.JobID,
+		notarization.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create notarization: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a notarization by ID
+func (r *NotarizationRepository) GetByID(id uuid.UUID) (*domain.Notarization, error) {
+	query := `SELECT ` + notarizationColumns + ` FROM ` + notarizationFrom + ` WHERE n.id = $1`
+
+	notarization, err := scanNotarization(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotarizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get notarization: %w", err)
+	}
+
+	return notarization, nil
+}
+
+// ListByDocumentHash retrieves every notarization of a document hash, oldest first
+func (r *NotarizationRepository) ListByDocumentHash(documentHash string) ([]*domain.Notarization, error) {
+	query := `
+		SELECT ` + notarizationColumns + `
+		FROM ` + notarizationFrom + `
+		WHERE n.document_hash = $1
+		ORDER BY n.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, documentHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notarizations: %w", err)
+	}
+	defer rows.Close()
+
+	var notarizations []*domain.Notarization
+	for rows.Next() {
+		notarization, err := scanNotarization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notarization: %w", err)
+		}
+		notarizations = append(notarizations, notarization)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return notarizations, nil
+}
+
+// MarkAnchored records the transaction that anchored the notarization created with jobID
+func (r *NotarizationRepository) MarkAnchored(jobID uuid.UUID, txHash string, anchoredAt time.Time) error {
+	query := `UPDATE notarizations SET tx_hash = $2, anchored_at = $3 WHERE job_id = $1`
+
+	result, err := r.db.Exec(query, jobID, txHash, anchoredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update notarization: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrNotarizationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// operationColumns lists the columns selected for every operation query, in scan
+// order; approvals are aggregated from operation_approvals
+const operationColumns = `o.id, o.did_id, o.did, o.operation_type, o.payload, o.status, o.proposed_by,
+	COALESCE(o.result, 'null'::jsonb), COALESCE(o.message, ''), o.created_at, o.executed_at,
+	COALESCE((SELECT array_agg(a.controller_did ORDER BY a.approved_at) FROM operation_approvals a WHERE a.operation_id = o.id), '{}')`
+
+// scanOperation scans a single operation row selected with operationColumns
+func scanOperation(row rowScanner) (*domain.ControlledOperation, error) {
+	var operation domain.ControlledOperation
+	var payload, result []byte
+	err := row.Scan(
+		&operation.ID,
+		&operation.DIDID,
+		&operation.Did,
+		&operation.Type,
+		&payload,
+		&operation.Status,
+		&operation.ProposedBy,
+		&result,
+		&operation.Message,
+		&operation.CreatedAt,
+		&operation.ExecutedAt,
+		pq.Array(&operation.Approvals),
+	)
+	if err != nil {
+		return nil, err
+	}
+	operation.Payload = payload
+	if string(result) != "null" {
+		operation.Result = result
+	}
+	return &operation, nil
+}
+
+// OrganizationRepository implements the organization repository interface
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// SetControllers replaces the controllers and approval threshold of a DID in one transaction
+func (r *OrganizationRepository) SetControllers(didID uuid.UUID, controllers []string, threshold int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE dids SET approval_threshold = $2, updated_at = NOW() WHERE id = $1`, didID, threshold)
+	if err != nil {
+		return fmt.Errorf("failed to update approval threshold: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrDIDNotFound
+	}
+
+	if _, err := tx.Exec(`DELETE FROM did_controllers WHERE did_id = $1`, didID); err != nil {
+		return fmt.Errorf("failed to clear controllers: %w", err)
+	}
+
+	for _, controller := range controllers {
+		if _, err := tx.Exec(
+			`INSERT INTO did_controllers (did_id, controller_did) VALUES ($1, $2)`,
+			didID, controller,
+		); err != nil {
+			return fmt.Errorf("failed to add controller: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit controllers: %w", err)
+	}
+
+	return nil
+}
+
+// ListControllers lists the controllers of a DID
+func (r *OrganizationRepository) ListControllers(didID uuid.UUID) ([]*domain.DIDController, error) {
+	query := `
+		SELECT controller_did, created_at
+		FROM did_controllers WHERE did_id = $1
+		ORDER BY created_at, controller_did
+	`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query controllers: %w", err)
+	}
+	defer rows.Close()
+
+	var controllers []*domain.DIDController
+	for rows.Next() {
+		var controller domain.DIDController
+		if err := rows.Scan(&controller.ControllerDID, &controller.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan controller: %w", err)
+		}
+		controllers = append(controllers, &controller)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return controllers, nil
+}
+
+// CreateOperation stores a new operation
+func (r *OrganizationRepository) CreateOperation(operation *domain.ControlledOperation) error {
+	query := `
+		INSERT INTO controlled_operations (id, did_id, did, operation_type, payload, status, proposed_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	var payload any
+	if len(operation.Payload) > 0 {
+		payload = []byte(operation.Payload)
+	}
+
+	_, err := r.db.Exec(query,
+		operation.ID,
+		operation.DIDID,
+		operation.Did,
+		operation.Type,
+		payload,
+		operation.Status,
+		operation.ProposedBy,
+		operation.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create operation: %w", err)
+	}
+
+	return nil
+}
+
+// GetOperation retrieves an operation and its approvals by ID
+func (r *OrganizationRepository) GetOperation(id uuid.UUID) (*domain.ControlledOperation, error) {
+	query := `SELECT ` + operationColumns + ` FROM controlled_operations o WHERE o.id = $1`
+
+	operation, err := scanOperation(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+
+	return operation, nil
+}
+
+// ListOperations lists the operations on a DID, optionally filtered by status
+func (r *OrganizationRepository) ListOperations(didID uuid.UUID, status string) ([]*domain.ControlledOperation, error) {
+	query := `
+		SELECT ` + operationColumns + `
+		FROM controlled_operations o
+		WHERE o.did_id = $1 AND ($2 = '' OR o.status = $2)
+		ORDER BY o.created_at DESC
+	`
+
+	rows, err := r.db.Query(query, didID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operations: %w", err)
+	}
+	defer rows.Close()
+
+	var operations []*domain.ControlledOperation
+	for rows.Next() {
+		operation, err := scanOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan operation: %w", err)
+		}
+		operations = append(operations, operation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return operations, nil
+}
+
+// AddApproval records a controller's approval of an operation
+func (r *OrganizationRepository) AddApproval(operationID uuid.UUID, controllerDID string) error {
+	query := `
+		INSERT INTO operation_approvals (operation_id, controller_did)
+		VALUES ($1, $2)
+		ON CONFLICT (operation_id, controller_did) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(query, operationID, controllerDID); err != nil {
+		return fmt.Errorf("failed to record approval: %w", err)
+	}
+
+	return nil
+}
+
+// MarkExecuting claims a pending operation for execution
+func (r *OrganizationRepository) MarkExecuting(id uuid.UUID) error {
+	query := `UPDATE controlled_operations SET status = $2 WHERE id = $1 AND status = 'pending'`
+
+	result, err := r.db.Exec(query, id, string(domain.OperationStatusExecuting))
+	if err != nil {
+		return fmt.Errorf("failed to claim operation: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrOperationClosed
+	}
+
+	return nil
+}
+
+// Finish records the outcome of an executed operation
+func (r *OrganizationRepository) Finish(id uuid.UUID, status string, result json.RawMessage, message string, executedAt time.Time) error {
+	query := `
+		UPDATE controlled_operations
+		SET status = $2, result = $3, message = $4, executed_at = $5
+		WHERE id = $1
+	`
+
+	var resultValue any
+	if len(result) > 0 {
+		resultValue = []byte(result)
+	}
+
+	if _, err := r.db.Exec(query, id, status, resultValue, message, executedAt); err != nil {
+		return fmt.Errorf("failed to finish operation: %w", err)
+	}
+
+	return nil
+}
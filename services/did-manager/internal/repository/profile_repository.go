@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ProfileRepository implements the DID profile repository interface
+type ProfileRepository struct {
+	db *sql.DB
+}
+
+// NewProfileRepository creates a new DID profile repository
+func NewProfileRepository(db *sql.DB) *ProfileRepository {
+	return &ProfileRepository{db: db}
+}
+
+// Get retrieves the profile of a DID
+func (r *ProfileRepository) Get(didID uuid.UUID) (*domain.DIDProfile, error) {
+	query := `
+		SELECT d.did, p.display_name, p.avatar_url, p.linked_domains, p.public_credentials, p.published, p.updated_at
+		FROM did_profiles p
+		JOIN dids d ON d.id = p.did_id
+		WHERE p.did_id = $1
+	`
+
+	var profile domain.DIDProfile
+	var credentialIDs, published []string
+	err := r.db.QueryRow(query, didID).Scan(
+		&profile.DID,
+		&profile.DisplayName,
+		&profile.AvatarURL,
+		pq.Array(&profile.LinkedDomains),
+		pq.Array(&credentialIDs),
+		pq.Array(&published),
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	profile.PublicCredentials = make([]uuid.UUID, 0, len(credentialIDs))
+	for _, id := range credentialIDs {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse profile credential ID: %w", err)
+		}
+		profile.PublicCredentials = append(profile.PublicCredentials, parsed)
+	}
+	profile.Published = make([]domain.ProfileField, len(published))
+	for i, field := range published {
+		profile.Published[i] = domain.ProfileField(field)
+	}
+
+	return &profile, nil
+}
+
+// Upsert creates or replaces the profile of a DID
+func (r *ProfileRepository) Upsert(didID uuid.UUID, profile *domain.DIDProfile) error {
+	query := `
+		INSERT INTO did_profiles (did_id, display_name, avatar_url, linked_domains, public_credentials, published, updated_at)
+		VALUES ($1, $2, $3, $4, $5::UUID[], $6, $7)
+		ON CONFLICT (did_id) DO UPDATE
+		SET display_name = EXCLUDED.display_name,
+			avatar_url = EXCLUDED.avatar_url,
+			linked_domains = EXCLUDED.linked_domains,
+			public_credentials = EXCLUDED.public_credentials,
+			published = EXCLUDED.published,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	credentialIDs := make([]string, len(profile.PublicCredentials))
+	for i, id := range profile.PublicCredentials {
+		credentialIDs[i] = id.String()
+	}
+	published := make([]string, len(profile.Published))
+	for i, field := range profile.Published {
+		published[i] = string(field)
+	}
+
+	_, err := r.db.Exec(query,
+		didID,
+		profile.DisplayName,
+		profile.AvatarURL,
+		pq.Array(profile.LinkedDomains),
+		pq.Array(credentialIDs),
+		pq.Array(published),
+		profile.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the profile of a DID
+func (r *ProfileRepository) Delete(didID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM did_profiles WHERE did_id = $1`, didID)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrProfileNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// purposeKeyColumns lists the columns selected for every purpose key query, in scan order
+const purposeKeyColumns = `id, did_id, purpose, verification_method, key_algorithm, public_key, private_key, created_at`
+
+// scanPurposeKey scans a single purpose key row selected with purposeKeyColumns
+func scanPurposeKey(row rowScanner) (*domain.PurposeKey, error) {
+	var key domain.PurposeKey
+	err := row.Scan(
+		&key.ID,
+		&key.DIDID,
+		&key.Purpose,
+		&key.VerificationMethod,
+		&key.KeyAlgorithm,
+		&key.PublicKey,
+		&key.PrivateKey,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// PurposeKeyRepository implements the purpose key repository interface
+type PurposeKeyRepository struct {
+	db *sql.DB
+}
+
+// NewPurposeKeyRepository creates a new purpose key repository
+func NewPurposeKeyRepository(db *sql.DB) *PurposeKeyRepository {
+	return &PurposeKeyRepository{db: db}
+}
+
+// Create stores the purpose keys of a DID in one transaction
+func (r *PurposeKeyRepository) Create(keys []*domain.PurposeKey) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, key := range keys {
+		_, err := tx.Exec(`
+			INSERT INTO did_purpose_keys (id, did_id, purpose, verification_method, key_algorithm, public_key, private_key, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, key.ID, key.DIDID, key.Purpose, key.VerificationMethod, key.KeyAlgorithm, key.PublicKey, key.PrivateKey, key.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store purpose key: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purpose keys: %w", err)
+	}
+
+	return nil
+}
+
+// ListByDIDID retrieves the purpose keys of a DID
+func (r *PurposeKeyRepository) ListByDIDID(didID uuid.UUID) ([]*domain.PurposeKey, error) {
+	query := `SELECT ` + purposeKeyColumns + ` FROM did_purpose_keys WHERE did_id = $1 ORDER BY created_at, purpose`
+
+	rows, err := r.db.Query(query, didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purpose keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.PurposeKey
+	for rows.Next() {
+		key, err := scanPurposeKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan purpose key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return keys, nil
+}
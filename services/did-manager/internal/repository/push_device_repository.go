@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// PushDeviceRepository implements the push device repository interface
+type PushDeviceRepository struct {
+	db *sql.DB
+}
+
+// NewPushDeviceRepository creates a new push device repository
+func NewPushDeviceRepository(db *sql.DB) *PushDeviceRepository {
+	return &PushDeviceRepository{db: db}
+}
+
+// Upsert registers a device token. Tokens are unique per device, so re-registering a
+// token refreshes it and moves it to the registering user.
+func (r *PushDeviceRepository) Upsert(device *domain.PushDevice) error {
+	query := `
+		INSERT INTO push_devices (id, user_id, platform, token, device_name, locale, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			platform = EXCLUDED.platform,
+			device_name = EXCLUDED.device_name,
+			locale = EXCLUDED.locale,
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query,
+		device.ID,
+		device.UserID,
+		device.Platform,
+		device.Token,
+		device.DeviceName,
+		device.Locale,
+		device.CreatedAt,
+		device.LastSeenAt,
+	).Scan(&device.ID, &device.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to register push device: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUserID retrieves all devices registered by a user
+func (r *PushDeviceRepository) ListByUserID(userID uuid.UUID) ([]*domain.PushDevice, error) {
+	query := `
+		SELECT id, user_id, platform, token, COALESCE(device_name, ''), locale, created_at, last_seen_at
+		FROM push_devices WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []*domain.PushDevice
+	for rows.Next() {
+		var device domain.PushDevice
+		if err := rows.Scan(
+			&device.ID,
+			&device.UserID,
+			&device.Platform,
+			&device.Token,
+			&device.DeviceName,
+			&device.Locale,
+			&device.CreatedAt,
+			&device.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan push device: %w", err)
+		}
+		devices = append(devices, &device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return devices, nil
+}
+
+// Delete removes a device registered by userID
+func (r *PushDeviceRepository) Delete(id uuid.UUID, userID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM push_devices WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// DeleteByToken removes a device by its token, e.g. once the provider reports it unregistered
+func (r *PushDeviceRepository) DeleteByToken(token string) error {
+	if _, err := r.db.Exec(`DELETE FROM push_devices WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+
+	return nil
+}
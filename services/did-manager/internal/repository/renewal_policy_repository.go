@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// renewalPolicyColumns lists the columns selected for every renewal policy query, in scan order
+const renewalPolicyColumns = `issuer_did, tenant, auto_renew, validity_days, COALESCE(webhook_url, ''),
+	COALESCE(webhook_secret, ''), updated_at`
+
+// scanRenewalPolicy scans a single policy row selected with renewalPolicyColumns
+func scanRenewalPolicy(row rowScanner) (*domain.RenewalPolicy, error) {
+	var policy domain.RenewalPolicy
+	err := row.Scan(
+		&policy.IssuerDID,
+		&policy.Tenant,
+		&policy.AutoRenew,
+		&policy.ValidityDays,
+		&policy.WebhookURL,
+		&policy.WebhookSecret,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// RenewalPolicyRepository implements the renewal policy repository interface
+type RenewalPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewRenewalPolicyRepository creates a new renewal policy repository
+func NewRenewalPolicyRepository(db *sql.DB) *RenewalPolicyRepository {
+	return &RenewalPolicyRepository{db: db}
+}
+
+// Upsert creates or replaces the policy for an issuer and tenant
+func (r *RenewalPolicyRepository) Upsert(policy *domain.RenewalPolicy) error {
+	query := `
+		INSERT INTO renewal_policies (issuer_did, tenant, auto_renew, validity_days, webhook_url, webhook_secret, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		ON CONFLICT (issuer_did, tenant) DO UPDATE
+		SET auto_renew = EXCLUDED.auto_renew,
+			validity_days = EXCLUDED.validity_days,
+			webhook_url = EXCLUDED.webhook_url,
+			webhook_secret = EXCLUDED.webhook_secret,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(query,
+		policy.IssuerDID,
+		policy.Tenant,
+		policy.AutoRenew,
+		policy.ValidityDays,
+		policy.WebhookURL,
+		policy.WebhookSecret,
+		policy.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save renewal policy: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves the policy for an issuer and tenant
+func (r *RenewalPolicyRepository) Get(issuerDID, tenant string) (*domain.RenewalPolicy, error) {
+	query := `SELECT ` + renewalPolicyColumns + ` FROM renewal_policies WHERE issuer_did = $1 AND tenant = $2`
+
+	policy, err := scanRenewalPolicy(r.db.QueryRow(query, issuerDID, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRenewalPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get renewal policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ListByIssuer retrieves every policy of an issuer
+func (r *RenewalPolicyRepository) ListByIssuer(issuerDID string) ([]*domain.RenewalPolicy, error) {
+	query := `
+		SELECT ` + renewalPolicyColumns + `
+		FROM renewal_policies WHERE issuer_did = $1
+		ORDER BY tenant
+	`
+
+	rows, err := r.db.Query(query, issuerDID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query renewal policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*domain.RenewalPolicy
+	for rows.Next() {
+		policy, err := scanRenewalPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan renewal policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return policies, nil
+}
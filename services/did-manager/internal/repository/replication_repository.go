@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// ReplicationRepository implements the replication repository interface against the
+// PostgreSQL logical replication catalogs
+type ReplicationRepository struct {
+	db *sql.DB
+}
+
+// NewReplicationRepository creates a new replication repository
+func NewReplicationRepository(db *sql.DB) *ReplicationRepository {
+	return &ReplicationRepository{db: db}
+}
+
+// GetSubscription retrieves a subscription with the last message received by its
+// apply workers
+func (r *ReplicationRepository) GetSubscription(name string) (*domain.ReplicationSubscription, error) {
+	query := `
+		SELECT s.subname, s.subenabled, MAX(st.last_msg_receipt_time)
+		FROM pg_subscription s
+		LEFT JOIN pg_stat_subscription st ON st.subid = s.oid AND st.relid IS NULL
+		WHERE s.subname = $1
+		GROUP BY s.subname, s.subenabled
+	`
+
+	var subscription domain.ReplicationSubscription
+	var lastReceivedAt sql.NullTime
+	err := r.db.QueryRow(query, name).Scan(&subscription.Name, &subscription.Enabled, &lastReceivedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get replication subscription: %w", err)
+	}
+	if lastReceivedAt.Valid {
+		subscription.LastReceivedAt = &lastReceivedAt.Time
+	}
+
+	return &subscription, nil
+}
+
+// DisableSubscription stops a subscription from applying the primary's changes. The
+// subscription and its slot on the primary are kept so they can be dropped or
+// re-enabled once the primary is reachable again.
+func (r *ReplicationRepository) DisableSubscription(name string) error {
+	if _, err := r.GetSubscription(name); err != nil {
+		return err
+	}
+
+	// Identifiers cannot be bound as parameters
+	if _, err := r.db.Exec("ALTER SUBSCRIPTION " + pq.QuoteIdentifier(name) + " DISABLE"); err != nil {
+		return fmt.Errorf("failed to disable replication subscription: %w", err)
+	}
+
+	return nil
+}
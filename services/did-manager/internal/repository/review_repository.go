@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// reviewColumns lists the columns selected for every review query, in scan order
+const reviewColumns = `id, operation, target_id, target, reasons, status, COALESCE(note, ''), created_at, resolved_at`
+
+// scanReview scans a single review row selected with reviewColumns
+func scanReview(row rowScanner) (*domain.Review, error) {
+	var review domain.Review
+	var reasons []byte
+	err := row.Scan(
+		&review.ID,
+		&review.Operation,
+		&review.TargetID,
+		&review.Target,
+		&reasons,
+		&review.Status,
+		&review.Note,
+		&review.CreatedAt,
+		&review.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(reasons, &review.Reasons); err != nil {
+		return nil, fmt.Errorf("failed to decode review reasons: %w", err)
+	}
+	return &review, nil
+}
+
+// ReviewRepository implements the review repository interface
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+// NewReviewRepository creates a new review repository
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create stores a new review
+func (r *ReviewRepository) Create(review *domain.Review) error {
+	reasons, err := json.Marshal(review.Reasons)
+	if err != nil {
+		return fmt.Errorf("failed to encode review reasons: %w", err)
+	}
+
+	query := `
+		INSERT INTO reviews (id, operation, target_id, target, reasons, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = r.db.Exec(query,
+		review.ID,
+		review.Operation,
+		review.TargetID,
+		review.Target,
+		reasons,
+		review.Status,
+		review.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a review by ID
+func (r *ReviewRepository) GetByID(id uuid.UUID) (*domain.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE id = $1`
+
+	review, err := scanReview(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+
+	return review, nil
+}
+
+// List lists reviews, newest first; empty filters match everything
+func (r *ReviewRepository) List(status, operation string, limit int) ([]*domain.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM reviews
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR operation = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(query, status, operation, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*domain.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// Resolve records a decision on a pending review
+func (r *ReviewRepository) Resolve(id uuid.UUID, status domain.ReviewStatus, note string, resolvedAt time.Time) error {
+	query := `
+		UPDATE reviews
+		SET status = $2, note = NULLIF($3, ''), resolved_at = $4
+		WHERE id = $1 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, id, string(status), note, resolvedAt, string(domain.ReviewStatusPending))
+	if err != nil {
+		return fmt.Errorf("failed to resolve review: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return domain.ErrReviewClosed
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// revocationBatchColumns lists the columns selected for every batch query, in scan order
+const revocationBatchColumns = `id, sequence, root, revoked_count, status, COALESCE(tx_hash, ''), COALESCE(error, ''), created_at, anchored_at`
+
+// scanRevocationBatch scans a single batch row selected with revocationBatchColumns
+func scanRevocationBatch(row rowScanner) (*domain.RevocationBatch, error) {
+	var batch domain.RevocationBatch
+	var root string
+	err := row.Scan(
+		&batch.ID,
+		&batch.Sequence,
+		&root,
+		&batch.RevokedCount,
+		&batch.Status,
+		&batch.TxHash,
+		&batch.Error,
+		&batch.CreatedAt,
+		&batch.AnchoredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.Root.UnmarshalText([]byte(root)); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// RevocationBatchRepository implements the revocation batch repository interface
+type RevocationBatchRepository struct {
+	db *sql.DB
+}
+
+// NewRevocationBatchRepository creates a new revocation batch repository
+func NewRevocationBatchRepository(db *sql.DB) *RevocationBatchRepository {
+	return &RevocationBatchRepository{db: db}
+}
+
+// Create stores a batch and assigns the revoked credentials to it in one transaction.
+// The batch's sequence is allocated by the database.
+func (r *RevocationBatchRepository) Create(batch *domain.RevocationBatch, credentialIDs []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO revocation_batches (id, root, revoked_count, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING sequence
+	`
+
+	err = tx.QueryRow(query,
+		batch.ID,
+		batch.Root.Hex(),
+		batch.RevokedCount,
+		batch.Status,
+		batch.CreatedAt,
+	).Scan(&batch.Sequence)
+	if err != nil {
+		return fmt.Errorf("failed to create revocation batch: %w", err)
+	}
+
+	ids := make([]string, len(credentialIDs))
+	for i, id := range credentialIDs {
+		ids[i] = id.String()
+	}
+
+	_, err = tx.Exec(
+		`UPDATE credentials SET revocation_batch_id = $1 WHERE id = ANY($2::uuid[])`,
+		batch.ID,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to assign credentials to revocation batch: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revocation batch: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestAnchored retrieves the anchored batch with the highest sequence
+func (r *RevocationBatchRepository) GetLatestAnchored() (*domain.RevocationBatch, error) {
+	query := `
+		SELECT ` + revocationBatchColumns + `
+		FROM revocation_batches WHERE status = $1
+		ORDER BY sequence DESC LIMIT 1
+	`
+
+	batch, err := scanRevocationBatch(r.db.QueryRow(query, domain.RevocationBatchAnchored))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNoAnchoredRevocationRoot
+		}
+		return nil, fmt.Errorf("failed to get revocation batch: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ListPending retrieves the batches awaiting anchoring, oldest first
+func (r *RevocationBatchRepository) ListPending() ([]*domain.RevocationBatch, error) {
+	query := `
+		SELECT ` + revocationBatchColumns + `
+		FROM revocation_batches WHERE status = $1
+		ORDER BY sequence ASC
+	`
+
+	rows, err := r.db.Query(query, domain.RevocationBatchPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revocation batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*domain.RevocationBatch
+	for rows.Next() {
+		batch, err := scanRevocationBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revocation batch: %w", err)
+		}
+		batches = append(batches, batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return batches, nil
+}
+
+// MarkAnchored records the transaction that anchored a batch's root
+func (r *RevocationBatchRepository) MarkAnchored(id uuid.UUID, txHash string, anchoredAt time.Time) error {
+	query := `UPDATE revocation_batches SET status = $2, tx_hash = $3, anchored_at = $4, error = NULL WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, domain.RevocationBatchAnchored, txHash, anchoredAt); err != nil {
+		return fmt.Errorf("failed to update revocation batch: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records why anchoring a batch failed; the batch stays pending for retry
+func (r *RevocationBatchRepository) MarkFailed(id uuid.UUID, message string) error {
+	if _, err := r.db.Exec(`UPDATE revocation_batches SET error = $2 WHERE id = $1`, id, message); err != nil {
+		return fmt.Errorf("failed to update revocation batch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// SidetreeRepository implements the Sidetree repository interface
+type SidetreeRepository struct {
+	db *sql.DB
+}
+
+// NewSidetreeRepository creates a new Sidetree repository
+func NewSidetreeRepository(db *sql.DB) *SidetreeRepository {
+	return &SidetreeRepository{db: db}
+}
+
+// CreateBatch stores a batch with the receipts of its operations in one transaction
+func (r *SidetreeRepository) CreateBatch(batch *domain.SidetreeBatch, receipts []*domain.SidetreeReceipt) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO sidetree_batches (
+			id, anchor_string, core_index_file_uri, chunk_file_uri, anchor_file_hash,
+			operation_count, contract, tx_hash, anchored_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		batch.ID, batch.AnchorString, batch.CoreIndexFileURI, batch.ChunkFileURI, batch.AnchorFileHash,
+		batch.OperationCount, batch.Contract, batch.TxHash, batch.AnchoredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create sidetree batch: %w", err)
+	}
+
+	for _, receipt := range receipts {
+		_, err := tx.Exec(`
+			INSERT INTO sidetree_operations (job_id, batch_id, did_id, did, operation, operation_index)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, receipt.JobID, batch.ID, receipt.DIDID, receipt.DID, receipt.Operation, receipt.OperationIndex)
+		if err != nil {
+			return fmt.Errorf("failed to create sidetree receipt: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sidetree batch: %w", err)
+	}
+
+	return nil
+}
+
+// ListReceipts retrieves the receipts of a DID's operations, oldest first
+func (r *SidetreeRepository) ListReceipts(did string) ([]*domain.SidetreeReceipt, error) {
+	query := `
+		SELECT o.job_id, o.did_id, o.did, o.operation, o.operation_index,
+			b.id, b.anchor_string, b.core_index_file_uri, b.chunk_file_uri, b.anchor_file_hash,
+			b.operation_count, b.contract, b.tx_hash, b.anchored_at
+		FROM sidetree_operations o
+		JOIN sidetree_batches b ON b.id = o.batch_id
+		WHERE o.did = $1
+		ORDER BY b.anchored_at ASC, o.operation_index ASC
+	`
+
+	rows, err := r.db.Query(query, did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sidetree receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []*domain.SidetreeReceipt
+	for rows.Next() {
+		receipt := &domain.SidetreeReceipt{Batch: &domain.SidetreeBatch{}}
+		err := rows.Scan(
+			&receipt.JobID, &receipt.DIDID, &receipt.DID, &receipt.Operation, &receipt.OperationIndex,
+			&receipt.Batch.ID, &receipt.Batch.AnchorString, &receipt.Batch.CoreIndexFileURI,
+			&receipt.Batch.ChunkFileURI, &receipt.Batch.AnchorFileHash, &receipt.Batch.OperationCount,
+			&receipt.Batch.Contract, &receipt.Batch.TxHash, &receipt.Batch.AnchoredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sidetree receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return receipts, nil
+}
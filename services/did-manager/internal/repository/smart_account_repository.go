@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// smartAccountColumns lists the columns selected for every smart account query, in scan order
+const smartAccountColumns = `id, did_id, did, address, owner, factory, entry_point, chain_id, salt, deployed, created_at, updated_at`
+
+// scanSmartAccount scans a single account row selected with smartAccountColumns
+func scanSmartAccount(row rowScanner) (*domain.SmartAccount, error) {
+	var account domain.SmartAccount
+	err := row.Scan(
+		&account.ID,
+		&account.DIDID,
+		&account.Did,
+		&account.Address,
+		&account.Owner,
+		&account.Factory,
+		&account.EntryPoint,
+		&account.ChainID,
+		&account.Salt,
+		&account.Deployed,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SmartAccountRepository implements the smart account repository interface
+type SmartAccountRepository struct {
+	db *sql.DB
+}
+
+// NewSmartAccountRepository creates a new smart account repository
+func NewSmartAccountRepository(db *sql.DB) *SmartAccountRepository {
+	return &SmartAccountRepository{db: db}
+}
+
+// Create stores a new smart account, unless the DID already has one
+func (r *SmartAccountRepository) Create(account *domain.SmartAccount) error {
+	query := `
+		INSERT INTO smart_accounts (id, did_id, did, address, owner, factory, entry_point, chain_id, salt, deployed, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (did_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(query,
+		account.ID,
+		account.DIDID,
+		account.Did,
+		account.Address,
+		account.Owner,
+		account.Factory,
+		account.EntryPoint,
+		account.ChainID,
+		account.Salt,
+		account.Deployed,
+		account.CreatedAt,
+		account.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create smart account: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrSmartAccountExists
+	}
+
+	return nil
+}
+
+// GetByDIDID retrieves the smart account controlling a DID
+func (r *SmartAccountRepository) GetByDIDID(didID uuid.UUID) (*domain.SmartAccount, error) {
+	query := `SELECT ` + smartAccountColumns + ` FROM smart_accounts WHERE did_id = $1`
+
+	account, err := scanSmartAccount(r.db.QueryRow(query, didID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrSmartAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get smart account: %w", err)
+	}
+
+	return account, nil
+}
+
+// MarkDeployed records that an account's contract has been deployed
+func (r *SmartAccountRepository) MarkDeployed(id uuid.UUID) error {
+	query := `UPDATE smart_accounts SET deployed = TRUE, updated_at = $2 WHERE id = $1`
+
+	result, err := r.db.Exec(query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark smart account deployed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrSmartAccountNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+)
+
+// SocialProofChallengeRepository implements the social proof challenge repository
+// interface
+type SocialProofChallengeRepository struct {
+	db *sql.DB
+}
+
+// NewSocialProofChallengeRepository creates a new social proof challenge repository
+func NewSocialProofChallengeRepository(db *sql.DB) *SocialProofChallengeRepository {
+	return &SocialProofChallengeRepository{db: db}
+}
+
+// Create stores an issued challenge
+func (r *SocialProofChallengeRepository) Create(challenge *domain.SocialProofChallenge) error {
+	query := `
+		INSERT INTO social_proof_challenges (id, did, platform, handle, challenge, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Exec(query,
+		challenge.ID,
+		challenge.DID,
+		challenge.Platform,
+		challenge.Handle,
+		challenge.Challenge,
+		challenge.CreatedAt,
+		challenge.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create social proof challenge: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves an unexpired, unused challenge
+func (r *SocialProofChallengeRepository) Get(id uuid.UUID) (*domain.SocialProofChallenge, error) {
+	query := `
+		SELECT id, did, platform, handle, challenge, created_at, expires_at
+		FROM social_proof_challenges
+		WHERE id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	challenge := &domain.SocialProofChallenge{}
+	err := r.db.QueryRow(query, id).Scan(
+		&challenge.ID,
+		&challenge.DID,
+		&challenge.Platform,
+		&challenge.Handle,
+		&challenge.Challenge,
+		&challenge.CreatedAt,
+		&challenge.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSocialChallengeNotFound
+		}
+		return nil, fmt.Errorf("failed to get social proof challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// Consume marks an unexpired, unused challenge used. The check and the update are one
+// statement, so concurrent requests cannot both use a challenge.
+func (r *SocialProofChallengeRepository) Consume(id uuid.UUID) error {
+	query := `
+		UPDATE social_proof_challenges
+		SET consumed_at = NOW()
+		WHERE id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to consume social proof challenge: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrSocialChallengeNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpired removes challenges that expired before the given time
+func (r *SocialProofChallengeRepository) DeleteExpired(before time.Time) error {
+	query := `DELETE FROM social_proof_challenges WHERE expires_at < $1`
+
+	if _, err := r.db.Exec(query, before); err != nil {
+		return fmt.Errorf("failed to delete expired social proof challenges: %w", err)
+	}
+
+	return nil
+}
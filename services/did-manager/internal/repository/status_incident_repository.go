@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// StatusIncidentRepository implements the status incident repository interface
+type StatusIncidentRepository struct {
+	db *sql.DB
+}
+
+// NewStatusIncidentRepository creates a new status incident repository
+func NewStatusIncidentRepository(db *sql.DB) *StatusIncidentRepository {
+	return &StatusIncidentRepository{db: db}
+}
+
+// List retrieves every raised incident flag, oldest first
+func (r *StatusIncidentRepository) List() ([]*domain.StatusIncident, error) {
+	query := `
+		SELECT flag, message, started_at, updated_at
+		FROM status_incidents
+		ORDER BY started_at, flag
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []*domain.StatusIncident
+	for rows.Next() {
+		var incident domain.StatusIncident
+		if err := rows.Scan(&incident.Flag, &incident.Message, &incident.StartedAt, &incident.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status incident: %w", err)
+		}
+		incidents = append(incidents, &incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return incidents, nil
+}
+
+// Upsert raises a flag, or updates the message of a raised flag keeping its start
+func (r *StatusIncidentRepository) Upsert(incident *domain.StatusIncident) error {
+	query := `
+		INSERT INTO status_incidents (flag, message, started_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (flag) DO UPDATE
+		SET message = EXCLUDED.message, updated_at = EXCLUDED.updated_at
+		RETURNING started_at
+	`
+
+	err := r.db.QueryRow(query, incident.Flag, incident.Message, incident.StartedAt, incident.UpdatedAt).Scan(&incident.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save status incident: %w", err)
+	}
+
+	return nil
+}
+
+// Delete clears a flag
+func (r *StatusIncidentRepository) Delete(flag domain.IncidentFlag) error {
+	query := `DELETE FROM status_incidents WHERE flag = $1`
+
+	result, err := r.db.Exec(query, flag)
+	if err != nil {
+		return fmt.Errorf("failed to delete status incident: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrStatusIncidentNotFound
+	}
+
+	return nil
+}
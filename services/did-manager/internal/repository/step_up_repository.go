@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+)
+
+// StepUpChallengeRepository implements the step-up challenge repository interface
+type StepUpChallengeRepository struct {
+	db *sql.DB
+}
+
+// NewStepUpChallengeRepository creates a new step-up challenge repository
+func NewStepUpChallengeRepository(db *sql.DB) *StepUpChallengeRepository {
+	return &StepUpChallengeRepository{db: db}
+}
+
+// Create stores an issued challenge
+func (r *StepUpChallengeRepository) Create(challenge *domain.StepUpChallenge) error {
+	query := `
+		INSERT INTO step_up_challenges (id, did, challenge, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.Exec(query, challenge.ID, challenge.DID, challenge.Challenge, challenge.CreatedAt, challenge.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create step-up challenge: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unexpired, unused challenge used and returns it. The check and the
+// update are one statement, so concurrent requests cannot both use a challenge.
+func (r *StepUpChallengeRepository) Consume(id uuid.UUID) (*domain.StepUpChallenge, error) {
+	query := `
+		UPDATE step_up_challenges
+		SET consumed_at = NOW()
+		WHERE id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+		RETURNING id, did, challenge, created_at, expires_at
+	`
+
+	challenge := &domain.StepUpChallenge{}
+	err := r.db.QueryRow(query, id).Scan(
+		&challenge.ID,
+		&challenge.DID,
+		&challenge.Challenge,
+		&challenge.CreatedAt,
+		&challenge.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrStepUpChallengeInvalid
+		}
+		return nil, fmt.Errorf("failed to consume step-up challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// DeleteExpired removes challenges that expired before the given time
+func (r *StepUpChallengeRepository) DeleteExpired(before time.Time) error {
+	query := `DELETE FROM step_up_challenges WHERE expires_at < $1`
+
+	if _, err := r.db.Exec(query, before); err != nil {
+		return fmt.Errorf("failed to delete expired step-up challenges: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// TrustRegistryRepository implements the trust registry repository interface
+type TrustRegistryRepository struct {
+	db *sql.DB
+}
+
+// NewTrustRegistryRepository creates a new trust registry repository
+func NewTrustRegistryRepository(db *sql.DB) *TrustRegistryRepository {
+	return &TrustRegistryRepository{db: db}
+}
+
+// List retrieves the registry's issuers ordered by DID, with the registry version.
+// Both are read from one snapshot of the database so they agree.
+func (r *TrustRegistryRepository) List() ([]*domain.TrustedIssuer, int64, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int64
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM trust_registry_changes`).Scan(&version); err != nil {
+		return nil, 0, fmt.Errorf("failed to get trust registry version: %w", err)
+	}
+
+	query := `
+		SELECT did, name, credential_types, version, added_at
+		FROM trusted_issuers
+		ORDER BY did
+	`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query trusted issuers: %w", err)
+	}
+	defer rows.Close()
+
+	issuers := []*domain.TrustedIssuer{}
+	for rows.Next() {
+		var issuer domain.TrustedIssuer
+		if err := rows.Scan(&issuer.DID, &issuer.Name, pq.Array(&issuer.CredentialTypes), &issuer.Version, &issuer.AddedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan trusted issuer: %w", err)
+		}
+		issuers = append(issuers, &issuer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return issuers, version, nil
+}
+
+// Add adds an issuer, logging the change whose ID becomes the issuer's version
+func (r *TrustRegistryRepository) Add(issuer *domain.TrustedIssuer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, err := logTrustRegistryChange(tx, issuer.DID, "added")
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO trusted_issuers (did, name, credential_types, version, added_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (did) DO NOTHING
+	`
+
+	result, err := tx.Exec(query, issuer.DID, issuer.Name, pq.Array(issuer.CredentialTypes), version, issuer.AddedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add trusted issuer: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrTrustedIssuerExists
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit trusted issuer: %w", err)
+	}
+	issuer.Version = version
+
+	return nil
+}
+
+// Remove removes an issuer, logging the change whose ID becomes the registry version
+func (r *TrustRegistryRepository) Remove(did string) (*domain.TrustedIssuer, int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM trusted_issuers
+		WHERE did = $1
+		RETURNING did, name, credential_types, version, added_at
+	`
+
+	var issuer domain.TrustedIssuer
+	err = tx.QueryRow(query, did).Scan(&issuer.DID, &issuer.Name, pq.Array(&issuer.CredentialTypes), &issuer.Version, &issuer.AddedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, domain.ErrTrustedIssuerNotFound
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to remove trusted issuer: %w", err)
+	}
+
+	version, err := logTrustRegistryChange(tx, did, "removed")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit trusted issuer removal: %w", err)
+	}
+
+	return &issuer, version, nil
+}
+
+// logTrustRegistryChange logs a change to the registry, returning the new version
+func logTrustRegistryChange(tx *sql.Tx, did, action string) (int64, error) {
+	query := `
+		INSERT INTO trust_registry_changes (did, action)
+		VALUES ($1, $2)
+		RETURNING version
+	`
+
+	var version int64
+	if err := tx.QueryRow(query, did, action).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to log trust registry change: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// UsageRepository implements the usage repository interface
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository creates a new usage repository
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Record stores a billable event
+func (r *UsageRepository) Record(event *domain.UsageEvent) error {
+	query := `
+		INSERT INTO usage_events (tenant, event_type, subject, occurred_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Exec(query, event.Tenant, event.Event, event.Subject, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record usage event: %w", err)
+	}
+
+	return nil
+}
+
+// Count counts a tenant's events of one type since the given time
+func (r *UsageRepository) Count(tenant string, event domain.UsageEventType, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM usage_events WHERE tenant = $1 AND event_type = $2 AND occurred_at >= $3`
+
+	var count int
+	if err := r.db.QueryRow(query, tenant, event, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count usage events: %w", err)
+	}
+
+	return count, nil
+}
+
+// DIDTenant returns the tenant whose creation of a DID was metered, or an empty string
+// for DIDs created before metering, in a sandbox or elsewhere
+func (r *UsageRepository) DIDTenant(did string) (string, error) {
+	query := `SELECT tenant FROM usage_events WHERE event_type = $1 AND subject = $2 ORDER BY occurred_at LIMIT 1`
+
+	var tenant string
+	err := r.db.QueryRow(query, domain.UsageDIDCreated, did).Scan(&tenant)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get DID tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// Summarize counts events per tenant and type over [from, to). Anchored transactions
+// are completed, non-dry-run jobs billed to the tenant whose creation of the DID was
+// metered; jobs for DIDs created before metering are not billed.
+func (r *UsageRepository) Summarize(from, to time.Time, tenant string) ([]*domain.BillingLine, error) {
+	query := `
+		SELECT tenant, event_type, COUNT(*)
+		FROM usage_events
+		WHERE occurred_at >= $1 AND occurred_at < $2 AND ($3 = '' OR tenant = $3)
+		GROUP BY tenant, event_type
+		UNION ALL
+		SELECT e.tenant, $4::VARCHAR, COUNT(*)
+		FROM blockchain_jobs j
+		JOIN usage_events e ON e.event_type = $5 AND e.subject = j.did
+		WHERE j.status = $6 AND NOT j.dry_run AND j.processed_at >= $1 AND j.processed_at < $2
+			AND ($3 = '' OR e.tenant = $3)
+		GROUP BY e.tenant
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.db.Query(query,
+		from,
+		to,
+		tenant,
+		string(domain.UsageAnchoredTransaction),
+		string(domain.UsageDIDCreated),
+		string(domain.JobStatusCompleted),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize usage: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []*domain.BillingLine
+	for rows.Next() {
+		var line domain.BillingLine
+		if err := rows.Scan(&line.Tenant, &line.Event, &line.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan billing line: %w", err)
+		}
+		lines = append(lines, &line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return lines, nil
+}
+
+// Funnel counts the DIDs whose creation was metered over [from, to) per tenant and day
+// (UTC), by whether they were anchored, verified at least once and issued a credential
+func (r *UsageRepository) Funnel(from, to time.Time, tenant string) ([]*domain.FunnelDay, error) {
+	query := `
+		SELECT day, tenant, COUNT(*),
+			COUNT(*) FILTER (WHERE anchored),
+			COUNT(*) FILTER (WHERE verified),
+			COUNT(*) FILTER (WHERE credential_issued)
+		FROM (
+			SELECT
+				to_char(e.occurred_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+				e.tenant,
+				d.blockchain_tx IS NOT NULL AS anchored,
+				EXISTS (
+					SELECT 1 FROM usage_events v WHERE v.event_type = $5 AND v.subject = e.subject
+				) AS verified,
+				EXISTS (
+					SELECT 1 FROM credentials c WHERE c.subject_did = e.subject
+				) AS credential_issued
+			FROM usage_events e
+			LEFT JOIN dids d ON d.did = e.subject
+			WHERE e.event_type = $4 AND e.occurred_at >= $1 AND e.occurred_at < $2
+				AND ($3 = '' OR e.tenant = $3)
+		) funnel
+		GROUP BY day, tenant
+		ORDER BY day, tenant
+	`
+
+	rows, err := r.db.Query(query,
+		from,
+		to,
+		tenant,
+		string(domain.UsageDIDCreated),
+		string(domain.UsageDIDVerification),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DID funnel: %w", err)
+	}
+	defer rows.Close()
+
+	days := []*domain.FunnelDay{}
+	for rows.Next() {
+		var day domain.FunnelDay
+		if err := rows.Scan(&day.Day, &day.Tenant, &day.Created, &day.Anchored, &day.Verified, &day.CredentialIssued); err != nil {
+			return nil, fmt.Errorf("failed to scan funnel day: %w", err)
+		}
+		days = append(days, &day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return days, nil
+}
+
+// ListQuotas retrieves every quota
+func (r *UsageRepository) ListQuotas() ([]*domain.TenantQuota, error) {
+	query := `
+		SELECT tenant, event_type, monthly_limit, updated_at
+		FROM tenant_quotas
+		ORDER BY tenant, event_type
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quotas: %w", err)
+	}
+	defer rows.Close()
+
+	var quotas []*domain.TenantQuota
+	for rows.Next() {
+		var quota domain.TenantQuota
+		if err := rows.Scan(&quota.Tenant, &quota.Event, &quota.MonthlyLimit, &quota.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan quota: %w", err)
+		}
+		quotas = append(quotas, &quota)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// EffectiveQuota returns the tenant's quota for an event, falling back to the default
+func (r *UsageRepository) EffectiveQuota(tenant string, event domain.UsageEventType) (*domain.TenantQuota, error) {
+	query := `
+		SELECT tenant, event_type, monthly_limit, updated_at
+		FROM tenant_quotas
+		WHERE event_type = $2 AND tenant IN ($1, '')
+		ORDER BY tenant DESC
+		LIMIT 1
+	`
+
+	var quota domain.TenantQuota
+	err := r.db.QueryRow(query, tenant, event).Scan(&quota.Tenant, &quota.Event, &quota.MonthlyLimit, &quota.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	return &quota, nil
+}
+
+// UpsertQuota creates or replaces a quota
+func (r *UsageRepository) UpsertQuota(quota *domain.TenantQuota) error {
+	query := `
+		INSERT INTO tenant_quotas (tenant, event_type, monthly_limit, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, event_type) DO UPDATE
+		SET monthly_limit = EXCLUDED.monthly_limit, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(query, quota.Tenant, quota.Event, quota.MonthlyLimit, quota.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save quota: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteQuota removes a quota
+func (r *UsageRepository) DeleteQuota(tenant string, event domain.UsageEventType) error {
+	query := `DELETE FROM tenant_quotas WHERE tenant = $1 AND event_type = $2`
+
+	result, err := r.db.Exec(query, tenant, event)
+	if err != nil {
+		return fmt.Errorf("failed to delete quota: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrQuotaNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// UserHashIndexRepository implements the user hash index repository interface
+type UserHashIndexRepository struct {
+	db *sql.DB
+}
+
+// NewUserHashIndexRepository creates a new user hash index repository
+func NewUserHashIndexRepository(db *sql.DB) *UserHashIndexRepository {
+	return &UserHashIndexRepository{db: db}
+}
+
+// Record stores the lookup indexes of a DID by pepper version
+func (r *UserHashIndexRepository) Record(didID uuid.UUID, indexes map[string]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for version, index := range indexes {
+		_, err := tx.Exec(`
+			INSERT INTO user_hash_index (did_id, pepper_version, lookup)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (did_id, pepper_version) DO UPDATE SET lookup = EXCLUDED.lookup
+		`, didID, version, index)
+		if err != nil {
+			return fmt.Errorf("failed to record user hash index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user hash index: %w", err)
+	}
+
+	return nil
+}
+
+// GetDID retrieves the DID with any of the given indexes
+func (r *UserHashIndexRepository) GetDID(indexes []string) (*domain.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM dids
+		WHERE id IN (SELECT did_id FROM user_hash_index WHERE lookup = ANY($1))
+		LIMIT 1
+	`
+
+	did, err := scanDID(r.db.QueryRow(query, pq.Array(indexes)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDIDNotFound
+		}
+		return nil, fmt.Errorf("failed to get DID by user hash index: %w", err)
+	}
+
+	return did, nil
+}
+
+// ListUnindexed retrieves up to limit DIDs without an index under version, oldest first
+func (r *UserHashIndexRepository) ListUnindexed(version string, limit int) ([]*domain.DID, error) {
+	query := `
+		SELECT ` + didColumns + `
+		FROM dids
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_hash_index
+			WHERE user_hash_index.did_id = dids.id AND user_hash_index.pepper_version = $1
+		)
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, version, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unindexed DIDs: %w", err)
+	}
+	defer rows.Close()
+
+	var dids []*domain.DID
+	for rows.Next() {
+		did, err := scanDID(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan DID: %w", err)
+		}
+		dids = append(dids, did)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return dids, nil
+}
+
+// CountUnindexed counts the DIDs without an index under version
+func (r *UserHashIndexRepository) CountUnindexed(version string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM dids
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_hash_index
+			WHERE user_hash_index.did_id = dids.id AND user_hash_index.pepper_version = $1
+		)
+	`
+
+	var count int
+	if err := r.db.QueryRow(query, version).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unindexed DIDs: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteExcept deletes the indexes under every version but the given ones
+func (r *UserHashIndexRepository) DeleteExcept(versions []string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM user_hash_index WHERE NOT (pepper_version = ANY($1))`, pq.Array(versions))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete retired user hash indexes: %w", err)
+	}
+
+	return result.RowsAffected()
+}
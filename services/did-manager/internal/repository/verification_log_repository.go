@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// VerificationLogRepository implements the verification log repository interface
+type VerificationLogRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationLogRepository creates a new verification log repository
+func NewVerificationLogRepository(db *sql.DB) *VerificationLogRepository {
+	return &VerificationLogRepository{db: db}
+}
+
+// Record stores a verification, keeping the first verification time of a relying party
+// that verified the subject before
+func (r *VerificationLogRepository) Record(record *domain.VerificationRecord) error {
+	query := `
+		INSERT INTO verification_log (verifier_type, verifier_id, subject_kind, subject, first_verified_at, last_verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (subject_kind, subject, verifier_type, verifier_id)
+		DO UPDATE SET last_verified_at = GREATEST(verification_log.last_verified_at, EXCLUDED.last_verified_at)
+	`
+
+	_, err := r.db.Exec(query,
+		record.VerifierType,
+		record.VerifierID,
+		record.SubjectKind,
+		record.Subject,
+		record.FirstVerifiedAt,
+		record.LastVerifiedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record verification: %w", err)
+	}
+
+	return nil
+}
+
+// ListVerifiers lists the relying parties that verified a subject since the given time
+func (r *VerificationLogRepository) ListVerifiers(kind domain.VerifiedSubjectKind, subject string, since time.Time) ([]*domain.VerificationRecord, error) {
+	query := `
+		SELECT verifier_type, verifier_id, subject_kind, subject, first_verified_at, last_verified_at
+		FROM verification_log
+		WHERE subject_kind = $1 AND subject = $2 AND last_verified_at >= $3
+		ORDER BY first_verified_at ASC
+	`
+
+	rows, err := r.db.Query(query, kind, subject, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list verifiers: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*domain.VerificationRecord
+	for rows.Next() {
+		record := &domain.VerificationRecord{}
+		if err := rows.Scan(
+			&record.VerifierType,
+			&record.VerifierID,
+			&record.SubjectKind,
+			&record.Subject,
+			&record.FirstVerifiedAt,
+			&record.LastVerifiedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan verification record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return records, nil
+}
+
+// DeleteBefore removes records last verified before the given time
+func (r *VerificationLogRepository) DeleteBefore(before time.Time) error {
+	query := `DELETE FROM verification_log WHERE last_verified_at < $1`
+
+	if _, err := r.db.Exec(query, before); err != nil {
+		return fmt.Errorf("failed to delete verification records: %w", err)
+	}
+
+	return nil
+}
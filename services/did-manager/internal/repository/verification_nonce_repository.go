@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// VerificationNonceRepository implements the verification nonce repository interface
+type VerificationNonceRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationNonceRepository creates a new verification nonce repository
+func NewVerificationNonceRepository(db *sql.DB) *VerificationNonceRepository {
+	return &VerificationNonceRepository{db: db}
+}
+
+// Create stores an issued nonce
+func (r *VerificationNonceRepository) Create(nonce *domain.VerificationNonce) error {
+	query := `
+		INSERT INTO verification_nonces (nonce, audience, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Exec(query, nonce.Nonce, nonce.Audience, nonce.CreatedAt, nonce.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verification nonce: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an issued nonce of the audience used. The check and the update are one
+// statement, so concurrent requests cannot both use a nonce.
+func (r *VerificationNonceRepository) Consume(nonce, audience string) error {
+	query := `
+		UPDATE verification_nonces
+		SET consumed_at = NOW()
+		WHERE nonce = $1 AND audience = $2 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	result, err := r.db.Exec(query, nonce, audience)
+	if err != nil {
+		return fmt.Errorf("failed to consume verification nonce: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrVerificationNonceInvalid
+	}
+
+	return nil
+}
+
+// DeleteExpired removes nonces that expired before the given time
+func (r *VerificationNonceRepository) DeleteExpired(before time.Time) error {
+	query := `DELETE FROM verification_nonces WHERE expires_at < $1`
+
+	if _, err := r.db.Exec(query, before); err != nil {
+		return fmt.Errorf("failed to delete expired verification nonces: %w", err)
+	}
+
+	return nil
+}
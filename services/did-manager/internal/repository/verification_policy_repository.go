@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// VerificationPolicyRepository implements the verification policy repository interface
+type VerificationPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationPolicyRepository creates a new verification policy repository
+func NewVerificationPolicyRepository(db *sql.DB) *VerificationPolicyRepository {
+	return &VerificationPolicyRepository{db: db}
+}
+
+// List retrieves every verification policy
+func (r *VerificationPolicyRepository) List() ([]*domain.VerificationPolicy, error) {
+	query := `
+		SELECT tenant, endpoint, fallback, updated_at
+		FROM verification_policies
+		ORDER BY tenant, endpoint
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query verification policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*domain.VerificationPolicy
+	for rows.Next() {
+		policy, err := scanVerificationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan verification policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Effective returns the most specific policy for a tenant and endpoint
+func (r *VerificationPolicyRepository) Effective(tenant string, endpoint domain.VerificationEndpoint) (*domain.VerificationPolicy, error) {
+	query := `
+		SELECT tenant, endpoint, fallback, updated_at
+		FROM verification_policies
+		WHERE tenant IN ($1, '') AND endpoint IN ($2, '')
+		ORDER BY tenant DESC, endpoint DESC
+		LIMIT 1
+	`
+
+	policy, err := scanVerificationPolicy(r.db.QueryRow(query, tenant, endpoint))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get verification policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Upsert creates or replaces a verification policy
+func (r *VerificationPolicyRepository) Upsert(policy *domain.VerificationPolicy) error {
+	query := `
+		INSERT INTO verification_policies (tenant, endpoint, fallback, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, endpoint) DO UPDATE
+		SET fallback = EXCLUDED.fallback, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(query, policy.Tenant, policy.Endpoint, policy.Fallback, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save verification policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a verification policy
+func (r *VerificationPolicyRepository) Delete(tenant string, endpoint domain.VerificationEndpoint) error {
+	query := `DELETE FROM verification_policies WHERE tenant = $1 AND endpoint = $2`
+
+	result, err := r.db.Exec(query, tenant, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete verification policy: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrVerificationPolicyNotFound
+	}
+
+	return nil
+}
+
+// scanVerificationPolicy scans a single verification policy row
+func scanVerificationPolicy(row rowScanner) (*domain.VerificationPolicy, error) {
+	var policy domain.VerificationPolicy
+	if err := row.Scan(&policy.Tenant, &policy.Endpoint, &policy.Fallback, &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
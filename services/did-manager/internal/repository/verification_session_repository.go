@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// verificationSessionColumns lists the columns selected for every session query, in scan order
+const verificationSessionColumns = `id, verifier_type, verifier_id, challenge, domain, credential_types, status,
+	COALESCE(holder_did, ''), COALESCE(message, ''), presentation, created_at, expires_at, completed_at`
+
+// scanVerificationSession scans a single session row selected with verificationSessionColumns
+func scanVerificationSession(row rowScanner) (*domain.VerificationSession, error) {
+	var session domain.VerificationSession
+	var presentation []byte
+	err := row.Scan(
+		&session.ID,
+		&session.VerifierType,
+		&session.VerifierID,
+		&session.Challenge,
+		&session.Domain,
+		pq.Array(&session.CredentialTypes),
+		&session.Status,
+		&session.HolderDID,
+		&session.Message,
+		&presentation,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.Presentation = presentation
+	return &session, nil
+}
+
+// VerificationSessionRepository implements the verification session repository interface
+type VerificationSessionRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationSessionRepository creates a new verification session repository
+func NewVerificationSessionRepository(db *sql.DB) *VerificationSessionRepository {
+	return &VerificationSessionRepository{db: db}
+}
+
+// Create stores a new verification session
+func (r *VerificationSessionRepository) Create(session *domain.VerificationSession) error {
+	query := `
+		INSERT INTO verification_sessions (id, verifier_type, verifier_id, challenge, domain, credential_types, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.Exec(query,
+		session.ID,
+		session.VerifierType,
+		session.VerifierID,
+		session.Challenge,
+		session.Domain,
+		pq.Array(session.CredentialTypes),
+		session.Status,
+		session.CreatedAt,
+		session.ExpiresAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create verification session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a verification session by ID
+func (r *VerificationSessionRepository) GetByID(id uuid.UUID) (*domain.VerificationSession, error) {
+	query := `SELECT ` + verificationSessionColumns + ` FROM verification_sessions WHERE id = $1`
+
+	session, err := scanVerificationSession(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrVerificationSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get verification session: %w", err)
+	}
+
+	return session, nil
+}
+
+// MarkScanned moves a pending session to scanned; sessions in any other state are left as is
+func (r *VerificationSessionRepository) MarkScanned(id uuid.UUID) error {
+	query := `UPDATE verification_sessions SET status = $2 WHERE id = $1 AND status = $3`
+
+	if _, err := r.db.Exec(query, id, domain.VerificationSessionScanned, domain.VerificationSessionPending); err != nil {
+		return fmt.Errorf("failed to update verification session: %w", err)
+	}
+
+	return nil
+}
+
+// Complete records the outcome of a session that is still open, so a session can
+// only ever be answered once
+func (r *VerificationSessionRepository) Complete(session *domain.VerificationSession) error {
+	query := `
+		UPDATE verification_sessions
+		SET status = $2, holder_did = $3, message = $4, presentation = $5, completed_at = $6
+		WHERE id = $1 AND status IN ($7, $8)
+	`
+
+	var presentation []byte
+	if len(session.Presentation) > 0 {
+		presentation = session.Presentation
+	}
+
+	result, err := r.db.Exec(query,
+		session.ID,
+		session.Status,
+		session.HolderDID,
+		session.Message,
+		presentation,
+		session.CompletedAt,
+		domain.VerificationSessionPending,
+		domain.VerificationSessionScanned,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete verification session: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrVerificationSessionClosed
+	}
+
+	return nil
+}
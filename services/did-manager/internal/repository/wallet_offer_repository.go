@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WalletOfferRepository implements the wallet offer repository interface
+type WalletOfferRepository struct {
+	db *sql.DB
+}
+
+// NewWalletOfferRepository creates a new wallet offer repository
+func NewWalletOfferRepository(db *sql.DB) *WalletOfferRepository {
+	return &WalletOfferRepository{db: db}
+}
+
+// Create stores a new wallet offer
+func (r *WalletOfferRepository) Create(offer *domain.WalletOffer) error {
+	query := `
+		INSERT INTO wallet_offers (id, did_id, credential_ids, code_hash, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	credentialIDs := make([]string, len(offer.CredentialIDs))
+	for i, id := range offer.CredentialIDs {
+		credentialIDs[i] = id.String()
+	}
+
+	_, err := r.db.Exec(query,
+		offer.ID,
+		offer.DIDID,
+		pq.Array(credentialIDs),
+		offer.CodeHash,
+		offer.Status,
+		offer.CreatedAt,
+		offer.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet offer: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a wallet offer by ID together with its DID
+func (r *WalletOfferRepository) GetByID(id uuid.UUID) (*domain.WalletOffer, error) {
+	query := `
+		SELECT o.id, o.did_id, d.did, o.credential_ids, o.code_hash, o.status, o.created_at, o.expires_at, o.claimed_at
+		FROM wallet_offers o
+		JOIN dids d ON d.id = o.did_id
+		WHERE o.id = $1
+	`
+
+	var offer domain.WalletOffer
+	var credentialIDs []string
+	err := r.db.QueryRow(query, id).Scan(
+		&offer.ID,
+		&offer.DIDID,
+		&offer.DID,
+		pq.Array(&credentialIDs),
+		&offer.CodeHash,
+		&offer.Status,
+		&offer.CreatedAt,
+		&offer.ExpiresAt,
+		&offer.ClaimedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWalletOfferNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet offer: %w", err)
+	}
+
+	offer.CredentialIDs = make([]uuid.UUID, 0, len(credentialIDs))
+	for _, credentialID := range credentialIDs {
+		parsed, err := uuid.Parse(credentialID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse offered credential ID: %w", err)
+		}
+		offer.CredentialIDs = append(offer.CredentialIDs, parsed)
+	}
+
+	return &offer, nil
+}
+
+// Close moves a pending offer to a final status, recording closedAt as the claim time
+// of claimed offers
+func (r *WalletOfferRepository) Close(id uuid.UUID, status domain.WalletOfferStatus, closedAt time.Time) error {
+	query := `
+		UPDATE wallet_offers
+		SET status = $2, claimed_at = $3
+		WHERE id = $1 AND status = $4
+	`
+
+	var claimedAt *time.Time
+	if status == domain.WalletOfferClaimed {
+		claimedAt = &closedAt
+	}
+
+	result, err := r.db.Exec(query, id, status, claimedAt, domain.WalletOfferPending)
+	if err != nil {
+		return fmt.Errorf("failed to close wallet offer: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrWalletOfferClosed
+	}
+
+	return nil
+}
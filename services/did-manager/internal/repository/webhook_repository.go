@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// webhookEndpointColumns lists the columns selected for every endpoint query, in scan
+// order
+const webhookEndpointColumns = `id, tenant_type, tenant, url, event_types, headers, secret, max_attempts,
+	backoff_seconds, active, created_at, updated_at, encrypt_to_did`
+
+// webhookDeliveryColumns lists the columns selected for every delivery query, in scan
+// order
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, created_at, updated_at, replay_id`
+
+// scanWebhookEndpoint scans a single endpoint row selected with webhookEndpointColumns
+func scanWebhookEndpoint(row rowScanner) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	var headers []byte
+	var encryptToDID sql.NullString
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.TenantType,
+		&endpoint.Tenant,
+		&endpoint.URL,
+		pq.Array(&endpoint.EventTypes),
+		&headers,
+		&endpoint.Secret,
+		&endpoint.MaxAttempts,
+		&endpoint.BackoffSeconds,
+		&endpoint.Active,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+		&encryptToDID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.EncryptToDID = encryptToDID.String
+
+	if err := json.Unmarshal(headers, &endpoint.Headers); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook headers: %w", err)
+	}
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+
+	return &endpoint, nil
+}
+
+// scanWebhookDelivery scans a single delivery row selected with webhookDeliveryColumns
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.EndpointID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+		&delivery.ReplayID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+// WebhookRepository implements the webhook repository interface
+type WebhookRepository struct {
+	db *sql.DB
+	// clock tells which deliveries are due
+	clock clock.Clock
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db, clock: clock.System}
+}
+
+// SetClock sets the time source telling which deliveries are due, clock.System by
+// default; it must be the clock the webhook service schedules deliveries on
+func (r *WebhookRepository) SetClock(clk clock.Clock) {
+	r.clock = clk
+}
+
+// CreateEndpoint stores a new webhook endpoint
+func (r *WebhookRepository) CreateEndpoint(endpoint *domain.WebhookEndpoint) error {
+	headers, err := json.Marshal(endpoint.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook headers: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_endpoints (id, tenant_type, tenant, url, event_types, headers, secret,
+			max_attempts, backoff_seconds, active, created_at, updated_at, encrypt_to_did)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = r.db.Exec(query,
+		endpoint.ID,
+		endpoint.TenantType,
+		endpoint.Tenant,
+		endpoint.URL,
+		pq.Array(endpoint.EventTypes),
+		headers,
+		endpoint.Secret,
+		endpoint.MaxAttempts,
+		endpoint.BackoffSeconds,
+		endpoint.Active,
+		endpoint.CreatedAt,
+		endpoint.UpdatedAt,
+		sql.NullString{String: endpoint.EncryptToDID, Valid: endpoint.EncryptToDID != ""},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID
+func (r *WebhookRepository) GetEndpoint(id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// UpdateEndpoint replaces the configuration and secret of a webhook endpoint
+func (r *WebhookRepository) UpdateEndpoint(endpoint *domain.WebhookEndpoint) error {
+	headers, err := json.Marshal(endpoint.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook headers: %w", err)
+	}
+
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $2, event_types = $3, headers = $4, secret = $5, max_attempts = $6,
+			backoff_seconds = $7, active = $8, updated_at = $9, encrypt_to_did = $10
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query,
+		endpoint.ID,
+		endpoint.URL,
+		pq.Array(endpoint.EventTypes),
+		headers,
+		endpoint.Secret,
+		endpoint.MaxAttempts,
+		endpoint.BackoffSeconds,
+		endpoint.Active,
+		endpoint.UpdatedAt,
+		sql.NullString{String: endpoint.EncryptToDID, Valid: endpoint.EncryptToDID != ""},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// DeleteEndpoint removes a webhook endpoint; its deliveries cascade
+func (r *WebhookRepository) DeleteEndpoint(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListEndpointsByTenant retrieves a tenant's webhook endpoints, oldest first
+func (r *WebhookRepository) ListEndpointsByTenant(tenantType domain.CallerType, tenant string) ([]*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE tenant_type = $1 AND tenant = $2
+		ORDER BY created_at ASC
+	`
+
+	return r.queryEndpoints(query, tenantType, tenant)
+}
+
+// ListActiveEndpoints retrieves the active endpoints subscribed to an event type,
+// explicitly or by subscribing to every type
+func (r *WebhookRepository) ListActiveEndpoints(eventType string) ([]*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE active AND (cardinality(event_types) = 0 OR $1 = ANY(event_types))
+	`
+
+	return r.queryEndpoints(query, eventType)
+}
+
+func (r *WebhookRepository) queryEndpoints(query string, args ...any) ([]*domain.WebhookEndpoint, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// CreateDelivery stores a delivery to attempt. An event already delivered to the
+// endpoint, e.g. redelivered by the stream, is ignored; replays deliver events under
+// their own event IDs.
+func (r *WebhookRepository) CreateDelivery(delivery *domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, status,
+			attempts, next_attempt_at, created_at, updated_at, replay_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(query,
+		delivery.ID,
+		delivery.EndpointID,
+		delivery.EventID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+		delivery.ReplayID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDelivery retrieves a webhook delivery by ID
+func (r *WebhookRepository) GetDelivery(id uuid.UUID) (*domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries retrieves an endpoint's deliveries, newest first
+func (r *WebhookRepository) ListDeliveries(endpointID uuid.UUID, query *domain.WebhookDeliveryQuery) ([]*domain.WebhookDelivery, error) {
+	statement := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	return r.queryDeliveries(statement, endpointID, query.Status, query.Limit)
+}
+
+// ClaimDueDeliveries retrieves the pending deliveries whose next attempt is due, oldest
+// first, and postpones them by lease in the same statement
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	statement := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $4::timestamptz + $3 * INTERVAL '1 millisecond', updated_at = $4
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $1 AND next_attempt_at <= $4
+			ORDER BY next_attempt_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	return r.queryDeliveries(statement, domain.WebhookDeliveryPending, limit, lease.Milliseconds(), r.clock.Now())
+}
+
+func (r *WebhookRepository) queryDeliveries(query string, args ...any) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt adds an attempt to a delivery's history and updates the delivery in
+// one transaction
+func (r *WebhookRepository) RecordAttempt(id uuid.UUID, attempt *domain.WebhookAttempt, status domain.WebhookDeliveryStatus, nextAttemptAt *time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, id, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, updated_at = $5
+		WHERE id = $1
+	`, id, status, attempt.Attempt, nextAttemptAt, attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttempts retrieves a delivery's attempts, oldest first
+func (r *WebhookRepository) ListAttempts(deliveryID uuid.UUID) ([]*domain.WebhookAttempt, error) {
+	query := `
+		SELECT attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt ASC
+	`
+
+	rows, err := r.db.Query(query, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*domain.WebhookAttempt
+	for rows.Next() {
+		var attempt domain.WebhookAttempt
+		err := rows.Scan(
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.AttemptedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return attempts, nil
+}
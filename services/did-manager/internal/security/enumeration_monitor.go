@@ -0,0 +1,89 @@
+package security
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EnumerationMonitor tracks resolution misses per caller and raises an alert when a
+// caller looks up many unknown DIDs in a short window, the typical scraping pattern
+type EnumerationMonitor struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	callers   map[string]*missWindow
+	now       func() time.Time
+}
+
+type missWindow struct {
+	start   time.Time
+	misses  int
+	flagged bool
+}
+
+// SuspectedCaller describes a caller that crossed the miss threshold
+type SuspectedCaller struct {
+	Key         string    `json:"key"`
+	Misses      int       `json:"misses"`
+	WindowStart time.Time `json:"window_start"`
+}
+
+// NewEnumerationMonitor creates a monitor alerting after threshold misses within window
+func NewEnumerationMonitor(threshold int, window time.Duration) *EnumerationMonitor {
+	return &EnumerationMonitor{
+		threshold: threshold,
+		window:    window,
+		callers:   make(map[string]*missWindow),
+		now:       time.Now,
+	}
+}
+
+// RecordMiss records a lookup of an unknown DID by the given caller
+func (m *EnumerationMonitor) RecordMiss(key string) {
+	if m.threshold <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	w, ok := m.callers[key]
+	if !ok || now.Sub(w.start) > m.window {
+		w = &missWindow{start: now}
+		m.callers[key] = w
+	}
+
+	w.misses++
+	if w.misses >= m.threshold && !w.flagged {
+		w.flagged = true
+		log.Printf("ALERT: possible DID enumeration by %s: %d unknown DIDs looked up since %s",
+			key, w.misses, w.start.Format(time.RFC3339))
+	}
+}
+
+// Suspects returns callers flagged within their current window, most misses first
+func (m *EnumerationMonitor) Suspects() []SuspectedCaller {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	suspects := []SuspectedCaller{}
+	for key, w := range m.callers {
+		if now.Sub(w.start) > m.window {
+			delete(m.callers, key)
+			continue
+		}
+		if w.flagged {
+			suspects = append(suspects, SuspectedCaller{Key: key, Misses: w.misses, WindowStart: w.start})
+		}
+	}
+
+	sort.Slice(suspects, func(i, j int) bool {
+		return suspects[i].Misses > suspects[j].Misses
+	})
+
+	return suspects
+}
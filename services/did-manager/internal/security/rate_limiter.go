@@ -0,0 +1,89 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long an unused bucket is kept before being swept
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimiter is an in-memory token bucket limiter keyed by caller
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens added per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per key with the given burst
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow consumes a token for key and reports whether the request may proceed
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.lastSeen).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// RetryAfter returns how long a caller should wait before a token is available
+func (l *RateLimiter) RetryAfter() time.Duration {
+	if l.rate <= 0 {
+		return time.Minute
+	}
+	return time.Duration(float64(time.Second) / l.rate)
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,50 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	current := time.Now()
+	limiter := NewRateLimiter(60, 2)
+	limiter.now = func() time.Time { return current }
+
+	if !limiter.Allow("a") || !limiter.Allow("a") {
+		t.Fatal("burst requests should be allowed")
+	}
+	if limiter.Allow("a") {
+		t.Fatal("request beyond burst should be rejected")
+	}
+	if !limiter.Allow("b") {
+		t.Fatal("other keys should have their own bucket")
+	}
+
+	current = current.Add(time.Second)
+	if !limiter.Allow("a") {
+		t.Fatal("bucket should refill one token per second")
+	}
+}
+
+func TestEnumerationMonitorFlagsCaller(t *testing.T) {
+	current := time.Now()
+	monitor := NewEnumerationMonitor(3, time.Minute)
+	monitor.now = func() time.Time { return current }
+
+	monitor.RecordMiss("ip:10.0.0.1")
+	monitor.RecordMiss("ip:10.0.0.1")
+	if len(monitor.Suspects()) != 0 {
+		t.Fatal("caller should not be flagged below threshold")
+	}
+
+	monitor.RecordMiss("ip:10.0.0.1")
+	suspects := monitor.Suspects()
+	if len(suspects) != 1 || suspects[0].Misses != 3 {
+		t.Fatalf("expected caller to be flagged, got %+v", suspects)
+	}
+
+	current = current.Add(2 * time.Minute)
+	if len(monitor.Suspects()) != 0 {
+		t.Fatal("flag should expire with the window")
+	}
+}
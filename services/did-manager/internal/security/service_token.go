@@ -0,0 +1,156 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DID Manager operation scopes, as issued by auth-service to service clients
+const (
+	ScopeDIDRead   = "did:read"
+	ScopeDIDWrite  = "did:write"
+	ScopeVCIssue   = "vc:issue"
+	ScopeVCVerify  = "vc:verify"
+	ScopeAdminJobs = "admin:jobs"
+)
+
+// TenantScopes are the scopes of the operations tenants perform on their own DIDs and
+// credentials, without the admin:jobs scope of service clients registered by an admin
+var TenantScopes = []string{ScopeDIDRead, ScopeDIDWrite, ScopeVCIssue, ScopeVCVerify}
+
+// serviceTokenType is the "type" claim of service access tokens
+const serviceTokenType = "service_access"
+
+// ErrInvalidServiceToken is returned for tokens that fail signature, expiry or claim checks
+var ErrInvalidServiceToken = errors.New("invalid service access token")
+
+// operationScopes maps the routes service tokens may call, by "METHOD path", to the
+// scope each needs. Routes not listed are refused to service tokens.
+var operationScopes = map[string]string{
+	"GET /api/v1/did/status/:did":   ScopeDIDRead,
+	"GET /api/v1/did/receipts/:did": ScopeDIDRead,
+	"GET /api/v1/did/anchor/:did":   ScopeDIDRead,
+	"GET /api/v1/did/resolve/:did":  ScopeDIDRead,
+	"GET /api/v1/did/user/:userID":  ScopeDIDRead,
+	"GET /api/v1/did/claims/:did":   ScopeDIDRead,
+	"GET /1.0/identifiers/:did":     ScopeDIDRead,
+
+	"POST /api/v1/did":            ScopeDIDWrite,
+	"POST /api/v1/did/deactivate": ScopeDIDWrite,
+	"POST /api/v1/did/claims":     ScopeDIDWrite,
+	"POST /1.0/create":            ScopeDIDWrite,
+	"POST /1.0/deactivate":        ScopeDIDWrite,
+
+	"POST /api/v1/credentials":            ScopeVCIssue,
+	"POST /api/v1/credentials/:id/revoke": ScopeVCIssue,
+	"POST /api/v1/credentials/age":        ScopeVCIssue,
+	"GET /api/v1/credentials/search":      ScopeVCIssue,
+	"POST /api/v1/delegations":            ScopeVCIssue,
+
+	"POST /api/v1/did/verify":             ScopeVCVerify,
+	"POST /api/v1/did/verify/nonce":       ScopeVCVerify,
+	"POST /api/v1/did/verify/signed":      ScopeVCVerify,
+	"POST /api/v1/did/claims/verify":      ScopeVCVerify,
+	"POST /api/v1/credentials/verify":     ScopeVCVerify,
+	"POST /api/v1/credentials/age/verify": ScopeVCVerify,
+	"POST /api/v1/delegations/verify":     ScopeVCVerify,
+	"GET /api/v1/revocation/root":         ScopeVCVerify,
+	"GET /api/v1/revocation/proofs/:id":   ScopeVCVerify,
+
+	"GET /api/v1/admin/jobs":              ScopeAdminJobs,
+	"POST /api/v1/admin/jobs/simulate":    ScopeAdminJobs,
+	"GET /api/v1/admin/jobs/:id":          ScopeAdminJobs,
+	"GET /api/v1/admin/jobs/:id/timeline": ScopeAdminJobs,
+	"POST /api/v1/admin/jobs/:id/cancel":  ScopeAdminJobs,
+	"GET /api/v1/admin/archive/jobs":      ScopeAdminJobs,
+	"POST /api/v1/admin/archive/jobs":     ScopeAdminJobs,
+	"GET /api/v1/admin/archive/jobs/:id":  ScopeAdminJobs,
+}
+
+// OperationScope returns the scope a service token needs to call the route registered
+// as fullPath, or "" when service tokens may not call it
+func OperationScope(method, fullPath string) string {
+	return operationScopes[method+" "+fullPath]
+}
+
+// ServiceClaims identifies the service client behind a request and the tenant it acts as
+type ServiceClaims struct {
+	ClientID string
+	// Tenant is the ID of the DID Manager API key the client acts as
+	Tenant string
+	Scopes []string
+}
+
+// IsServiceToken reports whether a bearer token claims to be a service token, without
+// verifying it, so tokens meant for the wallet API are left to WalletAuth
+func IsServiceToken(tokenString string) bool {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	tokenType, _ := claims["type"].(string)
+	return tokenType == serviceTokenType
+}
+
+// ServiceTokenVerifier verifies service access tokens issued by auth-service
+type ServiceTokenVerifier struct {
+	secret []byte
+}
+
+// NewServiceTokenVerifier creates a verifier for tokens signed with the shared service token secret
+func NewServiceTokenVerifier(secret string) *ServiceTokenVerifier {
+	return &ServiceTokenVerifier{secret: []byte(secret)}
+}
+
+// Enabled reports whether a secret is configured
+func (v *ServiceTokenVerifier) Enabled() bool {
+	return len(v.secret) > 0
+}
+
+// Verify validates a service access token and returns its claims
+func (v *ServiceTokenVerifier) Verify(tokenString string) (*ServiceClaims, error) {
+	if !v.Enabled() {
+		return nil, ErrInvalidServiceToken
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return v.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidServiceToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidServiceToken
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrInvalidServiceToken
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != serviceTokenType {
+		return nil, ErrInvalidServiceToken
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	tenant, _ := claims["tenant"].(string)
+	scope, _ := claims["scope"].(string)
+	if clientID == "" || tenant == "" {
+		return nil, ErrInvalidServiceToken
+	}
+
+	return &ServiceClaims{
+		ClientID: clientID,
+		Tenant:   tenant,
+		Scopes:   strings.Fields(scope),
+	}, nil
+}
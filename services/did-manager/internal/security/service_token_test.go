@@ -0,0 +1,82 @@
+package security
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestServiceTokenVerifier(t *testing.T) {
+	secret := "service-secret"
+	verifier := NewServiceTokenVerifier(secret)
+
+	valid := jwt.MapClaims{
+		"client_id": "sc_client",
+		"tenant":    "3f1c0d5e-8a4b-4c2d-9e6f-7a8b9c0d1e2f",
+		"scope":     ScopeDIDRead + " " + ScopeVCVerify,
+		"exp":       time.Now().Add(time.Minute).Unix(),
+		"type":      "service_access",
+	}
+
+	token := signWalletToken(t, secret, valid)
+	if !IsServiceToken(token) {
+		t.Fatal("expected a service token to be recognized")
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+	if claims.ClientID != "sc_client" || claims.Tenant != valid["tenant"] || len(claims.Scopes) != 2 {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	wallet := signWalletToken(t, secret, jwt.MapClaims{
+		"user_id": "0b6a1a52-5f3e-4d8e-a6a1-9c2f4e7d3b20", "exp": valid["exp"], "type": "wallet_access",
+	})
+	if IsServiceToken(wallet) || IsServiceToken("not a token") {
+		t.Error("expected only service tokens to be recognized")
+	}
+
+	cases := map[string]string{
+		"wrong secret": signWalletToken(t, "other-secret", valid),
+		"wallet token": wallet,
+		"no tenant": signWalletToken(t, secret, jwt.MapClaims{
+			"client_id": "sc_client", "scope": ScopeDIDRead, "exp": valid["exp"], "type": "service_access",
+		}),
+		"expired": signWalletToken(t, secret, jwt.MapClaims{
+			"client_id": "sc_client", "tenant": valid["tenant"], "exp": time.Now().Add(-time.Minute).Unix(), "type": "service_access",
+		}),
+	}
+	for name, token := range cases {
+		if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidServiceToken) {
+			t.Errorf("%s: expected ErrInvalidServiceToken, got %v", name, err)
+		}
+	}
+
+	if _, err := NewServiceTokenVerifier("").Verify(token); !errors.Is(err, ErrInvalidServiceToken) {
+		t.Errorf("expected a disabled verifier to reject tokens, got %v", err)
+	}
+}
+
+func TestOperationScope(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/api/v1/did/resolve/:did", ScopeDIDRead},
+		{http.MethodPost, "/api/v1/did", ScopeDIDWrite},
+		{http.MethodPost, "/api/v1/credentials", ScopeVCIssue},
+		{http.MethodPost, "/api/v1/credentials/verify", ScopeVCVerify},
+		{http.MethodPost, "/api/v1/admin/jobs/:id/cancel", ScopeAdminJobs},
+		// Other admin routes stay behind the admin key
+		{http.MethodPost, "/api/v1/admin/api-keys", ""},
+		{http.MethodGet, "/api/v1/did", ""},
+	}
+	for _, tt := range tests {
+		if got := OperationScope(tt.method, tt.path); got != tt.want {
+			t.Errorf("OperationScope(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
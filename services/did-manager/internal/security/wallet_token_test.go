@@ -0,0 +1,66 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func signWalletToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestWalletTokenVerifier(t *testing.T) {
+	secret := "wallet-secret"
+	verifier := NewWalletTokenVerifier(secret)
+	userID := uuid.New()
+
+	valid := jwt.MapClaims{
+		"user_id":   userID.String(),
+		"client_id": "wc_client",
+		"scope":     ScopeDIDsRead + " " + ScopeCredentialsRead,
+		"exp":       time.Now().Add(time.Minute).Unix(),
+		"type":      "wallet_access",
+	}
+
+	claims, err := verifier.Verify(signWalletToken(t, secret, valid))
+	if err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+	if claims.UserID != userID || claims.ClientID != "wc_client" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if !claims.HasScope(ScopeCredentialsRead) || claims.HasScope(ScopePresentationsCreate) {
+		t.Errorf("unexpected scopes: %v", claims.Scopes)
+	}
+
+	cases := map[string]string{
+		"wrong secret": signWalletToken(t, "other-secret", valid),
+		"session token": signWalletToken(t, secret, jwt.MapClaims{
+			"user_id": userID.String(), "exp": valid["exp"], "type": "access",
+		}),
+		"expired": signWalletToken(t, secret, jwt.MapClaims{
+			"user_id": userID.String(), "exp": time.Now().Add(-time.Minute).Unix(), "type": "wallet_access",
+		}),
+		"no expiry": signWalletToken(t, secret, jwt.MapClaims{
+			"user_id": userID.String(), "type": "wallet_access",
+		}),
+	}
+	for name, token := range cases {
+		if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidWalletToken) {
+			t.Errorf("%s: expected ErrInvalidWalletToken, got %v", name, err)
+		}
+	}
+
+	if NewWalletTokenVerifier("").Enabled() {
+		t.Error("verifier without a secret should be disabled")
+	}
+}
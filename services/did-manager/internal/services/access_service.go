@@ -0,0 +1,586 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix marks plaintext keys issued by this service
+const apiKeyPrefix = "dm_"
+
+// maxSignatureSkew bounds how old or how far in the future a signed caller timestamp may be
+const maxSignatureSkew = 5 * time.Minute
+
+// AccessService authenticates callers and enforces per-DID access control
+type AccessService struct {
+	apiKeyRepo domain.APIKeyRepository
+	aclRepo    domain.ACLRepository
+	didRepo    domain.DIDRepository
+	registry   *did.Registry
+	// deviceKeys verifies callers signing with a device subkey; nil when disabled
+	deviceKeys *DeviceKeyService
+	// serviceTokens verifies service tokens issued by auth-service; nil when disabled
+	serviceTokens *security.ServiceTokenVerifier
+	// ucanResolver resolves the keys UCANs are signed with; nil when UCANs are disabled
+	ucanResolver *ResolverService
+	// ucanAudience is the DID UCANs are invoked at, identifying this service
+	ucanAudience string
+	// clock checks the validity of UCAN chains and signed request timestamps
+	clock clock.Clock
+}
+
+// NewAccessService creates a new access service
+func NewAccessService(
+	apiKeyRepo domain.APIKeyRepository,
+	aclRepo domain.ACLRepository,
+	didRepo domain.DIDRepository,
+	registry *did.Registry,
+) *AccessService {
+	return &AccessService{
+		apiKeyRepo: apiKeyRepo,
+		aclRepo:    aclRepo,
+		didRepo:    didRepo,
+		registry:   registry,
+		clock:      clock.System,
+	}
+}
+
+// SetClock sets the time source of UCAN chain validity and signed request skew, clock.System by default
+func (s *AccessService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetDeviceKeys lets DID-authenticated callers sign with one of the DID's active device
+// keys instead of the DID's own key
+func (s *AccessService) SetDeviceKeys(keys *DeviceKeyService) {
+	s.deviceKeys = keys
+}
+
+// SetServiceTokens lets service clients of auth-service call as the tenant their
+// service token names, restricted to the token's scopes
+func (s *AccessService) SetServiceTokens(verifier *security.ServiceTokenVerifier) {
+	s.serviceTokens = verifier
+}
+
+// SetUCANs lets services call as a tenant with UCANs rooted at the tenant's root DID
+// and invoked at audience, resolving the keys of each link with resolver
+func (s *AccessService) SetUCANs(resolver *ResolverService, audience string) {
+	s.ucanResolver = resolver
+	s.ucanAudience = audience
+}
+
+// CreateAPIKey issues a new API key; the plaintext key is only returned here
+func (s *AccessService) CreateAPIKey(req *domain.APIKeyCreateRequest) (*domain.APIKeyCreateResponse, error) {
+	plaintext, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Prefix:    apiKeyDisplayPrefix(plaintext),
+		KeyHash:   hashAPIKey(plaintext),
+		Status:    string(domain.APIKeyStatusActive),
+		CreatedAt: s.clock.Now(),
+		Sandbox:   req.Sandbox,
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return &domain.APIKeyCreateResponse{APIKey: key, Key: plaintext}, nil
+}
+
+// ListAPIKeys lists all issued API keys
+func (s *AccessService) ListAPIKeys() ([]*domain.APIKey, error) {
+	return s.apiKeyRepo.List()
+}
+
+// RotateAPIKey replaces the secret of an active API key. The key keeps its ID, so the
+// tenant's usage, quotas, ACL grants and webhooks carry over; the previous secret stops
+// working at once. The new plaintext key is only returned here.
+func (s *AccessService) RotateAPIKey(id uuid.UUID) (*domain.APIKeyCreateResponse, error) {
+	plaintext, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.Rotate(id, apiKeyDisplayPrefix(plaintext), hashAPIKey(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: rotated API key %s (%s)", key.ID, key.Name)
+	return &domain.APIKeyCreateResponse{APIKey: key, Key: plaintext}, nil
+}
+
+// RevokeAPIKey revokes an API key along with its sub-keys
+func (s *AccessService) RevokeAPIKey(id uuid.UUID) error {
+	return s.apiKeyRepo.Revoke(id)
+}
+
+// SetRootDID binds the root capability of a tenant key to a DID, which may then
+// delegate the tenant's operations to services with UCANs. An empty DID unbinds it.
+func (s *AccessService) SetRootDID(id uuid.UUID, rootDID string) (*domain.APIKey, error) {
+	if rootDID != "" {
+		if !did.ValidSyntax(rootDID) {
+			return nil, domain.ErrInvalidRootDID
+		}
+		holder, err := s.apiKeyRepo.GetByRootDID(rootDID)
+		switch {
+		case err == nil && holder.ID != id:
+			return nil, domain.ErrRootDIDInUse
+		case err != nil && !errors.Is(err, domain.ErrAPIKeyNotFound):
+			return nil, err
+		}
+	}
+
+	key, err := s.apiKeyRepo.SetRootDID(id, rootDID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: root DID of API key %s set to %q", key.ID, rootDID)
+	return key, nil
+}
+
+// CreateSubKey issues a sub-key of a tenant key. Sub-keys act as their tenant, so they
+// share its DIDs, quotas and sandbox, but draw from a budget of their own and cannot
+// issue keys themselves. The plaintext key is only returned here.
+func (s *AccessService) CreateSubKey(parentID uuid.UUID, name string) (*domain.APIKeyCreateResponse, error) {
+	parent, err := s.apiKeyRepo.GetByID(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, domain.ErrSubKeyNotAllowed
+	}
+
+	plaintext, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    apiKeyDisplayPrefix(plaintext),
+		KeyHash:   hashAPIKey(plaintext),
+		Status:    string(domain.APIKeyStatusActive),
+		CreatedAt: s.clock.Now(),
+		Sandbox:   parent.Sandbox,
+		ParentID:  &parent.ID,
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, fmt.Errorf("failed to store sub-key: %w", err)
+	}
+
+	log.Printf("AUDIT: API key %s issued sub-key %s (%s)", parent.ID, key.ID, key.Name)
+	return &domain.APIKeyCreateResponse{APIKey: key, Key: plaintext}, nil
+}
+
+// ListSubKeys lists the sub-keys of a tenant key
+func (s *AccessService) ListSubKeys(parentID uuid.UUID) ([]*domain.APIKey, error) {
+	return s.apiKeyRepo.ListByParent(parentID)
+}
+
+// SubKey returns a sub-key of a tenant key, or ErrAPIKeyNotFound when the tenant did
+// not issue it
+func (s *AccessService) SubKey(parentID, id uuid.UUID) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.ParentID == nil || *key.ParentID != parentID {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// RevokeSubKey revokes a sub-key of a tenant key
+func (s *AccessService) RevokeSubKey(parentID, id uuid.UUID) error {
+	if _, err := s.SubKey(parentID, id); err != nil {
+		return err
+	}
+	if err := s.apiKeyRepo.Revoke(id); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: API key %s revoked sub-key %s", parentID, id)
+	return nil
+}
+
+// AuthenticateAPIKey resolves the caller behind a plaintext API key
+func (s *AccessService) AuthenticateAPIKey(plaintext string) (*domain.Caller, error) {
+	key, err := s.apiKeyRepo.GetByHash(hashAPIKey(plaintext))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	if key.Status != string(domain.APIKeyStatusActive) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID); err != nil {
+		log.Printf("Warning: failed to record API key usage: %v", err)
+	}
+
+	return apiKeyCaller(key), nil
+}
+
+// AuthenticateServiceToken authenticates a service client of auth-service with its
+// bearer token. The client calls as the API key its token names, which must still be
+// active, and only for the operations of the token's scopes.
+func (s *AccessService) AuthenticateServiceToken(token string) (*domain.Caller, error) {
+	if s.serviceTokens == nil {
+		return nil, domain.ErrUnauthenticated
+	}
+	claims, err := s.serviceTokens.Verify(token)
+	if err != nil {
+		return nil, domain.ErrUnauthenticated
+	}
+	tenant, err := uuid.Parse(claims.Tenant)
+	if err != nil {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	key, err := s.apiKeyRepo.GetByID(tenant)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if key.Status != string(domain.APIKeyStatusActive) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	caller := apiKeyCaller(key)
+	caller.Client = claims.ClientID
+	caller.Scopes = claims.Scopes
+	if caller.Scopes == nil {
+		caller.Scopes = []string{}
+	}
+	return caller, nil
+}
+
+// AuthenticateUCAN authenticates a service invoking a UCAN chain. The service calls as
+// the tenant whose root DID issued the chain's root, for the operations the invoked
+// UCAN grants: on any DID for capabilities with the wildcard resource, and on a single
+// DID otherwise.
+func (s *AccessService) AuthenticateUCAN(token string) (*domain.Caller, error) {
+	if s.ucanResolver == nil {
+		return nil, domain.ErrUnauthenticated
+	}
+	chain, err := did.ValidateUCANChain(token, s.ucanAudience, s.ucanKey, s.clock.Now())
+	if err != nil {
+		if errors.Is(err, did.ErrInvalidUCAN) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.GetByRootDID(chain[0].Claims().Issuer)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if key.Status != string(domain.APIKeyStatusActive) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	invoked := chain[len(chain)-1].Claims()
+	caller := apiKeyCaller(key)
+	caller.Client = invoked.Issuer
+	caller.Scopes = []string{}
+	for _, capability := range invoked.Capabilities {
+		for _, scope := range security.TenantScopes {
+			if capability.Can != did.UCANAny && capability.Can != scope {
+				continue
+			}
+			if capability.With == did.UCANAny {
+				caller.Scopes = append(caller.Scopes, scope)
+				continue
+			}
+			if caller.Resources == nil {
+				caller.Resources = make(map[string][]string)
+			}
+			caller.Resources[capability.With] = append(caller.Resources[capability.With], scope)
+		}
+	}
+	return caller, nil
+}
+
+// ucanKey resolves the key a UCAN issuer signed with, which its DID Document must list
+// under capabilityInvocation, or under assertionMethod when it lists no such keys
+func (s *AccessService) ucanKey(issuer, keyID string) (did.SignatureSuite, []byte, error) {
+	// A DID may always resolve itself, even when private
+	resolution, err := s.ucanResolver.Resolve(issuer, &domain.Caller{Type: domain.CallerTypeDID, ID: issuer})
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s cannot be resolved", did.ErrInvalidUCAN, issuer)
+		}
+		return nil, nil, err
+	}
+	if resolution.DocumentMetadata.Deactivated {
+		return nil, nil, fmt.Errorf("%w: %s is deactivated", did.ErrInvalidUCAN, issuer)
+	}
+
+	document := resolution.Document
+	purpose := did.PurposeCapabilityInvocation
+	if len(document.CapabilityInvocation) == 0 {
+		purpose = did.ProofPurposeAssertionMethod
+	}
+	method, ok := document.Method(keyID)
+	if !ok || !document.Authorizes(keyID, purpose) {
+		return nil, nil, fmt.Errorf("%w: %s is not a %s key of %s", did.ErrInvalidUCAN, keyID, purpose, issuer)
+	}
+
+	if method.PublicKeyJwk != nil {
+		suiteID, publicKey, err := method.JWKPublicKey()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", did.ErrInvalidUCAN, err)
+		}
+		suite, err := s.registry.SignatureSuite(suiteID)
+		return suite, publicKey, err
+	}
+	suite, err := s.registry.SignatureSuiteForMethodType(method.Type)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", did.ErrInvalidUCAN, err)
+	}
+	publicKey, err := method.PublicKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", did.ErrInvalidUCAN, err)
+	}
+	return suite, publicKey, nil
+}
+
+// apiKeyCaller returns the caller authenticated with an active API key
+func apiKeyCaller(key *domain.APIKey) *domain.Caller {
+	caller := &domain.Caller{Type: domain.CallerTypeAPIKey, ID: key.ID.String()}
+	if key.ParentID != nil {
+		// A sub-key acts as its tenant; revoking the tenant revokes its sub-keys
+		caller.ID = key.ParentID.String()
+		caller.KeyID = key.ID.String()
+	}
+	if key.Sandbox {
+		caller.SandboxTenant = caller.ID
+	}
+	return caller
+}
+
+// AuthenticateDID authenticates a caller that signed the request with the key of a DID
+// managed by this service. The signature covers CallerSignaturePayload(method, path, timestamp).
+// keyID names the verification method that signed when it is one of the DID's device
+// keys; it is empty for the DID's own key.
+func (s *AccessService) AuthenticateDID(didString, keyID, timestamp, signatureHex, method, path string) (*domain.Caller, error) {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	skew := s.clock.Now().Sub(time.Unix(unix, 0))
+	if skew > maxSignatureSkew || skew < -maxSignatureSkew {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	if record.Status != string(domain.DIDStatusActive) && record.Status != string(domain.DIDStatusPending) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	publicKey, keyAlgorithm := record.PublicKey, record.KeyAlgorithm
+	if keyID != "" && keyID != record.Did+"#key-1" {
+		if s.deviceKeys == nil {
+			return nil, domain.ErrUnauthenticated
+		}
+		deviceKey, err := s.deviceKeys.AuthenticationKey(record.Did, keyID)
+		if err != nil {
+			if errors.Is(err, domain.ErrDeviceKeyNotFound) {
+				return nil, domain.ErrUnauthenticated
+			}
+			return nil, err
+		}
+		publicKey, keyAlgorithm = deviceKey.PublicKey, deviceKey.KeyAlgorithm
+	}
+
+	keyMaterial, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load caller key: %w", err)
+	}
+
+	payload := []byte(CallerSignaturePayload(method, path, timestamp))
+	valid, err := s.registry.VerifySignature(keyAlgorithm, keyMaterial, payload, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify caller signature: %w", err)
+	}
+	if !valid {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	// Sandbox DIDs act within the partition they were created in
+	return &domain.Caller{Type: domain.CallerTypeDID, ID: record.Did, SandboxTenant: record.SandboxTenant}, nil
+}
+
+// CallerSignaturePayload builds the message a DID-authenticated caller signs
+func CallerSignaturePayload(method, path, timestamp string) string {
+	return method + " " + path + "\n" + timestamp
+}
+
+// CanResolve reports whether the caller may resolve the given DID. Sandbox DIDs are only
+// visible within their own sandbox, and sandbox callers only see their sandbox's DIDs.
+func (s *AccessService) CanResolve(record *domain.DID, caller *domain.Caller) (bool, error) {
+	var partition string
+	if caller != nil {
+		partition = caller.SandboxTenant
+	}
+	if record.SandboxTenant != partition {
+		return false, nil
+	}
+
+	if record.Visibility != string(domain.DIDVisibilityPrivate) {
+		return true, nil
+	}
+
+	if caller.IsAnonymous() {
+		return false, nil
+	}
+
+	// A DID may always resolve itself
+	if caller.Type == domain.CallerTypeDID && (caller.ID == record.Did || record.AliasDID != "" && caller.ID == record.AliasDID) {
+		return true, nil
+	}
+
+	return s.aclRepo.Exists(record.ID, string(caller.Type), caller.ID)
+}
+
+// CheckDIDAccess loads a DID and ensures the caller may see it. Private DIDs the caller
+// is not authorized for are reported as not found so they cannot be enumerated. A
+// legacy DID may also be named by its alias.
+func (s *AccessService) CheckDIDAccess(didString string, caller *domain.Caller) (*domain.DID, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if errors.Is(err, domain.ErrDIDNotFound) && !did.IsLegacyIdentifier(didString) {
+		record, err = s.didRepo.GetByAlias(didString)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.CanResolve(record, caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check access: %w", err)
+	}
+
+	if !allowed {
+		log.Printf("Denied access to private DID %s for %s caller %s", record.Did, caller.Type, caller.ID)
+		return nil, domain.ErrDIDNotFound
+	}
+
+	return record, nil
+}
+
+// GrantAccess adds an ACL entry allowing a caller to resolve a DID
+func (s *AccessService) GrantAccess(req *domain.ACLGrantRequest) (*domain.ACLEntry, error) {
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GranteeType == string(domain.CallerTypeAPIKey) {
+		if _, err := uuid.Parse(req.Grantee); err != nil {
+			return nil, fmt.Errorf("API key grantee must be an API key ID: %w", err)
+		}
+	}
+
+	entry := &domain.ACLEntry{
+		ID:          uuid.New(),
+		DIDID:       record.ID,
+		GranteeType: req.GranteeType,
+		Grantee:     req.Grantee,
+		CreatedAt:   s.clock.Now(),
+	}
+
+	if err := s.aclRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// RevokeAccess removes an ACL entry
+func (s *AccessService) RevokeAccess(id uuid.UUID) error {
+	return s.aclRepo.Delete(id)
+}
+
+// ListAccess lists the ACL entries of a DID
+func (s *AccessService) ListAccess(didString string) ([]*domain.ACLEntry, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.aclRepo.ListByDIDID(record.ID)
+}
+
+// SetVisibility changes whether a DID is publicly resolvable
+func (s *AccessService) SetVisibility(req *domain.DIDVisibilityRequest) error {
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err != nil {
+		return err
+	}
+
+	return s.didRepo.UpdateVisibility(record.ID, req.Visibility)
+}
+
+// newAPIKey generates a plaintext API key
+func newAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+// apiKeyDisplayPrefix returns the start of a plaintext key, stored to tell keys apart
+func apiKeyDisplayPrefix(plaintext string) string {
+	return plaintext[:len(apiKeyPrefix)+8]
+}
+
+// hashAPIKey hashes a plaintext API key for storage and lookup
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// AccountEventService records changes to users' accounts in the auth service on their
+// DIDs, so webhooks and the change feed see them next to the DIDs' own changes
+type AccountEventService struct {
+	didRepo domain.DIDRepository
+	events  EventPublisher
+}
+
+// NewAccountEventService creates a new account event service; events may be nil when
+// no event stream is available, leaving only the audit log
+func NewAccountEventService(didRepo domain.DIDRepository, events EventPublisher) *AccountEventService {
+	return &AccountEventService{
+		didRepo: didRepo,
+		events:  events,
+	}
+}
+
+// RecordPasswordChange records a change of a user's account password on each of their
+// DIDs. Commitments do not depend on the password, so nothing is re-derived.
+func (s *AccountEventService) RecordPasswordChange(userID uuid.UUID, req *domain.PasswordChangeRequest) (*domain.PasswordChangeResponse, error) {
+	records, err := s.didRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.PasswordChangeResponse{DIDs: make([]string, 0, len(records))}
+	now := time.Now()
+	for _, record := range records {
+		response.DIDs = append(response.DIDs, record.Did)
+		log.Printf("AUDIT: account password of user %s %s, recorded on DID %s", userID, passwordChangeVerb(req.Reason), record.Did)
+
+		if s.events == nil {
+			continue
+		}
+		event := &queue.Event{
+			ID:         uuid.New().String(),
+			Type:       queue.EventDIDPasswordChanged,
+			Subject:    record.Did,
+			Data:       map[string]string{"reason": string(req.Reason)},
+			OccurredAt: now,
+		}
+		if err := s.events.PublishEvent(event); err != nil {
+			log.Printf("Warning: failed to publish %s event: %v", event.Type, err)
+		}
+	}
+
+	return response, nil
+}
+
+// SuspendUserDIDs suspends a user's active DIDs, e.g. after the auth service detected a
+// suspicious sign-in. The status change is published like any other.
+func (s *AccountEventService) SuspendUserDIDs(userID uuid.UUID, req *domain.SuspensionRequest) (*domain.SuspensionResponse, error) {
+	response, err := s.setUserDIDStatus(userID, domain.DIDStatusActive, domain.DIDStatusSuspended)
+	if err != nil {
+		return nil, err
+	}
+	for _, did := range response.DIDs {
+		log.Printf("AUDIT: DID %s of user %s suspended: %s", did, userID, req.Reason)
+	}
+	return response, nil
+}
+
+// ReinstateUserDIDs makes a user's suspended DIDs active again
+func (s *AccountEventService) ReinstateUserDIDs(userID uuid.UUID) (*domain.SuspensionResponse, error) {
+	response, err := s.setUserDIDStatus(userID, domain.DIDStatusSuspended, domain.DIDStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	for _, did := range response.DIDs {
+		log.Printf("AUDIT: DID %s of user %s reinstated", did, userID)
+	}
+	return response, nil
+}
+
+// setUserDIDStatus moves a user's DIDs in status from to status to, keeping their
+// transactions
+func (s *AccountEventService) setUserDIDStatus(userID uuid.UUID, from, to domain.DIDStatus) (*domain.SuspensionResponse, error) {
+	records, err := s.didRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.SuspensionResponse{DIDs: []string{}}
+	for _, record := range records {
+		if record.Status != string(from) {
+			continue
+		}
+		if err := s.didRepo.UpdateStatus(record.ID, to, record.BlockchainTx); err != nil {
+			return nil, err
+		}
+		response.DIDs = append(response.DIDs, record.Did)
+	}
+	return response, nil
+}
+
+// passwordChangeVerb describes a password change for the audit log
+func passwordChangeVerb(reason domain.PasswordChangeReason) string {
+	if reason == domain.PasswordReset {
+		return "reset"
+	}
+	return "changed"
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/bbs"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// Claims of BBS age credentials besides the birthdate and age thresholds. BBS proofs
+// always disclose the ID, for status checks, the type and the expiry; the subject
+// stays hidden.
+const (
+	ageClaimID             = "id"
+	ageClaimType           = "type"
+	ageClaimIssuanceDate   = "issuanceDate"
+	ageClaimExpirationDate = "expirationDate"
+	ageClaimSubject        = "subject"
+)
+
+// bbsKeyInfo binds issuers' BBS keys, derived from their stored signing keys, to
+// age credentials
+const bbsKeyInfo = "did-manager/age-credential"
+
+// IssueAgeCredential issues an AgeOver credential from issuerDID attesting the
+// subject's birthdate and whether it had reached each requested age at issuance, each
+// as a separate claim its holder can disclose on its own
+func (s *CredentialService) IssueAgeCredential(issuerDID string, req *domain.AgeCredentialIssueRequest) (*domain.Credential, error) {
+	birthdate, err := time.Parse(did.BirthdateLayout, req.Birthdate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: birthdate must be a YYYY-MM-DD date", domain.ErrInvalidAgeCredential)
+	}
+	if birthdate.After(s.clock.Now().UTC()) {
+		return nil, fmt.Errorf("%w: birthdate is in the future", domain.ErrInvalidAgeCredential)
+	}
+
+	thresholds := ageThresholds(req.Thresholds)
+
+	issuer, err := s.didRepo.GetByDID(issuerDID)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.ApprovalThreshold > 0 {
+		return nil, fmt.Errorf("%w: organization DIDs issue credentials once their controllers approve", domain.ErrForbidden)
+	}
+
+	issueReq := &domain.CredentialIssueRequest{
+		SubjectDID: req.SubjectDID,
+		Type:       did.TypeAgeOverCredential,
+		Claims:     map[string]any{did.ClaimBirthdate: req.Birthdate},
+		ExpiresAt:  req.ExpiresAt,
+		Tenant:     req.Tenant,
+	}
+
+	return s.issueDocument(issuer, issueReq, func(id uuid.UUID, now time.Time) (json.RawMessage, error) {
+		ageClaims := make(map[string]bool, len(thresholds))
+		age := did.AgeAt(birthdate, now.UTC())
+		for _, threshold := range thresholds {
+			ageClaims[did.AgeOverClaim(threshold)] = age >= threshold
+		}
+
+		var err error
+		document := &domain.AgeCredentialDocument{Format: req.Format}
+		switch req.Format {
+		case domain.AgeCredentialFormatBBS:
+			document.BBS, err = s.signAgeBBS(issuer, id, now, req, thresholds, ageClaims)
+		case domain.AgeCredentialFormatSDJWT:
+			document.SDJWT, err = s.signAgeSDJWT(issuer, id, now, req, ageClaims)
+		default:
+			err = fmt.Errorf("%w: unknown format %s", domain.ErrInvalidAgeCredential, req.Format)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode age credential: %w", err)
+		}
+		return encoded, nil
+	})
+}
+
+// signAgeBBS signs an age credential as BBS messages with the issuer's BBS key
+func (s *CredentialService) signAgeBBS(issuer *domain.DID, id uuid.UUID, now time.Time, req *domain.AgeCredentialIssueRequest, thresholds []int, ageClaims map[string]bool) (*did.SelectiveCredential, error) {
+	sk, err := s.bbsKey(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var expirationDate any
+	if req.ExpiresAt != nil {
+		expirationDate = req.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	names := []string{ageClaimID, ageClaimType, ageClaimIssuanceDate, ageClaimExpirationDate, ageClaimSubject, did.ClaimBirthdate}
+	values := map[string]any{
+		ageClaimID:             "urn:uuid:" + id.String(),
+		ageClaimType:           did.TypeAgeOverCredential,
+		ageClaimIssuanceDate:   now.UTC().Format(time.RFC3339),
+		ageClaimExpirationDate: expirationDate,
+		ageClaimSubject:        req.SubjectDID,
+		did.ClaimBirthdate:     req.Birthdate,
+	}
+	for _, threshold := range thresholds {
+		name := did.AgeOverClaim(threshold)
+		names = append(names, name)
+		values[name] = ageClaims[name]
+	}
+
+	credential := &did.SelectiveCredential{Issuer: issuer.Did}
+	for _, name := range names {
+		claim, err := did.NewClaim(name, values[name])
+		if err != nil {
+			return nil, err
+		}
+		credential.Claims = append(credential.Claims, claim)
+	}
+
+	if err := did.SignSelectiveCredential(sk, credential, issuer.Did+"#bbs-key-1"); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// signAgeSDJWT signs an age credential as an SD-JWT with the issuer's key. The
+// birthdate and age claims are selectively disclosable; the holder's key is bound
+// through "cnf" so presentations can carry a key binding.
+func (s *CredentialService) signAgeSDJWT(issuer *domain.DID, id uuid.UUID, now time.Time, req *domain.AgeCredentialIssueRequest, ageClaims map[string]bool) (string, error) {
+	suite, privateKey, method, err := s.signingKey(issuer, did.PurposeAssertionMethod)
+	if err != nil {
+		return "", err
+	}
+	holderKey, err := s.holderKeyID(req.SubjectDID)
+	if err != nil {
+		return "", err
+	}
+
+	claims := map[string]any{
+		"iss": issuer.Did,
+		"iat": now.Unix(),
+		"jti": "urn:uuid:" + id.String(),
+		"vct": did.TypeAgeOverCredential,
+		"cnf": map[string]any{"kid": holderKey},
+	}
+	if req.ExpiresAt != nil {
+		claims["exp"] = req.ExpiresAt.Unix()
+	}
+
+	selective := map[string]any{did.ClaimBirthdate: req.Birthdate}
+	for name, value := range ageClaims {
+		selective[name] = value
+	}
+
+	return did.IssueSDJWT(suite, privateKey, method, claims, selective)
+}
+
+// holderKeyID returns the authentication key a holder binds presentations with: its
+// purpose key when it is a local DID with one, otherwise its own key
+func (s *CredentialService) holderKeyID(subjectDID string) (string, error) {
+	holder, err := s.didRepo.GetByDID(subjectDID)
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		return subjectDID + "#key-1", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	key, err := s.purposeKeys.key(holder, did.PurposeAuthentication)
+	if err != nil {
+		return "", err
+	}
+	return key.method, nil
+}
+
+// CreateAgeProof derives a proof from an age credential held by holderDID that
+// discloses only that its subject is over the requested age
+func (s *CredentialService) CreateAgeProof(holderDID string, id uuid.UUID, req *domain.AgeProofRequest) (*domain.AgeProof, error) {
+	record, err := s.credentialRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if record.SubjectDID != holderDID {
+		// Do not reveal credentials held by others
+		return nil, domain.ErrCredentialNotFound
+	}
+	if record.Type != did.TypeAgeOverCredential {
+		return nil, fmt.Errorf("%w: credential %s is not an age credential", domain.ErrInvalidAgeCredential, id)
+	}
+	if record.Status != string(domain.CredentialStatusActive) {
+		return nil, fmt.Errorf("%w: credential %s is %s", domain.ErrForbidden, id, record.Status)
+	}
+
+	var document domain.AgeCredentialDocument
+	if err := json.Unmarshal(record.Document, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode credential %s: %w", id, err)
+	}
+
+	ageClaim := did.AgeOverClaim(req.Threshold)
+	proof := &domain.AgeProof{Format: document.Format, Threshold: req.Threshold}
+	switch document.Format {
+	case domain.AgeCredentialFormatBBS:
+		proof.BBS, err = s.deriveAgeBBS(record, document.BBS, ageClaim, req)
+	case domain.AgeCredentialFormatSDJWT:
+		proof.SDJWT, err = s.presentAgeSDJWT(holderDID, document.SDJWT, ageClaim, req)
+	default:
+		err = fmt.Errorf("credential %s has unknown format %q", id, document.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// deriveAgeBBS derives a BBS proof disclosing the credential's ID, type, expiry and
+// age claim
+func (s *CredentialService) deriveAgeBBS(record *domain.Credential, credential *did.SelectiveCredential, ageClaim string, req *domain.AgeProofRequest) (*did.SelectiveDisclosure, error) {
+	if credential == nil {
+		return nil, fmt.Errorf("credential %s has no BBS document", record.ID)
+	}
+	index := credential.ClaimIndex(ageClaim)
+	if index < 0 {
+		return nil, fmt.Errorf("%w: credential does not attest age %d", domain.ErrInvalidAgeCredential, req.Threshold)
+	}
+	if string(credential.Claims[index].Value) != "true" {
+		return nil, fmt.Errorf("%w: subject was under %d at issuance", domain.ErrInvalidAgeCredential, req.Threshold)
+	}
+
+	issuer, err := s.didRepo.GetByDID(record.IssuerDID)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := s.bbsKey(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.DeriveDisclosure(sk.PublicKey(), credential,
+		[]string{ageClaimID, ageClaimType, ageClaimExpirationDate, ageClaim}, req.Nonce)
+}
+
+// presentAgeSDJWT presents an SD-JWT with only the age claim disclosed, adding a key
+// binding signed by the holder when the verifier supplied a nonce or audience
+func (s *CredentialService) presentAgeSDJWT(holderDID, token, ageClaim string, req *domain.AgeProofRequest) (string, error) {
+	full, err := did.ParseSDJWT(token)
+	if err != nil {
+		return "", err
+	}
+	value, ok := full.Claims()[ageClaim]
+	if !ok {
+		return "", fmt.Errorf("%w: credential does not attest age %d", domain.ErrInvalidAgeCredential, req.Threshold)
+	}
+	if value != true {
+		return "", fmt.Errorf("%w: subject was under %d at issuance", domain.ErrInvalidAgeCredential, req.Threshold)
+	}
+
+	presentation, err := did.PresentSDJWT(token, []string{ageClaim})
+	if err != nil {
+		return "", err
+	}
+	if req.Nonce == "" && req.Audience == "" {
+		return presentation, nil
+	}
+
+	holder, err := s.didRepo.GetByDID(holderDID)
+	if err != nil {
+		return "", err
+	}
+	suite, privateKey, _, err := s.signingKey(holder, did.PurposeAuthentication)
+	if err != nil {
+		return "", err
+	}
+	return did.AddKeyBinding(suite, privateKey, presentation, req.Audience, req.Nonce, s.clock.Now())
+}
+
+// VerifyAgeProof checks that an age proof is signed by a known issuer, discloses that
+// its subject is over the requested age, matches the verifier's nonce and audience,
+// and that its credential is neither expired nor revoked
+func (s *CredentialService) VerifyAgeProof(req *domain.AgeProofVerifyRequest) (*domain.AgeProofVerifyResponse, error) {
+	switch req.Format {
+	case domain.AgeCredentialFormatBBS:
+		if req.BBS == nil {
+			return &domain.AgeProofVerifyResponse{Valid: false, Threshold: req.Threshold, Message: "Missing BBS proof"}, nil
+		}
+		return s.verifyAgeBBS(req)
+	case domain.AgeCredentialFormatSDJWT:
+		if req.SDJWT == "" {
+			return &domain.AgeProofVerifyResponse{Valid: false, Threshold: req.Threshold, Message: "Missing SD-JWT presentation"}, nil
+		}
+		return s.verifyAgeSDJWT(req)
+	}
+	return &domain.AgeProofVerifyResponse{Valid: false, Threshold: req.Threshold, Message: "Unknown proof format"}, nil
+}
+
+// verifyAgeBBS checks a BBS age proof
+func (s *CredentialService) verifyAgeBBS(req *domain.AgeProofVerifyRequest) (*domain.AgeProofVerifyResponse, error) {
+	disclosure := req.BBS
+	result := &domain.AgeProofVerifyResponse{Issuer: disclosure.Issuer, Threshold: req.Threshold}
+	invalid := func(message string) (*domain.AgeProofVerifyResponse, error) {
+		result.Message = message
+		return result, nil
+	}
+
+	if disclosure.Nonce != req.Nonce {
+		return invalid("Proof nonce does not match")
+	}
+
+	issuer, err := s.didRepo.GetByDID(disclosure.Issuer)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return invalid("Unknown issuer")
+		}
+		return nil, err
+	}
+	if !issuer.Custodial {
+		return invalid("Issuer has no BBS key")
+	}
+	sk, err := s.bbsKey(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := did.VerifyDisclosure(sk.PublicKey(), disclosure)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	for name := range claims {
+		result.Disclosed = append(result.Disclosed, name)
+	}
+	sort.Strings(result.Disclosed)
+
+	var credentialType, credentialID string
+	var expirationDate *string
+	for name, target := range map[string]any{
+		ageClaimType:           &credentialType,
+		ageClaimID:             &credentialID,
+		ageClaimExpirationDate: &expirationDate,
+	} {
+		raw, ok := claims[name]
+		if !ok {
+			return invalid("Proof does not disclose " + name)
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return invalid("Malformed " + name + " claim")
+		}
+	}
+	if credentialType != did.TypeAgeOverCredential {
+		return invalid("Proof is not derived from an age credential")
+	}
+	if expirationDate != nil {
+		expires, err := time.Parse(time.RFC3339, *expirationDate)
+		if err != nil {
+			return invalid("Malformed expirationDate claim")
+		}
+		if s.clock.Now().After(expires) {
+			result.Status = "expired"
+			return invalid("Credential has expired")
+		}
+	}
+
+	over, ok := claims[did.AgeOverClaim(req.Threshold)]
+	return s.ageResult(result, credentialID, ok && string(over) == "true")
+}
+
+// verifyAgeSDJWT checks an SD-JWT age presentation
+func (s *CredentialService) verifyAgeSDJWT(req *domain.AgeProofVerifyRequest) (*domain.AgeProofVerifyResponse, error) {
+	result := &domain.AgeProofVerifyResponse{Threshold: req.Threshold}
+	invalid := func(message string) (*domain.AgeProofVerifyResponse, error) {
+		result.Message = message
+		return result, nil
+	}
+
+	token, err := did.ParseSDJWT(req.SDJWT)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	result.Issuer = token.Issuer()
+	if !strings.HasPrefix(token.KeyID(), result.Issuer+"#") {
+		return invalid("SD-JWT is not signed by its issuer")
+	}
+
+	issuer, err := s.didRepo.GetByDID(result.Issuer)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return invalid("Unknown issuer")
+		}
+		return nil, err
+	}
+	suite, publicKey, _, err := s.verificationKey(issuer, did.PurposeAssertionMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.Verify(suite, publicKey); err != nil {
+		return invalid(err.Error())
+	}
+
+	claims := token.Claims()
+	for _, disclosure := range token.Disclosures {
+		result.Disclosed = append(result.Disclosed, disclosure.Name)
+	}
+	sort.Strings(result.Disclosed)
+
+	if vct, _ := claims["vct"].(string); vct != did.TypeAgeOverCredential {
+		return invalid("SD-JWT is not an age credential")
+	}
+	if exp, ok := claims["exp"].(float64); ok && s.clock.Now().After(time.Unix(int64(exp), 0)) {
+		result.Status = "expired"
+		return invalid("Credential has expired")
+	}
+
+	if token.HasKeyBinding() || req.Nonce != "" || req.Audience != "" {
+		if message := s.checkAgeKeyBinding(token, claims, req); message != "" {
+			return invalid(message)
+		}
+	}
+
+	credentialID, _ := claims["jti"].(string)
+	over, _ := claims[did.AgeOverClaim(req.Threshold)].(bool)
+	return s.ageResult(result, credentialID, over)
+}
+
+// checkAgeKeyBinding verifies the key binding of an SD-JWT presentation with the key
+// of the holder named in its "cnf" claim, returning why it fails or an empty string
+func (s *CredentialService) checkAgeKeyBinding(token *did.SDJWT, claims map[string]any, req *domain.AgeProofVerifyRequest) string {
+	cnf, _ := claims["cnf"].(map[string]any)
+	kid, _ := cnf["kid"].(string)
+	holderDID, _, found := strings.Cut(kid, "#")
+	if !found {
+		return "SD-JWT does not name the holder's key"
+	}
+
+	holder, err := s.didRepo.GetByDID(holderDID)
+	if err != nil {
+		return "Unknown holder"
+	}
+	if holder.Status == string(domain.DIDStatusRevoked) || holder.Status == string(domain.DIDStatusExpired) {
+		return "Holder DID is " + holder.Status
+	}
+	suite, publicKey, method, err := s.verificationKey(holder, did.PurposeAuthentication)
+	if err != nil {
+		return err.Error()
+	}
+	if kid != method {
+		return "SD-JWT does not name an authentication key of the holder"
+	}
+	if err := token.VerifyKeyBinding(suite, publicKey, req.Audience, req.Nonce); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// ageResult completes an age proof result from the proven age claim and the stored
+// status of the credential
+func (s *CredentialService) ageResult(result *domain.AgeProofVerifyResponse, credentialID string, over bool) (*domain.AgeProofVerifyResponse, error) {
+	status, reason := s.storedStatus(credentialID)
+	result.Status = status
+	switch {
+	case reason != "":
+		result.Message = reason
+	case !over:
+		result.Message = fmt.Sprintf("Proof does not show the subject is over %d", result.Threshold)
+	default:
+		result.Valid = true
+		result.Message = fmt.Sprintf("Subject is over %d", result.Threshold)
+		result.CredentialID = credentialID
+	}
+	return result, nil
+}
+
+// bbsKey derives an issuer's BBS key from its own key, so rotating the DID's key also
+// rotates the BBS key
+func (s *CredentialService) bbsKey(issuer *domain.DID) (*bbs.SecretKey, error) {
+	_, privateKey, _, err := s.signingKey(issuer, did.PurposeCapabilityInvocation)
+	if err != nil {
+		return nil, err
+	}
+	return bbs.KeyGen(privateKey, []byte(bbsKeyInfo))
+}
+
+// ageThresholds sorts and deduplicates requested age thresholds, defaulting to
+// DefaultAgeThreshold
+func ageThresholds(requested []int) []int {
+	if len(requested) == 0 {
+		return []int{domain.DefaultAgeThreshold}
+	}
+	thresholds := append([]int(nil), requested...)
+	sort.Ints(thresholds)
+	unique := thresholds[:1]
+	for _, threshold := range thresholds[1:] {
+		if threshold != unique[len(unique)-1] {
+			unique = append(unique, threshold)
+		}
+	}
+	return unique
+}
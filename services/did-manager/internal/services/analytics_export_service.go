@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/parquet"
+	"did-manager/pkg/vault"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// analyticsBatchSize bounds the usage events read and exported at once
+	analyticsBatchSize = 50000
+	// analyticsSettleDelay holds back recent events. Usage event IDs are assigned
+	// before their transaction commits, so an event may become visible after one with
+	// a higher ID; once this old, it has committed.
+	analyticsSettleDelay = time.Minute
+	// maxAnalyticsExportsListed bounds the exports returned by ListExports
+	maxAnalyticsExportsListed = 100
+)
+
+// analyticsColumns are the columns of the exported Parquet files
+var analyticsColumns = []parquet.Column{
+	{Name: "event_id", Type: parquet.Int64},
+	{Name: "event_type", Type: parquet.String},
+	{Name: "tenant", Type: parquet.String},
+	{Name: "subject", Type: parquet.String},
+	{Name: "occurred_at", Type: parquet.Timestamp},
+}
+
+// AnalyticsExportService exports DID creations, DID verifications and credential
+// issuances from the usage events to Parquet files in an object store, partitioned by
+// day and tenant as <prefix>/date=YYYY-MM-DD/tenant=<tenant>/, so the data warehouse
+// reads them instead of querying the production database. Each run resumes after the
+// last exported event.
+type AnalyticsExportService struct {
+	repo   domain.AnalyticsExportRepository
+	store  vault.Store
+	prefix string
+}
+
+// NewAnalyticsExportService creates a new analytics export service. Without a store,
+// exports fail with ErrAnalyticsExportDisabled.
+func NewAnalyticsExportService(repo domain.AnalyticsExportRepository, store vault.Store, prefix string) *AnalyticsExportService {
+	return &AnalyticsExportService{
+		repo:   repo,
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+// Enabled reports whether an analytics bucket is configured
+func (s *AnalyticsExportService) Enabled() bool {
+	return s.store != nil
+}
+
+// RunExport exports the usage events recorded since the last export, one export per
+// batch of events, and returns the exports made
+func (s *AnalyticsExportService) RunExport() ([]*domain.AnalyticsExport, error) {
+	if s.store == nil {
+		return nil, domain.ErrAnalyticsExportDisabled
+	}
+
+	last, err := s.repo.LastEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	until := time.Now().Add(-analyticsSettleDelay)
+	exports := []*domain.AnalyticsExport{}
+	for {
+		events, err := s.repo.ListEventsAfter(last, analyticsBatchSize)
+		if err != nil {
+			return exports, err
+		}
+		full := len(events) == analyticsBatchSize
+
+		// Stop at the first recent event rather than skipping it, since events with
+		// lower IDs may still be committing
+		for i, event := range events {
+			if !event.OccurredAt.Before(until) {
+				events, full = events[:i], false
+				break
+			}
+		}
+		if len(events) == 0 {
+			return exports, nil
+		}
+
+		export, err := s.exportBatch(events)
+		if err != nil {
+			return exports, err
+		}
+		exports = append(exports, export)
+		last = export.LastEventID
+
+		if !full {
+			return exports, nil
+		}
+	}
+}
+
+// exportBatch writes a batch of events as one Parquet file per day and tenant, then
+// records the export. Object names are derived from the events, so a batch exported
+// again after a failure overwrites its files instead of duplicating them.
+func (s *AnalyticsExportService) exportBatch(events []*domain.AnalyticsEvent) (*domain.AnalyticsExport, error) {
+	type partition struct{ day, tenant string }
+	partitions := map[partition][]*domain.AnalyticsEvent{}
+	for _, event := range events {
+		key := partition{day: event.OccurredAt.UTC().Format("2006-01-02"), tenant: event.Tenant}
+		partitions[key] = append(partitions[key], event)
+	}
+
+	keys := make([]partition, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].day != keys[j].day {
+			return keys[i].day < keys[j].day
+		}
+		return keys[i].tenant < keys[j].tenant
+	})
+
+	for _, key := range keys {
+		partitionEvents := partitions[key]
+		rows := make([][]any, 0, len(partitionEvents))
+		for _, event := range partitionEvents {
+			rows = append(rows, []any{event.ID, string(event.Event), event.Tenant, event.Subject, event.OccurredAt})
+		}
+		file, err := parquet.Encode(analyticsColumns, rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode analytics events: %w", err)
+		}
+
+		name := path.Join(
+			s.prefix,
+			"date="+key.day,
+			"tenant="+url.PathEscape(key.tenant),
+			fmt.Sprintf("events-%d-%d.parquet", partitionEvents[0].ID, partitionEvents[len(partitionEvents)-1].ID),
+		)
+		if err := s.store.Put(context.Background(), name, file); err != nil {
+			return nil, fmt.Errorf("failed to store analytics file %s: %w", name, err)
+		}
+	}
+
+	export := &domain.AnalyticsExport{
+		ID:           uuid.New(),
+		FirstEventID: events[0].ID,
+		LastEventID:  events[len(events)-1].ID,
+		Events:       len(events),
+		Files:        len(keys),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.Create(export); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: analytics export %s wrote usage events %d to %d as %d Parquet files",
+		export.ID, export.FirstEventID, export.LastEventID, export.Files)
+
+	return export, nil
+}
+
+// ListExports lists the most recent exports, newest first
+func (s *AnalyticsExportService) ListExports() ([]*domain.AnalyticsExport, error) {
+	exports, err := s.repo.List(maxAnalyticsExportsListed)
+	if err != nil {
+		return nil, err
+	}
+	if exports == nil {
+		exports = []*domain.AnalyticsExport{}
+	}
+	return exports, nil
+}
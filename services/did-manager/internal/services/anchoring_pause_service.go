@@ -0,0 +1,110 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// AnchoringPauseService holds the operator switch pausing anchoring. The switch is
+// stored in the database so it pauses the workers of every instance; each instance
+// caches it and reloads it once older than the refresh interval.
+type AnchoringPauseService struct {
+	repo            domain.AnchoringPauseRepository
+	backlog         JobBacklog
+	refreshInterval time.Duration
+
+	mu       sync.RWMutex
+	pause    *domain.AnchoringPause
+	loadedAt time.Time
+}
+
+// NewAnchoringPauseService creates a new anchoring pause service
+func NewAnchoringPauseService(repo domain.AnchoringPauseRepository, backlog JobBacklog, refreshInterval time.Duration) *AnchoringPauseService {
+	return &AnchoringPauseService{
+		repo:            repo,
+		backlog:         backlog,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Paused reports whether anchoring is paused, keeping the cached state when the
+// database is unavailable
+func (s *AnchoringPauseService) Paused() bool {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) >= s.refreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		s.reload()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pause != nil
+}
+
+// Status reports whether anchoring is paused, why and since when, and how many jobs
+// are not yet anchored
+func (s *AnchoringPauseService) Status() (*domain.AnchoringPause, error) {
+	pause, err := s.repo.Get()
+	if err != nil {
+		return nil, err
+	}
+	if pause == nil {
+		pause = &domain.AnchoringPause{}
+	}
+
+	if s.backlog != nil {
+		if pause.PendingJobs, err = s.backlog.CountPending(); err != nil {
+			return nil, err
+		}
+	}
+	return pause, nil
+}
+
+// Pause stops every instance from submitting blockchain jobs, or updates the reason
+// of a pause. Jobs keep being queued and are submitted once anchoring resumes.
+func (s *AnchoringPauseService) Pause(req *domain.AnchoringPauseRequest) (*domain.AnchoringPause, error) {
+	now := time.Now().UTC()
+	pause := &domain.AnchoringPause{
+		Paused:   true,
+		Reason:   req.Reason,
+		PausedAt: &now,
+	}
+	if err := s.repo.Save(pause); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: anchoring paused: %s", req.Reason)
+	s.reload()
+	return s.Status()
+}
+
+// Resume lets the workers submit the queued jobs again
+func (s *AnchoringPauseService) Resume() (*domain.AnchoringPause, error) {
+	if err := s.repo.Delete(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: anchoring resumed")
+	s.reload()
+	return s.Status()
+}
+
+// reload refreshes the cached state, keeping it when the database is unavailable
+func (s *AnchoringPauseService) reload() {
+	pause, err := s.repo.Get()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("Failed to refresh anchoring pause: %v", err)
+	} else {
+		s.pause = pause
+	}
+	// Retry a failed refresh after the next interval rather than on every lookup
+	s.loadedAt = time.Now()
+}
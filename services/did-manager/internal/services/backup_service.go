@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/vault"
+
+	"github.com/google/uuid"
+)
+
+// maxBackupsListed bounds the backups returned by ListBackups
+const maxBackupsListed = 100
+
+// BackupService takes encrypted snapshots of the dids, credentials and blockchain_jobs
+// tables into an S3-compatible bucket. The chain only anchors DIDs, so these snapshots
+// are what restores the off-chain documents and key metadata. Restores are per tenant
+// and only insert rows missing from the tables.
+type BackupService struct {
+	repo  domain.BackupRepository
+	vault *vault.Vault
+	// retention is how long backups are kept
+	retention time.Duration
+}
+
+// NewBackupService creates a new backup service. Without a vault, backup operations
+// fail with ErrBackupsDisabled.
+func NewBackupService(repo domain.BackupRepository, backupVault *vault.Vault, retention time.Duration) *BackupService {
+	return &BackupService{
+		repo:      repo,
+		vault:     backupVault,
+		retention: retention,
+	}
+}
+
+// Enabled reports whether a backup bucket is configured
+func (s *BackupService) Enabled() bool {
+	return s.vault != nil
+}
+
+// RunBackup snapshots the identity tables, stores the snapshot gzipped and encrypted
+// in the bucket, and removes backups past the retention period
+func (s *BackupService) RunBackup() (*domain.Backup, error) {
+	if s.vault == nil {
+		return nil, domain.ErrBackupsDisabled
+	}
+
+	snapshot, err := s.repo.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode backup: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress backup: %w", err)
+	}
+
+	backup := &domain.Backup{
+		ID:          uuid.New(),
+		Size:        int64(buf.Len()),
+		DIDs:        len(snapshot.DIDs),
+		Credentials: len(snapshot.Credentials),
+		Jobs:        len(snapshot.Jobs),
+		CreatedAt:   snapshot.CreatedAt,
+	}
+	backup.ObjectName = fmt.Sprintf("backups/%s-%s.json.gz", backup.CreatedAt.UTC().Format("20060102T150405Z"), backup.ID)
+
+	backup.KeyID, err = s.vault.Put(context.Background(), backup.ObjectName, buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to store backup: %w", err)
+	}
+	if err := s.repo.Create(backup); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: backup %s stored as %s (%d DIDs, %d credentials, %d jobs)",
+		backup.ID, backup.ObjectName, backup.DIDs, backup.Credentials, backup.Jobs)
+
+	if err := s.prune(); err != nil {
+		log.Printf("Failed to prune backups: %v", err)
+	}
+
+	return backup, nil
+}
+
+// prune removes the backups older than the retention period from the bucket
+func (s *BackupService) prune() error {
+	if s.retention <= 0 {
+		return nil
+	}
+
+	expired, err := s.repo.ListBefore(time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	for _, backup := range expired {
+		if err := s.vault.Delete(context.Background(), backup.ObjectName); err != nil {
+			return fmt.Errorf("failed to delete backup %s: %w", backup.ID, err)
+		}
+		if err := s.repo.Delete(backup.ID); err != nil {
+			return err
+		}
+		log.Printf("AUDIT: backup %s expired and deleted", backup.ID)
+	}
+
+	return nil
+}
+
+// ListBackups lists the most recent backups, newest first
+func (s *BackupService) ListBackups() ([]*domain.Backup, error) {
+	backups, err := s.repo.List(maxBackupsListed)
+	if err != nil {
+		return nil, err
+	}
+	if backups == nil {
+		backups = []*domain.Backup{}
+	}
+	return backups, nil
+}
+
+// Restore restores a tenant's DIDs from a backup, with the credentials they issued or
+// hold and their blockchain jobs. Only rows missing from the tables are inserted, so
+// DIDs and credentials changed since the backup keep their current state.
+func (s *BackupService) Restore(id uuid.UUID, req *domain.BackupRestoreRequest) (*domain.BackupRestoreResult, error) {
+	if s.vault == nil {
+		return nil, domain.ErrBackupsDisabled
+	}
+
+	backup, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.load(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantSnapshot, err := filterSnapshot(snapshot, req.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.BackupRestoreResult{
+		BackupID:    backup.ID,
+		Tenant:      req.Tenant,
+		DryRun:      req.DryRun,
+		DIDs:        len(tenantSnapshot.DIDs),
+		Credentials: len(tenantSnapshot.Credentials),
+		Jobs:        len(tenantSnapshot.Jobs),
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	result.RestoredDIDs, result.RestoredCredentials, result.RestoredJobs, err = s.repo.Restore(tenantSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: tenant %s restored from backup %s (%d DIDs, %d credentials, %d jobs)",
+		req.Tenant, backup.ID, result.RestoredDIDs, result.RestoredCredentials, result.RestoredJobs)
+
+	return result, nil
+}
+
+// load downloads, decrypts and decompresses a backup's snapshot
+func (s *BackupService) load(backup *domain.Backup) (*domain.BackupSnapshot, error) {
+	data, err := s.vault.Get(context.Background(), backup.ObjectName, backup.KeyID)
+	if err != nil {
+		if errors.Is(err, vault.ErrNotFound) {
+			return nil, fmt.Errorf("%w: object %s is missing from the bucket", domain.ErrInvalidBackup, backup.ObjectName)
+		}
+		return nil, fmt.Errorf("failed to load backup: %w", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+	}
+
+	var snapshot domain.BackupSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+	}
+	if snapshot.Format != domain.BackupFormat {
+		return nil, fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidBackup, snapshot.Format)
+	}
+	return &snapshot, nil
+}
+
+// filterSnapshot keeps the DIDs a tenant created, the credentials issued by or to
+// them, and their blockchain jobs
+func filterSnapshot(snapshot *domain.BackupSnapshot, tenant string) (*domain.BackupSnapshot, error) {
+	filtered := &domain.BackupSnapshot{
+		Format:    snapshot.Format,
+		CreatedAt: snapshot.CreatedAt,
+	}
+
+	dids := make(map[string]bool)
+	didIDs := make(map[string]bool)
+	for _, row := range snapshot.DIDs {
+		var record struct {
+			ID  string `json:"id"`
+			DID string `json:"did"`
+		}
+		if err := json.Unmarshal(row, &record); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+		}
+		if snapshot.DIDTenants[record.DID] != tenant {
+			continue
+		}
+		dids[record.DID] = true
+		didIDs[record.ID] = true
+		filtered.DIDs = append(filtered.DIDs, row)
+	}
+
+	for _, row := range snapshot.Credentials {
+		var record struct {
+			IssuerDID  string `json:"issuer_did"`
+			SubjectDID string `json:"subject_did"`
+		}
+		if err := json.Unmarshal(row, &record); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+		}
+		if dids[record.IssuerDID] || dids[record.SubjectDID] {
+			filtered.Credentials = append(filtered.Credentials, row)
+		}
+	}
+
+	for _, row := range snapshot.Jobs {
+		var record struct {
+			DIDID string `json:"did_id"`
+		}
+		if err := json.Unmarshal(row, &record); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBackup, err)
+		}
+		if didIDs[record.DIDID] {
+			filtered.Jobs = append(filtered.Jobs, row)
+		}
+	}
+
+	return filtered, nil
+}
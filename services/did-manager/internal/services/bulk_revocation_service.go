@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+)
+
+const (
+	// maxBulkRevocationTargets caps the targets of a bulk revocation, including the
+	// credentials selected by issuer
+	maxBulkRevocationTargets = 100000
+	// bulkCredentialChunk is the number of credential targets revoked per revocation
+	// and run; they are not chain writes, so only the database paces them
+	bulkCredentialChunk = 500
+	// bulkRevocationListLimit caps the revocations listed
+	bulkRevocationListLimit = 100
+)
+
+// BulkRevocationService revokes many DIDs or credentials at once, e.g. when terminating
+// a whole organization. A revocation is created staged with its targets resolved, so
+// its scope can be reviewed and it can still be canceled. Once executed it can no
+// longer be canceled: ProcessExecuting revokes its credentials in chunks and deactivates
+// its DIDs, enqueueing at most chainWrites revoke_did jobs per run across revocations.
+type BulkRevocationService struct {
+	repo           domain.BulkRevocationRepository
+	credentialRepo domain.CredentialRepository
+	dids           *DIDService
+	credentials    *CredentialService
+	chainWrites    int
+}
+
+// NewBulkRevocationService creates a new bulk revocation service enqueueing at most
+// chainWrites DID deactivations per run
+func NewBulkRevocationService(
+	repo domain.BulkRevocationRepository,
+	credentialRepo domain.CredentialRepository,
+	dids *DIDService,
+	credentials *CredentialService,
+	chainWrites int,
+) *BulkRevocationService {
+	return &BulkRevocationService{
+		repo:           repo,
+		credentialRepo: credentialRepo,
+		dids:           dids,
+		credentials:    credentials,
+		chainWrites:    chainWrites,
+	}
+}
+
+// StageDIDs stages the deactivation of DIDs and, if requested, of the active
+// credentials they issued
+func (s *BulkRevocationService) StageDIDs(req *domain.DIDBulkRevocationRequest) (*domain.BulkRevocation, error) {
+	items := make([]*domain.BulkRevocationItem, 0, len(req.DIDs))
+	for _, didString := range req.DIDs {
+		items = append(items, bulkRevocationItem(domain.BulkRevocationTargetDID, didString))
+	}
+	if req.RevokeIssuedCredentials {
+		for _, didString := range req.DIDs {
+			issued, err := s.activeCredentials(didString, maxBulkRevocationTargets-len(items))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, issued...)
+		}
+	}
+
+	return s.stage(req.Reason, items)
+}
+
+// StageCredentials stages the revocation of the listed credentials, or of every active
+// credential of an issuer
+func (s *BulkRevocationService) StageCredentials(req *domain.CredentialBulkRevocationRequest) (*domain.BulkRevocation, error) {
+	items := make([]*domain.BulkRevocationItem, 0, len(req.CredentialIDs))
+	for _, id := range req.CredentialIDs {
+		items = append(items, bulkRevocationItem(domain.BulkRevocationTargetCredential, id.String()))
+	}
+	if req.IssuerDID != "" {
+		issued, err := s.activeCredentials(req.IssuerDID, maxBulkRevocationTargets-len(items))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, issued...)
+	}
+
+	return s.stage(req.Reason, items)
+}
+
+// Execute starts revoking a staged revocation's targets. This is the point of no
+// return: from here on the revocation can no longer be canceled.
+func (s *BulkRevocationService) Execute(id uuid.UUID) (*domain.BulkRevocation, error) {
+	if err := s.repo.Transition(id, domain.BulkRevocationStaged, domain.BulkRevocationExecuting, time.Now()); err != nil {
+		return nil, err
+	}
+
+	revocation, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: bulk revocation %s of %d targets executed by admin: %s", revocation.ID, revocation.Total, revocation.Reason)
+	return revocation, nil
+}
+
+// Cancel cancels a staged revocation; executing revocations cannot be canceled
+func (s *BulkRevocationService) Cancel(id uuid.UUID) (*domain.BulkRevocation, error) {
+	if err := s.repo.Transition(id, domain.BulkRevocationStaged, domain.BulkRevocationCanceled, time.Now()); err != nil {
+		return nil, err
+	}
+
+	revocation, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: bulk revocation %s canceled by admin before execution", revocation.ID)
+	return revocation, nil
+}
+
+// Get returns a revocation's progress with its failed targets
+func (s *BulkRevocationService) Get(id uuid.UUID) (*domain.BulkRevocationDetail, error) {
+	revocation, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := s.repo.ListFailedItems(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BulkRevocationDetail{BulkRevocation: revocation, Failures: failures}, nil
+}
+
+// List returns the most recent revocations with their progress
+func (s *BulkRevocationService) List() ([]*domain.BulkRevocation, error) {
+	return s.repo.List(bulkRevocationListLimit)
+}
+
+// ProcessExecuting advances the executing revocations, oldest first: it revokes a
+// chunk of each one's credentials, then deactivates DIDs until the run's chain write
+// budget is spent. Revocations without pending targets are completed.
+func (s *BulkRevocationService) ProcessExecuting() error {
+	revocations, err := s.repo.ListByStatus(domain.BulkRevocationExecuting)
+	if err != nil {
+		return err
+	}
+
+	budget := s.chainWrites
+	for _, revocation := range revocations {
+		credentials, err := s.repo.ListPendingItems(revocation.ID, domain.BulkRevocationTargetCredential, bulkCredentialChunk)
+		if err != nil {
+			return err
+		}
+		for _, item := range credentials {
+			if err := s.record(item, s.revokeCredential(item)); err != nil {
+				return err
+			}
+		}
+
+		if budget > 0 {
+			dids, err := s.repo.ListPendingItems(revocation.ID, domain.BulkRevocationTargetDID, budget)
+			if err != nil {
+				return err
+			}
+			for _, item := range dids {
+				if err := s.record(item, s.deactivateDID(item)); err != nil {
+					return err
+				}
+			}
+			budget -= len(dids)
+		}
+
+		progress, err := s.repo.Get(revocation.ID)
+		if err != nil {
+			return err
+		}
+		if progress.Pending > 0 {
+			continue
+		}
+		if err := s.repo.Transition(revocation.ID, domain.BulkRevocationExecuting, domain.BulkRevocationCompleted, time.Now()); err != nil {
+			return err
+		}
+		log.Printf("AUDIT: bulk revocation %s completed: %d revoked, %d skipped, %d failed",
+			progress.ID, progress.Revoked, progress.Skipped, progress.Failed)
+	}
+
+	return nil
+}
+
+// stage stores a revocation of the given targets, staged for review
+func (s *BulkRevocationService) stage(reason string, items []*domain.BulkRevocationItem) (*domain.BulkRevocation, error) {
+	if len(items) > maxBulkRevocationTargets {
+		return nil, fmt.Errorf("%w: %d targets, at most %d", domain.ErrBulkRevocationTooLarge, len(items), maxBulkRevocationTargets)
+	}
+
+	revocation := &domain.BulkRevocation{
+		ID:        uuid.New(),
+		Reason:    reason,
+		Status:    string(domain.BulkRevocationStaged),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(revocation, items); err != nil {
+		return nil, err
+	}
+
+	// Reload for the counts of the targets stored, without duplicates
+	staged, err := s.repo.Get(revocation.ID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: bulk revocation %s of %d targets staged by admin: %s", staged.ID, staged.Total, staged.Reason)
+	return staged, nil
+}
+
+// activeCredentials lists the active credentials issued by a DID as targets, failing
+// with ErrBulkRevocationTooLarge beyond limit
+func (s *BulkRevocationService) activeCredentials(issuerDID string, limit int) ([]*domain.BulkRevocationItem, error) {
+	const page = 1000
+	search := &domain.CredentialSearchRequest{Status: string(domain.CredentialStatusActive)}
+
+	var items []*domain.BulkRevocationItem
+	for offset := 0; ; offset += page {
+		credentials, total, err := s.credentialRepo.Search(issuerDID, search, page, offset)
+		if err != nil {
+			return nil, err
+		}
+		if total > limit {
+			return nil, fmt.Errorf("%w: %s issued %d active credentials", domain.ErrBulkRevocationTooLarge, issuerDID, total)
+		}
+		for _, credential := range credentials {
+			items = append(items, bulkRevocationItem(domain.BulkRevocationTargetCredential, credential.ID.String()))
+		}
+		if len(credentials) < page {
+			return items, nil
+		}
+	}
+}
+
+// revokeCredential revokes a credential target, reporting it skipped when it is
+// unknown or already revoked
+func (s *BulkRevocationService) revokeCredential(item *domain.BulkRevocationItem) error {
+	id, err := uuid.Parse(item.Target)
+	if err != nil {
+		return fmt.Errorf("invalid credential ID: %w", err)
+	}
+	record, err := s.credentialRepo.GetByID(id)
+	if errors.Is(err, domain.ErrCredentialNotFound) {
+		return errBulkTargetSkipped
+	}
+	if err != nil {
+		return err
+	}
+	if record.Status == string(domain.CredentialStatusRevoked) {
+		return errBulkTargetSkipped
+	}
+
+	_, err = s.credentials.RevokeCredential("", id)
+	return err
+}
+
+// deactivateDID deactivates a DID target, reporting it skipped when it is unknown or
+// already deactivated
+func (s *BulkRevocationService) deactivateDID(item *domain.BulkRevocationItem) error {
+	response, err := s.dids.DeactivateDID(item.Target, "")
+	if errors.Is(err, domain.ErrDIDNotFound) || errors.Is(err, domain.ErrDIDDeactivated) {
+		return errBulkTargetSkipped
+	}
+	if err != nil {
+		return err
+	}
+
+	item.JobID = &response.JobID
+	return nil
+}
+
+// record stores the outcome of revoking a target
+func (s *BulkRevocationService) record(item *domain.BulkRevocationItem, outcome error) error {
+	now := time.Now()
+	item.ProcessedAt = &now
+	switch {
+	case outcome == nil:
+		item.Status = string(domain.BulkRevocationItemRevoked)
+	case errors.Is(outcome, errBulkTargetSkipped):
+		item.Status = string(domain.BulkRevocationItemSkipped)
+	default:
+		item.Status = string(domain.BulkRevocationItemFailed)
+		item.Error = outcome.Error()
+		log.Printf("Bulk revocation %s failed to revoke %s %s: %v", item.RevocationID, item.TargetType, item.Target, outcome)
+	}
+	return s.repo.RecordItem(item)
+}
+
+// errBulkTargetSkipped reports a target that needs no revocation
+var errBulkTargetSkipped = errors.New("target needs no revocation")
+
+// bulkRevocationItem builds a pending target
+func bulkRevocationItem(targetType domain.BulkRevocationTarget, target string) *domain.BulkRevocationItem {
+	return &domain.BulkRevocationItem{
+		TargetType: string(targetType),
+		Target:     target,
+		Status:     string(domain.BulkRevocationItemPending),
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import "did-manager/internal/domain"
+
+// CapabilityService reports which of the service's external dependencies are
+// available, so callers can check before relying on them instead of failing mid-way
+type CapabilityService struct {
+	ledger Ledger
+	queue  JobPublisher
+	// conformance reports whether the conformance mode endpoints are served
+	conformance bool
+	// smartAccounts reports whether the smart account endpoints are served
+	smartAccounts bool
+}
+
+// NewCapabilityService creates a capability service over the dependencies the other
+// services were built with
+func NewCapabilityService(ledger Ledger, queue JobPublisher) *CapabilityService {
+	return &CapabilityService{ledger: ledger, queue: queue}
+}
+
+// SetConformanceMode reports the conformance capability as available
+func (s *CapabilityService) SetConformanceMode(enabled bool) {
+	s.conformance = enabled
+}
+
+// SetSmartAccounts reports the smart account capability as available
+func (s *CapabilityService) SetSmartAccounts(enabled bool) {
+	s.smartAccounts = enabled
+}
+
+// Check returns nil when a capability is available, or the reason it is offline
+func (s *CapabilityService) Check(capability domain.Capability) error {
+	switch capability {
+	case domain.CapabilityBlockchain:
+		return unavailable(s.ledger)
+	case domain.CapabilityQueue:
+		return unavailable(s.queue)
+	case domain.CapabilityConformance:
+		if !s.conformance {
+			return domain.ErrConformanceModeDisabled
+		}
+		return nil
+	case domain.CapabilitySmartAccounts:
+		if !s.smartAccounts {
+			return domain.ErrSmartAccountsDisabled
+		}
+		return nil
+	default:
+		return domain.ErrUnknownCapability
+	}
+}
+
+// List reports the status of every capability. The conformance capability lists its
+// deviations from the DID specifications whether or not it is enabled.
+func (s *CapabilityService) List() []domain.CapabilityStatus {
+	capabilities := []domain.Capability{domain.CapabilityBlockchain, domain.CapabilityQueue, domain.CapabilityConformance, domain.CapabilitySmartAccounts}
+	statuses := make([]domain.CapabilityStatus, 0, len(capabilities))
+	for _, capability := range capabilities {
+		status := domain.CapabilityStatus{Capability: capability, Available: true}
+		if err := s.Check(capability); err != nil {
+			status.Available = false
+			status.Reason = err.Error()
+		}
+		if capability == domain.CapabilityConformance {
+			status.Deviations = ConformanceDeviations
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"sync"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/queue"
+)
+
+// chainEventBatchBlocks bounds the blocks read in one poll, so catching up after an
+// outage stays within the log range limits of RPC providers
+const chainEventBatchBlocks = 2000
+
+// chainEventBuffer is how many events a slow stream subscriber may fall behind before
+// events are dropped for it
+const chainEventBuffer = 64
+
+// ChainEventSource reads the DID events of the registry contract; implemented by
+// *blockchain.EthereumClient
+type ChainEventSource interface {
+	HeadBlock() (uint64, error)
+	DIDEvents(fromBlock, toBlock uint64) ([]*blockchain.DIDEvent, error)
+}
+
+// ChainEventService follows the DIDUpdated and DIDRevoked events of the registry
+// contract. Each event drops the DID from the read cache, so cached records and the
+// verifications based on them do not outlive an on-chain change by more than a poll
+// interval, is published to the event stream for webhooks, and is streamed to the
+// subscribers that may resolve the DID.
+type ChainEventService struct {
+	source  ChainEventSource
+	didRepo domain.DIDRepository
+	// events publishes chain events to the event stream; nil without one
+	events EventPublisher
+	access *AccessService
+
+	// next is the first block the next poll reads; zero until the first poll
+	next uint64
+
+	mu          sync.Mutex
+	subscribers map[chan *queue.Event]*domain.Caller
+}
+
+// NewChainEventService creates a new chain event service. didRepo is the repository
+// whose read cache is invalidated; events may be nil.
+func NewChainEventService(source ChainEventSource, didRepo domain.DIDRepository, events EventPublisher, access *AccessService) *ChainEventService {
+	return &ChainEventService{
+		source:      source,
+		didRepo:     didRepo,
+		events:      events,
+		access:      access,
+		subscribers: make(map[chan *queue.Event]*domain.Caller),
+	}
+}
+
+// Poll handles the events of the blocks mined since the last poll. The first poll only
+// records the latest block: earlier changes are already reflected in the records.
+// Polls must not run concurrently.
+func (s *ChainEventService) Poll() error {
+	head, err := s.source.HeadBlock()
+	if err != nil {
+		return err
+	}
+	if s.next == 0 {
+		s.next = head + 1
+		return nil
+	}
+	if s.next > head {
+		return nil
+	}
+
+	to := head
+	if to-s.next >= chainEventBatchBlocks {
+		to = s.next + chainEventBatchBlocks - 1
+	}
+	events, err := s.source.DIDEvents(s.next, to)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		s.handle(event)
+	}
+
+	s.next = to + 1
+	return nil
+}
+
+// handle invalidates the cached record of an event's DID and publishes the event
+func (s *ChainEventService) handle(chainEvent *blockchain.DIDEvent) {
+	cached := forgetCachedDID(s.didRepo, chainEvent.DID)
+
+	eventType := queue.EventDIDChainUpdated
+	if chainEvent.Name == blockchain.DIDEventRevoked {
+		eventType = queue.EventDIDChainRevoked
+	}
+	event := &queue.Event{
+		// Derived from the transaction, so an event read twice is delivered once
+		ID:      chainEvent.TxHash + ":" + chainEvent.Name,
+		Type:    eventType,
+		Subject: chainEvent.DID,
+		Data: map[string]string{
+			"user_hash":        chainEvent.UserHash,
+			"transaction_hash": chainEvent.TxHash,
+			"block_number":     strconv.FormatUint(chainEvent.BlockNumber, 10),
+		},
+		OccurredAt: chainEvent.OccurredAt,
+	}
+
+	if cached {
+		log.Printf("Dropped cached DID %s after %s in block %d", chainEvent.DID, chainEvent.Name, chainEvent.BlockNumber)
+	}
+	if s.events != nil {
+		if err := s.events.PublishEvent(event); err != nil {
+			log.Printf("Warning: failed to publish %s event: %v", eventType, err)
+		}
+	}
+	s.broadcast(event)
+}
+
+// Subscribe streams the chain events of the DIDs the caller may resolve until the
+// returned function is called
+func (s *ChainEventService) Subscribe(caller *domain.Caller) (<-chan *queue.Event, func()) {
+	ch := make(chan *queue.Event, chainEventBuffer)
+
+	s.mu.Lock()
+	s.subscribers[ch] = caller
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// broadcast sends an event to the subscribers allowed to resolve its DID, without
+// waiting for slow ones. Events of DIDs unknown here are not streamed. The DID and the
+// subscribers' access are looked up outside the lock, so slow lookups do not hold up
+// subscribing and unsubscribing.
+func (s *ChainEventService) broadcast(event *queue.Event) {
+	s.mu.Lock()
+	subscribers := make(map[chan *queue.Event]*domain.Caller, len(s.subscribers))
+	for ch, caller := range s.subscribers {
+		subscribers[ch] = caller
+	}
+	s.mu.Unlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	record, err := s.didRepo.GetByDID(event.Subject)
+	if err != nil {
+		return
+	}
+	for ch, caller := range subscribers {
+		if allowed, err := s.access.CanResolve(record, caller); err != nil || !allowed {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Warning: dropped %s event for a slow chain event subscriber", event.Type)
+		}
+	}
+}
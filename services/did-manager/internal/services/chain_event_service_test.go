@@ -0,0 +1,240 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/queue"
+)
+
+// fakeChainEvents serves a head block and the events of a range of blocks, recording
+// the ranges read
+type fakeChainEvents struct {
+	head   uint64
+	events []*blockchain.DIDEvent
+	reads  [][2]uint64
+}
+
+func (f *fakeChainEvents) HeadBlock() (uint64, error) {
+	return f.head, nil
+}
+
+func (f *fakeChainEvents) DIDEvents(fromBlock, toBlock uint64) ([]*blockchain.DIDEvent, error) {
+	f.reads = append(f.reads, [2]uint64{fromBlock, toBlock})
+	var events []*blockchain.DIDEvent
+	for _, event := range f.events {
+		if event.BlockNumber >= fromBlock && event.BlockNumber <= toBlock {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// fakeDIDs serves fixed DID records, calling lookup first when set
+type fakeDIDs struct {
+	domain.DIDRepository
+	records map[string]*domain.DID
+	lookup  func()
+}
+
+func (f *fakeDIDs) GetByDID(did string) (*domain.DID, error) {
+	if f.lookup != nil {
+		f.lookup()
+	}
+	if record, ok := f.records[did]; ok {
+		return record, nil
+	}
+	return nil, domain.ErrDIDNotFound
+}
+
+// fakeACL grants access to the grantees it lists
+type fakeACL struct {
+	domain.ACLRepository
+	grantees map[string]bool
+}
+
+func (f *fakeACL) Exists(didID uuid.UUID, granteeType, grantee string) (bool, error) {
+	return f.grantees[grantee], nil
+}
+
+// fakePublisher records the events published
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []*queue.Event
+}
+
+func (f *fakePublisher) PublishEvent(event *queue.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return nil
+}
+
+func newChainEventService(source ChainEventSource, dids *fakeDIDs, events EventPublisher, acl *fakeACL) *ChainEventService {
+	return NewChainEventService(source, dids, events, NewAccessService(nil, acl, dids, nil))
+}
+
+func TestChainEventPollStartsAtHead(t *testing.T) {
+	source := &fakeChainEvents{
+		head:   100,
+		events: []*blockchain.DIDEvent{{Name: blockchain.DIDEventUpdated, DID: "did:example:old", BlockNumber: 90}},
+	}
+	publisher := &fakePublisher{}
+	service := newChainEventService(source, &fakeDIDs{}, publisher, &fakeACL{})
+
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(source.reads) != 0 || len(publisher.events) != 0 {
+		t.Fatalf("expected the first poll to read no events, got reads %v", source.reads)
+	}
+
+	// Nothing was mined since
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(source.reads) != 0 {
+		t.Fatalf("expected no reads without new blocks, got %v", source.reads)
+	}
+}
+
+func TestChainEventPollPublishesNewEvents(t *testing.T) {
+	occurredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeChainEvents{head: 100}
+	publisher := &fakePublisher{}
+	service := newChainEventService(source, &fakeDIDs{}, publisher, &fakeACL{})
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	source.head = 105
+	source.events = []*blockchain.DIDEvent{
+		{Name: blockchain.DIDEventUpdated, DID: "did:example:1", UserHash: "0xuser", TxHash: "0xa", BlockNumber: 102, OccurredAt: occurredAt},
+		{Name: blockchain.DIDEventRevoked, DID: "did:example:2", TxHash: "0xb", BlockNumber: 105, OccurredAt: occurredAt},
+	}
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	if len(source.reads) != 1 || source.reads[0] != [2]uint64{101, 105} {
+		t.Fatalf("expected blocks 101 to 105 read, got %v", source.reads)
+	}
+	if len(publisher.events) != 2 {
+		t.Fatalf("expected two events published, got %d", len(publisher.events))
+	}
+	updated, revoked := publisher.events[0], publisher.events[1]
+	if updated.ID != "0xa:DIDUpdated" || updated.Type != queue.EventDIDChainUpdated || updated.Subject != "did:example:1" || !updated.OccurredAt.Equal(occurredAt) {
+		t.Errorf("unexpected update event %+v", updated)
+	}
+	if updated.Data["user_hash"] != "0xuser" || updated.Data["block_number"] != "102" {
+		t.Errorf("unexpected update event data %v", updated.Data)
+	}
+	if revoked.Type != queue.EventDIDChainRevoked || revoked.Subject != "did:example:2" {
+		t.Errorf("unexpected revocation event %+v", revoked)
+	}
+
+	// The next poll continues after the blocks read
+	source.head = 106
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(source.reads) != 2 || source.reads[1] != [2]uint64{106, 106} {
+		t.Errorf("expected block 106 read next, got %v", source.reads)
+	}
+}
+
+func TestChainEventPollBoundsBatch(t *testing.T) {
+	source := &fakeChainEvents{head: 1}
+	service := newChainEventService(source, &fakeDIDs{}, nil, &fakeACL{})
+	if err := service.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	source.head = 1 + chainEventBatchBlocks + 500
+	for i := 0; i < 2; i++ {
+		if err := service.Poll(); err != nil {
+			t.Fatalf("Poll failed: %v", err)
+		}
+	}
+	want := [][2]uint64{{2, 1 + chainEventBatchBlocks}, {2 + chainEventBatchBlocks, source.head}}
+	if len(source.reads) != 2 || source.reads[0] != want[0] || source.reads[1] != want[1] {
+		t.Errorf("expected reads %v, got %v", want, source.reads)
+	}
+}
+
+func TestChainEventBroadcastChecksAccess(t *testing.T) {
+	dids := &fakeDIDs{records: map[string]*domain.DID{
+		"did:example:public":  {ID: uuid.New(), Did: "did:example:public"},
+		"did:example:private": {ID: uuid.New(), Did: "did:example:private", Visibility: string(domain.DIDVisibilityPrivate)},
+	}}
+	acl := &fakeACL{grantees: map[string]bool{"key-granted": true}}
+	service := newChainEventService(&fakeChainEvents{}, dids, nil, acl)
+
+	anonymous, unsubscribeAnonymous := service.Subscribe(&domain.Caller{Type: domain.CallerTypeAnonymous})
+	defer unsubscribeAnonymous()
+	granted, unsubscribeGranted := service.Subscribe(&domain.Caller{Type: domain.CallerTypeAPIKey, ID: "key-granted"})
+	defer unsubscribeGranted()
+
+	service.broadcast(&queue.Event{Type: queue.EventDIDChainUpdated, Subject: "did:example:public"})
+	service.broadcast(&queue.Event{Type: queue.EventDIDChainUpdated, Subject: "did:example:private"})
+	service.broadcast(&queue.Event{Type: queue.EventDIDChainUpdated, Subject: "did:example:unknown"})
+
+	if len(anonymous) != 1 || (<-anonymous).Subject != "did:example:public" {
+		t.Errorf("expected anonymous subscribers to get the public DID's event only")
+	}
+	if len(granted) != 2 {
+		t.Errorf("expected the granted subscriber to get both known DIDs' events, got %d", len(granted))
+	}
+}
+
+func TestChainEventBroadcastLooksUpOutsideLock(t *testing.T) {
+	dids := &fakeDIDs{records: map[string]*domain.DID{"did:example:1": {ID: uuid.New(), Did: "did:example:1"}}}
+	service := newChainEventService(&fakeChainEvents{}, dids, nil, &fakeACL{})
+
+	// Subscribing while the DID is looked up would block if the lookup held the lock
+	var late <-chan *queue.Event
+	dids.lookup = func() {
+		var unsubscribe func()
+		late, unsubscribe = service.Subscribe(nil)
+		defer unsubscribe()
+	}
+	ch, unsubscribe := service.Subscribe(nil)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		service.broadcast(&queue.Event{Type: queue.EventDIDChainRevoked, Subject: "did:example:1"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("broadcast held the subscriber lock during the DID lookup")
+	}
+
+	if len(ch) != 1 {
+		t.Errorf("expected the subscriber to get the event, got %d", len(ch))
+	}
+	if len(late) != 0 {
+		t.Errorf("expected subscribers joining during the broadcast to miss it")
+	}
+}
+
+func TestChainEventBroadcastDropsForSlowSubscribers(t *testing.T) {
+	dids := &fakeDIDs{records: map[string]*domain.DID{"did:example:1": {ID: uuid.New(), Did: "did:example:1"}}}
+	service := newChainEventService(&fakeChainEvents{}, dids, nil, &fakeACL{})
+	ch, unsubscribe := service.Subscribe(nil)
+	defer unsubscribe()
+
+	for i := 0; i < chainEventBuffer+10; i++ {
+		service.broadcast(&queue.Event{Type: queue.EventDIDChainUpdated, Subject: "did:example:1"})
+	}
+	if len(ch) != chainEventBuffer {
+		t.Errorf("expected %d buffered events, got %d", chainEventBuffer, len(ch))
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// defaultChangeLimit bounds change pages without an explicit limit
+const defaultChangeLimit = 100
+
+// ChangeReader reads retained domain events after a stream sequence; implemented by the
+// NATS queue
+type ChangeReader interface {
+	ReadEvents(typePrefix string, after uint64, limit int) ([]*queue.StoredEvent, uint64, bool, error)
+}
+
+// ChangeService serves the DID change feed, letting downstream caches and analytics
+// sync incrementally from the DID change events in the event stream. Changes are
+// retained as long as the stream keeps events; consumers whose cursor has expired
+// resync from a full export.
+type ChangeService struct {
+	reader   ChangeReader
+	resolver *ResolverService
+}
+
+// NewChangeService creates a new change service; reader may be nil when no event
+// stream is available
+func NewChangeService(reader ChangeReader, resolver *ResolverService) *ChangeService {
+	return &ChangeService{
+		reader:   reader,
+		resolver: resolver,
+	}
+}
+
+// Changes returns the DID changes after a cursor with the current state of each DID.
+// DIDs the caller may not resolve are left out.
+func (s *ChangeService) Changes(req *domain.DIDChangesRequest, caller *domain.Caller) (*domain.DIDChangePage, error) {
+	if s.reader == nil {
+		return nil, domain.ErrChangeFeedUnavailable
+	}
+
+	var after uint64
+	if req.Since != "" {
+		cursor, err := strconv.ParseUint(req.Since, 10, 64)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		after = cursor
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultChangeLimit
+	}
+
+	events, cursor, more, err := s.reader.ReadEvents(queue.EventDIDPrefix, after, limit)
+	if err != nil {
+		if errors.Is(err, queue.ErrCursorExpired) {
+			return nil, domain.ErrCursorExpired
+		}
+		return nil, err
+	}
+
+	// Keep the latest change of each DID; earlier ones resolve to the same state
+	latest := make(map[string]int, len(events))
+	for i, event := range events {
+		latest[event.Subject] = i
+	}
+
+	changes := []*domain.DIDChange{}
+	for i, event := range events {
+		if latest[event.Subject] != i {
+			continue
+		}
+
+		resolution, err := s.resolver.Resolve(event.Subject, caller)
+		if err != nil {
+			if errors.Is(err, domain.ErrDIDNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		changes = append(changes, &domain.DIDChange{
+			Sequence:   event.Sequence,
+			Type:       event.Type,
+			DID:        event.Subject,
+			OccurredAt: event.OccurredAt,
+			Resolution: resolution,
+		})
+	}
+
+	return &domain.DIDChangePage{
+		Changes: changes,
+		Cursor:  strconv.FormatUint(cursor, 10),
+		HasMore: more,
+	}, nil
+}
+
+// didChangePublisher publishes a DID change event after every change to a DID record
+type didChangePublisher struct {
+	domain.DIDRepository
+	events EventPublisher
+}
+
+// PublishDIDChanges wraps a DID repository so that every change to a DID record is
+// published to the event stream, feeding the change feed. Without an event publisher
+// the repository is returned as is.
+func PublishDIDChanges(repo domain.DIDRepository, events EventPublisher) domain.DIDRepository {
+	if events == nil {
+		return repo
+	}
+	return &didChangePublisher{DIDRepository: repo, events: events}
+}
+
+// Create stores a DID and publishes its creation
+func (r *didChangePublisher) Create(record *domain.DID) error {
+	if err := r.DIDRepository.Create(record); err != nil {
+		return err
+	}
+	r.publish(queue.EventDIDCreated, record.Did, map[string]string{"status": record.Status})
+	return nil
+}
+
+// Update stores a DID and publishes the update
+func (r *didChangePublisher) Update(record *domain.DID) error {
+	if err := r.DIDRepository.Update(record); err != nil {
+		return err
+	}
+	r.publish(queue.EventDIDUpdated, record.Did, map[string]string{"status": record.Status})
+	return nil
+}
+
+// UpdateStatus updates a DID's status and publishes the change
+func (r *didChangePublisher) UpdateStatus(id uuid.UUID, status domain.DIDStatus, txHash string) error {
+	if err := r.DIDRepository.UpdateStatus(id, status, txHash); err != nil {
+		return err
+	}
+	r.publishByID(id, queue.EventDIDStatusChanged, map[string]string{"status": string(status)})
+	return nil
+}
+
+// UpdateVisibility updates a DID's visibility and publishes the change
+func (r *didChangePublisher) UpdateVisibility(id uuid.UUID, visibility string) error {
+	if err := r.DIDRepository.UpdateVisibility(id, visibility); err != nil {
+		return err
+	}
+	r.publishByID(id, queue.EventDIDVisibilityChanged, map[string]string{"visibility": visibility})
+	return nil
+}
+
+// RotateKey replaces a DID's key and publishes the rotation
+func (r *didChangePublisher) RotateKey(id uuid.UUID, keyMaterial string, keyAlgorithm string) error {
+	if err := r.DIDRepository.RotateKey(id, keyMaterial, keyAlgorithm); err != nil {
+		return err
+	}
+	r.publishByID(id, queue.EventDIDKeyRotated, map[string]string{"key_algorithm": keyAlgorithm})
+	return nil
+}
+
+// publishByID publishes a change of the DID with the given record ID
+func (r *didChangePublisher) publishByID(id uuid.UUID, eventType string, data map[string]string) {
+	record, err := r.DIDRepository.GetByID(id)
+	if err != nil {
+		log.Printf("Warning: failed to load DID %s for %s event: %v", id, eventType, err)
+		return
+	}
+	r.publish(eventType, record.Did, data)
+}
+
+// publish emits a DID change event; failures are logged rather than failing the change
+func (r *didChangePublisher) publish(eventType, didString string, data map[string]string) {
+	event := &queue.Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Subject:    didString,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+	if err := r.events.PublishEvent(event); err != nil {
+		log.Printf("Warning: failed to publish %s event: %v", eventType, err)
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// claimTypePattern matches the types of claims added to DIDs, e.g. phone or
+// postal_address
+var claimTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ClaimService adds claims verified by tenants, such as a phone number or an address,
+// to existing DIDs. Each added claim is committed to under the generator's default
+// scheme and pepper and appended to the DID's claims, bumping the versionId of its
+// DID Document; when an issuer DID is configured, it also issues the DID a
+// VerifiedClaimCredential.
+type ClaimService struct {
+	claims      domain.ClaimRepository
+	access      *AccessService
+	didGen      *did.Generator
+	credentials *CredentialService
+	// issuerDID is the custodial DID issuing claim credentials; empty adds claims
+	// without issuing credentials
+	issuerDID string
+}
+
+// NewClaimService creates a new claim service
+func NewClaimService(
+	claims domain.ClaimRepository,
+	access *AccessService,
+	didGen *did.Generator,
+	credentials *CredentialService,
+	issuerDID string,
+) *ClaimService {
+	return &ClaimService{
+		claims:      claims,
+		access:      access,
+		didGen:      didGen,
+		credentials: credentials,
+		issuerDID:   issuerDID,
+	}
+}
+
+// AddClaim commits to a claim the caller verified for a DID and issues the DID a
+// credential for it. A claim of a type the DID already has supersedes it. The claim
+// stays committed if the credential cannot be issued.
+func (s *ClaimService) AddClaim(req *domain.ClaimAddRequest, caller *domain.Caller) (*domain.ClaimAddResponse, error) {
+	if err := validateClaimType(req.Type); err != nil {
+		return nil, err
+	}
+	record, err := s.access.CheckDIDAccess(req.DID, caller)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+
+	commitment, err := s.didGen.CommitClaim(req.Type, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	claim := &domain.ClaimCommitment{
+		ID:            uuid.New(),
+		DIDID:         record.ID,
+		Type:          req.Type,
+		Scheme:        commitment.Scheme,
+		Params:        commitment.Params,
+		Commitment:    commitment.Value,
+		PepperVersion: commitment.PepperVersion,
+		VerifiedBy:    req.VerifiedBy,
+		VerifiedAt:    time.Now(),
+	}
+	if err := s.claims.Append(claim); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: %s claim added to %s at version %d by %s", claim.Type, record.Did, claim.Version, claim.VerifiedBy)
+
+	response := &domain.ClaimAddResponse{Claim: claim}
+	if s.issuerDID == "" {
+		return response, nil
+	}
+
+	credential, err := s.credentials.IssueCredential(s.issuerDID, &domain.CredentialIssueRequest{
+		SubjectDID: record.Did,
+		Type:       domain.VerifiedClaimCredentialType,
+		Claims: map[string]any{
+			"claim_type": req.Type,
+			req.Type:     req.Value,
+		},
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim added at version %d, but its credential was not issued: %w", claim.Version, err)
+	}
+	if err := s.claims.SetCredential(claim.ID, credential.ID); err != nil {
+		return nil, err
+	}
+	claim.CredentialID = &credential.ID
+	response.Credential = credential
+
+	return response, nil
+}
+
+// ListClaims lists the claim types a DID has verified with their latest commitments:
+// the name and email committed to at creation, unless the DID is anonymous, followed
+// by the claims added since
+func (s *ClaimService) ListClaims(didString string, caller *domain.Caller) (*domain.DIDClaims, error) {
+	record, err := s.access.CheckDIDAccess(didString, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.claims.ListLatest(record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.DIDClaims{
+		DID:    record.Did,
+		Claims: make([]*domain.ClaimCommitment, 0, len(added)+2),
+	}
+	if record.HashScheme != did.HashSchemeNone {
+		for _, claimType := range []string{domain.ClaimTypeName, domain.ClaimTypeEmail} {
+			result.Claims = append(result.Claims, &domain.ClaimCommitment{
+				DIDID:      record.ID,
+				Type:       claimType,
+				Scheme:     record.HashScheme,
+				Commitment: record.UserHash,
+				VerifiedAt: record.CreatedAt,
+			})
+		}
+	}
+	for _, claim := range added {
+		result.Claims = append(result.Claims, claim)
+		result.Version = max(result.Version, claim.Version)
+	}
+
+	return result, nil
+}
+
+// VerifyClaim checks a value against the latest commitment of its type added to a
+// DID. Name and email are checked together through DID verification instead.
+func (s *ClaimService) VerifyClaim(req *domain.ClaimVerifyRequest, caller *domain.Caller) (*domain.ClaimVerifyResponse, error) {
+	if err := validateClaimType(req.Type); err != nil {
+		return nil, err
+	}
+	record, err := s.access.CheckDIDAccess(req.DID, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	claim, err := s.claims.Latest(record.ID, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.didGen.VerifyCommitment(&did.Commitment{
+		Scheme:        claim.Scheme,
+		Params:        claim.Params,
+		Value:         claim.Commitment,
+		PepperVersion: claim.PepperVersion,
+	}, did.ClaimCommitmentInput(claim.Type, req.Value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify claim: %w", err)
+	}
+
+	return &domain.ClaimVerifyResponse{
+		DID:     record.Did,
+		Type:    claim.Type,
+		Matches: matches,
+		Version: claim.Version,
+	}, nil
+}
+
+// validateClaimType rejects malformed claim types and the claims committed to at
+// creation, which cannot be added
+func validateClaimType(claimType string) error {
+	if !claimTypePattern.MatchString(claimType) {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidClaimType, claimType)
+	}
+	if claimType == domain.ClaimTypeName || claimType == domain.ClaimTypeEmail {
+		return fmt.Errorf("%w: %s is committed to when the DID is created", domain.ErrInvalidClaimType, claimType)
+	}
+	return nil
+}
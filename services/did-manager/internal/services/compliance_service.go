@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/pdf"
+
+	"github.com/google/uuid"
+)
+
+// ReportSignatureAlgorithm identifies the signature scheme of compliance reports
+const ReportSignatureAlgorithm = "Ed25519"
+
+// ComplianceService generates signed compliance reports for auditors
+type ComplianceService struct {
+	repo domain.ComplianceRepository
+	// signingKey signs reports; nil disables report generation
+	signingKey ed25519.PrivateKey
+}
+
+// NewComplianceService creates a new compliance service
+func NewComplianceService(repo domain.ComplianceRepository, signingKey ed25519.PrivateKey) *ComplianceService {
+	return &ComplianceService{
+		repo:       repo,
+		signingKey: signingKey,
+	}
+}
+
+// Generate builds and signs the report of [from, to)
+func (s *ComplianceService) Generate(from, to time.Time) (*domain.ComplianceReport, *domain.SignedComplianceReport, error) {
+	if s.signingKey == nil {
+		return nil, nil, domain.ErrReportSigningDisabled
+	}
+
+	report, err := s.repo.Summarize(from.UTC(), to.UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+	report.ID = uuid.New()
+	report.GeneratedAt = time.Now().UTC()
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	signed := &domain.SignedComplianceReport{
+		Report:    encoded,
+		Signature: s.sign(encoded),
+	}
+
+	log.Printf("AUDIT: compliance report %s generated for %s to %s",
+		report.ID, report.From.Format(time.RFC3339), report.To.Format(time.RFC3339))
+	return report, signed, nil
+}
+
+// Verify checks that a signed report was signed by this service and not altered.
+// Whitespace changes are tolerated; the report is compacted before verification.
+func (s *ComplianceService) Verify(signed *domain.SignedComplianceReport) error {
+	if s.signingKey == nil {
+		return domain.ErrReportSigningDisabled
+	}
+
+	publicKey := s.signingKey.Public().(ed25519.PublicKey)
+	if signed.Signature.Algorithm != ReportSignatureAlgorithm || signed.Signature.PublicKey != hex.EncodeToString(publicKey) {
+		return fmt.Errorf("%w: report was not signed by this service's key", domain.ErrInvalidSignature)
+	}
+
+	signature, err := hex.DecodeString(signed.Signature.Value)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", domain.ErrInvalidSignature)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, signed.Report); err != nil {
+		return fmt.Errorf("%w: malformed report", domain.ErrInvalidSignature)
+	}
+	if !ed25519.Verify(publicKey, compact.Bytes(), signature) {
+		return domain.ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// RenderPDF renders a signed report as a PDF, returning the document and the
+// signature over its bytes
+func (s *ComplianceService) RenderPDF(report *domain.ComplianceReport, signed *domain.SignedComplianceReport) ([]byte, *domain.ReportSignature) {
+	doc := pdf.New()
+	doc.Line("Compliance Report")
+	doc.Line("")
+	doc.Linef("Report ID:    %s", report.ID)
+	doc.Linef("Period:       %s to %s", report.From.Format(time.RFC3339), report.To.Format(time.RFC3339))
+	doc.Linef("Generated at: %s", report.GeneratedAt.Format(time.RFC3339))
+	doc.Line("")
+	doc.Line("DIDs")
+	doc.Linef("  Created:                  %d", report.DIDs.Created)
+	doc.Linef("  Revoked (on-chain):       %d", report.DIDs.Revoked)
+	doc.Line("Credentials")
+	doc.Linef("  Issued:                   %d", report.Credentials.Issued)
+	doc.Linef("  Revoked:                  %d", report.Credentials.Revoked)
+	doc.Line("Verification sessions")
+	doc.Linef("  Opened:                   %d", report.Verifications.Sessions)
+	doc.Linef("  Verified:                 %d", report.Verifications.Verified)
+	doc.Linef("  Rejected:                 %d", report.Verifications.Rejected)
+	doc.Linef("  Expired:                  %d", report.Verifications.Expired)
+	doc.Line("")
+	doc.Linef("Anchoring transactions (%d)", len(report.Anchors))
+	for _, anchor := range report.Anchors {
+		doc.Linef("  %s  %s  %s", anchor.AnchoredAt.UTC().Format(time.RFC3339), anchor.Kind, anchor.Subject)
+		doc.Linef("    tx %s", anchor.TxHash)
+	}
+	doc.Line("")
+	doc.Line("Signature")
+	doc.Linef("  The JSON export of report %s is signed with %s.", report.ID, signed.Signature.Algorithm)
+	doc.Linef("  Key ID:    %s", signed.Signature.KeyID)
+	doc.Linef("  Public key: %s", signed.Signature.PublicKey)
+	doc.Linef("  Signature: %s", signed.Signature.Value)
+
+	document := doc.Bytes()
+	return document, s.sign(document)
+}
+
+// sign signs data with the report signing key
+func (s *ComplianceService) sign(data []byte) *domain.ReportSignature {
+	return signReport(s.signingKey, data)
+}
+
+// signReport signs exported data with an Ed25519 key, identifying the key by the
+// fingerprint of its public key
+func signReport(signingKey ed25519.PrivateKey, data []byte) *domain.ReportSignature {
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	fingerprint := sha256.Sum256(publicKey)
+	return &domain.ReportSignature{
+		Algorithm: ReportSignatureAlgorithm,
+		KeyID:     hex.EncodeToString(fingerprint[:]),
+		PublicKey: hex.EncodeToString(publicKey),
+		Value:     hex.EncodeToString(ed25519.Sign(signingKey, data)),
+	}
+}
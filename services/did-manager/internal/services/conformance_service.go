@@ -0,0 +1,307 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// ConformanceDeviations are the known departures from the DID specifications, reported
+// with the conformance capability so the suites' expected failures are documented
+var ConformanceDeviations = []domain.ConformanceDeviation{
+	{
+		Spec:        "DID Core",
+		Section:     "3.1 DID Syntax",
+		Description: "DIDs are issued under the did:example method name, which the DID Specification Registries reserve for examples, until a method name is registered",
+	},
+	{
+		Spec:        "DID Core",
+		Section:     "5.2.1 Verification Material",
+		Description: "Verification methods of managed DIDs carry their keys as publicKeyHex, a property the DID Specification Registries deprecate in favour of publicKeyMultibase and publicKeyJwk",
+	},
+	{
+		Spec:        "DID Resolution",
+		Section:     "Resolving a DID",
+		Description: "Private DIDs resolve as notFound for callers outside their access control list, rather than with an access error, so their existence is not disclosed",
+	},
+	{
+		Spec:        "DID Resolution",
+		Section:     "Dereferencing a DID URL",
+		Description: "DID URL dereferencing is not supported; DID URLs with a path, query or fragment are rejected as invalidDid",
+	},
+	{
+		Spec:        "DID Registration",
+		Section:     "Create",
+		Description: "The registrar only creates anonymous DIDs, with a custodial key or the publicKeyHex option, and deactivates DIDs that sign the request; client-managed secret mode and the update operation are not supported",
+	},
+	{
+		Spec:        "DID Resolution",
+		Section:     "DID Resolution Result",
+		Description: "The /api/v1 endpoints wrap results in the service's success envelope; the unwrapped result is served by the /1.0/identifiers driver endpoint",
+	},
+}
+
+// conformanceContentTypes are the representations produced, JSON-LD first
+var conformanceContentTypes = []string{domain.ContentTypeDIDLDJSON, domain.ContentTypeDIDJSON}
+
+// ConformanceService serves DID resolution and registration in the shapes the W3C DID
+// test suites and the DIF Universal Resolver and Registrar expect, and builds the
+// implementation fixtures the suites are run with
+type ConformanceService struct {
+	resolver *ResolverService
+	dids     *DIDService
+	// fixtureDIDs are the resolvable DIDs and deactivatedDIDs the revoked DIDs the
+	// fixtures cover; they must be public
+	fixtureDIDs     []string
+	deactivatedDIDs []string
+	implementer     string
+}
+
+// NewConformanceService creates a conformance service producing fixtures for the given
+// DIDs, attributed to implementer
+func NewConformanceService(resolver *ResolverService, dids *DIDService, fixtureDIDs, deactivatedDIDs []string, implementer string) *ConformanceService {
+	return &ConformanceService{
+		resolver:        resolver,
+		dids:            dids,
+		fixtureDIDs:     fixtureDIDs,
+		deactivatedDIDs: deactivatedDIDs,
+		implementer:     implementer,
+	}
+}
+
+// Resolve resolves a DID for a caller. It never fails: errors are reported as DID
+// Resolution error codes in the resolution metadata, as the suites expect.
+func (s *ConformanceService) Resolve(didString string, caller *domain.Caller) *domain.DIDResolutionResult {
+	if !did.ValidSyntax(didString) {
+		return resolutionError(domain.ResolutionErrorInvalidDID)
+	}
+
+	result, err := s.resolver.Resolve(didString, caller)
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		return resolutionError(domain.ResolutionErrorNotFound)
+	}
+	if err != nil {
+		log.Printf("Failed to resolve %s for conformance: %v", didString, err)
+		return resolutionError(domain.ResolutionErrorInternal)
+	}
+	return result
+}
+
+// resolutionError is a failed resolution result
+func resolutionError(code string) *domain.DIDResolutionResult {
+	return &domain.DIDResolutionResult{ResolutionMetadata: domain.DIDResolutionMetadata{Error: code}}
+}
+
+// Register creates an anonymous DID through the DIF Universal Registrar interface. The
+// DID is anchored asynchronously; its registration status is reported in the metadata.
+func (s *ConformanceService) Register(method string, req *domain.RegistrarCreateRequest, caller *domain.Caller) (*domain.RegistrarResult, error) {
+	response, err := s.dids.CreateDID(&domain.DIDCreateRequest{
+		Anonymous:    true,
+		PublicKey:    req.Options.PublicKeyHex,
+		KeyAlgorithm: req.Options.KeyAlgorithm,
+		Method:       domain.DIDMethod(method),
+		Tenant:       caller.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.RegistrarResult{
+		DIDState: domain.RegistrarDIDState{
+			State: domain.RegistrarStateFinished,
+			DID:   response.DID.Did,
+		},
+		DIDRegistrationMetadata: map[string]any{"status": response.Status},
+	}
+	if resolution := s.Resolve(response.DID.Did, caller); resolution.Document != nil {
+		result.DIDState.DIDDocument = resolution.Document
+		result.DIDDocumentMetadata = resolution.DocumentMetadata
+	}
+	return result, nil
+}
+
+// Deactivate deactivates a DID through the DIF Universal Registrar interface. Only the
+// DID itself, authenticated by a signed request, can deactivate it.
+func (s *ConformanceService) Deactivate(didString string, caller *domain.Caller) (*domain.RegistrarResult, error) {
+	if caller.Type != domain.CallerTypeDID {
+		return nil, fmt.Errorf("%w: deactivation must be signed by the DID", domain.ErrForbidden)
+	}
+	response, err := s.dids.DeactivateDID(didString, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RegistrarResult{
+		DIDState: domain.RegistrarDIDState{
+			State: domain.RegistrarStateFinished,
+			DID:   response.DID,
+		},
+		DIDRegistrationMetadata: map[string]any{"status": response.Status, "jobId": response.JobID},
+	}, nil
+}
+
+// Represent produces a DID Document in one of the conformance content types. The
+// application/did+json representation leaves out the JSON-LD @context.
+func Represent(document *did.Document, contentType string) ([]byte, error) {
+	switch contentType {
+	case domain.ContentTypeDIDLDJSON:
+		return json.Marshal(document)
+	case domain.ContentTypeDIDJSON:
+		properties, err := documentProperties(document)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(properties)
+	default:
+		return nil, fmt.Errorf("unsupported DID Document representation %s", contentType)
+	}
+}
+
+// documentProperties returns the properties of the data model of a DID Document, which
+// excludes the representation-specific @context
+func documentProperties(document *did.Document) (map[string]any, error) {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DID Document: %w", err)
+	}
+	var properties map[string]any
+	if err := json.Unmarshal(encoded, &properties); err != nil {
+		return nil, fmt.Errorf("failed to decode DID Document: %w", err)
+	}
+	delete(properties, "@context")
+	return properties, nil
+}
+
+// DIDCoreFixture builds the implementation file of the W3C DID Core test suite for a
+// method, covering the configured DIDs of that method
+func (s *ConformanceService) DIDCoreFixture(method string) (map[string]any, error) {
+	fixture := map[string]any{
+		"didMethod":             "did:" + method,
+		"implementation":        "DID Manager",
+		"implementer":           s.implementer,
+		"supportedContentTypes": conformanceContentTypes,
+		"didParameters":         map[string]any{},
+	}
+
+	dids := []string{}
+	for _, didString := range fixtureDIDsOf(s.fixtureDIDs, method) {
+		result := s.Resolve(didString, domain.AnonymousCaller)
+		if result.Document == nil {
+			return nil, fmt.Errorf("fixture DID %s did not resolve: %s", didString, result.ResolutionMetadata.Error)
+		}
+
+		properties, err := documentProperties(result.Document)
+		if err != nil {
+			return nil, err
+		}
+		entry := map[string]any{
+			"didDocumentDataModel": map[string]any{"properties": properties},
+		}
+		for _, contentType := range conformanceContentTypes {
+			representation, err := Represent(result.Document, contentType)
+			if err != nil {
+				return nil, err
+			}
+			specific := map[string]any{}
+			if contentType == domain.ContentTypeDIDLDJSON {
+				specific["@context"] = result.Document.Context
+			}
+			entry[contentType] = map[string]any{
+				"didDocumentDataModel":  map[string]any{"representationSpecificEntries": specific},
+				"representation":        string(representation),
+				"didDocumentMetadata":   result.DocumentMetadata,
+				"didResolutionMetadata": domain.DIDResolutionMetadata{ContentType: contentType},
+			}
+		}
+		fixture[didString] = entry
+		dids = append(dids, didString)
+	}
+	fixture["dids"] = dids
+	return fixture, nil
+}
+
+// ResolutionFixture builds the implementation file of the W3C DID Resolution test suite
+// for a method: resolve and resolveRepresentation executions of the configured DIDs, of
+// a malformed and an unknown DID, and of the deactivated DIDs, indexed by outcome
+func (s *ConformanceService) ResolutionFixture(method string) (map[string]any, error) {
+	var executions []map[string]any
+	outcomes := map[string][]int{}
+	add := func(outcome string, execution map[string]any) {
+		outcomes[outcome] = append(outcomes[outcome], len(executions))
+		executions = append(executions, execution)
+	}
+
+	cases := []struct {
+		outcome string
+		dids    []string
+	}{
+		{"defaultOutcome", fixtureDIDsOf(s.fixtureDIDs, method)},
+		{"invalidDidErrorOutcome", []string{"did:" + method + ":invalid:"}},
+		{"notFoundErrorOutcome", []string{"did:" + method + ":" + uuid.New().String()}},
+		{"deactivatedOutcome", fixtureDIDsOf(s.deactivatedDIDs, method)},
+	}
+	for _, c := range cases {
+		for _, didString := range c.dids {
+			result := s.Resolve(didString, domain.AnonymousCaller)
+			if result.ResolutionMetadata.Error == domain.ResolutionErrorInternal {
+				return nil, fmt.Errorf("fixture DID %s failed to resolve", didString)
+			}
+
+			add(c.outcome, map[string]any{
+				"function": "resolve",
+				"input":    map[string]any{"did": didString, "resolutionOptions": map[string]any{}},
+				"output": map[string]any{
+					"didResolutionMetadata": result.ResolutionMetadata,
+					"didDocument":           result.Document,
+					"didDocumentMetadata":   result.DocumentMetadata,
+				},
+			})
+
+			metadata := result.ResolutionMetadata
+			var stream string
+			if result.Document != nil {
+				representation, err := Represent(result.Document, domain.ContentTypeDIDJSON)
+				if err != nil {
+					return nil, err
+				}
+				stream = string(representation)
+				metadata.ContentType = domain.ContentTypeDIDJSON
+			}
+			add(c.outcome, map[string]any{
+				"function": "resolveRepresentation",
+				"input":    map[string]any{"did": didString, "resolutionOptions": map[string]any{"accept": domain.ContentTypeDIDJSON}},
+				"output": map[string]any{
+					"didResolutionMetadata": metadata,
+					"didDocumentStream":     stream,
+					"didDocumentMetadata":   result.DocumentMetadata,
+				},
+			})
+		}
+	}
+
+	return map[string]any{
+		"didMethod":        "did:" + method,
+		"implementation":   "DID Manager",
+		"implementer":      s.implementer,
+		"expectedOutcomes": outcomes,
+		"executions":       executions,
+	}, nil
+}
+
+// fixtureDIDsOf returns the DIDs of a method
+func fixtureDIDsOf(dids []string, method string) []string {
+	var matching []string
+	for _, didString := range dids {
+		if strings.HasPrefix(didString, "did:"+method+":") {
+			matching = append(matching, didString)
+		}
+	}
+	return matching
+}
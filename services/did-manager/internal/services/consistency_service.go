@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// consistencyPollInterval is how often Await checks whether the database caught up
+const consistencyPollInterval = 50 * time.Millisecond
+
+// ConsistencyService gives reads right after a write, such as verifying a DID just
+// created, read-your-writes consistency. The writer hands out a token with the
+// primary's WAL position after the write; a reader presented with the token bypasses
+// its read cache for the DID and waits, up to maxWait, until its database has applied
+// the write.
+type ConsistencyService struct {
+	repo    domain.ConsistencyRepository
+	didRepo domain.DIDRepository
+	maxWait time.Duration
+}
+
+// NewConsistencyService creates a new consistency service waiting at most maxWait for
+// the database to catch up. didRepo is the possibly cached repository reads go through.
+func NewConsistencyService(repo domain.ConsistencyRepository, didRepo domain.DIDRepository, maxWait time.Duration) *ConsistencyService {
+	return &ConsistencyService{
+		repo:    repo,
+		didRepo: didRepo,
+		maxWait: maxWait,
+	}
+}
+
+// Token returns the consistency token of a write to a DID just committed
+func (s *ConsistencyService) Token(didString string) (string, error) {
+	lsn, err := s.repo.CurrentLSN()
+	if err != nil {
+		return "", err
+	}
+
+	return (&domain.ConsistencyToken{DID: didString, LSN: lsn}).Encode(), nil
+}
+
+// Await makes the reads of a request see the write a token was issued for. It drops
+// the token's DID from the read cache and waits until the database applied the write,
+// failing with ErrReplicaBehind when it has not within maxWait.
+func (s *ConsistencyService) Await(ctx context.Context, encoded string) error {
+	token, err := domain.ParseConsistencyToken(encoded)
+	if err != nil {
+		return err
+	}
+	forgetCachedDID(s.didRepo, token.DID)
+
+	ctx, cancel := context.WithTimeout(ctx, s.maxWait)
+	defer cancel()
+	ticker := time.NewTicker(consistencyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		replayed, err := s.repo.Replayed(token.LSN)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: WAL position %s not replayed within %s", domain.ErrReplicaBehind, token.LSN, s.maxWait)
+		case <-ticker.C:
+		}
+	}
+}
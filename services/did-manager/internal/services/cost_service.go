@@ -0,0 +1,380 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/internal/security"
+
+	"github.com/google/uuid"
+)
+
+// costTotalsTTL is how long the units an API key or tenant drew this month are cached
+// before being read again
+const costTotalsTTL = 30 * time.Second
+
+// chainWriteRoutes are the routes that submit transactions, by "METHOD path"
+var chainWriteRoutes = map[string]bool{
+	"POST /api/v1/did":                                   true,
+	"POST /api/v1/did/deactivate":                        true,
+	"POST /1.0/create":                                   true,
+	"POST /1.0/deactivate":                               true,
+	"POST /api/v1/notarize":                              true,
+	"POST /api/v1/organizations/:did/operations":         true,
+	"POST /api/v1/operations/:id/approve":                true,
+	"POST /api/v1/smart-accounts/:did":                   true,
+	"POST /api/v1/smart-accounts/:did/operations":        true,
+	"POST /api/v1/wallet/custody-transfers/:id/complete": true,
+}
+
+// costTotals caches the units drawn this month by an API key or tenant, with its budget
+type costTotals struct {
+	month    time.Time
+	units    int
+	budget   *domain.CostBudget
+	loadedAt time.Time
+}
+
+// CostService accounts for the cost of the requests API keys make. Operations are
+// classified as chain writes, reads or DB-only writes, each drawing cost units from
+// the caller's monthly budgets: a sub-key's own budget and its tenant's, which covers
+// every sub-key. Chain writes are also throttled per tenant. Usage is accumulated in
+// memory and flushed periodically, so budgets may be overrun by what was admitted
+// between flushes across instances. Only production API key callers are metered.
+type CostService struct {
+	repo        domain.CostRepository
+	chainWrites *security.RateLimiter
+	chainLimit  int
+
+	mu      sync.Mutex
+	pending map[costUsageKey]*domain.CostUsage
+	// pendingUnits sums pending units by totals key, so admission sees them before
+	// they are flushed
+	pendingUnits map[string]int
+	totals       map[string]*costTotals
+}
+
+// costUsageKey identifies a daily usage row
+type costUsageKey struct {
+	apiKeyID uuid.UUID
+	class    domain.CostClass
+	day      time.Time
+}
+
+// NewCostService creates a new cost service
+func NewCostService(repo domain.CostRepository) *CostService {
+	return &CostService{
+		repo:         repo,
+		pending:      make(map[costUsageKey]*domain.CostUsage),
+		pendingUnits: make(map[string]int),
+		totals:       make(map[string]*costTotals),
+	}
+}
+
+// SetChainWriteLimit throttles each tenant to perMinute chain writes, with the given
+// burst. Chain writes are not throttled when perMinute is not positive.
+func (s *CostService) SetChainWriteLimit(perMinute, burst int) {
+	if perMinute <= 0 {
+		s.chainWrites = nil
+		return
+	}
+	s.chainWrites = security.NewRateLimiter(perMinute, burst)
+	s.chainLimit = perMinute
+}
+
+// Classify returns the cost class of the route registered as fullPath
+func (s *CostService) Classify(method, fullPath string) domain.CostClass {
+	if chainWriteRoutes[method+" "+fullPath] {
+		return domain.CostChainWrite
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return domain.CostRead
+	}
+	return domain.CostDBOnly
+}
+
+// Admit checks whether the caller may make a request of the given class. It returns
+// ErrBudgetExceeded when the request would overrun the sub-key's or tenant's monthly
+// budget, and ErrChainWriteThrottled when the tenant writes to the chain too fast,
+// along with the quota that was hit.
+func (s *CostService) Admit(caller *domain.Caller, class domain.CostClass) (*domain.QuotaInfo, error) {
+	if !metered(caller) {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	cost := domain.CostUnits[class]
+
+	if caller.KeyID != "" {
+		quota, err := s.checkBudget(subKeyTotalsKey(caller.KeyID), caller.KeyID, domain.QuotaScopeSubKey, class, now)
+		if err != nil || quota != nil {
+			return quota, err
+		}
+	}
+	quota, err := s.checkBudget(tenantTotalsKey(caller.ID), caller.ID, domain.QuotaScopeTenant, class, now)
+	if err != nil || quota != nil {
+		return quota, err
+	}
+
+	if class == domain.CostChainWrite && s.chainWrites != nil && !s.chainWrites.Allow(caller.ID) {
+		retryAfter := int(math.Ceil(s.chainWrites.RetryAfter().Seconds()))
+		return &domain.QuotaInfo{
+			Scope:      domain.QuotaScopeTenant,
+			APIKeyID:   caller.ID,
+			CostClass:  class,
+			Limit:      s.chainLimit,
+			Cost:       cost,
+			ResetsAt:   now.Add(time.Duration(retryAfter) * time.Second),
+			RetryAfter: retryAfter,
+		}, domain.ErrChainWriteThrottled
+	}
+
+	return nil, nil
+}
+
+// checkBudget returns the quota a request would overrun, wrapped in ErrBudgetExceeded,
+// or nil when it fits the budget of the API key or tenant
+func (s *CostService) checkBudget(totalsKey, apiKeyID, scope string, class domain.CostClass, now time.Time) (*domain.QuotaInfo, error) {
+	totals, err := s.loadTotals(totalsKey, apiKeyID, now)
+	if err != nil {
+		// Cost accounting never blocks requests
+		log.Printf("Warning: failed to load cost totals for %s: %v", apiKeyID, err)
+		return nil, nil
+	}
+	if totals.budget == nil {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	used := totals.units + s.pendingUnits[totalsKey]
+	s.mu.Unlock()
+
+	cost := domain.CostUnits[class]
+	limit := totals.budget.MonthlyUnits
+	if used+cost <= limit {
+		return nil, nil
+	}
+
+	return &domain.QuotaInfo{
+		Scope:     scope,
+		APIKeyID:  apiKeyID,
+		CostClass: class,
+		Limit:     limit,
+		Used:      used,
+		Remaining: max(limit-used, 0),
+		Cost:      cost,
+		ResetsAt:  monthStart(now).AddDate(0, 1, 0),
+	}, fmt.Errorf("%w: %s %s has %d of %d units left this month", domain.ErrBudgetExceeded, scope, apiKeyID, max(limit-used, 0), limit)
+}
+
+// loadTotals returns the cached units drawn this month and the budget, reading them
+// again once stale
+func (s *CostService) loadTotals(totalsKey, apiKeyID string, now time.Time) (*costTotals, error) {
+	month := monthStart(now)
+
+	s.mu.Lock()
+	cached, ok := s.totals[totalsKey]
+	s.mu.Unlock()
+	if ok && cached.month.Equal(month) && now.Sub(cached.loadedAt) < costTotalsTTL {
+		return cached, nil
+	}
+
+	id, err := uuid.Parse(apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key ID: %w", err)
+	}
+
+	totals := &costTotals{month: month, loadedAt: now}
+	if totalsKey == tenantTotalsKey(apiKeyID) {
+		totals.units, err = s.repo.TenantUnits(apiKeyID, month)
+	} else {
+		totals.units, err = s.repo.Units(id, month)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if totals.budget, err = s.repo.GetBudget(id); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.totals[totalsKey] = totals
+	s.mu.Unlock()
+	return totals, nil
+}
+
+// Record accounts for a request of the given class that succeeded
+func (s *CostService) Record(caller *domain.Caller, class domain.CostClass) {
+	if !metered(caller) {
+		return
+	}
+
+	keyID := caller.ID
+	if caller.KeyID != "" {
+		keyID = caller.KeyID
+	}
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return
+	}
+
+	units := domain.CostUnits[class]
+	key := costUsageKey{apiKeyID: id, class: class, day: time.Now().UTC().Truncate(24 * time.Hour)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage, ok := s.pending[key]
+	if !ok {
+		usage = &domain.CostUsage{APIKeyID: id, Tenant: caller.ID, Class: class, Day: key.day}
+		s.pending[key] = usage
+	}
+	usage.Requests++
+	usage.Units += units
+
+	s.pendingUnits[tenantTotalsKey(caller.ID)] += units
+	if caller.KeyID != "" {
+		s.pendingUnits[subKeyTotalsKey(caller.KeyID)] += units
+	}
+}
+
+// Flush writes the usage accumulated since the last flush. Usage that fails to be
+// written is kept for the next flush.
+func (s *CostService) Flush() error {
+	s.mu.Lock()
+	pending, pendingUnits := s.pending, s.pendingUnits
+	s.pending = make(map[costUsageKey]*domain.CostUsage)
+	s.pendingUnits = make(map[string]int)
+	s.mu.Unlock()
+
+	usage := make([]*domain.CostUsage, 0, len(pending))
+	for _, u := range pending {
+		usage = append(usage, u)
+	}
+
+	if err := s.repo.AddUsage(usage); err != nil {
+		s.mu.Lock()
+		for key, u := range pending {
+			if current, ok := s.pending[key]; ok {
+				current.Requests += u.Requests
+				current.Units += u.Units
+			} else {
+				s.pending[key] = u
+			}
+		}
+		for key, units := range pendingUnits {
+			s.pendingUnits[key] += units
+		}
+		s.mu.Unlock()
+		return err
+	}
+
+	// The flushed units are now in the stored totals, which are read again
+	s.mu.Lock()
+	for key := range pendingUnits {
+		delete(s.totals, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// SetBudget sets the monthly budget of an API key, in cost units
+func (s *CostService) SetBudget(apiKeyID uuid.UUID, monthlyUnits int) (*domain.CostBudget, error) {
+	budget := &domain.CostBudget{
+		APIKeyID:     apiKeyID,
+		MonthlyUnits: monthlyUnits,
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.repo.UpsertBudget(budget); err != nil {
+		return nil, err
+	}
+
+	s.forget(apiKeyID)
+	log.Printf("AUDIT: set cost budget of API key %s to %d units per month", apiKeyID, monthlyUnits)
+	return budget, nil
+}
+
+// DeleteBudget removes the budget of an API key
+func (s *CostService) DeleteBudget(apiKeyID uuid.UUID) error {
+	if err := s.repo.DeleteBudget(apiKeyID); err != nil {
+		return err
+	}
+
+	s.forget(apiKeyID)
+	log.Printf("AUDIT: removed cost budget of API key %s", apiKeyID)
+	return nil
+}
+
+// forget drops the cached totals of an API key, whose budget changed
+func (s *CostService) forget(apiKeyID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.totals, tenantTotalsKey(apiKeyID.String()))
+	delete(s.totals, subKeyTotalsKey(apiKeyID.String()))
+}
+
+// Report summarizes the consumption of API keys over [from, to), optionally for one
+// tenant and its sub-keys. Usage not flushed yet is not included.
+func (s *CostService) Report(from, to time.Time, tenant string) (*domain.CostReport, error) {
+	usage, err := s.repo.Summarize(from, to, tenant)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := s.repo.ListBudgets()
+	if err != nil {
+		return nil, err
+	}
+
+	budgetByKey := make(map[uuid.UUID]int, len(budgets))
+	for _, budget := range budgets {
+		budgetByKey[budget.APIKeyID] = budget.MonthlyUnits
+	}
+
+	summaries := make(map[uuid.UUID]*domain.CostSummary)
+	for _, u := range usage {
+		summary, ok := summaries[u.APIKeyID]
+		if !ok {
+			summary = &domain.CostSummary{APIKeyID: u.APIKeyID.String(), Requests: make(map[domain.CostClass]int)}
+			if units, ok := budgetByKey[u.APIKeyID]; ok {
+				summary.MonthlyBudget = &units
+			}
+			summaries[u.APIKeyID] = summary
+		}
+		summary.Requests[u.Class] += u.Requests
+		summary.Units += u.Units
+	}
+
+	report := &domain.CostReport{From: from, To: to, Summaries: make([]*domain.CostSummary, 0, len(summaries))}
+	for _, summary := range summaries {
+		report.Summaries = append(report.Summaries, summary)
+	}
+	sort.Slice(report.Summaries, func(i, j int) bool {
+		return report.Summaries[i].APIKeyID < report.Summaries[j].APIKeyID
+	})
+	return report, nil
+}
+
+// MonthToDate summarizes a tenant's consumption this month
+func (s *CostService) MonthToDate(tenant string) (*domain.CostReport, error) {
+	now := time.Now().UTC()
+	return s.Report(monthStart(now), now.Truncate(24*time.Hour).AddDate(0, 0, 1), tenant)
+}
+
+// metered reports whether the caller's requests are accounted for: production API keys
+func metered(caller *domain.Caller) bool {
+	return caller != nil && caller.Type == domain.CallerTypeAPIKey && caller.SandboxTenant == ""
+}
+
+func tenantTotalsKey(tenant string) string {
+	return "tenant:" + tenant
+}
+
+func subKeyTotalsKey(apiKeyID string) string {
+	return "key:" + apiKeyID
+}
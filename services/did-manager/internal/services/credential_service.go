@@ -0,0 +1,925 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+	"did-manager/pkg/identity"
+	"did-manager/pkg/queue"
+	"did-manager/pkg/sidetree"
+
+	"github.com/google/uuid"
+)
+
+// CredentialService issues, stores and presents verifiable credentials for DIDs managed
+// by this service. Keys are custodial: issuer and holder proofs are signed with the
+// private keys stored alongside each DID.
+type CredentialService struct {
+	credentialRepo domain.CredentialRepository
+	didRepo        domain.DIDRepository
+	registry       *did.Registry
+	events         EventPublisher
+	// hooks run deployment-specific logic around issuance
+	hooks *Hooks
+	// reviews holds flagged credentials for manual review
+	reviews domain.ReviewRepository
+	// ion resolves did:ion issuers and holders of received credentials and
+	// presentations; nil when did:ion is not supported
+	ion *sidetree.Resolver
+	// documents holds the vault documents credentials reference; nil when the
+	// document vault is not configured
+	documents domain.DocumentRepository
+	// purposeKeys resolve the keys DIDs sign and verify with by purpose; nil uses each
+	// DID's own key for every purpose
+	purposeKeys *PurposeKeyService
+	// issuanceRules restricts what the issuers of each tenant may issue; nil allows
+	// every issuance
+	issuanceRules *IssuanceRuleService
+	// clock dates issuance and revocation and tells whether credentials and delegations
+	// have expired
+	clock clock.Clock
+}
+
+// NewCredentialService creates a new credential service; events may be nil when no
+// event stream is available
+func NewCredentialService(credentialRepo domain.CredentialRepository, didRepo domain.DIDRepository, registry *did.Registry, events EventPublisher) *CredentialService {
+	return &CredentialService{
+		credentialRepo: credentialRepo,
+		didRepo:        didRepo,
+		registry:       registry,
+		events:         events,
+		clock:          clock.System,
+	}
+}
+
+// SetClock sets the time source of issuance and expiry checks, clock.System by default
+func (s *CredentialService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetHooks installs the plugin hooks run around credential issuance
+func (s *CredentialService) SetHooks(hooks *Hooks) {
+	s.hooks = hooks
+}
+
+// SetIssuanceRules enforces the issuance rules of each tenant on every issuance
+func (s *CredentialService) SetIssuanceRules(rules *IssuanceRuleService) {
+	s.issuanceRules = rules
+}
+
+// SetReviewQueue installs the queue holding flagged credentials for manual review
+func (s *CredentialService) SetReviewQueue(reviews domain.ReviewRepository) {
+	s.reviews = reviews
+}
+
+// SetPurposeKeys signs and verifies with the purpose keys of DIDs that have them
+func (s *CredentialService) SetPurposeKeys(keys *PurposeKeyService) {
+	s.purposeKeys = keys
+}
+
+// SetIONResolver enables verifying credentials and presentations signed by did:ion
+// DIDs, resolved through resolver
+func (s *CredentialService) SetIONResolver(resolver *sidetree.Resolver) {
+	s.ion = resolver
+}
+
+// SetDocuments enables attaching documents from the vault to issued credentials
+func (s *CredentialService) SetDocuments(documents domain.DocumentRepository) {
+	s.documents = documents
+}
+
+// IssueCredential issues a credential from issuerDID to the requested subject
+func (s *CredentialService) IssueCredential(issuerDID string, req *domain.CredentialIssueRequest) (*domain.Credential, error) {
+	issuer, err := s.didRepo.GetByDID(issuerDID)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.ApprovalThreshold > 0 {
+		return nil, fmt.Errorf("%w: organization DIDs issue credentials once their controllers approve", domain.ErrForbidden)
+	}
+
+	return s.issue(issuer, req)
+}
+
+// IssueApprovedCredential issues a credential from an organization DID whose
+// controllers approved the request
+func (s *CredentialService) IssueApprovedCredential(issuer *domain.DID, req *domain.CredentialIssueRequest) (*domain.Credential, error) {
+	return s.issue(issuer, req)
+}
+
+// issue signs and stores a credential from issuer to the requested subject
+func (s *CredentialService) issue(issuer *domain.DID, req *domain.CredentialIssueRequest) (*domain.Credential, error) {
+	resources, err := s.attachmentResources(issuer.Did, req.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.issueDocument(issuer, req, func(id uuid.UUID, now time.Time) (json.RawMessage, error) {
+		credential := identity.NewCredential(identity.CredentialRequest{
+			ID:        "urn:uuid:" + id.String(),
+			Issuer:    issuer.Did,
+			Subject:   req.SubjectDID,
+			Type:      req.Type,
+			Claims:    req.Claims,
+			IssuedAt:  now,
+			ExpiresAt: req.ExpiresAt,
+		})
+
+		if req.OnBehalfOf != "" {
+			chain, err := s.loadDelegationChain(req.DelegationIDs)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.verifyDelegationChain(chain, req.OnBehalfOf, issuer.Did, did.CapabilityIssueCredential); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrForbidden, err)
+			}
+			credential.OnBehalfOf = req.OnBehalfOf
+			credential.Delegation = chain
+		}
+		credential.RelatedResource = resources
+
+		suite, privateKey, method, err := s.signingKey(issuer, did.PurposeAssertionMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := did.SignCredential(suite, privateKey, credential, did.ProofOptions{
+			VerificationMethod: method,
+			ProofPurpose:       did.ProofPurposeAssertionMethod,
+			Created:            now,
+		}); err != nil {
+			return nil, err
+		}
+
+		document, err := json.Marshal(credential)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode credential: %w", err)
+		}
+		return document, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Attachments) > 0 {
+		if err := s.documents.Attach(record.ID, req.Attachments); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// attachmentResources references the documents attached to a new credential by
+// digest. Only documents the issuer uploaded can be attached.
+func (s *CredentialService) attachmentResources(issuerDID string, ids []uuid.UUID) ([]did.RelatedResource, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.documents == nil {
+		return nil, domain.ErrDocumentVaultDisabled
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	resources := make([]did.RelatedResource, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: document %s is attached twice", domain.ErrInvalidDocument, id)
+		}
+		seen[id] = true
+
+		document, err := s.documents.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if document.OwnerDID != issuerDID {
+			// Do not reveal documents uploaded by others
+			return nil, domain.ErrDocumentNotFound
+		}
+		digest, err := hex.DecodeString(document.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("malformed digest of document %s: %w", id, err)
+		}
+
+		resources = append(resources, did.RelatedResource{
+			ID:        "urn:uuid:" + id.String(),
+			MediaType: document.ContentType,
+			DigestSRI: "sha256-" + base64.StdEncoding.EncodeToString(digest),
+		})
+	}
+	return resources, nil
+}
+
+// issueDocument stores a credential from issuer to the requested subject whose signed
+// document is produced by sign. Credentials flagged by hooks are stored pending review
+// and do not verify until approved.
+func (s *CredentialService) issueDocument(issuer *domain.DID, req *domain.CredentialIssueRequest, sign func(id uuid.UUID, now time.Time) (json.RawMessage, error)) (*domain.Credential, error) {
+	if issuer.Status != string(domain.DIDStatusActive) && issuer.Status != string(domain.DIDStatusPending) {
+		return nil, fmt.Errorf("%w: issuer DID is %s", domain.ErrForbidden, issuer.Status)
+	}
+	if s.issuanceRules != nil {
+		if err := s.issuanceRules.Authorize(issuer, req); err != nil {
+			return nil, err
+		}
+	}
+	reviewReasons, err := s.hooks.BeforeIssueCredential(issuer, req)
+	if err != nil {
+		return nil, err
+	}
+	held := len(reviewReasons) > 0
+	if held && s.reviews == nil {
+		return nil, errNoReviewQueue
+	}
+
+	now := s.clock.Now()
+	id := uuid.New()
+
+	document, err := sign(id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.CredentialStatusActive
+	if held {
+		status = domain.CredentialStatusPendingReview
+	}
+
+	record := &domain.Credential{
+		ID:         id,
+		IssuerDID:  issuer.Did,
+		SubjectDID: req.SubjectDID,
+		Type:       req.Type,
+		Status:     string(status),
+		IssuedAt:   now,
+		ExpiresAt:  req.ExpiresAt,
+		Tenant:     req.Tenant,
+		Document:   document,
+	}
+
+	if err := s.credentialRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	if held {
+		if err := queueReview(s.reviews, domain.ReviewOperationIssueCredential, record.ID, record.SubjectDID, reviewReasons); err != nil {
+			return nil, fmt.Errorf("failed to queue credential for review: %w", err)
+		}
+	} else {
+		s.publishOffered(record)
+	}
+	s.hooks.AfterIssueCredential(issuer, record)
+
+	return record, nil
+}
+
+// releaseReviewedCredential activates an approved credential held for review and offers
+// it to the holder, or marks it rejected
+func (s *CredentialService) releaseReviewedCredential(id uuid.UUID, approved bool) error {
+	status := domain.CredentialStatusRejected
+	if approved {
+		status = domain.CredentialStatusActive
+	}
+	if err := s.credentialRepo.ResolveReview(id, status); err != nil {
+		return err
+	}
+
+	if approved {
+		record, err := s.credentialRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		s.publishOffered(record)
+	}
+	return nil
+}
+
+// publishOffered tells the holder's wallet about a new credential
+func (s *CredentialService) publishOffered(record *domain.Credential) {
+	s.publish(queue.EventCredentialOffered, record.SubjectDID, map[string]string{
+		"credential_id": record.ID.String(),
+		"issuer":        record.IssuerDID,
+		"type":          record.Type,
+	})
+}
+
+// RenewCredential reissues a credential to the same subject with the original type,
+// claims and tenant, valid until expiresAt. Credentials of organization DIDs are not
+// renewed, as they are only issued with their controllers' approval.
+func (s *CredentialService) RenewCredential(record *domain.Credential, expiresAt time.Time) (*domain.Credential, error) {
+	if record.Type == did.TypeAgeOverCredential {
+		return nil, fmt.Errorf("%w: age credentials are not renewed automatically", domain.ErrForbidden)
+	}
+	issuer, err := s.didRepo.GetByDID(record.IssuerDID)
+	if err != nil {
+		return nil, err
+	}
+	// Renewing would sign a credential its controllers never approved
+	if issuer.ApprovalThreshold > 0 {
+		return nil, fmt.Errorf("%w: organization credentials are renewed through an issue_credential operation", domain.ErrForbidden)
+	}
+
+	original, err := decodeCredential(record)
+	if err != nil {
+		return nil, err
+	}
+	if original.OnBehalfOf != "" {
+		return nil, fmt.Errorf("%w: delegated credentials are not renewed automatically", domain.ErrForbidden)
+	}
+
+	claims := make(map[string]any, len(original.CredentialSubject))
+	for k, v := range original.CredentialSubject {
+		if k != "id" {
+			claims[k] = v
+		}
+	}
+
+	// The replacement references the same documents
+	var attachments []uuid.UUID
+	for _, resource := range original.RelatedResource {
+		if id, err := uuid.Parse(strings.TrimPrefix(resource.ID, "urn:uuid:")); err == nil {
+			attachments = append(attachments, id)
+		}
+	}
+
+	return s.issue(issuer, &domain.CredentialIssueRequest{
+		SubjectDID:  record.SubjectDID,
+		Type:        record.Type,
+		Claims:      claims,
+		ExpiresAt:   &expiresAt,
+		Tenant:      record.Tenant,
+		Attachments: attachments,
+	})
+}
+
+// RevokeCredential revokes a credential issued by issuerDID, the DID-authenticated
+// issuer, or any credential when issuerDID is empty, for administrators. The revocation
+// is reflected in the status API immediately and in the on-chain accumulator with the
+// next batch.
+func (s *CredentialService) RevokeCredential(issuerDID string, id uuid.UUID) (*domain.Credential, error) {
+	record, err := s.credentialRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if issuerDID != "" && record.IssuerDID != issuerDID {
+		// Do not reveal credentials issued by others
+		return nil, domain.ErrCredentialNotFound
+	}
+	if record.Status == string(domain.CredentialStatusRevoked) {
+		return record, nil
+	}
+
+	now := s.clock.Now()
+	if err := s.credentialRepo.Revoke(id, now); err != nil {
+		return nil, err
+	}
+	record.Status = string(domain.CredentialStatusRevoked)
+	record.RevokedAt = &now
+
+	s.publish(queue.EventCredentialRevoked, record.SubjectDID, map[string]string{
+		"credential_id": record.ID.String(),
+		"issuer":        record.IssuerDID,
+		"type":          record.Type,
+	})
+
+	return record, nil
+}
+
+// publish emits a domain event; failures are logged rather than failing the operation
+func (s *CredentialService) publish(eventType, subject string, data map[string]string) {
+	if s.events == nil {
+		return
+	}
+
+	event := &queue.Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Subject:    subject,
+		Data:       data,
+		OccurredAt: s.clock.Now(),
+	}
+	if err := s.events.PublishEvent(event); err != nil {
+		log.Printf("Warning: failed to publish %s event: %v", eventType, err)
+	}
+}
+
+// VerifyCredential checks a credential's proof against its issuer's key and its stored status
+func (s *CredentialService) VerifyCredential(document json.RawMessage) (*domain.CredentialVerifyResponse, error) {
+	var credential did.Credential
+	if err := json.Unmarshal(document, &credential); err != nil {
+		return &domain.CredentialVerifyResponse{Valid: false, Message: "Malformed credential"}, nil
+	}
+
+	var publicKey []byte
+	if s.ion != nil && sidetree.IsION(credential.Issuer) {
+		key, err := s.ionKey(credential.Issuer, credential.Proof, did.ProofPurposeAssertionMethod)
+		if err != nil {
+			return &domain.CredentialVerifyResponse{Valid: false, Message: err.Error()}, nil
+		}
+		publicKey = key
+	} else {
+		issuer, err := s.didRepo.GetByDID(credential.Issuer)
+		if err != nil {
+			if errors.Is(err, domain.ErrDIDNotFound) {
+				return &domain.CredentialVerifyResponse{Valid: false, Message: "Unknown issuer"}, nil
+			}
+			return nil, err
+		}
+
+		var method string
+		if _, publicKey, method, err = s.verificationKey(issuer, did.PurposeAssertionMethod); err != nil {
+			return nil, err
+		}
+		if credential.Proof != nil && credential.Proof.VerificationMethod != method {
+			return &domain.CredentialVerifyResponse{Valid: false, Message: "Credential is not signed with an assertionMethod key of its issuer"}, nil
+		}
+	}
+
+	if err := did.VerifyCredential(s.registry, publicKey, &credential); err != nil {
+		return &domain.CredentialVerifyResponse{Valid: false, Message: err.Error()}, nil
+	}
+
+	if credential.ExpirationDate != "" {
+		if expires, err := time.Parse(time.RFC3339, credential.ExpirationDate); err == nil && s.clock.Now().After(expires) {
+			return &domain.CredentialVerifyResponse{Valid: false, Status: "expired", Message: "Credential has expired"}, nil
+		}
+	}
+
+	if credential.OnBehalfOf != "" {
+		if err := s.verifyDelegationChain(credential.Delegation, credential.OnBehalfOf, credential.Issuer, did.CapabilityIssueCredential); err != nil {
+			return &domain.CredentialVerifyResponse{Valid: false, Message: err.Error()}, nil
+		}
+	}
+
+	status, reason := s.storedStatus(credential.ID)
+	if reason != "" {
+		return &domain.CredentialVerifyResponse{Valid: false, Status: status, Message: reason}, nil
+	}
+
+	return &domain.CredentialVerifyResponse{Valid: true, Status: status, Message: "Credential is valid", CredentialID: credential.ID}, nil
+}
+
+// storedStatus looks up the status of a credential by its document ID, together with
+// the reason it does not verify unless it is active. Credentials not stored here
+// count as active.
+func (s *CredentialService) storedStatus(credentialID string) (string, string) {
+	status := string(domain.CredentialStatusActive)
+	if id, err := uuid.Parse(strings.TrimPrefix(credentialID, "urn:uuid:")); err == nil {
+		if record, err := s.credentialRepo.GetByID(id); err == nil {
+			status = record.Status
+		}
+	}
+	switch status {
+	case string(domain.CredentialStatusRevoked):
+		return status, "Credential has been revoked"
+	case string(domain.CredentialStatusPendingReview):
+		return status, "Credential is pending review"
+	case string(domain.CredentialStatusRejected):
+		return status, "Credential was rejected in review"
+	}
+	return status, ""
+}
+
+// IssueDelegation issues a delegation credential from issuerDID to the requested
+// delegate. Re-delegating a parent delegation can only pass on a subset of the
+// capabilities the issuer holds through it.
+func (s *CredentialService) IssueDelegation(issuerDID string, req *domain.DelegationIssueRequest) (*domain.Credential, error) {
+	parentID := ""
+	if req.ParentID != nil {
+		parent, err := s.credentialRepo.GetByID(*req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.SubjectDID != issuerDID {
+			// Do not reveal delegations held by others
+			return nil, domain.ErrCredentialNotFound
+		}
+		if parent.Type != did.TypeDelegationCredential || parent.Status != string(domain.CredentialStatusActive) {
+			return nil, fmt.Errorf("%w: parent is not an active delegation", domain.ErrForbidden)
+		}
+
+		chain, err := s.delegationAncestry(parent)
+		if err != nil {
+			return nil, err
+		}
+		if len(chain) >= did.MaxDelegationDepth {
+			return nil, fmt.Errorf("%w: delegation chain is at its maximum depth", domain.ErrForbidden)
+		}
+
+		granted := did.DelegationCapabilities(chain[len(chain)-1])
+		for _, capability := range req.Capabilities {
+			if !containsString(granted, capability) {
+				return nil, fmt.Errorf("%w: capability %s is not held through the parent delegation", domain.ErrForbidden, capability)
+			}
+		}
+		parentID = chain[len(chain)-1].ID
+	}
+
+	return s.IssueCredential(issuerDID, &domain.CredentialIssueRequest{
+		SubjectDID: req.DelegateDID,
+		Type:       did.TypeDelegationCredential,
+		Claims:     did.DelegationClaims(req.Capabilities, parentID),
+		ExpiresAt:  req.ExpiresAt,
+	})
+}
+
+// VerifyDelegation verifies every credential in a delegation chain and checks that the
+// chain authorizes the delegate to exercise the capability on behalf of the delegator
+func (s *CredentialService) VerifyDelegation(req *domain.DelegationVerifyRequest) (*domain.CredentialVerifyResponse, error) {
+	chain := make([]*did.Credential, 0, len(req.Chain))
+	for _, raw := range req.Chain {
+		var credential did.Credential
+		if err := json.Unmarshal(raw, &credential); err != nil {
+			return &domain.CredentialVerifyResponse{Valid: false, Message: "Malformed delegation credential"}, nil
+		}
+		chain = append(chain, &credential)
+	}
+
+	if err := s.verifyDelegationChain(chain, req.Delegator, req.Delegate, req.Capability); err != nil {
+		if errors.Is(err, did.ErrInvalidDelegation) {
+			return &domain.CredentialVerifyResponse{Valid: false, Message: err.Error()}, nil
+		}
+		return nil, err
+	}
+
+	return &domain.CredentialVerifyResponse{Valid: true, Status: string(domain.CredentialStatusActive), Message: "Delegation is valid"}, nil
+}
+
+// verifyDelegationChain verifies each delegation credential's proof and status, then
+// the structure of the chain
+func (s *CredentialService) verifyDelegationChain(chain []*did.Credential, delegator, delegate, capability string) error {
+	if len(chain) > did.MaxDelegationDepth {
+		return fmt.Errorf("%w: chain exceeds %d delegations", did.ErrInvalidDelegation, did.MaxDelegationDepth)
+	}
+
+	for i, link := range chain {
+		if link == nil {
+			return fmt.Errorf("%w: link %d is empty", did.ErrInvalidDelegation, i)
+		}
+		raw, err := json.Marshal(link)
+		if err != nil {
+			return fmt.Errorf("failed to encode delegation: %w", err)
+		}
+		result, err := s.VerifyCredential(raw)
+		if err != nil {
+			return err
+		}
+		if !result.Valid {
+			return fmt.Errorf("%w: link %d: %s", did.ErrInvalidDelegation, i, result.Message)
+		}
+	}
+
+	return did.ValidateDelegationChain(chain, delegator, delegate, capability, s.clock.Now())
+}
+
+// loadDelegationChain loads stored delegation credentials in the given order
+func (s *CredentialService) loadDelegationChain(ids []uuid.UUID) ([]*did.Credential, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: a delegation chain is required to act on behalf of another DID", domain.ErrForbidden)
+	}
+
+	chain := make([]*did.Credential, 0, len(ids))
+	for _, id := range ids {
+		record, err := s.credentialRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		credential, err := decodeCredential(record)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, credential)
+	}
+	return chain, nil
+}
+
+// delegationAncestry returns the chain of stored delegations ending at record, root first
+func (s *CredentialService) delegationAncestry(record *domain.Credential) ([]*did.Credential, error) {
+	var chain []*did.Credential
+	for {
+		credential, err := decodeCredential(record)
+		if err != nil {
+			return nil, err
+		}
+		chain = append([]*did.Credential{credential}, chain...)
+
+		parent := did.DelegationParent(credential)
+		if parent == "" {
+			return chain, nil
+		}
+		if len(chain) > did.MaxDelegationDepth {
+			return nil, fmt.Errorf("%w: chain exceeds %d delegations", did.ErrInvalidDelegation, did.MaxDelegationDepth)
+		}
+
+		id, err := uuid.Parse(strings.TrimPrefix(parent, "urn:uuid:"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed parent delegation %s", did.ErrInvalidDelegation, parent)
+		}
+		if record, err = s.credentialRepo.GetByID(id); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// VerifyPresentation checks a presentation's holder proof against the expected challenge
+// and domain, verifies every embedded credential, and checks that the credentials are
+// issued to the holder and cover requiredTypes
+func (s *CredentialService) VerifyPresentation(document json.RawMessage, challenge, domainName string, requiredTypes []string) (*domain.PresentationVerifyResponse, error) {
+	var presentation did.Presentation
+	if err := json.Unmarshal(document, &presentation); err != nil {
+		return &domain.PresentationVerifyResponse{Valid: false, Message: "Malformed presentation"}, nil
+	}
+
+	invalid := func(message string) (*domain.PresentationVerifyResponse, error) {
+		return &domain.PresentationVerifyResponse{Valid: false, Holder: presentation.Holder, Message: message}, nil
+	}
+
+	proof := presentation.Proof
+	if proof == nil {
+		return invalid("Presentation is not signed")
+	}
+	if proof.ProofPurpose != did.ProofPurposeAuthentication {
+		return invalid("Presentation proof purpose must be authentication")
+	}
+	if proof.Challenge != challenge || proof.Domain != domainName {
+		return invalid("Presentation challenge or domain does not match")
+	}
+	if !strings.HasPrefix(proof.VerificationMethod, presentation.Holder+"#") {
+		return invalid("Presentation is not signed by its holder")
+	}
+
+	var publicKey []byte
+	if s.ion != nil && sidetree.IsION(presentation.Holder) {
+		key, err := s.ionKey(presentation.Holder, proof, did.ProofPurposeAuthentication)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		publicKey = key
+	} else {
+		holder, err := s.didRepo.GetByDID(presentation.Holder)
+		if err != nil {
+			if errors.Is(err, domain.ErrDIDNotFound) {
+				return invalid("Unknown holder")
+			}
+			return nil, err
+		}
+		if holder.Status == string(domain.DIDStatusRevoked) || holder.Status == string(domain.DIDStatusExpired) {
+			return invalid("Holder DID is " + holder.Status)
+		}
+
+		var method string
+		if _, publicKey, method, err = s.verificationKey(holder, did.PurposeAuthentication); err != nil {
+			return nil, err
+		}
+		if presentation.Proof != nil && presentation.Proof.VerificationMethod != method {
+			return invalid("Presentation is not signed with an authentication key of its holder")
+		}
+	}
+	if err := did.VerifyPresentation(s.registry, publicKey, &presentation); err != nil {
+		return invalid(err.Error())
+	}
+
+	presented := make(map[string]bool)
+	var credentialIDs []string
+	for _, credential := range presentation.VerifiableCredential {
+		if credential == nil {
+			return invalid("Presentation contains an empty credential")
+		}
+		if subject, _ := credential.CredentialSubject["id"].(string); subject != presentation.Holder {
+			return invalid("Credential " + credential.ID + " is not issued to the holder")
+		}
+
+		raw, err := json.Marshal(credential)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode credential: %w", err)
+		}
+		result, err := s.VerifyCredential(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Valid {
+			return invalid("Credential " + credential.ID + ": " + result.Message)
+		}
+
+		for _, t := range credential.Type {
+			presented[t] = true
+		}
+		credentialIDs = append(credentialIDs, credential.ID)
+	}
+
+	for _, t := range requiredTypes {
+		if !presented[t] {
+			return invalid("Missing credential of type " + t)
+		}
+	}
+
+	return &domain.PresentationVerifyResponse{
+		Valid:         true,
+		Holder:        presentation.Holder,
+		Message:       "Presentation is valid",
+		CredentialIDs: credentialIDs,
+	}, nil
+}
+
+// SearchCredentials returns a page of the credentials issued by issuerDID that match
+// the request's metadata filters
+func (s *CredentialService) SearchCredentials(issuerDID string, req *domain.CredentialSearchRequest) (*domain.CredentialSearchResponse, error) {
+	page, limit := pageAndLimit(req.Page, req.Limit)
+
+	credentials, total, err := s.credentialRepo.Search(issuerDID, req, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		credentials = []*domain.Credential{}
+	}
+
+	return &domain.CredentialSearchResponse{
+		Credentials: credentials,
+		Total:       total,
+		Page:        page,
+		Limit:       limit,
+	}, nil
+}
+
+// ListCredentialsForUser lists the credentials issued to any DID owned by userID
+func (s *CredentialService) ListCredentialsForUser(userID uuid.UUID) ([]*domain.Credential, error) {
+	dids, err := s.didRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(dids) == 0 {
+		return []*domain.Credential{}, nil
+	}
+
+	subjects := make([]string, 0, len(dids))
+	for _, record := range dids {
+		subjects = append(subjects, record.Did)
+	}
+
+	credentials, err := s.credentialRepo.ListBySubjects(subjects)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		credentials = []*domain.Credential{}
+	}
+	return credentials, nil
+}
+
+// CreatePresentation creates a presentation of credentials held by one of userID's DIDs,
+// signed with the holder DID's key
+func (s *CredentialService) CreatePresentation(userID uuid.UUID, req *domain.PresentationCreateRequest) (*did.Presentation, error) {
+	holder, err := s.didRepo.GetByDID(req.HolderDID)
+	if err != nil {
+		return nil, err
+	}
+	if holder.UserID != userID {
+		// Do not reveal that the DID exists
+		return nil, domain.ErrDIDNotFound
+	}
+
+	presentation := &did.Presentation{
+		Context: []string{did.ContextCredentialsV1},
+		ID:      "urn:uuid:" + uuid.New().String(),
+		Type:    []string{did.TypeVerifiablePresentation},
+		Holder:  holder.Did,
+	}
+
+	for _, id := range req.CredentialIDs {
+		record, err := s.credentialRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if record.SubjectDID != holder.Did {
+			return nil, domain.ErrCredentialNotFound
+		}
+		if record.Status != string(domain.CredentialStatusActive) {
+			return nil, fmt.Errorf("%w: credential %s is %s", domain.ErrForbidden, id, record.Status)
+		}
+		if record.Type == did.TypeAgeOverCredential {
+			return nil, fmt.Errorf("%w: age credential %s is presented with an age proof", domain.ErrForbidden, id)
+		}
+
+		var credential did.Credential
+		if err := json.Unmarshal(record.Document, &credential); err != nil {
+			return nil, fmt.Errorf("failed to decode credential %s: %w", id, err)
+		}
+		presentation.VerifiableCredential = append(presentation.VerifiableCredential, &credential)
+	}
+
+	suite, privateKey, method, err := s.signingKey(holder, did.PurposeAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := did.SignPresentation(suite, privateKey, presentation, did.ProofOptions{
+		VerificationMethod: method,
+		ProofPurpose:       did.ProofPurposeAuthentication,
+		Challenge:          req.Challenge,
+		Domain:             req.Domain,
+		Created:            s.clock.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return presentation, nil
+}
+
+// decodeCredential decodes the signed document of a stored credential
+func decodeCredential(record *domain.Credential) (*did.Credential, error) {
+	var credential did.Credential
+	if err := json.Unmarshal(record.Document, &credential); err != nil {
+		return nil, fmt.Errorf("failed to decode credential %s: %w", record.ID, err)
+	}
+	return &credential, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// signingKey loads the signature suite and private key of a custodial DID's key for
+// purpose, together with the key's verification method
+func (s *CredentialService) signingKey(record *domain.DID, purpose string) (did.SignatureSuite, []byte, string, error) {
+	if !record.Custodial {
+		return nil, nil, "", fmt.Errorf("%w: %w", domain.ErrForbidden, domain.ErrNotCustodial)
+	}
+	return s.storedKey(record, purpose)
+}
+
+// storedKey loads the signature suite and key material stored for a DID's key for
+// purpose: the private key of custodial DIDs, the owner's public key otherwise
+func (s *CredentialService) storedKey(record *domain.DID, purpose string) (did.SignatureSuite, []byte, string, error) {
+	key, err := s.purposeKeys.key(record, purpose)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	suite, err := s.registry.SignatureSuite(key.keyAlgorithm)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return suite, key.material, key.method, nil
+}
+
+// ionKey resolves the did:ion key a proof was made with, checking the DID is not
+// deactivated and the key is authorized for proofPurpose
+func (s *CredentialService) ionKey(didString string, proof *did.Proof, proofPurpose string) ([]byte, error) {
+	if proof == nil {
+		return nil, fmt.Errorf("%w: missing proof", did.ErrInvalidProof)
+	}
+
+	resolution, err := s.ion.Resolve(context.Background(), didString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", didString, err)
+	}
+	if resolution.Metadata.Deactivated {
+		return nil, fmt.Errorf("%s is deactivated", didString)
+	}
+
+	method, ok := resolution.Document.Method(proof.VerificationMethod)
+	if !ok || !resolution.Document.Authorizes(proof.VerificationMethod, proofPurpose) {
+		return nil, fmt.Errorf("%w: %s is not an %s key of %s", did.ErrInvalidProof, proof.VerificationMethod, proofPurpose, didString)
+	}
+	suite, publicKey, err := method.JWKPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", did.ErrInvalidProof, err)
+	}
+	if proof.Suite != suite {
+		return nil, fmt.Errorf("%w: proof suite %s does not match the %s key", did.ErrInvalidProof, proof.Suite, suite)
+	}
+
+	return publicKey, nil
+}
+
+// verificationKey loads the signature suite and public key of a DID's key for
+// purpose, together with the key's verification method
+func (s *CredentialService) verificationKey(record *domain.DID, purpose string) (did.SignatureSuite, []byte, string, error) {
+	suite, keyMaterial, method, err := s.storedKey(record, purpose)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to load DID key: %w", err)
+	}
+
+	return suite, publicKey, method, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// fakeCredentials records the credentials created
+type fakeCredentials struct {
+	domain.CredentialRepository
+	created []*domain.Credential
+}
+
+func (f *fakeCredentials) Create(credential *domain.Credential) error {
+	f.created = append(f.created, credential)
+	return nil
+}
+
+// newIssuer returns an active custodial DID able to sign credentials, requiring
+// threshold controllers to approve its operations
+func newIssuer(t *testing.T, generator *did.Generator, threshold int) *domain.DID {
+	t.Helper()
+	generated, err := generator.GenerateDID(uuid.New(), "Example Org", "org@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+	return &domain.DID{
+		ID:                uuid.New(),
+		Did:               generated.DID,
+		PublicKey:         generated.PrivateKeyHex,
+		KeyAlgorithm:      generated.KeyAlgorithm,
+		Custodial:         true,
+		Status:            string(domain.DIDStatusActive),
+		ApprovalThreshold: threshold,
+	}
+}
+
+func TestRenewCredential(t *testing.T) {
+	generator := did.NewGenerator()
+	issuer := newIssuer(t, generator, 0)
+	credentials := &fakeCredentials{}
+	service := NewCredentialService(credentials, &fakeDIDs{records: map[string]*domain.DID{issuer.Did: issuer}}, generator.Registry(), nil)
+
+	original, err := service.IssueCredential(issuer.Did, &domain.CredentialIssueRequest{
+		SubjectDID: "did:example:holder",
+		Type:       "EmployeeCredential",
+		Claims:     map[string]any{"role": "engineer"},
+	})
+	if err != nil {
+		t.Fatalf("IssueCredential failed: %v", err)
+	}
+	renewed, err := service.RenewCredential(original, time.Now().Add(365*24*time.Hour))
+	if err != nil {
+		t.Fatalf("RenewCredential failed: %v", err)
+	}
+	if renewed.ID == original.ID || renewed.SubjectDID != original.SubjectDID || renewed.Type != original.Type || len(credentials.created) != 2 {
+		t.Errorf("expected a new credential to the same subject, got %+v", renewed)
+	}
+}
+
+func TestRenewCredentialRefusesOrganizationIssuers(t *testing.T) {
+	generator := did.NewGenerator()
+	issuer := newIssuer(t, generator, 2)
+	credentials := &fakeCredentials{}
+	service := NewCredentialService(credentials, &fakeDIDs{records: map[string]*domain.DID{issuer.Did: issuer}}, generator.Registry(), nil)
+
+	// The original was issued once the controllers approved
+	original, err := service.IssueApprovedCredential(issuer, &domain.CredentialIssueRequest{
+		SubjectDID: "did:example:holder",
+		Type:       "EmployeeCredential",
+		Claims:     map[string]any{"role": "engineer"},
+	})
+	if err != nil {
+		t.Fatalf("IssueApprovedCredential failed: %v", err)
+	}
+
+	if _, err := service.RenewCredential(original, time.Now().Add(365*24*time.Hour)); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected ErrForbidden renewing an organization's credential, got %v", err)
+	}
+	if len(credentials.created) != 1 {
+		t.Errorf("expected no credential issued without the controllers' approval, got %d", len(credentials.created)-1)
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// custodyTransferTTL is how long the owner has to sign a transfer challenge
+const custodyTransferTTL = 15 * time.Minute
+
+// CustodyTransferMessage is the message the owner signs with the new key to prove
+// possession of it
+func CustodyTransferMessage(didString, challenge string) []byte {
+	return []byte("custody-transfer:" + didString + ":" + challenge)
+}
+
+// CustodyService transfers control of custodial DIDs to their owners
+type CustodyService struct {
+	transferRepo domain.CustodyTransferRepository
+	didRepo      domain.DIDRepository
+	jobRepo      domain.BlockchainJobRepository
+	registry     *did.Registry
+	queue        JobPublisher
+	// clock dates transfers and checks their expiry
+	clock clock.Clock
+}
+
+// NewCustodyService creates a new custody service; while queue is an
+// OfflineQueue, the key rotation job is only picked up by the database-backed worker
+func NewCustodyService(
+	transferRepo domain.CustodyTransferRepository,
+	didRepo domain.DIDRepository,
+	jobRepo domain.BlockchainJobRepository,
+	registry *did.Registry,
+	queue JobPublisher,
+) *CustodyService {
+	return &CustodyService{
+		transferRepo: transferRepo,
+		didRepo:      didRepo,
+		jobRepo:      jobRepo,
+		registry:     registry,
+		queue:        queue,
+		clock:        clock.System,
+	}
+}
+
+// SetClock sets the time source of custody transfer expiry, clock.System by default
+func (s *CustodyService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// StartTransfer opens a custody transfer of one of the user's custodial DIDs and
+// returns the challenge to sign with the new key
+func (s *CustodyService) StartTransfer(userID uuid.UUID, clientID string, didID uuid.UUID) (*domain.CustodyTransfer, error) {
+	record, err := s.ownedDID(userID, didID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.Custodial {
+		return nil, domain.ErrNotCustodial
+	}
+	if record.ApprovalThreshold > 0 {
+		return nil, fmt.Errorf("%w: organization DIDs stay under the control of their controllers", domain.ErrForbidden)
+	}
+	if record.Status != string(domain.DIDStatusActive) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	fingerprint, err := s.keyFingerprint(record)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := make([]byte, 16)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	now := s.clock.Now()
+	transfer := &domain.CustodyTransfer{
+		ID:                     uuid.New(),
+		DIDID:                  record.ID,
+		Did:                    record.Did,
+		UserID:                 userID,
+		ClientID:               clientID,
+		Challenge:              hex.EncodeToString(challenge),
+		Status:                 string(domain.CustodyTransferStatusPending),
+		PreviousKeyFingerprint: fingerprint,
+		CreatedAt:              now,
+		ExpiresAt:              now.Add(custodyTransferTTL),
+	}
+	if err := s.transferRepo.Create(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CompleteTransfer checks the owner's proof of possession of the new key, rotates the
+// DID to it and wipes the stored private key. The rotation is then anchored through
+// the blockchain job pipeline.
+func (s *CustodyService) CompleteTransfer(userID uuid.UUID, transferID uuid.UUID, req *domain.CustodyTransferCompleteRequest) (*domain.CustodyTransfer, error) {
+	transfer, err := s.transferRepo.GetByID(transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.UserID != userID {
+		return nil, domain.ErrCustodyTransferNotFound
+	}
+	if transfer.Status != string(domain.CustodyTransferStatusPending) {
+		return nil, domain.ErrCustodyTransferClosed
+	}
+	if s.clock.Now().After(transfer.ExpiresAt) {
+		if err := s.transferRepo.MarkExpired(transfer.ID); err != nil {
+			log.Printf("Failed to expire custody transfer %s: %v", transfer.ID, err)
+		}
+		return nil, domain.ErrCustodyTransferClosed
+	}
+
+	record, err := s.didRepo.GetByID(transfer.DIDID)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+
+	keyAlgorithm := req.KeyAlgorithm
+	if keyAlgorithm == "" {
+		keyAlgorithm = record.KeyAlgorithm
+	}
+	suite, err := s.registry.SignatureSuiteForNewKeys(keyAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSignature, err)
+	}
+
+	publicKey, err := hex.DecodeString(req.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed public key", domain.ErrInvalidSignature)
+	}
+	// Suites also derive public keys from private keys; accepting only a key that
+	// is its own public key keeps private keys from being stored again
+	derived, err := suite.PublicKey(publicKey)
+	if err != nil || !bytes.Equal(derived, publicKey) {
+		return nil, fmt.Errorf("%w: public_key is not a %s public key", domain.ErrInvalidSignature, suite.ID())
+	}
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", domain.ErrInvalidSignature)
+	}
+	if !suite.Verify(publicKey, CustodyTransferMessage(transfer.Did, transfer.Challenge), signature) {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	completedAt := s.clock.Now()
+	transfer.NewPublicKey = strings.ToLower(req.PublicKey)
+	transfer.KeyAlgorithm = suite.ID()
+	transfer.CompletedAt = &completedAt
+	if err := s.transferRepo.Complete(transfer); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: custody of %s transferred to user %s via client %s (transfer %s, previous key %s)",
+		transfer.Did, transfer.UserID, transfer.ClientID, transfer.ID, transfer.PreviousKeyFingerprint)
+
+	if _, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeUpdateDID, record); err != nil {
+		log.Printf("Warning: failed to anchor key rotation of %s: %v", record.Did, err)
+	}
+
+	return transfer, nil
+}
+
+// ownedDID loads a DID and checks that it belongs to the user
+func (s *CustodyService) ownedDID(userID, didID uuid.UUID) (*domain.DID, error) {
+	record, err := s.didRepo.GetByID(didID)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID != userID {
+		return nil, domain.ErrDIDNotFound
+	}
+	return record, nil
+}
+
+// keyFingerprint returns the hex SHA-256 of a DID's current public key
+func (s *CustodyService) keyFingerprint(record *domain.DID) (string, error) {
+	suite, err := s.registry.SignatureSuite(record.KeyAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return "", fmt.Errorf("failed to load DID key: %w", err)
+	}
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:]), nil
+}
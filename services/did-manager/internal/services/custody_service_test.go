@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// fakeCustodyDIDs serves fixed DID records by DID and by ID
+type fakeCustodyDIDs struct {
+	fakeDIDs
+}
+
+func (f *fakeCustodyDIDs) GetByID(id uuid.UUID) (*domain.DID, error) {
+	for _, record := range f.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return nil, domain.ErrDIDNotFound
+}
+
+// fakeTransfers keeps transfers in memory and, on completion, rotates the DID to the
+// new key and clears its custodial flag as the repository does
+type fakeTransfers struct {
+	domain.CustodyTransferRepository
+	dids      *fakeCustodyDIDs
+	transfers map[uuid.UUID]*domain.CustodyTransfer
+}
+
+func (f *fakeTransfers) Create(transfer *domain.CustodyTransfer) error {
+	f.transfers[transfer.ID] = transfer
+	return nil
+}
+
+func (f *fakeTransfers) GetByID(id uuid.UUID) (*domain.CustodyTransfer, error) {
+	transfer, ok := f.transfers[id]
+	if !ok {
+		return nil, domain.ErrCustodyTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (f *fakeTransfers) Complete(transfer *domain.CustodyTransfer) error {
+	record, err := f.dids.GetByID(transfer.DIDID)
+	if err != nil || !record.Custodial {
+		return domain.ErrCustodyTransferClosed
+	}
+	transfer.Status = string(domain.CustodyTransferStatusCompleted)
+	record.PublicKey = transfer.NewPublicKey
+	record.KeyAlgorithm = transfer.KeyAlgorithm
+	record.Custodial = false
+	return nil
+}
+
+func TestCompleteTransferWipesTheCustodialKey(t *testing.T) {
+	generator := did.NewGenerator()
+	userID := uuid.New()
+	record := newIssuer(t, generator, 0)
+	record.UserID = userID
+	custodialKey := record.PublicKey
+
+	dids := &fakeCustodyDIDs{fakeDIDs{records: map[string]*domain.DID{record.Did: record}}}
+	transfers := &fakeTransfers{dids: dids, transfers: map[uuid.UUID]*domain.CustodyTransfer{}}
+	jobs := &fakeJobs{}
+	service := NewCustodyService(transfers, dids, jobs, generator.Registry(), OfflineQueue(errors.New("no queue in tests")))
+
+	transfer, err := service.StartTransfer(userID, "wallet", record.ID)
+	if err != nil {
+		t.Fatalf("StartTransfer failed: %v", err)
+	}
+	suite, err := generator.Registry().SignatureSuite(record.KeyAlgorithm)
+	if err != nil {
+		t.Fatalf("failed to load signature suite: %v", err)
+	}
+	sign := func(privateKey []byte) string {
+		signature, err := suite.Sign(privateKey, CustodyTransferMessage(transfer.Did, transfer.Challenge))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return hex.EncodeToString(signature)
+	}
+
+	// A private key passed as the new key would be stored again
+	privateKey, err := hex.DecodeString(custodialKey)
+	if err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	_, err = service.CompleteTransfer(userID, transfer.ID, &domain.CustodyTransferCompleteRequest{PublicKey: custodialKey, Signature: sign(privateKey)})
+	if !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("expected a private key to be refused as the new key, got %v", err)
+	}
+
+	publicKey, ownerKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if _, err := service.CompleteTransfer(userID, transfer.ID, &domain.CustodyTransferCompleteRequest{
+		PublicKey: hex.EncodeToString(publicKey),
+		Signature: sign(ownerKey),
+	}); err != nil {
+		t.Fatalf("CompleteTransfer failed: %v", err)
+	}
+	if record.Custodial || record.PublicKey == custodialKey || record.PublicKey != hex.EncodeToString(publicKey) {
+		t.Fatalf("expected the DID to hold only the owner's public key, got custodial=%v key=%s", record.Custodial, record.PublicKey)
+	}
+	if len(jobs.created) != 1 || jobs.created[0].JobType != string(domain.JobTypeUpdateDID) {
+		t.Errorf("expected the rotation to be anchored, got %+v", jobs.created)
+	}
+
+	// The service no longer signs for the DID
+	credentials := NewCredentialService(&fakeCredentials{}, dids, generator.Registry(), nil)
+	_, err = credentials.IssueCredential(record.Did, &domain.CredentialIssueRequest{
+		SubjectDID: "did:example:holder",
+		Type:       "EmployeeCredential",
+		Claims:     map[string]any{"role": "engineer"},
+	})
+	if !errors.Is(err, domain.ErrNotCustodial) {
+		t.Errorf("expected custodial signing to be refused with ErrNotCustodial, got %v", err)
+	}
+	if _, err := service.StartTransfer(userID, "wallet", record.ID); !errors.Is(err, domain.ErrNotCustodial) {
+		t.Errorf("expected a self-custodial DID to refuse another transfer, got %v", err)
+	}
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/attestation"
+	"did-manager/pkg/did"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// maxDeviceKeysPerDID bounds the active device keys listed on one DID document
+const maxDeviceKeysPerDID = 20
+
+// DeviceKeyMessage is the message a device signs with its key to register the key on
+// a DID, proving it holds the private key
+func DeviceKeyMessage(didString, publicKeyHex string) []byte {
+	return []byte("device-key:" + didString + ":" + publicKeyHex)
+}
+
+// DeviceKeyAttestationChallenge is the challenge a platform attestation submitted with
+// a device key must be bound to: the SHA-256 hash of the key's DeviceKeyMessage. Play
+// Integrity tokens carry it base64url-encoded as their nonce; App Attest attestations
+// hash it as their client data.
+func DeviceKeyAttestationChallenge(didString, publicKeyHex string) []byte {
+	challenge := sha256.Sum256(DeviceKeyMessage(didString, publicKeyHex))
+	return challenge[:]
+}
+
+// ParseAttestedOperations parses the WALLET_ATTESTED_OPERATIONS setting: a
+// comma-separated list of wallet operations restricted to attested app instances
+func ParseAttestedOperations(spec string) (map[domain.WalletOperation]bool, error) {
+	operations := make(map[domain.WalletOperation]bool)
+	for _, name := range strings.Split(spec, ",") {
+		operation := domain.WalletOperation(strings.TrimSpace(name))
+		switch operation {
+		case "":
+			continue
+		case domain.WalletOperationCustodyTransfer, domain.WalletOperationPresentation, domain.WalletOperationDeviceKeys:
+			operations[operation] = true
+		default:
+			return nil, fmt.Errorf("unknown wallet operation: %s", operation)
+		}
+	}
+	return operations, nil
+}
+
+// DeviceKeyService manages device subkeys: keys held by a user's logged-in devices and
+// listed as authentication verification methods on the user's DID. A device signs
+// requests and session refreshes with its own key, so each device can be revoked
+// without touching the DID's key or the user's other devices.
+type DeviceKeyService struct {
+	keys     domain.DeviceKeyRepository
+	didRepo  domain.DIDRepository
+	registry *did.Registry
+	events   EventPublisher
+
+	// attestors verify app attestations by platform; attested lists the wallet
+	// operations only attested app instances may perform
+	attestors map[string]attestation.Verifier
+	attested  map[domain.WalletOperation]bool
+}
+
+// NewDeviceKeyService creates a new device key service; events may be nil when no
+// event stream is available
+func NewDeviceKeyService(keys domain.DeviceKeyRepository, didRepo domain.DIDRepository, registry *did.Registry, events EventPublisher) *DeviceKeyService {
+	return &DeviceKeyService{
+		keys:     keys,
+		didRepo:  didRepo,
+		registry: registry,
+		events:   events,
+	}
+}
+
+// SetAttestation accepts platform attestations from verifiers during device key
+// registration, and restricts the attested wallet operations to requests signed with
+// an attested device key
+func (s *DeviceKeyService) SetAttestation(verifiers []attestation.Verifier, attested map[domain.WalletOperation]bool) {
+	s.attestors = make(map[string]attestation.Verifier, len(verifiers))
+	for _, verifier := range verifiers {
+		s.attestors[verifier.Platform()] = verifier
+	}
+	s.attested = attested
+}
+
+// AttestationRequired reports whether a wallet operation is restricted to attested
+// app instances
+func (s *DeviceKeyService) AttestationRequired(operation domain.WalletOperation) bool {
+	return s.attested[operation]
+}
+
+// Register lists a device's key on one of the user's DIDs after checking the device's
+// signature over it and, when submitted, the app instance's platform attestation
+func (s *DeviceKeyService) Register(ctx context.Context, userID uuid.UUID, req *domain.DeviceKeyRegisterRequest) (*domain.DeviceKey, error) {
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID != userID {
+		// Do not reveal that the DID exists
+		return nil, domain.ErrDIDNotFound
+	}
+	if record.Status != string(domain.DIDStatusActive) && record.Status != string(domain.DIDStatusPending) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	suite, err := s.registry.SignatureSuiteForNewKeys(req.KeyAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidDeviceKey, err)
+	}
+	keyMaterial, err := hex.DecodeString(req.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed public key", domain.ErrInvalidDeviceKey)
+	}
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidDeviceKey, err)
+	}
+	// Only a public key may be submitted, never key material the suite derives one from
+	if !bytes.Equal(publicKey, keyMaterial) {
+		return nil, fmt.Errorf("%w: expected a public key", domain.ErrInvalidDeviceKey)
+	}
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil || !suite.Verify(publicKey, DeviceKeyMessage(record.Did, req.PublicKey), signature) {
+		return nil, fmt.Errorf("%w: proof of possession failed", domain.ErrInvalidSignature)
+	}
+
+	var keyAttestation *domain.DeviceKeyAttestation
+	if req.Attestation != nil {
+		keyAttestation, err = s.verifyAttestation(ctx, req.Attestation, DeviceKeyAttestationChallenge(record.Did, req.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := s.keys.CountActiveByDIDID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxDeviceKeysPerDID {
+		return nil, fmt.Errorf("%w: at most %d active device keys", domain.ErrDeviceKeyLimit, maxDeviceKeysPerDID)
+	}
+
+	id := uuid.New()
+	key := &domain.DeviceKey{
+		ID:                 id,
+		DIDID:              record.ID,
+		DID:                record.Did,
+		UserID:             userID,
+		Name:               req.Name,
+		VerificationMethod: record.Did + "#device-" + id.String(),
+		PublicKey:          hex.EncodeToString(publicKey),
+		KeyAlgorithm:       suite.ID(),
+		Status:             string(domain.DeviceKeyStatusActive),
+		CreatedAt:          time.Now(),
+		Attestation:        keyAttestation,
+	}
+	if err := s.keys.Create(key); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: device key %s (%s) added to DID %s of user %s", key.ID, key.Name, key.DID, userID)
+	if keyAttestation != nil {
+		log.Printf("AUDIT: device key %s attested by %s for app %s", key.ID, keyAttestation.Platform, keyAttestation.AppID)
+	}
+	s.publish(queue.EventDIDDeviceKeyAdded, key)
+	return key, nil
+}
+
+// List lists the device keys of a user's DIDs, revoked ones included
+func (s *DeviceKeyService) List(userID uuid.UUID) ([]*domain.DeviceKey, error) {
+	keys, err := s.keys.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []*domain.DeviceKey{}
+	}
+	return keys, nil
+}
+
+// Revoke removes one of a user's device keys from its DID, signing the device out
+func (s *DeviceKeyService) Revoke(userID, id uuid.UUID) error {
+	key, err := s.keys.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if key.UserID != userID {
+		return domain.ErrDeviceKeyNotFound
+	}
+	if err := s.keys.Revoke(id, time.Now()); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: device key %s (%s) revoked on DID %s of user %s", key.ID, key.Name, key.DID, userID)
+	s.publish(queue.EventDIDDeviceKeyRevoked, key)
+	return nil
+}
+
+// RevokeAll removes every device key of a user, e.g. when all of their sessions are
+// signed out after a password change
+func (s *DeviceKeyService) RevokeAll(userID uuid.UUID) (int64, error) {
+	revoked, err := s.keys.RevokeByUserID(userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if revoked > 0 {
+		log.Printf("AUDIT: %d device keys of user %s revoked", revoked, userID)
+	}
+	return revoked, nil
+}
+
+// Verify reports whether one of a user's active device keys signed message
+func (s *DeviceKeyService) Verify(userID, id uuid.UUID, req *domain.DeviceKeyVerifyRequest) (bool, error) {
+	key, err := s.keys.GetByID(id)
+	if err != nil {
+		return false, err
+	}
+	if key.UserID != userID {
+		return false, domain.ErrDeviceKeyNotFound
+	}
+	if key.Status != string(domain.DeviceKeyStatusActive) {
+		return false, nil
+	}
+	return s.verifySignature(key, []byte(req.Message), req.Signature)
+}
+
+// AuthenticationKey returns the active device key listed under a verification method
+// ID of a DID, for verifying requests the device signed
+func (s *DeviceKeyService) AuthenticationKey(didString, verificationMethod string) (*domain.DeviceKey, error) {
+	key, err := s.keys.GetByVerificationMethod(verificationMethod)
+	if err != nil {
+		return nil, err
+	}
+	if key.DID != didString || key.Status != string(domain.DeviceKeyStatusActive) {
+		return nil, domain.ErrDeviceKeyNotFound
+	}
+	return key, nil
+}
+
+// RequireAttested checks that a request authenticated as a DID with one of its device
+// keys comes from an attested app instance of the wallet token's user
+func (s *DeviceKeyService) RequireAttested(userID uuid.UUID, didString, verificationMethod string) (*domain.DeviceKey, error) {
+	if verificationMethod == "" {
+		return nil, fmt.Errorf("%w: request is not signed with a device key", domain.ErrAttestationRequired)
+	}
+	key, err := s.AuthenticationKey(didString, verificationMethod)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceKeyNotFound) {
+			return nil, fmt.Errorf("%w: unknown device key", domain.ErrAttestationRequired)
+		}
+		return nil, err
+	}
+	if key.UserID != userID {
+		return nil, fmt.Errorf("%w: device key belongs to another user", domain.ErrAttestationRequired)
+	}
+	if key.Attestation == nil {
+		return nil, fmt.Errorf("%w: device key was registered without an attestation", domain.ErrAttestationRequired)
+	}
+	return key, nil
+}
+
+// AddToDocument lists the DID's active device keys under authentication in document
+func (s *DeviceKeyService) AddToDocument(record *domain.DID, document *did.Document) error {
+	keys, err := s.keys.ListActiveByDIDID(record.ID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		suite, err := s.registry.SignatureSuite(key.KeyAlgorithm)
+		if err != nil {
+			return err
+		}
+		publicKey, err := hex.DecodeString(key.PublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to decode device key %s: %w", key.ID, err)
+		}
+		document.AddAuthenticationMethod(key.VerificationMethod, suite.VerificationMethodType(), publicKey)
+	}
+	return nil
+}
+
+// verifyAttestation verifies a platform attestation against challenge
+func (s *DeviceKeyService) verifyAttestation(ctx context.Context, evidence *domain.DeviceKeyAttestationEvidence, challenge []byte) (*domain.DeviceKeyAttestation, error) {
+	verifier, ok := s.attestors[evidence.Platform]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s attestations are not accepted", domain.ErrInvalidAttestation, evidence.Platform)
+	}
+
+	result, err := verifier.Verify(ctx, &attestation.Evidence{
+		Token: evidence.Token,
+		KeyID: evidence.KeyID,
+	}, challenge)
+	if err != nil {
+		if errors.Is(err, attestation.ErrInvalidAttestation) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidAttestation, err)
+		}
+		return nil, fmt.Errorf("failed to verify attestation: %w", err)
+	}
+
+	return &domain.DeviceKeyAttestation{
+		Platform:   result.Platform,
+		AppID:      result.AppID,
+		KeyID:      result.KeyID,
+		AttestedAt: time.Now(),
+	}, nil
+}
+
+// verifySignature checks a hex signature by a device key
+func (s *DeviceKeyService) verifySignature(key *domain.DeviceKey, message []byte, signatureHex string) (bool, error) {
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false, nil
+	}
+	publicKey, err := hex.DecodeString(key.PublicKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode device key %s: %w", key.ID, err)
+	}
+	return s.registry.VerifySignature(key.KeyAlgorithm, publicKey, message, signature)
+}
+
+// publish publishes a device key event on the key's DID
+func (s *DeviceKeyService) publish(eventType string, key *domain.DeviceKey) {
+	if s.events == nil {
+		return
+	}
+	event := &queue.Event{
+		ID:      uuid.New().String(),
+		Type:    eventType,
+		Subject: key.DID,
+		Data: map[string]string{
+			"device_key_id":       key.ID.String(),
+			"verification_method": key.VerificationMethod,
+			"name":                key.Name,
+		},
+		OccurredAt: time.Now(),
+	}
+	if key.Attestation != nil {
+		event.Data["attestation_platform"] = key.Attestation.Platform
+	}
+	if err := s.events.PublishEvent(event); err != nil {
+		log.Printf("Warning: failed to publish %s event: %v", event.Type, err)
+	}
+}
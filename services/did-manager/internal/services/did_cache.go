@@ -0,0 +1,130 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// maxCachedDIDs bounds the DID read cache; it is emptied when full
+const maxCachedDIDs = 10000
+
+// didReadCache caches DID records by DID string for a short TTL, so hot DIDs are not
+// read from the database on every resolution and verification. Writes through the
+// cache invalidate it.
+type didReadCache struct {
+	domain.DIDRepository
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedDID
+}
+
+// cachedDID is a DID record and when it was read
+type cachedDID struct {
+	record   *domain.DID
+	cachedAt time.Time
+}
+
+// CacheDIDReads wraps a DID repository so that lookups by DID string are cached for
+// ttl. Changes made elsewhere, such as a revocation on the primary, are seen once the
+// entry expires. A ttl of zero returns the repository as is.
+func CacheDIDReads(repo domain.DIDRepository, ttl time.Duration) domain.DIDRepository {
+	if ttl <= 0 {
+		return repo
+	}
+	return &didReadCache{DIDRepository: repo, ttl: ttl, entries: make(map[string]cachedDID)}
+}
+
+// GetByDID returns a copy of the cached record, reading it when missing or expired
+func (r *didReadCache) GetByDID(didString string) (*domain.DID, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[didString]
+	r.mu.Unlock()
+	if ok && time.Since(entry.cachedAt) < r.ttl {
+		record := *entry.record
+		return &record, nil
+	}
+
+	record, err := r.DIDRepository.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *record
+	r.mu.Lock()
+	if len(r.entries) >= maxCachedDIDs {
+		r.entries = make(map[string]cachedDID)
+	}
+	r.entries[didString] = cachedDID{record: &cached, cachedAt: time.Now()}
+	r.mu.Unlock()
+
+	return record, nil
+}
+
+// Update stores a DID and empties the cache
+func (r *didReadCache) Update(record *domain.DID) error {
+	r.invalidate()
+	return r.DIDRepository.Update(record)
+}
+
+// UpdateStatus updates a DID's status and empties the cache
+func (r *didReadCache) UpdateStatus(id uuid.UUID, status domain.DIDStatus, txHash string) error {
+	r.invalidate()
+	return r.DIDRepository.UpdateStatus(id, status, txHash)
+}
+
+// UpdateVisibility updates a DID's visibility and empties the cache
+func (r *didReadCache) UpdateVisibility(id uuid.UUID, visibility string) error {
+	r.invalidate()
+	return r.DIDRepository.UpdateVisibility(id, visibility)
+}
+
+// RotateKey replaces a DID's key and empties the cache
+func (r *didReadCache) RotateKey(id uuid.UUID, keyMaterial string, keyAlgorithm string) error {
+	r.invalidate()
+	return r.DIDRepository.RotateKey(id, keyMaterial, keyAlgorithm)
+}
+
+// SetEmailIndex records a DID's email index and empties the cache
+func (r *didReadCache) SetEmailIndex(id uuid.UUID, emailIndex string) error {
+	r.invalidate()
+	return r.DIDRepository.SetEmailIndex(id, emailIndex)
+}
+
+// SetUserID links a DID to another user and empties the cache
+func (r *didReadCache) SetUserID(id uuid.UUID, userID uuid.UUID) error {
+	r.invalidate()
+	return r.DIDRepository.SetUserID(id, userID)
+}
+
+// DeleteSandbox deletes a sandbox's DIDs and empties the cache
+func (r *didReadCache) DeleteSandbox(sandboxTenant string) (int64, error) {
+	r.invalidate()
+	return r.DIDRepository.DeleteSandbox(sandboxTenant)
+}
+
+// forgetCachedDID drops a DID from the read cache wrapping repo, reporting whether it
+// was cached. Repositories without a cache are left alone.
+func forgetCachedDID(repo domain.DIDRepository, didString string) bool {
+	cache, ok := repo.(*didReadCache)
+	if !ok {
+		return false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	_, cached := cache.entries[didString]
+	delete(cache.entries, didString)
+	return cached
+}
+
+// invalidate empties the cache. Writes by record ID do not name the DID string, and
+// they are rare next to reads.
+func (r *didReadCache) invalidate() {
+	r.mu.Lock()
+	r.entries = make(map[string]cachedDID)
+	r.mu.Unlock()
+}
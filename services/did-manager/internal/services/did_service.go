@@ -1,14 +1,21 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"did-manager/internal/domain"
+	"did-manager/internal/security"
 	"did-manager/pkg/blockchain"
+	"did-manager/pkg/clock"
 	"did-manager/pkg/did"
-	"did-manager/pkg/queue"
+	"did-manager/pkg/errreport"
+
+	"packages/canonical"
 
 	"github.com/google/uuid"
 )
@@ -18,63 +25,445 @@ type DIDService struct {
 	didRepo    domain.DIDRepository
 	queueRepo  domain.BlockchainJobRepository
 	didGen     *did.Generator
-	blockchain *blockchain.EthereumClient
-	queue      *queue.NATSQueue
+	blockchain Ledger
+	queue      JobPublisher
+	// notarizationRepo records the transactions of notarization jobs
+	notarizationRepo domain.NotarizationRepository
+	// features gates experimental signature suites per tenant
+	features *FeatureService
+	// dryRun simulates every job instead of submitting transactions
+	dryRun bool
+	// hooks run deployment-specific logic around DID creation and verification
+	hooks *Hooks
+	// duplicates detects new DIDs that likely duplicate an existing identity
+	duplicates *DuplicateService
+	// reviews holds flagged DIDs for manual review
+	reviews domain.ReviewRepository
+	// sidetree holds the receipts of DID operations anchored in Sidetree batches;
+	// nil when DIDs are registered with the registry contract one by one
+	sidetree domain.SidetreeRepository
+	// verificationPolicies decide how verification answers when the chain is
+	// unreachable; nil falls back to the local record with a warning
+	verificationPolicies *VerificationPolicyService
+	// emailIndex indexes the email addresses of new DIDs for lookups by email; nil
+	// leaves DIDs unindexed
+	emailIndex *did.BlindIndex
+	// userHashes indexes the user hashes of new DIDs for lookups; nil leaves them to
+	// the re-hash migration
+	userHashes *UserHashIndexService
+	// purposeKeys generates the purpose keys of new custodial DIDs; nil gives them a
+	// single key for every purpose
+	purposeKeys *PurposeKeyService
+	// pause is the operator switch pausing job submission; nil never pauses
+	pause *AnchoringPauseService
+	// consistency issues the consistency tokens of new DIDs; nil issues none
+	consistency *ConsistencyService
+	// reporter reports panics of blockchain jobs with the job's ID
+	reporter errreport.Reporter
+	// clock dates DIDs, jobs and simulations
+	clock clock.Clock
+	// claimsAttempts limits the claims checks of each DID, which hash with Argon2id; nil
+	// leaves them unlimited
+	claimsAttempts *security.RateLimiter
 }
 
-// NewDIDService creates a new DID service
+// NewDIDService creates a new DID service. Without a blockchain or queue, pass
+// OfflineLedger or OfflineQueue rather than nil.
 func NewDIDService(
 	didRepo domain.DIDRepository,
 	queueRepo domain.BlockchainJobRepository,
 	didGen *did.Generator,
-	blockchain *blockchain.EthereumClient,
-	queue *queue.NATSQueue,
+	blockchain Ledger,
+	queue JobPublisher,
+	notarizationRepo domain.NotarizationRepository,
+	features *FeatureService,
 ) *DIDService {
 	return &DIDService{
-		didRepo:    didRepo,
-		queueRepo:  queueRepo,
-		didGen:     didGen,
-		blockchain: blockchain,
-		queue:      queue,
+		didRepo:          didRepo,
+		queueRepo:        queueRepo,
+		didGen:           didGen,
+		blockchain:       blockchain,
+		queue:            queue,
+		notarizationRepo: notarizationRepo,
+		features:         features,
+		reporter:         errreport.Log,
+		clock:            clock.System,
+	}
+}
+
+// SetClock sets the time source dating DIDs, jobs and simulations, clock.System by
+// default
+func (s *DIDService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetErrorReporter sets where panics of blockchain jobs are reported, errreport.Log by
+// default
+func (s *DIDService) SetErrorReporter(reporter errreport.Reporter) {
+	s.reporter = reporter
+}
+
+// SetDryRun makes every blockchain job a dry run, simulated instead of submitted
+func (s *DIDService) SetDryRun(enabled bool) {
+	s.dryRun = enabled
+}
+
+// SetHooks installs the plugin hooks run around DID creation and verification
+func (s *DIDService) SetHooks(hooks *Hooks) {
+	s.hooks = hooks
+}
+
+// SetDuplicateDetection installs duplicate identity detection on DID creation
+func (s *DIDService) SetDuplicateDetection(duplicates *DuplicateService) {
+	s.duplicates = duplicates
+}
+
+// SetReviewQueue installs the queue holding flagged DIDs for manual review
+func (s *DIDService) SetReviewQueue(reviews domain.ReviewRepository) {
+	s.reviews = reviews
+}
+
+// SetSidetreeBatching leaves DID operation jobs to the Sidetree batcher and verifies
+// DIDs by the receipts of their batches
+func (s *DIDService) SetSidetreeBatching(repo domain.SidetreeRepository) {
+	s.sidetree = repo
+}
+
+// SetAnchoringPause makes queue processing honor the operator switch pausing anchoring
+func (s *DIDService) SetAnchoringPause(pause *AnchoringPauseService) {
+	s.pause = pause
+}
+
+// SetConsistencyTokens returns a consistency token with each new DID, so reads right
+// after its creation can be made to see it
+func (s *DIDService) SetConsistencyTokens(consistency *ConsistencyService) {
+	s.consistency = consistency
+}
+
+// AnchoringPaused reports whether an operator paused anchoring
+func (s *DIDService) AnchoringPaused() bool {
+	return s.pause != nil && s.pause.Paused()
+}
+
+// SetVerificationPolicies applies per-tenant and per-endpoint policies to verification
+// when the blockchain is unreachable
+func (s *DIDService) SetVerificationPolicies(policies *VerificationPolicyService) {
+	s.verificationPolicies = policies
+}
+
+// SetEmailIndex indexes the email addresses of new DIDs, and of existing DIDs once
+// their claims verify, so they can be looked up by email
+func (s *DIDService) SetEmailIndex(index *did.BlindIndex) {
+	s.emailIndex = index
+}
+
+// SetUserHashIndex indexes the user hashes of new DIDs for lookups by user hash
+func (s *DIDService) SetUserHashIndex(index *UserHashIndexService) {
+	s.userHashes = index
+}
+
+// SetPurposeKeys generates separate authentication, assertionMethod and keyAgreement
+// keys for new custodial DIDs
+func (s *DIDService) SetPurposeKeys(keys *PurposeKeyService) {
+	s.purposeKeys = keys
+}
+
+// SetClaimsAttemptLimit limits the claims checks of each DID to perMinute, with the
+// given burst, so claims cannot be guessed against one DID. Claims checks are not
+// limited when perMinute is not positive.
+func (s *DIDService) SetClaimsAttemptLimit(perMinute, burst int) {
+	if perMinute <= 0 {
+		s.claimsAttempts = nil
+		return
+	}
+	s.claimsAttempts = security.NewRateLimiter(perMinute, burst)
+}
+
+// ClaimsRetryAfter returns how long a DID's claims checks wait once over their limit
+func (s *DIDService) ClaimsRetryAfter() time.Duration {
+	if s.claimsAttempts == nil {
+		return 0
 	}
+	return s.claimsAttempts.RetryAfter()
 }
 
-// CreateDID creates a new DID for a user
+// CreateDID creates a new DID for a user. DIDs flagged by hooks or duplicate
+// detection are held for manual review and only registered on-chain once approved.
+// DIDs created in a sandbox are active at once, with a simulated anchoring transaction.
 func (s *DIDService) CreateDID(req *domain.DIDCreateRequest) (*domain.DIDResponse, error) {
+	if err := canonicalizeClaims(req); err != nil {
+		return nil, err
+	}
+
+	reviewReasons, err := s.hooks.BeforeCreateDID(req)
+	if err != nil {
+		return nil, err
+	}
+	sandbox := req.SandboxTenant != ""
+
+	// Sandbox identities are test data, never matched against production identities
+	var duplicates *duplicateCheck
+	if !sandbox {
+		duplicates, err = s.duplicates.check(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if duplicates != nil {
+		reviewReasons = append(reviewReasons, duplicates.reviewReasons...)
+	}
+	held := len(reviewReasons) > 0
+	if held && s.reviews == nil {
+		return nil, errNoReviewQueue
+	}
+
 	// Generate DID, user hash, and keys
-	didString, userHash, privateKey, err := s.didGen.GenerateDID(req.UserID, req.Name, req.Email)
+	allowExperimental := s.features.Enabled(domain.FeaturePQSignatures, req.Tenant)
+	generated, err := s.generateDID(req, allowExperimental)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate DID: %w", err)
+		return nil, err
+	}
+	didString := generated.DID
+	userHash := generated.Commitment.Value
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = string(domain.DIDVisibilityPublic)
+	}
+
+	status := domain.DIDStatusPending
+	switch {
+	case held:
+		status = domain.DIDStatusPendingReview
+	case sandbox:
+		status = domain.DIDStatusActive
 	}
 
 	// Create DID record in database
 	didRecord := &domain.DID{
-		ID:        uuid.New(),
-		UserID:    req.UserID,
-		Did:       didString,
-		UserHash:  userHash,
-		PublicKey: privateKey, // In production, this should be encrypted
-		Status:    string(domain.DIDStatusPending),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            uuid.New(),
+		UserID:        req.UserID,
+		Did:           didString,
+		UserHash:      userHash,
+		HashScheme:    generated.Commitment.Scheme,
+		HashParams:    generated.Commitment.Params,
+		PepperVersion: s.didGen.PepperVersion(),
+		PublicKey:     generated.PrivateKeyHex, // In production, this should be encrypted
+		KeyAlgorithm:  generated.KeyAlgorithm,
+		Custodial:     generated.PrivateKeyHex != "",
+		Status:        string(status),
+		Visibility:    visibility,
+		CreatedAt:     s.clock.Now(),
+		UpdatedAt:     s.clock.Now(),
+	}
+	if sandbox {
+		didRecord.SandboxTenant = req.SandboxTenant
+		didRecord.BlockchainTx = sandboxTxHash(didRecord.ID.String())
+	}
+	if !didRecord.Custodial {
+		didRecord.PublicKey = hex.EncodeToString(generated.PublicKey)
+	}
+	if s.emailIndex != nil && !req.Anonymous {
+		didRecord.EmailIndex = s.emailIndex.Email(req.Email)
 	}
 
 	if err := s.didRepo.Create(didRecord); err != nil {
 		return nil, fmt.Errorf("failed to create DID record: %w", err)
 	}
+	s.duplicates.record(didRecord, duplicates)
+	s.userHashes.record(didRecord)
+	s.purposeKeys.generate(didRecord)
+
+	message := "DID created successfully and queued for blockchain registration"
+	switch {
+	case held:
+		if err := queueReview(s.reviews, domain.ReviewOperationCreateDID, didRecord.ID, didRecord.Did, reviewReasons); err != nil {
+			return nil, fmt.Errorf("failed to queue DID for review: %w", err)
+		}
+		message = "DID created and held for manual review before blockchain registration"
+	case sandbox:
+		message = "Sandbox DID created; its blockchain registration was simulated"
+	case req.DryRun:
+		s.queueRegistration(didRecord, true)
+		message = "DID created successfully; its blockchain registration will only be simulated"
+	default:
+		s.queueRegistration(didRecord, false)
+	}
+
+	response := &domain.DIDResponse{
+		DID:      didRecord,
+		UserHash: userHash,
+		Status:   didRecord.Status,
+		Message:  message,
+	}
+	// The DID is created either way; without a token, reads may only lag behind
+	if s.consistency != nil {
+		token, err := s.consistency.Token(didRecord.Did)
+		if err != nil {
+			log.Printf("Failed to issue consistency token for %s: %v", didRecord.Did, err)
+		}
+		response.ConsistencyToken = token
+	}
+	s.hooks.AfterCreateDID(req, response)
+
+	return response, nil
+}
+
+// GetOrCreateDID returns the DID a user already has, or creates one. An existing DID is
+// found by the user ID, or through the email index when its claims commitment matches
+// the request's name and email; a DID found by email is linked to the requesting user.
+// Failed, rejected and deactivated DIDs are not reused; a suspended DID is returned as is.
+func (s *DIDService) GetOrCreateDID(req *domain.DIDCreateRequest) (*domain.DIDResponse, error) {
+	if req.Anonymous {
+		return nil, fmt.Errorf("%w: anonymous DIDs cannot be matched to an existing DID", domain.ErrInvalidDIDRequest)
+	}
+	if err := canonicalizeClaims(req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.findExistingDID(req)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return s.CreateDID(req)
+	}
+
+	return &domain.DIDResponse{
+		DID:      existing,
+		UserHash: existing.UserHash,
+		Status:   existing.Status,
+		Message:  "User already has a DID",
+		Existing: true,
+	}, nil
+}
+
+// findExistingDID finds the reusable DID of a create request's user, or nil
+func (s *DIDService) findExistingDID(req *domain.DIDCreateRequest) (*domain.DID, error) {
+	record, err := s.didRepo.GetByUserID(req.UserID)
+	switch {
+	case err == nil && reusableDID(record) && record.SandboxTenant == req.SandboxTenant:
+		return record, nil
+	case err != nil && !errors.Is(err, domain.ErrDIDNotFound):
+		return nil, err
+	}
 
+	if s.emailIndex == nil {
+		return nil, nil
+	}
+	record, err = s.didRepo.GetByEmailIndex(s.emailIndex.Email(req.Email))
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reusableDID(record) || record.SandboxTenant != req.SandboxTenant {
+		return nil, nil
+	}
+
+	// The email index only proves the address; the commitment proves the claims
+	commitment := &did.Commitment{
+		Scheme:        record.HashScheme,
+		Params:        record.HashParams,
+		Value:         record.UserHash,
+		PepperVersion: record.PepperVersion,
+	}
+	matches, err := s.didGen.VerifyClaims(commitment, req.Name, req.Email)
+	if err != nil || !matches {
+		return nil, nil
+	}
+
+	if record.UserID != req.UserID {
+		if err := s.didRepo.SetUserID(record.ID, req.UserID); err != nil {
+			return nil, err
+		}
+		log.Printf("AUDIT: DID %s linked from user %s to user %s by its email commitment", record.Did, record.UserID, req.UserID)
+		record.UserID = req.UserID
+	}
+	return record, nil
+}
+
+// canonicalizeClaims rejects spoofed names and puts the name and email of a create
+// request in canonical form, before they are screened, committed to and indexed
+func canonicalizeClaims(req *domain.DIDCreateRequest) error {
+	if req.Anonymous {
+		return nil
+	}
+	if err := canonical.ValidateName(req.Name); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidDIDRequest, err)
+	}
+	req.Name = canonical.Name(req.Name)
+	req.Email = canonical.Email(req.Email)
+	return nil
+}
+
+// reusableDID reports whether get-or-create may return a DID rather than create one
+func reusableDID(record *domain.DID) bool {
+	switch domain.DIDStatus(record.Status) {
+	case domain.DIDStatusPending, domain.DIDStatusActive, domain.DIDStatusPendingReview:
+		return true
+	case domain.DIDStatusSuspended:
+		// Returned as is, so that a new DID does not lift the suspension
+		return true
+	}
+	return false
+}
+
+// generateDID generates the DID, user hash and key of a create request. Anonymous DIDs
+// carry no claims and are only created for tenants with the anonymous_dids feature.
+func (s *DIDService) generateDID(req *domain.DIDCreateRequest, allowExperimental bool) (*did.GeneratedDID, error) {
+	if !req.Anonymous {
+		if req.PublicKey != "" {
+			return nil, fmt.Errorf("%w: public_key is only accepted for anonymous DIDs", domain.ErrInvalidDIDRequest)
+		}
+		generated, err := s.didGen.GenerateDIDWithSuiteAllowing(req.UserID, req.Name, req.Email, req.KeyAlgorithm, allowExperimental)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate DID: %w", err)
+		}
+		return generated, nil
+	}
+
+	if !s.features.Enabled(domain.FeatureAnonymousDIDs, req.Tenant) {
+		return nil, domain.ErrAnonymousDIDsDisabled
+	}
+	if req.Name != "" || req.Email != "" || req.Password != "" {
+		return nil, fmt.Errorf("%w: anonymous DIDs take no name, email or password", domain.ErrInvalidDIDRequest)
+	}
+
+	var publicKey []byte
+	if req.PublicKey != "" {
+		var err error
+		if publicKey, err = hex.DecodeString(req.PublicKey); err != nil {
+			return nil, fmt.Errorf("%w: malformed public_key", domain.ErrInvalidDIDRequest)
+		}
+	}
+	generated, err := s.didGen.GenerateAnonymousDID(req.KeyAlgorithm, publicKey, allowExperimental)
+	if errors.Is(err, did.ErrInvalidPublicKey) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidDIDRequest, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DID: %w", err)
+	}
+	return generated, nil
+}
+
+// queueRegistration creates and publishes the job registering a DID on-chain; failures
+// are logged rather than failing the DID
+func (s *DIDService) queueRegistration(record *domain.DID, dryRun bool) {
 	// Create blockchain job for async processing
 	blockchainJob := &domain.BlockchainJob{
 		ID:         uuid.New(),
 		JobType:    string(domain.JobTypeRegisterDID),
-		DIDID:      didRecord.ID,
-		UserHash:   userHash,
-		DID:        didString,
+		DIDID:      record.ID,
+		UserHash:   record.UserHash,
+		DID:        record.AnchoredDID(),
 		Status:     string(domain.JobStatusPending),
 		RetryCount: 0,
 		MaxRetries: 3,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		CreatedAt:  s.clock.Now(),
+		UpdatedAt:  s.clock.Now(),
+		DryRun:     dryRun,
 	}
 
 	if err := s.queueRepo.Create(blockchainJob); err != nil {
@@ -82,55 +471,79 @@ func (s *DIDService) CreateDID(req *domain.DIDCreateRequest) (*domain.DIDRespons
 		// Continue with DID creation even if job creation fails
 	}
 
-	// Publish job to NATS queue for async processing
-	queueJob := &queue.BlockchainJob{
-		ID:        blockchainJob.ID.String(),
-		JobType:   blockchainJob.JobType,
-		DIDID:     blockchainJob.DIDID.String(),
-		UserHash:  blockchainJob.UserHash,
-		DID:       blockchainJob.DID,
-		CreatedAt: blockchainJob.CreatedAt,
+	// Publish job to NATS queue for async processing; the database-backed worker picks
+	// it up either way
+	publishJob(s.queueRepo, s.queue, blockchainJob)
+}
+
+// releaseReviewedDID registers an approved DID held for review, or marks it rejected
+func (s *DIDService) releaseReviewedDID(id uuid.UUID, approved bool) error {
+	record, err := s.didRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if record.Status != string(domain.DIDStatusPendingReview) {
+		return fmt.Errorf("%w: DID is %s", domain.ErrReviewClosed, record.Status)
 	}
 
-	if err := s.queue.PublishJob(queueJob); err != nil {
-		log.Printf("Warning: failed to publish job to queue: %v", err)
-		// Continue with DID creation even if queue publishing fails
+	if !approved {
+		return s.didRepo.UpdateStatus(id, domain.DIDStatusRejected, "")
 	}
 
-	return &domain.DIDResponse{
-		DID:      didRecord,
-		UserHash: userHash,
-		Status:   string(domain.DIDStatusPending),
-		Message:  "DID created successfully and queued for blockchain registration",
-	}, nil
+	if err := s.didRepo.UpdateStatus(id, domain.DIDStatusPending, ""); err != nil {
+		return err
+	}
+	record.Status = string(domain.DIDStatusPending)
+	s.queueRegistration(record, false)
+	return nil
 }
 
-// VerifyDID verifies a DID on the blockchain
+// VerifyDID verifies a DID on the blockchain, running the verification hooks around it
 func (s *DIDService) VerifyDID(req *domain.DIDVerificationRequest) (*domain.DIDVerificationResponse, error) {
+	if err := s.hooks.BeforeVerifyDID(req); err != nil {
+		return nil, err
+	}
+
+	response, err := s.verifyDID(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.AfterVerifyDID(req, response)
+	return response, nil
+}
+
+// verifyDID verifies a DID against the local record and the blockchain
+func (s *DIDService) verifyDID(req *domain.DIDVerificationRequest) (*domain.DIDVerificationResponse, error) {
 	log.Printf("DEBUG SERVICE: Starting verification for DID: %s", req.DID)
 
 	// Check if repository is nil
 	if s.didRepo == nil {
 		log.Printf("DEBUG SERVICE: didRepo is nil!")
 		return &domain.DIDVerificationResponse{
-			IsValid:  false,
-			DID:      req.DID,
-			UserHash: req.UserHash,
-			Status:   "not_found",
-			Message:  "Service not properly initialized",
+			IsValid:   false,
+			DID:       req.DID,
+			UserHash:  req.UserHash,
+			Status:    "not_found",
+			Message:   "Service not properly initialized",
+			Assurance: domain.AssuranceLocalDB,
 		}, nil
 	}
 
-	// First check if DID exists in our database
+	// First check if DID exists in our database; sandbox DIDs only exist in their sandbox
 	didRecord, err := s.didRepo.GetByDID(req.DID)
+	if err == nil && didRecord.SandboxTenant != req.SandboxTenant {
+		err = domain.ErrDIDNotFound
+	}
 	if err != nil {
 		log.Printf("DEBUG SERVICE: GetByDID failed: %v", err)
 		return &domain.DIDVerificationResponse{
-			IsValid:  false,
-			DID:      req.DID,
-			UserHash: req.UserHash,
-			Status:   "not_found",
-			Message:  "DID not found in local database: " + err.Error(),
+			IsValid:   false,
+			DID:       req.DID,
+			UserHash:  req.UserHash,
+			Status:    "not_found",
+			Message:   "DID not found in local database: " + err.Error(),
+			Assurance: domain.AssuranceLocalDB,
 		}, nil
 	}
 
@@ -139,46 +552,179 @@ func (s *DIDService) VerifyDID(req *domain.DIDVerificationRequest) (*domain.DIDV
 	// Verify user hash matches (skip if empty for status checks)
 	if req.UserHash != "" && didRecord.UserHash != req.UserHash {
 		return &domain.DIDVerificationResponse{
-			IsValid:  false,
-			DID:      req.DID,
-			UserHash: req.UserHash,
-			Status:   "hash_mismatch",
-			Message:  "User hash does not match",
+			IsValid:   false,
+			DID:       req.DID,
+			UserHash:  req.UserHash,
+			Status:    "hash_mismatch",
+			Message:   "User hash does not match",
+			Assurance: domain.AssuranceLocalDB,
 		}, nil
 	}
 
-	// Verify on blockchain
-	isValid, err := s.blockchain.VerifyDID(req.DID)
-	if err != nil {
-		log.Printf("Blockchain verification failed: %v", err)
-		// Return local verification result if blockchain is unavailable
+	// A suspension is local; the chain still lists the DID as valid
+	if didRecord.Status == string(domain.DIDStatusSuspended) {
+		return &domain.DIDVerificationResponse{
+			IsValid:      false,
+			DID:          req.DID,
+			UserHash:     req.UserHash,
+			Status:       didRecord.Status,
+			Message:      "DID is suspended",
+			BlockchainTx: didRecord.BlockchainTx,
+			Assurance:    domain.AssuranceLocalDB,
+		}, nil
+	}
+
+	// Verify claims against the stored commitment when provided
+	if req.Name != "" || req.Email != "" {
+		if s.claimsAttempts != nil && !s.claimsAttempts.Allow(didRecord.Did) {
+			log.Printf("AUDIT: claims check of DID %s refused over its attempt limit", didRecord.Did)
+			return nil, domain.ErrClaimsAttemptsExceeded
+		}
+		commitment := &did.Commitment{
+			Scheme:        didRecord.HashScheme,
+			Params:        didRecord.HashParams,
+			Value:         didRecord.UserHash,
+			PepperVersion: didRecord.PepperVersion,
+		}
+		matches, err := s.didGen.VerifyClaims(commitment, req.Name, req.Email)
+		if err != nil {
+			message := "Failed to verify claims: " + err.Error()
+			switch {
+			case errors.Is(err, did.ErrCommitmentNotRecomputable):
+				message = "Claims cannot be verified for DIDs using the legacy " + didRecord.HashScheme + " hash scheme"
+			case errors.Is(err, did.ErrNoCommitment):
+				message = "Claims cannot be verified for anonymous DIDs"
+			}
+			return &domain.DIDVerificationResponse{
+				IsValid:   false,
+				DID:       req.DID,
+				UserHash:  req.UserHash,
+				Status:    "claims_unverifiable",
+				Message:   message,
+				Assurance: domain.AssuranceLocalDB,
+			}, nil
+		}
+		if !matches {
+			return &domain.DIDVerificationResponse{
+				IsValid:   false,
+				DID:       req.DID,
+				UserHash:  req.UserHash,
+				Status:    "claims_mismatch",
+				Message:   "Claims do not match the DID commitment",
+				Assurance: domain.AssuranceLocalDB,
+			}, nil
+		}
+		s.backfillEmailIndex(didRecord, req.Email)
+	}
+
+	// Sandbox DIDs are never on-chain; their anchoring is simulated
+	if didRecord.SandboxTenant != "" {
 		return &domain.DIDVerificationResponse{
 			IsValid:      didRecord.Status == string(domain.DIDStatusActive),
 			DID:          req.DID,
 			UserHash:     req.UserHash,
 			Status:       didRecord.Status,
-			Message:      "Blockchain verification failed, using local status",
+			Message:      "Sandbox DID verification completed against its simulated anchoring",
 			BlockchainTx: didRecord.BlockchainTx,
+			Assurance:    domain.AssuranceLocalDB,
 		}, nil
 	}
 
-	// Update local status if blockchain verification succeeds
-	if isValid && didRecord.Status != string(domain.DIDStatusActive) {
-		didRecord.Status = string(domain.DIDStatusActive)
-		didRecord.UpdatedAt = time.Now()
-		if err := s.didRepo.Update(didRecord); err != nil {
-			log.Printf("Warning: failed to update DID status: %v", err)
+	// DIDs anchored in a Sidetree batch are not in the registry contract; the receipt
+	// of their batch is the proof of anchoring
+	if s.sidetree != nil {
+		receipts, err := s.sidetree.ListReceipts(req.DID)
+		if err != nil {
+			log.Printf("Failed to look up Sidetree receipts: %v", err)
+		} else if len(receipts) > 0 {
+			return &domain.DIDVerificationResponse{
+				IsValid:      didRecord.Status == string(domain.DIDStatusActive),
+				DID:          req.DID,
+				UserHash:     req.UserHash,
+				Status:       didRecord.Status,
+				Message:      "DID verification completed against its Sidetree batch",
+				BlockchainTx: didRecord.BlockchainTx,
+				Assurance:    domain.AssuranceLocalDB,
+			}, nil
 		}
 	}
 
-	return &domain.DIDVerificationResponse{
-		IsValid:      isValid,
+	// The verification policy decides what happens without the chain
+	fallback := domain.VerificationFailOpen
+	if s.verificationPolicies != nil {
+		fallback = s.verificationPolicies.Fallback(req.Tenant, req.Endpoint)
+	}
+	local := &domain.DIDVerificationResponse{
+		IsValid:      didRecord.Status == string(domain.DIDStatusActive),
 		DID:          req.DID,
 		UserHash:     req.UserHash,
 		Status:       didRecord.Status,
-		Message:      "DID verification completed",
+		Message:      "DID verification completed against the local record",
 		BlockchainTx: didRecord.BlockchainTx,
-	}, nil
+		Assurance:    domain.AssuranceLocalDB,
+	}
+	if fallback == domain.VerificationLocalOnly {
+		return local, nil
+	}
+
+	// Verify on blockchain
+	check, chainErr := s.blockchain.Verify(req.DID, didRecord.BlockchainTx)
+	if chainErr != nil {
+		log.Printf("Blockchain verification failed: %v", chainErr)
+		if fallback == domain.VerificationFailClosed {
+			return nil, fmt.Errorf("%w: %v", domain.ErrChainUnreachable, chainErr)
+		}
+		local.Message = "Blockchain verification failed, using local status"
+		local.Warning = "The blockchain could not be reached; the result is based on the local record only"
+		return local, nil
+	}
+
+	// Update local status if blockchain verification succeeds
+	if check.Valid && didRecord.Status != string(domain.DIDStatusActive) && !deactivated(didRecord) {
+		didRecord.Status = string(domain.DIDStatusActive)
+		didRecord.UpdatedAt = s.clock.Now()
+		if err := s.didRepo.Update(didRecord); err != nil {
+			log.Printf("Warning: failed to update DID status: %v", err)
+		}
+	}
+
+	blockTimestamp := check.BlockTimestamp
+	response := &domain.DIDVerificationResponse{
+		IsValid:        check.Valid,
+		DID:            req.DID,
+		UserHash:       req.UserHash,
+		Status:         didRecord.Status,
+		Message:        "DID verification completed",
+		BlockchainTx:   didRecord.BlockchainTx,
+		Assurance:      domain.AssuranceChainUnconfirmed,
+		BlockNumber:    check.BlockNumber,
+		BlockTimestamp: &blockTimestamp,
+	}
+
+	// The depth of the DID's transaction tells how final the result is
+	if didRecord.BlockchainTx != "" {
+		confirmations, mined, err := s.blockchain.Confirmations(didRecord.BlockchainTx, check.BlockNumber)
+		if err != nil {
+			log.Printf("Failed to get confirmations of %s: %v", didRecord.BlockchainTx, err)
+		} else if mined {
+			response.Assurance = domain.AssuranceChainConfirmed(confirmations)
+			response.Confirmations = confirmations
+		}
+	}
+
+	return response, nil
+}
+
+// backfillEmailIndex indexes the email address of a DID whose claims just verified.
+// The address is not stored, so DIDs created before email indexing only become
+// discoverable by email once their holder proves it.
+func (s *DIDService) backfillEmailIndex(record *domain.DID, email string) {
+	if s.emailIndex == nil || email == "" || record.EmailIndex != "" {
+		return
+	}
+	if err := s.didRepo.SetEmailIndex(record.ID, s.emailIndex.Email(email)); err != nil {
+		log.Printf("Failed to index email of DID %s: %v", record.Did, err)
+	}
 }
 
 // GetDIDByUserID retrieves a DID by user ID
@@ -187,24 +733,158 @@ func (s *DIDService) GetDIDByUserID(userID uuid.UUID) (*domain.DID, error) {
 }
 
 // UpdateDIDStatus updates the status of a DID
-func (s *DIDService) UpdateDIDStatus(didID uuid.UUID, status string, txHash string) error {
+func (s *DIDService) UpdateDIDStatus(didID uuid.UUID, status domain.DIDStatus, txHash string) error {
 	return s.didRepo.UpdateStatus(didID, status, txHash)
 }
 
-// ProcessBlockchainQueue processes pending blockchain jobs
+// DeactivateDID deactivates a DID: it stops accepting updates right away and resolves as
+// deactivated once the deactivation marker is anchored on-chain. callerDID is the
+// DID-authenticated caller, which must be the DID itself, or empty for administrators.
+// Organization DIDs are deactivated by their controllers through a revoke_did operation.
+func (s *DIDService) DeactivateDID(didString, callerDID string) (*domain.DIDDeactivationResponse, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+	if callerDID != "" {
+		if callerDID != record.Did {
+			return nil, fmt.Errorf("%w: only %s can deactivate itself", domain.ErrForbidden, record.Did)
+		}
+		if record.ApprovalThreshold > 0 {
+			return nil, fmt.Errorf("%w: organization DIDs are deactivated through a revoke_did operation", domain.ErrForbidden)
+		}
+	}
+	if !deactivated(record) && record.Status != string(domain.DIDStatusActive) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	job, err := deactivateDID(s.didRepo, s.queueRepo, s.queue, record)
+	if err != nil {
+		return nil, err
+	}
+
+	caller := callerDID
+	if caller == "" {
+		caller = "admin"
+	}
+	log.Printf("AUDIT: DID %s deactivated by %s; anchoring the marker with job %s", record.Did, caller, job.ID)
+
+	return &domain.DIDDeactivationResponse{
+		DID:    record.Did,
+		Status: string(domain.DIDStatusDeactivating),
+		JobID:  job.ID,
+	}, nil
+}
+
+// ListSidetreeReceipts retrieves the receipts of a DID's operations anchored in
+// Sidetree batches
+func (s *DIDService) ListSidetreeReceipts(did string) ([]*domain.SidetreeReceipt, error) {
+	if s.sidetree == nil {
+		return nil, domain.ErrSidetreeDisabled
+	}
+
+	receipts, err := s.sidetree.ListReceipts(did)
+	if err != nil {
+		return nil, err
+	}
+	if receipts == nil {
+		receipts = []*domain.SidetreeReceipt{}
+	}
+	return receipts, nil
+}
+
+// GetAnchorProof proves a DID's anchoring in the same shape whichever backend anchored
+// it. A DID anchored in a Sidetree batch is proven by the batch's transaction and the
+// inclusion of its latest operation in the batch.
+func (s *DIDService) GetAnchorProof(didString string) (*domain.AnchorProof, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+	if record.BlockchainTx == "" {
+		return nil, fmt.Errorf("%w: %s", domain.ErrDIDNotAnchored, record.Did)
+	}
+
+	proof := &domain.AnchorProof{DID: record.Did, TransactionID: record.BlockchainTx}
+	// Sandbox DIDs are never on-chain; their anchoring is simulated
+	if record.SandboxTenant != "" {
+		proof.Chain = domain.AnchorChainSandbox
+		proof.Timestamp = &record.UpdatedAt
+		proof.Simulated = true
+		return proof, nil
+	}
+
+	if s.sidetree != nil {
+		receipts, err := s.sidetree.ListReceipts(record.Did)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up Sidetree receipts: %w", err)
+		}
+		if len(receipts) > 0 {
+			receipt := receipts[len(receipts)-1]
+			proof.TransactionID = receipt.Batch.TxHash
+			proof.Inclusion = &domain.AnchorInclusion{
+				Operation:        receipt.Operation,
+				OperationIndex:   receipt.OperationIndex,
+				OperationCount:   receipt.Batch.OperationCount,
+				AnchorString:     receipt.Batch.AnchorString,
+				CoreIndexFileURI: receipt.Batch.CoreIndexFileURI,
+				ChunkFileURI:     receipt.Batch.ChunkFileURI,
+				AnchorFileHash:   receipt.Batch.AnchorFileHash,
+			}
+		}
+	}
+
+	if err := unavailable(s.blockchain); err != nil {
+		return nil, err
+	}
+	anchoring, err := s.blockchain.Anchoring(proof.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction %s: %w", proof.TransactionID, err)
+	}
+	if anchoring == nil {
+		return nil, fmt.Errorf("%w: transaction %s is not on-chain", domain.ErrDIDNotAnchored, proof.TransactionID)
+	}
+	proof.Chain = anchoring.Chain
+	proof.Network = anchoring.Network
+	proof.Anchor = anchoring.Anchor
+	proof.BlockNumber = anchoring.BlockNumber
+	proof.Timestamp = &anchoring.Timestamp
+	proof.Confirmations = anchoring.Confirmations
+	return proof, nil
+}
+
+// ProcessBlockchainQueue processes pending blockchain jobs, except those left to the
+// Sidetree batcher. Offline, it fails with ErrBlockchainUnavailable and leaves the jobs
+// pending rather than failing them; while anchoring is paused, it fails with
+// ErrAnchoringPaused.
 func (s *DIDService) ProcessBlockchainQueue() error {
-	// Get pending jobs
-	jobs, err := s.queueRepo.GetPendingJobs(10) // Process 10 jobs at a time
+	if err := unavailable(s.blockchain); err != nil {
+		return err
+	}
+	if s.AnchoringPaused() {
+		return domain.ErrAnchoringPaused
+	}
+
+	// Get pending jobs, 10 at a time
+	getPending := s.queueRepo.GetPendingJobs
+	if s.batching() {
+		getPending = s.queueRepo.GetPendingUnbatchedJobs
+	}
+	jobs, err := getPending(10)
 	if err != nil {
 		return fmt.Errorf("failed to get pending jobs: %w", err)
 	}
 
 	for _, job := range jobs {
-		if err := s.processJob(job); err != nil {
+		if err := s.processJobRecovered(job); err != nil {
+			// Another worker claimed the job, or it was canceled, since it was listed
+			if errors.Is(err, domain.ErrJobNotClaimable) {
+				continue
+			}
 			log.Printf("Failed to process job %s: %v", job.ID, err)
 
 			// Update job status to failed
-			if err := s.queueRepo.UpdateStatus(job.ID, string(domain.JobStatusFailed), err.Error()); err != nil {
+			if err := s.queueRepo.UpdateStatus(job.ID, domain.JobStatusFailed, err.Error()); err != nil {
 				log.Printf("Failed to update job status: %v", err)
 			}
 		}
@@ -213,37 +893,108 @@ func (s *DIDService) ProcessBlockchainQueue() error {
 	return nil
 }
 
+// processJobRecovered processes a job, failing it with an error when it panics so the
+// rest of the batch is still processed. The panic is reported with the job's ID.
+func (s *DIDService) processJobRecovered(job *domain.BlockchainJob) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			s.reporter.Report(errreport.Panic(value, map[string]string{
+				errreport.TagComponent: "blockchain-worker",
+				errreport.TagJobID:     job.ID.String(),
+			}))
+			err = fmt.Errorf("job processing panicked: %v", value)
+		}
+	}()
+	return s.processJob(job)
+}
+
 // processJob processes a single blockchain job
 func (s *DIDService) processJob(job *domain.BlockchainJob) error {
-	// Update job status to processing
-	if err := s.queueRepo.UpdateStatus(job.ID, string(domain.JobStatusProcessing), ""); err != nil {
-		return fmt.Errorf("failed to update job status: %w", err)
+	// Claim the job, unless it was claimed or canceled since it was listed
+	if err := s.queueRepo.Claim(job.ID); err != nil {
+		return err
 	}
 
-	var txHash string
-	var err error
+	if job.DryRun || s.dryRun {
+		return s.simulateJob(job)
+	}
 
-	// Process based on job type
-	switch job.JobType {
-	case string(domain.JobTypeRegisterDID):
-		txHash, err = s.blockchain.RegisterDID(job.UserHash, job.DID)
-	case string(domain.JobTypeUpdateDID):
-		txHash, err = s.blockchain.UpdateDID(job.UserHash, job.DID)
-	default:
-		return fmt.Errorf("unknown job type: %s", job.JobType)
+	// Operations on sandbox DIDs complete with a simulated transaction
+	if record, err := s.didRepo.GetByID(job.DIDID); err == nil && record.SandboxTenant != "" {
+		return s.completeJob(job, sandboxTxHash(job.ID.String()))
 	}
 
+	op, err := jobOperation(job)
+	if err != nil {
+		return err
+	}
+	txHash, err := s.blockchain.Submit(submissionContext(s.queueRepo, job.ID), op)
 	if err != nil {
 		return fmt.Errorf("blockchain operation failed: %w", err)
 	}
 
-	// Update DID status to active
-	if err := s.didRepo.UpdateStatus(job.DIDID, string(domain.DIDStatusActive), txHash); err != nil {
-		return fmt.Errorf("failed to update DID status: %w", err)
+	return s.completeJob(job, txHash)
+}
+
+// jobOperation returns the operation a job anchors. Notarization jobs carry the
+// document hash in place of a user hash.
+func jobOperation(job *domain.BlockchainJob) (*blockchain.Operation, error) {
+	op := &blockchain.Operation{
+		Type:     blockchain.OperationType(job.JobType),
+		DID:      job.DID,
+		UserHash: job.UserHash,
+	}
+	switch domain.JobType(job.JobType) {
+	case domain.JobTypeRegisterDID, domain.JobTypeUpdateDID, domain.JobTypeRevokeDID:
+	case domain.JobTypeNotarize:
+		op.UserHash, op.DocumentHash = "", job.UserHash
+	default:
+		return nil, fmt.Errorf("unknown job type: %s", job.JobType)
+	}
+	return op, nil
+}
+
+// sandboxTxHash derives the simulated transaction hash of a sandbox operation
+func sandboxTxHash(seed string) string {
+	hash := sha256.Sum256([]byte("sandbox:" + seed))
+	return "0x" + hex.EncodeToString(hash[:])
+}
+
+// batching reports whether DID operation jobs are anchored in Sidetree batches. A
+// global dry run simulates every job one by one instead.
+func (s *DIDService) batching() bool {
+	return s.sidetree != nil && !s.dryRun
+}
+
+// completeJob records the transaction that carried out a job on its DID or
+// notarization and marks the job completed
+func (s *DIDService) completeJob(job *domain.BlockchainJob, txHash string) error {
+	switch job.JobType {
+	case string(domain.JobTypeNotarize):
+		// Record the anchoring transaction on the notarization
+		if err := s.notarizationRepo.MarkAnchored(job.ID, txHash, s.clock.Now()); err != nil {
+			return fmt.Errorf("failed to update notarization: %w", err)
+		}
+	case string(domain.JobTypeRevokeDID):
+		if err := s.didRepo.UpdateStatus(job.DIDID, domain.DIDStatusRevoked, txHash); err != nil {
+			return fmt.Errorf("failed to update DID status: %w", err)
+		}
+	default:
+		// Update DID status to active, unless the DID started deactivating or was
+		// suspended while an update was being anchored
+		status := domain.DIDStatusActive
+		if job.JobType == string(domain.JobTypeUpdateDID) {
+			if record, err := s.didRepo.GetByID(job.DIDID); err == nil && (deactivated(record) || record.Status == string(domain.DIDStatusSuspended)) {
+				status = domain.DIDStatus(record.Status)
+			}
+		}
+		if err := s.didRepo.UpdateStatus(job.DIDID, status, txHash); err != nil {
+			return fmt.Errorf("failed to update DID status: %w", err)
+		}
 	}
 
 	// Mark job as completed
-	if err := s.queueRepo.MarkCompleted(job.ID); err != nil {
+	if err := s.queueRepo.MarkCompleted(job.ID, txHash); err != nil {
 		return fmt.Errorf("failed to mark job completed: %w", err)
 	}
 
@@ -251,7 +1002,198 @@ func (s *DIDService) processJob(job *domain.BlockchainJob) error {
 	return nil
 }
 
-// GetDIDRepo returns the DID repository for direct access (debug purposes)
-func (s *DIDService) GetDIDRepo() domain.DIDRepository {
-	return s.didRepo
+// simulateJob simulates a job on-chain and records the outcome on the job without
+// touching the DID
+func (s *DIDService) simulateJob(job *domain.BlockchainJob) error {
+	op, err := jobOperation(job)
+	if err != nil {
+		return err
+	}
+	simulation, err := s.blockchain.Simulate(op)
+	if err != nil {
+		return fmt.Errorf("blockchain simulation failed: %w", err)
+	}
+
+	result := &domain.JobSimulation{
+		GasEstimate:  simulation.GasEstimate,
+		GasLimit:     simulation.GasLimit,
+		Reverted:     simulation.Reverted,
+		RevertReason: simulation.RevertReason,
+		SimulatedAt:  s.clock.Now(),
+	}
+	if simulation.GasPrice != nil {
+		result.GasPrice = simulation.GasPrice.String()
+	}
+	if err := s.queueRepo.RecordSimulation(job.ID, result); err != nil {
+		return fmt.Errorf("failed to record simulation: %w", err)
+	}
+	job.Status = string(domain.JobStatusSimulated)
+	job.Simulation = result
+
+	log.Printf("Simulated job %s (%s): gas %d, reverted %t", job.ID, job.JobType, result.GasEstimate, result.Reverted)
+	return nil
+}
+
+// SimulateJob simulates a blockchain operation on an existing DID, returning the job
+// with the recorded outcome. The job is created already claimed so the background
+// worker does not pick it up.
+func (s *DIDService) SimulateJob(req *domain.JobSimulateRequest) (*domain.BlockchainJob, error) {
+	if err := unavailable(s.blockchain); err != nil {
+		return nil, err
+	}
+
+	record, err := s.didRepo.GetByID(req.DIDID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	job := &domain.BlockchainJob{
+		ID:         uuid.New(),
+		JobType:    string(req.JobType),
+		DIDID:      record.ID,
+		UserHash:   record.UserHash,
+		DID:        record.Did,
+		Status:     string(domain.JobStatusProcessing),
+		MaxRetries: 0,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		DryRun:     true,
+	}
+	if err := s.queueRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create blockchain job: %w", err)
+	}
+
+	if err := s.simulateJob(job); err != nil {
+		if updateErr := s.queueRepo.UpdateStatus(job.ID, domain.JobStatusFailed, err.Error()); updateErr != nil {
+			log.Printf("Failed to update job status: %v", updateErr)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJob returns a blockchain job, including the simulation of dry-run jobs
+func (s *DIDService) GetJob(id uuid.UUID) (*domain.BlockchainJob, error) {
+	return s.queueRepo.GetByID(id)
+}
+
+// ListDIDs returns a page of DIDs for administrators, newest first unless sorted
+// otherwise. The private keys of custodial DIDs are left out.
+func (s *DIDService) ListDIDs(req *domain.DIDListRequest) (*domain.DIDListResponse, error) {
+	if req.Status != "" && !req.Status.Valid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidDIDStatus, req.Status)
+	}
+	page, limit := pageAndLimit(req.Page, req.Limit)
+
+	dids, total, err := s.didRepo.List(req, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if dids == nil {
+		dids = []*domain.DID{}
+	}
+	for _, record := range dids {
+		if record.Custodial {
+			record.PublicKey = ""
+		}
+	}
+
+	return &domain.DIDListResponse{
+		DIDs:  dids,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// ListJobs returns a page of blockchain jobs, newest first unless sorted otherwise
+func (s *DIDService) ListJobs(req *domain.JobListRequest) (*domain.JobListResponse, error) {
+	if req.Status != "" && !req.Status.Valid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidJobStatus, req.Status)
+	}
+	if req.JobType != "" && !req.JobType.Valid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidJobType, req.JobType)
+	}
+	page, limit := pageAndLimit(req.Page, req.Limit)
+
+	jobs, total, err := s.queueRepo.List(req, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []*domain.BlockchainJob{}
+	}
+
+	return &domain.JobListResponse{
+		Jobs:  jobs,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// pageAndLimit applies the defaults of paged listings: the first page of 50 results
+func pageAndLimit(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 50
+	}
+	return page, limit
+}
+
+// deactivated reports whether a DID is deactivated or its deactivation marker is being
+// anchored; either way it accepts no further updates
+func deactivated(record *domain.DID) bool {
+	return record.Status == string(domain.DIDStatusRevoked) || record.Status == string(domain.DIDStatusDeactivating)
+}
+
+// deactivateDID marks a DID deactivating and enqueues the revoke_did job anchoring its
+// deactivation marker. The job marks the DID revoked once the marker is on-chain.
+func deactivateDID(didRepo domain.DIDRepository, jobRepo domain.BlockchainJobRepository, q JobPublisher, record *domain.DID) (*domain.BlockchainJob, error) {
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+	if err := didRepo.UpdateStatus(record.ID, domain.DIDStatusDeactivating, record.BlockchainTx); err != nil {
+		return nil, fmt.Errorf("failed to update DID status: %w", err)
+	}
+	record.Status = string(domain.DIDStatusDeactivating)
+	return enqueueDIDJob(jobRepo, q, domain.JobTypeRevokeDID, record)
+}
+
+// enqueueDIDJob records a blockchain job for a DID and publishes it to the queue; while
+// the queue is offline, the job is only picked up by the database-backed worker. Updates
+// of deactivated and suspended DIDs are rejected. Jobs name the identifier anchored for
+// the DID, its alias once migrated from a legacy identifier.
+func enqueueDIDJob(jobRepo domain.BlockchainJobRepository, q JobPublisher, jobType domain.JobType, record *domain.DID) (*domain.BlockchainJob, error) {
+	if jobType == domain.JobTypeUpdateDID && deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+	if jobType == domain.JobTypeUpdateDID && record.Status == string(domain.DIDStatusSuspended) {
+		return nil, fmt.Errorf("%w: %s is suspended", domain.ErrForbidden, record.Did)
+	}
+
+	now := time.Now()
+	job := &domain.BlockchainJob{
+		ID:         uuid.New(),
+		JobType:    string(jobType),
+		DIDID:      record.ID,
+		UserHash:   record.UserHash,
+		DID:        record.Did,
+		Status:     string(domain.JobStatusPending),
+		RetryCount: 0,
+		MaxRetries: 3,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create blockchain job: %w", err)
+	}
+
+	publishJob(jobRepo, q, job)
+
+	return job, nil
 }
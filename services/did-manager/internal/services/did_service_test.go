@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+func TestVerifyDIDLimitsClaimsAttempts(t *testing.T) {
+	// Legacy commitments are not recomputable, so the claims checks do not hash
+	dids := &fakeDIDs{records: map[string]*domain.DID{
+		"did:example:1": {ID: uuid.New(), Did: "did:example:1", HashScheme: did.HashSchemeSHA256},
+		"did:example:2": {ID: uuid.New(), Did: "did:example:2", HashScheme: did.HashSchemeSHA256},
+	}}
+	service := NewDIDService(dids, nil, did.NewGenerator(), nil, nil, nil, nil)
+	service.SetClaimsAttemptLimit(1, 2)
+
+	verify := func(didID string) error {
+		_, err := service.VerifyDID(&domain.DIDVerificationRequest{DID: didID, Name: "John Doe", Email: "john@example.com"})
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := verify("did:example:1"); err != nil {
+			t.Fatalf("attempt %d failed: %v", i+1, err)
+		}
+	}
+	if err := verify("did:example:1"); !errors.Is(err, domain.ErrClaimsAttemptsExceeded) {
+		t.Errorf("expected ErrClaimsAttemptsExceeded past the burst, got %v", err)
+	}
+	if err := verify("did:example:2"); err != nil {
+		t.Errorf("expected other DIDs' attempts unaffected, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/vault"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxDocumentURLTTL bounds the lifetime of download URLs
+	maxDocumentURLTTL = 24 * time.Hour
+	// maxDocumentNameLength bounds document file names
+	maxDocumentNameLength = 255
+)
+
+// DocumentService keeps documents associated with DIDs and credentials, such as
+// diploma PDFs, encrypted in an object store. DID-authenticated owners upload them and
+// attach them to the credentials they issue; owners and the subjects of those
+// credentials get expiring, signed download URLs to hand to relying parties.
+type DocumentService struct {
+	repo  domain.DocumentRepository
+	vault *vault.Vault
+	// urlSecret keys the signatures of download URLs
+	urlSecret []byte
+	// baseURL is the public URL download URLs point at
+	baseURL string
+	urlTTL  time.Duration
+	maxSize int64
+}
+
+// NewDocumentService creates a new document service. Without a vault, document
+// operations fail with ErrDocumentVaultDisabled.
+func NewDocumentService(repo domain.DocumentRepository, documentVault *vault.Vault, urlSecret []byte, baseURL string, urlTTL time.Duration, maxSize int64) *DocumentService {
+	return &DocumentService{
+		repo:      repo,
+		vault:     documentVault,
+		urlSecret: urlSecret,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		urlTTL:    urlTTL,
+		maxSize:   maxSize,
+	}
+}
+
+// MaxSize is the largest document accepted
+func (s *DocumentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload encrypts a document into the vault on behalf of the calling DID
+func (s *DocumentService) Upload(caller *domain.Caller, name, contentType string, content []byte) (*domain.Document, error) {
+	if s.vault == nil {
+		return nil, domain.ErrDocumentVaultDisabled
+	}
+	if caller.Type != domain.CallerTypeDID {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "" || name == "." || name == "/" || len(name) > maxDocumentNameLength || strings.ContainsAny(name, "\"\r\n") {
+		return nil, fmt.Errorf("%w: invalid file name", domain.ErrInvalidDocument)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid content type", domain.ErrInvalidDocument)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: document is empty", domain.ErrInvalidDocument)
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, fmt.Errorf("%w: document exceeds %d bytes", domain.ErrInvalidDocument, s.maxSize)
+	}
+
+	digest := sha256.Sum256(content)
+	document := &domain.Document{
+		ID:          uuid.New(),
+		OwnerDID:    caller.ID,
+		Name:        name,
+		ContentType: mediaType,
+		Size:        int64(len(content)),
+		Digest:      hex.EncodeToString(digest[:]),
+		CreatedAt:   time.Now(),
+	}
+
+	document.KeyID, err = s.vault.Put(context.Background(), document.StorageName(), content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+	if err := s.repo.Create(document); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: document %s (%s, %d bytes) uploaded by %s", document.ID, document.ContentType, document.Size, caller.ID)
+
+	return document, nil
+}
+
+// ListDocuments lists the documents uploaded by the calling DID
+func (s *DocumentService) ListDocuments(caller *domain.Caller) ([]*domain.Document, error) {
+	if caller.Type != domain.CallerTypeDID {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	documents, err := s.repo.ListByOwner(caller.ID)
+	if err != nil {
+		return nil, err
+	}
+	if documents == nil {
+		documents = []*domain.Document{}
+	}
+	return documents, nil
+}
+
+// GetDocument returns the metadata of a document the calling DID may access
+func (s *DocumentService) GetDocument(caller *domain.Caller, id uuid.UUID) (*domain.Document, error) {
+	return s.accessible(caller, id)
+}
+
+// CreateURL issues an expiring download URL for a document the calling DID may access
+func (s *DocumentService) CreateURL(caller *domain.Caller, id uuid.UUID, req *domain.DocumentURLRequest) (*domain.DocumentURL, error) {
+	if s.vault == nil {
+		return nil, domain.ErrDocumentVaultDisabled
+	}
+	document, err := s.accessible(caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.urlTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	ttl = min(ttl, maxDocumentURLTTL)
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.urlSignature(document.ID, expires)},
+	}
+	log.Printf("AUDIT: download URL for document %s issued to %s until %s", document.ID, caller.ID, expiresAt.Format(time.RFC3339))
+
+	return &domain.DocumentURL{
+		URL:       fmt.Sprintf("%s/api/v1/documents/%s/content?%s", s.baseURL, document.ID, query.Encode()),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Download decrypts a document for a signed download URL that has not expired. The
+// content is checked against the digest credentials reference it by.
+func (s *DocumentService) Download(id uuid.UUID, expires, signature string) (*domain.Document, []byte, error) {
+	if s.vault == nil {
+		return nil, nil, domain.ErrDocumentVaultDisabled
+	}
+
+	expected := s.urlSignature(id, expires)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, nil, domain.ErrDocumentURLInvalid
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().After(time.Unix(unix, 0)) {
+		return nil, nil, domain.ErrDocumentURLInvalid
+	}
+
+	document, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.vault.Get(context.Background(), document.StorageName(), document.KeyID)
+	if err != nil {
+		if errors.Is(err, vault.ErrNotFound) {
+			return nil, nil, domain.ErrDocumentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load document: %w", err)
+	}
+
+	digest := sha256.Sum256(content)
+	if hex.EncodeToString(digest[:]) != document.Digest {
+		return nil, nil, fmt.Errorf("document %s does not match its digest", document.ID)
+	}
+	return document, content, nil
+}
+
+// DeleteDocument removes one of the calling DID's documents from the vault. Documents
+// attached to a credential are kept, as the credential references them.
+func (s *DocumentService) DeleteDocument(caller *domain.Caller, id uuid.UUID) error {
+	if s.vault == nil {
+		return domain.ErrDocumentVaultDisabled
+	}
+	document, err := s.owned(caller, id)
+	if err != nil {
+		return err
+	}
+
+	attached, err := s.repo.IsAttached(id)
+	if err != nil {
+		return err
+	}
+	if attached {
+		return domain.ErrDocumentAttached
+	}
+
+	if err := s.vault.Delete(context.Background(), document.StorageName()); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	log.Printf("AUDIT: document %s deleted by %s", id, caller.ID)
+
+	return nil
+}
+
+// owned loads a document uploaded by the calling DID. Other DIDs' documents are
+// reported as not found.
+func (s *DocumentService) owned(caller *domain.Caller, id uuid.UUID) (*domain.Document, error) {
+	if caller.Type != domain.CallerTypeDID {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	document, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if document.OwnerDID != caller.ID {
+		return nil, domain.ErrDocumentNotFound
+	}
+	return document, nil
+}
+
+// accessible loads a document the calling DID uploaded or that is attached to an
+// active credential issued to it. Other documents are reported as not found.
+func (s *DocumentService) accessible(caller *domain.Caller, id uuid.UUID) (*domain.Document, error) {
+	if caller.Type != domain.CallerTypeDID {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	document, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if document.OwnerDID == caller.ID {
+		return document, nil
+	}
+
+	attached, err := s.repo.IsAttachedForSubject(id, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !attached {
+		return nil, domain.ErrDocumentNotFound
+	}
+	return document, nil
+}
+
+// urlSignature signs a document ID and expiry for a download URL
+func (s *DocumentService) urlSignature(id uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, s.urlSecret)
+	mac.Write([]byte(id.String() + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
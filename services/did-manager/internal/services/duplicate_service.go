@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// ParseDuplicateRules parses rule actions of the form "email=review,claims=reject"
+func ParseDuplicateRules(spec string) (map[domain.DuplicateRule]domain.DuplicateAction, error) {
+	rules := make(map[domain.DuplicateRule]domain.DuplicateAction)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid duplicate rule %q: expected rule=off|review|reject", entry)
+		}
+		rule := domain.DuplicateRule(strings.TrimSpace(name))
+		if rule != domain.DuplicateRuleEmail && rule != domain.DuplicateRuleClaims {
+			return nil, fmt.Errorf("unknown duplicate rule: %s", rule)
+		}
+		action := domain.DuplicateAction(strings.TrimSpace(value))
+		switch action {
+		case domain.DuplicateActionOff, domain.DuplicateActionReview, domain.DuplicateActionReject:
+		default:
+			return nil, fmt.Errorf("invalid action for duplicate rule %s: %s", rule, action)
+		}
+		rules[rule] = action
+	}
+	return rules, nil
+}
+
+// DuplicateService detects new DIDs that likely duplicate an existing identity of
+// another user. Every DID's email and claims fingerprints are recorded; a match under
+// a rule either rejects the new DID or holds it for manual review. DIDs created
+// before detection was introduced have no fingerprints and are never matched.
+// Fingerprints are keyed with the pepper, so during a pepper rotation they are
+// recorded and matched under the previous version too; being derived from claims,
+// they cannot be re-hashed, and DIDs fingerprinted only under a version no longer
+// looked up are not matched.
+type DuplicateService struct {
+	repo   domain.DuplicateRepository
+	didGen *did.Generator
+	rules  map[domain.DuplicateRule]domain.DuplicateAction
+}
+
+// duplicateCheck carries the outcome of a check through to recording the new DID
+type duplicateCheck struct {
+	// fingerprints are by pepper version, then rule
+	fingerprints map[string]map[domain.DuplicateRule]string
+	// reviewReasons describe matches under rules holding the DID for review
+	reviewReasons []string
+}
+
+// NewDuplicateService creates a new duplicate service; rules without an action are off
+func NewDuplicateService(repo domain.DuplicateRepository, didGen *did.Generator, rules map[domain.DuplicateRule]domain.DuplicateAction) *DuplicateService {
+	return &DuplicateService{
+		repo:   repo,
+		didGen: didGen,
+		rules:  rules,
+	}
+}
+
+// check fingerprints the requested identity and matches it against existing DIDs,
+// returning ErrDuplicateIdentity when a rejecting rule matches and the reasons for
+// review when a reviewing rule does
+func (s *DuplicateService) check(req *domain.DIDCreateRequest) (*duplicateCheck, error) {
+	// Anonymous DIDs have no identity to match
+	if s == nil || req.Anonymous {
+		return nil, nil
+	}
+
+	result := &duplicateCheck{fingerprints: make(map[string]map[domain.DuplicateRule]string)}
+	for _, version := range s.didGen.LookupVersions() {
+		fingerprints, err := s.didGen.IdentityFingerprintsAt(version, req.Name, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		result.fingerprints[version] = map[domain.DuplicateRule]string{
+			domain.DuplicateRuleEmail:  fingerprints[did.FingerprintEmail],
+			domain.DuplicateRuleClaims: fingerprints[did.FingerprintClaims],
+		}
+	}
+
+	// Claims matches are stronger and also match on email, so they are checked first and
+	// each existing DID is reported once
+	reported := make(map[uuid.UUID]bool)
+	for _, rule := range []domain.DuplicateRule{domain.DuplicateRuleClaims, domain.DuplicateRuleEmail} {
+		action := s.rules[rule]
+		if action == "" || action == domain.DuplicateActionOff {
+			continue
+		}
+
+		var fingerprints []string
+		for _, byRule := range result.fingerprints {
+			fingerprints = append(fingerprints, byRule[rule])
+		}
+		matches, err := s.repo.FindMatches(rule, fingerprints, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		if action == domain.DuplicateActionReject {
+			log.Printf("AUDIT: DID creation for user %s rejected: %s matches %s", req.UserID, rule, matches[0].Did)
+			return nil, fmt.Errorf("%w: %s matches an existing DID", domain.ErrDuplicateIdentity, rule)
+		}
+		for _, match := range matches {
+			if !reported[match.DIDID] {
+				reported[match.DIDID] = true
+				result.reviewReasons = append(result.reviewReasons,
+					fmt.Sprintf("duplicate detection: %s matches %s", match.Rule, match.Did))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// record stores the new DID's fingerprints. Failures are logged rather than failing a
+// DID that already exists.
+func (s *DuplicateService) record(record *domain.DID, result *duplicateCheck) {
+	if s == nil || result == nil {
+		return
+	}
+
+	for version, fingerprints := range result.fingerprints {
+		if err := s.repo.RecordFingerprints(record.ID, version, fingerprints); err != nil {
+			log.Printf("Warning: failed to record identity fingerprints for %s: %v", record.Did, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// EmailLookupService tells relying parties whether an email address already has a DID,
+// e.g. to invite its holder to present it rather than create a new one. Addresses are
+// matched by their blind index, never stored or compared in plaintext, and only DIDs
+// the caller may resolve are disclosed, so the lookup does not expose the directory.
+type EmailLookupService struct {
+	didRepo domain.DIDRepository
+	access  *AccessService
+	index   *did.BlindIndex
+}
+
+// NewEmailLookupService creates a new email lookup service; a nil index disables lookups
+func NewEmailLookupService(didRepo domain.DIDRepository, access *AccessService, index *did.BlindIndex) *EmailLookupService {
+	return &EmailLookupService{
+		didRepo: didRepo,
+		access:  access,
+		index:   index,
+	}
+}
+
+// Lookup looks up the DID of an email address for an API key caller. DIDs created
+// before email indexing are only found once their claims have been verified.
+func (s *EmailLookupService) Lookup(caller *domain.Caller, req *domain.EmailLookupRequest) (*domain.EmailLookupResponse, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+	// Lookups serve relying parties; holders have no business probing other addresses
+	if caller.Type != domain.CallerTypeAPIKey {
+		return nil, domain.ErrForbidden
+	}
+	if s.index == nil {
+		return nil, domain.ErrEmailLookupDisabled
+	}
+
+	record, err := s.didRepo.GetByEmailIndex(s.index.Email(req.Email))
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			log.Printf("AUDIT: email lookup by %s caller %s found no DID", caller.Type, caller.ID)
+			return &domain.EmailLookupResponse{}, nil
+		}
+		return nil, err
+	}
+
+	allowed, err := s.access.CanResolve(record, caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check access: %w", err)
+	}
+	if !allowed {
+		log.Printf("AUDIT: email lookup by %s caller %s matched private DID %s, reported as not found", caller.Type, caller.ID, record.Did)
+		return &domain.EmailLookupResponse{}, nil
+	}
+
+	log.Printf("AUDIT: email lookup by %s caller %s found DID %s", caller.Type, caller.ID, record.Did)
+	return &domain.EmailLookupResponse{Exists: true, DID: record.Did}, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// EncryptionService encrypts payloads to the keyAgreement key of a DID as ECDH-ES
+// JWEs, so that only the DID's holder can read them: for internal services through the
+// encrypt-to-DID endpoint, and for webhook endpoints that ask for encrypted payloads.
+// Only DIDs with purpose keys have a keyAgreement key.
+type EncryptionService struct {
+	access      *AccessService
+	purposeKeys *PurposeKeyService
+}
+
+// NewEncryptionService creates a new encryption service
+func NewEncryptionService(access *AccessService, purposeKeys *PurposeKeyService) *EncryptionService {
+	return &EncryptionService{
+		access:      access,
+		purposeKeys: purposeKeys,
+	}
+}
+
+// EncryptToDID encrypts a JSON payload to a DID for an API key caller, i.e. another
+// service, which must be able to resolve the DID
+func (s *EncryptionService) EncryptToDID(caller *domain.Caller, req *domain.EncryptToDIDRequest) (*domain.EncryptedPayload, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+	if caller.Type != domain.CallerTypeAPIKey {
+		return nil, fmt.Errorf("%w: encrypting to DIDs is reserved for services", domain.ErrForbidden)
+	}
+
+	encrypted, err := s.Encrypt(caller, req.DID, "json", req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: payload encrypted to %s for %s caller %s", encrypted.DID, caller.Type, caller.ID)
+	return encrypted, nil
+}
+
+// Encrypt encrypts plaintext of a content type to the keyAgreement key of a DID the
+// caller may resolve
+func (s *EncryptionService) Encrypt(caller *domain.Caller, didString, contentType string, plaintext []byte) (*domain.EncryptedPayload, error) {
+	key, err := s.recipient(caller, didString)
+	if err != nil {
+		return nil, err
+	}
+
+	jwe, err := did.EncryptJWE(key.material, key.method, contentType, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt to %s: %w", didString, err)
+	}
+	return &domain.EncryptedPayload{DID: didString, KeyID: key.method, JWE: jwe}, nil
+}
+
+// CheckRecipient reports whether payloads can be encrypted to a DID for the caller,
+// returning the error Encrypt would fail with otherwise
+func (s *EncryptionService) CheckRecipient(caller *domain.Caller, didString string) error {
+	_, err := s.recipient(caller, didString)
+	return err
+}
+
+// recipient returns the keyAgreement key of an active DID the caller may resolve
+func (s *EncryptionService) recipient(caller *domain.Caller, didString string) (*didKey, error) {
+	record, err := s.access.CheckDIDAccess(didString, caller)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+	return s.purposeKeys.agreementKey(record)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// MaxEndorsementGraphDepth bounds how many hops a graph query follows from its root
+const MaxEndorsementGraphDepth = 3
+
+// EndorsementMessage is the message an endorser signs: the JSON object
+// {"endorser":...,"subject":...,"type":...,"statement":...} with fields in that order
+func EndorsementMessage(endorserDID, subjectDID, endorsementType, statement string) []byte {
+	message, _ := json.Marshal(struct {
+		Endorser  string `json:"endorser"`
+		Subject   string `json:"subject"`
+		Type      string `json:"type"`
+		Statement string `json:"statement"`
+	}{endorserDID, subjectDID, endorsementType, statement})
+	return message
+}
+
+// EndorsementService records signed endorsements between DIDs and queries the
+// resulting graph
+type EndorsementService struct {
+	endorsementRepo domain.EndorsementRepository
+	didRepo         domain.DIDRepository
+	registry        *did.Registry
+}
+
+// NewEndorsementService creates a new endorsement service
+func NewEndorsementService(endorsementRepo domain.EndorsementRepository, didRepo domain.DIDRepository, registry *did.Registry) *EndorsementService {
+	return &EndorsementService{
+		endorsementRepo: endorsementRepo,
+		didRepo:         didRepo,
+		registry:        registry,
+	}
+}
+
+// Endorse verifies the caller DID's signature over an endorsement and stores it.
+// Repeating an identical endorsement returns the existing one.
+func (s *EndorsementService) Endorse(callerDID string, req *domain.EndorsementCreateRequest) (*domain.Endorsement, error) {
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != string(domain.DIDStatusActive) && record.Status != string(domain.DIDStatusPending) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+	if req.SubjectDID == record.Did {
+		return nil, fmt.Errorf("%w: a DID cannot endorse itself", domain.ErrForbidden)
+	}
+
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", domain.ErrInvalidSignature)
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	message := EndorsementMessage(record.Did, req.SubjectDID, req.Type, req.Statement)
+	valid, err := s.registry.VerifySignature(record.KeyAlgorithm, keyMaterial, message, signature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	existing, err := s.endorsementRepo.GetActive(record.Did, req.SubjectDID, req.Type)
+	if err == nil {
+		if existing.Statement == req.Statement {
+			return existing, nil
+		}
+		return nil, domain.ErrEndorsementExists
+	}
+	if !errors.Is(err, domain.ErrEndorsementNotFound) {
+		return nil, err
+	}
+
+	endorsement := &domain.Endorsement{
+		ID:                 uuid.New(),
+		EndorserDID:        record.Did,
+		SubjectDID:         req.SubjectDID,
+		Type:               req.Type,
+		Statement:          req.Statement,
+		Signature:          strings.ToLower(req.Signature),
+		KeyAlgorithm:       record.KeyAlgorithm,
+		VerificationMethod: record.Did + "#key-1",
+		CreatedAt:          time.Now(),
+	}
+	if err := s.endorsementRepo.Create(endorsement); err != nil {
+		return nil, err
+	}
+
+	return endorsement, nil
+}
+
+// RevokeEndorsement withdraws an endorsement made by the caller DID
+func (s *EndorsementService) RevokeEndorsement(callerDID string, id uuid.UUID) (*domain.Endorsement, error) {
+	endorsement, err := s.endorsementRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if endorsement.EndorserDID != callerDID {
+		return nil, domain.ErrEndorsementNotFound
+	}
+	if endorsement.RevokedAt != nil {
+		return endorsement, nil
+	}
+
+	now := time.Now()
+	if err := s.endorsementRepo.Revoke(id, now); err != nil {
+		return nil, err
+	}
+	endorsement.RevokedAt = &now
+	return endorsement, nil
+}
+
+// GetEndorsement returns an endorsement
+func (s *EndorsementService) GetEndorsement(id uuid.UUID) (*domain.Endorsement, error) {
+	return s.endorsementRepo.GetByID(id)
+}
+
+// ListEndorsements lists endorsements matching a filter
+func (s *EndorsementService) ListEndorsements(filter *domain.EndorsementFilter) ([]*domain.Endorsement, error) {
+	endorsements, err := s.endorsementRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	if endorsements == nil {
+		endorsements = []*domain.Endorsement{}
+	}
+	return endorsements, nil
+}
+
+// Graph returns the active endorsements within depth hops of root, following
+// endorsements in both directions
+func (s *EndorsementService) Graph(root string, depth int) (*domain.EndorsementGraph, error) {
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > MaxEndorsementGraphDepth {
+		depth = MaxEndorsementGraphDepth
+	}
+
+	graph := &domain.EndorsementGraph{
+		Root:  root,
+		Nodes: []string{root},
+		Edges: []*domain.Endorsement{},
+	}
+	visited := map[string]bool{root: true}
+	seenEdges := make(map[uuid.UUID]bool)
+
+	frontier := []string{root}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		endorsements, err := s.endorsementRepo.ListTouching(frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for _, endorsement := range endorsements {
+			if !seenEdges[endorsement.ID] {
+				seenEdges[endorsement.ID] = true
+				graph.Edges = append(graph.Edges, endorsement)
+			}
+			for _, node := range []string{endorsement.EndorserDID, endorsement.SubjectDID} {
+				if !visited[node] {
+					visited[node] = true
+					graph.Nodes = append(graph.Nodes, node)
+					next = append(next, node)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ExportService gathers the data held about a user for data-subject access requests
+type ExportService struct {
+	didRepo        domain.DIDRepository
+	credentialRepo domain.CredentialRepository
+	aclRepo        domain.ACLRepository
+	queueRepo      domain.BlockchainJobRepository
+	resolver       *ResolverService
+	// archive holds the jobs moved out of the queue; nil when jobs are not archived
+	archive domain.JobArchiveRepository
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	didRepo domain.DIDRepository,
+	credentialRepo domain.CredentialRepository,
+	aclRepo domain.ACLRepository,
+	queueRepo domain.BlockchainJobRepository,
+	resolver *ResolverService,
+) *ExportService {
+	return &ExportService{
+		didRepo:        didRepo,
+		credentialRepo: credentialRepo,
+		aclRepo:        aclRepo,
+		queueRepo:      queueRepo,
+		resolver:       resolver,
+	}
+}
+
+// SetJobArchive includes archived jobs in the exported job history
+func (s *ExportService) SetJobArchive(archive domain.JobArchiveRepository) {
+	s.archive = archive
+}
+
+// ExportUser exports a user's DIDs with their documents, credentials, access grants
+// and blockchain job history. A user without DIDs exports an empty list.
+func (s *ExportService) ExportUser(userID uuid.UUID) (*domain.UserDataExport, error) {
+	records, err := s.didRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &domain.UserDataExport{
+		UserID:      userID,
+		GeneratedAt: time.Now(),
+		DIDs:        make([]*domain.DIDExport, 0, len(records)),
+	}
+	for _, record := range records {
+		entry, err := s.exportDID(record)
+		if err != nil {
+			return nil, err
+		}
+		export.DIDs = append(export.DIDs, entry)
+	}
+
+	log.Printf("AUDIT: exported data of user %s (%d DIDs)", userID, len(export.DIDs))
+	return export, nil
+}
+
+// exportDID gathers the data attached to one of a user's DIDs
+func (s *ExportService) exportDID(record *domain.DID) (*domain.DIDExport, error) {
+	// A DID may always resolve itself, whatever its visibility
+	document, err := s.resolver.Resolve(record.Did, &domain.Caller{Type: domain.CallerTypeDID, ID: record.Did})
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.credentialRepo.ListBySubjects([]string{record.Did})
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.aclRepo.ListByDIDID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.queueRepo.ListByDIDID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var trail []*domain.JobAuditEntry
+	// Archived jobs finished before the jobs still in the queue
+	if s.archive != nil {
+		archived, err := s.archive.ListByDIDID(record.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range archived {
+			trail = append(trail, job.Record)
+		}
+	}
+	for _, job := range jobs {
+		phases, err := s.queueRepo.ListPhases(job.ID)
+		if err != nil {
+			return nil, err
+		}
+		if phases == nil {
+			phases = []*domain.JobPhaseEvent{}
+		}
+		trail = append(trail, &domain.JobAuditEntry{Job: job, Phases: phases})
+	}
+
+	if record.Custodial {
+		record.PublicKey = ""
+	}
+	if credentials == nil {
+		credentials = []*domain.Credential{}
+	}
+	if grants == nil {
+		grants = []*domain.ACLEntry{}
+	}
+	if trail == nil {
+		trail = []*domain.JobAuditEntry{}
+	}
+
+	return &domain.DIDExport{
+		Record:       record,
+		Document:     document,
+		Credentials:  credentials,
+		AccessGrants: grants,
+		AuditTrail:   trail,
+	}, nil
+}
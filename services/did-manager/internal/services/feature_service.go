@@ -0,0 +1,224 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// ParseFeatureFlags parses configured flag defaults of the form "flag=true,flag=false"
+func ParseFeatureFlags(spec string) (map[domain.FeatureFlag]bool, error) {
+	flags := make(map[domain.FeatureFlag]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature flag %q: expected flag=true|false", entry)
+		}
+		flag := domain.FeatureFlag(strings.TrimSpace(name))
+		if _, known := domain.FeatureDescriptions[flag]; !known {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownFeature, flag)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for feature flag %s: %w", flag, err)
+		}
+		flags[flag] = enabled
+	}
+	return flags, nil
+}
+
+// FeatureService resolves feature flags from configured defaults and database
+// overrides. Overrides are cached and reloaded once older than the refresh interval,
+// so changes made through any instance take effect without a redeploy.
+type FeatureService struct {
+	repo     domain.FeatureFlagRepository
+	defaults map[domain.FeatureFlag]bool
+	// registry follows the global state of FeaturePQSignatures for callers that do not
+	// resolve flags per tenant
+	registry        *did.Registry
+	refreshInterval time.Duration
+
+	mu sync.RWMutex
+	// overrides maps flags to their overrides by tenant, the empty tenant being global
+	overrides map[domain.FeatureFlag]map[string]bool
+	loadedAt  time.Time
+}
+
+// NewFeatureService creates a new feature service
+func NewFeatureService(
+	repo domain.FeatureFlagRepository,
+	defaults map[domain.FeatureFlag]bool,
+	registry *did.Registry,
+	refreshInterval time.Duration,
+) *FeatureService {
+	return &FeatureService{
+		repo:            repo,
+		defaults:        defaults,
+		registry:        registry,
+		refreshInterval: refreshInterval,
+		overrides:       make(map[domain.FeatureFlag]map[string]bool),
+	}
+}
+
+// Enabled reports whether a flag is enabled for a tenant; an empty tenant resolves
+// the global state
+func (s *FeatureService) Enabled(flag domain.FeatureFlag, tenant string) bool {
+	return s.State(flag, tenant).Enabled
+}
+
+// State resolves a flag for a tenant: a tenant override wins over a global override,
+// which wins over the configured default
+func (s *FeatureService) State(flag domain.FeatureFlag, tenant string) *domain.FeatureState {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resolve(flag, tenant)
+}
+
+// States resolves every known flag for a tenant, ordered by name
+func (s *FeatureService) States(tenant string) []*domain.FeatureState {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]*domain.FeatureState, 0, len(domain.FeatureDescriptions))
+	for flag := range domain.FeatureDescriptions {
+		states = append(states, s.resolve(flag, tenant))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Flag < states[j].Flag })
+	return states
+}
+
+// Overrides lists every stored override
+func (s *FeatureService) Overrides() ([]*domain.FeatureOverride, error) {
+	overrides, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if overrides == nil {
+		overrides = []*domain.FeatureOverride{}
+	}
+	return overrides, nil
+}
+
+// SetOverride overrides a flag globally or for a tenant
+func (s *FeatureService) SetOverride(flag domain.FeatureFlag, req *domain.FeatureOverrideRequest) (*domain.FeatureOverride, error) {
+	if _, known := domain.FeatureDescriptions[flag]; !known {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownFeature, flag)
+	}
+
+	override := &domain.FeatureOverride{
+		Flag:      flag,
+		Tenant:    req.Tenant,
+		Enabled:   *req.Enabled,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Upsert(override); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: feature %s set to %t for tenant %q", flag, override.Enabled, override.Tenant)
+	s.reload()
+	return override, nil
+}
+
+// DeleteOverride removes an override, restoring the next source in precedence
+func (s *FeatureService) DeleteOverride(flag domain.FeatureFlag, tenant string) error {
+	if _, known := domain.FeatureDescriptions[flag]; !known {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownFeature, flag)
+	}
+	if err := s.repo.Delete(flag, tenant); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: feature %s override removed for tenant %q", flag, tenant)
+	s.reload()
+	return nil
+}
+
+// Refresh reloads overrides from the database
+func (s *FeatureService) Refresh() error {
+	overrides, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+
+	byFlag := make(map[domain.FeatureFlag]map[string]bool)
+	for _, override := range overrides {
+		if byFlag[override.Flag] == nil {
+			byFlag[override.Flag] = make(map[string]bool)
+		}
+		byFlag[override.Flag][override.Tenant] = override.Enabled
+	}
+
+	s.mu.Lock()
+	s.overrides = byFlag
+	s.loadedAt = time.Now()
+	pqEnabled := s.resolve(domain.FeaturePQSignatures, "").Enabled
+	s.mu.Unlock()
+
+	if s.registry != nil {
+		s.registry.EnableExperimentalSuites(pqEnabled)
+	}
+	return nil
+}
+
+// refreshIfStale reloads overrides once the cached copy is older than the refresh interval
+func (s *FeatureService) refreshIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) >= s.refreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		s.reload()
+	}
+}
+
+// reload refreshes overrides, keeping the cached copy when the database is unavailable
+func (s *FeatureService) reload() {
+	if err := s.Refresh(); err != nil {
+		log.Printf("Failed to refresh feature overrides: %v", err)
+		// Retry after the next interval rather than on every lookup
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// resolve resolves a flag from the cached overrides; callers hold s.mu
+func (s *FeatureService) resolve(flag domain.FeatureFlag, tenant string) *domain.FeatureState {
+	state := &domain.FeatureState{
+		Flag:        flag,
+		Description: domain.FeatureDescriptions[flag],
+		Enabled:     s.defaults[flag],
+		Source:      domain.FeatureSourceConfig,
+	}
+
+	overrides := s.overrides[flag]
+	if enabled, ok := overrides[""]; ok {
+		state.Enabled = enabled
+		state.Source = domain.FeatureSourceGlobalOverride
+	}
+	if tenant != "" {
+		if enabled, ok := overrides[tenant]; ok {
+			state.Enabled = enabled
+			state.Source = domain.FeatureSourceTenantOverride
+		}
+	}
+
+	return state
+}
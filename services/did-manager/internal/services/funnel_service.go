@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// maxFunnelDays bounds the days of a funnel report
+const maxFunnelDays = 366
+
+// FunnelService measures where DIDs stall between creation and first use: of the DIDs
+// each tenant created on a day, how many were anchored, verified at least once and
+// issued a credential. Days are counted from the usage events, so DIDs created
+// anonymously or before metering are not included.
+type FunnelService struct {
+	repo domain.FunnelRepository
+	// metricsDays is the number of days, up to today, exposed as metrics
+	metricsDays int
+}
+
+// NewFunnelService creates a new funnel service exposing the last metricsDays days as
+// metrics
+func NewFunnelService(repo domain.FunnelRepository, metricsDays int) *FunnelService {
+	return &FunnelService{
+		repo:        repo,
+		metricsDays: max(metricsDays, 1),
+	}
+}
+
+// Report returns the funnel of each tenant, or of one, for the days from from to to
+// inclusive (UTC)
+func (s *FunnelService) Report(from, to time.Time, tenant string) (*domain.FunnelReport, error) {
+	from, to = utcDay(from), utcDay(to)
+	if days := int(to.Sub(from)/(24*time.Hour)) + 1; days > maxFunnelDays {
+		return nil, fmt.Errorf("%w: %d days, at most %d", domain.ErrFunnelRangeTooLong, days, maxFunnelDays)
+	}
+
+	days, err := s.repo.Funnel(from, to.AddDate(0, 0, 1), tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FunnelReport{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		GeneratedAt: time.Now().UTC(),
+		Days:        days,
+	}, nil
+}
+
+// Recent returns the funnel of each tenant for the days exposed as metrics
+func (s *FunnelService) Recent() ([]*domain.FunnelDay, error) {
+	today := utcDay(time.Now())
+	return s.repo.Funnel(today.AddDate(0, 0, 1-s.metricsDays), today.AddDate(0, 0, 1), "")
+}
+
+// utcDay truncates a time to the start of its day in UTC
+func utcDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
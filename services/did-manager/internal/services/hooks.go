@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"did-manager/internal/domain"
+)
+
+// DIDCreationHook runs custom logic around DID creation. BeforeCreateDID rejects the
+// request by returning an error, or holds the new DID for manual review with an error
+// wrapping ErrReviewRequired; AfterCreateDID observes the created DID.
+type DIDCreationHook interface {
+	BeforeCreateDID(req *domain.DIDCreateRequest) error
+	AfterCreateDID(req *domain.DIDCreateRequest, response *domain.DIDResponse)
+}
+
+// DIDVerificationHook runs custom logic around DID verification. BeforeVerifyDID
+// rejects the request by returning an error; AfterVerifyDID observes the result.
+type DIDVerificationHook interface {
+	BeforeVerifyDID(req *domain.DIDVerificationRequest) error
+	AfterVerifyDID(req *domain.DIDVerificationRequest, response *domain.DIDVerificationResponse)
+}
+
+// CredentialIssuanceHook runs custom logic around credential issuance, including
+// organization-approved issuance and automatic renewal. BeforeIssueCredential rejects
+// the request by returning an error, or holds the credential for manual review with an
+// error wrapping ErrReviewRequired; AfterIssueCredential observes the stored credential.
+type CredentialIssuanceHook interface {
+	BeforeIssueCredential(issuer *domain.DID, req *domain.CredentialIssueRequest) error
+	AfterIssueCredential(issuer *domain.DID, credential *domain.Credential)
+}
+
+// Hooks holds the plugins registered at wiring time. Hooks run in registration order,
+// and the first pre hook to fail rejects the operation with ErrRejectedByHook; pre
+// hooks requesting review are collected as the review reasons. A nil *Hooks runs nothing.
+type Hooks struct {
+	didCreation        []DIDCreationHook
+	didVerification    []DIDVerificationHook
+	credentialIssuance []CredentialIssuanceHook
+}
+
+// NewHooks creates an empty hook registry
+func NewHooks() *Hooks {
+	return &Hooks{}
+}
+
+// Register adds a plugin to every hook point whose interface it implements. Hooks
+// are registered before the server starts and are not safe to add concurrently.
+func (h *Hooks) Register(plugin any) error {
+	registered := false
+	if hook, ok := plugin.(DIDCreationHook); ok {
+		h.didCreation = append(h.didCreation, hook)
+		registered = true
+	}
+	if hook, ok := plugin.(DIDVerificationHook); ok {
+		h.didVerification = append(h.didVerification, hook)
+		registered = true
+	}
+	if hook, ok := plugin.(CredentialIssuanceHook); ok {
+		h.credentialIssuance = append(h.credentialIssuance, hook)
+		registered = true
+	}
+
+	if !registered {
+		return fmt.Errorf("plugin %T implements no hook interface", plugin)
+	}
+	return nil
+}
+
+// BeforeCreateDID runs the DID creation pre hooks, returning the reasons of those
+// requesting review
+func (h *Hooks) BeforeCreateDID(req *domain.DIDCreateRequest) ([]string, error) {
+	if h == nil {
+		return nil, nil
+	}
+	var reviewReasons []string
+	for _, hook := range h.didCreation {
+		if err := hook.BeforeCreateDID(req); err != nil {
+			if errors.Is(err, domain.ErrReviewRequired) {
+				reviewReasons = append(reviewReasons, err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("%w: %w", domain.ErrRejectedByHook, err)
+		}
+	}
+	return reviewReasons, nil
+}
+
+// AfterCreateDID runs the DID creation post hooks
+func (h *Hooks) AfterCreateDID(req *domain.DIDCreateRequest, response *domain.DIDResponse) {
+	if h == nil {
+		return
+	}
+	for _, hook := range h.didCreation {
+		hook.AfterCreateDID(req, response)
+	}
+}
+
+// BeforeVerifyDID runs the DID verification pre hooks
+func (h *Hooks) BeforeVerifyDID(req *domain.DIDVerificationRequest) error {
+	if h == nil {
+		return nil
+	}
+	for _, hook := range h.didVerification {
+		if err := hook.BeforeVerifyDID(req); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrRejectedByHook, err)
+		}
+	}
+	return nil
+}
+
+// AfterVerifyDID runs the DID verification post hooks
+func (h *Hooks) AfterVerifyDID(req *domain.DIDVerificationRequest, response *domain.DIDVerificationResponse) {
+	if h == nil {
+		return
+	}
+	for _, hook := range h.didVerification {
+		hook.AfterVerifyDID(req, response)
+	}
+}
+
+// BeforeIssueCredential runs the credential issuance pre hooks, returning the reasons
+// of those requesting review
+func (h *Hooks) BeforeIssueCredential(issuer *domain.DID, req *domain.CredentialIssueRequest) ([]string, error) {
+	if h == nil {
+		return nil, nil
+	}
+	var reviewReasons []string
+	for _, hook := range h.credentialIssuance {
+		if err := hook.BeforeIssueCredential(issuer, req); err != nil {
+			if errors.Is(err, domain.ErrReviewRequired) {
+				reviewReasons = append(reviewReasons, err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("%w: %w", domain.ErrRejectedByHook, err)
+		}
+	}
+	return reviewReasons, nil
+}
+
+// AfterIssueCredential runs the credential issuance post hooks
+func (h *Hooks) AfterIssueCredential(issuer *domain.DID, credential *domain.Credential) {
+	if h == nil {
+		return
+	}
+	for _, hook := range h.credentialIssuance {
+		hook.AfterIssueCredential(issuer, credential)
+	}
+}
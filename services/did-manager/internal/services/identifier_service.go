@@ -0,0 +1,165 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// identifierMigrationBatch is the number of DIDs migrated or anchored per batch
+const identifierMigrationBatch = 500
+
+// IdentifierService migrates DIDs created with legacy identifier formats, such as the
+// truncated hex key, to the current format. Legacy identifiers stay the DIDs'
+// identifiers, which credentials and access lists refer to. Each DID is given its
+// identifier in the current format as an alias resolving to the same DID, listed in its
+// document as alsoKnownAs, and an update_did job anchors the alias on-chain for the
+// DID's user hash.
+type IdentifierService struct {
+	migrations domain.IdentifierMigrationRepository
+	didRepo    domain.DIDRepository
+	jobRepo    domain.BlockchainJobRepository
+	registry   *did.Registry
+	queue      JobPublisher
+}
+
+// NewIdentifierService creates a new identifier service
+func NewIdentifierService(
+	migrations domain.IdentifierMigrationRepository,
+	didRepo domain.DIDRepository,
+	jobRepo domain.BlockchainJobRepository,
+	registry *did.Registry,
+	queue JobPublisher,
+) *IdentifierService {
+	return &IdentifierService{
+		migrations: migrations,
+		didRepo:    didRepo,
+		jobRepo:    jobRepo,
+		registry:   registry,
+		queue:      queue,
+	}
+}
+
+// Migrate records a migration for every legacy DID without one, then enqueues the
+// update jobs anchoring the new identifiers of active DIDs. The new identifier commits
+// to the DID's inception key, so DIDs whose key was rotated since are recorded as
+// unmigratable and keep resolving by their legacy identifier only. Migrations of DIDs
+// that are not active are anchored once they are.
+func (s *IdentifierService) Migrate() (*domain.IdentifierMigrationRun, error) {
+	run := &domain.IdentifierMigrationRun{}
+
+	for {
+		records, err := s.didRepo.ListLegacyUnmigrated(identifierMigrationBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			migration := &domain.IdentifierMigration{
+				DIDID:      record.ID,
+				LegacyDID:  record.Did,
+				FromFormat: did.IdentifierFormat(record.Did),
+				CreatedAt:  time.Now(),
+			}
+			if alias, err := s.alias(record); err != nil {
+				log.Printf("Warning: legacy DID %s cannot be migrated: %v", record.Did, err)
+				migration.Error = err.Error()
+				run.Unmigratable++
+			} else {
+				migration.NewDID = alias
+				migration.ToFormat = did.IdentifierFormat(alias)
+				run.Migrated++
+			}
+			if err := s.migrations.Record(migration); err != nil {
+				return nil, err
+			}
+		}
+		if len(records) < identifierMigrationBatch {
+			break
+		}
+	}
+
+	for {
+		migrations, err := s.migrations.ListUnanchored(identifierMigrationBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, migration := range migrations {
+			record, err := s.didRepo.GetByID(migration.DIDID)
+			if err != nil {
+				return nil, err
+			}
+			job, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeUpdateDID, record)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.migrations.SetJob(migration.DIDID, job.ID); err != nil {
+				return nil, err
+			}
+			run.Anchoring++
+		}
+		if len(migrations) < identifierMigrationBatch {
+			break
+		}
+	}
+
+	if run.Migrated > 0 || run.Unmigratable > 0 || run.Anchoring > 0 {
+		log.Printf("AUDIT: migrated %d legacy DID identifiers, %d unmigratable, %d being anchored", run.Migrated, run.Unmigratable, run.Anchoring)
+	}
+	return run, nil
+}
+
+// alias derives the current identifier of a legacy DID from its stored key
+func (s *IdentifierService) alias(record *domain.DID) (string, error) {
+	suite, err := s.registry.SignatureSuite(record.KeyAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return "", err
+	}
+	return did.MigrateIdentifier(record.Did, suite, publicKey)
+}
+
+// GetMigration returns the identifier migration of a DID, by its legacy identifier or
+// its new one
+func (s *IdentifierService) GetMigration(didString string) (*domain.IdentifierMigration, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		record, err = s.didRepo.GetByAlias(didString)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.migrations.Get(record.ID)
+}
+
+// ListMigrations lists identifier migrations page by page, filtered by status and DID
+func (s *IdentifierService) ListMigrations(query *domain.IdentifierMigrationQuery) (*domain.IdentifierMigrationPage, error) {
+	page, limit := pageAndLimit(query.Page, query.Limit)
+
+	migrations, total, err := s.migrations.List(query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if migrations == nil {
+		migrations = []*domain.IdentifierMigration{}
+	}
+
+	return &domain.IdentifierMigrationPage{
+		Migrations: migrations,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// DIDTenants finds the tenant that created a DID; *repository.UsageRepository
+// implements it from the metered DID creations
+type DIDTenants interface {
+	DIDTenant(did string) (string, error)
+}
+
+// IssuanceRuleService decides which credentials the issuers of each tenant may issue
+// to which subjects, and audit-logs every decision taken under a rule
+type IssuanceRuleService struct {
+	repo    domain.IssuanceRuleRepository
+	didRepo domain.DIDRepository
+	tenants DIDTenants
+}
+
+// NewIssuanceRuleService creates a new issuance rule service
+func NewIssuanceRuleService(repo domain.IssuanceRuleRepository, didRepo domain.DIDRepository, tenants DIDTenants) *IssuanceRuleService {
+	return &IssuanceRuleService{
+		repo:    repo,
+		didRepo: didRepo,
+		tenants: tenants,
+	}
+}
+
+// Authorize checks that issuer may issue the requested credential, returning an error
+// wrapping ErrForbidden and ErrIssuanceNotAllowed when no rule of its tenant allows it.
+// Issuers of tenants without rules issue anything. When the rules cannot be read the
+// issuance is refused.
+func (s *IssuanceRuleService) Authorize(issuer *domain.DID, req *domain.CredentialIssueRequest) error {
+	tenant, err := s.tenantOf(issuer)
+	if err != nil {
+		return err
+	}
+	rules, err := s.repo.ListForTenant(tenant)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	sameTenant, err := s.sameTenant(tenant, req.SubjectDID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.Allows(issuer.Did, req.Type, req.SubjectDID, sameTenant) {
+			log.Printf("AUDIT: issuance of %s by %s (tenant %q) to %s allowed by issuance rule %s", req.Type, issuer.Did, tenant, req.SubjectDID, rule.ID)
+			return nil
+		}
+	}
+
+	log.Printf("AUDIT: issuance of %s by %s (tenant %q) to %s denied: no issuance rule allows it", req.Type, issuer.Did, tenant, req.SubjectDID)
+	return fmt.Errorf("%w: %w", domain.ErrForbidden, domain.ErrIssuanceNotAllowed)
+}
+
+// tenantOf returns the tenant of a DID: its sandbox partition, or the API key that
+// created it. It is empty for DIDs whose creation was not metered.
+func (s *IssuanceRuleService) tenantOf(record *domain.DID) (string, error) {
+	if record.SandboxTenant != "" {
+		return record.SandboxTenant, nil
+	}
+	tenant, err := s.tenants.DIDTenant(record.Did)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant of %s: %w", record.Did, err)
+	}
+	return tenant, nil
+}
+
+// sameTenant reports whether subjectDID is a DID of this service created by tenant
+func (s *IssuanceRuleService) sameTenant(tenant, subjectDID string) (bool, error) {
+	if tenant == "" {
+		return false, nil
+	}
+	subject, err := s.didRepo.GetByDID(subjectDID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	subjectTenant, err := s.tenantOf(subject)
+	if err != nil {
+		return false, err
+	}
+	return subjectTenant == tenant, nil
+}
+
+// ListRules lists every issuance rule
+func (s *IssuanceRuleService) ListRules() ([]*domain.IssuanceRule, error) {
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*domain.IssuanceRule{}
+	}
+	return rules, nil
+}
+
+// AddRule adds an issuance rule. The first rule of a tenant restricts its issuers to
+// what its rules allow.
+func (s *IssuanceRuleService) AddRule(req *domain.IssuanceRuleRequest) (*domain.IssuanceRule, error) {
+	rule := &domain.IssuanceRule{
+		ID:              uuid.New(),
+		Tenant:          req.Tenant,
+		IssuerPrefix:    req.IssuerPrefix,
+		CredentialTypes: req.CredentialTypes,
+		Subjects:        req.Subjects,
+		SubjectPrefixes: req.SubjectPrefixes,
+		CreatedAt:       time.Now(),
+	}
+	if rule.CredentialTypes == nil {
+		rule.CredentialTypes = []string{}
+	}
+	if rule.Subjects != domain.IssuanceSubjectsPrefixes || rule.SubjectPrefixes == nil {
+		rule.SubjectPrefixes = []string{}
+	}
+
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: issuance rule %s added for tenant %q: issuers %q may issue %v to %s subjects %v",
+		rule.ID, rule.Tenant, rule.IssuerPrefix, rule.CredentialTypes, rule.Subjects, rule.SubjectPrefixes)
+	return rule, nil
+}
+
+// DeleteRule removes an issuance rule
+func (s *IssuanceRuleService) DeleteRule(id uuid.UUID) error {
+	rule, err := s.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: issuance rule %s of tenant %q removed", rule.ID, rule.Tenant)
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// jobArchiveBatchSize bounds the jobs moved into the archive per transaction
+const jobArchiveBatchSize = 500
+
+// JobArchiveService moves finished blockchain jobs out of the job queue into a
+// compressed archive instead of deleting them, so the transactions that registered,
+// updated or revoked every DID stay retrievable for compliance after the queue is
+// trimmed.
+type JobArchiveService struct {
+	repo domain.JobArchiveRepository
+	// after is how long a job stays in the queue once finished
+	after time.Duration
+}
+
+// NewJobArchiveService creates a new job archive service archiving jobs finished more
+// than after ago
+func NewJobArchiveService(repo domain.JobArchiveRepository, after time.Duration) *JobArchiveService {
+	return &JobArchiveService{
+		repo:  repo,
+		after: after,
+	}
+}
+
+// RunArchival moves every job finished before the cutoff into the archive, one batch
+// per transaction
+func (s *JobArchiveService) RunArchival() (*domain.JobArchiveRun, error) {
+	run := &domain.JobArchiveRun{Cutoff: time.Now().Add(-s.after)}
+	for {
+		archived, err := s.repo.ArchiveFinished(run.Cutoff, jobArchiveBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		run.Archived += archived
+		if archived < jobArchiveBatchSize {
+			break
+		}
+	}
+
+	if run.Archived > 0 {
+		log.Printf("Archived %d blockchain jobs finished before %s", run.Archived, run.Cutoff.Format(time.RFC3339))
+	}
+	return run, nil
+}
+
+// ListArchivedJobs lists archived jobs page by page, filtered by DID, transaction, type
+// and processing time
+func (s *JobArchiveService) ListArchivedJobs(query *domain.JobArchiveQuery) (*domain.JobArchivePage, error) {
+	if query.JobType != "" && !query.JobType.Valid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidJobType, query.JobType)
+	}
+	page, limit := pageAndLimit(query.Page, query.Limit)
+
+	jobs, total, err := s.repo.List(query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []*domain.ArchivedJob{}
+	}
+
+	return &domain.JobArchivePage{
+		Jobs:  jobs,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// GetArchivedJob returns an archived job with its timeline
+func (s *JobArchiveService) GetArchivedJob(id uuid.UUID) (*domain.ArchivedJob, error) {
+	return s.repo.Get(id)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CancelJob cancels a blockchain job, e.g. when its holder deleted their account before
+// the DID was anchored. A job whose transaction was not broadcast is canceled, and a
+// DID whose registration is canceled is marked revoked without reaching the chain. A
+// broadcast registration or update cannot be withdrawn, so a compensating job revokes
+// the DID on-chain instead.
+func (s *DIDService) CancelJob(id uuid.UUID, reason string) (*domain.JobCancellation, error) {
+	job, err := s.queueRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.queueRepo.Cancel(id, reason)
+	if errors.Is(err, domain.ErrJobNotCancelable) {
+		return s.compensateJob(id, reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: blockchain job %s (%s of %s) canceled before broadcast: %s", job.ID, job.JobType, job.DID, reason)
+
+	if job.JobType == string(domain.JobTypeRegisterDID) && !job.DryRun {
+		s.withdrawRegistration(job)
+	}
+
+	canceled, err := s.queueRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.JobCancellation{Job: canceled, Canceled: true}, nil
+}
+
+// withdrawRegistration marks a DID revoked once its registration is canceled, unless
+// another job anchored it meanwhile; failures are logged since the job is canceled
+// either way
+func (s *DIDService) withdrawRegistration(job *domain.BlockchainJob) {
+	record, err := s.didRepo.GetByID(job.DIDID)
+	if err != nil {
+		log.Printf("Failed to load DID of canceled job %s: %v", job.ID, err)
+		return
+	}
+	if record.Status != string(domain.DIDStatusPending) {
+		return
+	}
+	if err := s.didRepo.UpdateStatus(record.ID, domain.DIDStatusRevoked, ""); err != nil {
+		log.Printf("Failed to revoke DID %s of canceled job %s: %v", record.Did, job.ID, err)
+	}
+}
+
+// compensateJob schedules the revocation of a DID whose registration or update could
+// not be canceled because its transaction was broadcast. Jobs still being processed,
+// already compensated or that cannot be undone fail with ErrJobNotCancelable.
+func (s *DIDService) compensateJob(id uuid.UUID, reason string) (*domain.JobCancellation, error) {
+	job, err := s.queueRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := s.queueRepo.ListPhases(id)
+	if err != nil {
+		return nil, err
+	}
+	broadcast := job.Status == string(domain.JobStatusCompleted)
+	for _, phase := range phases {
+		switch phase.Phase {
+		case domain.JobPhaseSubmitted:
+			broadcast = true
+		case domain.JobPhaseCanceled:
+			return nil, fmt.Errorf("%w: job was already canceled", domain.ErrJobNotCancelable)
+		}
+	}
+
+	switch {
+	case !broadcast && job.Status == string(domain.JobStatusProcessing):
+		return nil, fmt.Errorf("%w: job is being processed; retry once it settles", domain.ErrJobNotCancelable)
+	case !broadcast:
+		return nil, fmt.Errorf("%w: job is %s", domain.ErrJobNotCancelable, job.Status)
+	case job.JobType != string(domain.JobTypeRegisterDID) && job.JobType != string(domain.JobTypeUpdateDID):
+		return nil, fmt.Errorf("%w: the %s transaction was broadcast and cannot be compensated", domain.ErrJobNotCancelable, job.JobType)
+	}
+
+	record, err := s.didRepo.GetByID(job.DIDID)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: DID is already %s", domain.ErrJobNotCancelable, record.Status)
+	}
+
+	compensating, err := deactivateDID(s.didRepo, s.queueRepo, s.queue, record)
+	if err != nil {
+		return nil, err
+	}
+	recordJobPhase(s.queueRepo, job.ID, domain.JobPhaseCanceled, domain.NewSpanID(), "compensated by job "+compensating.ID.String())
+	log.Printf("AUDIT: blockchain job %s (%s of %s) was broadcast; revoking the DID with job %s: %s",
+		job.ID, job.JobType, job.DID, compensating.ID, reason)
+
+	return &domain.JobCancellation{Job: job, CompensatingJob: compensating}, nil
+}
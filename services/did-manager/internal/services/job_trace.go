@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// publishJob publishes a stored job to the queue within its trace and records it
+// published. Publishing is best effort: the database-backed worker picks the job up
+// either way, so nothing is published while the queue is offline.
+func publishJob(jobs domain.BlockchainJobRepository, q JobPublisher, job *domain.BlockchainJob) {
+	if unavailable(q) != nil {
+		return
+	}
+
+	queueJob := &queue.BlockchainJob{
+		ID:        job.ID.String(),
+		JobType:   job.JobType,
+		DIDID:     job.DIDID.String(),
+		UserHash:  job.UserHash,
+		DID:       job.DID,
+		CreatedAt: job.CreatedAt,
+		DryRun:    job.DryRun,
+		TraceID:   job.TraceID,
+		SpanID:    domain.NewSpanID(),
+	}
+
+	if err := q.PublishJob(queueJob); err != nil {
+		log.Printf("Warning: failed to publish job to queue: %v", err)
+		return
+	}
+	recordJobPhase(jobs, job.ID, domain.JobPhasePublished, queueJob.SpanID, "")
+}
+
+// recordJobPhase adds a phase to a job's timeline. The timeline only serves
+// observability, so failing to record it does not fail the job.
+func recordJobPhase(jobs domain.BlockchainJobRepository, id uuid.UUID, phase domain.JobPhase, spanID, detail string) {
+	if err := jobs.RecordPhase(id, phase, spanID, detail); err != nil {
+		log.Printf("Failed to record %s phase of job %s: %v", phase, id, err)
+	}
+}
+
+// submissionContext returns a context recording the submitted phase of the jobs whose
+// transaction is sent under it
+func submissionContext(jobs domain.BlockchainJobRepository, ids ...uuid.UUID) context.Context {
+	return blockchain.WithSubmitObserver(context.Background(), func(txHash string) {
+		spanID := domain.NewSpanID()
+		for _, id := range ids {
+			recordJobPhase(jobs, id, domain.JobPhaseSubmitted, spanID, txHash)
+		}
+	})
+}
+
+// GetJobTimeline returns the phases of a blockchain job with the latency attributed to
+// queueing, submitting the transaction and waiting for it to be mined
+func (s *DIDService) GetJobTimeline(id uuid.UUID) (*domain.JobTimeline, error) {
+	job, err := s.queueRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := s.queueRepo.ListPhases(id)
+	if err != nil {
+		return nil, err
+	}
+	if phases == nil {
+		phases = []*domain.JobPhaseEvent{}
+	}
+
+	return &domain.JobTimeline{
+		JobID:   job.ID,
+		TraceID: job.TraceID,
+		Status:  job.Status,
+		Phases:  phases,
+		Latency: jobLatency(phases),
+	}, nil
+}
+
+// jobLatency attributes the latency of a job to its stages. Enqueueing starts at the
+// first enqueued phase; the other stages end at their last phase, the latest attempt.
+func jobLatency(phases []*domain.JobPhaseEvent) domain.JobLatency {
+	var enqueued, claimed, submitted, confirmed *time.Time
+	for _, phase := range phases {
+		at := phase.OccurredAt
+		switch phase.Phase {
+		case domain.JobPhaseEnqueued:
+			if enqueued == nil {
+				enqueued = &at
+			}
+		case domain.JobPhaseClaimed:
+			claimed = &at
+		case domain.JobPhaseSubmitted:
+			submitted = &at
+		case domain.JobPhaseConfirmed:
+			confirmed = &at
+		}
+	}
+
+	between := func(from, to *time.Time) *int64 {
+		if from == nil || to == nil || to.Before(*from) {
+			return nil
+		}
+		ms := to.Sub(*from).Milliseconds()
+		return &ms
+	}
+
+	return domain.JobLatency{
+		Queued:       between(enqueued, claimed),
+		Submission:   between(claimed, submitted),
+		Confirmation: between(submitted, confirmed),
+		Total:        between(enqueued, confirmed),
+	}
+}
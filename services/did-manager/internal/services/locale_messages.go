@@ -0,0 +1,160 @@
+package services
+
+// messageLocales are the locales end-user messages are translated to, English first as
+// the fallback for untranslated messages
+var messageLocales = []string{"en", "es", "fr", "de", "pt"}
+
+// localeMessages holds the messages shown to end users by locale, then key: push
+// notification templates, and descriptions of DID statuses and of the status page
+var localeMessages = map[string]map[string]string{
+	"en": {
+		"push.credential_offered.title":     "New credential",
+		"push.credential_offered.body":      "A new credential has been issued to your wallet.",
+		"push.presentation_requested.title": "Presentation requested",
+		"push.presentation_requested.body":  "A verifier is requesting credentials from your wallet.",
+		"push.credential_revoked.title":     "Credential revoked",
+		"push.credential_revoked.body":      "One of your credentials has been revoked by its issuer.",
+		"push.credential_expiring.title":    "Credential expiring",
+		"push.credential_expiring.body":     "One of your credentials expires soon.",
+
+		"did.status.pending":             "Your digital identity is being registered.",
+		"did.status.active":              "Your digital identity is active.",
+		"did.status.revoked":             "This digital identity has been deactivated.",
+		"did.status.expired":             "This digital identity has expired.",
+		"did.status.failed":              "Registering this digital identity failed.",
+		"did.status.deactivating":        "This digital identity is being deactivated.",
+		"did.status.pending_review":      "Your digital identity is awaiting review.",
+		"did.status.rejected":            "This digital identity was rejected in review.",
+		"did.status.suspended":           "This digital identity is temporarily suspended.",
+		"did.status.not_found":           "This digital identity was not found.",
+		"did.status.hash_mismatch":       "The details provided do not match this digital identity.",
+		"did.status.claims_mismatch":     "The name or email provided does not match this digital identity.",
+		"did.status.claims_unverifiable": "The name and email of this digital identity cannot be checked.",
+
+		"service.status.operational":    "All systems are operational.",
+		"service.status.maintenance":    "Scheduled maintenance is in progress.",
+		"service.status.degraded":       "Some services are running slower than usual.",
+		"service.status.partial_outage": "Some services are unavailable.",
+		"service.status.major_outage":   "Services are unavailable.",
+	},
+	"es": {
+		"push.credential_offered.title":     "Nueva credencial",
+		"push.credential_offered.body":      "Se ha emitido una nueva credencial a tu billetera.",
+		"push.presentation_requested.title": "Presentación solicitada",
+		"push.presentation_requested.body":  "Un verificador solicita credenciales de tu billetera.",
+		"push.credential_revoked.title":     "Credencial revocada",
+		"push.credential_revoked.body":      "Su emisor ha revocado una de tus credenciales.",
+		"push.credential_expiring.title":    "Credencial por vencer",
+		"push.credential_expiring.body":     "Una de tus credenciales vence pronto.",
+
+		"did.status.pending":             "Tu identidad digital se está registrando.",
+		"did.status.active":              "Tu identidad digital está activa.",
+		"did.status.revoked":             "Esta identidad digital ha sido desactivada.",
+		"did.status.expired":             "Esta identidad digital ha caducado.",
+		"did.status.failed":              "No se pudo registrar esta identidad digital.",
+		"did.status.deactivating":        "Esta identidad digital se está desactivando.",
+		"did.status.pending_review":      "Tu identidad digital está pendiente de revisión.",
+		"did.status.rejected":            "Esta identidad digital fue rechazada en la revisión.",
+		"did.status.suspended":           "Esta identidad digital está suspendida temporalmente.",
+		"did.status.not_found":           "No se encontró esta identidad digital.",
+		"did.status.hash_mismatch":       "Los datos proporcionados no coinciden con esta identidad digital.",
+		"did.status.claims_mismatch":     "El nombre o el correo proporcionado no coincide con esta identidad digital.",
+		"did.status.claims_unverifiable": "No se pueden comprobar el nombre y el correo de esta identidad digital.",
+
+		"service.status.operational":    "Todos los sistemas funcionan con normalidad.",
+		"service.status.maintenance":    "Hay un mantenimiento programado en curso.",
+		"service.status.degraded":       "Algunos servicios funcionan más lento de lo habitual.",
+		"service.status.partial_outage": "Algunos servicios no están disponibles.",
+		"service.status.major_outage":   "Los servicios no están disponibles.",
+	},
+	"fr": {
+		"push.credential_offered.title":     "Nouvelle attestation",
+		"push.credential_offered.body":      "Une nouvelle attestation a été ajoutée à votre portefeuille.",
+		"push.presentation_requested.title": "Présentation demandée",
+		"push.presentation_requested.body":  "Un vérificateur demande des attestations de votre portefeuille.",
+		"push.credential_revoked.title":     "Attestation révoquée",
+		"push.credential_revoked.body":      "L'une de vos attestations a été révoquée par son émetteur.",
+		"push.credential_expiring.title":    "Attestation bientôt expirée",
+		"push.credential_expiring.body":     "L'une de vos attestations expire bientôt.",
+
+		"did.status.pending":             "Votre identité numérique est en cours d'enregistrement.",
+		"did.status.active":              "Votre identité numérique est active.",
+		"did.status.revoked":             "Cette identité numérique a été désactivée.",
+		"did.status.expired":             "Cette identité numérique a expiré.",
+		"did.status.failed":              "L'enregistrement de cette identité numérique a échoué.",
+		"did.status.deactivating":        "Cette identité numérique est en cours de désactivation.",
+		"did.status.pending_review":      "Votre identité numérique est en attente de vérification.",
+		"did.status.rejected":            "Cette identité numérique a été refusée lors de la vérification.",
+		"did.status.suspended":           "Cette identité numérique est temporairement suspendue.",
+		"did.status.not_found":           "Cette identité numérique est introuvable.",
+		"did.status.hash_mismatch":       "Les informations fournies ne correspondent pas à cette identité numérique.",
+		"did.status.claims_mismatch":     "Le nom ou l'e-mail fourni ne correspond pas à cette identité numérique.",
+		"did.status.claims_unverifiable": "Le nom et l'e-mail de cette identité numérique ne peuvent pas être vérifiés.",
+
+		"service.status.operational":    "Tous les systèmes fonctionnent normalement.",
+		"service.status.maintenance":    "Une maintenance programmée est en cours.",
+		"service.status.degraded":       "Certains services sont plus lents que d'habitude.",
+		"service.status.partial_outage": "Certains services sont indisponibles.",
+		"service.status.major_outage":   "Les services sont indisponibles.",
+	},
+	"de": {
+		"push.credential_offered.title":     "Neuer Nachweis",
+		"push.credential_offered.body":      "Ein neuer Nachweis wurde in deine Wallet ausgestellt.",
+		"push.presentation_requested.title": "Nachweis angefordert",
+		"push.presentation_requested.body":  "Ein Prüfer fordert Nachweise aus deiner Wallet an.",
+		"push.credential_revoked.title":     "Nachweis widerrufen",
+		"push.credential_revoked.body":      "Einer deiner Nachweise wurde vom Aussteller widerrufen.",
+		"push.credential_expiring.title":    "Nachweis läuft ab",
+		"push.credential_expiring.body":     "Einer deiner Nachweise läuft bald ab.",
+
+		"did.status.pending":             "Deine digitale Identität wird registriert.",
+		"did.status.active":              "Deine digitale Identität ist aktiv.",
+		"did.status.revoked":             "Diese digitale Identität wurde deaktiviert.",
+		"did.status.expired":             "Diese digitale Identität ist abgelaufen.",
+		"did.status.failed":              "Die Registrierung dieser digitalen Identität ist fehlgeschlagen.",
+		"did.status.deactivating":        "Diese digitale Identität wird deaktiviert.",
+		"did.status.pending_review":      "Deine digitale Identität wartet auf Prüfung.",
+		"did.status.rejected":            "Diese digitale Identität wurde bei der Prüfung abgelehnt.",
+		"did.status.suspended":           "Diese digitale Identität ist vorübergehend gesperrt.",
+		"did.status.not_found":           "Diese digitale Identität wurde nicht gefunden.",
+		"did.status.hash_mismatch":       "Die angegebenen Daten passen nicht zu dieser digitalen Identität.",
+		"did.status.claims_mismatch":     "Der angegebene Name oder die E-Mail-Adresse passt nicht zu dieser digitalen Identität.",
+		"did.status.claims_unverifiable": "Name und E-Mail-Adresse dieser digitalen Identität können nicht geprüft werden.",
+
+		"service.status.operational":    "Alle Systeme laufen normal.",
+		"service.status.maintenance":    "Geplante Wartungsarbeiten laufen.",
+		"service.status.degraded":       "Einige Dienste sind langsamer als gewöhnlich.",
+		"service.status.partial_outage": "Einige Dienste sind nicht verfügbar.",
+		"service.status.major_outage":   "Die Dienste sind nicht verfügbar.",
+	},
+	"pt": {
+		"push.credential_offered.title":     "Nova credencial",
+		"push.credential_offered.body":      "Uma nova credencial foi emitida para a sua carteira.",
+		"push.presentation_requested.title": "Apresentação solicitada",
+		"push.presentation_requested.body":  "Um verificador está solicitando credenciais da sua carteira.",
+		"push.credential_revoked.title":     "Credencial revogada",
+		"push.credential_revoked.body":      "Uma das suas credenciais foi revogada pelo emissor.",
+		"push.credential_expiring.title":    "Credencial a expirar",
+		"push.credential_expiring.body":     "Uma das suas credenciais expira em breve.",
+
+		"did.status.pending":             "A sua identidade digital está sendo registrada.",
+		"did.status.active":              "A sua identidade digital está ativa.",
+		"did.status.revoked":             "Esta identidade digital foi desativada.",
+		"did.status.expired":             "Esta identidade digital expirou.",
+		"did.status.failed":              "O registro desta identidade digital falhou.",
+		"did.status.deactivating":        "Esta identidade digital está sendo desativada.",
+		"did.status.pending_review":      "A sua identidade digital aguarda revisão.",
+		"did.status.rejected":            "Esta identidade digital foi rejeitada na revisão.",
+		"did.status.suspended":           "Esta identidade digital está temporariamente suspensa.",
+		"did.status.not_found":           "Esta identidade digital não foi encontrada.",
+		"did.status.hash_mismatch":       "Os dados informados não correspondem a esta identidade digital.",
+		"did.status.claims_mismatch":     "O nome ou o e-mail informado não corresponde a esta identidade digital.",
+		"did.status.claims_unverifiable": "Não é possível verificar o nome e o e-mail desta identidade digital.",
+
+		"service.status.operational":    "Todos os sistemas estão operacionais.",
+		"service.status.maintenance":    "Há uma manutenção programada em andamento.",
+		"service.status.degraded":       "Alguns serviços estão mais lentos que o normal.",
+		"service.status.partial_outage": "Alguns serviços estão indisponíveis.",
+		"service.status.major_outage":   "Os serviços estão indisponíveis.",
+	},
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"did-manager/internal/domain"
+
+	"packages/i18n"
+
+	"github.com/google/uuid"
+)
+
+// LocaleService picks the locale of messages shown to end users, such as status
+// descriptions and push notifications, and translates them. A request's
+// Accept-Language wins over the default locale of the tenant calling, which wins over
+// the server's default locale.
+type LocaleService struct {
+	catalog       *i18n.Catalog
+	apiKeyRepo    domain.APIKeyRepository
+	defaultLocale string
+}
+
+// NewLocaleService creates a new locale service whose messages default to defaultLocale,
+// which must be supported
+func NewLocaleService(apiKeyRepo domain.APIKeyRepository, defaultLocale string) (*LocaleService, error) {
+	catalog, err := i18n.NewCatalog(localeMessages, messageLocales...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &LocaleService{
+		catalog:    catalog,
+		apiKeyRepo: apiKeyRepo,
+	}
+	if s.defaultLocale, err = s.Supported(defaultLocale); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Locales lists the supported locales
+func (s *LocaleService) Locales() []string {
+	return s.catalog.Locales()
+}
+
+// DefaultLocale returns the server's default locale
+func (s *LocaleService) DefaultLocale() string {
+	return s.defaultLocale
+}
+
+// Supported returns the supported locale serving a locale, such as "pt" for "pt-BR",
+// or ErrUnsupportedLocale
+func (s *LocaleService) Supported(locale string) (string, error) {
+	supported, err := s.catalog.Supported(locale)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q, supported locales are %v", domain.ErrUnsupportedLocale, locale, s.catalog.Locales())
+	}
+	return supported, nil
+}
+
+// Resolve returns the locale of messages for a request with an Accept-Language header,
+// falling back to the default locale of the caller's tenant, then the server's
+func (s *LocaleService) Resolve(acceptLanguage string, caller *domain.Caller) string {
+	if locale, ok := s.catalog.Match(acceptLanguage); ok {
+		return locale
+	}
+	if caller == nil || caller.Type != domain.CallerTypeAPIKey {
+		return s.defaultLocale
+	}
+
+	id, err := uuid.Parse(caller.ID)
+	if err != nil {
+		return s.defaultLocale
+	}
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		if !errors.Is(err, domain.ErrAPIKeyNotFound) {
+			log.Printf("Warning: failed to load default locale of tenant %s: %v", caller.ID, err)
+		}
+		return s.defaultLocale
+	}
+	return s.Or(key.DefaultLocale)
+}
+
+// Or returns a stored locale, or the server's default locale when it is empty
+func (s *LocaleService) Or(locale string) string {
+	if locale == "" {
+		return s.defaultLocale
+	}
+	return locale
+}
+
+// Message translates the message for key to a locale, formatted with args
+func (s *LocaleService) Message(locale, key string, args ...any) string {
+	return s.catalog.Message(locale, key, args...)
+}
+
+// DIDStatusDescription describes a DID status, or a verification status such as
+// claims_mismatch, to the DID's holder; empty for statuses without a description
+func (s *LocaleService) DIDStatusDescription(locale, status string) string {
+	key := "did.status." + status
+	if !s.catalog.Has(key) {
+		return ""
+	}
+	return s.Message(locale, key)
+}
+
+// ServiceStatusDescription describes the overall status of the status page
+func (s *LocaleService) ServiceStatusDescription(locale string, status domain.ServiceStatus) string {
+	return s.Message(locale, "service.status."+string(status))
+}
+
+// SetTenantLocale sets the default locale of a tenant's end users, or clears it back to
+// the server's default when locale is empty
+func (s *LocaleService) SetTenantLocale(id uuid.UUID, locale string) (*domain.APIKey, error) {
+	if locale != "" {
+		var err error
+		if locale, err = s.Supported(locale); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := s.apiKeyRepo.SetDefaultLocale(id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: default locale of API key %s set to %q", key.ID, locale)
+	return key, nil
+}
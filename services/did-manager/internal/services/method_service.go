@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// creatableMethods are the DID methods this deployment generates DIDs for
+var creatableMethods = map[domain.DIDMethod]bool{
+	domain.DIDMethodExample: true,
+}
+
+// MethodService reports which DID methods the deployment can create and resolve and
+// enforces per-tenant method policies. It is registered as a plugin hook rejecting the
+// creation of methods the deployment cannot create or the tenant may not use.
+type MethodService struct {
+	repo     domain.DIDMethodPolicyRepository
+	resolver *ResolverService
+}
+
+// NewMethodService creates a new method service
+func NewMethodService(repo domain.DIDMethodPolicyRepository, resolver *ResolverService) *MethodService {
+	return &MethodService{
+		repo:     repo,
+		resolver: resolver,
+	}
+}
+
+// Capabilities lists every known method with whether the deployment creates and
+// resolves it, and whether the tenant's policy allows creating it
+func (s *MethodService) Capabilities(tenant string) ([]domain.DIDMethodCapability, error) {
+	policy, err := s.repo.Effective(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := make([]domain.DIDMethodCapability, 0, len(domain.KnownDIDMethods))
+	for _, method := range domain.KnownDIDMethods {
+		capabilities = append(capabilities, domain.DIDMethodCapability{
+			Method:  method,
+			Create:  creatableMethods[method],
+			Resolve: s.resolver.Resolves(method),
+			Allowed: creatableMethods[method] && policyAllows(policy, method),
+		})
+	}
+	return capabilities, nil
+}
+
+// BeforeCreateDID rejects methods the deployment cannot create or the tenant's policy
+// does not allow
+func (s *MethodService) BeforeCreateDID(req *domain.DIDCreateRequest) error {
+	method := req.Method
+	if method == "" {
+		method = domain.DIDMethodExample
+	}
+	if !creatableMethods[method] {
+		return fmt.Errorf("%w: did:%s", domain.ErrUnsupportedDIDMethod, method)
+	}
+
+	policy, err := s.repo.Effective(req.Tenant)
+	if err != nil {
+		return fmt.Errorf("failed to get DID method policy: %w", err)
+	}
+	if !policyAllows(policy, method) {
+		return fmt.Errorf("%w: did:%s", domain.ErrDIDMethodNotAllowed, method)
+	}
+	return nil
+}
+
+// AfterCreateDID does nothing; the method policy only gates creation
+func (s *MethodService) AfterCreateDID(*domain.DIDCreateRequest, *domain.DIDResponse) {}
+
+// ListPolicies lists every method policy
+func (s *MethodService) ListPolicies() ([]*domain.DIDMethodPolicy, error) {
+	policies, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*domain.DIDMethodPolicy{}
+	}
+	return policies, nil
+}
+
+// SetPolicy sets the methods a tenant may create, or the default policy when the
+// tenant is empty
+func (s *MethodService) SetPolicy(req *domain.DIDMethodPolicyRequest) (*domain.DIDMethodPolicy, error) {
+	for _, method := range req.Methods {
+		if !creatableMethods[method] {
+			return nil, fmt.Errorf("%w: did:%s", domain.ErrUnsupportedDIDMethod, method)
+		}
+	}
+
+	policy := &domain.DIDMethodPolicy{
+		Tenant:    req.Tenant,
+		Methods:   req.Methods,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Upsert(policy); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: DID method policy for tenant %q set to %v", policy.Tenant, policy.Methods)
+	return policy, nil
+}
+
+// DeletePolicy removes a method policy
+func (s *MethodService) DeletePolicy(tenant string) error {
+	if err := s.repo.Delete(tenant); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: DID method policy for tenant %q removed", tenant)
+	return nil
+}
+
+// policyAllows reports whether a policy allows a method; no policy allows every method
+func policyAllows(policy *domain.DIDMethodPolicy, method domain.DIDMethod) bool {
+	if policy == nil {
+		return true
+	}
+	for _, allowed := range policy.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
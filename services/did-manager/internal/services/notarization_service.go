@@ -0,0 +1,153 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// NotarizationService notarizes document hashes signed by DIDs, anchoring them through
+// the blockchain job pipeline used for DID registration
+type NotarizationService struct {
+	notarizationRepo domain.NotarizationRepository
+	jobRepo          domain.BlockchainJobRepository
+	didRepo          domain.DIDRepository
+	registry         *did.Registry
+	queue            JobPublisher
+	// contract is the DID Registry address notarizations are anchored in
+	contract string
+}
+
+// NewNotarizationService creates a new notarization service; while queue is an
+// OfflineQueue, jobs are only picked up by the database-backed worker
+func NewNotarizationService(
+	notarizationRepo domain.NotarizationRepository,
+	jobRepo domain.BlockchainJobRepository,
+	didRepo domain.DIDRepository,
+	registry *did.Registry,
+	queue JobPublisher,
+	contract string,
+) *NotarizationService {
+	return &NotarizationService{
+		notarizationRepo: notarizationRepo,
+		jobRepo:          jobRepo,
+		didRepo:          didRepo,
+		registry:         registry,
+		queue:            queue,
+		contract:         contract,
+	}
+}
+
+// Notarize verifies the caller DID's signature over a document hash and queues the hash
+// for anchoring. Notarizing the same hash again returns the existing notarization.
+func (s *NotarizationService) Notarize(callerDID string, req *domain.NotarizeRequest) (*domain.Notarization, error) {
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != string(domain.DIDStatusActive) && record.Status != string(domain.DIDStatusPending) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	documentHash := strings.ToLower(req.DocumentHash)
+	hash, err := hex.DecodeString(documentHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed document hash", domain.ErrInvalidSignature)
+	}
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", domain.ErrInvalidSignature)
+	}
+
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	valid, err := s.registry.VerifySignature(record.KeyAlgorithm, keyMaterial, hash, signature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	existing, err := s.notarizationRepo.ListByDocumentHash(documentHash)
+	if err != nil {
+		return nil, err
+	}
+	for _, notarization := range existing {
+		if notarization.Did == record.Did {
+			notarization.Contract = s.contract
+			return notarization, nil
+		}
+	}
+
+	now := time.Now()
+	job := &domain.BlockchainJob{
+		ID:         uuid.New(),
+		JobType:    string(domain.JobTypeNotarize),
+		DIDID:      record.ID,
+		UserHash:   documentHash,
+		DID:        record.Did,
+		Status:     string(domain.JobStatusPending),
+		RetryCount: 0,
+		MaxRetries: 3,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create blockchain job: %w", err)
+	}
+
+	notarization := &domain.Notarization{
+		ID:                 uuid.New(),
+		DIDID:              record.ID,
+		Did:                record.Did,
+		DocumentHash:       documentHash,
+		Signature:          strings.ToLower(req.Signature),
+		KeyAlgorithm:       record.KeyAlgorithm,
+		VerificationMethod: record.Did + "#key-1",
+		JobID:              job.ID,
+		Status:             string(domain.NotarizationStatusPending),
+		Contract:           s.contract,
+		CreatedAt:          now,
+	}
+	if err := s.notarizationRepo.Create(notarization); err != nil {
+		return nil, err
+	}
+
+	publishJob(s.jobRepo, s.queue, job)
+
+	return notarization, nil
+}
+
+// GetNotarization returns a notarization proof
+func (s *NotarizationService) GetNotarization(id uuid.UUID) (*domain.Notarization, error) {
+	notarization, err := s.notarizationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	notarization.Contract = s.contract
+	return notarization, nil
+}
+
+// ListByDocumentHash returns every notarization of a document hash
+func (s *NotarizationService) ListByDocumentHash(documentHash string) ([]*domain.Notarization, error) {
+	notarizations, err := s.notarizationRepo.ListByDocumentHash(strings.ToLower(documentHash))
+	if err != nil {
+		return nil, err
+	}
+	if notarizations == nil {
+		notarizations = []*domain.Notarization{}
+	}
+	for _, notarization := range notarizations {
+		notarization.Contract = s.contract
+	}
+	return notarizations, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/push"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// EventPublisher publishes domain events; implemented by the NATS queue
+type EventPublisher interface {
+	PublishEvent(event *queue.Event) error
+}
+
+// pushSendTimeout bounds delivery to a single device
+const pushSendTimeout = 10 * time.Second
+
+// notificationTemplates maps wallet-relevant event types to the message keys of the
+// alert shown on the device, whose title and body are the keys' ".title" and ".body"
+var notificationTemplates = map[string]string{
+	queue.EventCredentialOffered:     "push.credential_offered",
+	queue.EventPresentationRequested: "push.presentation_requested",
+	queue.EventCredentialRevoked:     "push.credential_revoked",
+	queue.EventCredentialExpiring:    "push.credential_expiring",
+}
+
+// NotificationService manages push devices and delivers push notifications for
+// wallet-relevant domain events
+type NotificationService struct {
+	deviceRepo domain.PushDeviceRepository
+	didRepo    domain.DIDRepository
+	locales    *LocaleService
+	providers  map[string]push.Provider
+}
+
+// NewNotificationService creates a new notification service; devices on platforms
+// without a configured provider can register but are not notified. Notifications are
+// shown in the locale each device registered with.
+func NewNotificationService(deviceRepo domain.PushDeviceRepository, didRepo domain.DIDRepository, locales *LocaleService, providers ...push.Provider) *NotificationService {
+	byPlatform := make(map[string]push.Provider, len(providers))
+	for _, provider := range providers {
+		byPlatform[provider.Platform()] = provider
+	}
+
+	return &NotificationService{
+		deviceRepo: deviceRepo,
+		didRepo:    didRepo,
+		locales:    locales,
+		providers:  byPlatform,
+	}
+}
+
+// RegisterDevice registers a device to receive a user's wallet notifications in the
+// locale it asks for, or the best one its Accept-Language header accepts
+func (s *NotificationService) RegisterDevice(userID uuid.UUID, req *domain.DeviceRegisterRequest, acceptLanguage string) (*domain.PushDevice, error) {
+	locale := s.locales.Resolve(acceptLanguage, nil)
+	if req.Locale != "" {
+		var err error
+		if locale, err = s.locales.Supported(req.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	device := &domain.PushDevice{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Platform:   req.Platform,
+		Token:      req.Token,
+		DeviceName: req.DeviceName,
+		Locale:     locale,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := s.deviceRepo.Upsert(device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// ListDevices lists the devices registered by a user
+func (s *NotificationService) ListDevices(userID uuid.UUID) ([]*domain.PushDevice, error) {
+	devices, err := s.deviceRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if devices == nil {
+		devices = []*domain.PushDevice{}
+	}
+	return devices, nil
+}
+
+// UnregisterDevice removes one of a user's devices
+func (s *NotificationService) UnregisterDevice(userID uuid.UUID, deviceID uuid.UUID) error {
+	return s.deviceRepo.Delete(deviceID, userID)
+}
+
+// HandleEvent notifies the devices of the user owning the event's subject DID.
+// Events that are not wallet-relevant, or concern DIDs this service does not manage,
+// are ignored.
+func (s *NotificationService) HandleEvent(event *queue.Event) error {
+	templateKey, ok := notificationTemplates[event.Type]
+	if !ok {
+		return nil
+	}
+
+	subject, err := s.didRepo.GetByDID(event.Subject)
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	devices, err := s.deviceRepo.ListByUserID(subject.UserID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"did":        event.Subject,
+	}
+	for k, v := range event.Data {
+		data[k] = v
+	}
+
+	var failed int
+	for _, device := range devices {
+		provider, ok := s.providers[device.Platform]
+		if !ok {
+			continue
+		}
+
+		locale := s.locales.Or(device.Locale)
+		notification := push.Notification{
+			Title: s.locales.Message(locale, templateKey+".title"),
+			Body:  s.locales.Message(locale, templateKey+".body"),
+			Data:  data,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), pushSendTimeout)
+		err := provider.Send(ctx, device.Token, &notification)
+		cancel()
+
+		switch {
+		case err == nil:
+		case errors.Is(err, push.ErrUnregistered):
+			// The app was uninstalled or the token rotated; stop notifying it
+			if err := s.deviceRepo.DeleteByToken(device.Token); err != nil {
+				log.Printf("Warning: failed to remove unregistered push device %s: %v", device.ID, err)
+			}
+		default:
+			log.Printf("Failed to send %s notification to device %s: %v", event.Type, device.ID, err)
+			failed++
+		}
+	}
+
+	// Only redeliver when nothing got through, so a single broken device does not
+	// cause repeated notifications on the others
+	if failed > 0 && failed == len(devices) {
+		return fmt.Errorf("failed to notify any of %d devices", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/queue"
+)
+
+// Ledger anchors DID operations and notarizations on a chain and verifies DIDs against
+// it. Any chain backend of package blockchain implements it, such as
+// *blockchain.EthereumClient or *blockchain.HederaClient; OfflineLedger stands in when
+// the chain cannot be reached.
+type Ledger interface {
+	blockchain.Chain
+}
+
+// JobPublisher publishes blockchain jobs for the workers. *queue.NATSQueue implements
+// it; OfflineQueue stands in when NATS cannot be reached.
+type JobPublisher interface {
+	PublishJob(job *queue.BlockchainJob) error
+}
+
+// offlineDependency is implemented by the stand-ins of dependencies the service runs
+// without
+type offlineDependency interface {
+	offlineReason() error
+}
+
+// unavailable returns why a dependency is offline, or nil when it is available
+func unavailable(dependency any) error {
+	if offline, ok := dependency.(offlineDependency); ok {
+		return offline.offlineReason()
+	}
+	return nil
+}
+
+// offlineLedger fails every ledger operation with ErrBlockchainUnavailable
+type offlineLedger struct {
+	err error
+}
+
+// OfflineLedger returns a ledger for running without a blockchain; every operation
+// fails with domain.ErrBlockchainUnavailable, wrapping reason when given
+func OfflineLedger(reason error) Ledger {
+	return &offlineLedger{err: offlineError(domain.ErrBlockchainUnavailable, reason)}
+}
+
+func (l *offlineLedger) offlineReason() error {
+	return l.err
+}
+
+func (l *offlineLedger) Submit(context.Context, *blockchain.Operation) (string, error) {
+	return "", l.err
+}
+
+func (l *offlineLedger) Simulate(*blockchain.Operation) (*blockchain.Simulation, error) {
+	return nil, l.err
+}
+
+func (l *offlineLedger) Verify(string, string) (*blockchain.ChainCheck, error) {
+	return nil, l.err
+}
+
+func (l *offlineLedger) Confirmations(string, uint64) (uint64, bool, error) {
+	return 0, false, l.err
+}
+
+func (l *offlineLedger) Anchoring(string) (*blockchain.Anchoring, error) {
+	return nil, l.err
+}
+
+// offlineQueue fails every publish with ErrQueueUnavailable
+type offlineQueue struct {
+	err error
+}
+
+// OfflineQueue returns a job publisher for running without NATS; publishing fails with
+// domain.ErrQueueUnavailable, wrapping reason when given. Jobs are still stored and
+// picked up by the database-backed worker.
+func OfflineQueue(reason error) JobPublisher {
+	return &offlineQueue{err: offlineError(domain.ErrQueueUnavailable, reason)}
+}
+
+func (q *offlineQueue) offlineReason() error {
+	return q.err
+}
+
+func (q *offlineQueue) PublishJob(*queue.BlockchainJob) error {
+	return q.err
+}
+
+func offlineError(sentinel, reason error) error {
+	if reason == nil {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %v", sentinel, reason)
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationService manages organization DIDs, whose mutating operations take effect
+// only after m-of-n of the DID's controllers approve them
+type OrganizationService struct {
+	orgRepo     domain.OrganizationRepository
+	didRepo     domain.DIDRepository
+	jobRepo     domain.BlockchainJobRepository
+	credentials *CredentialService
+	registry    *did.Registry
+	queue       JobPublisher
+	// profiles sets the profiles of organization DIDs; nil rejects set_profile
+	profiles *ProfileService
+	// clock dates proposals and their execution
+	clock clock.Clock
+}
+
+// NewOrganizationService creates a new organization service; while queue is an
+// OfflineQueue, jobs are only picked up by the database-backed worker
+func NewOrganizationService(
+	orgRepo domain.OrganizationRepository,
+	didRepo domain.DIDRepository,
+	jobRepo domain.BlockchainJobRepository,
+	credentials *CredentialService,
+	registry *did.Registry,
+	queue JobPublisher,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:     orgRepo,
+		didRepo:     didRepo,
+		jobRepo:     jobRepo,
+		credentials: credentials,
+		registry:    registry,
+		queue:       queue,
+		clock:       clock.System,
+	}
+}
+
+// SetClock sets the time source of proposal and execution dates, clock.System by default
+func (s *OrganizationService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetProfiles enables set_profile operations, which replace an organization DID's profile
+func (s *OrganizationService) SetProfiles(profiles *ProfileService) {
+	s.profiles = profiles
+}
+
+// SetControllers makes a custodial DID an organization DID controlled by the requested
+// DIDs, or replaces the controllers and threshold of an existing one
+func (s *OrganizationService) SetControllers(req *domain.OrganizationControllersRequest) (*domain.Organization, error) {
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.Custodial {
+		return nil, domain.ErrNotCustodial
+	}
+
+	controllers := make([]string, 0, len(req.Controllers))
+	for _, controller := range req.Controllers {
+		if controller == record.Did {
+			return nil, fmt.Errorf("%w: a DID cannot control itself", domain.ErrForbidden)
+		}
+		if containsString(controllers, controller) {
+			continue
+		}
+		if _, err := s.didRepo.GetByDID(controller); err != nil {
+			if errors.Is(err, domain.ErrDIDNotFound) {
+				return nil, fmt.Errorf("%w: controller %s", domain.ErrDIDNotFound, controller)
+			}
+			return nil, err
+		}
+		controllers = append(controllers, controller)
+	}
+	if req.Threshold > len(controllers) {
+		return nil, fmt.Errorf("%w: threshold %d exceeds the %d controllers", domain.ErrForbidden, req.Threshold, len(controllers))
+	}
+
+	if err := s.orgRepo.SetControllers(record.ID, controllers, req.Threshold); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Organization DID %s now requires %d of %d controller approvals", record.Did, req.Threshold, len(controllers))
+	return s.GetOrganization(record.Did)
+}
+
+// GetOrganization returns an organization DID and its controllers
+func (s *OrganizationService) GetOrganization(didString string) (*domain.Organization, error) {
+	record, controllers, err := s.load(didString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Organization{
+		Did:         record.Did,
+		Threshold:   record.ApprovalThreshold,
+		Controllers: controllers,
+	}, nil
+}
+
+// Propose records an operation on an organization DID proposed by one of its
+// controllers. The proposal counts as the proposer's approval, so the operation runs
+// immediately when the threshold is one.
+func (s *OrganizationService) Propose(callerDID, orgDID string, req *domain.OperationProposeRequest) (*domain.ControlledOperation, error) {
+	record, controllers, err := s.load(orgDID)
+	if err != nil {
+		return nil, err
+	}
+	if !isController(controllers, callerDID) {
+		return nil, fmt.Errorf("%w: %s is not a controller of %s", domain.ErrForbidden, callerDID, record.Did)
+	}
+	if record.Status != string(domain.DIDStatusActive) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	var payload any
+	switch domain.OperationType(req.Type) {
+	case domain.OperationTypeRotateKey:
+		if req.KeyAlgorithm != "" {
+			if _, err := s.registry.SignatureSuiteForNewKeys(req.KeyAlgorithm); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrForbidden, err)
+			}
+			payload = map[string]string{"key_algorithm": req.KeyAlgorithm}
+		}
+	case domain.OperationTypeIssueCredential:
+		payload = req.Credential
+	case domain.OperationTypeSetProfile:
+		if s.profiles == nil {
+			return nil, fmt.Errorf("%w: DID profiles are not enabled", domain.ErrForbidden)
+		}
+		payload = req.Profile
+	}
+
+	operation := &domain.ControlledOperation{
+		ID:         uuid.New(),
+		DIDID:      record.ID,
+		Did:        record.Did,
+		Type:       req.Type,
+		Status:     string(domain.OperationStatusPending),
+		ProposedBy: callerDID,
+		CreatedAt:  s.clock.Now(),
+	}
+	if payload != nil {
+		operation.Payload, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode operation: %w", err)
+		}
+	}
+	if err := s.orgRepo.CreateOperation(operation); err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.AddApproval(operation.ID, callerDID); err != nil {
+		return nil, err
+	}
+
+	return s.advance(record, controllers, operation.ID)
+}
+
+// Approve records a controller's approval of a pending operation and runs the
+// operation once enough controllers have approved it
+func (s *OrganizationService) Approve(callerDID string, id uuid.UUID) (*domain.ControlledOperation, error) {
+	operation, err := s.orgRepo.GetOperation(id)
+	if err != nil {
+		return nil, err
+	}
+	record, controllers, err := s.load(operation.Did)
+	if err != nil {
+		return nil, err
+	}
+	if !isController(controllers, callerDID) {
+		// Do not reveal operations on DIDs the caller does not control
+		return nil, domain.ErrOperationNotFound
+	}
+	if operation.Status != string(domain.OperationStatusPending) {
+		return nil, domain.ErrOperationClosed
+	}
+
+	if err := s.orgRepo.AddApproval(operation.ID, callerDID); err != nil {
+		return nil, err
+	}
+
+	return s.advance(record, controllers, operation.ID)
+}
+
+// GetOperation returns an operation to one of the organization's controllers
+func (s *OrganizationService) GetOperation(callerDID string, id uuid.UUID) (*domain.ControlledOperation, error) {
+	operation, err := s.orgRepo.GetOperation(id)
+	if err != nil {
+		return nil, err
+	}
+	record, controllers, err := s.load(operation.Did)
+	if err != nil {
+		return nil, err
+	}
+	if !isController(controllers, callerDID) {
+		return nil, domain.ErrOperationNotFound
+	}
+
+	operation.Threshold = record.ApprovalThreshold
+	return operation, nil
+}
+
+// ListOperations lists the operations on an organization DID, optionally filtered by
+// status, to one of its controllers
+func (s *OrganizationService) ListOperations(callerDID, orgDID, status string) ([]*domain.ControlledOperation, error) {
+	record, controllers, err := s.load(orgDID)
+	if err != nil {
+		return nil, err
+	}
+	if !isController(controllers, callerDID) {
+		return nil, fmt.Errorf("%w: %s is not a controller of %s", domain.ErrForbidden, callerDID, record.Did)
+	}
+
+	operations, err := s.orgRepo.ListOperations(record.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	if operations == nil {
+		operations = []*domain.ControlledOperation{}
+	}
+	for _, operation := range operations {
+		operation.Threshold = record.ApprovalThreshold
+	}
+	return operations, nil
+}
+
+// load retrieves an organization DID and its controllers
+func (s *OrganizationService) load(didString string) (*domain.DID, []*domain.DIDController, error) {
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record.ApprovalThreshold == 0 {
+		return nil, nil, domain.ErrNotOrganization
+	}
+
+	controllers, err := s.orgRepo.ListControllers(record.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, controllers, nil
+}
+
+// advance runs an operation once approvals from current controllers reach the
+// threshold. Only one approver claims the operation, so it runs at most once.
+func (s *OrganizationService) advance(record *domain.DID, controllers []*domain.DIDController, id uuid.UUID) (*domain.ControlledOperation, error) {
+	operation, err := s.orgRepo.GetOperation(id)
+	if err != nil {
+		return nil, err
+	}
+	operation.Threshold = record.ApprovalThreshold
+
+	approvals := 0
+	for _, approver := range operation.Approvals {
+		if isController(controllers, approver) {
+			approvals++
+		}
+	}
+	if approvals < record.ApprovalThreshold {
+		return operation, nil
+	}
+
+	if err := s.orgRepo.MarkExecuting(operation.ID); err != nil {
+		if !errors.Is(err, domain.ErrOperationClosed) {
+			return nil, err
+		}
+		// Another approver claimed it first
+		operation, err = s.orgRepo.GetOperation(id)
+		if err != nil {
+			return nil, err
+		}
+		operation.Threshold = record.ApprovalThreshold
+		return operation, nil
+	}
+
+	status := domain.OperationStatusExecuted
+	message := ""
+	result, err := s.execute(record, operation)
+	if err != nil {
+		log.Printf("Operation %s (%s) on %s failed: %v", operation.ID, operation.Type, record.Did, err)
+		status = domain.OperationStatusFailed
+		message = err.Error()
+	} else {
+		log.Printf("Operation %s (%s) on %s executed with approvals from %v", operation.ID, operation.Type, record.Did, operation.Approvals)
+	}
+
+	executedAt := s.clock.Now()
+	if err := s.orgRepo.Finish(operation.ID, string(status), result, message, executedAt); err != nil {
+		return nil, err
+	}
+
+	operation.Status = string(status)
+	operation.Result = result
+	operation.Message = message
+	operation.ExecutedAt = &executedAt
+	return operation, nil
+}
+
+// execute carries out an approved operation and returns its result
+func (s *OrganizationService) execute(record *domain.DID, operation *domain.ControlledOperation) (json.RawMessage, error) {
+	// The DID may have been deactivated since the operation was proposed
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+
+	switch domain.OperationType(operation.Type) {
+	case domain.OperationTypeRotateKey:
+		var params struct {
+			KeyAlgorithm string `json:"key_algorithm"`
+		}
+		if len(operation.Payload) > 0 {
+			if err := json.Unmarshal(operation.Payload, &params); err != nil {
+				return nil, fmt.Errorf("failed to decode operation: %w", err)
+			}
+		}
+		if params.KeyAlgorithm == "" {
+			params.KeyAlgorithm = record.KeyAlgorithm
+		}
+
+		suite, err := s.registry.SignatureSuiteForNewKeys(params.KeyAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		_, privateKey, err := suite.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		if err := s.didRepo.RotateKey(record.ID, hex.EncodeToString(privateKey), suite.ID()); err != nil {
+			return nil, err
+		}
+
+		job, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeUpdateDID, record)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"key_algorithm": suite.ID(), "job_id": job.ID.String()})
+
+	case domain.OperationTypeRevokeDID:
+		job, err := deactivateDID(s.didRepo, s.jobRepo, s.queue, record)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"job_id": job.ID.String()})
+
+	case domain.OperationTypeIssueCredential:
+		var req domain.CredentialIssueRequest
+		if err := json.Unmarshal(operation.Payload, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode operation: %w", err)
+		}
+		credential, err := s.credentials.IssueApprovedCredential(record, &req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(credential)
+
+	case domain.OperationTypeSetProfile:
+		if s.profiles == nil {
+			return nil, errors.New("DID profiles are not enabled")
+		}
+		var req domain.DIDProfileRequest
+		if err := json.Unmarshal(operation.Payload, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode operation: %w", err)
+		}
+		profile, err := s.profiles.SetApprovedProfile(record, &req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(profile)
+	}
+
+	return nil, fmt.Errorf("unknown operation type: %s", operation.Type)
+}
+
+// isController reports whether didString is one of controllers
+func isController(controllers []*domain.DIDController, didString string) bool {
+	for _, controller := range controllers {
+		if controller.ControllerDID == didString {
+			return true
+		}
+	}
+	return false
+}
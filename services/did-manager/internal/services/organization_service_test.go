@@ -0,0 +1,240 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// fakeOrganizations keeps controllers, operations and approvals in memory. claimed
+// runs in MarkExecuting before the claim, to let another approver win the race.
+type fakeOrganizations struct {
+	domain.OrganizationRepository
+	controllers map[uuid.UUID][]*domain.DIDController
+	operations  map[uuid.UUID]*domain.ControlledOperation
+	claimed     func(id uuid.UUID)
+	finished    int
+}
+
+func newFakeOrganizations() *fakeOrganizations {
+	return &fakeOrganizations{
+		controllers: map[uuid.UUID][]*domain.DIDController{},
+		operations:  map[uuid.UUID]*domain.ControlledOperation{},
+	}
+}
+
+func (f *fakeOrganizations) SetControllers(didID uuid.UUID, controllers []string, threshold int) error {
+	f.controllers[didID] = nil
+	for _, controller := range controllers {
+		f.controllers[didID] = append(f.controllers[didID], &domain.DIDController{ControllerDID: controller})
+	}
+	return nil
+}
+
+func (f *fakeOrganizations) ListControllers(didID uuid.UUID) ([]*domain.DIDController, error) {
+	return f.controllers[didID], nil
+}
+
+func (f *fakeOrganizations) CreateOperation(operation *domain.ControlledOperation) error {
+	stored := *operation
+	f.operations[operation.ID] = &stored
+	return nil
+}
+
+func (f *fakeOrganizations) GetOperation(id uuid.UUID) (*domain.ControlledOperation, error) {
+	operation, ok := f.operations[id]
+	if !ok {
+		return nil, domain.ErrOperationNotFound
+	}
+	copied := *operation
+	copied.Approvals = append([]string(nil), operation.Approvals...)
+	return &copied, nil
+}
+
+func (f *fakeOrganizations) AddApproval(operationID uuid.UUID, controllerDID string) error {
+	operation := f.operations[operationID]
+	if !containsString(operation.Approvals, controllerDID) {
+		operation.Approvals = append(operation.Approvals, controllerDID)
+	}
+	return nil
+}
+
+func (f *fakeOrganizations) MarkExecuting(id uuid.UUID) error {
+	if f.claimed != nil {
+		f.claimed(id)
+	}
+	operation := f.operations[id]
+	if operation.Status != string(domain.OperationStatusPending) {
+		return domain.ErrOperationClosed
+	}
+	operation.Status = string(domain.OperationStatusExecuting)
+	return nil
+}
+
+func (f *fakeOrganizations) Finish(id uuid.UUID, status string, result json.RawMessage, message string, executedAt time.Time) error {
+	f.finished++
+	operation := f.operations[id]
+	operation.Status = status
+	operation.Result = result
+	operation.Message = message
+	operation.ExecutedAt = &executedAt
+	return nil
+}
+
+// fakeStatusDIDs serves fixed DID records and records their status updates
+type fakeStatusDIDs struct {
+	fakeDIDs
+}
+
+func (f *fakeStatusDIDs) UpdateStatus(id uuid.UUID, status domain.DIDStatus, txHash string) error {
+	for _, record := range f.records {
+		if record.ID == id {
+			record.Status = string(status)
+		}
+	}
+	return nil
+}
+
+// fakeJobs records the blockchain jobs created
+type fakeJobs struct {
+	domain.BlockchainJobRepository
+	created []*domain.BlockchainJob
+}
+
+func (f *fakeJobs) Create(job *domain.BlockchainJob) error {
+	f.created = append(f.created, job)
+	return nil
+}
+
+// newOrganization returns an organization service for an active organization DID
+// controlled by controllers, requiring threshold of them to approve its operations
+func newOrganization(t *testing.T, threshold int, controllers ...string) (*OrganizationService, *fakeOrganizations, *fakeJobs, *domain.DID) {
+	t.Helper()
+	generator := did.NewGenerator()
+	record := newIssuer(t, generator, threshold)
+
+	dids := &fakeStatusDIDs{fakeDIDs{records: map[string]*domain.DID{record.Did: record}}}
+	orgs := newFakeOrganizations()
+	if err := orgs.SetControllers(record.ID, controllers, threshold); err != nil {
+		t.Fatalf("SetControllers failed: %v", err)
+	}
+	jobs := &fakeJobs{}
+	service := NewOrganizationService(orgs, dids, jobs, nil, generator.Registry(), OfflineQueue(errors.New("no queue in tests")))
+	return service, orgs, jobs, record
+}
+
+func TestProposeAndApproveRunOnceAtThreshold(t *testing.T) {
+	service, orgs, jobs, record := newOrganization(t, 2, "did:example:alice", "did:example:bob", "did:example:carol")
+
+	operation, err := service.Propose("did:example:alice", record.Did, &domain.OperationProposeRequest{Type: string(domain.OperationTypeRevokeDID)})
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusPending) || len(operation.Approvals) != 1 {
+		t.Fatalf("expected a pending operation with the proposer's approval, got %+v", operation)
+	}
+	// Approving twice does not count twice
+	operation, err = service.Approve("did:example:alice", operation.ID)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusPending) || len(jobs.created) != 0 {
+		t.Fatalf("expected a repeated approval to leave the operation pending, got %+v", operation)
+	}
+
+	operation, err = service.Approve("did:example:bob", operation.ID)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusExecuted) || operation.ExecutedAt == nil {
+		t.Fatalf("expected the operation to run at the threshold, got %+v", operation)
+	}
+	if len(jobs.created) != 1 || jobs.created[0].JobType != string(domain.JobTypeRevokeDID) {
+		t.Errorf("expected one revoke job, got %+v", jobs.created)
+	}
+	if record.Status != string(domain.DIDStatusDeactivating) {
+		t.Errorf("expected the DID to be deactivating, got %s", record.Status)
+	}
+
+	// Approvals past the threshold do not run it again
+	if _, err := service.Approve("did:example:carol", operation.ID); !errors.Is(err, domain.ErrOperationClosed) {
+		t.Errorf("expected ErrOperationClosed, got %v", err)
+	}
+	if len(jobs.created) != 1 || orgs.finished != 1 {
+		t.Errorf("expected the operation to run once, got %d jobs and %d finishes", len(jobs.created), orgs.finished)
+	}
+}
+
+func TestProposeRunsImmediatelyAtThresholdOne(t *testing.T) {
+	service, _, jobs, record := newOrganization(t, 1, "did:example:alice", "did:example:bob")
+
+	operation, err := service.Propose("did:example:alice", record.Did, &domain.OperationProposeRequest{Type: string(domain.OperationTypeRevokeDID)})
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusExecuted) || len(jobs.created) != 1 {
+		t.Errorf("expected the proposal to run the operation, got %+v", operation)
+	}
+}
+
+func TestApprovalsOfRemovedControllersDoNotCount(t *testing.T) {
+	service, orgs, jobs, record := newOrganization(t, 2, "did:example:alice", "did:example:bob", "did:example:carol")
+
+	operation, err := service.Propose("did:example:alice", record.Did, &domain.OperationProposeRequest{Type: string(domain.OperationTypeRevokeDID)})
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+	// alice is removed before anyone else approves
+	if err := orgs.SetControllers(record.ID, []string{"did:example:bob", "did:example:carol"}, 2); err != nil {
+		t.Fatalf("SetControllers failed: %v", err)
+	}
+
+	if _, err := service.Approve("did:example:alice", operation.ID); !errors.Is(err, domain.ErrOperationNotFound) {
+		t.Errorf("expected a removed controller to be refused with ErrOperationNotFound, got %v", err)
+	}
+	operation, err = service.Approve("did:example:bob", operation.ID)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusPending) || len(jobs.created) != 0 {
+		t.Fatalf("expected alice's approval not to count, got %+v", operation)
+	}
+
+	operation, err = service.Approve("did:example:carol", operation.ID)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusExecuted) || len(jobs.created) != 1 {
+		t.Errorf("expected the operation to run with two current controllers, got %+v", operation)
+	}
+}
+
+func TestApproveLosingTheExecutionRace(t *testing.T) {
+	service, orgs, jobs, record := newOrganization(t, 2, "did:example:alice", "did:example:bob")
+
+	operation, err := service.Propose("did:example:alice", record.Did, &domain.OperationProposeRequest{Type: string(domain.OperationTypeRevokeDID)})
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+	// Another approver claims the operation between bob's approval and bob's claim
+	orgs.claimed = func(id uuid.UUID) {
+		orgs.operations[id].Status = string(domain.OperationStatusExecuting)
+	}
+
+	operation, err = service.Approve("did:example:bob", operation.ID)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if operation.Status != string(domain.OperationStatusExecuting) {
+		t.Errorf("expected the operation claimed by the other approver, got %+v", operation)
+	}
+	if len(jobs.created) != 0 || orgs.finished != 0 {
+		t.Errorf("expected the losing approver not to run the operation, got %d jobs and %d finishes", len(jobs.created), orgs.finished)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ProfileService maintains the human-readable profiles of DIDs. A DID's controller
+// sets its profile and opts in to publishing each field; anyone who may resolve the
+// DID sees the published fields.
+type ProfileService struct {
+	profileRepo    domain.DIDProfileRepository
+	didRepo        domain.DIDRepository
+	credentialRepo domain.CredentialRepository
+	access         *AccessService
+}
+
+// NewProfileService creates a new DID profile service
+func NewProfileService(profileRepo domain.DIDProfileRepository, didRepo domain.DIDRepository, credentialRepo domain.CredentialRepository, access *AccessService) *ProfileService {
+	return &ProfileService{
+		profileRepo:    profileRepo,
+		didRepo:        didRepo,
+		credentialRepo: credentialRepo,
+		access:         access,
+	}
+}
+
+// GetProfile returns the full profile of the calling DID, including unpublished fields
+func (s *ProfileService) GetProfile(callerDID string) (*domain.DIDProfile, error) {
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return nil, err
+	}
+	return s.profileRepo.Get(record.ID)
+}
+
+// SetProfile replaces the profile of the calling DID. Organization DIDs set their
+// profile through a set_profile operation their controllers approve.
+func (s *ProfileService) SetProfile(callerDID string, req *domain.DIDProfileRequest) (*domain.DIDProfile, error) {
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return nil, err
+	}
+	if record.ApprovalThreshold > 0 {
+		return nil, fmt.Errorf("%w: organization DIDs set their profile once their controllers approve", domain.ErrForbidden)
+	}
+	return s.SetApprovedProfile(record, req)
+}
+
+// SetApprovedProfile replaces the profile of a DID whose controllers approved it. Every
+// public credential must be an active credential held by the DID.
+func (s *ProfileService) SetApprovedProfile(record *domain.DID, req *domain.DIDProfileRequest) (*domain.DIDProfile, error) {
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+	for _, id := range req.PublicCredentials {
+		credential, err := s.credentialRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if credential.SubjectDID != record.Did {
+			return nil, fmt.Errorf("%w: credential %s is not held by %s", domain.ErrForbidden, id, record.Did)
+		}
+		if credential.Status != string(domain.CredentialStatusActive) {
+			return nil, fmt.Errorf("%w: credential %s is %s", domain.ErrForbidden, id, credential.Status)
+		}
+	}
+
+	profile := &domain.DIDProfile{
+		DID:               record.Did,
+		DisplayName:       req.DisplayName,
+		AvatarURL:         req.AvatarURL,
+		LinkedDomains:     req.LinkedDomains,
+		PublicCredentials: req.PublicCredentials,
+		Published:         req.Published,
+		UpdatedAt:         time.Now(),
+	}
+	if profile.LinkedDomains == nil {
+		profile.LinkedDomains = []string{}
+	}
+	if profile.PublicCredentials == nil {
+		profile.PublicCredentials = []uuid.UUID{}
+	}
+	if profile.Published == nil {
+		profile.Published = []domain.ProfileField{}
+	}
+
+	if err := s.profileRepo.Upsert(record.ID, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// DeleteProfile removes the profile of the calling DID
+func (s *ProfileService) DeleteProfile(callerDID string) error {
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return err
+	}
+	if record.ApprovalThreshold > 0 {
+		return fmt.Errorf("%w: organization DIDs set their profile once their controllers approve", domain.ErrForbidden)
+	}
+	return s.profileRepo.Delete(record.ID)
+}
+
+// PublicProfile returns the published fields of a DID's profile to a caller who may
+// resolve the DID. Published credentials no longer active or expired are left out.
+// DIDs without published fields and deactivated DIDs report ErrProfileNotFound, and
+// DIDs the caller may not see ErrDIDNotFound.
+func (s *ProfileService) PublicProfile(didString string, caller *domain.Caller) (*domain.PublicProfile, error) {
+	record, err := s.access.CheckDIDAccess(didString, caller)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, domain.ErrProfileNotFound
+	}
+
+	profile, err := s.profileRepo.Get(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile.Published) == 0 {
+		return nil, domain.ErrProfileNotFound
+	}
+
+	public := &domain.PublicProfile{
+		DID:       record.Did,
+		UpdatedAt: profile.UpdatedAt,
+	}
+	if slices.Contains(profile.Published, domain.ProfileFieldDisplayName) {
+		public.DisplayName = profile.DisplayName
+	}
+	if slices.Contains(profile.Published, domain.ProfileFieldAvatar) {
+		public.AvatarURL = profile.AvatarURL
+	}
+	if slices.Contains(profile.Published, domain.ProfileFieldLinkedDomains) {
+		public.LinkedDomains = profile.LinkedDomains
+	}
+	if slices.Contains(profile.Published, domain.ProfileFieldPublicCredentials) {
+		now := time.Now()
+		for _, id := range profile.PublicCredentials {
+			credential, err := s.credentialRepo.GetByID(id)
+			if err != nil {
+				if errors.Is(err, domain.ErrCredentialNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			if credential.SubjectDID != record.Did || credential.Status != string(domain.CredentialStatusActive) {
+				continue
+			}
+			if credential.ExpiresAt != nil && credential.ExpiresAt.Before(now) {
+				continue
+			}
+			public.Credentials = append(public.Credentials, &domain.ProfileCredential{
+				ID:        credential.ID,
+				Type:      credential.Type,
+				IssuerDID: credential.IssuerDID,
+				IssuedAt:  credential.IssuedAt,
+				ExpiresAt: credential.ExpiresAt,
+				Document:  credential.Document,
+			})
+		}
+	}
+
+	return public, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// PurposeKeyService manages the purpose keys of custodial DIDs: separate
+// authentication, assertionMethod and keyAgreement keys, so the key controlling a DID
+// does not also sign its sessions and credentials. DIDs without purpose keys, those
+// created before they were introduced and those no longer custodial, use their own
+// key for every purpose.
+type PurposeKeyService struct {
+	keys   domain.PurposeKeyRepository
+	didGen *did.Generator
+}
+
+// NewPurposeKeyService creates a new purpose key service
+func NewPurposeKeyService(keys domain.PurposeKeyRepository, didGen *did.Generator) *PurposeKeyService {
+	return &PurposeKeyService{
+		keys:   keys,
+		didGen: didGen,
+	}
+}
+
+// didKey is the key of a DID serving a purpose
+type didKey struct {
+	// method is the ID of the key in the DID document
+	method       string
+	keyAlgorithm string
+	// material is the private key of custodial DIDs, the public key otherwise
+	material []byte
+}
+
+// generate creates and stores the purpose keys of a new custodial DID. Failures are
+// logged rather than failing a DID that already exists, which then uses its own key
+// for every purpose.
+func (s *PurposeKeyService) generate(record *domain.DID) {
+	if s == nil || !record.Custodial {
+		return
+	}
+
+	generated, err := s.didGen.GeneratePurposeKeys(record.KeyAlgorithm)
+	if err != nil {
+		log.Printf("Warning: failed to generate purpose keys for %s: %v", record.Did, err)
+		return
+	}
+
+	now := time.Now()
+	keys := make([]*domain.PurposeKey, 0, len(generated))
+	for _, key := range generated {
+		keys = append(keys, &domain.PurposeKey{
+			ID:                 uuid.New(),
+			DIDID:              record.ID,
+			Purpose:            key.Purpose,
+			VerificationMethod: record.Did + "#" + key.Fragment,
+			KeyAlgorithm:       key.KeyAlgorithm,
+			PublicKey:          hex.EncodeToString(key.PublicKey),
+			PrivateKey:         key.PrivateKeyHex, // In production, this should be encrypted
+			CreatedAt:          now,
+		})
+	}
+	if err := s.keys.Create(keys); err != nil {
+		log.Printf("Warning: failed to store purpose keys for %s: %v", record.Did, err)
+	}
+}
+
+// key returns the key of a DID serving purpose: its purpose key when it has one,
+// otherwise its own key
+func (s *PurposeKeyService) key(record *domain.DID, purpose string) (*didKey, error) {
+	if s != nil && record.Custodial && purpose != did.PurposeCapabilityInvocation {
+		keys, err := s.keys.ListByDIDID(record.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if key.Purpose != purpose {
+				continue
+			}
+			material, err := hex.DecodeString(key.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s key: %w", purpose, err)
+			}
+			return &didKey{method: key.VerificationMethod, keyAlgorithm: key.KeyAlgorithm, material: material}, nil
+		}
+	}
+
+	material, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	return &didKey{method: record.Did + "#key-1", keyAlgorithm: record.KeyAlgorithm, material: material}, nil
+}
+
+// agreementKey returns the keyAgreement key of a DID, which only DIDs with purpose keys
+// have: unlike signing, encryption cannot fall back to the DID's own key
+func (s *PurposeKeyService) agreementKey(record *domain.DID) (*didKey, error) {
+	if s == nil {
+		return nil, domain.ErrNoKeyAgreementKey
+	}
+	keys, err := s.keys.ListByDIDID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.Purpose != did.PurposeKeyAgreement {
+			continue
+		}
+		publicKey, err := hex.DecodeString(key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s key: %w", key.Purpose, err)
+		}
+		return &didKey{method: key.VerificationMethod, keyAlgorithm: key.KeyAlgorithm, material: publicKey}, nil
+	}
+	return nil, domain.ErrNoKeyAgreementKey
+}
+
+// AddToDocument lists the DID's purpose keys under their purposes in document, moving
+// authentication and assertionMethod off the DID's own key
+func (s *PurposeKeyService) AddToDocument(record *domain.DID, document *did.Document) error {
+	keys, err := s.keys.ListByDIDID(record.ID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		methodType := did.VerificationMethodX25519
+		if key.KeyAlgorithm != did.KeyAgreementX25519 {
+			suite, err := s.didGen.Registry().SignatureSuite(key.KeyAlgorithm)
+			if err != nil {
+				return err
+			}
+			methodType = suite.VerificationMethodType()
+		}
+		publicKey, err := hex.DecodeString(key.PublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to decode purpose key %s: %w", key.VerificationMethod, err)
+		}
+		document.SetPurposeKey(key.VerificationMethod, key.Purpose, methodType, publicKey)
+	}
+	return nil
+}
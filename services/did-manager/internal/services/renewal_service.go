@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/egress"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// expiryBatchSize bounds the credentials handled per scheduler run
+const expiryBatchSize = 100
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the webhook body keyed with
+// the policy's webhook secret
+const WebhookSignatureHeader = "X-DID-Manager-Signature"
+
+// RenewalService detects credentials nearing expiry, notifies holders and issuers, and
+// reissues credentials whose issuer enabled auto-renewal for the tenant
+type RenewalService struct {
+	credentialRepo domain.CredentialRepository
+	policyRepo     domain.RenewalPolicyRepository
+	credentials    *CredentialService
+	// window is how long before expiry reminders are sent
+	window time.Duration
+	client *http.Client
+	// clock tells which credentials are nearing expiry and dates renewals
+	clock clock.Clock
+}
+
+// NewRenewalService creates a new renewal service
+func NewRenewalService(
+	credentialRepo domain.CredentialRepository,
+	policyRepo domain.RenewalPolicyRepository,
+	credentials *CredentialService,
+	window time.Duration,
+) *RenewalService {
+	return &RenewalService{
+		credentialRepo: credentialRepo,
+		policyRepo:     policyRepo,
+		credentials:    credentials,
+		window:         window,
+		client:         egress.Client(10 * time.Second),
+		clock:          clock.System,
+	}
+}
+
+// SetClock sets the time source of expiry handling, clock.System by default
+func (s *RenewalService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetPolicy configures expiry handling for the issuer's credentials under a tenant. A
+// webhook secret is generated when a webhook is first configured.
+func (s *RenewalService) SetPolicy(issuerDID string, req *domain.RenewalPolicyRequest) (*domain.RenewalPolicy, error) {
+	policy := &domain.RenewalPolicy{
+		IssuerDID:    issuerDID,
+		Tenant:       req.Tenant,
+		AutoRenew:    req.AutoRenew,
+		ValidityDays: req.ValidityDays,
+		WebhookURL:   req.WebhookURL,
+		UpdatedAt:    s.clock.Now(),
+	}
+
+	if policy.WebhookURL != "" {
+		if err := egress.CheckURL(policy.WebhookURL); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+		}
+		existing, err := s.policyRepo.Get(issuerDID, req.Tenant)
+		switch {
+		case err == nil && existing.WebhookSecret != "":
+			policy.WebhookSecret = existing.WebhookSecret
+		case err == nil, errors.Is(err, domain.ErrRenewalPolicyNotFound):
+			secret := make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+			}
+			policy.WebhookSecret = hex.EncodeToString(secret)
+		default:
+			return nil, err
+		}
+	}
+
+	if err := s.policyRepo.Upsert(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListPolicies lists the issuer's renewal policies
+func (s *RenewalService) ListPolicies(issuerDID string) ([]*domain.RenewalPolicy, error) {
+	policies, err := s.policyRepo.ListByIssuer(issuerDID)
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*domain.RenewalPolicy{}
+	}
+	return policies, nil
+}
+
+// ProcessExpiring handles credentials entering the reminder window: holders are
+// notified through the domain event stream, issuers through their webhook, and
+// credentials are reissued where auto-renewal is enabled
+func (s *RenewalService) ProcessExpiring() error {
+	now := s.clock.Now()
+	credentials, err := s.credentialRepo.ListExpiring(now.Add(s.window), expiryBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list expiring credentials: %w", err)
+	}
+
+	for _, credential := range credentials {
+		if err := s.handleExpiring(credential, now); err != nil {
+			log.Printf("Failed to handle expiry of credential %s: %v", credential.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// handleExpiring notifies about and optionally renews a single expiring credential
+func (s *RenewalService) handleExpiring(credential *domain.Credential, now time.Time) error {
+	policy, err := s.policy(credential.IssuerDID, credential.Tenant)
+	if err != nil {
+		return err
+	}
+
+	var renewedID *uuid.UUID
+	if policy != nil && policy.AutoRenew {
+		renewed, err := s.credentials.RenewCredential(credential, s.renewedExpiry(credential, policy, now))
+		if err != nil {
+			// Still remind the holder so the credential does not lapse silently
+			log.Printf("Failed to renew credential %s: %v", credential.ID, err)
+		} else {
+			renewedID = &renewed.ID
+			log.Printf("Renewed credential %s as %s", credential.ID, renewed.ID)
+		}
+	}
+
+	data := map[string]string{
+		"credential_id": credential.ID.String(),
+		"issuer":        credential.IssuerDID,
+		"type":          credential.Type,
+		"expires_at":    credential.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if renewedID != nil {
+		data["renewed_credential_id"] = renewedID.String()
+	}
+	s.credentials.publish(queue.EventCredentialExpiring, credential.SubjectDID, data)
+
+	if policy != nil && policy.WebhookURL != "" {
+		notice := &domain.ExpiryNotice{
+			Type:                queue.EventCredentialExpiring,
+			Credential:          credential,
+			RenewedCredentialID: renewedID,
+			SentAt:              now,
+		}
+		if err := s.deliverWebhook(policy, notice); err != nil {
+			log.Printf("Failed to deliver expiry webhook for credential %s: %v", credential.ID, err)
+		}
+	}
+
+	return s.credentialRepo.MarkExpiryNotified(credential.ID, now, renewedID)
+}
+
+// policy resolves the renewal policy for a tenant, falling back to the issuer's
+// default; nil means the issuer configured none
+func (s *RenewalService) policy(issuerDID, tenant string) (*domain.RenewalPolicy, error) {
+	policy, err := s.policyRepo.Get(issuerDID, tenant)
+	if errors.Is(err, domain.ErrRenewalPolicyNotFound) && tenant != "" {
+		policy, err = s.policyRepo.Get(issuerDID, "")
+	}
+	if errors.Is(err, domain.ErrRenewalPolicyNotFound) {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// renewedExpiry computes the expiry of a renewed credential, which starts where the
+// original ends (or now, if it already lapsed)
+func (s *RenewalService) renewedExpiry(credential *domain.Credential, policy *domain.RenewalPolicy, now time.Time) time.Time {
+	start := *credential.ExpiresAt
+	if start.Before(now) {
+		start = now
+	}
+	if policy.ValidityDays > 0 {
+		return start.AddDate(0, 0, policy.ValidityDays)
+	}
+	return start.Add(credential.ExpiresAt.Sub(credential.IssuedAt))
+}
+
+// deliverWebhook posts an expiry notice to the policy's webhook
+func (s *RenewalService) deliverWebhook(policy *domain.RenewalPolicy, notice *domain.ExpiryNotice) error {
+	body, err := json.Marshal(notice)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, policy.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	mac := hmac.New(sha256.New, []byte(policy.WebhookSecret))
+	mac.Write(body)
+	req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/blockchain"
+
+	"github.com/google/uuid"
+)
+
+// RegistryReader reads DID registrations from the registry contract
+type RegistryReader interface {
+	GetDIDRecord(userHash string) (*blockchain.OnChainDID, error)
+	RegisteredDIDs(fromBlock uint64) ([]*blockchain.OnChainDID, error)
+}
+
+// RepairService cross-checks dids, blockchain_jobs and the registry contract, reporting
+// and optionally repairing records that disagree
+type RepairService struct {
+	didRepo domain.DIDRepository
+	jobRepo domain.BlockchainJobRepository
+	// userHashes finds local DIDs by the user hashes registered on-chain
+	userHashes *UserHashIndexService
+	// registry is nil when the chain is unreachable, limiting checks to the database
+	registry RegistryReader
+	queue    JobPublisher
+	// fromBlock is where the scan for on-chain registrations starts
+	fromBlock uint64
+}
+
+// NewRepairService creates a new repair service
+func NewRepairService(
+	didRepo domain.DIDRepository,
+	jobRepo domain.BlockchainJobRepository,
+	userHashes *UserHashIndexService,
+	registry RegistryReader,
+	queue JobPublisher,
+	fromBlock uint64,
+) *RepairService {
+	return &RepairService{
+		didRepo:    didRepo,
+		jobRepo:    jobRepo,
+		userHashes: userHashes,
+		registry:   registry,
+		queue:      queue,
+		fromBlock:  fromBlock,
+	}
+}
+
+// Check reports every inconsistency found; with fix set, repairable findings are
+// repaired as they are found
+func (s *RepairService) Check(fix bool) (*domain.ConsistencyReport, error) {
+	report := &domain.ConsistencyReport{
+		CheckedAt:       time.Now(),
+		Fix:             fix,
+		OnChain:         s.registry != nil,
+		Inconsistencies: []*domain.Inconsistency{},
+	}
+
+	// Registration transactions by local user hash, recovered from registry events
+	registrations := make(map[string]*blockchain.OnChainDID)
+	if s.registry != nil {
+		registered, err := s.registry.RegisteredDIDs(s.fromBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list on-chain registrations: %w", err)
+		}
+		for _, record := range registered {
+			registrations[localUserHash(record.UserHash)] = record
+		}
+	}
+
+	if err := s.checkActive(report, registrations); err != nil {
+		return nil, err
+	}
+	if err := s.checkRevoked(report); err != nil {
+		return nil, err
+	}
+	if err := s.checkCompletedJobs(report, registrations); err != nil {
+		return nil, err
+	}
+	if err := s.checkMissingLocally(report, registrations); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Consistency check found %d inconsistencies, repaired %d (fix=%t, on_chain=%t)",
+		len(report.Inconsistencies), report.Repaired, fix, report.OnChain)
+	return report, nil
+}
+
+// checkActive finds active DIDs without a registration transaction or without an
+// active on-chain record
+func (s *RepairService) checkActive(report *domain.ConsistencyReport, registrations map[string]*blockchain.OnChainDID) error {
+	dids, err := s.didRepo.ListByStatus(domain.DIDStatusActive)
+	if err != nil {
+		return err
+	}
+	report.DIDsChecked += len(dids)
+
+	for _, record := range dids {
+		var onChain *blockchain.OnChainDID
+		if s.registry != nil {
+			onChain, err = s.registry.GetDIDRecord(record.UserHash)
+			if err != nil {
+				return fmt.Errorf("failed to read on-chain record of %s: %w", record.Did, err)
+			}
+		}
+
+		switch {
+		case record.BlockchainTx == "":
+			issue := didInconsistency(domain.InconsistencyActiveWithoutTx, record, "DID is active but has no registration transaction")
+			var repair func() error
+			switch registration := registrations[record.UserHash]; {
+			case registration != nil:
+				issue.Repair = "record registration transaction " + registration.TxHash
+				repair = func() error {
+					return s.didRepo.UpdateStatus(record.ID, domain.DIDStatus(record.Status), registration.TxHash)
+				}
+			case s.registry != nil && onChain == nil:
+				issue.Repair = "mark pending and re-register on-chain"
+				repair = s.reregister(record)
+			case onChain != nil:
+				issue.Detail += "; registered on-chain before the scanned block range"
+			}
+			s.record(report, issue, repair)
+		case s.registry != nil && onChain == nil:
+			issue := didInconsistency(domain.InconsistencyNotOnChain, record, "DID is active but the registry has no record of it")
+			issue.Repair = "mark pending and re-register on-chain"
+			s.record(report, issue, s.reregister(record))
+		case onChain != nil && onChain.Revoked:
+			// Revocation cannot be undone on-chain; an operator decides which side is right
+			s.record(report, didInconsistency(domain.InconsistencyNotOnChain, record, "DID is active but revoked on-chain"), nil)
+		}
+	}
+
+	return nil
+}
+
+// checkRevoked finds revoked DIDs whose revocation never reached the chain
+func (s *RepairService) checkRevoked(report *domain.ConsistencyReport) error {
+	dids, err := s.didRepo.ListByStatus(domain.DIDStatusRevoked)
+	if err != nil {
+		return err
+	}
+	report.DIDsChecked += len(dids)
+
+	if s.registry == nil {
+		return nil
+	}
+
+	for _, record := range dids {
+		onChain, err := s.registry.GetDIDRecord(record.UserHash)
+		if err != nil {
+			return fmt.Errorf("failed to read on-chain record of %s: %w", record.Did, err)
+		}
+		if onChain == nil || onChain.Revoked {
+			continue
+		}
+
+		issue := didInconsistency(domain.InconsistencyRevocationNotOnChain, record, "DID is revoked but still active on-chain")
+		issue.Repair = "revoke on-chain"
+		s.record(report, issue, func() error {
+			_, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeRevokeDID, record)
+			return err
+		})
+	}
+
+	return nil
+}
+
+// checkCompletedJobs finds DIDs left pending after their blockchain job completed
+func (s *RepairService) checkCompletedJobs(report *domain.ConsistencyReport, registrations map[string]*blockchain.OnChainDID) error {
+	jobs, err := s.jobRepo.ListCompletedWithPendingDID()
+	if err != nil {
+		return err
+	}
+	report.JobsChecked += len(jobs)
+
+	// Only the latest completed job decides the status a DID should have
+	latest := make(map[uuid.UUID]*domain.BlockchainJob)
+	var order []uuid.UUID
+	for _, job := range jobs {
+		if _, seen := latest[job.DIDID]; !seen {
+			order = append(order, job.DIDID)
+		}
+		latest[job.DIDID] = job
+	}
+
+	for _, didID := range order {
+		job := latest[didID]
+		record, err := s.didRepo.GetByID(didID)
+		if err != nil {
+			return err
+		}
+
+		issue := didInconsistency(domain.InconsistencyCompletedJobPendingDID, record,
+			fmt.Sprintf("%s job completed but DID is still pending", job.JobType))
+		issue.JobID = &job.ID
+
+		var onChain *blockchain.OnChainDID
+		if s.registry != nil {
+			onChain, err = s.registry.GetDIDRecord(record.UserHash)
+			if err != nil {
+				return fmt.Errorf("failed to read on-chain record of %s: %w", record.Did, err)
+			}
+		}
+
+		if s.registry != nil && onChain == nil {
+			issue.Detail += "; the registry has no record of it"
+			issue.Repair = "re-register on-chain"
+			s.record(report, issue, func() error {
+				_, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeRegisterDID, record)
+				return err
+			})
+			continue
+		}
+
+		status := domain.DIDStatusActive
+		if job.JobType == string(domain.JobTypeRevokeDID) || (onChain != nil && onChain.Revoked) {
+			status = domain.DIDStatusRevoked
+		}
+		txHash := record.BlockchainTx
+		if registration := registrations[record.UserHash]; txHash == "" && registration != nil {
+			txHash = registration.TxHash
+		}
+
+		issue.Repair = "mark " + string(status)
+		s.record(report, issue, func() error {
+			return s.didRepo.UpdateStatus(record.ID, status, txHash)
+		})
+	}
+
+	return nil
+}
+
+// checkMissingLocally finds on-chain registrations with no local DID. These need manual
+// attention: the DID's keys and identity data cannot be recovered from the chain.
+func (s *RepairService) checkMissingLocally(report *domain.ConsistencyReport, registrations map[string]*blockchain.OnChainDID) error {
+	for userHash, registration := range registrations {
+		_, err := s.userHashes.GetDID(userHash)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, domain.ErrDIDNotFound) {
+			return err
+		}
+
+		s.record(report, &domain.Inconsistency{
+			Kind:     domain.InconsistencyMissingLocally,
+			DID:      registration.DID,
+			UserHash: userHash,
+			Detail:   fmt.Sprintf("registered on-chain in transaction %s but unknown locally", registration.TxHash),
+		}, nil)
+	}
+
+	return nil
+}
+
+// reregister returns a repair that marks a DID pending and queues its registration
+func (s *RepairService) reregister(record *domain.DID) func() error {
+	return func() error {
+		if err := s.didRepo.UpdateStatus(record.ID, domain.DIDStatusPending, ""); err != nil {
+			return err
+		}
+		_, err := enqueueDIDJob(s.jobRepo, s.queue, domain.JobTypeRegisterDID, record)
+		return err
+	}
+}
+
+// record adds a finding to the report, applying its repair when fixing is enabled
+func (s *RepairService) record(report *domain.ConsistencyReport, issue *domain.Inconsistency, repair func() error) {
+	report.Inconsistencies = append(report.Inconsistencies, issue)
+	if !report.Fix || repair == nil {
+		return
+	}
+
+	if err := repair(); err != nil {
+		issue.Error = err.Error()
+		log.Printf("Failed to repair %s for %s: %v", issue.Kind, issue.DID, err)
+		return
+	}
+	issue.Repaired = true
+	report.Repaired++
+	log.Printf("AUDIT: repaired %s for %s: %s", issue.Kind, issue.DID, issue.Repair)
+}
+
+// didInconsistency describes a finding about a local DID
+func didInconsistency(kind domain.InconsistencyKind, record *domain.DID, detail string) *domain.Inconsistency {
+	return &domain.Inconsistency{
+		Kind:     kind,
+		DIDID:    &record.ID,
+		DID:      record.Did,
+		UserHash: record.UserHash,
+		Detail:   detail,
+	}
+}
+
+// localUserHash converts an on-chain bytes32 user hash to the stored hex form
+func localUserHash(userHash string) string {
+	return strings.TrimPrefix(strings.ToLower(userHash), "0x")
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// QueuePromoter switches a standby's queue connection over to the primary streams
+type QueuePromoter interface {
+	Promote() error
+}
+
+// ReplicationService tracks the deployment's role in an active-passive pair. A standby
+// is read-only: writes are rejected and background workers pause until it is promoted.
+type ReplicationService struct {
+	repo domain.ReplicationRepository
+	// queue is nil when the queue is not mirrored
+	queue        QueuePromoter
+	region       string
+	subscription string
+	mirrorDomain string
+
+	mu         sync.RWMutex
+	role       domain.ReplicationRole
+	promotedAt *time.Time
+	onPromote  []func() error
+}
+
+// NewReplicationService creates a new replication service. subscription names the
+// logical replication subscription disabled on promotion, and mirrorDomain the
+// JetStream domain the queue mirrors; either may be empty.
+func NewReplicationService(
+	repo domain.ReplicationRepository,
+	queue QueuePromoter,
+	role domain.ReplicationRole,
+	region string,
+	subscription string,
+	mirrorDomain string,
+) *ReplicationService {
+	return &ReplicationService{
+		repo:         repo,
+		queue:        queue,
+		role:         role,
+		region:       region,
+		subscription: subscription,
+		mirrorDomain: mirrorDomain,
+	}
+}
+
+// ReadOnly reports whether the deployment is a standby
+func (s *ReplicationService) ReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.role == domain.ReplicationRoleStandby
+}
+
+// OnPromote registers a function run once the standby is promoted, e.g. to start a
+// component that only runs on the primary. Errors are logged without undoing the
+// promotion.
+func (s *ReplicationService) OnPromote(fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPromote = append(s.onPromote, fn)
+}
+
+// Status reports the role and, when configured, the replication subscription
+func (s *ReplicationService) Status() (*domain.ReplicationStatus, error) {
+	s.mu.RLock()
+	status := &domain.ReplicationStatus{
+		Role:              s.role,
+		Region:            s.region,
+		ReadOnly:          s.role == domain.ReplicationRoleStandby,
+		QueueMirrorDomain: s.mirrorDomain,
+		PromotedAt:        s.promotedAt,
+	}
+	s.mu.RUnlock()
+
+	if s.subscription != "" {
+		subscription, err := s.repo.GetSubscription(s.subscription)
+		if err != nil && !errors.Is(err, domain.ErrSubscriptionNotFound) {
+			return nil, err
+		}
+		status.Subscription = subscription
+	}
+
+	return status, nil
+}
+
+// Promote makes a standby the primary: the replication subscription stops applying the
+// old primary's changes, the queue switches to its own streams, then writes and
+// background workers resume. Only promote once the old primary is fenced off.
+func (s *ReplicationService) Promote() (*domain.ReplicationStatus, error) {
+	s.mu.Lock()
+	if s.role != domain.ReplicationRoleStandby {
+		s.mu.Unlock()
+		return nil, domain.ErrNotStandby
+	}
+
+	if s.subscription != "" {
+		err := s.repo.DisableSubscription(s.subscription)
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			// Already dropped by an operator; nothing is applying changes
+			log.Printf("Replication subscription %s not found, promoting without detaching it", s.subscription)
+		} else if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	if s.queue != nil {
+		if err := s.queue.Promote(); err != nil {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("failed to promote queue: %w", err)
+		}
+	}
+
+	now := time.Now()
+	s.role = domain.ReplicationRolePrimary
+	s.promotedAt = &now
+	onPromote := s.onPromote
+	s.mu.Unlock()
+
+	log.Printf("AUDIT: deployment in region %q promoted to primary", s.region)
+
+	for _, fn := range onPromote {
+		if err := fn(); err != nil {
+			log.Printf("Failed to resume after promotion: %v", err)
+		}
+	}
+
+	return s.Status()
+}
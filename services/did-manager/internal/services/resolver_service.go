@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+	"did-manager/pkg/sidetree"
+)
+
+// ResolverService resolves DIDs managed by this service into DID Documents, and
+// did:ion DIDs through an external resolver when one is configured
+type ResolverService struct {
+	access   *AccessService
+	registry *did.Registry
+	// ion resolves did:ion DIDs; nil when did:ion is not supported
+	ion *sidetree.Resolver
+	// smartAccounts lists the smart accounts controlling DIDs; nil when disabled
+	smartAccounts domain.SmartAccountRepository
+	// deviceKeys lists device subkeys under authentication; nil when disabled
+	deviceKeys *DeviceKeyService
+	// purposeKeys lists the purpose keys of DIDs under their purposes; nil when disabled
+	purposeKeys *PurposeKeyService
+	// claims versions the documents of DIDs claims are added to; nil when disabled
+	claims domain.ClaimRepository
+	// keyFormat is the representation of keys in documents of DIDs managed here
+	keyFormat did.KeyFormat
+}
+
+// NewResolverService creates a new resolver service serving keys as publicKeyMultibase
+func NewResolverService(access *AccessService, registry *did.Registry) *ResolverService {
+	return &ResolverService{
+		access:    access,
+		registry:  registry,
+		keyFormat: did.KeyFormatMultibase,
+	}
+}
+
+// SetKeyFormat sets the default representation of keys in documents of DIDs managed
+// here
+func (s *ResolverService) SetKeyFormat(format did.KeyFormat) {
+	s.keyFormat = format
+}
+
+// SetIONResolver enables resolving did:ion DIDs through resolver
+func (s *ResolverService) SetIONResolver(resolver *sidetree.Resolver) {
+	s.ion = resolver
+}
+
+// SetSmartAccounts makes the ERC-4337 smart account bound to a DID the controller of
+// its document
+func (s *ResolverService) SetSmartAccounts(accounts domain.SmartAccountRepository) {
+	s.smartAccounts = accounts
+}
+
+// SetDeviceKeys lists the active device keys of a DID in its document
+func (s *ResolverService) SetDeviceKeys(keys *DeviceKeyService) {
+	s.deviceKeys = keys
+}
+
+// SetPurposeKeys lists the purpose keys of a DID in its document under their purposes
+func (s *ResolverService) SetPurposeKeys(keys *PurposeKeyService) {
+	s.purposeKeys = keys
+}
+
+// SetClaims sets the versionId of a DID's document to its claims version once claims
+// are added to it
+func (s *ResolverService) SetClaims(claims domain.ClaimRepository) {
+	s.claims = claims
+}
+
+// Resolves reports whether DIDs of a method can be resolved: the DIDs managed here,
+// and did:ion when an ION resolver is configured
+func (s *ResolverService) Resolves(method domain.DIDMethod) bool {
+	switch method {
+	case domain.DIDMethodExample:
+		return true
+	case domain.DIDMethodION:
+		return s.ion != nil
+	default:
+		return false
+	}
+}
+
+// Resolve resolves a DID into its document, enforcing the DID's access control list
+func (s *ResolverService) Resolve(didString string, caller *domain.Caller) (*domain.DIDResolutionResult, error) {
+	return s.ResolveWithKeyFormat(didString, caller, s.keyFormat)
+}
+
+// ResolveWithKeyFormat resolves a DID like Resolve with the keys of DIDs managed here
+// in a given representation, the default one when empty. Documents of external DIDs
+// keep their own.
+func (s *ResolverService) ResolveWithKeyFormat(didString string, caller *domain.Caller, format did.KeyFormat) (*domain.DIDResolutionResult, error) {
+	if format == "" {
+		format = s.keyFormat
+	}
+	if s.ion != nil && sidetree.IsION(didString) {
+		return s.resolveION(didString)
+	}
+
+	record, err := s.access.CheckDIDAccess(didString, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := s.registry.SignatureSuite(record.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DID key: %w", err)
+	}
+
+	document := did.NewDocument(record.Did, suite.VerificationMethodType(), publicKey)
+	if s.smartAccounts != nil {
+		account, err := s.smartAccounts.GetByDIDID(record.ID)
+		switch {
+		case err == nil:
+			document.SetAccountController(fmt.Sprintf("eip155:%d:%s", account.ChainID, account.Address))
+		case !errors.Is(err, domain.ErrSmartAccountNotFound):
+			return nil, err
+		}
+	}
+	// Purpose keys replace the DID's own key under authentication before device keys
+	// are added there
+	if s.purposeKeys != nil {
+		if err := s.purposeKeys.AddToDocument(record, document); err != nil {
+			return nil, err
+		}
+	}
+	if s.deviceKeys != nil {
+		if err := s.deviceKeys.AddToDocument(record, document); err != nil {
+			return nil, err
+		}
+	}
+	if err := document.EncodeKeys(format); err != nil {
+		return nil, err
+	}
+
+	metadata := domain.DIDDocumentMetadata{
+		Created:      &record.CreatedAt,
+		Updated:      &record.UpdatedAt,
+		Status:       record.Status,
+		Deactivated:  record.Status == string(domain.DIDStatusRevoked),
+		BlockchainTx: record.BlockchainTx,
+	}
+	// The document keeps the legacy identifier credentials refer to; its alias, the
+	// identifier in the current format, is equivalent
+	if record.AliasDID != "" {
+		document.AlsoKnownAs = []string{record.AliasDID}
+		metadata.EquivalentID = []string{record.AliasDID}
+	}
+	if didString != record.Did {
+		metadata.CanonicalID = record.Did
+	}
+	if s.claims != nil {
+		version, err := s.claims.Version(record.ID)
+		if err != nil {
+			return nil, err
+		}
+		if version > 0 {
+			metadata.VersionID = strconv.Itoa(version)
+		}
+	}
+
+	return &domain.DIDResolutionResult{
+		Document:         document,
+		DocumentMetadata: metadata,
+		ResolutionMetadata: domain.DIDResolutionMetadata{
+			ContentType: "application/did+ld+json",
+		},
+	}, nil
+}
+
+// resolveION resolves a did:ion DID through the external resolver
+func (s *ResolverService) resolveION(didString string) (*domain.DIDResolutionResult, error) {
+	resolution, err := s.ion.Resolve(context.Background(), didString)
+	if err != nil {
+		if errors.Is(err, sidetree.ErrDIDNotFound) || errors.Is(err, sidetree.ErrInvalidDID) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrDIDNotFound, err)
+		}
+		return nil, fmt.Errorf("failed to resolve did:ion DID: %w", err)
+	}
+
+	status := "published"
+	switch {
+	case resolution.Metadata.Deactivated:
+		status = "deactivated"
+	case !resolution.Metadata.Method.Published:
+		status = "unpublished"
+	}
+
+	return &domain.DIDResolutionResult{
+		Document: resolution.Document,
+		DocumentMetadata: domain.DIDDocumentMetadata{
+			Status:      status,
+			Deactivated: resolution.Metadata.Deactivated,
+			CanonicalID: resolution.Metadata.CanonicalID,
+		},
+		ResolutionMetadata: domain.DIDResolutionMetadata{
+			ContentType: "application/did+ld+json",
+		},
+	}, nil
+}
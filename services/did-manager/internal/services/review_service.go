@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// defaultReviewLimit bounds review listings without an explicit limit
+const defaultReviewLimit = 100
+
+// ReviewService lets administrators decide on operations held for manual review by
+// screening, duplicate detection or other plugin hooks. Approved DIDs continue to
+// on-chain registration through the normal job pipeline and approved credentials
+// become valid; rejected ones are marked rejected.
+type ReviewService struct {
+	repo        domain.ReviewRepository
+	dids        *DIDService
+	credentials *CredentialService
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(repo domain.ReviewRepository, dids *DIDService, credentials *CredentialService) *ReviewService {
+	return &ReviewService{
+		repo:        repo,
+		dids:        dids,
+		credentials: credentials,
+	}
+}
+
+// List lists the review queue, pending reviews unless another status is requested
+func (s *ReviewService) List(req *domain.ReviewListRequest) ([]*domain.Review, error) {
+	status := req.Status
+	switch status {
+	case "":
+		status = string(domain.ReviewStatusPending)
+	case "all":
+		status = ""
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultReviewLimit
+	}
+
+	reviews, err := s.repo.List(status, req.Operation, limit)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []*domain.Review{}
+	}
+	return reviews, nil
+}
+
+// Get retrieves a review
+func (s *ReviewService) Get(id uuid.UUID) (*domain.Review, error) {
+	return s.repo.GetByID(id)
+}
+
+// Resolve records an administrator's decision on a pending review and releases or
+// rejects the held operation. The review is resolved first so that concurrent
+// decisions cannot release an operation twice.
+func (s *ReviewService) Resolve(id uuid.UUID, req *domain.ReviewResolveRequest) (*domain.Review, error) {
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.ReviewStatus(req.Decision)
+	if err := s.repo.Resolve(id, status, req.Note, time.Now()); err != nil {
+		return nil, err
+	}
+
+	approved := status == domain.ReviewStatusApproved
+	switch review.Operation {
+	case domain.ReviewOperationCreateDID:
+		err = s.dids.releaseReviewedDID(review.TargetID, approved)
+	case domain.ReviewOperationIssueCredential:
+		err = s.credentials.releaseReviewedCredential(review.TargetID, approved)
+	default:
+		err = fmt.Errorf("unknown review operation: %s", review.Operation)
+	}
+	if err != nil {
+		log.Printf("AUDIT: review %s resolved as %s but %s %s was not released: %v", id, status, review.Operation, review.Target, err)
+		return nil, fmt.Errorf("failed to release %s: %w", review.Operation, err)
+	}
+
+	log.Printf("AUDIT: review %s of %s %s resolved as %s", id, review.Operation, review.Target, status)
+	return s.repo.GetByID(id)
+}
+
+// errNoReviewQueue is returned when an operation is flagged but no review queue is configured
+var errNoReviewQueue = fmt.Errorf("%w: no review queue is configured", domain.ErrReviewRequired)
+
+// queueReview holds an operation on target for manual review
+func queueReview(repo domain.ReviewRepository, operation domain.ReviewOperation, targetID uuid.UUID, target string, reasons []string) error {
+	review := &domain.Review{
+		ID:        uuid.New(),
+		Operation: operation,
+		TargetID:  targetID,
+		Target:    target,
+		Reasons:   reasons,
+		Status:    string(domain.ReviewStatusPending),
+		CreatedAt: time.Now(),
+	}
+	if err := repo.Create(review); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: %s of %s held for review %s: %s", operation, target, review.ID, strings.Join(reasons, "; "))
+	return nil
+}
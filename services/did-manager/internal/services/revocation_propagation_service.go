@@ -0,0 +1,136 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/queue"
+)
+
+// RevocationPropagationService notifies relying parties when a DID or credential they
+// verified is revoked. Valid verifications by authenticated callers are recorded in a
+// verification log: DID verifications through the DIDVerificationHook, credential
+// and presentation verifications by the handlers and verification sessions. When a
+// revocation event arrives, every relying party that verified the subject within the
+// propagation window receives a revocation notice at its webhook endpoints.
+// Recording failures are logged and never fail a verification.
+type RevocationPropagationService struct {
+	repo     domain.VerificationLogRepository
+	webhooks *WebhookService
+	window   time.Duration
+}
+
+// NewRevocationPropagationService creates a new revocation propagation service
+func NewRevocationPropagationService(repo domain.VerificationLogRepository, webhooks *WebhookService, window time.Duration) *RevocationPropagationService {
+	return &RevocationPropagationService{
+		repo:     repo,
+		webhooks: webhooks,
+		window:   window,
+	}
+}
+
+// BeforeVerifyDID does nothing; verifications are recorded once they succeed
+func (s *RevocationPropagationService) BeforeVerifyDID(*domain.DIDVerificationRequest) error {
+	return nil
+}
+
+// AfterVerifyDID records a valid DID verification by its relying party
+func (s *RevocationPropagationService) AfterVerifyDID(req *domain.DIDVerificationRequest, response *domain.DIDVerificationResponse) {
+	if !response.IsValid {
+		return
+	}
+	s.RecordVerification(&domain.Caller{Type: req.TenantType, ID: req.Tenant}, domain.VerifiedSubjectDID, req.DID)
+}
+
+// RecordVerification records that a relying party verified DIDs or credentials.
+// Credentials are given by their stored ID or document ID. Anonymous callers are not
+// recorded, as they cannot receive webhooks. A nil service records nothing.
+func (s *RevocationPropagationService) RecordVerification(verifier *domain.Caller, kind domain.VerifiedSubjectKind, subjects ...string) {
+	if s == nil || verifier == nil || verifier.IsAnonymous() || verifier.ID == "" {
+		return
+	}
+
+	now := time.Now()
+	for _, subject := range subjects {
+		if kind == domain.VerifiedSubjectCredential {
+			subject = strings.TrimPrefix(subject, "urn:uuid:")
+		}
+		if subject == "" {
+			continue
+		}
+
+		record := &domain.VerificationRecord{
+			VerifierType:    verifier.Type,
+			VerifierID:      verifier.ID,
+			SubjectKind:     kind,
+			Subject:         subject,
+			FirstVerifiedAt: now,
+			LastVerifiedAt:  now,
+		}
+		if err := s.repo.Record(record); err != nil {
+			log.Printf("Warning: failed to record verification of %s %s by %s: %v", kind, subject, verifier.ID, err)
+		}
+	}
+}
+
+// HandleEvent notifies the relying parties of a revoked credential or DID. Other
+// events are ignored.
+func (s *RevocationPropagationService) HandleEvent(event *queue.Event) error {
+	var kind domain.VerifiedSubjectKind
+	var subject string
+	switch {
+	case event.Type == queue.EventCredentialRevoked:
+		kind, subject = domain.VerifiedSubjectCredential, event.Data["credential_id"]
+	case event.Type == queue.EventDIDStatusChanged && event.Data["status"] == string(domain.DIDStatusRevoked):
+		kind, subject = domain.VerifiedSubjectDID, event.Subject
+	default:
+		return nil
+	}
+	if subject == "" {
+		return nil
+	}
+
+	verifiers, err := s.repo.ListVerifiers(kind, subject, event.OccurredAt.Add(-s.window))
+	if err != nil {
+		return err
+	}
+
+	for _, verifier := range verifiers {
+		notice := &queue.Event{
+			// Derived from the revocation, so redelivered events are notified once
+			ID:      event.ID + ":notice",
+			Type:    queue.EventRevocationNotice,
+			Subject: event.Subject,
+			Data: map[string]string{
+				"subject_kind":     string(kind),
+				"subject":          subject,
+				"revocation_event": event.ID,
+				"last_verified_at": verifier.LastVerifiedAt.UTC().Format(time.RFC3339),
+			},
+			OccurredAt: event.OccurredAt,
+		}
+		for _, key := range []string{"issuer", "type"} {
+			if value := event.Data[key]; value != "" {
+				notice.Data[key] = value
+			}
+		}
+
+		tenant := &domain.Caller{Type: verifier.VerifierType, ID: verifier.VerifierID}
+		if err := s.webhooks.Notify(tenant, notice); err != nil {
+			return err
+		}
+	}
+
+	if len(verifiers) > 0 {
+		log.Printf("AUDIT: revocation of %s %s propagated to %d relying parties", kind, subject, len(verifiers))
+	}
+	return nil
+}
+
+// Prune removes verifications older than the propagation window, whose relying
+// parties are no longer notified
+func (s *RevocationPropagationService) Prune() error {
+	return s.repo.DeleteBefore(time.Now().Add(-s.window))
+}
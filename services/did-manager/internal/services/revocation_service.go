@@ -0,0 +1,188 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/smt"
+
+	"github.com/google/uuid"
+)
+
+// RevocationAnchor publishes revocation accumulator roots on-chain; implemented by the
+// Ethereum client
+type RevocationAnchor interface {
+	PublishRevocationRoot(root [32]byte, batch uint64, revokedCount int) (string, error)
+	RevocationRegistry() string
+}
+
+// RevocationService maintains the revocation accumulator: a sparse Merkle tree of
+// revoked credential IDs whose root is anchored on-chain per batch, so verifiers can
+// check non-revocation proofs against the chain instead of trusting the status API.
+type RevocationService struct {
+	credentialRepo domain.CredentialRepository
+	batchRepo      domain.RevocationBatchRepository
+	anchor         RevocationAnchor
+
+	// processMu serializes batch creation so each batch's root extends the previous one
+	processMu sync.Mutex
+
+	// The tree for the latest anchored batch is cached, as proofs are served far more
+	// often than batches are anchored
+	treeMu       sync.Mutex
+	tree         *smt.Tree
+	treeSequence int64
+}
+
+// NewRevocationService creates a new revocation service; anchor may be nil, in which
+// case batches accumulate until an anchor is available
+func NewRevocationService(credentialRepo domain.CredentialRepository, batchRepo domain.RevocationBatchRepository, anchor RevocationAnchor) *RevocationService {
+	return &RevocationService{
+		credentialRepo: credentialRepo,
+		batchRepo:      batchRepo,
+		anchor:         anchor,
+	}
+}
+
+// CredentialKey returns the accumulator key of a stored credential, derived from the
+// ID in its signed document
+func CredentialKey(id uuid.UUID) smt.Hash {
+	return smt.Key("urn:uuid:" + id.String())
+}
+
+// ProcessBatch folds new revocations into a batch and anchors every pending batch in
+// sequence order, stopping at the first failure so roots are published in order
+func (s *RevocationService) ProcessBatch() error {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	if err := s.createBatch(); err != nil {
+		return err
+	}
+
+	if s.anchor == nil {
+		return nil
+	}
+
+	pending, err := s.batchRepo.ListPending()
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range pending {
+		txHash, err := s.anchor.PublishRevocationRoot(batch.Root, uint64(batch.Sequence), batch.RevokedCount)
+		if err != nil {
+			if markErr := s.batchRepo.MarkFailed(batch.ID, err.Error()); markErr != nil {
+				log.Printf("Warning: failed to record revocation batch failure: %v", markErr)
+			}
+			return fmt.Errorf("failed to anchor revocation batch %d: %w", batch.Sequence, err)
+		}
+
+		if err := s.batchRepo.MarkAnchored(batch.ID, txHash, time.Now()); err != nil {
+			return err
+		}
+		log.Printf("Anchored revocation batch %d with root %s in tx %s", batch.Sequence, batch.Root.Hex(), txHash)
+	}
+
+	return nil
+}
+
+// createBatch assigns revocations not yet in a batch to a new batch whose root covers
+// them and every previously batched revocation
+func (s *RevocationService) createBatch() error {
+	unbatched, err := s.credentialRepo.ListUnbatchedRevoked()
+	if err != nil {
+		return err
+	}
+	if len(unbatched) == 0 {
+		return nil
+	}
+
+	batched, err := s.credentialRepo.ListRevokedThroughBatch(-1)
+	if err != nil {
+		return err
+	}
+
+	tree := buildRevocationTree(append(batched, unbatched...))
+	batch := &domain.RevocationBatch{
+		ID:           uuid.New(),
+		Root:         tree.Root(),
+		RevokedCount: tree.Len(),
+		Status:       string(domain.RevocationBatchPending),
+		CreatedAt:    time.Now(),
+	}
+
+	return s.batchRepo.Create(batch, unbatched)
+}
+
+// LatestRoot returns the most recently anchored batch
+func (s *RevocationService) LatestRoot() (*domain.RevocationBatch, error) {
+	return s.batchRepo.GetLatestAnchored()
+}
+
+// Proof proves a credential's revocation state against the latest anchored root.
+// Revocations that are not anchored yet are not reflected.
+func (s *RevocationService) Proof(credentialID uuid.UUID) (*domain.RevocationProof, error) {
+	batch, err := s.batchRepo.GetLatestAnchored()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.treeFor(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	key := CredentialKey(credentialID)
+	proof := &domain.RevocationProof{
+		CredentialID: "urn:uuid:" + credentialID.String(),
+		Key:          key,
+		Revoked:      tree.Contains(key),
+		Root:         batch.Root,
+		Batch:        batch.Sequence,
+		TxHash:       batch.TxHash,
+		AnchoredAt:   batch.AnchoredAt,
+		Proof:        tree.Prove(key),
+	}
+	if s.anchor != nil {
+		proof.Contract = s.anchor.RevocationRegistry()
+	}
+
+	return proof, nil
+}
+
+// treeFor returns the accumulator tree as of an anchored batch
+func (s *RevocationService) treeFor(batch *domain.RevocationBatch) (*smt.Tree, error) {
+	s.treeMu.Lock()
+	defer s.treeMu.Unlock()
+
+	if s.tree != nil && s.treeSequence == batch.Sequence {
+		return s.tree, nil
+	}
+
+	ids, err := s.credentialRepo.ListRevokedThroughBatch(batch.Sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := buildRevocationTree(ids)
+	if tree.Root() != batch.Root {
+		return nil, fmt.Errorf("revocation tree for batch %d does not match its anchored root", batch.Sequence)
+	}
+
+	s.tree = tree
+	s.treeSequence = batch.Sequence
+	return tree, nil
+}
+
+// buildRevocationTree builds the accumulator over revoked credential IDs
+func buildRevocationTree(ids []uuid.UUID) *smt.Tree {
+	keys := make([]smt.Hash, len(ids))
+	for i, id := range ids {
+		keys[i] = CredentialKey(id)
+	}
+	return smt.New(keys)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// SandboxService manages tenant sandboxes. A sandbox API key works in its own
+// partition: the DIDs it creates are visible only to it and to those DIDs, anchoring is
+// simulated, usage is not metered and lookups have relaxed rate limits. Resetting a
+// sandbox deletes its data so integrators can start over.
+type SandboxService struct {
+	didRepo domain.DIDRepository
+}
+
+// NewSandboxService creates a new sandbox service
+func NewSandboxService(didRepo domain.DIDRepository) *SandboxService {
+	return &SandboxService{didRepo: didRepo}
+}
+
+// Reset deletes the DIDs of the caller's own sandbox and the credentials they issued or
+// hold. Only sandbox API keys may reset their sandbox.
+func (s *SandboxService) Reset(caller *domain.Caller) (*domain.SandboxReset, error) {
+	if caller.Type != domain.CallerTypeAPIKey || caller.SandboxTenant != caller.ID {
+		return nil, domain.ErrNotSandbox
+	}
+	return s.ResetTenant(caller.SandboxTenant)
+}
+
+// ResetTenant deletes the data of a sandbox partition on behalf of an administrator
+func (s *SandboxService) ResetTenant(sandboxTenant string) (*domain.SandboxReset, error) {
+	if sandboxTenant == "" {
+		return nil, domain.ErrNotSandbox
+	}
+
+	deleted, err := s.didRepo.DeleteSandbox(sandboxTenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset sandbox: %w", err)
+	}
+
+	log.Printf("AUDIT: sandbox %s reset, %d DIDs deleted", sandboxTenant, deleted)
+	return &domain.SandboxReset{
+		SandboxTenant: sandboxTenant,
+		DeletedDIDs:   deleted,
+		ResetAt:       time.Now(),
+	}, nil
+}
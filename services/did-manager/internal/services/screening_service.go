@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/screening"
+)
+
+// screeningTimeout bounds a single provider lookup
+const screeningTimeout = 10 * time.Second
+
+// ScreeningService screens identities against a sanctions or denylist provider before
+// DIDs are created and credentials issued. It is registered as a plugin hook: rejected
+// identities fail the operation, flagged ones are held for manual review, and every
+// decision is written to the audit log. Screening fails closed when the provider is
+// unavailable.
+type ScreeningService struct {
+	provider screening.Provider
+}
+
+// NewScreeningService creates a new screening service
+func NewScreeningService(provider screening.Provider) *ScreeningService {
+	return &ScreeningService{provider: provider}
+}
+
+// BeforeCreateDID screens the name and email the DID is created for
+func (s *ScreeningService) BeforeCreateDID(req *domain.DIDCreateRequest) error {
+	// Anonymous DIDs carry no identity to screen
+	if req.Anonymous {
+		return nil
+	}
+	return s.screen("DID creation", "user "+req.UserID.String(), screening.Subject{
+		Name:  req.Name,
+		Email: req.Email,
+	})
+}
+
+// AfterCreateDID implements DIDCreationHook
+func (s *ScreeningService) AfterCreateDID(*domain.DIDCreateRequest, *domain.DIDResponse) {}
+
+// BeforeIssueCredential screens the credential subject's DID and any name and email claims
+func (s *ScreeningService) BeforeIssueCredential(issuer *domain.DID, req *domain.CredentialIssueRequest) error {
+	subject := screening.Subject{DID: req.SubjectDID}
+	if name, ok := req.Claims["name"].(string); ok {
+		subject.Name = name
+	}
+	if email, ok := req.Claims["email"].(string); ok {
+		subject.Email = email
+	}
+
+	return s.screen("credential issuance by "+issuer.Did, "subject "+req.SubjectDID, subject)
+}
+
+// AfterIssueCredential implements CredentialIssuanceHook
+func (s *ScreeningService) AfterIssueCredential(*domain.DID, *domain.Credential) {}
+
+// screen asks the provider for a decision and records it in the audit log
+func (s *ScreeningService) screen(operation, identity string, subject screening.Subject) error {
+	ctx, cancel := context.WithTimeout(context.Background(), screeningTimeout)
+	defer cancel()
+
+	result, err := s.provider.Screen(ctx, subject)
+	if err != nil {
+		log.Printf("AUDIT: screening for %s of %s failed with %s, rejecting: %v", operation, identity, s.provider.Name(), err)
+		return fmt.Errorf("screening unavailable: %w", err)
+	}
+
+	log.Printf("AUDIT: screening for %s of %s by %s: %s %s", operation, identity, s.provider.Name(), result.Decision, result.Reason)
+
+	switch result.Decision {
+	case screening.DecisionReject:
+		return fmt.Errorf("%w: %s", domain.ErrIdentityRejected, result.Reason)
+	case screening.DecisionFlag:
+		return fmt.Errorf("%w: screening flagged %s", domain.ErrReviewRequired, result.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/sidetree"
+
+	"github.com/google/uuid"
+)
+
+// SidetreeAnchor writes the anchor of a Sidetree batch on-chain
+type SidetreeAnchor interface {
+	AnchorSidetreeBatch(ctx context.Context, anchorFileHash [32]byte, operationCount int) (string, error)
+	// SidetreeAnchor returns the address of the anchor contract
+	SidetreeAnchor() string
+}
+
+// sidetreeOperations maps the DID operation jobs to Sidetree operation types
+var sidetreeOperations = map[string]sidetree.OperationType{
+	string(domain.JobTypeRegisterDID): sidetree.OperationCreate,
+	string(domain.JobTypeUpdateDID):   sidetree.OperationUpdate,
+	string(domain.JobTypeRevokeDID):   sidetree.OperationDeactivate,
+}
+
+// sidetreeOperationRank orders operation types the way a batch lists them
+var sidetreeOperationRank = map[sidetree.OperationType]int{
+	sidetree.OperationCreate:     0,
+	sidetree.OperationUpdate:     1,
+	sidetree.OperationDeactivate: 2,
+}
+
+// SidetreeService anchors pending DID operations in Sidetree-style batches: the
+// operations are written to batch files in a content-addressed store such as IPFS and
+// one transaction commits to them, instead of one registry transaction per operation.
+// Each operation gets a receipt locating it in its batch, so observers can check it
+// against the files and the anchor contract.
+type SidetreeService struct {
+	repo          domain.SidetreeRepository
+	jobs          domain.BlockchainJobRepository
+	dids          *DIDService
+	store         sidetree.Store
+	anchor        SidetreeAnchor
+	maxOperations int
+}
+
+// NewSidetreeService creates a new Sidetree service writing batches of at most
+// maxOperations operations
+func NewSidetreeService(
+	repo domain.SidetreeRepository,
+	jobs domain.BlockchainJobRepository,
+	dids *DIDService,
+	store sidetree.Store,
+	anchor SidetreeAnchor,
+	maxOperations int,
+) *SidetreeService {
+	return &SidetreeService{
+		repo:          repo,
+		jobs:          jobs,
+		dids:          dids,
+		store:         store,
+		anchor:        anchor,
+		maxOperations: maxOperations,
+	}
+}
+
+// ProcessBatch anchors the pending DID operations in one batch and completes their
+// jobs. When writing or anchoring the batch fails, every job in it fails.
+func (s *SidetreeService) ProcessBatch() error {
+	if !s.dids.batching() {
+		return nil
+	}
+
+	jobs, err := s.jobs.GetPendingBatchJobs(s.maxOperations)
+	if err != nil {
+		return fmt.Errorf("failed to get pending jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	// Put the jobs in the batch's canonical order so receipts can index into it
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return sidetreeOperationRank[sidetreeOperations[jobs[i].JobType]] < sidetreeOperationRank[sidetreeOperations[jobs[j].JobType]]
+	})
+
+	// Leave out the jobs canceled since they were listed
+	claimed := jobs[:0]
+	for _, job := range jobs {
+		if err := s.jobs.Claim(job.ID); err != nil {
+			if errors.Is(err, domain.ErrJobNotClaimable) {
+				continue
+			}
+			return fmt.Errorf("failed to claim job: %w", err)
+		}
+		claimed = append(claimed, job)
+	}
+	jobs = claimed
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	operations := make([]sidetree.Operation, 0, len(jobs))
+	for _, job := range jobs {
+		operations = append(operations, sidetree.Operation{
+			Type:     sidetreeOperations[job.JobType],
+			DID:      job.DID,
+			UserHash: job.UserHash,
+		})
+	}
+
+	batch, err := sidetree.WriteBatch(context.Background(), s.store, operations)
+	if err != nil {
+		s.failJobs(jobs, err)
+		return fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	txHash, err := s.anchor.AnchorSidetreeBatch(submissionContext(s.jobs, ids...), batch.AnchorFileHash, len(batch.Operations))
+	if err != nil {
+		s.failJobs(jobs, err)
+		return fmt.Errorf("failed to anchor batch: %w", err)
+	}
+
+	record := &domain.SidetreeBatch{
+		ID:               uuid.New(),
+		AnchorString:     batch.AnchorString,
+		CoreIndexFileURI: batch.CoreIndexFileURI,
+		ChunkFileURI:     batch.ChunkFileURI,
+		AnchorFileHash:   "0x" + hex.EncodeToString(batch.AnchorFileHash[:]),
+		OperationCount:   len(batch.Operations),
+		Contract:         s.anchor.SidetreeAnchor(),
+		TxHash:           txHash,
+		AnchoredAt:       time.Now(),
+	}
+	receipts := make([]*domain.SidetreeReceipt, len(jobs))
+	for i, job := range jobs {
+		receipts[i] = &domain.SidetreeReceipt{
+			JobID:          job.ID,
+			DIDID:          job.DIDID,
+			DID:            job.DID,
+			Operation:      string(batch.Operations[i].Type),
+			OperationIndex: i,
+		}
+	}
+	// The batch is anchored either way, so the jobs complete even when its record
+	// cannot be stored
+	if err := s.repo.CreateBatch(record, receipts); err != nil {
+		log.Printf("Failed to record Sidetree batch %s (transaction %s): %v", batch.AnchorString, txHash, err)
+	}
+
+	for _, job := range jobs {
+		if err := s.dids.completeJob(job, txHash); err != nil {
+			log.Printf("Failed to complete job %s: %v", job.ID, err)
+		}
+	}
+
+	log.Printf("Anchored Sidetree batch %s with %d operations, transaction: %s", batch.AnchorString, len(jobs), txHash)
+	return nil
+}
+
+// failJobs marks the jobs of a batch that could not be anchored failed
+func (s *SidetreeService) failJobs(jobs []*domain.BlockchainJob, cause error) {
+	for _, job := range jobs {
+		if err := s.jobs.UpdateStatus(job.ID, domain.JobStatusFailed, cause.Error()); err != nil {
+			log.Printf("Failed to update job status: %v", err)
+		}
+	}
+}
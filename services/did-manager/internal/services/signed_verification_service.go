@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+)
+
+// SignedVerificationMessage is the message a holder signs to authorize the audience to
+// verify its DID once
+func SignedVerificationMessage(didString, nonce, audience string) []byte {
+	return []byte("did-verify:" + didString + ":" + nonce + ":" + audience)
+}
+
+// SignedVerificationService verifies DIDs on behalf of their holders: a relying party
+// obtains a single-use nonce bound to itself, the holder signs the DID, nonce and
+// relying party, and only then is the DID verified. Third parties cannot trigger these
+// verifications or probe DIDs with them, and a signature cannot be replayed.
+type SignedVerificationService struct {
+	nonces   domain.VerificationNonceRepository
+	didRepo  domain.DIDRepository
+	registry *did.Registry
+	dids     *DIDService
+	ttl      time.Duration
+	// clock dates nonces and checks their expiry
+	clock clock.Clock
+}
+
+// NewSignedVerificationService creates a new signed verification service issuing
+// nonces valid for ttl
+func NewSignedVerificationService(
+	nonces domain.VerificationNonceRepository,
+	didRepo domain.DIDRepository,
+	registry *did.Registry,
+	dids *DIDService,
+	ttl time.Duration,
+) *SignedVerificationService {
+	return &SignedVerificationService{
+		nonces:   nonces,
+		didRepo:  didRepo,
+		registry: registry,
+		dids:     dids,
+		ttl:      ttl,
+		clock:    clock.System,
+	}
+}
+
+// SetClock sets the time source of nonce issuance and expiry, clock.System by default
+func (s *SignedVerificationService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// IssueNonce issues a nonce for the caller, which is its audience
+func (s *SignedVerificationService) IssueNonce(caller *domain.Caller) (*domain.VerificationNonce, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	now := s.clock.Now()
+	nonce := &domain.VerificationNonce{
+		Nonce:     hex.EncodeToString(value),
+		Audience:  caller.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.nonces.Create(nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// Verify verifies a DID once its holder's signature over the caller's nonce checks
+// out. The nonce is used up by the attempt whatever its outcome. Unknown DIDs, and DIDs
+// left without a key, fail like bad signatures, so the endpoint does not reveal which
+// DIDs exist.
+func (s *SignedVerificationService) Verify(caller *domain.Caller, req *domain.SignedVerificationRequest) (*domain.DIDVerificationResponse, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+	if req.Audience != caller.ID {
+		return nil, fmt.Errorf("%w: audience does not match the caller", domain.ErrVerificationNonceInvalid)
+	}
+	if err := s.nonces.Consume(req.Nonce, caller.ID); err != nil {
+		return nil, err
+	}
+
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err == nil && record.SandboxTenant != caller.SandboxTenant {
+		err = domain.ErrDIDNotFound
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrDIDNotFound) {
+			return nil, domain.ErrInvalidSignature
+		}
+		return nil, err
+	}
+
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", domain.ErrInvalidSignature)
+	}
+	// No signature checks out against a wiped key
+	if record.PublicKey == "" {
+		return nil, domain.ErrInvalidSignature
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	message := SignedVerificationMessage(record.Did, req.Nonce, req.Audience)
+	valid, err := s.registry.VerifySignature(record.KeyAlgorithm, keyMaterial, message, signature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	return s.dids.VerifyDID(&domain.DIDVerificationRequest{
+		DID:           record.Did,
+		Tenant:        caller.ID,
+		TenantType:    caller.Type,
+		SandboxTenant: caller.SandboxTenant,
+		Endpoint:      domain.VerificationEndpointSigned,
+	})
+}
+
+// DeleteExpiredNonces removes nonces past their expiry
+func (s *SignedVerificationService) DeleteExpiredNonces() error {
+	return s.nonces.DeleteExpired(s.clock.Now())
+}
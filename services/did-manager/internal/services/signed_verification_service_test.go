@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// fakeNonces keeps issued nonces in memory until they are consumed
+type fakeNonces struct {
+	issued map[string]*domain.VerificationNonce
+}
+
+func (f *fakeNonces) Create(nonce *domain.VerificationNonce) error {
+	f.issued[nonce.Nonce] = nonce
+	return nil
+}
+
+func (f *fakeNonces) Consume(nonce, audience string) error {
+	issued, ok := f.issued[nonce]
+	if !ok || issued.Audience != audience || time.Now().After(issued.ExpiresAt) {
+		return domain.ErrVerificationNonceInvalid
+	}
+	delete(f.issued, nonce)
+	return nil
+}
+
+func (f *fakeNonces) DeleteExpired(before time.Time) error {
+	return nil
+}
+
+// newSignedVerification returns a signed verification service for the DIDs in dids,
+// and a function signing verification requests with holder's key
+func newSignedVerification(t *testing.T, generator *did.Generator, holder *domain.DID, dids *fakeDIDs) (*SignedVerificationService, func(didString, nonce, audience string) string) {
+	t.Helper()
+	service := NewSignedVerificationService(
+		&fakeNonces{issued: map[string]*domain.VerificationNonce{}},
+		dids,
+		generator.Registry(),
+		NewDIDService(dids, nil, generator, OfflineLedger(errors.New("no ledger in tests")), nil, nil, nil),
+		5*time.Minute,
+	)
+
+	suite, err := generator.Registry().SignatureSuite(holder.KeyAlgorithm)
+	if err != nil {
+		t.Fatalf("failed to load signature suite: %v", err)
+	}
+	privateKey, err := hex.DecodeString(holder.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	sign := func(didString, nonce, audience string) string {
+		signature, err := suite.Sign(privateKey, SignedVerificationMessage(didString, nonce, audience))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return hex.EncodeToString(signature)
+	}
+	return service, sign
+}
+
+func TestSignedVerificationRejectsReplayedNonces(t *testing.T) {
+	generator := did.NewGenerator()
+	holder := newIssuer(t, generator, 0)
+	service, sign := newSignedVerification(t, generator, holder, &fakeDIDs{records: map[string]*domain.DID{holder.Did: holder}})
+	caller := &domain.Caller{Type: domain.CallerTypeAPIKey, ID: "relying-party"}
+
+	nonce, err := service.IssueNonce(caller)
+	if err != nil {
+		t.Fatalf("IssueNonce failed: %v", err)
+	}
+	req := &domain.SignedVerificationRequest{
+		DID:       holder.Did,
+		Nonce:     nonce.Nonce,
+		Audience:  caller.ID,
+		Signature: sign(holder.Did, nonce.Nonce, caller.ID),
+	}
+	response, err := service.Verify(caller, req)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !response.IsValid {
+		t.Errorf("expected the DID to verify, got %+v", response)
+	}
+
+	if _, err := service.Verify(caller, req); !errors.Is(err, domain.ErrVerificationNonceInvalid) {
+		t.Errorf("expected the replayed request to be refused with ErrVerificationNonceInvalid, got %v", err)
+	}
+}
+
+func TestSignedVerificationRejectsMismatchedAudience(t *testing.T) {
+	generator := did.NewGenerator()
+	holder := newIssuer(t, generator, 0)
+	service, sign := newSignedVerification(t, generator, holder, &fakeDIDs{records: map[string]*domain.DID{holder.Did: holder}})
+	relyingParty := &domain.Caller{Type: domain.CallerTypeAPIKey, ID: "relying-party"}
+	other := &domain.Caller{Type: domain.CallerTypeAPIKey, ID: "other-party"}
+
+	nonce, err := service.IssueNonce(relyingParty)
+	if err != nil {
+		t.Fatalf("IssueNonce failed: %v", err)
+	}
+	signature := sign(holder.Did, nonce.Nonce, relyingParty.ID)
+
+	// Another party presenting the holder's signature for the relying party
+	_, err = service.Verify(other, &domain.SignedVerificationRequest{DID: holder.Did, Nonce: nonce.Nonce, Audience: relyingParty.ID, Signature: signature})
+	if !errors.Is(err, domain.ErrVerificationNonceInvalid) {
+		t.Errorf("expected a foreign audience to be refused, got %v", err)
+	}
+	// Or claiming it as its own, with the relying party's nonce
+	_, err = service.Verify(other, &domain.SignedVerificationRequest{DID: holder.Did, Nonce: nonce.Nonce, Audience: other.ID, Signature: signature})
+	if !errors.Is(err, domain.ErrVerificationNonceInvalid) {
+		t.Errorf("expected another audience's nonce to be refused, got %v", err)
+	}
+}
+
+func TestSignedVerificationHidesUnknownDIDs(t *testing.T) {
+	generator := did.NewGenerator()
+	holder := newIssuer(t, generator, 0)
+	// The holder's DID record has been wiped of its key
+	wiped := *holder
+	wiped.PublicKey = ""
+	service, sign := newSignedVerification(t, generator, holder, &fakeDIDs{records: map[string]*domain.DID{wiped.Did: &wiped}})
+	caller := &domain.Caller{Type: domain.CallerTypeAPIKey, ID: "relying-party"}
+
+	for _, didString := range []string{"did:example:unknown", wiped.Did} {
+		nonce, err := service.IssueNonce(caller)
+		if err != nil {
+			t.Fatalf("IssueNonce failed: %v", err)
+		}
+		_, err = service.Verify(caller, &domain.SignedVerificationRequest{
+			DID:       didString,
+			Nonce:     nonce.Nonce,
+			Audience:  caller.ID,
+			Signature: sign(didString, nonce.Nonce, caller.ID),
+		})
+		if err != domain.ErrInvalidSignature {
+			t.Errorf("expected %s to fail like a bad signature, got %v", didString, err)
+		}
+	}
+}
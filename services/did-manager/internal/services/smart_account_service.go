@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/erc4337"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+)
+
+// smartAccountTimeout bounds the node and bundler calls of a smart account request
+const smartAccountTimeout = 30 * time.Second
+
+// FeeSuggester suggests EIP-1559 fees; *ethclient.Client implements it
+type FeeSuggester interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// SmartAccountService binds DIDs to ERC-4337 smart accounts and relays the accounts'
+// user operations to a bundler. Operations are signed by the account owner; the
+// service only builds them and never holds the owner key.
+type SmartAccountService struct {
+	accounts domain.SmartAccountRepository
+	didRepo  domain.DIDRepository
+	factory  *erc4337.Factory
+	bundler  *erc4337.Bundler
+	fees     FeeSuggester
+	chainID  *big.Int
+}
+
+// NewSmartAccountService creates a new smart account service for accounts of factory
+// on the chain with chainID
+func NewSmartAccountService(
+	accounts domain.SmartAccountRepository,
+	didRepo domain.DIDRepository,
+	factory *erc4337.Factory,
+	bundler *erc4337.Bundler,
+	fees FeeSuggester,
+	chainID *big.Int,
+) *SmartAccountService {
+	return &SmartAccountService{
+		accounts: accounts,
+		didRepo:  didRepo,
+		factory:  factory,
+		bundler:  bundler,
+		fees:     fees,
+		chainID:  chainID,
+	}
+}
+
+// CreateAccount makes the smart account of an owner key the controller of the calling
+// DID. The account address is derived counterfactually, so the account is usable
+// before its first operation deploys it.
+func (s *SmartAccountService) CreateAccount(didString, callerDID string, req *domain.SmartAccountCreateRequest) (*domain.SmartAccount, error) {
+	record, err := s.ownDID(didString, callerDID)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := new(big.Int)
+	if req.Salt != "" {
+		if _, ok := salt.SetString(req.Salt, 10); !ok || salt.Sign() < 0 {
+			return nil, fmt.Errorf("%w: invalid salt", domain.ErrInvalidUserOperation)
+		}
+	}
+	owner := common.HexToAddress(req.Owner)
+
+	ctx, cancel := context.WithTimeout(context.Background(), smartAccountTimeout)
+	defer cancel()
+
+	address, err := s.factory.AccountAddress(ctx, owner, salt)
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := s.factory.Deployed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	account := &domain.SmartAccount{
+		ID:         uuid.New(),
+		DIDID:      record.ID,
+		Did:        record.Did,
+		Address:    address.Hex(),
+		Owner:      owner.Hex(),
+		Factory:    s.factory.Address().Hex(),
+		EntryPoint: s.factory.EntryPoint().Hex(),
+		ChainID:    s.chainID.Int64(),
+		Salt:       salt.String(),
+		Deployed:   deployed,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.accounts.Create(account); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: DID %s bound to smart account %s owned by %s", record.Did, account.Address, account.Owner)
+	return account, nil
+}
+
+// GetAccount retrieves the smart account of the calling DID
+func (s *SmartAccountService) GetAccount(didString, callerDID string) (*domain.SmartAccount, error) {
+	record, err := s.ownDID(didString, callerDID)
+	if err != nil {
+		return nil, err
+	}
+	return s.accounts.GetByDIDID(record.ID)
+}
+
+// PrepareOperation builds an unsigned user operation making the calling DID's account
+// call a target, with gas estimated by the bundler. The first operation also deploys
+// the account.
+func (s *SmartAccountService) PrepareOperation(didString, callerDID string, req *domain.UserOperationPrepareRequest) (*domain.PreparedUserOperation, error) {
+	account, err := s.GetAccount(didString, callerDID)
+	if err != nil {
+		return nil, err
+	}
+
+	value := new(big.Int)
+	if req.Value != "" {
+		if _, ok := value.SetString(req.Value, 10); !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("%w: invalid value", domain.ErrInvalidUserOperation)
+		}
+	}
+	callData, err := erc4337.ExecuteCallData(common.HexToAddress(req.Target), value, common.FromHex(req.Data))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smartAccountTimeout)
+	defer cancel()
+
+	sender := common.HexToAddress(account.Address)
+	initCode, err := s.initCode(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := s.factory.Nonce(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := s.fees.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	tip, err := s.fees.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas tip: %w", err)
+	}
+
+	op := &erc4337.UserOperation{
+		Sender:   sender,
+		Nonce:    (*hexutil.Big)(nonce),
+		InitCode: initCode,
+		CallData: callData,
+		// Double the suggested price leaves headroom for base fee increases until the
+		// bundler includes the operation
+		MaxFeePerGas:         (*hexutil.Big)(new(big.Int).Mul(gasPrice, big.NewInt(2))),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tip),
+		PaymasterAndData:     hexutil.Bytes{},
+		Signature:            erc4337.DummySignature,
+	}
+	estimate, err := s.bundler.EstimateGas(ctx, op)
+	if err != nil {
+		if errors.Is(err, erc4337.ErrBundlerRejected) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidUserOperation, err)
+		}
+		return nil, err
+	}
+	op.CallGasLimit = estimate.CallGasLimit
+	op.VerificationGasLimit = estimate.VerificationGasLimit
+	op.PreVerificationGas = estimate.PreVerificationGas
+	op.Signature = hexutil.Bytes{}
+
+	hash, err := op.Hash(s.factory.EntryPoint(), s.chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PreparedUserOperation{Operation: op, Hash: hash.Hex()}, nil
+}
+
+// SubmitOperation relays a user operation signed by the account owner to the bundler
+// and returns its hash
+func (s *SmartAccountService) SubmitOperation(didString, callerDID string, req *domain.UserOperationSubmitRequest) (string, error) {
+	account, err := s.GetAccount(didString, callerDID)
+	if err != nil {
+		return "", err
+	}
+
+	op := req.Operation
+	if op.Sender != common.HexToAddress(account.Address) {
+		return "", fmt.Errorf("%w: sender is not the DID's smart account", domain.ErrInvalidUserOperation)
+	}
+	if op.Nonce == nil || op.CallGasLimit == nil || op.VerificationGasLimit == nil || op.PreVerificationGas == nil ||
+		op.MaxFeePerGas == nil || op.MaxPriorityFeePerGas == nil || len(op.Signature) == 0 {
+		return "", fmt.Errorf("%w: operation is incomplete or unsigned", domain.ErrInvalidUserOperation)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smartAccountTimeout)
+	defer cancel()
+
+	hash, err := s.bundler.Send(ctx, op)
+	if err != nil {
+		if errors.Is(err, erc4337.ErrBundlerRejected) {
+			return "", fmt.Errorf("%w: %v", domain.ErrInvalidUserOperation, err)
+		}
+		return "", err
+	}
+
+	log.Printf("AUDIT: user operation %s submitted for smart account %s of DID %s", hash.Hex(), account.Address, account.Did)
+	return hash.Hex(), nil
+}
+
+// OperationStatus reports whether a submitted operation was included, recording the
+// account's deployment once it was
+func (s *SmartAccountService) OperationStatus(didString, callerDID, hash string) (*domain.UserOperationStatus, error) {
+	account, err := s.GetAccount(didString, callerDID)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := hexutil.Decode(hash)
+	if err != nil || len(decoded) != common.HashLength {
+		return nil, fmt.Errorf("%w: invalid operation hash", domain.ErrInvalidUserOperation)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smartAccountTimeout)
+	defer cancel()
+
+	receipt, err := s.bundler.Receipt(ctx, common.BytesToHash(decoded))
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.UserOperationStatus{Hash: common.BytesToHash(decoded).Hex()}
+	if receipt == nil {
+		return status, nil
+	}
+
+	status.Included = true
+	status.Success = receipt.Success
+	status.Reason = receipt.Reason
+	status.TxHash = receipt.Receipt.TransactionHash.Hex()
+	if !account.Deployed {
+		if _, err := s.initCode(ctx, account); err != nil {
+			log.Printf("Failed to check deployment of smart account %s: %v", account.Address, err)
+		}
+	}
+	return status, nil
+}
+
+// initCode returns the initCode deploying an account, or none once it is deployed
+func (s *SmartAccountService) initCode(ctx context.Context, account *domain.SmartAccount) ([]byte, error) {
+	if account.Deployed {
+		return hexutil.Bytes{}, nil
+	}
+
+	deployed, err := s.factory.Deployed(ctx, common.HexToAddress(account.Address))
+	if err != nil {
+		return nil, err
+	}
+	if deployed {
+		if err := s.accounts.MarkDeployed(account.ID); err != nil {
+			return nil, err
+		}
+		account.Deployed = true
+		return hexutil.Bytes{}, nil
+	}
+
+	salt, ok := new(big.Int).SetString(account.Salt, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid salt of smart account %s", account.Address)
+	}
+	return s.factory.InitCode(common.HexToAddress(account.Owner), salt)
+}
+
+// ownDID loads the calling DID, which must be active
+func (s *SmartAccountService) ownDID(didString, callerDID string) (*domain.DID, error) {
+	if callerDID != didString {
+		return nil, fmt.Errorf("%w: only %s can manage its smart account", domain.ErrForbidden, didString)
+	}
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+	if record.Status != string(domain.DIDStatusActive) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+	return record, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+	"did-manager/pkg/socialproof"
+)
+
+// socialHandles are the valid account handles of each platform
+var socialHandles = map[string]*regexp.Regexp{
+	"github":  regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,38}$`),
+	"twitter": regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`),
+}
+
+// hexSignature finds the hex strings of a post that may be the signed challenge
+var hexSignature = regexp.MustCompile(`[0-9a-fA-F]{64,}`)
+
+// SocialProofMessage is the message a DID's holder signs to link a social account
+func SocialProofMessage(platform, handle, didString, challenge string) []byte {
+	return []byte("social-proof:" + platform + ":" + strings.ToLower(handle) + ":" + didString + ":" + challenge)
+}
+
+// SocialProofService links social accounts to DIDs. A DID obtains a challenge for an
+// account, signs it and publishes the signature in a public post from the account,
+// such as a gist or a tweet; once the post checks out, the service's issuer DID issues
+// the DID a SocialAccountLinkage credential.
+type SocialProofService struct {
+	challenges  domain.SocialProofChallengeRepository
+	didRepo     domain.DIDRepository
+	registry    *did.Registry
+	credentials *CredentialService
+	fetchers    map[string]socialproof.Fetcher
+	// issuerDID is the custodial DID issuing linkage credentials; empty disables proofs
+	issuerDID string
+	ttl       time.Duration
+	// validity is how long linkage credentials are valid; zero issues them without expiry
+	validity time.Duration
+}
+
+// NewSocialProofService creates a new social proof service issuing challenges valid for
+// ttl and linkage credentials valid for validity
+func NewSocialProofService(
+	challenges domain.SocialProofChallengeRepository,
+	didRepo domain.DIDRepository,
+	registry *did.Registry,
+	credentials *CredentialService,
+	issuerDID string,
+	ttl, validity time.Duration,
+) *SocialProofService {
+	return &SocialProofService{
+		challenges:  challenges,
+		didRepo:     didRepo,
+		registry:    registry,
+		credentials: credentials,
+		fetchers:    make(map[string]socialproof.Fetcher),
+		issuerDID:   issuerDID,
+		ttl:         ttl,
+		validity:    validity,
+	}
+}
+
+// AddPlatform accepts proofs posted on the fetcher's platform
+func (s *SocialProofService) AddPlatform(fetcher socialproof.Fetcher) {
+	s.fetchers[fetcher.Platform()] = fetcher
+}
+
+// IssueChallenge issues a challenge for the calling DID to link an account
+func (s *SocialProofService) IssueChallenge(callerDID string, req *domain.SocialProofChallengeRequest) (*domain.SocialProofChallengeResponse, error) {
+	if _, err := s.fetcher(req.Platform); err != nil {
+		return nil, err
+	}
+	if !socialHandles[req.Platform].MatchString(req.Handle) {
+		return nil, fmt.Errorf("%w: invalid %s handle %q", domain.ErrSocialProofInvalid, req.Platform, req.Handle)
+	}
+	record, err := s.didRepo.GetByDID(callerDID)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	now := time.Now()
+	challenge := &domain.SocialProofChallenge{
+		ID:        uuid.New(),
+		DID:       record.Did,
+		Platform:  req.Platform,
+		Handle:    req.Handle,
+		Challenge: hex.EncodeToString(value),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.challenges.Create(challenge); err != nil {
+		return nil, err
+	}
+
+	return &domain.SocialProofChallengeResponse{
+		SocialProofChallenge: challenge,
+		Message:              string(SocialProofMessage(challenge.Platform, challenge.Handle, challenge.DID, challenge.Challenge)),
+	}, nil
+}
+
+// Verify checks the post carrying the calling DID's signed challenge and issues the
+// DID a SocialAccountLinkage credential. The post must be published by the account
+// the challenge names and contain the hex signature of the challenge's message by the
+// DID's key. The challenge stays usable until a proof succeeds, so a post can be
+// fixed and submitted again.
+func (s *SocialProofService) Verify(ctx context.Context, callerDID string, req *domain.SocialProofRequest) (*domain.Credential, error) {
+	challenge, err := s.challenges.Get(req.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.DID != callerDID {
+		return nil, domain.ErrSocialChallengeNotFound
+	}
+	fetcher, err := s.fetcher(challenge.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := fetcher.Fetch(ctx, req.URL)
+	if err != nil {
+		if errors.Is(err, socialproof.ErrUnsupportedURL) || errors.Is(err, socialproof.ErrPostNotFound) {
+			return nil, fmt.Errorf("%w: %w", domain.ErrSocialProofInvalid, err)
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(post.Author, challenge.Handle) {
+		return nil, fmt.Errorf("%w: post is published by %s, not %s", domain.ErrSocialProofInvalid, post.Author, challenge.Handle)
+	}
+
+	record, err := s.didRepo.GetByDID(challenge.DID)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := s.signed(record, challenge, post.Content)
+	if err != nil {
+		return nil, err
+	}
+	if !signed {
+		return nil, fmt.Errorf("%w: post does not carry the DID's signature of the challenge", domain.ErrSocialProofInvalid)
+	}
+
+	if err := s.challenges.Consume(challenge.ID); err != nil {
+		return nil, err
+	}
+
+	issueReq := &domain.CredentialIssueRequest{
+		SubjectDID: record.Did,
+		Type:       domain.SocialAccountLinkageType,
+		Claims: map[string]any{
+			"platform":  challenge.Platform,
+			"handle":    post.Author,
+			"proof_url": req.URL,
+		},
+	}
+	if s.validity > 0 {
+		expiresAt := time.Now().Add(s.validity)
+		issueReq.ExpiresAt = &expiresAt
+	}
+	credential, err := s.credentials.IssueCredential(s.issuerDID, issueReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue linkage credential: %w", err)
+	}
+
+	log.Printf("Linked %s account %s to %s with proof %s", challenge.Platform, post.Author, record.Did, req.URL)
+	return credential, nil
+}
+
+// DeleteExpiredChallenges purges challenges that expired
+func (s *SocialProofService) DeleteExpiredChallenges() error {
+	return s.challenges.DeleteExpired(time.Now())
+}
+
+// fetcher returns the fetcher of an enabled platform
+func (s *SocialProofService) fetcher(platform string) (socialproof.Fetcher, error) {
+	if s.issuerDID == "" {
+		return nil, domain.ErrSocialProofsDisabled
+	}
+	fetcher, ok := s.fetchers[platform]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrSocialPlatformUnsupported, platform)
+	}
+	return fetcher, nil
+}
+
+// signed reports whether content carries a hex signature of the challenge's message by
+// the DID's key
+func (s *SocialProofService) signed(record *domain.DID, challenge *domain.SocialProofChallenge, content string) (bool, error) {
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	message := SocialProofMessage(challenge.Platform, challenge.Handle, challenge.DID, challenge.Challenge)
+
+	for _, candidate := range hexSignature.FindAllString(content, -1) {
+		signature, err := hex.DecodeString(candidate)
+		if err != nil {
+			continue
+		}
+		valid, err := s.registry.VerifySignature(record.KeyAlgorithm, keyMaterial, message, signature)
+		if err != nil {
+			return false, err
+		}
+		if valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
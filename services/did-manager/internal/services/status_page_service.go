@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/lifecycle"
+)
+
+// statusPageTTL is how long a status page snapshot is served before it is rebuilt, so
+// anonymous requests cannot load the database
+const statusPageTTL = 15 * time.Second
+
+// Anchoring backlog bucket bounds, in jobs waiting or being processed
+const (
+	backlogLowLimit      = 100
+	backlogElevatedLimit = 1000
+)
+
+// statusComponentGroups maps lifecycle components to the public components they are
+// shown as; unlisted components count as background processing
+var statusComponentGroups = map[string]string{
+	"http":                 "api",
+	"blockchain-worker":    "anchoring",
+	"chain-events":         "anchoring",
+	"sidetree-batcher":     "anchoring",
+	"revocation-anchoring": "anchoring",
+}
+
+// statusComponentOrder is the order public components are listed in
+var statusComponentOrder = []string{"api", "anchoring", "background_processing"}
+
+// ComponentStatuses reports the lifecycle state of the service's components
+type ComponentStatuses interface {
+	Statuses() []lifecycle.Status
+}
+
+// JobBacklog counts the blockchain jobs not yet anchored
+type JobBacklog interface {
+	CountPending() (int, error)
+}
+
+// AnchoringSwitch reports whether an operator paused anchoring
+type AnchoringSwitch interface {
+	Paused() bool
+}
+
+// StatusPageService builds the public status page from component health, the
+// anchoring backlog and the incident flags administrators raise. The page carries
+// statuses and buckets only, never component names, counts or errors.
+type StatusPageService struct {
+	repo       domain.StatusIncidentRepository
+	components ComponentStatuses
+	backlog    JobBacklog
+	// pause shows anchoring paused by an operator as degraded; nil never does
+	pause AnchoringSwitch
+
+	mu       sync.Mutex
+	page     *domain.StatusPage
+	loadedAt time.Time
+}
+
+// NewStatusPageService creates a new status page service
+func NewStatusPageService(repo domain.StatusIncidentRepository, components ComponentStatuses, backlog JobBacklog) *StatusPageService {
+	return &StatusPageService{
+		repo:       repo,
+		components: components,
+		backlog:    backlog,
+	}
+}
+
+// SetAnchoringPause shows anchoring as degraded while an operator has paused it
+func (s *StatusPageService) SetAnchoringPause(pause AnchoringSwitch) {
+	s.pause = pause
+}
+
+// Page returns the current status page, rebuilt at most every statusPageTTL
+func (s *StatusPageService) Page() *domain.StatusPage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.page == nil || time.Since(s.loadedAt) > statusPageTTL {
+		s.page = s.build()
+		s.loadedAt = time.Now()
+	}
+	return s.page
+}
+
+// Incidents lists the raised incident flags
+func (s *StatusPageService) Incidents() ([]*domain.StatusIncident, error) {
+	return s.repo.List()
+}
+
+// RaiseIncident raises an incident flag, or updates the message of a raised one
+func (s *StatusPageService) RaiseIncident(flag domain.IncidentFlag, req *domain.StatusIncidentRequest) (*domain.StatusIncident, error) {
+	if _, known := domain.IncidentStatuses[flag]; !known {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownIncidentFlag, flag)
+	}
+
+	now := time.Now().UTC()
+	incident := &domain.StatusIncident{
+		Flag:      flag,
+		Message:   req.Message,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Upsert(incident); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return incident, nil
+}
+
+// ClearIncident clears a raised incident flag
+func (s *StatusPageService) ClearIncident(flag domain.IncidentFlag) error {
+	if _, known := domain.IncidentStatuses[flag]; !known {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownIncidentFlag, flag)
+	}
+	if err := s.repo.Delete(flag); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// invalidate makes the next Page rebuild the status page, so changed flags show
+// right away on this instance
+func (s *StatusPageService) invalidate() {
+	s.mu.Lock()
+	s.page = nil
+	s.mu.Unlock()
+}
+
+// build assembles the status page. The overall status is the worst of the component
+// statuses and the raised incident flags.
+func (s *StatusPageService) build() *domain.StatusPage {
+	page := &domain.StatusPage{
+		Status:    domain.ServiceOperational,
+		Incidents: []*domain.StatusIncident{},
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	page.Components = s.componentHealth()
+	page.AnchoringBacklog = s.backlogBucket()
+	page.AnchoringPaused = s.pause != nil && s.pause.Paused()
+
+	for i, component := range page.Components {
+		if component.Name == "anchoring" && (page.AnchoringBacklog == domain.BacklogHigh || page.AnchoringPaused) {
+			page.Components[i].Status = component.Status.Worse(domain.ServiceDegraded)
+		}
+		page.Status = page.Status.Worse(page.Components[i].Status)
+	}
+
+	incidents, err := s.repo.List()
+	if err != nil {
+		// The page is public, so the failure is shown as a degraded API rather than
+		// as the error
+		page.Status = page.Status.Worse(domain.ServiceDegraded)
+	}
+	for _, incident := range incidents {
+		page.Incidents = append(page.Incidents, incident)
+		page.Status = page.Status.Worse(domain.IncidentStatuses[incident.Flag])
+	}
+
+	return page
+}
+
+// componentHealth groups the lifecycle components into public components. A group
+// is operational while all its components run, a major outage when none does and
+// degraded otherwise.
+func (s *StatusPageService) componentHealth() []domain.ComponentHealth {
+	running := make(map[string]int)
+	total := make(map[string]int)
+	for _, status := range s.components.Statuses() {
+		group, ok := statusComponentGroups[status.Name]
+		if !ok {
+			group = "background_processing"
+		}
+		total[group]++
+		if status.State == lifecycle.StateRunning {
+			running[group]++
+		}
+	}
+
+	// The API is always listed and never shown down, since it answers this request
+	components := []domain.ComponentHealth{}
+	for _, group := range statusComponentOrder {
+		if total[group] == 0 && group != "api" {
+			continue
+		}
+		health := domain.ComponentHealth{Name: group, Status: domain.ServiceOperational}
+		switch {
+		case running[group] == total[group]:
+		case running[group] == 0 && group != "api":
+			health.Status = domain.ServiceMajorOutage
+		default:
+			health.Status = domain.ServiceDegraded
+		}
+		components = append(components, health)
+	}
+	return components
+}
+
+// backlogBucket buckets the number of jobs not yet anchored; a backlog that cannot be
+// counted is reported as high
+func (s *StatusPageService) backlogBucket() domain.BacklogBucket {
+	if s.backlog == nil {
+		return domain.BacklogEmpty
+	}
+
+	count, err := s.backlog.CountPending()
+	switch {
+	case err != nil:
+		return domain.BacklogHigh
+	case count == 0:
+		return domain.BacklogEmpty
+	case count < backlogLowLimit:
+		return domain.BacklogLow
+	case count < backlogElevatedLimit:
+		return domain.BacklogElevated
+	default:
+		return domain.BacklogHigh
+	}
+}
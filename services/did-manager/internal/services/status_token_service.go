@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+)
+
+// StatusTokenService issues status tokens: short-lived JWTs signed by the service
+// asserting that a DID was active at a time with the assurance of the check. Verifiers
+// at doors and gates without network access fetch them in batch ahead of an event and
+// verify them offline against the published key set.
+type StatusTokenService struct {
+	access *AccessService
+	dids   *DIDService
+	// signingKey signs tokens; nil disables status tokens
+	signingKey ed25519.PrivateKey
+	issuer     string
+	ttl        time.Duration
+	maxTTL     time.Duration
+	// clock dates the tokens issued
+	clock clock.Clock
+}
+
+// NewStatusTokenService creates a new status token service issuing tokens valid for ttl
+// by default and at most maxTTL
+func NewStatusTokenService(access *AccessService, dids *DIDService, signingKey ed25519.PrivateKey, issuer string, ttl, maxTTL time.Duration) *StatusTokenService {
+	return &StatusTokenService{
+		access:     access,
+		dids:       dids,
+		signingKey: signingKey,
+		issuer:     issuer,
+		ttl:        ttl,
+		maxTTL:     maxTTL,
+		clock:      clock.System,
+	}
+}
+
+// SetClock sets the time source of token issuance and expiry, clock.System by default
+func (s *StatusTokenService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// Issue checks the status of a batch of DIDs the caller may resolve and signs a token
+// for each active one. Every check counts as a status verification of the caller.
+func (s *StatusTokenService) Issue(caller *domain.Caller, req *domain.StatusTokenRequest) (*domain.StatusTokenBatch, error) {
+	if s.signingKey == nil {
+		return nil, domain.ErrStatusTokensDisabled
+	}
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	ttl := s.ttl
+	if req.TTL > 0 {
+		ttl = min(time.Duration(req.TTL)*time.Second, s.maxTTL)
+	}
+
+	batch := &domain.StatusTokenBatch{
+		Tokens:   make([]*domain.StatusToken, 0, len(req.DIDs)),
+		IssuedAt: s.clock.Now().UTC(),
+	}
+	for _, didString := range req.DIDs {
+		token, err := s.issue(caller, didString, ttl)
+		if err != nil {
+			return nil, err
+		}
+		batch.Tokens = append(batch.Tokens, token)
+	}
+
+	log.Printf("AUDIT: %s caller %s fetched status tokens for %d DIDs", caller.Type, caller.ID, len(req.DIDs))
+	return batch, nil
+}
+
+// issue checks the status of one DID and signs a token when it is active. Failures
+// specific to the DID are reported on its entry; quota and other failures end the batch.
+func (s *StatusTokenService) issue(caller *domain.Caller, didString string, ttl time.Duration) (*domain.StatusToken, error) {
+	entry := &domain.StatusToken{DID: didString}
+
+	record, err := s.access.CheckDIDAccess(didString, caller)
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		entry.Status = "not_found"
+		return entry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.dids.VerifyDID(&domain.DIDVerificationRequest{
+		DID:           record.Did,
+		Tenant:        caller.ID,
+		TenantType:    caller.Type,
+		SandboxTenant: caller.SandboxTenant,
+		Endpoint:      domain.VerificationEndpointStatus,
+	})
+	switch {
+	case errors.Is(err, domain.ErrChainUnreachable), errors.Is(err, domain.ErrRejectedByHook):
+		entry.Status = record.Status
+		entry.Error = err.Error()
+		return entry, nil
+	case err != nil:
+		return nil, err
+	}
+
+	entry.Status = response.Status
+	entry.Assurance = response.Assurance
+	if !response.IsValid || response.Status != string(domain.DIDStatusActive) {
+		return entry, nil
+	}
+
+	issuedAt := s.clock.Now().UTC().Truncate(time.Second)
+	expiresAt := issuedAt.Add(ttl)
+	entry.Token, err = did.IssueStatusToken(s.signingKey, &did.StatusTokenClaims{
+		Issuer:    s.issuer,
+		Subject:   didString,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		Status:    response.Status,
+		Assurance: string(response.Assurance),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign status token: %w", err)
+	}
+	entry.ExpiresAt = &expiresAt
+	return entry, nil
+}
+
+// KeySet returns the JWK set verifiers check status tokens against
+func (s *StatusTokenService) KeySet() (*domain.StatusTokenKeySet, error) {
+	if s.signingKey == nil {
+		return nil, domain.ErrStatusTokensDisabled
+	}
+
+	publicKey := s.signingKey.Public().(ed25519.PublicKey)
+	return &domain.StatusTokenKeySet{
+		Issuer: s.issuer,
+		Keys: []*domain.StatusTokenKey{{
+			JWK:       did.StatusTokenJWK(publicKey),
+			KeyID:     did.StatusTokenKeyID(publicKey),
+			Algorithm: did.JWSAlgorithmEdDSA,
+			Use:       "sig",
+		}},
+	}, nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+)
+
+// StepUpDomain is the proof domain of step-up presentations, so a presentation made
+// for a relying party cannot be replayed as a step-up proof
+const StepUpDomain = "step-up"
+
+// StepUpMessage is the message a DID's holder signs to step up with a challenge
+func StepUpMessage(didString, challenge string) []byte {
+	return []byte("step-up:" + didString + ":" + challenge)
+}
+
+// ParseStepUpPolicies parses step-up policies of the form
+// "custody_transfer=credential:VerifiedPerson,organization_operation=did_challenge|credential:VerifiedPerson",
+// where any one of the "|"-separated proofs satisfies an operation's policy
+func ParseStepUpPolicies(spec string) (map[domain.StepUpOperation]*domain.StepUpPolicy, error) {
+	policies := make(map[domain.StepUpOperation]*domain.StepUpPolicy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid step-up policy %q: expected operation=did_challenge|credential:<type>", entry)
+		}
+		operation := domain.StepUpOperation(strings.TrimSpace(name))
+		if operation != domain.StepUpCustodyTransfer && operation != domain.StepUpOrganizationOperation {
+			return nil, fmt.Errorf("unknown step-up operation: %s", operation)
+		}
+
+		policy := &domain.StepUpPolicy{Operation: operation}
+		for _, method := range strings.Split(value, "|") {
+			method = strings.TrimSpace(method)
+			switch {
+			case method == string(domain.StepUpMethodDIDChallenge):
+				policy.AnyOf = append(policy.AnyOf, domain.StepUpRequirement{Method: domain.StepUpMethodDIDChallenge})
+			case strings.HasPrefix(method, string(domain.StepUpMethodCredential)+":"):
+				credentialType := strings.TrimSpace(strings.TrimPrefix(method, string(domain.StepUpMethodCredential)+":"))
+				if credentialType == "" {
+					return nil, fmt.Errorf("missing credential type in step-up policy for %s", operation)
+				}
+				policy.AnyOf = append(policy.AnyOf, domain.StepUpRequirement{
+					Method:         domain.StepUpMethodCredential,
+					CredentialType: credentialType,
+				})
+			default:
+				return nil, fmt.Errorf("invalid step-up method for %s: %s", operation, method)
+			}
+		}
+		policies[operation] = policy
+	}
+	return policies, nil
+}
+
+// StepUpService enforces step-up authentication on sensitive operations. The caller
+// obtains a single-use challenge for one of its DIDs and proves itself again with the
+// DID: by signing the challenge with the DID's key, or by presenting a credential of a
+// required type over the challenge. Operations without a policy need no step-up.
+type StepUpService struct {
+	challenges  domain.StepUpChallengeRepository
+	didRepo     domain.DIDRepository
+	registry    *did.Registry
+	credentials *CredentialService
+	policies    map[domain.StepUpOperation]*domain.StepUpPolicy
+	ttl         time.Duration
+	// clock dates challenges and checks their expiry
+	clock clock.Clock
+}
+
+// NewStepUpService creates a new step-up service issuing challenges valid for ttl
+func NewStepUpService(
+	challenges domain.StepUpChallengeRepository,
+	didRepo domain.DIDRepository,
+	registry *did.Registry,
+	credentials *CredentialService,
+	policies map[domain.StepUpOperation]*domain.StepUpPolicy,
+	ttl time.Duration,
+) *StepUpService {
+	return &StepUpService{
+		challenges:  challenges,
+		didRepo:     didRepo,
+		registry:    registry,
+		credentials: credentials,
+		policies:    policies,
+		ttl:         ttl,
+		clock:       clock.System,
+	}
+}
+
+// SetClock sets the time source of challenge issuance and expiry, clock.System by default
+func (s *StepUpService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// Policy returns the step-up policy of an operation, or nil when it needs no step-up
+func (s *StepUpService) Policy(operation domain.StepUpOperation) *domain.StepUpPolicy {
+	return s.policies[operation]
+}
+
+// IssueChallenge issues a challenge for one of the subject's DIDs
+func (s *StepUpService) IssueChallenge(subject domain.StepUpSubject, didString string) (*domain.StepUpChallengeResponse, error) {
+	record, err := s.subjectDID(subject, didString)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	now := s.clock.Now()
+	challenge := &domain.StepUpChallenge{
+		ID:        uuid.New(),
+		DID:       record.Did,
+		Challenge: hex.EncodeToString(value),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.challenges.Create(challenge); err != nil {
+		return nil, err
+	}
+
+	return &domain.StepUpChallengeResponse{
+		StepUpChallenge: challenge,
+		Message:         string(StepUpMessage(challenge.DID, challenge.Challenge)),
+		Domain:          StepUpDomain,
+	}, nil
+}
+
+// Verify checks that the subject stepped up for an operation. The proof's challenge is
+// used up by the attempt whatever its outcome. Errors wrapping ErrStepUpRequired mean
+// the proof is missing or does not satisfy the operation's policy.
+func (s *StepUpService) Verify(operation domain.StepUpOperation, subject domain.StepUpSubject, proof *domain.StepUpProof) error {
+	policy := s.policies[operation]
+	if policy == nil {
+		return nil
+	}
+	if proof == nil {
+		return domain.ErrStepUpRequired
+	}
+
+	challenge, err := s.challenges.Consume(proof.ChallengeID)
+	if err != nil {
+		if errors.Is(err, domain.ErrStepUpChallengeInvalid) {
+			return fmt.Errorf("%w: %v", domain.ErrStepUpRequired, err)
+		}
+		return err
+	}
+	// Challenges expire on the clock they were issued on, not the database's
+	if s.clock.Now().After(challenge.ExpiresAt) {
+		return fmt.Errorf("%w: %v", domain.ErrStepUpRequired, domain.ErrStepUpChallengeInvalid)
+	}
+
+	record, err := s.subjectDID(subject, challenge.DID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDIDNotFound):
+			return fmt.Errorf("%w: challenge was issued for another DID", domain.ErrStepUpRequired)
+		case errors.Is(err, domain.ErrForbidden):
+			return fmt.Errorf("%w: %v", domain.ErrStepUpRequired, err)
+		}
+		return err
+	}
+
+	reason := "no proof accepted by the policy was presented"
+	for _, requirement := range policy.AnyOf {
+		switch requirement.Method {
+		case domain.StepUpMethodDIDChallenge:
+			if proof.Signature == "" {
+				continue
+			}
+			if record.Custodial {
+				reason = "custodial DIDs step up with a credential"
+				continue
+			}
+			valid, err := s.verifySignature(record, challenge, proof.Signature)
+			if err != nil {
+				return err
+			}
+			if valid {
+				s.audit(operation, record.Did, requirement)
+				return nil
+			}
+			reason = "challenge signature does not verify"
+		case domain.StepUpMethodCredential:
+			if len(proof.Presentation) == 0 {
+				continue
+			}
+			result, err := s.credentials.VerifyPresentation(proof.Presentation, challenge.Challenge, StepUpDomain, []string{requirement.CredentialType})
+			if err != nil {
+				return err
+			}
+			if result.Valid && result.Holder == record.Did {
+				s.audit(operation, record.Did, requirement)
+				return nil
+			}
+			reason = result.Message
+			if result.Valid {
+				reason = "presentation holder is not the challenged DID"
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: %s", domain.ErrStepUpRequired, reason)
+}
+
+// DeleteExpiredChallenges removes challenges past their expiry
+func (s *StepUpService) DeleteExpiredChallenges() error {
+	return s.challenges.DeleteExpired(s.clock.Now())
+}
+
+// subjectDID loads a usable DID belonging to the subject. DIDs of other subjects are
+// reported as not found, so their existence is not revealed.
+func (s *StepUpService) subjectDID(subject domain.StepUpSubject, didString string) (*domain.DID, error) {
+	if subject.DID == "" && subject.UserID == uuid.Nil {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	record, err := s.didRepo.GetByDID(didString)
+	if err != nil {
+		return nil, err
+	}
+	owned := (subject.DID != "" && record.Did == subject.DID) ||
+		(subject.UserID != uuid.Nil && record.UserID == subject.UserID)
+	if !owned {
+		return nil, domain.ErrDIDNotFound
+	}
+	if record.Status != string(domain.DIDStatusActive) && record.Status != string(domain.DIDStatusPending) {
+		return nil, fmt.Errorf("%w: DID is %s", domain.ErrForbidden, record.Status)
+	}
+
+	return record, nil
+}
+
+// verifySignature checks the holder's hex signature over the challenge
+func (s *StepUpService) verifySignature(record *domain.DID, challenge *domain.StepUpChallenge, signatureHex string) (bool, error) {
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false, nil
+	}
+	keyMaterial, err := hex.DecodeString(record.PublicKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode DID key: %w", err)
+	}
+	return s.registry.VerifySignature(record.KeyAlgorithm, keyMaterial, StepUpMessage(challenge.DID, challenge.Challenge), signature)
+}
+
+// audit records a successful step-up
+func (s *StepUpService) audit(operation domain.StepUpOperation, didString string, requirement domain.StepUpRequirement) {
+	method := string(requirement.Method)
+	if requirement.CredentialType != "" {
+		method += ":" + requirement.CredentialType
+	}
+	log.Printf("AUDIT: step-up for %s by %s with %s", operation, didString, method)
+}
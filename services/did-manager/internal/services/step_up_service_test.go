@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+)
+
+// fakeChallenges keeps issued challenges in memory; like the database, each is
+// consumed once. Expiry is left to the service's clock.
+type fakeChallenges struct {
+	issued   map[uuid.UUID]*domain.StepUpChallenge
+	consumed map[uuid.UUID]bool
+}
+
+func (f *fakeChallenges) Create(challenge *domain.StepUpChallenge) error {
+	f.issued[challenge.ID] = challenge
+	return nil
+}
+
+func (f *fakeChallenges) Consume(id uuid.UUID) (*domain.StepUpChallenge, error) {
+	challenge, ok := f.issued[id]
+	if !ok || f.consumed[id] {
+		return nil, domain.ErrStepUpChallengeInvalid
+	}
+	f.consumed[id] = true
+	return challenge, nil
+}
+
+func (f *fakeChallenges) DeleteExpired(before time.Time) error {
+	return nil
+}
+
+// newStepUp returns a step-up service requiring a DID challenge for custody transfers,
+// the self-custodial DID it steps up with and a function signing its challenges
+func newStepUp(t *testing.T, clk clock.Clock) (*StepUpService, *domain.DID, func(*domain.StepUpChallengeResponse) string) {
+	t.Helper()
+	registry := did.NewGenerator().Registry()
+	suite, err := registry.SignatureSuite(did.SignatureSuiteEd25519)
+	if err != nil {
+		t.Fatalf("failed to load signature suite: %v", err)
+	}
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	record := &domain.DID{
+		ID:           uuid.New(),
+		Did:          "did:example:holder",
+		PublicKey:    hex.EncodeToString(publicKey),
+		KeyAlgorithm: suite.ID(),
+		Status:       string(domain.DIDStatusActive),
+	}
+
+	policies, err := ParseStepUpPolicies("custody_transfer=did_challenge")
+	if err != nil {
+		t.Fatalf("ParseStepUpPolicies failed: %v", err)
+	}
+	service := NewStepUpService(
+		&fakeChallenges{issued: map[uuid.UUID]*domain.StepUpChallenge{}, consumed: map[uuid.UUID]bool{}},
+		&fakeDIDs{records: map[string]*domain.DID{record.Did: record}},
+		registry,
+		nil,
+		policies,
+		5*time.Minute,
+	)
+	service.SetClock(clk)
+
+	sign := func(challenge *domain.StepUpChallengeResponse) string {
+		signature, err := suite.Sign(privateKey, []byte(challenge.Message))
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return hex.EncodeToString(signature)
+	}
+	return service, record, sign
+}
+
+func TestStepUpChallengesAreSingleUse(t *testing.T) {
+	service, record, sign := newStepUp(t, clock.NewManual(time.Now()))
+	subject := domain.StepUpSubject{DID: record.Did}
+
+	challenge, err := service.IssueChallenge(subject, record.Did)
+	if err != nil {
+		t.Fatalf("IssueChallenge failed: %v", err)
+	}
+	proof := &domain.StepUpProof{ChallengeID: challenge.ID, Signature: sign(challenge)}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, proof); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, proof); !errors.Is(err, domain.ErrStepUpRequired) {
+		t.Errorf("expected a reused challenge to require step-up again, got %v", err)
+	}
+
+	// A failed attempt uses the challenge up as well
+	challenge, err = service.IssueChallenge(subject, record.Did)
+	if err != nil {
+		t.Fatalf("IssueChallenge failed: %v", err)
+	}
+	bad := &domain.StepUpProof{ChallengeID: challenge.ID, Signature: hex.EncodeToString(make([]byte, 64))}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, bad); !errors.Is(err, domain.ErrStepUpRequired) {
+		t.Fatalf("expected a bad signature to require step-up, got %v", err)
+	}
+	proof = &domain.StepUpProof{ChallengeID: challenge.ID, Signature: sign(challenge)}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, proof); !errors.Is(err, domain.ErrStepUpRequired) {
+		t.Errorf("expected the challenge of a failed attempt to be used up, got %v", err)
+	}
+}
+
+func TestStepUpChallengesExpire(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	service, record, sign := newStepUp(t, clk)
+	subject := domain.StepUpSubject{DID: record.Did}
+
+	challenge, err := service.IssueChallenge(subject, record.Did)
+	if err != nil {
+		t.Fatalf("IssueChallenge failed: %v", err)
+	}
+	clk.Advance(5*time.Minute + time.Second)
+
+	proof := &domain.StepUpProof{ChallengeID: challenge.ID, Signature: sign(challenge)}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, proof); !errors.Is(err, domain.ErrStepUpRequired) {
+		t.Errorf("expected an expired challenge to require step-up, got %v", err)
+	}
+
+	challenge, err = service.IssueChallenge(subject, record.Did)
+	if err != nil {
+		t.Fatalf("IssueChallenge failed: %v", err)
+	}
+	clk.Advance(5 * time.Minute)
+	proof = &domain.StepUpProof{ChallengeID: challenge.ID, Signature: sign(challenge)}
+	if err := service.Verify(domain.StepUpCustodyTransfer, subject, proof); err != nil {
+		t.Errorf("expected a challenge to be valid until it expires, got %v", err)
+	}
+}
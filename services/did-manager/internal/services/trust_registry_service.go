@@ -0,0 +1,185 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// defaultTrustRegistryTemplates renders the message of each trust registry event, so
+// relying parties can forward it to their operators as is
+var defaultTrustRegistryTemplates = map[string]string{
+	queue.EventTrustedIssuerAdded:   `{{.Name}} ({{.DID}}) was added to the trust registry{{if .CredentialTypes}} for {{join .CredentialTypes ", "}}{{end}} in version {{.Version}}.`,
+	queue.EventTrustedIssuerRemoved: `{{.Name}} ({{.DID}}) was removed from the trust registry in version {{.Version}}.`,
+}
+
+// trustRegistryChange is the data trust registry event templates are rendered with
+type trustRegistryChange struct {
+	DID             string
+	Name            string
+	CredentialTypes []string
+	Version         int64
+}
+
+// ParseTrustRegistryTemplates parses event message templates by event type, starting
+// from the defaults; overrides replace the default of their event type
+func ParseTrustRegistryTemplates(overrides map[string]string) (map[string]*template.Template, error) {
+	sources := make(map[string]string, len(defaultTrustRegistryTemplates))
+	for eventType, source := range defaultTrustRegistryTemplates {
+		sources[eventType] = source
+	}
+	for eventType, source := range overrides {
+		if _, known := defaultTrustRegistryTemplates[eventType]; !known {
+			return nil, fmt.Errorf("unknown trust registry event type %q", eventType)
+		}
+		sources[eventType] = source
+	}
+
+	templates := make(map[string]*template.Template, len(sources))
+	for eventType, source := range sources {
+		tmpl, err := template.New(eventType).Funcs(template.FuncMap{"join": strings.Join}).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", eventType, err)
+		}
+		templates[eventType] = tmpl
+	}
+	return templates, nil
+}
+
+// TrustRegistryService manages the issuers accredited in the trust registry. Every
+// change bumps the registry version and is published as an event, which the webhooks
+// of subscribed verifiers receive, and the registry is exported as signed snapshots so
+// downstream policy engines can check they hold the latest version.
+type TrustRegistryService struct {
+	repo      domain.TrustRegistryRepository
+	events    EventPublisher
+	templates map[string]*template.Template
+	// signingKey signs snapshots; nil disables the snapshot export
+	signingKey ed25519.PrivateKey
+}
+
+// NewTrustRegistryService creates a new trust registry service rendering event
+// messages with templates from ParseTrustRegistryTemplates
+func NewTrustRegistryService(
+	repo domain.TrustRegistryRepository,
+	events EventPublisher,
+	templates map[string]*template.Template,
+	signingKey ed25519.PrivateKey,
+) *TrustRegistryService {
+	return &TrustRegistryService{
+		repo:       repo,
+		events:     events,
+		templates:  templates,
+		signingKey: signingKey,
+	}
+}
+
+// List lists the registry's issuers
+func (s *TrustRegistryService) List() ([]*domain.TrustedIssuer, error) {
+	issuers, _, err := s.repo.List()
+	return issuers, err
+}
+
+// AddIssuer accredits an issuer and notifies subscribed verifiers
+func (s *TrustRegistryService) AddIssuer(req *domain.TrustedIssuerRequest) (*domain.TrustedIssuer, error) {
+	issuer := &domain.TrustedIssuer{
+		DID:             req.DID,
+		Name:            req.Name,
+		CredentialTypes: req.CredentialTypes,
+		AddedAt:         time.Now().UTC(),
+	}
+	if issuer.CredentialTypes == nil {
+		issuer.CredentialTypes = []string{}
+	}
+	if err := s.repo.Add(issuer); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: issuer %s added to the trust registry in version %d", issuer.DID, issuer.Version)
+	s.publish(queue.EventTrustedIssuerAdded, issuer, issuer.Version)
+	return issuer, nil
+}
+
+// RemoveIssuer withdraws an issuer's accreditation and notifies subscribed verifiers
+func (s *TrustRegistryService) RemoveIssuer(did string) error {
+	issuer, version, err := s.repo.Remove(did)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: issuer %s removed from the trust registry in version %d", issuer.DID, version)
+	s.publish(queue.EventTrustedIssuerRemoved, issuer, version)
+	return nil
+}
+
+// Snapshot exports the registry at its current version, signed with the snapshot
+// signing key
+func (s *TrustRegistryService) Snapshot() (*domain.TrustRegistrySnapshot, *domain.SignedTrustRegistrySnapshot, error) {
+	if s.signingKey == nil {
+		return nil, nil, domain.ErrTrustRegistryUnsigned
+	}
+
+	issuers, version, err := s.repo.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot := &domain.TrustRegistrySnapshot{
+		Version:     version,
+		Issuers:     issuers,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return snapshot, &domain.SignedTrustRegistrySnapshot{
+		Snapshot:  encoded,
+		Signature: signReport(s.signingKey, encoded),
+	}, nil
+}
+
+// publish emits a trust registry event with its rendered message; failures are
+// logged rather than failing the change
+func (s *TrustRegistryService) publish(eventType string, issuer *domain.TrustedIssuer, version int64) {
+	if s.events == nil {
+		return
+	}
+
+	var message strings.Builder
+	change := &trustRegistryChange{
+		DID:             issuer.DID,
+		Name:            issuer.Name,
+		CredentialTypes: issuer.CredentialTypes,
+		Version:         version,
+	}
+	if err := s.templates[eventType].Execute(&message, change); err != nil {
+		log.Printf("Warning: failed to render %s message: %v", eventType, err)
+	}
+
+	event := &queue.Event{
+		ID:      uuid.New().String(),
+		Type:    eventType,
+		Subject: issuer.DID,
+		Data: map[string]string{
+			"name":             issuer.Name,
+			"credential_types": strings.Join(issuer.CredentialTypes, ","),
+			"version":          strconv.FormatInt(version, 10),
+			"message":          message.String(),
+		},
+		OccurredAt: time.Now(),
+	}
+	if err := s.events.PublishEvent(event); err != nil {
+		log.Printf("Warning: failed to publish %s event: %v", eventType, err)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// UsageService meters billable events per tenant, enforces optional monthly quotas and
+// exports usage for invoicing. It is registered as a plugin hook: quotas are checked
+// before DIDs are created or verified and credentials issued, and the events are
+// recorded once the operation succeeds. Anonymous and sandbox requests are not metered.
+// Metering failures are logged and never fail an operation.
+type UsageService struct {
+	repo domain.UsageRepository
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService(repo domain.UsageRepository) *UsageService {
+	return &UsageService{repo: repo}
+}
+
+// BeforeCreateDID enforces the tenant's DID creation quota
+func (s *UsageService) BeforeCreateDID(req *domain.DIDCreateRequest) error {
+	return s.checkQuota(meteredTenant(req.Tenant, req.SandboxTenant), domain.UsageDIDCreated)
+}
+
+// AfterCreateDID meters a DID creation
+func (s *UsageService) AfterCreateDID(req *domain.DIDCreateRequest, response *domain.DIDResponse) {
+	s.record(meteredTenant(req.Tenant, req.SandboxTenant), domain.UsageDIDCreated, response.DID.Did)
+}
+
+// BeforeVerifyDID enforces the tenant's verification quota
+func (s *UsageService) BeforeVerifyDID(req *domain.DIDVerificationRequest) error {
+	return s.checkQuota(meteredTenant(req.Tenant, req.SandboxTenant), domain.UsageDIDVerification)
+}
+
+// AfterVerifyDID meters a DID verification
+func (s *UsageService) AfterVerifyDID(req *domain.DIDVerificationRequest, _ *domain.DIDVerificationResponse) {
+	s.record(meteredTenant(req.Tenant, req.SandboxTenant), domain.UsageDIDVerification, req.DID)
+}
+
+// BeforeIssueCredential enforces the issuer's issuance quota
+func (s *UsageService) BeforeIssueCredential(issuer *domain.DID, _ *domain.CredentialIssueRequest) error {
+	return s.checkQuota(meteredTenant(issuer.Did, issuer.SandboxTenant), domain.UsageCredentialIssued)
+}
+
+// AfterIssueCredential meters a credential issuance
+func (s *UsageService) AfterIssueCredential(issuer *domain.DID, credential *domain.Credential) {
+	s.record(meteredTenant(issuer.Did, issuer.SandboxTenant), domain.UsageCredentialIssued, credential.ID.String())
+}
+
+// meteredTenant returns the tenant an operation is metered for; operations in a
+// sandbox are not metered
+func meteredTenant(tenant, sandboxTenant string) string {
+	if sandboxTenant != "" {
+		return ""
+	}
+	return tenant
+}
+
+// checkQuota returns ErrQuotaExceeded once the tenant has used its monthly quota
+func (s *UsageService) checkQuota(tenant string, event domain.UsageEventType) error {
+	if tenant == "" {
+		return nil
+	}
+
+	quota, err := s.repo.EffectiveQuota(tenant, event)
+	if err != nil {
+		log.Printf("Warning: failed to get %s quota of %s: %v", event, tenant, err)
+		return nil
+	}
+	if quota == nil {
+		return nil
+	}
+
+	used, err := s.repo.Count(tenant, event, monthStart(time.Now()))
+	if err != nil {
+		log.Printf("Warning: failed to count %s usage of %s: %v", event, tenant, err)
+		return nil
+	}
+	if used >= quota.MonthlyLimit {
+		return fmt.Errorf("%w: %d of %d %s events used this month", domain.ErrQuotaExceeded, used, quota.MonthlyLimit, event)
+	}
+	return nil
+}
+
+// record stores a billable event of an identified tenant
+func (s *UsageService) record(tenant string, event domain.UsageEventType, subject string) {
+	if tenant == "" {
+		return
+	}
+
+	err := s.repo.Record(&domain.UsageEvent{
+		Tenant:     tenant,
+		Event:      event,
+		Subject:    subject,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to meter %s for %s: %v", event, tenant, err)
+	}
+}
+
+// ListQuotas lists every quota
+func (s *UsageService) ListQuotas() ([]*domain.TenantQuota, error) {
+	quotas, err := s.repo.ListQuotas()
+	if err != nil {
+		return nil, err
+	}
+	if quotas == nil {
+		quotas = []*domain.TenantQuota{}
+	}
+	return quotas, nil
+}
+
+// SetQuota sets a tenant's monthly quota for an event, or the default quota when the
+// tenant is empty
+func (s *UsageService) SetQuota(req *domain.TenantQuotaRequest) (*domain.TenantQuota, error) {
+	quota := &domain.TenantQuota{
+		Tenant:       req.Tenant,
+		Event:        req.Event,
+		MonthlyLimit: *req.MonthlyLimit,
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.repo.UpsertQuota(quota); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: %s quota for tenant %q set to %d per month", quota.Event, quota.Tenant, quota.MonthlyLimit)
+	return quota, nil
+}
+
+// DeleteQuota removes a quota
+func (s *UsageService) DeleteQuota(tenant string, event domain.UsageEventType) error {
+	if err := s.repo.DeleteQuota(tenant, event); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: %s quota for tenant %q removed", event, tenant)
+	return nil
+}
+
+// Export summarizes billable usage over [from, to), optionally for a single tenant
+func (s *UsageService) Export(from, to time.Time, tenant string) (*domain.BillingExport, error) {
+	lines, err := s.repo.Summarize(from, to, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		lines = []*domain.BillingLine{}
+	}
+
+	return &domain.BillingExport{
+		From:        from.UTC(),
+		To:          to.UTC(),
+		GeneratedAt: time.Now().UTC(),
+		Lines:       lines,
+	}, nil
+}
+
+// RenderCSV renders an export with one row per tenant and event type
+func (s *UsageService) RenderCSV(export *domain.BillingExport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"period_start", "period_end", "tenant", "event", "count"}); err != nil {
+		return nil, err
+	}
+	from := export.From.Format(time.RFC3339)
+	to := export.To.Format(time.RFC3339)
+	for _, line := range export.Lines {
+		if err := w.Write([]string{from, to, line.Tenant, string(line.Event), strconv.Itoa(line.Count)}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render billing export: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// monthStart returns the start of t's calendar month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
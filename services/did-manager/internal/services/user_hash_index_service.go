@@ -0,0 +1,106 @@
+package services
+
+import (
+	"log"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+)
+
+// userHashRehashBatch is the number of DIDs re-hashed per batch
+const userHashRehashBatch = 500
+
+// UserHashIndexService maintains the peppered lookup index of user hashes. Lookups by
+// user hash go through the index, so rotating the pepper does not break them: during a
+// rotation indexes are written and searched under both the current and the previous
+// pepper version, and Rehash migrates DIDs indexed before the rotation to the current
+// version in the background.
+type UserHashIndexService struct {
+	repo   domain.UserHashIndexRepository
+	didGen *did.Generator
+}
+
+// NewUserHashIndexService creates a new user hash index service
+func NewUserHashIndexService(repo domain.UserHashIndexRepository, didGen *did.Generator) *UserHashIndexService {
+	return &UserHashIndexService{
+		repo:   repo,
+		didGen: didGen,
+	}
+}
+
+// record indexes a new DID's user hash. Failures are logged rather than failing a DID
+// that already exists; the next Rehash indexes it.
+func (s *UserHashIndexService) record(record *domain.DID) {
+	if s == nil {
+		return
+	}
+
+	if err := s.index(record); err != nil {
+		log.Printf("Warning: failed to index user hash of %s: %v", record.Did, err)
+	}
+}
+
+// index writes a DID's user hash index under every lookup version
+func (s *UserHashIndexService) index(record *domain.DID) error {
+	indexes, err := s.didGen.UserHashIndexes(record.UserHash)
+	if err != nil {
+		return err
+	}
+	return s.repo.Record(record.ID, indexes)
+}
+
+// GetDID retrieves the DID with the given user hash, searching its index under every
+// lookup version
+func (s *UserHashIndexService) GetDID(userHash string) (*domain.DID, error) {
+	indexes, err := s.didGen.UserHashIndexes(userHash)
+	if err != nil {
+		return nil, err
+	}
+
+	lookups := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		lookups = append(lookups, index)
+	}
+	return s.repo.GetDID(lookups)
+}
+
+// Rehash indexes every DID lacking an index under the current pepper version, in
+// batches, from its stored user hash. Once none is left, indexes under versions no
+// longer looked up are deleted, completing a rotation.
+func (s *UserHashIndexService) Rehash() (*domain.UserHashRehashRun, error) {
+	run := &domain.UserHashRehashRun{Version: s.didGen.PepperVersion()}
+
+	for {
+		records, err := s.repo.ListUnindexed(run.Version, userHashRehashBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if err := s.index(record); err != nil {
+				return nil, err
+			}
+		}
+		run.Rehashed += len(records)
+		if len(records) < userHashRehashBatch {
+			break
+		}
+	}
+
+	remaining, err := s.repo.CountUnindexed(run.Version)
+	if err != nil {
+		return nil, err
+	}
+	run.Remaining = remaining
+	if remaining == 0 {
+		retired, err := s.repo.DeleteExcept(s.didGen.LookupVersions())
+		if err != nil {
+			return nil, err
+		}
+		run.Retired = retired
+	}
+
+	if run.Rehashed > 0 || run.Retired > 0 {
+		log.Printf("AUDIT: re-hashed %d user hash indexes under pepper version %s, retired %d", run.Rehashed, run.Version, run.Retired)
+	}
+	return run, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"did-manager/internal/domain"
+)
+
+// VerificationPolicyService decides, per tenant and endpoint, how DID verification
+// answers when the blockchain cannot be reached
+type VerificationPolicyService struct {
+	repo domain.VerificationPolicyRepository
+	// defaultFallback applies when no policy matches
+	defaultFallback domain.VerificationFallback
+}
+
+// NewVerificationPolicyService creates a new verification policy service
+func NewVerificationPolicyService(repo domain.VerificationPolicyRepository, defaultFallback domain.VerificationFallback) *VerificationPolicyService {
+	return &VerificationPolicyService{
+		repo:            repo,
+		defaultFallback: defaultFallback,
+	}
+}
+
+// ParseVerificationFallback parses a configured fallback; empty selects fail_open,
+// the behavior before policies existed
+func ParseVerificationFallback(name string) (domain.VerificationFallback, error) {
+	switch fallback := domain.VerificationFallback(name); fallback {
+	case "":
+		return domain.VerificationFailOpen, nil
+	case domain.VerificationFailClosed, domain.VerificationFailOpen, domain.VerificationLocalOnly:
+		return fallback, nil
+	default:
+		return "", fmt.Errorf("unknown verification fallback: %s", name)
+	}
+}
+
+// Fallback returns the fallback for a tenant's verification through an endpoint. When
+// the policy cannot be read the default applies.
+func (s *VerificationPolicyService) Fallback(tenant string, endpoint domain.VerificationEndpoint) domain.VerificationFallback {
+	policy, err := s.repo.Effective(tenant, endpoint)
+	if err != nil {
+		log.Printf("Failed to get verification policy, using %s: %v", s.defaultFallback, err)
+		return s.defaultFallback
+	}
+	if policy == nil {
+		return s.defaultFallback
+	}
+	return policy.Fallback
+}
+
+// ListPolicies lists every verification policy
+func (s *VerificationPolicyService) ListPolicies() ([]*domain.VerificationPolicy, error) {
+	policies, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*domain.VerificationPolicy{}
+	}
+	return policies, nil
+}
+
+// SetPolicy sets the fallback of a tenant and endpoint; an empty tenant or endpoint
+// sets it for any
+func (s *VerificationPolicyService) SetPolicy(req *domain.VerificationPolicyRequest) (*domain.VerificationPolicy, error) {
+	policy := &domain.VerificationPolicy{
+		Tenant:    req.Tenant,
+		Endpoint:  req.Endpoint,
+		Fallback:  req.Fallback,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Upsert(policy); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: verification policy for tenant %q, endpoint %q set to %s", policy.Tenant, policy.Endpoint, policy.Fallback)
+	return policy, nil
+}
+
+// DeletePolicy removes a verification policy
+func (s *VerificationPolicyService) DeletePolicy(tenant string, endpoint domain.VerificationEndpoint) error {
+	if err := s.repo.Delete(tenant, endpoint); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT: verification policy for tenant %q, endpoint %q removed", tenant, endpoint)
+	return nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+// verificationRequestScheme is the URI scheme wallets register to handle scanned
+// verification requests
+const verificationRequestScheme = "didwallet://verify"
+
+// VerificationSessionService runs QR-initiated cross-device verification: a verifier
+// on one device creates a session, a wallet on another device scans its QR code and
+// answers with a signed presentation, and the verifier polls or streams the outcome.
+type VerificationSessionService struct {
+	sessionRepo domain.VerificationSessionRepository
+	credentials *CredentialService
+	propagation *RevocationPropagationService
+	// baseURL is the public URL wallets use to reach this service
+	baseURL string
+	ttl     time.Duration
+	// clock dates sessions and checks their expiry
+	clock clock.Clock
+}
+
+// NewVerificationSessionService creates a new verification session service
+func NewVerificationSessionService(sessionRepo domain.VerificationSessionRepository, credentials *CredentialService, baseURL string, ttl time.Duration) *VerificationSessionService {
+	return &VerificationSessionService{
+		sessionRepo: sessionRepo,
+		credentials: credentials,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		ttl:         ttl,
+		clock:       clock.System,
+	}
+}
+
+// SetClock sets the time source of session creation and expiry, clock.System by default
+func (s *VerificationSessionService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetRevocationPropagation records the holders and credentials of verified
+// presentations for the session's verifier, who is notified when they are revoked
+func (s *VerificationSessionService) SetRevocationPropagation(propagation *RevocationPropagationService) {
+	s.propagation = propagation
+}
+
+// CreateSession starts a verification session for an authenticated verifier
+func (s *VerificationSessionService) CreateSession(caller *domain.Caller, req *domain.VerificationSessionCreateRequest) (*domain.VerificationSessionCreateResponse, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	challenge := make([]byte, 16)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	credentialTypes := req.CredentialTypes
+	if credentialTypes == nil {
+		credentialTypes = []string{}
+	}
+
+	now := s.clock.Now()
+	session := &domain.VerificationSession{
+		ID:              uuid.New(),
+		VerifierType:    caller.Type,
+		VerifierID:      caller.ID,
+		Challenge:       hex.EncodeToString(challenge),
+		Domain:          req.Domain,
+		CredentialTypes: credentialTypes,
+		Status:          string(domain.VerificationSessionPending),
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.ttl),
+	}
+
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	sessionURL := s.sessionURL(session.ID)
+	return &domain.VerificationSessionCreateResponse{
+		Session:    session,
+		QRPayload:  s.QRPayload(session.ID),
+		QRImageURL: sessionURL + "/qr",
+		EventsURL:  sessionURL + "/events",
+	}, nil
+}
+
+// GetSession returns a session to the verifier that created it. Sessions belonging
+// to other callers are reported as not found.
+func (s *VerificationSessionService) GetSession(caller *domain.Caller, id uuid.UUID) (*domain.VerificationSession, error) {
+	session, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if caller.IsAnonymous() || session.VerifierType != caller.Type || session.VerifierID != caller.ID {
+		return nil, domain.ErrVerificationSessionNotFound
+	}
+	return session, nil
+}
+
+// QRPayload returns the content of a session's QR code: a wallet deep link pointing at
+// the session's request
+func (s *VerificationSessionService) QRPayload(id uuid.UUID) string {
+	return verificationRequestScheme + "?request_uri=" + url.QueryEscape(s.sessionURL(id)+"/request")
+}
+
+// GetRequest returns the verification request a wallet fetches after scanning the QR
+// code, and marks the session as scanned so the verifier can update its UI
+func (s *VerificationSessionService) GetRequest(id uuid.UUID) (*domain.VerificationRequest, error) {
+	session, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerificationSessionStatus(session.Status).IsFinal() {
+		return nil, domain.ErrVerificationSessionClosed
+	}
+
+	if err := s.sessionRepo.MarkScanned(id); err != nil {
+		return nil, err
+	}
+
+	return &domain.VerificationRequest{
+		SessionID:       session.ID,
+		Challenge:       session.Challenge,
+		Domain:          session.Domain,
+		CredentialTypes: session.CredentialTypes,
+		ResponseURI:     s.sessionURL(session.ID) + "/response",
+		ExpiresAt:       session.ExpiresAt,
+	}, nil
+}
+
+// Respond verifies the wallet's presentation against the session and records the
+// outcome. A session accepts a single response, whether it verifies or not.
+func (s *VerificationSessionService) Respond(id uuid.UUID, req *domain.VerificationResponseRequest) (*domain.PresentationVerifyResponse, error) {
+	session, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerificationSessionStatus(session.Status).IsFinal() {
+		return nil, domain.ErrVerificationSessionClosed
+	}
+
+	result, err := s.credentials.VerifyPresentation(req.Presentation, session.Challenge, session.Domain, session.CredentialTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	session.Status = string(domain.VerificationSessionRejected)
+	if result.Valid {
+		session.Status = string(domain.VerificationSessionVerified)
+		session.Presentation = req.Presentation
+	}
+	session.HolderDID = result.Holder
+	session.Message = result.Message
+	session.CompletedAt = &now
+
+	if err := s.sessionRepo.Complete(session); err != nil {
+		return nil, err
+	}
+
+	if result.Valid && s.propagation != nil {
+		verifier := &domain.Caller{Type: session.VerifierType, ID: session.VerifierID}
+		s.propagation.RecordVerification(verifier, domain.VerifiedSubjectDID, result.Holder)
+		s.propagation.RecordVerification(verifier, domain.VerifiedSubjectCredential, result.CredentialIDs...)
+	}
+
+	return result, nil
+}
+
+// load retrieves a session, expiring it once its deadline has passed without a response
+func (s *VerificationSessionService) load(id uuid.UUID) (*domain.VerificationSession, error) {
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !domain.VerificationSessionStatus(session.Status).IsFinal() && s.clock.Now().After(session.ExpiresAt) {
+		now := s.clock.Now()
+		session.Status = string(domain.VerificationSessionExpired)
+		session.Message = "Session expired before the wallet responded"
+		session.CompletedAt = &now
+		// A concurrent response may have won the race; report whatever was stored
+		if err := s.sessionRepo.Complete(session); err != nil {
+			return s.sessionRepo.GetByID(id)
+		}
+	}
+
+	return session, nil
+}
+
+// sessionURL returns the public URL of a session
+func (s *VerificationSessionService) sessionURL(id uuid.UUID) string {
+	return s.baseURL + "/api/v1/verification-sessions/" + id.String()
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+// walletClaimScheme is the URI scheme wallets register to handle claim links
+const walletClaimScheme = "didwallet://claim"
+
+// walletOfferCodeBytes is the entropy of a claim code
+const walletOfferCodeBytes = 32
+
+// WalletOfferService offers DIDs and their credentials to their owners' mobile wallets:
+// an administrator, such as auth-service at the end of signup, creates an offer for an
+// active DID and sends its one-time claim link to the user, whose wallet claims it.
+type WalletOfferService struct {
+	offerRepo      domain.WalletOfferRepository
+	didRepo        domain.DIDRepository
+	credentialRepo domain.CredentialRepository
+	// baseURL is the public URL wallets use to reach this service
+	baseURL string
+	ttl     time.Duration
+	// clock dates offers and checks their expiry
+	clock clock.Clock
+}
+
+// NewWalletOfferService creates a new wallet offer service whose offers stay claimable
+// for ttl
+func NewWalletOfferService(offerRepo domain.WalletOfferRepository, didRepo domain.DIDRepository, credentialRepo domain.CredentialRepository, baseURL string, ttl time.Duration) *WalletOfferService {
+	return &WalletOfferService{
+		offerRepo:      offerRepo,
+		didRepo:        didRepo,
+		credentialRepo: credentialRepo,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		ttl:            ttl,
+		clock:          clock.System,
+	}
+}
+
+// SetClock sets the time source of offer creation and expiry, clock.System by default
+func (s *WalletOfferService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// CreateOffer offers an active DID, and the given credentials it holds or all of them,
+// to its owner's wallet. The claim link is only returned here.
+func (s *WalletOfferService) CreateOffer(req *domain.WalletOfferCreateRequest) (*domain.WalletOfferCreateResponse, error) {
+	record, err := s.didRepo.GetByDID(req.DID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != string(domain.DIDStatusActive) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDNotActive, record.Did, record.Status)
+	}
+
+	for _, id := range req.CredentialIDs {
+		credential, err := s.credentialRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if credential.SubjectDID != record.Did {
+			return nil, fmt.Errorf("%w: %s is not held by %s", domain.ErrCredentialNotFound, id, record.Did)
+		}
+	}
+
+	code := make([]byte, walletOfferCodeBytes)
+	if _, err := rand.Read(code); err != nil {
+		return nil, fmt.Errorf("failed to generate claim code: %w", err)
+	}
+	plaintext := hex.EncodeToString(code)
+
+	credentialIDs := req.CredentialIDs
+	if credentialIDs == nil {
+		credentialIDs = []uuid.UUID{}
+	}
+
+	now := s.clock.Now()
+	offer := &domain.WalletOffer{
+		ID:            uuid.New(),
+		DIDID:         record.ID,
+		DID:           record.Did,
+		CredentialIDs: credentialIDs,
+		CodeHash:      hashClaimCode(plaintext),
+		Status:        string(domain.WalletOfferPending),
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.ttl),
+	}
+	if err := s.offerRepo.Create(offer); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: wallet offer %s created for %s", offer.ID, record.Did)
+	return &domain.WalletOfferCreateResponse{
+		Offer:    offer,
+		ClaimURI: s.claimURI(offer.ID, plaintext),
+	}, nil
+}
+
+// GetOffer returns an offer, so its creator can track whether it was claimed
+func (s *WalletOfferService) GetOffer(id uuid.UUID) (*domain.WalletOffer, error) {
+	return s.load(id)
+}
+
+// Claim hands the DID and credentials of an offer to the wallet presenting its claim
+// code. An offer is claimed once; a wrong code reports the offer as not found.
+func (s *WalletOfferService) Claim(id uuid.UUID, code string) (*domain.WalletOfferClaim, error) {
+	offer, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashClaimCode(code)), []byte(offer.CodeHash)) != 1 {
+		return nil, domain.ErrWalletOfferNotFound
+	}
+	if domain.WalletOfferStatus(offer.Status).IsFinal() {
+		return nil, domain.ErrWalletOfferClosed
+	}
+
+	record, err := s.didRepo.GetByID(offer.DIDID)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated(record) {
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrDIDDeactivated, record.Did, record.Status)
+	}
+
+	held, err := s.credentialRepo.ListBySubjects([]string{record.Did})
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]*domain.Credential, 0, len(held))
+	for _, credential := range held {
+		if credential.Status != string(domain.CredentialStatusActive) {
+			continue
+		}
+		if len(offer.CredentialIDs) > 0 && !slices.Contains(offer.CredentialIDs, credential.ID) {
+			continue
+		}
+		credentials = append(credentials, credential)
+	}
+
+	// A concurrent claim may have won the race; only one wallet gets the offer
+	if err := s.offerRepo.Close(offer.ID, domain.WalletOfferClaimed, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: wallet offer %s claimed for %s", offer.ID, record.Did)
+	return &domain.WalletOfferClaim{
+		DIDID:       record.ID,
+		DID:         record.Did,
+		Custodial:   record.Custodial,
+		Credentials: credentials,
+	}, nil
+}
+
+// load retrieves an offer, expiring it once its deadline has passed unclaimed
+func (s *WalletOfferService) load(id uuid.UUID) (*domain.WalletOffer, error) {
+	offer, err := s.offerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Status == string(domain.WalletOfferPending) && s.clock.Now().After(offer.ExpiresAt) {
+		// A concurrent claim may have won the race; report whatever was stored
+		if err := s.offerRepo.Close(id, domain.WalletOfferExpired, s.clock.Now()); err != nil {
+			return s.offerRepo.GetByID(id)
+		}
+		offer.Status = string(domain.WalletOfferExpired)
+	}
+
+	return offer, nil
+}
+
+// claimURI returns the wallet deep link claiming an offer with its code
+func (s *WalletOfferService) claimURI(id uuid.UUID, code string) string {
+	claimURL := s.baseURL + "/api/v1/wallet-offers/" + id.String() + "/claim"
+	return walletClaimScheme + "?claim_uri=" + url.QueryEscape(claimURL) + "&code=" + code
+}
+
+// hashClaimCode returns the hex SHA-256 of a claim code
+func hashClaimCode(code string) string {
+	digest := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"did-manager/internal/domain"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// WalletService serves the wallet API used by third-party applications on behalf of a user
+type WalletService struct {
+	didRepo     domain.DIDRepository
+	credentials *CredentialService
+}
+
+// NewWalletService creates a new wallet service
+func NewWalletService(didRepo domain.DIDRepository, credentials *CredentialService) *WalletService {
+	return &WalletService{
+		didRepo:     didRepo,
+		credentials: credentials,
+	}
+}
+
+// ListDIDs lists the DIDs owned by a user
+func (s *WalletService) ListDIDs(userID uuid.UUID) ([]*domain.WalletDID, error) {
+	records, err := s.didRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dids := make([]*domain.WalletDID, 0, len(records))
+	for _, record := range records {
+		dids = append(dids, domain.NewWalletDID(record))
+	}
+	return dids, nil
+}
+
+// ListCredentials lists the credentials held by a user's DIDs
+func (s *WalletService) ListCredentials(userID uuid.UUID) ([]*domain.Credential, error) {
+	return s.credentials.ListCredentialsForUser(userID)
+}
+
+// CreatePresentation presents credentials held by one of the user's DIDs
+func (s *WalletService) CreatePresentation(userID uuid.UUID, req *domain.PresentationCreateRequest) (*did.Presentation, error) {
+	return s.credentials.CreatePresentation(userID, req)
+}
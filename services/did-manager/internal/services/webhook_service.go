@@ -0,0 +1,778 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"did-manager/internal/domain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+	"did-manager/pkg/egress"
+	"did-manager/pkg/queue"
+
+	"github.com/google/uuid"
+)
+
+// Headers identifying a webhook delivery, so receivers can deduplicate retries
+const (
+	WebhookEventHeader    = "X-DID-Manager-Event"
+	WebhookDeliveryHeader = "X-DID-Manager-Delivery"
+	// WebhookReplayHeader carries the replay ID of a re-delivered event
+	WebhookReplayHeader = "X-DID-Manager-Replay"
+)
+
+const (
+	// webhookDeliveryBatchSize bounds the deliveries attempted per worker run
+	webhookDeliveryBatchSize = 50
+	// webhookDeliveryLease postpones claimed deliveries so other workers skip them
+	// while they are attempted
+	webhookDeliveryLease = 2 * time.Minute
+	// webhookMaxBackoff caps the delay between attempts
+	webhookMaxBackoff = 24 * time.Hour
+	// defaultWebhookDeliveryLimit bounds delivery pages without an explicit limit
+	defaultWebhookDeliveryLimit = 20
+	// defaultWebhookReplayLimit bounds the events of a replay without an explicit limit
+	defaultWebhookReplayLimit = 1000
+	// webhookReplayPageSize is the number of retained events read at a time by a replay
+	webhookReplayPageSize = 500
+)
+
+// webhookEventTypes are the event types endpoints may subscribe to
+var webhookEventTypes = map[string]bool{
+	queue.EventCredentialOffered:     true,
+	queue.EventPresentationRequested: true,
+	queue.EventCredentialRevoked:     true,
+	queue.EventCredentialExpiring:    true,
+	queue.EventDIDCreated:            true,
+	queue.EventDIDStatusChanged:      true,
+	queue.EventDIDVisibilityChanged:  true,
+	queue.EventDIDKeyRotated:         true,
+	queue.EventDIDUpdated:            true,
+	queue.EventDIDChainUpdated:       true,
+	queue.EventDIDChainRevoked:       true,
+	queue.EventDIDPasswordChanged:    true,
+	queue.EventDIDDeviceKeyAdded:     true,
+	queue.EventDIDDeviceKeyRevoked:   true,
+	queue.EventRevocationNotice:      true,
+	queue.EventTrustedIssuerAdded:    true,
+	queue.EventTrustedIssuerRemoved:  true,
+}
+
+// webhookHeaderName matches the header names endpoints may set
+var webhookHeaderName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// reservedWebhookHeaders are set on every delivery and cannot be overridden
+var reservedWebhookHeaders = map[string]bool{
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Transfer-Encoding": true,
+	http.CanonicalHeaderKey(WebhookSignatureHeader): true,
+	http.CanonicalHeaderKey(WebhookEventHeader):     true,
+	http.CanonicalHeaderKey(WebhookDeliveryHeader):  true,
+	http.CanonicalHeaderKey(WebhookReplayHeader):    true,
+}
+
+// EventHistory reads the retained domain events from a point in time; implemented by
+// the NATS queue
+type EventHistory interface {
+	ChangeReader
+	// SequenceAt returns the cursor just before the first event published at or after t
+	SequenceAt(t time.Time) (uint64, error)
+}
+
+// WebhookService lets tenants receive domain events at their own webhook endpoints.
+// Every event is stored as a delivery per subscribed endpoint and posted, signed with
+// the endpoint's secret, until it succeeds or runs out of attempts; the attempts are
+// kept for tenants to inspect.
+type WebhookService struct {
+	repo   domain.WebhookRepository
+	access *AccessService
+	client *http.Client
+	// events reads retained events for replays; nil when no event stream is available
+	events EventHistory
+	// encryption encrypts payloads for endpoints that ask for it; nil when payloads
+	// cannot be encrypted
+	encryption *EncryptionService
+	// clock dates endpoints and deliveries and schedules retries
+	clock clock.Clock
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo domain.WebhookRepository, access *AccessService) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		access: access,
+		client: egress.Client(10 * time.Second),
+		clock:  clock.System,
+	}
+}
+
+// SetClock sets the time source dating deliveries and scheduling retries, clock.System
+// by default. The repository must schedule on the same clock.
+func (s *WebhookService) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// SetEventHistory lets administrators replay retained events to tenants' endpoints
+func (s *WebhookService) SetEventHistory(events EventHistory) {
+	s.events = events
+}
+
+// SetEncryption lets endpoints receive their payloads encrypted to a DID
+func (s *WebhookService) SetEncryption(encryption *EncryptionService) {
+	s.encryption = encryption
+}
+
+// CreateEndpoint configures a webhook endpoint for the calling tenant. The response
+// carries the generated signing secret, which is not shown again.
+func (s *WebhookService) CreateEndpoint(caller *domain.Caller, req *domain.WebhookEndpointRequest) (*domain.WebhookEndpoint, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkEncryption(caller, req); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	endpoint := &domain.WebhookEndpoint{
+		ID:         uuid.New(),
+		TenantType: caller.Type,
+		Tenant:     caller.ID,
+		Secret:     secret,
+		CreatedAt:  now,
+	}
+	applyWebhookRequest(endpoint, req, now)
+
+	if err := s.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: webhook endpoint %s created by %s caller %s for %s", endpoint.ID, caller.Type, caller.ID, endpoint.URL)
+
+	return endpoint, nil
+}
+
+// ListEndpoints lists the calling tenant's webhook endpoints
+func (s *WebhookService) ListEndpoints(caller *domain.Caller) ([]*domain.WebhookEndpoint, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	endpoints, err := s.repo.ListEndpointsByTenant(caller.Type, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoints == nil {
+		endpoints = []*domain.WebhookEndpoint{}
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// GetEndpoint returns one of the calling tenant's webhook endpoints
+func (s *WebhookService) GetEndpoint(caller *domain.Caller, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.ownedEndpoint(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// UpdateEndpoint replaces the configuration of one of the calling tenant's endpoints,
+// keeping its secret
+func (s *WebhookService) UpdateEndpoint(caller *domain.Caller, id uuid.UUID, req *domain.WebhookEndpointRequest) (*domain.WebhookEndpoint, error) {
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.ownedEndpoint(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEncryption(caller, req); err != nil {
+		return nil, err
+	}
+	applyWebhookRequest(endpoint, req, s.clock.Now())
+
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// RotateSecret replaces the signing secret of one of the calling tenant's endpoints,
+// returning the endpoint with the new secret
+func (s *WebhookService) RotateSecret(caller *domain.Caller, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	endpoint, err := s.ownedEndpoint(caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	endpoint.UpdatedAt = s.clock.Now()
+
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	log.Printf("AUDIT: webhook endpoint %s secret rotated by %s caller %s", endpoint.ID, caller.Type, caller.ID)
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes one of the calling tenant's endpoints with its delivery
+// history
+func (s *WebhookService) DeleteEndpoint(caller *domain.Caller, id uuid.UUID) error {
+	if _, err := s.ownedEndpoint(caller, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteEndpoint(id); err != nil {
+		return err
+	}
+	log.Printf("AUDIT: webhook endpoint %s deleted by %s caller %s", id, caller.Type, caller.ID)
+	return nil
+}
+
+// ListDeliveries lists the deliveries of one of the calling tenant's endpoints, newest
+// first, with their payloads
+func (s *WebhookService) ListDeliveries(caller *domain.Caller, endpointID uuid.UUID, query *domain.WebhookDeliveryQuery) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.ownedEndpoint(caller, endpointID); err != nil {
+		return nil, err
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultWebhookDeliveryLimit
+	}
+	deliveries, err := s.repo.ListDeliveries(endpointID, query)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery of one of the calling tenant's endpoints with the
+// history of its attempts
+func (s *WebhookService) GetDelivery(caller *domain.Caller, endpointID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	if _, err := s.ownedEndpoint(caller, endpointID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.EndpointID != endpointID {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+
+	attempts, err := s.repo.ListAttempts(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts == nil {
+		attempts = []*domain.WebhookAttempt{}
+	}
+	delivery.AttemptLog = attempts
+	return delivery, nil
+}
+
+// checkEncryption ensures payloads can be encrypted to the DID an endpoint request asks
+// for on behalf of the tenant
+func (s *WebhookService) checkEncryption(caller *domain.Caller, req *domain.WebhookEndpointRequest) error {
+	if req.EncryptToDID == "" {
+		return nil
+	}
+	if s.encryption == nil {
+		return fmt.Errorf("%w: encrypted payloads are not available", domain.ErrInvalidWebhook)
+	}
+	if err := s.encryption.CheckRecipient(caller, req.EncryptToDID); err != nil {
+		return fmt.Errorf("%w: cannot encrypt to %s: %v", domain.ErrInvalidWebhook, req.EncryptToDID, err)
+	}
+	return nil
+}
+
+// ownedEndpoint loads an endpoint of the calling tenant. Other tenants' endpoints are
+// reported as not found.
+func (s *WebhookService) ownedEndpoint(caller *domain.Caller, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	if caller.IsAnonymous() {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	endpoint, err := s.repo.GetEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.TenantType != caller.Type || endpoint.Tenant != caller.ID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return endpoint, nil
+}
+
+// HandleEvent stores a delivery of a domain event for every active endpoint subscribed
+// to its type whose tenant may see it; the delivery worker posts them
+func (s *WebhookService) HandleEvent(event *queue.Event) error {
+	endpoints, err := s.repo.ListActiveEndpoints(event.Type)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	now := s.clock.Now()
+	for _, endpoint := range endpoints {
+		visible, err := s.visible(event, &domain.Caller{Type: endpoint.TenantType, ID: endpoint.Tenant})
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+		if err := s.enqueue(endpoint, event, payload, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Notify stores a delivery of an event addressed to a single tenant, such as a
+// revocation notice, for each of its active endpoints subscribed to the event type
+func (s *WebhookService) Notify(tenant *domain.Caller, event *queue.Event) error {
+	endpoints, err := s.repo.ListEndpointsByTenant(tenant.Type, tenant.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	now := s.clock.Now()
+	for _, endpoint := range endpoints {
+		if !endpoint.Active || !endpoint.Subscribes(event.Type) {
+			continue
+		}
+		if err := s.enqueue(endpoint, event, payload, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replay re-delivers the retained events selected by an administrator to a tenant's
+// endpoints. Each endpoint gets the events it subscribes to that its tenant may see,
+// as new deliveries carrying the original event in their payload, so receivers can
+// deduplicate by event ID. Events are retained as long as the event stream keeps them;
+// revocation notices are not part of it and cannot be replayed.
+func (s *WebhookService) Replay(req *domain.WebhookReplayRequest) (*domain.WebhookReplay, error) {
+	if s.events == nil {
+		return nil, domain.ErrWebhookReplayUnavailable
+	}
+
+	// Events are stamped with the wall clock by the event stream
+	until := time.Now()
+	if req.Until != nil {
+		until = *req.Until
+	}
+	if !req.Since.Before(until) {
+		return nil, fmt.Errorf("%w: since must be before until", domain.ErrInvalidWebhookReplay)
+	}
+	eventTypes := make(map[string]bool, len(req.EventTypes))
+	for _, eventType := range req.EventTypes {
+		if !webhookEventTypes[eventType] || eventType == queue.EventRevocationNotice {
+			return nil, fmt.Errorf("%w: event type %q cannot be replayed", domain.ErrInvalidWebhookReplay, eventType)
+		}
+		eventTypes[eventType] = true
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultWebhookReplayLimit
+	}
+
+	tenant := webhookTenant(req.Tenant)
+	endpoints, err := s.replayEndpoints(tenant, req.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &domain.WebhookReplay{
+		ID:          uuid.New(),
+		Tenant:      req.Tenant,
+		EndpointIDs: make([]uuid.UUID, 0, len(endpoints)),
+	}
+	for _, endpoint := range endpoints {
+		replay.EndpointIDs = append(replay.EndpointIDs, endpoint.ID)
+	}
+
+	cursor, err := s.events.SequenceAt(req.Since)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		events, next, more, err := s.events.ReadEvents("", cursor, webhookReplayPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read retained events: %w", err)
+		}
+
+		for _, stored := range events {
+			event := &stored.Event
+			if event.OccurredAt.Before(req.Since) {
+				continue
+			}
+			if event.OccurredAt.After(until) {
+				more = false
+				break
+			}
+			if len(eventTypes) > 0 && !eventTypes[event.Type] {
+				continue
+			}
+			if req.Resource != "" && event.Subject != req.Resource && event.Data["credential_id"] != req.Resource {
+				continue
+			}
+			if replay.Events == limit {
+				replay.ResumeAt = &event.OccurredAt
+				more = false
+				break
+			}
+
+			queued, err := s.replayEvent(replay.ID, endpoints, tenant, event)
+			if err != nil {
+				return nil, err
+			}
+			if queued > 0 {
+				replay.Events++
+				replay.Deliveries += queued
+			}
+		}
+
+		if !more {
+			break
+		}
+		cursor = next
+	}
+
+	log.Printf("AUDIT: webhook replay %s queued %d deliveries of %d events for tenant %s",
+		replay.ID, replay.Deliveries, replay.Events, req.Tenant)
+	return replay, nil
+}
+
+// replayEndpoints selects the endpoints a replay delivers to: the given endpoint of the
+// tenant, or every active endpoint of the tenant
+func (s *WebhookService) replayEndpoints(tenant *domain.Caller, endpointID *uuid.UUID) ([]*domain.WebhookEndpoint, error) {
+	endpoints, err := s.repo.ListEndpointsByTenant(tenant.Type, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*domain.WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if endpointID != nil && endpoint.ID == *endpointID {
+			return []*domain.WebhookEndpoint{endpoint}, nil
+		}
+		if endpointID == nil && endpoint.Active {
+			selected = append(selected, endpoint)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return selected, nil
+}
+
+// replayEvent queues a replayed event for the endpoints subscribed to it if the tenant
+// may see it, returning the number of deliveries queued
+func (s *WebhookService) replayEvent(replayID uuid.UUID, endpoints []*domain.WebhookEndpoint, tenant *domain.Caller, event *queue.Event) (int, error) {
+	var subscribed []*domain.WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Subscribes(event.Type) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+	if len(subscribed) == 0 {
+		return 0, nil
+	}
+
+	visible, err := s.visible(event, tenant)
+	if err != nil || !visible {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	now := s.clock.Now()
+	for _, endpoint := range subscribed {
+		delivery := newWebhookDelivery(endpoint, event, payload, now)
+		delivery.EventID = event.ID + ":replay:" + replayID.String()
+		delivery.ReplayID = &replayID
+		if err := s.repo.CreateDelivery(delivery); err != nil {
+			return 0, err
+		}
+	}
+	return len(subscribed), nil
+}
+
+// webhookTenant identifies the caller behind a tenant, which is a DID or an API key ID
+func webhookTenant(tenant string) *domain.Caller {
+	if strings.HasPrefix(tenant, "did:") {
+		return &domain.Caller{Type: domain.CallerTypeDID, ID: tenant}
+	}
+	return &domain.Caller{Type: domain.CallerTypeAPIKey, ID: tenant}
+}
+
+// enqueue stores a pending delivery of an event to an endpoint
+func (s *WebhookService) enqueue(endpoint *domain.WebhookEndpoint, event *queue.Event, payload []byte, now time.Time) error {
+	return s.repo.CreateDelivery(newWebhookDelivery(endpoint, event, payload, now))
+}
+
+// newWebhookDelivery builds a pending delivery of an event to an endpoint, due now
+func newWebhookDelivery(endpoint *domain.WebhookEndpoint, event *queue.Event, payload []byte, now time.Time) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    endpoint.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// visible reports whether a tenant may receive an event. DID change events reach the
+// tenants that may resolve the DID, like the change feed, and changes to the public
+// trust registry reach every tenant; other events only reach their subject and the
+// issuer of the credential they concern.
+func (s *WebhookService) visible(event *queue.Event, tenant *domain.Caller) (bool, error) {
+	if strings.HasPrefix(event.Type, queue.EventTrustRegistryPrefix) {
+		return true, nil
+	}
+	if !strings.HasPrefix(event.Type, queue.EventDIDPrefix) {
+		return tenant.Type == domain.CallerTypeDID &&
+			(tenant.ID == event.Subject || tenant.ID == event.Data["issuer"]), nil
+	}
+
+	_, err := s.access.CheckDIDAccess(event.Subject, tenant)
+	if errors.Is(err, domain.ErrDIDNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeliverDue attempts the deliveries whose next attempt is due. Failed attempts are
+// retried with exponential backoff until the endpoint's attempts run out.
+func (s *WebhookService) DeliverDue() error {
+	deliveries, err := s.repo.ClaimDueDeliveries(webhookDeliveryBatchSize, webhookDeliveryLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	endpoints := make(map[uuid.UUID]*domain.WebhookEndpoint)
+	for _, delivery := range deliveries {
+		endpoint, ok := endpoints[delivery.EndpointID]
+		if !ok {
+			endpoint, err = s.repo.GetEndpoint(delivery.EndpointID)
+			if errors.Is(err, domain.ErrWebhookNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			endpoints[delivery.EndpointID] = endpoint
+		}
+		// Deliveries to a deactivated endpoint wait until it is reactivated
+		if !endpoint.Active {
+			continue
+		}
+
+		if err := s.attempt(endpoint, delivery); err != nil {
+			log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// attempt posts a delivery once and records the outcome
+func (s *WebhookService) attempt(endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) error {
+	attempt := &domain.WebhookAttempt{
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: s.clock.Now(),
+	}
+	start := time.Now()
+	attempt.StatusCode, attempt.Error = s.post(endpoint, delivery)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+
+	status := domain.WebhookDeliverySucceeded
+	var next *time.Time
+	if attempt.Error != "" {
+		status = domain.WebhookDeliveryFailed
+		if attempt.Attempt < endpoint.MaxAttempts {
+			status = domain.WebhookDeliveryPending
+			at := attempt.AttemptedAt.Add(webhookBackoff(endpoint.BackoffSeconds, attempt.Attempt))
+			next = &at
+		}
+		log.Printf("Webhook delivery %s to endpoint %s failed (attempt %d of %d): %s",
+			delivery.ID, endpoint.ID, attempt.Attempt, endpoint.MaxAttempts, attempt.Error)
+	}
+
+	return s.repo.RecordAttempt(delivery.ID, attempt, status, next)
+}
+
+// post sends a delivery to its endpoint, returning the response status, and an error
+// message unless the endpoint answered with a 2xx status. Response bodies are not kept,
+// so endpoints cannot be used to read other services through the delivery history.
+func (s *WebhookService) post(endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (int, string) {
+	payload, contentType := []byte(delivery.Payload), "application/json"
+	if endpoint.EncryptToDID != "" {
+		if s.encryption == nil {
+			return 0, "encrypted payloads are not available"
+		}
+		tenant := &domain.Caller{Type: endpoint.TenantType, ID: endpoint.Tenant}
+		encrypted, err := s.encryption.Encrypt(tenant, endpoint.EncryptToDID, "json", delivery.Payload)
+		if err != nil {
+			return 0, fmt.Sprintf("failed to encrypt webhook payload: %v", err)
+		}
+		payload, contentType = []byte(encrypted.JWE), did.JWEMediaType
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Sprintf("failed to create webhook request: %v", err)
+	}
+	for name, value := range endpoint.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	if delivery.ReplayID != nil {
+		req.Header.Set(WebhookReplayHeader, delivery.ReplayID.String())
+	}
+	mac := hmac.New(sha256.New, []byte(endpoint.Secret))
+	mac.Write(payload)
+	req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Sprintf("webhook request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
+
+// webhookBackoff is the delay before retrying after the given attempt: the base delay
+// doubled with every retry, capped at webhookMaxBackoff
+func webhookBackoff(baseSeconds, attempt int) time.Duration {
+	delay := time.Duration(baseSeconds) * time.Second
+	for i := 1; i < attempt && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
+
+// validateWebhookRequest checks the URL, event types and headers of an endpoint request.
+// URLs naming a non-public address are refused here, and hostnames resolving to one when
+// delivering.
+func validateWebhookRequest(req *domain.WebhookEndpointRequest) error {
+	if err := egress.CheckURL(req.URL); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+	}
+	for _, eventType := range req.EventTypes {
+		if !webhookEventTypes[eventType] {
+			return fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidWebhook, eventType)
+		}
+	}
+	for name, value := range req.Headers {
+		if !webhookHeaderName.MatchString(name) {
+			return fmt.Errorf("%w: invalid header name %q", domain.ErrInvalidWebhook, name)
+		}
+		if reservedWebhookHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("%w: header %s is set by the service", domain.ErrInvalidWebhook, name)
+		}
+		if strings.ContainsAny(value, "\r\n") || len(value) > 1024 {
+			return fmt.Errorf("%w: invalid value for header %s", domain.ErrInvalidWebhook, name)
+		}
+	}
+	return nil
+}
+
+// applyWebhookRequest copies an endpoint request onto an endpoint, applying defaults
+func applyWebhookRequest(endpoint *domain.WebhookEndpoint, req *domain.WebhookEndpointRequest, now time.Time) {
+	endpoint.URL = req.URL
+	endpoint.EventTypes = req.EventTypes
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+	endpoint.Headers = make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		endpoint.Headers[http.CanonicalHeaderKey(name)] = value
+	}
+	endpoint.MaxAttempts = req.MaxAttempts
+	if endpoint.MaxAttempts == 0 {
+		endpoint.MaxAttempts = domain.DefaultWebhookMaxAttempts
+	}
+	endpoint.BackoffSeconds = req.BackoffSeconds
+	if endpoint.BackoffSeconds == 0 {
+		endpoint.BackoffSeconds = domain.DefaultWebhookBackoffSeconds
+	}
+	endpoint.Active = req.Active == nil || *req.Active
+	endpoint.EncryptToDID = req.EncryptToDID
+	endpoint.UpdatedAt = now
+}
+
+// newWebhookSecret generates a hex-encoded 256-bit signing secret
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
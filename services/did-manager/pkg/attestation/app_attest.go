@@ -0,0 +1,212 @@
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// appAttestNonceOID is the extension of App Attest credential certificates holding the
+// attestation's nonce
+var appAttestNonceOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+
+// App Attest AAGUIDs of the development and production environments
+var (
+	appAttestDevelopmentAAGUID = []byte("appattestdevelop")
+	appAttestProductionAAGUID  = append([]byte("appattest"), make([]byte, 7)...)
+)
+
+// AppAttestConfig configures App Attest verification
+type AppAttestConfig struct {
+	// AppID is the app's <team ID>.<bundle ID>
+	AppID string
+	// RootCAPEM is Apple's App Attestation Root CA certificate
+	RootCAPEM []byte
+	// Development accepts attestations from the development environment instead of
+	// production
+	Development bool
+}
+
+// AppAttestVerifier verifies App Attest attestation objects. The app attests its key
+// with the SHA-256 hash of the challenge as the client data hash.
+type AppAttestVerifier struct {
+	cfg   AppAttestConfig
+	roots *x509.CertPool
+	now   func() time.Time
+}
+
+// NewAppAttestVerifier creates an App Attest verifier
+func NewAppAttestVerifier(cfg AppAttestConfig) (*AppAttestVerifier, error) {
+	if cfg.AppID == "" {
+		return nil, fmt.Errorf("invalid App Attest configuration: app ID is required")
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(cfg.RootCAPEM) {
+		return nil, fmt.Errorf("invalid App Attest configuration: no root CA certificate")
+	}
+
+	return &AppAttestVerifier{
+		cfg:   cfg,
+		roots: roots,
+		now:   time.Now,
+	}, nil
+}
+
+// Platform returns the App Attest platform identifier
+func (v *AppAttestVerifier) Platform() string {
+	return PlatformAppAttest
+}
+
+// Verify checks an App Attest attestation object following Apple's server-side
+// validation steps: the certificate chain, the nonce over the authenticator data and
+// challenge, the key ID, the app ID, the counter and the environment
+func (v *AppAttestVerifier) Verify(ctx context.Context, evidence *Evidence, challenge []byte) (*Result, error) {
+	keyID, err := base64.StdEncoding.DecodeString(evidence.KeyID)
+	if err != nil || len(keyID) != sha256.Size {
+		return nil, fmt.Errorf("%w: malformed key ID", ErrInvalidAttestation)
+	}
+	object, err := base64.StdEncoding.DecodeString(evidence.Token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed attestation object", ErrInvalidAttestation)
+	}
+
+	decoded, err := decodeCBOR(object)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	attestation, _ := decoded.(map[string]any)
+	statement, _ := attestation["attStmt"].(map[string]any)
+	authData, _ := attestation["authData"].([]byte)
+	chain, _ := statement["x5c"].([]any)
+	if attestation["fmt"] != "apple-appattest" || len(authData) == 0 || len(chain) == 0 {
+		return nil, fmt.Errorf("%w: not an App Attest attestation object", ErrInvalidAttestation)
+	}
+
+	credCert, err := v.verifyChain(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(challenge)
+	nonce := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	certNonce, err := credentialNonce(credCert)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(certNonce, nonce[:]) {
+		return nil, fmt.Errorf("%w: nonce does not match the challenge", ErrInvalidAttestation)
+	}
+
+	// The key ID is the hash of the credential key's uncompressed point
+	publicKey, ok := credCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: credential key is not P-256", ErrInvalidAttestation)
+	}
+	point, err := publicKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	if keyHash := sha256.Sum256(point.Bytes()); !bytes.Equal(keyHash[:], keyID) {
+		return nil, fmt.Errorf("%w: key ID does not match the credential key", ErrInvalidAttestation)
+	}
+
+	if err := v.checkAuthData(authData, keyID); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Platform: PlatformAppAttest,
+		AppID:    v.cfg.AppID,
+		KeyID:    evidence.KeyID,
+	}, nil
+}
+
+// verifyChain verifies the attestation's certificate chain up to the root CA and
+// returns the credential certificate
+func (v *AppAttestVerifier) verifyChain(chain []any) (*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0, len(chain))
+	for _, item := range chain {
+		der, ok := item.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed certificate chain", ErrInvalidAttestation)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed certificate: %v", ErrInvalidAttestation, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: certificate chain does not verify: %v", ErrInvalidAttestation, err)
+	}
+	return certs[0], nil
+}
+
+// checkAuthData checks the authenticator data's relying party, counter, environment
+// and credential ID
+func (v *AppAttestVerifier) checkAuthData(authData, keyID []byte) error {
+	// rpIdHash (32) | flags (1) | counter (4) | aaguid (16) | credentialIdLength (2) | credentialId
+	const credentialOffset = 32 + 1 + 4 + 16 + 2
+	if len(authData) < credentialOffset {
+		return fmt.Errorf("%w: truncated authenticator data", ErrInvalidAttestation)
+	}
+
+	appIDHash := sha256.Sum256([]byte(v.cfg.AppID))
+	if !bytes.Equal(authData[:32], appIDHash[:]) {
+		return fmt.Errorf("%w: attestation is for another app", ErrInvalidAttestation)
+	}
+	if binary.BigEndian.Uint32(authData[33:37]) != 0 {
+		return fmt.Errorf("%w: key was already attested", ErrInvalidAttestation)
+	}
+
+	aaguid := appAttestProductionAAGUID
+	if v.cfg.Development {
+		aaguid = appAttestDevelopmentAAGUID
+	}
+	if !bytes.Equal(authData[37:53], aaguid) {
+		return fmt.Errorf("%w: attestation is from another environment", ErrInvalidAttestation)
+	}
+
+	length := int(binary.BigEndian.Uint16(authData[53:55]))
+	if len(authData) < credentialOffset+length || !bytes.Equal(authData[credentialOffset:credentialOffset+length], keyID) {
+		return fmt.Errorf("%w: credential ID does not match the key ID", ErrInvalidAttestation)
+	}
+	return nil
+}
+
+// credentialNonce extracts the nonce from a credential certificate's App Attest
+// extension
+func credentialNonce(cert *x509.Certificate) ([]byte, error) {
+	for _, extension := range cert.Extensions {
+		if !extension.Id.Equal(appAttestNonceOID) {
+			continue
+		}
+		var value struct {
+			Nonce []byte `asn1:"tag:1,explicit"`
+		}
+		if _, err := asn1.Unmarshal(extension.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: malformed nonce extension", ErrInvalidAttestation)
+		}
+		return value.Nonce, nil
+	}
+	return nil, fmt.Errorf("%w: credential certificate has no nonce", ErrInvalidAttestation)
+}
@@ -0,0 +1,47 @@
+// Package attestation verifies platform attestations of mobile app instances: Play
+// Integrity verdicts on Android and App Attest attestations on iOS. An attestation
+// vouches that a genuine build of the app on a genuine device made a request bound to
+// a server-chosen challenge.
+package attestation
+
+import (
+	"context"
+	"errors"
+)
+
+// Attestation platforms
+const (
+	PlatformPlayIntegrity = "play_integrity"
+	PlatformAppAttest     = "app_attest"
+)
+
+// ErrInvalidAttestation is returned when an attestation does not verify: it is
+// malformed, forged, stale, for another app or challenge, or from an untrusted device
+var ErrInvalidAttestation = errors.New("attestation is invalid")
+
+// Evidence is an attestation as submitted by an app instance
+type Evidence struct {
+	// Token is the Play Integrity token, or the base64 App Attest attestation object
+	Token string
+	// KeyID is the base64 App Attest key ID; Play Integrity has none
+	KeyID string
+}
+
+// Result describes the app instance an attestation vouches for
+type Result struct {
+	Platform string
+	// AppID is the attested app: the Android package name, or the iOS app ID
+	// (<team ID>.<bundle ID>)
+	AppID string
+	// KeyID is the app instance's App Attest key ID; empty for Play Integrity
+	KeyID string
+}
+
+// Verifier verifies the attestations of one platform
+type Verifier interface {
+	// Platform is the platform identifier attestations are submitted with
+	Platform() string
+	// Verify checks that evidence attests an app instance and is bound to challenge.
+	// Attestations that do not verify return errors wrapping ErrInvalidAttestation.
+	Verify(ctx context.Context, evidence *Evidence, challenge []byte) (*Result, error)
+}
@@ -0,0 +1,225 @@
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const testAppID = "TEAMID1234.com.example.wallet"
+
+// appAttestFixture is an App Attest CA and an app instance key it can attest
+type appAttestFixture struct {
+	rootKey  *ecdsa.PrivateKey
+	root     *x509.Certificate
+	rootPEM  []byte
+	appKey   *ecdsa.PrivateKey
+	keyIDRaw []byte
+}
+
+func newAppAttestFixture(t *testing.T) *appAttestFixture {
+	t.Helper()
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test App Attestation Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := appKey.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := sha256.Sum256(point.Bytes())
+
+	return &appAttestFixture{
+		rootKey:  rootKey,
+		root:     root,
+		rootPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		appKey:   appKey,
+		keyIDRaw: keyID[:],
+	}
+}
+
+// attest builds an attestation object for the app key over challenge
+func (f *appAttestFixture) attest(t *testing.T, appID string, challenge []byte) *Evidence {
+	t.Helper()
+
+	appIDHash := sha256.Sum256([]byte(appID))
+	authData := append([]byte(nil), appIDHash[:]...)
+	authData = append(authData, 0x41)
+	authData = binary.BigEndian.AppendUint32(authData, 0)
+	authData = append(authData, appAttestProductionAAGUID...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(f.keyIDRaw)))
+	authData = append(authData, f.keyIDRaw...)
+
+	clientDataHash := sha256.Sum256(challenge)
+	nonce := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	extension, err := asn1.Marshal(struct {
+		Nonce []byte `asn1:"tag:1,explicit"`
+	}{Nonce: nonce[:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		Subject:         pkix.Name{CommonName: "credential"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: appAttestNonceOID, Value: extension}},
+	}
+	credCert, err := x509.CreateCertificate(rand.Reader, template, f.root, &f.appKey.PublicKey, f.rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := cborMap(
+		"fmt", cborText("apple-appattest"),
+		"attStmt", cborMap(
+			"x5c", cborArray(cborBytes(credCert)),
+			"receipt", cborBytes([]byte("receipt")),
+		),
+		"authData", cborBytes(authData),
+	)
+	return &Evidence{
+		Token: base64.StdEncoding.EncodeToString(object),
+		KeyID: base64.StdEncoding.EncodeToString(f.keyIDRaw),
+	}
+}
+
+func TestAppAttestVerifier(t *testing.T) {
+	fixture := newAppAttestFixture(t)
+	verifier, err := NewAppAttestVerifier(AppAttestConfig{AppID: testAppID, RootCAPEM: fixture.rootPEM})
+	if err != nil {
+		t.Fatalf("NewAppAttestVerifier: %v", err)
+	}
+	challenge := []byte("device-key challenge")
+
+	t.Run("valid attestation", func(t *testing.T) {
+		result, err := verifier.Verify(context.Background(), fixture.attest(t, testAppID, challenge), challenge)
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		if result.Platform != PlatformAppAttest || result.AppID != testAppID {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("other challenge", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), fixture.attest(t, testAppID, challenge), []byte("other"))
+		if !errors.Is(err, ErrInvalidAttestation) {
+			t.Errorf("expected ErrInvalidAttestation, got %v", err)
+		}
+	})
+
+	t.Run("other app", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), fixture.attest(t, "TEAMID1234.com.example.other", challenge), challenge)
+		if !errors.Is(err, ErrInvalidAttestation) {
+			t.Errorf("expected ErrInvalidAttestation, got %v", err)
+		}
+	})
+
+	t.Run("untrusted root", func(t *testing.T) {
+		other := newAppAttestFixture(t)
+		_, err := verifier.Verify(context.Background(), other.attest(t, testAppID, challenge), challenge)
+		if !errors.Is(err, ErrInvalidAttestation) {
+			t.Errorf("expected ErrInvalidAttestation, got %v", err)
+		}
+	})
+
+	t.Run("development environment", func(t *testing.T) {
+		development, err := NewAppAttestVerifier(AppAttestConfig{AppID: testAppID, RootCAPEM: fixture.rootPEM, Development: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = development.Verify(context.Background(), fixture.attest(t, testAppID, challenge), challenge)
+		if !errors.Is(err, ErrInvalidAttestation) {
+			t.Errorf("expected ErrInvalidAttestation, got %v", err)
+		}
+	})
+}
+
+func TestDecodeCBORRejectsMalformedInput(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":          {},
+		"truncated text": {0x63, 'a', 'b'},
+		"trailing bytes": {0x01, 0x02},
+		"integer key":    {0xa1, 0x01, 0x01},
+		"indefinite":     {0x9f, 0xff},
+	} {
+		if _, err := decodeCBOR(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// cborHead encodes a CBOR item head
+func cborHead(major byte, argument int) []byte {
+	switch {
+	case argument < 24:
+		return []byte{major<<5 | byte(argument)}
+	case argument < 1<<8:
+		return []byte{major<<5 | 24, byte(argument)}
+	default:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(argument))
+	}
+}
+
+func cborBytes(value []byte) []byte {
+	return append(cborHead(2, len(value)), value...)
+}
+
+func cborText(value string) []byte {
+	return append(cborHead(3, len(value)), value...)
+}
+
+func cborArray(items ...[]byte) []byte {
+	encoded := cborHead(4, len(items))
+	for _, item := range items {
+		encoded = append(encoded, item...)
+	}
+	return encoded
+}
+
+// cborMap encodes alternating text keys and encoded values
+func cborMap(entries ...any) []byte {
+	encoded := cborHead(5, len(entries)/2)
+	for i := 0; i < len(entries); i += 2 {
+		encoded = append(encoded, cborText(entries[i].(string))...)
+		encoded = append(encoded, entries[i+1].([]byte)...)
+	}
+	return encoded
+}
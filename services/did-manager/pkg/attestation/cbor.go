@@ -0,0 +1,122 @@
+package attestation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxCBORDepth bounds the nesting of decoded CBOR items
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("truncated CBOR item")
+
+// decodeCBOR decodes the definite-length CBOR subset used by attestation objects:
+// unsigned and negative integers, byte and text strings, arrays, maps with text keys
+// and simple values. Integers decode to int64, byte strings to []byte, text to string,
+// arrays to []any and maps to map[string]any.
+func decodeCBOR(data []byte) (any, error) {
+	value, rest, err := decodeCBORItem(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after CBOR item", len(rest))
+	}
+	return value, nil
+}
+
+// decodeCBORItem decodes one item, returning it with the bytes following it
+func decodeCBORItem(data []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("CBOR nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	var argument uint64
+	switch {
+	case info < 24:
+		argument = uint64(info)
+	case info == 24 && len(data) >= 1:
+		argument, data = uint64(data[0]), data[1:]
+	case info == 25 && len(data) >= 2:
+		argument, data = uint64(binary.BigEndian.Uint16(data)), data[2:]
+	case info == 26 && len(data) >= 4:
+		argument, data = uint64(binary.BigEndian.Uint32(data)), data[4:]
+	case info == 27 && len(data) >= 8:
+		argument, data = binary.BigEndian.Uint64(data), data[8:]
+	case info >= 28:
+		return nil, nil, fmt.Errorf("unsupported CBOR additional information %d", info)
+	default:
+		return nil, nil, errCBORTruncated
+	}
+
+	switch major {
+	case 0, 1:
+		if argument > 1<<63-1 {
+			return nil, nil, errors.New("CBOR integer out of range")
+		}
+		if major == 1 {
+			return -1 - int64(argument), data, nil
+		}
+		return int64(argument), data, nil
+	case 2, 3:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		value := data[:argument]
+		if major == 3 {
+			return string(value), data[argument:], nil
+		}
+		return append([]byte(nil), value...), data[argument:], nil
+	case 4:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		items := make([]any, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, rest, err := decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items, data = append(items, item), rest
+		}
+		return items, data, nil
+	case 5:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		entries := make(map[string]any, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, rest, err := decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, nil, errors.New("CBOR map key is not text")
+			}
+			value, rest, err := decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			entries[name], data = value, rest
+		}
+		return entries, data, nil
+	case 7:
+		switch argument {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unsupported CBOR major type %d", major)
+}
@@ -0,0 +1,234 @@
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	playIntegrityScope     = "https://www.googleapis.com/auth/playintegrity"
+	playIntegrityDecodeURL = "https://playintegrity.googleapis.com/v1/%s:decodeIntegrityToken"
+	googleTokenURI         = "https://oauth2.googleapis.com/token"
+	tokenRefreshGap        = time.Minute
+
+	// playIntegrityMaxAge bounds how long ago a Play Integrity verdict may have been
+	// requested
+	playIntegrityMaxAge = 10 * time.Minute
+)
+
+// serviceAccount holds the fields of a Google service account key file used to call
+// the Play Integrity API
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// PlayIntegrityVerifier verifies Play Integrity tokens by decoding them with Google's
+// Play Integrity API. The app requests the token with the base64url-encoded challenge
+// as its nonce. A verdict passes when Play recognizes the app and the device meets
+// device integrity.
+type PlayIntegrityVerifier struct {
+	packageName string
+	account     serviceAccount
+	decodeURL   string
+	httpClient  *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewPlayIntegrityVerifier creates a Play Integrity verifier for an Android package from
+// the contents of a service account key file with access to the Play Integrity API
+func NewPlayIntegrityVerifier(packageName string, credentialsJSON []byte) (*PlayIntegrityVerifier, error) {
+	if packageName == "" {
+		return nil, fmt.Errorf("invalid Play Integrity configuration: package name is required")
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(credentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("invalid Play Integrity credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("invalid Play Integrity credentials: client_email and private_key are required")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = googleTokenURI
+	}
+
+	return &PlayIntegrityVerifier{
+		packageName: packageName,
+		account:     account,
+		decodeURL:   fmt.Sprintf(playIntegrityDecodeURL, url.PathEscape(packageName)),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Platform returns the Play Integrity platform identifier
+func (v *PlayIntegrityVerifier) Platform() string {
+	return PlatformPlayIntegrity
+}
+
+// integrityVerdict is the decoded payload of a Play Integrity token
+type integrityVerdict struct {
+	RequestDetails struct {
+		RequestPackageName string `json:"requestPackageName"`
+		Nonce              string `json:"nonce"`
+		TimestampMillis    string `json:"timestampMillis"`
+	} `json:"requestDetails"`
+	AppIntegrity struct {
+		AppRecognitionVerdict string `json:"appRecognitionVerdict"`
+		PackageName           string `json:"packageName"`
+	} `json:"appIntegrity"`
+	DeviceIntegrity struct {
+		DeviceRecognitionVerdict []string `json:"deviceRecognitionVerdict"`
+	} `json:"deviceIntegrity"`
+}
+
+// Verify decodes a Play Integrity token and checks its verdict against challenge
+func (v *PlayIntegrityVerifier) Verify(ctx context.Context, evidence *Evidence, challenge []byte) (*Result, error) {
+	if evidence.Token == "" {
+		return nil, fmt.Errorf("%w: missing integrity token", ErrInvalidAttestation)
+	}
+	verdict, err := v.decode(ctx, evidence.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	details := verdict.RequestDetails
+	if details.RequestPackageName != v.packageName || verdict.AppIntegrity.PackageName != v.packageName {
+		return nil, fmt.Errorf("%w: token is for another app", ErrInvalidAttestation)
+	}
+	if strings.TrimRight(details.Nonce, "=") != base64.RawURLEncoding.EncodeToString(challenge) {
+		return nil, fmt.Errorf("%w: nonce does not match the challenge", ErrInvalidAttestation)
+	}
+	millis, err := strconv.ParseInt(details.TimestampMillis, 10, 64)
+	if err != nil || time.Since(time.UnixMilli(millis)) > playIntegrityMaxAge {
+		return nil, fmt.Errorf("%w: verdict is stale", ErrInvalidAttestation)
+	}
+	if verdict.AppIntegrity.AppRecognitionVerdict != "PLAY_RECOGNIZED" {
+		return nil, fmt.Errorf("%w: app is %s", ErrInvalidAttestation, verdict.AppIntegrity.AppRecognitionVerdict)
+	}
+	if !slices.Contains(verdict.DeviceIntegrity.DeviceRecognitionVerdict, "MEETS_DEVICE_INTEGRITY") {
+		return nil, fmt.Errorf("%w: device does not meet device integrity", ErrInvalidAttestation)
+	}
+
+	return &Result{
+		Platform: PlatformPlayIntegrity,
+		AppID:    v.packageName,
+	}, nil
+}
+
+// decode exchanges an integrity token for its verdict with the Play Integrity API
+func (v *PlayIntegrityVerifier) decode(ctx context.Context, token string) (*integrityVerdict, error) {
+	accessToken, err := v.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]string{"integrity_token": token})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Play Integrity request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.decodeURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Play Integrity token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: malformed integrity token", ErrInvalidAttestation)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("Play Integrity API returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		TokenPayloadExternal integrityVerdict `json:"tokenPayloadExternal"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Play Integrity verdict: %w", err)
+	}
+	return &result.TokenPayloadExternal, nil
+}
+
+// token returns a cached OAuth access token, exchanging a signed service account
+// assertion for a new one when it is about to expire
+func (v *PlayIntegrityVerifier) token(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.accessToken != "" && time.Now().Add(tokenRefreshGap).Before(v.expiresAt) {
+		return v.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(v.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid Play Integrity private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   v.account.ClientEmail,
+		"scope": playIntegrityScope,
+		"aud":   v.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Play Integrity assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Play Integrity access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Play Integrity token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Play Integrity access token: %w", err)
+	}
+
+	v.accessToken = result.AccessToken
+	v.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return v.accessToken, nil
+}
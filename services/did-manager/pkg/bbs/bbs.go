@@ -0,0 +1,505 @@
+// Package bbs implements BBS signatures over BLS12-381 with selective disclosure
+// proofs, following the BLS12-381-SHA-256 ciphersuite of
+// draft-irtf-cfrg-bbs-signatures. A signer signs an ordered list of messages; the
+// holder of the signature derives proofs that disclose any subset of the messages
+// without revealing the others or the signature itself, so separate proofs cannot
+// be linked to each other through the signature.
+package bbs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/cloudflare/circl/expander"
+)
+
+// Sizes of encoded keys, signatures and proof elements
+const (
+	SecretKeySize = bls12381.ScalarSize
+	PublicKeySize = bls12381.G2SizeCompressed
+	SignatureSize = bls12381.G1SizeCompressed + bls12381.ScalarSize
+	// proofBaseSize is the size of a proof disclosing every message
+	proofBaseSize = 3*bls12381.G1SizeCompressed + 4*bls12381.ScalarSize
+)
+
+// apiID prefixes every domain separation tag of the ciphersuite
+const apiID = "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_H2G_HM2S_"
+
+// expandLen is the number of uniform bytes reduced into a scalar
+const expandLen = 48
+
+var (
+	// ErrInvalidKey is returned for malformed keys and key material
+	ErrInvalidKey = errors.New("bbs: invalid key")
+	// ErrInvalidSignature is returned for signatures that are malformed or do not verify
+	ErrInvalidSignature = errors.New("bbs: invalid signature")
+	// ErrInvalidProof is returned for proofs that are malformed or do not verify
+	ErrInvalidProof = errors.New("bbs: invalid proof")
+)
+
+// SecretKey is a BBS signing key
+type SecretKey struct {
+	x bls12381.Scalar
+}
+
+// PublicKey is a BBS verification key, a point of G2
+type PublicKey struct {
+	w bls12381.G2
+}
+
+// KeyGen derives a secret key from at least 32 bytes of secret key material, with
+// optional info binding the key to an application context
+func KeyGen(ikm, info []byte) (*SecretKey, error) {
+	if len(ikm) < 32 {
+		return nil, fmt.Errorf("%w: key material must be at least 32 bytes", ErrInvalidKey)
+	}
+	if len(info) > 65535 {
+		return nil, fmt.Errorf("%w: key info is too long", ErrInvalidKey)
+	}
+
+	input := make([]byte, 0, len(ikm)+2+len(info))
+	input = append(input, ikm...)
+	input = binary.BigEndian.AppendUint16(input, uint16(len(info)))
+	input = append(input, info...)
+
+	sk := &SecretKey{}
+	sk.x = hashToScalar(input, apiID+"KEYGEN_DST_")
+	if sk.x.IsZero() == 1 {
+		return nil, ErrInvalidKey
+	}
+	return sk, nil
+}
+
+// Bytes encodes the secret key
+func (sk *SecretKey) Bytes() []byte {
+	b, _ := sk.x.MarshalBinary()
+	return b
+}
+
+// PublicKey returns the verification key of sk
+func (sk *SecretKey) PublicKey() *PublicKey {
+	pk := &PublicKey{}
+	pk.w.ScalarMult(&sk.x, bls12381.G2Generator())
+	return pk
+}
+
+// Bytes encodes the public key as a compressed G2 point
+func (pk *PublicKey) Bytes() []byte {
+	return pk.w.BytesCompressed()
+}
+
+// ParsePublicKey decodes a compressed public key
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeySize {
+		return nil, fmt.Errorf("%w: public key must be %d bytes", ErrInvalidKey, PublicKeySize)
+	}
+	pk := &PublicKey{}
+	if err := pk.w.SetBytes(b); err != nil || pk.w.IsIdentity() {
+		return nil, ErrInvalidKey
+	}
+	return pk, nil
+}
+
+// Sign signs messages, in order, together with an optional header that every proof
+// must repeat
+func Sign(sk *SecretKey, header []byte, messages [][]byte) ([]byte, error) {
+	pk := sk.PublicKey()
+	scalars := messageScalars(messages)
+	q1, h := generators(len(messages))
+	domain := calculateDomain(pk, q1, h, header)
+
+	// e is derived deterministically from the key, messages and domain
+	input := sk.Bytes()
+	for i := range scalars {
+		input = appendScalar(input, &scalars[i])
+	}
+	input = appendScalar(input, &domain)
+	e := hashToScalar(input, apiID+"H2S_")
+
+	b := commitment(q1, h, &domain, scalars, nil)
+
+	var denominator bls12381.Scalar
+	denominator.Add(&sk.x, &e)
+	if denominator.IsZero() == 1 {
+		return nil, ErrInvalidKey
+	}
+	denominator.Inv(&denominator)
+
+	var a bls12381.G1
+	a.ScalarMult(&denominator, b)
+
+	signature := a.BytesCompressed()
+	return appendScalar(signature, &e), nil
+}
+
+// Verify checks a signature over messages and header
+func Verify(pk *PublicKey, signature, header []byte, messages [][]byte) error {
+	a, e, err := parseSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	scalars := messageScalars(messages)
+	q1, h := generators(len(messages))
+	domain := calculateDomain(pk, q1, h, header)
+	b := commitment(q1, h, &domain, scalars, nil)
+
+	// e(A, W + e*P2) * e(B, -P2) == 1
+	var we bls12381.G2
+	we.ScalarMult(e, bls12381.G2Generator())
+	we.Add(&we, &pk.w)
+	check := bls12381.ProdPairFrac(
+		[]*bls12381.G1{a, b},
+		[]*bls12381.G2{&we, bls12381.G2Generator()},
+		[]int{1, -1},
+	)
+	if !check.IsIdentity() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// DeriveProof proves knowledge of a signature over messages while disclosing only the
+// messages at the disclosed indexes. The presentation header, e.g. a verifier's
+// nonce, is bound into the proof.
+func DeriveProof(pk *PublicKey, signature, header, presentationHeader []byte, messages [][]byte, disclosed []int) ([]byte, error) {
+	if err := Verify(pk, signature, header, messages); err != nil {
+		return nil, err
+	}
+	a, e, err := parseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := normalizeIndexes(disclosed, len(messages))
+	if err != nil {
+		return nil, err
+	}
+	revealed := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		revealed[i] = true
+	}
+	var undisclosed []int
+	for i := range messages {
+		if !revealed[i] {
+			undisclosed = append(undisclosed, i)
+		}
+	}
+
+	scalars := messageScalars(messages)
+	q1, h := generators(len(messages))
+	domain := calculateDomain(pk, q1, h, header)
+
+	random, err := randomScalars(5 + len(undisclosed))
+	if err != nil {
+		return nil, err
+	}
+	r1, r2, eTilde, r1Tilde, r3Tilde := &random[0], &random[1], &random[2], &random[3], &random[4]
+	mTilde := random[5:]
+
+	b := commitment(q1, h, &domain, scalars, nil)
+
+	// D = B * r2, Abar = A * (r1 * r2), Bbar = D * r1 - Abar * e
+	var d, aBar, bBar, tmp bls12381.G1
+	d.ScalarMult(r2, b)
+	var r1r2 bls12381.Scalar
+	r1r2.Mul(r1, r2)
+	aBar.ScalarMult(&r1r2, a)
+	bBar.ScalarMult(r1, &d)
+	tmp.ScalarMult(e, &aBar)
+	tmp.Neg()
+	bBar.Add(&bBar, &tmp)
+
+	// T1 = Abar * e~ + D * r1~, T2 = D * r3~ + sum(H_j * m~_j)
+	var t1, t2 bls12381.G1
+	t1.ScalarMult(eTilde, &aBar)
+	tmp.ScalarMult(r1Tilde, &d)
+	t1.Add(&t1, &tmp)
+	t2.ScalarMult(r3Tilde, &d)
+	for k, j := range undisclosed {
+		tmp.ScalarMult(&mTilde[k], &h[j])
+		t2.Add(&t2, &tmp)
+	}
+
+	challenge := proofChallenge(&aBar, &bBar, &d, &t1, &t2, indexes, scalars, &domain, presentationHeader)
+
+	// e^ = e~ + e * c, r1^ = r1~ - r1 * c, r3^ = r3~ - r2^-1 * c, m^_j = m~_j + m_j * c
+	var eHat, r1Hat, r3Hat, r3, product bls12381.Scalar
+	product.Mul(e, &challenge)
+	eHat.Add(eTilde, &product)
+	product.Mul(r1, &challenge)
+	r1Hat.Sub(r1Tilde, &product)
+	r3.Inv(r2)
+	product.Mul(&r3, &challenge)
+	r3Hat.Sub(r3Tilde, &product)
+
+	proof := make([]byte, 0, proofBaseSize+len(undisclosed)*bls12381.ScalarSize)
+	proof = append(proof, aBar.BytesCompressed()...)
+	proof = append(proof, bBar.BytesCompressed()...)
+	proof = append(proof, d.BytesCompressed()...)
+	proof = appendScalar(proof, &eHat)
+	proof = appendScalar(proof, &r1Hat)
+	proof = appendScalar(proof, &r3Hat)
+	for k, j := range undisclosed {
+		var mHat bls12381.Scalar
+		product.Mul(&scalars[j], &challenge)
+		mHat.Add(&mTilde[k], &product)
+		proof = appendScalar(proof, &mHat)
+	}
+	return appendScalar(proof, &challenge), nil
+}
+
+// MessageCount returns the number of messages signed by the signature a proof was
+// derived from
+func MessageCount(proof []byte) (int, error) {
+	extra := len(proof) - proofBaseSize
+	if extra < 0 || extra%bls12381.ScalarSize != 0 {
+		return 0, fmt.Errorf("%w: unexpected proof length", ErrInvalidProof)
+	}
+	return extra / bls12381.ScalarSize, nil
+}
+
+// VerifyProof checks a proof against the disclosed messages, keyed by their index in
+// the signed list, and the header and presentation header it was derived with
+func VerifyProof(pk *PublicKey, proof, header, presentationHeader []byte, disclosed map[int][]byte) error {
+	undisclosedCount, err := MessageCount(proof)
+	if err != nil {
+		return err
+	}
+	total := undisclosedCount + len(disclosed)
+
+	indexes := make([]int, 0, len(disclosed))
+	for i := range disclosed {
+		indexes = append(indexes, i)
+	}
+	if indexes, err = normalizeIndexes(indexes, total); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	var points [3]bls12381.G1
+	offset := 0
+	for i := range points {
+		if err := points[i].SetBytes(proof[offset : offset+bls12381.G1SizeCompressed]); err != nil {
+			return fmt.Errorf("%w: malformed point", ErrInvalidProof)
+		}
+		offset += bls12381.G1SizeCompressed
+	}
+	aBar, bBar, d := &points[0], &points[1], &points[2]
+	if aBar.IsIdentity() || d.IsIdentity() {
+		return ErrInvalidProof
+	}
+
+	responses := make([]bls12381.Scalar, 4+undisclosedCount)
+	for i := range responses {
+		if err := responses[i].UnmarshalBinary(proof[offset : offset+bls12381.ScalarSize]); err != nil {
+			return fmt.Errorf("%w: malformed scalar", ErrInvalidProof)
+		}
+		offset += bls12381.ScalarSize
+	}
+	eHat, r1Hat, r3Hat := &responses[0], &responses[1], &responses[2]
+	mHat := responses[3 : 3+undisclosedCount]
+	challenge := &responses[len(responses)-1]
+
+	q1, h := generators(total)
+	domain := calculateDomain(pk, q1, h, header)
+
+	scalars := make([]bls12381.Scalar, total)
+	revealed := make(map[int]bool, len(disclosed))
+	for i, message := range disclosed {
+		scalars[i] = mapMessage(message)
+		revealed[i] = true
+	}
+
+	// T1 = Bbar * c + Abar * e^ + D * r1^
+	var t1, t2, tmp bls12381.G1
+	t1.ScalarMult(challenge, bBar)
+	tmp.ScalarMult(eHat, aBar)
+	t1.Add(&t1, &tmp)
+	tmp.ScalarMult(r1Hat, d)
+	t1.Add(&t1, &tmp)
+
+	// T2 = Bv * c + D * r3^ + sum(H_j * m^_j), Bv committing to the disclosed messages
+	bv := commitment(q1, h, &domain, scalars, revealed)
+	t2.ScalarMult(challenge, bv)
+	tmp.ScalarMult(r3Hat, d)
+	t2.Add(&t2, &tmp)
+	k := 0
+	for j := 0; j < total; j++ {
+		if revealed[j] {
+			continue
+		}
+		tmp.ScalarMult(&mHat[k], &h[j])
+		t2.Add(&t2, &tmp)
+		k++
+	}
+
+	expected := proofChallenge(aBar, bBar, d, &t1, &t2, indexes, scalars, &domain, presentationHeader)
+	if expected.IsEqual(challenge) != 1 {
+		return ErrInvalidProof
+	}
+
+	// e(Abar, W) * e(Bbar, -P2) == 1
+	check := bls12381.ProdPairFrac(
+		[]*bls12381.G1{aBar, bBar},
+		[]*bls12381.G2{&pk.w, bls12381.G2Generator()},
+		[]int{1, -1},
+	)
+	if !check.IsIdentity() {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// parseSignature decodes a signature into A and e
+func parseSignature(signature []byte) (*bls12381.G1, *bls12381.Scalar, error) {
+	if len(signature) != SignatureSize {
+		return nil, nil, fmt.Errorf("%w: signature must be %d bytes", ErrInvalidSignature, SignatureSize)
+	}
+	a := &bls12381.G1{}
+	if err := a.SetBytes(signature[:bls12381.G1SizeCompressed]); err != nil || a.IsIdentity() {
+		return nil, nil, ErrInvalidSignature
+	}
+	e := &bls12381.Scalar{}
+	if err := e.UnmarshalBinary(signature[bls12381.G1SizeCompressed:]); err != nil {
+		return nil, nil, ErrInvalidSignature
+	}
+	return a, e, nil
+}
+
+// commitment computes P1 + Q1 * domain + sum(H_i * m_i) over the messages whose index
+// is in include, or over all messages when include is nil
+func commitment(q1 *bls12381.G1, h []bls12381.G1, domain *bls12381.Scalar, scalars []bls12381.Scalar, include map[int]bool) *bls12381.G1 {
+	b := basePoint()
+	var tmp bls12381.G1
+	tmp.ScalarMult(domain, q1)
+	b.Add(b, &tmp)
+	for i := range scalars {
+		if include != nil && !include[i] {
+			continue
+		}
+		tmp.ScalarMult(&scalars[i], &h[i])
+		b.Add(b, &tmp)
+	}
+	return b
+}
+
+// calculateDomain binds the public key, generators and header into a scalar
+func calculateDomain(pk *PublicKey, q1 *bls12381.G1, h []bls12381.G1, header []byte) bls12381.Scalar {
+	input := pk.Bytes()
+	input = binary.BigEndian.AppendUint64(input, uint64(len(h)))
+	input = append(input, q1.BytesCompressed()...)
+	for i := range h {
+		input = append(input, h[i].BytesCompressed()...)
+	}
+	input = append(input, apiID...)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(header)))
+	input = append(input, header...)
+	return hashToScalar(input, apiID+"H2S_")
+}
+
+// proofChallenge computes the Fiat-Shamir challenge of a proof
+func proofChallenge(aBar, bBar, d, t1, t2 *bls12381.G1, disclosed []int, scalars []bls12381.Scalar, domain *bls12381.Scalar, presentationHeader []byte) bls12381.Scalar {
+	input := binary.BigEndian.AppendUint64(nil, uint64(len(disclosed)))
+	for _, i := range disclosed {
+		input = binary.BigEndian.AppendUint64(input, uint64(i))
+		input = appendScalar(input, &scalars[i])
+	}
+	for _, point := range []*bls12381.G1{aBar, bBar, d, t1, t2} {
+		input = append(input, point.BytesCompressed()...)
+	}
+	input = appendScalar(input, domain)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(presentationHeader)))
+	input = append(input, presentationHeader...)
+	return hashToScalar(input, apiID+"H2S_")
+}
+
+// normalizeIndexes sorts message indexes, rejecting duplicates and indexes outside
+// a list of count messages
+func normalizeIndexes(indexes []int, count int) ([]int, error) {
+	sorted := append([]int(nil), indexes...)
+	sort.Ints(sorted)
+	for k, i := range sorted {
+		if i < 0 || i >= count {
+			return nil, fmt.Errorf("message index %d is out of range", i)
+		}
+		if k > 0 && sorted[k-1] == i {
+			return nil, fmt.Errorf("message index %d is repeated", i)
+		}
+	}
+	return sorted, nil
+}
+
+// generators returns Q1 and one generator per message
+func generators(count int) (*bls12381.G1, []bls12381.G1) {
+	points := createGenerators(count+1, apiID+"MESSAGE_GENERATOR_SEED")
+	return &points[0], points[1:]
+}
+
+// basePoint returns the fixed point P1 every signature commits to
+func basePoint() *bls12381.G1 {
+	return &createGenerators(1, apiID+"BP_MESSAGE_GENERATOR_SEED")[0]
+}
+
+// createGenerators derives count independent points of G1 from a seed
+func createGenerators(count int, seed string) []bls12381.G1 {
+	seedDST := []byte(apiID + "SIG_GENERATOR_SEED_")
+	generatorDST := []byte(apiID + "SIG_GENERATOR_DST_")
+
+	v := expand([]byte(seed), seedDST)
+	points := make([]bls12381.G1, count)
+	for i := range points {
+		v = expand(binary.BigEndian.AppendUint64(v, uint64(i+1)), seedDST)
+		points[i].Hash(v, generatorDST)
+	}
+	return points
+}
+
+// messageScalars maps messages to scalars
+func messageScalars(messages [][]byte) []bls12381.Scalar {
+	scalars := make([]bls12381.Scalar, len(messages))
+	for i, message := range messages {
+		scalars[i] = mapMessage(message)
+	}
+	return scalars
+}
+
+// mapMessage maps a message to a scalar
+func mapMessage(message []byte) bls12381.Scalar {
+	return hashToScalar(message, apiID+"MAP_MSG_TO_SCALAR_AS_HASH_")
+}
+
+// hashToScalar hashes input to a scalar under a domain separation tag
+func hashToScalar(input []byte, dst string) bls12381.Scalar {
+	var s bls12381.Scalar
+	s.SetBytes(expand(input, []byte(dst)))
+	return s
+}
+
+// expand expands input to expandLen uniform bytes with expand_message_xmd
+func expand(input, dst []byte) []byte {
+	return expander.NewExpanderMD(crypto.SHA256, dst).Expand(input, expandLen)
+}
+
+// randomScalars draws count uniformly random non-zero scalars
+func randomScalars(count int) ([]bls12381.Scalar, error) {
+	scalars := make([]bls12381.Scalar, count)
+	for i := range scalars {
+		for scalars[i].IsZero() == 1 {
+			if err := scalars[i].Random(rand.Reader); err != nil {
+				return nil, fmt.Errorf("bbs: failed to draw randomness: %w", err)
+			}
+		}
+	}
+	return scalars, nil
+}
+
+// appendScalar appends the 32-byte big-endian encoding of s
+func appendScalar(b []byte, s *bls12381.Scalar) []byte {
+	encoded, _ := s.MarshalBinary()
+	return append(b, encoded...)
+}
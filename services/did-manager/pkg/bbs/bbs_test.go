@@ -0,0 +1,153 @@
+package bbs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKey(t *testing.T) (*SecretKey, *PublicKey) {
+	t.Helper()
+	sk, err := KeyGen(bytes.Repeat([]byte{7}, 32), []byte("test"))
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return sk, sk.PublicKey()
+}
+
+func testMessages() [][]byte {
+	return [][]byte{
+		[]byte(`type="AgeOverCredential"`),
+		[]byte(`birthdate="1990-04-01"`),
+		[]byte(`age_over_18=true`),
+		[]byte(`age_over_21=true`),
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	sk, pk := testKey(t)
+	header := []byte("header")
+	messages := testMessages()
+
+	signature, err := Sign(sk, header, messages)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if len(signature) != SignatureSize {
+		t.Fatalf("expected %d byte signature, got %d", SignatureSize, len(signature))
+	}
+	if err := Verify(pk, signature, header, messages); err != nil {
+		t.Fatalf("expected signature to verify: %v", err)
+	}
+
+	messages[1] = []byte(`birthdate="2015-04-01"`)
+	if err := Verify(pk, signature, header, messages); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected tampered message to fail, got %v", err)
+	}
+	if err := Verify(pk, signature, []byte("other"), testMessages()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected different header to fail, got %v", err)
+	}
+}
+
+func TestPublicKeyRoundTrip(t *testing.T) {
+	_, pk := testKey(t)
+
+	parsed, err := ParsePublicKey(pk.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse public key: %v", err)
+	}
+	if !bytes.Equal(parsed.Bytes(), pk.Bytes()) {
+		t.Error("expected parsed key to match")
+	}
+	if _, err := ParsePublicKey(pk.Bytes()[1:]); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected truncated key to fail, got %v", err)
+	}
+}
+
+func TestSelectiveDisclosureProof(t *testing.T) {
+	sk, pk := testKey(t)
+	header := []byte("header")
+	messages := testMessages()
+
+	signature, err := Sign(sk, header, messages)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	nonce := []byte("nonce-1")
+	proof, err := DeriveProof(pk, signature, header, nonce, messages, []int{2, 0})
+	if err != nil {
+		t.Fatalf("failed to derive proof: %v", err)
+	}
+	if count, err := MessageCount(proof); err != nil || count != 2 {
+		t.Fatalf("expected 2 undisclosed messages, got %d (%v)", count, err)
+	}
+
+	disclosed := map[int][]byte{0: messages[0], 2: messages[2]}
+	if err := VerifyProof(pk, proof, header, nonce, disclosed); err != nil {
+		t.Fatalf("expected proof to verify: %v", err)
+	}
+
+	if err := VerifyProof(pk, proof, header, []byte("nonce-2"), disclosed); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected replay under another nonce to fail, got %v", err)
+	}
+
+	forged := map[int][]byte{0: messages[0], 2: []byte(`age_over_18=false`)}
+	if err := VerifyProof(pk, proof, header, nonce, forged); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected altered disclosed message to fail, got %v", err)
+	}
+
+	moved := map[int][]byte{0: messages[0], 3: messages[2]}
+	if err := VerifyProof(pk, proof, header, nonce, moved); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected message at another index to fail, got %v", err)
+	}
+
+	other, err := KeyGen(bytes.Repeat([]byte{8}, 32), nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if err := VerifyProof(other.PublicKey(), proof, header, nonce, disclosed); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected proof under another key to fail, got %v", err)
+	}
+}
+
+func TestProofsAreUnlinkable(t *testing.T) {
+	sk, pk := testKey(t)
+	messages := testMessages()
+
+	signature, err := Sign(sk, nil, messages)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	first, err := DeriveProof(pk, signature, nil, nil, messages, []int{2})
+	if err != nil {
+		t.Fatalf("failed to derive proof: %v", err)
+	}
+	second, err := DeriveProof(pk, signature, nil, nil, messages, []int{2})
+	if err != nil {
+		t.Fatalf("failed to derive proof: %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("expected proofs to be randomized")
+	}
+	if bytes.Contains(first, signature[:48]) {
+		t.Error("expected proof not to contain the signature")
+	}
+}
+
+func TestDeriveProofRejectsBadIndexes(t *testing.T) {
+	sk, pk := testKey(t)
+	messages := testMessages()
+
+	signature, err := Sign(sk, nil, messages)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	for _, disclosed := range [][]int{{4}, {-1}, {1, 1}} {
+		if _, err := DeriveProof(pk, signature, nil, nil, messages, disclosed); err == nil {
+			t.Errorf("expected indexes %v to be rejected", disclosed)
+		}
+	}
+}
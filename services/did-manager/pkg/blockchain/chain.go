@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OperationType is the kind of an anchored operation. The values match the job types
+// of the DID Manager's blockchain jobs.
+type OperationType string
+
+const (
+	OperationRegisterDID OperationType = "register_did"
+	OperationUpdateDID   OperationType = "update_did"
+	OperationRevokeDID   OperationType = "revoke_did"
+	OperationNotarize    OperationType = "notarize"
+)
+
+// Operation is a DID operation or document notarization to anchor on a chain
+type Operation struct {
+	Type     OperationType
+	DID      string
+	UserHash string
+	// DocumentHash is the hash a notarization anchors
+	DocumentHash string
+}
+
+// Chain anchors operations on one chain. Each backend builds, signs and submits its
+// chain's transactions itself, so the job processor works with any of them:
+// *EthereumClient calls the DID registry contract and *HederaClient submits messages
+// to a Hedera Consensus Service topic.
+type Chain interface {
+	// Submit anchors an operation and waits until the chain accepted it, returning the
+	// ID of its transaction
+	Submit(ctx context.Context, op *Operation) (string, error)
+	// Simulate checks what submitting an operation would do without submitting it
+	Simulate(op *Operation) (*Simulation, error)
+	// Verify checks a DID against the chain. txID is the transaction that last
+	// anchored the DID, for chains that look DIDs up by their transactions.
+	Verify(did, txID string) (*ChainCheck, error)
+	// Confirmations returns how many blocks up to head confirm a transaction; ok is
+	// false when it is not final yet
+	Confirmations(txID string, head uint64) (confirmations uint64, ok bool, err error)
+	// Anchoring describes a transaction that anchored operations; nil when the chain
+	// has no successful transaction of that ID
+	Anchoring(txID string) (*Anchoring, error)
+}
+
+// Anchoring describes the transaction that anchored an operation, in terms every
+// backend can fill in
+type Anchoring struct {
+	// Chain is the chain family, e.g. "ethereum" or "hedera", and Network the chain
+	// within it: the chain ID of an EVM chain or the name of a Hedera network
+	Chain   string
+	Network string
+	// Anchor is the contract or topic the transaction wrote to
+	Anchor        string
+	TransactionID string
+	// BlockNumber is zero on chains without blocks
+	BlockNumber uint64
+	Timestamp   time.Time
+	// Confirmations counts the blocks confirming the transaction; one on chains with
+	// immediate finality
+	Confirmations uint64
+}
+
+// unknownOperation is the error for an operation type a backend does not anchor
+func unknownOperation(op *Operation) error {
+	return fmt.Errorf("unknown operation type: %s", op.Type)
+}
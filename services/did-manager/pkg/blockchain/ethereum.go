@@ -3,6 +3,7 @@ package blockchain
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -17,15 +18,26 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// ErrReadOnly is returned for transactions sent through a client without a signing key
+var ErrReadOnly = errors.New("blockchain client is read-only")
+
 // EthereumClient handles interactions with Ethereum blockchain
 type EthereumClient struct {
 	client     *ethclient.Client
 	privateKey *ecdsa.PrivateKey
 	address    common.Address
 	contract   common.Address
-	chainID    *big.Int
-	gasLimit   uint64
-	gasPrice   *big.Int
+	// revocationRegistry is the RevocationRegistry contract; zero when not configured
+	revocationRegistry common.Address
+	// sidetreeAnchor is the SidetreeAnchor contract; zero when not configured
+	sidetreeAnchor common.Address
+	chainID        *big.Int
+	gasLimit       uint64
+	// fees prices transactions; nil for a read-only client
+	fees *FeeOracle
+	// trusted are the contracts pinned by a verified trust configuration; nil when
+	// none was verified
+	trusted map[common.Address]common.Hash
 }
 
 // NewEthereumClient creates a new Ethereum client
@@ -60,10 +72,10 @@ func NewEthereumClient(rpcURL, privateKeyHex, contractAddress string) (*Ethereum
 	// Parse contract address
 	contract := common.HexToAddress(contractAddress)
 
-	// Get gas price
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	// Get gas price; Fees returns the oracle for periodic refreshes
+	fees := NewFeeOracle(client)
+	if err := fees.Refresh(context.Background()); err != nil {
+		return nil, err
 	}
 
 	return &EthereumClient{
@@ -73,18 +85,78 @@ func NewEthereumClient(rpcURL, privateKeyHex, contractAddress string) (*Ethereum
 		contract:   contract,
 		chainID:    chainID,
 		gasLimit:   300000, // Adjust based on contract complexity
-		gasPrice:   gasPrice,
+		fees:       fees,
+	}, nil
+}
+
+// NewReadOnlyClient creates a client for contract reads only, without a signing key.
+// Transactions sent through it fail with ErrReadOnly.
+func NewReadOnlyClient(rpcURL, contractAddress string) (*EthereumClient, error) {
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
+	}
+
+	chainID, err := client.NetworkID(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	return &EthereumClient{
+		client:   client,
+		contract: common.HexToAddress(contractAddress),
+		chainID:  chainID,
+		gasLimit: 300000,
 	}, nil
 }
 
+// Submit anchors an operation in the DID registry contract, returning the transaction
+// hash once it is mined
+func (e *EthereumClient) Submit(ctx context.Context, op *Operation) (string, error) {
+	data, err := packOperation(op)
+	if err != nil {
+		return "", err
+	}
+
+	// Create transaction
+	tx, err := e.sendTransaction(ctx, e.contract, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return tx.Hash().Hex(), nil
+}
+
+// packOperation encodes the registry call carrying an operation
+func packOperation(op *Operation) ([]byte, error) {
+	switch op.Type {
+	case OperationRegisterDID:
+		return packRegisterDID(op.UserHash, op.DID)
+	case OperationUpdateDID:
+		return packUpdateDID(op.UserHash, op.DID)
+	case OperationRevokeDID:
+		return packRevokeDID(op.UserHash)
+	case OperationNotarize:
+		return packNotarizeDocument(op.DocumentHash, op.DID)
+	default:
+		return nil, unknownOperation(op)
+	}
+}
+
 // RegisterDID registers a DID on the blockchain
-func (e *EthereumClient) RegisterDID(userHash, did string) (string, error) {
+func (e *EthereumClient) RegisterDID(ctx context.Context, userHash, did string) (string, error) {
+	return e.Submit(ctx, &Operation{Type: OperationRegisterDID, DID: did, UserHash: userHash})
+}
+
+// packRegisterDID encodes the registerDID call
+func packRegisterDID(userHash, did string) ([]byte, error) {
 	// DID Registry ABI (simplified)
 	didRegistryABI := `[
 		{
 			"inputs": [
 				{"name": "userHash", "type": "bytes32"},
-				{"name": "did", "type": "string"}
+				{"name": "did", "type": "string"},
+				{"name": "metadata", "type": "string"}
 			],
 			"name": "registerDID",
 			"outputs": [],
@@ -95,32 +167,32 @@ func (e *EthereumClient) RegisterDID(userHash, did string) (string, error) {
 
 	parsedABI, err := abi.JSON(strings.NewReader(didRegistryABI))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
-	// Encode function call
-	data, err := parsedABI.Pack("registerDID", common.HexToHash(userHash), did)
+	// Encode function call; the registry's metadata is unused and left empty
+	data, err := parsedABI.Pack("registerDID", common.HexToHash(userHash), did, "")
 	if err != nil {
-		return "", fmt.Errorf("failed to pack function call: %w", err)
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
 	}
 
-	// Create transaction
-	tx, err := e.sendTransaction(data)
-	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
-	}
-
-	return tx.Hash().Hex(), nil
+	return data, nil
 }
 
 // UpdateDID updates a DID on the blockchain
-func (e *EthereumClient) UpdateDID(userHash, did string) (string, error) {
+func (e *EthereumClient) UpdateDID(ctx context.Context, userHash, did string) (string, error) {
+	return e.Submit(ctx, &Operation{Type: OperationUpdateDID, DID: did, UserHash: userHash})
+}
+
+// packUpdateDID encodes the updateDID call
+func packUpdateDID(userHash, did string) ([]byte, error) {
 	// DID Registry ABI for update
 	didRegistryABI := `[
 		{
 			"inputs": [
 				{"name": "userHash", "type": "bytes32"},
-				{"name": "did", "type": "string"}
+				{"name": "did", "type": "string"},
+				{"name": "metadata", "type": "string"}
 			],
 			"name": "updateDID",
 			"outputs": [],
@@ -131,26 +203,68 @@ func (e *EthereumClient) UpdateDID(userHash, did string) (string, error) {
 
 	parsedABI, err := abi.JSON(strings.NewReader(didRegistryABI))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
-	// Encode function call
-	data, err := parsedABI.Pack("updateDID", common.HexToHash(userHash), did)
+	// Encode function call; the registry's metadata is unused and left empty
+	data, err := parsedABI.Pack("updateDID", common.HexToHash(userHash), did, "")
 	if err != nil {
-		return "", fmt.Errorf("failed to pack function call: %w", err)
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
 	}
 
-	// Create transaction
-	tx, err := e.sendTransaction(data)
+	return data, nil
+}
+
+// RevokeDID revokes a DID on the blockchain
+func (e *EthereumClient) RevokeDID(ctx context.Context, userHash string) (string, error) {
+	return e.Submit(ctx, &Operation{Type: OperationRevokeDID, UserHash: userHash})
+}
+
+// packRevokeDID encodes the revokeDID call
+func packRevokeDID(userHash string) ([]byte, error) {
+	// DID Registry ABI for revoke
+	didRegistryABI := `[
+		{
+			"inputs": [
+				{"name": "userHash", "type": "bytes32"}
+			],
+			"name": "revokeDID",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`
+
+	parsedABI, err := abi.JSON(strings.NewReader(didRegistryABI))
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
-	return tx.Hash().Hex(), nil
+	// Encode function call
+	data, err := parsedABI.Pack("revokeDID", common.HexToHash(userHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	return data, nil
+}
+
+// ChainCheck is the outcome of a DID check against the registry contract, with the
+// block it was read at
+type ChainCheck struct {
+	Valid          bool
+	BlockNumber    uint64
+	BlockTimestamp time.Time
 }
 
-// VerifyDID verifies a DID on the blockchain
-func (e *EthereumClient) VerifyDID(did string) (bool, error) {
+// Verify checks a DID in the registry contract at the latest block; the registry holds
+// the state of every DID, so txID is not needed
+func (e *EthereumClient) Verify(did, txID string) (*ChainCheck, error) {
+	return e.VerifyDID(did)
+}
+
+// VerifyDID verifies a DID on the blockchain at the latest block
+func (e *EthereumClient) VerifyDID(did string) (*ChainCheck, error) {
 	// DID Registry ABI for verification
 	didRegistryABI := `[
 		{
@@ -166,38 +280,185 @@ func (e *EthereumClient) VerifyDID(did string) (bool, error) {
 
 	parsedABI, err := abi.JSON(strings.NewReader(didRegistryABI))
 	if err != nil {
-		return false, fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
 	// Encode function call
 	data, err := parsedABI.Pack("verifyDID", did)
 	if err != nil {
-		return false, fmt.Errorf("failed to pack function call: %w", err)
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	// Pin the call to a block so the result can be attributed to it
+	head, err := e.client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
 	}
 
 	// Call contract (read-only)
 	result, err := e.client.CallContract(context.Background(), ethereum.CallMsg{
 		To:   &e.contract,
 		Data: data,
-	}, nil)
+	}, head.Number)
 	if err != nil {
-		return false, fmt.Errorf("failed to call contract: %w", err)
+		return nil, fmt.Errorf("failed to call contract: %w", err)
 	}
 
 	// Decode result
 	var isValid bool
 	err = parsedABI.UnpackIntoInterface(&isValid, "verifyDID", result)
 	if err != nil {
-		return false, fmt.Errorf("failed to unpack result: %w", err)
+		return nil, fmt.Errorf("failed to unpack result: %w", err)
+	}
+
+	return &ChainCheck{
+		Valid:          isValid,
+		BlockNumber:    head.Number.Uint64(),
+		BlockTimestamp: time.Unix(int64(head.Time), 0).UTC(),
+	}, nil
+}
+
+// Confirmations returns how many blocks up to head confirm a transaction, counting the
+// block it was mined in; ok is false when the transaction is not mined
+func (e *EthereumClient) Confirmations(txHash string, head uint64) (confirmations uint64, ok bool, err error) {
+	receipt, err := e.client.TransactionReceipt(context.Background(), common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusFailed || receipt.BlockNumber == nil {
+		return 0, false, nil
+	}
+
+	mined := receipt.BlockNumber.Uint64()
+	if mined > head {
+		return 0, false, nil
+	}
+	return head - mined + 1, true, nil
+}
+
+// Anchoring describes a mined, successful transaction: the contract it called and the
+// block it was mined in
+func (e *EthereumClient) Anchoring(txID string) (*Anchoring, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hash := common.HexToHash(txID)
+	receipt, err := e.client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusFailed || receipt.BlockNumber == nil {
+		return nil, nil
+	}
+
+	tx, _, err := e.client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	block, err := e.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	head, err := e.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	anchoring := &Anchoring{
+		Chain:         "ethereum",
+		Network:       e.chainID.String(),
+		TransactionID: tx.Hash().Hex(),
+		BlockNumber:   receipt.BlockNumber.Uint64(),
+		Timestamp:     time.Unix(int64(block.Time), 0).UTC(),
+	}
+	if tx.To() != nil {
+		anchoring.Anchor = tx.To().Hex()
+	}
+	if head >= anchoring.BlockNumber {
+		anchoring.Confirmations = head - anchoring.BlockNumber + 1
+	}
+	return anchoring, nil
+}
+
+// NotarizeDocument anchors a document hash notarized by a DID
+func (e *EthereumClient) NotarizeDocument(ctx context.Context, documentHash, did string) (string, error) {
+	return e.Submit(ctx, &Operation{Type: OperationNotarize, DID: did, DocumentHash: documentHash})
+}
+
+// packNotarizeDocument encodes the notarize call
+func packNotarizeDocument(documentHash, did string) ([]byte, error) {
+	// DID Registry ABI for notarization
+	didRegistryABI := `[
+		{
+			"inputs": [
+				{"name": "documentHash", "type": "bytes32"},
+				{"name": "did", "type": "string"}
+			],
+			"name": "notarize",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`
+
+	parsedABI, err := abi.JSON(strings.NewReader(didRegistryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
 
-	return isValid, nil
+	// Encode function call
+	data, err := parsedABI.Pack("notarize", common.HexToHash(documentHash), did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	return data, nil
 }
 
-// sendTransaction sends a transaction to the blockchain
-func (e *EthereumClient) sendTransaction(data []byte) (*types.Transaction, error) {
+// ContractAddress returns the address of the DID Registry contract
+func (e *EthereumClient) ContractAddress() string {
+	return e.contract.Hex()
+}
+
+// ChainID returns the chain ID transactions are signed for
+func (e *EthereumClient) ChainID() *big.Int {
+	return new(big.Int).Set(e.chainID)
+}
+
+// Fees returns the fee oracle transactions are priced with; nil for a read-only client
+func (e *EthereumClient) Fees() *FeeOracle {
+	return e.fees
+}
+
+// Node returns the underlying node connection, for contract calls outside the registry
+func (e *EthereumClient) Node() *ethclient.Client {
+	return e.client
+}
+
+// sendTransaction sends a transaction calling the contract at to and waits for it to be
+// mined, reporting it to the submit observer of ctx once sent
+func (e *EthereumClient) sendTransaction(ctx context.Context, to common.Address, data []byte) (*types.Transaction, error) {
+	if e.privateKey == nil {
+		return nil, ErrReadOnly
+	}
+	if err := e.checkTrusted(to); err != nil {
+		return nil, err
+	}
+
+	// Price the transaction at the current gas price
+	gasPrice := e.fees.GasPrice()
+	if gasPrice == nil {
+		return nil, fmt.Errorf("no gas price available")
+	}
+
 	// Get nonce
-	nonce, err := e.client.PendingNonceAt(context.Background(), e.address)
+	nonce, err := e.client.PendingNonceAt(ctx, e.address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nonce: %w", err)
 	}
@@ -205,10 +466,10 @@ func (e *EthereumClient) sendTransaction(data []byte) (*types.Transaction, error
 	// Create transaction
 	tx := types.NewTransaction(
 		nonce,
-		e.contract,
+		to,
 		big.NewInt(0), // No ETH transfer
 		e.gasLimit,
-		e.gasPrice,
+		gasPrice,
 		data,
 	)
 
@@ -219,13 +480,14 @@ func (e *EthereumClient) sendTransaction(data []byte) (*types.Transaction, error
 	}
 
 	// Send transaction
-	err = e.client.SendTransaction(context.Background(), signedTx)
+	err = e.client.SendTransaction(ctx, signedTx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send transaction: %w", err)
 	}
+	notifySubmitted(ctx, signedTx.Hash().Hex())
 
 	// Wait for transaction to be mined
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	// Poll for transaction receipt
@@ -0,0 +1,141 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrInvalidGasPrice is returned for a gas price override or maximum that is not
+// positive
+var ErrInvalidGasPrice = errors.New("gas price must be positive")
+
+// GasPriceSuggester suggests a legacy gas price; *ethclient.Client implements it
+type GasPriceSuggester interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// FeeStatus is a snapshot of a fee oracle. Prices are in wei; nil when unset.
+type FeeStatus struct {
+	// GasPrice is the price the next transaction is sent with
+	GasPrice  *big.Int
+	Suggested *big.Int
+	Override  *big.Int
+	Max       *big.Int
+	// UpdatedAt is when the suggestion was last refreshed
+	UpdatedAt time.Time
+}
+
+// FeeOracle holds the gas price transactions are sent with. The node's suggestion is
+// refreshed periodically; an operator override takes its place, and both are capped at
+// a maximum price. It is safe for concurrent use.
+type FeeOracle struct {
+	source GasPriceSuggester
+
+	mu        sync.RWMutex
+	suggested *big.Int
+	override  *big.Int
+	max       *big.Int
+	updatedAt time.Time
+}
+
+// NewFeeOracle creates a fee oracle for the suggestions of source. Refresh it before
+// the first transaction.
+func NewFeeOracle(source GasPriceSuggester) *FeeOracle {
+	return &FeeOracle{source: source}
+}
+
+// Refresh asks the node for its current gas price suggestion. On failure the previous
+// suggestion is kept.
+func (o *FeeOracle) Refresh(ctx context.Context) error {
+	price, err := o.source.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get gas price: %w", err)
+	}
+	if price == nil || price.Sign() <= 0 {
+		return fmt.Errorf("node suggested an invalid gas price %v", price)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.suggested = new(big.Int).Set(price)
+	o.updatedAt = time.Now()
+	return nil
+}
+
+// GasPrice returns the price to send a transaction with: the override when set, the
+// last suggestion otherwise, at most the maximum price. It is nil before the first
+// successful refresh without an override.
+func (o *FeeOracle) GasPrice() *big.Int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	price, capped := o.gasPrice()
+	if capped {
+		log.Printf("Gas price exceeds the maximum, sending with %s wei", price)
+	}
+	return price
+}
+
+// gasPrice returns the price to send with and whether it was capped; o.mu is held
+func (o *FeeOracle) gasPrice() (*big.Int, bool) {
+	price := o.suggested
+	if o.override != nil {
+		price = o.override
+	}
+	if price == nil {
+		return nil, false
+	}
+	if o.max != nil && price.Cmp(o.max) > 0 {
+		return new(big.Int).Set(o.max), true
+	}
+	return new(big.Int).Set(price), false
+}
+
+// SetOverride pins the gas price, ignoring suggestions until cleared with nil
+func (o *FeeOracle) SetOverride(price *big.Int) error {
+	if price != nil && price.Sign() <= 0 {
+		return fmt.Errorf("%w: override %s", ErrInvalidGasPrice, price)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.override = copyPrice(price)
+	return nil
+}
+
+// SetMaxGasPrice caps the gas price; nil removes the cap
+func (o *FeeOracle) SetMaxGasPrice(price *big.Int) error {
+	if price != nil && price.Sign() <= 0 {
+		return fmt.Errorf("%w: maximum %s", ErrInvalidGasPrice, price)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.max = copyPrice(price)
+	return nil
+}
+
+// Status returns a snapshot of the oracle
+func (o *FeeOracle) Status() FeeStatus {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	price, _ := o.gasPrice()
+	return FeeStatus{
+		GasPrice:  price,
+		Suggested: copyPrice(o.suggested),
+		Override:  copyPrice(o.override),
+		Max:       copyPrice(o.max),
+		UpdatedAt: o.updatedAt,
+	}
+}
+
+func copyPrice(price *big.Int) *big.Int {
+	if price == nil {
+		return nil
+	}
+	return new(big.Int).Set(price)
+}
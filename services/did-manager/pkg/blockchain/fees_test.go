@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// fakeSuggester suggests a settable gas price
+type fakeSuggester struct {
+	mu    sync.Mutex
+	price *big.Int
+	err   error
+}
+
+func (s *fakeSuggester) SuggestGasPrice(context.Context) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.price, s.err
+}
+
+func (s *fakeSuggester) set(price int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.price = big.NewInt(price)
+}
+
+func TestFeeOracle(t *testing.T) {
+	source := &fakeSuggester{}
+	oracle := NewFeeOracle(source)
+	if oracle.GasPrice() != nil {
+		t.Fatal("expected no gas price before the first refresh")
+	}
+
+	source.set(100)
+	if err := oracle.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if got := oracle.GasPrice(); got.Int64() != 100 {
+		t.Errorf("expected the suggested 100 wei, got %v", got)
+	}
+
+	// The network moves and the next refresh follows it
+	source.set(250)
+	oracle.Refresh(context.Background())
+	if got := oracle.GasPrice(); got.Int64() != 250 {
+		t.Errorf("expected the refreshed 250 wei, got %v", got)
+	}
+
+	// A failed refresh keeps the previous suggestion
+	source.err = errors.New("node unreachable")
+	if err := oracle.Refresh(context.Background()); err == nil {
+		t.Error("expected the refresh to fail")
+	}
+	if got := oracle.GasPrice(); got.Int64() != 250 {
+		t.Errorf("expected the previous 250 wei, got %v", got)
+	}
+	source.err = nil
+
+	if err := oracle.SetOverride(big.NewInt(400)); err != nil {
+		t.Fatalf("SetOverride failed: %v", err)
+	}
+	if got := oracle.GasPrice(); got.Int64() != 400 {
+		t.Errorf("expected the 400 wei override, got %v", got)
+	}
+
+	if err := oracle.SetMaxGasPrice(big.NewInt(300)); err != nil {
+		t.Fatalf("SetMaxGasPrice failed: %v", err)
+	}
+	if got := oracle.GasPrice(); got.Int64() != 300 {
+		t.Errorf("expected the override capped at 300 wei, got %v", got)
+	}
+
+	oracle.SetOverride(nil)
+	status := oracle.Status()
+	if status.GasPrice.Int64() != 250 || status.Override != nil || status.Max.Int64() != 300 || status.UpdatedAt.IsZero() {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Callers cannot change the oracle's prices through the values it returns
+	oracle.GasPrice().SetInt64(1)
+	if got := oracle.GasPrice(); got.Int64() != 250 {
+		t.Errorf("expected 250 wei after modifying a returned price, got %v", got)
+	}
+}
+
+func TestFeeOracleRejectsInvalidPrices(t *testing.T) {
+	oracle := NewFeeOracle(&fakeSuggester{price: big.NewInt(0)})
+	if err := oracle.Refresh(context.Background()); err == nil {
+		t.Error("expected a zero suggestion to be rejected")
+	}
+	if err := oracle.SetOverride(big.NewInt(0)); !errors.Is(err, ErrInvalidGasPrice) {
+		t.Errorf("expected ErrInvalidGasPrice for a zero override, got %v", err)
+	}
+	if err := oracle.SetMaxGasPrice(big.NewInt(-1)); !errors.Is(err, ErrInvalidGasPrice) {
+		t.Errorf("expected ErrInvalidGasPrice for a negative maximum, got %v", err)
+	}
+}
+
+func TestFeeOracleConcurrentUse(t *testing.T) {
+	source := &fakeSuggester{price: big.NewInt(1)}
+	oracle := NewFeeOracle(source)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			for j := int64(0); j < 100; j++ {
+				source.set(i*100 + j + 1)
+				oracle.Refresh(context.Background())
+				oracle.SetMaxGasPrice(big.NewInt(i * 1000))
+				if price := oracle.GasPrice(); price == nil || price.Sign() <= 0 {
+					t.Errorf("unexpected gas price %v", price)
+				}
+				oracle.Status()
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,631 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// HederaClient anchors operations as messages on a Hedera Consensus Service topic,
+// which costs a fraction of a contract call and is final once it reaches consensus.
+// Transactions are encoded by hand in the protobuf wire format of the Hedera API,
+// covering only the fields below, signed with the operator's Ed25519 key and sent to
+// a consensus node through its gRPC-web proxy. Their outcome is read from a mirror
+// node.
+type HederaClient struct {
+	http *http.Client
+	// network names the Hedera network, e.g. mainnet or testnet
+	network     string
+	nodeURL     string
+	nodeAccount hederaEntityID
+	mirrorURL   string
+	topic       hederaEntityID
+	// operator pays for and signs transactions; key is nil for a read-only client
+	operator hederaEntityID
+	key      ed25519.PrivateKey
+	maxFee   uint64
+	// pollInterval is how often the mirror node is asked for a submitted transaction
+	pollInterval time.Duration
+}
+
+// HederaConfig configures a HederaClient
+type HederaConfig struct {
+	// Network names the Hedera network, e.g. mainnet or testnet
+	Network string
+	// NodeURL is the gRPC-web proxy of the consensus node transactions are sent to,
+	// and NodeAccountID the account of that node, e.g. 0.0.3
+	NodeURL       string
+	NodeAccountID string
+	// MirrorURL is the REST API of a mirror node, e.g. https://testnet.mirrornode.hedera.com
+	MirrorURL string
+	// TopicID is the topic operations are anchored in
+	TopicID string
+	// OperatorID is the account paying for transactions, signed with OperatorKey: a
+	// hex Ed25519 private key, raw or DER-encoded. Without them the client is read-only.
+	OperatorID  string
+	OperatorKey string
+	// MaxTransactionFee is the most a transaction may cost, in tinybars; 2 hbar when zero
+	MaxTransactionFee uint64
+}
+
+const (
+	// hederaMaxMessageSize is the largest message a topic takes in one transaction
+	hederaMaxMessageSize = 1024
+	// hederaDefaultMaxFee is 2 hbar in tinybars
+	hederaDefaultMaxFee = 200_000_000
+	// hederaValidDuration is how long a transaction may wait for consensus
+	hederaValidDuration = 120 * time.Second
+	// hederaSubmitMethod is the gRPC method submitting topic messages
+	hederaSubmitMethod = "/proto.ConsensusService/submitMessage"
+	// hederaSuccess is the result of a transaction that reached consensus
+	hederaSuccess = "SUCCESS"
+)
+
+// NewHederaClient creates a Hedera Consensus Service client
+func NewHederaClient(config HederaConfig) (*HederaClient, error) {
+	if config.MirrorURL == "" {
+		return nil, fmt.Errorf("a Hedera mirror node URL is required")
+	}
+	topic, err := parseHederaEntityID(config.TopicID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Hedera topic ID: %w", err)
+	}
+
+	client := &HederaClient{
+		http:         &http.Client{Timeout: 30 * time.Second},
+		network:      config.Network,
+		nodeURL:      strings.TrimSuffix(config.NodeURL, "/"),
+		mirrorURL:    strings.TrimSuffix(config.MirrorURL, "/"),
+		topic:        topic,
+		maxFee:       config.MaxTransactionFee,
+		pollInterval: time.Second,
+	}
+	if client.maxFee == 0 {
+		client.maxFee = hederaDefaultMaxFee
+	}
+	if config.OperatorKey == "" {
+		return client, nil
+	}
+
+	if client.nodeURL == "" {
+		return nil, fmt.Errorf("a Hedera node URL is required to submit transactions")
+	}
+	if client.nodeAccount, err = parseHederaEntityID(config.NodeAccountID); err != nil {
+		return nil, fmt.Errorf("invalid Hedera node account ID: %w", err)
+	}
+	if client.operator, err = parseHederaEntityID(config.OperatorID); err != nil {
+		return nil, fmt.Errorf("invalid Hedera operator ID: %w", err)
+	}
+	if client.key, err = parseHederaKey(config.OperatorKey); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// parseHederaKey parses a hex Ed25519 private key, either the raw seed or DER-encoded
+// PKCS #8 as exported by the Hedera portal
+func parseHederaKey(value string) (ed25519.PrivateKey, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Hedera operator key: %w", err)
+	}
+	if len(raw) == ed25519.SeedSize {
+		return ed25519.NewKeyFromSeed(raw), nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Hedera operator key: %w", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("Hedera operator key is not an Ed25519 key")
+	}
+	return privateKey, nil
+}
+
+// TopicID returns the topic operations are anchored in
+func (h *HederaClient) TopicID() string {
+	return h.topic.String()
+}
+
+// hederaMessage is the topic message anchoring an operation
+type hederaMessage struct {
+	Type         OperationType `json:"type"`
+	DID          string        `json:"did,omitempty"`
+	UserHash     string        `json:"user_hash,omitempty"`
+	DocumentHash string        `json:"document_hash,omitempty"`
+}
+
+// encodeHederaMessage encodes the topic message of an operation
+func encodeHederaMessage(op *Operation) ([]byte, error) {
+	switch op.Type {
+	case OperationRegisterDID, OperationUpdateDID, OperationRevokeDID, OperationNotarize:
+	default:
+		return nil, unknownOperation(op)
+	}
+	return json.Marshal(&hederaMessage{
+		Type:         op.Type,
+		DID:          op.DID,
+		UserHash:     op.UserHash,
+		DocumentHash: op.DocumentHash,
+	})
+}
+
+// Submit anchors an operation as a topic message and waits for it to reach consensus,
+// returning the Hedera transaction ID
+func (h *HederaClient) Submit(ctx context.Context, op *Operation) (string, error) {
+	if h.key == nil {
+		return "", ErrReadOnly
+	}
+	message, err := encodeHederaMessage(op)
+	if err != nil {
+		return "", err
+	}
+	if len(message) > hederaMaxMessageSize {
+		return "", fmt.Errorf("operation message of %d bytes exceeds the topic limit of %d", len(message), hederaMaxMessageSize)
+	}
+
+	// Start the transaction a little in the past, as the node rejects transactions
+	// that start after its own clock
+	txID := hederaTransactionID{account: h.operator, validStart: time.Now().Add(-10 * time.Second)}
+	if err := h.send(ctx, h.signedSubmitMessage(txID, message)); err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	notifySubmitted(ctx, txID.String())
+
+	// Wait for the mirror node to report the consensus outcome
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var record *mirrorTransaction
+	for {
+		record, err = h.transaction(ctx, txID.String())
+		if err == nil && record != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("transaction wait timeout")
+		case <-time.After(h.pollInterval):
+			// Continue polling
+		}
+	}
+
+	if record.Result != hederaSuccess {
+		return "", fmt.Errorf("transaction failed: %s", record.Result)
+	}
+
+	log.Printf("Transaction reached consensus: %s", txID)
+	return txID.String(), nil
+}
+
+// Simulate checks that an operation fits in a topic message. Topic messages run no
+// code, so nothing else can make the transaction fail short of the operator's balance.
+func (h *HederaClient) Simulate(op *Operation) (*Simulation, error) {
+	if h.key == nil {
+		return nil, ErrReadOnly
+	}
+	message, err := encodeHederaMessage(op)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{}
+	if len(message) > hederaMaxMessageSize {
+		simulation.Reverted = true
+		simulation.RevertReason = fmt.Sprintf("operation message of %d bytes exceeds the topic limit of %d", len(message), hederaMaxMessageSize)
+	}
+	return simulation, nil
+}
+
+// Verify checks a DID by the topic message of the transaction that last anchored it:
+// the DID is valid when that message registered or updated it on the client's topic
+func (h *HederaClient) Verify(did, txID string) (*ChainCheck, error) {
+	if txID == "" {
+		return &ChainCheck{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	record, err := h.transaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Result != hederaSuccess {
+		return &ChainCheck{}, nil
+	}
+
+	var topicMessage struct {
+		Message string `json:"message"`
+		TopicID string `json:"topic_id"`
+	}
+	found, err := h.mirrorGet(ctx, "/api/v1/topics/messages/"+url.PathEscape(record.ConsensusTimestamp), &topicMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !found || topicMessage.TopicID != h.topic.String() {
+		return &ChainCheck{}, nil
+	}
+
+	check := &ChainCheck{BlockTimestamp: record.consensusTime()}
+	data, err := base64.StdEncoding.DecodeString(topicMessage.Message)
+	if err != nil {
+		return check, nil
+	}
+	var message hederaMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return check, nil
+	}
+	check.Valid = message.DID == did && (message.Type == OperationRegisterDID || message.Type == OperationUpdateDID)
+	return check, nil
+}
+
+// Confirmations reports a transaction as final once it reached consensus. Hedera has
+// no forks, so consensus is never undone and one confirmation is all there is.
+func (h *HederaClient) Confirmations(txID string, head uint64) (uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	record, err := h.transaction(ctx, txID)
+	if err != nil {
+		return 0, false, err
+	}
+	if record == nil || record.Result != hederaSuccess {
+		return 0, false, nil
+	}
+	return 1, true, nil
+}
+
+// Anchoring describes a transaction that reached consensus: the topic it wrote to and
+// its consensus time
+func (h *HederaClient) Anchoring(txID string) (*Anchoring, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	record, err := h.transaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Result != hederaSuccess {
+		return nil, nil
+	}
+	return &Anchoring{
+		Chain:         "hedera",
+		Network:       h.network,
+		Anchor:        record.EntityID,
+		TransactionID: txID,
+		Timestamp:     record.consensusTime(),
+		Confirmations: 1,
+	}, nil
+}
+
+// mirrorTransaction is a transaction as reported by the mirror node
+type mirrorTransaction struct {
+	ConsensusTimestamp string `json:"consensus_timestamp"`
+	Result             string `json:"result"`
+	// EntityID is the topic of a topic message
+	EntityID string `json:"entity_id"`
+}
+
+// consensusTime parses the consensus timestamp, seconds.nanoseconds
+func (t *mirrorTransaction) consensusTime() time.Time {
+	seconds, nanos, _ := strings.Cut(t.ConsensusTimestamp, ".")
+	s, _ := strconv.ParseInt(seconds, 10, 64)
+	n, _ := strconv.ParseInt(nanos, 10, 64)
+	return time.Unix(s, n).UTC()
+}
+
+// transaction looks up a transaction on the mirror node; nil when it has not reached
+// the mirror node (yet)
+func (h *HederaClient) transaction(ctx context.Context, txID string) (*mirrorTransaction, error) {
+	id, err := mirrorTransactionID(txID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Transactions []*mirrorTransaction `json:"transactions"`
+	}
+	found, err := h.mirrorGet(ctx, "/api/v1/transactions/"+url.PathEscape(id), &response)
+	if err != nil || !found || len(response.Transactions) == 0 {
+		return nil, err
+	}
+	// Duplicates of a transaction are listed too; the one that reached consensus wins
+	for _, record := range response.Transactions {
+		if record.Result == hederaSuccess {
+			return record, nil
+		}
+	}
+	return response.Transactions[0], nil
+}
+
+// mirrorGet decodes a mirror node response into v, reporting false for a 404
+func (h *HederaClient) mirrorGet(ctx context.Context, path string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.mirrorURL+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create mirror node request: %w", err)
+	}
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach mirror node: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("mirror node returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to decode mirror node response: %w", err)
+	}
+	return true, nil
+}
+
+// hederaEntityID is an account or topic ID, shard.realm.num
+type hederaEntityID struct {
+	shard, realm, num int64
+}
+
+func parseHederaEntityID(value string) (hederaEntityID, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return hederaEntityID{}, fmt.Errorf("%q is not of the form shard.realm.num", value)
+	}
+	var numbers [3]int64
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return hederaEntityID{}, fmt.Errorf("%q is not of the form shard.realm.num", value)
+		}
+		numbers[i] = n
+	}
+	return hederaEntityID{shard: numbers[0], realm: numbers[1], num: numbers[2]}, nil
+}
+
+func (id hederaEntityID) String() string {
+	return fmt.Sprintf("%d.%d.%d", id.shard, id.realm, id.num)
+}
+
+// hederaTransactionID identifies a transaction by its payer and start time
+type hederaTransactionID struct {
+	account    hederaEntityID
+	validStart time.Time
+}
+
+// String formats the ID as account@seconds.nanoseconds
+func (id hederaTransactionID) String() string {
+	return fmt.Sprintf("%s@%d.%09d", id.account, id.validStart.Unix(), id.validStart.Nanosecond())
+}
+
+// mirrorTransactionID converts account@seconds.nanoseconds to the mirror node's
+// account-seconds-nanoseconds
+func mirrorTransactionID(txID string) (string, error) {
+	account, start, ok := strings.Cut(txID, "@")
+	seconds, nanos, ok2 := strings.Cut(start, ".")
+	if !ok || !ok2 {
+		return "", fmt.Errorf("invalid Hedera transaction ID %q", txID)
+	}
+	return account + "-" + seconds + "-" + nanos, nil
+}
+
+// Protobuf field numbers of the Hedera API messages the client builds and reads
+const (
+	// Transaction
+	hapiTransactionSignedBytes protowire.Number = 5
+	// SignedTransaction
+	hapiSignedBodyBytes protowire.Number = 1
+	hapiSignedSigMap    protowire.Number = 2
+	// SignatureMap
+	hapiSigMapPair protowire.Number = 1
+	// SignaturePair
+	hapiSigPairPubKey  protowire.Number = 1
+	hapiSigPairEd25519 protowire.Number = 3
+	// TransactionBody
+	hapiBodyTransactionID protowire.Number = 1
+	hapiBodyNodeAccountID protowire.Number = 2
+	hapiBodyFee           protowire.Number = 3
+	hapiBodyValidDuration protowire.Number = 4
+	hapiBodySubmitMessage protowire.Number = 27
+	// TransactionID
+	hapiTransactionIDStart protowire.Number = 1
+	hapiTransactionIDPayer protowire.Number = 2
+	// ConsensusSubmitMessageTransactionBody
+	hapiSubmitTopicID protowire.Number = 1
+	hapiSubmitMessage protowire.Number = 2
+	// TransactionResponse
+	hapiResponsePrecheckCode protowire.Number = 1
+)
+
+// signedSubmitMessage builds a signed ConsensusSubmitMessage transaction
+func (h *HederaClient) signedSubmitMessage(txID hederaTransactionID, message []byte) []byte {
+	var validStart []byte
+	validStart = appendVarintField(validStart, 1, uint64(txID.validStart.Unix()))
+	validStart = appendVarintField(validStart, 2, uint64(txID.validStart.Nanosecond()))
+
+	var transactionID []byte
+	transactionID = appendMessageField(transactionID, hapiTransactionIDStart, validStart)
+	transactionID = appendMessageField(transactionID, hapiTransactionIDPayer, txID.account.marshal())
+
+	var submit []byte
+	submit = appendMessageField(submit, hapiSubmitTopicID, h.topic.marshal())
+	submit = appendMessageField(submit, hapiSubmitMessage, message)
+
+	var body []byte
+	body = appendMessageField(body, hapiBodyTransactionID, transactionID)
+	body = appendMessageField(body, hapiBodyNodeAccountID, h.nodeAccount.marshal())
+	body = appendVarintField(body, hapiBodyFee, h.maxFee)
+	body = appendMessageField(body, hapiBodyValidDuration, appendVarintField(nil, 1, uint64(hederaValidDuration/time.Second)))
+	body = appendMessageField(body, hapiBodySubmitMessage, submit)
+
+	var pair []byte
+	pair = appendMessageField(pair, hapiSigPairPubKey, h.key.Public().(ed25519.PublicKey))
+	pair = appendMessageField(pair, hapiSigPairEd25519, ed25519.Sign(h.key, body))
+
+	var signed []byte
+	signed = appendMessageField(signed, hapiSignedBodyBytes, body)
+	signed = appendMessageField(signed, hapiSignedSigMap, appendMessageField(nil, hapiSigMapPair, pair))
+
+	return appendMessageField(nil, hapiTransactionSignedBytes, signed)
+}
+
+// marshal encodes an AccountID or TopicID, which share their field numbers
+func (id hederaEntityID) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(id.shard))
+	b = appendVarintField(b, 2, uint64(id.realm))
+	return appendVarintField(b, 3, uint64(id.num))
+}
+
+// appendVarintField appends a varint field, leaving out zero values as proto3 does
+func appendVarintField(b []byte, number protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// appendMessageField appends a length-delimited field
+func appendMessageField(b []byte, number protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// hederaPrecheckCodes names the precheck codes a submission is commonly rejected with
+var hederaPrecheckCodes = map[uint64]string{
+	4:  "TRANSACTION_EXPIRED",
+	5:  "INVALID_TRANSACTION_START",
+	7:  "INVALID_SIGNATURE",
+	9:  "INSUFFICIENT_TX_FEE",
+	10: "INSUFFICIENT_PAYER_BALANCE",
+	11: "DUPLICATE_TRANSACTION",
+	12: "BUSY",
+}
+
+// send submits a transaction to the consensus node and checks its precheck code
+func (h *HederaClient) send(ctx context.Context, transaction []byte) error {
+	// A gRPC-web request body is one frame: a zero flag byte, the big-endian message
+	// length and the message
+	frame := make([]byte, 5, 5+len(transaction))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(transaction)))
+	frame = append(frame, transaction...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.nodeURL+hederaSubmitMethod, bytes.NewReader(frame))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("X-Grpc-Web", "1")
+
+	resp, err := h.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach consensus node: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consensus node returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read consensus node response: %w", err)
+	}
+
+	response, err := parseGRPCWebResponse(resp.Header, body)
+	if err != nil {
+		return err
+	}
+	code, err := precheckCode(response)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		name, ok := hederaPrecheckCodes[code]
+		if !ok {
+			name = strconv.FormatUint(code, 10)
+		}
+		return fmt.Errorf("consensus node rejected the transaction: %s", name)
+	}
+	return nil
+}
+
+// parseGRPCWebResponse returns the message of a gRPC-web response, failing for a
+// non-zero gRPC status in its headers or trailer frame
+func parseGRPCWebResponse(header http.Header, body []byte) ([]byte, error) {
+	status, message := header.Get("Grpc-Status"), header.Get("Grpc-Message")
+	var response []byte
+	for len(body) >= 5 {
+		flag, length := body[0], binary.BigEndian.Uint32(body[1:5])
+		if uint32(len(body)-5) < length {
+			return nil, errors.New("truncated gRPC-web response")
+		}
+		payload := body[5 : 5+length]
+		body = body[5+length:]
+
+		if flag&0x80 == 0 {
+			response = payload
+			continue
+		}
+		// Trailers are HTTP/1 header lines
+		for _, line := range strings.Split(string(payload), "\r\n") {
+			key, value, _ := strings.Cut(line, ":")
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "grpc-status":
+				status = strings.TrimSpace(value)
+			case "grpc-message":
+				message = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	if status != "" && status != "0" {
+		return nil, fmt.Errorf("consensus node returned gRPC status %s: %s", status, message)
+	}
+	if response == nil {
+		return nil, errors.New("empty gRPC-web response")
+	}
+	return response, nil
+}
+
+// precheckCode reads the precheck code of a TransactionResponse; absent means OK
+func precheckCode(response []byte) (uint64, error) {
+	for len(response) > 0 {
+		number, wireType, n := protowire.ConsumeTag(response)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		response = response[n:]
+		if number == hapiResponsePrecheckCode && wireType == protowire.VarintType {
+			code, n := protowire.ConsumeVarint(response)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			return code, nil
+		}
+		n = protowire.ConsumeFieldValue(number, wireType, response)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		response = response[n:]
+	}
+	return 0, nil
+}
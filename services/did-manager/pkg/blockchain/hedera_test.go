@@ -0,0 +1,298 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoFields decodes the fields of a protobuf message, keeping the last value of
+// each; varints are returned in their wire encoding
+func protoFields(t *testing.T, b []byte) map[protowire.Number][]byte {
+	t.Helper()
+	fields := map[protowire.Number][]byte{}
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("malformed protobuf: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch wireType {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatalf("malformed protobuf: %v", protowire.ParseError(n))
+			}
+			fields[number], b = value, b[n:]
+		case protowire.VarintType:
+			_, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatalf("malformed protobuf: %v", protowire.ParseError(n))
+			}
+			fields[number], b = b[:n], b[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", wireType)
+		}
+	}
+	return fields
+}
+
+func protoVarint(t *testing.T, b []byte) int64 {
+	t.Helper()
+	if b == nil {
+		return 0
+	}
+	value, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		t.Fatalf("malformed varint: %v", protowire.ParseError(n))
+	}
+	return int64(value)
+}
+
+// fakeHedera is a consensus node that accepts topic messages signed by operatorKey and
+// a mirror node reporting them
+type fakeHedera struct {
+	t           *testing.T
+	operatorKey ed25519.PublicKey
+	// precheck is the precheck code submissions are answered with
+	precheck uint64
+
+	mu           sync.Mutex
+	transactions map[string]string
+	messages     map[string][]byte
+}
+
+func newFakeHedera(t *testing.T, operatorKey ed25519.PublicKey) (*fakeHedera, *httptest.Server) {
+	fake := &fakeHedera{
+		t:            t,
+		operatorKey:  operatorKey,
+		transactions: map[string]string{},
+		messages:     map[string][]byte{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+hederaSubmitMethod, fake.submit)
+	mux.HandleFunc("GET /api/v1/transactions/{id}", fake.transaction)
+	mux.HandleFunc("GET /api/v1/topics/messages/{timestamp}", fake.message)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeHedera) submit(w http.ResponseWriter, r *http.Request) {
+	t := f.t
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("Content-Type") != "application/grpc-web+proto" || len(body) < 5 || body[0] != 0 ||
+		int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		t.Errorf("malformed gRPC-web request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	signed := protoFields(t, protoFields(t, body[5:])[hapiTransactionSignedBytes])
+	bodyBytes := signed[hapiSignedBodyBytes]
+	pair := protoFields(t, protoFields(t, signed[hapiSignedSigMap])[hapiSigMapPair])
+	if !ed25519.PublicKey(pair[hapiSigPairPubKey]).Equal(f.operatorKey) || !ed25519.Verify(f.operatorKey, bodyBytes, pair[hapiSigPairEd25519]) {
+		t.Errorf("transaction is not signed by the operator")
+	}
+
+	txBody := protoFields(t, bodyBytes)
+	transactionID := protoFields(t, txBody[hapiBodyTransactionID])
+	start := protoFields(t, transactionID[hapiTransactionIDStart])
+	payer := protoFields(t, transactionID[hapiTransactionIDPayer])
+	submit := protoFields(t, txBody[hapiBodySubmitMessage])
+	topic := protoFields(t, submit[hapiSubmitTopicID])
+	if protoVarint(t, txBody[hapiBodyFee]) == 0 || protoVarint(t, topic[3]) != 1234 {
+		t.Errorf("unexpected transaction body")
+	}
+
+	id := fmt.Sprintf("0.0.%d-%d-%09d", protoVarint(t, payer[3]), protoVarint(t, start[1]), protoVarint(t, start[2]))
+	if f.precheck == 0 {
+		f.mu.Lock()
+		timestamp := fmt.Sprintf("%d.000000001", time.Now().Unix()+int64(len(f.transactions)))
+		f.transactions[id] = timestamp
+		f.messages[timestamp] = submit[hapiSubmitMessage]
+		f.mu.Unlock()
+	}
+
+	var response []byte
+	if f.precheck != 0 {
+		response = appendVarintField(nil, hapiResponsePrecheckCode, f.precheck)
+	}
+	frame := append([]byte{0, 0, 0, 0, 0}, response...)
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(response)))
+	trailer := []byte("grpc-status: 0\r\n")
+	frame = append(frame, 0x80, 0, 0, 0, byte(len(trailer)))
+	frame = append(frame, trailer...)
+	w.Header().Set("Content-Type", "application/grpc-web+proto")
+	w.Write(frame)
+}
+
+func (f *fakeHedera) transaction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	timestamp, ok := f.transactions[r.PathValue("id")]
+	f.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"transactions": []map[string]string{{"consensus_timestamp": timestamp, "result": "SUCCESS", "entity_id": "0.0.1234"}},
+	})
+}
+
+func (f *fakeHedera) message(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	message, ok := f.messages[r.PathValue("timestamp")]
+	f.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"topic_id": "0.0.1234",
+		"message":  base64.StdEncoding.EncodeToString(message),
+	})
+}
+
+func newTestHederaClient(t *testing.T) (*HederaClient, *fakeHedera) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake, server := newFakeHedera(t, publicKey)
+
+	client, err := NewHederaClient(HederaConfig{
+		Network:       "testnet",
+		NodeURL:       server.URL,
+		NodeAccountID: "0.0.3",
+		MirrorURL:     server.URL,
+		TopicID:       "0.0.1234",
+		OperatorID:    "0.0.5678",
+		OperatorKey:   hex.EncodeToString(privateKey.Seed()),
+	})
+	if err != nil {
+		t.Fatalf("NewHederaClient failed: %v", err)
+	}
+	client.pollInterval = 10 * time.Millisecond
+	return client, fake
+}
+
+func TestHederaClientAnchorsOperations(t *testing.T) {
+	client, _ := newTestHederaClient(t)
+	var chain Chain = client
+	did := "did:example:123"
+
+	var submitted string
+	ctx := WithSubmitObserver(context.Background(), func(txID string) { submitted = txID })
+	registerTx, err := chain.Submit(ctx, &Operation{Type: OperationRegisterDID, DID: did, UserHash: "0xabc"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if !strings.HasPrefix(registerTx, "0.0.5678@") || submitted != registerTx {
+		t.Errorf("unexpected transaction ID %q, observed %q", registerTx, submitted)
+	}
+
+	check, err := chain.Verify(did, registerTx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !check.Valid || check.BlockTimestamp.IsZero() {
+		t.Errorf("expected the registered DID to verify, got %+v", check)
+	}
+	if check, _ := chain.Verify("did:example:other", registerTx); check.Valid {
+		t.Error("expected a DID the transaction does not anchor to fail")
+	}
+	if confirmations, ok, err := chain.Confirmations(registerTx, 0); err != nil || !ok || confirmations != 1 {
+		t.Errorf("expected a final transaction, got %d, %t, %v", confirmations, ok, err)
+	}
+
+	anchoring, err := chain.Anchoring(registerTx)
+	if err != nil {
+		t.Fatalf("Anchoring failed: %v", err)
+	}
+	if anchoring == nil || anchoring.Chain != "hedera" || anchoring.Network != "testnet" || anchoring.Anchor != "0.0.1234" ||
+		anchoring.TransactionID != registerTx || anchoring.Timestamp.IsZero() || anchoring.Confirmations != 1 {
+		t.Errorf("unexpected anchoring %+v", anchoring)
+	}
+
+	revokeTx, err := chain.Submit(context.Background(), &Operation{Type: OperationRevokeDID, DID: did, UserHash: "0xabc"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if check, _ := chain.Verify(did, revokeTx); check.Valid {
+		t.Error("expected the revoked DID to fail verification")
+	}
+
+	// Transactions the mirror node does not know are not anchored
+	if check, err := chain.Verify(did, "0.0.5678@1.000000000"); err != nil || check.Valid {
+		t.Errorf("expected an unknown transaction not to verify, got %+v, %v", check, err)
+	}
+	if _, ok, err := chain.Confirmations("0.0.5678@1.000000000", 0); err != nil || ok {
+		t.Errorf("expected an unknown transaction not to be final, got %t, %v", ok, err)
+	}
+	if anchoring, err := chain.Anchoring("0.0.5678@1.000000000"); err != nil || anchoring != nil {
+		t.Errorf("expected an unknown transaction not to be anchored, got %+v, %v", anchoring, err)
+	}
+}
+
+func TestHederaClientRejections(t *testing.T) {
+	client, fake := newTestHederaClient(t)
+
+	fake.precheck = 10
+	_, err := client.Submit(context.Background(), &Operation{Type: OperationRegisterDID, DID: "did:example:123"})
+	if err == nil || !strings.Contains(err.Error(), "INSUFFICIENT_PAYER_BALANCE") {
+		t.Errorf("expected the precheck code to fail the submission, got %v", err)
+	}
+
+	if _, err := client.Submit(context.Background(), &Operation{Type: "transfer"}); err == nil {
+		t.Error("expected an unknown operation to be rejected")
+	}
+
+	oversized := &Operation{Type: OperationRegisterDID, DID: "did:example:" + strings.Repeat("a", hederaMaxMessageSize)}
+	simulation, err := client.Simulate(oversized)
+	if err != nil || !simulation.Reverted {
+		t.Errorf("expected an oversized message to be reported, got %+v, %v", simulation, err)
+	}
+
+	readOnly, err := NewHederaClient(HederaConfig{MirrorURL: "http://localhost", TopicID: "0.0.1234"})
+	if err != nil {
+		t.Fatalf("NewHederaClient failed: %v", err)
+	}
+	if _, err := readOnly.Submit(context.Background(), &Operation{Type: OperationRegisterDID}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestParseHederaKey(t *testing.T) {
+	// DER-encoded key as exported by the Hedera portal
+	der := "302e020100300506032b657004220420" + strings.Repeat("01", ed25519.SeedSize)
+	key, err := parseHederaKey(der)
+	if err != nil {
+		t.Fatalf("parseHederaKey failed: %v", err)
+	}
+	raw, err := parseHederaKey(strings.Repeat("01", ed25519.SeedSize))
+	if err != nil {
+		t.Fatalf("parseHederaKey failed: %v", err)
+	}
+	if !key.Equal(raw) {
+		t.Error("expected the DER and raw encodings of a key to match")
+	}
+	if _, err := parseHederaKey("zz"); err == nil {
+		t.Error("expected a malformed key to be rejected")
+	}
+}
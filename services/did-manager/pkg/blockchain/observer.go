@@ -0,0 +1,20 @@
+package blockchain
+
+import "context"
+
+// submittedKey keys the submit observer of a context
+type submittedKey struct{}
+
+// WithSubmitObserver returns a context that reports the hash of each transaction sent
+// under it as soon as it is sent, before it is mined, so callers can tell the time
+// spent sending a transaction from the time waiting for it to be mined
+func WithSubmitObserver(ctx context.Context, observer func(txHash string)) context.Context {
+	return context.WithValue(ctx, submittedKey{}, observer)
+}
+
+// notifySubmitted reports a sent transaction to the submit observer of ctx, if any
+func notifySubmitted(ctx context.Context, txHash string) {
+	if observer, ok := ctx.Value(submittedKey{}).(func(string)); ok {
+		observer(txHash)
+	}
+}
@@ -0,0 +1,236 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// didRegistryReadABI covers the DIDRegistry state getter and events read when
+// reconciling local records against the chain and when following DID changes
+const didRegistryReadABI = `[
+	{
+		"inputs": [{"name": "", "type": "bytes32"}],
+		"name": "didRecords",
+		"outputs": [
+			{"name": "did", "type": "string"},
+			{"name": "registrationTime", "type": "uint256"},
+			{"name": "lastUpdateTime", "type": "uint256"},
+			{"name": "isActive", "type": "bool"},
+			{"name": "isRevoked", "type": "bool"},
+			{"name": "metadata", "type": "string"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "name": "userHash", "type": "bytes32"},
+			{"indexed": false, "name": "did", "type": "string"},
+			{"indexed": false, "name": "timestamp", "type": "uint256"}
+		],
+		"name": "DIDRegistered",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "name": "userHash", "type": "bytes32"},
+			{"indexed": false, "name": "did", "type": "string"},
+			{"indexed": false, "name": "timestamp", "type": "uint256"}
+		],
+		"name": "DIDUpdated",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "name": "userHash", "type": "bytes32"},
+			{"indexed": false, "name": "did", "type": "string"},
+			{"indexed": false, "name": "timestamp", "type": "uint256"}
+		],
+		"name": "DIDRevoked",
+		"type": "event"
+	}
+]`
+
+// DIDRegistry events that change a registered DID
+const (
+	DIDEventUpdated = "DIDUpdated"
+	DIDEventRevoked = "DIDRevoked"
+)
+
+// OnChainDID is a DID record as stored by the DIDRegistry contract
+type OnChainDID struct {
+	UserHash     string
+	DID          string
+	RegisteredAt time.Time
+	Active       bool
+	Revoked      bool
+	// TxHash is the registering transaction; only known for records read from events
+	TxHash string
+}
+
+// GetDIDRecord reads the on-chain record for a user hash, returning nil when the
+// user hash was never registered
+func (e *EthereumClient) GetDIDRecord(userHash string) (*OnChainDID, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(didRegistryReadABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack("didRecords", common.HexToHash(userHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	// Call contract (read-only)
+	result, err := e.client.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &e.contract,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	values, err := parsedABI.Unpack("didRecords", result)
+	if err != nil || len(values) != 6 {
+		return nil, fmt.Errorf("failed to unpack result: %v", err)
+	}
+
+	registrationTime, _ := values[1].(*big.Int)
+	if registrationTime == nil || registrationTime.Sign() == 0 {
+		return nil, nil
+	}
+
+	record := &OnChainDID{
+		UserHash:     common.HexToHash(userHash).Hex(),
+		RegisteredAt: time.Unix(registrationTime.Int64(), 0),
+	}
+	record.DID, _ = values[0].(string)
+	record.Active, _ = values[3].(bool)
+	record.Revoked, _ = values[4].(bool)
+
+	return record, nil
+}
+
+// RegisteredDIDs lists the DIDs registered on-chain from fromBlock onwards, as
+// recorded by DIDRegistered events; Active and Revoked are not populated
+func (e *EthereumClient) RegisteredDIDs(fromBlock uint64) ([]*OnChainDID, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(didRegistryReadABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	event := parsedABI.Events["DIDRegistered"]
+
+	logs, err := e.client.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		Addresses: []common.Address{e.contract},
+		Topics:    [][]common.Hash{{event.ID}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	records := make([]*OnChainDID, 0, len(logs))
+	for _, entry := range logs {
+		if len(entry.Topics) < 2 {
+			continue
+		}
+
+		values, err := event.Inputs.NonIndexed().Unpack(entry.Data)
+		if err != nil || len(values) != 2 {
+			return nil, fmt.Errorf("failed to unpack DIDRegistered event: %v", err)
+		}
+
+		record := &OnChainDID{
+			UserHash: entry.Topics[1].Hex(),
+			TxHash:   entry.TxHash.Hex(),
+		}
+		record.DID, _ = values[0].(string)
+		if timestamp, ok := values[1].(*big.Int); ok {
+			record.RegisteredAt = time.Unix(timestamp.Int64(), 0)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// DIDEvent is a DIDUpdated or DIDRevoked event emitted by the DIDRegistry contract
+type DIDEvent struct {
+	// Name is DIDEventUpdated or DIDEventRevoked
+	Name        string
+	UserHash    string
+	DID         string
+	TxHash      string
+	BlockNumber uint64
+	OccurredAt  time.Time
+}
+
+// HeadBlock returns the number of the latest block
+func (e *EthereumClient) HeadBlock() (uint64, error) {
+	head, err := e.client.BlockNumber(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return head, nil
+}
+
+// DIDEvents lists the DIDUpdated and DIDRevoked events emitted in the blocks from
+// fromBlock to toBlock inclusive, in chain order
+func (e *EthereumClient) DIDEvents(fromBlock, toBlock uint64) ([]*DIDEvent, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(didRegistryReadABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	updated := parsedABI.Events[DIDEventUpdated]
+	revoked := parsedABI.Events[DIDEventRevoked]
+
+	logs, err := e.client.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{e.contract},
+		Topics:    [][]common.Hash{{updated.ID, revoked.ID}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	events := make([]*DIDEvent, 0, len(logs))
+	for _, entry := range logs {
+		if len(entry.Topics) < 2 || entry.Removed {
+			continue
+		}
+
+		event := updated
+		if entry.Topics[0] == revoked.ID {
+			event = revoked
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(entry.Data)
+		if err != nil || len(values) != 2 {
+			return nil, fmt.Errorf("failed to unpack %s event: %v", event.Name, err)
+		}
+
+		didEvent := &DIDEvent{
+			Name:        event.Name,
+			UserHash:    entry.Topics[1].Hex(),
+			TxHash:      entry.TxHash.Hex(),
+			BlockNumber: entry.BlockNumber,
+		}
+		didEvent.DID, _ = values[0].(string)
+		if timestamp, ok := values[1].(*big.Int); ok {
+			didEvent.OccurredAt = time.Unix(timestamp.Int64(), 0)
+		}
+		events = append(events, didEvent)
+	}
+
+	return events, nil
+}
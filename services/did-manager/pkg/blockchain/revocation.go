@@ -0,0 +1,117 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// revocationRegistryABI covers the RevocationRegistry functions used by the service
+const revocationRegistryABI = `[
+	{
+		"inputs": [
+			{"name": "root", "type": "bytes32"},
+			{"name": "batch", "type": "uint256"},
+			{"name": "revokedCount", "type": "uint256"}
+		],
+		"name": "publishRoot",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "latestRoot",
+		"outputs": [
+			{"name": "root", "type": "bytes32"},
+			{"name": "batch", "type": "uint256"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// SetRevocationRegistry configures the address of the RevocationRegistry contract
+func (e *EthereumClient) SetRevocationRegistry(address string) {
+	e.revocationRegistry = common.HexToAddress(address)
+}
+
+// RevocationRegistry returns the configured RevocationRegistry address, or an empty
+// string when none is configured
+func (e *EthereumClient) RevocationRegistry() string {
+	if e.revocationRegistry == (common.Address{}) {
+		return ""
+	}
+	return e.revocationRegistry.Hex()
+}
+
+// PublishRevocationRoot anchors the revocation accumulator root for a batch
+func (e *EthereumClient) PublishRevocationRoot(root [32]byte, batch uint64, revokedCount int) (string, error) {
+	if e.revocationRegistry == (common.Address{}) {
+		return "", fmt.Errorf("revocation registry contract is not configured")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(revocationRegistryABI))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	// Encode function call
+	data, err := parsedABI.Pack("publishRoot", root, new(big.Int).SetUint64(batch), big.NewInt(int64(revokedCount)))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	tx, err := e.sendTransaction(context.Background(), e.revocationRegistry, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return tx.Hash().Hex(), nil
+}
+
+// LatestRevocationRoot reads the most recently anchored revocation root and its batch
+func (e *EthereumClient) LatestRevocationRoot() ([32]byte, uint64, error) {
+	var root [32]byte
+
+	if e.revocationRegistry == (common.Address{}) {
+		return root, 0, fmt.Errorf("revocation registry contract is not configured")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(revocationRegistryABI))
+	if err != nil {
+		return root, 0, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack("latestRoot")
+	if err != nil {
+		return root, 0, fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	// Call contract (read-only)
+	result, err := e.client.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &e.revocationRegistry,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return root, 0, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	values, err := parsedABI.Unpack("latestRoot", result)
+	if err != nil || len(values) != 2 {
+		return root, 0, fmt.Errorf("failed to unpack result: %v", err)
+	}
+
+	root, _ = values[0].([32]byte)
+	batch, _ := values[1].(*big.Int)
+	if batch == nil {
+		return root, 0, fmt.Errorf("failed to unpack batch")
+	}
+
+	return root, batch.Uint64(), nil
+}
@@ -0,0 +1,348 @@
+package blockchain
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Methods of a chain a ShadowChain compares
+const (
+	ShadowMethodSubmit   = "submit"
+	ShadowMethodSimulate = "simulate"
+	ShadowMethodVerify   = "verify"
+)
+
+// Results of comparing a shadow call with the primary's
+const (
+	ShadowMatch    = "match"
+	ShadowDiverged = "diverged"
+	// ShadowSkipped counts calls not replayed because too many shadow calls were in
+	// flight
+	ShadowSkipped = "skipped"
+)
+
+// maxShadowDivergences bounds the recent divergences a ShadowChain keeps
+const maxShadowDivergences = 100
+
+// maxShadowTransactions bounds the shadow transactions a ShadowChain remembers for
+// verifying DIDs on the shadow
+const maxShadowTransactions = 10000
+
+// ShadowConfig tunes a ShadowChain; zero values use the defaults
+type ShadowConfig struct {
+	// Timeout bounds each shadow call (default 5m, as long as a Hedera submission waits)
+	Timeout time.Duration
+	// MaxInFlight bounds the shadow calls running at once; further calls are skipped
+	// rather than queued (default 16)
+	MaxInFlight int
+}
+
+// ShadowDivergence is a call whose shadow outcome differed from the primary's
+type ShadowDivergence struct {
+	Method    string        `json:"method"`
+	Operation OperationType `json:"operation,omitempty"`
+	DID       string        `json:"did,omitempty"`
+	// Primary and Shadow describe the outcomes, e.g. "anchored" or "failed: <error>"
+	Primary string    `json:"primary"`
+	Shadow  string    `json:"shadow"`
+	At      time.Time `json:"at"`
+}
+
+// ShadowCount counts the comparisons of a method with a result
+type ShadowCount struct {
+	Method string `json:"method"`
+	Result string `json:"result"`
+	Count  uint64 `json:"count"`
+}
+
+// ShadowStats reports how a shadow chain compares with the primary
+type ShadowStats struct {
+	Comparisons []ShadowCount `json:"comparisons"`
+	InFlight    int           `json:"in_flight"`
+	// Divergences lists the most recent divergences, oldest first
+	Divergences []ShadowDivergence `json:"divergences"`
+}
+
+// shadowOutcome is the outcome of a call: kind is compared between the chains, detail
+// only explains it, since transaction IDs and error messages differ between backends
+type shadowOutcome struct {
+	kind   string
+	detail string
+}
+
+func (o shadowOutcome) String() string {
+	if o.detail == "" {
+		return o.kind
+	}
+	return o.kind + ": " + o.detail
+}
+
+// panicOutcome is the outcome of a call that panicked
+var panicOutcome = shadowOutcome{kind: "panicked"}
+
+// errorOutcome is the outcome of a failed call
+func errorOutcome(err error) shadowOutcome {
+	return shadowOutcome{kind: "failed", detail: err.Error()}
+}
+
+// ShadowChain anchors on a primary chain while replaying each operation, simulation and
+// verification on a shadow chain in parallel, such as a new backend being migrated to.
+// Callers only ever get the primary's results; the shadow's outcomes are compared with
+// them and divergences counted and logged, so the new backend can be trusted before
+// cutover. Confirmations and Anchoring take primary transaction IDs and are not
+// replayed.
+type ShadowChain struct {
+	primary Chain
+	shadow  Chain
+	timeout time.Duration
+	slots   chan struct{}
+	pending sync.WaitGroup
+
+	mu          sync.Mutex
+	counts      map[[2]string]uint64
+	divergences []ShadowDivergence
+	// transactions maps DIDs to the shadow transaction that last anchored them, for
+	// shadows that verify DIDs by their transactions
+	transactions map[string]string
+}
+
+// NewShadowChain creates a chain anchoring on primary and replaying on shadow
+func NewShadowChain(primary, shadow Chain, cfg ShadowConfig) *ShadowChain {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 16
+	}
+	return &ShadowChain{
+		primary:      primary,
+		shadow:       shadow,
+		timeout:      cfg.Timeout,
+		slots:        make(chan struct{}, cfg.MaxInFlight),
+		counts:       make(map[[2]string]uint64),
+		transactions: make(map[string]string),
+	}
+}
+
+// Submit anchors an operation on the primary, submitting it to the shadow in parallel
+func (s *ShadowChain) Submit(ctx context.Context, op *Operation) (string, error) {
+	primary := s.replay(ShadowMethodSubmit, op.Type, op.DID, func(ctx context.Context) shadowOutcome {
+		txID, err := s.shadow.Submit(ctx, op)
+		if err != nil {
+			return submitOutcome("", err)
+		}
+		if op.DID != "" {
+			s.rememberTransaction(op.DID, txID)
+		}
+		return submitOutcome(txID, nil)
+	})
+	outcome := panicOutcome
+	defer func() { primary(outcome) }()
+
+	txID, err := s.primary.Submit(ctx, op)
+	outcome = submitOutcome(txID, err)
+	return txID, err
+}
+
+// Simulate simulates an operation on the primary, simulating it on the shadow in
+// parallel
+func (s *ShadowChain) Simulate(op *Operation) (*Simulation, error) {
+	primary := s.replay(ShadowMethodSimulate, op.Type, op.DID, func(context.Context) shadowOutcome {
+		return simulationOutcome(s.shadow.Simulate(op))
+	})
+	outcome := panicOutcome
+	defer func() { primary(outcome) }()
+
+	simulation, err := s.primary.Simulate(op)
+	outcome = simulationOutcome(simulation, err)
+	return simulation, err
+}
+
+// Verify checks a DID on the primary, checking it on the shadow in parallel against the
+// shadow transaction that last anchored it
+func (s *ShadowChain) Verify(did, txID string) (*ChainCheck, error) {
+	shadowTxID := s.transaction(did)
+	primary := s.replay(ShadowMethodVerify, "", did, func(context.Context) shadowOutcome {
+		return checkOutcome(s.shadow.Verify(did, shadowTxID))
+	})
+	outcome := panicOutcome
+	defer func() { primary(outcome) }()
+
+	check, err := s.primary.Verify(did, txID)
+	outcome = checkOutcome(check, err)
+	return check, err
+}
+
+// Confirmations counts the confirmations of a primary transaction
+func (s *ShadowChain) Confirmations(txID string, head uint64) (uint64, bool, error) {
+	return s.primary.Confirmations(txID, head)
+}
+
+// Anchoring describes a primary transaction
+func (s *ShadowChain) Anchoring(txID string) (*Anchoring, error) {
+	return s.primary.Anchoring(txID)
+}
+
+// Stats reports the comparisons so far
+func (s *ShadowChain) Stats() *ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := &ShadowStats{
+		Comparisons: make([]ShadowCount, 0, len(s.counts)),
+		InFlight:    len(s.slots),
+		Divergences: append([]ShadowDivergence(nil), s.divergences...),
+	}
+	for key, count := range s.counts {
+		stats.Comparisons = append(stats.Comparisons, ShadowCount{Method: key[0], Result: key[1], Count: count})
+	}
+	sort.Slice(stats.Comparisons, func(i, j int) bool {
+		a, b := stats.Comparisons[i], stats.Comparisons[j]
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Result < b.Result
+	})
+	return stats
+}
+
+// Drain waits for the shadow calls in flight, giving up once ctx ends
+func (s *ShadowChain) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// replay starts a shadow call in the background and returns the function the caller
+// must report the primary's outcome with; the two are compared once both are known. The
+// shadow call gets a context of its own, so it neither reports its transactions to the
+// primary's submit observer nor ends with the primary's call.
+func (s *ShadowChain) replay(method string, opType OperationType, did string, call func(ctx context.Context) shadowOutcome) func(shadowOutcome) {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.count(method, ShadowSkipped)
+		return func(shadowOutcome) {}
+	}
+
+	primary := make(chan shadowOutcome, 1)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		defer func() { <-s.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		shadow := callShadow(ctx, call)
+		s.compare(method, opType, did, <-primary, shadow)
+	}()
+	return func(outcome shadowOutcome) {
+		primary <- outcome
+	}
+}
+
+// callShadow runs a shadow call, recovering a panic so a faulty backend under
+// evaluation cannot take the service down
+func callShadow(ctx context.Context, call func(ctx context.Context) shadowOutcome) (outcome shadowOutcome) {
+	defer func() {
+		if value := recover(); value != nil {
+			log.Printf("Shadow chain panicked: %v", value)
+			outcome = panicOutcome
+		}
+	}()
+	return call(ctx)
+}
+
+// compare counts a comparison, recording and logging a divergence
+func (s *ShadowChain) compare(method string, opType OperationType, did string, primary, shadow shadowOutcome) {
+	if primary.kind == shadow.kind {
+		s.count(method, ShadowMatch)
+		return
+	}
+
+	divergence := ShadowDivergence{
+		Method:    method,
+		Operation: opType,
+		DID:       did,
+		Primary:   primary.String(),
+		Shadow:    shadow.String(),
+		At:        time.Now().UTC(),
+	}
+	log.Printf("Shadow chain diverged on %s %s %s: primary %s, shadow %s", method, opType, did, divergence.Primary, divergence.Shadow)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[[2]string{method, ShadowDiverged}]++
+	if len(s.divergences) == maxShadowDivergences {
+		s.divergences = append(s.divergences[:0], s.divergences[1:]...)
+	}
+	s.divergences = append(s.divergences, divergence)
+}
+
+func (s *ShadowChain) count(method, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[[2]string{method, result}]++
+}
+
+func (s *ShadowChain) rememberTransaction(did, txID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.transactions[did]; !ok && len(s.transactions) >= maxShadowTransactions {
+		// Forget an arbitrary DID; verifying it on the shadow may then diverge
+		for forgotten := range s.transactions {
+			delete(s.transactions, forgotten)
+			break
+		}
+	}
+	s.transactions[did] = txID
+}
+
+func (s *ShadowChain) transaction(did string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.transactions[did]
+}
+
+// submitOutcome is the outcome of a submission: whether the operation was anchored
+func submitOutcome(txID string, err error) shadowOutcome {
+	if err != nil {
+		return errorOutcome(err)
+	}
+	return shadowOutcome{kind: "anchored", detail: txID}
+}
+
+// simulationOutcome is the outcome of a simulation: whether it would succeed
+func simulationOutcome(simulation *Simulation, err error) shadowOutcome {
+	switch {
+	case err != nil:
+		return errorOutcome(err)
+	case simulation.Reverted:
+		return shadowOutcome{kind: "reverted", detail: simulation.RevertReason}
+	default:
+		return shadowOutcome{kind: "succeeded"}
+	}
+}
+
+// checkOutcome is the outcome of a verification: whether the DID is valid
+func checkOutcome(check *ChainCheck, err error) shadowOutcome {
+	switch {
+	case err != nil:
+		return errorOutcome(err)
+	case check.Valid:
+		return shadowOutcome{kind: "valid"}
+	default:
+		return shadowOutcome{kind: "invalid"}
+	}
+}
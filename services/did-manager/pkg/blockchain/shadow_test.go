@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeChain answers with fixed outcomes, recording the DIDs it verified and their
+// transactions
+type fakeChain struct {
+	txID      string
+	submitErr error
+	reverted  bool
+	valid     bool
+	block     chan struct{}
+
+	mu       sync.Mutex
+	verified map[string]string
+}
+
+func (f *fakeChain) Submit(ctx context.Context, op *Operation) (string, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	if f.submitErr != nil {
+		return "", f.submitErr
+	}
+	return f.txID, nil
+}
+
+func (f *fakeChain) Simulate(op *Operation) (*Simulation, error) {
+	return &Simulation{Reverted: f.reverted}, nil
+}
+
+func (f *fakeChain) Verify(did, txID string) (*ChainCheck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.verified == nil {
+		f.verified = map[string]string{}
+	}
+	f.verified[did] = txID
+	return &ChainCheck{Valid: f.valid}, nil
+}
+
+func (f *fakeChain) Confirmations(string, uint64) (uint64, bool, error) {
+	return 1, true, nil
+}
+
+func (f *fakeChain) Anchoring(string) (*Anchoring, error) {
+	return nil, nil
+}
+
+// count returns the comparisons of a method with a result
+func count(stats *ShadowStats, method, result string) uint64 {
+	for _, c := range stats.Comparisons {
+		if c.Method == method && c.Result == result {
+			return c.Count
+		}
+	}
+	return 0
+}
+
+func drain(t *testing.T, chain *ShadowChain) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := chain.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+}
+
+func TestShadowChainReturnsPrimaryResults(t *testing.T) {
+	primary := &fakeChain{txID: "0xprimary", valid: true}
+	shadow := &fakeChain{submitErr: errors.New("topic not found"), reverted: true}
+	chain := NewShadowChain(primary, shadow, ShadowConfig{})
+
+	op := &Operation{Type: OperationRegisterDID, DID: "did:example:1"}
+	txID, err := chain.Submit(context.Background(), op)
+	if err != nil || txID != "0xprimary" {
+		t.Fatalf("expected the primary's transaction, got %q, %v", txID, err)
+	}
+	simulation, err := chain.Simulate(op)
+	if err != nil || simulation.Reverted {
+		t.Fatalf("expected the primary's simulation, got %+v, %v", simulation, err)
+	}
+	drain(t, chain)
+
+	stats := chain.Stats()
+	if count(stats, ShadowMethodSubmit, ShadowDiverged) != 1 || count(stats, ShadowMethodSimulate, ShadowDiverged) != 1 {
+		t.Errorf("expected submit and simulate to diverge, got %+v", stats.Comparisons)
+	}
+	if len(stats.Divergences) != 2 {
+		t.Fatalf("expected two divergences, got %d", len(stats.Divergences))
+	}
+	divergence := stats.Divergences[0]
+	if divergence.Method != ShadowMethodSubmit || divergence.DID != "did:example:1" || divergence.Primary != "anchored: 0xprimary" || divergence.Shadow != "failed: topic not found" {
+		t.Errorf("unexpected divergence %+v", divergence)
+	}
+}
+
+func TestShadowChainVerifiesWithShadowTransaction(t *testing.T) {
+	primary := &fakeChain{txID: "0xprimary", valid: true}
+	shadow := &fakeChain{txID: "0.0.5678@1700000000.000000000", valid: true}
+	chain := NewShadowChain(primary, shadow, ShadowConfig{})
+
+	if _, err := chain.Submit(context.Background(), &Operation{Type: OperationRegisterDID, DID: "did:example:1"}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	drain(t, chain)
+	if _, err := chain.Verify("did:example:1", "0xprimary"); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	drain(t, chain)
+
+	if primary.verified["did:example:1"] != "0xprimary" || shadow.verified["did:example:1"] != shadow.txID {
+		t.Errorf("expected each chain to verify with its own transaction, got %v and %v", primary.verified, shadow.verified)
+	}
+	stats := chain.Stats()
+	if count(stats, ShadowMethodSubmit, ShadowMatch) != 1 || count(stats, ShadowMethodVerify, ShadowMatch) != 1 || len(stats.Divergences) != 0 {
+		t.Errorf("expected matches only, got %+v", stats)
+	}
+}
+
+func TestShadowChainSkipsWhenBusy(t *testing.T) {
+	shadow := &fakeChain{block: make(chan struct{})}
+	chain := NewShadowChain(&fakeChain{}, shadow, ShadowConfig{MaxInFlight: 1})
+
+	op := &Operation{Type: OperationRevokeDID, DID: "did:example:1"}
+	if _, err := chain.Submit(context.Background(), op); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	// The first shadow submission still blocks, so the second is not replayed
+	if _, err := chain.Submit(context.Background(), op); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if stats := chain.Stats(); stats.InFlight != 1 || count(stats, ShadowMethodSubmit, ShadowSkipped) != 1 {
+		t.Errorf("expected one call in flight and one skipped, got %+v", stats)
+	}
+
+	close(shadow.block)
+	drain(t, chain)
+	if count(chain.Stats(), ShadowMethodSubmit, ShadowMatch) != 1 {
+		t.Errorf("expected the replayed submission to match, got %+v", chain.Stats().Comparisons)
+	}
+}
@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// sidetreeAnchorABI covers the SidetreeAnchor functions used by the service
+const sidetreeAnchorABI = `[
+	{
+		"inputs": [
+			{"name": "anchorFileHash", "type": "bytes32"},
+			{"name": "numberOfOperations", "type": "uint256"}
+		],
+		"name": "anchorHash",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
+
+// SetSidetreeAnchor configures the address of the SidetreeAnchor contract
+func (e *EthereumClient) SetSidetreeAnchor(address string) {
+	e.sidetreeAnchor = common.HexToAddress(address)
+}
+
+// SidetreeAnchor returns the configured SidetreeAnchor address, or an empty string
+// when none is configured
+func (e *EthereumClient) SidetreeAnchor() string {
+	if e.sidetreeAnchor == (common.Address{}) {
+		return ""
+	}
+	return e.sidetreeAnchor.Hex()
+}
+
+// AnchorSidetreeBatch anchors a batch of DID operations by the digest of its core
+// index file
+func (e *EthereumClient) AnchorSidetreeBatch(ctx context.Context, anchorFileHash [32]byte, operationCount int) (string, error) {
+	if e.sidetreeAnchor == (common.Address{}) {
+		return "", fmt.Errorf("sidetree anchor contract is not configured")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(sidetreeAnchorABI))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	// Encode function call
+	data, err := parsedABI.Pack("anchorHash", anchorFileHash, big.NewInt(int64(operationCount)))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack function call: %w", err)
+	}
+
+	tx, err := e.sendTransaction(ctx, e.sidetreeAnchor, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return tx.Hash().Hex(), nil
+}
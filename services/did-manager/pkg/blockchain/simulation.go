@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Simulation is the outcome of simulating a transaction with eth_call and gas
+// estimation instead of submitting it
+type Simulation struct {
+	// GasEstimate is zero when the call reverted
+	GasEstimate uint64
+	// GasLimit is the limit the transaction would be submitted with
+	GasLimit     uint64
+	GasPrice     *big.Int
+	Reverted     bool
+	RevertReason string
+}
+
+// Simulate simulates anchoring an operation in the DID registry contract without
+// submitting a transaction
+func (e *EthereumClient) Simulate(op *Operation) (*Simulation, error) {
+	data, err := packOperation(op)
+	if err != nil {
+		return nil, err
+	}
+	return e.simulateTransaction(e.contract, data)
+}
+
+// simulateTransaction executes a call from the service account against the latest
+// block and estimates its gas. No gas price is set, so the account needs no funds.
+func (e *EthereumClient) simulateTransaction(to common.Address, data []byte) (*Simulation, error) {
+	if e.privateKey == nil {
+		return nil, ErrReadOnly
+	}
+	simulation := &Simulation{
+		GasLimit: e.gasLimit,
+		GasPrice: e.fees.GasPrice(),
+	}
+	msg := ethereum.CallMsg{
+		From: e.address,
+		To:   &to,
+		Data: data,
+	}
+
+	if _, err := e.client.CallContract(context.Background(), msg, nil); err != nil {
+		if !isRevert(err) {
+			return nil, fmt.Errorf("failed to call contract: %w", err)
+		}
+		simulation.Reverted = true
+		simulation.RevertReason = err.Error()
+		return simulation, nil
+	}
+
+	gas, err := e.client.EstimateGas(context.Background(), msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	simulation.GasEstimate = gas
+
+	return simulation, nil
+}
+
+// isRevert reports whether a call failed because the contract reverted, as opposed to
+// the node being unreachable
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
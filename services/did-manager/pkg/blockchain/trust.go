@@ -0,0 +1,119 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUntrusted is returned when the chain or a contract does not match the trust
+// configuration, and for transactions to contracts it does not pin
+var ErrUntrusted = errors.New("blockchain does not match the trust configuration")
+
+// TrustConfig pins the chains and contracts the client may submit transactions to,
+// guarding against a misconfigured or poisoned RPC URL or contract address
+type TrustConfig struct {
+	// ChainIDs are the chain IDs the node may report; any chain when empty
+	ChainIDs []uint64 `json:"chain_ids"`
+	// Contracts maps contract addresses to the keccak256 hash of their deployed
+	// bytecode. When set, transactions are only sent to these contracts.
+	Contracts map[string]string `json:"contracts"`
+}
+
+// ParseTrustConfig decodes a JSON trust configuration. With a public key, the config
+// must carry a valid Ed25519 signature by it over the exact bytes of data.
+func ParseTrustConfig(data, signature []byte, publicKey ed25519.PublicKey) (*TrustConfig, error) {
+	if publicKey != nil {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: malformed trust configuration public key", ErrUntrusted)
+		}
+		if !ed25519.Verify(publicKey, data, signature) {
+			return nil, fmt.Errorf("%w: invalid trust configuration signature", ErrUntrusted)
+		}
+	}
+
+	var config TrustConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode trust configuration: %w", err)
+	}
+	for address, codeHash := range config.Contracts {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid contract address %q in trust configuration", address)
+		}
+		if len(strings.TrimPrefix(codeHash, "0x")) != 2*common.HashLength {
+			return nil, fmt.Errorf("invalid code hash for %s in trust configuration", address)
+		}
+	}
+	return &config, nil
+}
+
+// VerifyTrust checks the chain ID reported by the node and the code deployed at every
+// configured contract against a trust configuration. Once verified, the client refuses
+// to send transactions to contracts the configuration does not pin.
+func (e *EthereumClient) VerifyTrust(ctx context.Context, config *TrustConfig) error {
+	chainID, err := e.client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if len(config.ChainIDs) > 0 {
+		trusted := false
+		for _, id := range config.ChainIDs {
+			if chainID.IsUint64() && chainID.Uint64() == id {
+				trusted = true
+			}
+		}
+		if !trusted {
+			return fmt.Errorf("%w: chain ID %s is not pinned", ErrUntrusted, chainID)
+		}
+	}
+	if chainID.Cmp(e.chainID) != 0 {
+		return fmt.Errorf("%w: chain ID %s differs from network ID %s", ErrUntrusted, chainID, e.chainID)
+	}
+
+	if len(config.Contracts) == 0 {
+		return nil
+	}
+
+	pinned := make(map[common.Address]common.Hash, len(config.Contracts))
+	for address, codeHash := range config.Contracts {
+		pinned[common.HexToAddress(address)] = common.HexToHash(codeHash)
+	}
+	for _, address := range []common.Address{e.contract, e.revocationRegistry, e.sidetreeAnchor} {
+		if address == (common.Address{}) {
+			continue
+		}
+		want, ok := pinned[address]
+		if !ok {
+			return fmt.Errorf("%w: contract %s is not pinned", ErrUntrusted, address.Hex())
+		}
+		code, err := e.client.CodeAt(ctx, address, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get code of %s: %w", address.Hex(), err)
+		}
+		if got := crypto.Keccak256Hash(code); got != want {
+			return fmt.Errorf("%w: code hash of %s is %s, expected %s", ErrUntrusted, address.Hex(), got.Hex(), want.Hex())
+		}
+	}
+
+	e.trusted = pinned
+	return nil
+}
+
+// checkTrusted refuses transactions to contracts outside a verified trust configuration
+func (e *EthereumClient) checkTrusted(to common.Address) error {
+	if e.trusted == nil {
+		return nil
+	}
+	if _, ok := e.trusted[to]; !ok {
+		log.Printf("ALERT: critical: refusing to submit a transaction to %s, which the trust configuration does not pin", to.Hex())
+		return fmt.Errorf("%w: contract %s is not pinned", ErrUntrusted, to.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package blockchain
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const trustConfig = `{"chain_ids":[11155111],"contracts":{"0x1234567890123456789012345678901234567890":"0x` +
+	`c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"}}`
+
+func TestParseTrustConfig(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := ed25519.Sign(privateKey, []byte(trustConfig))
+
+	config, err := ParseTrustConfig([]byte(trustConfig), signature, publicKey)
+	if err != nil {
+		t.Fatalf("signed configuration rejected: %v", err)
+	}
+	if len(config.ChainIDs) != 1 || config.ChainIDs[0] != 11155111 || len(config.Contracts) != 1 {
+		t.Errorf("unexpected configuration %+v", config)
+	}
+
+	tampered := []byte(trustConfig[:len(trustConfig)-3] + `71"}}`)
+	if _, err := ParseTrustConfig(tampered, signature, publicKey); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("expected a tampered configuration to be untrusted, got %v", err)
+	}
+
+	if _, err := ParseTrustConfig([]byte(trustConfig), nil, nil); err != nil {
+		t.Errorf("unsigned configuration rejected without a public key: %v", err)
+	}
+
+	if _, err := ParseTrustConfig([]byte(`{"contracts":{"0x1234":"0x00"}}`), nil, nil); err == nil {
+		t.Error("expected an invalid contract address to be rejected")
+	}
+}
+
+func TestCheckTrusted(t *testing.T) {
+	pinned := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	client := &EthereumClient{}
+	if err := client.checkTrusted(common.HexToAddress("0x01")); err != nil {
+		t.Errorf("expected any contract to be allowed without a trust configuration, got %v", err)
+	}
+
+	client.trusted = map[common.Address]common.Hash{pinned: {}}
+	if err := client.checkTrusted(pinned); err != nil {
+		t.Errorf("pinned contract refused: %v", err)
+	}
+	if err := client.checkTrusted(common.HexToAddress("0x01")); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("expected an unpinned contract to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package challenge verifies anti-automation challenges, such as CAPTCHAs and proofs
+// of work, solved by clients before they may call costly public endpoints
+package challenge
+
+import (
+	"context"
+	"errors"
+)
+
+// Challenge errors
+var (
+	// ErrMissing is returned when a request carries no challenge response
+	ErrMissing = errors.New("challenge response required")
+	// ErrFailed is returned when a challenge response is invalid, expired or reused
+	ErrFailed = errors.New("challenge verification failed")
+)
+
+// Verifier checks the response a client gives to an anti-automation challenge
+type Verifier interface {
+	// Name identifies the provider, so clients know which challenge to solve
+	Name() string
+	// Verify returns nil when response solves a challenge, wrapping ErrMissing or
+	// ErrFailed otherwise; remoteIP is the client's address, for providers that check it
+	Verify(ctx context.Context, response, remoteIP string) error
+}
+
+// Issuer is implemented by verifiers whose challenges the service hands out itself,
+// rather than a third-party widget
+type Issuer interface {
+	// Issue returns a new challenge for a client to solve
+	Issue() (any, error)
+}
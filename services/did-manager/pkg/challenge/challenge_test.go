@@ -0,0 +1,131 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// solve finds a nonce for a proof-of-work challenge
+func solve(t *testing.T, challenge *ProofOfWorkChallenge) string {
+	t.Helper()
+	for nonce := 0; nonce < 1<<24; nonce++ {
+		response := challenge.Challenge + ":" + strconv.Itoa(nonce)
+		if leadingZeroBits(sha256.Sum256([]byte(response))) >= challenge.Difficulty {
+			return response
+		}
+	}
+	t.Fatal("no nonce found")
+	return ""
+}
+
+func newTestProofOfWork(t *testing.T) *ProofOfWork {
+	t.Helper()
+	pow, err := NewProofOfWork([]byte(strings.Repeat("k", 32)), 8, time.Minute)
+	if err != nil {
+		t.Fatalf("NewProofOfWork failed: %v", err)
+	}
+	return pow
+}
+
+func TestProofOfWork(t *testing.T) {
+	pow := newTestProofOfWork(t)
+	now := time.Now()
+
+	challenge, err := pow.issue(now)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	response := solve(t, challenge)
+
+	if err := pow.verify(response, now); err != nil {
+		t.Fatalf("expected solved challenge to verify: %v", err)
+	}
+	if err := pow.verify(response, now); !errors.Is(err, ErrFailed) {
+		t.Errorf("expected reused challenge to fail, got %v", err)
+	}
+}
+
+func TestProofOfWorkRejectsInvalidResponses(t *testing.T) {
+	pow := newTestProofOfWork(t)
+	now := time.Now()
+
+	challenge, err := pow.issue(now)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	response := solve(t, challenge)
+
+	other := newTestProofOfWork(t)
+	other.key = []byte(strings.Repeat("o", 32))
+	tampered := strings.Replace(response, ".8.", ".1.", 1)
+
+	tests := []struct {
+		name     string
+		verifier *ProofOfWork
+		response string
+		now      time.Time
+		want     error
+	}{
+		{"missing", pow, "", now, ErrMissing},
+		{"no nonce", pow, challenge.Challenge, now, ErrFailed},
+		{"other key", other, response, now, ErrFailed},
+		{"lowered difficulty", pow, tampered, now, ErrFailed},
+		{"expired", pow, response, now.Add(2 * time.Minute), ErrFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.verifier.verify(tt.response, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewProofOfWorkValidatesConfiguration(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	if _, err := NewProofOfWork(key[:16], 8, time.Minute); err == nil {
+		t.Error("expected short key to be rejected")
+	}
+	if _, err := NewProofOfWork(key, maxDifficulty+1, time.Minute); err == nil {
+		t.Error("expected excessive difficulty to be rejected")
+	}
+}
+
+func TestSiteVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm failed: %v", err)
+		}
+		if r.PostForm.Get("secret") != "site-secret" {
+			t.Errorf("unexpected secret %q", r.PostForm.Get("secret"))
+		}
+		if r.PostForm.Get("response") == "valid" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	verifier, err := NewSiteVerify("turnstile", server.URL, "site-secret")
+	if err != nil {
+		t.Fatalf("NewSiteVerify failed: %v", err)
+	}
+
+	if err := verifier.Verify(context.Background(), "valid", "203.0.113.7"); err != nil {
+		t.Errorf("expected valid token to verify: %v", err)
+	}
+	if err := verifier.Verify(context.Background(), "invalid", ""); !errors.Is(err, ErrFailed) {
+		t.Errorf("expected invalid token to fail, got %v", err)
+	}
+	if err := verifier.Verify(context.Background(), "", ""); !errors.Is(err, ErrMissing) {
+		t.Errorf("expected missing token to be reported, got %v", err)
+	}
+}
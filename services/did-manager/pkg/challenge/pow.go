@@ -0,0 +1,161 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDifficulty bounds proof-of-work difficulty, in leading zero bits
+const maxDifficulty = 32
+
+// ProofOfWorkChallenge is a proof-of-work challenge handed to a client. The client
+// finds a nonce such that SHA-256("<challenge>:<nonce>") starts with Difficulty zero
+// bits, and responds with "<challenge>:<nonce>".
+type ProofOfWorkChallenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ProofOfWork issues and verifies hashcash-style proof-of-work challenges. Challenges
+// are stateless, authenticated with an HMAC key, and each is accepted once. Solved
+// challenges are remembered in memory until they expire, so instances sharing the key
+// do not see each other's.
+type ProofOfWork struct {
+	key        []byte
+	difficulty int
+	ttl        time.Duration
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewProofOfWork creates a proof-of-work verifier issuing challenges of difficulty
+// leading zero bits, valid for ttl
+func NewProofOfWork(key []byte, difficulty int, ttl time.Duration) (*ProofOfWork, error) {
+	if len(key) < 32 {
+		return nil, fmt.Errorf("invalid proof-of-work configuration: key must be at least 32 bytes")
+	}
+	if difficulty < 1 || difficulty > maxDifficulty {
+		return nil, fmt.Errorf("invalid proof-of-work configuration: difficulty must be between 1 and %d", maxDifficulty)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid proof-of-work configuration: challenge lifetime must be positive")
+	}
+	return &ProofOfWork{
+		key:        key,
+		difficulty: difficulty,
+		ttl:        ttl,
+		used:       make(map[string]time.Time),
+	}, nil
+}
+
+// Name identifies the provider
+func (p *ProofOfWork) Name() string {
+	return "pow"
+}
+
+// Issue returns a new challenge of the configured difficulty
+func (p *ProofOfWork) Issue() (any, error) {
+	return p.issue(time.Now())
+}
+
+func (p *ProofOfWork) issue(now time.Time) (*ProofOfWorkChallenge, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	expiresAt := now.Add(p.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%d.%d.%s", expiresAt.Unix(), p.difficulty, hex.EncodeToString(random))
+	return &ProofOfWorkChallenge{
+		Challenge:  payload + "." + p.mac(payload),
+		Difficulty: p.difficulty,
+		ExpiresAt:  expiresAt.UTC(),
+	}, nil
+}
+
+// Verify checks a "<challenge>:<nonce>" response
+func (p *ProofOfWork) Verify(ctx context.Context, response, remoteIP string) error {
+	return p.verify(response, time.Now())
+}
+
+func (p *ProofOfWork) verify(response string, now time.Time) error {
+	if response == "" {
+		return ErrMissing
+	}
+
+	challenge, nonce, ok := strings.Cut(response, ":")
+	if !ok || nonce == "" {
+		return fmt.Errorf("%w: expected <challenge>:<nonce>", ErrFailed)
+	}
+
+	// <expires>.<difficulty>.<random>.<mac>
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 4 {
+		return fmt.Errorf("%w: malformed challenge", ErrFailed)
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(p.mac(payload))) {
+		return fmt.Errorf("%w: challenge was not issued by this service", ErrFailed)
+	}
+
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed challenge", ErrFailed)
+	}
+	expiresAt := time.Unix(expires, 0)
+	if !now.Before(expiresAt) {
+		return fmt.Errorf("%w: challenge expired", ErrFailed)
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: malformed challenge", ErrFailed)
+	}
+	if leadingZeroBits(sha256.Sum256([]byte(response))) < difficulty {
+		return fmt.Errorf("%w: insufficient work", ErrFailed)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for used, usedExpiry := range p.used {
+		if !now.Before(usedExpiry) {
+			delete(p.used, used)
+		}
+	}
+	if _, reused := p.used[challenge]; reused {
+		return fmt.Errorf("%w: challenge already used", ErrFailed)
+	}
+	p.used[challenge] = expiresAt
+
+	return nil
+}
+
+// mac authenticates a challenge payload
+func (p *ProofOfWork) mac(payload string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// leadingZeroBits counts the zero bits a hash starts with
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
@@ -0,0 +1,252 @@
+// Package client is a Go client for the DID Manager API. Listings are iterators that
+// fetch one page after another, filters are typed, and every request waits on a rate
+// limiter that honors the caller's context and the server's Retry-After, so services
+// consuming the API do not each write their own paging and throttling loops.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is the number of results fetched per page by listings
+const DefaultPageSize = 100
+
+// maxRetries bounds how many times a request is retried after a 429 response
+const maxRetries = 3
+
+// Signer signs the payload of a DID-authenticated request with the caller DID's key
+type Signer func(payload []byte) ([]byte, error)
+
+// Client calls the DID Manager API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	limiter    *Limiter
+	pageSize   int
+
+	apiKey    string
+	adminKey  string
+	callerDID string
+	signer    Signer
+
+	// profile is the profile the client was created from; nil without one
+	profile *Profile
+}
+
+// Option configures a client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates requests as a relying party with an API key
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithAdminKey sends the admin key needed by the admin listings, ListDIDs and ListJobs
+func WithAdminKey(adminKey string) Option {
+	return func(c *Client) {
+		c.adminKey = adminKey
+	}
+}
+
+// WithCallerDID authenticates requests as a DID, signing each with sign. Credential
+// listings require a DID-authenticated issuer.
+func WithCallerDID(did string, sign Signer) Option {
+	return func(c *Client) {
+		c.callerDID = did
+		c.signer = sign
+	}
+}
+
+// WithRateLimit limits requests to rps per second with bursts of up to burst requests
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = NewLimiter(rps, burst)
+	}
+}
+
+// WithPageSize sets the number of results fetched per page by listings, at most 200
+func WithPageSize(size int) Option {
+	return func(c *Client) {
+		c.pageSize = min(max(size, 1), 200)
+	}
+}
+
+// New creates a client for the API at baseURL, such as http://localhost:8081
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    NewLimiter(0, 0),
+		pageSize:   DefaultPageSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    string
+}
+
+func (e *APIError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("did-manager: %d %s: %s", e.StatusCode, e.Message, e.Details)
+	}
+	return fmt.Sprintf("did-manager: %d %s", e.StatusCode, e.Message)
+}
+
+// get fetches path and decodes the data of a successful response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.call(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// call sends a request with an optional JSON body and decodes the data of a successful
+// response into out. Requests refused with 429 are retried once the server's
+// Retry-After has passed.
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := c.do(ctx, method, path, query, body, out)
+		var limited *rateLimitedError
+		if !errors.As(err, &limited) {
+			return err
+		}
+		if attempt == maxRetries {
+			return limited.APIError
+		}
+		c.limiter.Block(limited.retryAfter)
+	}
+}
+
+// rateLimitedError is a 429 response, with how long the server asked to wait
+type rateLimitedError struct {
+	*APIError
+	retryAfter time.Duration
+}
+
+// do sends a single request
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := c.authenticate(req); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var errorBody struct {
+			Error   string `json:"error"`
+			Details string `json:"details"`
+		}
+		json.Unmarshal(respBody, &errorBody)
+		if errorBody.Error == "" {
+			errorBody.Error = http.StatusText(resp.StatusCode)
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: errorBody.Error, Details: errorBody.Details}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &rateLimitedError{APIError: apiErr, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return apiErr
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// authenticate adds the configured credentials to a request. A caller DID signs the
+// method, path and Unix timestamp of the request.
+func (c *Client) authenticate(req *http.Request) error {
+	if c.adminKey != "" {
+		req.Header.Set("X-Admin-Key", c.adminKey)
+	}
+
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("X-API-Key", c.apiKey)
+	case c.callerDID != "":
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		signature, err := c.signer([]byte(req.Method + " " + req.URL.Path + "\n" + timestamp))
+		if err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+		req.Header.Set("X-Caller-DID", c.callerDID)
+		req.Header.Set("X-Caller-Timestamp", timestamp)
+		req.Header.Set("X-Caller-Signature", hex.EncodeToString(signature))
+	}
+	return nil
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or as an HTTP date, defaulting
+// to a second
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return time.Second
+}
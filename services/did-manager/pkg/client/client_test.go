@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serveDIDs serves total DIDs from the admin listing, recording the queries received
+func serveDIDs(t *testing.T, total int, queries chan<- string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Admin-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid admin key"})
+			return
+		}
+		if queries != nil {
+			queries <- r.URL.RawQuery
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		dids := []map[string]any{}
+		for i := (page - 1) * limit; i < min(page*limit, total); i++ {
+			dids = append(dids, map[string]any{"did": fmt.Sprintf("did:example:%d", i)})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"success": true,
+			"data":    map[string]any{"dids": dids, "total": total, "page": page, "limit": limit},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListDIDsPaginates(t *testing.T) {
+	queries := make(chan string, 10)
+	server := serveDIDs(t, 5, queries)
+	c := New(server.URL, WithAdminKey("secret"), WithPageSize(2))
+
+	var got []string
+	for did, err := range c.ListDIDs(context.Background(), DIDFilter{Status: "active", OldestFirst: true}) {
+		if err != nil {
+			t.Fatalf("ListDIDs failed: %v", err)
+		}
+		got = append(got, did.DID)
+	}
+
+	if len(got) != 5 || got[4] != "did:example:4" {
+		t.Fatalf("expected 5 DIDs in order, got %v", got)
+	}
+	close(queries)
+	var pages int
+	for query := range queries {
+		pages++
+		want := fmt.Sprintf("limit=2&page=%d&sort=created_at&status=active", pages)
+		if query != want {
+			t.Errorf("page %d: expected query %q, got %q", pages, want, query)
+		}
+	}
+	if pages != 3 {
+		t.Errorf("expected 3 pages, fetched %d", pages)
+	}
+}
+
+func TestListDIDsStopsEarly(t *testing.T) {
+	queries := make(chan string, 10)
+	server := serveDIDs(t, 10, queries)
+	c := New(server.URL, WithAdminKey("secret"), WithPageSize(2))
+
+	for _, err := range c.ListDIDs(context.Background(), DIDFilter{}) {
+		if err != nil {
+			t.Fatalf("ListDIDs failed: %v", err)
+		}
+		break
+	}
+	if len(queries) != 1 {
+		t.Errorf("expected a single page to be fetched, got %d", len(queries))
+	}
+}
+
+func TestListDIDsYieldsAPIError(t *testing.T) {
+	server := serveDIDs(t, 1, nil)
+	c := New(server.URL, WithAdminKey("wrong"))
+
+	for _, err := range c.ListDIDs(context.Background(), DIDFilter{}) {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected a 401 API error, got %v", err)
+		}
+	}
+}
+
+func TestRetriesAfterTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"jobs":[{"id":"job-1"}],"total":1}}`)
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	var jobs int
+	for job, err := range c.ListJobs(context.Background(), JobFilter{}) {
+		if err != nil {
+			t.Fatalf("ListJobs failed: %v", err)
+		}
+		if job.ID != "job-1" {
+			t.Errorf("unexpected job %q", job.ID)
+		}
+		jobs++
+	}
+	if jobs != 1 || calls.Load() != 2 {
+		t.Errorf("expected one job after a retry, got %d jobs in %d calls", jobs, calls.Load())
+	}
+}
+
+func TestSignsCallerDIDRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "GET /api/v1/credentials/search\n" + r.Header.Get("X-Caller-Timestamp")
+		if r.Header.Get("X-Caller-DID") != "did:example:issuer" || r.Header.Get("X-Caller-Signature") != fmt.Sprintf("%x", want) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"credentials":[],"total":0}}`)
+	}))
+	defer server.Close()
+
+	// The test signer returns the payload itself
+	c := New(server.URL, WithCallerDID("did:example:issuer", func(payload []byte) ([]byte, error) {
+		return payload, nil
+	}))
+	for _, err := range c.ListCredentials(context.Background(), CredentialFilter{Type: "KYC"}) {
+		if err != nil {
+			t.Fatalf("ListCredentials failed: %v", err)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(1, 2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("burst request %d waited: %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the third request to wait past the deadline, got %v", err)
+	}
+
+	blocked := NewLimiter(0, 0)
+	blocked.Block(time.Hour)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := blocked.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a blocked limiter to wait, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket pacing requests. Waiting honors the context, and the
+// bucket can be blocked for the time a server asked clients to back off.
+type Limiter struct {
+	// rate is in tokens per second; zero leaves requests unpaced
+	rate  float64
+	burst float64
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewLimiter creates a limiter allowing rps requests per second with bursts of up to
+// burst requests. A rate of zero or less only applies backoffs set with Block.
+func NewLimiter(rps float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Block holds back every request for d, as asked by a 429 response's Retry-After
+func (l *Limiter) Block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// reserve takes a token, returning zero, or returns how long to wait before trying again
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
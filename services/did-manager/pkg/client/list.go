@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DID is a DID record as listed by administrators. The keys of custodial DIDs are
+// left out.
+type DID struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	DID          string    `json:"did"`
+	UserHash     string    `json:"user_hash"`
+	HashScheme   string    `json:"hash_scheme"`
+	PublicKey    string    `json:"public_key"`
+	KeyAlgorithm string    `json:"key_algorithm"`
+	Custodial    bool      `json:"custodial"`
+	Status       string    `json:"status"`
+	Visibility   string    `json:"visibility"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	BlockchainTx string    `json:"blockchain_tx"`
+}
+
+// Job is a blockchain job anchoring or revoking a DID
+type Job struct {
+	ID          string     `json:"id"`
+	JobType     string     `json:"job_type"`
+	DIDID       string     `json:"did_id"`
+	DID         string     `json:"did"`
+	Status      string     `json:"status"`
+	RetryCount  int        `json:"retry_count"`
+	MaxRetries  int        `json:"max_retries"`
+	Error       string     `json:"error"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ProcessedAt *time.Time `json:"processed_at"`
+	TxHash      string     `json:"tx_hash,omitempty"`
+	DryRun      bool       `json:"dry_run"`
+	TraceID     string     `json:"trace_id,omitempty"`
+}
+
+// Credential is a credential issued by the calling DID
+type Credential struct {
+	ID         string     `json:"id"`
+	IssuerDID  string     `json:"issuer_did"`
+	SubjectDID string     `json:"subject_did"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	IssuedAt   time.Time  `json:"issued_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Tenant     string     `json:"tenant,omitempty"`
+	// Document is the signed credential
+	Document json.RawMessage `json:"document"`
+}
+
+// DIDFilter selects the DIDs listed; zero fields match everything
+type DIDFilter struct {
+	Status string
+	UserID string
+	// Method is a DID method such as example or key
+	Method        string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// OldestFirst lists DIDs in creation order instead of newest first
+	OldestFirst bool
+}
+
+func (f DIDFilter) query() url.Values {
+	q := url.Values{}
+	setString(q, "status", f.Status)
+	setString(q, "user_id", f.UserID)
+	setString(q, "method", f.Method)
+	setTime(q, "created_after", f.CreatedAfter)
+	setTime(q, "created_before", f.CreatedBefore)
+	setSort(q, "created_at", f.OldestFirst)
+	return q
+}
+
+// JobFilter selects the blockchain jobs listed; zero fields match everything
+type JobFilter struct {
+	Status string
+	// JobType is register_did, update_did, revoke_did or notarize
+	JobType       string
+	DID           string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// OldestFirst lists jobs in creation order instead of newest first
+	OldestFirst bool
+}
+
+func (f JobFilter) query() url.Values {
+	q := url.Values{}
+	setString(q, "status", f.Status)
+	setString(q, "job_type", f.JobType)
+	setString(q, "did", f.DID)
+	setTime(q, "created_after", f.CreatedAfter)
+	setTime(q, "created_before", f.CreatedBefore)
+	setSort(q, "created_at", f.OldestFirst)
+	return q
+}
+
+// CredentialFilter selects the credentials listed; zero fields match everything
+type CredentialFilter struct {
+	Type       string
+	SubjectDID string
+	Tenant     string
+	// Status is active or revoked
+	Status        string
+	IssuedAfter   time.Time
+	IssuedBefore  time.Time
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+	// OldestFirst lists credentials in issuance order instead of newest first
+	OldestFirst bool
+}
+
+func (f CredentialFilter) query() url.Values {
+	q := url.Values{}
+	setString(q, "type", f.Type)
+	setString(q, "subject_did", f.SubjectDID)
+	setString(q, "tenant", f.Tenant)
+	setString(q, "status", f.Status)
+	setTime(q, "issued_after", f.IssuedAfter)
+	setTime(q, "issued_before", f.IssuedBefore)
+	setTime(q, "expires_after", f.ExpiresAfter)
+	setTime(q, "expires_before", f.ExpiresBefore)
+	setSort(q, "issued_at", f.OldestFirst)
+	return q
+}
+
+// ListDIDs iterates over the DIDs matching filter. It needs the admin key.
+func (c *Client) ListDIDs(ctx context.Context, filter DIDFilter) iter.Seq2[*DID, error] {
+	return list[DID](ctx, c, "/api/v1/admin/dids", "dids", filter.query())
+}
+
+// ListJobs iterates over the blockchain jobs matching filter. It needs the admin key.
+func (c *Client) ListJobs(ctx context.Context, filter JobFilter) iter.Seq2[*Job, error] {
+	return list[Job](ctx, c, "/api/v1/admin/jobs", "jobs", filter.query())
+}
+
+// ListCredentials iterates over the credentials issued by the calling DID that match
+// filter. It needs a caller DID. The tenant defaults to the client profile's.
+func (c *Client) ListCredentials(ctx context.Context, filter CredentialFilter) iter.Seq2[*Credential, error] {
+	if filter.Tenant == "" && c.profile != nil {
+		filter.Tenant = c.profile.Tenant
+	}
+	return list[Credential](ctx, c, "/api/v1/credentials/search", "credentials", filter.query())
+}
+
+// list iterates over a paged listing, fetching the next page once the items of the
+// previous one were consumed. It stops at the first error, which it yields. Pages are
+// offsets into the results, so items created while iterating newest first shift later
+// pages and may be seen twice; list oldest first to avoid that.
+func list[T any](ctx context.Context, c *Client, path, field string, query url.Values) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		seen := 0
+		for page := 1; ; page++ {
+			query.Set("page", strconv.Itoa(page))
+			query.Set("limit", strconv.Itoa(c.pageSize))
+
+			var result map[string]json.RawMessage
+			if err := c.get(ctx, path, query, &result); err != nil {
+				yield(nil, err)
+				return
+			}
+			var items []*T
+			var total int
+			if err := json.Unmarshal(result[field], &items); err != nil {
+				yield(nil, err)
+				return
+			}
+			if err := json.Unmarshal(result["total"], &total); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			seen += len(items)
+			if len(items) < c.pageSize || seen >= total {
+				return
+			}
+		}
+	}
+}
+
+func setString(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func setTime(q url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		q.Set(key, value.UTC().Format(time.RFC3339))
+	}
+}
+
+func setSort(q url.Values, column string, ascending bool) {
+	if ascending {
+		q.Set("sort", column)
+	} else {
+		q.Set("sort", "-"+column)
+	}
+}
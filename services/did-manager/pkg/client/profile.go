@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ConfigEnv names the environment variable overriding the path of the config file
+const ConfigEnv = "DID_MANAGER_CONFIG"
+
+// Profile is a named DID Manager environment, such as dev, stage or prod, so tools
+// switch between deployments by name instead of by hand-copied URLs and keys
+type Profile struct {
+	// Name is the profile's key in the config file
+	Name     string `json:"-"`
+	URL      string `json:"url"`
+	APIKey   string `json:"api_key,omitempty"`
+	AdminKey string `json:"admin_key,omitempty"`
+	// Tenant is the tenant the profile acts for; credential listings default to it
+	Tenant string `json:"tenant,omitempty"`
+	// Chain names the chain the deployment anchors to, such as sepolia or polygon
+	Chain string `json:"chain,omitempty"`
+	// Protected marks environments, such as production, where tools confirm writes
+	Protected bool `json:"protected,omitempty"`
+}
+
+// Config is the profiles config file shared by the CLI and Go client:
+//
+//	{
+//	  "default_profile": "dev",
+//	  "profiles": {
+//	    "dev":  {"url": "http://localhost:8081", "admin_key": "...", "chain": "sepolia"},
+//	    "prod": {"url": "https://did.example.com", "api_key": "dm_...", "chain": "polygon", "protected": true}
+//	  }
+//	}
+type Config struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// ConfigPath returns the path of the config file: DID_MANAGER_CONFIG, or
+// did-manager/config.json in the user's config directory
+func ConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "did-manager", "config.json"), nil
+}
+
+// LoadConfig reads the config file at path. A missing file is an empty config.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	for name, profile := range config.Profiles {
+		if profile == nil || profile.URL == "" {
+			return nil, fmt.Errorf("profile %q in %s has no url", name, path)
+		}
+		profile.Name = name
+	}
+	if config.DefaultProfile != "" && config.Profiles[config.DefaultProfile] == nil {
+		return nil, fmt.Errorf("default profile %q is not in %s", config.DefaultProfile, path)
+	}
+	return &config, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty. It
+// returns nil without an error when neither is set.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for known := range c.Profiles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q, known profiles: %v", name, names)
+	}
+	return profile, nil
+}
+
+// NewFromProfile creates a client for the environment of a profile, authenticated
+// with its keys. opts are applied after the profile's.
+func NewFromProfile(profile *Profile, opts ...Option) *Client {
+	profileOpts := []Option{func(c *Client) {
+		c.profile = profile
+	}}
+	if profile.APIKey != "" {
+		profileOpts = append(profileOpts, WithAPIKey(profile.APIKey))
+	}
+	if profile.AdminKey != "" {
+		profileOpts = append(profileOpts, WithAdminKey(profile.AdminKey))
+	}
+	return New(profile.URL, append(profileOpts, opts...)...)
+}
+
+// Profile returns the profile the client was created from, nil without one
+func (c *Client) Profile() *Profile {
+	return c.profile
+}
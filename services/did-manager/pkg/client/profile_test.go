@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a config file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestConfigProfiles(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, `{
+		"default_profile": "dev",
+		"profiles": {
+			"dev": {"url": "http://localhost:8081", "admin_key": "dev-admin"},
+			"prod": {"url": "https://did.example.com", "chain": "polygon", "protected": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	dev, err := config.Profile("")
+	if err != nil || dev.Name != "dev" || dev.AdminKey != "dev-admin" {
+		t.Fatalf("expected the default dev profile, got %+v, %v", dev, err)
+	}
+	prod, err := config.Profile("prod")
+	if err != nil || prod.Name != "prod" || prod.Chain != "polygon" || !prod.Protected {
+		t.Fatalf("expected the protected prod profile, got %+v, %v", prod, err)
+	}
+	if _, err := config.Profile("stage"); err == nil {
+		t.Fatal("expected an unknown profile to fail")
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if profile, err := config.Profile(""); profile != nil || err != nil {
+		t.Fatalf("expected no profile, got %+v, %v", profile, err)
+	}
+}
+
+func TestLoadConfigRejectsInvalidProfiles(t *testing.T) {
+	tests := map[string]string{
+		"missing url":             `{"profiles": {"dev": {"api_key": "dm_1"}}}`,
+		"unknown default profile": `{"default_profile": "prod", "profiles": {"dev": {"url": "http://localhost:8081"}}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadConfig(writeConfig(t, content)); err == nil {
+				t.Fatal("expected the config to be rejected")
+			}
+		})
+	}
+}
+
+func TestNewFromProfile(t *testing.T) {
+	server := serveDIDs(t, 1, nil)
+	c := NewFromProfile(&Profile{Name: "dev", URL: server.URL, AdminKey: "secret", Tenant: "tenant-1"})
+
+	if c.Profile().Name != "dev" {
+		t.Fatalf("expected the dev profile, got %+v", c.Profile())
+	}
+	for _, err := range c.ListDIDs(context.Background(), DIDFilter{}) {
+		if err != nil {
+			t.Fatalf("expected the profile's admin key to be sent, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Verification session statuses; verified, rejected and expired are final
+const (
+	SessionPending  = "pending"
+	SessionScanned  = "scanned"
+	SessionVerified = "verified"
+	SessionRejected = "rejected"
+	SessionExpired  = "expired"
+)
+
+// VerificationSession is a relying party's request for a wallet to authenticate with
+// its DID or present credentials
+type VerificationSession struct {
+	ID              string          `json:"id"`
+	Challenge       string          `json:"challenge"`
+	Domain          string          `json:"domain"`
+	CredentialTypes []string        `json:"credential_types"`
+	Status          string          `json:"status"`
+	HolderDID       string          `json:"holder_did,omitempty"`
+	Message         string          `json:"message,omitempty"`
+	Presentation    json.RawMessage `json:"presentation,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Final reports whether the session can no longer change
+func (s *VerificationSession) Final() bool {
+	return s.Status == SessionVerified || s.Status == SessionRejected || s.Status == SessionExpired
+}
+
+// VerificationSessionStart is a started session with what the wallet needs to join it
+type VerificationSessionStart struct {
+	Session *VerificationSession `json:"session"`
+	// QRPayload is the wallet deep link to render as a QR code or open on the same device
+	QRPayload  string `json:"qr_payload"`
+	QRImageURL string `json:"qr_image_url"`
+	EventsURL  string `json:"events_url"`
+}
+
+// CreateVerificationSession asks a wallet to present credentials of the given types
+// for domain, or only to prove control of its DID without types. It needs an API key
+// or caller DID, which the session is then bound to.
+func (c *Client) CreateVerificationSession(ctx context.Context, domain string, credentialTypes []string) (*VerificationSessionStart, error) {
+	req := map[string]any{"domain": domain, "credential_types": credentialTypes}
+	var start VerificationSessionStart
+	if err := c.call(ctx, http.MethodPost, "/api/v1/verification-sessions", nil, req, &start); err != nil {
+		return nil, err
+	}
+	return &start, nil
+}
+
+// GetVerificationSession returns the state of a session created by the same caller
+func (c *Client) GetVerificationSession(ctx context.Context, id string) (*VerificationSession, error) {
+	var session VerificationSession
+	if err := c.get(ctx, "/api/v1/verification-sessions/"+id, nil, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,159 @@
+// Package clock abstracts the time source of time-dependent behavior, such as
+// expirations, backoff and periodic jobs, so it can be tested deterministically and
+// shifted for simulations.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules ticks
+type Clock interface {
+	Now() time.Time
+	// NewTicker returns a ticker firing every d, which must be positive
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C until stopped, dropping ticks for slow receivers like
+// time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// System is the wall clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// Offset returns a clock telling the time of base shifted by offset, ahead when
+// positive, so expirations can be simulated without waiting for them. Ticks keep
+// base's pace.
+func Offset(base Clock, offset time.Duration) Clock {
+	if offset == 0 {
+		return base
+	}
+	return offsetClock{base: base, offset: offset}
+}
+
+type offsetClock struct {
+	base   Clock
+	offset time.Duration
+}
+
+func (c offsetClock) Now() time.Time {
+	return c.base.Now().Add(c.offset)
+}
+
+func (c offsetClock) NewTicker(d time.Duration) Ticker {
+	return c.base.NewTicker(d)
+}
+
+// Manual is a clock that only moves when set or advanced, firing the tickers due on
+// the way. It is safe for concurrent use.
+type Manual struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+}
+
+// NewManual creates a manual clock set to now
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+// Now returns the clock's time
+func (c *Manual) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker firing every d of the clock's time
+func (c *Manual) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &manualTicker{
+		clock:  c,
+		period: d,
+		next:   c.now.Add(d),
+		c:      make(chan time.Time, 1),
+	}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance moves the clock forward by d
+func (c *Manual) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to now. Moving it backwards fires no ticks.
+func (c *Manual) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(now)
+}
+
+// set moves the clock and fires the tickers due by now; the caller holds mu
+func (c *Manual) set(now time.Time) {
+	c.now = now
+	for _, ticker := range c.tickers {
+		for !ticker.next.After(now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+type manualTicker struct {
+	clock  *Manual
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, ticker := range t.clock.tickers {
+		if ticker == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualAdvance(t *testing.T) {
+	clk := NewManual(epoch)
+
+	clk.Advance(90 * time.Minute)
+	if got, want := clk.Now(), epoch.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+
+	clk.Set(epoch)
+	if got := clk.Now(); !got.Equal(epoch) {
+		t.Errorf("Now() after Set = %v, want %v", got, epoch)
+	}
+}
+
+func TestManualTicker(t *testing.T) {
+	clk := NewManual(epoch)
+	ticker := clk.NewTicker(time.Minute)
+
+	clk.Advance(30 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before its interval")
+	default:
+	}
+
+	clk.Advance(30 * time.Second)
+	select {
+	case tick := <-ticker.C():
+		if want := epoch.Add(time.Minute); !tick.Equal(want) {
+			t.Errorf("tick = %v, want %v", tick, want)
+		}
+	default:
+		t.Fatal("ticker did not fire at its interval")
+	}
+
+	// Ticks missed by a slow receiver are dropped, like time.Ticker's
+	clk.Advance(5 * time.Minute)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Error("ticker buffered more than one tick")
+	default:
+	}
+
+	ticker.Stop()
+	clk.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Error("stopped ticker fired")
+	default:
+	}
+}
+
+func TestOffset(t *testing.T) {
+	base := NewManual(epoch)
+	clk := Offset(base, 48*time.Hour)
+
+	if got, want := clk.Now(), epoch.Add(48*time.Hour); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	if Offset(base, 0) != Clock(base) {
+		t.Error("expected a zero offset to return the base clock")
+	}
+}
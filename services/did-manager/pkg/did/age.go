@@ -0,0 +1,31 @@
+package did
+
+import (
+	"strconv"
+	"time"
+)
+
+// Age-over credentials attest that their subject had reached given ages when they
+// were issued. The birthdate and every age threshold are separate claims, so the
+// holder can prove e.g. "over 18" without revealing the birthdate.
+const (
+	TypeAgeOverCredential = "AgeOverCredential"
+	ClaimBirthdate        = "birthdate"
+	// BirthdateLayout is the format of birthdate claims
+	BirthdateLayout = "2006-01-02"
+)
+
+// AgeOverClaim names the claim attesting that the subject is at least threshold
+// years old
+func AgeOverClaim(threshold int) string {
+	return "age_over_" + strconv.Itoa(threshold)
+}
+
+// AgeAt returns the age in whole years of someone born on birthdate at the given time
+func AgeAt(birthdate, at time.Time) int {
+	years := at.Year() - birthdate.Year()
+	if at.Month() < birthdate.Month() || (at.Month() == birthdate.Month() && at.Day() < birthdate.Day()) {
+		years--
+	}
+	return years
+}
@@ -0,0 +1,35 @@
+package did
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// MinBlindIndexKeySize is the minimum size of the secret keying blind indexes
+const MinBlindIndexKeySize = 32
+
+// BlindIndex derives searchable, non-reversible indexes of claims. Commitments are
+// salted, so they cannot be matched against a claim without the DID; a blind index is
+// deterministic instead, but keyed with a secret that never leaves the server, so the
+// stored indexes cannot be brute-forced from guessable addresses.
+type BlindIndex struct {
+	key []byte
+}
+
+// NewBlindIndex creates a blind index keyed with the given secret
+func NewBlindIndex(key []byte) (*BlindIndex, error) {
+	if len(key) < MinBlindIndexKeySize {
+		return nil, fmt.Errorf("blind index key must be at least %d bytes", MinBlindIndexKeySize)
+	}
+	return &BlindIndex{key: key}, nil
+}
+
+// Email returns the hex-encoded index of an email address, HMAC-SHA256 over the
+// address normalized like for duplicate detection
+func (b *BlindIndex) Email(email string) string {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte("email:" + NormalizeEmail(email)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
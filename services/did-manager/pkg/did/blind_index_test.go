@@ -0,0 +1,31 @@
+package did
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlindIndexEmail(t *testing.T) {
+	index, err := NewBlindIndex(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("NewBlindIndex failed: %v", err)
+	}
+	other, err := NewBlindIndex(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatalf("NewBlindIndex failed: %v", err)
+	}
+
+	if index.Email("alice@example.com") != index.Email("  Alice+invites@Example.COM ") {
+		t.Error("expected spellings of the same address to share an index")
+	}
+	if index.Email("alice@example.com") == index.Email("bob@example.com") {
+		t.Error("expected different addresses to have different indexes")
+	}
+	if index.Email("alice@example.com") == other.Email("alice@example.com") {
+		t.Error("expected indexes under different keys to differ")
+	}
+
+	if _, err := NewBlindIndex([]byte("short")); err == nil {
+		t.Error("expected a short key to be rejected")
+	}
+}
@@ -0,0 +1,176 @@
+package did
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Supported user hash schemes. The scheme is stored alongside every DID so
+// commitments created under older schemes remain verifiable after the default changes.
+const (
+	// HashSchemeSHA256 is the legacy scheme: SHA-256 (or HMAC-SHA256 with a pepper)
+	// over name:email:timestamp. The timestamp is not stored, so legacy commitments
+	// can only be compared, not recomputed from claims.
+	HashSchemeSHA256 = "sha256-v1"
+	// HashSchemeArgon2id is a salted, memory-hard commitment over name:email
+	HashSchemeArgon2id = "argon2id-v1"
+	// HashSchemeNone marks anonymous DIDs, created without claims; their user hash is
+	// a random value committing to nothing
+	HashSchemeNone = "none"
+)
+
+var (
+	// ErrCommitmentNotRecomputable is returned when a commitment cannot be checked against claims
+	ErrCommitmentNotRecomputable = errors.New("commitment scheme cannot be recomputed from claims")
+	// ErrNoCommitment is returned when checking claims against an anonymous DID
+	ErrNoCommitment = errors.New("DID was created without a claims commitment")
+)
+
+// Commitment is a user hash together with everything needed to recompute it
+type Commitment struct {
+	Scheme string
+	// Params is a PHC-style parameter string, e.g. $argon2id$v=19$m=65536,t=1,p=4,l=32$<salt>,
+	// empty for the legacy scheme
+	Params string
+	// Value is the hex-encoded hash, stored as the DID's user hash
+	Value string
+	// PepperVersion names the pepper keying Value; empty is LegacyPepperVersion. Values
+	// cannot be re-keyed without the claims, so it never changes.
+	PepperVersion string
+}
+
+// sha256Scheme implements the legacy HashSchemeSHA256
+type sha256Scheme struct{}
+
+func (sha256Scheme) ID() string { return HashSchemeSHA256 }
+
+func (sha256Scheme) Commit(userData string, pepper []byte) (*Commitment, error) {
+	return &Commitment{Scheme: HashSchemeSHA256, Value: hashUserData(userData, pepper)}, nil
+}
+
+func (sha256Scheme) Verify(*Commitment, string, []byte) (bool, error) {
+	return false, ErrCommitmentNotRecomputable
+}
+
+// hashUserData hashes user data, keyed by the pepper when one is configured
+func hashUserData(userData string, pepper []byte) string {
+	if len(pepper) == 0 {
+		userHash := sha256.Sum256([]byte(userData))
+		return hex.EncodeToString(userHash[:])
+	}
+
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(userData))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Argon2Params are the tunable Argon2id parameters
+type Argon2Params struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+	KeyLength uint32
+}
+
+// DefaultArgon2Params follow the OWASP baseline recommendation for Argon2id
+var DefaultArgon2Params = Argon2Params{
+	Time:      1,
+	MemoryKiB: 64 * 1024,
+	Threads:   4,
+	KeyLength: 32,
+}
+
+// argon2SaltLength is the length of the random salt in bytes
+const argon2SaltLength = 16
+
+// argon2idScheme implements HashSchemeArgon2id. params only apply to new
+// commitments; verification uses the parameters recorded with each commitment.
+type argon2idScheme struct {
+	params Argon2Params
+}
+
+// NewArgon2idScheme creates the Argon2id hash scheme with the given parameters
+// for new commitments; the zero value uses DefaultArgon2Params
+func NewArgon2idScheme(params Argon2Params) HashScheme {
+	if params == (Argon2Params{}) {
+		params = DefaultArgon2Params
+	}
+	return argon2idScheme{params: params}
+}
+
+func (argon2idScheme) ID() string { return HashSchemeArgon2id }
+
+func (s argon2idScheme) Commit(userData string, pepper []byte) (*Commitment, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return &Commitment{
+		Scheme: HashSchemeArgon2id,
+		Params: encodeArgon2Params(s.params, salt),
+		Value:  argon2Hash(userData, salt, s.params, pepper),
+	}, nil
+}
+
+func (argon2idScheme) Verify(commitment *Commitment, userData string, pepper []byte) (bool, error) {
+	params, salt, err := decodeArgon2Params(commitment.Params)
+	if err != nil {
+		return false, err
+	}
+
+	computed := argon2Hash(userData, salt, params, pepper)
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(commitment.Value)) == 1, nil
+}
+
+// argon2Hash derives the hex-encoded Argon2id hash, keyed by the pepper when configured
+func argon2Hash(userData string, salt []byte, params Argon2Params, pepper []byte) string {
+	key := argon2.IDKey([]byte(userData), salt, params.Time, params.MemoryKiB, params.Threads, params.KeyLength)
+
+	if len(pepper) > 0 {
+		mac := hmac.New(sha256.New, pepper)
+		mac.Write(key)
+		key = mac.Sum(nil)
+	}
+
+	return hex.EncodeToString(key)
+}
+
+// encodeArgon2Params encodes parameters and salt in PHC string format
+func encodeArgon2Params(params Argon2Params, salt []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d,l=%d$%s",
+		argon2.Version, params.MemoryKiB, params.Time, params.Threads, params.KeyLength,
+		base64.RawStdEncoding.EncodeToString(salt))
+}
+
+// decodeArgon2Params parses a PHC string produced by encodeArgon2Params
+func decodeArgon2Params(encoded string) (Argon2Params, []byte, error) {
+	var params Argon2Params
+	var version int
+	var saltB64 string
+
+	_, err := fmt.Sscanf(encoded, "$argon2id$v=%d$m=%d,t=%d,p=%d,l=%d$%s",
+		&version, &params.MemoryKiB, &params.Time, &params.Threads, &params.KeyLength, &saltB64)
+	if err != nil {
+		return params, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	if version != argon2.Version {
+		return params, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(saltB64)
+	if err != nil {
+		return params, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	return params, salt, nil
+}
@@ -0,0 +1,193 @@
+package did
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Verifiable Credentials contexts and types
+const (
+	ContextCredentialsV1        = "https://www.w3.org/2018/credentials/v1"
+	TypeVerifiableCredential    = "VerifiableCredential"
+	TypeVerifiablePresentation  = "VerifiablePresentation"
+	ProofPurposeAssertionMethod = "assertionMethod"
+	ProofPurposeAuthentication  = "authentication"
+	proofTypeSuffix             = "Signature"
+)
+
+// ErrInvalidProof is returned when a credential or presentation proof does not verify
+var ErrInvalidProof = errors.New("invalid proof")
+
+// Proof is a linked-data style proof over a credential or presentation. The
+// signature covers the JSON encoding of the document without its proof, plus the
+// proof options (everything but ProofValue).
+type Proof struct {
+	Type string `json:"type"`
+	// Suite is the signature suite ID used to create ProofValue
+	Suite              string `json:"suite"`
+	Created            string `json:"created"`
+	VerificationMethod string `json:"verificationMethod"`
+	ProofPurpose       string `json:"proofPurpose"`
+	Challenge          string `json:"challenge,omitempty"`
+	Domain             string `json:"domain,omitempty"`
+	ProofValue         string `json:"proofValue,omitempty"`
+}
+
+// Credential represents a W3C Verifiable Credential
+type Credential struct {
+	Context           []string       `json:"@context"`
+	ID                string         `json:"id"`
+	Type              []string       `json:"type"`
+	Issuer            string         `json:"issuer"`
+	IssuanceDate      string         `json:"issuanceDate"`
+	ExpirationDate    string         `json:"expirationDate,omitempty"`
+	CredentialSubject map[string]any `json:"credentialSubject"`
+	// OnBehalfOf names the DID the issuer acted for; Delegation then carries the
+	// delegation chain from that DID to the issuer
+	OnBehalfOf string        `json:"onBehalfOf,omitempty"`
+	Delegation []*Credential `json:"delegation,omitempty"`
+	// RelatedResource references documents attached to the credential by digest
+	RelatedResource []RelatedResource `json:"relatedResource,omitempty"`
+	Proof           *Proof            `json:"proof,omitempty"`
+}
+
+// RelatedResource references a resource outside a credential, such as a diploma PDF,
+// with the Subresource Integrity digest of its content so verifiers can check they
+// were given the original
+type RelatedResource struct {
+	ID        string `json:"id"`
+	MediaType string `json:"mediaType,omitempty"`
+	DigestSRI string `json:"digestSRI"`
+}
+
+// Presentation represents a W3C Verifiable Presentation
+type Presentation struct {
+	Context              []string      `json:"@context"`
+	ID                   string        `json:"id"`
+	Type                 []string      `json:"type"`
+	Holder               string        `json:"holder"`
+	VerifiableCredential []*Credential `json:"verifiableCredential"`
+	Proof                *Proof        `json:"proof,omitempty"`
+}
+
+// ProofOptions configures a new proof
+type ProofOptions struct {
+	VerificationMethod string
+	ProofPurpose       string
+	Challenge          string
+	Domain             string
+	// Created dates the proof; the zero value is the system time
+	Created time.Time
+}
+
+// SignCredential adds a proof to credential using privateKey under suite
+func SignCredential(suite SignatureSuite, privateKey []byte, credential *Credential, opts ProofOptions) error {
+	credential.Proof = nil
+	proof, err := createProof(suite, privateKey, credential, opts)
+	if err != nil {
+		return err
+	}
+	credential.Proof = proof
+	return nil
+}
+
+// VerifyCredential checks a credential's proof with the public key of its verification method
+func VerifyCredential(registry *Registry, publicKey []byte, credential *Credential) error {
+	unsigned := *credential
+	unsigned.Proof = nil
+	return verifyProof(registry, publicKey, &unsigned, credential.Proof)
+}
+
+// SignPresentation adds a proof to presentation using the holder's privateKey under suite
+func SignPresentation(suite SignatureSuite, privateKey []byte, presentation *Presentation, opts ProofOptions) error {
+	presentation.Proof = nil
+	proof, err := createProof(suite, privateKey, presentation, opts)
+	if err != nil {
+		return err
+	}
+	presentation.Proof = proof
+	return nil
+}
+
+// VerifyPresentation checks a presentation's proof with the holder's public key. It does
+// not verify the embedded credentials, which are signed by their issuers.
+func VerifyPresentation(registry *Registry, publicKey []byte, presentation *Presentation) error {
+	unsigned := *presentation
+	unsigned.Proof = nil
+	return verifyProof(registry, publicKey, &unsigned, presentation.Proof)
+}
+
+// createProof signs the document together with the proof options
+func createProof(suite SignatureSuite, privateKey []byte, document any, opts ProofOptions) (*Proof, error) {
+	created := opts.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	proof := &Proof{
+		Type:               suite.VerificationMethodType() + proofTypeSuffix,
+		Suite:              suite.ID(),
+		Created:            created.UTC().Format(time.RFC3339),
+		VerificationMethod: opts.VerificationMethod,
+		ProofPurpose:       opts.ProofPurpose,
+		Challenge:          opts.Challenge,
+		Domain:             opts.Domain,
+	}
+
+	payload, err := proofPayload(document, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := suite.Sign(privateKey, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign proof: %w", err)
+	}
+	proof.ProofValue = hex.EncodeToString(signature)
+
+	return proof, nil
+}
+
+// verifyProof checks proof over the unsigned document
+func verifyProof(registry *Registry, publicKey []byte, unsigned any, proof *Proof) error {
+	if proof == nil || proof.ProofValue == "" {
+		return fmt.Errorf("%w: missing proof", ErrInvalidProof)
+	}
+
+	signature, err := hex.DecodeString(proof.ProofValue)
+	if err != nil {
+		return fmt.Errorf("%w: malformed proof value", ErrInvalidProof)
+	}
+
+	options := *proof
+	options.ProofValue = ""
+	payload, err := proofPayload(unsigned, &options)
+	if err != nil {
+		return err
+	}
+
+	ok, err := registry.VerifySignature(proof.Suite, publicKey, payload, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidProof)
+	}
+	return nil
+}
+
+// proofPayload is the byte string signed by a proof
+func proofPayload(document any, options *Proof) ([]byte, error) {
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	optionsBytes, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode proof options: %w", err)
+	}
+	return append(append(optionsBytes, '\n'), documentBytes...), nil
+}
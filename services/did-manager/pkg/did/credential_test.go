@@ -0,0 +1,79 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCredentialProofRoundTrip(t *testing.T) {
+	registry := NewRegistry()
+	suite := registry.DefaultSignatureSuite()
+
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	credential := &Credential{
+		Context:           []string{ContextCredentialsV1},
+		ID:                "urn:uuid:1",
+		Type:              []string{TypeVerifiableCredential, "EmailCredential"},
+		Issuer:            "did:example:issuer",
+		IssuanceDate:      "2024-01-01T00:00:00Z",
+		CredentialSubject: map[string]any{"id": "did:example:holder", "email": "alice@example.com"},
+	}
+
+	err = SignCredential(suite, privateKey, credential, ProofOptions{
+		VerificationMethod: "did:example:issuer#key-1",
+		ProofPurpose:       ProofPurposeAssertionMethod,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign credential: %v", err)
+	}
+
+	if err := VerifyCredential(registry, publicKey, credential); err != nil {
+		t.Errorf("expected credential to verify: %v", err)
+	}
+
+	credential.CredentialSubject["email"] = "mallory@example.com"
+	if err := VerifyCredential(registry, publicKey, credential); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected tampered credential to fail, got %v", err)
+	}
+}
+
+func TestPresentationProofBindsChallenge(t *testing.T) {
+	registry := NewRegistry()
+	suite := registry.DefaultSignatureSuite()
+
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	presentation := &Presentation{
+		Context: []string{ContextCredentialsV1},
+		ID:      "urn:uuid:2",
+		Type:    []string{TypeVerifiablePresentation},
+		Holder:  "did:example:holder",
+	}
+
+	err = SignPresentation(suite, privateKey, presentation, ProofOptions{
+		VerificationMethod: "did:example:holder#key-1",
+		ProofPurpose:       ProofPurposeAuthentication,
+		Challenge:          "nonce-1",
+		Domain:             "verifier.example",
+	})
+	if err != nil {
+		t.Fatalf("failed to sign presentation: %v", err)
+	}
+
+	if err := VerifyPresentation(registry, publicKey, presentation); err != nil {
+		t.Errorf("expected presentation to verify: %v", err)
+	}
+
+	// Replaying the presentation against a different challenge must fail
+	presentation.Proof.Challenge = "nonce-2"
+	if err := VerifyPresentation(registry, publicKey, presentation); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected changed challenge to fail, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Delegation credentials let one DID authorize another to act on its behalf. The
+// delegator issues a DelegationCredential to the delegate listing the capabilities
+// granted; the delegate may re-delegate a subset by issuing a credential that names
+// its own delegation as parent, forming a chain rooted at the original delegator.
+const (
+	TypeDelegationCredential = "DelegationCredential"
+	// CapabilityIssueCredential allows issuing credentials on the delegator's behalf
+	CapabilityIssueCredential = "credential:issue"
+	// MaxDelegationDepth bounds the length of delegation chains
+	MaxDelegationDepth = 5
+)
+
+// Delegation credential subject claims
+const (
+	claimCapabilities     = "capabilities"
+	claimParentDelegation = "parentDelegation"
+)
+
+// ErrInvalidDelegation is returned when a delegation chain does not authorize the delegate
+var ErrInvalidDelegation = errors.New("invalid delegation chain")
+
+// DelegationClaims builds the credential subject of a delegation credential. parentID
+// is the ID of the delegation being re-delegated, or empty for a root delegation.
+func DelegationClaims(capabilities []string, parentID string) map[string]any {
+	claims := map[string]any{claimCapabilities: capabilities}
+	if parentID != "" {
+		claims[claimParentDelegation] = parentID
+	}
+	return claims
+}
+
+// DelegationCapabilities returns the capabilities granted by a delegation credential
+func DelegationCapabilities(credential *Credential) []string {
+	var capabilities []string
+	switch values := credential.CredentialSubject[claimCapabilities].(type) {
+	case []string:
+		capabilities = values
+	case []any:
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				capabilities = append(capabilities, s)
+			}
+		}
+	}
+	return capabilities
+}
+
+// DelegationParent returns the ID of the delegation a credential re-delegates, if any
+func DelegationParent(credential *Credential) string {
+	parent, _ := credential.CredentialSubject[claimParentDelegation].(string)
+	return parent
+}
+
+// IsDelegation reports whether a credential is a delegation credential
+func IsDelegation(credential *Credential) bool {
+	for _, t := range credential.Type {
+		if t == TypeDelegationCredential {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDelegationChain checks that chain, ordered from the delegator's credential
+// to the delegate's, authorizes delegate to exercise capability on behalf of delegator.
+// It validates the chain's structure only; each credential's proof and status must be
+// verified separately.
+func ValidateDelegationChain(chain []*Credential, delegator, delegate, capability string, now time.Time) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("%w: empty chain", ErrInvalidDelegation)
+	}
+	if len(chain) > MaxDelegationDepth {
+		return fmt.Errorf("%w: chain exceeds %d delegations", ErrInvalidDelegation, MaxDelegationDepth)
+	}
+
+	expectedIssuer := delegator
+	var granted []string
+	for i, link := range chain {
+		if link == nil || !IsDelegation(link) {
+			return fmt.Errorf("%w: link %d is not a delegation credential", ErrInvalidDelegation, i)
+		}
+		if link.Issuer != expectedIssuer {
+			return fmt.Errorf("%w: link %d is issued by %s, expected %s", ErrInvalidDelegation, i, link.Issuer, expectedIssuer)
+		}
+
+		parent := DelegationParent(link)
+		if i == 0 && parent != "" {
+			return fmt.Errorf("%w: chain does not start at the root delegation", ErrInvalidDelegation)
+		}
+		if i > 0 && parent != chain[i-1].ID {
+			return fmt.Errorf("%w: link %d does not reference its parent delegation", ErrInvalidDelegation, i)
+		}
+
+		if link.ExpirationDate != "" {
+			expires, err := time.Parse(time.RFC3339, link.ExpirationDate)
+			if err != nil || now.After(expires) {
+				return fmt.Errorf("%w: link %d has expired", ErrInvalidDelegation, i)
+			}
+		}
+
+		capabilities := DelegationCapabilities(link)
+		if i > 0 {
+			// A delegate can only pass on capabilities it was granted
+			for _, c := range capabilities {
+				if !containsString(granted, c) {
+					return fmt.Errorf("%w: link %d grants %s beyond its parent", ErrInvalidDelegation, i, c)
+				}
+			}
+		}
+		granted = capabilities
+
+		subject, _ := link.CredentialSubject["id"].(string)
+		if subject == "" {
+			return fmt.Errorf("%w: link %d has no delegate", ErrInvalidDelegation, i)
+		}
+		expectedIssuer = subject
+	}
+
+	if expectedIssuer != delegate {
+		return fmt.Errorf("%w: chain ends at %s, not %s", ErrInvalidDelegation, expectedIssuer, delegate)
+	}
+	if !containsString(granted, capability) {
+		return fmt.Errorf("%w: capability %s is not granted", ErrInvalidDelegation, capability)
+	}
+
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
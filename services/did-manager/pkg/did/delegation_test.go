@@ -0,0 +1,67 @@
+package did
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func delegation(id, issuer, delegate, parent string, capabilities ...string) *Credential {
+	subject := DelegationClaims(capabilities, parent)
+	subject["id"] = delegate
+	return &Credential{
+		Context:           []string{ContextCredentialsV1},
+		ID:                id,
+		Type:              []string{TypeVerifiableCredential, TypeDelegationCredential},
+		Issuer:            issuer,
+		IssuanceDate:      "2024-01-01T00:00:00Z",
+		CredentialSubject: subject,
+	}
+}
+
+func TestValidateDelegationChain(t *testing.T) {
+	now := time.Now()
+	root := delegation("urn:uuid:1", "did:example:org", "did:example:manager", "", CapabilityIssueCredential, "did:update")
+	child := delegation("urn:uuid:2", "did:example:manager", "did:example:employee", "urn:uuid:1", CapabilityIssueCredential)
+
+	if err := ValidateDelegationChain([]*Credential{root}, "did:example:org", "did:example:manager", "did:update", now); err != nil {
+		t.Errorf("expected direct delegation to validate: %v", err)
+	}
+	if err := ValidateDelegationChain([]*Credential{root, child}, "did:example:org", "did:example:employee", CapabilityIssueCredential, now); err != nil {
+		t.Errorf("expected re-delegation to validate: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		chain      []*Credential
+		delegate   string
+		capability string
+	}{
+		{"empty chain", nil, "did:example:employee", CapabilityIssueCredential},
+		{"capability not re-delegated", []*Credential{root, child}, "did:example:employee", "did:update"},
+		{"wrong delegate", []*Credential{root, child}, "did:example:mallory", CapabilityIssueCredential},
+		{"chain out of order", []*Credential{child, root}, "did:example:manager", CapabilityIssueCredential},
+		{"escalated capability", []*Credential{root, delegation("urn:uuid:3", "did:example:manager", "did:example:employee", "urn:uuid:1", "did:revoke")}, "did:example:employee", "did:revoke"},
+		{"missing parent reference", []*Credential{root, delegation("urn:uuid:4", "did:example:manager", "did:example:employee", "", CapabilityIssueCredential)}, "did:example:employee", CapabilityIssueCredential},
+		{"not issued by delegator", []*Credential{delegation("urn:uuid:5", "did:example:mallory", "did:example:employee", "", CapabilityIssueCredential)}, "did:example:employee", CapabilityIssueCredential},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDelegationChain(tt.chain, "did:example:org", tt.delegate, tt.capability, now)
+			if !errors.Is(err, ErrInvalidDelegation) {
+				t.Errorf("expected ErrInvalidDelegation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDelegationChainRejectsExpiredLink(t *testing.T) {
+	root := delegation("urn:uuid:1", "did:example:org", "did:example:employee", "", CapabilityIssueCredential)
+	root.ExpirationDate = "2024-06-01T00:00:00Z"
+
+	err := ValidateDelegationChain([]*Credential{root}, "did:example:org", "did:example:employee", CapabilityIssueCredential, time.Now())
+	if !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected expired delegation to fail, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DID Core contexts included in every generated document
+const (
+	ContextDIDCore   = "https://www.w3.org/ns/did/v1"
+	ContextEd25519V1 = "https://w3id.org/security/suites/ed25519-2020/v1"
+)
+
+// ContextSecp256k1Recovery defines the verification method type of blockchain accounts
+const ContextSecp256k1Recovery = "https://w3id.org/security/suites/secp256k1recovery-2020/v2"
+
+// didSyntax matches the DID syntax of DID Core: did, a lowercase method name and a
+// method-specific ID of colon-separated segments, the last one not empty
+var didSyntax = regexp.MustCompile(`^did:[a-z0-9]+:(?:(?:[A-Za-z0-9._-]|%[0-9A-Fa-f]{2})*:)*(?:[A-Za-z0-9._-]|%[0-9A-Fa-f]{2})+$`)
+
+// ValidSyntax reports whether a string is a DID as defined by DID Core, without a path,
+// query or fragment
+func ValidSyntax(did string) bool {
+	return didSyntax.MatchString(did)
+}
+
+// Document represents a W3C DID Document
+type Document struct {
+	Context    []string `json:"@context"`
+	ID         string   `json:"id"`
+	Controller string   `json:"controller,omitempty"`
+	// AlsoKnownAs lists other identifiers of the subject, such as the current
+	// identifier of a DID created with a legacy one
+	AlsoKnownAs        []string             `json:"alsoKnownAs,omitempty"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	Authentication     []string             `json:"authentication"`
+	AssertionMethod    []string             `json:"assertionMethod"`
+	// KeyAgreement and CapabilityInvocation are only listed for DIDs with purpose keys
+	KeyAgreement         []string `json:"keyAgreement,omitempty"`
+	CapabilityInvocation []string `json:"capabilityInvocation,omitempty"`
+}
+
+// VerificationMethod represents a public key entry in a DID Document. Documents are
+// built with hex keys and served with their keys re-encoded by EncodeKeys; keys of
+// resolved external DIDs, such as did:ion, are usually JWKs.
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyHex       string `json:"publicKeyHex,omitempty"`
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
+	PublicKeyJwk       *JWK   `json:"publicKeyJwk,omitempty"`
+	// BlockchainAccountID is the CAIP-10 account ID of an account controlling the DID
+	BlockchainAccountID string `json:"blockchainAccountId,omitempty"`
+}
+
+// JWK is a public JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKPublicKey returns the JWK of the verification method together with the
+// signature suite that verifies it. Only Ed25519 keys are supported.
+func (m *VerificationMethod) JWKPublicKey() (string, []byte, error) {
+	jwk := m.PublicKeyJwk
+	if jwk == nil {
+		return "", nil, fmt.Errorf("verification method %s has no JWK", m.ID)
+	}
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+		return "", nil, fmt.Errorf("unsupported JWK key type %s/%s", jwk.Kty, jwk.Crv)
+	}
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.X, "="))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return "", nil, fmt.Errorf("malformed Ed25519 JWK in %s", m.ID)
+	}
+	return SignatureSuiteEd25519, key, nil
+}
+
+// NewDocument builds a DID Document for a DID controlled by a single key whose
+// verification method type is given by its signature suite
+func NewDocument(did, verificationMethodType string, publicKey []byte) *Document {
+	keyID := did + "#key-1"
+
+	return &Document{
+		Context:    []string{ContextDIDCore, ContextEd25519V1},
+		ID:         did,
+		Controller: did,
+		VerificationMethod: []VerificationMethod{
+			{
+				ID:           keyID,
+				Type:         verificationMethodType,
+				Controller:   did,
+				PublicKeyHex: hex.EncodeToString(publicKey),
+			},
+		},
+		Authentication:  []string{keyID},
+		AssertionMethod: []string{keyID},
+	}
+}
+
+// SetAccountController makes a blockchain account, given as a CAIP-10 account ID such
+// as eip155:1:0xab16..., the controller of the document and lists it as a verification
+// method. Smart contract accounts verify signatures on-chain, so the account is not
+// added to the proof purposes of the DID's own key.
+func (d *Document) SetAccountController(accountID string) {
+	d.Controller = "did:pkh:" + accountID
+	d.VerificationMethod = append(d.VerificationMethod, VerificationMethod{
+		ID:                  d.ID + "#smart-account",
+		Type:                "EcdsaSecp256k1RecoveryMethod2020",
+		Controller:          d.Controller,
+		BlockchainAccountID: accountID,
+	})
+	if !d.hasContext(ContextSecp256k1Recovery) {
+		d.Context = append(d.Context, ContextSecp256k1Recovery)
+	}
+}
+
+// AddAuthenticationMethod lists an additional key of the DID, such as a device subkey,
+// under authentication only: it proves control of the DID in sessions but cannot make
+// assertions such as issuing credentials
+func (d *Document) AddAuthenticationMethod(id, verificationMethodType string, publicKey []byte) {
+	d.VerificationMethod = append(d.VerificationMethod, VerificationMethod{
+		ID:           id,
+		Type:         verificationMethodType,
+		Controller:   d.ID,
+		PublicKeyHex: hex.EncodeToString(publicKey),
+	})
+	d.Authentication = append(d.Authentication, id)
+}
+
+// SetPurposeKey lists a key generated for a single purpose under it. Authentication
+// and assertionMethod keys take over their purpose from the DID's own key, which is
+// then listed under capabilityInvocation only.
+func (d *Document) SetPurposeKey(id, purpose, verificationMethodType string, publicKey []byte) {
+	d.VerificationMethod = append(d.VerificationMethod, VerificationMethod{
+		ID:           id,
+		Type:         verificationMethodType,
+		Controller:   d.ID,
+		PublicKeyHex: hex.EncodeToString(publicKey),
+	})
+
+	switch purpose {
+	case PurposeAuthentication:
+		d.Authentication = []string{id}
+	case PurposeAssertionMethod:
+		d.AssertionMethod = []string{id}
+	case PurposeKeyAgreement:
+		d.KeyAgreement = append(d.KeyAgreement, id)
+		if !d.hasContext(ContextX25519V1) {
+			d.Context = append(d.Context, ContextX25519V1)
+		}
+	case PurposeCapabilityInvocation:
+		d.CapabilityInvocation = append(d.CapabilityInvocation, id)
+	}
+	if len(d.CapabilityInvocation) == 0 {
+		d.CapabilityInvocation = []string{d.ID + "#key-1"}
+	}
+}
+
+func (d *Document) hasContext(context string) bool {
+	for _, c := range d.Context {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
+
+// Method finds a verification method by ID
+func (d *Document) Method(id string) (*VerificationMethod, bool) {
+	for i := range d.VerificationMethod {
+		if d.VerificationMethod[i].ID == id {
+			return &d.VerificationMethod[i], true
+		}
+	}
+	return nil, false
+}
+
+// Authorizes reports whether the verification method is listed under the proof
+// purpose: authentication, assertionMethod, keyAgreement or capabilityInvocation
+func (d *Document) Authorizes(id, proofPurpose string) bool {
+	var listed []string
+	switch proofPurpose {
+	case ProofPurposeAuthentication:
+		listed = d.Authentication
+	case ProofPurposeAssertionMethod:
+		listed = d.AssertionMethod
+	case PurposeKeyAgreement:
+		listed = d.KeyAgreement
+	case PurposeCapabilityInvocation:
+		listed = d.CapabilityInvocation
+	}
+	for _, method := range listed {
+		if method == id {
+			return true
+		}
+	}
+	return false
+}
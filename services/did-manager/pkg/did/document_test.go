@@ -0,0 +1,141 @@
+package did
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewDocument(t *testing.T) {
+	publicKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	doc := NewDocument("did:example:user:abc:def", "Ed25519VerificationKey2020", publicKey)
+
+	if doc.ID != "did:example:user:abc:def" {
+		t.Errorf("unexpected document ID: %s", doc.ID)
+	}
+	if len(doc.VerificationMethod) != 1 {
+		t.Fatalf("expected one verification method, got %d", len(doc.VerificationMethod))
+	}
+	if doc.Authentication[0] != doc.VerificationMethod[0].ID {
+		t.Errorf("authentication should reference the verification method")
+	}
+	if doc.VerificationMethod[0].PublicKeyHex != hex.EncodeToString(publicKey) {
+		t.Errorf("unexpected public key in document")
+	}
+}
+
+func TestSetAccountController(t *testing.T) {
+	publicKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	doc := NewDocument("did:example:user:abc", "Ed25519VerificationKey2020", publicKey)
+	accountID := "eip155:11155111:0xab16a96D359eC26a11e2C2b3d8f8B8942d5Bfcdb"
+	doc.SetAccountController(accountID)
+
+	if doc.Controller != "did:pkh:"+accountID {
+		t.Errorf("unexpected controller: %s", doc.Controller)
+	}
+	method, ok := doc.Method("did:example:user:abc#smart-account")
+	if !ok || method.BlockchainAccountID != accountID {
+		t.Fatalf("expected the account to be listed as a verification method")
+	}
+	if len(doc.Authentication) != 1 {
+		t.Errorf("the account should not be added to authentication")
+	}
+}
+
+func TestAddAuthenticationMethod(t *testing.T) {
+	publicKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	deviceKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	doc := NewDocument("did:example:user:abc", "Ed25519VerificationKey2020", publicKey)
+	id := "did:example:user:abc#device-1"
+	doc.AddAuthenticationMethod(id, "Ed25519VerificationKey2020", deviceKey)
+
+	method, ok := doc.Method(id)
+	if !ok || method.PublicKeyHex != hex.EncodeToString(deviceKey) || method.Controller != doc.ID {
+		t.Fatalf("expected the device key to be listed as a verification method")
+	}
+	if !doc.Authorizes(id, ProofPurposeAuthentication) {
+		t.Errorf("the device key should be authorized for authentication")
+	}
+	if doc.Authorizes(id, ProofPurposeAssertionMethod) {
+		t.Errorf("the device key should not be authorized for assertions")
+	}
+}
+
+func TestSetPurposeKey(t *testing.T) {
+	gen := NewGenerator()
+	generated, err := gen.GenerateDID([16]byte{}, "Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+	keys, err := gen.GeneratePurposeKeys(generated.KeyAlgorithm)
+	if err != nil {
+		t.Fatalf("failed to generate purpose keys: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected authentication, assertionMethod and keyAgreement keys, got %d", len(keys))
+	}
+
+	doc := NewDocument(generated.DID, "Ed25519VerificationKey2020", generated.PublicKey)
+	for _, key := range keys {
+		methodType := "Ed25519VerificationKey2020"
+		if key.KeyAlgorithm == KeyAgreementX25519 {
+			methodType = VerificationMethodX25519
+			if len(key.PublicKey) != 32 {
+				t.Errorf("expected a 32 byte X25519 key, got %d bytes", len(key.PublicKey))
+			}
+		}
+		doc.SetPurposeKey(doc.ID+"#"+key.Fragment, key.Purpose, methodType, key.PublicKey)
+	}
+
+	own := doc.ID + "#key-1"
+	if doc.Authorizes(own, ProofPurposeAuthentication) || doc.Authorizes(own, ProofPurposeAssertionMethod) {
+		t.Error("the DID's own key should no longer serve authentication or assertionMethod")
+	}
+	if !doc.Authorizes(own, PurposeCapabilityInvocation) {
+		t.Error("the DID's own key should be the capabilityInvocation key")
+	}
+	for _, purpose := range []string{PurposeAuthentication, PurposeAssertionMethod, PurposeKeyAgreement} {
+		fragment, _ := PurposeKeyFragment(purpose)
+		for _, other := range []string{PurposeAuthentication, PurposeAssertionMethod, PurposeKeyAgreement} {
+			if doc.Authorizes(doc.ID+"#"+fragment, other) != (other == purpose) {
+				t.Errorf("key %s should only serve %s, checked %s", fragment, purpose, other)
+			}
+		}
+	}
+	if !doc.hasContext(ContextX25519V1) {
+		t.Error("expected the X25519 context for the keyAgreement key")
+	}
+}
+
+func TestValidSyntax(t *testing.T) {
+	tests := map[string]bool{
+		"did:example:user:abc:def":             true,
+		"did:key:z6MkhaXgBZDvotDkL5257faiztiG": true,
+		"did:web:example.com%3A8443":           true,
+		"did:example:":                         false,
+		"did:Example:123":                      false,
+		"did:example:abc:":                     false,
+		"did:example:abc#key-1":                false,
+		"did:example:%zz":                      false,
+		"example:123":                          false,
+	}
+	for did, want := range tests {
+		if got := ValidSyntax(did); got != want {
+			t.Errorf("ValidSyntax(%q) = %t, want %t", did, got, want)
+		}
+	}
+}
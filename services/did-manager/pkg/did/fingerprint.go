@@ -0,0 +1,55 @@
+package did
+
+import (
+	"strings"
+
+	"packages/canonical"
+)
+
+// Identity fingerprint kinds, each a separate hash domain
+const (
+	FingerprintEmail  = "email"
+	FingerprintClaims = "claims"
+)
+
+// NormalizeEmail canonicalizes an email address for duplicate detection: on top of
+// its canonical form, any "+tag" suffix of the local part is dropped
+func NormalizeEmail(email string) string {
+	email = canonical.Email(email)
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	return local + "@" + domain
+}
+
+// NormalizeName canonicalizes a name for duplicate detection: its canonical form is
+// lower-cased
+func NormalizeName(name string) string {
+	return strings.ToLower(canonical.Name(name))
+}
+
+// IdentityFingerprints derives deterministic fingerprints of a user's normalized email
+// and claims, keyed by the current pepper when configured. Unlike salted commitments
+// they can be compared for equality, so they detect identities registered more than once.
+func (g *Generator) IdentityFingerprints(name, email string) map[string]string {
+	return identityFingerprints(name, email, g.pepper)
+}
+
+// IdentityFingerprintsAt derives identity fingerprints under a pepper version
+func (g *Generator) IdentityFingerprintsAt(version, name, email string) (map[string]string, error) {
+	pepper, err := g.pepperFor(version)
+	if err != nil {
+		return nil, err
+	}
+	return identityFingerprints(name, email, pepper), nil
+}
+
+func identityFingerprints(name, email string, pepper []byte) map[string]string {
+	normalizedEmail := NormalizeEmail(email)
+	return map[string]string{
+		FingerprintEmail:  hashUserData(FingerprintEmail+":"+normalizedEmail, pepper),
+		FingerprintClaims: hashUserData(FingerprintClaims+":"+CommitmentInput(NormalizeName(name), normalizedEmail), pepper),
+	}
+}
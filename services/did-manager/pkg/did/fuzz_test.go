@@ -0,0 +1,239 @@
+package did
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The fuzz targets below cover the inputs the service takes from outside: DIDs and
+// documents of foreign DIDs, and credentials, presentations and tokens presented to
+// it. They only check that malformed input is rejected without a panic, plus the
+// invariants noted. Run one with e.g. go test ./pkg/did -run '^$' -fuzz FuzzParseSDJWT.
+
+// fuzzPublicKey and fuzzPrivateKey are an Ed25519 key pair the fuzzed proofs and
+// tokens are verified with
+var fuzzPublicKey, fuzzPrivateKey, _ = ed25519Suite{}.GenerateKey()
+
+func FuzzDIDIdentifier(f *testing.F) {
+	f.Add("did:example:123456789abcdefghi")
+	f.Add("did:example:9f86d081884c7d659a2feaa0c55ad015:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	f.Add("did:example:9f86d081884c7d659a2feaa0c55ad015:a3b1c2d4e5f60718293a4b5c6d7e8f90")
+	f.Add("did:ion:EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w")
+	f.Add("did:web:example.com%3A8443:users:alice")
+	f.Add("did:example:")
+	f.Add("did::z")
+
+	f.Fuzz(func(t *testing.T, did string) {
+		if ValidSyntax(did) && (!strings.HasPrefix(did, "did:") || strings.ContainsAny(did, "/?#")) {
+			t.Fatalf("ValidSyntax accepted %q", did)
+		}
+		IsLegacyIdentifier(did)
+		IdentifierFormat(did)
+		if methodType, publicKey, ok := IdentifierKey(did); ok {
+			if !CommitsToKey(did, methodType, publicKey) {
+				t.Fatalf("%q does not commit to its embedded key", did)
+			}
+		}
+		CommitsToKey(did, "Ed25519VerificationKey2020", fuzzPublicKey)
+	})
+}
+
+func FuzzDecodeMultibase(f *testing.F) {
+	f.Add("z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	f.Add("z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc")
+	f.Add("z")
+	f.Add("z111")
+	f.Add("f00")
+
+	f.Fuzz(func(t *testing.T, value string) {
+		methodType, publicKey, err := DecodeMultibase(value)
+		if err != nil {
+			return
+		}
+		encoded, err := EncodeMultibase(methodType, publicKey)
+		if err != nil {
+			t.Fatalf("decoded %q as a %s key that does not encode: %v", value, methodType, err)
+		}
+		if encoded != value {
+			t.Fatalf("%q decoded and re-encoded as %q", value, encoded)
+		}
+	})
+}
+
+func FuzzDocument(f *testing.F) {
+	document := NewDocument("did:example:123", "Ed25519VerificationKey2020", fuzzPublicKey)
+	document.SetPurposeKey("did:example:123#key-2", PurposeKeyAgreement, VerificationMethodX25519, fuzzPublicKey)
+	seed, _ := json.Marshal(document)
+	f.Add(seed)
+	if err := document.EncodeKeys(KeyFormatJWK); err == nil {
+		seed, _ = json.Marshal(document)
+		f.Add(seed)
+	}
+	f.Add([]byte(`{"id":"did:ion:x","verificationMethod":[{"id":"#key-1","type":"JsonWebKey2020","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}],"authentication":["#key-1"]}`))
+	f.Add([]byte(`{"verificationMethod":[{"publicKeyMultibase":"z"},{"publicKeyJwk":{}},{}]}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var document Document
+		if err := json.Unmarshal(data, &document); err != nil {
+			return
+		}
+		for _, method := range document.VerificationMethod {
+			method.PublicKey()
+			method.JWKPublicKey()
+			document.Method(method.ID)
+			for _, purpose := range []string{PurposeAuthentication, PurposeAssertionMethod, PurposeKeyAgreement, PurposeCapabilityInvocation} {
+				document.Authorizes(method.ID, purpose)
+			}
+		}
+		for _, format := range []KeyFormat{KeyFormatMultibase, KeyFormatJWK, KeyFormatHex} {
+			encoded := document
+			encoded.VerificationMethod = append([]VerificationMethod(nil), document.VerificationMethod...)
+			encoded.EncodeKeys(format)
+		}
+		if _, err := json.Marshal(&document); err != nil {
+			t.Fatalf("decoded document does not encode: %v", err)
+		}
+	})
+}
+
+func FuzzCredential(f *testing.F) {
+	registry := NewRegistry()
+	credential := &Credential{
+		Context:           []string{ContextCredentialsV1},
+		ID:                "urn:uuid:1",
+		Type:              []string{TypeVerifiableCredential, TypeDelegationCredential},
+		Issuer:            "did:example:issuer",
+		IssuanceDate:      "2026-01-02T15:04:05Z",
+		ExpirationDate:    "2027-01-02T15:04:05Z",
+		CredentialSubject: DelegationClaims([]string{CapabilityIssueCredential}, ""),
+	}
+	credential.CredentialSubject["id"] = "did:example:delegate"
+	if err := SignCredential(registry.DefaultSignatureSuite(), fuzzPrivateKey, credential, ProofOptions{
+		VerificationMethod: "did:example:issuer#key-1",
+		ProofPurpose:       ProofPurposeAssertionMethod,
+	}); err == nil {
+		seed, _ := json.Marshal(credential)
+		f.Add(seed)
+	}
+	f.Add([]byte(`{"type":["DelegationCredential"],"credentialSubject":{"capabilities":[1,"x"],"parentDelegation":2},"proof":{"suite":"unknown","proofValue":"zz"}}`))
+	f.Add([]byte(`{"delegation":[null,{}],"proof":{}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var credential Credential
+		if err := json.Unmarshal(data, &credential); err != nil {
+			return
+		}
+		VerifyCredential(registry, fuzzPublicKey, &credential)
+		IsDelegation(&credential)
+		DelegationCapabilities(&credential)
+		DelegationParent(&credential)
+		chain := append([]*Credential{&credential}, credential.Delegation...)
+		ValidateDelegationChain(chain, credential.Issuer, credential.OnBehalfOf, CapabilityIssueCredential, time.Now())
+	})
+}
+
+func FuzzPresentation(f *testing.F) {
+	registry := NewRegistry()
+	presentation := &Presentation{
+		Context: []string{ContextCredentialsV1},
+		Type:    []string{TypeVerifiablePresentation},
+		Holder:  "did:example:holder",
+		VerifiableCredential: []*Credential{{
+			Type:   []string{TypeVerifiableCredential},
+			Issuer: "did:example:issuer",
+		}},
+	}
+	if err := SignPresentation(registry.DefaultSignatureSuite(), fuzzPrivateKey, presentation, ProofOptions{
+		VerificationMethod: "did:example:holder#key-1",
+		ProofPurpose:       ProofPurposeAuthentication,
+		Challenge:          "nonce",
+	}); err == nil {
+		seed, _ := json.Marshal(presentation)
+		f.Add(seed)
+	}
+	f.Add([]byte(`{"verifiableCredential":[null],"proof":{"suite":"ed25519-2020","proofValue":"00"}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var presentation Presentation
+		if err := json.Unmarshal(data, &presentation); err != nil {
+			return
+		}
+		VerifyPresentation(registry, fuzzPublicKey, &presentation)
+		for _, credential := range presentation.VerifiableCredential {
+			if credential != nil {
+				VerifyCredential(registry, fuzzPublicKey, credential)
+			}
+		}
+	})
+}
+
+func FuzzParseSDJWT(f *testing.F) {
+	suite := ed25519Suite{}
+	token, err := IssueSDJWT(suite, fuzzPrivateKey, "did:example:issuer#key-1",
+		map[string]any{"iss": "did:example:issuer", "vct": TypeAgeOverCredential},
+		map[string]any{ClaimBirthdate: "1990-04-01", AgeOverClaim(18): true})
+	if err == nil {
+		f.Add(token)
+		if bound, err := AddKeyBinding(suite, fuzzPrivateKey, token, "verifier", "nonce", time.Now()); err == nil {
+			f.Add(bound)
+		}
+	}
+	f.Add("e30.e30.~")
+	f.Add("a.b.c~d~")
+
+	f.Fuzz(func(t *testing.T, presentation string) {
+		parsed, err := ParseSDJWT(presentation)
+		if err != nil {
+			return
+		}
+		parsed.Issuer()
+		parsed.KeyID()
+		parsed.Algorithm()
+		parsed.Claims()
+		parsed.Verify(suite, fuzzPublicKey)
+		if parsed.HasKeyBinding() {
+			parsed.VerifyKeyBinding(suite, fuzzPublicKey, "verifier", "nonce")
+		}
+	})
+}
+
+func FuzzVerifyStatusToken(f *testing.F) {
+	now := time.Now()
+	token, err := IssueStatusToken(fuzzPrivateKey, &StatusTokenClaims{
+		Issuer:    "did:example:service",
+		Subject:   "did:example:123",
+		Status:    "active",
+		Assurance: "local_db",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	if err == nil {
+		f.Add(token)
+	}
+	f.Add("e30.e30.")
+
+	f.Fuzz(func(t *testing.T, token string) {
+		claims, err := VerifyStatusToken(token, fuzzPublicKey, now)
+		if err == nil && claims.Subject == "" {
+			t.Fatal("verified a status token without a subject")
+		}
+	})
+}
+
+func FuzzDecryptJWE(f *testing.F) {
+	publicKey, privateKey, err := GenerateKeyAgreementKey()
+	if err != nil {
+		f.Fatalf("failed to generate key: %v", err)
+	}
+	if token, err := EncryptJWE(publicKey, "did:example:123#key-2", "application/json", []byte(`{"hello":"world"}`)); err == nil {
+		f.Add(token)
+	}
+	f.Add("e30..a.b.c")
+
+	f.Fuzz(func(t *testing.T, token string) {
+		DecryptJWE(privateKey, token)
+	})
+}
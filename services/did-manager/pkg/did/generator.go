@@ -1,54 +1,312 @@
 package did
 
 import (
-	"crypto/ed25519"
+	"bytes"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"time"
+
+	"did-manager/pkg/clock"
+
+	"packages/canonical"
 
 	"github.com/google/uuid"
 )
 
+// ErrInvalidPublicKey is returned when a client-supplied key is not a public key of the
+// requested signature suite
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
 // Generator handles DID creation and management
-type Generator struct{}
+type Generator struct {
+	// pepper is a server-side secret mixed into user hashes so identifiers
+	// cannot be brute-forced from guessable name/email combinations
+	pepper []byte
+	// pepperVersion names pepper; records store it so they stay verifiable after a rotation
+	pepperVersion string
+	// previousPepperVersion is the version being rotated away from, if any
+	previousPepperVersion string
+	// peppers holds every configured pepper by version, including the legacy one
+	peppers  map[string][]byte
+	registry *Registry
+	clock    clock.Clock
+}
 
-// NewGenerator creates a new DID generator
+// GeneratorConfig configures a DID generator
+type GeneratorConfig struct {
+	// Pepper keys user hashes, and therefore method-specific IDs, with HMAC-SHA256. It
+	// is the pepper of LegacyPepperVersion.
+	Pepper []byte
+	// Peppers holds versioned peppers by version; old versions stay configured while
+	// records keyed with them exist
+	Peppers map[string][]byte
+	// PepperVersion selects the pepper keying new records, defaulting to LegacyPepperVersion
+	PepperVersion string
+	// PreviousPepperVersion names the version being rotated away from; lookup indexes
+	// are written and searched under it too until they are re-hashed
+	PreviousPepperVersion string
+	// HashScheme selects the commitment scheme for new DIDs, defaulting to HashSchemeArgon2id
+	HashScheme string
+	// SignatureSuite selects the key algorithm for new DIDs, defaulting to SignatureSuiteEd25519
+	SignatureSuite string
+	// Argon2Params tunes the Argon2id scheme; the zero value uses DefaultArgon2Params
+	Argon2Params Argon2Params
+	// ExperimentalSuites allows experimental signature suites, such as ML-DSA, for new keys
+	ExperimentalSuites bool
+	// Registry supplies the algorithm implementations; nil uses NewRegistry
+	Registry *Registry
+	// Clock timestamps user hashes under the legacy scheme; nil uses clock.System
+	Clock clock.Clock
+}
+
+// GeneratedDID is the output of DID generation
+type GeneratedDID struct {
+	DID string
+	// Commitment binds the DID to the user's claims; its Value is the user hash
+	Commitment *Commitment
+	// KeyAlgorithm is the signature suite ID of the generated key
+	KeyAlgorithm  string
+	PublicKey     []byte
+	PrivateKeyHex string
+}
+
+// NewGenerator creates a new DID generator with default settings
 func NewGenerator() *Generator {
-	return &Generator{}
+	generator, _ := NewGeneratorWithConfig(GeneratorConfig{})
+	return generator
+}
+
+// NewGeneratorWithConfig creates a new DID generator from the given configuration
+func NewGeneratorWithConfig(cfg GeneratorConfig) (*Generator, error) {
+	registry := cfg.Registry
+	if registry == nil {
+		registry = NewRegistry()
+	}
+
+	if cfg.Argon2Params != (Argon2Params{}) {
+		registry.RegisterHashScheme(NewArgon2idScheme(cfg.Argon2Params))
+	}
+	registry.EnableExperimentalSuites(cfg.ExperimentalSuites)
+	if cfg.HashScheme != "" {
+		if err := registry.SetDefaultHashScheme(cfg.HashScheme); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SignatureSuite != "" {
+		if err := registry.SetDefaultSignatureSuite(cfg.SignatureSuite); err != nil {
+			return nil, err
+		}
+	}
+
+	peppers := map[string][]byte{LegacyPepperVersion: cfg.Pepper}
+	for version, pepper := range cfg.Peppers {
+		if version == LegacyPepperVersion {
+			return nil, fmt.Errorf("pepper version %s is reserved for the unversioned pepper", LegacyPepperVersion)
+		}
+		peppers[version] = pepper
+	}
+	version := cfg.PepperVersion
+	if version == "" {
+		version = LegacyPepperVersion
+	}
+	for _, v := range []string{version, cfg.PreviousPepperVersion} {
+		if _, ok := peppers[v]; v != "" && !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPepperVersion, v)
+		}
+	}
+
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.System
+	}
+
+	return &Generator{
+		pepper:                peppers[version],
+		pepperVersion:         version,
+		previousPepperVersion: cfg.PreviousPepperVersion,
+		peppers:               peppers,
+		registry:              registry,
+		clock:                 clk,
+	}, nil
+}
+
+// Registry returns the algorithm registry used by the generator
+func (g *Generator) Registry() *Registry {
+	return g.registry
+}
+
+// GenerateDID creates a new DID for a user using the registry's default algorithms
+func (g *Generator) GenerateDID(userID uuid.UUID, name, email string) (*GeneratedDID, error) {
+	return g.GenerateDIDWithSuite(userID, name, email, "")
+}
+
+// GenerateDIDWithSuite creates a new DID for a user with the given signature suite;
+// an empty suite ID uses the registry's default
+func (g *Generator) GenerateDIDWithSuite(userID uuid.UUID, name, email, suiteID string) (*GeneratedDID, error) {
+	suite, err := g.registry.SignatureSuiteForNewKeys(suiteID)
+	if err != nil {
+		return nil, err
+	}
+	return g.generateDID(name, email, suite)
 }
 
-// GenerateDID creates a new DID for a user
-func (g *Generator) GenerateDID(userID uuid.UUID, name, email string) (string, string, string, error) {
-	// Generate Ed25519 key pair
-	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+// GenerateDIDWithSuiteAllowing is GenerateDIDWithSuite with experimental suites
+// allowed per call rather than by the registry-wide setting
+func (g *Generator) GenerateDIDWithSuiteAllowing(userID uuid.UUID, name, email, suiteID string, allowExperimental bool) (*GeneratedDID, error) {
+	suite, err := g.registry.SignatureSuiteForNewKeysAllowing(suiteID, allowExperimental)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate key pair: %w", err)
+		return nil, err
 	}
+	return g.generateDID(name, email, suite)
+}
 
-	// Create user hash from name, email, and timestamp
-	timestamp := time.Now().Unix()
-	userData := fmt.Sprintf("%s:%s:%d", name, email, timestamp)
-	userHash := sha256.Sum256([]byte(userData))
-	userHashHex := hex.EncodeToString(userHash[:])
+// generateDID creates a new DID for a user with a resolved signature suite
+func (g *Generator) generateDID(name, email string, suite SignatureSuite) (*GeneratedDID, error) {
+	scheme := g.registry.DefaultHashScheme()
+
+	// Generate key pair
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	// Create user hash from the canonical name and email (plus a timestamp under the
+	// legacy scheme)
+	userData := CommitmentInput(canonical.Name(name), canonical.Email(email))
+	if scheme.ID() == HashSchemeSHA256 {
+		userData = fmt.Sprintf("%s:%d", userData, g.clock.Now().Unix())
+	}
+
+	commitment, err := scheme.Commit(userData, g.pepper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user hash: %w", err)
+	}
+	commitment.PepperVersion = g.pepperVersion
+	userHashHex := commitment.Value
 
 	// Create DID using the public key and user hash
-	// Format: did:example:user:hash:publickey
-	did := fmt.Sprintf("did:example:user:%s:%s", userHashHex[:16], hex.EncodeToString(publicKey[:16]))
+	// Format: did:example:user:hash:keyid
+	did := NewIdentifier(userHashHex, suite, publicKey)
+
+	return &GeneratedDID{
+		DID:          did,
+		Commitment:   commitment,
+		KeyAlgorithm: suite.ID(),
+		PublicKey:    publicKey,
+		// Convert private key to hex for storage (in production, this should be encrypted)
+		PrivateKeyHex: hex.EncodeToString(privateKey),
+	}, nil
+}
+
+// GenerateAnonymousDID creates a DID that is not bound to any claims, e.g. for
+// anonymous ticketing. The DID is keyed by publicKey when given, which must be a public
+// key of the suite and leaves the private key with the holder; otherwise a key pair is
+// generated. The user hash is random, under HashSchemeNone.
+func (g *Generator) GenerateAnonymousDID(suiteID string, publicKey []byte, allowExperimental bool) (*GeneratedDID, error) {
+	suite, err := g.registry.SignatureSuiteForNewKeysAllowing(suiteID, allowExperimental)
+	if err != nil {
+		return nil, err
+	}
 
-	// Convert private key to hex for storage (in production, this should be encrypted)
-	privateKeyHex := hex.EncodeToString(privateKey)
+	generated := &GeneratedDID{KeyAlgorithm: suite.ID()}
+	if publicKey != nil {
+		// Suites also derive public keys from private keys; only a key that is its own
+		// public key is accepted so private keys are never submitted
+		derived, err := suite.PublicKey(publicKey)
+		if err != nil || !bytes.Equal(derived, publicKey) {
+			return nil, fmt.Errorf("%w: not a %s public key", ErrInvalidPublicKey, suite.ID())
+		}
+		generated.PublicKey = publicKey
+	} else {
+		public, private, err := suite.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		generated.PublicKey = public
+		generated.PrivateKeyHex = hex.EncodeToString(private)
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate user hash: %w", err)
+	}
+	generated.Commitment = &Commitment{Scheme: HashSchemeNone, Value: hex.EncodeToString(nonce)}
+	generated.DID = NewIdentifier(generated.Commitment.Value, suite, generated.PublicKey)
+
+	return generated, nil
+}
+
+// VerifyCommitment checks that userData matches a stored commitment under the scheme
+// and pepper version recorded with it, even if the defaults have since changed
+func (g *Generator) VerifyCommitment(commitment *Commitment, userData string) (bool, error) {
+	if commitment.Scheme == HashSchemeNone {
+		return false, ErrNoCommitment
+	}
+
+	scheme, err := g.registry.HashScheme(commitment.Scheme)
+	if err != nil {
+		return false, err
+	}
+	pepper, err := g.pepperFor(commitment.PepperVersion)
+	if err != nil {
+		return false, err
+	}
+
+	return scheme.Verify(commitment, userData, pepper)
+}
+
+// VerifyClaims checks a name and email against a user hash commitment. Claims are
+// committed in canonical form; commitments made before canonicalization are matched
+// against the claims as given.
+func (g *Generator) VerifyClaims(commitment *Commitment, name, email string) (bool, error) {
+	userData := CommitmentInput(canonical.Name(name), canonical.Email(email))
+	matches, err := g.VerifyCommitment(commitment, userData)
+	if err != nil || matches {
+		return matches, err
+	}
+	if legacy := CommitmentInput(name, email); legacy != userData {
+		return g.VerifyCommitment(commitment, legacy)
+	}
+	return false, nil
+}
+
+// CommitClaim commits to a claim added to a DID after its creation, such as a phone
+// number, under the default scheme and pepper. Claims are always recomputable, so the
+// legacy scheme is replaced by Argon2id.
+func (g *Generator) CommitClaim(claimType, value string) (*Commitment, error) {
+	scheme := g.registry.DefaultHashScheme()
+	if scheme.ID() == HashSchemeSHA256 {
+		var err error
+		if scheme, err = g.registry.HashScheme(HashSchemeArgon2id); err != nil {
+			return nil, err
+		}
+	}
+
+	commitment, err := scheme.Commit(ClaimCommitmentInput(claimType, value), g.pepper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit claim: %w", err)
+	}
+	commitment.PepperVersion = g.pepperVersion
+	return commitment, nil
+}
+
+// ClaimCommitmentInput builds the string committed to by a claim commitment. The type
+// is part of it, so a commitment to one claim cannot verify another with the same value.
+func ClaimCommitmentInput(claimType, value string) string {
+	return fmt.Sprintf("claim:%s:%s", claimType, value)
+}
 
-	return did, userHashHex, privateKeyHex, nil
+// CommitmentInput builds the claims string committed to by a user hash
+func CommitmentInput(name, email string) string {
+	return fmt.Sprintf("%s:%s", name, email)
 }
 
 // GenerateUserHash creates a hash from user data
 func (g *Generator) GenerateUserHash(name, email string) string {
-	timestamp := time.Now().Unix()
+	timestamp := g.clock.Now().Unix()
 	userData := fmt.Sprintf("%s:%s:%d", name, email, timestamp)
-	userHash := sha256.Sum256([]byte(userData))
-	return hex.EncodeToString(userHash[:])
+	return hashUserData(userData, g.pepper)
 }
 
 // ValidateDIDFormat validates if a DID string follows the expected format
@@ -0,0 +1,296 @@
+package did
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"did-manager/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+func TestHashUserDataPepper(t *testing.T) {
+	pepper := []byte("secret-pepper")
+	data := "Alice:alice@example.com:1700000000"
+
+	if hashUserData(data, nil) == hashUserData(data, pepper) {
+		t.Error("peppered hash should differ from unpeppered hash")
+	}
+	if hashUserData(data, pepper) == hashUserData(data, []byte("other-pepper")) {
+		t.Error("hashes with different peppers should differ")
+	}
+	if hashUserData(data, pepper) != hashUserData(data, pepper) {
+		t.Error("peppered hash should be deterministic")
+	}
+	if len(hashUserData(data, pepper)) != 64 {
+		t.Errorf("expected 64 hex characters, got %d", len(hashUserData(data, pepper)))
+	}
+}
+
+func TestGenerateUserHashClock(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	gen := newTestGenerator(t, GeneratorConfig{Pepper: []byte("secret-pepper"), Clock: clk})
+
+	hash := gen.GenerateUserHash("Alice", "alice@example.com")
+	if gen.GenerateUserHash("Alice", "alice@example.com") != hash {
+		t.Error("expected the same user hash while the clock stands still")
+	}
+	clk.Advance(time.Second)
+	if gen.GenerateUserHash("Alice", "alice@example.com") == hash {
+		t.Error("expected a new user hash once the clock moved")
+	}
+}
+
+// newTestGenerator creates a generator with cheap Argon2id parameters
+func newTestGenerator(t *testing.T, cfg GeneratorConfig) *Generator {
+	t.Helper()
+	if cfg.Argon2Params == (Argon2Params{}) {
+		cfg.Argon2Params = Argon2Params{Time: 1, MemoryKiB: 1024, Threads: 1, KeyLength: 32}
+	}
+	gen, err := NewGeneratorWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	return gen
+}
+
+func TestCommitmentRoundTrip(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{Pepper: []byte("pepper")})
+
+	generated, err := gen.GenerateDID(uuid.New(), "Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+	commitment := generated.Commitment
+	if commitment.Scheme != HashSchemeArgon2id {
+		t.Fatalf("expected argon2id scheme, got %s", commitment.Scheme)
+	}
+
+	ok, err := gen.VerifyCommitment(commitment, CommitmentInput("Alice", "alice@example.com"))
+	if err != nil || !ok {
+		t.Fatalf("expected commitment to verify, got %v, %v", ok, err)
+	}
+
+	ok, err = gen.VerifyCommitment(commitment, CommitmentInput("Alice", "mallory@example.com"))
+	if err != nil || ok {
+		t.Fatalf("expected mismatch for different claims, got %v, %v", ok, err)
+	}
+}
+
+func TestVerifyClaimsCanonical(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{Pepper: []byte("pepper")})
+
+	generated, err := gen.GenerateDID(uuid.New(), "  Jose\u0301  Garcia ", "Jose\u0301@Example.COM")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+
+	ok, err := gen.VerifyClaims(generated.Commitment, "José Garcia", "josé@example.com")
+	if err != nil || !ok {
+		t.Fatalf("expected canonical claims to verify, got %v, %v", ok, err)
+	}
+
+	// Commitments made before canonicalization still verify with the claims as given
+	scheme, err := gen.Registry().HashScheme(HashSchemeArgon2id)
+	if err != nil {
+		t.Fatalf("failed to get scheme: %v", err)
+	}
+	legacy, err := scheme.Commit(CommitmentInput("Alice  Smith", "Alice@Example.com"), gen.pepper)
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	legacy.PepperVersion = gen.PepperVersion()
+	ok, err = gen.VerifyClaims(legacy, "Alice  Smith", "Alice@Example.com")
+	if err != nil || !ok {
+		t.Fatalf("expected claims as given to verify, got %v, %v", ok, err)
+	}
+	ok, err = gen.VerifyClaims(legacy, "Alice Smith", "alice@example.com")
+	if err != nil || ok {
+		t.Fatalf("expected mismatch for claims not as given, got %v, %v", ok, err)
+	}
+}
+
+func TestLegacyCommitmentNotRecomputable(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{HashScheme: HashSchemeSHA256})
+
+	generated, err := gen.GenerateDID(uuid.New(), "Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+	commitment := generated.Commitment
+	if commitment.Scheme != HashSchemeSHA256 || commitment.Params != "" {
+		t.Fatalf("unexpected legacy commitment: %+v", commitment)
+	}
+
+	if _, err := gen.VerifyCommitment(commitment, CommitmentInput("Bob", "bob@example.com")); err != ErrCommitmentNotRecomputable {
+		t.Fatalf("expected ErrCommitmentNotRecomputable, got %v", err)
+	}
+}
+
+func TestClaimCommitment(t *testing.T) {
+	for _, scheme := range []string{HashSchemeArgon2id, HashSchemeSHA256} {
+		gen := newTestGenerator(t, GeneratorConfig{Pepper: []byte("pepper"), HashScheme: scheme})
+
+		commitment, err := gen.CommitClaim("phone", "+15551234567")
+		if err != nil {
+			t.Fatalf("failed to commit claim: %v", err)
+		}
+		if commitment.Scheme != HashSchemeArgon2id {
+			t.Fatalf("expected claims to be committed with argon2id under %s, got %s", scheme, commitment.Scheme)
+		}
+
+		ok, err := gen.VerifyCommitment(commitment, ClaimCommitmentInput("phone", "+15551234567"))
+		if err != nil || !ok {
+			t.Fatalf("expected claim to verify, got %v, %v", ok, err)
+		}
+		ok, err = gen.VerifyCommitment(commitment, ClaimCommitmentInput("address", "+15551234567"))
+		if err != nil || ok {
+			t.Fatalf("expected mismatch for another claim type, got %v, %v", ok, err)
+		}
+	}
+}
+
+func TestVerifyAfterDefaultSchemeChange(t *testing.T) {
+	registry := NewRegistry()
+	oldGen := newTestGenerator(t, GeneratorConfig{Registry: registry})
+
+	generated, err := oldGen.GenerateDID(uuid.New(), "Carol", "carol@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+
+	// Switching the default must not break commitments recorded under the old scheme
+	newGen := newTestGenerator(t, GeneratorConfig{Registry: registry, HashScheme: HashSchemeSHA256})
+	ok, err := newGen.VerifyCommitment(generated.Commitment, CommitmentInput("Carol", "carol@example.com"))
+	if err != nil || !ok {
+		t.Fatalf("expected legacy artifact to verify, got %v, %v", ok, err)
+	}
+}
+
+func TestIdentityFingerprints(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{Pepper: []byte("pepper")})
+
+	original := gen.IdentityFingerprints("Dave  Jones", "dave@example.com")
+	variant := gen.IdentityFingerprints("dave jones", " Dave+work@Example.com ")
+	for _, kind := range []string{FingerprintEmail, FingerprintClaims} {
+		if original[kind] == "" || original[kind] != variant[kind] {
+			t.Errorf("expected matching %s fingerprints for normalized variants", kind)
+		}
+	}
+
+	renamed := gen.IdentityFingerprints("David Jones", "dave@example.com")
+	if renamed[FingerprintEmail] != original[FingerprintEmail] {
+		t.Error("expected the email fingerprint to ignore the name")
+	}
+	if renamed[FingerprintClaims] == original[FingerprintClaims] {
+		t.Error("expected the claims fingerprint to change with the name")
+	}
+
+	otherPepper := newTestGenerator(t, GeneratorConfig{Pepper: []byte("other")})
+	if otherPepper.IdentityFingerprints("Dave Jones", "dave@example.com")[FingerprintEmail] == original[FingerprintEmail] {
+		t.Error("expected fingerprints to be keyed by the pepper")
+	}
+}
+
+func TestGenerateAnonymousDID(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{})
+
+	generated, err := gen.GenerateAnonymousDID("", nil, false)
+	if err != nil {
+		t.Fatalf("failed to generate anonymous DID: %v", err)
+	}
+	if generated.Commitment.Scheme != HashSchemeNone || len(generated.Commitment.Value) != 64 {
+		t.Fatalf("expected a random commitment under the none scheme, got %+v", generated.Commitment)
+	}
+	if generated.PrivateKeyHex == "" {
+		t.Fatal("expected a generated key pair")
+	}
+
+	other, err := gen.GenerateAnonymousDID("", nil, false)
+	if err != nil {
+		t.Fatalf("failed to generate anonymous DID: %v", err)
+	}
+	if other.DID == generated.DID || other.Commitment.Value == generated.Commitment.Value {
+		t.Fatal("anonymous DIDs should not collide")
+	}
+
+	_, err = gen.VerifyCommitment(generated.Commitment, CommitmentInput("", ""))
+	if !errors.Is(err, ErrNoCommitment) {
+		t.Fatalf("expected ErrNoCommitment, got %v", err)
+	}
+}
+
+func TestGenerateAnonymousDIDWithClientKey(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{})
+	publicKey, privateKey, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	generated, err := gen.GenerateAnonymousDID(SignatureSuiteEd25519, publicKey, false)
+	if err != nil {
+		t.Fatalf("failed to generate anonymous DID: %v", err)
+	}
+	if generated.PrivateKeyHex != "" {
+		t.Fatal("a client-keyed DID must not carry a private key")
+	}
+	if _, embedded, ok := IdentifierKey(generated.DID); !ok || !bytes.Equal(embedded, publicKey) {
+		t.Fatalf("expected the DID to embed the client key, got %s", generated.DID)
+	}
+
+	if _, err := gen.GenerateAnonymousDID(SignatureSuiteEd25519, privateKey, false); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected a private key to be rejected, got %v", err)
+	}
+	if _, err := gen.GenerateAnonymousDID(SignatureSuiteEd25519, []byte("short"), false); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected a malformed key to be rejected, got %v", err)
+	}
+}
+
+func TestPepperRotation(t *testing.T) {
+	peppers, err := ParsePeppers("v1:first-secret, v2:second-secret")
+	if err != nil {
+		t.Fatalf("failed to parse peppers: %v", err)
+	}
+	before := newTestGenerator(t, GeneratorConfig{Pepper: []byte("legacy"), Peppers: peppers, PepperVersion: "v1"})
+	generated, err := before.GenerateDID(uuid.New(), "Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate DID: %v", err)
+	}
+	if generated.Commitment.PepperVersion != "v1" {
+		t.Fatalf("expected commitment keyed with v1, got %q", generated.Commitment.PepperVersion)
+	}
+
+	// After rotating to v2 the commitment still verifies under the version it records
+	after := newTestGenerator(t, GeneratorConfig{Pepper: []byte("legacy"), Peppers: peppers, PepperVersion: "v2", PreviousPepperVersion: "v1"})
+	ok, err := after.VerifyCommitment(generated.Commitment, CommitmentInput("Alice", "alice@example.com"))
+	if err != nil || !ok {
+		t.Fatalf("expected commitment to verify after rotation, got %v, %v", ok, err)
+	}
+
+	// Lookup indexes are dual-written under both versions during the rotation
+	indexes, err := after.UserHashIndexes(generated.Commitment.Value)
+	if err != nil {
+		t.Fatalf("failed to derive lookup indexes: %v", err)
+	}
+	if len(indexes) != 2 || indexes["v1"] == indexes["v2"] {
+		t.Fatalf("expected distinct v1 and v2 lookup indexes, got %v", indexes)
+	}
+	if previous, _ := before.UserHashIndex("v1", generated.Commitment.Value); previous != indexes["v1"] {
+		t.Error("v1 lookup index should not change with the current version")
+	}
+
+	// Records keyed with a version that is no longer configured fail loudly
+	retired := newTestGenerator(t, GeneratorConfig{Peppers: map[string][]byte{"v2": []byte("second-secret")}, PepperVersion: "v2"})
+	if _, err := retired.VerifyCommitment(generated.Commitment, CommitmentInput("Alice", "alice@example.com")); !errors.Is(err, ErrUnknownPepperVersion) {
+		t.Fatalf("expected ErrUnknownPepperVersion, got %v", err)
+	}
+
+	if _, err := ParsePeppers("v0:secret"); err == nil {
+		t.Error("expected the legacy version to be reserved")
+	}
+	if _, err := NewGeneratorWithConfig(GeneratorConfig{PepperVersion: "v3"}); !errors.Is(err, ErrUnknownPepperVersion) {
+		t.Errorf("expected an unconfigured current version to be rejected, got %v", err)
+	}
+}
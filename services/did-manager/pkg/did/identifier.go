@@ -0,0 +1,112 @@
+package did
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DIDs are did:example:user:<user hash prefix>:<key ID>. The key ID commits to the
+// DID's inception key: for suites with a multicodec it is the full key as a base58btc
+// multibase value, like a did:key identifier, so signatures made with that key verify
+// against the DID alone; post-quantum keys are too large for an identifier and are
+// committed to by a multibase SHA-256 multihash, their key taken from the document.
+//
+// Legacy DIDs carry the first 16 bytes of their key in hex instead, which neither
+// identifies the key nor verifies anything. They keep their identifier, which is
+// anchored, and are given a current identifier as an alias resolving to them.
+const (
+	identifierPrefix = "did:example:user:"
+	// userHashPrefixLength is the number of hex characters of the user hash in a DID
+	userHashPrefixLength = 16
+)
+
+// Identifier formats of DIDs managed here, recorded by identifier migrations so that
+// later format changes are migrated the same way
+const (
+	// IdentifierFormatLegacy identifiers carry the first 16 bytes of the key in hex
+	IdentifierFormatLegacy = "hex-key-prefix"
+	// IdentifierFormatMultibaseKey identifiers carry the multibase key or its multihash
+	IdentifierFormatMultibaseKey = "multibase-key"
+)
+
+// multihashSHA256 prefixes SHA-256 digests as multihashes
+var multihashSHA256 = []byte{0x12, 0x20}
+
+// legacyKeyID matches the key ID of legacy DIDs: 16 key bytes in hex
+var legacyKeyID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// KeyID derives the key ID of a DID from its inception key
+func KeyID(verificationMethodType string, publicKey []byte) string {
+	if encoded, err := EncodeMultibase(verificationMethodType, publicKey); err == nil {
+		return encoded
+	}
+	digest := sha256.Sum256(publicKey)
+	return "z" + encodeBase58(append(bytes.Clone(multihashSHA256), digest[:]...))
+}
+
+// NewIdentifier builds the DID of a user hash and inception key of a signature suite
+func NewIdentifier(userHash string, suite SignatureSuite, publicKey []byte) string {
+	return identifierPrefix + userHash[:userHashPrefixLength] + ":" + KeyID(suite.VerificationMethodType(), publicKey)
+}
+
+// IsLegacyIdentifier reports whether a DID carries the truncated hex key of legacy
+// identifiers
+func IsLegacyIdentifier(did string) bool {
+	segments := strings.Split(strings.TrimPrefix(did, identifierPrefix), ":")
+	return strings.HasPrefix(did, identifierPrefix) && len(segments) == 2 && legacyKeyID.MatchString(segments[1])
+}
+
+// IdentifierFormat reports the format of a DID managed here, or empty for other DIDs
+func IdentifierFormat(did string) string {
+	if IsLegacyIdentifier(did) {
+		return IdentifierFormatLegacy
+	}
+	segments := strings.Split(strings.TrimPrefix(did, identifierPrefix), ":")
+	if !strings.HasPrefix(did, identifierPrefix) || len(segments) != 2 || !strings.HasPrefix(segments[1], "z") {
+		return ""
+	}
+	if _, err := decodeBase58(segments[1][1:]); err != nil {
+		return ""
+	}
+	return IdentifierFormatMultibaseKey
+}
+
+// MigrateIdentifier derives the current identifier of a legacy DID from its inception
+// key, keeping its user hash prefix. The key must be the one the legacy identifier was
+// created with.
+func MigrateIdentifier(legacyDID string, suite SignatureSuite, publicKey []byte) (string, error) {
+	if !IsLegacyIdentifier(legacyDID) {
+		return "", fmt.Errorf("%s is not a legacy identifier", legacyDID)
+	}
+	userHash, keyID, _ := strings.Cut(strings.TrimPrefix(legacyDID, identifierPrefix), ":")
+	if len(publicKey) < 16 || hex.EncodeToString(publicKey[:16]) != keyID {
+		return "", fmt.Errorf("key of %s is not its inception key", legacyDID)
+	}
+	return identifierPrefix + userHash + ":" + KeyID(suite.VerificationMethodType(), publicKey), nil
+}
+
+// IdentifierKey returns the inception key embedded in a DID with its verification
+// method type, or false when the DID commits to its key by hash or is legacy
+func IdentifierKey(did string) (string, []byte, bool) {
+	if !strings.HasPrefix(did, identifierPrefix) || IsLegacyIdentifier(did) {
+		return "", nil, false
+	}
+	keyID := did[strings.LastIndex(did, ":")+1:]
+	methodType, publicKey, err := DecodeMultibase(keyID)
+	if err != nil {
+		return "", nil, false
+	}
+	return methodType, publicKey, true
+}
+
+// CommitsToKey reports whether a current DID identifier commits to a public key
+func CommitsToKey(did, verificationMethodType string, publicKey []byte) bool {
+	if !strings.HasPrefix(did, identifierPrefix) || IsLegacyIdentifier(did) {
+		return false
+	}
+	return did[strings.LastIndex(did, ":")+1:] == KeyID(verificationMethodType, publicKey)
+}
@@ -0,0 +1,89 @@
+package did
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestIdentifierEmbedsKey(t *testing.T) {
+	suite := ed25519Suite{}
+	publicKey, _, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	did := NewIdentifier("94b97f078270a88c0123", suite, publicKey)
+	if !ValidSyntax(did) {
+		t.Fatalf("expected a valid DID, got %s", did)
+	}
+	if IsLegacyIdentifier(did) {
+		t.Error("a current identifier should not be legacy")
+	}
+	methodType, embedded, ok := IdentifierKey(did)
+	if !ok || methodType != suite.VerificationMethodType() || !bytes.Equal(embedded, publicKey) {
+		t.Fatalf("expected the full key in %s", did)
+	}
+	if !CommitsToKey(did, suite.VerificationMethodType(), publicKey) {
+		t.Error("expected the DID to commit to its key")
+	}
+
+	other, _, _ := suite.GenerateKey()
+	if CommitsToKey(did, suite.VerificationMethodType(), other) {
+		t.Error("expected the DID not to commit to another key")
+	}
+}
+
+func TestIdentifierHashesPostQuantumKeys(t *testing.T) {
+	suite := mldsa65Suite{}
+	publicKey, _, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	did := NewIdentifier("94b97f078270a88c", suite, publicKey)
+	if len(did) > 100 {
+		t.Errorf("expected the key to be committed to by hash, got a %d character DID", len(did))
+	}
+	if _, _, ok := IdentifierKey(did); ok {
+		t.Error("a hash-based identifier carries no key")
+	}
+	if !CommitsToKey(did, suite.VerificationMethodType(), publicKey) {
+		t.Error("expected the DID to commit to its key")
+	}
+}
+
+func TestMigrateIdentifier(t *testing.T) {
+	suite := ed25519Suite{}
+	publicKey, _, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	legacy := "did:example:user:94b97f078270a88c:" + hex.EncodeToString(publicKey[:16])
+	if !IsLegacyIdentifier(legacy) {
+		t.Fatalf("expected %s to be legacy", legacy)
+	}
+
+	migrated, err := MigrateIdentifier(legacy, suite, publicKey)
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if migrated != NewIdentifier("94b97f078270a88c", suite, publicKey) {
+		t.Errorf("expected the current identifier with the same user hash, got %s", migrated)
+	}
+	if IdentifierFormat(legacy) != IdentifierFormatLegacy || IdentifierFormat(migrated) != IdentifierFormatMultibaseKey {
+		t.Errorf("expected %s then %s formats", IdentifierFormatLegacy, IdentifierFormatMultibaseKey)
+	}
+	if IdentifierFormat("did:ion:EiDahaOGH") != "" {
+		t.Error("expected DIDs of other methods to have no identifier format")
+	}
+
+	other, _, _ := suite.GenerateKey()
+	if _, err := MigrateIdentifier(legacy, suite, other); err == nil {
+		t.Error("expected a key other than the inception key to be rejected")
+	}
+	if _, err := MigrateIdentifier(migrated, suite, publicKey); err == nil {
+		t.Error("expected current identifiers not to be migrated")
+	}
+}
@@ -0,0 +1,202 @@
+package did
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Payloads are encrypted to a DID's keyAgreement key as compact JWEs (RFC 7516) using
+// ECDH-ES direct key agreement with an ephemeral X25519 key (RFC 8037) and A256GCM
+// content encryption, readable by any JOSE library.
+const (
+	JWEAlgorithmECDHES   = "ECDH-ES"
+	JWEEncryptionA256GCM = "A256GCM"
+	// JWEMediaType is the media type of compact JWEs, e.g. encrypted webhook payloads
+	JWEMediaType = "application/jose"
+
+	jweKeySize = 32
+)
+
+// ErrInvalidJWE is returned for JWEs that are malformed, use unsupported algorithms or
+// do not decrypt
+var ErrInvalidJWE = errors.New("invalid JWE")
+
+// JWEHeader is the protected header of a JWE encrypted to a key agreement key
+type JWEHeader struct {
+	Algorithm  string `json:"alg"`
+	Encryption string `json:"enc"`
+	// KeyID is the verification method of the recipient's key agreement key
+	KeyID string `json:"kid,omitempty"`
+	// ContentType describes the plaintext, e.g. "json"
+	ContentType  string     `json:"cty,omitempty"`
+	EphemeralKey *jweOKPKey `json:"epk"`
+}
+
+// jweOKPKey is an X25519 public key as a JWK
+type jweOKPKey struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+}
+
+// GenerateKeyAgreementKey generates an X25519 key agreement key pair
+func GenerateKeyAgreementKey() (publicKey, privateKey []byte, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.PublicKey().Bytes(), key.Bytes(), nil
+}
+
+// EncryptJWE encrypts plaintext to an X25519 public key, naming the recipient's key
+// kid and the plaintext's content type contentType when they are not empty
+func EncryptJWE(recipientKey []byte, kid, contentType string, plaintext []byte) (string, error) {
+	recipient, err := ecdh.X25519().NewPublicKey(recipientKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient key: %w", err)
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", fmt.Errorf("key agreement failed: %w", err)
+	}
+
+	header, err := json.Marshal(&JWEHeader{
+		Algorithm:   JWEAlgorithmECDHES,
+		Encryption:  JWEEncryptionA256GCM,
+		KeyID:       kid,
+		ContentType: contentType,
+		EphemeralKey: &jweOKPKey{
+			KeyType: "OKP",
+			Curve:   "X25519",
+			X:       base64.RawURLEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(header)
+
+	gcm, err := jweCipher(shared)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate IV: %w", err)
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	// Direct key agreement has no encrypted key, leaving its part empty
+	return strings.Join([]string{
+		protected,
+		"",
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts a compact JWE encrypted with EncryptJWE using the recipient's
+// X25519 private key, returning the plaintext and the protected header
+func DecryptJWE(privateKey []byte, token string) ([]byte, *JWEHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, nil, fmt.Errorf("%w: expected a compact JWE with direct key agreement", ErrInvalidJWE)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed header", ErrInvalidJWE)
+	}
+	var header JWEHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed header", ErrInvalidJWE)
+	}
+	if header.Algorithm != JWEAlgorithmECDHES || header.Encryption != JWEEncryptionA256GCM {
+		return nil, nil, fmt.Errorf("%w: unsupported algorithm %s/%s", ErrInvalidJWE, header.Algorithm, header.Encryption)
+	}
+	if header.EphemeralKey == nil || header.EphemeralKey.KeyType != "OKP" || header.EphemeralKey.Curve != "X25519" {
+		return nil, nil, fmt.Errorf("%w: expected an X25519 ephemeral key", ErrInvalidJWE)
+	}
+
+	epk, err := base64.RawURLEncoding.DecodeString(header.EphemeralKey.X)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed ephemeral key", ErrInvalidJWE)
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed ephemeral key", ErrInvalidJWE)
+	}
+	recipient, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid recipient key: %w", err)
+	}
+	shared, err := recipient.ECDH(ephemeral)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: key agreement failed", ErrInvalidJWE)
+	}
+
+	var decoded [3][]byte
+	for i, part := range parts[2:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("%w: malformed encoding", ErrInvalidJWE)
+		}
+	}
+	iv, ciphertext, tag := decoded[0], decoded[1], decoded[2]
+
+	gcm, err := jweCipher(shared)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, nil, fmt.Errorf("%w: malformed IV or tag", ErrInvalidJWE)
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: decryption failed", ErrInvalidJWE)
+	}
+
+	return plaintext, &header, nil
+}
+
+// jweCipher derives the content encryption key from a shared secret and returns its
+// AES-GCM cipher
+func jweCipher(shared []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(concatKDF(shared, JWEEncryptionA256GCM, jweKeySize))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// concatKDF derives a key from a shared secret with the Concat KDF of NIST SP 800-56A
+// as profiled for ECDH-ES by RFC 7518 section 4.6.2, with empty party information. A
+// single SHA-256 round covers keys of up to 32 bytes.
+func concatKDF(shared []byte, algorithmID string, keySize int) []byte {
+	var otherInfo []byte
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(algorithmID)))
+	otherInfo = append(otherInfo, algorithmID...)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, 0) // PartyUInfo
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, 0) // PartyVInfo
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keySize*8))
+
+	hash := sha256.New()
+	hash.Write([]byte{0, 0, 0, 1})
+	hash.Write(shared)
+	hash.Write(otherInfo)
+	return hash.Sum(nil)[:keySize]
+}
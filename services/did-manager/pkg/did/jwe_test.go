@@ -0,0 +1,81 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJWERoundTrip(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeyAgreementKey()
+	if err != nil {
+		t.Fatalf("failed to generate key agreement key: %v", err)
+	}
+
+	plaintext := []byte(`{"event":"did.created"}`)
+	token, err := EncryptJWE(publicKey, "did:example:abc#agree-1", "json", plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	if strings.Contains(token, "did.created") {
+		t.Fatal("JWE should not contain the plaintext")
+	}
+
+	decrypted, header, err := DecryptJWE(privateKey, token)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+	if string(decrypted) != string(plaintext) {
+		t.Errorf("expected %s, got %s", plaintext, decrypted)
+	}
+	if header.Algorithm != JWEAlgorithmECDHES || header.Encryption != JWEEncryptionA256GCM {
+		t.Errorf("unexpected algorithms %s/%s", header.Algorithm, header.Encryption)
+	}
+	if header.KeyID != "did:example:abc#agree-1" || header.ContentType != "json" {
+		t.Errorf("unexpected kid %q or cty %q", header.KeyID, header.ContentType)
+	}
+
+	again, err := EncryptJWE(publicKey, "", "", plaintext)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	if again == token {
+		t.Error("every encryption should use a fresh ephemeral key and IV")
+	}
+}
+
+func TestJWERejectsTamperingAndOtherKeys(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeyAgreementKey()
+	if err != nil {
+		t.Fatalf("failed to generate key agreement key: %v", err)
+	}
+	_, otherKey, err := GenerateKeyAgreementKey()
+	if err != nil {
+		t.Fatalf("failed to generate key agreement key: %v", err)
+	}
+
+	token, err := EncryptJWE(publicKey, "", "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	if _, _, err := DecryptJWE(otherKey, token); !errors.Is(err, ErrInvalidJWE) {
+		t.Errorf("expected ErrInvalidJWE for another recipient, got %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := []byte(parts[3])
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+	parts[3] = string(tampered)
+	if _, _, err := DecryptJWE(privateKey, strings.Join(parts, ".")); !errors.Is(err, ErrInvalidJWE) {
+		t.Errorf("expected ErrInvalidJWE for a tampered ciphertext, got %v", err)
+	}
+
+	if _, _, err := DecryptJWE(privateKey, "not.a.jwe"); !errors.Is(err, ErrInvalidJWE) {
+		t.Errorf("expected ErrInvalidJWE for a malformed token, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package did
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// KeyFormat is the representation of public keys in served DID documents
+type KeyFormat string
+
+const (
+	// KeyFormatMultibase serves keys as publicKeyMultibase: base58btc multicodec keys,
+	// as the Ed25519 and X25519 2020 verification method types define
+	KeyFormatMultibase KeyFormat = "multibase"
+	// KeyFormatJWK serves keys as publicKeyJwk in JsonWebKey2020 verification methods
+	KeyFormatJWK KeyFormat = "jwk"
+	// KeyFormatHex serves keys as publicKeyHex, the representation of documents
+	// served before standard representations were supported
+	KeyFormatHex KeyFormat = "hex"
+)
+
+// Verification method type and context of keys served as JWKs
+const (
+	VerificationMethodJWK = "JsonWebKey2020"
+	ContextJWS2020V1      = "https://w3id.org/security/suites/jws-2020/v1"
+)
+
+// ErrUnsupportedKeyFormat is returned for keys without a representation in a format
+var ErrUnsupportedKeyFormat = errors.New("unsupported key format")
+
+// maxBase58Length bounds the base58 strings decoded, well above the length of any key
+// with a multicodec. Decoding takes time quadratic in the length, and multibase keys
+// come from the documents of foreign DIDs.
+const maxBase58Length = 512
+
+// keyEncoding maps a verification method type to the multicodec prefix and JWK curve of
+// its keys. Post-quantum and hybrid keys have neither registered yet and keep their hex
+// representation.
+type keyEncoding struct {
+	multicodec []byte
+	curve      string
+}
+
+var keyEncodings = map[string]keyEncoding{
+	"Ed25519VerificationKey2020": {multicodec: []byte{0xed, 0x01}, curve: "Ed25519"},
+	VerificationMethodX25519:     {multicodec: []byte{0xec, 0x01}, curve: "X25519"},
+}
+
+// ParseKeyFormat parses a key format; empty selects multibase
+func ParseKeyFormat(value string) (KeyFormat, error) {
+	switch format := KeyFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return KeyFormatMultibase, nil
+	case KeyFormatMultibase, KeyFormatJWK, KeyFormatHex:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %s, expected multibase, jwk or hex", ErrUnsupportedKeyFormat, value)
+	}
+}
+
+// EncodeMultibase encodes a public key of a verification method type as a base58btc
+// multibase multicodec key, as used by publicKeyMultibase and did:key
+func EncodeMultibase(verificationMethodType string, publicKey []byte) (string, error) {
+	encoding, ok := keyEncodings[verificationMethodType]
+	if !ok {
+		return "", fmt.Errorf("%w: %s keys have no multicodec", ErrUnsupportedKeyFormat, verificationMethodType)
+	}
+	return "z" + encodeBase58(append(bytes.Clone(encoding.multicodec), publicKey...)), nil
+}
+
+// DecodeMultibase decodes a base58btc multibase multicodec key, returning the
+// verification method type its multicodec identifies and the key
+func DecodeMultibase(value string) (string, []byte, error) {
+	if !strings.HasPrefix(value, "z") {
+		return "", nil, fmt.Errorf("%w: only base58btc multibase keys are supported", ErrUnsupportedKeyFormat)
+	}
+	decoded, err := decodeBase58(value[1:])
+	if err != nil {
+		return "", nil, err
+	}
+	for methodType, encoding := range keyEncodings {
+		if bytes.HasPrefix(decoded, encoding.multicodec) {
+			return methodType, decoded[len(encoding.multicodec):], nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: unknown multicodec", ErrUnsupportedKeyFormat)
+}
+
+// PublicKeyJWK returns the JWK of a public key of a verification method type
+func PublicKeyJWK(verificationMethodType string, publicKey []byte) (*JWK, error) {
+	encoding, ok := keyEncodings[verificationMethodType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s keys have no JWK", ErrUnsupportedKeyFormat, verificationMethodType)
+	}
+	return &JWK{Kty: "OKP", Crv: encoding.curve, X: base64.RawURLEncoding.EncodeToString(publicKey)}, nil
+}
+
+// PublicKey decodes the public key of the verification method from whichever
+// representation it carries
+func (m *VerificationMethod) PublicKey() ([]byte, error) {
+	switch {
+	case m.PublicKeyMultibase != "":
+		_, key, err := DecodeMultibase(m.PublicKeyMultibase)
+		return key, err
+	case m.PublicKeyJwk != nil:
+		if m.PublicKeyJwk.Kty != "OKP" {
+			return nil, fmt.Errorf("%w: JWK key type %s", ErrUnsupportedKeyFormat, m.PublicKeyJwk.Kty)
+		}
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(m.PublicKeyJwk.X, "="))
+	case m.PublicKeyHex != "":
+		return hex.DecodeString(m.PublicKeyHex)
+	default:
+		return nil, fmt.Errorf("verification method %s has no public key", m.ID)
+	}
+}
+
+// EncodeKeys re-encodes the hex keys of the document's verification methods in a key
+// format. Keys without a representation in the format keep publicKeyHex.
+func (d *Document) EncodeKeys(format KeyFormat) error {
+	if format == KeyFormatHex {
+		return nil
+	}
+
+	for i := range d.VerificationMethod {
+		method := &d.VerificationMethod[i]
+		if method.PublicKeyHex == "" {
+			continue
+		}
+		if _, ok := keyEncodings[method.Type]; !ok {
+			continue
+		}
+		publicKey, err := hex.DecodeString(method.PublicKeyHex)
+		if err != nil {
+			return fmt.Errorf("malformed key in %s: %w", method.ID, err)
+		}
+
+		switch format {
+		case KeyFormatMultibase:
+			if method.PublicKeyMultibase, err = EncodeMultibase(method.Type, publicKey); err != nil {
+				return err
+			}
+		case KeyFormatJWK:
+			if method.PublicKeyJwk, err = PublicKeyJWK(method.Type, publicKey); err != nil {
+				return err
+			}
+			method.Type = VerificationMethodJWK
+			if !d.hasContext(ContextJWS2020V1) {
+				d.Context = append(d.Context, ContextJWS2020V1)
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedKeyFormat, format)
+		}
+		method.PublicKeyHex = ""
+	}
+	return nil
+}
+
+// base58Alphabet is the Bitcoin base58 alphabet of base58btc multibase
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeBase58 encodes bytes as Bitcoin base58, keeping leading zero bytes as '1's
+func encodeBase58(data []byte) string {
+	number := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+// decodeBase58 decodes Bitcoin base58
+func decodeBase58(encoded string) ([]byte, error) {
+	if len(encoded) > maxBase58Length {
+		return nil, fmt.Errorf("base58 string exceeds %d characters", maxBase58Length)
+	}
+	number := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range encoded {
+		digit := strings.IndexRune(base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		number.Mul(number, radix)
+		number.Add(number, big.NewInt(int64(digit)))
+	}
+
+	var zeros int
+	for zeros < len(encoded) && encoded[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), number.Bytes()...), nil
+}
@@ -0,0 +1,120 @@
+package did
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	if encoded := encodeBase58([]byte("Hello World!")); encoded != "2NEpo7TZRRrLZSi2U" {
+		t.Errorf("expected 2NEpo7TZRRrLZSi2U, got %s", encoded)
+	}
+
+	data := []byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}
+	encoded := encodeBase58(data)
+	if !strings.HasPrefix(encoded, "11") {
+		t.Errorf("expected leading zero bytes as 1s, got %s", encoded)
+	}
+	decoded, err := decodeBase58(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("expected %x, got %x", data, decoded)
+	}
+
+	if _, err := decodeBase58("0OIl"); err == nil {
+		t.Error("expected characters outside the alphabet to be rejected")
+	}
+}
+
+func TestMultibaseKeys(t *testing.T) {
+	publicKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	encoded, err := EncodeMultibase("Ed25519VerificationKey2020", publicKey)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	// Ed25519 multicodec keys all start with z6Mk, as in did:key identifiers
+	if !strings.HasPrefix(encoded, "z6Mk") {
+		t.Errorf("expected an Ed25519 multibase key, got %s", encoded)
+	}
+	methodType, decoded, err := DecodeMultibase(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if methodType != "Ed25519VerificationKey2020" || !bytes.Equal(decoded, publicKey) {
+		t.Errorf("round trip returned %s %x", methodType, decoded)
+	}
+
+	agreementKey, _, err := GenerateKeyAgreementKey()
+	if err != nil {
+		t.Fatalf("failed to generate key agreement key: %v", err)
+	}
+	encoded, err = EncodeMultibase(VerificationMethodX25519, agreementKey)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "z6LS") {
+		t.Errorf("expected an X25519 multibase key, got %s", encoded)
+	}
+
+	if _, err := EncodeMultibase("MLDSA65VerificationKey2024", publicKey); err == nil {
+		t.Error("expected post-quantum keys to have no multicodec")
+	}
+}
+
+func TestEncodeKeys(t *testing.T) {
+	publicKey, _, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	for _, format := range []KeyFormat{KeyFormatMultibase, KeyFormatJWK, KeyFormatHex} {
+		doc := NewDocument("did:example:user:abc", "Ed25519VerificationKey2020", publicKey)
+		doc.AddAuthenticationMethod(doc.ID+"#pq-1", "MLDSA65VerificationKey2024", []byte{1, 2, 3})
+		if err := doc.EncodeKeys(format); err != nil {
+			t.Fatalf("failed to encode keys as %s: %v", format, err)
+		}
+
+		method := doc.VerificationMethod[0]
+		switch format {
+		case KeyFormatMultibase:
+			if method.PublicKeyMultibase == "" || method.PublicKeyHex != "" {
+				t.Errorf("expected only publicKeyMultibase, got %+v", method)
+			}
+		case KeyFormatJWK:
+			if method.PublicKeyJwk == nil || method.PublicKeyHex != "" || method.Type != VerificationMethodJWK {
+				t.Errorf("expected a JsonWebKey2020 method, got %+v", method)
+			}
+			if !doc.hasContext(ContextJWS2020V1) {
+				t.Error("expected the JWS 2020 context")
+			}
+			if suite, key, err := method.JWKPublicKey(); err != nil || suite != SignatureSuiteEd25519 || !bytes.Equal(key, publicKey) {
+				t.Errorf("JWK does not carry the key: %v", err)
+			}
+		case KeyFormatHex:
+			if method.PublicKeyHex == "" {
+				t.Errorf("expected publicKeyHex, got %+v", method)
+			}
+		}
+
+		decoded, err := method.PublicKey()
+		if err != nil || !bytes.Equal(decoded, publicKey) {
+			t.Errorf("%s key does not decode to the public key: %v", format, err)
+		}
+		if doc.VerificationMethod[1].PublicKeyHex != "010203" {
+			t.Errorf("expected keys without a %s representation to stay hex", format)
+		}
+	}
+
+	if _, err := ParseKeyFormat("pem"); err == nil {
+		t.Error("expected unknown key formats to be rejected")
+	}
+	if format, err := ParseKeyFormat(""); err != nil || format != KeyFormatMultibase {
+		t.Errorf("expected multibase by default, got %s", format)
+	}
+}
@@ -0,0 +1,89 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LegacyPepperVersion is the version of the unversioned GeneratorConfig.Pepper, which
+// keyed every record created before peppers were versioned
+const LegacyPepperVersion = "v0"
+
+// ErrUnknownPepperVersion is returned when a record is keyed with a pepper version
+// that is not configured
+var ErrUnknownPepperVersion = errors.New("unknown pepper version")
+
+// ParsePeppers parses versioned peppers given as comma-separated "<version>:<secret>"
+// pairs. The legacy version is reserved for the unversioned pepper.
+func ParsePeppers(spec string) (map[string][]byte, error) {
+	peppers := make(map[string][]byte)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		version, secret, ok := strings.Cut(entry, ":")
+		if !ok || version == "" || secret == "" {
+			return nil, fmt.Errorf("invalid pepper entry, expected <version>:<secret>")
+		}
+		if version == LegacyPepperVersion {
+			return nil, fmt.Errorf("pepper version %s is reserved for the unversioned pepper", LegacyPepperVersion)
+		}
+		if _, exists := peppers[version]; exists {
+			return nil, fmt.Errorf("duplicate pepper version %s", version)
+		}
+		peppers[version] = []byte(secret)
+	}
+	return peppers, nil
+}
+
+// PepperVersion returns the version of the pepper keying new records
+func (g *Generator) PepperVersion() string {
+	return g.pepperVersion
+}
+
+// LookupVersions returns the pepper versions lookup indexes are written and searched
+// under: the current version and, while a rotation is in progress, the previous one
+func (g *Generator) LookupVersions() []string {
+	if g.previousPepperVersion == "" || g.previousPepperVersion == g.pepperVersion {
+		return []string{g.pepperVersion}
+	}
+	return []string{g.pepperVersion, g.previousPepperVersion}
+}
+
+// pepperFor returns the pepper of a version; an empty version is the legacy version
+func (g *Generator) pepperFor(version string) ([]byte, error) {
+	if version == "" {
+		version = LegacyPepperVersion
+	}
+	pepper, ok := g.peppers[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPepperVersion, version)
+	}
+	return pepper, nil
+}
+
+// UserHashIndex derives the lookup index of a user hash under a pepper version. User
+// hashes are stored, so unlike commitments and fingerprints the index can be re-hashed
+// under a new pepper without the user's claims.
+func (g *Generator) UserHashIndex(version, userHash string) (string, error) {
+	pepper, err := g.pepperFor(version)
+	if err != nil {
+		return "", err
+	}
+	return hashUserData("user_hash:"+userHash, pepper), nil
+}
+
+// UserHashIndexes derives the lookup indexes of a user hash under every lookup version
+func (g *Generator) UserHashIndexes(userHash string) (map[string]string, error) {
+	indexes := make(map[string]string)
+	for _, version := range g.LookupVersions() {
+		index, err := g.UserHashIndex(version, userHash)
+		if err != nil {
+			return nil, err
+		}
+		indexes[version] = index
+	}
+	return indexes, nil
+}
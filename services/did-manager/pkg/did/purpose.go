@@ -0,0 +1,88 @@
+package did
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Verification relationships of a DID document. DIDs created with purpose keys have a
+// separate key for each; their own key, #key-1, is the capabilityInvocation key that
+// controls the DID.
+const (
+	PurposeAuthentication       = ProofPurposeAuthentication
+	PurposeAssertionMethod      = ProofPurposeAssertionMethod
+	PurposeKeyAgreement         = "keyAgreement"
+	PurposeCapabilityInvocation = "capabilityInvocation"
+)
+
+// KeyAgreementX25519 is the algorithm of keyAgreement keys: X25519, as used by DIDComm
+const KeyAgreementX25519 = "x25519-2020"
+
+// X25519 key agreement verification method type and its context
+const (
+	VerificationMethodX25519 = "X25519KeyAgreementKey2020"
+	ContextX25519V1          = "https://w3id.org/security/suites/x25519-2020/v1"
+)
+
+// purposeFragments names the verification method of each generated purpose key
+var purposeFragments = map[string]string{
+	PurposeAuthentication:  "auth-1",
+	PurposeAssertionMethod: "assert-1",
+	PurposeKeyAgreement:    "agree-1",
+}
+
+// PurposeKey is a key generated for a single verification relationship of a DID
+type PurposeKey struct {
+	Purpose string
+	// Fragment identifies the key's verification method within the DID document
+	Fragment      string
+	KeyAlgorithm  string
+	PublicKey     []byte
+	PrivateKeyHex string
+}
+
+// PurposeKeyFragment returns the fragment of the verification method generated for a
+// purpose, or false when no key is generated for it
+func PurposeKeyFragment(purpose string) (string, bool) {
+	fragment, ok := purposeFragments[purpose]
+	return fragment, ok
+}
+
+// GeneratePurposeKeys generates separate authentication, assertionMethod and
+// keyAgreement keys for a DID whose own key is of the given signature suite. The
+// signing keys use the same suite; the keyAgreement key is X25519.
+func (g *Generator) GeneratePurposeKeys(suiteID string) ([]*PurposeKey, error) {
+	suite, err := g.registry.SignatureSuite(suiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*PurposeKey
+	for _, purpose := range []string{PurposeAuthentication, PurposeAssertionMethod} {
+		publicKey, privateKey, err := suite.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s key: %w", purpose, err)
+		}
+		keys = append(keys, &PurposeKey{
+			Purpose:       purpose,
+			Fragment:      purposeFragments[purpose],
+			KeyAlgorithm:  suite.ID(),
+			PublicKey:     publicKey,
+			PrivateKeyHex: hex.EncodeToString(privateKey),
+		})
+	}
+
+	publicKey, privateKey, err := GenerateKeyAgreementKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", PurposeKeyAgreement, err)
+	}
+	keys = append(keys, &PurposeKey{
+		Purpose:       PurposeKeyAgreement,
+		Fragment:      purposeFragments[PurposeKeyAgreement],
+		KeyAlgorithm:  KeyAgreementX25519,
+		PublicKey:     publicKey,
+		PrivateKeyHex: hex.EncodeToString(privateKey),
+	})
+
+	return keys, nil
+}
@@ -0,0 +1,264 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// HashScheme derives and verifies user hash commitments under one versioned algorithm
+type HashScheme interface {
+	// ID is the versioned scheme identifier stored with every commitment
+	ID() string
+	// Commit creates a commitment over userData
+	Commit(userData string, pepper []byte) (*Commitment, error)
+	// Verify checks userData against a commitment created by this scheme
+	Verify(commitment *Commitment, userData string, pepper []byte) (bool, error)
+}
+
+// SignatureSuite generates keys and creates and verifies signatures under one
+// versioned algorithm
+type SignatureSuite interface {
+	// ID is the versioned suite identifier stored with every key and proof
+	ID() string
+	// VerificationMethodType is the DID Document verification method type for keys of this suite
+	VerificationMethodType() string
+	// GenerateKey creates a new key pair
+	GenerateKey() (publicKey, privateKey []byte, err error)
+	// PublicKey extracts the public key from stored key material (public or private key)
+	PublicKey(keyMaterial []byte) ([]byte, error)
+	// Sign signs message with privateKey
+	Sign(privateKey, message []byte) ([]byte, error)
+	// Verify checks signature over message with publicKey
+	Verify(publicKey, message, signature []byte) bool
+}
+
+// ErrSuiteDisabled is returned when new keys are requested for an experimental
+// signature suite while experimental suites are disabled
+var ErrSuiteDisabled = errors.New("signature suite is experimental and not enabled")
+
+// Registry maps algorithm identifiers to implementations. New artifacts use the
+// defaults, while artifacts recorded with older identifiers stay verifiable for as
+// long as their implementation remains registered.
+type Registry struct {
+	mu              sync.RWMutex
+	hashSchemes     map[string]HashScheme
+	signatureSuites map[string]SignatureSuite
+	defaultHash     string
+	defaultSuite    string
+	// experimental suites verify existing keys but only issue new keys when
+	// experimentalEnabled is set
+	experimental        map[string]bool
+	experimentalEnabled bool
+}
+
+// NewRegistry creates a registry with the built-in algorithms registered
+func NewRegistry() *Registry {
+	r := &Registry{
+		hashSchemes:     make(map[string]HashScheme),
+		signatureSuites: make(map[string]SignatureSuite),
+		experimental:    make(map[string]bool),
+	}
+
+	r.RegisterHashScheme(sha256Scheme{})
+	r.RegisterHashScheme(NewArgon2idScheme(DefaultArgon2Params))
+	r.RegisterSignatureSuite(ed25519Suite{})
+	r.RegisterExperimentalSignatureSuite(mldsa65Suite{})
+	r.RegisterExperimentalSignatureSuite(hybridSuite{})
+
+	r.defaultHash = HashSchemeArgon2id
+	r.defaultSuite = SignatureSuiteEd25519
+
+	return r
+}
+
+// RegisterHashScheme adds or replaces a hash scheme
+func (r *Registry) RegisterHashScheme(scheme HashScheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hashSchemes[scheme.ID()] = scheme
+}
+
+// RegisterSignatureSuite adds or replaces a signature suite
+func (r *Registry) RegisterSignatureSuite(suite SignatureSuite) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signatureSuites[suite.ID()] = suite
+	delete(r.experimental, suite.ID())
+}
+
+// RegisterExperimentalSignatureSuite adds or replaces a signature suite that can
+// only be used for new keys once experimental suites are enabled
+func (r *Registry) RegisterExperimentalSignatureSuite(suite SignatureSuite) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signatureSuites[suite.ID()] = suite
+	r.experimental[suite.ID()] = true
+}
+
+// EnableExperimentalSuites allows experimental signature suites to be used for new keys
+func (r *Registry) EnableExperimentalSuites(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.experimentalEnabled = enabled
+}
+
+// IsExperimental reports whether a signature suite is registered as experimental
+func (r *Registry) IsExperimental(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.experimental[id]
+}
+
+// SetDefaultHashScheme selects the hash scheme used for new commitments
+func (r *Registry) SetDefaultHashScheme(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.hashSchemes[id]; !ok {
+		return fmt.Errorf("unknown hash scheme: %s", id)
+	}
+	r.defaultHash = id
+	return nil
+}
+
+// SetDefaultSignatureSuite selects the signature suite used for new keys
+func (r *Registry) SetDefaultSignatureSuite(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.signatureSuites[id]; !ok {
+		return fmt.Errorf("unknown signature suite: %s", id)
+	}
+	if r.experimental[id] && !r.experimentalEnabled {
+		return fmt.Errorf("%w: %s", ErrSuiteDisabled, id)
+	}
+	r.defaultSuite = id
+	return nil
+}
+
+// HashScheme looks up a hash scheme; an empty ID resolves to the legacy scheme,
+// which is what records created before schemes were tracked use
+func (r *Registry) HashScheme(id string) (HashScheme, error) {
+	if id == "" {
+		id = HashSchemeSHA256
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scheme, ok := r.hashSchemes[id]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash scheme: %s", id)
+	}
+	return scheme, nil
+}
+
+// SignatureSuite looks up a signature suite; an empty ID resolves to Ed25519,
+// which is what records created before suites were tracked use
+func (r *Registry) SignatureSuite(id string) (SignatureSuite, error) {
+	if id == "" {
+		id = SignatureSuiteEd25519
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	suite, ok := r.signatureSuites[id]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature suite: %s", id)
+	}
+	return suite, nil
+}
+
+// SignatureSuiteForMethodType looks up the signature suite of keys whose DID Document
+// verification method has the given type
+func (r *Registry) SignatureSuiteForMethodType(methodType string) (SignatureSuite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, suite := range r.signatureSuites {
+		if suite.VerificationMethodType() == methodType {
+			return suite, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported verification method type: %s", methodType)
+}
+
+// SignatureSuiteForNewKeys looks up a signature suite for issuing new keys; an
+// empty ID resolves to the default suite. Experimental suites are rejected with
+// ErrSuiteDisabled unless enabled.
+func (r *Registry) SignatureSuiteForNewKeys(id string) (SignatureSuite, error) {
+	r.mu.RLock()
+	enabled := r.experimentalEnabled
+	r.mu.RUnlock()
+	return r.SignatureSuiteForNewKeysAllowing(id, enabled)
+}
+
+// SignatureSuiteForNewKeysAllowing is SignatureSuiteForNewKeys with the registry-wide
+// experimental setting replaced by allowExperimental, for callers that gate
+// experimental suites per tenant
+func (r *Registry) SignatureSuiteForNewKeysAllowing(id string, allowExperimental bool) (SignatureSuite, error) {
+	if id == "" {
+		return r.DefaultSignatureSuite(), nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	suite, ok := r.signatureSuites[id]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signature suite: %s", id)
+	}
+	if r.experimental[id] && !allowExperimental {
+		return nil, fmt.Errorf("%w: %s", ErrSuiteDisabled, id)
+	}
+	return suite, nil
+}
+
+// DefaultHashScheme returns the hash scheme used for new commitments
+func (r *Registry) DefaultHashScheme() HashScheme {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hashSchemes[r.defaultHash]
+}
+
+// DefaultSignatureSuite returns the signature suite used for new keys
+func (r *Registry) DefaultSignatureSuite() SignatureSuite {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signatureSuites[r.defaultSuite]
+}
+
+// Algorithms lists the registered hash schemes and signature suites
+func (r *Registry) Algorithms() (hashSchemes []string, signatureSuites []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id := range r.hashSchemes {
+		hashSchemes = append(hashSchemes, id)
+	}
+	for id := range r.signatureSuites {
+		signatureSuites = append(signatureSuites, id)
+	}
+
+	sort.Strings(hashSchemes)
+	sort.Strings(signatureSuites)
+	return hashSchemes, signatureSuites
+}
+
+// VerifySignature verifies a signature with the suite recorded alongside the key
+func (r *Registry) VerifySignature(suiteID string, keyMaterial, message, signature []byte) (bool, error) {
+	suite, err := r.SignatureSuite(suiteID)
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return false, err
+	}
+
+	return suite.Verify(publicKey, message, signature), nil
+}
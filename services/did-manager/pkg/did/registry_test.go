@@ -0,0 +1,57 @@
+package did
+
+import (
+	"testing"
+)
+
+func TestRegistryLookups(t *testing.T) {
+	registry := NewRegistry()
+
+	if _, err := registry.HashScheme(""); err != nil {
+		t.Errorf("empty hash scheme should resolve to the legacy scheme: %v", err)
+	}
+	if _, err := registry.SignatureSuite(""); err != nil {
+		t.Errorf("empty signature suite should resolve to Ed25519: %v", err)
+	}
+	if _, err := registry.HashScheme("md5-v0"); err == nil {
+		t.Error("expected error for unknown hash scheme")
+	}
+	if err := registry.SetDefaultSignatureSuite("unknown"); err == nil {
+		t.Error("expected error when defaulting to an unknown suite")
+	}
+	if suite, err := registry.SignatureSuiteForMethodType("Ed25519VerificationKey2020"); err != nil || suite.ID() != SignatureSuiteEd25519 {
+		t.Errorf("expected Ed25519 keys to resolve to the Ed25519 suite, got %v", err)
+	}
+	if _, err := registry.SignatureSuiteForMethodType("RsaVerificationKey2018"); err == nil {
+		t.Error("expected error for an unknown verification method type")
+	}
+}
+
+func TestRegistryVerifySignature(t *testing.T) {
+	registry := NewRegistry()
+	suite := registry.DefaultSignatureSuite()
+
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	message := []byte("hello")
+	signature, err := suite.Sign(privateKey, message)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	// Stored key material may be either half of the key pair
+	for _, material := range [][]byte{publicKey, privateKey} {
+		ok, err := registry.VerifySignature(suite.ID(), material, message, signature)
+		if err != nil || !ok {
+			t.Errorf("expected signature to verify, got %v, %v", ok, err)
+		}
+	}
+
+	ok, err := registry.VerifySignature(suite.ID(), publicKey, []byte("tampered"), signature)
+	if err != nil || ok {
+		t.Errorf("expected tampered message to fail, got %v, %v", ok, err)
+	}
+}
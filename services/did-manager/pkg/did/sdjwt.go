@@ -0,0 +1,401 @@
+package did
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SD-JWT (selective disclosure JWT) issuer-signed tokens carry digests of their
+// selectively disclosable claims in "_sd"; each claim travels separately as a
+// disclosure, base64url([salt, name, value]), and the holder presents only the
+// disclosures it chooses. A key binding JWT signed by the holder ties a presentation
+// to a verifier's nonce and audience.
+const (
+	// SDJWTHashAlgorithm is the digest algorithm of disclosures
+	SDJWTHashAlgorithm = "sha-256"
+	// JWSAlgorithmEdDSA is the JWS algorithm of ed25519 keys; other suites use their
+	// suite ID as algorithm
+	JWSAlgorithmEdDSA = "EdDSA"
+
+	sdjwtSeparator  = "~"
+	claimSD         = "_sd"
+	claimSDAlg      = "_sd_alg"
+	claimSDHash     = "sd_hash"
+	sdjwtSaltSize   = 16
+	keyBindingType  = "kb+jwt"
+	sdjwtHeaderType = "vc+sd-jwt"
+)
+
+// ErrInvalidSDJWT is returned for SD-JWTs and key binding JWTs that are malformed or
+// do not verify
+var ErrInvalidSDJWT = errors.New("invalid SD-JWT")
+
+// Disclosure is a selectively disclosable claim of an SD-JWT
+type Disclosure struct {
+	Salt    string
+	Name    string
+	Value   any
+	Encoded string
+}
+
+// digest returns the value listed in "_sd" for the disclosure
+func (d *Disclosure) digest() string {
+	sum := sha256.Sum256([]byte(d.Encoded))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// SDJWT is a parsed SD-JWT presentation: the issuer-signed JWT, the disclosures
+// presented with it and an optional key binding JWT
+type SDJWT struct {
+	issuerJWT   string
+	header      map[string]any
+	payload     map[string]any
+	signed      string
+	signature   []byte
+	Disclosures []*Disclosure
+	keyBinding  string
+}
+
+// IssueSDJWT creates an SD-JWT signed by the issuer. Claims are always visible; each
+// selective claim becomes a disclosure, and the result carries every disclosure so
+// the holder can choose which to present.
+func IssueSDJWT(suite SignatureSuite, privateKey []byte, keyID string, claims, selective map[string]any) (string, error) {
+	payload := make(map[string]any, len(claims)+2)
+	for name, value := range claims {
+		payload[name] = value
+	}
+
+	names := make([]string, 0, len(selective))
+	for name := range selective {
+		if _, ok := payload[name]; ok || name == claimSD || name == claimSDAlg {
+			return "", fmt.Errorf("%w: claim %s cannot be selectively disclosable", ErrInvalidSDJWT, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	disclosures := make([]string, 0, len(names))
+	digests := make([]string, 0, len(names))
+	for _, name := range names {
+		disclosure, err := newDisclosure(name, selective[name])
+		if err != nil {
+			return "", err
+		}
+		disclosures = append(disclosures, disclosure.Encoded)
+		digests = append(digests, disclosure.digest())
+	}
+	// Digests are sorted so their order does not reveal the claim names
+	sort.Strings(digests)
+	payload[claimSD] = digests
+	payload[claimSDAlg] = SDJWTHashAlgorithm
+
+	token, err := signJWS(suite, privateKey, map[string]any{"typ": sdjwtHeaderType, "kid": keyID}, payload)
+	if err != nil {
+		return "", err
+	}
+	return token + sdjwtSeparator + strings.Join(append(disclosures, ""), sdjwtSeparator), nil
+}
+
+// PresentSDJWT keeps only the disclosures of the named claims, dropping any key
+// binding
+func PresentSDJWT(token string, names []string) (string, error) {
+	parts := strings.Split(token, sdjwtSeparator)
+	if len(parts) < 2 {
+		return "", fmt.Errorf("%w: missing disclosure separator", ErrInvalidSDJWT)
+	}
+
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+
+	presented := []string{parts[0]}
+	found := make(map[string]bool, len(names))
+	for _, encoded := range parts[1 : len(parts)-1] {
+		disclosure, err := parseDisclosure(encoded)
+		if err != nil {
+			return "", err
+		}
+		if keep[disclosure.Name] {
+			presented = append(presented, encoded)
+			found[disclosure.Name] = true
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			return "", fmt.Errorf("%w: no disclosure for claim %s", ErrInvalidSDJWT, name)
+		}
+	}
+
+	return strings.Join(append(presented, ""), sdjwtSeparator), nil
+}
+
+// AddKeyBinding appends a key binding JWT signed by the holder, binding the
+// presentation to the verifier's audience and nonce
+func AddKeyBinding(suite SignatureSuite, privateKey []byte, presentation, audience, nonce string, issuedAt time.Time) (string, error) {
+	if !strings.HasSuffix(presentation, sdjwtSeparator) {
+		return "", fmt.Errorf("%w: presentation already carries a key binding", ErrInvalidSDJWT)
+	}
+
+	kb, err := signJWS(suite, privateKey, map[string]any{"typ": keyBindingType}, map[string]any{
+		"iat":       issuedAt.Unix(),
+		"aud":       audience,
+		"nonce":     nonce,
+		claimSDHash: sdHash(presentation),
+	})
+	if err != nil {
+		return "", err
+	}
+	return presentation + kb, nil
+}
+
+// ParseSDJWT parses an SD-JWT presentation without verifying it
+func ParseSDJWT(presentation string) (*SDJWT, error) {
+	parts := strings.Split(presentation, sdjwtSeparator)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%w: missing disclosure separator", ErrInvalidSDJWT)
+	}
+
+	header, payload, signed, signature, err := parseJWS(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	token := &SDJWT{
+		issuerJWT:  parts[0],
+		header:     header,
+		payload:    payload,
+		signed:     signed,
+		signature:  signature,
+		keyBinding: parts[len(parts)-1],
+	}
+	for _, encoded := range parts[1 : len(parts)-1] {
+		disclosure, err := parseDisclosure(encoded)
+		if err != nil {
+			return nil, err
+		}
+		token.Disclosures = append(token.Disclosures, disclosure)
+	}
+	return token, nil
+}
+
+// Issuer returns the "iss" claim
+func (t *SDJWT) Issuer() string {
+	iss, _ := t.payload["iss"].(string)
+	return iss
+}
+
+// KeyID returns the "kid" header naming the issuer's key
+func (t *SDJWT) KeyID() string {
+	kid, _ := t.header["kid"].(string)
+	return kid
+}
+
+// Algorithm returns the "alg" header
+func (t *SDJWT) Algorithm() string {
+	alg, _ := t.header["alg"].(string)
+	return alg
+}
+
+// HasKeyBinding reports whether the presentation carries a key binding JWT
+func (t *SDJWT) HasKeyBinding() bool {
+	return t.keyBinding != ""
+}
+
+// Verify checks the issuer's signature with the public key of suite and that every
+// disclosure is committed to by the token
+func (t *SDJWT) Verify(suite SignatureSuite, publicKey []byte) error {
+	if t.Algorithm() != jwsAlgorithm(suite) {
+		return fmt.Errorf("%w: algorithm %s does not match the %s key", ErrInvalidSDJWT, t.Algorithm(), suite.ID())
+	}
+	if !suite.Verify(publicKey, []byte(t.signed), t.signature) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSDJWT)
+	}
+	if alg, _ := t.payload[claimSDAlg].(string); alg != SDJWTHashAlgorithm {
+		return fmt.Errorf("%w: unsupported digest algorithm %q", ErrInvalidSDJWT, alg)
+	}
+
+	committed := make(map[string]bool)
+	digests, _ := t.payload[claimSD].([]any)
+	for _, d := range digests {
+		if digest, ok := d.(string); ok {
+			committed[digest] = true
+		}
+	}
+	seen := make(map[string]bool, len(t.Disclosures))
+	for _, disclosure := range t.Disclosures {
+		digest := disclosure.digest()
+		if !committed[digest] {
+			return fmt.Errorf("%w: disclosure of %s is not part of the token", ErrInvalidSDJWT, disclosure.Name)
+		}
+		if seen[digest] {
+			return fmt.Errorf("%w: disclosure of %s is repeated", ErrInvalidSDJWT, disclosure.Name)
+		}
+		seen[digest] = true
+	}
+	return nil
+}
+
+// VerifyKeyBinding checks the key binding JWT with the holder's public key under suite
+// against the expected audience and nonce
+func (t *SDJWT) VerifyKeyBinding(suite SignatureSuite, publicKey []byte, audience, nonce string) error {
+	if t.keyBinding == "" {
+		return fmt.Errorf("%w: missing key binding", ErrInvalidSDJWT)
+	}
+
+	header, payload, signed, signature, err := parseJWS(t.keyBinding)
+	if err != nil {
+		return err
+	}
+	if typ, _ := header["typ"].(string); typ != keyBindingType {
+		return fmt.Errorf("%w: unexpected key binding type %q", ErrInvalidSDJWT, typ)
+	}
+	if alg, _ := header["alg"].(string); alg != jwsAlgorithm(suite) {
+		return fmt.Errorf("%w: key binding algorithm %s does not match the %s key", ErrInvalidSDJWT, alg, suite.ID())
+	}
+	if !suite.Verify(publicKey, []byte(signed), signature) {
+		return fmt.Errorf("%w: key binding signature mismatch", ErrInvalidSDJWT)
+	}
+
+	if aud, _ := payload["aud"].(string); aud != audience {
+		return fmt.Errorf("%w: key binding audience does not match", ErrInvalidSDJWT)
+	}
+	if n, _ := payload["nonce"].(string); n != nonce {
+		return fmt.Errorf("%w: key binding nonce does not match", ErrInvalidSDJWT)
+	}
+
+	presented := t.issuerJWT + sdjwtSeparator
+	for _, disclosure := range t.Disclosures {
+		presented += disclosure.Encoded + sdjwtSeparator
+	}
+	if hash, _ := payload[claimSDHash].(string); hash != sdHash(presented) {
+		return fmt.Errorf("%w: key binding does not cover the presented disclosures", ErrInvalidSDJWT)
+	}
+	return nil
+}
+
+// Claims returns the always-visible claims merged with the disclosed ones
+func (t *SDJWT) Claims() map[string]any {
+	claims := make(map[string]any, len(t.payload)+len(t.Disclosures))
+	for name, value := range t.payload {
+		if name != claimSD && name != claimSDAlg {
+			claims[name] = value
+		}
+	}
+	for _, disclosure := range t.Disclosures {
+		claims[disclosure.Name] = disclosure.Value
+	}
+	return claims
+}
+
+// newDisclosure creates a salted disclosure of a claim
+func newDisclosure(name string, value any) (*Disclosure, error) {
+	salt := make([]byte, sdjwtSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate disclosure salt: %w", err)
+	}
+	encodedSalt := base64.RawURLEncoding.EncodeToString(salt)
+
+	raw, err := json.Marshal([]any{encodedSalt, name, value})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode disclosure of %s: %w", name, err)
+	}
+	return &Disclosure{
+		Salt:    encodedSalt,
+		Name:    name,
+		Value:   value,
+		Encoded: base64.RawURLEncoding.EncodeToString(raw),
+	}, nil
+}
+
+// parseDisclosure decodes a disclosure of an object property
+func parseDisclosure(encoded string) (*Disclosure, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed disclosure", ErrInvalidSDJWT)
+	}
+	var fields []any
+	if err := json.Unmarshal(raw, &fields); err != nil || len(fields) != 3 {
+		return nil, fmt.Errorf("%w: malformed disclosure", ErrInvalidSDJWT)
+	}
+	salt, saltOK := fields[0].(string)
+	name, nameOK := fields[1].(string)
+	if !saltOK || !nameOK || name == claimSD || name == claimSDAlg {
+		return nil, fmt.Errorf("%w: malformed disclosure", ErrInvalidSDJWT)
+	}
+	return &Disclosure{Salt: salt, Name: name, Value: fields[2], Encoded: encoded}, nil
+}
+
+// sdHash is the digest of a presentation covered by its key binding
+func sdHash(presentation string) string {
+	sum := sha256.Sum256([]byte(presentation))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// jwsAlgorithm returns the JWS "alg" of keys of suite
+func jwsAlgorithm(suite SignatureSuite) string {
+	if suite.ID() == SignatureSuiteEd25519 {
+		return JWSAlgorithmEdDSA
+	}
+	return suite.ID()
+}
+
+// SuiteForJWSAlgorithm returns the signature suite of a JWS "alg"
+func (r *Registry) SuiteForJWSAlgorithm(alg string) (SignatureSuite, error) {
+	if alg == JWSAlgorithmEdDSA {
+		alg = SignatureSuiteEd25519
+	}
+	return r.SignatureSuite(alg)
+}
+
+// signJWS creates a compact JWS over payload, adding the suite's "alg" to header
+func signJWS(suite SignatureSuite, privateKey []byte, header, payload map[string]any) (string, error) {
+	header["alg"] = jwsAlgorithm(suite)
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWS header: %w", err)
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWS payload: %w", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payloadJSON)
+	signature, err := suite.Sign(privateKey, []byte(signed))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWS: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseJWS decodes a compact JWS into its header, payload, signing input and signature
+func parseJWS(token string) (map[string]any, map[string]any, string, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, "", nil, fmt.Errorf("%w: malformed JWS", ErrInvalidSDJWT)
+	}
+
+	var header, payload map[string]any
+	for i, target := range []*map[string]any{&header, &payload} {
+		raw, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, nil, "", nil, fmt.Errorf("%w: malformed JWS", ErrInvalidSDJWT)
+		}
+		if err := json.Unmarshal(raw, target); err != nil || *target == nil {
+			return nil, nil, "", nil, fmt.Errorf("%w: malformed JWS", ErrInvalidSDJWT)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, "", nil, fmt.Errorf("%w: malformed JWS signature", ErrInvalidSDJWT)
+	}
+
+	return header, payload, parts[0] + "." + parts[1], signature, nil
+}
@@ -0,0 +1,119 @@
+package did
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSDJWTSelectiveDisclosure(t *testing.T) {
+	registry := NewRegistry()
+	suite := registry.DefaultSignatureSuite()
+
+	issuerPublic, issuerPrivate, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	token, err := IssueSDJWT(suite, issuerPrivate, "did:example:issuer#key-1",
+		map[string]any{"iss": "did:example:issuer", "vct": TypeAgeOverCredential},
+		map[string]any{ClaimBirthdate: "1990-04-01", AgeOverClaim(18): true})
+	if err != nil {
+		t.Fatalf("failed to issue SD-JWT: %v", err)
+	}
+
+	presentation, err := PresentSDJWT(token, []string{AgeOverClaim(18)})
+	if err != nil {
+		t.Fatalf("failed to present SD-JWT: %v", err)
+	}
+	if strings.Contains(presentation, token[strings.Index(token, "~")+1:strings.LastIndex(token, "~")]) {
+		t.Fatal("expected presentation to drop disclosures")
+	}
+
+	parsed, err := ParseSDJWT(presentation)
+	if err != nil {
+		t.Fatalf("failed to parse presentation: %v", err)
+	}
+	if err := parsed.Verify(suite, issuerPublic); err != nil {
+		t.Fatalf("expected presentation to verify: %v", err)
+	}
+
+	claims := parsed.Claims()
+	if claims[AgeOverClaim(18)] != true {
+		t.Errorf("expected age_over_18 to be disclosed, got %v", claims)
+	}
+	if _, ok := claims[ClaimBirthdate]; ok {
+		t.Error("expected birthdate to stay undisclosed")
+	}
+	if parsed.Issuer() != "did:example:issuer" {
+		t.Errorf("unexpected issuer %q", parsed.Issuer())
+	}
+
+	// A disclosure from another token is not committed to by this one
+	other, err := IssueSDJWT(suite, issuerPrivate, "did:example:issuer#key-1",
+		map[string]any{"iss": "did:example:issuer"}, map[string]any{AgeOverClaim(21): true})
+	if err != nil {
+		t.Fatalf("failed to issue SD-JWT: %v", err)
+	}
+	foreign := strings.Split(other, "~")[1]
+	forged, err := ParseSDJWT(presentation + foreign + "~")
+	if err != nil {
+		t.Fatalf("failed to parse presentation: %v", err)
+	}
+	if err := forged.Verify(suite, issuerPublic); !errors.Is(err, ErrInvalidSDJWT) {
+		t.Errorf("expected foreign disclosure to fail, got %v", err)
+	}
+}
+
+func TestSDJWTKeyBinding(t *testing.T) {
+	registry := NewRegistry()
+	suite := registry.DefaultSignatureSuite()
+
+	_, issuerPrivate, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	holderPublic, holderPrivate, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	token, err := IssueSDJWT(suite, issuerPrivate, "did:example:issuer#key-1",
+		map[string]any{"iss": "did:example:issuer"}, map[string]any{AgeOverClaim(18): true})
+	if err != nil {
+		t.Fatalf("failed to issue SD-JWT: %v", err)
+	}
+	presentation, err := PresentSDJWT(token, []string{AgeOverClaim(18)})
+	if err != nil {
+		t.Fatalf("failed to present SD-JWT: %v", err)
+	}
+	bound, err := AddKeyBinding(suite, holderPrivate, presentation, "https://shop.example", "nonce-1", time.Now())
+	if err != nil {
+		t.Fatalf("failed to add key binding: %v", err)
+	}
+
+	parsed, err := ParseSDJWT(bound)
+	if err != nil {
+		t.Fatalf("failed to parse presentation: %v", err)
+	}
+	if !parsed.HasKeyBinding() {
+		t.Fatal("expected key binding")
+	}
+	if err := parsed.VerifyKeyBinding(suite, holderPublic, "https://shop.example", "nonce-1"); err != nil {
+		t.Errorf("expected key binding to verify: %v", err)
+	}
+	if err := parsed.VerifyKeyBinding(suite, holderPublic, "https://shop.example", "nonce-2"); !errors.Is(err, ErrInvalidSDJWT) {
+		t.Errorf("expected other nonce to fail, got %v", err)
+	}
+
+	// Dropping the disclosure invalidates the key binding's sd_hash
+	kb := bound[strings.LastIndex(bound, "~")+1:]
+	stripped, err := ParseSDJWT(strings.Split(bound, "~")[0] + "~" + kb)
+	if err != nil {
+		t.Fatalf("failed to parse presentation: %v", err)
+	}
+	if err := stripped.VerifyKeyBinding(suite, holderPublic, "https://shop.example", "nonce-1"); !errors.Is(err, ErrInvalidSDJWT) {
+		t.Errorf("expected altered presentation to fail, got %v", err)
+	}
+}
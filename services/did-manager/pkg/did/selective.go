@@ -0,0 +1,218 @@
+package did
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"did-manager/pkg/bbs"
+)
+
+// ProofTypeBBS is the proof type of credentials signed with BBS, whose holders
+// derive selective disclosure proofs from them
+const ProofTypeBBS = "BbsBlsSignature2020"
+
+// ErrInvalidDisclosure is returned for selective disclosures that are malformed or do
+// not verify
+var ErrInvalidDisclosure = errors.New("invalid selective disclosure")
+
+// Claim is a named claim of a selectively disclosable credential. The value is kept
+// as encoded JSON so it signs and verifies byte for byte.
+type Claim struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NewClaim encodes a claim value
+func NewClaim(name string, value any) (Claim, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return Claim{}, fmt.Errorf("failed to encode claim %s: %w", name, err)
+	}
+	return Claim{Name: name, Value: raw}, nil
+}
+
+// message is the BBS message signed for the claim
+func (c Claim) message() ([]byte, error) {
+	var value bytes.Buffer
+	if err := json.Compact(&value, c.Value); err != nil {
+		return nil, fmt.Errorf("malformed value of claim %s: %w", c.Name, err)
+	}
+	return append([]byte(c.Name+"="), value.Bytes()...), nil
+}
+
+// SelectiveCredential is a credential whose claims are signed with BBS, one message
+// per claim in order, with the issuer DID as signature header. Its holder derives
+// proofs disclosing only some claims.
+type SelectiveCredential struct {
+	Issuer string  `json:"issuer"`
+	Claims []Claim `json:"claims"`
+	Proof  *Proof  `json:"proof,omitempty"`
+}
+
+// SelectiveDisclosure is a proof derived from a selective credential that discloses
+// some of its claims, keyed by their position in the credential. The proof is bound
+// to the verifier's nonce.
+type SelectiveDisclosure struct {
+	Issuer string        `json:"issuer"`
+	Claims map[int]Claim `json:"claims"`
+	Nonce  string        `json:"nonce,omitempty"`
+	Proof  *Proof        `json:"proof"`
+}
+
+// SignSelectiveCredential signs a credential's claims with the issuer's BBS key
+func SignSelectiveCredential(sk *bbs.SecretKey, credential *SelectiveCredential, verificationMethod string) error {
+	messages, err := claimMessages(credential.Claims)
+	if err != nil {
+		return err
+	}
+
+	signature, err := bbs.Sign(sk, []byte(credential.Issuer), messages)
+	if err != nil {
+		return err
+	}
+	credential.Proof = &Proof{
+		Type:               ProofTypeBBS,
+		VerificationMethod: verificationMethod,
+		ProofPurpose:       ProofPurposeAssertionMethod,
+		ProofValue:         base64.RawURLEncoding.EncodeToString(signature),
+	}
+	return nil
+}
+
+// VerifySelectiveCredential checks a selective credential's BBS signature
+func VerifySelectiveCredential(pk *bbs.PublicKey, credential *SelectiveCredential) error {
+	signature, messages, err := selectiveSignature(credential)
+	if err != nil {
+		return err
+	}
+	if err := bbs.Verify(pk, signature, []byte(credential.Issuer), messages); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+// DeriveDisclosure derives a proof from a selective credential disclosing only the
+// named claims, bound to nonce
+func DeriveDisclosure(pk *bbs.PublicKey, credential *SelectiveCredential, names []string, nonce string) (*SelectiveDisclosure, error) {
+	signature, messages, err := selectiveSignature(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	disclosure := &SelectiveDisclosure{
+		Issuer: credential.Issuer,
+		Claims: make(map[int]Claim, len(names)),
+		Nonce:  nonce,
+	}
+	indexes := make([]int, 0, len(names))
+	for _, name := range names {
+		index := credential.ClaimIndex(name)
+		if index < 0 {
+			return nil, fmt.Errorf("%w: credential has no claim %s", ErrInvalidDisclosure, name)
+		}
+		indexes = append(indexes, index)
+		disclosure.Claims[index] = credential.Claims[index]
+	}
+
+	proof, err := bbs.DeriveProof(pk, signature, []byte(credential.Issuer), []byte(nonce), messages, indexes)
+	if err != nil {
+		return nil, err
+	}
+	disclosure.Proof = &Proof{
+		Type:               ProofTypeBBS + "Proof",
+		VerificationMethod: credential.Proof.VerificationMethod,
+		ProofPurpose:       ProofPurposeAssertionMethod,
+		Challenge:          nonce,
+		ProofValue:         base64.RawURLEncoding.EncodeToString(proof),
+	}
+	return disclosure, nil
+}
+
+// VerifyDisclosure checks a selective disclosure with the issuer's BBS key and returns
+// its claims by name
+func VerifyDisclosure(pk *bbs.PublicKey, disclosure *SelectiveDisclosure) (map[string]json.RawMessage, error) {
+	if disclosure.Proof == nil || disclosure.Proof.ProofValue == "" {
+		return nil, fmt.Errorf("%w: missing proof", ErrInvalidDisclosure)
+	}
+	proof, err := base64.RawURLEncoding.DecodeString(disclosure.Proof.ProofValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed proof value", ErrInvalidDisclosure)
+	}
+
+	claims := make(map[string]json.RawMessage, len(disclosure.Claims))
+	messages := make(map[int][]byte, len(disclosure.Claims))
+	for index, claim := range disclosure.Claims {
+		if claim.Name == "" || strings.Contains(claim.Name, "=") {
+			return nil, fmt.Errorf("%w: invalid claim name %q", ErrInvalidDisclosure, claim.Name)
+		}
+		if _, ok := claims[claim.Name]; ok {
+			return nil, fmt.Errorf("%w: claim %s is disclosed twice", ErrInvalidDisclosure, claim.Name)
+		}
+		message, err := claim.message()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDisclosure, err)
+		}
+		messages[index] = message
+		claims[claim.Name] = claim.Value
+	}
+
+	if err := bbs.VerifyProof(pk, proof, []byte(disclosure.Issuer), []byte(disclosure.Nonce), messages); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDisclosure, err)
+	}
+	return claims, nil
+}
+
+// ClaimIndex returns the position of the named claim, or -1
+func (c *SelectiveCredential) ClaimIndex(name string) int {
+	for i, claim := range c.Claims {
+		if claim.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectiveSignature decodes a selective credential's signature and claim messages
+func selectiveSignature(credential *SelectiveCredential) ([]byte, [][]byte, error) {
+	if credential.Proof == nil || credential.Proof.ProofValue == "" {
+		return nil, nil, fmt.Errorf("%w: missing proof", ErrInvalidProof)
+	}
+	if credential.Proof.Type != ProofTypeBBS {
+		return nil, nil, fmt.Errorf("%w: unsupported proof type %s", ErrInvalidProof, credential.Proof.Type)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(credential.Proof.ProofValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed proof value", ErrInvalidProof)
+	}
+	messages, err := claimMessages(credential.Claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signature, messages, nil
+}
+
+// claimMessages encodes claims as BBS messages, rejecting empty, ambiguous and
+// repeated names
+func claimMessages(claims []Claim) ([][]byte, error) {
+	seen := make(map[string]bool, len(claims))
+	messages := make([][]byte, 0, len(claims))
+	for _, claim := range claims {
+		if claim.Name == "" || strings.Contains(claim.Name, "=") {
+			return nil, fmt.Errorf("invalid claim name %q", claim.Name)
+		}
+		if seen[claim.Name] {
+			return nil, fmt.Errorf("claim %q is repeated", claim.Name)
+		}
+		seen[claim.Name] = true
+		message, err := claim.message()
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
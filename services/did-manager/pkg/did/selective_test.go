@@ -0,0 +1,96 @@
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"did-manager/pkg/bbs"
+)
+
+func TestSelectiveCredentialDisclosure(t *testing.T) {
+	sk, err := bbs.KeyGen(bytes.Repeat([]byte{1}, 32), nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pk := sk.PublicKey()
+
+	credential := &SelectiveCredential{Issuer: "did:example:issuer"}
+	for _, claim := range []struct {
+		name  string
+		value any
+	}{
+		{"type", TypeAgeOverCredential},
+		{ClaimBirthdate, "1990-04-01"},
+		{AgeOverClaim(18), true},
+	} {
+		c, err := NewClaim(claim.name, claim.value)
+		if err != nil {
+			t.Fatalf("failed to encode claim: %v", err)
+		}
+		credential.Claims = append(credential.Claims, c)
+	}
+
+	if err := SignSelectiveCredential(sk, credential, "did:example:issuer#bbs-key-1"); err != nil {
+		t.Fatalf("failed to sign credential: %v", err)
+	}
+	if err := VerifySelectiveCredential(pk, credential); err != nil {
+		t.Fatalf("expected credential to verify: %v", err)
+	}
+
+	disclosure, err := DeriveDisclosure(pk, credential, []string{"type", AgeOverClaim(18)}, "nonce-1")
+	if err != nil {
+		t.Fatalf("failed to derive disclosure: %v", err)
+	}
+
+	// The disclosure travels as JSON
+	raw, err := json.Marshal(disclosure)
+	if err != nil {
+		t.Fatalf("failed to encode disclosure: %v", err)
+	}
+	if bytes.Contains(raw, []byte("1990")) {
+		t.Fatal("expected birthdate to stay undisclosed")
+	}
+	var received SelectiveDisclosure
+	if err := json.Unmarshal(raw, &received); err != nil {
+		t.Fatalf("failed to decode disclosure: %v", err)
+	}
+
+	claims, err := VerifyDisclosure(pk, &received)
+	if err != nil {
+		t.Fatalf("expected disclosure to verify: %v", err)
+	}
+	if string(claims[AgeOverClaim(18)]) != "true" {
+		t.Errorf("expected age_over_18 to be disclosed, got %v", claims)
+	}
+
+	received.Nonce = "nonce-2"
+	if _, err := VerifyDisclosure(pk, &received); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Errorf("expected replay under another nonce to fail, got %v", err)
+	}
+	received.Nonce = "nonce-1"
+	received.Claims[2] = Claim{Name: AgeOverClaim(18), Value: json.RawMessage("false")}
+	if _, err := VerifyDisclosure(pk, &received); !errors.Is(err, ErrInvalidDisclosure) {
+		t.Errorf("expected altered claim to fail, got %v", err)
+	}
+}
+
+func TestAgeAt(t *testing.T) {
+	birthdate := time.Date(2008, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC), 17},
+		{time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC), 18},
+		{time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC), 18},
+	}
+	for _, tt := range tests {
+		if got := AgeAt(birthdate, tt.at); got != tt.want {
+			t.Errorf("AgeAt(%s) = %d, want %d", tt.at.Format(BirthdateLayout), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status tokens are compact JWTs signed by the service asserting that a DID had a
+// status at a time, with the assurance of the check. They are short-lived and verify
+// with the service's public key alone, so gates without network access can check DIDs
+// from tokens fetched ahead of time.
+const (
+	// StatusTokenType is the "typ" header of status tokens
+	StatusTokenType = "status+jwt"
+)
+
+// ErrInvalidStatusToken is returned for status tokens that are malformed, not signed
+// by the expected key or not valid at the time of verification
+var ErrInvalidStatusToken = errors.New("invalid status token")
+
+// StatusTokenClaims are the claims of a status token
+type StatusTokenClaims struct {
+	Issuer string `json:"iss"`
+	// Subject is the DID whose status is asserted
+	Subject string `json:"sub"`
+	// IssuedAt is when the status was checked
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+	Status    string    `json:"status"`
+	// Assurance tells what the status check was based on, e.g. chain_confirmed_12
+	Assurance string `json:"assurance"`
+}
+
+// StatusTokenKeyID derives the key ID of a status token signing key
+func StatusTokenKeyID(publicKey ed25519.PublicKey) string {
+	digest := sha256.Sum256(publicKey)
+	return hex.EncodeToString(digest[:8])
+}
+
+// StatusTokenJWK returns the JWK of a status token signing key, as published to
+// verifiers
+func StatusTokenJWK(publicKey ed25519.PublicKey) *JWK {
+	return &JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(publicKey)}
+}
+
+// IssueStatusToken signs status token claims with an Ed25519 key
+func IssueStatusToken(privateKey ed25519.PrivateKey, claims *StatusTokenClaims) (string, error) {
+	header := map[string]any{
+		"typ": StatusTokenType,
+		"kid": StatusTokenKeyID(privateKey.Public().(ed25519.PublicKey)),
+	}
+	payload := map[string]any{
+		"iss":       claims.Issuer,
+		"sub":       claims.Subject,
+		"iat":       claims.IssuedAt.Unix(),
+		"exp":       claims.ExpiresAt.Unix(),
+		"status":    claims.Status,
+		"assurance": claims.Assurance,
+	}
+	return signJWS(ed25519Suite{}, privateKey, header, payload)
+}
+
+// VerifyStatusToken verifies a status token against the issuer's key and checks that
+// it is valid at now, returning its claims
+func VerifyStatusToken(token string, publicKey ed25519.PublicKey, now time.Time) (*StatusTokenClaims, error) {
+	header, payload, signed, signature, err := parseJWS(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidStatusToken)
+	}
+	if header["typ"] != StatusTokenType || header["alg"] != JWSAlgorithmEdDSA {
+		return nil, fmt.Errorf("%w: not an EdDSA status token", ErrInvalidStatusToken)
+	}
+	if !ed25519.Verify(publicKey, []byte(signed), signature) {
+		return nil, fmt.Errorf("%w: signature does not verify", ErrInvalidStatusToken)
+	}
+
+	claims := &StatusTokenClaims{}
+	claims.Issuer, _ = payload["iss"].(string)
+	claims.Subject, _ = payload["sub"].(string)
+	claims.Status, _ = payload["status"].(string)
+	claims.Assurance, _ = payload["assurance"].(string)
+	issuedAt, iatOK := payload["iat"].(float64)
+	expiresAt, expOK := payload["exp"].(float64)
+	if claims.Subject == "" || !iatOK || !expOK {
+		return nil, fmt.Errorf("%w: missing claims", ErrInvalidStatusToken)
+	}
+	claims.IssuedAt = time.Unix(int64(issuedAt), 0)
+	claims.ExpiresAt = time.Unix(int64(expiresAt), 0)
+
+	if !now.Before(claims.ExpiresAt) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrInvalidStatusToken, claims.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return claims, nil
+}
@@ -0,0 +1,56 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusToken(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	issuedAt := time.Unix(1760000000, 0)
+	token, err := IssueStatusToken(privateKey, &StatusTokenClaims{
+		Issuer:    "did-manager",
+		Subject:   "did:example:user:94b97f078270a88c:z6MkTest",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(time.Hour),
+		Status:    "active",
+		Assurance: "chain_confirmed_12",
+	})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	if strings.Count(token, ".") != 2 {
+		t.Fatalf("expected a compact JWT, got %s", token)
+	}
+
+	claims, err := VerifyStatusToken(token, publicKey, issuedAt.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+	if claims.Subject != "did:example:user:94b97f078270a88c:z6MkTest" || claims.Status != "active" || claims.Assurance != "chain_confirmed_12" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if !claims.IssuedAt.Equal(issuedAt) {
+		t.Errorf("expected iat %s, got %s", issuedAt, claims.IssuedAt)
+	}
+
+	if _, err := VerifyStatusToken(token, publicKey, issuedAt.Add(time.Hour)); !errors.Is(err, ErrInvalidStatusToken) {
+		t.Errorf("expected expired tokens to be rejected, got %v", err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := VerifyStatusToken(token, otherKey, issuedAt); !errors.Is(err, ErrInvalidStatusToken) {
+		t.Errorf("expected tokens of another key to be rejected, got %v", err)
+	}
+	parts := strings.Split(token, ".")
+	if _, err := VerifyStatusToken(parts[0]+"."+parts[0]+"."+parts[2], publicKey, issuedAt); !errors.Is(err, ErrInvalidStatusToken) {
+		t.Errorf("expected altered tokens to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+)
+
+// Supported signature suites
+const (
+	SignatureSuiteEd25519 = "ed25519-2020"
+)
+
+// ed25519Suite implements SignatureSuite with Ed25519
+type ed25519Suite struct{}
+
+func (ed25519Suite) ID() string { return SignatureSuiteEd25519 }
+
+func (ed25519Suite) VerificationMethodType() string { return "Ed25519VerificationKey2020" }
+
+func (ed25519Suite) GenerateKey() ([]byte, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, privateKey, nil
+}
+
+func (ed25519Suite) PublicKey(keyMaterial []byte) ([]byte, error) {
+	switch len(keyMaterial) {
+	case ed25519.PublicKeySize:
+		return keyMaterial, nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(keyMaterial).Public().(ed25519.PublicKey), nil
+	default:
+		return nil, fmt.Errorf("unexpected key material length: %d", len(keyMaterial))
+	}
+}
+
+func (ed25519Suite) Sign(privateKey, message []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("unexpected private key length: %d", len(privateKey))
+	}
+	return ed25519.Sign(ed25519.PrivateKey(privateKey), message), nil
+}
+
+func (ed25519Suite) Verify(publicKey, message, signature []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(publicKey), message, signature)
+}
@@ -0,0 +1,141 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+)
+
+// Experimental post-quantum signature suites. They are always available for
+// verifying existing keys but only used for new keys when experimental suites
+// are enabled on the generator.
+const (
+	SignatureSuiteMLDSA65        = "ml-dsa-65-v1"
+	SignatureSuiteEd25519MLDSA65 = "ed25519-ml-dsa-65-hybrid-v1"
+)
+
+// mldsa65Suite implements SignatureSuite with ML-DSA-65 (FIPS 204)
+type mldsa65Suite struct{}
+
+func (mldsa65Suite) ID() string { return SignatureSuiteMLDSA65 }
+
+func (mldsa65Suite) VerificationMethodType() string { return "MLDSA65VerificationKey2024" }
+
+func (mldsa65Suite) GenerateKey() ([]byte, []byte, error) {
+	publicKey, privateKey, err := mldsa65.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey.Bytes(), privateKey.Bytes(), nil
+}
+
+func (mldsa65Suite) PublicKey(keyMaterial []byte) ([]byte, error) {
+	switch len(keyMaterial) {
+	case mldsa65.PublicKeySize:
+		return keyMaterial, nil
+	case mldsa65.PrivateKeySize:
+		var privateKey mldsa65.PrivateKey
+		if err := privateKey.UnmarshalBinary(keyMaterial); err != nil {
+			return nil, fmt.Errorf("invalid ML-DSA-65 private key: %w", err)
+		}
+		return privateKey.Public().(*mldsa65.PublicKey).Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unexpected key material length: %d", len(keyMaterial))
+	}
+}
+
+func (mldsa65Suite) Sign(privateKey, message []byte) ([]byte, error) {
+	var key mldsa65.PrivateKey
+	if err := key.UnmarshalBinary(privateKey); err != nil {
+		return nil, fmt.Errorf("invalid ML-DSA-65 private key: %w", err)
+	}
+
+	signature := make([]byte, mldsa65.SignatureSize)
+	if err := mldsa65.SignTo(&key, message, nil, true, signature); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return signature, nil
+}
+
+func (mldsa65Suite) Verify(publicKey, message, signature []byte) bool {
+	var key mldsa65.PublicKey
+	if err := key.UnmarshalBinary(publicKey); err != nil {
+		return false
+	}
+	return mldsa65.Verify(&key, message, nil, signature)
+}
+
+// hybridSuite implements SignatureSuite with an Ed25519 and an ML-DSA-65 key used
+// together: keys and signatures are the concatenation of both components, and a
+// signature is only valid if both component signatures verify.
+type hybridSuite struct{}
+
+func (hybridSuite) ID() string { return SignatureSuiteEd25519MLDSA65 }
+
+func (hybridSuite) VerificationMethodType() string { return "Ed25519MLDSA65HybridVerificationKey2024" }
+
+func (hybridSuite) GenerateKey() ([]byte, []byte, error) {
+	edPublic, edPrivate, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	pqPublic, pqPrivate, err := mldsa65Suite{}.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return concat(edPublic, pqPublic), concat(edPrivate, pqPrivate), nil
+}
+
+func (hybridSuite) PublicKey(keyMaterial []byte) ([]byte, error) {
+	switch len(keyMaterial) {
+	case ed25519.PublicKeySize + mldsa65.PublicKeySize:
+		return keyMaterial, nil
+	case ed25519.PrivateKeySize + mldsa65.PrivateKeySize:
+		edPublic, err := ed25519Suite{}.PublicKey(keyMaterial[:ed25519.PrivateKeySize])
+		if err != nil {
+			return nil, err
+		}
+		pqPublic, err := mldsa65Suite{}.PublicKey(keyMaterial[ed25519.PrivateKeySize:])
+		if err != nil {
+			return nil, err
+		}
+		return concat(edPublic, pqPublic), nil
+	default:
+		return nil, fmt.Errorf("unexpected key material length: %d", len(keyMaterial))
+	}
+}
+
+func (hybridSuite) Sign(privateKey, message []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize+mldsa65.PrivateKeySize {
+		return nil, fmt.Errorf("unexpected private key length: %d", len(privateKey))
+	}
+
+	edSignature, err := ed25519Suite{}.Sign(privateKey[:ed25519.PrivateKeySize], message)
+	if err != nil {
+		return nil, err
+	}
+	pqSignature, err := mldsa65Suite{}.Sign(privateKey[ed25519.PrivateKeySize:], message)
+	if err != nil {
+		return nil, err
+	}
+	return concat(edSignature, pqSignature), nil
+}
+
+func (hybridSuite) Verify(publicKey, message, signature []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize+mldsa65.PublicKeySize ||
+		len(signature) != ed25519.SignatureSize+mldsa65.SignatureSize {
+		return false
+	}
+
+	return ed25519Suite{}.Verify(publicKey[:ed25519.PublicKeySize], message, signature[:ed25519.SignatureSize]) &&
+		mldsa65Suite{}.Verify(publicKey[ed25519.PublicKeySize:], message, signature[ed25519.SignatureSize:])
+}
+
+// concat joins two byte slices into a new slice
+func concat(a, b []byte) []byte {
+	out := make([]byte, 0, len(a)+len(b))
+	out = append(out, a...)
+	return append(out, b...)
+}
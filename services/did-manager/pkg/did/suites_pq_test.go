@@ -0,0 +1,104 @@
+package did
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPostQuantumSuitesSignAndVerify(t *testing.T) {
+	registry := NewRegistry()
+
+	for _, id := range []string{SignatureSuiteMLDSA65, SignatureSuiteEd25519MLDSA65} {
+		suite, err := registry.SignatureSuite(id)
+		if err != nil {
+			t.Fatalf("suite %s not registered: %v", id, err)
+		}
+
+		publicKey, privateKey, err := suite.GenerateKey()
+		if err != nil {
+			t.Fatalf("%s: failed to generate key: %v", id, err)
+		}
+
+		message := []byte("hello")
+		signature, err := suite.Sign(privateKey, message)
+		if err != nil {
+			t.Fatalf("%s: failed to sign: %v", id, err)
+		}
+
+		for _, material := range [][]byte{publicKey, privateKey} {
+			ok, err := registry.VerifySignature(id, material, message, signature)
+			if err != nil || !ok {
+				t.Errorf("%s: expected signature to verify, got %v, %v", id, ok, err)
+			}
+		}
+
+		ok, err := registry.VerifySignature(id, publicKey, []byte("tampered"), signature)
+		if err != nil || ok {
+			t.Errorf("%s: expected tampered message to fail, got %v, %v", id, ok, err)
+		}
+	}
+}
+
+func TestHybridSuiteRequiresBothSignatures(t *testing.T) {
+	suite := hybridSuite{}
+	publicKey, privateKey, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	message := []byte("hello")
+	signature, err := suite.Sign(privateKey, message)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	// Corrupt each half in turn; either must invalidate the hybrid signature
+	for _, offset := range []int{0, len(signature) - 1} {
+		corrupted := append([]byte(nil), signature...)
+		corrupted[offset] ^= 0xff
+		if suite.Verify(publicKey, message, corrupted) {
+			t.Errorf("expected corrupted signature at offset %d to fail", offset)
+		}
+	}
+}
+
+func TestExperimentalSuitesRequireFlag(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{})
+	if _, err := gen.GenerateDIDWithSuite(uuid.New(), "Alice", "alice@example.com", SignatureSuiteMLDSA65); !errors.Is(err, ErrSuiteDisabled) {
+		t.Errorf("expected ErrSuiteDisabled, got %v", err)
+	}
+
+	if _, err := NewGeneratorWithConfig(GeneratorConfig{SignatureSuite: SignatureSuiteMLDSA65}); !errors.Is(err, ErrSuiteDisabled) {
+		t.Errorf("expected ErrSuiteDisabled for experimental default, got %v", err)
+	}
+
+	gen = newTestGenerator(t, GeneratorConfig{ExperimentalSuites: true})
+	generated, err := gen.GenerateDIDWithSuite(uuid.New(), "Alice", "alice@example.com", SignatureSuiteEd25519MLDSA65)
+	if err != nil {
+		t.Fatalf("failed to generate hybrid DID: %v", err)
+	}
+	if generated.KeyAlgorithm != SignatureSuiteEd25519MLDSA65 {
+		t.Errorf("expected key algorithm %s, got %s", SignatureSuiteEd25519MLDSA65, generated.KeyAlgorithm)
+	}
+	if !gen.ValidateDIDFormat(generated.DID) {
+		t.Errorf("generated DID has invalid format: %s", generated.DID)
+	}
+}
+
+func TestExperimentalSuitesAllowedPerCall(t *testing.T) {
+	gen := newTestGenerator(t, GeneratorConfig{})
+	generated, err := gen.GenerateDIDWithSuiteAllowing(uuid.New(), "Alice", "alice@example.com", SignatureSuiteMLDSA65, true)
+	if err != nil {
+		t.Fatalf("failed to generate ML-DSA DID: %v", err)
+	}
+	if generated.KeyAlgorithm != SignatureSuiteMLDSA65 {
+		t.Errorf("expected key algorithm %s, got %s", SignatureSuiteMLDSA65, generated.KeyAlgorithm)
+	}
+
+	gen = newTestGenerator(t, GeneratorConfig{ExperimentalSuites: true})
+	if _, err := gen.GenerateDIDWithSuiteAllowing(uuid.New(), "Alice", "alice@example.com", SignatureSuiteMLDSA65, false); !errors.Is(err, ErrSuiteDisabled) {
+		t.Errorf("expected ErrSuiteDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package did
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UCANs are capability tokens: compact JWTs in which an issuer DID grants an audience
+// DID capabilities, each an operation on a resource. The audience may delegate a subset
+// by issuing a UCAN that carries its own as proof, forming a chain rooted at the DID
+// the capabilities come from. A service invokes the chain by issuing its last UCAN to
+// the verifier. Each UCAN has at most one proof, so chains are linear.
+const (
+	// UCANVersion is the "ucv" header of UCANs, which tells them apart from other JWTs
+	UCANVersion = "0.10.0"
+	// UCANAny is the wildcard resource or operation of a capability
+	UCANAny = "*"
+	// MaxUCANDepth bounds the length of UCAN chains
+	MaxUCANDepth = 5
+)
+
+// ErrInvalidUCAN is returned for UCANs that are malformed, not signed by their issuer's
+// key, not valid at the time of verification or that exceed their proof
+var ErrInvalidUCAN = errors.New("invalid UCAN")
+
+// UCANCapability grants an operation on a resource
+type UCANCapability struct {
+	// With is the resource, a DID, or UCANAny for every resource
+	With string `json:"with"`
+	// Can is the operation, or UCANAny for every operation
+	Can string `json:"can"`
+}
+
+// Covers reports whether the capability grants at least what other grants
+func (c UCANCapability) Covers(other UCANCapability) bool {
+	return (c.With == UCANAny || c.With == other.With) && (c.Can == UCANAny || c.Can == other.Can)
+}
+
+// UCANClaims are the claims of a UCAN
+type UCANClaims struct {
+	Issuer   string `json:"iss"`
+	Audience string `json:"aud"`
+	// NotBefore is zero for UCANs valid from the start
+	NotBefore    time.Time
+	ExpiresAt    time.Time
+	Capabilities []UCANCapability
+	// Proof is the UCAN delegating the capabilities to the issuer; empty for a root UCAN
+	Proof string
+}
+
+// UCAN is a parsed UCAN whose signature is not verified yet
+type UCAN struct {
+	header    map[string]any
+	claims    *UCANClaims
+	signed    string
+	signature []byte
+}
+
+// IssueUCAN signs UCAN claims with the issuer's key of suite, named by keyID
+func IssueUCAN(suite SignatureSuite, privateKey []byte, keyID string, claims *UCANClaims) (string, error) {
+	header := map[string]any{
+		"typ": "JWT",
+		"ucv": UCANVersion,
+		"kid": keyID,
+	}
+	payload := map[string]any{
+		"iss": claims.Issuer,
+		"aud": claims.Audience,
+		"exp": claims.ExpiresAt.Unix(),
+		"att": claims.Capabilities,
+	}
+	if !claims.NotBefore.IsZero() {
+		payload["nbf"] = claims.NotBefore.Unix()
+	}
+	if claims.Proof != "" {
+		payload["prf"] = []string{claims.Proof}
+	}
+	return signJWS(suite, privateKey, header, payload)
+}
+
+// IsUCAN reports whether a bearer token claims to be a UCAN, without verifying it
+func IsUCAN(token string) bool {
+	header, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+	var fields struct {
+		Version string `json:"ucv"`
+	}
+	return json.Unmarshal(raw, &fields) == nil && fields.Version != ""
+}
+
+// ParseUCAN decodes a UCAN without verifying its signature
+func ParseUCAN(token string) (*UCAN, error) {
+	header, payload, signed, signature, err := parseJWS(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidUCAN)
+	}
+	if header["ucv"] != UCANVersion {
+		return nil, fmt.Errorf("%w: unsupported version %v", ErrInvalidUCAN, header["ucv"])
+	}
+
+	claims := &UCANClaims{}
+	claims.Issuer, _ = payload["iss"].(string)
+	claims.Audience, _ = payload["aud"].(string)
+	expiresAt, expOK := payload["exp"].(float64)
+	if claims.Issuer == "" || claims.Audience == "" || !expOK {
+		return nil, fmt.Errorf("%w: missing claims", ErrInvalidUCAN)
+	}
+	claims.ExpiresAt = time.Unix(int64(expiresAt), 0)
+	if notBefore, ok := payload["nbf"].(float64); ok {
+		claims.NotBefore = time.Unix(int64(notBefore), 0)
+	}
+
+	attenuations, _ := payload["att"].([]any)
+	for _, a := range attenuations {
+		fields, _ := a.(map[string]any)
+		with, _ := fields["with"].(string)
+		can, _ := fields["can"].(string)
+		if with == "" || can == "" {
+			return nil, fmt.Errorf("%w: capability without a resource or operation", ErrInvalidUCAN)
+		}
+		claims.Capabilities = append(claims.Capabilities, UCANCapability{With: with, Can: can})
+	}
+	if len(claims.Capabilities) == 0 {
+		return nil, fmt.Errorf("%w: no capabilities", ErrInvalidUCAN)
+	}
+
+	proofs, _ := payload["prf"].([]any)
+	if len(proofs) > 1 {
+		return nil, fmt.Errorf("%w: more than one proof", ErrInvalidUCAN)
+	}
+	if len(proofs) == 1 {
+		if claims.Proof, _ = proofs[0].(string); claims.Proof == "" {
+			return nil, fmt.Errorf("%w: malformed proof", ErrInvalidUCAN)
+		}
+	}
+
+	return &UCAN{header: header, claims: claims, signed: signed, signature: signature}, nil
+}
+
+// Claims returns the claims of the UCAN
+func (u *UCAN) Claims() *UCANClaims {
+	return u.claims
+}
+
+// KeyID returns the "kid" header naming the issuer's key
+func (u *UCAN) KeyID() string {
+	kid, _ := u.header["kid"].(string)
+	return kid
+}
+
+// Verify checks the issuer's signature with the public key of suite
+func (u *UCAN) Verify(suite SignatureSuite, publicKey []byte) error {
+	if alg, _ := u.header["alg"].(string); alg != jwsAlgorithm(suite) {
+		return fmt.Errorf("%w: algorithm %s does not match the %s key", ErrInvalidUCAN, alg, suite.ID())
+	}
+	if !suite.Verify(publicKey, []byte(u.signed), u.signature) {
+		return fmt.Errorf("%w: signature of %s does not verify", ErrInvalidUCAN, u.claims.Issuer)
+	}
+	return nil
+}
+
+// UCANKeyResolver returns the signature suite and public key of the issuer's key named
+// by keyID, which must be authorized to invoke capabilities
+type UCANKeyResolver func(issuer, keyID string) (SignatureSuite, []byte, error)
+
+// ValidateUCANChain verifies a UCAN invoked at audience together with the proofs it
+// carries, returning the chain ordered from the root UCAN to the invoked one. Every
+// link must be signed by its issuer, valid at now and issued by the audience of its
+// proof, with capabilities and expiry within the proof's.
+func ValidateUCANChain(token, audience string, resolveKey UCANKeyResolver, now time.Time) ([]*UCAN, error) {
+	var chain []*UCAN
+	for next := token; next != ""; {
+		if len(chain) == MaxUCANDepth {
+			return nil, fmt.Errorf("%w: chain exceeds %d UCANs", ErrInvalidUCAN, MaxUCANDepth)
+		}
+		link, err := ParseUCAN(next)
+		if err != nil {
+			return nil, err
+		}
+		chain = append([]*UCAN{link}, chain...)
+		next = link.claims.Proof
+	}
+
+	for i, link := range chain {
+		claims := link.claims
+		suite, publicKey, err := resolveKey(claims.Issuer, link.KeyID())
+		if err != nil {
+			return nil, err
+		}
+		if err := link.Verify(suite, publicKey); err != nil {
+			return nil, err
+		}
+
+		if !now.Before(claims.ExpiresAt) {
+			return nil, fmt.Errorf("%w: UCAN of %s expired at %s", ErrInvalidUCAN, claims.Issuer, claims.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		if now.Before(claims.NotBefore) {
+			return nil, fmt.Errorf("%w: UCAN of %s is not valid yet", ErrInvalidUCAN, claims.Issuer)
+		}
+
+		if i == 0 {
+			continue
+		}
+		proof := chain[i-1].claims
+		if claims.Issuer != proof.Audience {
+			return nil, fmt.Errorf("%w: %s delegates a UCAN issued to %s", ErrInvalidUCAN, claims.Issuer, proof.Audience)
+		}
+		if claims.ExpiresAt.After(proof.ExpiresAt) {
+			return nil, fmt.Errorf("%w: UCAN of %s outlives its proof", ErrInvalidUCAN, claims.Issuer)
+		}
+		// A delegate can only pass on capabilities it was granted
+		for _, capability := range claims.Capabilities {
+			if !coveredBy(capability, proof.Capabilities) {
+				return nil, fmt.Errorf("%w: %s grants %s on %s beyond its proof", ErrInvalidUCAN, claims.Issuer, capability.Can, capability.With)
+			}
+		}
+	}
+
+	if invoked := chain[len(chain)-1].claims; invoked.Audience != audience {
+		return nil, fmt.Errorf("%w: UCAN is issued to %s, not %s", ErrInvalidUCAN, invoked.Audience, audience)
+	}
+	return chain, nil
+}
+
+// coveredBy reports whether one of granted covers capability
+func coveredBy(capability UCANCapability, granted []UCANCapability) bool {
+	for _, g := range granted {
+		if g.Covers(capability) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// ucanParty is a DID with a key issuing UCANs in tests
+type ucanParty struct {
+	did        string
+	publicKey  []byte
+	privateKey []byte
+}
+
+func newUCANParty(t *testing.T, did string) *ucanParty {
+	t.Helper()
+	publicKey, privateKey, err := ed25519Suite{}.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &ucanParty{did: did, publicKey: publicKey, privateKey: privateKey}
+}
+
+// issue signs a UCAN from the party to audience
+func (p *ucanParty) issue(t *testing.T, audience string, expires time.Time, proof string, capabilities ...UCANCapability) string {
+	t.Helper()
+	token, err := IssueUCAN(ed25519Suite{}, p.privateKey, p.did+"#key-1", &UCANClaims{
+		Issuer:       p.did,
+		Audience:     audience,
+		ExpiresAt:    expires,
+		Capabilities: capabilities,
+		Proof:        proof,
+	})
+	if err != nil {
+		t.Fatalf("failed to issue UCAN: %v", err)
+	}
+	return token
+}
+
+// ucanKeys resolves the keys of parties
+func ucanKeys(parties ...*ucanParty) UCANKeyResolver {
+	return func(issuer, keyID string) (SignatureSuite, []byte, error) {
+		for _, p := range parties {
+			if p.did == issuer && keyID == p.did+"#key-1" {
+				return ed25519Suite{}, p.publicKey, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("%w: unknown key %s", ErrInvalidUCAN, keyID)
+	}
+}
+
+func TestValidateUCANChain(t *testing.T) {
+	const verifier = "did:web:did-manager.example.com"
+	now := time.Now()
+	tenant := newUCANParty(t, "did:example:tenant")
+	billing := newUCANParty(t, "did:example:billing")
+	worker := newUCANParty(t, "did:example:worker")
+	resolveKey := ucanKeys(tenant, billing, worker)
+
+	read := UCANCapability{With: "did:example:user-1", Can: "did:read"}
+	root := tenant.issue(t, billing.did, now.Add(time.Hour), "", UCANCapability{With: UCANAny, Can: "did:read"})
+	delegated := billing.issue(t, worker.did, now.Add(30*time.Minute), root, read)
+	invocation := worker.issue(t, verifier, now.Add(time.Minute), delegated, read)
+
+	if !IsUCAN(invocation) || IsUCAN("not a token") {
+		t.Fatal("expected only UCANs to be recognized")
+	}
+
+	chain, err := ValidateUCANChain(invocation, verifier, resolveKey, now)
+	if err != nil {
+		t.Fatalf("expected the chain to validate: %v", err)
+	}
+	if len(chain) != 3 || chain[0].Claims().Issuer != tenant.did || chain[2].Claims().Issuer != worker.did {
+		t.Fatalf("expected the chain from the tenant to the worker, got %d links", len(chain))
+	}
+	if got := chain[2].Claims().Capabilities; len(got) != 1 || got[0] != read {
+		t.Errorf("unexpected invoked capabilities: %+v", got)
+	}
+}
+
+func TestValidateUCANChainRejects(t *testing.T) {
+	const verifier = "did:web:did-manager.example.com"
+	now := time.Now()
+	tenant := newUCANParty(t, "did:example:tenant")
+	billing := newUCANParty(t, "did:example:billing")
+	mallory := newUCANParty(t, "did:example:mallory")
+	resolveKey := ucanKeys(tenant, billing, mallory)
+
+	read := UCANCapability{With: "did:example:user-1", Can: "did:read"}
+	root := tenant.issue(t, billing.did, now.Add(time.Hour), "", read)
+
+	forged := newUCANParty(t, tenant.did)
+	cases := map[string]string{
+		"escalated operation": billing.issue(t, verifier, now.Add(time.Minute), root, UCANCapability{With: read.With, Can: "did:write"}),
+		"other resource":      billing.issue(t, verifier, now.Add(time.Minute), root, UCANCapability{With: "did:example:user-2", Can: "did:read"}),
+		"wildcard resource":   billing.issue(t, verifier, now.Add(time.Minute), root, UCANCapability{With: UCANAny, Can: "did:read"}),
+		"outlives its proof":  billing.issue(t, verifier, now.Add(2*time.Hour), root, read),
+		"expired":             tenant.issue(t, verifier, now.Add(-time.Minute), "", read),
+		"wrong audience":      billing.issue(t, "did:web:other.example.com", now.Add(time.Minute), root, read),
+		"not the delegate":    mallory.issue(t, verifier, now.Add(time.Minute), root, read),
+		"forged signature":    forged.issue(t, verifier, now.Add(time.Minute), "", read),
+		"no capabilities":     tenant.issue(t, verifier, now.Add(time.Minute), ""),
+	}
+	for name, token := range cases {
+		if _, err := ValidateUCANChain(token, verifier, resolveKey, now); !errors.Is(err, ErrInvalidUCAN) {
+			t.Errorf("%s: expected ErrInvalidUCAN, got %v", name, err)
+		}
+	}
+
+	// Chains are bounded, even when each link would be valid
+	token := ""
+	for i := 0; i <= MaxUCANDepth; i++ {
+		token = tenant.issue(t, tenant.did, now.Add(time.Hour), token, read)
+	}
+	if _, err := ValidateUCANChain(token, tenant.did, resolveKey, now); !errors.Is(err, ErrInvalidUCAN) {
+		t.Errorf("expected a chain beyond MaxUCANDepth to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package egress
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, tt := range tests {
+		if got := Public(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Public(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/events", true},
+		{"http://93.184.215.14:8080/hook", true},
+		{"ftp://hooks.example.com/events", false},
+		{"https:///events", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]:8080/hook", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://localhost:8080/hook", false},
+		{"http://admin.LOCALHOST./hook", false},
+	}
+	for _, tt := range tests {
+		if err := CheckURL(tt.url); (err == nil) != tt.ok {
+			t.Errorf("CheckURL(%q) = %v, want ok %v", tt.url, err, tt.ok)
+		}
+	}
+}
+
+func TestClientRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached a loopback server")
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which a hostname may equally resolve to
+	_, err := Client(time.Second).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress, got %v", err)
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			followed = true
+		}
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := newClient(time.Second, func(netip.Addr) bool { return true })
+	resp, err := client.Post(server.URL+"/hook", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || followed {
+		t.Errorf("expected the redirect returned unfollowed, got %d (followed %v)", resp.StatusCode, followed)
+	}
+}
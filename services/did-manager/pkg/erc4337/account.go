@@ -0,0 +1,185 @@
+package erc4337
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// accountABI covers the SimpleAccountFactory, SimpleAccount and EntryPoint functions
+// used to deploy accounts and build their operations
+const accountABI = `[
+	{
+		"inputs": [
+			{"name": "owner", "type": "address"},
+			{"name": "salt", "type": "uint256"}
+		],
+		"name": "createAccount",
+		"outputs": [{"name": "ret", "type": "address"}],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"name": "owner", "type": "address"},
+			{"name": "salt", "type": "uint256"}
+		],
+		"name": "getAddress",
+		"outputs": [{"name": "", "type": "address"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"name": "dest", "type": "address"},
+			{"name": "value", "type": "uint256"},
+			{"name": "func", "type": "bytes"}
+		],
+		"name": "execute",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"name": "sender", "type": "address"},
+			{"name": "key", "type": "uint192"}
+		],
+		"name": "getNonce",
+		"outputs": [{"name": "nonce", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+var parsedAccountABI = mustParseABI(accountABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Chain is the node access needed to derive and inspect accounts; *ethclient.Client
+// implements it
+type Chain interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Factory derives and deploys smart accounts through a SimpleAccountFactory-compatible
+// factory contract, whose accounts are owned by a single key that can add guardians or
+// session keys through account calls
+type Factory struct {
+	chain      Chain
+	address    common.Address
+	entryPoint common.Address
+}
+
+// NewFactory creates a factory for the contract at address whose accounts use entryPoint
+func NewFactory(chain Chain, address, entryPoint common.Address) *Factory {
+	return &Factory{chain: chain, address: address, entryPoint: entryPoint}
+}
+
+// Address returns the factory contract address
+func (f *Factory) Address() common.Address {
+	return f.address
+}
+
+// EntryPoint returns the entry point the factory's accounts use
+func (f *Factory) EntryPoint() common.Address {
+	return f.entryPoint
+}
+
+// AccountAddress returns the counterfactual address of the account of owner and salt,
+// which is the same before and after deployment
+func (f *Factory) AccountAddress(ctx context.Context, owner common.Address, salt *big.Int) (common.Address, error) {
+	var address common.Address
+	if err := f.call(ctx, f.address, "getAddress", &address, owner, salt); err != nil {
+		return common.Address{}, err
+	}
+	return address, nil
+}
+
+// Deployed reports whether an account's contract is deployed
+func (f *Factory) Deployed(ctx context.Context, account common.Address) (bool, error) {
+	code, err := f.chain.CodeAt(ctx, account, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get account code: %w", err)
+	}
+	return len(code) > 0, nil
+}
+
+// Nonce returns the next nonce of an account's default nonce sequence
+func (f *Factory) Nonce(ctx context.Context, account common.Address) (*big.Int, error) {
+	var nonce *big.Int
+	if err := f.call(ctx, f.entryPoint, "getNonce", &nonce, account, new(big.Int)); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// InitCode returns the initCode deploying the account of owner and salt with the first
+// operation sent from it
+func (f *Factory) InitCode(owner common.Address, salt *big.Int) ([]byte, error) {
+	data, err := parsedAccountABI.Pack("createAccount", owner, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack createAccount: %w", err)
+	}
+	return append(f.address.Bytes(), data...), nil
+}
+
+// ExecuteCallData returns the callData of an operation making the account call target
+// with value and data
+func ExecuteCallData(target common.Address, value *big.Int, data []byte) ([]byte, error) {
+	callData, err := parsedAccountABI.Pack("execute", target, value, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack execute: %w", err)
+	}
+	return callData, nil
+}
+
+// call reads a single value from a contract function of accountABI
+func (f *Factory) call(ctx context.Context, contract common.Address, method string, out any, args ...any) error {
+	data, err := parsedAccountABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+	result, err := f.chain.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	values, err := parsedAccountABI.Unpack(method, result)
+	if err != nil || len(values) != 1 {
+		return fmt.Errorf("failed to decode %s result: %v", method, err)
+	}
+	return assign(out, values[0], method)
+}
+
+// assign stores an unpacked ABI value into out
+func assign(out any, value any, method string) error {
+	switch target := out.(type) {
+	case *common.Address:
+		address, ok := value.(common.Address)
+		if !ok {
+			return fmt.Errorf("unexpected %s result %T", method, value)
+		}
+		*target = address
+	case **big.Int:
+		number, ok := value.(*big.Int)
+		if !ok {
+			return fmt.Errorf("unexpected %s result %T", method, value)
+		}
+		*target = number
+	default:
+		return fmt.Errorf("unsupported %s result target %T", method, out)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package erc4337
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrBundlerRejected is returned when the bundler answers with a JSON-RPC error, such
+// as an operation failing validation
+var ErrBundlerRejected = errors.New("bundler rejected the request")
+
+// GasEstimate is the gas the bundler estimates an operation needs
+type GasEstimate struct {
+	PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+}
+
+// Receipt is the outcome of an included user operation
+type Receipt struct {
+	UserOpHash common.Hash `json:"userOpHash"`
+	Success    bool        `json:"success"`
+	Reason     string      `json:"reason,omitempty"`
+	Receipt    struct {
+		TransactionHash common.Hash `json:"transactionHash"`
+	} `json:"receipt"`
+}
+
+// Bundler is a JSON-RPC client for an ERC-4337 bundler
+type Bundler struct {
+	url        string
+	entryPoint common.Address
+	client     *http.Client
+}
+
+// NewBundler creates a bundler client submitting operations to entryPoint
+func NewBundler(url string, entryPoint common.Address) *Bundler {
+	return &Bundler{
+		url:        url,
+		entryPoint: entryPoint,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// EstimateGas estimates the gas limits of an operation; the signature may be a dummy of
+// the right length
+func (b *Bundler) EstimateGas(ctx context.Context, op *UserOperation) (*GasEstimate, error) {
+	var estimate GasEstimate
+	if err := b.call(ctx, "eth_estimateUserOperationGas", &estimate, op, b.entryPoint); err != nil {
+		return nil, err
+	}
+	return &estimate, nil
+}
+
+// Send submits a signed operation and returns its hash
+func (b *Bundler) Send(ctx context.Context, op *UserOperation) (common.Hash, error) {
+	var hash common.Hash
+	if err := b.call(ctx, "eth_sendUserOperation", &hash, op, b.entryPoint); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// Receipt returns the receipt of an operation, or nil while it is not yet included
+func (b *Bundler) Receipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	if err := b.call(ctx, "eth_getUserOperationReceipt", &receipt, hash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call performs a JSON-RPC request and decodes its result into out
+func (b *Bundler) call(ctx context.Context, method string, out any, params ...any) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call bundler: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read bundler response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bundler returned status %d", resp.StatusCode)
+	}
+
+	var decoded rpcResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("failed to decode bundler response: %w", err)
+	}
+	if decoded.Error != nil {
+		return fmt.Errorf("%w: %s (code %d)", ErrBundlerRejected, decoded.Error.Message, decoded.Error.Code)
+	}
+	if err := json.Unmarshal(decoded.Result, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
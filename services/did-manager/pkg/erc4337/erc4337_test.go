@@ -0,0 +1,157 @@
+package erc4337
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestUserOperationHash(t *testing.T) {
+	entryPoint := common.HexToAddress(EntryPointV06)
+	op := &UserOperation{
+		Sender:   common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:    (*hexutil.Big)(big.NewInt(1)),
+		CallData: hexutil.Bytes{0x01},
+	}
+
+	hash, err := op.Hash(entryPoint, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op.Signature = hexutil.Bytes{0xff}
+	signed, err := op.Hash(entryPoint, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed != hash {
+		t.Error("expected the signature to be excluded from the hash")
+	}
+
+	otherChain, err := op.Hash(entryPoint, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherChain == hash {
+		t.Error("expected the hash to be bound to the chain")
+	}
+
+	if len(DummySignature) != 65 {
+		t.Errorf("expected a 65-byte dummy signature, got %d bytes", len(DummySignature))
+	}
+}
+
+// stubChain answers getAddress and getNonce calls with fixed values
+type stubChain struct {
+	account common.Address
+	code    []byte
+}
+
+func (s *stubChain) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	method, err := parsedAccountABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "getAddress":
+		return method.Outputs.Pack(s.account)
+	case "getNonce":
+		return method.Outputs.Pack(big.NewInt(7))
+	}
+	return nil, errors.New("unexpected call")
+}
+
+func (s *stubChain) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return s.code, nil
+}
+
+func TestFactory(t *testing.T) {
+	account := common.HexToAddress("0xabcdefabcdefabcdefabcdefabcdefabcdefabcd")
+	factoryAddress := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	factory := NewFactory(&stubChain{account: account}, factoryAddress, common.HexToAddress(EntryPointV06))
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	got, err := factory.AccountAddress(context.Background(), owner, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != account {
+		t.Errorf("expected account %s, got %s", account.Hex(), got.Hex())
+	}
+
+	nonce, err := factory.Nonce(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Int64() != 7 {
+		t.Errorf("expected nonce 7, got %s", nonce)
+	}
+
+	deployed, err := factory.Deployed(context.Background(), account)
+	if err != nil || deployed {
+		t.Errorf("expected an account without code to be undeployed, got %v, %v", deployed, err)
+	}
+
+	initCode, err := factory.InitCode(owner, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToAddress(initCode[:20]) != factoryAddress || len(initCode) != 20+4+64 {
+		t.Errorf("unexpected initCode %x", initCode)
+	}
+
+	callData, err := ExecuteCallData(owner, big.NewInt(0), []byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hexutil.Encode(callData[:4]) != "0xb61d27f6" {
+		t.Errorf("unexpected execute selector %x", callData[:4])
+	}
+}
+
+func TestBundler(t *testing.T) {
+	opHash := common.HexToHash("0x01")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		switch req.Method {
+		case "eth_sendUserOperation":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + opHash.Hex() + `"}`))
+		case "eth_estimateUserOperationGas":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32500,"message":"AA21 didn't pay prefund"}}`))
+		case "eth_getUserOperationReceipt":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+		}
+	}))
+	defer server.Close()
+
+	bundler := NewBundler(server.URL, common.HexToAddress(EntryPointV06))
+	op := &UserOperation{Nonce: (*hexutil.Big)(big.NewInt(0))}
+
+	hash, err := bundler.Send(context.Background(), op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != opHash {
+		t.Errorf("expected hash %s, got %s", opHash.Hex(), hash.Hex())
+	}
+
+	if _, err := bundler.EstimateGas(context.Background(), op); !errors.Is(err, ErrBundlerRejected) {
+		t.Errorf("expected a rejected estimate, got %v", err)
+	}
+
+	receipt, err := bundler.Receipt(context.Background(), hash)
+	if err != nil || receipt != nil {
+		t.Errorf("expected no receipt for a pending operation, got %v, %v", receipt, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package erc4337 builds and submits ERC-4337 user operations for smart accounts: the
+// operation format and hash of EntryPoint v0.6, calldata for SimpleAccount-compatible
+// account factories and accounts, and a JSON-RPC client for bundlers.
+package erc4337
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EntryPointV06 is the canonical address of the EntryPoint v0.6 contract
+const EntryPointV06 = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+
+// DummySignature is a well-formed ECDSA signature of SimpleAccount owners, for gas
+// estimation before the operation is signed
+var DummySignature = hexutil.MustDecode("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+// UserOperation is an EntryPoint v0.6 user operation, encoded as bundlers expect
+type UserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+var (
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	bytes32Type, _ = abi.NewType("bytes32", "", nil)
+	addressType, _ = abi.NewType("address", "", nil)
+)
+
+// packedOperation is the ABI layout hashed by EntryPoint v0.6, with the dynamic fields
+// replaced by their hashes
+var packedOperation = abi.Arguments{
+	{Type: addressType},
+	{Type: uint256Type},
+	{Type: bytes32Type},
+	{Type: bytes32Type},
+	{Type: uint256Type},
+	{Type: uint256Type},
+	{Type: uint256Type},
+	{Type: uint256Type},
+	{Type: uint256Type},
+	{Type: bytes32Type},
+}
+
+// Hash returns the hash the account owner signs: the hash of the packed operation
+// bound to the entry point and chain
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	packed, err := packedOperation.Pack(
+		op.Sender,
+		bigOrZero(op.Nonce),
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		bigOrZero(op.CallGasLimit),
+		bigOrZero(op.VerificationGasLimit),
+		bigOrZero(op.PreVerificationGas),
+		bigOrZero(op.MaxFeePerGas),
+		bigOrZero(op.MaxPriorityFeePerGas),
+		crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack user operation: %w", err)
+	}
+
+	bound, err := abi.Arguments{{Type: bytes32Type}, {Type: addressType}, {Type: uint256Type}}.Pack(
+		crypto.Keccak256Hash(packed),
+		entryPoint,
+		chainID,
+	)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack user operation hash: %w", err)
+	}
+	return crypto.Keccak256Hash(bound), nil
+}
+
+func bigOrZero(value *hexutil.Big) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value.ToInt()
+}
@@ -0,0 +1,156 @@
+// Package errreport reports errors and recovered panics, with their stack and the
+// request, tenant and job they happened in, to an error tracker such as Sentry.
+package errreport
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Tags naming what an event happened in
+const (
+	TagRequestID = "request_id"
+	TagTenantID  = "tenant_id"
+	TagJobID     = "job_id"
+	TagEventID   = "event_id"
+	TagComponent = "component"
+	TagRoute     = "route"
+)
+
+// Levels of events
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Frame is a stack frame of an event
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Event is an error or a recovered panic to report
+type Event struct {
+	Level   string
+	Message string
+	// Panic is set for recovered panics, whose stack is the panicking goroutine's
+	Panic bool
+	// Frames lists the stack, innermost call first
+	Frames    []Frame
+	Tags      map[string]string
+	Timestamp time.Time
+}
+
+// Reporter delivers events to an error tracker. Report must not block the caller on
+// the tracker for long, as it runs in request handlers and workers.
+type Reporter interface {
+	Report(event *Event)
+}
+
+// Log is a reporter writing events to the standard logger, the default when no error
+// tracker is configured
+var Log Reporter = logReporter{}
+
+type logReporter struct{}
+
+func (logReporter) Report(event *Event) {
+	kind := "error"
+	if event.Panic {
+		kind = "panic"
+	}
+	log.Printf("[ErrorReport] %s%s: %s\n%s", kind, event.formatTags(), event.Message, event.StackTrace())
+}
+
+// Multi returns a reporter reporting each event to every reporter
+func Multi(reporters ...Reporter) Reporter {
+	return multiReporter(reporters)
+}
+
+type multiReporter []Reporter
+
+func (m multiReporter) Report(event *Event) {
+	for _, reporter := range m {
+		reporter.Report(event)
+	}
+}
+
+// Panic builds the event of a recovered panic. Call it from the deferred function
+// that recovered the panic, so the stack is the panicking goroutine's.
+func Panic(value any, tags map[string]string) *Event {
+	return &Event{
+		Level:     LevelFatal,
+		Message:   fmt.Sprint(value),
+		Panic:     true,
+		Frames:    callers(3),
+		Tags:      tags,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// Error builds the event of an error, with the stack of its caller
+func Error(err error, tags map[string]string) *Event {
+	return &Event{
+		Level:     LevelError,
+		Message:   err.Error(),
+		Frames:    callers(3),
+		Tags:      tags,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// Recover recovers a panic of the function deferring it and reports it, so the
+// function returns normally instead of killing the process:
+//
+//	defer errreport.Recover(reporter, map[string]string{errreport.TagComponent: "worker"})
+func Recover(reporter Reporter, tags map[string]string) {
+	if value := recover(); value != nil {
+		reporter.Report(Panic(value, tags))
+	}
+}
+
+// StackTrace formats the event's stack like a goroutine trace
+func (e *Event) StackTrace() string {
+	var b strings.Builder
+	for _, frame := range e.Frames {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// formatTags formats the event's tags for logs
+func (e *Event) formatTags() string {
+	var b strings.Builder
+	for _, key := range []string{TagComponent, TagRoute, TagRequestID, TagTenantID, TagJobID, TagEventID} {
+		if value := e.Tags[key]; value != "" {
+			fmt.Fprintf(&b, " %s=%s", key, value)
+		}
+	}
+	return b.String()
+}
+
+// callers returns the stack of the goroutine, skipping the given number of frames and
+// the runtime's panic machinery
+func callers(skip int) []Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	iter := runtime.CallersFrames(pcs[:n])
+
+	var frames []Frame
+	for {
+		frame, more := iter.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			frames = append(frames, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			return frames
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package errreport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the events it is given
+type recorder struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (r *recorder) Report(event *Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func panicking() {
+	panic("boom")
+}
+
+func TestRecover(t *testing.T) {
+	reporter := &recorder{}
+	func() {
+		defer Recover(reporter, map[string]string{TagJobID: "job-1"})
+		panicking()
+	}()
+
+	if len(reporter.events) != 1 {
+		t.Fatalf("expected one event, got %d", len(reporter.events))
+	}
+	event := reporter.events[0]
+	if !event.Panic || event.Level != LevelFatal || event.Message != "boom" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.Tags[TagJobID] != "job-1" {
+		t.Errorf("expected the job ID tag, got %v", event.Tags)
+	}
+	if !strings.Contains(event.StackTrace(), "errreport.panicking") {
+		t.Errorf("expected the panicking function in the stack, got:\n%s", event.StackTrace())
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	reporter := &recorder{}
+	func() {
+		defer Recover(reporter, nil)
+	}()
+	if len(reporter.events) != 0 {
+		t.Errorf("expected no events, got %d", len(reporter.events))
+	}
+}
+
+func TestMulti(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	Multi(first, second).Report(Error(errors.New("failed"), nil))
+	if len(first.events) != 1 || len(second.events) != 1 {
+		t.Error("expected every reporter to get the event")
+	}
+}
+
+func TestNewSentryDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"https://key@sentry.example.com/42", "https://sentry.example.com/api/42/store/"},
+		{"https://key@example.com/sentry/42", "https://example.com/sentry/api/42/store/"},
+	}
+	for _, tt := range tests {
+		s, err := NewSentry(tt.dsn, "test")
+		if err != nil {
+			t.Fatalf("NewSentry(%q) failed: %v", tt.dsn, err)
+		}
+		if s.storeURL != tt.want {
+			t.Errorf("NewSentry(%q) store URL = %q, want %q", tt.dsn, s.storeURL, tt.want)
+		}
+	}
+
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://key@sentry.example.com/", "::"} {
+		if _, err := NewSentry(dsn, "test"); err == nil {
+			t.Errorf("expected an error for DSN %q", dsn)
+		}
+	}
+}
+
+func TestSentryReport(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Sentry-Auth")
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		received <- body
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public@", 1) + "/7"
+	s, err := NewSentry(dsn, "staging")
+	if err != nil {
+		t.Fatalf("NewSentry failed: %v", err)
+	}
+	s.Report(Panic("boom", map[string]string{TagRequestID: "req-1", TagTenantID: "tenant-1"}))
+
+	select {
+	case body := <-received:
+		if body["environment"] != "staging" || body["level"] != LevelFatal {
+			t.Errorf("unexpected event %v", body)
+		}
+		tags, _ := body["tags"].(map[string]any)
+		if tags[TagRequestID] != "req-1" || tags[TagTenantID] != "tenant-1" {
+			t.Errorf("expected request and tenant tags, got %v", tags)
+		}
+		if !strings.Contains(auth, "sentry_key=public") {
+			t.Errorf("expected the DSN key in X-Sentry-Auth, got %q", auth)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the event to be posted")
+	}
+}
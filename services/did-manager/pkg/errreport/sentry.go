@@ -0,0 +1,145 @@
+package errreport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// sentryClient identifies the reporter to the tracker
+const sentryClient = "did-manager-errreport/1.0"
+
+// Sentry reports events to Sentry, or a tracker speaking its store API such as
+// GlitchTip, identified by a DSN of the form https://<key>@<host>/<project>. Events are
+// sent in the background, so reporting never waits on the tracker.
+type Sentry struct {
+	storeURL    string
+	auth        string
+	environment string
+	serverName  string
+	client      *http.Client
+}
+
+// NewSentry creates a reporter sending events of an environment, such as production,
+// to the project of a Sentry DSN
+func NewSentry(dsn, environment string) (*Sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	key := u.User.Username()
+	path, project := "", strings.Trim(u.Path, "/")
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		path, project = project[:i], project[i+1:]
+	}
+	if key == "" || project == "" || u.Host == "" {
+		return nil, errors.New("invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = "/" + path
+	}
+	serverName, _ := os.Hostname()
+	return &Sentry{
+		storeURL:    fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, key),
+		environment: environment,
+		serverName:  serverName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Report sends an event in the background, logging it when it cannot be delivered
+func (s *Sentry) Report(event *Event) {
+	go func() {
+		if err := s.send(event); err != nil {
+			log.Printf("Failed to report error to Sentry: %v", err)
+			Log.Report(event)
+		}
+	}()
+}
+
+// sentryFrame is a stack frame of the store API, listed outermost call first
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// send posts an event to the store API
+func (s *Sentry) send(event *Event) error {
+	payload, err := json.Marshal(s.payload(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.storeURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tracker answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// payload builds the store API body of an event
+func (s *Sentry) payload(event *Event) map[string]any {
+	frames := make([]sentryFrame, 0, len(event.Frames))
+	for i := len(event.Frames) - 1; i >= 0; i-- {
+		frame := event.Frames[i]
+		frames = append(frames, sentryFrame{
+			Function: frame.Function,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, "did-manager/") || strings.HasPrefix(frame.Function, "main."),
+		})
+	}
+
+	exceptionType := "error"
+	if event.Panic {
+		exceptionType = "panic"
+	}
+	return map[string]any{
+		"event_id":    eventID(),
+		"timestamp":   event.Timestamp.Format(time.RFC3339),
+		"platform":    "go",
+		"level":       event.Level,
+		"environment": s.environment,
+		"server_name": s.serverName,
+		"tags":        event.Tags,
+		"exception": map[string]any{
+			"values": []map[string]any{{
+				"type":       exceptionType,
+				"value":      event.Message,
+				"stacktrace": map[string]any{"frames": frames},
+			}},
+		},
+	}
+}
+
+// eventID returns a random event ID, 32 hex characters
+func eventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,88 @@
+package identity
+
+import (
+	"time"
+
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// CredentialRequest describes a credential to issue
+type CredentialRequest struct {
+	// ID identifies the credential, defaulting to a random urn:uuid
+	ID      string
+	Issuer  string
+	Subject string
+	Type    string
+	Claims  map[string]any
+	// IssuedAt defaults to now, on the Identity's clock when issued with IssueCredential
+	IssuedAt  time.Time
+	ExpiresAt *time.Time
+}
+
+// NewCredential builds an unsigned verifiable credential from a request, with the
+// subject's DID as the id claim; a zero IssuedAt is the system time
+func NewCredential(req CredentialRequest) *did.Credential {
+	id := req.ID
+	if id == "" {
+		id = "urn:uuid:" + uuid.New().String()
+	}
+	issuedAt := req.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+
+	subject := make(map[string]any, len(req.Claims)+1)
+	for k, v := range req.Claims {
+		subject[k] = v
+	}
+	subject["id"] = req.Subject
+
+	credential := &did.Credential{
+		Context:           []string{did.ContextCredentialsV1},
+		ID:                id,
+		Type:              []string{did.TypeVerifiableCredential, req.Type},
+		Issuer:            req.Issuer,
+		IssuanceDate:      issuedAt.UTC().Format(time.RFC3339),
+		CredentialSubject: subject,
+	}
+	if req.ExpiresAt != nil {
+		credential.ExpirationDate = req.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return credential
+}
+
+// IssueCredential builds a credential and signs it with the issuer's key, as the
+// issuer's first verification method
+func (i *Identity) IssueCredential(req CredentialRequest, key Key) (*did.Credential, error) {
+	if req.IssuedAt.IsZero() {
+		req.IssuedAt = i.clock.Now()
+	}
+	credential := NewCredential(req)
+	if err := i.SignCredential(credential, key); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// SignCredential signs a credential with the issuer's key, as the issuer's first
+// verification method
+func (i *Identity) SignCredential(credential *did.Credential, key Key) error {
+	suite, err := i.Registry().SignatureSuite(key.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	return did.SignCredential(suite, key.PrivateKey, credential, did.ProofOptions{
+		VerificationMethod: credential.Issuer + "#key-1",
+		ProofPurpose:       did.ProofPurposeAssertionMethod,
+		Created:            i.clock.Now(),
+	})
+}
+
+// VerifyCredential checks a credential's proof with the issuer's public key. Whether
+// the issuer's DID is still active is up to the caller, e.g. with VerifyAnchor.
+func (i *Identity) VerifyCredential(credential *did.Credential, publicKey []byte) error {
+	return did.VerifyCredential(i.Registry(), publicKey, credential)
+}
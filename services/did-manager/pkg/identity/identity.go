@@ -0,0 +1,177 @@
+// Package identity is the embeddable core of the DID manager. It creates DIDs bound to
+// their holders' claims, builds their DID Documents, issues and verifies credentials and
+// anchors DIDs on a ledger, without the database, queue or HTTP server of the service,
+// so other Go services can embed identity features by importing this package.
+package identity
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/clock"
+	"did-manager/pkg/did"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoAnchor is returned for anchoring operations when no anchor is configured
+var ErrNoAnchor = errors.New("no anchor is configured")
+
+// Anchor records DIDs on a ledger. *blockchain.EthereumClient anchors them in the DID
+// registry contract.
+type Anchor interface {
+	RegisterDID(ctx context.Context, userHash, did string) (string, error)
+	RevokeDID(ctx context.Context, userHash string) (string, error)
+	VerifyDID(did string) (*blockchain.ChainCheck, error)
+}
+
+// Config configures an Identity
+type Config struct {
+	// Pepper is a server-side secret keying commitments, so claims cannot be
+	// brute-forced from DIDs; it must stay the same for commitments to verify
+	Pepper []byte
+	// HashScheme selects the commitment scheme for new DIDs, defaulting to
+	// did.HashSchemeArgon2id
+	HashScheme string
+	// SignatureSuite selects the key algorithm for new DIDs, defaulting to
+	// did.SignatureSuiteEd25519
+	SignatureSuite string
+	// Argon2Params tunes the Argon2id scheme; the zero value uses did.DefaultArgon2Params
+	Argon2Params did.Argon2Params
+	// ExperimentalSuites allows experimental signature suites for new keys
+	ExperimentalSuites bool
+	// Anchor records DIDs on a ledger; nil leaves DIDs unanchored
+	Anchor Anchor
+	// Clock dates credentials, their proofs and legacy user hashes; nil uses clock.System
+	Clock clock.Clock
+}
+
+// Identity creates and verifies DIDs and credentials
+type Identity struct {
+	generator *did.Generator
+	anchor    Anchor
+	clock     clock.Clock
+}
+
+// New creates an Identity from the given configuration
+func New(cfg Config) (*Identity, error) {
+	generator, err := did.NewGeneratorWithConfig(did.GeneratorConfig{
+		Pepper:             cfg.Pepper,
+		HashScheme:         cfg.HashScheme,
+		SignatureSuite:     cfg.SignatureSuite,
+		Argon2Params:       cfg.Argon2Params,
+		ExperimentalSuites: cfg.ExperimentalSuites,
+		Clock:              cfg.Clock,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.System
+	}
+	return &Identity{generator: generator, anchor: cfg.Anchor, clock: clk}, nil
+}
+
+// Registry returns the registry of hash schemes and signature suites, e.g. to register
+// additional algorithms
+func (i *Identity) Registry() *did.Registry {
+	return i.generator.Registry()
+}
+
+// Key is the key pair of a DID under a signature suite
+type Key struct {
+	Algorithm  string
+	PublicKey  []byte
+	PrivateKey []byte
+}
+
+// DID is a newly created DID with its commitment and key. The embedding service
+// stores them; the private key is needed to sign on the DID's behalf and should be
+// encrypted at rest or handed to the holder.
+type DID struct {
+	ID string
+	// Commitment binds the DID to its holder's claims; keep all of it to verify claims
+	Commitment *did.Commitment
+	Key        Key
+}
+
+// UserHash is the commitment value the DID is anchored by
+func (d *DID) UserHash() string {
+	return d.Commitment.Value
+}
+
+// CreateDID creates a DID committing to the holder's name and email
+func (i *Identity) CreateDID(name, email string) (*DID, error) {
+	generated, err := i.generator.GenerateDIDWithSuite(uuid.Nil, name, email, "")
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := hex.DecodeString(generated.PrivateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode generated key: %w", err)
+	}
+
+	return &DID{
+		ID:         generated.DID,
+		Commitment: generated.Commitment,
+		Key: Key{
+			Algorithm:  generated.KeyAlgorithm,
+			PublicKey:  generated.PublicKey,
+			PrivateKey: privateKey,
+		},
+	}, nil
+}
+
+// VerifyClaims reports whether a name and email match a DID's commitment, in canonical
+// form or as given for commitments made before canonicalization. Commitments of the
+// legacy did.HashSchemeSHA256 scheme cannot be recomputed and return
+// did.ErrCommitmentNotRecomputable.
+func (i *Identity) VerifyClaims(commitment *did.Commitment, name, email string) (bool, error) {
+	return i.generator.VerifyClaims(commitment, name, email)
+}
+
+// Document builds the DID Document of a DID controlled by a single key. keyMaterial is
+// the public key, or the private key of the suite to derive it from.
+func (i *Identity) Document(didString, algorithm string, keyMaterial []byte) (*did.Document, error) {
+	suite, err := i.Registry().SignatureSuite(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := suite.PublicKey(keyMaterial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DID key: %w", err)
+	}
+
+	return did.NewDocument(didString, suite.VerificationMethodType(), publicKey), nil
+}
+
+// Anchor registers a DID on the ledger, returning the transaction hash
+func (i *Identity) Anchor(ctx context.Context, d *DID) (string, error) {
+	if i.anchor == nil {
+		return "", ErrNoAnchor
+	}
+	return i.anchor.RegisterDID(ctx, d.UserHash(), d.ID)
+}
+
+// Revoke revokes the anchored DID of a commitment, returning the transaction hash
+func (i *Identity) Revoke(ctx context.Context, userHash string) (string, error) {
+	if i.anchor == nil {
+		return "", ErrNoAnchor
+	}
+	return i.anchor.RevokeDID(ctx, userHash)
+}
+
+// VerifyAnchor checks a DID against the ledger
+func (i *Identity) VerifyAnchor(didString string) (*blockchain.ChainCheck, error) {
+	if i.anchor == nil {
+		return nil, ErrNoAnchor
+	}
+	return i.anchor.VerifyDID(didString)
+}
@@ -0,0 +1,122 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"did-manager/pkg/blockchain"
+	"did-manager/pkg/did"
+)
+
+// fakeAnchor records the DIDs registered with it
+type fakeAnchor struct {
+	registered map[string]string
+}
+
+func (a *fakeAnchor) RegisterDID(_ context.Context, userHash, didString string) (string, error) {
+	a.registered[didString] = userHash
+	return "0xtx", nil
+}
+
+func (a *fakeAnchor) RevokeDID(context.Context, string) (string, error) {
+	return "0xrevoke", nil
+}
+
+func (a *fakeAnchor) VerifyDID(didString string) (*blockchain.ChainCheck, error) {
+	_, ok := a.registered[didString]
+	return &blockchain.ChainCheck{Valid: ok}, nil
+}
+
+func newTestIdentity(t *testing.T, anchor Anchor) *Identity {
+	t.Helper()
+	id, err := New(Config{
+		Pepper:       []byte("pepper"),
+		Argon2Params: did.Argon2Params{Time: 1, MemoryKiB: 1024, Threads: 1, KeyLength: 32},
+		Anchor:       anchor,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return id
+}
+
+func TestCreateDIDAndVerifyClaims(t *testing.T) {
+	id := newTestIdentity(t, nil)
+
+	created, err := id.CreateDID("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("CreateDID failed: %v", err)
+	}
+	if ok, err := id.VerifyClaims(created.Commitment, "Alice", "alice@example.com"); err != nil || !ok {
+		t.Errorf("expected the claims to verify, got %v, %v", ok, err)
+	}
+	if ok, _ := id.VerifyClaims(created.Commitment, "Alice", "bob@example.com"); ok {
+		t.Error("expected other claims not to verify")
+	}
+
+	// The document is the same from the public or the private key
+	fromPrivate, err := id.Document(created.ID, created.Key.Algorithm, created.Key.PrivateKey)
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	fromPublic, err := id.Document(created.ID, created.Key.Algorithm, created.Key.PublicKey)
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if fromPrivate.VerificationMethod[0].PublicKeyHex != fromPublic.VerificationMethod[0].PublicKeyHex {
+		t.Error("expected the same verification method from either key")
+	}
+}
+
+func TestIssueAndVerifyCredential(t *testing.T) {
+	id := newTestIdentity(t, nil)
+	issuer, err := id.CreateDID("Issuer", "issuer@example.com")
+	if err != nil {
+		t.Fatalf("CreateDID failed: %v", err)
+	}
+
+	credential, err := id.IssueCredential(CredentialRequest{
+		Issuer:  issuer.ID,
+		Subject: "did:example:subject",
+		Type:    "EmployeeCredential",
+		Claims:  map[string]any{"role": "engineer"},
+	}, issuer.Key)
+	if err != nil {
+		t.Fatalf("IssueCredential failed: %v", err)
+	}
+	if credential.CredentialSubject["id"] != "did:example:subject" {
+		t.Errorf("expected the subject as id claim, got %v", credential.CredentialSubject)
+	}
+	if err := id.VerifyCredential(credential, issuer.Key.PublicKey); err != nil {
+		t.Errorf("expected the credential to verify, got %v", err)
+	}
+
+	credential.CredentialSubject["role"] = "admin"
+	if err := id.VerifyCredential(credential, issuer.Key.PublicKey); err == nil {
+		t.Error("expected a tampered credential not to verify")
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	unanchored := newTestIdentity(t, nil)
+	created, err := unanchored.CreateDID("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("CreateDID failed: %v", err)
+	}
+	if _, err := unanchored.Anchor(context.Background(), created); !errors.Is(err, ErrNoAnchor) {
+		t.Errorf("expected ErrNoAnchor, got %v", err)
+	}
+
+	anchor := &fakeAnchor{registered: make(map[string]string)}
+	anchored := newTestIdentity(t, anchor)
+	if _, err := anchored.Anchor(context.Background(), created); err != nil {
+		t.Fatalf("Anchor failed: %v", err)
+	}
+	if anchor.registered[created.ID] != created.UserHash() {
+		t.Errorf("expected the DID anchored by its user hash, got %v", anchor.registered)
+	}
+	if check, err := anchored.VerifyAnchor(created.ID); err != nil || !check.Valid {
+		t.Errorf("expected the anchored DID to verify, got %+v, %v", check, err)
+	}
+}
@@ -0,0 +1,283 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"did-manager/pkg/clock"
+)
+
+// Component is a long-running part of the service. Start returns once the component
+// is up, leaving any loop running in the background; Stop returns once it is down.
+// Either may be nil for components with nothing to do on that side.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+	// StartTimeout and StopTimeout bound Start and Stop; zero uses the manager defaults
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+}
+
+// State is the lifecycle state of a component
+type State string
+
+const (
+	StateIdle     State = "idle"
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateStopping State = "stopping"
+	StateStopped  State = "stopped"
+	StateFailed   State = "failed"
+)
+
+// Status reports the state of one component
+type Status struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// PanicHandler is told about a panic recovered from a run of a periodic component. It
+// is called from the deferred function recovering the panic, so it can capture the
+// panicking stack.
+type PanicHandler func(component string, value any)
+
+// panicHandlerKey carries the manager's PanicHandler to the components it starts
+type panicHandlerKey struct{}
+
+// ErrAlreadyStarted is returned when Start is called more than once
+var ErrAlreadyStarted = errors.New("lifecycle already started")
+
+// Manager starts components in the order they were added and stops them in reverse,
+// so each component can rely on those added before it. The manager is ready only
+// while every component is running.
+type Manager struct {
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+
+	mu         sync.RWMutex
+	components []Component
+	states     []Status
+	started    bool
+	ready      bool
+	onPanic    PanicHandler
+}
+
+// NewManager creates a manager with the default per-component timeouts
+func NewManager(startTimeout, stopTimeout time.Duration) *Manager {
+	return &Manager{
+		startTimeout: startTimeout,
+		stopTimeout:  stopTimeout,
+	}
+}
+
+// SetPanicHandler sets the handler told about panics recovered from periodic
+// components, which otherwise log them with their stack; it must be set before Start
+func (m *Manager) SetPanicHandler(handler PanicHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPanic = handler
+}
+
+// Add appends a component; components must be added before Start
+func (m *Manager) Add(component Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component)
+	m.states = append(m.states, Status{Name: component.Name, State: StateIdle})
+}
+
+// Start starts every component in order. When a component fails to start, those
+// already started are stopped in reverse order and the start error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.started {
+		m.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	m.started = true
+	components := m.components
+	if m.onPanic != nil {
+		ctx = context.WithValue(ctx, panicHandlerKey{}, m.onPanic)
+	}
+	m.mu.Unlock()
+
+	for i, component := range components {
+		m.setState(i, StateStarting, nil)
+		if err := m.run(ctx, component.Start, component.StartTimeout, m.startTimeout); err != nil {
+			m.setState(i, StateFailed, err)
+			m.stopFrom(ctx, i-1)
+			return fmt.Errorf("failed to start %s: %w", component.Name, err)
+		}
+		m.setState(i, StateRunning, nil)
+	}
+
+	m.mu.Lock()
+	m.ready = true
+	m.mu.Unlock()
+	return nil
+}
+
+// Stop stops every running component in reverse order. Every component is given the
+// chance to stop even when an earlier one fails; the errors are joined.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.RLock()
+	last := len(m.components) - 1
+	m.mu.RUnlock()
+	return m.stopFrom(ctx, last)
+}
+
+// Ready reports whether every component is running
+func (m *Manager) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ready
+}
+
+// Statuses reports the state of every component in start order
+func (m *Manager) Statuses() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make([]Status, len(m.states))
+	copy(statuses, m.states)
+	return statuses
+}
+
+// stopFrom stops the running components from index last down to the first
+func (m *Manager) stopFrom(ctx context.Context, last int) error {
+	m.mu.Lock()
+	m.ready = false
+	m.mu.Unlock()
+
+	var errs []error
+	for i := last; i >= 0; i-- {
+		m.mu.RLock()
+		component, state := m.components[i], m.states[i].State
+		m.mu.RUnlock()
+		if state != StateRunning {
+			continue
+		}
+
+		m.setState(i, StateStopping, nil)
+		if err := m.run(ctx, component.Stop, component.StopTimeout, m.stopTimeout); err != nil {
+			m.setState(i, StateFailed, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", component.Name, err))
+			continue
+		}
+		m.setState(i, StateStopped, nil)
+	}
+	return errors.Join(errs...)
+}
+
+// run calls fn under the component timeout, or the manager default when unset. fn
+// is abandoned when it outlives the timeout, so a hung component cannot block the
+// rest of the sequence.
+func (m *Manager) run(ctx context.Context, fn func(context.Context) error, timeout, defaultTimeout time.Duration) error {
+	if fn == nil {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) setState(i int, state State, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[i].State = state
+	m.states[i].Error = ""
+	if err != nil {
+		m.states[i].Error = err.Error()
+	}
+}
+
+// Periodic creates a component running fn every interval until stopped. Stop cancels
+// the context passed to fn and waits for a run in progress to return. A run that
+// panics is recovered and reported to the manager's PanicHandler, and the next run
+// happens as scheduled.
+func Periodic(name string, interval time.Duration, fn func(ctx context.Context)) Component {
+	return PeriodicWithClock(name, clock.System, interval, fn)
+}
+
+// PeriodicWithClock creates a periodic component ticking on clk, so runs can be driven
+// by a manual clock
+func PeriodicWithClock(name string, clk clock.Clock, interval time.Duration, fn func(ctx context.Context)) Component {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	return Component{
+		Name: name,
+		Start: func(startCtx context.Context) error {
+			onPanic, _ := startCtx.Value(panicHandlerKey{}).(PanicHandler)
+			if onPanic == nil {
+				onPanic = logPanic
+			}
+
+			// The loop outlives Start, so it runs under its own context
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+
+			go func() {
+				defer close(done)
+				ticker := clk.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C():
+						runRecovered(ctx, name, fn, onPanic)
+					}
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// runRecovered runs fn, recovering a panic and handing it to onPanic
+func runRecovered(ctx context.Context, name string, fn func(ctx context.Context), onPanic PanicHandler) {
+	defer func() {
+		if value := recover(); value != nil {
+			onPanic(name, value)
+		}
+	}()
+	fn(ctx)
+}
+
+// logPanic logs a panic recovered from a component with its stack
+func logPanic(component string, value any) {
+	log.Printf("[Recovery] panic in %s: %v\n%s", component, value, debug.Stack())
+}
@@ -0,0 +1,240 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"did-manager/pkg/clock"
+)
+
+// recorder records start and stop calls across components
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) component(name string, startErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			r.record("start " + name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func TestManagerOrder(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(time.Second, time.Second)
+	m.Add(rec.component("events", nil))
+	m.Add(rec.component("worker", nil))
+	m.Add(rec.component("http", nil))
+
+	if m.Ready() {
+		t.Fatal("expected not ready before start")
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !m.Ready() {
+		t.Fatal("expected ready once every component started")
+	}
+	if err := m.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("expected ErrAlreadyStarted, got %v", err)
+	}
+
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if m.Ready() {
+		t.Error("expected not ready after stop")
+	}
+
+	want := []string{"start events", "start worker", "start http", "stop http", "stop worker", "stop events"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("expected %v, got %v", want, rec.calls)
+	}
+	for _, status := range m.Statuses() {
+		if status.State != StateStopped {
+			t.Errorf("expected %s stopped, got %s", status.Name, status.State)
+		}
+	}
+}
+
+func TestManagerStartFailureRollsBack(t *testing.T) {
+	rec := &recorder{}
+	m := NewManager(time.Second, time.Second)
+	m.Add(rec.component("events", nil))
+	m.Add(rec.component("worker", errors.New("boom")))
+	m.Add(rec.component("http", nil))
+
+	if err := m.Start(context.Background()); err == nil {
+		t.Fatal("expected start to fail")
+	}
+	if m.Ready() {
+		t.Error("expected not ready after a failed start")
+	}
+
+	want := []string{"start events", "start worker", "stop events"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("expected %v, got %v", want, rec.calls)
+	}
+
+	statuses := m.Statuses()
+	if statuses[1].State != StateFailed || statuses[1].Error == "" {
+		t.Errorf("expected worker failed with an error, got %+v", statuses[1])
+	}
+	if statuses[2].State != StateIdle {
+		t.Errorf("expected http never started, got %s", statuses[2].State)
+	}
+}
+
+func TestManagerTimeouts(t *testing.T) {
+	m := NewManager(time.Second, 20*time.Millisecond)
+	m.Add(Component{
+		Name: "hung",
+		Stop: func(ctx context.Context) error {
+			select {} // never returns
+		},
+	})
+	m.Add(Component{
+		Name:         "slow",
+		StartTimeout: 20 * time.Millisecond,
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	err := m.Start(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the component start timeout, got %v", err)
+	}
+
+	// The rollback abandoned the hung stop after the default stop timeout
+	statuses := m.Statuses()
+	if statuses[0].State != StateFailed {
+		t.Errorf("expected hung component failed to stop, got %s", statuses[0].State)
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	runs := make(chan struct{}, 10)
+	component := Periodic("ticker", 5*time.Millisecond, func(context.Context) {
+		runs <- struct{}{}
+	})
+
+	if err := component.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected a periodic run")
+	}
+
+	if err := component.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	// Drain runs that happened before the stop, then make sure none follow
+	for len(runs) > 0 {
+		<-runs
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(runs) != 0 {
+		t.Error("expected no runs after stop")
+	}
+}
+
+func TestPeriodicWithClock(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	runs := make(chan struct{}, 10)
+	component := PeriodicWithClock("ticker", clk, time.Hour, func(context.Context) {
+		runs <- struct{}{}
+	})
+
+	if err := component.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer component.Stop(context.Background())
+
+	// The loop may not have created its ticker yet, so advance until it runs
+	deadline := time.After(time.Second)
+	for ran := false; !ran; {
+		clk.Advance(time.Hour)
+		select {
+		case <-runs:
+			ran = true
+		case <-deadline:
+			t.Fatal("expected a run once the clock passed the interval")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	// Drain runs of ticks delivered while waiting, then stay short of the next interval
+	time.Sleep(20 * time.Millisecond)
+	for len(runs) > 0 {
+		<-runs
+	}
+	clk.Advance(30 * time.Minute)
+	select {
+	case <-runs:
+		t.Error("expected no run before the next interval")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestPeriodicRecoversPanics(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	panics := make(chan string, 10)
+	runs := make(chan struct{}, 10)
+	first := true
+
+	m := NewManager(time.Second, time.Second)
+	m.SetPanicHandler(func(component string, value any) {
+		panics <- component
+	})
+	m.Add(PeriodicWithClock("flaky", clk, time.Minute, func(context.Context) {
+		if first {
+			first = false
+			panic("boom")
+		}
+		runs <- struct{}{}
+	}))
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer m.Stop(context.Background())
+
+	// The panicking run is reported, and the loop keeps running
+	deadline := time.After(time.Second)
+	for reported, ran := false, false; !reported || !ran; {
+		clk.Advance(time.Minute)
+		select {
+		case component := <-panics:
+			if component != "flaky" {
+				t.Errorf("expected the panic of flaky, got %s", component)
+			}
+			reported = true
+		case <-runs:
+			ran = true
+		case <-deadline:
+			t.Fatal("expected a reported panic followed by a run")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
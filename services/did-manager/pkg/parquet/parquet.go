@@ -0,0 +1,241 @@
+// Package parquet writes flat tables as Apache Parquet files for analytics tools and
+// data warehouses. It supports the subset the service needs: required string, int64
+// and timestamp columns, written as a single row group of GZIP-compressed, PLAIN
+// encoded pages.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// createdBy identifies the writer in the file metadata
+const createdBy = "did-manager parquet writer"
+
+// ColumnType is the type of a column's values
+type ColumnType int
+
+const (
+	// String columns hold UTF-8 strings
+	String ColumnType = iota
+	// Int64 columns hold int64 values
+	Int64
+	// Timestamp columns hold time.Time values, stored as UTC milliseconds
+	Timestamp
+)
+
+// Column is a required column of a table
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Parquet physical types, converted types, encodings, codecs and page types used
+const (
+	typeInt64     = 2
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecGzip = 2
+
+	pageTypeData = 0
+)
+
+// ErrInvalidRow is returned for rows whose values do not match the columns
+var ErrInvalidRow = errors.New("row does not match the columns")
+
+// Encode writes rows as a Parquet file. Each row holds one value per column: a string,
+// int64 or time.Time as the column's type requires.
+func Encode(columns []Column, rows [][]any) ([]byte, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("a Parquet file needs at least one column")
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("%w: row %d has %d values for %d columns", ErrInvalidRow, i, len(row), len(columns))
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]*columnChunk, 0, len(columns))
+	var totalSize int64
+	for i, column := range columns {
+		values, err := plainValues(column, i, rows)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := writeColumn(&file, column, values, len(rows))
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+		totalSize += chunk.uncompressedSize
+	}
+
+	footer := fileMetaData(columns, chunks, int64(len(rows)), totalSize)
+	file.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	file.Write(length[:])
+	file.WriteString(magic)
+
+	return file.Bytes(), nil
+}
+
+// plainValues PLAIN-encodes the values of one column
+func plainValues(column Column, index int, rows [][]any) ([]byte, error) {
+	var values bytes.Buffer
+	var scratch [8]byte
+	for i, row := range rows {
+		switch column.Type {
+		case String:
+			value, ok := row[index].(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s of row %d is not a string", ErrInvalidRow, column.Name, i)
+			}
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(value)))
+			values.Write(scratch[:4])
+			values.WriteString(value)
+		case Int64:
+			value, ok := row[index].(int64)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s of row %d is not an int64", ErrInvalidRow, column.Name, i)
+			}
+			binary.LittleEndian.PutUint64(scratch[:], uint64(value))
+			values.Write(scratch[:])
+		case Timestamp:
+			value, ok := row[index].(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s of row %d is not a time", ErrInvalidRow, column.Name, i)
+			}
+			binary.LittleEndian.PutUint64(scratch[:], uint64(value.UnixMilli()))
+			values.Write(scratch[:])
+		default:
+			return nil, fmt.Errorf("unsupported type of column %s", column.Name)
+		}
+	}
+	return values.Bytes(), nil
+}
+
+// columnChunk locates a written column chunk
+type columnChunk struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+	numValues        int64
+}
+
+// writeColumn appends a column chunk of a single data page. Required columns of a flat
+// schema have no repetition or definition levels, so the page holds only the values.
+func writeColumn(file *bytes.Buffer, column Column, values []byte, numValues int) (*columnChunk, error) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(values); err != nil {
+		return nil, fmt.Errorf("failed to compress column %s: %w", column.Name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress column %s: %w", column.Name, err)
+	}
+
+	var header compactWriter
+	header.i32(1, pageTypeData)
+	header.i32(2, int32(len(values)))
+	header.i32(3, int32(compressed.Len()))
+	header.beginStruct(5)
+	header.i32(1, int32(numValues))
+	header.i32(2, encodingPlain)
+	header.i32(3, encodingRLE)
+	header.i32(4, encodingRLE)
+	header.endStruct()
+	header.stop()
+
+	chunk := &columnChunk{
+		offset:           int64(file.Len()),
+		uncompressedSize: int64(header.buf.Len() + len(values)),
+		compressedSize:   int64(header.buf.Len() + compressed.Len()),
+		numValues:        int64(numValues),
+	}
+	file.Write(header.buf.Bytes())
+	file.Write(compressed.Bytes())
+	return chunk, nil
+}
+
+// fileMetaData encodes the footer describing the schema and the single row group
+func fileMetaData(columns []Column, chunks []*columnChunk, numRows, totalSize int64) []byte {
+	var meta compactWriter
+	meta.i32(1, 1)
+
+	meta.beginList(2, compactStruct, len(columns)+1)
+	meta.beginElement()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.endStruct()
+	for _, column := range columns {
+		meta.beginElement()
+		meta.i32(1, physicalType(column.Type))
+		meta.i32(3, repetitionRequired)
+		meta.binary(4, column.Name)
+		switch column.Type {
+		case String:
+			meta.i32(6, convertedUTF8)
+		case Timestamp:
+			meta.i32(6, convertedTimestampMillis)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64(3, numRows)
+
+	meta.beginList(4, compactStruct, 1)
+	meta.beginElement()
+	meta.beginList(1, compactStruct, len(chunks))
+	for i, chunk := range chunks {
+		meta.beginElement()
+		meta.i64(2, chunk.offset)
+		meta.beginStruct(3)
+		meta.i32(1, physicalType(columns[i].Type))
+		meta.beginList(2, compactI32, 2)
+		meta.listI32(encodingPlain)
+		meta.listI32(encodingRLE)
+		meta.beginList(3, compactBinary, 1)
+		meta.listBinary(columns[i].Name)
+		meta.i32(4, codecGzip)
+		meta.i64(5, chunk.numValues)
+		meta.i64(6, chunk.uncompressedSize)
+		meta.i64(7, chunk.compressedSize)
+		meta.i64(9, chunk.offset)
+		meta.endStruct()
+		meta.endStruct()
+	}
+	meta.i64(2, totalSize)
+	meta.i64(3, numRows)
+	meta.endStruct()
+
+	meta.binary(6, createdBy)
+	meta.stop()
+	return meta.buf.Bytes()
+}
+
+// physicalType returns the Parquet physical type storing a column type
+func physicalType(columnType ColumnType) int32 {
+	if columnType == String {
+		return typeByteArray
+	}
+	return typeInt64
+}
@@ -0,0 +1,138 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// compactReader decodes Thrift compact structs into maps of field ID to value, enough
+// to check the metadata written by Encode
+type compactReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *compactReader) varint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *compactReader) zigzag() int64 {
+	value := r.varint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *compactReader) value(valueType byte) any {
+	switch valueType {
+	case compactI32, compactI64:
+		return r.zigzag()
+	case compactBinary:
+		n := int(r.varint())
+		value := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return value
+	case compactList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case compactStruct:
+		return r.structure()
+	}
+	panic("unexpected compact type")
+}
+
+func (r *compactReader) structure() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+func TestEncode(t *testing.T) {
+	columns := []Column{
+		{Name: "event_id", Type: Int64},
+		{Name: "tenant", Type: String},
+		{Name: "occurred_at", Type: Timestamp},
+	}
+	occurredAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	rows := [][]any{
+		{int64(1), "tenant-a", occurredAt},
+		{int64(2), "tenant-b", occurredAt.Add(time.Second)},
+	}
+
+	file, err := Encode(columns, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(file[:4]) != magic || string(file[len(file)-4:]) != magic {
+		t.Fatal("file is not framed by the Parquet magic")
+	}
+
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := (&compactReader{data: file[len(file)-8-footerLength : len(file)-8]}).structure()
+	if footer[3] != int64(2) {
+		t.Errorf("expected 2 rows, got %v", footer[3])
+	}
+	schema := footer[2].([]any)
+	if len(schema) != 4 || schema[0].(map[int16]any)[5] != int64(3) || schema[2].(map[int16]any)[4] != "tenant" {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	rowGroup := footer[4].([]any)[0].(map[int16]any)
+	chunk := rowGroup[1].([]any)[1].(map[int16]any)
+	metadata := chunk[3].(map[int16]any)
+	offset := metadata[9].(int64)
+
+	reader := &compactReader{data: file, pos: int(offset)}
+	page := reader.structure()
+	compressed := file[reader.pos : reader.pos+int(page[3].(int64))]
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x08\x00\x00\x00tenant-a\x08\x00\x00\x00tenant-b"; string(values) != want {
+		t.Errorf("unexpected tenant values %q", values)
+	}
+	if page[2].(int64) != int64(len(values)) {
+		t.Errorf("uncompressed page size %d does not match %d value bytes", page[2], len(values))
+	}
+}
+
+func TestEncodeInvalidRow(t *testing.T) {
+	columns := []Column{{Name: "tenant", Type: String}}
+	if _, err := Encode(columns, [][]any{{int64(1)}}); !errors.Is(err, ErrInvalidRow) {
+		t.Errorf("expected a mistyped value to be rejected, got %v", err)
+	}
+	if _, err := Encode(columns, [][]any{{"a", "b"}}); !errors.Is(err, ErrInvalidRow) {
+		t.Errorf("expected a row with extra values to be rejected, got %v", err)
+	}
+}
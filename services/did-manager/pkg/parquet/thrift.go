@@ -0,0 +1,103 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol types used by the Parquet metadata
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes Parquet metadata with the Thrift compact protocol. Field IDs
+// are delta-encoded against the previous field of the enclosing struct, so the ID of
+// the last field is kept per nesting level.
+type compactWriter struct {
+	buf    bytes.Buffer
+	last   int16
+	parent []int16
+}
+
+// field writes a field header
+func (w *compactWriter) field(id int16, fieldType byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(uint64(zigzag(int64(id))))
+	}
+	w.last = id
+}
+
+func (w *compactWriter) i32(id int16, value int32) {
+	w.field(id, compactI32)
+	w.varint(zigzag(int64(value)))
+}
+
+func (w *compactWriter) i64(id int16, value int64) {
+	w.field(id, compactI64)
+	w.varint(zigzag(value))
+}
+
+func (w *compactWriter) binary(id int16, value string) {
+	w.field(id, compactBinary)
+	w.listBinary(value)
+}
+
+// beginStruct starts a struct field; its fields follow until endStruct
+func (w *compactWriter) beginStruct(id int16) {
+	w.field(id, compactStruct)
+	w.beginElement()
+}
+
+// beginElement starts a struct that is a list element, which has no field header
+func (w *compactWriter) beginElement() {
+	w.parent = append(w.parent, w.last)
+	w.last = 0
+}
+
+// endStruct ends the current struct
+func (w *compactWriter) endStruct() {
+	w.stop()
+	w.last = w.parent[len(w.parent)-1]
+	w.parent = w.parent[:len(w.parent)-1]
+}
+
+// beginList writes a list field header; the elements follow
+func (w *compactWriter) beginList(id int16, elementType byte, size int) {
+	w.field(id, compactList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elementType)
+	w.varint(uint64(size))
+}
+
+func (w *compactWriter) listI32(value int32) {
+	w.varint(zigzag(int64(value)))
+}
+
+func (w *compactWriter) listBinary(value string) {
+	w.varint(uint64(len(value)))
+	w.buf.WriteString(value)
+}
+
+// stop ends a struct's fields
+func (w *compactWriter) stop() {
+	w.buf.WriteByte(0)
+}
+
+func (w *compactWriter) varint(value uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	w.buf.Write(scratch[:binary.PutUvarint(scratch[:], value)])
+}
+
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
@@ -0,0 +1,124 @@
+// Package pdf writes minimal text-only PDF documents: monospaced lines laid out top
+// to bottom on US Letter pages, without external dependencies.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth    = 612
+	pageHeight   = 792
+	margin       = 50
+	fontSize     = 9
+	lineHeight   = 12
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+	// MaxLineLength is the number of Courier characters fitting between the margins
+	MaxLineLength = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+)
+
+// Document accumulates lines of text, wrapping long lines and breaking pages as needed
+type Document struct {
+	lines []string
+}
+
+// New creates an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// Line appends a line of text, wrapping it at MaxLineLength characters
+func (d *Document) Line(text string) {
+	text = sanitize(text)
+	for len(text) > MaxLineLength {
+		d.lines = append(d.lines, text[:MaxLineLength])
+		text = "  " + text[MaxLineLength:]
+	}
+	d.lines = append(d.lines, text)
+}
+
+// Linef appends a formatted line of text
+func (d *Document) Linef(format string, args ...interface{}) {
+	d.Line(fmt.Sprintf(format, args...))
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	var pages [][]string
+	for start := 0; start < len(d.lines); start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = [][]string{nil}
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and its content stream per page
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	)
+	for i, lines := range pages {
+		content := pageContent(lines)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// pageContent renders the content stream drawing lines from the top margin down
+func pageContent(lines []string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", escape(line))
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+// escape escapes the characters with special meaning in PDF string literals
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(text)
+}
+
+// sanitize replaces characters outside printable ASCII, which the standard fonts
+// cannot render without an encoding
+func sanitize(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, text)
+}
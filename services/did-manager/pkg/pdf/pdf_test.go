@@ -0,0 +1,70 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEmptyDocumentHasOnePage(t *testing.T) {
+	out := New().Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header")
+	}
+	if !bytes.Contains(out, []byte("/Count 1")) {
+		t.Errorf("expected a single page")
+	}
+	if !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Errorf("missing EOF marker")
+	}
+}
+
+func TestLinesBreakAcrossPages(t *testing.T) {
+	doc := New()
+	for i := 0; i < linesPerPage+1; i++ {
+		doc.Linef("line %d", i)
+	}
+	if out := doc.Bytes(); !bytes.Contains(out, []byte("/Count 2")) {
+		t.Errorf("expected two pages")
+	}
+}
+
+func TestLongLinesWrap(t *testing.T) {
+	doc := New()
+	doc.Line(strings.Repeat("a", MaxLineLength+10))
+	if len(doc.lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(doc.lines))
+	}
+	if doc.lines[1] != "  "+strings.Repeat("a", 10) {
+		t.Errorf("unexpected continuation line %q", doc.lines[1])
+	}
+}
+
+func TestTextIsEscapedAndSanitized(t *testing.T) {
+	doc := New()
+	doc.Line(`a (b) \ é`)
+	if out := doc.Bytes(); !bytes.Contains(out, []byte(`(a \(b\) \\ ?) Tj`)) {
+		t.Errorf("text not escaped: %s", out)
+	}
+}
+
+func TestXrefOffsetsPointAtObjects(t *testing.T) {
+	doc := New()
+	doc.Line("hello")
+	out := doc.Bytes()
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 xref entries, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com/3/device/"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com/3/device/"
+	// APNs rejects provider tokens older than an hour and throttles refreshes more
+	// frequent than every 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig configures token-based authentication with Apple Push Notification service
+type APNsConfig struct {
+	KeyID    string
+	TeamID   string
+	BundleID string
+	// PrivateKeyPEM is the contents of the .p8 signing key
+	PrivateKeyPEM []byte
+	Production    bool
+}
+
+// APNsProvider sends notifications with the APNs HTTP/2 provider API
+type APNsProvider struct {
+	cfg        APNsConfig
+	key        *ecdsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsProvider creates an APNs provider
+func NewAPNsProvider(cfg APNsConfig) (*APNsProvider, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.BundleID == "" {
+		return nil, fmt.Errorf("invalid APNs configuration: key ID, team ID and bundle ID are required")
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(cfg.PrivateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs signing key: %w", err)
+	}
+
+	baseURL := apnsSandboxURL
+	if cfg.Production {
+		baseURL = apnsProductionURL
+	}
+
+	return &APNsProvider{
+		cfg:        cfg,
+		key:        key,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Platform returns the APNs platform identifier
+func (p *APNsProvider) Platform() string {
+	return PlatformAPNs
+}
+
+// Send delivers a notification to an APNs device token
+func (p *APNsProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	providerToken, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+		},
+	}
+	for k, v := range notification.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", p.cfg.BundleID)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send APNs notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(respBody, &result)
+
+	if resp.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return ErrUnregistered
+	}
+	return fmt.Errorf("APNs returned status %d: %s", resp.StatusCode, result.Reason)
+}
+
+// providerToken returns a cached ES256 provider token, re-signing it before APNs expires it
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.jwt != "" && time.Since(p.issuedAt) < apnsTokenLifetime {
+		return p.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.cfg.KeyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs provider token: %w", err)
+	}
+
+	p.jwt = signed
+	p.issuedAt = now
+	return p.jwt, nil
+}
@@ -0,0 +1,173 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope        = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL      = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	googleTokenURI  = "https://oauth2.googleapis.com/token"
+	tokenRefreshGap = time.Minute
+)
+
+// serviceAccount holds the fields of a Google service account key file used by FCM
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider sends notifications with the Firebase Cloud Messaging HTTP v1 API,
+// authenticating with a service account
+type FCMProvider struct {
+	account    serviceAccount
+	sendURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider creates an FCM provider from a service account key file's contents
+func NewFCMProvider(credentialsJSON []byte) (*FCMProvider, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("invalid FCM credentials: project_id, client_email and private_key are required")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = googleTokenURI
+	}
+
+	return &FCMProvider{
+		account:    account,
+		sendURL:    fmt.Sprintf(fcmSendURL, account.ProjectID),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Platform returns the FCM platform identifier
+func (p *FCMProvider) Platform() string {
+	return PlatformFCM
+}
+
+// Send delivers a notification to an FCM registration token
+func (p *FCMProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token": token,
+			"notification": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"data": notification.Data,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode FCM message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.sendURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send FCM message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(respBody), "UNREGISTERED") {
+		return ErrUnregistered
+	}
+	return fmt.Errorf("FCM returned status %d: %s", resp.StatusCode, respBody)
+}
+
+// token returns a cached OAuth access token, exchanging a signed service account
+// assertion for a new one when it is about to expire
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Add(tokenRefreshGap).Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid FCM private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign FCM assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch FCM access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode FCM access token: %w", err)
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
@@ -0,0 +1,32 @@
+package push
+
+import (
+	"context"
+	"errors"
+)
+
+// Push platforms
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// ErrUnregistered is returned when the provider reports that a device token is no
+// longer valid; callers should forget the device
+var ErrUnregistered = errors.New("device token is no longer registered")
+
+// Notification is a platform-neutral push notification
+type Notification struct {
+	Title string
+	Body  string
+	// Data is delivered to the app alongside the alert, e.g. the event type and IDs
+	Data map[string]string
+}
+
+// Provider delivers notifications through one push platform
+type Provider interface {
+	// Platform is the platform identifier devices register with
+	Platform() string
+	// Send delivers a notification to a single device token
+	Send(ctx context.Context, token string, notification *Notification) error
+}
@@ -0,0 +1,159 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testNotification() *Notification {
+	return &Notification{
+		Title: "New credential",
+		Body:  "A new credential has been issued to your wallet.",
+		Data:  map[string]string{"event_type": "credential.offered"},
+	}
+}
+
+func newTestAPNsProvider(t *testing.T, baseURL string) *APNsProvider {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+
+	provider, err := NewAPNsProvider(APNsConfig{
+		KeyID:         "KEY123",
+		TeamID:        "TEAM123",
+		BundleID:      "com.example.wallet",
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatalf("NewAPNsProvider failed: %v", err)
+	}
+	provider.baseURL = baseURL
+	return provider
+}
+
+func TestAPNsProviderSend(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/device-token" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("apns-topic") != "com.example.wallet" {
+			t.Errorf("unexpected topic %q", r.Header.Get("apns-topic"))
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "bearer ") {
+			t.Errorf("missing provider token")
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	provider := newTestAPNsProvider(t, server.URL+"/")
+	if err := provider.Send(context.Background(), "device-token", testNotification()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	aps, ok := payload["aps"].(map[string]any)
+	if !ok {
+		t.Fatalf("payload is missing aps: %v", payload)
+	}
+	if alert := aps["alert"].(map[string]any); alert["title"] != "New credential" {
+		t.Errorf("unexpected alert %v", alert)
+	}
+	if payload["event_type"] != "credential.offered" {
+		t.Errorf("custom data not delivered: %v", payload)
+	}
+}
+
+func TestAPNsProviderUnregistered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(`{"reason":"Unregistered"}`))
+	}))
+	defer server.Close()
+
+	provider := newTestAPNsProvider(t, server.URL+"/")
+	err := provider.Send(context.Background(), "device-token", testNotification())
+	if !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("expected ErrUnregistered, got %v", err)
+	}
+}
+
+func TestFCMProviderSend(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var tokenRequests int
+	var message map[string]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			w.Write([]byte(`{"access_token":"access-123","expires_in":3600}`))
+		case "/send":
+			if r.Header.Get("Authorization") != "Bearer access-123" {
+				t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			}
+			json.NewDecoder(r.Body).Decode(&message)
+			if message["message"]["token"] == "stale-token" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"project_id":   "wallet-project",
+		"client_email": "push@wallet-project.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    server.URL + "/token",
+	})
+	provider, err := NewFCMProvider(credentials)
+	if err != nil {
+		t.Fatalf("NewFCMProvider failed: %v", err)
+	}
+	provider.sendURL = server.URL + "/send"
+
+	if err := provider.Send(context.Background(), "device-token", testNotification()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if message["message"]["token"] != "device-token" {
+		t.Errorf("unexpected message %v", message)
+	}
+
+	err = provider.Send(context.Background(), "stale-token", testNotification())
+	if !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("expected ErrUnregistered, got %v", err)
+	}
+
+	if tokenRequests != 1 {
+		t.Errorf("expected the access token to be cached, fetched %d times", tokenRequests)
+	}
+}
+
+func TestNewFCMProviderRejectsIncompleteCredentials(t *testing.T) {
+	if _, err := NewFCMProvider([]byte(`{"project_id":"wallet-project"}`)); err == nil {
+		t.Fatal("expected incomplete credentials to be rejected")
+	}
+}
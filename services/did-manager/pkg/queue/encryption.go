@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Encrypted job messages name the cipher and the key their payload is encrypted with
+const (
+	HeaderEncryption      = "Encryption"
+	HeaderEncryptionKeyID = "Encryption-Key-Id"
+)
+
+// EncryptionAES256GCM encrypts payloads with AES-256-GCM; the payload is the nonce
+// followed by the ciphertext
+const EncryptionAES256GCM = "aes-256-gcm"
+
+// encryptionKeySize is the size of AES-256 keys
+const encryptionKeySize = 32
+
+// ErrUnknownEncryptionKey is returned for payloads encrypted with a key the consumer
+// does not have, e.g. one rotated in before the consumer was given it
+var ErrUnknownEncryptionKey = errors.New("unknown payload encryption key")
+
+// KeyProvider supplies the symmetric keys job payloads are encrypted with, such as data
+// keys issued by a KMS
+type KeyProvider interface {
+	// CurrentKey returns the key new payloads are encrypted with
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns a key by ID, for payloads encrypted before a rotation
+	Key(id string) ([]byte, error)
+}
+
+// Keyring is a KeyProvider over a fixed set of keys, e.g. loaded from a KMS into the
+// environment at deploy time. Rotating adds a key and makes it current; the previous
+// key stays in the ring until no message encrypted with it is left in the stream.
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// ParseKeyring parses keys given as comma-separated "<id>:<base64 key>" pairs, with
+// current naming the key to encrypt with
+func ParseKeyring(current, keys string) (*Keyring, error) {
+	ring := &Keyring{current: current, keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid encryption key entry, expected <id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != encryptionKeySize {
+			return nil, fmt.Errorf("encryption key %s must be %d base64-encoded bytes", id, encryptionKeySize)
+		}
+		ring.keys[id] = key
+	}
+	if _, ok := ring.keys[current]; !ok {
+		return nil, fmt.Errorf("current encryption key %q is not in the keyring", current)
+	}
+	return ring, nil
+}
+
+// CurrentKey returns the key new payloads are encrypted with
+func (k *Keyring) CurrentKey() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+// Key returns a key by ID
+func (k *Keyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, id)
+	}
+	return key, nil
+}
+
+// EncryptPayload encrypts a message payload with the current key and records the
+// cipher and key ID in its headers. The message ID is authenticated with the payload,
+// so a payload cannot be replayed under another job's ID.
+func EncryptPayload(header nats.Header, data []byte, keys KeyProvider) ([]byte, error) {
+	id, key, err := keys.CurrentKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header.Set(HeaderEncryption, EncryptionAES256GCM)
+	header.Set(HeaderEncryptionKeyID, id)
+	return aead.Seal(nonce, nonce, data, []byte(header.Get(nats.MsgIdHdr))), nil
+}
+
+// DecryptPayload decrypts the payload of an encrypted message; payloads of messages
+// without an encryption header are returned as they are
+func DecryptPayload(header nats.Header, data []byte, keys KeyProvider) ([]byte, error) {
+	cipherName := header.Get(HeaderEncryption)
+	if cipherName == "" {
+		return data, nil
+	}
+	if cipherName != EncryptionAES256GCM {
+		return nil, fmt.Errorf("%w: unknown encryption %q", ErrMalformedJob, cipherName)
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("%w: no keys configured", ErrUnknownEncryptionKey)
+	}
+
+	key, err := keys.Key(header.Get(HeaderEncryptionKeyID))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: encrypted payload too short", ErrMalformedJob)
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(header.Get(nats.MsgIdHdr)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload authentication failed", ErrMalformedJob)
+	}
+	return plaintext, nil
+}
+
+// newAEAD creates the AES-256-GCM cipher for a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", encryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
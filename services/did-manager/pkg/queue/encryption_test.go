@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func testKeyring(t *testing.T, current string) *Keyring {
+	t.Helper()
+	old := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	rotated := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	ring, err := ParseKeyring(current, "old:"+old+", rotated:"+rotated)
+	if err != nil {
+		t.Fatalf("ParseKeyring failed: %v", err)
+	}
+	return ring
+}
+
+func TestEncryptDecryptPayload(t *testing.T) {
+	header, data, err := EncodeJob(testJob(), EncodingJSON)
+	if err != nil {
+		t.Fatalf("EncodeJob failed: %v", err)
+	}
+	header.Set(nats.MsgIdHdr, testJob().ID)
+
+	encrypted, err := EncryptPayload(header, data, testKeyring(t, "old"))
+	if err != nil {
+		t.Fatalf("EncryptPayload failed: %v", err)
+	}
+	if header.Get(HeaderEncryption) != EncryptionAES256GCM || header.Get(HeaderEncryptionKeyID) != "old" {
+		t.Errorf("unexpected encryption headers %v", header)
+	}
+	if bytes.Contains(encrypted, []byte(testJob().UserHash)) {
+		t.Error("expected the payload to be encrypted")
+	}
+
+	// After a rotation, payloads encrypted with the previous key still decrypt
+	decrypted, err := DecryptPayload(header, encrypted, testKeyring(t, "rotated"))
+	if err != nil {
+		t.Fatalf("DecryptPayload failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, data) {
+		t.Errorf("expected %s, got %s", data, decrypted)
+	}
+
+	// Unencrypted messages pass through
+	if plain, err := DecryptPayload(nats.Header{}, data, nil); err != nil || !bytes.Equal(plain, data) {
+		t.Errorf("expected plaintext to pass through, got %s, %v", plain, err)
+	}
+
+	replayed := nats.Header{}
+	for key, values := range header {
+		replayed[key] = values
+	}
+	replayed.Set(nats.MsgIdHdr, "another-job")
+	if _, err := DecryptPayload(replayed, encrypted, testKeyring(t, "old")); !errors.Is(err, ErrMalformedJob) {
+		t.Errorf("expected ErrMalformedJob for a payload under another message ID, got %v", err)
+	}
+
+	replayed.Set(HeaderEncryptionKeyID, "unknown")
+	if _, err := DecryptPayload(replayed, encrypted, testKeyring(t, "old")); !errors.Is(err, ErrUnknownEncryptionKey) {
+		t.Errorf("expected ErrUnknownEncryptionKey, got %v", err)
+	}
+	if _, err := DecryptPayload(header, encrypted, nil); !errors.Is(err, ErrUnknownEncryptionKey) {
+		t.Errorf("expected ErrUnknownEncryptionKey without keys, got %v", err)
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	for _, tc := range []struct {
+		current, keys string
+	}{
+		{"k1", "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"k1", "k1"},
+		{"k2", "k1:" + key},
+	} {
+		if _, err := ParseKeyring(tc.current, tc.keys); err == nil {
+			t.Errorf("expected ParseKeyring(%q, %q) to fail", tc.current, tc.keys)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Job messages carry their envelope in headers: the schema version of the payload and
+// its encoding. Messages without a schema version predate the envelope and are JSON.
+const (
+	HeaderSchemaVersion    = "Schema-Version"
+	HeaderContentType      = "Content-Type"
+	HeaderDeadLetterReason = "Dead-Letter-Reason"
+)
+
+// JobSchemaVersion is the BlockchainJob schema version published. Fields are only
+// ever added under new field numbers and JSON names, so a consumer decodes every
+// version up to its own, ignoring unknown fields. A breaking change bumps the version,
+// and consumers leave newer messages for upgraded consumers.
+const JobSchemaVersion = 1
+
+// Encoding is the content type of a job message payload
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "application/json"
+	EncodingProtobuf Encoding = "application/x-protobuf"
+)
+
+// JobTypes are the job types consumers know how to process
+var JobTypes = map[string]bool{
+	"register_did": true,
+	"update_did":   true,
+	"revoke_did":   true,
+	"notarize":     true,
+}
+
+var (
+	// ErrUnsupportedSchema is returned for messages of a newer schema version
+	ErrUnsupportedSchema = errors.New("unsupported job schema version")
+	// ErrMalformedJob is returned for messages that cannot be decoded
+	ErrMalformedJob = errors.New("malformed job message")
+	// ErrUnknownJobType is returned for jobs of a type no consumer processes
+	ErrUnknownJobType = errors.New("unknown job type")
+)
+
+// ParseEncoding parses a configured encoding name: json or protobuf
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "", "json":
+		return EncodingJSON, nil
+	case "protobuf":
+		return EncodingProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown queue encoding: %s", name)
+	}
+}
+
+// Protobuf field numbers of BlockchainJob, see blockchain_job.proto
+const (
+	jobFieldID        protowire.Number = 1
+	jobFieldJobType   protowire.Number = 2
+	jobFieldDIDID     protowire.Number = 3
+	jobFieldUserHash  protowire.Number = 4
+	jobFieldDID       protowire.Number = 5
+	jobFieldCreatedAt protowire.Number = 6
+	jobFieldDryRun    protowire.Number = 7
+)
+
+// EncodeJob encodes a job with its envelope headers
+func EncodeJob(job *BlockchainJob, encoding Encoding) (nats.Header, []byte, error) {
+	var data []byte
+	switch encoding {
+	case EncodingJSON:
+		encoded, err := json.Marshal(job)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal job: %w", err)
+		}
+		data = encoded
+	case EncodingProtobuf:
+		data = marshalJobProto(job)
+	default:
+		return nil, nil, fmt.Errorf("unknown queue encoding: %s", encoding)
+	}
+
+	header := nats.Header{}
+	header.Set(HeaderSchemaVersion, strconv.Itoa(JobSchemaVersion))
+	header.Set(HeaderContentType, string(encoding))
+	return header, data, nil
+}
+
+// DecodeJob decodes a job message of any supported schema version and encoding
+func DecodeJob(header nats.Header, data []byte) (*BlockchainJob, error) {
+	version := 0
+	if value := header.Get(HeaderSchemaVersion); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("%w: schema version %q", ErrMalformedJob, value)
+		}
+		version = parsed
+	}
+	if version > JobSchemaVersion {
+		return nil, fmt.Errorf("%w: %d, consumer supports up to %d", ErrUnsupportedSchema, version, JobSchemaVersion)
+	}
+
+	var job BlockchainJob
+	encoding := Encoding(header.Get(HeaderContentType))
+	if version == 0 {
+		// Legacy messages are unversioned JSON
+		encoding = EncodingJSON
+	}
+	switch encoding {
+	case EncodingJSON:
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedJob, err)
+		}
+	case EncodingProtobuf:
+		if err := unmarshalJobProto(data, &job); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedJob, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown content type %q", ErrMalformedJob, encoding)
+	}
+
+	if !JobTypes[job.JobType] {
+		return &job, fmt.Errorf("%w: %q", ErrUnknownJobType, job.JobType)
+	}
+	return &job, nil
+}
+
+// marshalJobProto encodes a job in the protobuf wire format
+func marshalJobProto(job *BlockchainJob) []byte {
+	var b []byte
+	for _, field := range []struct {
+		number protowire.Number
+		value  string
+	}{
+		{jobFieldID, job.ID},
+		{jobFieldJobType, job.JobType},
+		{jobFieldDIDID, job.DIDID},
+		{jobFieldUserHash, job.UserHash},
+		{jobFieldDID, job.DID},
+	} {
+		if field.value != "" {
+			b = protowire.AppendTag(b, field.number, protowire.BytesType)
+			b = protowire.AppendString(b, field.value)
+		}
+	}
+	if !job.CreatedAt.IsZero() {
+		b = protowire.AppendTag(b, jobFieldCreatedAt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(job.CreatedAt.UnixNano()))
+	}
+	if job.DryRun {
+		b = protowire.AppendTag(b, jobFieldDryRun, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	return b
+}
+
+// unmarshalJobProto decodes a job from the protobuf wire format, skipping unknown fields
+func unmarshalJobProto(b []byte, job *BlockchainJob) error {
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var target *string
+		switch number {
+		case jobFieldID:
+			target = &job.ID
+		case jobFieldJobType:
+			target = &job.JobType
+		case jobFieldDIDID:
+			target = &job.DIDID
+		case jobFieldUserHash:
+			target = &job.UserHash
+		case jobFieldDID:
+			target = &job.DID
+		}
+
+		switch {
+		case target != nil && wireType == protowire.BytesType:
+			value, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			*target = value
+			b = b[n:]
+		case number == jobFieldCreatedAt && wireType == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			job.CreatedAt = time.Unix(0, int64(value)).UTC()
+			b = b[n:]
+		case number == jobFieldDryRun && wireType == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			job.DryRun = protowire.DecodeBool(value)
+			b = b[n:]
+		default:
+			// Fields added by newer producers
+			n := protowire.ConsumeFieldValue(number, wireType, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func testJob() *BlockchainJob {
+	return &BlockchainJob{
+		ID:        "2f1d6a0e-4c1b-4b43-9a4e-0f4f9c3f8b11",
+		JobType:   "register_did",
+		DIDID:     "8c6e3c55-1e0f-4b8a-a4d5-5e2a4d7c9f01",
+		UserHash:  "abc123",
+		DID:       "did:example:user:abc:def",
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC),
+		DryRun:    true,
+	}
+}
+
+func TestEncodeDecodeJob(t *testing.T) {
+	for _, encoding := range []Encoding{EncodingJSON, EncodingProtobuf} {
+		header, data, err := EncodeJob(testJob(), encoding)
+		if err != nil {
+			t.Fatalf("%s: EncodeJob failed: %v", encoding, err)
+		}
+		if header.Get(HeaderSchemaVersion) != "1" || header.Get(HeaderContentType) != string(encoding) {
+			t.Errorf("%s: unexpected envelope headers %v", encoding, header)
+		}
+
+		job, err := DecodeJob(header, data)
+		if err != nil {
+			t.Fatalf("%s: DecodeJob failed: %v", encoding, err)
+		}
+		if !reflect.DeepEqual(job, testJob()) {
+			t.Errorf("%s: expected %+v, got %+v", encoding, testJob(), job)
+		}
+	}
+}
+
+func TestDecodeJobCompatibility(t *testing.T) {
+	// Messages published before the envelope carry plain JSON without headers
+	legacy := []byte(`{"id":"1","job_type":"revoke_did","did_id":"2","user_hash":"h","did":"did:example:x","created_at":"2024-05-01T12:00:00Z"}`)
+	job, err := DecodeJob(nil, legacy)
+	if err != nil {
+		t.Fatalf("DecodeJob of a legacy message failed: %v", err)
+	}
+	if job.JobType != "revoke_did" || job.DID != "did:example:x" {
+		t.Errorf("unexpected legacy job %+v", job)
+	}
+
+	// Fields added by newer producers of the same schema version are skipped
+	header, data, _ := EncodeJob(testJob(), EncodingProtobuf)
+	data = protowire.AppendTag(data, 42, protowire.BytesType)
+	data = protowire.AppendString(data, "added later")
+	if _, err := DecodeJob(header, data); err != nil {
+		t.Errorf("expected unknown protobuf fields to be skipped, got %v", err)
+	}
+
+	newer := nats.Header{}
+	newer.Set(HeaderSchemaVersion, "2")
+	newer.Set(HeaderContentType, string(EncodingJSON))
+	if _, err := DecodeJob(newer, legacy); !errors.Is(err, ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema for a newer version, got %v", err)
+	}
+
+	unknownType := []byte(`{"id":"1","job_type":"mint_token"}`)
+	if _, err := DecodeJob(nil, unknownType); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("expected ErrUnknownJobType, got %v", err)
+	}
+
+	xml := nats.Header{}
+	xml.Set(HeaderSchemaVersion, "1")
+	xml.Set(HeaderContentType, "application/xml")
+	if _, err := DecodeJob(xml, []byte("<job/>")); !errors.Is(err, ErrMalformedJob) {
+		t.Errorf("expected ErrMalformedJob for an unknown content type, got %v", err)
+	}
+}
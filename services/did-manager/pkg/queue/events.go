@@ -0,0 +1,254 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Domain event stream; every event is published to events.<type>
+const (
+	eventsStream        = "DOMAIN_EVENTS"
+	eventsSubjectPrefix = "events."
+)
+
+// Wallet-relevant domain event types
+const (
+	EventCredentialOffered     = "credential.offered"
+	EventPresentationRequested = "presentation.requested"
+	EventCredentialRevoked     = "credential.revoked"
+	EventCredentialExpiring    = "credential.expiring"
+)
+
+// DID change event types, published whenever a DID record changes
+const (
+	EventDIDPrefix            = "did."
+	EventDIDCreated           = "did.created"
+	EventDIDStatusChanged     = "did.status_changed"
+	EventDIDVisibilityChanged = "did.visibility_changed"
+	EventDIDKeyRotated        = "did.key_rotated"
+	EventDIDUpdated           = "did.updated"
+	// EventDIDChainUpdated and EventDIDChainRevoked are published when the registry
+	// contract emits DIDUpdated or DIDRevoked for a DID
+	EventDIDChainUpdated = "did.chain_updated"
+	EventDIDChainRevoked = "did.chain_revoked"
+	// EventDIDPasswordChanged is published on the DIDs of a user whose account password
+	// was changed or reset in the auth service
+	EventDIDPasswordChanged = "did.password_changed"
+	// EventDIDDeviceKeyAdded and EventDIDDeviceKeyRevoked are published when a device
+	// subkey is listed on or removed from a DID's authentication methods
+	EventDIDDeviceKeyAdded   = "did.device_key_added"
+	EventDIDDeviceKeyRevoked = "did.device_key_revoked"
+)
+
+// Trust registry event types, published when administrators add or remove an issuer.
+// The registry is public, so they reach every subscribed webhook.
+const (
+	EventTrustRegistryPrefix  = "trust_registry."
+	EventTrustedIssuerAdded   = "trust_registry.issuer_added"
+	EventTrustedIssuerRemoved = "trust_registry.issuer_removed"
+)
+
+// EventRevocationNotice tells a relying party that a DID or credential it verified was
+// revoked. It is not published to the event stream but delivered to the webhooks of
+// the relying parties that verified the subject.
+const EventRevocationNotice = "revocation.notice"
+
+// ErrCursorExpired is returned when events after a cursor are no longer retained
+var ErrCursorExpired = errors.New("events after the cursor are no longer retained")
+
+// fetchTimeout bounds reading a page of retained events
+const fetchTimeout = 5 * time.Second
+
+// Event is a domain event published for downstream consumers
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Subject is the DID the event concerns, e.g. a credential's holder
+	Subject    string            `json:"subject"`
+	Data       map[string]string `json:"data,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// StoredEvent is an event with its sequence in the event stream
+type StoredEvent struct {
+	Sequence uint64 `json:"sequence"`
+	Event
+}
+
+// ensureEventStream creates the domain event stream if it does not exist
+func (n *NATSQueue) ensureEventStream() {
+	_, err := n.js.AddStream(&nats.StreamConfig{
+		Name:      eventsStream,
+		Subjects:  []string{eventsSubjectPrefix + ">"},
+		Storage:   nats.FileStorage,
+		Retention: nats.LimitsPolicy,
+		MaxAge:    7 * 24 * time.Hour, // Keep events for a week so consumers can catch up
+	})
+
+	if err != nil && err.Error() != "stream name already in use" {
+		log.Printf("Warning: failed to create event stream: %v", err)
+	}
+}
+
+// PublishEvent publishes a domain event to the event stream
+func (n *NATSQueue) PublishEvent(event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	ack, err := n.js.Publish(eventsSubjectPrefix+event.Type, data)
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	log.Printf("Published event %s of type %s, stream sequence: %d", event.ID, event.Type, ack.Sequence)
+
+	return nil
+}
+
+// SubscribeToEvents consumes every domain event with a durable consumer. Events the
+// handler fails on are redelivered. Draining the returned subscription stops delivery
+// once in-flight events are handled.
+func (n *NATSQueue) SubscribeToEvents(durable string, handler func(*Event) error) (*nats.Subscription, error) {
+	sub, err := n.js.Subscribe(eventsSubjectPrefix+">", func(msg *nats.Msg) {
+		var event Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			// A malformed event will never succeed, so drop it instead of redelivering
+			log.Printf("Failed to unmarshal event: %v", err)
+			msg.Term()
+			return
+		}
+
+		if err := handler(&event); err != nil {
+			log.Printf("Failed to handle event %s for %s: %v", event.ID, durable, err)
+			msg.Nak()
+			return
+		}
+
+		msg.Ack()
+	}, nats.Durable(durable), nats.AckExplicit(), nats.ManualAck())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	log.Printf("Subscribed to domain events with durable consumer %s", durable)
+
+	return sub, nil
+}
+
+// ReadEvents reads up to limit retained events whose type starts with typePrefix,
+// published after the stream sequence after (0 reads from the oldest retained event).
+// It returns the cursor to continue from, which moves past the end of the stream once no
+// matching event is left, and whether more matching events follow.
+func (n *NATSQueue) ReadEvents(typePrefix string, after uint64, limit int) ([]*StoredEvent, uint64, bool, error) {
+	info, err := n.js.StreamInfo(eventsStream)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to get event stream: %w", err)
+	}
+	// A cursor past the end belongs to a stream that has since been recreated
+	if after > info.State.LastSeq || (after > 0 && after+1 < info.State.FirstSeq) {
+		return nil, 0, false, ErrCursorExpired
+	}
+	if after == info.State.LastSeq {
+		return nil, after, false, nil
+	}
+
+	start := after + 1
+	if start < info.State.FirstSeq {
+		start = info.State.FirstSeq
+	}
+	sub, err := n.js.PullSubscribe(
+		eventsSubjectPrefix+typePrefix+">",
+		"",
+		nats.BindStream(eventsStream),
+		nats.StartSequence(start),
+		nats.InactiveThreshold(time.Minute),
+	)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to read events: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	consumer, err := sub.ConsumerInfo()
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to read events: %w", err)
+	}
+	if consumer.NumPending == 0 {
+		return nil, info.State.LastSeq, false, nil
+	}
+
+	batch := limit
+	if consumer.NumPending < uint64(batch) {
+		batch = int(consumer.NumPending)
+	}
+	msgs, err := sub.Fetch(batch, nats.MaxWait(fetchTimeout))
+	if err != nil && len(msgs) == 0 {
+		return nil, 0, false, fmt.Errorf("failed to fetch events: %w", err)
+	}
+
+	events := make([]*StoredEvent, 0, len(msgs))
+	cursor := after
+	for _, msg := range msgs {
+		meta, err := msg.Metadata()
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("failed to read event metadata: %w", err)
+		}
+		cursor = meta.Sequence.Stream
+
+		stored := &StoredEvent{Sequence: meta.Sequence.Stream}
+		if err := json.Unmarshal(msg.Data, &stored.Event); err != nil {
+			log.Printf("Skipping malformed event at sequence %d: %v", meta.Sequence.Stream, err)
+			continue
+		}
+		events = append(events, stored)
+	}
+
+	return events, cursor, consumer.NumPending > uint64(len(msgs)), nil
+}
+
+// SequenceAt returns the cursor just before the first retained event published at or
+// after t, for ReadEvents to read from; the end of the stream when there is none
+func (n *NATSQueue) SequenceAt(t time.Time) (uint64, error) {
+	info, err := n.js.StreamInfo(eventsStream)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event stream: %w", err)
+	}
+
+	sub, err := n.js.PullSubscribe(
+		eventsSubjectPrefix+">",
+		"",
+		nats.BindStream(eventsStream),
+		nats.StartTime(t),
+		nats.InactiveThreshold(time.Minute),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read events: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	consumer, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read events: %w", err)
+	}
+	if consumer.NumPending == 0 {
+		return info.State.LastSeq, nil
+	}
+
+	msgs, err := sub.Fetch(1, nats.MaxWait(fetchTimeout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch events: %w", err)
+	}
+	meta, err := msgs[0].Metadata()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read event metadata: %w", err)
+	}
+
+	return meta.Sequence.Stream - 1, nil
+}
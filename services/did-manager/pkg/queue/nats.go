@@ -1,18 +1,49 @@
 package queue
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go"
 )
 
+// Blockchain job stream; every job is published to blockchain.jobs.<type>
+const jobsStream = "BLOCKCHAIN_JOBS"
+
+// Dead-letter stream keeping job messages no consumer can process, published to
+// blockchain.deadletter.<type> with the reason in a header
+const (
+	deadLetterStream        = "BLOCKCHAIN_JOBS_DEADLETTER"
+	deadLetterSubjectPrefix = "blockchain.deadletter."
+)
+
+// jobDuplicateWindow is how long the job stream remembers published message IDs. Jobs
+// are published with their ID as Nats-Msg-Id, so a publish retried within the window,
+// e.g. after a lost acknowledgement, is stored once and submitted on-chain once.
+const jobDuplicateWindow = time.Hour
+
+// jobMaxDeliver is how often a job message is delivered before it is dead-lettered
+const jobMaxDeliver = 3
+
+// unsupportedSchemaDelay is how long a message of a newer schema version waits for an
+// upgraded consumer before it is redelivered
+const unsupportedSchemaDelay = time.Minute
+
+// mirrorSuffix names the streams a standby mirrors from its primary
+const mirrorSuffix = "_MIRROR"
+
 // NATSQueue handles message queuing using NATS
 type NATSQueue struct {
 	conn *nats.Conn
 	js   nats.JetStreamContext
+	// encoding is the payload encoding of published jobs
+	encoding Encoding
+	// keys encrypt published job payloads and decrypt consumed ones; nil publishes
+	// plaintext
+	keys KeyProvider
 }
 
 // NewNATSQueue creates a new NATS queue instance
@@ -30,39 +61,153 @@ func NewNATSQueue(natsURL string) (*NATSQueue, error) {
 		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
 	}
 
+	q := &NATSQueue{
+		conn:     conn,
+		js:       js,
+		encoding: EncodingJSON,
+	}
+	q.ensureJobStream()
+	q.ensureEventStream()
+
+	return q, nil
+}
+
+// NewNATSStandby creates a queue for a standby deployment. Instead of its own streams,
+// it mirrors the job and event streams of the primary in the given JetStream domain
+// into <stream>_MIRROR streams, keeping the primary's history in the standby region.
+// SetEncryption encrypts published job payloads with the current key of a provider.
+// Consumers decrypt with any key the provider holds and still accept plaintext
+// messages, so encryption can be enabled while unencrypted jobs are in the stream.
+func (n *NATSQueue) SetEncryption(keys KeyProvider) {
+	n.keys = keys
+}
+
+// Promote creates the regular streams once the standby takes over.
+func NewNATSStandby(natsURL, primaryDomain string) (*NATSQueue, error) {
+	conn, err := nats.Connect(natsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	for _, stream := range []string{jobsStream, eventsStream} {
+		_, err := js.AddStream(&nats.StreamConfig{
+			Name:    stream + mirrorSuffix,
+			Storage: nats.FileStorage,
+			Mirror: &nats.StreamSource{
+				Name:   stream,
+				Domain: primaryDomain,
+			},
+		})
+		if err != nil && err.Error() != "stream name already in use" {
+			conn.Close()
+			return nil, fmt.Errorf("failed to mirror stream %s: %w", stream, err)
+		}
+	}
+
+	log.Printf("Mirroring job and event streams from JetStream domain %s", primaryDomain)
+
+	return &NATSQueue{
+		conn:     conn,
+		js:       js,
+		encoding: EncodingJSON,
+	}, nil
+}
+
+// SetEncoding selects the payload encoding of published jobs. Consumers decode both
+// encodings, so switching is safe once every consumer runs a version with the envelope.
+func (n *NATSQueue) SetEncoding(encoding Encoding) {
+	n.encoding = encoding
+}
+
+// Promote creates the streams a primary publishes to and consumes from; the mirrors
+// of a standby are left in place
+func (n *NATSQueue) Promote() error {
+	n.ensureJobStream()
+	n.ensureEventStream()
+
+	if _, err := n.js.StreamInfo(jobsStream); err != nil {
+		return fmt.Errorf("failed to create job stream: %w", err)
+	}
+	if _, err := n.js.StreamInfo(eventsStream); err != nil {
+		return fmt.Errorf("failed to create event stream: %w", err)
+	}
+	return nil
+}
+
+// ensureJobStream creates the blockchain job stream and its worker consumer if they
+// do not exist
+func (n *NATSQueue) ensureJobStream() {
 	// Create stream for blockchain jobs
-	stream, err := js.AddStream(&nats.StreamConfig{
-		Name:      "BLOCKCHAIN_JOBS",
-		Subjects:  []string{"blockchain.jobs.*"},
-		Storage:   nats.FileStorage,
-		Retention: nats.LimitsPolicy,
-		MaxAge:    24 * time.Hour, // Keep messages for 24 hours
-		MaxMsgs:   10000,          // Max 10k messages
-	})
+	config := &nats.StreamConfig{
+		Name:       jobsStream,
+		Subjects:   []string{"blockchain.jobs.*"},
+		Storage:    nats.FileStorage,
+		Retention:  nats.LimitsPolicy,
+		MaxAge:     24 * time.Hour, // Keep messages for 24 hours
+		MaxMsgs:    10000,          // Max 10k messages
+		Duplicates: jobDuplicateWindow,
+	}
+	stream, err := n.js.AddStream(config)
 
 	if err != nil && err.Error() != "stream name already in use" {
 		log.Printf("Warning: failed to create stream: %v", err)
 	} else if err == nil {
 		log.Printf("Created stream: %s", stream.Config.Name)
+	} else {
+		n.ensureDuplicateWindow(config)
 	}
 
 	// Create consumer for processing jobs
-	_, err = js.AddConsumer("BLOCKCHAIN_JOBS", &nats.ConsumerConfig{
+	_, err = n.js.AddConsumer(jobsStream, &nats.ConsumerConfig{
 		Durable:       "did-manager-worker",
 		FilterSubject: "blockchain.jobs.register_did",
 		AckPolicy:     nats.AckExplicitPolicy,
 		MaxAckPending: 100,
-		MaxDeliver:    3, // Retry failed jobs up to 3 times
+		MaxDeliver:    jobMaxDeliver, // Retry failed jobs up to 3 times
 	})
 
 	if err != nil && err.Error() != "consumer name already in use" {
 		log.Printf("Warning: failed to create consumer: %v", err)
 	}
 
-	return &NATSQueue{
-		conn: conn,
-		js:   js,
-	}, nil
+	_, err = n.js.AddStream(&nats.StreamConfig{
+		Name:      deadLetterStream,
+		Subjects:  []string{deadLetterSubjectPrefix + ">"},
+		Storage:   nats.FileStorage,
+		Retention: nats.LimitsPolicy,
+		MaxAge:    7 * 24 * time.Hour, // Keep dead letters long enough to inspect and replay
+	})
+
+	if err != nil && err.Error() != "stream name already in use" {
+		log.Printf("Warning: failed to create dead-letter stream: %v", err)
+	}
+}
+
+// ensureDuplicateWindow widens the duplicate window of a job stream created before
+// jobs were deduplicated
+func (n *NATSQueue) ensureDuplicateWindow(config *nats.StreamConfig) {
+	info, err := n.js.StreamInfo(config.Name)
+	if err != nil {
+		log.Printf("Warning: failed to get stream info: %v", err)
+		return
+	}
+	if info.Config.Duplicates >= config.Duplicates {
+		return
+	}
+
+	updated := info.Config
+	updated.Duplicates = config.Duplicates
+	if _, err := n.js.UpdateStream(&updated); err != nil {
+		log.Printf("Warning: failed to update duplicate window of stream %s: %v", config.Name, err)
+		return
+	}
+	log.Printf("Updated duplicate window of stream %s to %s", config.Name, config.Duplicates)
 }
 
 // BlockchainJob represents a job to be processed on the blockchain
@@ -73,17 +218,47 @@ type BlockchainJob struct {
 	UserHash  string    `json:"user_hash"`
 	DID       string    `json:"did"`
 	CreatedAt time.Time `json:"created_at"`
+	// DryRun jobs are simulated instead of submitted
+	DryRun bool `json:"dry_run,omitempty"`
+	// TraceID and SpanID identify the trace of the job and the span publishing it.
+	// They travel in the traceparent header rather than the payload.
+	TraceID string `json:"-"`
+	SpanID  string `json:"-"`
 }
 
-// PublishJob publishes a blockchain job to the queue
+// PublishJob publishes a blockchain job to the queue in a versioned envelope. The job
+// ID is the message ID, so publishing the same job again within the stream's duplicate
+// window is acknowledged without storing a second message.
 func (n *NATSQueue) PublishJob(job *BlockchainJob) error {
 	subject := fmt.Sprintf("blockchain.jobs.%s", job.JobType)
 
+	header, data, err := EncodeJob(job, n.encoding)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header = header
+	msg.Header.Set(nats.MsgIdHdr, job.ID)
+	if job.TraceID != "" && job.SpanID != "" {
+		msg.Header.Set(HeaderTraceParent, FormatTraceParent(job.TraceID, job.SpanID))
+	}
+	if n.keys != nil {
+		data, err = EncryptPayload(msg.Header, data, n.keys)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt job: %w", err)
+		}
+	}
+	msg.Data = data
+
 	// Publish with JetStream for persistence
-	ack, err := n.js.Publish(subject, job.toJSON())
+	ack, err := n.js.PublishMsg(msg)
 	if err != nil {
 		return fmt.Errorf("failed to publish job: %w", err)
 	}
+	if ack.Duplicate {
+		log.Printf("Job %s was already published, stream sequence: %d", job.ID, ack.Sequence)
+		return nil
+	}
 
 	log.Printf("Published job %s to subject %s, stream sequence: %d",
 		job.ID, subject, ack.Sequence)
@@ -91,24 +266,37 @@ func (n *NATSQueue) PublishJob(job *BlockchainJob) error {
 	return nil
 }
 
-// SubscribeToJobs subscribes to blockchain jobs for processing
+// SubscribeToJobs subscribes to blockchain jobs for processing. Messages that cannot be
+// decoded or are of an unknown job type, and jobs still failing on their last delivery,
+// are moved to the dead-letter stream instead of being dropped. Messages of a newer
+// schema version or encrypted with a key this consumer lacks are redelivered later, for
+// an upgraded consumer to pick up.
 func (n *NATSQueue) SubscribeToJobs(jobType string, handler func(*BlockchainJob) error) error {
 	subject := fmt.Sprintf("blockchain.jobs.%s", jobType)
 
 	// Subscribe with JetStream for reliable delivery
 	_, err := n.js.Subscribe(subject, func(msg *nats.Msg) {
-		var job BlockchainJob
-		if err := json.Unmarshal(msg.Data, &job); err != nil {
-			log.Printf("Failed to unmarshal job: %v", err)
-			msg.Nak() // Negative acknowledgment - will retry
+		job, err := n.decodeJob(msg)
+		if err != nil {
+			deferrable := errors.Is(err, ErrUnsupportedSchema) || errors.Is(err, ErrUnknownEncryptionKey)
+			if deferrable && !lastDelivery(msg) {
+				log.Printf("Deferring job message: %v", err)
+				msg.NakWithDelay(unsupportedSchemaDelay)
+				return
+			}
+			n.deadLetter(msg, err)
 			return
 		}
 
 		log.Printf("Processing job %s of type %s", job.ID, job.JobType)
 
 		// Process the job
-		if err := handler(&job); err != nil {
+		if err := handler(job); err != nil {
 			log.Printf("Failed to process job %s: %v", job.ID, err)
+			if lastDelivery(msg) {
+				n.deadLetter(msg, err)
+				return
+			}
 			msg.Nak() // Negative acknowledgment - will retry
 			return
 		}
@@ -134,20 +322,56 @@ func (n *NATSQueue) Close() {
 	}
 }
 
-// toJSON converts a BlockchainJob to JSON bytes
-func (j *BlockchainJob) toJSON() []byte {
-	data, err := json.Marshal(j)
+// decodeJob decrypts and decodes a job message
+func (n *NATSQueue) decodeJob(msg *nats.Msg) (*BlockchainJob, error) {
+	data, err := DecryptPayload(msg.Header, msg.Data, n.keys)
 	if err != nil {
-		log.Printf("Failed to marshal job: %v", err)
-		return nil
+		return nil, err
+	}
+	job, err := DecodeJob(msg.Header, data)
+	if err != nil {
+		return nil, err
+	}
+	job.TraceID, job.SpanID, _ = ParseTraceParent(msg.Header.Get(HeaderTraceParent))
+	return job, nil
+}
+
+// deadLetter moves a job message to the dead-letter stream with the reason it could
+// not be processed. If the dead letter cannot be published the message is redelivered.
+func (n *NATSQueue) deadLetter(msg *nats.Msg, reason error) {
+	jobType := strings.TrimPrefix(msg.Subject, "blockchain.jobs.")
+
+	dead := nats.NewMsg(deadLetterSubjectPrefix + jobType)
+	dead.Header = nats.Header{}
+	for key, values := range msg.Header {
+		dead.Header[key] = values
+	}
+	dead.Header.Set(HeaderDeadLetterReason, reason.Error())
+	dead.Data = msg.Data
+
+	if _, err := n.js.PublishMsg(dead); err != nil {
+		log.Printf("Failed to dead-letter job message: %v", err)
+		msg.Nak()
+		return
+	}
+
+	log.Printf("Dead-lettered job message from %s: %v", msg.Subject, reason)
+	msg.Term()
+}
+
+// lastDelivery reports whether a message will not be redelivered if it fails again
+func lastDelivery(msg *nats.Msg) bool {
+	meta, err := msg.Metadata()
+	if err != nil {
+		return false
 	}
-	return data
+	return meta.NumDelivered >= jobMaxDeliver
 }
 
 // GetPendingJobs retrieves pending jobs count from the stream
 func (n *NATSQueue) GetPendingJobs(jobType string, limit int) (int, error) {
 	// Get stream info to check pending messages
-	streamInfo, err := n.js.StreamInfo("BLOCKCHAIN_JOBS")
+	streamInfo, err := n.js.StreamInfo(jobsStream)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get stream info: %w", err)
 	}
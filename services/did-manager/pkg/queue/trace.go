@@ -0,0 +1,28 @@
+package queue
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// HeaderTraceParent carries the W3C Trace Context of a job message, linking the
+// consumer's processing to the trace the job was enqueued in
+const HeaderTraceParent = "traceparent"
+
+// traceParentPattern matches a version 00 traceparent: version, trace ID, parent span
+// ID and flags
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// FormatTraceParent formats a sampled traceparent for a trace and span
+func FormatTraceParent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// ParseTraceParent parses a traceparent into its trace and parent span IDs
+func ParseTraceParent(value string) (traceID, spanID string, err error) {
+	match := traceParentPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", fmt.Errorf("invalid traceparent: %q", value)
+	}
+	return match[1], match[2], nil
+}
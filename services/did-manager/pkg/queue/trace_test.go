@@ -0,0 +1,19 @@
+package queue
+
+import "testing"
+
+func TestTraceParent(t *testing.T) {
+	traceID, spanID := "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	value := FormatTraceParent(traceID, spanID)
+	if value != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("unexpected traceparent %s", value)
+	}
+
+	parsedTrace, parsedSpan, err := ParseTraceParent(value)
+	if err != nil || parsedTrace != traceID || parsedSpan != spanID {
+		t.Errorf("expected %s/%s, got %s/%s, %v", traceID, spanID, parsedTrace, parsedSpan, err)
+	}
+	if _, _, err := ParseTraceParent("00-short-00f067aa0ba902b7-01"); err == nil {
+		t.Error("expected an invalid traceparent to be rejected")
+	}
+}
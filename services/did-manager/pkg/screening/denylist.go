@@ -0,0 +1,125 @@
+package screening
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Denylist is a static screening provider. Entries match a subject's name, email or
+// DID exactly, ignoring case and surrounding or repeated whitespace; an entry starting
+// with "@" matches every email address at that domain.
+type Denylist struct {
+	entries map[string]Decision
+}
+
+// NewDenylist creates a denylist from entries mapped to the decision they produce
+func NewDenylist(entries map[string]Decision) (*Denylist, error) {
+	d := &Denylist{entries: make(map[string]Decision, len(entries))}
+	for value, decision := range entries {
+		if err := d.add(value, decision); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// ParseDenylist reads a denylist with one "<reject|flag> <value>" entry per line;
+// blank lines and lines starting with "#" are ignored
+func ParseDenylist(r io.Reader) (*Denylist, error) {
+	d := &Denylist{entries: make(map[string]Decision)}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		action, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"<reject|flag> <value>\"", lineNumber)
+		}
+		if err := d.add(value, Decision(action)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+
+	return d, nil
+}
+
+// LoadDenylist reads a denylist file in the ParseDenylist format
+func LoadDenylist(path string) (*Denylist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open denylist: %w", err)
+	}
+	defer f.Close()
+
+	return ParseDenylist(f)
+}
+
+// add records an entry, keeping the more severe decision for duplicates
+func (d *Denylist) add(value string, decision Decision) error {
+	if decision != DecisionFlag && decision != DecisionReject {
+		return fmt.Errorf("invalid denylist action %q: expected reject or flag", decision)
+	}
+
+	key := normalize(value)
+	if key == "" {
+		return fmt.Errorf("empty denylist entry")
+	}
+	if MoreSevere(decision, d.entries[key]) {
+		d.entries[key] = decision
+	}
+	return nil
+}
+
+// Len returns the number of entries
+func (d *Denylist) Len() int {
+	return len(d.entries)
+}
+
+// Name identifies the provider in audit records
+func (d *Denylist) Name() string {
+	return "static_denylist"
+}
+
+// Screen matches the subject against the denylist, returning the most severe match
+func (d *Denylist) Screen(_ context.Context, subject Subject) (*Result, error) {
+	candidates := []struct {
+		field, value string
+	}{
+		{"name", subject.Name},
+		{"email", subject.Email},
+		{"did", subject.DID},
+	}
+	if _, domain, ok := strings.Cut(subject.Email, "@"); ok {
+		candidates = append(candidates, struct{ field, value string }{"email domain", "@" + domain})
+	}
+
+	result := &Result{Decision: DecisionClear}
+	for _, candidate := range candidates {
+		key := normalize(candidate.value)
+		if key == "" {
+			continue
+		}
+		if decision, ok := d.entries[key]; ok && MoreSevere(decision, result.Decision) {
+			result.Decision = decision
+			result.Reason = fmt.Sprintf("%s matches denylist entry %q", candidate.field, key)
+		}
+	}
+
+	return result, nil
+}
+
+// normalize lower-cases a value and collapses its whitespace
+func normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
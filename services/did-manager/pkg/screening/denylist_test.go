@@ -0,0 +1,63 @@
+package screening
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseDenylist(t *testing.T) {
+	list, err := ParseDenylist(strings.NewReader(`
+# Sanctioned parties
+reject  Jane   Doe
+reject did:example:blocked
+flag @watch.example
+flag mallory@example.com
+reject mallory@example.com
+`))
+	if err != nil {
+		t.Fatalf("ParseDenylist failed: %v", err)
+	}
+	if list.Len() != 4 {
+		t.Fatalf("expected 4 entries, got %d", list.Len())
+	}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		want    Decision
+	}{
+		{"unlisted", Subject{Name: "John Smith", Email: "john@example.com"}, DecisionClear},
+		{"name ignores case and spacing", Subject{Name: "  jane DOE "}, DecisionReject},
+		{"did", Subject{DID: "did:example:blocked"}, DecisionReject},
+		{"email domain", Subject{Email: "someone@Watch.example"}, DecisionFlag},
+		{"duplicate keeps most severe", Subject{Email: "mallory@example.com"}, DecisionReject},
+		{"most severe match wins", Subject{Name: "Jane Doe", Email: "x@watch.example"}, DecisionReject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := list.Screen(context.Background(), tt.subject)
+			if err != nil {
+				t.Fatalf("Screen failed: %v", err)
+			}
+			if result.Decision != tt.want {
+				t.Errorf("expected %s, got %s (%s)", tt.want, result.Decision, result.Reason)
+			}
+			if tt.want != DecisionClear && result.Reason == "" {
+				t.Error("expected a reason for a match")
+			}
+		})
+	}
+}
+
+func TestParseDenylistRejectsInvalidEntries(t *testing.T) {
+	for _, input := range []string{
+		"jane@example.com",
+		"block jane@example.com",
+		"clear jane@example.com",
+	} {
+		if _, err := ParseDenylist(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package screening
+
+import "context"
+
+// Decision is the outcome of screening an identity
+type Decision string
+
+// Screening decisions, from least to most severe
+const (
+	DecisionClear  Decision = "clear"
+	DecisionFlag   Decision = "flag"
+	DecisionReject Decision = "reject"
+)
+
+// severity orders decisions so the most severe of several matches wins
+var severity = map[Decision]int{
+	DecisionClear:  0,
+	DecisionFlag:   1,
+	DecisionReject: 2,
+}
+
+// Subject is the identity being screened; empty fields are not screened
+type Subject struct {
+	Name  string
+	Email string
+	DID   string
+}
+
+// Result is a provider's decision on a subject
+type Result struct {
+	Decision Decision
+	// Reason explains a flag or rejection, e.g. the matched list entry
+	Reason string
+}
+
+// Provider screens identities against a sanctions list or denylist
+type Provider interface {
+	// Name identifies the provider in audit records
+	Name() string
+	// Screen decides whether an identity may be served
+	Screen(ctx context.Context, subject Subject) (*Result, error)
+}
+
+// MoreSevere reports whether decision a is more severe than b
+func MoreSevere(a, b Decision) bool {
+	return severity[a] > severity[b]
+}
@@ -0,0 +1,202 @@
+package sidetree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxFileSize bounds batch files so that each is stored as a single raw IPFS block,
+// whose CID is then the SHA-256 digest of the file itself
+const MaxFileSize = 256 * 1024
+
+// ErrNotFound is returned when a store has no content for a CID
+var ErrNotFound = errors.New("content not found")
+
+// Store is a content-addressed store for batch files, such as IPFS
+type Store interface {
+	// Put stores data and returns its CID
+	Put(ctx context.Context, data []byte) (string, error)
+	// Get retrieves the data stored under a CID
+	Get(ctx context.Context, cid string) ([]byte, error)
+}
+
+// CIDv1 prefix for a raw block addressed by its SHA-256 multihash
+var rawSHA256Prefix = []byte{0x01, 0x55, 0x12, 0x20}
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// ComputeCID returns the CIDv1 (raw codec, SHA-256, base32) IPFS assigns data stored
+// as a single raw block
+func ComputeCID(data []byte) string {
+	digest := sha256.Sum256(data)
+	return "b" + base32Lower.EncodeToString(append(append([]byte{}, rawSHA256Prefix...), digest[:]...))
+}
+
+// DigestFromCID extracts the SHA-256 digest from a CID computed by ComputeCID
+func DigestFromCID(cid string) ([32]byte, error) {
+	var digest [32]byte
+
+	if !strings.HasPrefix(cid, "b") {
+		return digest, fmt.Errorf("unsupported CID encoding: %s", cid)
+	}
+	decoded, err := base32Lower.DecodeString(cid[1:])
+	if err != nil {
+		return digest, fmt.Errorf("invalid CID: %w", err)
+	}
+	if len(decoded) != len(rawSHA256Prefix)+len(digest) || !bytes.Equal(decoded[:len(rawSHA256Prefix)], rawSHA256Prefix) {
+		return digest, fmt.Errorf("unsupported CID: %s", cid)
+	}
+
+	copy(digest[:], decoded[len(rawSHA256Prefix):])
+	return digest, nil
+}
+
+// MemoryStore is an in-memory Store, for tests and local development
+type MemoryStore struct {
+	mu      sync.RWMutex
+	content map[string][]byte
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{content: make(map[string][]byte)}
+}
+
+// Put stores data under its CID
+func (s *MemoryStore) Put(_ context.Context, data []byte) (string, error) {
+	cid := ComputeCID(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content[cid] = append([]byte(nil), data...)
+	return cid, nil
+}
+
+// Get retrieves data by CID
+func (s *MemoryStore) Get(_ context.Context, cid string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.content[cid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, cid)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// IPFSStore stores batch files through the HTTP RPC API of an IPFS (Kubo) node,
+// pinning them so they stay available to observers
+type IPFSStore struct {
+	apiURL     string
+	httpClient *http.Client
+}
+
+// NewIPFSStore creates a store for the IPFS node API at apiURL, e.g. http://localhost:5001
+func NewIPFSStore(apiURL string) *IPFSStore {
+	return &IPFSStore{
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Put adds and pins data as a single raw block, checking the node assigned the
+// expected CID
+func (s *IPFSStore) Put(ctx context.Context, data []byte) (string, error) {
+	if len(data) > MaxFileSize {
+		return "", fmt.Errorf("file of %d bytes exceeds the %d byte limit", len(data), MaxFileSize)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "batch")
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	query := url.Values{
+		"cid-version": {"1"},
+		"raw-leaves":  {"true"},
+		"hash":        {"sha2-256"},
+		"chunker":     {fmt.Sprintf("size-%d", MaxFileSize)},
+		"pin":         {"true"},
+	}
+	resp, err := s.call(ctx, "add", query, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var added struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return "", fmt.Errorf("failed to decode IPFS response: %w", err)
+	}
+
+	if expected := ComputeCID(data); added.Hash != expected {
+		return "", fmt.Errorf("IPFS returned CID %s, expected %s", added.Hash, expected)
+	}
+	return added.Hash, nil
+}
+
+// Get retrieves data by CID, checking it matches the CID
+func (s *IPFSStore) Get(ctx context.Context, cid string) ([]byte, error) {
+	resp, err := s.call(ctx, "cat", url.Values{"arg": {cid}}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPFS response: %w", err)
+	}
+	if ComputeCID(data) != cid {
+		return nil, fmt.Errorf("content of %s does not match its CID", cid)
+	}
+	return data, nil
+}
+
+// call invokes an RPC API command; the API only accepts POST
+func (s *IPFSStore) call(ctx context.Context, command string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/api/v0/"+command+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach IPFS: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if command == "cat" && bytes.Contains(message, []byte("not found")) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, query.Get("arg"))
+		}
+		return nil, fmt.Errorf("IPFS %s failed with status %d: %s", command, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return resp, nil
+}
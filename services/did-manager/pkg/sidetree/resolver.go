@@ -0,0 +1,177 @@
+package sidetree
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"did-manager/pkg/did"
+)
+
+// IONPrefix prefixes did:ion identifiers, both short-form and long-form
+const IONPrefix = "did:ion:"
+
+// maxResolutionSize bounds resolution responses
+const maxResolutionSize = 1024 * 1024
+
+var (
+	// ErrInvalidDID is returned for identifiers that are not Sidetree DIDs
+	ErrInvalidDID = errors.New("invalid sidetree DID")
+	// ErrDIDNotFound is returned when the resolver does not know a DID
+	ErrDIDNotFound = errors.New("DID not found")
+)
+
+// sidetreeDIDPattern matches "did:<method>:<suffix>" with an optional long-form part;
+// every segment is base64url
+var sidetreeDIDPattern = regexp.MustCompile(`^did:[a-z0-9]+:[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)?$`)
+
+// IsION reports whether an identifier is a did:ion DID
+func IsION(didString string) bool {
+	return strings.HasPrefix(didString, IONPrefix)
+}
+
+// Resolution is a resolved Sidetree DID
+type Resolution struct {
+	Document *did.Document
+	Metadata DocumentMetadata
+}
+
+// DocumentMetadata describes the state of a Sidetree DID
+type DocumentMetadata struct {
+	Method struct {
+		// Published reports whether the DID is anchored; unpublished long-form DIDs
+		// resolve from the identifier itself
+		Published bool `json:"published"`
+	} `json:"method"`
+	CanonicalID string `json:"canonicalId,omitempty"`
+	Deactivated bool   `json:"deactivated,omitempty"`
+}
+
+// resolutionResponse is the DID resolution result returned by ION nodes and by the
+// universal resolver
+type resolutionResponse struct {
+	Document *document        `json:"didDocument"`
+	Metadata DocumentMetadata `json:"didDocumentMetadata"`
+}
+
+// document is a Sidetree DID Document. Its @context carries JSON-LD objects, and method
+// IDs are usually relative to the DID, e.g. "#key-1".
+type document struct {
+	ID                 string                   `json:"id"`
+	VerificationMethod []did.VerificationMethod `json:"verificationMethod"`
+	Authentication     []string                 `json:"authentication"`
+	AssertionMethod    []string                 `json:"assertionMethod"`
+}
+
+// Resolver resolves Sidetree DIDs, such as did:ion, through the HTTP resolution
+// endpoint of an ION node or a universal resolver
+type Resolver struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewResolver creates a resolver for an endpoint the DID is appended to, e.g.
+// http://localhost:3000/identifiers/ for an ION node or
+// https://resolver.example/1.0/identifiers/ for a universal resolver
+func NewResolver(endpoint string) *Resolver {
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return &Resolver{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Resolve resolves a DID into its document, with verification method references made
+// absolute. Deactivated DIDs resolve with Metadata.Deactivated set.
+func (r *Resolver) Resolve(ctx context.Context, didString string) (*Resolution, error) {
+	if !sidetreeDIDPattern.MatchString(didString) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDID, didString)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+didString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/ld+json;profile=\"https://w3id.org/did-resolution\", application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach resolver: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResolutionSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolver response: %w", err)
+	}
+	if len(body) > maxResolutionSize {
+		return nil, fmt.Errorf("resolver response exceeds %d bytes", maxResolutionSize)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusGone:
+		// Deactivated DIDs are reported as 410 Gone with their final metadata
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrDIDNotFound, didString)
+	default:
+		return nil, fmt.Errorf("resolver failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result resolutionResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode resolver response: %w", err)
+	}
+	if result.Document == nil {
+		if result.Metadata.Deactivated {
+			return &Resolution{Metadata: result.Metadata}, nil
+		}
+		return nil, fmt.Errorf("resolver returned no document for %s", didString)
+	}
+
+	return &Resolution{
+		Document: result.Document.normalize(didString),
+		Metadata: result.Metadata,
+	}, nil
+}
+
+// normalize converts a Sidetree document into a DID Document with absolute method IDs
+func (d *document) normalize(didString string) *did.Document {
+	id := d.ID
+	if id == "" {
+		id = didString
+	}
+	absolute := func(ref string) string {
+		if strings.HasPrefix(ref, "#") {
+			return id + ref
+		}
+		return ref
+	}
+
+	doc := &did.Document{
+		Context:            []string{did.ContextDIDCore},
+		ID:                 id,
+		VerificationMethod: make([]did.VerificationMethod, 0, len(d.VerificationMethod)),
+	}
+	for _, method := range d.VerificationMethod {
+		method.ID = absolute(method.ID)
+		if method.Controller == "" {
+			method.Controller = id
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, method)
+	}
+	for _, ref := range d.Authentication {
+		doc.Authentication = append(doc.Authentication, absolute(ref))
+	}
+	for _, ref := range d.AssertionMethod {
+		doc.AssertionMethod = append(doc.AssertionMethod, absolute(ref))
+	}
+	return doc
+}
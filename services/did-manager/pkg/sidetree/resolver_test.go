@@ -0,0 +1,90 @@
+package sidetree
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const ionDID = "did:ion:EiClkZMDxPKqC9c-umQfTkR8vvZ9JPhl_xLDI9Nfk38w5w"
+
+// ionResolution is a resolution result in the shape ION nodes return
+const ionResolution = `{
+	"@context": "https://w3id.org/did-resolution/v1",
+	"didDocument": {
+		"id": "` + ionDID + `",
+		"@context": ["https://www.w3.org/ns/did/v1", {"@base": "` + ionDID + `"}],
+		"verificationMethod": [{
+			"id": "#key-1",
+			"controller": "` + ionDID + `",
+			"type": "JsonWebKey2020",
+			"publicKeyJwk": {"kty": "OKP", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+		}],
+		"authentication": ["#key-1"],
+		"assertionMethod": ["#key-1"]
+	},
+	"didDocumentMetadata": {
+		"method": {"published": true, "recoveryCommitment": "EiB", "updateCommitment": "EiC"},
+		"canonicalId": "` + ionDID + `"
+	}
+}`
+
+func TestResolverResolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/identifiers/") {
+		case ionDID:
+			w.Write([]byte(ionResolution))
+		case "did:ion:deactivated":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"didDocument": null, "didDocumentMetadata": {"method": {"published": true}, "deactivated": true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(server.URL + "/identifiers")
+
+	resolution, err := resolver.Resolve(context.Background(), ionDID)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !resolution.Metadata.Method.Published || resolution.Metadata.Deactivated {
+		t.Errorf("unexpected metadata %+v", resolution.Metadata)
+	}
+
+	doc := resolution.Document
+	keyID := ionDID + "#key-1"
+	method, ok := doc.Method(keyID)
+	if !ok {
+		t.Fatalf("expected verification method %s, got %+v", keyID, doc.VerificationMethod)
+	}
+	if !doc.Authorizes(keyID, "authentication") || !doc.Authorizes(keyID, "assertionMethod") {
+		t.Error("expected the key to be authorized for authentication and assertions")
+	}
+	suite, key, err := method.JWKPublicKey()
+	if err != nil {
+		t.Fatalf("JWKPublicKey failed: %v", err)
+	}
+	if suite != "ed25519-2020" || len(key) != 32 {
+		t.Errorf("unexpected key %s/%x", suite, key)
+	}
+
+	deactivated, err := resolver.Resolve(context.Background(), "did:ion:deactivated")
+	if err != nil {
+		t.Fatalf("Resolve of a deactivated DID failed: %v", err)
+	}
+	if !deactivated.Metadata.Deactivated || deactivated.Document != nil {
+		t.Errorf("expected a deactivated DID without document, got %+v", deactivated)
+	}
+
+	if _, err := resolver.Resolve(context.Background(), "did:ion:unknown"); !errors.Is(err, ErrDIDNotFound) {
+		t.Errorf("expected ErrDIDNotFound, got %v", err)
+	}
+	if _, err := resolver.Resolve(context.Background(), "did:ion:../admin"); !errors.Is(err, ErrInvalidDID) {
+		t.Errorf("expected ErrInvalidDID, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Package sidetree batches DID operations the way DIF Sidetree (and ION) do: the
+// operations are written to content-addressed files and a single anchor string
+// committing to them is written on-chain, so one transaction anchors many operations.
+//
+// A batch consists of two gzip-compressed JSON files. The chunk file carries the
+// operation deltas; the core index file references the chunk file and lists the DIDs
+// operated on, grouped by operation type. The anchor string is
+// "<number of operations>.<core index file CID>", and the SHA-256 digest of the core
+// index file is what is written on-chain.
+//
+// The package also resolves DIDs of Sidetree networks, such as did:ion, through an
+// external resolver.
+package sidetree
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OperationType is the kind of DID operation
+type OperationType string
+
+const (
+	OperationCreate     OperationType = "create"
+	OperationUpdate     OperationType = "update"
+	OperationDeactivate OperationType = "deactivate"
+)
+
+// maxDecompressedSize bounds decompressed batch files
+const maxDecompressedSize = 4 * 1024 * 1024
+
+// Operation is a DID operation included in a batch
+type Operation struct {
+	Type OperationType `json:"type"`
+	DID  string        `json:"did"`
+	// UserHash is the commitment the DID is registered with
+	UserHash string `json:"userHash"`
+}
+
+// Batch is a written batch of operations
+type Batch struct {
+	// Operations are in their canonical order: creates, updates, then deactivates,
+	// each in the order given. A receipt's operation index refers to this order.
+	Operations       []Operation
+	ChunkFileURI     string
+	CoreIndexFileURI string
+	// AnchorFileHash is the SHA-256 digest of the core index file, written on-chain
+	AnchorFileHash [32]byte
+	AnchorString   string
+}
+
+// coreIndexFile lists the operations of a batch
+type coreIndexFile struct {
+	ChunkFileURI string         `json:"chunkFileUri"`
+	Operations   coreOperations `json:"operations"`
+}
+
+type coreOperations struct {
+	Create     []operationReference `json:"create,omitempty"`
+	Update     []operationReference `json:"update,omitempty"`
+	Deactivate []operationReference `json:"deactivate,omitempty"`
+}
+
+type operationReference struct {
+	DID string `json:"did"`
+}
+
+// chunkFile carries one delta per operation, in the order the core index file lists
+// them
+type chunkFile struct {
+	Deltas []delta `json:"deltas"`
+}
+
+type delta struct {
+	UserHash string `json:"userHash"`
+}
+
+// WriteBatch writes the files of a batch to the store and derives its anchor string
+func WriteBatch(ctx context.Context, store Store, operations []Operation) (*Batch, error) {
+	if len(operations) == 0 {
+		return nil, errors.New("batch has no operations")
+	}
+
+	var creates, updates, deactivates []Operation
+	for _, op := range operations {
+		switch op.Type {
+		case OperationCreate:
+			creates = append(creates, op)
+		case OperationUpdate:
+			updates = append(updates, op)
+		case OperationDeactivate:
+			deactivates = append(deactivates, op)
+		default:
+			return nil, fmt.Errorf("unknown operation type: %s", op.Type)
+		}
+	}
+
+	ordered := make([]Operation, 0, len(operations))
+	ordered = append(append(append(ordered, creates...), updates...), deactivates...)
+
+	var chunk chunkFile
+	var core coreIndexFile
+	for _, op := range ordered {
+		chunk.Deltas = append(chunk.Deltas, delta{UserHash: op.UserHash})
+		ref := operationReference{DID: op.DID}
+		switch op.Type {
+		case OperationCreate:
+			core.Operations.Create = append(core.Operations.Create, ref)
+		case OperationUpdate:
+			core.Operations.Update = append(core.Operations.Update, ref)
+		case OperationDeactivate:
+			core.Operations.Deactivate = append(core.Operations.Deactivate, ref)
+		}
+	}
+
+	chunkURI, err := putFile(ctx, store, chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write chunk file: %w", err)
+	}
+	core.ChunkFileURI = chunkURI
+
+	coreURI, err := putFile(ctx, store, core)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write core index file: %w", err)
+	}
+	digest, err := DigestFromCID(coreURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Batch{
+		Operations:       ordered,
+		ChunkFileURI:     chunkURI,
+		CoreIndexFileURI: coreURI,
+		AnchorFileHash:   digest,
+		AnchorString:     AnchorString(len(ordered), coreURI),
+	}, nil
+}
+
+// ReadBatch resolves the operations committed to by an anchor string, in their
+// canonical order
+func ReadBatch(ctx context.Context, store Store, anchorString string) ([]Operation, error) {
+	count, coreURI, err := ParseAnchorString(anchorString)
+	if err != nil {
+		return nil, err
+	}
+
+	var core coreIndexFile
+	if err := getFile(ctx, store, coreURI, &core); err != nil {
+		return nil, fmt.Errorf("failed to read core index file: %w", err)
+	}
+	var chunk chunkFile
+	if err := getFile(ctx, store, core.ChunkFileURI, &chunk); err != nil {
+		return nil, fmt.Errorf("failed to read chunk file: %w", err)
+	}
+
+	var operations []Operation
+	for _, group := range []struct {
+		opType OperationType
+		refs   []operationReference
+	}{
+		{OperationCreate, core.Operations.Create},
+		{OperationUpdate, core.Operations.Update},
+		{OperationDeactivate, core.Operations.Deactivate},
+	} {
+		for _, ref := range group.refs {
+			operations = append(operations, Operation{Type: group.opType, DID: ref.DID})
+		}
+	}
+
+	if len(operations) != count || len(chunk.Deltas) != count {
+		return nil, fmt.Errorf("batch lists %d operations and %d deltas, anchor string declares %d", len(operations), len(chunk.Deltas), count)
+	}
+	for i := range operations {
+		operations[i].UserHash = chunk.Deltas[i].UserHash
+	}
+
+	return operations, nil
+}
+
+// AnchorString formats the anchor string of a batch
+func AnchorString(operationCount int, coreIndexFileURI string) string {
+	return strconv.Itoa(operationCount) + "." + coreIndexFileURI
+}
+
+// ParseAnchorString splits an anchor string into its operation count and core index
+// file CID
+func ParseAnchorString(anchorString string) (int, string, error) {
+	countPart, cid, ok := strings.Cut(anchorString, ".")
+	if !ok || cid == "" {
+		return 0, "", fmt.Errorf("invalid anchor string: %s", anchorString)
+	}
+	count, err := strconv.Atoi(countPart)
+	if err != nil || count <= 0 {
+		return 0, "", fmt.Errorf("invalid operation count in anchor string: %s", anchorString)
+	}
+	return count, cid, nil
+}
+
+// putFile compresses and stores a batch file
+func putFile(ctx context.Context, store Store, file any) (string, error) {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	if compressed.Len() > MaxFileSize {
+		return "", fmt.Errorf("compressed file of %d bytes exceeds the %d byte limit", compressed.Len(), MaxFileSize)
+	}
+
+	return store.Put(ctx, compressed.Bytes())
+}
+
+// getFile retrieves and decompresses a batch file
+func getFile(ctx context.Context, store Store, cid string, file any) error {
+	compressed, err := store.Get(ctx, cid)
+	if err != nil {
+		return err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", cid, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", cid, err)
+	}
+	if len(data) > maxDecompressedSize {
+		return fmt.Errorf("file %s exceeds the decompressed size limit", cid)
+	}
+
+	return json.Unmarshal(data, file)
+}
@@ -0,0 +1,160 @@
+package sidetree
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeCID(t *testing.T) {
+	// CID IPFS assigns the empty file added with --cid-version=1 --raw-leaves
+	if cid := ComputeCID(nil); cid != "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" {
+		t.Errorf("unexpected CID %s", cid)
+	}
+
+	data := []byte("core index file")
+	digest, err := DigestFromCID(ComputeCID(data))
+	if err != nil {
+		t.Fatalf("DigestFromCID failed: %v", err)
+	}
+	if digest != sha256.Sum256(data) {
+		t.Error("expected the CID digest to be the SHA-256 of the data")
+	}
+
+	if _, err := DigestFromCID("QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"); err == nil {
+		t.Error("expected CIDv0 to be rejected")
+	}
+}
+
+func TestWriteAndReadBatch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	operations := []Operation{
+		{Type: OperationDeactivate, DID: "did:example:c", UserHash: "hash-c"},
+		{Type: OperationCreate, DID: "did:example:a", UserHash: "hash-a"},
+		{Type: OperationUpdate, DID: "did:example:b", UserHash: "hash-b"},
+		{Type: OperationCreate, DID: "did:example:d", UserHash: "hash-d"},
+	}
+	batch, err := WriteBatch(ctx, store, operations)
+	if err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+
+	wantOrder := []string{"did:example:a", "did:example:d", "did:example:b", "did:example:c"}
+	for i, op := range batch.Operations {
+		if op.DID != wantOrder[i] {
+			t.Errorf("operation %d: expected %s, got %s", i, wantOrder[i], op.DID)
+		}
+	}
+	if !strings.HasPrefix(batch.AnchorString, "4.") || !strings.HasSuffix(batch.AnchorString, batch.CoreIndexFileURI) {
+		t.Errorf("unexpected anchor string %s", batch.AnchorString)
+	}
+
+	coreFile, err := store.Get(ctx, batch.CoreIndexFileURI)
+	if err != nil {
+		t.Fatalf("core index file not stored: %v", err)
+	}
+	if batch.AnchorFileHash != sha256.Sum256(coreFile) {
+		t.Error("expected the anchor file hash to be the digest of the core index file")
+	}
+
+	read, err := ReadBatch(ctx, store, batch.AnchorString)
+	if err != nil {
+		t.Fatalf("ReadBatch failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, batch.Operations) {
+		t.Errorf("expected %+v, got %+v", batch.Operations, read)
+	}
+
+	// The same operations produce the same files
+	again, err := WriteBatch(ctx, store, operations)
+	if err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	if again.AnchorString != batch.AnchorString {
+		t.Error("expected batches to be deterministic")
+	}
+}
+
+func TestWriteBatchRejectsInvalid(t *testing.T) {
+	store := NewMemoryStore()
+	if _, err := WriteBatch(context.Background(), store, nil); err == nil {
+		t.Error("expected an empty batch to be rejected")
+	}
+	if _, err := WriteBatch(context.Background(), store, []Operation{{Type: "recover", DID: "did:example:a"}}); err == nil {
+		t.Error("expected an unknown operation type to be rejected")
+	}
+}
+
+func TestParseAnchorString(t *testing.T) {
+	count, cid, err := ParseAnchorString("12.bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku")
+	if err != nil || count != 12 || cid != "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" {
+		t.Errorf("unexpected parse: %d %s %v", count, cid, err)
+	}
+
+	for _, invalid := range []string{"", "12", "x.bafy", "0.bafy", "12."} {
+		if _, _, err := ParseAnchorString(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestIPFSStore(t *testing.T) {
+	content := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/api/v0/add":
+			if r.URL.Query().Get("cid-version") != "1" || r.URL.Query().Get("raw-leaves") != "true" {
+				t.Errorf("unexpected add options %s", r.URL.RawQuery)
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("missing file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			cid := ComputeCID(data)
+			content[cid] = data
+			json.NewEncoder(w).Encode(map[string]string{"Name": "batch", "Hash": cid})
+		case "/api/v0/cat":
+			data, ok := content[r.URL.Query().Get("arg")]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"block was not found locally"}`))
+				return
+			}
+			w.Write(data)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	store := NewIPFSStore(server.URL + "/")
+	ctx := context.Background()
+
+	batch, err := WriteBatch(ctx, store, []Operation{{Type: OperationCreate, DID: "did:example:a", UserHash: "hash-a"}})
+	if err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	read, err := ReadBatch(ctx, store, batch.AnchorString)
+	if err != nil {
+		t.Fatalf("ReadBatch failed: %v", err)
+	}
+	if len(read) != 1 || read[0].UserHash != "hash-a" {
+		t.Errorf("unexpected operations %+v", read)
+	}
+
+	if _, err := store.Get(ctx, ComputeCID([]byte("missing"))); err == nil {
+		t.Error("expected missing content to fail")
+	}
+}
@@ -0,0 +1,35 @@
+package siem
+
+import (
+	"bytes"
+	"io"
+)
+
+// auditPrefix marks the audit lines of the standard logger
+var auditPrefix = []byte("AUDIT: ")
+
+// auditWriter passes log output on and ships its audit lines
+type auditWriter struct {
+	shipper *Shipper
+	next    io.Writer
+}
+
+// AuditWriter returns an output for the standard logger that writes to next and ships
+// every line logged with the "AUDIT: " prefix as an audit record. The logger writes
+// each line with a single call, so lines are never split.
+func AuditWriter(shipper *Shipper, next io.Writer) io.Writer {
+	return &auditWriter{shipper: shipper, next: next}
+}
+
+func (w *auditWriter) Write(p []byte) (int, error) {
+	if i := bytes.Index(p, auditPrefix); i >= 0 {
+		w.shipper.Ship(&Record{
+			Category: CategoryAudit,
+			Action:   "audit.log",
+			Outcome:  OutcomeSuccess,
+			Severity: SeverityNotice,
+			Message:  string(bytes.TrimSpace(p[i+len(auditPrefix):])),
+		})
+	}
+	return w.next.Write(p)
+}
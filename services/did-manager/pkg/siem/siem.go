@@ -0,0 +1,351 @@
+// Package siem ships audit records, security-relevant API activity and domain events
+// to a SIEM through syslog, HTTP or Kafka sinks. Records are buffered and sent in
+// batches in the background, so shipping never slows requests down.
+package siem
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SchemaVersion is the version of the Record schema, bumped on incompatible changes
+const SchemaVersion = "1"
+
+// Record categories
+const (
+	// CategoryAudit records administrative and security-sensitive changes the service
+	// made, such as revoked keys or changed tenant settings
+	CategoryAudit = "audit"
+	// CategorySecurity records API requests of security interest: admin calls and
+	// requests denied or rate limited
+	CategorySecurity = "security"
+	// CategoryEvent records domain events, such as created or revoked DIDs
+	CategoryEvent = "event"
+)
+
+// Outcomes of the action a record describes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// Severities, those of syslog
+const (
+	SeverityError   = 3
+	SeverityWarning = 4
+	SeverityNotice  = 5
+	SeverityInfo    = 6
+)
+
+// Record is a single entry shipped to the SIEM. Fields are flat so SIEMs index them
+// without mapping; empty fields are omitted.
+type Record struct {
+	SchemaVersion string    `json:"schema_version"`
+	ID            string    `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	// Source names the service and instance the record comes from
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	// Action names what happened, e.g. audit.log, http.request or did.revoked
+	Action   string `json:"action"`
+	Outcome  string `json:"outcome,omitempty"`
+	Severity int    `json:"severity"`
+	// ActorType and ActorID name the caller: an API key, DID or wallet user
+	ActorType string `json:"actor_type,omitempty"`
+	ActorID   string `json:"actor_id,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	SourceIP  string `json:"source_ip,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// HTTPMethod, HTTPRoute and HTTPStatus describe API requests; the route is the
+	// pattern matched, e.g. /api/v1/did/status/:did
+	HTTPMethod string `json:"http_method,omitempty"`
+	HTTPRoute  string `json:"http_route,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	// Subject is the DID the record concerns
+	Subject string            `json:"subject,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Sink delivers batches of records to a SIEM
+type Sink interface {
+	// Name identifies the sink in metrics
+	Name() string
+	Write(ctx context.Context, records []*Record) error
+}
+
+// Config tunes a shipper; zero values use the defaults
+type Config struct {
+	// Source names the service and instance in every record
+	Source string
+	// BufferSize bounds the records waiting to be shipped (default 10000)
+	BufferSize int
+	// BatchSize bounds the records sent to a sink at once (default 100)
+	BatchSize int
+	// FlushInterval is how long records wait for a batch to fill (default 5s)
+	FlushInterval time.Duration
+	// MaxRetries bounds the retries of a batch a sink failed to take, with exponential
+	// backoff from RetryBackoff (defaults 3 and 1s); the batch is then dropped
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// BlockTimeout is how long Ship waits for room in a full buffer before dropping the
+	// record; zero drops it at once, so producers are never slowed down
+	BlockTimeout time.Duration
+}
+
+// SinkStats counts the records a sink shipped and failed to ship
+type SinkStats struct {
+	Sink    string `json:"sink"`
+	Shipped uint64 `json:"shipped"`
+	Failed  uint64 `json:"failed"`
+	Retries uint64 `json:"retries"`
+}
+
+// Stats reports the delivery metrics of a shipper
+type Stats struct {
+	// Queued records wait in the buffer; Capacity is the buffer's size
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+	// Dropped records found the buffer full
+	Dropped uint64      `json:"dropped"`
+	Sinks   []SinkStats `json:"sinks"`
+}
+
+// sinkCounters counts the deliveries of one sink
+type sinkCounters struct {
+	shipped atomic.Uint64
+	failed  atomic.Uint64
+	retries atomic.Uint64
+}
+
+// Shipper buffers records and ships them in batches to every sink
+type Shipper struct {
+	sinks    []Sink
+	cfg      Config
+	queue    chan *Record
+	dropped  atomic.Uint64
+	counters []*sinkCounters
+
+	mu   sync.Mutex
+	stop chan context.Context
+	done chan struct{}
+}
+
+// NewShipper creates a shipper sending records to the given sinks
+func NewShipper(cfg Config, sinks ...Sink) (*Shipper, error) {
+	if len(sinks) == 0 {
+		return nil, errors.New("siem shipper needs a sink")
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+
+	counters := make([]*sinkCounters, len(sinks))
+	for i := range counters {
+		counters[i] = &sinkCounters{}
+	}
+	return &Shipper{
+		sinks:    sinks,
+		cfg:      cfg,
+		queue:    make(chan *Record, cfg.BufferSize),
+		counters: counters,
+	}, nil
+}
+
+// Ship queues a record, filling in its schema version, ID, time and source. When the
+// buffer is full it waits up to the configured block timeout, then drops the record.
+func (s *Shipper) Ship(record *Record) {
+	record.SchemaVersion = SchemaVersion
+	if record.ID == "" {
+		record.ID = recordID()
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+	if record.Source == "" {
+		record.Source = s.cfg.Source
+	}
+
+	select {
+	case s.queue <- record:
+		return
+	default:
+	}
+	if s.cfg.BlockTimeout > 0 {
+		timer := time.NewTimer(s.cfg.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- record:
+			return
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+// Stats reports the shipper's delivery metrics
+func (s *Shipper) Stats() *Stats {
+	stats := &Stats{
+		Queued:   len(s.queue),
+		Capacity: cap(s.queue),
+		Dropped:  s.dropped.Load(),
+		Sinks:    make([]SinkStats, len(s.sinks)),
+	}
+	for i, sink := range s.sinks {
+		stats.Sinks[i] = SinkStats{
+			Sink:    sink.Name(),
+			Shipped: s.counters[i].shipped.Load(),
+			Failed:  s.counters[i].failed.Load(),
+			Retries: s.counters[i].retries.Load(),
+		}
+	}
+	return stats
+}
+
+// Start starts shipping in the background
+func (s *Shipper) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		return errors.New("siem shipper already started")
+	}
+
+	s.stop = make(chan context.Context, 1)
+	s.done = make(chan struct{})
+	go s.run(s.stop)
+	return nil
+}
+
+// Stop ships the records still buffered, giving up once ctx ends
+func (s *Shipper) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	stop <- ctx
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run collects batches until the batch size or flush interval is reached and ships
+// them; once stopped it drains the buffer under the stop context
+func (s *Shipper) run(stop <-chan context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	// Batches are shipped to completion while running; sinks bound each write
+	ctx := context.Background()
+	batch := make([]*Record, 0, s.cfg.BatchSize)
+	for {
+		select {
+		case record := <-s.queue:
+			batch = append(batch, record)
+			if len(batch) >= s.cfg.BatchSize {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case stopCtx := <-stop:
+			s.drain(stopCtx, batch)
+			return
+		}
+	}
+}
+
+// drain ships the partial batch and every buffered record
+func (s *Shipper) drain(ctx context.Context, batch []*Record) {
+	for {
+		select {
+		case record := <-s.queue:
+			batch = append(batch, record)
+			if len(batch) < s.cfg.BatchSize {
+				continue
+			}
+		default:
+		}
+		if len(batch) == 0 {
+			return
+		}
+		s.flush(ctx, batch)
+		batch = batch[:0]
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// flush sends a batch to every sink, retrying with backoff; batches a sink still
+// fails to take are counted as failed and dropped for that sink
+func (s *Shipper) flush(ctx context.Context, batch []*Record) {
+	for i, sink := range s.sinks {
+		counters := s.counters[i]
+		backoff := s.cfg.RetryBackoff
+		for attempt := 0; ; attempt++ {
+			err := sink.Write(ctx, batch)
+			if err == nil {
+				counters.shipped.Add(uint64(len(batch)))
+				break
+			}
+			if attempt >= s.cfg.MaxRetries || !sleep(ctx, backoff) {
+				log.Printf("Failed to ship %d records to SIEM sink %s: %v", len(batch), sink.Name(), err)
+				counters.failed.Add(uint64(len(batch)))
+				break
+			}
+			counters.retries.Add(1)
+			backoff *= 2
+		}
+	}
+}
+
+// sleep waits for d, reporting false when ctx ends first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordID returns a random record ID
+func recordID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,267 @@
+package siem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSink records the batches it takes, failing the first failures writes
+type fakeSink struct {
+	mu       sync.Mutex
+	batches  [][]*Record
+	failures int
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Write(_ context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, append([]*Record(nil), records...))
+	return nil
+}
+
+func (s *fakeSink) records() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestShipperBatchesAndDrainsOnStop(t *testing.T) {
+	sink := &fakeSink{}
+	shipper, err := NewShipper(Config{Source: "did-manager", BatchSize: 2, FlushInterval: time.Hour}, sink)
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+	if err := shipper.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		shipper.Ship(&Record{Category: CategoryEvent, Action: "did.created"})
+	}
+	if err := shipper.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if got := sink.records(); got != 5 {
+		t.Errorf("expected 5 records shipped, got %d", got)
+	}
+	for _, batch := range sink.batches {
+		if len(batch) > 2 {
+			t.Errorf("expected batches of at most 2 records, got %d", len(batch))
+		}
+	}
+	record := sink.batches[0][0]
+	if record.SchemaVersion != SchemaVersion || record.ID == "" || record.Source != "did-manager" || record.Timestamp.IsZero() {
+		t.Errorf("expected the shipper to fill in the record, got %+v", record)
+	}
+	if stats := shipper.Stats(); stats.Sinks[0].Shipped != 5 || stats.Queued != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestShipperDropsWhenFull(t *testing.T) {
+	shipper, err := NewShipper(Config{BufferSize: 2}, &fakeSink{})
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+
+	// Not started, so nothing drains the buffer
+	for i := 0; i < 3; i++ {
+		shipper.Ship(&Record{Category: CategoryAudit})
+	}
+	stats := shipper.Stats()
+	if stats.Queued != 2 || stats.Capacity != 2 || stats.Dropped != 1 {
+		t.Errorf("expected 2 queued and 1 dropped, got %+v", stats)
+	}
+}
+
+func TestShipperRetries(t *testing.T) {
+	sink := &fakeSink{failures: 1}
+	failing := &fakeSink{failures: 100}
+	shipper, err := NewShipper(Config{MaxRetries: 2, RetryBackoff: time.Millisecond}, sink, failing)
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+
+	shipper.flush(context.Background(), []*Record{{Category: CategoryAudit}})
+
+	stats := shipper.Stats()
+	if stats.Sinks[0].Shipped != 1 || stats.Sinks[0].Retries != 1 {
+		t.Errorf("expected the record shipped after a retry, got %+v", stats.Sinks[0])
+	}
+	if stats.Sinks[1].Failed != 1 || stats.Sinks[1].Retries != 2 {
+		t.Errorf("expected the record failed after 2 retries, got %+v", stats.Sinks[1])
+	}
+}
+
+func TestNewShipperNeedsSink(t *testing.T) {
+	if _, err := NewShipper(Config{}); err == nil {
+		t.Error("expected an error without sinks")
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lines []string
+	var auth, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, "Splunk token")
+	err := sink.Write(context.Background(), []*Record{{ID: "a"}, {ID: "b"}})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"id":"b"`) {
+		t.Errorf("expected one JSON record per line, got %q", lines)
+	}
+	if auth != "Splunk token" || contentType != "application/x-ndjson" {
+		t.Errorf("unexpected headers %q, %q", auth, contentType)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	var path string
+	var body struct {
+		Records []struct {
+			Key   string  `json:"key"`
+			Value *Record `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewKafkaSink(server.URL+"/", "siem-audit", "")
+	if err := sink.Write(context.Background(), []*Record{{ID: "a", Tenant: "tenant-1"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if path != "/topics/siem-audit" {
+		t.Errorf("expected the topic path, got %s", path)
+	}
+	if len(body.Records) != 1 || body.Records[0].Key != "tenant-1" || body.Records[0].Value.ID != "a" {
+		t.Errorf("unexpected body %+v", body)
+	}
+}
+
+func TestHTTPSinkFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := NewHTTPSink(server.URL, "").Write(context.Background(), []*Record{{}}); err == nil {
+		t.Error("expected an error for a failing collector")
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		length, _ := reader.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(length))
+		message := make([]byte, n)
+		_, _ = io.ReadFull(reader, message)
+		received <- string(message)
+	}()
+
+	sink, err := NewSyslogSink("tcp", listener.Addr().String(), "did-manager")
+	if err != nil {
+		t.Fatalf("NewSyslogSink failed: %v", err)
+	}
+	record := &Record{
+		ID:        "a",
+		Category:  CategorySecurity,
+		Severity:  SeverityWarning,
+		Timestamp: time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+	}
+	if err := sink.Write(context.Background(), []*Record{record}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	select {
+	case message := <-received:
+		if !strings.HasPrefix(message, "<108>1 2026-10-17T08:30:00Z ") {
+			t.Errorf("unexpected syslog header in %q", message)
+		}
+		if !strings.Contains(message, " did-manager ") || !strings.Contains(message, " security - {") {
+			t.Errorf("expected the app name, category and JSON record in %q", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a syslog message")
+	}
+}
+
+func TestNewSyslogSinkNetwork(t *testing.T) {
+	if _, err := NewSyslogSink("unix", "/dev/log", "did-manager"); err == nil {
+		t.Error("expected an error for an unsupported network")
+	}
+}
+
+func TestAuditWriter(t *testing.T) {
+	shipper, err := NewShipper(Config{}, &fakeSink{})
+	if err != nil {
+		t.Fatalf("NewShipper failed: %v", err)
+	}
+	var out bytes.Buffer
+	writer := AuditWriter(shipper, &out)
+
+	writer.Write([]byte("2026/10/17 08:30:00 AUDIT: API key 42 revoked\n"))
+	writer.Write([]byte("2026/10/17 08:30:01 Processing job 7\n"))
+
+	if !strings.Contains(out.String(), "Processing job 7") {
+		t.Error("expected every line passed on")
+	}
+	if shipper.Stats().Queued != 1 {
+		t.Fatalf("expected one audit record, got %d", shipper.Stats().Queued)
+	}
+	record := <-shipper.queue
+	if record.Category != CategoryAudit || record.Message != "API key 42 revoked" {
+		t.Errorf("unexpected audit record %+v", record)
+	}
+}
@@ -0,0 +1,211 @@
+// Package smt implements a 256-level sparse Merkle tree over keccak256, used as the
+// credential revocation accumulator anchored on-chain. Empty subtrees hash to zero,
+// so proofs only carry non-empty siblings and can be verified by the
+// RevocationRegistry contract.
+package smt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Depth is the number of levels between the root and the leaves
+const Depth = 256
+
+// Hash is a 32-byte tree key or node hash
+type Hash [32]byte
+
+// Empty is the value of absent leaves and the hash of empty subtrees
+var Empty Hash
+
+// Present is the leaf value stored for keys in the tree
+var Present = Hash{31: 1}
+
+// ErrInvalidProof is returned for proofs whose shape does not match their bitmap
+var ErrInvalidProof = errors.New("invalid sparse Merkle proof")
+
+// Key derives the tree key for an identifier
+func Key(id string) Hash {
+	return Hash(crypto.Keccak256Hash([]byte(id)))
+}
+
+// hashNode hashes two children; a node with two empty children is itself empty
+func hashNode(left, right Hash) Hash {
+	if left == Empty && right == Empty {
+		return Empty
+	}
+	return Hash(crypto.Keccak256Hash(left[:], right[:]))
+}
+
+// bit returns the bit of key at level, counted from the leaves (0) and taken
+// least-significant first, so the root branches on the most significant bit
+func bit(key Hash, level int) uint {
+	return uint(key[31-level/8]>>(level%8)) & 1
+}
+
+// Proof is a compressed Merkle path from a leaf to the root. Bit i of Bitmap is set
+// when the sibling at level i is non-empty; Siblings lists those siblings from the
+// leaf upwards.
+type Proof struct {
+	Bitmap   Hash   `json:"bitmap"`
+	Siblings []Hash `json:"siblings"`
+}
+
+// Tree is an immutable sparse Merkle tree over a set of keys
+type Tree struct {
+	keys []Hash
+	root Hash
+}
+
+// New builds a tree containing keys
+func New(keys []Hash) *Tree {
+	sorted := make([]Hash, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	// Drop duplicates so each key occupies a single leaf
+	unique := sorted[:0]
+	for i, key := range sorted {
+		if i == 0 || key != sorted[i-1] {
+			unique = append(unique, key)
+		}
+	}
+
+	t := &Tree{keys: unique}
+	t.root = subtree(Depth, t.keys)
+	return t
+}
+
+// Root returns the tree's root hash
+func (t *Tree) Root() Hash {
+	return t.root
+}
+
+// Len returns the number of keys in the tree
+func (t *Tree) Len() int {
+	return len(t.keys)
+}
+
+// Contains reports whether key is in the tree
+func (t *Tree) Contains(key Hash) bool {
+	i := sort.Search(len(t.keys), func(i int) bool {
+		return bytes.Compare(t.keys[i][:], key[:]) >= 0
+	})
+	return i < len(t.keys) && t.keys[i] == key
+}
+
+// Prove returns a proof of key's leaf: Present when the key is in the tree, Empty
+// (a non-membership proof) otherwise
+func (t *Tree) Prove(key Hash) *Proof {
+	proof := &Proof{}
+	siblings := make([]Hash, Depth)
+
+	keys := t.keys
+	for height := Depth; height > 0; height-- {
+		level := height - 1
+		left, right := split(keys, level)
+		if bit(key, level) == 0 {
+			siblings[level] = subtree(level, right)
+			keys = left
+		} else {
+			siblings[level] = subtree(level, left)
+			keys = right
+		}
+	}
+
+	for level, sibling := range siblings {
+		if sibling != Empty {
+			proof.Bitmap[31-level/8] |= 1 << (level % 8)
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+	return proof
+}
+
+// ComputeRoot recomputes the root implied by a proof for key holding leaf
+func ComputeRoot(key, leaf Hash, proof *Proof) (Hash, error) {
+	node := leaf
+	next := 0
+	for level := 0; level < Depth; level++ {
+		sibling := Empty
+		if bit(proof.Bitmap, level) == 1 {
+			if next >= len(proof.Siblings) {
+				return Empty, ErrInvalidProof
+			}
+			sibling = proof.Siblings[next]
+			next++
+		}
+
+		if bit(key, level) == 0 {
+			node = hashNode(node, sibling)
+		} else {
+			node = hashNode(sibling, node)
+		}
+	}
+
+	if next != len(proof.Siblings) {
+		return Empty, ErrInvalidProof
+	}
+	return node, nil
+}
+
+// Verify checks that proof shows key as present (or absent) under root
+func Verify(root, key Hash, present bool, proof *Proof) bool {
+	leaf := Empty
+	if present {
+		leaf = Present
+	}
+
+	computed, err := ComputeRoot(key, leaf, proof)
+	return err == nil && computed == root
+}
+
+// subtree computes the hash of the subtree of the given height holding sorted keys,
+// which all share the path above it
+func subtree(height int, keys []Hash) Hash {
+	if len(keys) == 0 {
+		return Empty
+	}
+	if height == 0 {
+		return Present
+	}
+
+	left, right := split(keys, height-1)
+	return hashNode(subtree(height-1, left), subtree(height-1, right))
+}
+
+// split partitions sorted keys sharing the path above level by their bit at level
+func split(keys []Hash, level int) (left, right []Hash) {
+	i := sort.Search(len(keys), func(i int) bool {
+		return bit(keys[i], level) == 1
+	})
+	return keys[:i], keys[i:]
+}
+
+// Hex returns the 0x-prefixed hex encoding of the hash
+func (h Hash) Hex() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// MarshalText encodes the hash as 0x-prefixed hex
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.Hex()), nil
+}
+
+// UnmarshalText decodes a 0x-prefixed hex hash
+func (h *Hash) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(decoded) != len(h) {
+		return fmt.Errorf("invalid hash: %q", text)
+	}
+	copy(h[:], decoded)
+	return nil
+}
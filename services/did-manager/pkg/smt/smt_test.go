@@ -0,0 +1,90 @@
+package smt
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func testKeys(n int) []Hash {
+	keys := make([]Hash, n)
+	for i := range keys {
+		keys[i] = Key(fmt.Sprintf("urn:uuid:%d", i))
+	}
+	return keys
+}
+
+func TestEmptyTreeRootIsEmpty(t *testing.T) {
+	if root := New(nil).Root(); root != Empty {
+		t.Errorf("expected empty root, got %s", root.Hex())
+	}
+}
+
+func TestMembershipAndNonMembershipProofs(t *testing.T) {
+	keys := testKeys(50)
+	tree := New(keys[:40])
+	root := tree.Root()
+
+	for _, key := range keys[:40] {
+		proof := tree.Prove(key)
+		if !Verify(root, key, true, proof) {
+			t.Fatalf("membership proof failed for %s", key.Hex())
+		}
+		if Verify(root, key, false, proof) {
+			t.Fatalf("non-membership accepted for present key %s", key.Hex())
+		}
+	}
+
+	for _, key := range keys[40:] {
+		proof := tree.Prove(key)
+		if !Verify(root, key, false, proof) {
+			t.Fatalf("non-membership proof failed for %s", key.Hex())
+		}
+		if Verify(root, key, true, proof) {
+			t.Fatalf("membership accepted for absent key %s", key.Hex())
+		}
+	}
+}
+
+func TestRootIsIndependentOfInsertionOrder(t *testing.T) {
+	keys := testKeys(20)
+	reversed := make([]Hash, len(keys))
+	for i, key := range keys {
+		reversed[len(keys)-1-i] = key
+	}
+
+	if New(keys).Root() != New(append(reversed, keys[0])).Root() {
+		t.Error("expected the same root regardless of order and duplicates")
+	}
+}
+
+func TestProofDoesNotVerifyAgainstOtherRoot(t *testing.T) {
+	keys := testKeys(10)
+	before := New(keys[:5])
+	after := New(keys[:6])
+
+	// Once keys[5] is revoked, its old non-membership proof no longer matches the root
+	proof := before.Prove(keys[5])
+	if Verify(after.Root(), keys[5], false, proof) {
+		t.Error("stale non-membership proof verified against new root")
+	}
+}
+
+func TestProofJSONRoundTrip(t *testing.T) {
+	tree := New(testKeys(8))
+	key := Key("urn:uuid:absent")
+
+	data, err := json.Marshal(tree.Prove(key))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var proof Proof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !Verify(tree.Root(), key, false, &proof) {
+		t.Error("decoded proof failed to verify")
+	}
+}
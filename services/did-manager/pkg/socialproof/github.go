@@ -0,0 +1,113 @@
+package socialproof
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GitHubAPIURL is the base URL of the GitHub REST API
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubGists fetches gists, published at https://gist.github.com/{user}/{id}
+type GitHubGists struct {
+	apiURL     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitHubGists creates a gist fetcher. token is optional; authenticated requests
+// get a higher rate limit.
+func NewGitHubGists(apiURL, token string) *GitHubGists {
+	if apiURL == "" {
+		apiURL = GitHubAPIURL
+	}
+	return &GitHubGists{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Platform names the platform
+func (g *GitHubGists) Platform() string {
+	return "github"
+}
+
+// Fetch returns a gist with the contents of its files joined in file name order
+func (g *GitHubGists) Fetch(ctx context.Context, postURL string) (*Post, error) {
+	id, err := gistID(postURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.apiURL+"/gists/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gist request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch gist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: gist %s", ErrPostNotFound, id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned status %d", resp.StatusCode)
+	}
+
+	var gist struct {
+		Public bool `json:"public"`
+		Owner  *struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Files map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return nil, fmt.Errorf("failed to decode gist: %w", err)
+	}
+	// Anonymous gists have no owner to link
+	if gist.Owner == nil || !gist.Public {
+		return nil, fmt.Errorf("%w: gist %s is not a public gist of an account", ErrPostNotFound, id)
+	}
+
+	names := make([]string, 0, len(gist.Files))
+	for name := range gist.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	contents := make([]string, len(names))
+	for i, name := range names {
+		contents[i] = gist.Files[name].Content
+	}
+
+	return &Post{Author: gist.Owner.Login, Content: strings.Join(contents, "\n")}, nil
+}
+
+// gistID extracts the gist ID from a gist URL
+func gistID(postURL string) (string, error) {
+	parsed, err := url.Parse(postURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host != "gist.github.com" {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, postURL)
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	id := segments[len(segments)-1]
+	if len(segments) > 2 || id == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, postURL)
+	}
+	return id, nil
+}
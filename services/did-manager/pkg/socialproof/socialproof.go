@@ -0,0 +1,32 @@
+// Package socialproof fetches public posts from social platforms, such as GitHub gists
+// and tweets, in which users prove they control an account
+package socialproof
+
+import (
+	"context"
+	"errors"
+)
+
+// Fetch errors
+var (
+	// ErrUnsupportedURL is returned for a URL that is not a post of the platform
+	ErrUnsupportedURL = errors.New("URL is not a post of this platform")
+	// ErrPostNotFound is returned when the post does not exist or is not public
+	ErrPostNotFound = errors.New("post not found")
+)
+
+// Post is a public post and the account that published it
+type Post struct {
+	// Author is the handle of the account, as the platform spells it
+	Author  string
+	Content string
+}
+
+// Fetcher fetches the posts of one platform
+type Fetcher interface {
+	// Platform names the platform, e.g. "github"
+	Platform() string
+	// Fetch returns the post at postURL, wrapping ErrUnsupportedURL or ErrPostNotFound
+	// when it is not a public post of the platform
+	Fetch(ctx context.Context, postURL string) (*Post, error)
+}
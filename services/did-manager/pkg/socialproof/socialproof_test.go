@@ -0,0 +1,95 @@
+package socialproof
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubGistsFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gists/abc123":
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("unexpected authorization %q", got)
+			}
+			w.Write([]byte(`{"public": true, "owner": {"login": "Octocat"}, "files": {
+				"b.txt": {"content": "second"},
+				"a.txt": {"content": "first"}
+			}}`))
+		case "/gists/anonymous":
+			w.Write([]byte(`{"public": true, "owner": null, "files": {}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gists := NewGitHubGists(server.URL, "token")
+	post, err := gists.Fetch(context.Background(), "https://gist.github.com/octocat/abc123")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if post.Author != "Octocat" || post.Content != "first\nsecond" {
+		t.Errorf("unexpected post %+v", post)
+	}
+
+	if _, err := gists.Fetch(context.Background(), "https://gist.github.com/anonymous"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected anonymous gist to be rejected, got %v", err)
+	}
+	if _, err := gists.Fetch(context.Background(), "https://gist.github.com/octocat/missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected missing gist to be not found, got %v", err)
+	}
+	for _, postURL := range []string{
+		"http://gist.github.com/octocat/abc123",
+		"https://github.com/octocat/abc123",
+		"https://gist.github.com/octocat/abc123/raw",
+	} {
+		if _, err := gists.Fetch(context.Background(), postURL); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("expected %s to be unsupported, got %v", postURL, err)
+		}
+	}
+}
+
+func TestTwitterFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer bearer" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		switch r.URL.Path {
+		case "/2/tweets/1234":
+			w.Write([]byte(`{"data": {"id": "1234", "text": "proof", "author_id": "42"},
+				"includes": {"users": [{"id": "42", "username": "jack"}]}}`))
+		default:
+			w.Write([]byte(`{"errors": [{"title": "Not Found Error"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	twitter, err := NewTwitter(server.URL, "bearer")
+	if err != nil {
+		t.Fatalf("NewTwitter failed: %v", err)
+	}
+
+	for _, postURL := range []string{"https://x.com/jack/status/1234", "https://twitter.com/jack/status/1234"} {
+		post, err := twitter.Fetch(context.Background(), postURL)
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		if post.Author != "jack" || post.Content != "proof" {
+			t.Errorf("unexpected post %+v", post)
+		}
+	}
+
+	if _, err := twitter.Fetch(context.Background(), "https://x.com/jack/status/999"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected deleted tweet to be not found, got %v", err)
+	}
+	if _, err := twitter.Fetch(context.Background(), "https://x.com/jack"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("expected profile URL to be unsupported, got %v", err)
+	}
+	if _, err := NewTwitter("", ""); err == nil {
+		t.Error("expected a missing bearer token to be rejected")
+	}
+}
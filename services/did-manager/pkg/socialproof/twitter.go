@@ -0,0 +1,127 @@
+package socialproof
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TwitterAPIURL is the base URL of the X (Twitter) API
+const TwitterAPIURL = "https://api.twitter.com"
+
+// Twitter fetches tweets, published at https://x.com/{user}/status/{id} or the same
+// path on twitter.com
+type Twitter struct {
+	apiURL      string
+	bearerToken string
+	httpClient  *http.Client
+}
+
+// NewTwitter creates a tweet fetcher authenticating to the API with an app's bearer
+// token
+func NewTwitter(apiURL, bearerToken string) (*Twitter, error) {
+	if bearerToken == "" {
+		return nil, fmt.Errorf("invalid Twitter configuration: bearer token is required")
+	}
+	if apiURL == "" {
+		apiURL = TwitterAPIURL
+	}
+	return &Twitter{
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		bearerToken: bearerToken,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Platform names the platform
+func (t *Twitter) Platform() string {
+	return "twitter"
+}
+
+// Fetch returns a tweet with the handle of its author
+func (t *Twitter) Fetch(ctx context.Context, postURL string) (*Post, error) {
+	id, err := tweetID(postURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"expansions": {"author_id"}, "user.fields": {"username"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.apiURL+"/2/tweets/"+id+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tweet request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.bearerToken)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tweet: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Twitter returned status %d", resp.StatusCode)
+	}
+
+	// Deleted and protected tweets come back as errors with status 200
+	var tweet struct {
+		Data *struct {
+			Text     string `json:"text"`
+			AuthorID string `json:"author_id"`
+		} `json:"data"`
+		Includes struct {
+			Users []struct {
+				ID       string `json:"id"`
+				Username string `json:"username"`
+			} `json:"users"`
+		} `json:"includes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tweet); err != nil {
+		return nil, fmt.Errorf("failed to decode tweet: %w", err)
+	}
+	if tweet.Data == nil {
+		return nil, fmt.Errorf("%w: tweet %s", ErrPostNotFound, id)
+	}
+
+	for _, user := range tweet.Includes.Users {
+		if user.ID == tweet.Data.AuthorID {
+			return &Post{Author: user.Username, Content: tweet.Data.Text}, nil
+		}
+	}
+	return nil, fmt.Errorf("tweet %s has no author", id)
+}
+
+// tweetID extracts the tweet ID from a tweet URL
+func tweetID(postURL string) (string, error) {
+	parsed, err := url.Parse(postURL)
+	if err != nil || parsed.Scheme != "https" {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, postURL)
+	}
+	switch strings.TrimPrefix(parsed.Host, "www.") {
+	case "x.com", "twitter.com", "mobile.twitter.com":
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, postURL)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) != 3 || segments[1] != "status" || !isDigits(segments[2]) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, postURL)
+	}
+	return segments[2], nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	gcsScope        = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsBaseURL      = "https://storage.googleapis.com"
+	googleTokenURI  = "https://oauth2.googleapis.com/token"
+	tokenRefreshGap = time.Minute
+)
+
+// serviceAccount holds the fields of a Google service account key file used by GCS
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// GCSStore stores objects in a Google Cloud Storage bucket with the JSON API,
+// authenticating with a service account
+type GCSStore struct {
+	bucket     string
+	account    serviceAccount
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewGCSStore creates a GCS store from a service account key file's contents
+func NewGCSStore(bucket string, credentialsJSON []byte) (*GCSStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid GCS configuration: bucket is required")
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(credentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("invalid GCS credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("invalid GCS credentials: client_email and private_key are required")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = googleTokenURI
+	}
+
+	return &GCSStore{
+		bucket:     bucket,
+		account:    account,
+		baseURL:    gcsBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Put uploads an object
+func (s *GCSStore) Put(ctx context.Context, name string, data []byte) error {
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		s.baseURL, url.PathEscape(s.bucket), url.QueryEscape(name))
+	resp, err := s.do(ctx, http.MethodPost, endpoint, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return gcsError(resp, "upload", name)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *GCSStore) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(name)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, gcsError(resp, "download", name)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCS object %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Delete removes an object
+func (s *GCSStore) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return gcsError(resp, "delete", name)
+	}
+	return nil
+}
+
+// objectURL returns the JSON API URL of an object's metadata
+func (s *GCSStore) objectURL(name string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", s.baseURL, url.PathEscape(s.bucket), url.PathEscape(name))
+}
+
+// do sends an authenticated request
+func (s *GCSStore) do(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GCS request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// token returns a cached OAuth access token, exchanging a signed service account
+// assertion for a new one when it is about to expire
+func (s *GCSStore) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Add(tokenRefreshGap).Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(s.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid GCS private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": gcsScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GCS assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GCS access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GCS token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode GCS access token: %w", err)
+	}
+
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+// gcsError describes a failed GCS request with the start of its error response
+func gcsError(resp *http.Response, operation, name string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("failed to %s GCS object %s: status %d: %s", operation, name, resp.StatusCode, body)
+}
@@ -0,0 +1,122 @@
+// Package vault keeps documents encrypted in an object store. Objects are encrypted
+// with AES-256-GCM before they leave the service, so the storage provider only ever
+// holds ciphertext.
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// keySize is the size of AES-256 keys
+const keySize = 32
+
+var (
+	// ErrNotFound is returned for objects the store does not hold
+	ErrNotFound = errors.New("object not found")
+	// ErrDecryption is returned for objects that do not decrypt under their key,
+	// e.g. because they were altered in the store
+	ErrDecryption = errors.New("object failed to decrypt")
+)
+
+// Store is an object storage provider, such as an S3 or GCS bucket
+type Store interface {
+	// Put stores an object, replacing any object of the same name
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns an object, or ErrNotFound
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Delete removes an object; deleting a missing object succeeds
+	Delete(ctx context.Context, name string) error
+}
+
+// KeyProvider supplies the symmetric keys objects are encrypted with. A queue.Keyring
+// loaded from the environment satisfies it.
+type KeyProvider interface {
+	// CurrentKey returns the key new objects are encrypted with
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns a key by ID, for objects encrypted before a rotation
+	Key(id string) ([]byte, error)
+}
+
+// Vault encrypts objects into a store and decrypts them on the way out
+type Vault struct {
+	store Store
+	keys  KeyProvider
+}
+
+// New creates a vault over a store
+func New(store Store, keys KeyProvider) *Vault {
+	return &Vault{store: store, keys: keys}
+}
+
+// Put encrypts data with the current key and stores it under name, returning the ID
+// of the key to decrypt it with. The name is authenticated with the ciphertext, so an
+// object cannot be swapped for another in the store.
+func (v *Vault) Put(ctx context.Context, name string, data []byte) (string, error) {
+	id, key, err := v.keys.CurrentKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	if err := v.store.Put(ctx, name, aead.Seal(nonce, nonce, data, []byte(name))); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Get loads the object stored under name and decrypts it with the given key
+func (v *Vault) Get(ctx context.Context, name, keyID string) ([]byte, error) {
+	key, err := v.keys.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := v.store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: object too short", ErrDecryption)
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return plaintext, nil
+}
+
+// Delete removes the object stored under name
+func (v *Vault) Delete(ctx context.Context, name string) error {
+	return v.store.Delete(ctx, name)
+}
+
+// newAEAD creates the AES-256-GCM cipher for a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Put(_ context.Context, name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, name)
+	return nil
+}
+
+// testKeys is a KeyProvider over fixed keys
+type testKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (k *testKeys) CurrentKey() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *testKeys) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", id)
+	}
+	return key, nil
+}
+
+func newTestKeys() *testKeys {
+	return &testKeys{
+		current: "k1",
+		keys: map[string][]byte{
+			"k1": bytes.Repeat([]byte{1}, keySize),
+			"k2": bytes.Repeat([]byte{2}, keySize),
+		},
+	}
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	store := newMemoryStore()
+	keys := newTestKeys()
+	v := New(store, keys)
+	ctx := context.Background()
+	document := []byte("%PDF-1.4 diploma")
+
+	keyID, err := v.Put(ctx, "documents/a", document)
+	if err != nil {
+		t.Fatalf("failed to put object: %v", err)
+	}
+	if keyID != "k1" {
+		t.Errorf("expected current key k1, got %s", keyID)
+	}
+	if bytes.Contains(store.objects["documents/a"], document) {
+		t.Error("expected the stored object to be encrypted")
+	}
+
+	// Objects encrypted before a rotation still decrypt with their key
+	keys.current = "k2"
+	got, err := v.Get(ctx, "documents/a", keyID)
+	if err != nil {
+		t.Fatalf("failed to get object: %v", err)
+	}
+	if !bytes.Equal(got, document) {
+		t.Errorf("expected %q, got %q", document, got)
+	}
+
+	if err := v.Delete(ctx, "documents/a"); err != nil {
+		t.Fatalf("failed to delete object: %v", err)
+	}
+	if _, err := v.Get(ctx, "documents/a", keyID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleted object to be missing, got %v", err)
+	}
+}
+
+func TestVaultRejectsTamperedObjects(t *testing.T) {
+	store := newMemoryStore()
+	v := New(store, newTestKeys())
+	ctx := context.Background()
+
+	keyID, err := v.Put(ctx, "documents/a", []byte("first"))
+	if err != nil {
+		t.Fatalf("failed to put object: %v", err)
+	}
+	if _, err := v.Put(ctx, "documents/b", []byte("second")); err != nil {
+		t.Fatalf("failed to put object: %v", err)
+	}
+
+	// An object moved under another name does not decrypt
+	store.objects["documents/a"] = store.objects["documents/b"]
+	if _, err := v.Get(ctx, "documents/a", keyID); !errors.Is(err, ErrDecryption) {
+		t.Errorf("expected swapped object to fail, got %v", err)
+	}
+
+	store.objects["documents/b"][len(store.objects["documents/b"])-1] ^= 1
+	if _, err := v.Get(ctx, "documents/b", keyID); !errors.Is(err, ErrDecryption) {
+		t.Errorf("expected altered object to fail, got %v", err)
+	}
+}
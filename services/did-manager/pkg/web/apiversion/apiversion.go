@@ -0,0 +1,327 @@
+// Package apiversion serves several versions of an API side by side under their own
+// path prefixes, such as /api/v1 and /api/v2. A version can be based on an earlier
+// one: it serves its own routes and the routes of its base it does not replace, with
+// requests and responses mapped between the two, so only the routes that change need
+// a handler of their own. Deprecated versions announce their deprecation, sunset and
+// successor in the response headers of RFC 9745 and RFC 8594.
+package apiversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"did-manager/pkg/web"
+)
+
+// Response headers set on every versioned request
+const (
+	// Header names the version that served the request
+	Header            = "API-Version"
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// contextKey keys the version of a request in its context
+const contextKey = "api_version"
+
+// RequestMapper maps the JSON object body of a request in a version to the body the
+// handlers of its base expect
+type RequestMapper func(body map[string]any) (map[string]any, error)
+
+// ResponseMapper maps a JSON response written by a handler to the body the version
+// answers with
+type ResponseMapper func(status int, body any) any
+
+// Version is a version of the API
+type Version struct {
+	// Name identifies the version in the API-Version header, e.g. v2
+	Name string
+	// Prefix is the path prefix of the version's routes, e.g. /api/v2
+	Prefix string
+	// Base is the version whose routes this version serves where it has none of its
+	// own; nil for the first version
+	Base *Version
+	// Request and Response map the requests and responses of every route of the
+	// version, including the middleware's; nil leaves them unchanged
+	Request  RequestMapper
+	Response ResponseMapper
+	// Deprecation is when the version was or will be deprecated, and Sunset when it
+	// stops being served; zero when not planned
+	Deprecation time.Time
+	Sunset      time.Time
+	// Successor is the version clients should move to, linked from deprecated responses
+	Successor *Version
+}
+
+// FromContext returns the version of a request, or nil outside the versioned API
+func FromContext(c web.Context) *Version {
+	if value, ok := c.Get(contextKey); ok {
+		if version, ok := value.(*Version); ok {
+			return version
+		}
+	}
+	return nil
+}
+
+// Canonical returns the path a route of the version was written as: routes under its
+// prefix have the prefix of the first version it is based on, so route tables keyed by
+// the original paths cover every version. Other paths, and any path of a nil version,
+// are returned unchanged.
+func (v *Version) Canonical(path string) string {
+	if v == nil || v.Base == nil {
+		return path
+	}
+	rest, ok := v.trim(path)
+	if !ok {
+		return path
+	}
+	return v.Base.Canonical(v.Base.Prefix + rest)
+}
+
+// trim returns the path below the version's prefix, reporting whether the path is
+// under it
+func (v *Version) trim(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, v.Prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	return rest, true
+}
+
+// mapped reports whether the version maps requests or responses
+func (v *Version) mapped() bool {
+	return v.Request != nil || v.Response != nil
+}
+
+// route is a route registered through an API, kept to be served by later versions
+type route struct {
+	method   string
+	path     string
+	handlers []web.HandlerFunc
+}
+
+// API registers routes with a router for several versions. It is a web.Router itself,
+// so handlers register their routes with it as with any router; once every route is
+// registered, Mirror serves the routes of each version's base the version lacks.
+type API struct {
+	router   web.Router
+	versions []*Version
+	routes   []route
+	served   map[string]bool
+}
+
+// NewAPI returns an API registering routes with router for the given versions. It adds
+// the middleware setting the version headers to router, so it should come before the
+// other middleware.
+func NewAPI(router web.Router, versions ...*Version) *API {
+	a := &API{
+		router:   router,
+		versions: versions,
+		served:   make(map[string]bool),
+	}
+	router.Use(a.headers)
+	return a
+}
+
+// version returns the version a request path is under, or nil
+func (a *API) version(path string) *Version {
+	for _, v := range a.versions {
+		if _, ok := v.trim(path); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// headers stores the version of the request and sets its response headers
+func (a *API) headers(c web.Context) {
+	v := a.version(c.Request().URL.Path)
+	if v == nil {
+		c.Next()
+		return
+	}
+
+	c.Set(contextKey, v)
+	c.Header(Header, v.Name)
+	if !v.Deprecation.IsZero() {
+		c.Header(DeprecationHeader, fmt.Sprintf("@%d", v.Deprecation.Unix()))
+		if v.Successor != nil {
+			rest, _ := v.trim(c.Request().URL.Path)
+			c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, v.Successor.Prefix, rest))
+		}
+	}
+	if !v.Sunset.IsZero() {
+		c.Header(SunsetHeader, v.Sunset.UTC().Format(http.TimeFormat))
+	}
+	c.Next()
+}
+
+// wrap maps the requests and responses of a handler by the version of each request
+func (a *API) wrap(handler web.HandlerFunc) web.HandlerFunc {
+	return func(c web.Context) {
+		if v := a.version(c.Request().URL.Path); v != nil && v.mapped() {
+			handler(&context{Context: c, version: v})
+			return
+		}
+		handler(c)
+	}
+}
+
+func (a *API) wrapAll(handlers []web.HandlerFunc) []web.HandlerFunc {
+	wrapped := make([]web.HandlerFunc, len(handlers))
+	for i, handler := range handlers {
+		wrapped[i] = a.wrap(handler)
+	}
+	return wrapped
+}
+
+// handle registers a route, keeping it for the versions based on its own
+func (a *API) handle(method, path string, handlers []web.HandlerFunc) {
+	a.routes = append(a.routes, route{method: method, path: path, handlers: handlers})
+	a.served[method+" "+path] = true
+	a.router.Handle(method, path, a.wrapAll(handlers)...)
+}
+
+// Mirror serves, under each version with a base, the routes of its base it does not
+// serve itself. It is called once every route is registered, and serves versions in
+// the order given to NewAPI, so a version also gets the routes its base mirrored.
+func (a *API) Mirror() {
+	for _, v := range a.versions {
+		if v.Base == nil {
+			continue
+		}
+		for _, r := range a.routes {
+			rest, ok := v.Base.trim(r.path)
+			if !ok || a.served[r.method+" "+v.Prefix+rest] {
+				continue
+			}
+			a.handle(r.method, v.Prefix+rest, r.handlers)
+		}
+	}
+}
+
+// Use adds middleware to the routes of every version
+func (a *API) Use(middleware ...web.HandlerFunc) {
+	a.router.Use(a.wrapAll(middleware)...)
+}
+
+// Group returns a router for routes under prefix, behind the given middleware
+func (a *API) Group(prefix string, middleware ...web.HandlerFunc) web.Router {
+	return &group{api: a, prefix: prefix, middleware: middleware}
+}
+
+// Handle registers a route for a method
+func (a *API) Handle(method, path string, handlers ...web.HandlerFunc) {
+	a.handle(method, path, handlers)
+}
+
+func (a *API) GET(path string, handlers ...web.HandlerFunc) {
+	a.Handle(http.MethodGet, path, handlers...)
+}
+
+func (a *API) POST(path string, handlers ...web.HandlerFunc) {
+	a.Handle(http.MethodPost, path, handlers...)
+}
+
+func (a *API) PUT(path string, handlers ...web.HandlerFunc) {
+	a.Handle(http.MethodPut, path, handlers...)
+}
+
+func (a *API) DELETE(path string, handlers ...web.HandlerFunc) {
+	a.Handle(http.MethodDelete, path, handlers...)
+}
+
+// group registers routes under a prefix through an API, with the group's middleware
+// ahead of each route's handlers, so the API sees every route's full path
+type group struct {
+	api        *API
+	prefix     string
+	middleware []web.HandlerFunc
+}
+
+func (g *group) Use(middleware ...web.HandlerFunc) {
+	g.middleware = append(g.middleware, middleware...)
+}
+
+func (g *group) Group(prefix string, middleware ...web.HandlerFunc) web.Router {
+	return &group{
+		api:        g.api,
+		prefix:     g.prefix + prefix,
+		middleware: append(append([]web.HandlerFunc(nil), g.middleware...), middleware...),
+	}
+}
+
+func (g *group) Handle(method, path string, handlers ...web.HandlerFunc) {
+	chain := append(append([]web.HandlerFunc(nil), g.middleware...), handlers...)
+	g.api.handle(method, g.prefix+path, chain)
+}
+
+func (g *group) GET(path string, handlers ...web.HandlerFunc) {
+	g.Handle(http.MethodGet, path, handlers...)
+}
+
+func (g *group) POST(path string, handlers ...web.HandlerFunc) {
+	g.Handle(http.MethodPost, path, handlers...)
+}
+
+func (g *group) PUT(path string, handlers ...web.HandlerFunc) {
+	g.Handle(http.MethodPut, path, handlers...)
+}
+
+func (g *group) DELETE(path string, handlers ...web.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handlers...)
+}
+
+// context maps the JSON requests and responses of a version
+type context struct {
+	web.Context
+	version *Version
+}
+
+// ShouldBindJSON maps a JSON object body before binding it
+func (c *context) ShouldBindJSON(obj any) error {
+	if c.version.Request == nil {
+		return c.Context.ShouldBindJSON(obj)
+	}
+
+	req := c.Request()
+	raw, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var body map[string]any
+	if decoder.Decode(&body) == nil && body != nil {
+		mapped, err := c.version.Request(body)
+		if err != nil {
+			return err
+		}
+		if raw, err = json.Marshal(mapped); err != nil {
+			return err
+		}
+	}
+	// Bodies other than JSON objects are left for the handler's binding to reject
+	req.Body = io.NopCloser(bytes.NewReader(raw))
+	return c.Context.ShouldBindJSON(obj)
+}
+
+func (c *context) JSON(code int, obj any) {
+	c.Context.JSON(code, c.response(code, obj))
+}
+
+func (c *context) AbortWithStatusJSON(code int, obj any) {
+	c.Context.AbortWithStatusJSON(code, c.response(code, obj))
+}
+
+func (c *context) response(code int, obj any) any {
+	if c.version.Response == nil {
+		return obj
+	}
+	return c.version.Response(code, obj)
+}
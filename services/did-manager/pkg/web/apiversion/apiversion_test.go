@@ -0,0 +1,195 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"did-manager/pkg/web"
+	"did-manager/pkg/web/stdweb"
+)
+
+// versions returns a deprecated v1 and a v2 based on it, wrapping v1's responses and
+// renaming the "name" of v1 requests to "display_name"
+func versions() (v1, v2 *Version) {
+	v1 = &Version{
+		Name:        "v1",
+		Prefix:      "/api/v1",
+		Deprecation: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	v2 = &Version{
+		Name:   "v2",
+		Prefix: "/api/v2",
+		Base:   v1,
+		Request: func(body map[string]any) (map[string]any, error) {
+			body["name"] = body["display_name"]
+			delete(body, "display_name")
+			return body, nil
+		},
+		Response: func(status int, body any) any {
+			return web.H{"status": status, "v2": body}
+		},
+	}
+	v1.Successor = v2
+	return v1, v2
+}
+
+// newAPI serves a v1 API with a guarded group, a route v2 replaces and a route outside
+// the versions
+func newAPI() *stdweb.Router {
+	router := stdweb.New()
+	v1, v2 := versions()
+	api := NewAPI(router, v1, v2)
+	api.Use(func(c web.Context) {
+		if c.GetHeader("X-Blocked") != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, web.H{"error": "blocked"})
+			return
+		}
+		c.Next()
+	})
+
+	group := api.Group("/api/v1", func(c web.Context) {
+		c.Set("group", true)
+		c.Next()
+	})
+	group.GET("/things/:id", func(c web.Context) {
+		c.JSON(http.StatusOK, web.H{
+			"id":    c.Param("id"),
+			"group": c.GetBool("group"),
+			"route": FromContext(c).Canonical(c.FullPath()),
+		})
+	})
+	group.POST("/things", func(c web.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, web.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, web.H{"name": req.Name})
+	})
+	api.GET("/api/v1/replaced", func(c web.Context) {
+		c.JSON(http.StatusOK, web.H{"version": "v1"})
+	})
+	api.GET("/api/v2/replaced", func(c web.Context) {
+		c.JSON(http.StatusOK, web.H{"version": "v2"})
+	})
+	api.GET("/health", func(c web.Context) {
+		c.JSON(http.StatusOK, web.H{"status": "ok"})
+	})
+	api.Mirror()
+	return router
+}
+
+func serve(router *stdweb.Router, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMirroredRoutes(t *testing.T) {
+	router := newAPI()
+
+	w := serve(router, http.MethodGet, "/api/v1/things/42", "")
+	if want := `{"group":true,"id":"42","route":"/api/v1/things/:id"}`; w.Body.String() != want {
+		t.Errorf("expected %s from v1, got %s", want, w.Body.String())
+	}
+
+	w = serve(router, http.MethodGet, "/api/v2/things/42", "")
+	if want := `{"status":200,"v2":{"group":true,"id":"42","route":"/api/v1/things/:id"}}`; w.Body.String() != want {
+		t.Errorf("expected %s from v2, got %s", want, w.Body.String())
+	}
+	if w.Header().Get(Header) != "v2" || w.Header().Get(DeprecationHeader) != "" {
+		t.Errorf("unexpected v2 headers %v", w.Header())
+	}
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	w := serve(newAPI(), http.MethodGet, "/api/v1/things/42", "")
+
+	if w.Header().Get(Header) != "v1" {
+		t.Errorf("expected the v1 version header, got %q", w.Header().Get(Header))
+	}
+	if got := w.Header().Get(DeprecationHeader); got != "@1790812800" {
+		t.Errorf("unexpected Deprecation header %q", got)
+	}
+	if got := w.Header().Get(SunsetHeader); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Errorf("unexpected Sunset header %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `</api/v2/things/42>; rel="successor-version"` {
+		t.Errorf("unexpected Link header %q", got)
+	}
+}
+
+func TestVersionReplacesRoute(t *testing.T) {
+	router := newAPI()
+	if w := serve(router, http.MethodGet, "/api/v1/replaced", ""); w.Body.String() != `{"version":"v1"}` {
+		t.Errorf("expected the v1 route, got %s", w.Body.String())
+	}
+	if w := serve(router, http.MethodGet, "/api/v2/replaced", ""); !strings.Contains(w.Body.String(), `"version":"v2"`) {
+		t.Errorf("expected the v2 route, got %s", w.Body.String())
+	}
+}
+
+func TestRequestMapping(t *testing.T) {
+	router := newAPI()
+
+	w := serve(router, http.MethodPost, "/api/v2/things", `{"display_name":"Alice"}`)
+	if want := `{"status":201,"v2":{"name":"Alice"}}`; w.Body.String() != want {
+		t.Errorf("expected %s, got %s", want, w.Body.String())
+	}
+	w = serve(router, http.MethodPost, "/api/v1/things", `{"name":"Alice"}`)
+	if want := `{"name":"Alice"}`; w.Body.String() != want {
+		t.Errorf("expected v1 requests unmapped, got %s", w.Body.String())
+	}
+}
+
+func TestMiddlewareResponsesMapped(t *testing.T) {
+	w := serve(newAPI(), http.MethodGet, "/api/v2/things/42", "", "X-Blocked", "1")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+	if want := `{"status":403,"v2":{"error":"blocked"}}`; w.Body.String() != want {
+		t.Errorf("expected %s, got %s", want, w.Body.String())
+	}
+}
+
+func TestUnversionedRoutes(t *testing.T) {
+	router := newAPI()
+	w := serve(router, http.MethodGet, "/health", "")
+	if w.Body.String() != `{"status":"ok"}` || w.Header().Get(Header) != "" {
+		t.Errorf("expected the route unversioned, got %s with %v", w.Body.String(), w.Header())
+	}
+	if w := serve(router, http.MethodGet, "/api/v2/health", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected routes outside v1 not mirrored, got %d", w.Code)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	v1, v2 := versions()
+	v3 := &Version{Name: "v3", Prefix: "/api/v3", Base: v2}
+	tests := []struct {
+		version *Version
+		path    string
+		want    string
+	}{
+		{v2, "/api/v2/things/:id", "/api/v1/things/:id"},
+		{v3, "/api/v3/things/:id", "/api/v1/things/:id"},
+		{v2, "/api/v20/things", "/api/v20/things"},
+		{v1, "/api/v1/things", "/api/v1/things"},
+		{nil, "/metrics", "/metrics"},
+	}
+	for _, tt := range tests {
+		if got := tt.version.Canonical(tt.path); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package compress compresses HTTP responses with a content coding the client accepts.
+// It wraps any http.Handler, so it works the same in front of Gin and the stdweb
+// router. Only text and JSON responses above a minimum size are compressed; files such
+// as vault documents and event streams are passed through.
+package compress
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder compresses a response body
+type Encoder interface {
+	io.WriteCloser
+	// Flush writes buffered compressed data to the underlying writer
+	Flush() error
+}
+
+// EncoderFunc creates an encoder writing to w
+type EncoderFunc func(w io.Writer) Encoder
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFunc{
+		"gzip": newGzipEncoder,
+	}
+)
+
+// Register adds a content coding, such as br with a Brotli implementation, that
+// compressors can be configured with. gzip is built in.
+func Register(name string, newEncoder EncoderFunc) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(name)] = newEncoder
+}
+
+// gzipWriters pools gzip writers, which are costly to allocate
+var gzipWriters sync.Pool
+
+// gzipEncoder returns its writer to the pool when closed
+type gzipEncoder struct {
+	*gzip.Writer
+}
+
+func newGzipEncoder(w io.Writer) Encoder {
+	if writer, ok := gzipWriters.Get().(*gzip.Writer); ok {
+		writer.Reset(w)
+		return gzipEncoder{writer}
+	}
+	return gzipEncoder{gzip.NewWriter(w)}
+}
+
+func (e gzipEncoder) Close() error {
+	err := e.Writer.Close()
+	gzipWriters.Put(e.Writer)
+	return err
+}
+
+// Compressor compresses the responses of a handler
+type Compressor struct {
+	// encodings lists the offered content codings in order of preference
+	encodings  []string
+	newEncoder map[string]EncoderFunc
+	// minSize is the smallest body compressed; smaller bodies gain too little
+	minSize int
+}
+
+// New creates a compressor offering the given content codings, in order of preference,
+// for bodies of at least minSize bytes
+func New(encodings []string, minSize int) (*Compressor, error) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	c := &Compressor{newEncoder: make(map[string]EncoderFunc), minSize: minSize}
+	for _, name := range encodings {
+		name = strings.ToLower(strings.TrimSpace(name))
+		newEncoder, ok := encoders[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported content coding %q", name)
+		}
+		c.encodings = append(c.encodings, name)
+		c.newEncoder[name] = newEncoder
+	}
+	return c, nil
+}
+
+// Handler compresses the responses of next
+func (c *Compressor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := c.negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &responseWriter{ResponseWriter: w, compressor: c, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiate picks the offered coding with the highest quality in an Accept-Encoding
+// header, preferring the compressor's order on ties; empty when none is acceptable
+func (c *Compressor) negotiate(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, name := range c.encodings {
+		quality, ok := accepted[name]
+		if !ok {
+			quality, ok = accepted["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// compressible reports whether responses of a content type are worth compressing
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		// Streamed events are flushed one by one
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/xml", mediaType == "image/svg+xml":
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		// Covers application/did+json, application/did+ld+json and application/ld+json
+		return true
+	}
+	return false
+}
+
+// responseWriter buffers the start of a response until it knows whether to compress it
+type responseWriter struct {
+	http.ResponseWriter
+	compressor *Compressor
+	encoding   string
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder Encoder
+}
+
+// WriteHeader records the status; it is sent once the body is large enough to decide
+// on compression
+func (w *responseWriter) WriteHeader(code int) {
+	if w.decided {
+		return
+	}
+	w.status = code
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.compressor.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends what was written so far, compressed when it is large enough
+func (w *responseWriter) Flush() {
+	if !w.decided {
+		w.decide(len(w.buf) >= w.compressor.minSize)
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide sends the headers, compressing the body when allowed and worthwhile, and
+// writes the buffered start of the body
+func (w *responseWriter) decide(allowed bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// Sniff before compressing, as net/http would sniff the compressed bytes
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if allowed && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		w.encoder = w.compressor.newEncoder[w.encoding](w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// close completes the response once the handler returned
+func (w *responseWriter) close() {
+	if !w.decided {
+		w.decide(len(w.buf) >= w.compressor.minSize)
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,114 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serve(t *testing.T, c *Compressor, acceptEncoding, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/did/resolve/did:example:123", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressesLargeJSON(t *testing.T) {
+	c, err := New([]string{"gzip"}, 64)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	body := `{"didDocument":"` + strings.Repeat("a", 1000) + `"}`
+
+	w := serve(t, c, "br;q=1.0, gzip;q=0.8", "application/did+json", body)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if string(decoded) != body {
+		t.Errorf("decompressed body does not match")
+	}
+}
+
+func TestPassesThrough(t *testing.T) {
+	c, err := New([]string{"gzip"}, 64)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	large := strings.Repeat("a", 1000)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+	}{
+		{"not accepted", "", "application/json", large},
+		{"refused", "gzip;q=0", "application/json", large},
+		{"small body", "gzip", "application/json", `{"ok":true}`},
+		{"binary document", "gzip", "application/pdf", large},
+		{"event stream", "gzip", "text/event-stream", large},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(t, c, tt.acceptEncoding, tt.contentType, tt.body)
+			if encoding := w.Header().Get("Content-Encoding"); encoding != "" {
+				t.Errorf("expected no encoding, got %q", encoding)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("body was altered")
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	Register("test", func(w io.Writer) Encoder { return nil })
+	c, err := New([]string{"test", "gzip"}, 0)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"gzip":                "gzip",
+		"gzip, test":          "test",
+		"test;q=0.5, gzip":    "gzip",
+		"*":                   "test",
+		"*;q=0.1, test;q=0":   "gzip",
+		"identity":            "",
+		"gzip;q=abc, test;q=": "",
+	}
+	for header, want := range tests {
+		if got := c.negotiate(header); got != want {
+			t.Errorf("negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+
+	if _, err := New([]string{"compress"}, 0); err == nil {
+		t.Error("expected an unknown coding to be rejected")
+	}
+}
@@ -0,0 +1,79 @@
+// Package ginweb adapts Gin to the web abstraction, registering handlers written
+// against web.Context with a Gin engine or route group
+package ginweb
+
+import (
+	"net/http"
+
+	"did-manager/pkg/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// context adapts the Gin context, whose methods already match web.Context apart from
+// the request
+type context struct {
+	*gin.Context
+}
+
+func (c context) Request() *http.Request {
+	return c.Context.Request
+}
+
+func (c context) Status() int {
+	return c.Context.Writer.Status()
+}
+
+// router registers routes with a Gin router
+type router struct {
+	gin gin.IRouter
+}
+
+// New returns a router registering routes with a Gin engine or route group
+func New(r gin.IRouter) web.Router {
+	return &router{gin: r}
+}
+
+// Wrap converts a handler into a Gin handler, e.g. to use the middleware outside the
+// web abstraction
+func Wrap(handler web.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler(context{Context: c})
+	}
+}
+
+func wrapAll(handlers []web.HandlerFunc) []gin.HandlerFunc {
+	wrapped := make([]gin.HandlerFunc, len(handlers))
+	for i, handler := range handlers {
+		wrapped[i] = Wrap(handler)
+	}
+	return wrapped
+}
+
+func (r *router) Use(middleware ...web.HandlerFunc) {
+	r.gin.Use(wrapAll(middleware)...)
+}
+
+func (r *router) Group(prefix string, middleware ...web.HandlerFunc) web.Router {
+	return &router{gin: r.gin.Group(prefix, wrapAll(middleware)...)}
+}
+
+func (r *router) Handle(method, path string, handlers ...web.HandlerFunc) {
+	r.gin.Handle(method, path, wrapAll(handlers)...)
+}
+
+func (r *router) GET(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodGet, path, handlers...)
+}
+
+func (r *router) POST(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodPost, path, handlers...)
+}
+
+func (r *router) PUT(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodPut, path, handlers...)
+}
+
+func (r *router) DELETE(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handlers...)
+}
@@ -0,0 +1,137 @@
+package stdweb
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// bindingValidator checks the binding tags of bound requests, like Gin does
+var bindingValidator = newBindingValidator()
+
+func newBindingValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+
+// validate checks the binding tags of a bound struct
+func validate(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return bindingValidator.Struct(obj)
+}
+
+// bindForm decodes query parameters into the fields of a struct by their form tags,
+// falling back to the field name. Times are parsed with the time_format tag, RFC 3339
+// by default; empty values leave fields at their zero value.
+func bindForm(values url.Values, obj any) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("query parameters can only be bound to a pointer to a struct")
+	}
+	return bindStruct(values, value.Elem())
+}
+
+func bindStruct(values url.Values, value reflect.Value) error {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(values, value.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 || raw[0] == "" {
+			continue
+		}
+		if err := setField(value.Field(i), field, raw[0]); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func setField(value reflect.Value, field reflect.StructField, raw string) error {
+	if value.Kind() == reflect.Pointer {
+		target := reflect.New(value.Type().Elem())
+		if err := setField(target.Elem(), field, raw); err != nil {
+			return err
+		}
+		value.Set(target)
+		return nil
+	}
+
+	if value.Type() == timeType {
+		layout := field.Tag.Get("time_format")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		parsed, err := time.Parse(layout, raw)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
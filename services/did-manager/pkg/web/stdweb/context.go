@@ -0,0 +1,234 @@
+package stdweb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+
+	"did-manager/pkg/web"
+)
+
+// abortIndex is past the end of any handler chain
+const abortIndex = math.MaxInt / 2
+
+// responseWriter records the status of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client
+func (w *responseWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// context implements web.Context for a request served by a Router
+type context struct {
+	writer   *responseWriter
+	request  *http.Request
+	fullPath string
+	handlers []web.HandlerFunc
+	index    int
+	keys     map[string]any
+	query    url.Values
+}
+
+func newContext(w http.ResponseWriter, req *http.Request, fullPath string, handlers []web.HandlerFunc) *context {
+	return &context{
+		writer:   &responseWriter{ResponseWriter: w, status: http.StatusOK},
+		request:  req,
+		fullPath: fullPath,
+		handlers: handlers,
+		index:    -1,
+	}
+}
+
+// recover answers a panic in a handler with 500 Internal Server Error
+func (c *context) recover() {
+	if err := recover(); err != nil {
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		log.Printf("[Recovery] panic serving %s %s: %v\n%s", c.request.Method, c.request.URL.Path, err, debug.Stack())
+		c.index = abortIndex
+		if !c.writer.written {
+			c.writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (c *context) Request() *http.Request {
+	return c.request
+}
+
+func (c *context) Param(name string) string {
+	return c.request.PathValue(name)
+}
+
+func (c *context) Query(name string) string {
+	if c.query == nil {
+		c.query = c.request.URL.Query()
+	}
+	return c.query.Get(name)
+}
+
+func (c *context) GetHeader(name string) string {
+	return c.request.Header.Get(name)
+}
+
+func (c *context) Header(name, value string) {
+	if value == "" {
+		c.writer.Header().Del(name)
+		return
+	}
+	c.writer.Header().Set(name, value)
+}
+
+// ClientIP returns the address the request came from. Forwarding headers are not
+// trusted; behind a proxy, it should set the request's remote address.
+func (c *context) ClientIP() string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(c.request.RemoteAddr))
+	if err != nil {
+		return c.request.RemoteAddr
+	}
+	return host
+}
+
+func (c *context) FullPath() string {
+	return c.fullPath
+}
+
+func (c *context) ShouldBindJSON(obj any) error {
+	if err := json.NewDecoder(c.request.Body).Decode(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func (c *context) ShouldBindQuery(obj any) error {
+	if c.query == nil {
+		c.query = c.request.URL.Query()
+	}
+	if err := bindForm(c.query, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func (c *context) JSON(code int, obj any) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		panic(fmt.Errorf("failed to encode response: %w", err))
+	}
+	c.writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.writer.WriteHeader(code)
+	c.writer.Write(body)
+}
+
+func (c *context) Data(code int, contentType string, data []byte) {
+	c.writer.Header().Set("Content-Type", contentType)
+	c.writer.WriteHeader(code)
+	c.writer.Write(data)
+}
+
+func (c *context) Stream(step func(w io.Writer) bool) bool {
+	done := c.request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.writer)
+			c.writer.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+func (c *context) SSEvent(name string, message any) {
+	header := c.writer.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "text/event-stream")
+	}
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	data, ok := message.(string)
+	if !ok {
+		encoded, err := json.Marshal(message)
+		if err != nil {
+			panic(fmt.Errorf("failed to encode event: %w", err))
+		}
+		data = string(encoded)
+	}
+	fmt.Fprintf(c.writer, "event:%s\ndata:%s\n\n", name, strings.ReplaceAll(data, "\n", "\ndata:"))
+}
+
+func (c *context) Status() int {
+	return c.writer.status
+}
+
+func (c *context) Next() {
+	c.index++
+	for c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
+
+func (c *context) AbortWithStatusJSON(code int, obj any) {
+	c.index = abortIndex
+	c.JSON(code, obj)
+}
+
+func (c *context) Set(key string, value any) {
+	if c.keys == nil {
+		c.keys = make(map[string]any)
+	}
+	c.keys[key] = value
+}
+
+func (c *context) Get(key string) (any, bool) {
+	value, ok := c.keys[key]
+	return value, ok
+}
+
+func (c *context) GetBool(key string) bool {
+	value, _ := c.keys[key].(bool)
+	return value
+}
@@ -0,0 +1,113 @@
+// Package stdweb serves handlers written against the web abstraction with the standard
+// library. A Router is an http.Handler, so the API can be mounted into any net/http
+// compatible router, e.g. mux.Handle("/api/", router) or chi's r.Mount("/", router).
+package stdweb
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"did-manager/pkg/web"
+)
+
+// Router routes requests with an http.ServeMux. Routes are matched by method and path,
+// with path parameters captured from the :name segments they were registered with.
+type Router struct {
+	mux        *http.ServeMux
+	prefix     string
+	middleware []web.HandlerFunc
+}
+
+// New creates a router
+func New() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// ServeHTTP serves a request. Panics in handlers are logged and answered with 500
+// Internal Server Error.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// Use adds middleware to the routes registered afterwards
+func (r *Router) Use(middleware ...web.HandlerFunc) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Group returns a router for routes under prefix, behind the router's middleware and
+// the given middleware
+func (r *Router) Group(prefix string, middleware ...web.HandlerFunc) web.Router {
+	chain := make([]web.HandlerFunc, 0, len(r.middleware)+len(middleware))
+	chain = append(chain, r.middleware...)
+	return &Router{
+		mux:        r.mux,
+		prefix:     r.prefix + prefix,
+		middleware: append(chain, middleware...),
+	}
+}
+
+// Handle registers a route for a method
+func (r *Router) Handle(method, path string, handlers ...web.HandlerFunc) {
+	fullPath := r.prefix + path
+	chain := make([]web.HandlerFunc, 0, len(r.middleware)+len(handlers))
+	chain = append(chain, r.middleware...)
+	chain = append(chain, handlers...)
+
+	r.mux.HandleFunc(method+" "+muxPattern(fullPath), func(w http.ResponseWriter, req *http.Request) {
+		c := newContext(w, req, fullPath, chain)
+		defer c.recover()
+		c.Next()
+	})
+}
+
+func (r *Router) GET(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodGet, path, handlers...)
+}
+
+func (r *Router) POST(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodPost, path, handlers...)
+}
+
+func (r *Router) PUT(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodPut, path, handlers...)
+}
+
+func (r *Router) DELETE(path string, handlers ...web.HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handlers...)
+}
+
+// muxPattern converts a path with :name and *name parameters to an http.ServeMux
+// pattern. Paths are matched exactly, including those ending in a slash.
+func muxPattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "{" + segment[1:] + "}"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "{" + segment[1:] + "...}"
+		}
+	}
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+	return pattern
+}
+
+// Logger logs every request with its status and latency
+func Logger() web.HandlerFunc {
+	return func(c web.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := 0
+		if ctx, ok := c.(*context); ok {
+			status = ctx.writer.status
+		}
+		log.Printf("[HTTP] %3d | %13v | %15s | %-7s %s",
+			status, time.Since(start), c.ClientIP(), c.Request().Method, c.Request().URL.Path)
+	}
+}
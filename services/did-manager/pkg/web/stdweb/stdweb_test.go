@@ -0,0 +1,176 @@
+package stdweb
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"did-manager/pkg/web"
+)
+
+func serve(r *Router, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestRouterParamsAndMiddleware(t *testing.T) {
+	r := New()
+	var order []string
+	r.Use(func(c web.Context) {
+		order = append(order, "root")
+		c.Set("seen", true)
+		c.Next()
+		order = append(order, "root after")
+	})
+
+	api := r.Group("/api/v1")
+	api.GET("/did/status/:did", func(c web.Context) {
+		order = append(order, "handler")
+		c.JSON(http.StatusOK, web.H{"did": c.Param("did"), "path": c.FullPath(), "seen": c.GetBool("seen")})
+	})
+
+	admin := r.Group("/api/v1/admin", func(c web.Context) {
+		if c.GetHeader("X-Admin-Key") != "secret" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, web.H{"error": "Invalid admin key"})
+			return
+		}
+		c.Next()
+	})
+	admin.DELETE("/keys/:id", func(c web.Context) {
+		c.JSON(http.StatusOK, web.H{"deleted": c.Param("id")})
+	})
+
+	w := serve(r, http.MethodGet, "/api/v1/did/status/did:example:123", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	want := `{"did":"did:example:123","path":"/api/v1/did/status/:did","seen":true}`
+	if w.Body.String() != want {
+		t.Errorf("expected %s, got %s", want, w.Body.String())
+	}
+	if strings.Join(order, ",") != "root,handler,root after" {
+		t.Errorf("unexpected handler order %v", order)
+	}
+
+	if w := serve(r, http.MethodDelete, "/api/v1/admin/keys/1", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the admin middleware to abort with 401, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodPost, "/api/v1/did/status/x", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for another method, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/api/v1/did/status/x/extra", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown path, got %d", w.Code)
+	}
+}
+
+func TestStatusAfterNext(t *testing.T) {
+	r := New()
+	var statuses []int
+	r.Use(func(c web.Context) {
+		statuses = append(statuses, c.Status())
+		c.Next()
+		statuses = append(statuses, c.Status())
+	})
+	r.POST("/did", func(c web.Context) {
+		c.JSON(http.StatusCreated, web.H{})
+	})
+
+	serve(r, http.MethodPost, "/did", "")
+	if len(statuses) != 2 || statuses[0] != http.StatusOK || statuses[1] != http.StatusCreated {
+		t.Errorf("expected the middleware to see 200 then 201, got %v", statuses)
+	}
+}
+
+func TestShouldBindJSONValidates(t *testing.T) {
+	type request struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	r := New()
+	r.POST("/lookup", func(c web.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, web.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+
+	if w := serve(r, http.MethodPost, "/lookup", `{"email":"alice@example.com"}`); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, body := range []string{`{"email":"not an email"}`, `{}`, ``} {
+		if w := serve(r, http.MethodPost, "/lookup", body); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %q, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestShouldBindQuery(t *testing.T) {
+	type query struct {
+		From   time.Time  `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+		Before *time.Time `form:"before"`
+		Limit  int        `form:"limit" binding:"omitempty,min=1,max=10"`
+		All    bool       `form:"all"`
+		Tenant string     `form:"tenant"`
+	}
+
+	bind := func(target string) (*query, error) {
+		var q query
+		c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil), "/", nil)
+		return &q, c.ShouldBindQuery(&q)
+	}
+
+	q, err := bind("/?from=2024-01-02T03:04:05Z&before=2024-02-01T00:00:00Z&limit=5&all=true&tenant=acme")
+	if err != nil {
+		t.Fatalf("ShouldBindQuery failed: %v", err)
+	}
+	if !q.From.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || q.Before == nil || q.Limit != 5 || !q.All || q.Tenant != "acme" {
+		t.Errorf("unexpected binding %+v", q)
+	}
+
+	if q, err := bind("/?from=2024-01-02T03:04:05Z"); err != nil || q.Before != nil {
+		t.Errorf("expected missing parameters to stay zero, got %+v, %v", q, err)
+	}
+	for _, target := range []string{"/", "/?from=yesterday", "/?from=2024-01-02T03:04:05Z&limit=50", "/?from=2024-01-02T03:04:05Z&limit=x"} {
+		if _, err := bind(target); err == nil {
+			t.Errorf("expected binding %s to fail", target)
+		}
+	}
+}
+
+func TestServerSentEvents(t *testing.T) {
+	r := New()
+	r.GET("/events", func(c web.Context) {
+		sent := 0
+		c.Stream(func(w io.Writer) bool {
+			c.SSEvent("status", web.H{"n": sent})
+			sent++
+			return sent < 2
+		})
+		c.SSEvent("message", "line one\nline two")
+	})
+
+	w := serve(r, http.MethodGet, "/events", "")
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", w.Header().Get("Content-Type"))
+	}
+	want := "event:status\ndata:{\"n\":0}\n\nevent:status\ndata:{\"n\":1}\n\nevent:message\ndata:line one\ndata:line two\n\n"
+	if w.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestRecoversFromPanics(t *testing.T) {
+	r := New()
+	r.GET("/panic", func(c web.Context) {
+		panic("boom")
+	})
+
+	if w := serve(r, http.MethodGet, "/panic", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
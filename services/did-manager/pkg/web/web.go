@@ -0,0 +1,103 @@
+// Package web abstracts the HTTP layer the handlers are written against, so they can be
+// mounted into any router: the ginweb adapter registers them with Gin, and the stdweb
+// adapter serves them as a plain net/http handler for services embedding the API into
+// a net/http, chi or similar router without depending on Gin.
+package web
+
+import (
+	"io"
+	"net/http"
+)
+
+// H is a shorthand for JSON objects
+type H map[string]any
+
+// Context is the request context handlers and middleware receive. Its methods mirror
+// those of the Gin context so handlers read the same under every adapter.
+type Context interface {
+	// Request returns the incoming request
+	Request() *http.Request
+	// Param returns the value of a path parameter declared as :name
+	Param(name string) string
+	// Query returns the first value of a query parameter
+	Query(name string) string
+	// GetHeader returns a request header
+	GetHeader(name string) string
+	// Header sets a response header, deleting it when value is empty
+	Header(name, value string)
+	// ClientIP returns the IP address of the client
+	ClientIP() string
+	// FullPath returns the path the matched route was registered with, e.g.
+	// /api/v1/did/status/:did
+	FullPath() string
+
+	// ShouldBindJSON decodes the JSON body into obj and validates its binding tags
+	ShouldBindJSON(obj any) error
+	// ShouldBindQuery decodes the query parameters into obj by its form tags and
+	// validates its binding tags
+	ShouldBindQuery(obj any) error
+
+	// JSON writes obj as a JSON response
+	JSON(code int, obj any)
+	// Data writes a response of the given content type
+	Data(code int, contentType string, data []byte)
+	// Stream calls step, flushing after each call, until it returns false or the client
+	// goes away; it reports whether the client went away
+	Stream(step func(w io.Writer) bool) bool
+	// SSEvent writes a server-sent event
+	SSEvent(name string, message any)
+	// Status returns the status of the response, 200 until one is written
+	Status() int
+
+	// Next runs the remaining handlers of the chain, for middleware acting after them
+	Next()
+	// AbortWithStatusJSON stops the chain and writes obj as a JSON response
+	AbortWithStatusJSON(code int, obj any)
+
+	// Set stores a value for the rest of the request
+	Set(key string, value any)
+	// Get returns a value stored with Set
+	Get(key string) (any, bool)
+	// GetBool returns a value stored with Set as a bool, false when missing
+	GetBool(key string) bool
+}
+
+// HandlerFunc handles a request or, in a chain, acts as middleware around the next
+// handlers
+type HandlerFunc func(c Context)
+
+// Router registers routes. Paths declare parameters as :name, and the handlers of a
+// route run after the middleware of the router and its parent groups.
+type Router interface {
+	// Use adds middleware to the routes registered afterwards
+	Use(middleware ...HandlerFunc)
+	// Group returns a router for routes under prefix, behind the given middleware
+	Group(prefix string, middleware ...HandlerFunc) Router
+	// Handle registers a route for a method
+	Handle(method, path string, handlers ...HandlerFunc)
+
+	GET(path string, handlers ...HandlerFunc)
+	POST(path string, handlers ...HandlerFunc)
+	PUT(path string, handlers ...HandlerFunc)
+	DELETE(path string, handlers ...HandlerFunc)
+}
+
+// Routes is implemented by the handlers, which register their routes with a router
+type Routes interface {
+	RegisterRoutes(router Router)
+}
+
+// RoutesFunc adapts a function registering routes to the Routes interface
+type RoutesFunc func(router Router)
+
+// RegisterRoutes calls f(router)
+func (f RoutesFunc) RegisterRoutes(router Router) {
+	f(router)
+}
+
+// Mount registers the routes of each handler with a router
+func Mount(router Router, routes ...Routes) {
+	for _, r := range routes {
+		r.RegisterRoutes(router)
+	}
+}
@@ -8,11 +8,34 @@ CREATE TABLE IF NOT EXISTS dids (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL,
     did VARCHAR(255) NOT NULL UNIQUE,
+    -- Current identifier of a DID created with a legacy identifier carrying a truncated
+    -- key, resolving to the same DID; set by its identifier migration
+    alias_did VARCHAR(255) UNIQUE,
     user_hash VARCHAR(64) NOT NULL UNIQUE,
+    -- Commitment scheme and PHC parameter string used to derive user_hash
+    hash_scheme VARCHAR(32) NOT NULL DEFAULT 'sha256-v1',
+    hash_params TEXT,
+    -- Version of the pepper keying user_hash; v0 is the unversioned DID_ID_PEPPER
+    pepper_version VARCHAR(32) NOT NULL DEFAULT 'v0',
     public_key TEXT NOT NULL,
+    -- Signature suite of the DID key, e.g. ed25519-2020
+    key_algorithm VARCHAR(32) NOT NULL DEFAULT 'ed25519-2020',
+    -- Custodial DIDs store the private key in public_key; cleared when custody is
+    -- transferred to the owner
+    custodial BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Controller approvals required for mutating operations on organization DIDs;
+    -- 0 for DIDs without controllers
+    approval_threshold INTEGER NOT NULL DEFAULT 0,
     status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public',
     blockchain_tx VARCHAR(66),
     -- Ethereum transaction hash
+    -- Keyed blind index of the holder's email address, for lookups by email without
+    -- storing it; NULL for DIDs created without an index key and not yet verified
+    email_index VARCHAR(64),
+    -- Sandbox API key whose partition holds the DID; NULL for production DIDs. Sandbox
+    -- DIDs are anchored by simulation and deleted when the sandbox is reset
+    sandbox_tenant VARCHAR(255),
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
@@ -30,7 +53,80 @@ CREATE TABLE IF NOT EXISTS blockchain_jobs (
     error TEXT,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    processed_at TIMESTAMP WITH TIME ZONE
+    processed_at TIMESTAMP WITH TIME ZONE,
+    -- Transaction submitted by the completed job
+    tx_hash VARCHAR(66),
+    -- Dry-run jobs are simulated (eth_call and gas estimation) instead of submitted
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    simulation JSONB,
+    -- Trace the job's phases are spans of, propagated to queue messages
+    trace_id VARCHAR(32)
+);
+
+-- Create api_keys table for relying parties
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    -- SHA-256 of the plaintext key; the key itself is never stored
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    -- Sandbox keys work in their own partition of sandbox DIDs
+    sandbox BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Sub-keys are issued by a tenant key and act as it, under their own budget
+    parent_id UUID REFERENCES api_keys(id) ON DELETE CASCADE,
+    -- DID holding the tenant's root capability; UCANs rooted at it call as the tenant
+    root_did VARCHAR(255) NOT NULL DEFAULT '',
+    -- Locale of messages shown to the tenant's end users; empty for the server default
+    default_locale VARCHAR(35) NOT NULL DEFAULT ''
+);
+
+-- Create did_acl_entries table granting resolution of private DIDs
+CREATE TABLE IF NOT EXISTS did_acl_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    grantee_type VARCHAR(20) NOT NULL,
+    -- API key ID or DID string, depending on grantee_type
+    grantee VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (did_id, grantee_type, grantee)
+);
+
+-- Create credentials table holding issued verifiable credentials
+CREATE TABLE IF NOT EXISTS credentials (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    issuer_did VARCHAR(255) NOT NULL,
+    subject_did VARCHAR(255) NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    -- Issuer-assigned label grouping subjects, used in issuer searches
+    tenant VARCHAR(255),
+    -- Set once the expiry reminder was sent, with the replacement issued on auto-renewal
+    expiry_notified_at TIMESTAMP WITH TIME ZONE,
+    renewed_by_id UUID,
+    -- Revocation batch whose accumulator root first includes this revocation
+    revocation_batch_id UUID,
+    -- Signed W3C credential
+    document JSONB NOT NULL
+);
+
+-- Create revocation_batches table recording revocation accumulator roots anchored on-chain
+CREATE TABLE IF NOT EXISTS revocation_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sequence BIGSERIAL NOT NULL UNIQUE,
+    -- Sparse Merkle root over every credential revoked up to this batch
+    root VARCHAR(66) NOT NULL,
+    revoked_count INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    anchored_at TIMESTAMP WITH TIME ZONE
 );
 
 -- Create indexes for better performance
@@ -40,12 +136,755 @@ CREATE INDEX IF NOT EXISTS idx_dids_status ON dids(status);
 
 CREATE INDEX IF NOT EXISTS idx_dids_user_hash ON dids(user_hash);
 
+CREATE INDEX IF NOT EXISTS idx_dids_email_index ON dids(email_index) WHERE email_index IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_dids_sandbox_tenant ON dids(sandbox_tenant) WHERE sandbox_tenant IS NOT NULL;
+
 CREATE INDEX IF NOT EXISTS idx_blockchain_jobs_status ON blockchain_jobs(status);
+-- Create notarizations table holding document hashes anchored on behalf of DIDs
+CREATE TABLE IF NOT EXISTS notarizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    -- Hex SHA-256 of the notarized document
+    document_hash VARCHAR(64) NOT NULL,
+    -- DID signature over the raw document hash, and the suite it was made with
+    signature TEXT NOT NULL,
+    key_algorithm VARCHAR(32) NOT NULL,
+    verification_method VARCHAR(255) NOT NULL,
+    -- Blockchain job anchoring the hash
+    job_id UUID NOT NULL REFERENCES blockchain_jobs(id),
+    tx_hash VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    anchored_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (document_hash, did)
+);
+
+-- Create push_devices table holding mobile wallet devices registered for notifications
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    platform VARCHAR(10) NOT NULL,
+    -- FCM registration token or APNs device token
+    token TEXT NOT NULL UNIQUE,
+    device_name VARCHAR(255),
+    -- Locale notifications are shown in; empty for the server default
+    locale VARCHAR(35) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+-- Create device_keys table holding the subkeys of a user's devices, listed as
+-- authentication verification methods on the user's DID
+CREATE TABLE IF NOT EXISTS device_keys (
+    id UUID PRIMARY KEY,
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- ID of the key in the DID document, <did>#device-<id>
+    verification_method VARCHAR(512) NOT NULL UNIQUE,
+    public_key TEXT NOT NULL,
+    key_algorithm VARCHAR(32) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    -- Platform attestation of the app instance that registered the key, if any
+    attestation_platform VARCHAR(20),
+    attested_app_id VARCHAR(255),
+    -- App Attest key ID of the attested app instance
+    attestation_key_id VARCHAR(64),
+    attested_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create did_purpose_keys table, the keystore of keys generated for a single
+-- verification relationship of a custodial DID. The DID's own key in dids is its
+-- capabilityInvocation key; purpose keys are deleted when custody is transferred
+CREATE TABLE IF NOT EXISTS did_purpose_keys (
+    id UUID PRIMARY KEY,
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    -- authentication, assertionMethod or keyAgreement
+    purpose VARCHAR(32) NOT NULL,
+    -- ID of the key in the DID document, e.g. <did>#assert-1
+    verification_method VARCHAR(512) NOT NULL UNIQUE,
+    key_algorithm VARCHAR(32) NOT NULL,
+    public_key TEXT NOT NULL,
+    -- Hex-encoded private key held for the custodial DID
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (did_id, purpose)
+);
+
+-- Create verification_sessions table for QR-initiated cross-device verification
+CREATE TABLE IF NOT EXISTS verification_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- Caller that created the session and may read its outcome
+    verifier_type VARCHAR(20) NOT NULL,
+    verifier_id VARCHAR(255) NOT NULL,
+    challenge VARCHAR(64) NOT NULL,
+    domain VARCHAR(255) NOT NULL,
+    credential_types TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    holder_did VARCHAR(255),
+    message TEXT,
+    -- Verified presentation submitted by the wallet
+    presentation JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create custody_transfers table auditing transfers of custodial DIDs to their owners
+CREATE TABLE IF NOT EXISTS custody_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    -- Owner and wallet application that requested the transfer
+    user_id UUID NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    challenge VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- Hex SHA-256 of the custodial public key that was replaced
+    previous_key_fingerprint VARCHAR(64) NOT NULL,
+    -- Owner's public key and its signature suite, set on completion
+    new_public_key TEXT,
+    key_algorithm VARCHAR(32),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create did_controllers table listing the controllers of organization DIDs
+CREATE TABLE IF NOT EXISTS did_controllers (
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    controller_did VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (did_id, controller_did)
+);
+
+-- Create controlled_operations table holding operations on organization DIDs that
+-- await m-of-n controller approval
+CREATE TABLE IF NOT EXISTS controlled_operations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    operation_type VARCHAR(32) NOT NULL,
+    payload JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    proposed_by VARCHAR(255) NOT NULL,
+    -- Output of the executed operation, or the failure message
+    result JSONB,
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    executed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS operation_approvals (
+    operation_id UUID NOT NULL REFERENCES controlled_operations(id) ON DELETE CASCADE,
+    controller_did VARCHAR(255) NOT NULL,
+    approved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (operation_id, controller_did)
+);
+
+-- Create endorsements table holding the signed endorsement graph between DIDs
+CREATE TABLE IF NOT EXISTS endorsements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    endorser_did VARCHAR(255) NOT NULL,
+    subject_did VARCHAR(255) NOT NULL,
+    endorsement_type VARCHAR(100) NOT NULL,
+    statement TEXT,
+    -- Endorser's signature over the endorsement message, and the suite it was made with
+    signature TEXT NOT NULL,
+    key_algorithm VARCHAR(32) NOT NULL,
+    verification_method VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create renewal_policies table configuring expiry reminders and auto-renewal per
+-- issuer and tenant; the empty tenant is the issuer's default
+CREATE TABLE IF NOT EXISTS renewal_policies (
+    issuer_did VARCHAR(255) NOT NULL,
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    auto_renew BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Validity of renewed credentials; 0 keeps the original validity period
+    validity_days INTEGER NOT NULL DEFAULT 0,
+    webhook_url TEXT,
+    -- HMAC-SHA256 key signing webhook deliveries
+    webhook_secret VARCHAR(64),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (issuer_did, tenant)
+);
+
+-- Create feature_overrides table overriding configured feature flags at runtime;
+-- an empty tenant overrides the flag for every tenant
+CREATE TABLE IF NOT EXISTS feature_overrides (
+    flag VARCHAR(64) NOT NULL,
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (flag, tenant)
+);
+
+-- Create status_incidents table holding the incident flags raised on the public
+-- status page
+CREATE TABLE IF NOT EXISTS status_incidents (
+    flag VARCHAR(64) PRIMARY KEY,
+    message VARCHAR(280) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create identity_fingerprints table holding peppered hashes of each DID's normalized
+-- email and claims, compared to detect duplicate identities. During a pepper rotation
+-- they are recorded under both the current and the previous pepper version
+CREATE TABLE IF NOT EXISTS identity_fingerprints (
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    rule VARCHAR(32) NOT NULL,
+    pepper_version VARCHAR(32) NOT NULL DEFAULT 'v0',
+    fingerprint VARCHAR(64) NOT NULL,
+    PRIMARY KEY (did_id, rule, pepper_version)
+);
+
+-- Create user_hash_index table holding peppered lookup indexes of each DID's user hash
+-- by pepper version. After a pepper rotation, indexes are re-hashed under the new
+-- version from the stored user hash and those under retired versions deleted
+CREATE TABLE IF NOT EXISTS user_hash_index (
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    pepper_version VARCHAR(32) NOT NULL,
+    lookup VARCHAR(64) NOT NULL,
+    PRIMARY KEY (did_id, pepper_version)
+);
+
+-- Create reviews table holding DIDs and credentials flagged by screening or duplicate
+-- detection until an administrator approves or rejects them
+CREATE TABLE IF NOT EXISTS reviews (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    operation VARCHAR(32) NOT NULL,
+    -- Held DID or credential, and its DID or the credential's subject DID
+    target_id UUID NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create usage_events table metering billable events per tenant (API key ID or DID)
+CREATE TABLE IF NOT EXISTS usage_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(255) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    -- DID created or verified, or credential issued
+    subject VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create tenant_quotas table limiting events per tenant and calendar month; an empty
+-- tenant is the default for tenants without their own quota
+CREATE TABLE IF NOT EXISTS tenant_quotas (
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    event_type VARCHAR(32) NOT NULL,
+    monthly_limit INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant, event_type)
+);
+
+-- Create cost_usage table counting each API key's successful requests per cost class
+-- and day (UTC); tenant is the key's parent for sub-keys, the key itself otherwise
+CREATE TABLE IF NOT EXISTS cost_usage (
+    api_key_id UUID NOT NULL,
+    tenant VARCHAR(255) NOT NULL,
+    cost_class VARCHAR(32) NOT NULL,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    units BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, cost_class, day)
+);
+
+-- Create cost_budgets table limiting the cost units an API key draws per calendar month
+CREATE TABLE IF NOT EXISTS cost_budgets (
+    api_key_id UUID PRIMARY KEY REFERENCES api_keys(id) ON DELETE CASCADE,
+    monthly_units BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create tenant_did_methods table restricting the DID methods a tenant may create; an
+-- empty tenant is the default for tenants without their own policy
+CREATE TABLE IF NOT EXISTS tenant_did_methods (
+    tenant VARCHAR(255) PRIMARY KEY DEFAULT '',
+    methods TEXT[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create verification_policies table deciding how DID verification answers when the
+-- blockchain is unreachable; an empty tenant or endpoint matches any
+CREATE TABLE IF NOT EXISTS verification_policies (
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    endpoint VARCHAR(20) NOT NULL DEFAULT '',
+    fallback VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant, endpoint),
+    CONSTRAINT chk_verification_policies_fallback CHECK (fallback IN ('fail_closed', 'fail_open', 'local_only'))
+);
+
+-- Create verification_nonces table holding the single-use nonces holders sign to
+-- authorize a relying party (the audience) to verify their DID
+CREATE TABLE IF NOT EXISTS verification_nonces (
+    nonce VARCHAR(64) PRIMARY KEY,
+    audience VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create step_up_challenges table holding the single-use challenges a DID signs, or
+-- presents a credential over, before a sensitive operation
+CREATE TABLE IF NOT EXISTS step_up_challenges (
+    id UUID PRIMARY KEY,
+    did VARCHAR(255) NOT NULL,
+    challenge VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create sidetree_batches table recording batches of DID operations anchored with one
+-- transaction; the batch files are stored in IPFS
+CREATE TABLE IF NOT EXISTS sidetree_batches (
+    id UUID PRIMARY KEY,
+    -- <operation count>.<core index file CID>
+    anchor_string VARCHAR(255) NOT NULL UNIQUE,
+    core_index_file_uri VARCHAR(100) NOT NULL,
+    chunk_file_uri VARCHAR(100) NOT NULL,
+    -- SHA-256 of the core index file, as written on-chain
+    anchor_file_hash VARCHAR(66) NOT NULL,
+    operation_count INTEGER NOT NULL,
+    contract VARCHAR(42) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    anchored_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create sidetree_operations table holding the receipt of each batched DID operation;
+-- receipts outlive the cleaned-up jobs they were created from
+CREATE TABLE IF NOT EXISTS sidetree_operations (
+    job_id UUID PRIMARY KEY,
+    batch_id UUID NOT NULL REFERENCES sidetree_batches(id) ON DELETE CASCADE,
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    operation_index INTEGER NOT NULL
+);
+
+-- Create blockchain_job_phases table recording when each job was enqueued, published,
+-- claimed, submitted and confirmed, each phase a span of the job's trace
+CREATE TABLE IF NOT EXISTS blockchain_job_phases (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES blockchain_jobs(id) ON DELETE CASCADE,
+    phase VARCHAR(20) NOT NULL,
+    span_id VARCHAR(16) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create webhook_endpoints table holding the URLs tenants (API key ID or DID) receive
+-- domain events at; an empty event_types subscribes to every event type
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY,
+    tenant_type VARCHAR(20) NOT NULL,
+    tenant VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    -- Custom headers sent with every delivery
+    headers JSONB NOT NULL DEFAULT '{}',
+    -- HMAC-SHA256 key signing deliveries
+    secret VARCHAR(64) NOT NULL,
+    max_attempts INTEGER NOT NULL,
+    backoff_seconds INTEGER NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- DID whose keyAgreement key payloads are encrypted to, NULL for plaintext payloads
+    encrypt_to_did VARCHAR(255)
+);
+
+-- Create webhook_deliveries table holding each event to deliver to an endpoint with
+-- the payload posted, retried until it succeeds or runs out of attempts
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    -- Replayed events are delivered as <event ID>:replay:<replay ID>
+    event_id VARCHAR(128) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- Replay that re-delivered the event, NULL for live deliveries
+    replay_id UUID,
+    -- Events redelivered by the stream are only delivered once per endpoint
+    UNIQUE (endpoint_id, event_id)
+);
+
+-- Create webhook_attempts table recording every attempt of a delivery with the
+-- receiver's response status
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create documents table holding the metadata of files kept encrypted in the document
+-- vault; the content is stored in the object store under documents/<id>
+CREATE TABLE IF NOT EXISTS documents (
+    id UUID PRIMARY KEY,
+    owner_did VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    -- Hex SHA-256 of the content, referenced by the credentials it is attached to
+    digest VARCHAR(64) NOT NULL,
+    -- Key the stored object is encrypted with
+    key_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create credential_documents table linking credentials to the documents they reference
+CREATE TABLE IF NOT EXISTS credential_documents (
+    credential_id UUID NOT NULL REFERENCES credentials(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id),
+    PRIMARY KEY (document_id, credential_id)
+);
+
+-- Create verification_log table recording the relying parties (API key ID or DID) that
+-- verified a DID or credential, so they are notified when it is revoked
+CREATE TABLE IF NOT EXISTS verification_log (
+    verifier_type VARCHAR(20) NOT NULL,
+    verifier_id VARCHAR(255) NOT NULL,
+    subject_kind VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    first_verified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_verified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subject_kind, subject, verifier_type, verifier_id)
+);
+
+-- Create backups table recording the encrypted snapshots of the dids, credentials and
+-- blockchain_jobs tables kept in the backup bucket
+CREATE TABLE IF NOT EXISTS backups (
+    id UUID PRIMARY KEY,
+    object_name VARCHAR(255) NOT NULL UNIQUE,
+    -- Key the stored snapshot is encrypted with
+    key_id VARCHAR(64) NOT NULL,
+    size BIGINT NOT NULL,
+    did_count INTEGER NOT NULL,
+    credential_count INTEGER NOT NULL,
+    job_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create analytics_exports table recording the usage events written to the analytics
+-- bucket as Parquet files; the last exported event is where the next export resumes
+CREATE TABLE IF NOT EXISTS analytics_exports (
+    id UUID PRIMARY KEY,
+    first_event_id BIGINT NOT NULL,
+    last_event_id BIGINT NOT NULL,
+    event_count INTEGER NOT NULL,
+    file_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create smart_accounts table binding DIDs to the ERC-4337 smart accounts that control
+-- them
+CREATE TABLE IF NOT EXISTS smart_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL UNIQUE REFERENCES dids(id) ON DELETE CASCADE,
+    did VARCHAR(255) NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    owner VARCHAR(42) NOT NULL,
+    factory VARCHAR(42) NOT NULL,
+    entry_point VARCHAR(42) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    salt VARCHAR(78) NOT NULL DEFAULT '0',
+    -- Set once the first user operation has deployed the account
+    deployed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create blockchain_job_archive table keeping finished blockchain jobs moved out of
+-- blockchain_jobs as anchoring evidence for compliance; record holds the job with its
+-- timeline as gzipped JSON. Archived jobs outlive their DIDs.
+CREATE TABLE IF NOT EXISTS blockchain_job_archive (
+    id UUID PRIMARY KEY,
+    job_type VARCHAR(50) NOT NULL,
+    did_id UUID NOT NULL,
+    did VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    record BYTEA NOT NULL
+);
+
+-- Create did_identifier_migrations table mapping the legacy identifiers of DIDs to
+-- identifiers of the current format. new_did is empty for DIDs whose inception key is
+-- no longer stored; job_id is the update_did job anchoring new_did, which may be
+-- archived once finished
+CREATE TABLE IF NOT EXISTS did_identifier_migrations (
+    did_id UUID PRIMARY KEY REFERENCES dids(id) ON DELETE CASCADE,
+    legacy_did VARCHAR(255) NOT NULL,
+    from_format VARCHAR(32) NOT NULL,
+    new_did VARCHAR(255) NOT NULL DEFAULT '',
+    to_format VARCHAR(32) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    job_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create anchoring_pause table holding at most one row, present while an operator has
+-- paused the submission of blockchain jobs
+CREATE TABLE IF NOT EXISTS anchoring_pause (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    reason VARCHAR(280) NOT NULL,
+    paused_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create trusted_issuers table holding the issuers accredited in the trust registry;
+-- an empty credential_types accredits every type
+CREATE TABLE IF NOT EXISTS trusted_issuers (
+    did VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    credential_types TEXT[] NOT NULL DEFAULT '{}',
+    version BIGINT NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create trust_registry_changes table logging every addition and removal of the trust
+-- registry; the ID of the last change is the registry version
+CREATE TABLE IF NOT EXISTS trust_registry_changes (
+    version BIGSERIAL PRIMARY KEY,
+    did VARCHAR(255) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create issuance_rules table holding the credential issuance allowed per tenant; an
+-- empty tenant applies to every tenant and empty credential_types allows every type
+CREATE TABLE IF NOT EXISTS issuance_rules (
+    id UUID PRIMARY KEY,
+    tenant VARCHAR(255) NOT NULL DEFAULT '',
+    issuer_prefix VARCHAR(255) NOT NULL DEFAULT '',
+    credential_types TEXT[] NOT NULL DEFAULT '{}',
+    subjects VARCHAR(16) NOT NULL,
+    subject_prefixes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_issuance_rules_tenant ON issuance_rules(tenant);
+
+-- Create did_profiles table holding the profile each DID's controller maintains; only
+-- the fields listed in published are shown publicly
+CREATE TABLE IF NOT EXISTS did_profiles (
+    did_id UUID PRIMARY KEY REFERENCES dids(id) ON DELETE CASCADE,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    avatar_url VARCHAR(2048) NOT NULL DEFAULT '',
+    linked_domains TEXT[] NOT NULL DEFAULT '{}',
+    public_credentials UUID[] NOT NULL DEFAULT '{}',
+    published TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create social_proof_challenges table holding the challenges a DID signs and publishes
+-- from a social account to link it
+CREATE TABLE IF NOT EXISTS social_proof_challenges (
+    id UUID PRIMARY KEY,
+    did VARCHAR(255) NOT NULL,
+    platform VARCHAR(16) NOT NULL,
+    handle VARCHAR(64) NOT NULL,
+    challenge VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create did_claims table appending the claims verified for a DID after its creation;
+-- each claim is the DID's next claims version
+CREATE TABLE IF NOT EXISTS did_claims (
+    id UUID PRIMARY KEY,
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    claim_type VARCHAR(64) NOT NULL,
+    scheme VARCHAR(32) NOT NULL,
+    params VARCHAR(255) NOT NULL DEFAULT '',
+    commitment VARCHAR(255) NOT NULL,
+    pepper_version VARCHAR(32) NOT NULL DEFAULT 'v0',
+    version INTEGER NOT NULL,
+    credential_id UUID,
+    verified_by VARCHAR(255) NOT NULL,
+    verified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (did_id, version)
+);
+
+-- Create bulk_revocations table holding staged job groups revoking many DIDs or
+-- credentials at once; staged revocations can be canceled until they are executed
+CREATE TABLE IF NOT EXISTS bulk_revocations (
+    id UUID PRIMARY KEY,
+    reason VARCHAR(500) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'staged',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    executed_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    canceled_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create bulk_revocation_items table holding the targets of bulk revocations: DIDs,
+-- deactivated with a revoke_did job each, and credential IDs
+CREATE TABLE IF NOT EXISTS bulk_revocation_items (
+    revocation_id UUID NOT NULL REFERENCES bulk_revocations(id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    job_id UUID,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (revocation_id, target_type, target)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_revocation_items_status ON bulk_revocation_items(revocation_id, status);
+
+-- Create wallet_offers table for one-time links importing a DID and its credentials
+-- into a mobile wallet
+CREATE TABLE IF NOT EXISTS wallet_offers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    did_id UUID NOT NULL REFERENCES dids(id) ON DELETE CASCADE,
+    -- Credentials offered; empty offers every active credential of the DID
+    credential_ids UUID[] NOT NULL DEFAULT '{}',
+    -- SHA-256 of the claim code; the code itself is never stored
+    code_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_offers_did_id ON wallet_offers(did_id);
 
 CREATE INDEX IF NOT EXISTS idx_blockchain_jobs_did_id ON blockchain_jobs(did_id);
 
 CREATE INDEX IF NOT EXISTS idx_blockchain_jobs_created_at ON blockchain_jobs(created_at);
 
+-- Archive lookups by DID, transaction and processing time
+CREATE INDEX IF NOT EXISTS idx_blockchain_job_archive_did ON blockchain_job_archive(did);
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_job_archive_did_id ON blockchain_job_archive(did_id);
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_job_archive_tx_hash ON blockchain_job_archive(tx_hash);
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_job_archive_processed_at ON blockchain_job_archive(processed_at);
+
+-- Migrations waiting for an update job to anchor them
+CREATE INDEX IF NOT EXISTS idx_did_identifier_migrations_unanchored ON did_identifier_migrations(created_at) WHERE job_id IS NULL AND new_did <> '';
+
+CREATE INDEX IF NOT EXISTS idx_did_acl_entries_did_id ON did_acl_entries(did_id);
+
+CREATE INDEX IF NOT EXISTS idx_credentials_subject_did ON credentials(subject_did);
+
+CREATE INDEX IF NOT EXISTS idx_credentials_issuer_did ON credentials(issuer_did);
+
+-- Issuer credential search by type, tenant and expiry
+CREATE INDEX IF NOT EXISTS idx_credentials_issuer_type_expires ON credentials(issuer_did, type, status, expires_at);
+
+CREATE INDEX IF NOT EXISTS idx_credentials_issuer_tenant ON credentials(issuer_did, tenant);
+
+CREATE INDEX IF NOT EXISTS idx_credentials_issuer_issued_at ON credentials(issuer_did, issued_at DESC);
+
+-- Expiry scheduler scan over active credentials not yet notified
+CREATE INDEX IF NOT EXISTS idx_credentials_expiry_pending ON credentials(expires_at)
+WHERE
+    status = 'active'
+    AND expiry_notified_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_credentials_revocation_batch_id ON credentials(revocation_batch_id);
+
+CREATE INDEX IF NOT EXISTS idx_notarizations_job_id ON notarizations(job_id);
+
+CREATE INDEX IF NOT EXISTS idx_identity_fingerprints_lookup ON identity_fingerprints(rule, fingerprint);
+
+CREATE INDEX IF NOT EXISTS idx_user_hash_index_lookup ON user_hash_index(lookup);
+
+CREATE INDEX IF NOT EXISTS idx_usage_events_tenant ON usage_events(tenant, event_type, occurred_at);
+
+CREATE INDEX IF NOT EXISTS idx_usage_events_occurred_at ON usage_events(occurred_at);
+
+CREATE INDEX IF NOT EXISTS idx_cost_usage_tenant ON cost_usage(tenant, day);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_parent_id ON api_keys(parent_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_root_did ON api_keys(root_did) WHERE root_did <> '';
+
+-- Finds the verifications of DIDs for the DID funnel
+CREATE INDEX IF NOT EXISTS idx_usage_events_did_verification ON usage_events(subject)
+WHERE
+    event_type = 'did_verification';
+
+-- Attributes anchored transactions to the tenant that created the DID
+CREATE INDEX IF NOT EXISTS idx_usage_events_did_created ON usage_events(subject)
+WHERE
+    event_type = 'did_created';
+
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews(status, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_sidetree_operations_did ON sidetree_operations(did, batch_id);
+
+CREATE INDEX IF NOT EXISTS idx_verification_nonces_expires_at ON verification_nonces(expires_at);
+
+CREATE INDEX IF NOT EXISTS idx_step_up_challenges_expires_at ON step_up_challenges(expires_at);
+
+CREATE INDEX IF NOT EXISTS idx_blockchain_job_phases_job ON blockchain_job_phases(job_id, occurred_at);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_tenant ON webhook_endpoints(tenant_type, tenant);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at DESC);
+
+-- Delivery worker scan over pending deliveries by due time
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at)
+WHERE
+    status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts(delivery_id, attempt);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_device_keys_did_id ON device_keys(did_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_device_keys_user_id ON device_keys(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_custody_transfers_did_id ON custody_transfers(did_id);
+
+CREATE INDEX IF NOT EXISTS idx_controlled_operations_did_id ON controlled_operations(did_id);
+
+CREATE INDEX IF NOT EXISTS idx_endorsements_subject_did ON endorsements(subject_did);
+
+CREATE INDEX IF NOT EXISTS idx_verification_log_last_verified_at ON verification_log(last_verified_at);
+
+CREATE INDEX IF NOT EXISTS idx_documents_owner_did ON documents(owner_did);
+
+CREATE INDEX IF NOT EXISTS idx_backups_created_at ON backups(created_at);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_exports_last_event_id ON analytics_exports(last_event_id);
+
+-- One active endorsement per endorser, subject and type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_endorsements_active ON endorsements(endorser_did, subject_did, endorsement_type)
+WHERE
+    revoked_at IS NULL;
+
 -- Create status check constraints
 ALTER TABLE
     dids
@@ -56,10 +895,91 @@ ADD
             'active',
             'revoked',
             'expired',
-            'failed'
+            'failed',
+            'pending_review',
+            'rejected'
         )
     );
 
+ALTER TABLE
+    dids
+ADD
+    CONSTRAINT chk_dids_visibility CHECK (visibility IN ('public', 'private'));
+
+ALTER TABLE
+    api_keys
+ADD
+    CONSTRAINT chk_api_keys_status CHECK (status IN ('active', 'revoked'));
+
+ALTER TABLE
+    did_acl_entries
+ADD
+    CONSTRAINT chk_did_acl_entries_grantee_type CHECK (grantee_type IN ('api_key', 'did'));
+
+ALTER TABLE
+    credentials
+ADD
+    CONSTRAINT chk_credentials_status CHECK (
+        status IN ('active', 'revoked', 'pending_review', 'rejected')
+    );
+
+ALTER TABLE
+    revocation_batches
+ADD
+    CONSTRAINT chk_revocation_batches_status CHECK (status IN ('pending', 'anchored'));
+
+ALTER TABLE
+    push_devices
+ADD
+    CONSTRAINT chk_push_devices_platform CHECK (platform IN ('fcm', 'apns'));
+
+ALTER TABLE
+    verification_sessions
+ADD
+    CONSTRAINT chk_verification_sessions_status CHECK (
+        status IN (
+            'pending',
+            'scanned',
+            'verified',
+            'rejected',
+            'expired'
+        )
+    );
+
+ALTER TABLE
+    custody_transfers
+ADD
+    CONSTRAINT chk_custody_transfers_status CHECK (status IN ('pending', 'completed', 'expired'));
+
+ALTER TABLE
+    tenant_quotas
+ADD
+    CONSTRAINT chk_tenant_quotas_monthly_limit CHECK (monthly_limit >= 0);
+
+ALTER TABLE
+    reviews
+ADD
+    CONSTRAINT chk_reviews_status CHECK (status IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE
+    reviews
+ADD
+    CONSTRAINT chk_reviews_operation CHECK (operation IN ('create_did', 'issue_credential'));
+
+ALTER TABLE
+    controlled_operations
+ADD
+    CONSTRAINT chk_controlled_operations_type CHECK (
+        operation_type IN ('rotate_key', 'revoke_did', 'issue_credential')
+    );
+
+ALTER TABLE
+    controlled_operations
+ADD
+    CONSTRAINT chk_controlled_operations_status CHECK (
+        status IN ('pending', 'executing', 'executed', 'failed')
+    );
+
 ALTER TABLE
     blockchain_jobs
 ADD
@@ -69,10 +989,22 @@ ADD
             'processing',
             'completed',
             'failed',
-            'retrying'
+            'retrying',
+            'simulated',
+            'canceled'
         )
     );
 
+ALTER TABLE
+    webhook_endpoints
+ADD
+    CONSTRAINT chk_webhook_endpoints_tenant_type CHECK (tenant_type IN ('api_key', 'did'));
+
+ALTER TABLE
+    webhook_deliveries
+ADD
+    CONSTRAINT chk_webhook_deliveries_status CHECK (status IN ('pending', 'succeeded', 'failed'));
+
 -- Create function to update updated_at timestamp
 CREATE
 OR REPLACE FUNCTION update_updated_at_column() RETURNS TRIGGER AS $ $ BEGIN NEW.updated_at = NOW();
@@ -0,0 +1,124 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type createdKey struct {
+	Key string `json:"key"`
+}
+
+type didResponse struct {
+	DID struct {
+		Did string `json:"did"`
+	} `json:"did"`
+	UserHash string `json:"user_hash"`
+	Status   string `json:"status"`
+}
+
+type verification struct {
+	IsValid      bool   `json:"is_valid"`
+	Status       string `json:"status"`
+	BlockchainTx string `json:"blockchain_tx"`
+	Assurance    string `json:"assurance"`
+}
+
+// waitForStatus polls the DID's status until it reaches want
+func waitForStatus(t *testing.T, headers map[string]string, did, want string) *verification {
+	t.Helper()
+
+	var status verification
+	deadline := time.Now().Add(90 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := env.Do(http.MethodGet, "/api/v1/did/status/"+url.PathEscape(did), headers, nil, &status); err != nil {
+			t.Fatalf("status check failed: %v", err)
+		}
+		if status.Status == want {
+			return &status
+		}
+		time.Sleep(time.Second)
+	}
+	t.Fatalf("DID %s stayed %q, expected %q", did, status.Status, want)
+	return nil
+}
+
+// TestDIDLifecycle creates a DID, waits for it to be anchored, verifies it, and
+// revokes it, checking the registry contract directly at each step
+func TestDIDLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	var tenant createdKey
+	if _, err := env.Do(http.MethodPost, "/api/v1/admin/api-keys", env.Admin(), map[string]any{"name": "integration"}, &tenant); err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+	caller := map[string]string{"X-API-Key": tenant.Key}
+
+	// Create
+	var created didResponse
+	code, err := env.Do(http.MethodPost, "/api/v1/did", caller, map[string]any{
+		"user_id": uuid.New(),
+		"name":    "Ada Lovelace",
+		"email":   "ada@example.com",
+	}, &created)
+	if err != nil {
+		t.Fatalf("failed to create DID: %v", err)
+	}
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	did := created.DID.Did
+	if did == "" {
+		t.Fatal("expected the created DID in the response")
+	}
+
+	// Anchor
+	anchored := waitForStatus(t, caller, did, "active")
+	if anchored.BlockchainTx == "" {
+		t.Error("expected the anchoring transaction on the active DID")
+	}
+	if valid, err := env.VerifyOnChain(ctx, did); err != nil || !valid {
+		t.Fatalf("expected the registry to hold the DID as valid, got %v (%v)", valid, err)
+	}
+
+	// Verify
+	var verified verification
+	if _, err := env.Do(http.MethodPost, "/api/v1/did/verify", caller, map[string]any{
+		"did":   did,
+		"name":  "Ada Lovelace",
+		"email": "ada@example.com",
+	}, &verified); err != nil {
+		t.Fatalf("failed to verify DID: %v", err)
+	}
+	if !verified.IsValid {
+		t.Errorf("expected the anchored DID to verify, got %+v", verified)
+	}
+
+	// Revoke
+	code, err = env.Do(http.MethodPost, "/api/v1/admin/did/deactivate", env.Admin(), map[string]any{"did": did}, nil)
+	if err != nil {
+		t.Fatalf("failed to deactivate DID: %v", err)
+	}
+	if code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+
+	waitForStatus(t, caller, did, "revoked")
+	if valid, err := env.VerifyOnChain(ctx, did); err != nil || valid {
+		t.Fatalf("expected the registry to hold the DID as revoked, got valid=%v (%v)", valid, err)
+	}
+
+	if _, err := env.Do(http.MethodPost, "/api/v1/did/verify", caller, map[string]any{"did": did}, &verified); err != nil {
+		t.Fatalf("failed to verify revoked DID: %v", err)
+	}
+	if verified.IsValid {
+		t.Errorf("expected the revoked DID not to verify, got %+v", verified)
+	}
+}
@@ -0,0 +1,7 @@
+// Package integration runs the DID Manager end to end against real dependencies:
+// PostgreSQL, NATS JetStream and an Anvil chain with the DIDRegistry contract, started
+// in containers by testcontainers. The service is built from source and exercised
+// through its HTTP API. The tests need Docker and only build with the integration tag:
+//
+//	go test -tags=integration ./...
+package integration
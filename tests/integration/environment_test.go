@@ -0,0 +1,407 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/nats"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Container images, overridable for mirrors and air-gapped CI
+var (
+	postgresImage = imageFromEnv("INTEGRATION_POSTGRES_IMAGE", "postgres:15-alpine")
+	natsImage     = imageFromEnv("INTEGRATION_NATS_IMAGE", "nats:2.10-alpine")
+	anvilImage    = imageFromEnv("INTEGRATION_ANVIL_IMAGE", "ghcr.io/foundry-rs/foundry:stable")
+)
+
+// anvilKey is the private key of Anvil's first prefunded development account, which
+// deploys the registry and signs the service's transactions
+const anvilKey = "ac0974bec39a17e1c6be6ea1b6b8f7c9b3a6d9a83e0d4b0bd3cea2b8a6ab3b63"
+
+// adminKey is the admin API key the service is started with
+const adminKey = "integration-admin-key"
+
+// Environment is a running DID Manager with its dependencies
+type Environment struct {
+	// BaseURL is the root of the service's HTTP API
+	BaseURL string
+	// Chain is a client of the Anvil node, for checking what was anchored
+	Chain *ethclient.Client
+	// Registry is the address of the deployed DIDRegistry contract
+	Registry common.Address
+
+	registryABI abi.ABI
+	containers  []testcontainers.Container
+	server      *exec.Cmd
+	logPath     string
+}
+
+// repoPath resolves a path relative to the repository root
+func repoPath(elem ...string) string {
+	return filepath.Join(append([]string{"..", ".."}, elem...)...)
+}
+
+func imageFromEnv(name, fallback string) string {
+	if image := os.Getenv(name); image != "" {
+		return image
+	}
+	return fallback
+}
+
+// StartEnvironment starts the containers, deploys the registry, then builds and starts
+// the service, returning once it reports ready. Stop releases everything, also when
+// starting failed halfway.
+func StartEnvironment(ctx context.Context) (*Environment, error) {
+	env := &Environment{}
+
+	db, err := postgres.Run(ctx, postgresImage,
+		postgres.WithDatabase("did_manager_db"),
+		postgres.WithUsername("did_manager"),
+		postgres.WithPassword("did_manager_password"),
+		postgres.WithInitScripts(repoPath("services", "did-manager", "scripts", "init.sql")),
+		postgres.BasicWaitStrategies(),
+	)
+	if db != nil {
+		env.containers = append(env.containers, db)
+	}
+	if err != nil {
+		return env, fmt.Errorf("failed to start postgres: %w", err)
+	}
+
+	queue, err := nats.Run(ctx, natsImage)
+	if queue != nil {
+		env.containers = append(env.containers, queue)
+	}
+	if err != nil {
+		return env, fmt.Errorf("failed to start nats: %w", err)
+	}
+
+	anvil, err := testcontainers.Run(ctx, anvilImage,
+		testcontainers.WithEntrypoint("anvil"),
+		testcontainers.WithCmd("--host", "0.0.0.0", "--chain-id", "31337"),
+		testcontainers.WithExposedPorts("8545/tcp"),
+		testcontainers.WithWaitStrategy(wait.ForListeningPort("8545/tcp")),
+	)
+	if anvil != nil {
+		env.containers = append(env.containers, anvil)
+	}
+	if err != nil {
+		return env, fmt.Errorf("failed to start anvil: %w", err)
+	}
+
+	rpcURL, err := anvil.PortEndpoint(ctx, "8545/tcp", "http")
+	if err != nil {
+		return env, err
+	}
+	if env.Chain, err = ethclient.DialContext(ctx, rpcURL); err != nil {
+		return env, fmt.Errorf("failed to connect to anvil: %w", err)
+	}
+	if err := env.deployRegistry(ctx); err != nil {
+		return env, err
+	}
+
+	dbHost, err := db.Host(ctx)
+	if err != nil {
+		return env, err
+	}
+	dbPort, err := db.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		return env, err
+	}
+	natsURL, err := queue.ConnectionString(ctx)
+	if err != nil {
+		return env, err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return env, err
+	}
+	env.BaseURL = fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	if err := env.startServer(ctx, map[string]string{
+		"PORT":                      fmt.Sprint(port),
+		"DB_HOST":                   dbHost,
+		"DB_PORT":                   dbPort.Port(),
+		"DB_USER":                   "did_manager",
+		"DB_PASSWORD":               "did_manager_password",
+		"DB_NAME":                   "did_manager_db",
+		"DB_SSLMODE":                "disable",
+		"ETHEREUM_RPC_URL":          rpcURL,
+		"ETHEREUM_PRIVATE_KEY":      anvilKey,
+		"ETHEREUM_CONTRACT_ADDRESS": env.Registry.Hex(),
+		"NATS_URL":                  natsURL,
+		"ADMIN_API_KEY":             adminKey,
+		"JOB_PROCESSING_INTERVAL":   "1s",
+		"VERIFICATION_FALLBACK":     "fail_closed",
+	}); err != nil {
+		return env, err
+	}
+
+	return env, nil
+}
+
+// Stop stops the service and terminates the containers
+func (e *Environment) Stop() {
+	if e.server != nil && e.server.Process != nil {
+		e.server.Process.Signal(os.Interrupt)
+		done := make(chan struct{})
+		go func() {
+			e.server.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			e.server.Process.Kill()
+		}
+	}
+	if e.Chain != nil {
+		e.Chain.Close()
+	}
+	for i := len(e.containers) - 1; i >= 0; i-- {
+		testcontainers.TerminateContainer(e.containers[i])
+	}
+}
+
+// ServerLog returns what the service logged, to diagnose failures
+func (e *Environment) ServerLog() string {
+	if e.logPath == "" {
+		return ""
+	}
+	log, err := os.ReadFile(e.logPath)
+	if err != nil {
+		return err.Error()
+	}
+	return string(log)
+}
+
+// deployRegistry deploys DIDRegistry from its compiled artifact
+func (e *Environment) deployRegistry(ctx context.Context) error {
+	artifact, err := os.ReadFile(repoPath("contracts", "artifacts", "contracts", "DIDRegistry.sol", "DIDRegistry.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read the DIDRegistry artifact: %w", err)
+	}
+	var compiled struct {
+		ABI      json.RawMessage `json:"abi"`
+		Bytecode string          `json:"bytecode"`
+	}
+	if err := json.Unmarshal(artifact, &compiled); err != nil {
+		return fmt.Errorf("failed to decode the DIDRegistry artifact: %w", err)
+	}
+	if e.registryABI, err = abi.JSON(bytes.NewReader(compiled.ABI)); err != nil {
+		return fmt.Errorf("failed to parse the DIDRegistry ABI: %w", err)
+	}
+
+	key, err := crypto.HexToECDSA(anvilKey)
+	if err != nil {
+		return err
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	chainID, err := e.Chain.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	nonce, err := e.Chain.PendingNonceAt(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasPrice, err := e.Chain.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	tx, err := types.SignTx(
+		types.NewContractCreation(nonce, big.NewInt(0), 5_000_000, gasPrice, common.FromHex(compiled.Bytecode)),
+		types.NewEIP155Signer(chainID),
+		key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sign deployment: %w", err)
+	}
+	if err := e.Chain.SendTransaction(ctx, tx); err != nil {
+		return fmt.Errorf("failed to deploy DIDRegistry: %w", err)
+	}
+
+	receipt, err := waitMined(ctx, e.Chain, tx.Hash())
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("DIDRegistry deployment reverted")
+	}
+	e.Registry = receipt.ContractAddress
+	return nil
+}
+
+// VerifyOnChain calls the registry's verifyDID, bypassing the service
+func (e *Environment) VerifyOnChain(ctx context.Context, did string) (bool, error) {
+	data, err := e.registryABI.Pack("verifyDID", did)
+	if err != nil {
+		return false, err
+	}
+	result, err := e.Chain.CallContract(ctx, ethereum.CallMsg{To: &e.Registry, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to call verifyDID: %w", err)
+	}
+	values, err := e.registryABI.Unpack("verifyDID", result)
+	if err != nil {
+		return false, fmt.Errorf("failed to unpack verifyDID: %w", err)
+	}
+	return values[0].(bool), nil
+}
+
+// startServer builds the service and runs it with the given environment until ready
+func (e *Environment) startServer(ctx context.Context, vars map[string]string) error {
+	dir, err := os.MkdirTemp("", "did-manager-integration")
+	if err != nil {
+		return err
+	}
+	binary := filepath.Join(dir, "did-manager")
+
+	build := exec.CommandContext(ctx, "go", "build", "-o", binary, "./cmd/server")
+	build.Dir = repoPath("services", "did-manager")
+	if output, err := build.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build did-manager: %w\n%s", err, output)
+	}
+
+	e.logPath = filepath.Join(dir, "did-manager.log")
+	logFile, err := os.Create(e.logPath)
+	if err != nil {
+		return err
+	}
+
+	// The working directory has no .env file, so only vars configure the service
+	e.server = exec.Command(binary)
+	e.server.Dir = dir
+	e.server.Stdout = logFile
+	e.server.Stderr = logFile
+	e.server.Env = append(os.Environ(), "ENV=test")
+	for name, value := range vars {
+		e.server.Env = append(e.server.Env, name+"="+value)
+	}
+	if err := e.server.Start(); err != nil {
+		return fmt.Errorf("failed to start did-manager: %w", err)
+	}
+
+	deadline := time.Now().Add(60 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(e.BaseURL + "/api/v1/ready")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("did-manager did not become ready")
+}
+
+// Do sends a JSON request to the service with the given headers and decodes the
+// response's "data" into out, when set
+func (e *Environment) Do(method, path string, headers map[string]string, body, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, e.BaseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if out != nil {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// Admin returns the headers authenticating as the administrator
+func (e *Environment) Admin() map[string]string {
+	return map[string]string{"X-Admin-Key": adminKey}
+}
+
+// waitMined polls for the receipt of a transaction
+func waitMined(ctx context.Context, client *ethclient.Client, hash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s was not mined: %w", hash.Hex(), ctx.Err())
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// freePort returns a TCP port that is free on the loopback interface
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,33 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// env is the environment shared by the tests in the package
+var env *Environment
+
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	started, err := StartEnvironment(ctx)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start the integration environment: %v\n%s", err, started.ServerLog())
+		started.Stop()
+		os.Exit(1)
+	}
+	env = started
+
+	code := m.Run()
+	if code != 0 {
+		fmt.Fprintf(os.Stderr, "did-manager log:\n%s", env.ServerLog())
+	}
+	env.Stop()
+	os.Exit(code)
+}